@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/tuannvm/slack-mcp-client/internal/app"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+
+	slackbot "github.com/tuannvm/slack-mcp-client/internal/slack"
+)
+
+// runChat opens an interactive conversation in the terminal with the configured
+// LLM providers and MCP servers, to try a configuration before deploying it.
+// Nothing is posted to Slack, so no frontend tokens are needed. Logs below
+// warnings are hidden unless --debug or LOG_LEVEL is set.
+func runChat() error {
+	setDefaultLLMProvider()
+	logger := setupQuietLogging()
+	cfg, err := loadLocalConfig(logger)
+	if err != nil {
+		return err
+	}
+	cfg.Slack.Conversations.DirectMessages = config.ConversationReplyAll
+
+	fmt.Println("Starting MCP servers...")
+	mcpClients, discoveredTools, warnings, err := app.InitializeMCPServers(logger, cfg)
+	if err != nil {
+		return err
+	}
+	for _, warning := range app.ImportOpenAPITools(logger, cfg, warnings) {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+
+	repl := slackbot.NewReplClient(logger, os.Stdin, os.Stdout, cfg.Slack.ThinkingMessage)
+	client, err := slackbot.NewClient(repl, logger, mcpClients, discoveredTools, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize the chat client: %w", err)
+	}
+	addChatCommands(repl, client, cfg)
+	fmt.Printf("Chatting with %s and %d tools.\n", describeModel(cfg, "", ""), len(client.Tools()))
+
+	// Ctrl-C stops the answer in progress, or leaves the chat when there is none
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+	go func() {
+		for sig := range sigChan {
+			if sig == syscall.SIGTERM || !repl.Interrupt() {
+				_ = repl.Close()
+				return
+			}
+			fmt.Println("\n(stopped)")
+		}
+	}()
+
+	runErr := client.Run()
+	if err := client.Close(); err != nil {
+		logger.ErrorKV("Failed to close the chat client", "error", err)
+	}
+	app.CloseMCPClients(logger, client)
+	return runErr
+}
+
+// addChatCommands registers the REPL commands that inspect and switch the
+// tools and models of the chat
+func addChatCommands(repl *slackbot.ReplClient, client *slackbot.Client, cfg *config.Config) {
+	repl.AddCommand("tools", slackbot.ReplCommand{
+		Usage: "[filter]",
+		Help:  "list the tools the LLM can call",
+		Run: func(args []string) string {
+			return listChatTools(client, strings.Join(args, " "))
+		},
+	})
+
+	var provider, model string // The pinned model, "" for the configured one
+	repl.AddCommand("model", slackbot.ReplCommand{
+		Usage: "[provider [model] | default]",
+		Help:  "show the providers, or answer with another provider and model",
+		Run: func(args []string) string {
+			switch {
+			case len(args) == 0:
+				return listChatModels(cfg, provider, model)
+			case args[0] == "default":
+				if err := client.SetModel("", ""); err != nil {
+					return fmt.Sprintf("Failed to restore the configured model: %v", err)
+				}
+				provider, model = "", ""
+			default:
+				nextModel := ""
+				if len(args) > 1 {
+					nextModel = args[1]
+				}
+				if err := client.SetModel(args[0], nextModel); err != nil {
+					return fmt.Sprintf("Cannot switch to %s: %v", args[0], err)
+				}
+				provider, model = args[0], nextModel
+			}
+			return "Now answering with " + describeModel(cfg, provider, model) + "."
+		},
+	})
+}
+
+// listChatTools lists the tools whose name, server or description contain filter
+func listChatTools(client *slackbot.Client, filter string) string {
+	tools := client.Tools()
+	names := make([]string, 0, len(tools))
+	for name, tool := range tools {
+		text := strings.ToLower(name + " " + tool.ServerName + " " + tool.ToolDescription)
+		if strings.Contains(text, strings.ToLower(filter)) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "No tools found."
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		tool := tools[name]
+		description, _, _ := strings.Cut(strings.TrimSpace(tool.ToolDescription), "\n")
+		fmt.Fprintf(&b, "  %s (%s): %s\n", name, tool.ServerName, description)
+	}
+	fmt.Fprintf(&b, "%d tool(s)", len(names))
+	return b.String()
+}
+
+// listChatModels lists the configured providers, marking the one answering
+func listChatModels(cfg *config.Config, provider, model string) string {
+	names := make([]string, 0, len(cfg.LLM.Providers))
+	for name := range cfg.LLM.Providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	current := provider
+	if current == "" {
+		current = cfg.LLM.Provider
+	}
+	var b strings.Builder
+	for _, name := range names {
+		marker := " "
+		if name == current {
+			marker = "*"
+		}
+		fmt.Fprintf(&b, "%s %s (model: %s)\n", marker, name, cfg.LLM.Providers[name].Model)
+	}
+	fmt.Fprintf(&b, "Answering with %s.", describeModel(cfg, provider, model))
+	return b.String()
+}
+
+// describeModel names the provider and model answering, which are the configured
+// ones when provider is empty
+func describeModel(cfg *config.Config, provider, model string) string {
+	if provider == "" {
+		provider = cfg.LLM.Provider
+		if cfg.LLM.Routing.Enabled {
+			return provider + " (model routing enabled)"
+		}
+	}
+	if model == "" {
+		model = cfg.LLM.Providers[provider].Model
+	}
+	if model == "" {
+		return provider
+	}
+	return provider + "/" + model
+}
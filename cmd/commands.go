@@ -0,0 +1,740 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/tuannvm/slack-mcp-client/internal/app"
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/doctor"
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+)
+
+// command is a command of the CLI, such as "rag search". A command either runs
+// or groups subcommands.
+type command struct {
+	Name     string
+	Args     string // Positional arguments shown in the usage, e.g. "<query>"
+	NArgs    int    // Number of positional arguments; -1 for one or more
+	Short    string // Shown in the list of commands
+	Long     string // Shown in the command's help (default: Short)
+	Flags    func(fs *flag.FlagSet)
+	Run      func(args []string) error
+	Commands []*command
+
+	parent *command
+}
+
+// persistentFlags are the global flags that every command accepts
+var persistentFlags = []string{"config", "config-profile", "config-extra", "debug", "mcpdebug"}
+
+// deprecatedFlags maps the flags that predate the subcommands to their replacement
+var deprecatedFlags = map[string]string{
+	"config-validate":       "config validate",
+	"config-schema":         "config schema",
+	"migrate-config":        "config migrate",
+	"migrate-output":        "config migrate --output",
+	"migrate-dry-run":       "config migrate --dry-run",
+	"rag-init":              "rag init",
+	"rag-ingest":            "rag ingest",
+	"rag-ingest-url":        "rag ingest-url",
+	"rag-search":            "rag search",
+	"rag-list":              "rag list",
+	"rag-delete":            "rag delete",
+	"rag-stats":             "rag stats",
+	"rag-dedupe":            "rag dedupe",
+	"rag-gc":                "rag gc",
+	"rag-gc-dry-run":        "rag gc --dry-run",
+	"rag-gc-min-age":        "rag gc --min-age",
+	"rag-eval":              "rag eval",
+	"rag-eval-k":            "rag eval --k",
+	"rag-db":                "rag <command> --db",
+	"rag-provider":          "rag <command> --provider",
+	"rag-namespace":         "rag <command> --namespace",
+	"rag-ingest-workers":    "rag ingest --workers",
+	"rag-crawl-depth":       "rag ingest-url --depth",
+	"rag-max-pages":         "rag ingest-url --max-pages",
+	"rag-chunk-strategy":    "rag ingest --chunk-strategy",
+	"rag-chunk-size":        "rag ingest --chunk-size",
+	"rag-chunk-overlap":     "rag ingest --chunk-overlap",
+	"rag-chunk-unit":        "rag ingest --chunk-unit",
+	"rag-assistant-name":    "rag init --assistant-name",
+	"rag-vector-store-name": "rag init --vector-store-name",
+}
+
+// rootCommand returns the command tree of the CLI. Without a command, the bot runs.
+func rootCommand() *command {
+	root := &command{
+		Name:  "slack-mcp-client",
+		Short: "Slack bot that answers with LLMs and the tools of MCP servers",
+		Run:   func([]string) error { return runServe() },
+		Commands: []*command{
+			{
+				Name:  "serve",
+				Short: "Run the bot (the default command)",
+				Flags: func(fs *flag.FlagSet) {
+					fs.StringVar(metricsPort, "metrics-port", *metricsPort, "Port for metrics endpoint")
+				},
+				Run: func([]string) error { return runServe() },
+			},
+			{
+				Name:  "chat",
+				Short: "Chat with the configured LLM providers and MCP servers in the terminal",
+				Run:   func([]string) error { return runChat() },
+			},
+			configCommand(),
+			ragCommand(),
+			toolsCommand(),
+			doctorCommand(),
+		},
+	}
+	root.link()
+	return root
+}
+
+// configCommand groups the commands that work on the configuration file
+func configCommand() *command {
+	return &command{
+		Name:  "config",
+		Short: "Validate, migrate and describe the configuration file",
+		Commands: []*command{
+			{
+				Name:  "validate",
+				Short: "Validate the configuration file, report every problem found",
+				Run:   func([]string) error { handleConfigValidate(); return nil },
+			},
+			{
+				Name:  "schema",
+				Short: "Print the JSON Schema of the configuration file",
+				Run:   func([]string) error { handleConfigSchema(); return nil },
+			},
+			{
+				Name:  "migrate",
+				Short: "Migrate a legacy configuration file (--config, default mcp-servers.json) to the current format",
+				Flags: func(fs *flag.FlagSet) {
+					fs.StringVar(migrateOutput, "output", *migrateOutput, "File the migrated configuration is written to")
+					fs.BoolVar(migrateDryRun, "dry-run", *migrateDryRun, "Print the migrated configuration instead of writing it")
+				},
+				Run: func([]string) error {
+					handleConfigMigration(*configFile, *migrateOutput, *migrateDryRun)
+					return nil
+				},
+			},
+		},
+	}
+}
+
+// ragCommand groups the commands that manage the knowledge base
+func ragCommand() *command {
+	return &command{
+		Name:  "rag",
+		Short: "Manage the knowledge base",
+		Commands: []*command{
+			{
+				Name:  "init",
+				Short: "Initialize the vector store",
+				Flags: func(fs *flag.FlagSet) {
+					ragFlags(fs)
+					fs.StringVar(ragAssistantName, "assistant-name", *ragAssistantName, "Name for the OpenAI assistant")
+					fs.StringVar(ragVectorStoreName, "vector-store-name", *ragVectorStoreName, "Name for the vector store")
+				},
+				Run: func([]string) error { handleRAGInit(); return nil },
+			},
+			{
+				Name:  "ingest",
+				Args:  "<directory>",
+				NArgs: 1,
+				Short: "Ingest the PDF files of a directory",
+				Flags: func(fs *flag.FlagSet) {
+					ragFlags(fs)
+					ragChunkFlags(fs)
+					fs.IntVar(ragIngestWorkers, "workers", *ragIngestWorkers, "Files ingested at once")
+				},
+				Run: func(args []string) error { handleRAGIngest(args[0]); return nil },
+			},
+			{
+				Name:  "ingest-url",
+				Args:  "<url>",
+				NArgs: 1,
+				Short: "Ingest a web page and, with --depth, the same-site pages it links to",
+				Flags: func(fs *flag.FlagSet) {
+					ragFlags(fs)
+					ragChunkFlags(fs)
+					fs.IntVar(ragCrawlDepth, "depth", *ragCrawlDepth, "Link depth to crawl (0 ingests only the page)")
+					fs.IntVar(ragMaxPages, "max-pages", *ragMaxPages, "Maximum pages to fetch")
+				},
+				Run: func(args []string) error { handleRAGIngestURL(args[0]); return nil },
+			},
+			{
+				Name:  "search",
+				Args:  "<query>",
+				NArgs: -1,
+				Short: "Search the knowledge base",
+				Flags: ragFlags,
+				Run:   func(args []string) error { handleRAGSearch(strings.Join(args, " ")); return nil },
+			},
+			{
+				Name:  "list",
+				Short: "List the files in the knowledge base",
+				Flags: ragFlags,
+				Run:   func([]string) error { handleRAGList(); return nil },
+			},
+			{
+				Name:  "delete",
+				Args:  "<ids>",
+				NArgs: 1,
+				Short: "Delete files from the knowledge base (comma-separated IDs)",
+				Flags: ragFlags,
+				Run:   func(args []string) error { handleRAGDelete(args[0]); return nil },
+			},
+			{
+				Name:  "stats",
+				Short: "Show knowledge base statistics",
+				Flags: ragFlags,
+				Run:   func([]string) error { handleRAGStats(); return nil },
+			},
+			{
+				Name:  "dedupe",
+				Short: "Remove duplicated documents and chunks from the knowledge base",
+				Flags: ragFlags,
+				Run:   func([]string) error { handleRAGDedupe(); return nil },
+			},
+			{
+				Name:  "gc",
+				Short: "Remove orphaned files and failed vector store entries (openai provider)",
+				Flags: func(fs *flag.FlagSet) {
+					ragFlags(fs)
+					fs.BoolVar(ragGCDryRun, "dry-run", *ragGCDryRun, "Report what would be removed without removing it")
+					fs.DurationVar(ragGCMinAge, "min-age", *ragGCMinAge, "Keep unattached files younger than this, as their ingest may still be running")
+				},
+				Run: func([]string) error { handleRAGGC(); return nil },
+			},
+			{
+				Name:  "eval",
+				Args:  "<file>",
+				NArgs: 1,
+				Short: "Score a YAML file of questions and expected sources: recall@k, MRR and latency",
+				Flags: func(fs *flag.FlagSet) {
+					ragFlags(fs)
+					fs.IntVar(ragEvalK, "k", *ragEvalK, "Results scored per question (default: the file's k, or 5)")
+				},
+				Run: func(args []string) error { handleRAGEval(args[0]); return nil },
+			},
+		},
+	}
+}
+
+// ragFlags registers the flags that select the knowledge base
+func ragFlags(fs *flag.FlagSet) {
+	fs.StringVar(ragDatabase, "db", *ragDatabase, "Path to RAG database file (JSON databases are migrated to SQLite)")
+	fs.StringVar(ragProvider, "provider", *ragProvider, "RAG provider to use (simple, json, openai)")
+	fs.StringVar(ragNamespace, "namespace", *ragNamespace, "Knowledge base namespace")
+}
+
+// ragChunkFlags registers the flags that control chunking during ingestion
+func ragChunkFlags(fs *flag.FlagSet) {
+	fs.StringVar(ragChunkStrategy, "chunk-strategy", *ragChunkStrategy, "Chunking strategy (recursive, sentence, markdown, semantic; default: rag.chunking.strategy of the config)")
+	fs.IntVar(ragChunkSize, "chunk-size", *ragChunkSize, "Maximum chunk size in --chunk-unit (default: rag.chunking.size of the config)")
+	fs.IntVar(ragChunkOverlap, "chunk-overlap", *ragChunkOverlap, "Text repeated between consecutive chunks (default: rag.chunking.overlap of the config)")
+	fs.StringVar(ragChunkUnit, "chunk-unit", *ragChunkUnit, "Unit of chunk sizes (characters, tokens; default: rag.chunking.unit of the config)")
+}
+
+// toolsCommand groups the commands that inspect the tools of the MCP servers
+func toolsCommand() *command {
+	var server, callArgs string
+	var schemas bool
+	return &command{
+		Name:  "tools",
+		Short: "Inspect and call the tools of the configured MCP servers",
+		Commands: []*command{
+			{
+				Name:  "list",
+				Short: "Start the MCP servers and list their tools",
+				Flags: func(fs *flag.FlagSet) {
+					fs.StringVar(&server, "server", "", "Only start and list this MCP server")
+					fs.BoolVar(&schemas, "schemas", false, "Print the input schema of each tool")
+				},
+				Run: func([]string) error { return runToolsList(server, schemas) },
+			},
+			{
+				Name:  "call",
+				Args:  "<tool>",
+				NArgs: 1,
+				Short: "Call a tool with JSON arguments and print its result",
+				Long: "Call a tool with JSON arguments and print its result. The tool is named as in\n" +
+					"\"tools list\", or by its name on the server when --server is set.",
+				Flags: func(fs *flag.FlagSet) {
+					fs.StringVar(&server, "server", "", "Only start this MCP server")
+					fs.StringVar(&callArgs, "args", "{}", "Arguments as a JSON object, or - to read them from stdin")
+				},
+				Run: func(args []string) error { return runToolsCall(server, args[0], callArgs) },
+			},
+		},
+	}
+}
+
+// startToolServers starts the configured MCP servers, or only server, and
+// returns their tools and a function that stops them
+func startToolServers(logger *logging.Logger, server string) (map[string]mcp.ToolInfo, int, func(), error) {
+	cfg, err := loadLocalConfig(logger)
+	if err != nil {
+		return nil, 0, func() {}, err
+	}
+	if server != "" {
+		serverConfig, ok := cfg.MCPServers[server]
+		if !ok {
+			return nil, 0, func() {}, fmt.Errorf("no MCP server named '%s' in the configuration", server)
+		}
+		cfg.MCPServers = map[string]config.MCPServerConfig{server: serverConfig}
+	}
+
+	mcpClients, discoveredTools, warnings, err := app.InitializeMCPServers(logger, cfg)
+	stop := func() {
+		for name, mcpClient := range mcpClients {
+			if err := mcpClient.Close(); err != nil {
+				logger.ErrorKV("Failed to close MCP client", "name", name, "error", err)
+			}
+		}
+	}
+	if err != nil {
+		return nil, 0, stop, err
+	}
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+	for name, tool := range discoveredTools {
+		tool.Timeout = cfg.GetToolTimeout(tool.ServerName, tool.RemoteName)
+		discoveredTools[name] = tool
+	}
+	return discoveredTools, len(mcpClients), stop, nil
+}
+
+// runToolsList starts the configured MCP servers, or only server, and lists their
+// tools, with their input schemas when schemas is set
+func runToolsList(server string, schemas bool) error {
+	logger := setupQuietLogging()
+	discoveredTools, serverCount, stop, err := startToolServers(logger, server)
+	defer stop()
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(discoveredTools))
+	for name := range discoveredTools {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := discoveredTools[names[i]], discoveredTools[names[j]]
+		if a.ServerName != b.ServerName {
+			return a.ServerName < b.ServerName
+		}
+		return names[i] < names[j]
+	})
+
+	if schemas {
+		for _, name := range names {
+			tool := discoveredTools[name]
+			fmt.Printf("%s (%s)\n", name, tool.ServerName)
+			if description := strings.TrimSpace(tool.ToolDescription); description != "" {
+				fmt.Printf("  %s\n", strings.ReplaceAll(description, "\n", "\n  "))
+			}
+			schema, err := json.MarshalIndent(tool.InputSchema, "  ", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to encode the input schema of %s: %w", name, err)
+			}
+			fmt.Printf("  %s\n\n", schema)
+		}
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "TOOL\tSERVER\tDESCRIPTION")
+		for _, name := range names {
+			tool := discoveredTools[name]
+			description, _, _ := strings.Cut(strings.TrimSpace(tool.ToolDescription), "\n")
+			fmt.Fprintf(w, "%s\t%s\t%s\n", name, tool.ServerName, description)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		fmt.Println()
+	}
+	fmt.Printf("%d tool(s) from %d server(s)\n", len(names), serverCount)
+	return nil
+}
+
+// runToolsCall starts the configured MCP servers, or only server, calls toolName
+// with the JSON object rawArgs ("-" reads it from stdin) and prints the result
+func runToolsCall(server, toolName, rawArgs string) error {
+	if rawArgs == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("failed to read the arguments from stdin: %w", err)
+		}
+		rawArgs = string(data)
+	}
+	args := map[string]interface{}{}
+	if strings.TrimSpace(rawArgs) != "" {
+		if err := json.Unmarshal([]byte(rawArgs), &args); err != nil {
+			return fmt.Errorf("the arguments must be a JSON object: %w", err)
+		}
+	}
+
+	logger := setupQuietLogging()
+	discoveredTools, _, stop, err := startToolServers(logger, server)
+	defer stop()
+	if err != nil {
+		return err
+	}
+	name, tool, err := findTool(discoveredTools, toolName, server != "")
+	if err != nil {
+		return err
+	}
+
+	started := time.Now()
+	result, err := mcp.CallToolWithTimeout(context.Background(), tool.Client, name, tool.Timeout, args)
+	if err != nil {
+		return fmt.Errorf("tool %s failed after %s: %w", name, time.Since(started).Round(time.Millisecond), err)
+	}
+	fmt.Println(result)
+	fmt.Fprintf(os.Stderr, "Called %s on %s in %s\n", name, tool.ServerName, time.Since(started).Round(time.Millisecond))
+	return nil
+}
+
+// findTool finds a tool by the name the LLM sees or, when a single server runs,
+// by its name on the server
+func findTool(tools map[string]mcp.ToolInfo, name string, singleServer bool) (string, mcp.ToolInfo, error) {
+	if tool, ok := tools[name]; ok {
+		return name, tool, nil
+	}
+	if singleServer {
+		for toolName, tool := range tools {
+			if tool.RemoteName == name {
+				return toolName, tool, nil
+			}
+		}
+	}
+	return "", mcp.ToolInfo{}, fmt.Errorf("no tool named '%s', run \"tools list\" to see the available tools", name)
+}
+
+// doctorCommand checks the environment the bot needs
+func doctorCommand() *command {
+	var noColor bool
+	return &command{
+		Name:  "doctor",
+		Short: "Check the Slack tokens, LLM providers, MCP servers and knowledge base",
+		Long: "Check the Slack tokens and their scopes, that the LLM providers are reachable and\n" +
+			"serve their models, that each MCP server starts, and that the knowledge base opens.\n" +
+			"Exits with status 1 when a check fails.",
+		Flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&noColor, "no-color", false, "Print the report without colors (default: colors on a terminal unless NO_COLOR is set)")
+		},
+		Run: func([]string) error { return runDoctor(!noColor && colorTerminal()) },
+	}
+}
+
+// runDoctor runs the environment checks and prints their report
+func runDoctor(color bool) error {
+	setDefaultLLMProvider()
+	logger := setupQuietLogging()
+	cfg, err := loadLocalConfig(logger)
+	if err != nil {
+		return fmt.Errorf("%w (run the config validate command for details)", err)
+	}
+	fmt.Println("Checking the environment...")
+	fmt.Println()
+	report := doctor.NewChecker(cfg, logger).Run(context.Background())
+	report.Print(os.Stdout, color)
+	if report.Failed() {
+		return errors.New("some checks failed")
+	}
+	return nil
+}
+
+// colorTerminal reports whether stdout is a terminal that should show colors
+func colorTerminal() bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// setupQuietLogging sets up logging for commands whose output is for the user:
+// logs below warnings are hidden unless --debug or LOG_LEVEL is set
+func setupQuietLogging() *logging.Logger {
+	if os.Getenv("LOG_LEVEL") == "" && !*debug {
+		if err := os.Setenv("LOG_LEVEL", "warn"); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to set LOG_LEVEL environment variable: %v\n", err)
+		}
+	}
+	return setupLogging()
+}
+
+// loadLocalConfig loads the configuration for commands that run in the terminal,
+// which need no frontend tokens
+func loadLocalConfig(logger *logging.Logger) (*config.Config, error) {
+	cfg, err := config.ReadConfig(*configFile, logger, configOverlayFiles...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	cfg.UseStdIOClient = true
+	if err := cfg.ValidateAfterDefaults(); err != nil {
+		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	}
+	if err := app.ConfigureNetwork(logger, cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// link sets the parent of every subcommand
+func (c *command) link() {
+	for _, sub := range c.Commands {
+		sub.parent = c
+		sub.link()
+	}
+}
+
+// path returns the command line that runs the command, e.g. "slack-mcp-client rag search"
+func (c *command) path() string {
+	if c.parent == nil {
+		return c.Name
+	}
+	return c.parent.path() + " " + c.Name
+}
+
+// find returns the subcommand with the given name, or nil
+func (c *command) find(name string) *command {
+	for _, sub := range c.Commands {
+		if sub.Name == name {
+			return sub
+		}
+	}
+	return nil
+}
+
+// execute runs the command named by args, which start after the global flags,
+// and returns the exit code
+func (c *command) execute(args []string) int {
+	// Walk down to the command named by the leading arguments
+	cmd := c
+	for len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		if args[0] == "help" {
+			cmd.helpFor(args[1:]).printHelp(os.Stdout)
+			return 0
+		}
+		sub := cmd.find(args[0])
+		if sub == nil {
+			break
+		}
+		cmd, args = sub, args[1:]
+	}
+	if cmd.Run == nil && (len(args) == 0 || !strings.HasPrefix(args[0], "-")) {
+		if len(args) > 0 {
+			fmt.Fprintf(os.Stderr, "Error: unknown command \"%s\" for \"%s\"\n", args[0], cmd.path())
+			fmt.Fprintf(os.Stderr, "Run '%s --help' for usage.\n", cmd.path())
+			return 1
+		}
+		cmd.printHelp(os.Stdout)
+		return 0
+	}
+
+	fs := cmd.flagSet()
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 1
+	}
+	if cmd.Run == nil {
+		cmd.printHelp(os.Stdout)
+		return 0
+	}
+	if cmd == c && len(positional) > 0 {
+		fmt.Fprintf(os.Stderr, "Error: unknown command \"%s\" for \"%s\"\n", positional[0], cmd.path())
+		fmt.Fprintf(os.Stderr, "Run '%s --help' for usage.\n", cmd.path())
+		return 1
+	}
+	if cmd != c && !cmd.acceptsArgs(len(positional)) {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", cmd.argsError(len(positional)))
+		fmt.Fprintf(os.Stderr, "Usage: %s\n", cmd.usage())
+		return 1
+	}
+
+	explicitConfig := false
+	visit := func(f *flag.Flag) {
+		explicitFlags[f.Name] = true
+		if f.Name == "config" {
+			explicitConfig = true
+		}
+	}
+	flag.Visit(visit)
+	fs.Visit(visit)
+	if err := prepareConfigFlags(explicitConfig); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if err := cmd.Run(positional); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// parseInterspersed parses the flags of args, which may follow the positional
+// arguments as with cobra, and returns the positional arguments. Everything
+// after "--" is positional.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		positional, args = append(positional, rest[0]), rest[1:]
+	}
+}
+
+// helpFor returns the command that "help" followed by args asks about
+func (c *command) helpFor(args []string) *command {
+	cmd := c
+	for _, name := range args {
+		sub := cmd.find(name)
+		if sub == nil {
+			break
+		}
+		cmd = sub
+	}
+	return cmd
+}
+
+// flagSet returns the flags of the command: the global flags, which the root
+// command already parsed, and the command's own
+func (c *command) flagSet() *flag.FlagSet {
+	if c.parent == nil {
+		return flag.CommandLine
+	}
+	fs := flag.NewFlagSet(c.path(), flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	fs.Usage = func() { c.printHelp(os.Stderr) }
+	for _, name := range persistentFlags {
+		f := flag.Lookup(name)
+		fs.Var(f.Value, f.Name, f.Usage)
+	}
+	if c.Flags != nil {
+		c.Flags(fs)
+	}
+	return fs
+}
+
+// acceptsArgs reports whether the command takes n positional arguments
+func (c *command) acceptsArgs(n int) bool {
+	if c.NArgs < 0 {
+		return n > 0
+	}
+	return n == c.NArgs
+}
+
+// argsError describes why the positional arguments were rejected
+func (c *command) argsError(n int) string {
+	if c.NArgs < 0 {
+		return fmt.Sprintf("%s requires %s", c.path(), c.Args)
+	}
+	return fmt.Sprintf("%s accepts %d arg(s), received %d", c.path(), c.NArgs, n)
+}
+
+// usage returns the command's usage line
+func (c *command) usage() string {
+	usage := c.path()
+	if c.Args != "" {
+		usage += " " + c.Args
+	}
+	return usage + " [flags]"
+}
+
+// printHelp prints the help of the command in the style of cobra
+func (c *command) printHelp(w io.Writer) {
+	description := c.Long
+	if description == "" {
+		description = c.Short
+	}
+	fmt.Fprintf(w, "%s\n\nUsage:\n", description)
+	if c.Run != nil {
+		fmt.Fprintf(w, "  %s\n", c.usage())
+	}
+	if len(c.Commands) > 0 {
+		fmt.Fprintf(w, "  %s [command]\n\nAvailable Commands:\n", c.path())
+		tw := tabwriter.NewWriter(w, 0, 4, 3, ' ', 0)
+		for _, sub := range c.Commands {
+			fmt.Fprintf(tw, "  %s\t%s\n", sub.Name, sub.Short)
+		}
+		if c.parent == nil {
+			fmt.Fprintf(tw, "  help\tHelp about any command\n")
+		}
+		_ = tw.Flush()
+	}
+
+	if c.parent == nil {
+		fmt.Fprintf(w, "\nFlags:\n")
+		printFlags(w, flag.CommandLine, func(name string) bool {
+			_, deprecated := deprecatedFlags[name]
+			return !deprecated
+		})
+	} else {
+		if c.Flags != nil {
+			fmt.Fprintf(w, "\nFlags:\n")
+			fs := flag.NewFlagSet(c.Name, flag.ContinueOnError)
+			c.Flags(fs)
+			printFlags(w, fs, func(string) bool { return true })
+		}
+		fmt.Fprintf(w, "\nGlobal Flags:\n")
+		printFlags(w, flag.CommandLine, func(name string) bool {
+			for _, persistent := range persistentFlags {
+				if name == persistent {
+					return true
+				}
+			}
+			return false
+		})
+	}
+
+	if len(c.Commands) > 0 {
+		fmt.Fprintf(w, "\nUse \"%s [command] --help\" for more information about a command.\n", c.path())
+	}
+}
+
+// printFlags prints the flags of fs that show selects, with their type and default
+func printFlags(w io.Writer, fs *flag.FlagSet, show func(name string) bool) {
+	tw := tabwriter.NewWriter(w, 0, 4, 3, ' ', 0)
+	fs.VisitAll(func(f *flag.Flag) {
+		if !show(f.Name) {
+			return
+		}
+		typeName, usage := flag.UnquoteUsage(f)
+		name := "--" + f.Name
+		if typeName != "" {
+			name += " " + typeName
+		}
+		if f.DefValue != "" && f.DefValue != "0" && f.DefValue != "false" {
+			usage += fmt.Sprintf(" (default %q)", f.DefValue)
+		}
+		fmt.Fprintf(tw, "  %s\t%s\n", name, usage)
+	})
+	_ = tw.Flush()
+}
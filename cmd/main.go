@@ -4,13 +4,11 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
-	"os/exec"
 	"os/signal"
 	"strings"
 	"syscall"
@@ -18,120 +16,254 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/tuannvm/slack-mcp-client/internal/app"
-	customErrors "github.com/tuannvm/slack-mcp-client/internal/common/errors"
+	"github.com/tuannvm/slack-mcp-client/internal/availability"
 	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
 	"github.com/tuannvm/slack-mcp-client/internal/config"
-	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+	"github.com/tuannvm/slack-mcp-client/internal/llm"
 	"github.com/tuannvm/slack-mcp-client/internal/monitoring"
 	"github.com/tuannvm/slack-mcp-client/internal/rag"
-
 	slackbot "github.com/tuannvm/slack-mcp-client/internal/slack"
+
+	"github.com/tuannvm/slack-mcp-client/pkg/middleware"
 )
 
 // ToolInfo definition is moved to internal/common/types.go
 
 var (
 	// Define command-line flags
-	configFile  = flag.String("config", "config.json", "Path to the configuration file (supports both config.json and legacy mcp-servers.json formats)")
+	configFile  = flag.String("config", "config.json", "Path to the configuration file (supports both config.json and legacy mcp-servers.json formats); empty to configure from environment variables only")
 	debug       = flag.Bool("debug", false, "Enable debug logging")
 	mcpDebug    = flag.Bool("mcpdebug", false, "Enable debug logging for MCP clients")
-	metricsPort = flag.String("metrics-port", "8080", "Port for metrics endpoint (default: 8080)")
+	metricsPort = flag.String("metrics-port", "8080", "Port for metrics endpoint")
 	// Configuration validation flag
-	configValidate = flag.Bool("config-validate", false, "Validate configuration file and exit")
+	configValidate = flag.Bool("config-validate", false, "Validate configuration file, report every problem found and exit")
+	// Configuration overlay flags
+	configProfile = flag.String("config-profile", "", "Profile whose overlay (e.g. config.prod.json for \"prod\") is merged into the config file (default: $CONFIG_PROFILE)")
+	configExtra   stringList
+	// Overlays resolved from the flags at startup
+	configOverlayFiles []string
+	// Flags given on the command line, by name, for options that override the config
+	explicitFlags = make(map[string]bool)
+	// Configuration schema flag
+	configSchema = flag.Bool("config-schema", false, "Print the JSON Schema of the configuration file and exit")
 	// Configuration migration flag
 	migrateConfig = flag.Bool("migrate-config", false, "Migrate legacy configuration to new format and exit")
+	migrateOutput = flag.String("migrate-output", "config.json", "File written by --migrate-config")
+	migrateDryRun = flag.Bool("migrate-dry-run", false, "Print the configuration --migrate-config would write instead of writing it")
 
 	// RAG-related flags
 	ragIngest          = flag.String("rag-ingest", "", "Ingest PDF files from directory and exit")
 	ragSearch          = flag.String("rag-search", "", "Search RAG database and exit")
-	ragDatabase        = flag.String("rag-db", "./knowledge.json", "Path to RAG database file")
-	ragProvider        = flag.String("rag-provider", "", "RAG provider to use (simple, openai)")
+	ragDatabase        = flag.String("rag-db", "./knowledge.json", "Path to RAG database file (JSON databases are migrated to SQLite)")
+	ragProvider        = flag.String("rag-provider", "", "RAG provider to use (simple, json, openai)")
 	ragInit            = flag.Bool("rag-init", false, "Initialize vector store and exit")
 	ragList            = flag.Bool("rag-list", false, "List files in vector store and exit")
 	ragDelete          = flag.String("rag-delete", "", "Delete files from vector store (comma-separated IDs) and exit")
 	ragStats           = flag.Bool("rag-stats", false, "Show RAG statistics and exit")
+	ragEval            = flag.String("rag-eval", "", "Run a YAML file of questions and expected sources against the RAG database, report recall@k, MRR and latency, and exit")
+	ragEvalK           = flag.Int("rag-eval-k", 0, "Results scored per question with --rag-eval (default: the file's k, or 5)")
+	ragDedupe          = flag.Bool("rag-dedupe", false, "Remove duplicated documents and chunks from the RAG database and exit")
+	ragGC              = flag.Bool("rag-gc", false, "Remove orphaned files and failed vector store entries from the OpenAI account and exit")
+	ragGCDryRun        = flag.Bool("rag-gc-dry-run", false, "Report what --rag-gc would remove without removing it")
+	ragGCMinAge        = flag.Duration("rag-gc-min-age", 24*time.Hour, "Keep unattached files younger than this with --rag-gc")
+	ragNamespace       = flag.String("rag-namespace", "", "Knowledge base namespace to ingest into, search or list")
+	ragIngestURL       = flag.String("rag-ingest-url", "", "Ingest a web page (and crawl same-site links up to --rag-crawl-depth) and exit")
+	ragIngestWorkers   = flag.Int("rag-ingest-workers", rag.DefaultIngestWorkers, "Files ingested at once with --rag-ingest")
+	ragCrawlDepth      = flag.Int("rag-crawl-depth", 0, "Link depth to crawl with --rag-ingest-url (0 ingests only the page)")
+	ragMaxPages        = flag.Int("rag-max-pages", rag.DefaultWebMaxPages, "Maximum pages to fetch with --rag-ingest-url")
+	ragChunkStrategy   = flag.String("rag-chunk-strategy", "", "Chunking strategy for ingestion (recursive, sentence, markdown, semantic; default: rag.chunking.strategy)")
+	ragChunkSize       = flag.Int("rag-chunk-size", 0, "Maximum chunk size in --rag-chunk-unit (default: rag.chunking.size)")
+	ragChunkOverlap    = flag.Int("rag-chunk-overlap", 0, "Text repeated between consecutive chunks (default: rag.chunking.overlap)")
+	ragChunkUnit       = flag.String("rag-chunk-unit", "", "Unit of chunk sizes (characters, tokens; default: rag.chunking.unit)")
 	ragAssistantName   = flag.String("rag-assistant-name", "", "Name for the OpenAI assistant (for init)")
 	ragVectorStoreName = flag.String("rag-vector-store-name", "", "Name for the vector store (for init)")
 )
 
 func init() {
 	monitoring.RegisterMetrics()
+	flag.Var(&configExtra, "config-extra", "Config overlay deep-merged into the config file after the profile overlay (repeatable)")
 }
 
-func main() {
-	flag.Parse()
+// stringList is a flag that can be given several times
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
 
-	// Validate configuration and exit if requested
-	if *configValidate {
-		// Load and validate config (runtime validation and strict JSON parsing)
-		if _, err := config.LoadConfig(*configFile, nil); err != nil {
-			fmt.Fprintf(os.Stderr, "Configuration validation failed: %v\n", err)
-			os.Exit(1)
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// configOverlays returns the config overlays in the order they are merged: the
+// profile's overlay, then each --config-extra file
+func configOverlays() ([]string, error) {
+	var overlays []string
+	profile := *configProfile
+	if profile == "" {
+		profile = os.Getenv(config.EnvConfigProfile)
+	}
+	if profile != "" {
+		if *configFile == "" {
+			return nil, fmt.Errorf("config profile '%s' requires a config file", profile)
 		}
-		fmt.Println("Configuration is valid")
-		os.Exit(0)
+		overlays = append(overlays, config.ProfilePath(*configFile, profile))
 	}
+	return append(overlays, configExtra...), nil
+}
 
-	// Migrate configuration and exit if requested
-	if *migrateConfig {
-		handleConfigMigration(*configFile)
-		return
+// configFilePath returns the configuration file to load. Without --config, a
+// missing config.json means configuring from environment variables only, so
+// deployments need not mount a file.
+func configFilePath(explicit bool) string {
+	if !explicit {
+		if _, err := os.Stat(*configFile); os.IsNotExist(err) {
+			return ""
+		}
 	}
+	return *configFile
+}
 
-	// Handle RAG utility commands first (these exit after completion)
-	if *ragInit {
-		handleRAGInit()
-		return
-	}
+func main() {
+	root := rootCommand()
+	flag.Usage = func() { root.printHelp(os.Stderr) }
+	flag.Parse()
 
-	if *ragIngest != "" {
-		handleRAGIngest(*ragIngest)
+	// The flags that predate the subcommands still work, with a deprecation warning
+	if handleDeprecatedFlags() {
 		return
 	}
+	os.Exit(root.execute(flag.Args()))
+}
 
-	if *ragSearch != "" {
-		handleRAGSearch(*ragSearch)
-		return
+// prepareConfigFlags resolves the config file and its overlays from the flags.
+// explicitConfig is whether --config was given.
+func prepareConfigFlags(explicitConfig bool) error {
+	*configFile = configFilePath(explicitConfig)
+	overlays, err := configOverlays()
+	if err != nil {
+		return err
 	}
+	configOverlayFiles = overlays
+	return nil
+}
 
-	if *ragList {
-		handleRAGList()
-		return
+// handleDeprecatedFlags runs the command selected by a flag that predates the
+// subcommands, and reports whether there was one
+func handleDeprecatedFlags() bool {
+	explicitConfig := false
+	deprecated := false
+	flag.Visit(func(f *flag.Flag) {
+		explicitFlags[f.Name] = true
+		if f.Name == "config" {
+			explicitConfig = true
+		}
+		if replacement, ok := deprecatedFlags[f.Name]; ok {
+			fmt.Fprintf(os.Stderr, "Flag --%s has been deprecated, use \"%s\" instead\n", f.Name, replacement)
+			deprecated = true
+		}
+	})
+	if !deprecated {
+		return false
 	}
-
-	if *ragDelete != "" {
-		handleRAGDelete(*ragDelete)
-		return
+	if err := prepareConfigFlags(explicitConfig); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
 	}
 
-	if *ragStats {
+	switch {
+	case *configValidate:
+		handleConfigValidate()
+	case *configSchema:
+		handleConfigSchema()
+	case *migrateConfig:
+		handleConfigMigration(*configFile, *migrateOutput, *migrateDryRun)
+	case *ragInit:
+		handleRAGInit()
+	case *ragIngest != "":
+		handleRAGIngest(*ragIngest)
+	case *ragIngestURL != "":
+		handleRAGIngestURL(*ragIngestURL)
+	case *ragSearch != "":
+		handleRAGSearch(*ragSearch)
+	case *ragList:
+		handleRAGList()
+	case *ragDelete != "":
+		handleRAGDelete(*ragDelete)
+	case *ragStats:
 		handleRAGStats()
-		return
-	}
+	case *ragDedupe:
+		handleRAGDedupe()
+	case *ragGC:
+		handleRAGGC()
+	case *ragEval != "":
+		handleRAGEval(*ragEval)
+	default:
+		// Only option flags such as --rag-db were given; run the default command
+		return false
+	}
+	return true
+}
 
-	// Set LLM_PROVIDER=openai by default if not already set
-	if os.Getenv("LLM_PROVIDER") == "" {
-		if err := os.Setenv("LLM_PROVIDER", "openai"); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to set LLM_PROVIDER environment variable: %v\n", err)
-		}
-	}
+// runServe runs the bot until it is stopped, reloading it when the config changes
+func runServe() error {
+	setDefaultLLMProvider()
 
 	// Setup logging with structured logger
 	logger := setupLogging()
 	logger.Info("Starting Slack MCP Client (debug=%v)", *debug)
+	if *configFile == "" {
+		logger.Info("No config file, configuring from environment variables only")
+	}
 
 	// Start metrics server
 	go func() {
 		http.Handle("/metrics", promhttp.Handler())
+		http.HandleFunc("/readyz", availability.ReadinessHandler)
+		http.HandleFunc("/debug/captures", middleware.CapturesHandler)
 		logger.Info("Starting metrics server on port %s", *metricsPort)
 		log.Fatal(http.ListenAndServe(fmt.Sprintf(":%s", *metricsPort), nil))
 	}()
 
 	// Run application with reload capability
-	if err := app.RunWithReload(logger, *configFile, runMainApplication); err != nil {
+	if err := app.RunWithReload(logger, *configFile, configOverlayFiles, runMainApplication); err != nil {
 		logger.Fatal("Application failed to start: %v", err)
+		return err
+	}
+	return nil
+}
+
+// setDefaultLLMProvider sets LLM_PROVIDER=openai if it is not already set
+func setDefaultLLMProvider() {
+	if os.Getenv("LLM_PROVIDER") == "" {
+		if err := os.Setenv("LLM_PROVIDER", "openai"); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to set LLM_PROVIDER environment variable: %v\n", err)
+		}
+	}
+}
+
+// handleConfigValidate checks the config file structure (keys, types, durations)
+// and then runs the runtime validation, reporting every problem found
+func handleConfigValidate() {
+	if problems := config.CheckConfigFile(*configFile, configOverlayFiles...); len(problems) > 0 {
+		fmt.Fprintf(os.Stderr, "Configuration validation failed with %d problem(s):\n", len(problems))
+		for _, problem := range problems {
+			fmt.Fprintf(os.Stderr, "  - %s\n", problem)
+		}
+		os.Exit(1)
+	}
+	fmt.Println("Configuration is valid")
+}
+
+// handleConfigSchema prints the JSON Schema of the configuration file
+func handleConfigSchema() {
+	schema, err := config.SchemaJSON()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to generate configuration schema: %v\n", err)
 		os.Exit(1)
 	}
+	fmt.Println(string(schema))
 }
 
 // runMainApplication contains the core application logic that can be reloaded
@@ -139,11 +271,16 @@ func runMainApplication(ctx context.Context, logger *logging.Logger) error {
 	// Load and prepare configuration
 	cfg := loadAndPrepareConfig(logger)
 
-	// Initialize MCP clients and discover tools
-	mcpClients, discoveredTools := initializeMCPClients(logger, cfg)
+	// Initialize MCP clients and the Slack client. In async mode servers are
+	// initialized in the background once the Slack client is running.
+	logger.Info("Starting Slack client...")
+	bot, err := app.NewBot(logger, cfg, nil)
+	if err != nil {
+		logger.Fatal("%v", err)
+	}
 
-	// Initialize and run Slack client
-	startSlackClient(ctx, logger, mcpClients, discoveredTools, cfg)
+	// Run the Slack client until shutdown
+	runSlackClient(ctx, logger, bot)
 
 	return nil
 }
@@ -188,7 +325,7 @@ func setupLogging() *logging.Logger {
 // loadAndPrepareConfig loads the configuration and applies any overrides
 func loadAndPrepareConfig(logger *logging.Logger) *config.Config {
 	// Load configuration
-	cfg, err := config.LoadConfig(*configFile, logger)
+	cfg, err := config.LoadConfig(*configFile, logger, configOverlayFiles...)
 	if err != nil {
 		logger.Fatal("Failed to load configuration: %v", err)
 	}
@@ -203,337 +340,30 @@ func loadAndPrepareConfig(logger *logging.Logger) *config.Config {
 		logger.Fatal("Error applying command-line flags: %v", err)
 	}
 
+	// Route outbound connections through the proxy and trust the configured CAs
+	// before any client is created
+	if err := app.ConfigureNetwork(logger, cfg); err != nil {
+		logger.Fatal("%v", err)
+	}
+
 	// Log configuration information
-	logger.Info("Configuration loaded. Slack Bot Token Present: %t, Slack App Token Present: %t",
-		cfg.Slack.BotToken != "", cfg.Slack.AppToken != "")
+	switch cfg.Frontend {
+	case config.FrontendDiscord:
+		logger.Info("Configuration loaded. Frontend: %s, Discord Bot Token Present: %t", cfg.Frontend, cfg.Discord.BotToken != "")
+	case config.FrontendTeams:
+		logger.Info("Configuration loaded. Frontend: %s, Teams App ID Present: %t", cfg.Frontend, cfg.Teams.AppID != "")
+	case config.FrontendMattermost:
+		logger.Info("Configuration loaded. Frontend: %s, Mattermost URL: %s, Bot Token Present: %t", cfg.Frontend, cfg.Mattermost.URL, cfg.Mattermost.BotToken != "")
+	default:
+		logger.Info("Configuration loaded. Slack Bot Token Present: %t, Slack App Token Present: %t",
+			cfg.Slack.BotToken != "", cfg.Slack.AppToken != "")
+	}
 	logLLMSettings(logger, cfg) // Log LLM settings
 	logger.Info("MCP Servers Configured (in file): %d", len(cfg.MCPServers))
 
 	return cfg
 }
 
-// initializeMCPClients initializes all MCP clients and discovers available tools
-// Use mcp.Client from the internal mcp package
-func initializeMCPClients(logger *logging.Logger, cfg *config.Config) (map[string]*mcp.Client, map[string]mcp.ToolInfo) {
-	// Initialize MCP Clients and Discover Tools Sequentially
-	mcpClients := make(map[string]*mcp.Client)
-	allDiscoveredTools := make(map[string]mcp.ToolInfo) // Map: toolName -> common.ToolInfo
-	failedServers := []string{}
-	initializedClientCount := 0
-
-	logger.Info("--- Starting MCP Client Initialization and Tool Discovery --- ")
-	for serverName, serverConf := range cfg.MCPServers {
-		processSingleMCPServer(
-			logger,
-			serverName,
-			serverConf,
-			mcpClients,
-			allDiscoveredTools,
-			&failedServers,
-			&initializedClientCount,
-		)
-	}
-
-	logger.Info("--- Finished MCP Client Initialization and Tool Discovery --- ")
-
-	// Log summary
-	// Use the imported function from the mcp package
-	logger.Info("Successfully initialized %d MCP clients: %v", initializedClientCount, mcp.GetClientMapKeys(mcpClients))
-	if len(failedServers) > 0 {
-		logger.Info("Failed to fully initialize/get tools from %d servers: %v", len(failedServers), failedServers)
-	}
-	logger.Info("Total unique discovered tools across all initialized servers: %d", len(allDiscoveredTools))
-
-	// Log a warning if no clients were initialized, but continue running
-	if initializedClientCount == 0 {
-		logger.Warn("No MCP clients could be successfully initialized. Application will run with LLM capabilities only.")
-	}
-
-	return mcpClients, allDiscoveredTools
-}
-
-// processSingleMCPServer processes a single MCP server configuration
-func processSingleMCPServer(
-	logger *logging.Logger,
-	serverName string,
-	serverConf config.MCPServerConfig,
-	mcpClients map[string]*mcp.Client, // Use mcp.Client
-	discoveredTools map[string]mcp.ToolInfo,
-	failedServers *[]string,
-	initializedClientCount *int,
-) {
-	logger.Info("Processing server: '%s'", serverName)
-
-	// Skip disabled servers
-	if serverConf.Disabled {
-		logger.Info("  Skipping disabled server '%s'", serverName)
-		return
-	}
-
-	// Create a component-specific logger for this server
-	serverLogger := logger.WithName(serverName)
-
-	// Create dedicated logger for this MCP client
-	mcpLoggerStd := log.New(os.Stdout, fmt.Sprintf("mcp-%s: ", strings.ToLower(serverName)), log.LstdFlags)
-
-	// Create client instance (assuming HTTP/SSE based on simplified config)
-	// Use mcp.NewClient from the internal package
-	mcpClient, err := createMCPClient(serverLogger, serverConf, serverName, mcpLoggerStd)
-	if err != nil {
-		*failedServers = append(*failedServers, serverName+fmt.Sprintf("(create: %s)", err))
-		return
-	}
-
-	serverLogger.Info("Successfully created MCP client instance")
-
-	// Only close the client if initialization fails
-	// We'll keep successful clients open for the lifetime of the application
-	closeClientOnFailure := func() {
-		if mcpClient != nil && mcpClients[serverName] == nil { // Only close if not stored in mcpClients
-			serverLogger.Info("Closing unused MCP client")
-			if err := mcpClient.Close(); err != nil {
-				serverLogger.ErrorKV("Failed to close MCP client", "error", err)
-			}
-		}
-	}
-	defer closeClientOnFailure()
-
-	// Initialize client
-	// Use mcp.Client from the internal mcp package (via mcpClient variable)
-	if err := initializeMCPClientInstance(serverLogger, mcpClient, serverConf.InitializeTimeoutSeconds); err != nil {
-		*failedServers = append(*failedServers, serverName+"(initialize failed)")
-		return
-	}
-
-	// Store successfully initialized client
-	serverLogger.Info("Adding MCP client for '%s' to active client map", serverName)
-	mcpClients[serverName] = mcpClient
-	*initializedClientCount++
-
-	// Special debugging for Kubernetes server
-	if serverName == "kubernetes" {
-		serverLogger.Info("Successfully initialized Kubernetes MCP client")
-	}
-
-	// Discover tools
-	// Use mcp.Client from the internal mcp package (via mcpClient variable)
-	serverLogger.Info("Discovering tools (timeout: 20s)...")
-	discoveryCtx, discoveryCancel := context.WithTimeout(context.Background(), 20*time.Second)
-	defer discoveryCancel()
-
-	listResult, toolsErr := mcpClient.GetAvailableTools(discoveryCtx)
-
-	if toolsErr != nil {
-		serverLogger.Warn("Failed to retrieve tools: %v", toolsErr)
-		*failedServers = append(*failedServers, serverName+"(tool discovery failed)")
-		return
-	}
-
-	if listResult == nil || len(listResult.Tools) == 0 {
-		serverLogger.Warn("Server initialized but returned 0 tools")
-		return
-	}
-
-	blockListMap := map[string]bool{}
-	allowListMap := map[string]bool{}
-	for _, toolName := range serverConf.Tools.BlockList {
-		blockListMap[toolName] = true
-	}
-	for _, toolName := range serverConf.Tools.AllowList {
-		allowListMap[toolName] = true
-	}
-
-	serverLogger.Info("Discovered %d tools", len(listResult.Tools))
-	for _, toolDef := range listResult.Tools {
-		if _, exists := blockListMap[toolDef.Name]; exists {
-			serverLogger.Debug("    Tool '%s' is in block list, skipping", toolDef.Name)
-			continue
-		}
-		if len(allowListMap) > 0 && !allowListMap[toolDef.Name] {
-			serverLogger.Debug("    Tool '%s' is not in allow list, skipping", toolDef.Name)
-			continue
-		}
-		toolName := fmt.Sprintf("%s_%s", serverName, toolDef.Name)
-		if _, exists := discoveredTools[toolName]; !exists {
-			var inputSchemaMap map[string]interface{}
-			// Marshal the ToolInputSchema struct to JSON bytes
-			schemaBytes, err := json.Marshal(toolDef.InputSchema)
-			if err != nil {
-				serverLogger.Error("    Failed to marshal input schema struct for tool '%s': %v", toolName, err)
-				inputSchemaMap = make(map[string]interface{}) // Use empty map on error
-			} else {
-				// Unmarshal the JSON bytes into the map
-				if err := json.Unmarshal(schemaBytes, &inputSchemaMap); err != nil {
-					serverLogger.Error("    Failed to unmarshal input schema JSON for tool '%s': %v", toolName, err)
-					inputSchemaMap = make(map[string]interface{}) // Use empty map on error
-				}
-			}
-
-			// Use common.ToolInfo
-			discoveredTools[toolName] = mcp.ToolInfo{
-				ServerName:      serverName,
-				ToolName:        toolName,
-				ToolDescription: toolDef.Description,
-				InputSchema:     inputSchemaMap,
-				Client:          mcpClient,
-			}
-			if *mcpDebug {
-				serverLogger.Debug("Stored tool: '%s' (Desc: %s)", toolName, toolDef.Description)
-				if *debug {
-					// Only log the full schema if debug mode is enabled
-					schemaJSON, _ := json.MarshalIndent(inputSchemaMap, "", "  ")
-					serverLogger.Debug("Tool schema: %s", string(schemaJSON))
-				}
-			}
-		} else {
-			existingInfo := discoveredTools[toolName]
-			serverLogger.Warn("Tool '%s' is available from multiple servers ('%s' and '%s'). Using the first one found ('%s').",
-				toolName, existingInfo.ServerName, serverName, existingInfo.ServerName)
-		}
-	}
-}
-
-// resolveHTTPHeaders resolves environment variables in HTTP headers
-func resolveHTTPHeaders(headers map[string]string, logger *logging.Logger) map[string]string {
-	resolvedHeaders := make(map[string]string)
-	for k, v := range headers {
-		// Handle variable substitution from environment
-		if strings.HasPrefix(v, "${") && strings.HasSuffix(v, "}") {
-			envVar := strings.TrimSuffix(strings.TrimPrefix(v, "${"), "}")
-			if envValue := os.Getenv(envVar); envValue != "" {
-				resolvedHeaders[k] = envValue
-				logger.Debug("Substituted environment variable %s for HTTP header", envVar)
-			} else {
-				logger.Warn("Environment variable %s not found for HTTP header substitution", envVar)
-				resolvedHeaders[k] = "" // Set empty value
-			}
-		} else {
-			resolvedHeaders[k] = v
-		}
-	}
-	return resolvedHeaders
-}
-
-// createMCPClient creates an MCP client based on configuration
-// Use mcp.Client and mcp.NewClient from the internal mcp package
-func createMCPClient(logger *logging.Logger, serverConf config.MCPServerConfig, serverName string, _ *log.Logger) (*mcp.Client, error) {
-	// Check if this is a URL-based (HTTP/SSE) configuration
-	if serverConf.URL != "" {
-		// Assume "sse" transport by default for HTTP-based connections
-		transport := serverConf.Transport
-		if transport == "" {
-			transport = "sse" // Default to SSE if not specified
-		}
-		logger.InfoKV("Creating MCP client", "transport", transport, "address", serverConf.URL)
-
-		// Resolve HTTPHeaders environment variables for URL-based configurations
-		resolvedHeaders := resolveHTTPHeaders(serverConf.HTTPHeaders, logger)
-
-		// Use the imported mcp.NewClient from internal/mcp/client.go with structured logger
-		mcpClient, createErr := mcp.NewClient(transport, serverConf.URL, serverName, nil, nil, resolvedHeaders, logger)
-		if createErr != nil {
-			logger.Error("Failed to create MCP client for URL %s: %v", serverConf.URL, createErr)
-			// Create a domain-specific error with additional context
-			domainErr := customErrors.WrapMCPError(createErr, "client_creation_failed",
-				fmt.Sprintf("Failed to create MCP client for URL '%s'", serverConf.URL))
-
-			// Add additional context data
-			domainErr = domainErr.WithData("transport", transport)
-			domainErr = domainErr.WithData("url", serverConf.URL)
-			return nil, domainErr
-		}
-		return mcpClient, nil
-	}
-
-	// Check if this is a command-based (stdio) configuration
-	if serverConf.Command != "" {
-		transport := "stdio"
-		logger.InfoKV("Creating MCP client", "transport", transport, "command", serverConf.Command, "args", serverConf.Args)
-
-		// Process environment variables
-		env := make(map[string]string)
-		for k, v := range serverConf.Env {
-			// Handle variable substitution from environment
-			if strings.HasPrefix(v, "${") && strings.HasSuffix(v, "}") {
-				envVar := strings.TrimSuffix(strings.TrimPrefix(v, "${"), "}")
-				if envValue := os.Getenv(envVar); envValue != "" {
-					env[k] = envValue
-					logger.Debug("Substituted environment variable %s for MCP server", envVar)
-				} else {
-					logger.Warn("Environment variable %s not found for substitution", envVar)
-					env[k] = "" // Set empty value
-				}
-			} else {
-				env[k] = v
-			}
-		}
-
-		// Resolve HTTPHeaders environment variables
-		resolvedHeaders := resolveHTTPHeaders(serverConf.HTTPHeaders, logger)
-
-		// Create the MCP client
-		logger.DebugKV("Executing command", "command", serverConf.Command, "args", serverConf.Args, "env", env, "headers", resolvedHeaders)
-		mcpClient, createErr := mcp.NewClient(transport, serverConf.Command, serverName, serverConf.Args, env, resolvedHeaders, logger)
-		if createErr != nil {
-			logger.Error("Failed to create MCP client: %v", createErr)
-			// Create a domain-specific error with additional context
-			domainErr := customErrors.WrapMCPError(createErr, "client_creation_failed",
-				fmt.Sprintf("Failed to create MCP client for command '%s'", serverConf.Command))
-
-			// Add additional context data
-			domainErr = domainErr.WithData("transport", transport)
-			domainErr = domainErr.WithData("command", serverConf.Command)
-			return nil, domainErr
-		}
-		return mcpClient, nil
-	}
-
-	// Neither URL nor Command specified
-	logger.Error("Skipping server: Neither 'url' nor 'command' specified in config")
-	return nil, customErrors.NewMCPError("invalid_config", "Missing both URL and command in server configuration")
-}
-
-// initializeMCPClientInstance initializes an MCP client with proper timeout
-// Use mcp.Client from the internal mcp package
-func initializeMCPClientInstance(logger *logging.Logger, client *mcp.Client, timeoutSeconds *int) error {
-	initTimeout := 5 // Default timeout
-	if timeoutSeconds != nil {
-		initTimeout = *timeoutSeconds
-	}
-	logger.Info("Attempting to initialize MCP client (timeout: %d)...", initTimeout)
-	// Create a context with timeout for initialization
-	initCtx, initCancel := context.WithTimeout(context.Background(), time.Duration(initTimeout)*time.Second)
-	defer initCancel()
-
-	// Try to initialize the client
-	initErr := client.Initialize(initCtx)
-	if initErr != nil {
-		// Log detailed error information
-		logger.Error("Failed to initialize MCP client: %v", initErr)
-
-		// Create a domain-specific error with additional context
-		domainErr := customErrors.WrapMCPError(initErr, "initialization_failed", "Failed to initialize MCP client")
-
-		// Check for specific error conditions and add more context
-		if strings.Contains(initErr.Error(), "context deadline exceeded") {
-			logger.Error("Initialization timed out. The MCP server may be slow to start or not responding.")
-			logger.Error("Try increasing the timeout or check if the NPM package is installed correctly.")
-			domainErr = domainErr.WithData("timeout_exceeded", true)
-			domainErr = domainErr.WithData("suggestion", "Increase timeout or check NPM package installation")
-		} else if strings.Contains(initErr.Error(), "file already closed") {
-			logger.Error("The MCP server process exited prematurely. Check command and arguments.")
-			domainErr = domainErr.WithData("process_exited", true)
-			domainErr = domainErr.WithData("suggestion", "Check command and arguments")
-		}
-
-		logger.Warn("Client will not be used for tool discovery or execution")
-		return domainErr
-	}
-
-	logger.Info("MCP client successfully initialized")
-	return nil
-}
-
 // applyCommandLineOverrides applies command-line flags directly to the loaded config
 func applyCommandLineOverrides(logger *logging.Logger, cfg *config.Config) error {
 	// Command-line overrides for LLM settings are not applied directly.
@@ -562,100 +392,10 @@ func logLLMSettings(logger *logging.Logger, cfg *config.Config) {
 	}
 }
 
-// startSlackClient starts the Slack client and handles shutdown
-// Use mcp.Client from the internal mcp package
-func startSlackClient(ctx context.Context, logger *logging.Logger, mcpClients map[string]*mcp.Client, discoveredTools map[string]mcp.ToolInfo, cfg *config.Config) {
-	logger.Info("Starting Slack client...")
-
-	// Initialize RAG client if enabled and add tools to discoveredTools
-	// The actual RAG client will be created in the Slack client where it gets added to the bridge
-	if cfg.RAG.Enabled {
-		logger.InfoKV("RAG enabled, preparing tools for bridge integration", "provider", cfg.RAG.Provider)
-
-		// Add RAG tools to discoveredTools for bridge integration
-		if discoveredTools == nil {
-			discoveredTools = make(map[string]mcp.ToolInfo)
-		}
-
-		// Manually add RAG tools since we'll create the client in the Slack package
-		discoveredTools["rag_search"] = mcp.ToolInfo{
-			ToolName:        "rag_search",
-			ToolDescription: "Search the RAG knowledge base for relevant information",
-			InputSchema: map[string]interface{}{
-				"type": "object",
-				"properties": map[string]interface{}{
-					"query": map[string]interface{}{
-						"type":        "string",
-						"description": "The search query to find relevant information",
-					},
-				},
-				"required": []string{"query"},
-			},
-			ServerName: "rag", // Internal RAG server identifier
-		}
-		discoveredTools["rag_ingest"] = mcp.ToolInfo{
-			ToolName:        "rag_ingest",
-			ToolDescription: "Ingest a file into the RAG knowledge base",
-			InputSchema: map[string]interface{}{
-				"type": "object",
-				"properties": map[string]interface{}{
-					"file_path": map[string]interface{}{
-						"type":        "string",
-						"description": "Path to the file to ingest",
-					},
-					"metadata": map[string]interface{}{
-						"type":        "object",
-						"description": "Optional metadata for the file",
-					},
-				},
-				"required": []string{"file_path"},
-			},
-			ServerName: "rag", // Internal RAG server identifier
-		}
-		discoveredTools["rag_stats"] = mcp.ToolInfo{
-			ToolName:        "rag_stats",
-			ToolDescription: "Get statistics about the RAG knowledge base",
-			InputSchema: map[string]interface{}{
-				"type":       "object",
-				"properties": map[string]interface{}{},
-			},
-			ServerName: "rag", // Internal RAG server identifier
-		}
-
-		logger.InfoKV("Added RAG tools to available tools", "tool_count", 3)
-	} else {
-		logger.Info("RAG integration disabled in configuration")
-	}
-
-	var err error
-
-	var userFrontend slackbot.UserFrontend
-	// Use the structured logger for the Slack client
-	if cfg.UseStdIOClient {
-		userFrontend = slackbot.NewStdioClient(logger)
-	} else {
-		userFrontend, err = slackbot.GetSlackClient(
-			cfg.Slack.BotToken,
-			cfg.Slack.AppToken,
-			logger,
-			cfg.Slack.ThinkingMessage,
-		)
-		if err != nil {
-			logger.Fatal("Failed to initialize Slack client: %v", err)
-		}
-	}
-
-	// Use the structured logger for the Slack client
-	client, err := slackbot.NewClient(
-		userFrontend,
-		logger,          // Pass the structured logger
-		mcpClients,      // Pass the map of initialized clients
-		discoveredTools, // Pass the map of tool information
-		cfg,             // Pass the whole config object
-	)
-	if err != nil {
-		logger.Fatal("Failed to initialize Slack client: %v", err)
-	}
+// runSlackClient runs the Slack client until a shutdown signal, the context is
+// cancelled or the client stops
+func runSlackClient(ctx context.Context, logger *logging.Logger, bot *app.Bot) {
+	bot.Start(ctx)
 
 	// Create a channel to signal when Slack client exits
 	slackDone := make(chan error, 1)
@@ -663,7 +403,7 @@ func startSlackClient(ctx context.Context, logger *logging.Logger, mcpClients ma
 	// Start listening for Slack events in a separate goroutine
 	go func() {
 		defer close(slackDone)
-		if err := client.Run(); err != nil {
+		if err := bot.Run(); err != nil {
 			logger.ErrorKV("Slack client error", "error", err)
 			slackDone <- err
 		}
@@ -692,7 +432,7 @@ func startSlackClient(ctx context.Context, logger *logging.Logger, mcpClients ma
 
 	// Try to close Slack client gracefully (if Close method is available)
 	logger.Info("Stopping Slack client...")
-	if closeErr := client.Close(); closeErr != nil {
+	if closeErr := bot.Close(); closeErr != nil {
 		logger.ErrorKV("Failed to close Slack client gracefully", "error", closeErr)
 	}
 
@@ -704,16 +444,7 @@ func startSlackClient(ctx context.Context, logger *logging.Logger, mcpClients ma
 		logger.Warn("Slack client stop timed out")
 	}
 
-	// Gracefully close all MCP clients
-	logger.Info("Closing all MCP clients...")
-	for name, client := range mcpClients {
-		if client != nil {
-			logger.InfoKV("Closing MCP client", "name", name)
-			if err := client.Close(); err != nil {
-				logger.ErrorKV("Failed to close MCP client", "name", name, "error", err)
-			}
-		}
-	}
+	bot.CloseMCPClients()
 }
 
 // handleRAGIngest processes PDF files from a directory and ingests them into the RAG database
@@ -734,12 +465,26 @@ func handleRAGIngest(path string) {
 		}
 	}()
 
-	ctx := context.Background()
+	if err := configureRAGChunking(ragClient, provider); err != nil {
+		fmt.Printf("Error configuring chunking: %v\n", err)
+		os.Exit(1)
+	}
+
+	ragClient.SetIngestWorkers(*ragIngestWorkers)
+
+	// Report each file of a directory as it is done
+	ctx := rag.WithIngestProgress(context.Background(), func(progress rag.IngestProgress) {
+		if progress.Err != nil {
+			fmt.Printf("[%d/%d] Failed: %s (%v)\n", progress.Done, progress.Total, progress.File, progress.Err)
+			return
+		}
+		fmt.Printf("[%d/%d] %s\n", progress.Done, progress.Total, progress.File)
+	})
 
 	// Use the RAG client to ingest
 	result, err := ragClient.CallTool(ctx, "rag_ingest", map[string]interface{}{
-		"file_path":    path,
-		"is_directory": true,
+		"file_path": path,
+		"namespace": *ragNamespace,
 	})
 	if err != nil {
 		fmt.Printf("Error during ingestion: %v\n", err)
@@ -757,6 +502,44 @@ func handleRAGIngest(path string) {
 	}
 }
 
+// handleRAGIngestURL fetches a web page, crawling same-site links when requested, and
+// ingests the pages into the RAG database
+func handleRAGIngestURL(pageURL string) {
+	provider := getRAGProvider()
+	fmt.Printf("Ingesting web pages from: %s (provider: %s, depth: %d)\n", pageURL, provider, *ragCrawlDepth)
+
+	// Create RAG configuration
+	config := getRAGConfig(provider)
+	ragClient, err := rag.NewClientWithProvider(provider, config)
+	if err != nil {
+		fmt.Printf("Error creating RAG client: %v\n", err)
+		os.Exit(1)
+	}
+	defer func() {
+		if err := ragClient.GetProvider().Close(); err != nil {
+			fmt.Printf("Warning: failed to close RAG client: %v\n", err)
+		}
+	}()
+	ragClient.SetWebOptions(rag.WebOptions{MaxDepth: *ragCrawlDepth, MaxPages: *ragMaxPages})
+	if err := configureRAGChunking(ragClient, provider); err != nil {
+		fmt.Printf("Error configuring chunking: %v\n", err)
+		os.Exit(1)
+	}
+
+	result, err := ragClient.CallTool(context.Background(), "rag_ingest_url", map[string]interface{}{
+		"url":       pageURL,
+		"depth":     *ragCrawlDepth,
+		"max_pages": *ragMaxPages,
+		"namespace": *ragNamespace,
+	})
+	if err != nil {
+		fmt.Printf("Error during ingestion: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Ingestion complete: %s\n", result)
+}
+
 // handleRAGSearch searches the RAG database and displays results
 func handleRAGSearch(query string) {
 	provider := getRAGProvider()
@@ -775,7 +558,7 @@ func handleRAGSearch(query string) {
 		}
 	}()
 
-	ctx := context.Background()
+	ctx := rag.WithNamespace(context.Background(), *ragNamespace)
 
 	// Use the RAG client to search
 	result, err := ragClient.CallTool(ctx, "rag_search", map[string]interface{}{
@@ -829,11 +612,6 @@ func handleRAGList() {
 	provider := getRAGProvider()
 	fmt.Printf("Listing files in vector store (provider: %s)\n", provider)
 
-	if provider == "simple" {
-		fmt.Printf("File listing not supported for simple provider\n")
-		return
-	}
-
 	// Create RAG configuration
 	config := getRAGConfig(provider)
 	ragClient, err := rag.NewClientWithProvider(provider, config)
@@ -858,8 +636,15 @@ func handleRAGList() {
 
 	fmt.Printf("Found %d files:\n", len(files))
 	for _, file := range files {
-		fmt.Printf("  - ID: %s, Name: %s, Size: %d bytes, Status: %s\n",
+		if *ragNamespace != "" && file.Metadata[rag.NamespaceMetadataKey] != *ragNamespace {
+			continue
+		}
+		fmt.Printf("  - ID: %s, Name: %s, Size: %d bytes, Status: %s",
 			file.ID, file.Name, file.Size, file.Status)
+		if namespace := file.Metadata[rag.NamespaceMetadataKey]; namespace != "" {
+			fmt.Printf(", Namespace: %s", namespace)
+		}
+		fmt.Println()
 	}
 }
 
@@ -868,11 +653,6 @@ func handleRAGDelete(fileIDs string) {
 	provider := getRAGProvider()
 	fmt.Printf("Deleting files from vector store (provider: %s)\n", provider)
 
-	if provider == "simple" {
-		fmt.Printf("File deletion not supported for simple provider\n")
-		return
-	}
-
 	ids := strings.Split(fileIDs, ",")
 	for i, id := range ids {
 		ids[i] = strings.TrimSpace(id)
@@ -931,6 +711,140 @@ func handleRAGStats() {
 	fmt.Printf("%s\n", result)
 }
 
+// handleRAGDedupe removes documents ingested more than once and repeated chunks
+func handleRAGDedupe() {
+	provider := getRAGProvider()
+	fmt.Printf("Deduplicating RAG database (provider: %s)\n", provider)
+
+	// Create RAG configuration
+	config := getRAGConfig(provider)
+	ragClient, err := rag.NewClientWithProvider(provider, config)
+	if err != nil {
+		fmt.Printf("Error creating RAG client: %v\n", err)
+		os.Exit(1)
+	}
+	defer func() {
+		if err := ragClient.GetProvider().Close(); err != nil {
+			fmt.Printf("Warning: failed to close RAG client: %v\n", err)
+		}
+	}()
+
+	result, err := ragClient.Dedupe(context.Background())
+	if err != nil {
+		fmt.Printf("Error during deduplication: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Removed %d duplicate document(s) and %d duplicate chunk(s)\n", result.Documents, result.Chunks)
+}
+
+// handleRAGGC removes the files failed ingests left in the OpenAI account
+func handleRAGGC() {
+	provider := getRAGProvider()
+	fmt.Printf("Collecting RAG garbage (provider: %s, min age: %s, dry run: %t)\n", provider, *ragGCMinAge, *ragGCDryRun)
+
+	// Create RAG configuration
+	config := getRAGConfig(provider)
+	ragClient, err := rag.NewClientWithProvider(provider, config)
+	if err != nil {
+		fmt.Printf("Error creating RAG client: %v\n", err)
+		os.Exit(1)
+	}
+	defer func() {
+		if err := ragClient.GetProvider().Close(); err != nil {
+			fmt.Printf("Warning: failed to close RAG client: %v\n", err)
+		}
+	}()
+
+	result, err := ragClient.CollectGarbage(context.Background(), rag.GCOptions{MinAge: *ragGCMinAge, DryRun: *ragGCDryRun})
+	if err != nil {
+		fmt.Printf("Error during garbage collection: %v\n", err)
+		os.Exit(1)
+	}
+	verb := "Removed"
+	if *ragGCDryRun {
+		verb = "Would remove"
+	}
+	fmt.Printf("%s %d failed vector store file(s) and %d orphaned file(s) (%d bytes)\n", verb, result.FailedEntries, result.OrphanedFiles, result.FreedBytes)
+}
+
+// handleRAGEval scores retrieval against an eval set of questions and expected sources
+func handleRAGEval(path string) {
+	evalSet, err := rag.LoadEvalSet(path)
+	if err != nil {
+		fmt.Printf("Error loading eval set: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Search the knowledge base as the bot does, with its vector search, recency
+	// decay and reranking; the flags only select another knowledge base
+	logger := setupQuietLogging()
+	cfg, err := config.ReadConfig(*configFile, logger, configOverlayFiles...)
+	if err != nil {
+		fmt.Printf("Error loading configuration: %v\n", err)
+		os.Exit(1)
+	}
+	if explicitFlags["provider"] || explicitFlags["rag-provider"] {
+		cfg.RAG.Provider = *ragProvider
+	}
+	if explicitFlags["db"] || explicitFlags["rag-db"] {
+		if cfg.RAG.Providers == nil {
+			cfg.RAG.Providers = make(map[string]config.RAGProviderConfig)
+		}
+		settings := cfg.RAG.Providers[cfg.RAG.Provider]
+		settings.DatabasePath = *ragDatabase
+		cfg.RAG.Providers[cfg.RAG.Provider] = settings
+	}
+	fmt.Printf("Evaluating %d question(s) from %s (provider: %s)\n\n", len(evalSet.Cases), path, cfg.RAG.Provider)
+
+	registry, err := llm.NewProviderRegistry(cfg, logger)
+	if err != nil {
+		fmt.Printf("Error initializing LLM providers: %v\n", err)
+		os.Exit(1)
+	}
+	ragClient, err := slackbot.NewRAGClient(cfg)
+	if err != nil {
+		fmt.Printf("Error creating RAG client: %v\n", err)
+		os.Exit(1)
+	}
+	if err := slackbot.ConfigureRAGRetrieval(ragClient, cfg, registry, logger); err != nil {
+		fmt.Printf("Error configuring RAG retrieval: %v\n", err)
+		os.Exit(1)
+	}
+	defer func() {
+		if err := ragClient.GetProvider().Close(); err != nil {
+			fmt.Printf("Warning: failed to close RAG client: %v\n", err)
+		}
+	}()
+
+	report := ragClient.Evaluate(context.Background(), evalSet, *ragEvalK)
+	fmt.Print(report.String())
+}
+
+// configureRAGChunking applies rag.chunking of the configuration, as the bot does,
+// with the chunking flags given overriding it. The semantic strategy embeds
+// sentences with the embedder of rag.search.
+func configureRAGChunking(ragClient *rag.Client, provider string) error {
+	cfg, err := config.ReadConfig(*configFile, setupQuietLogging(), configOverlayFiles...)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	chunking := &cfg.RAG.Chunking
+	if *ragChunkStrategy != "" {
+		chunking.Strategy = *ragChunkStrategy
+	}
+	if *ragChunkSize != 0 {
+		chunking.Size = *ragChunkSize
+	}
+	if *ragChunkOverlap != 0 {
+		chunking.Overlap = *ragChunkOverlap
+	}
+	if *ragChunkUnit != "" {
+		chunking.Unit = *ragChunkUnit
+	}
+	cfg.RAG.Provider = provider
+	return slackbot.ConfigureRAGChunking(ragClient, cfg)
+}
+
 // getRAGProvider determines the RAG provider to use
 func getRAGProvider() string {
 	if *ragProvider != "" {
@@ -968,23 +882,42 @@ func getRAGConfig(provider string) map[string]interface{} {
 	return config
 }
 
-// handleConfigMigration handles the configuration migration from legacy format
-func handleConfigMigration(inputFile string) {
-	fmt.Printf("Migrating configuration from legacy format...\n")
-
-	// Determine input and output files
+// handleConfigMigration converts a legacy configuration file to the current format
+func handleConfigMigration(inputFile, outputFile string, dryRun bool) {
+	// Determine input file
 	if inputFile == "" {
 		inputFile = "mcp-servers.json"
 	}
-	outputFile := "config.json"
 
-	// Check if input file exists
-	if _, err := os.Stat(inputFile); os.IsNotExist(err) {
-		fmt.Fprintf(os.Stderr, "Error: Input file '%s' not found\n", inputFile)
-		fmt.Fprintf(os.Stderr, "Usage: slack-mcp-client --migrate-config [--config input-file]\n")
+	// Read the legacy configuration
+	data, err := os.ReadFile(inputFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Cannot read input file '%s': %v\n", inputFile, err)
+		fmt.Fprintf(os.Stderr, "Usage: slack-mcp-client config migrate [--config input-file] [--output output-file] [--dry-run]\n")
 		os.Exit(1)
 	}
 
+	migration, err := config.MigrateLegacyConfig(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Migration failed: %v\n", err)
+		os.Exit(1)
+	}
+	migrated, err := migration.JSON()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Migration failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Dry run: show the result on stdout and the notes on stderr, so the output can be redirected
+	if dryRun {
+		fmt.Fprintf(os.Stderr, "Detected legacy %s format\n", migration.Format)
+		for _, note := range migration.Notes {
+			fmt.Fprintln(os.Stderr, note)
+		}
+		fmt.Println(string(migrated))
+		return
+	}
+
 	// Check if output file already exists
 	if _, err := os.Stat(outputFile); err == nil {
 		fmt.Fprintf(os.Stderr, "Error: Output file '%s' already exists\n", outputFile)
@@ -992,8 +925,11 @@ func handleConfigMigration(inputFile string) {
 		os.Exit(1)
 	}
 
-	// Execute the migration script
-	if err := executeMigrationScript(inputFile, outputFile); err != nil {
+	fmt.Printf("Migrating configuration from legacy %s format...\n", migration.Format)
+	for _, note := range migration.Notes {
+		fmt.Printf("  %s\n", note)
+	}
+	if err := os.WriteFile(outputFile, append(migrated, '\n'), 0o600); err != nil {
 		fmt.Fprintf(os.Stderr, "Migration failed: %v\n", err)
 		os.Exit(1)
 	}
@@ -1003,35 +939,7 @@ func handleConfigMigration(inputFile string) {
 	fmt.Printf("  Output: %s\n", outputFile)
 	fmt.Printf("\nNext steps:\n")
 	fmt.Printf("1. Review the generated %s file\n", outputFile)
-	fmt.Printf("2. Test with: slack-mcp-client --config %s --config-validate\n", outputFile)
-	fmt.Printf("3. Update your deployment scripts to use --config %s\n", outputFile)
-}
-
-// executeMigrationScript runs the migration script to convert legacy config
-func executeMigrationScript(inputFile, outputFile string) error {
-	// Look for the migration script in common locations
-	scriptPaths := []string{
-		"scripts/migrate-config.sh",
-		"./migrate-config.sh",
-		"/usr/local/bin/migrate-config.sh",
-	}
-
-	var scriptPath string
-	for _, path := range scriptPaths {
-		if _, err := os.Stat(path); err == nil {
-			scriptPath = path
-			break
-		}
-	}
-
-	if scriptPath == "" {
-		return fmt.Errorf("migration script not found in any of the expected locations: %v", scriptPaths)
-	}
-
-	// Execute the script with input and output files
-	cmd := exec.Command("bash", scriptPath, inputFile, outputFile)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	return cmd.Run()
+	fmt.Printf("2. Set SLACK_BOT_TOKEN, SLACK_APP_TOKEN and your LLM provider's API key\n")
+	fmt.Printf("3. Test with: slack-mcp-client --config %s config validate\n", outputFile)
+	fmt.Printf("4. Update your deployment scripts to use --config %s\n", outputFile)
 }
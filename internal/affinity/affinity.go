@@ -0,0 +1,146 @@
+// Package affinity remembers the LLM provider and model that answered each
+// thread, so later turns of the thread are answered by the same model even after
+// a failover or a configuration change. Switching models mid-thread changes the
+// answer style and can break tool call formats the earlier turns relied on.
+package affinity
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/filestore"
+)
+
+// Model is the provider and model a thread is answered with
+type Model struct {
+	Provider string    `json:"provider"`
+	Model    string    `json:"model,omitempty"`
+	Override bool      `json:"override,omitempty"` // Chosen by a user for the thread rather than recorded
+	Used     time.Time `json:"used"`               // Last time the thread was answered or the model was set
+}
+
+// Key identifies a thread in the store
+func Key(channelID, threadTS string) string {
+	return channelID + ":" + threadTS
+}
+
+// Store keeps the model of each thread, by conversation key. It keeps at most
+// maxThreads threads, dropping the least recently used first. With a path, it is
+// persisted to a JSON file so threads keep their model across restarts and
+// configuration reloads.
+type Store struct {
+	path       string
+	maxThreads int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	threads map[string]Model
+}
+
+// NewStore opens (or creates) a store that keeps at most maxThreads threads. An
+// empty path keeps the models in memory only.
+func NewStore(path string, maxThreads int) (*Store, error) {
+	s := &Store{path: path, maxThreads: maxThreads, now: time.Now, threads: make(map[string]Model)}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Get returns the model of a thread
+func (s *Store) Get(key string) (Model, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	model, ok := s.threads[key]
+	return model, ok
+}
+
+// Record remembers the model that answered a thread. A model a user chose for
+// the thread stays an override while it is the one answering.
+func (s *Store) Record(key, provider, model string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, ok := s.threads[key]
+	override := ok && existing.Override && existing.Provider == provider && existing.Model == model
+	if ok && existing.Provider == provider && existing.Model == model && s.now().Sub(existing.Used) < time.Minute {
+		return nil // Unchanged and recently saved
+	}
+	return s.set(key, Model{Provider: provider, Model: model, Override: override, Used: s.now()})
+}
+
+// Override sets the model of a thread chosen by a user
+func (s *Store) Override(key, provider, model string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.set(key, Model{Provider: provider, Model: model, Override: true, Used: s.now()})
+}
+
+// Delete forgets the model of a thread, so its next turn is answered by the
+// configured one, and reports whether it was known
+func (s *Store) Delete(key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.threads[key]; !ok {
+		return false, nil
+	}
+	delete(s.threads, key)
+	return true, s.save()
+}
+
+// Len returns the number of threads whose model is remembered
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.threads)
+}
+
+// set stores the model of a thread and drops the least recently used threads over
+// the limit; callers must hold mu
+func (s *Store) set(key string, model Model) error {
+	s.threads[key] = model
+	for s.maxThreads > 0 && len(s.threads) > s.maxThreads {
+		oldest := ""
+		for k, m := range s.threads {
+			if oldest == "" || m.Used.Before(s.threads[oldest].Used) {
+				oldest = k
+			}
+		}
+		delete(s.threads, oldest)
+	}
+	return s.save()
+}
+
+// load reads the store file if it exists
+func (s *Store) load() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read thread model store: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.threads); err != nil {
+		return fmt.Errorf("failed to parse thread model store: %w", err)
+	}
+	if s.threads == nil {
+		s.threads = make(map[string]Model)
+	}
+	return nil
+}
+
+// save atomically writes the store file; callers must hold mu
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+	if err := filestore.WriteJSON(s.path, s.threads); err != nil {
+		return fmt.Errorf("failed to save thread model store: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package affinity
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreRemembersThreadModels(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "threads.json")
+	store, err := NewStore(path, 2)
+	require.NoError(t, err)
+	now := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+
+	require.NoError(t, store.Record(Key("C1", "100.1"), "openai", "gpt-4o"))
+	require.NoError(t, store.Override(Key("C1", "200.1"), "anthropic", "claude-sonnet-4-5"))
+
+	// A user's choice stays an override while it answers, and is replaced by another model
+	require.NoError(t, store.Record(Key("C1", "200.1"), "anthropic", "claude-sonnet-4-5"))
+	model, ok := store.Get(Key("C1", "200.1"))
+	require.True(t, ok)
+	assert.True(t, model.Override)
+
+	// Threads survive a restart
+	reopened, err := NewStore(path, 2)
+	require.NoError(t, err)
+	model, ok = reopened.Get(Key("C1", "100.1"))
+	require.True(t, ok)
+	assert.Equal(t, Model{Provider: "openai", Model: "gpt-4o", Used: now}, model)
+
+	// The least recently used thread is dropped over the limit
+	now = now.Add(time.Hour)
+	require.NoError(t, store.Record(Key("C1", "200.1"), "ollama", "llama3"))
+	model, _ = store.Get(Key("C1", "200.1"))
+	assert.False(t, model.Override)
+	now = now.Add(time.Hour)
+	require.NoError(t, store.Record(Key("C2", "300.1"), "openai", "gpt-4o"))
+	assert.Equal(t, 2, store.Len())
+	_, ok = store.Get(Key("C1", "100.1"))
+	assert.False(t, ok)
+
+	deleted, err := store.Delete(Key("C2", "300.1"))
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	deleted, err = store.Delete(Key("C2", "300.1"))
+	require.NoError(t, err)
+	assert.False(t, deleted)
+}
@@ -0,0 +1,120 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/httptools"
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+	slackbot "github.com/tuannvm/slack-mcp-client/internal/slack"
+)
+
+// Bot is the client with its MCP servers, started the same way by the binary and
+// by services embedding it
+type Bot struct {
+	*slackbot.Client
+
+	logger *logging.Logger
+	cfg    *config.Config
+}
+
+// NewBot builds the client: it connects the MCP servers, unless mcpStartup is
+// async, imports the OpenAPI tools, creates the frontend selected by the config
+// when none is given, and supervises the servers. Call Start before running it.
+func NewBot(logger *logging.Logger, cfg *config.Config, frontend slackbot.UserFrontend, opts ...slackbot.ClientOption) (*Bot, error) {
+	mcpClients := make(map[string]*mcp.Client)
+	discoveredTools := make(map[string]mcp.ToolInfo)
+	var warnings []string
+	if cfg.MCPStartup.IsAsync() {
+		logger.Info("MCP servers will be initialized in the background")
+	} else {
+		var err error
+		if mcpClients, discoveredTools, warnings, err = InitializeMCPServers(logger, cfg); err != nil {
+			return nil, err
+		}
+	}
+	warnings = ImportOpenAPITools(logger, cfg, warnings)
+
+	if frontend == nil {
+		var err error
+		if frontend, err = NewFrontend(logger, cfg); err != nil {
+			closeMCPClients(logger, mcpClients)
+			return nil, err
+		}
+	}
+	client, err := slackbot.NewClient(frontend, logger, mcpClients, discoveredTools, cfg, opts...)
+	if err != nil {
+		closeMCPClients(logger, mcpClients)
+		return nil, fmt.Errorf("failed to initialize Slack client: %w", err)
+	}
+
+	client.AddStatusWarnings(warnings...)
+	for serverName, mcpClient := range mcpClients {
+		SuperviseMCPServer(logger, client, serverName, cfg.MCPServers[serverName], cfg.ToolCollision, mcpClient)
+	}
+	return &Bot{Client: client, logger: logger, cfg: cfg}, nil
+}
+
+// Start connects the servers of an async mcpStartup in the background, and posts
+// the startup self-test once every server is ready or failed
+func (b *Bot) Start(ctx context.Context) {
+	var mcpReady <-chan struct{}
+	if b.cfg.MCPStartup.IsAsync() {
+		mcpReady = StartMCPServersAsync(ctx, b.logger, b.cfg, b.Client)
+	}
+	go func() {
+		if mcpReady != nil {
+			select {
+			case <-mcpReady:
+			case <-ctx.Done():
+				return
+			}
+		}
+		b.RunSelfTest(ctx)
+	}()
+}
+
+// CloseMCPClients closes the clients of the MCP servers the bot uses
+func (b *Bot) CloseMCPClients() {
+	CloseMCPClients(b.logger, b.Client)
+}
+
+// CloseMCPClients gracefully closes all MCP clients of a client, including the
+// servers registered after startup
+func CloseMCPClients(logger *logging.Logger, client *slackbot.Client) {
+	logger.Info("Closing all MCP clients...")
+	closeMCPClients(logger, client.MCPClients())
+}
+
+// ImportOpenAPITools imports the allowlisted operations of OpenAPI documents as
+// HTTP tools, and returns the startup warnings with the documents that failed
+func ImportOpenAPITools(logger *logging.Logger, cfg *config.Config, startupWarnings []string) []string {
+	for apiName, api := range cfg.OpenAPI {
+		importCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		tools, skipped, err := httptools.ImportOpenAPI(importCtx, api)
+		cancel()
+		if err != nil {
+			logger.ErrorKV("Failed to import OpenAPI document, skipping it", "api", apiName, "error", err)
+			startupWarnings = append(startupWarnings, fmt.Sprintf("OpenAPI document '%s' could not be imported: %v", apiName, err))
+			continue
+		}
+		for _, operation := range skipped {
+			logger.WarnKV("Skipped OpenAPI operation", "api", apiName, "operation", operation)
+		}
+		if cfg.HTTPTools == nil {
+			cfg.HTTPTools = make(map[string]config.HTTPToolConfig)
+		}
+		for name, tool := range tools {
+			if _, exists := cfg.HTTPTools[name]; exists {
+				logger.WarnKV("OpenAPI tool name is already used by an HTTP tool, skipping it", "api", apiName, "tool", name)
+				continue
+			}
+			cfg.HTTPTools[name] = tool
+		}
+		logger.InfoKV("Imported OpenAPI operations", "api", apiName, "tool_count", len(tools))
+	}
+	return startupWarnings
+}
@@ -0,0 +1,70 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/network"
+	slackbot "github.com/tuannvm/slack-mcp-client/internal/slack"
+)
+
+// ConfigureNetwork routes outbound connections through the configured proxy and
+// trusts the configured CAs. Call it before any client is created.
+func ConfigureNetwork(logger *logging.Logger, cfg *config.Config) error {
+	if err := network.Configure(network.Options{
+		ProxyURL:           cfg.Network.ProxyURL,
+		NoProxy:            cfg.Network.NoProxy,
+		CABundle:           cfg.Network.CABundle,
+		InsecureSkipVerify: cfg.Network.InsecureSkipVerify,
+	}); err != nil {
+		return fmt.Errorf("failed to configure outbound connections: %w", err)
+	}
+	if cfg.Network.ProxyURL != "" {
+		logger.Info("Routing outbound connections through the configured proxy (%d noProxy entries)", len(cfg.Network.NoProxy))
+	}
+	if len(cfg.Network.InsecureSkipVerify) > 0 {
+		logger.Warn("TLS certificate verification is disabled for: %s", strings.Join(cfg.Network.InsecureSkipVerify, ", "))
+	}
+	return nil
+}
+
+// NewFrontend creates the frontend selected by the config: the terminal with
+// UseStdIOClient, Discord, Microsoft Teams, Mattermost, the Events API in http mode, or Socket Mode
+func NewFrontend(logger *logging.Logger, cfg *config.Config) (slackbot.UserFrontend, error) {
+	switch {
+	case cfg.UseStdIOClient:
+		return slackbot.NewStdioClient(logger), nil
+	case cfg.Frontend == config.FrontendDiscord:
+		frontend, err := slackbot.NewDiscordClient(cfg.Discord, logger, cfg.Slack.ThinkingMessage, cfg.Slack.Outbound)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Discord client: %w", err)
+		}
+		return frontend, nil
+	case cfg.Frontend == config.FrontendTeams:
+		frontend, err := slackbot.NewTeamsClient(cfg.Teams, logger, cfg.Slack.ThinkingMessage, cfg.Slack.Outbound)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Microsoft Teams client: %w", err)
+		}
+		return frontend, nil
+	case cfg.Frontend == config.FrontendMattermost:
+		frontend, err := slackbot.NewMattermostClient(cfg.Mattermost, logger, cfg.Slack.ThinkingMessage, cfg.Slack.Outbound)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Mattermost client: %w", err)
+		}
+		return frontend, nil
+	case cfg.Slack.Mode == config.SlackModeHTTP:
+		frontend, err := slackbot.GetSlackHTTPClient(cfg.Slack, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Slack HTTP events client: %w", err)
+		}
+		return frontend, nil
+	default:
+		frontend, err := slackbot.GetSlackClient(cfg.Slack.BotToken, cfg.Slack.AppToken, logger, cfg.Slack.ThinkingMessage, cfg.Slack.Outbound)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Slack client: %w", err)
+		}
+		return frontend, nil
+	}
+}
@@ -22,13 +22,14 @@ type ReloadTrigger struct {
 	Signal os.Signal
 }
 
-// RunWithReload wraps the main application function with reload capability
-func RunWithReload(logger *logging.Logger, configFile string, appFunc func(context.Context, *logging.Logger) error) error {
+// RunWithReload wraps the main application function with reload capability.
+// overlays are the config overlays merged into configFile on each load.
+func RunWithReload(logger *logging.Logger, configFile string, overlays []string, appFunc func(context.Context, *logging.Logger) error) error {
 	for {
 		reloadStartTime := time.Now()
 
 		// Load and validate configuration
-		reloadInterval, shouldReload, err := loadAndValidateReloadConfig(configFile, logger)
+		reloadInterval, shouldReload, err := loadAndValidateReloadConfig(configFile, overlays, logger)
 		if err != nil || !shouldReload {
 			// Either config loading failed or reload is disabled - run normally
 			return appFunc(context.Background(), logger)
@@ -140,9 +141,9 @@ func validateReloadInterval(interval string) error {
 
 // loadAndValidateReloadConfig loads configuration and validates reload settings
 // Returns: (reloadInterval, shouldReload, error)
-func loadAndValidateReloadConfig(configFile string, logger *logging.Logger) (time.Duration, bool, error) {
+func loadAndValidateReloadConfig(configFile string, overlays []string, logger *logging.Logger) (time.Duration, bool, error) {
 	// Load configuration
-	cfg, err := config.LoadConfig(configFile, logger)
+	cfg, err := config.LoadConfig(configFile, logger, overlays...)
 	if err != nil {
 		logger.ErrorKV("Failed to load config for reload check", "error", err)
 		return 0, false, err
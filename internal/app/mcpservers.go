@@ -0,0 +1,590 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	mcpgo "github.com/mark3labs/mcp-go/mcp"
+
+	customErrors "github.com/tuannvm/slack-mcp-client/internal/common/errors"
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+	"github.com/tuannvm/slack-mcp-client/internal/network"
+	slackbot "github.com/tuannvm/slack-mcp-client/internal/slack"
+)
+
+// InitializeMCPServers initializes all MCP clients and discovers available tools.
+// Tool name collisions are resolved with the configured strategy and returned as
+// warnings; a collision the strategy rejects is an error.
+func InitializeMCPServers(logger *logging.Logger, cfg *config.Config) (map[string]*mcp.Client, map[string]mcp.ToolInfo, []string, error) {
+	// Initialize MCP Clients and Discover Tools Sequentially
+	mcpClients := make(map[string]*mcp.Client)
+	allDiscoveredTools := make(map[string]mcp.ToolInfo) // Map: toolName -> common.ToolInfo
+	failedServers := []string{}
+	warnings := []string{}
+	initializedClientCount := 0
+
+	logger.Info("--- Starting MCP Client Initialization and Tool Discovery --- ")
+	remote := mcpRemoteOptions(cfg)
+	for serverName, serverConf := range cfg.MCPServers {
+		serverTools := make(map[string]mcp.ToolInfo)
+		processSingleMCPServer(
+			logger,
+			serverName,
+			serverConf,
+			cfg.ToolCollision,
+			remote,
+			mcpClients,
+			serverTools,
+			&failedServers,
+			&initializedClientCount,
+		)
+
+		collisions, err := mcp.MergeTools(cfg.ToolCollision, allDiscoveredTools, serverTools)
+		if err != nil {
+			closeMCPClients(logger, mcpClients)
+			return nil, nil, nil, fmt.Errorf("failed to register tools from server '%s': %w", serverName, err)
+		}
+		for _, collision := range collisions {
+			logger.Warn("%s", collision.String())
+			warnings = append(warnings, collision.String())
+		}
+	}
+
+	logger.Info("--- Finished MCP Client Initialization and Tool Discovery --- ")
+
+	// Log summary
+	// Use the imported function from the mcp package
+	logger.Info("Successfully initialized %d MCP clients: %v", initializedClientCount, mcp.GetClientMapKeys(mcpClients))
+	if len(failedServers) > 0 {
+		logger.Info("Failed to fully initialize/get tools from %d servers: %v", len(failedServers), failedServers)
+	}
+	logger.Info("Total unique discovered tools across all initialized servers: %d", len(allDiscoveredTools))
+
+	// Log a warning if no clients were initialized, but continue running
+	if initializedClientCount == 0 {
+		logger.Warn("No MCP clients could be successfully initialized. Application will run with LLM capabilities only.")
+	}
+
+	return mcpClients, allDiscoveredTools, warnings, nil
+}
+
+// closeMCPClients closes the clients of the MCP servers
+func closeMCPClients(logger *logging.Logger, mcpClients map[string]*mcp.Client) {
+	for name, mcpClient := range mcpClients {
+		if mcpClient == nil {
+			continue
+		}
+		logger.InfoKV("Closing MCP client", "name", name)
+		if err := mcpClient.Close(); err != nil {
+			logger.ErrorKV("Failed to close MCP client", "name", name, "error", err)
+		}
+	}
+}
+
+// StartMCPServersAsync initializes each MCP server in its own goroutine and
+// registers it with the Slack client as soon as it is ready, so a slow server does
+// not delay connecting to Slack. The returned channel is closed once every server
+// is ready or failed.
+func StartMCPServersAsync(ctx context.Context, logger *logging.Logger, cfg *config.Config, slackClient *slackbot.Client) <-chan struct{} {
+	logger.Info("--- Starting background MCP Client Initialization and Tool Discovery --- ")
+	remote := mcpRemoteOptions(cfg)
+	var wg sync.WaitGroup
+	for serverName, serverConf := range cfg.MCPServers {
+		if serverConf.Disabled {
+			logger.Info("  Skipping disabled server '%s'", serverName)
+			continue
+		}
+		wg.Add(1)
+		go func(serverName string, serverConf config.MCPServerConfig) {
+			defer wg.Done()
+			serverClients := make(map[string]*mcp.Client)
+			serverTools := make(map[string]mcp.ToolInfo)
+			failedServers := []string{}
+			initializedClientCount := 0
+			processSingleMCPServer(logger, serverName, serverConf, cfg.ToolCollision, remote, serverClients, serverTools, &failedServers, &initializedClientCount)
+
+			mcpClient, ok := serverClients[serverName]
+			if ok && ctx.Err() == nil {
+				if err := slackClient.RegisterMCPServer(serverName, mcpClient, serverTools); err != nil {
+					failedServers = append(failedServers, err.Error())
+				} else {
+					SuperviseMCPServer(logger, slackClient, serverName, serverConf, cfg.ToolCollision, mcpClient)
+					mcpClient = nil
+				}
+			}
+			if mcpClient != nil {
+				// Not registered (shutting down or rejected); nobody else will close it
+				if err := mcpClient.Close(); err != nil {
+					logger.ErrorKV("Failed to close MCP client", "name", serverName, "error", err)
+				}
+			}
+			if len(failedServers) > 0 {
+				slackClient.NotifyMCPServerFailed(serverName, strings.Join(failedServers, ", "))
+			}
+		}(serverName, serverConf)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	return done
+}
+
+// SuperviseMCPServer announces the crashes of a supervised stdio server and, once
+// its process is restarted, discovers its tools again and re-registers them
+func SuperviseMCPServer(logger *logging.Logger, slackClient *slackbot.Client, serverName string, serverConf config.MCPServerConfig,
+	collisionCfg config.ToolCollisionConfig, mcpClient *mcp.Client) {
+	serverLogger := logger.WithName(serverName)
+	mcpClient.OnServerEvent(func(event mcp.ServerEvent) {
+		switch event.Type {
+		case mcp.ServerEventCrashed:
+			slackClient.NotifyMCPServerCrashed(serverName, event.Err, true)
+		case mcp.ServerEventGaveUp:
+			slackClient.NotifyMCPServerCrashed(serverName, event.Err, false)
+		case mcp.ServerEventRestarted:
+			discoveryCtx, discoveryCancel := context.WithTimeout(context.Background(), 20*time.Second)
+			defer discoveryCancel()
+			listResult, err := mcpClient.GetAvailableTools(discoveryCtx)
+			if err != nil {
+				serverLogger.Warn("Failed to retrieve tools after restart: %v", err)
+				return
+			}
+			tools := make(map[string]mcp.ToolInfo)
+			collectServerTools(serverLogger, serverName, serverConf, collisionCfg, mcpClient, listResult.Tools, tools)
+			if err := slackClient.RegisterMCPServer(serverName, mcpClient, tools); err != nil {
+				serverLogger.Warn("Failed to register tools after restart: %v", err)
+			}
+		}
+	})
+}
+
+// processSingleMCPServer processes a single MCP server configuration
+func processSingleMCPServer(
+	logger *logging.Logger,
+	serverName string,
+	serverConf config.MCPServerConfig,
+	collisionCfg config.ToolCollisionConfig,
+	remote mcp.RemoteOptions,
+	mcpClients map[string]*mcp.Client, // Use mcp.Client
+	discoveredTools map[string]mcp.ToolInfo,
+	failedServers *[]string,
+	initializedClientCount *int,
+) {
+	logger.Info("Processing server: '%s'", serverName)
+
+	// Skip disabled servers
+	if serverConf.Disabled {
+		logger.Info("  Skipping disabled server '%s'", serverName)
+		return
+	}
+
+	// Create a component-specific logger for this server
+	serverLogger := logger.WithName(serverName)
+
+	// Create client instance (assuming HTTP/SSE based on simplified config)
+	// Use mcp.NewClient from the internal package
+	mcpClient, err := createMCPClient(serverLogger, serverConf, serverName, remote)
+	if err != nil {
+		*failedServers = append(*failedServers, serverName+fmt.Sprintf("(create: %s)", err))
+		return
+	}
+
+	serverLogger.Info("Successfully created MCP client instance")
+	mcpClient.SetIdentityConfig(serverConf.Identity)
+	if serverConf.Identity.Enabled {
+		serverLogger.InfoKV("User identity propagation enabled", "mode", serverConf.Identity.GetMode(), "include_email", serverConf.Identity.IncludeEmail)
+	}
+
+	// Only close the client if initialization fails
+	// We'll keep successful clients open for the lifetime of the application
+	closeClientOnFailure := func() {
+		if mcpClient != nil && mcpClients[serverName] == nil { // Only close if not stored in mcpClients
+			serverLogger.Info("Closing unused MCP client")
+			if err := mcpClient.Close(); err != nil {
+				serverLogger.ErrorKV("Failed to close MCP client", "error", err)
+			}
+		}
+	}
+	defer closeClientOnFailure()
+
+	// Initialize client
+	// Use mcp.Client from the internal mcp package (via mcpClient variable)
+	if err := initializeMCPClientInstance(serverLogger, mcpClient, serverConf.InitializeTimeoutSeconds); err != nil {
+		*failedServers = append(*failedServers, serverName+"(initialize failed)")
+		return
+	}
+
+	// Store successfully initialized client
+	serverLogger.Info("Adding MCP client for '%s' to active client map", serverName)
+	mcpClients[serverName] = mcpClient
+	*initializedClientCount++
+
+	// Special debugging for Kubernetes server
+	if serverName == "kubernetes" {
+		serverLogger.Info("Successfully initialized Kubernetes MCP client")
+	}
+
+	// Discover tools
+	// Use mcp.Client from the internal mcp package (via mcpClient variable)
+	serverLogger.Info("Discovering tools (timeout: 20s)...")
+	discoveryCtx, discoveryCancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer discoveryCancel()
+
+	listResult, toolsErr := mcpClient.GetAvailableTools(discoveryCtx)
+
+	if toolsErr != nil {
+		serverLogger.Warn("Failed to retrieve tools: %v", toolsErr)
+		*failedServers = append(*failedServers, serverName+"(tool discovery failed)")
+		return
+	}
+
+	if listResult == nil || len(listResult.Tools) == 0 {
+		serverLogger.Warn("Server initialized but returned 0 tools")
+		return
+	}
+
+	collectServerTools(serverLogger, serverName, serverConf, collisionCfg, mcpClient, listResult.Tools, discoveredTools)
+}
+
+// CheckMCPServer starts a configured server, discovers its tools and stops it
+// again, returning the number of tools exposed to the LLM. Unlike
+// InitializeMCPServers, it returns why the server failed.
+func CheckMCPServer(logger *logging.Logger, cfg *config.Config, serverName string) (int, error) {
+	serverConf, ok := cfg.MCPServers[serverName]
+	if !ok {
+		return 0, fmt.Errorf("no MCP server named '%s' in the configuration", serverName)
+	}
+	serverLogger := logger.WithName(serverName)
+	mcpClient, err := createMCPClient(serverLogger, serverConf, serverName, mcpRemoteOptions(cfg))
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err := mcpClient.Close(); err != nil {
+			serverLogger.ErrorKV("Failed to close MCP client", "error", err)
+		}
+	}()
+
+	if err := initializeMCPClientInstance(serverLogger, mcpClient, serverConf.InitializeTimeoutSeconds); err != nil {
+		return 0, err
+	}
+	discoveryCtx, discoveryCancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer discoveryCancel()
+	listResult, err := mcpClient.GetAvailableTools(discoveryCtx)
+	if err != nil {
+		return 0, fmt.Errorf("tool discovery failed: %w", err)
+	}
+	if listResult == nil {
+		return 0, nil
+	}
+	tools := make(map[string]mcp.ToolInfo)
+	collectServerTools(serverLogger, serverName, serverConf, cfg.ToolCollision, mcpClient, listResult.Tools, tools)
+	return len(tools), nil
+}
+
+// collectServerTools adds a server's tools that pass its allow and block lists to
+// discoveredTools, under the names they are exposed to the LLM with
+func collectServerTools(
+	serverLogger *logging.Logger,
+	serverName string,
+	serverConf config.MCPServerConfig,
+	collisionCfg config.ToolCollisionConfig,
+	mcpClient *mcp.Client,
+	tools []mcpgo.Tool,
+	discoveredTools map[string]mcp.ToolInfo,
+) {
+	blockListMap := map[string]bool{}
+	allowListMap := map[string]bool{}
+	for _, toolName := range serverConf.Tools.BlockList {
+		blockListMap[toolName] = true
+	}
+	for _, toolName := range serverConf.Tools.AllowList {
+		allowListMap[toolName] = true
+	}
+
+	serverLogger.Info("Discovered %d tools", len(tools))
+	for _, toolDef := range tools {
+		if _, exists := blockListMap[toolDef.Name]; exists {
+			serverLogger.Debug("    Tool '%s' is in block list, skipping", toolDef.Name)
+			continue
+		}
+		if len(allowListMap) > 0 && !allowListMap[toolDef.Name] {
+			serverLogger.Debug("    Tool '%s' is not in allow list, skipping", toolDef.Name)
+			continue
+		}
+		toolName := mcp.ExposedToolName(collisionCfg, serverName, toolDef.Name)
+		if _, exists := discoveredTools[toolName]; !exists {
+			var inputSchemaMap map[string]interface{}
+			// Marshal the ToolInputSchema struct to JSON bytes
+			schemaBytes, err := json.Marshal(toolDef.InputSchema)
+			if err != nil {
+				serverLogger.Error("    Failed to marshal input schema struct for tool '%s': %v", toolName, err)
+				inputSchemaMap = make(map[string]interface{}) // Use empty map on error
+			} else {
+				// Unmarshal the JSON bytes into the map
+				if err := json.Unmarshal(schemaBytes, &inputSchemaMap); err != nil {
+					serverLogger.Error("    Failed to unmarshal input schema JSON for tool '%s': %v", toolName, err)
+					inputSchemaMap = make(map[string]interface{}) // Use empty map on error
+				}
+			}
+
+			// Use common.ToolInfo
+			discoveredTools[toolName] = mcp.ToolInfo{
+				ServerName:      serverName,
+				ToolName:        toolName,
+				RemoteName:      toolDef.Name,
+				ToolDescription: toolDef.Description,
+				InputSchema:     inputSchemaMap,
+				Client:          mcpClient,
+			}
+			if os.Getenv("MCP_DEBUG") == "true" {
+				// Debug messages, including the full schema, are only logged at the debug level
+				serverLogger.Debug("Stored tool: '%s' (Desc: %s)", toolName, toolDef.Description)
+				schemaJSON, _ := json.MarshalIndent(inputSchemaMap, "", "  ")
+				serverLogger.Debug("Tool schema: %s", string(schemaJSON))
+			}
+		}
+	}
+}
+
+// resolveHTTPHeaders resolves environment variables in HTTP headers
+func resolveHTTPHeaders(headers map[string]string, logger *logging.Logger) map[string]string {
+	resolvedHeaders := make(map[string]string)
+	for k, v := range headers {
+		// Handle variable substitution from environment
+		if strings.HasPrefix(v, "${") && strings.HasSuffix(v, "}") {
+			envVar := strings.TrimSuffix(strings.TrimPrefix(v, "${"), "}")
+			if envValue := os.Getenv(envVar); envValue != "" {
+				resolvedHeaders[k] = envValue
+				logger.Debug("Substituted environment variable %s for HTTP header", envVar)
+			} else {
+				logger.Warn("Environment variable %s not found for HTTP header substitution", envVar)
+				resolvedHeaders[k] = "" // Set empty value
+			}
+		} else {
+			resolvedHeaders[k] = v
+		}
+	}
+	return resolvedHeaders
+}
+
+// mcpRemoteOptions returns the connection settings shared by the sse and http
+// servers. They share one HTTP client, and so one pool of kept-alive connections.
+func mcpRemoteOptions(cfg *config.Config) mcp.RemoteOptions {
+	connections := cfg.MCPConnections
+	options := mcp.HTTPOptions{
+		MaxIdleConns:        connections.GetMaxIdleConns(),
+		MaxIdleConnsPerHost: connections.GetMaxIdleConnsPerHost(),
+		MaxConnsPerHost:     connections.MaxConnsPerHost,
+		IdleConnTimeout:     connections.GetIdleConnTimeout(),
+		KeepAlive:           connections.GetKeepAliveInterval(),
+	}
+	return mcp.RemoteOptions{
+		HTTPClient: &http.Client{Transport: network.Derive(options.Apply)},
+		HTTP:       options,
+		Reconnect: mcp.ReconnectPolicy{
+			MaxAttempts:    connections.Reconnect.GetMaxAttempts(),
+			InitialBackoff: connections.Reconnect.GetInitialBackoff(),
+			MaxBackoff:     connections.Reconnect.GetMaxBackoff(),
+			Jitter:         connections.Reconnect.GetJitter(),
+		},
+	}
+}
+
+// withClientCertificate gives a server configured for mutual TLS its own HTTP
+// client, with the shared pool settings, that presents the client certificate.
+// Certificate files are reloaded when they are rotated.
+func withClientCertificate(remote mcp.RemoteOptions, settings config.MCPTLSConfig, logger *logging.Logger) (mcp.RemoteOptions, error) {
+	if !settings.Enabled() {
+		return remote, nil
+	}
+	var cert *network.ClientCertificate
+	var err error
+	switch {
+	case settings.CertFile != "":
+		cert, err = network.LoadClientCertificate(settings.CertFile, settings.KeyFile, logger)
+	case settings.Cert != "":
+		cert, err = network.ParseClientCertificate([]byte(settings.Cert), []byte(settings.Key))
+	}
+	if err != nil {
+		return remote, err
+	}
+	transport, err := network.MutualTLSTransport(cert, settings.CAFile)
+	if err != nil {
+		return remote, err
+	}
+	remote.HTTP.Apply(transport)
+	remote.HTTPClient = &http.Client{Transport: transport}
+	return remote, nil
+}
+
+// createMCPClient creates an MCP client based on configuration
+func createMCPClient(logger *logging.Logger, serverConf config.MCPServerConfig, serverName string, remote mcp.RemoteOptions) (*mcp.Client, error) {
+	// Built-in servers run in process
+	if serverConf.Builtin != "" {
+		logger.InfoKV("Creating MCP client", "transport", config.TransportBuiltin, "builtin", serverConf.Builtin, "args", serverConf.Args)
+		mcpClient, createErr := mcp.NewBuiltinClient(serverName, serverConf)
+		if createErr != nil {
+			logger.Error("Failed to create builtin MCP server %s: %v", serverConf.Builtin, createErr)
+			return nil, customErrors.WrapMCPError(createErr, "client_creation_failed",
+				fmt.Sprintf("Failed to create builtin MCP server '%s'", serverConf.Builtin)).WithData("builtin", serverConf.Builtin)
+		}
+		return mcpClient, nil
+	}
+
+	// Check if this is a URL-based (HTTP/SSE) configuration
+	if serverConf.URL != "" {
+		// Assume "sse" transport by default for HTTP-based connections
+		transport := serverConf.Transport
+		if transport == "" {
+			transport = "sse" // Default to SSE if not specified
+		}
+		logger.InfoKV("Creating MCP client", "transport", transport, "address", serverConf.URL)
+
+		// Resolve HTTPHeaders environment variables for URL-based configurations
+		resolvedHeaders := resolveHTTPHeaders(serverConf.HTTPHeaders, logger)
+
+		// Present the client certificate to servers requiring mutual TLS
+		remote, tlsErr := withClientCertificate(remote, serverConf.TLS, logger)
+		if tlsErr != nil {
+			logger.Error("Failed to set up mutual TLS for MCP server %s: %v", serverName, tlsErr)
+			return nil, customErrors.WrapMCPError(tlsErr, "client_creation_failed",
+				fmt.Sprintf("Failed to set up mutual TLS for MCP server '%s'", serverName)).WithData("server", serverName)
+		}
+
+		// Use the imported mcp.NewClient from internal/mcp/client.go with structured logger
+		mcpClient, createErr := mcp.NewClient(transport, serverConf.URL, serverName, nil, nil, mcp.EnvPolicy{}, mcp.RestartPolicy{}, resolvedHeaders, remote, logger)
+		if createErr != nil {
+			logger.Error("Failed to create MCP client for URL %s: %v", serverConf.URL, createErr)
+			// Create a domain-specific error with additional context
+			domainErr := customErrors.WrapMCPError(createErr, "client_creation_failed",
+				fmt.Sprintf("Failed to create MCP client for URL '%s'", serverConf.URL))
+
+			// Add additional context data
+			domainErr = domainErr.WithData("transport", transport)
+			domainErr = domainErr.WithData("url", serverConf.URL)
+			return nil, domainErr
+		}
+		return mcpClient, nil
+	}
+
+	// Check if this is a command-based (stdio) configuration, run directly or in a container
+	if serverConf.Command != "" || serverConf.IsDocker() {
+		transport := "stdio"
+		logger.InfoKV("Creating MCP client", "transport", transport, "command", serverConf.Command, "args", serverConf.Args, "runtime", serverConf.Runtime)
+
+		// Process environment variables
+		env := make(map[string]string)
+		for k, v := range serverConf.Env {
+			// Handle variable substitution from environment
+			if strings.HasPrefix(v, "${") && strings.HasSuffix(v, "}") {
+				envVar := strings.TrimSuffix(strings.TrimPrefix(v, "${"), "}")
+				if envValue := os.Getenv(envVar); envValue != "" {
+					env[k] = envValue
+					logger.Debug("Substituted environment variable %s for MCP server", envVar)
+				} else {
+					logger.Warn("Environment variable %s not found for substitution", envVar)
+					env[k] = "" // Set empty value
+				}
+			} else {
+				env[k] = v
+			}
+		}
+
+		// Resolve HTTPHeaders environment variables
+		resolvedHeaders := resolveHTTPHeaders(serverConf.HTTPHeaders, logger)
+
+		// Wrap the command in "docker run" for the docker runtime
+		command, args := serverConf.Command, serverConf.Args
+		if serverConf.IsDocker() {
+			command, args = mcp.DockerCommand(serverConf.Docker, serverName, command, args, env)
+		}
+
+		// Create the MCP client
+		logger.DebugKV("Executing command", "command", command, "args", args, "env", env, "headers", resolvedHeaders)
+		envPolicy := mcp.EnvPolicy{Isolated: !serverConf.InheritsEnv(), Allowlist: serverConf.EnvAllowlist}
+		mcpClient, createErr := mcp.NewClient(transport, command, serverName, args, env, envPolicy, restartPolicy(serverConf), resolvedHeaders, mcp.RemoteOptions{}, logger)
+		if createErr != nil {
+			logger.Error("Failed to create MCP client: %v", createErr)
+			// Create a domain-specific error with additional context
+			domainErr := customErrors.WrapMCPError(createErr, "client_creation_failed",
+				fmt.Sprintf("Failed to create MCP client for command '%s'", command))
+
+			// Add additional context data
+			domainErr = domainErr.WithData("transport", transport)
+			domainErr = domainErr.WithData("command", command)
+			return nil, domainErr
+		}
+		return mcpClient, nil
+	}
+
+	// Neither URL nor Command specified
+	logger.Error("Skipping server: Neither 'url', 'command' nor 'builtin' specified in config")
+	return nil, customErrors.NewMCPError("invalid_config", "Missing both URL and command in server configuration")
+}
+
+// restartPolicy returns how a stdio server whose process exits is restarted
+func restartPolicy(serverConf config.MCPServerConfig) mcp.RestartPolicy {
+	if !serverConf.RestartsOnExit() {
+		return mcp.RestartPolicy{}
+	}
+	return mcp.RestartPolicy{
+		MaxAttempts:       serverConf.Restart.GetMaxAttempts(),
+		InitialBackoff:    serverConf.Restart.GetInitialBackoff(),
+		MaxBackoff:        serverConf.Restart.GetMaxBackoff(),
+		StableAfter:       serverConf.Restart.GetStableAfter(),
+		InitializeTimeout: time.Duration(serverConf.GetInitializeTimeout()) * time.Second,
+		KillOnCancel:      serverConf.Restart.KillsOnCancel(),
+	}
+}
+
+// initializeMCPClientInstance initializes an MCP client with proper timeout
+// Use mcp.Client from the internal mcp package
+func initializeMCPClientInstance(logger *logging.Logger, client *mcp.Client, timeoutSeconds *int) error {
+	initTimeout := 5 // Default timeout
+	if timeoutSeconds != nil {
+		initTimeout = *timeoutSeconds
+	}
+	logger.Info("Attempting to initialize MCP client (timeout: %d)...", initTimeout)
+	// Create a context with timeout for initialization
+	initCtx, initCancel := context.WithTimeout(context.Background(), time.Duration(initTimeout)*time.Second)
+	defer initCancel()
+
+	// Try to initialize the client
+	initErr := client.Initialize(initCtx)
+	if initErr != nil {
+		// Log detailed error information
+		logger.Error("Failed to initialize MCP client: %v", initErr)
+
+		// Create a domain-specific error with additional context
+		domainErr := customErrors.WrapMCPError(initErr, "initialization_failed", "Failed to initialize MCP client")
+
+		// Check for specific error conditions and add more context
+		if strings.Contains(initErr.Error(), "context deadline exceeded") {
+			logger.Error("Initialization timed out. The MCP server may be slow to start or not responding.")
+			logger.Error("Try increasing the timeout or check if the NPM package is installed correctly.")
+			domainErr = domainErr.WithData("timeout_exceeded", true)
+			domainErr = domainErr.WithData("suggestion", "Increase timeout or check NPM package installation")
+		} else if strings.Contains(initErr.Error(), "file already closed") {
+			logger.Error("The MCP server process exited prematurely. Check command and arguments.")
+			domainErr = domainErr.WithData("process_exited", true)
+			domainErr = domainErr.WithData("suggestion", "Check command and arguments")
+		}
+
+		logger.Warn("Client will not be used for tool discovery or execution")
+		return domainErr
+	}
+
+	logger.Info("MCP client successfully initialized")
+	return nil
+}
@@ -0,0 +1,184 @@
+// Package availability decides whether the bot answers right now. Maintenance
+// mode, toggled in the config or at runtime by an admin, and recurring quiet
+// hours make it reply with a notice instead, or serve only admins.
+package availability
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+// Reasons the bot is unavailable
+const (
+	ReasonMaintenance = "maintenance"
+	ReasonQuietHours  = "quiet_hours"
+)
+
+// Status is whether the bot answers right now
+type Status struct {
+	Available  bool   `json:"available"`
+	Reason     string `json:"reason,omitempty"`     // ReasonMaintenance or ReasonQuietHours
+	AdminsOnly bool   `json:"adminsOnly,omitempty"` // Admins are still answered
+	Message    string `json:"-"`                    // Notice sent to everyone else
+}
+
+// window is a parsed quiet hours window
+type window struct {
+	start, end time.Duration // Offsets from midnight
+	days       map[time.Weekday]bool
+	location   *time.Location
+	message    string
+}
+
+// Controller evaluates maintenance mode and quiet hours
+type Controller struct {
+	cfg     config.MaintenanceConfig
+	windows []window
+	now     func() time.Time
+
+	mu       sync.RWMutex
+	override *bool // Maintenance mode set at runtime, overriding the config
+}
+
+// NewController creates a Controller from the maintenance configuration
+func NewController(cfg config.MaintenanceConfig) (*Controller, error) {
+	c := &Controller{cfg: cfg, now: time.Now}
+	for i, quiet := range cfg.QuietHours {
+		w, err := parseWindow(quiet)
+		if err != nil {
+			return nil, fmt.Errorf("quiet hours %d: %w", i+1, err)
+		}
+		c.windows = append(c.windows, w)
+	}
+	return c, nil
+}
+
+// parseWindow parses the times, days and timezone of a quiet hours window
+func parseWindow(quiet config.QuietHoursWindow) (window, error) {
+	start, err := parseClock(quiet.Start)
+	if err != nil {
+		return window{}, err
+	}
+	end, err := parseClock(quiet.End)
+	if err != nil {
+		return window{}, err
+	}
+	location, err := time.LoadLocation(quiet.Timezone)
+	if err != nil {
+		return window{}, fmt.Errorf("unknown timezone '%s': %w", quiet.Timezone, err)
+	}
+	w := window{start: start, end: end, location: location, message: quiet.Message}
+	if len(quiet.Days) > 0 {
+		w.days = make(map[time.Weekday]bool, len(quiet.Days))
+		for _, day := range quiet.Days {
+			weekday, ok := config.Weekdays[strings.ToLower(day)]
+			if !ok {
+				return window{}, fmt.Errorf("unknown day '%s'", day)
+			}
+			w.days[weekday] = true
+		}
+	}
+	return w, nil
+}
+
+// parseClock parses "HH:MM" as an offset from midnight
+func parseClock(clock string) (time.Duration, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time '%s' (use HH:MM)", clock)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// contains reports whether the window covers the instant. A window that runs past
+// midnight belongs to the day it starts on.
+func (w window) contains(now time.Time) bool {
+	local := now.In(w.location)
+	offset := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute
+	day := local.Weekday()
+	if w.start < w.end {
+		return offset >= w.start && offset < w.end && w.startsOn(day)
+	}
+	if offset >= w.start {
+		return w.startsOn(day)
+	}
+	return offset < w.end && w.startsOn((day+6)%7)
+}
+
+func (w window) startsOn(day time.Weekday) bool {
+	return w.days == nil || w.days[day]
+}
+
+// Status reports whether the bot answers right now. Maintenance mode takes
+// precedence over quiet hours.
+func (c *Controller) Status() Status {
+	if c.Maintenance() {
+		return Status{Reason: ReasonMaintenance, AdminsOnly: c.cfg.AdminsOnly, Message: c.cfg.Message}
+	}
+	now := c.now()
+	for _, w := range c.windows {
+		if w.contains(now) {
+			return Status{Reason: ReasonQuietHours, AdminsOnly: c.cfg.AdminsOnly, Message: w.message}
+		}
+	}
+	return Status{Available: true}
+}
+
+// Maintenance reports whether maintenance mode is on
+func (c *Controller) Maintenance() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.override != nil {
+		return *c.override
+	}
+	return c.cfg.Enabled
+}
+
+// SetMaintenance turns maintenance mode on or off until the process restarts
+func (c *Controller) SetMaintenance(on bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.override = &on
+}
+
+// current is the controller of the running client, read by the readiness endpoint
+var current atomic.Pointer[Controller]
+
+// Register makes the controller the one reported by the readiness endpoint. A
+// maintenance mode set at runtime carries over from the previous controller, so
+// it survives config reloads.
+func Register(c *Controller) {
+	if previous := current.Swap(c); previous != nil {
+		previous.mu.RLock()
+		override := previous.override
+		previous.mu.RUnlock()
+		c.mu.Lock()
+		c.override = override
+		c.mu.Unlock()
+	}
+}
+
+// ReadinessHandler serves the readiness endpoint. It answers 503 until a client
+// is running, then 200 with the current availability:
+//
+//	{"status": "ready", "availability": {"available": false, "reason": "maintenance"}}
+func ReadinessHandler(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	controller := current.Load()
+	if controller == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_ = json.NewEncoder(w).Encode(map[string]string{"status": "starting"})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":       "ready",
+		"availability": controller.Status(),
+	})
+}
@@ -0,0 +1,96 @@
+package availability
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+func newTestController(t *testing.T, cfg config.MaintenanceConfig, now time.Time) *Controller {
+	t.Helper()
+	c, err := NewController(cfg)
+	require.NoError(t, err)
+	c.now = func() time.Time { return now }
+	return c
+}
+
+func TestQuietHours(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	cfg := config.MaintenanceConfig{QuietHours: []config.QuietHoursWindow{
+		{Start: "22:00", End: "07:00", Days: []string{"fri"}, Timezone: "Europe/Berlin", Message: "Quiet"},
+		{Start: "12:00", End: "13:00", Timezone: "UTC", Message: "Lunch"},
+	}}
+
+	tests := []struct {
+		name    string
+		now     time.Time
+		message string
+	}{
+		{"friday night", time.Date(2026, 10, 16, 23, 0, 0, 0, berlin), "Quiet"},
+		{"early saturday belongs to friday's window", time.Date(2026, 10, 17, 6, 59, 0, 0, berlin), "Quiet"},
+		{"window ended", time.Date(2026, 10, 17, 7, 0, 0, 0, berlin), ""},
+		{"thursday night is not quiet", time.Date(2026, 10, 15, 23, 0, 0, 0, berlin), ""},
+		{"early friday belongs to thursday", time.Date(2026, 10, 16, 6, 0, 0, 0, berlin), ""},
+		{"daily window in UTC", time.Date(2026, 10, 14, 12, 30, 0, 0, time.UTC), "Lunch"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := newTestController(t, cfg, tt.now).Status()
+			assert.Equal(t, tt.message == "", status.Available)
+			assert.Equal(t, tt.message, status.Message)
+			if tt.message != "" {
+				assert.Equal(t, ReasonQuietHours, status.Reason)
+			}
+		})
+	}
+}
+
+func TestMaintenanceToggle(t *testing.T) {
+	cfg := config.MaintenanceConfig{Enabled: true, Message: "Down", AdminsOnly: true}
+	c := newTestController(t, cfg, time.Now())
+
+	status := c.Status()
+	assert.False(t, status.Available)
+	assert.Equal(t, ReasonMaintenance, status.Reason)
+	assert.True(t, status.AdminsOnly)
+	assert.Equal(t, "Down", status.Message)
+
+	c.SetMaintenance(false)
+	assert.True(t, c.Status().Available)
+
+	// A runtime toggle survives the controller being replaced on reload
+	Register(c)
+	reloaded := newTestController(t, cfg, time.Now())
+	Register(reloaded)
+	assert.False(t, reloaded.Maintenance())
+}
+
+func TestReadinessHandler(t *testing.T) {
+	current.Store(nil)
+	recorder := httptest.NewRecorder()
+	ReadinessHandler(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+	c := newTestController(t, config.MaintenanceConfig{Enabled: true, Message: "Down"}, time.Now())
+	Register(c)
+	recorder = httptest.NewRecorder()
+	ReadinessHandler(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var body struct {
+		Status       string `json:"status"`
+		Availability Status `json:"availability"`
+	}
+	require.NoError(t, json.NewDecoder(recorder.Body).Decode(&body))
+	assert.Equal(t, "ready", body.Status)
+	assert.False(t, body.Availability.Available)
+	assert.Equal(t, ReasonMaintenance, body.Availability.Reason)
+}
@@ -0,0 +1,233 @@
+// Package circuit stops calling the tools of an MCP server that keeps being
+// unreachable. After a number of consecutive calls failed because the server could
+// not be reached, its circuit opens and calls fail at once instead of waiting for
+// the transport to give up. Once the open duration passes, one call is let through
+// to probe the server: its success closes the circuit, its failure opens it again.
+package circuit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+	"github.com/tuannvm/slack-mcp-client/internal/monitoring"
+	"github.com/tuannvm/slack-mcp-client/pkg/middleware"
+)
+
+// OpenError is returned for a call to a server whose circuit is open. It wraps the
+// error that opened the circuit, so it is recognized as the server being unavailable.
+type OpenError struct {
+	Server   string
+	Failures int
+	Cause    error
+}
+
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("MCP server '%s' is unavailable (circuit breaker open after %d failed calls): %v", e.Server, e.Failures, e.Cause)
+}
+
+func (e *OpenError) Unwrap() error {
+	return e.Cause
+}
+
+// Event is a circuit that opened or closed
+type Event struct {
+	Server   string
+	Open     bool  // False when the circuit closed again
+	Failures int   // Consecutive failed calls that opened the circuit
+	Err      error // Last error of the server, nil when the circuit closed
+}
+
+// state is the circuit of one server
+type state struct {
+	failures int
+	lastErr  error
+	open     bool
+	openedAt time.Time
+	probing  bool // A call is probing the open server
+}
+
+// Breakers keeps a circuit per server
+type Breakers struct {
+	threshold int
+	openFor   time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	servers  map[string]*state
+	onChange func(Event)
+}
+
+// NewBreakers returns the circuit breakers of the configuration, or nil when they
+// are disabled
+func NewBreakers(cfg config.MCPCircuitBreakerConfig) *Breakers {
+	if cfg.Disabled {
+		return nil
+	}
+	b := &Breakers{threshold: cfg.FailureThreshold, now: time.Now, servers: make(map[string]*state)}
+	b.openFor, _ = time.ParseDuration(cfg.OpenDuration) // Validated with the config
+	return b
+}
+
+// SetChangeHandler sets the function told about circuits that open or close
+func (b *Breakers) SetChangeHandler(handler func(Event)) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onChange = handler
+}
+
+// server returns the circuit of a server. It is called with b.mu held.
+func (b *Breakers) server(name string) *state {
+	s, ok := b.servers[name]
+	if !ok {
+		s = &state{}
+		b.servers[name] = s
+	}
+	return s
+}
+
+// Allow reports whether a call to the server may go through. It returns an
+// OpenError while the circuit is open, except for the one call probing the server
+// once the open duration has passed.
+func (b *Breakers) Allow(server string) error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s := b.server(server)
+	if !s.open {
+		return nil
+	}
+	if !s.probing && b.now().Sub(s.openedAt) >= b.openFor {
+		s.probing = true
+		return nil
+	}
+	monitoring.MCPCircuitRejections.WithLabelValues(server).Inc()
+	return &OpenError{Server: server, Failures: s.failures, Cause: s.lastErr}
+}
+
+// Record records the outcome of a call to the server. Only errors of an unreachable
+// server count as failures: a tool that returns an error still has a server.
+func (b *Breakers) Record(server string, err error) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	s := b.server(server)
+	var event *Event
+	switch {
+	case mcp.IsServerUnavailable(err):
+		s.failures++
+		s.lastErr = err
+		switch {
+		case s.probing:
+			s.probing = false
+			s.openedAt = b.now() // Still down; wait again without a new alert
+		case !s.open && s.failures >= b.threshold:
+			s.open = true
+			s.openedAt = b.now()
+			event = &Event{Server: server, Open: true, Failures: s.failures, Err: err}
+		}
+	case s.open:
+		event = &Event{Server: server}
+		*s = state{}
+	default:
+		s.failures = 0
+	}
+	handler := b.onChange
+	b.mu.Unlock()
+	b.notify(handler, event)
+}
+
+// Release gives up the probe of a call that was cancelled before its outcome was known
+func (b *Breakers) Release(server string) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.server(server).probing = false
+}
+
+// Reset closes the circuit of a server that was just initialized again, such as a
+// stdio server whose process was restarted
+func (b *Breakers) Reset(server string) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	s := b.server(server)
+	var event *Event
+	if s.open {
+		event = &Event{Server: server}
+	}
+	*s = state{}
+	handler := b.onChange
+	b.mu.Unlock()
+	b.notify(handler, event)
+}
+
+// IsOpen reports whether the circuit of a server is open
+func (b *Breakers) IsOpen(server string) bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.server(server).open
+}
+
+// notify exports the state of a circuit that changed and tells the change handler
+func (b *Breakers) notify(handler func(Event), event *Event) {
+	if event == nil {
+		return
+	}
+	if event.Open {
+		monitoring.MCPCircuitOpen.WithLabelValues(event.Server).Set(1)
+	} else {
+		monitoring.MCPCircuitOpen.WithLabelValues(event.Server).Set(0)
+	}
+	if handler != nil {
+		handler(*event)
+	}
+}
+
+// breakerMiddleware runs tool calls through the circuit of their server
+type breakerMiddleware struct {
+	middleware.Base
+	breakers *Breakers
+}
+
+// Middleware returns a middleware that fails the calls of open circuits and
+// records the outcome of the others, or nil when circuit breakers are disabled
+func (b *Breakers) Middleware() middleware.Middleware {
+	if b == nil {
+		return nil
+	}
+	return breakerMiddleware{breakers: b}
+}
+
+func (m breakerMiddleware) WrapTool(next middleware.ToolHandler) middleware.ToolHandler {
+	return func(ctx context.Context, call *middleware.ToolCall) (string, error) {
+		if call.Server == "" {
+			return next(ctx, call)
+		}
+		if err := m.breakers.Allow(call.Server); err != nil {
+			return "", err
+		}
+		result, err := next(ctx, call)
+		if err != nil && ctx.Err() != nil {
+			m.breakers.Release(call.Server)
+			return result, err
+		}
+		m.breakers.Record(call.Server, err)
+		return result, err
+	}
+}
@@ -0,0 +1,142 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+	"github.com/tuannvm/slack-mcp-client/pkg/middleware"
+)
+
+var errRefused = errors.New("failed to send request: dial tcp 127.0.0.1:8080: connect: connection refused")
+
+// newTestBreakers returns breakers with the default settings, their clock and the
+// events they reported
+func newTestBreakers(t *testing.T) (*Breakers, *time.Time, *[]Event) {
+	t.Helper()
+	c := &config.Config{}
+	c.ApplyDefaults()
+	breakers := NewBreakers(c.MCPCircuit)
+	require.NotNil(t, breakers)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	breakers.now = func() time.Time { return now }
+	var events []Event
+	breakers.SetChangeHandler(func(event Event) { events = append(events, event) })
+	return breakers, &now, &events
+}
+
+func TestNewBreakersDisabled(t *testing.T) {
+	var breakers *Breakers = NewBreakers(config.MCPCircuitBreakerConfig{Disabled: true})
+	assert.Nil(t, breakers)
+	assert.Nil(t, breakers.Middleware())
+
+	// Nil breakers let every call through
+	assert.NoError(t, breakers.Allow("kubernetes"))
+	breakers.Record("kubernetes", errRefused)
+	breakers.Reset("kubernetes")
+	assert.False(t, breakers.IsOpen("kubernetes"))
+}
+
+func TestCircuitOpensAfterConsecutiveFailures(t *testing.T) {
+	breakers, _, events := newTestBreakers(t)
+
+	// Tool errors and successes do not count, and reset the count
+	breakers.Record("kubernetes", errRefused)
+	breakers.Record("kubernetes", errors.New("namespace not found"))
+	breakers.Record("kubernetes", errRefused)
+	breakers.Record("kubernetes", nil)
+	breakers.Record("kubernetes", errRefused)
+	breakers.Record("kubernetes", syscall.EPIPE)
+	assert.False(t, breakers.IsOpen("kubernetes"))
+	assert.Empty(t, *events)
+
+	breakers.Record("kubernetes", errRefused)
+	assert.True(t, breakers.IsOpen("kubernetes"))
+	require.Len(t, *events, 1)
+	assert.Equal(t, Event{Server: "kubernetes", Open: true, Failures: 3, Err: errRefused}, (*events)[0])
+
+	// Other servers keep their own circuit
+	assert.NoError(t, breakers.Allow("github"))
+
+	err := breakers.Allow("kubernetes")
+	var openErr *OpenError
+	require.ErrorAs(t, err, &openErr)
+	assert.Equal(t, "kubernetes", openErr.Server)
+	assert.True(t, mcp.IsServerUnavailable(err))
+}
+
+func TestCircuitProbesAfterOpenDuration(t *testing.T) {
+	breakers, now, events := newTestBreakers(t)
+	for i := 0; i < 3; i++ {
+		breakers.Record("kubernetes", errRefused)
+	}
+	assert.Error(t, breakers.Allow("kubernetes"))
+
+	// One call probes the server once the open duration passed
+	*now = now.Add(30 * time.Second)
+	assert.NoError(t, breakers.Allow("kubernetes"))
+	assert.Error(t, breakers.Allow("kubernetes"))
+
+	// A failed probe opens the circuit again, without a new event
+	breakers.Record("kubernetes", errRefused)
+	assert.Error(t, breakers.Allow("kubernetes"))
+	assert.Len(t, *events, 1)
+
+	// A successful probe closes it
+	*now = now.Add(30 * time.Second)
+	assert.NoError(t, breakers.Allow("kubernetes"))
+	breakers.Record("kubernetes", nil)
+	assert.False(t, breakers.IsOpen("kubernetes"))
+	assert.NoError(t, breakers.Allow("kubernetes"))
+	require.Len(t, *events, 2)
+	assert.Equal(t, Event{Server: "kubernetes"}, (*events)[1])
+}
+
+func TestResetClosesCircuit(t *testing.T) {
+	breakers, _, events := newTestBreakers(t)
+	breakers.Reset("kubernetes")
+	assert.Empty(t, *events)
+
+	for i := 0; i < 3; i++ {
+		breakers.Record("kubernetes", errRefused)
+	}
+	breakers.Reset("kubernetes")
+	assert.False(t, breakers.IsOpen("kubernetes"))
+	require.Len(t, *events, 2)
+	assert.False(t, (*events)[1].Open)
+}
+
+func TestMiddlewareFailsCallsOfOpenCircuits(t *testing.T) {
+	breakers, now, _ := newTestBreakers(t)
+	calls := 0
+	handler := breakers.Middleware().WrapTool(func(ctx context.Context, call *middleware.ToolCall) (string, error) {
+		calls++
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		return "", errRefused
+	})
+
+	call := &middleware.ToolCall{Tool: "get_pods", Server: "kubernetes"}
+	for i := 0; i < 5; i++ {
+		_, err := handler(context.Background(), call)
+		assert.True(t, mcp.IsServerUnavailable(err))
+	}
+	assert.Equal(t, 3, calls)
+
+	// A cancelled probe lets the next call probe again
+	*now = now.Add(time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _ = handler(ctx, call)
+	assert.Equal(t, 4, calls)
+	_, _ = handler(context.Background(), call)
+	assert.Equal(t, 5, calls)
+}
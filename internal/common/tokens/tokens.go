@@ -0,0 +1,40 @@
+// Package tokens counts the tokens of text, for budgets that follow what an LLM
+// reads rather than characters or messages
+package tokens
+
+import (
+	"sync"
+	"unicode/utf8"
+
+	"github.com/pkoukk/tiktoken-go"
+	tiktokenloader "github.com/pkoukk/tiktoken-go-loader"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+)
+
+// Encoding is the tokenizer used to count tokens
+const Encoding = "cl100k_base"
+
+var (
+	logger = logging.New("tokens", logging.LevelInfo)
+
+	tokenizerOnce sync.Once
+	tokenizer     *tiktoken.Tiktoken
+)
+
+// Count counts tokens with the cl100k_base encoding, which is embedded in the
+// binary. If the encoding cannot be loaded, tokens are estimated at four characters each.
+func Count(text string) int {
+	tokenizerOnce.Do(func() {
+		tiktoken.SetBpeLoader(tiktokenloader.NewOfflineLoader())
+		var err error
+		tokenizer, err = tiktoken.GetEncoding(Encoding)
+		if err != nil {
+			logger.WarnKV("Failed to load the tokenizer, estimating token counts", "encoding", Encoding, "error", err)
+		}
+	})
+	if tokenizer == nil {
+		return (utf8.RuneCountInString(text) + 3) / 4
+	}
+	return len(tokenizer.Encode(text, nil, nil))
+}
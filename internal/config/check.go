@@ -0,0 +1,361 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Problem is one issue found in a configuration file
+type Problem struct {
+	File       string // Config file or overlay the problem is in; empty for the environment
+	Path       string // JSON path of the offending value, e.g. $.mcpServers.github.restart.maxBackoff
+	Message    string
+	Suggestion string // How to fix it, when known
+}
+
+// String formats the problem as "file: path: message (suggestion)"
+func (p Problem) String() string {
+	text := p.Message
+	if p.Path != "" {
+		text = p.Path + ": " + text
+	}
+	if p.File != "" {
+		text = p.File + ": " + text
+	}
+	if p.Suggestion != "" {
+		text += " (" + p.Suggestion + ")"
+	}
+	return text
+}
+
+// Problems is every issue found in a configuration file
+type Problems []Problem
+
+// Error joins the problems, one per line
+func (p Problems) Error() string {
+	lines := make([]string, len(p))
+	for i, problem := range p {
+		lines[i] = problem.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// CheckConfigFile validates a configuration file and reports every structural
+// problem in it and in MCP_SERVERS_JSON: JSON syntax errors, unknown keys, values
+// of the wrong type and malformed durations. When the structure is sound, the
+// configuration is also loaded like LoadConfig does and the first semantic error
+// (such as a missing token) is reported. An empty configFile checks the
+// environment-only configuration. Overlays are checked one by one, then loaded
+// merged into the config file. It returns nil when the configuration is valid.
+func CheckConfigFile(configFile string, overlays ...string) Problems {
+	problems := checkMCPServersEnv()
+	if configFile != "" {
+		problems = append(problems, checkFile(configFile, false)...)
+	}
+	for _, overlay := range overlays {
+		problems = append(problems, checkFile(overlay, true)...)
+	}
+	if len(problems) > 0 {
+		return problems
+	}
+
+	if _, err := LoadConfig(configFile, nil, overlays...); err != nil {
+		return Problems{{Message: err.Error()}}
+	}
+	return nil
+}
+
+// checkFile reports the structural problems of a configuration file or overlay
+func checkFile(configFile string, overlay bool) Problems {
+	data, err := os.ReadFile(configFile)
+	if err != nil {
+		return Problems{{File: configFile, Message: fmt.Sprintf("cannot read config file: %v", err)}}
+	}
+
+	raw, problem := decodeForCheck(data, "$")
+	if problem != nil {
+		problem.File = configFile
+		return Problems{*problem}
+	}
+	var problems Problems
+	if !overlay && isLegacyFormat(data) {
+		// Legacy files only hold mcpServers; other keys are ignored when loading
+		servers := raw.(map[string]interface{})["mcpServers"]
+		checkValue(servers, reflect.TypeOf(Config{}.MCPServers), "mcpServers", "$.mcpServers", &problems)
+	} else {
+		checkValue(raw, reflect.TypeOf(Config{}), "", "$", &problems)
+	}
+	for i := range problems {
+		problems[i].File = configFile
+	}
+	return problems
+}
+
+// checkMCPServersEnv reports the structural problems of MCP_SERVERS_JSON
+func checkMCPServersEnv() Problems {
+	value := os.Getenv(EnvMCPServersJSON)
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	raw, problem := decodeForCheck(mcpServersJSONBody([]byte(value)), EnvMCPServersJSON)
+	if problem != nil {
+		return Problems{*problem}
+	}
+	var problems Problems
+	checkValue(raw, reflect.TypeOf(Config{}.MCPServers), "", EnvMCPServersJSON, &problems)
+	return problems
+}
+
+// decodeForCheck decodes JSON for checking, locating syntax errors
+func decodeForCheck(data []byte, path string) (interface{}, *Problem) {
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		problem := &Problem{Path: path, Message: fmt.Sprintf("invalid JSON: %v", err)}
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			line, column := lineColumn(data, syntaxErr.Offset)
+			problem.Message = fmt.Sprintf("invalid JSON at line %d, column %d: %v", line, column, err)
+		}
+		return nil, problem
+	}
+	return raw, nil
+}
+
+// lineColumn returns the 1-based line and column of the byte before offset, which
+// is where json.SyntaxError offsets point just past
+func lineColumn(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	if offset > 0 {
+		offset--
+	}
+	before := string(data[:offset])
+	return strings.Count(before, "\n") + 1, len(before) - strings.LastIndex(before, "\n")
+}
+
+// plainKey matches map keys that can be written after a dot in a JSON path
+var plainKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// childPath appends an object key to a JSON path
+func childPath(path, key string) string {
+	if plainKey.MatchString(key) {
+		return path + "." + key
+	}
+	return path + "[" + strconv.Quote(key) + "]"
+}
+
+// jsonType names the type of a decoded JSON value for error messages
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "an array"
+	case string:
+		return "a string"
+	case float64:
+		return "a number"
+	case bool:
+		return "a boolean"
+	default:
+		return "null"
+	}
+}
+
+// checkValue compares a decoded JSON value with the Go type it is loaded into.
+// name is the JSON name of the field holding it, used to recognize durations.
+func checkValue(value interface{}, t reflect.Type, name, path string, problems *Problems) {
+	if value == nil {
+		return // null leaves the default in place
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	mismatch := func(expected string) {
+		*problems = append(*problems, Problem{Path: path, Message: fmt.Sprintf("expected %s, got %s", expected, jsonType(value))})
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		s, ok := value.(string)
+		if !ok {
+			if n, isNumber := value.(float64); isNumber && isDurationField(name) {
+				seconds := strconv.FormatFloat(n, 'f', -1, 64)
+				*problems = append(*problems, Problem{Path: path, Message: "expected a duration string, got a number",
+					Suggestion: fmt.Sprintf("write \"%ss\" for %s seconds", seconds, seconds)})
+				return
+			}
+			mismatch("a string")
+			return
+		}
+		if isDurationField(name) && s != "" {
+			checkDuration(s, path, problems)
+		}
+	case reflect.Bool:
+		if _, ok := value.(bool); !ok {
+			problem := Problem{Path: path, Message: fmt.Sprintf("expected a boolean, got %s", jsonType(value))}
+			if s, isString := value.(string); isString && (s == "true" || s == "false") {
+				problem.Suggestion = fmt.Sprintf("remove the quotes: %s", s)
+			}
+			*problems = append(*problems, problem)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := value.(float64)
+		if !ok {
+			problem := Problem{Path: path, Message: fmt.Sprintf("expected an integer, got %s", jsonType(value))}
+			if s, isString := value.(string); isString {
+				if _, err := strconv.Atoi(s); err == nil {
+					problem.Suggestion = fmt.Sprintf("remove the quotes: %s", s)
+				}
+			}
+			*problems = append(*problems, problem)
+			return
+		}
+		if n != float64(int64(n)) {
+			mismatch("an integer")
+		} else if n < 0 && t.Kind() >= reflect.Uint && t.Kind() <= reflect.Uint64 {
+			*problems = append(*problems, Problem{Path: path, Message: "must not be negative"})
+		}
+	case reflect.Float32, reflect.Float64:
+		if _, ok := value.(float64); !ok {
+			mismatch("a number")
+		}
+	case reflect.Slice, reflect.Array:
+		items, ok := value.([]interface{})
+		if !ok {
+			problem := Problem{Path: path, Message: fmt.Sprintf("expected an array, got %s", jsonType(value))}
+			if _, isString := value.(string); isString {
+				problem.Suggestion = "wrap the value in [ ]"
+			}
+			*problems = append(*problems, problem)
+			return
+		}
+		for i, item := range items {
+			checkValue(item, t.Elem(), name, fmt.Sprintf("%s[%d]", path, i), problems)
+		}
+	case reflect.Map:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			mismatch("an object")
+			return
+		}
+		for _, key := range sortedKeys(object) {
+			checkValue(object[key], t.Elem(), "", childPath(path, key), problems)
+		}
+	case reflect.Struct:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			mismatch("an object")
+			return
+		}
+		checkObject(object, t, path, problems)
+	}
+}
+
+// checkObject checks the keys of an object loaded into a struct. Like
+// encoding/json, keys match field names case-insensitively.
+func checkObject(object map[string]interface{}, t reflect.Type, path string, problems *Problems) {
+	fields := jsonFields(t, reflect.Value{})
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.name
+	}
+
+	for _, key := range sortedKeys(object) {
+		if path == "$" && key == "$schema" {
+			continue
+		}
+		var match *jsonField
+		for i := range fields {
+			if fields[i].name == key {
+				match = &fields[i]
+				break
+			}
+			if match == nil && strings.EqualFold(fields[i].name, key) {
+				match = &fields[i]
+			}
+		}
+		if match == nil {
+			problem := Problem{Path: childPath(path, key), Message: fmt.Sprintf("unknown key '%s'", key)}
+			if suggestion := closestName(key, names); suggestion != "" {
+				problem.Suggestion = fmt.Sprintf("did you mean '%s'?", suggestion)
+			}
+			*problems = append(*problems, problem)
+			continue
+		}
+		checkValue(object[key], match.field.Type, match.name, childPath(path, key), problems)
+	}
+}
+
+// checkDuration reports a string that time.ParseDuration rejects
+func checkDuration(value, path string, problems *Problems) {
+	if _, err := time.ParseDuration(value); err == nil {
+		return
+	}
+	problem := Problem{Path: path, Message: fmt.Sprintf("invalid duration '%s'", value)}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		problem.Suggestion = fmt.Sprintf("add a unit, e.g. '%ss'", value)
+	} else {
+		problem.Suggestion = "use a Go duration such as '500ms', '30s', '5m' or '1h30m'"
+	}
+	*problems = append(*problems, problem)
+}
+
+// sortedKeys returns the keys of an object in order, so problems are reported
+// in a stable order
+func sortedKeys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// closestName returns the candidate nearest to name by edit distance, or "" when
+// none is close enough to be a likely typo
+func closestName(name string, candidates []string) string {
+	best, bestDistance := "", 0
+	for _, candidate := range candidates {
+		distance := editDistance(strings.ToLower(name), strings.ToLower(candidate))
+		if best == "" || distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	if best == "" || bestDistance > max(2, len(name)/3) {
+		return ""
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
@@ -2,9 +2,16 @@
 package config
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"os"
+	"path"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Constants for provider types
@@ -14,6 +21,12 @@ const (
 	ProviderAnthropic = "anthropic"
 )
 
+// Agent implementations
+const (
+	AgentTypeConversational = "conversational" // ReAct-style agent that writes tool calls as text
+	AgentTypeTools          = "tools"          // Agent built on the provider's native tool calling
+)
+
 // Observability Providers
 const (
 	ObservabilityProviderSimple   = "simple-otel"
@@ -21,10 +34,211 @@ const (
 	ObservabilityProviderDisabled = "disabled"
 )
 
+// Chat platforms the bot can serve
+const (
+	FrontendSlack      = "slack"
+	FrontendDiscord    = "discord"
+	FrontendTeams      = "teams"
+	FrontendMattermost = "mattermost"
+)
+
+// Slack event delivery modes
+const (
+	SlackModeSocket = "socket"
+	SlackModeHTTP   = "http"
+)
+
+// Retention of intermediate agent messages once the answer is posted
+const (
+	IntermediateKeep     = "keep"
+	IntermediateDelete   = "delete"
+	IntermediateCollapse = "collapse"
+)
+
+// Conversation types, as reported by Slack's conversations.info
+const (
+	ConversationIM      = "im"
+	ConversationMPIM    = "mpim"
+	ConversationPrivate = "private_channel"
+	ConversationPublic  = "public_channel"
+)
+
+// Which messages the bot answers in a type of conversation
+const (
+	ConversationReplyAll      = "all"      // Every message
+	ConversationReplyMentions = "mentions" // Messages that mention the bot
+	ConversationReplyOff      = "off"      // None
+)
+
+// What an edit of the user's latest prompt does
+const (
+	EditedPromptsIgnore   = "ignore"
+	EditedPromptsReanswer = "reanswer"
+)
+
+// Quick actions run by reacting to one of the bot's answers
+const (
+	ReactionActionRegenerate = "regenerate"
+	ReactionActionExpand     = "expand"
+	ReactionActionCanvas     = "canvas"
+	ReactionActionPrompt     = "prompt"
+)
+
+// Kinds of errors users are told about, keys of slack.errorMessages
+const (
+	ErrorKindToolTimeout       = "tool_timeout"
+	ErrorKindServerUnavailable = "server_unavailable"
+	ErrorKindToolFailed        = "tool_failed"
+	ErrorKindLLMRateLimited    = "llm_rate_limited"
+	ErrorKindLLMTimeout        = "llm_timeout"
+	ErrorKindLLMAuth           = "llm_auth"
+	ErrorKindLLMContextLength  = "llm_context_length"
+	ErrorKindLLMUnavailable    = "llm_unavailable"
+	ErrorKindInternal          = "internal"
+)
+
+// ErrorKinds lists the kinds of errors users are told about
+var ErrorKinds = []string{
+	ErrorKindToolTimeout, ErrorKindServerUnavailable, ErrorKindToolFailed,
+	ErrorKindLLMRateLimited, ErrorKindLLMTimeout, ErrorKindLLMAuth, ErrorKindLLMContextLength, ErrorKindLLMUnavailable,
+	ErrorKindInternal,
+}
+
+// Types of Slack answer post-processors
+const (
+	PostProcessorAppend       = "append"
+	PostProcessorStrip        = "strip"
+	PostProcessorRewriteLinks = "rewriteLinks"
+)
+
+// Model routes chosen per message when LLM routing is enabled
+const (
+	RouteCheap    = "cheap"
+	RoutePowerful = "powerful"
+)
+
+// Runtimes that launch a stdio MCP server
+const (
+	RuntimeProcess = "process"
+	RuntimeDocker  = "docker"
+)
+
+// Built-in MCP servers that run inside the client
+const (
+	BuiltinFilesystem = "filesystem"
+	BuiltinFetch      = "fetch"
+	BuiltinTime       = "time"
+	BuiltinCalculator = "calculator"
+	BuiltinIssues     = "issues"
+)
+
+// Issue trackers of the built-in issues server
+const (
+	IssueProviderJira   = "jira"
+	IssueProviderGitHub = "github"
+)
+
+// TransportBuiltin is the transport of built-in MCP servers, which are called in process
+const TransportBuiltin = "builtin"
+
+// EnvMCPServersJSON names the environment variable holding inline MCP server
+// definitions, for deployments configured without a config file
+const EnvMCPServersJSON = "MCP_SERVERS_JSON"
+
+// Weekdays maps the day names used in quiet hours to weekdays
+var Weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// MaxAssistantSuggestedPrompts is the number of suggested prompts Slack shows in an assistant thread
+const MaxAssistantSuggestedPrompts = 4
+
+// Event de-duplication providers
+const (
+	DedupeProviderMemory = "memory"
+	DedupeProviderRedis  = "redis"
+)
+
+// Moderation providers
+const (
+	ModerationProviderOpenAI = "openai"
+	ModerationProviderLocal  = "local"
+)
+
+// Moderation actions taken when content is flagged
+const (
+	ModerationActionBlock    = "block"
+	ModerationActionFlag     = "flag"
+	ModerationActionAnnotate = "annotate"
+)
+
+// Grounding check actions taken when an answer is not supported by its sources
+const (
+	GroundingActionWarn       = "warn"
+	GroundingActionRegenerate = "regenerate"
+)
+
+// Tool name collision strategies
+const (
+	ToolCollisionPrefix   = "prefix"
+	ToolCollisionPriority = "priority"
+	ToolCollisionError    = "error"
+)
+
+// RAG retrieval modes
+const (
+	RAGSearchKeyword = "keyword"
+	RAGSearchVector  = "vector"
+	RAGSearchHybrid  = "hybrid"
+)
+
+// RAG rerank providers
+const (
+	RAGRerankLLM          = "llm"
+	RAGRerankCrossEncoder = "crossEncoder"
+)
+
+// RAG chunking strategies and size units
+const (
+	RAGChunkRecursive = "recursive"
+	RAGChunkSentence  = "sentence"
+	RAGChunkMarkdown  = "markdown"
+	RAGChunkSemantic  = "semantic"
+
+	RAGChunkUnitCharacters = "characters"
+	RAGChunkUnitTokens     = "tokens"
+)
+
+// RAG-first answer classifiers
+const (
+	RAGAnswerClassifierRetrieval = "retrieval"
+	RAGAnswerClassifierLLM       = "llm"
+)
+
+// Intents of a message, which decide what the LLM is offered to answer it
+const (
+	IntentChat  = "chat"  // Plain chat, answered without tools
+	IntentRAG   = "rag"   // Knowledge base question, answered with the knowledge base search only
+	IntentTools = "tools" // Everything else, answered with every tool
+
+	IntentClassifierHeuristic = "heuristic"
+	IntentClassifierLLM       = "llm"
+)
+
 // Config represents the main application configuration
 type Config struct {
 	Version        string                     `json:"version"`
+	Frontend       string                     `json:"frontend,omitempty"` // Chat platform: "slack", "discord", "teams" or "mattermost" (default: slack)
 	Slack          SlackConfig                `json:"slack"`
+	Discord        DiscordConfig              `json:"discord,omitempty"`    // Discord bot settings, used when frontend is "discord"
+	Teams          TeamsConfig                `json:"teams,omitempty"`      // Microsoft Teams bot settings, used when frontend is "teams"
+	Mattermost     MattermostConfig           `json:"mattermost,omitempty"` // Mattermost bot settings, used when frontend is "mattermost"
 	LLM            LLMConfig                  `json:"llm"`
 	MCPServers     map[string]MCPServerConfig `json:"mcpServers"`
 	RAG            RAGConfig                  `json:"rag,omitempty"`
@@ -34,15 +248,410 @@ type Config struct {
 	Retry          RetryConfig                `json:"retry,omitempty"`
 	Reload         ReloadConfig               `json:"reload,omitempty"`
 	Observability  ObservabilityConfig        `json:"observability,omitempty"`
+	Dedupe         DedupeConfig               `json:"dedupe,omitempty"`
+	Credentials    CredentialsConfig          `json:"credentials,omitempty"`
+	Moderation     ModerationConfig           `json:"moderation,omitempty"`
+	MCPStartup     MCPStartupConfig           `json:"mcpStartup,omitempty"`
+	MCPConnections MCPConnectionsConfig       `json:"mcpConnections,omitempty"`    // Connection pooling and reconnects for sse and http MCP servers
+	MCPCircuit     MCPCircuitBreakerConfig    `json:"mcpCircuitBreaker,omitempty"` // Stop calling MCP servers that keep being unreachable
+	MCPAlerts      MCPAlertsConfig            `json:"mcpAlerts,omitempty"`         // Alerts about MCP servers that are down
+	SelfTest       SelfTestConfig             `json:"selfTest,omitempty"`          // Summary posted once the bot has started
+	ToolCollision  ToolCollisionConfig        `json:"toolCollision,omitempty"`
+	Maintenance    MaintenanceConfig          `json:"maintenance,omitempty"`    // Maintenance mode and quiet hours
+	OnCall         OnCallConfig               `json:"onCall,omitempty"`         // Who-is-on-call lookups through PagerDuty or Opsgenie
+	WASMTools      map[string]WASMToolConfig  `json:"wasmTools,omitempty"`      // Pure-compute tools run as sandboxed WebAssembly modules, by tool name
+	HTTPTools      map[string]HTTPToolConfig  `json:"httpTools,omitempty"`      // REST endpoints exposed as tools, by tool name
+	OpenAPI        map[string]OpenAPIConfig   `json:"openApi,omitempty"`        // OpenAPI documents whose selected operations become HTTP tools, by API name
+	Middlewares    []MiddlewareConfig         `json:"middlewares,omitempty"`    // Hooks around tool calls and LLM calls, outermost first
+	Hooks          []HookConfig               `json:"hooks,omitempty"`          // External executables or webhooks that allow, deny or modify messages, tool calls and responses
+	Experiments    []ExperimentConfig         `json:"experiments,omitempty"`    // A/B experiments that answer a share of conversations with another system prompt or model
+	SLO            SLOConfig                  `json:"slo,omitempty"`            // Service level objectives tracked over a sliding window, with alerts
+	Memory         MemoryConfig               `json:"memory,omitempty"`         // Long-term facts about users and teams learned from conversations
+	Jobs           JobsConfig                 `json:"jobs,omitempty"`           // Tool calls the LLM schedules to run later
+	Network        NetworkConfig              `json:"network,omitempty"`        // Proxy and TLS settings for outbound connections
 	UseStdIOClient bool                       `json:"useStdIOClient,omitempty"` // Use terminal client instead of a real slack bot, for local development
 }
 
+// NetworkConfig sets the proxy and trusted certificates used by every outbound
+// connection: Slack, LLM providers, MCP servers over SSE/HTTP and RAG providers
+type NetworkConfig struct {
+	ProxyURL           string   `json:"proxyUrl,omitempty"`           // http://, https:// or socks5:// proxy (default: HTTP_PROXY, HTTPS_PROXY and NO_PROXY)
+	NoProxy            []string `json:"noProxy,omitempty"`            // Hosts, domains (".example.com") and CIDRs reached without proxyUrl
+	CABundle           string   `json:"caBundle,omitempty"`           // PEM file of CA certificates trusted in addition to the system roots
+	InsecureSkipVerify []string `json:"insecureSkipVerify,omitempty"` // Hosts whose TLS certificates are not verified; for testing only
+}
+
+// MiddlewareConfig enables a middleware registered under Name, either a built-in
+// one (redaction, cache, audit, rbac) or one compiled in from a Go plugin package
+type MiddlewareConfig struct {
+	Name     string                 `json:"name"`               // Registered middleware name
+	Disabled bool                   `json:"disabled,omitempty"` // Keep the entry but skip it
+	Config   map[string]interface{} `json:"config,omitempty"`   // Middleware-specific settings
+}
+
+// HookConfig runs an executable or calls a webhook with a JSON payload at the
+// events it subscribes to. Its JSON answer allows, denies or modifies the message,
+// tool call or response.
+type HookConfig struct {
+	Name    string            `json:"name"`              // Name used in logs and denial messages
+	Events  []string          `json:"events"`            // "message_received", "before_tool_call", "after_response"
+	Command string            `json:"command,omitempty"` // Executable that reads the payload on stdin and writes the decision on stdout
+	Args    []string          `json:"args,omitempty"`    // Arguments of the executable
+	URL     string            `json:"url,omitempty"`     // Webhook the payload is POSTed to
+	Headers map[string]string `json:"headers,omitempty"` // Extra webhook request headers, e.g. Authorization
+	Timeout string            `json:"timeout,omitempty"` // How long the hook may take (default: "5s")
+	OnError string            `json:"onError,omitempty"` // "allow" or "deny" when the hook fails (default: "allow")
+}
+
+// ExperimentConfig sends a share of conversations to a variant with another system
+// prompt or model. The other conversations form the control arm, which keeps the
+// configuration as it is.
+type ExperimentConfig struct {
+	Name     string                   `json:"name"`               // Name reported in traces and metrics
+	Percent  int                      `json:"percent"`            // Share of conversations, 0-100, answered by the variant
+	Channels []string                 `json:"channels,omitempty"` // Channel IDs the experiment runs in (default: all)
+	Disabled bool                     `json:"disabled,omitempty"` // Keep the entry but stop assigning conversations to it
+	Variant  ExperimentVariantConfig  `json:"variant"`            // What the variant changes
+	Rollback ExperimentRollbackConfig `json:"rollback,omitempty"` // Automatic rollback of a variant that does worse than allowed
+}
+
+// ExperimentRollbackConfig rolls an experiment's variant back to the control arm,
+// the configuration as it was, when its answers get too much negative feedback or
+// fail too often within the window, and alerts an admin channel
+type ExperimentRollbackConfig struct {
+	Enabled         bool    `json:"enabled,omitempty"`         // Watch the variant and roll it back (default: false)
+	Window          string  `json:"window,omitempty"`          // Period the rates are measured over (default: "1h")
+	MinSamples      int     `json:"minSamples,omitempty"`      // Answers, or feedback reactions, in the window before a rate is judged (default: 10)
+	MaxNegativeRate float64 `json:"maxNegativeRate,omitempty"` // Highest share of negative feedback on the variant's answers (default: 0.5)
+	MaxErrorRate    float64 `json:"maxErrorRate,omitempty"`    // Highest share of the variant's prompts that fail (default: 0.2)
+	AdminChannel    string  `json:"adminChannel,omitempty"`    // Channel ID alerted about rollbacks (default: none, only logged)
+	StorePath       string  `json:"storePath,omitempty"`       // JSON file the rollback persists to, so it lasts until the variant is changed; empty keeps it in memory (default: "")
+}
+
+// SLOConfig tracks the bot's service level objectives over a sliding window: the
+// p95 latency of answers, the share of prompts that fail and the share of tool
+// calls that fail. Each objective has an error budget, the share of answers or
+// calls allowed to miss it, and alerts fire when the budget burns too fast.
+type SLOConfig struct {
+	Enabled         bool    `json:"enabled,omitempty"`         // Track the objectives (default: false)
+	Window          string  `json:"window,omitempty"`          // Period the objectives are measured over (default: "1h")
+	MinSamples      int     `json:"minSamples,omitempty"`      // Answers, or tool calls, in the window before an objective is judged (default: 20)
+	LatencyP95      string  `json:"latencyP95,omitempty"`      // Time 95% of answers complete within (default: "60s")
+	ErrorRate       float64 `json:"errorRate,omitempty"`       // Share of prompts allowed to fail (default: 0.05)
+	ToolFailureRate float64 `json:"toolFailureRate,omitempty"` // Share of tool calls allowed to fail (default: 0.1)
+	BurnRate        float64 `json:"burnRate,omitempty"`        // Alert when a budget is spent this many times faster than allowed (default: 2)
+	AlertChannel    string  `json:"alertChannel,omitempty"`    // Channel ID alerts are posted to (default: none, only logged)
+}
+
+// ExperimentVariantConfig is what the variant arm of an experiment changes
+type ExperimentVariantConfig struct {
+	CustomPrompt string `json:"customPrompt,omitempty"` // System prompt (default: llm.customPrompt)
+	Provider     string `json:"provider,omitempty"`     // Key of llm.providers (default: the provider chosen for the message)
+	Model        string `json:"model,omitempty"`        // Model name (default: the provider's configured model)
+}
+
+// Hook events
+const (
+	HookEventMessageReceived = "message_received"
+	HookEventBeforeToolCall  = "before_tool_call"
+	HookEventAfterResponse   = "after_response"
+)
+
+// Hook failure handling
+const (
+	HookOnErrorAllow = "allow"
+	HookOnErrorDeny  = "deny"
+)
+
+// DiscordConfig connects the bot to Discord servers through the Gateway. Replies
+// to messages in server channels go to a thread started on the message.
+type DiscordConfig struct {
+	BotToken       string `json:"botToken,omitempty"`       // Bot token (default: DISCORD_BOT_TOKEN)
+	APIURL         string `json:"apiUrl,omitempty"`         // REST API base URL (default: https://discord.com/api/v10)
+	GatewayURL     string `json:"gatewayUrl,omitempty"`     // Gateway URL (default: asked from the REST API)
+	ReplyInChannel bool   `json:"replyInChannel,omitempty"` // Reply in the channel instead of starting threads; replies in existing threads stay there
+}
+
+// TeamsConfig connects the bot to Microsoft Teams through the Bot Framework.
+// Teams posts activities to the messaging endpoint, and replies go to the
+// Bot Connector service the activity came from.
+type TeamsConfig struct {
+	AppID             string `json:"appId,omitempty"`             // Microsoft App ID of the Azure Bot (default: TEAMS_APP_ID)
+	AppPassword       string `json:"appPassword,omitempty"`       // Client secret of the app (default: TEAMS_APP_PASSWORD)
+	TenantID          string `json:"tenantId,omitempty"`          // Tenant of a single-tenant app (default: botframework.com, for multi-tenant apps)
+	ListenAddr        string `json:"listenAddr,omitempty"`        // Address of the messaging endpoint (default: :3978)
+	MessagesPath      string `json:"messagesPath,omitempty"`      // Path of the messaging endpoint (default: /api/messages)
+	OpenIDMetadataURL string `json:"openIdMetadataUrl,omitempty"` // Where the keys of Bot Framework tokens are published (default: https://login.botframework.com/v1/.well-known/openidconfiguration)
+	TokenURL          string `json:"tokenUrl,omitempty"`          // OAuth token endpoint (default: https://login.microsoftonline.com/<tenantId>/oauth2/v2.0/token)
+}
+
+// MattermostConfig connects the bot to a self-hosted Mattermost server. Events
+// arrive over the WebSocket API and replies are posted with the REST API.
+type MattermostConfig struct {
+	URL      string `json:"url,omitempty"`      // Server URL, such as https://chat.example.com (default: MATTERMOST_URL)
+	BotToken string `json:"botToken,omitempty"` // Access token of the bot account (default: MATTERMOST_BOT_TOKEN)
+}
+
 // SlackConfig contains Slack-specific configuration
 type SlackConfig struct {
-	BotToken        string `json:"botToken"`
-	AppToken        string `json:"appToken"`
-	MessageHistory  int    `json:"messageHistory,omitempty"`  // Max messages to keep in history per channel (default: 50)
-	ThinkingMessage string `json:"thinkingMessage,omitempty"` // Custom "thinking" message (default: "Thinking...")
+	BotToken             string                          `json:"botToken"`
+	AppToken             string                          `json:"appToken"`
+	MessageHistory       int                             `json:"messageHistory,omitempty"`       // Max messages to keep in history per channel (default: 50)
+	HistoryTokens        SlackHistoryTokensConfig        `json:"historyTokens,omitempty"`        // Token budget of the history per thread, evicting tool results first
+	ToolHistory          SlackToolHistoryConfig          `json:"toolHistory,omitempty"`          // How tool results are kept in the history
+	ThinkingMessage      string                          `json:"thinkingMessage,omitempty"`      // Custom "thinking" message (default: "Thinking...")
+	ProgressUpdates      *bool                           `json:"progressUpdates,omitempty"`      // Edit the thinking message with progress, then into the answer (default: true)
+	Outbound             SlackOutboundConfig             `json:"outbound,omitempty"`             // Outbound message queue and retry settings
+	Mode                 string                          `json:"mode,omitempty"`                 // Event delivery: "socket" or "http" (default: "socket")
+	SigningSecret        string                          `json:"signingSecret,omitempty"`        // Signing secret used to verify Events API requests (http mode)
+	HTTP                 SlackHTTPConfig                 `json:"http,omitempty"`                 // Events API listener settings (http mode)
+	Assistant            SlackAssistantConfig            `json:"assistant,omitempty"`            // Slack AI app (Assistant) surface
+	IntermediateMessages SlackIntermediateConfig         `json:"intermediateMessages,omitempty"` // What happens to agent steps once the answer is posted
+	EditedPrompts        string                          `json:"editedPrompts,omitempty"`        // Edits of the user's latest prompt: "ignore" or "reanswer" (default: "ignore")
+	RetryWithEdit        bool                            `json:"retryWithEdit,omitempty"`        // Post a button under answers that re-runs the prompt after editing it (default: false)
+	Scratchpad           SlackScratchpadConfig           `json:"scratchpad,omitempty"`           // Stored agent reasoning per thread, shown by a "Show work" button
+	FeedbackReactions    SlackFeedbackReactionsConfig    `json:"feedbackReactions,omitempty"`    // Reactions on answers counted as feedback for experiments
+	ReactionActions      map[string]SlackReactionAction  `json:"reactionActions,omitempty"`      // Reaction name -> quick action run when a user reacts to an answer
+	ErrorMessages        map[string]string               `json:"errorMessages,omitempty"`        // Error kind -> message shown to users instead of the built-in one; {tool}, {server}, {provider} and {timeout} are filled in
+	Conversations        SlackConversationsConfig        `json:"conversations,omitempty"`        // Which messages are answered per conversation type
+	Listeners            map[string]SlackListenerConfig  `json:"listeners,omitempty"`            // Channels, by ID, where matching messages are answered without a mention
+	Digests              []SlackDigestConfig             `json:"digests,omitempty"`              // Scheduled channel summaries
+	Incidents            SlackIncidentsConfig            `json:"incidents,omitempty"`            // Incident channel assistant mode
+	SlashCommand         string                          `json:"slashCommand,omitempty"`         // Slash command configured in the Slack app, e.g. "/mcp memory list" (default: "/mcp")
+	Commands             map[string]SlackCommandTemplate `json:"commands,omitempty"`             // Subcommand -> tool call it runs without the LLM, e.g. "deploy-status" for "/mcp deploy-status <service>"
+	PostProcessors       []SlackPostProcessorConfig      `json:"postProcessors,omitempty"`       // Edits applied, in order, to the bot's messages before they are posted
+	Workers              SlackWorkersConfig              `json:"workers,omitempty"`              // How many prompts are answered at once, and what users hear when all workers are busy
+	WorkflowStep         SlackWorkflowStepConfig         `json:"workflowStep,omitempty"`         // "Ask MCP" custom step for Workflow Builder
+}
+
+// SlackWorkflowStepConfig exposes the bot as a custom step of Workflow Builder. The
+// step posts its prompt in a channel, answers it in the thread and returns the
+// answer as an output variable of the workflow.
+type SlackWorkflowStepConfig struct {
+	Enabled    bool   `json:"enabled,omitempty"`    // Answer executions of the custom function (default: false)
+	CallbackID string `json:"callbackId,omitempty"` // Callback ID of the function in the app manifest (default: "ask_mcp")
+}
+
+// SlackWorkersConfig limits how many prompts are answered at once. Prompts that
+// arrive while every worker is busy wait in line, and the user is told right away
+// that their prompt will be answered shortly.
+type SlackWorkersConfig struct {
+	MaxConcurrent int    `json:"maxConcurrent,omitempty"` // Prompts answered at once, -1 for no limit (default: 10)
+	MaxQueued     int    `json:"maxQueued,omitempty"`     // Prompts waiting for a worker before new ones are turned away, -1 for no limit (default: 100)
+	BusyMessage   string `json:"busyMessage,omitempty"`   // Reply to a prompt that waits for a worker, removed once it is picked up where messages can be deleted (default: "I'm handling a lot right now, I'll get to this shortly.")
+	FullMessage   string `json:"fullMessage,omitempty"`   // Reply to a prompt turned away because the line is full (default: "I'm too busy to take this on right now. Please try again in a few minutes.")
+}
+
+// SlackPostProcessorConfig edits answers before they are posted, for example to
+// add a compliance disclaimer or send links through a proxy
+type SlackPostProcessorConfig struct {
+	Type        string   `json:"type"`                  // "append", "strip" or "rewriteLinks"
+	Channels    []string `json:"channels,omitempty"`    // Channel IDs the processor applies to (default: all channels)
+	Text        string   `json:"text,omitempty"`        // append: text added below the answer
+	Pattern     string   `json:"pattern,omitempty"`     // strip: regular expression removed from answers; rewriteLinks: only links matching it are rewritten (default: every link)
+	Replacement string   `json:"replacement,omitempty"` // strip: text that replaces each match (default: "")
+	Template    string   `json:"template,omitempty"`    // rewriteLinks: new link, with {url} replaced by the URL-encoded original
+}
+
+// AppliesTo reports whether the post-processor applies to answers in the channel
+func (p SlackPostProcessorConfig) AppliesTo(channelID string) bool {
+	return len(p.Channels) == 0 || slices.Contains(p.Channels, channelID)
+}
+
+// SlackHistoryTokensConfig limits the conversation history kept per thread by its
+// size in tokens, since a few large tool results fill a model's context long
+// before messageHistory is reached. Over budget, the oldest tool results are
+// evicted first, then the oldest messages.
+type SlackHistoryTokensConfig struct {
+	MaxTokens   int                `json:"maxTokens,omitempty"`   // Weighted tokens kept per thread; -1 disables the budget (default: 8000)
+	RoleWeights map[string]float64 `json:"roleWeights,omitempty"` // Multiplier of the tokens of each role: "user", "assistant" or "tool" (default: 1)
+}
+
+// Weight returns the multiplier of the tokens of messages with role
+func (h SlackHistoryTokensConfig) Weight(role string) float64 {
+	if weight, ok := h.RoleWeights[role]; ok {
+		return weight
+	}
+	return 1
+}
+
+// SlackToolHistoryConfig sets how tool results are kept in the conversation
+// history. Each result is stored with the tool's name and a hash of its
+// arguments, and a long one is truncated: the model can fetch the full result
+// with the get_tool_result tool while it stays in the history.
+//
+// With DiffRepeated, a result is compared with the result of the previous call of
+// the tool with the same arguments in the thread, for monitoring-style questions
+// such as "how many pods are crashlooping now?". The LLM is asked to highlight
+// what changed, and the raw diff is attached to the answer.
+type SlackToolHistoryConfig struct {
+	MaxResultChars int  `json:"maxResultChars,omitempty"` // Characters of a result kept in the history; -1 keeps whole results (default: 4000)
+	DiffRepeated   bool `json:"diffRepeated,omitempty"`   // Diff repeated tool calls against the previous result (default: false)
+	MaxDiffLines   int  `json:"maxDiffLines,omitempty"`   // Changed lines of the diff attached to the answer (default: 40)
+}
+
+// SlackScratchpadConfig keeps the thoughts, tool calls and tool results of agent
+// runs per thread. A "Show work" button under answers displays them, and follow-up
+// prompts in the thread build on them.
+type SlackScratchpadConfig struct {
+	Enabled    bool   `json:"enabled,omitempty"`    // Record agent scratchpads and offer the button (default: false)
+	StorePath  string `json:"storePath,omitempty"`  // JSON file the scratchpads persist to; empty keeps them in memory (default: "")
+	MaxThreads int    `json:"maxThreads,omitempty"` // Threads kept, least recently active dropped first (default: 500)
+	Resume     *bool  `json:"resume,omitempty"`     // Give follow-up prompts the thread's latest scratchpad (default: true)
+}
+
+// Resumes reports whether follow-up prompts build on the thread's latest scratchpad
+func (s SlackScratchpadConfig) Resumes() bool {
+	return s.Resume == nil || *s.Resume
+}
+
+// SlackFeedbackReactionsConfig lists the reactions on the bot's answers that count
+// as positive or negative feedback
+type SlackFeedbackReactionsConfig struct {
+	Positive []string `json:"positive,omitempty"` // Reaction names (default: "+1", "thumbsup", "white_check_mark", "heart")
+	Negative []string `json:"negative,omitempty"` // Reaction names (default: "-1", "thumbsdown", "x")
+}
+
+// SlackReactionAction is a quick action run when a user reacts to one of the bot's
+// answers, such as answering again on 🔁 or saving the answer to a canvas on 📌
+type SlackReactionAction struct {
+	Action string `json:"action"`           // "regenerate", "expand", "canvas" or "prompt"
+	Prompt string `json:"prompt,omitempty"` // Follow-up prompt of "prompt" and "expand" (default for "expand": ask for more detail)
+}
+
+// SlackCommandTemplate maps a subcommand of the slash command to a tool call, which
+// runs right away without the LLM, for quick lookups that always call the same tool
+type SlackCommandTemplate struct {
+	Tool        string                 `json:"tool"`                  // Tool called, by the name the LLM sees
+	Params      []string               `json:"params,omitempty"`      // Names of the subcommand's arguments, in order; the last one takes the rest of the text
+	Args        map[string]interface{} `json:"args,omitempty"`        // Tool arguments; strings may reference params and context variables as "${name}"
+	Description string                 `json:"description,omitempty"` // Shown in the slash command's help
+}
+
+// ReservedSlackCommands are the subcommands of the slash command that command templates cannot replace
+var ReservedSlackCommands = []string{"help", "memory", "jobs"}
+
+// SlackCommandNamePattern matches the names of command templates and their params
+var SlackCommandNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// SlackIncidentsConfig configures incident mode: in an incident channel the bot
+// keeps a timeline of key messages, answers questions from the channel's
+// messages and drafts a postmortem on demand
+type SlackIncidentsConfig struct {
+	Enabled          bool     `json:"enabled,omitempty"`          // Accept the "incident" commands (default: false)
+	ChannelPattern   string   `json:"channelPattern,omitempty"`   // Regular expression of channel names that start incident mode automatically, e.g. "^inc-"
+	TimelineKeywords []string `json:"timelineKeywords,omitempty"` // Words that add a message to the timeline (default: declared, mitigated, resolved, rollback, ...)
+	TimelineReaction string   `json:"timelineReaction,omitempty"` // Reaction that adds a message to the timeline (default: "pushpin")
+	PostmortemPrompt string   `json:"postmortemPrompt,omitempty"` // Instructions for the postmortem draft (default: built-in)
+	MaxMessages      int      `json:"maxMessages,omitempty"`      // Most recent channel messages read for the postmortem (default: 1000)
+}
+
+// SlackDigestConfig posts a summary of a channel's recent messages on a schedule
+type SlackDigestConfig struct {
+	Channel     string `json:"channel"`               // Channel ID to summarize
+	Schedule    string `json:"schedule"`              // Cron expression (minute hour day month weekday) or @daily, @weekly
+	Timezone    string `json:"timezone,omitempty"`    // IANA time zone of the schedule (default: "UTC")
+	Hours       int    `json:"hours,omitempty"`       // Summarize the last N hours (default: 24)
+	PostTo      string `json:"postTo,omitempty"`      // Channel ID the digest is posted to (default: the summarized channel)
+	Canvas      bool   `json:"canvas,omitempty"`      // Write the digest to a canvas and post a link to it (default: false)
+	Prompt      string `json:"prompt,omitempty"`      // Instructions for the summary (default: built-in)
+	MaxMessages int    `json:"maxMessages,omitempty"` // Most recent messages summarized (default: 500)
+}
+
+// SlackListenerConfig makes the bot answer messages in a channel that match any
+// trigger, without an @mention
+type SlackListenerConfig struct {
+	Keywords  []string `json:"keywords,omitempty"`  // Words that trigger an answer, matched case-insensitively as whole words
+	Questions bool     `json:"questions,omitempty"` // Answer messages that end with a question mark
+	Patterns  []string `json:"patterns,omitempty"`  // Regular expressions that trigger an answer
+	Cooldown  string   `json:"cooldown,omitempty"`  // Minimum time between triggered answers in the channel (default: "2m")
+}
+
+// SlackConversationsConfig sets which messages the bot answers in each type of
+// conversation: "all", "mentions" or "off"
+type SlackConversationsConfig struct {
+	DirectMessages  string `json:"directMessages,omitempty"`  // Direct messages with the bot (default: "all")
+	GroupMessages   string `json:"groupMessages,omitempty"`   // Group DMs (default: "mentions")
+	PrivateChannels string `json:"privateChannels,omitempty"` // Private channels (default: "mentions")
+	PublicChannels  string `json:"publicChannels,omitempty"`  // Public channels (default: "mentions")
+	CacheTTL        string `json:"cacheTtl,omitempty"`        // How long resolved conversation types are cached (default: "1h")
+}
+
+// ReplyMode returns which messages are answered in a type of conversation
+func (c *SlackConversationsConfig) ReplyMode(conversationType string) string {
+	var mode string
+	switch conversationType {
+	case ConversationIM:
+		mode = c.DirectMessages
+		if mode == "" {
+			return ConversationReplyAll
+		}
+	case ConversationMPIM:
+		mode = c.GroupMessages
+	case ConversationPrivate:
+		mode = c.PrivateChannels
+	default:
+		mode = c.PublicChannels
+	}
+	if mode == "" {
+		return ConversationReplyMentions
+	}
+	return mode
+}
+
+// SlackIntermediateConfig controls the intermediate messages posted by agent mode
+type SlackIntermediateConfig struct {
+	Retention string            `json:"retention,omitempty"` // "keep", "delete" or "collapse" (default: "keep")
+	Channels  map[string]string `json:"channels,omitempty"`  // Retention by channel ID, overriding the default
+}
+
+// RetentionForChannel returns the retention of intermediate messages in the channel
+func (c *SlackIntermediateConfig) RetentionForChannel(channelID string) string {
+	if retention, ok := c.Channels[channelID]; ok && retention != "" {
+		return retention
+	}
+	if c.Retention == "" {
+		return IntermediateKeep
+	}
+	return c.Retention
+}
+
+// SlackAssistantConfig contains settings for the Slack Assistant (AI apps) surface
+type SlackAssistantConfig struct {
+	Enabled          bool                   `json:"enabled,omitempty"`          // Handle assistant threads (default: false)
+	PromptsTitle     string                 `json:"promptsTitle,omitempty"`     // Heading shown above suggested prompts (default: "Try asking")
+	SuggestedPrompts []SlackAssistantPrompt `json:"suggestedPrompts,omitempty"` // Prompts offered when a thread starts (at most 4)
+	ThinkingStatus   string                 `json:"thinkingStatus,omitempty"`   // Thread status while the LLM works (default: "is thinking...")
+	SearchingStatus  string                 `json:"searchingStatus,omitempty"`  // Thread status while the knowledge base is searched (default: "is searching the knowledge base...")
+	ToolStatus       string                 `json:"toolStatus,omitempty"`       // Thread status while a tool runs, %s is the tool name (default: "is running %s...")
+}
+
+// SlackAssistantPrompt is a suggested prompt shown in a new assistant thread
+type SlackAssistantPrompt struct {
+	Title   string `json:"title"`   // Label of the prompt button
+	Message string `json:"message"` // Message sent when the prompt is clicked
+}
+
+// ProgressUpdatesEnabled reports whether the thinking message is edited into the answer
+func (c SlackConfig) ProgressUpdatesEnabled() bool {
+	return c.ProgressUpdates == nil || *c.ProgressUpdates
+}
+
+// SlackHTTPConfig contains settings for receiving events over the HTTP Events API
+type SlackHTTPConfig struct {
+	ListenAddr        string `json:"listenAddr,omitempty"`        // Address the events listener binds to (default: ":3000")
+	EventsPath        string `json:"eventsPath,omitempty"`        // Request URL path configured in the Slack app (default: "/slack/events")
+	InteractivityPath string `json:"interactivityPath,omitempty"` // Interactivity Request URL path, for buttons such as cancelling a slow tool call (default: "/slack/interactive")
+	CommandsPath      string `json:"commandsPath,omitempty"`      // Request URL path of the slash command (default: "/slack/commands")
+}
+
+// SlackOutboundConfig contains settings for the outbound message queue
+type SlackOutboundConfig struct {
+	QueueSize      int    `json:"queueSize,omitempty"`      // Pending messages buffered before senders block (default: 100)
+	MaxAttempts    int    `json:"maxAttempts,omitempty"`    // Delivery attempts per message (default: 5)
+	BaseBackoff    string `json:"baseBackoff,omitempty"`    // Backoff used when Slack sends no Retry-After (default: "1s")
+	MaxBackoff     string `json:"maxBackoff,omitempty"`     // Maximum backoff between attempts (default: "30s")
+	DeadLetterFile string `json:"deadLetterFile,omitempty"` // JSON-lines file for undeliverable messages (default: log only)
 }
 
 // LLMConfig contains LLM provider configuration
@@ -50,13 +659,125 @@ type LLMConfig struct {
 	Provider           string                       `json:"provider"`
 	UseNativeTools     bool                         `json:"useNativeTools,omitempty"`
 	UseAgent           bool                         `json:"useAgent,omitempty"`
+	StructuredOutput   bool                         `json:"structuredOutput,omitempty"` // Request schema-constrained JSON for internal steps (default: false)
 	CustomPrompt       string                       `json:"customPrompt,omitempty"`
 	CustomPromptFile   string                       `json:"customPromptFile,omitempty"`
 	ReplaceToolPrompt  bool                         `json:"replaceToolPrompt,omitempty"`
+	AgentType          string                       `json:"agentType,omitempty"`          // Agent implementation: "conversational" or "tools" (default: "conversational")
 	MaxAgentIterations int                          `json:"maxAgentIterations,omitempty"` // Maximum agent iterations (default: 20)
+	AgentBudget        AgentBudgetConfig            `json:"agentBudget,omitempty"`        // Per-interaction limits of the agent
+	FewShot            FewShotConfig                `json:"fewShot,omitempty"`            // Example tool calls included in the tool prompt
+	ToolSelection      ToolSelectionConfig          `json:"toolSelection,omitempty"`      // Embedding-based pre-filter of tools sent to the LLM
+	Routing            LLMRoutingConfig             `json:"routing,omitempty"`            // Per-message choice between a cheap and a powerful model
+	ThreadAffinity     LLMThreadAffinityConfig      `json:"threadAffinity,omitempty"`     // Keep each thread on the provider and model that first answered it
+	Grounding          LLMGroundingConfig           `json:"grounding,omitempty"`          // Check that answers are supported by the retrieved sources before posting
+	Intent             LLMIntentConfig              `json:"intent,omitempty"`             // Classify messages as chat, knowledge base questions or tool requests before prompting
 	Providers          map[string]LLMProviderConfig `json:"providers"`
 }
 
+// FewShotConfig controls the example tool calls shown to non-native-tool providers
+type FewShotConfig struct {
+	Learn      bool `json:"learn,omitempty"`      // Learn examples from successful tool calls (default: false)
+	MaxPerTool int  `json:"maxPerTool,omitempty"` // Maximum learned examples shown per tool (default: 2)
+}
+
+// ToolSelectionConfig controls the embedding-based tool pre-filter, which sends only
+// the tools most relevant to the user's message to the LLM
+type ToolSelectionConfig struct {
+	Enabled       bool           `json:"enabled,omitempty"`       // Enable tool pre-filtering (default: false)
+	TopK          int            `json:"topK,omitempty"`          // Number of tools sent to the LLM (default: 10)
+	Provider      string         `json:"provider,omitempty"`      // Embedding provider: "openai" or "ollama" (default: "ollama" for the ollama LLM provider, otherwise "openai")
+	Model         string         `json:"model,omitempty"`         // Embedding model (default: "text-embedding-3-small", or "nomic-embed-text" for ollama)
+	AlwaysInclude []string       `json:"alwaysInclude,omitempty"` // Tools that are always sent
+	ChannelTopK   map[string]int `json:"channelTopK,omitempty"`   // Channel ID -> top K for that channel (0 sends every tool)
+}
+
+// TopKForChannel returns the number of tools to send for a channel; 0 means every tool
+func (t *ToolSelectionConfig) TopKForChannel(channelID string) int {
+	if topK, ok := t.ChannelTopK[channelID]; ok {
+		return topK
+	}
+	return t.TopK
+}
+
+// LLMRoutingConfig sends trivial prompts to a cheap, fast model and the rest to a
+// powerful one. A prompt goes to the powerful model when it is long, looks like it
+// needs a tool, or comes from a channel routed to it.
+type LLMRoutingConfig struct {
+	Enabled            bool              `json:"enabled,omitempty"`            // Enable model routing (default: false)
+	Cheap              LLMRouteConfig    `json:"cheap,omitempty"`              // Model for short prompts without tool intent
+	Powerful           LLMRouteConfig    `json:"powerful,omitempty"`           // Model for everything else
+	MaxCheapLength     int               `json:"maxCheapLength,omitempty"`     // Longest prompt, in characters, sent to the cheap model (default: 200)
+	ToolIntentKeywords []string          `json:"toolIntentKeywords,omitempty"` // Words that signal a tool is needed (default: search, find, look up, list, create, update, delete, run, check)
+	Channels           map[string]string `json:"channels,omitempty"`           // Channel ID -> route ("cheap" or "powerful") that bypasses classification
+}
+
+// LLMThreadAffinityConfig keeps each thread on the provider and model that first
+// answered it, unless that provider is no longer available. Routing, experiments
+// and configuration changes then only apply to new threads. Users can switch the
+// model of a thread with the "model" command.
+type LLMThreadAffinityConfig struct {
+	Enabled    bool   `json:"enabled,omitempty"`    // Remember the model of each thread (default: false)
+	StorePath  string `json:"storePath,omitempty"`  // JSON file the thread models persist to; empty keeps them in memory (default: "")
+	MaxThreads int    `json:"maxThreads,omitempty"` // Threads remembered, least recently used dropped first (default: 10000)
+}
+
+// LLMGroundingConfig adds a second LLM call, usually to a cheaper model, that checks
+// whether an answer synthesized from tool results or knowledge base contexts is
+// supported by them. An ungrounded answer is posted with a warning, or regenerated
+// once with the unsupported claims pointed out.
+type LLMGroundingConfig struct {
+	Enabled         bool   `json:"enabled,omitempty"`         // Check answers before posting (default: false)
+	Provider        string `json:"provider,omitempty"`        // Key of llm.providers (default: the cheap route's provider when routing is enabled, otherwise llm.provider)
+	Model           string `json:"model,omitempty"`           // Model name (default: the cheap route's model when routing is enabled, otherwise the provider's configured model)
+	Action          string `json:"action,omitempty"`          // "warn" or "regenerate" (default: "warn")
+	Warning         string `json:"warning,omitempty"`         // Note appended to an ungrounded answer (default: built-in)
+	MaxSourceLength int    `json:"maxSourceLength,omitempty"` // Characters of sources given to the check, the rest is cut (default: 12000)
+}
+
+// LLMIntentConfig classifies each message before the tool prompt is built. Plain
+// chat such as "thanks!" is answered without the tool schemas, and knowledge base
+// questions with the knowledge base search only, which keeps their prompts small.
+type LLMIntentConfig struct {
+	Enabled     bool     `json:"enabled,omitempty"`     // Classify messages before prompting (default: false)
+	Classifier  string   `json:"classifier,omitempty"`  // "heuristic" (chat words and keywords) or "llm" (also ask a small model what the heuristic leaves open) (default: "heuristic")
+	Provider    string   `json:"provider,omitempty"`    // llm classifier: key of llm.providers (default: the cheap route's provider when routing is enabled, otherwise llm.provider)
+	Model       string   `json:"model,omitempty"`       // llm classifier: model name (default: the cheap route's model when routing is enabled, otherwise the provider's configured model)
+	Timeout     string   `json:"timeout,omitempty"`     // llm classifier: longest the classification may take before every tool is offered (default: "5s")
+	ChatWords   []string `json:"chatWords,omitempty"`   // Messages made only of these words and emoji are plain chat (default: thanks, hi, hello, bye, ...)
+	RAGKeywords []string `json:"ragKeywords,omitempty"` // Words that make a message a knowledge base question unless it names a tool (default: none)
+}
+
+// AgentBudgetConfig limits what a single agent interaction may spend. When a limit
+// is reached the agent stops and summarizes its partial progress instead of
+// iterating up to maxAgentIterations. Zero or empty values are unlimited.
+type AgentBudgetConfig struct {
+	MaxToolCalls int    `json:"maxToolCalls,omitempty"` // Tool calls per interaction (default: unlimited)
+	MaxTokens    int    `json:"maxTokens,omitempty"`    // Cumulative LLM tokens per interaction (default: unlimited)
+	MaxDuration  string `json:"maxDuration,omitempty"`  // Wall-clock time per interaction, e.g. "90s" (default: unlimited)
+}
+
+// Enabled reports whether any limit is set
+func (b AgentBudgetConfig) Enabled() bool {
+	return b.MaxToolCalls > 0 || b.MaxTokens > 0 || b.GetMaxDuration() > 0
+}
+
+// GetMaxDuration returns the wall-clock limit of an interaction, or 0 when unlimited
+func (b AgentBudgetConfig) GetMaxDuration() time.Duration {
+	return durationOr(b.MaxDuration, 0)
+}
+
+// LLMRouteConfig is the provider and model of a route
+type LLMRouteConfig struct {
+	Provider string `json:"provider,omitempty"` // Key of llm.providers (default: llm.provider)
+	Model    string `json:"model,omitempty"`    // Model name (default: the provider's configured model)
+}
+
+// RouteForChannel returns the route forced for a channel, or "" to classify the prompt
+func (r *LLMRoutingConfig) RouteForChannel(channelID string) string {
+	return r.Channels[channelID]
+}
+
 // LLMProviderConfig contains provider-specific settings
 type LLMProviderConfig struct {
 	Model       string  `json:"model"`
@@ -69,14 +790,152 @@ type LLMProviderConfig struct {
 // MCPServerConfig contains MCP server configuration
 type MCPServerConfig struct {
 	Command                  string            `json:"command,omitempty"`
+	Builtin                  string            `json:"builtin,omitempty"` // Built-in server to run in process: "filesystem", "fetch", "time", "calculator" or "issues"
 	Args                     []string          `json:"args,omitempty"`
 	URL                      string            `json:"url,omitempty"`
 	Transport                string            `json:"transport,omitempty"`
 	Env                      map[string]string `json:"env,omitempty"`
+	InheritEnv               *bool             `json:"inheritEnv,omitempty"`   // Pass this process's whole environment to a stdio server (default: true)
+	EnvAllowlist             []string          `json:"envAllowlist,omitempty"` // Variables passed when inheritEnv is false; "PREFIX_*" matches by prefix
+	Runtime                  string            `json:"runtime,omitempty"`      // "process" or "docker" (default: "process")
+	Docker                   MCPDockerConfig   `json:"docker,omitempty"`       // Container settings for the docker runtime
+	Restart                  MCPRestartConfig  `json:"restart,omitempty"`      // Restarting a stdio server whose process exits
 	HTTPHeaders              map[string]string `json:"httpHeaders,omitempty"`
 	Disabled                 bool              `json:"disabled,omitempty"`
 	InitializeTimeoutSeconds *int              `json:"initializeTimeoutSeconds,omitempty"`
 	Tools                    MCPToolsConfig    `json:"tools,omitempty"`
+	Identity                 MCPIdentityConfig `json:"identity,omitempty"` // Forward the requesting Slack user's identity (opt-in)
+	AuthMode                 string            `json:"authMode,omitempty"` // "shared" or "per-user" (default: "shared")
+	OAuth                    MCPOAuthConfig    `json:"oauth,omitempty"`    // OAuth client used to link user accounts (per-user auth mode)
+	Issues                   MCPIssuesConfig   `json:"issues,omitempty"`   // Issue tracker of the builtin "issues" server
+	TLS                      MCPTLSConfig      `json:"tls,omitempty"`      // Client certificate for mutual TLS with an sse or http server
+}
+
+// MCPTLSConfig is the client certificate presented to an MCP server that requires
+// mutual TLS. Give either certFile and keyFile, which are reloaded when they change
+// on disk, or the PEM contents in cert and key, usually as "${ENV_VAR}" references.
+type MCPTLSConfig struct {
+	CertFile string `json:"certFile,omitempty"` // PEM client certificate file, with any intermediates
+	KeyFile  string `json:"keyFile,omitempty"`  // PEM private key file of certFile
+	Cert     string `json:"cert,omitempty"`     // PEM client certificate, instead of certFile
+	Key      string `json:"key,omitempty"`      // PEM private key, instead of keyFile
+	CAFile   string `json:"caFile,omitempty"`   // PEM CA certificates that verify the server, in addition to network.caBundle
+}
+
+// Enabled reports whether a client certificate or server CA is configured
+func (t MCPTLSConfig) Enabled() bool {
+	return t != MCPTLSConfig{}
+}
+
+// MCPIssuesConfig configures the builtin "issues" server, whose create_issue tool
+// files a conversation summary as a Jira or GitHub issue. Title, Body, Labels and
+// string Fields are Go templates of .Title, .Summary, .Priority, .Date and
+// .Requester.
+type MCPIssuesConfig struct {
+	Provider   string                 `json:"provider,omitempty"`   // "jira" or "github"
+	URL        string                 `json:"url,omitempty"`        // Jira site (https://example.atlassian.net) or GitHub API URL (default: "https://api.github.com")
+	Token      string                 `json:"token,omitempty"`      // GitHub token or Jira API token
+	User       string                 `json:"user,omitempty"`       // Jira account email for basic auth; without it the token is sent as a bearer token
+	Project    string                 `json:"project,omitempty"`    // Jira project key
+	IssueType  string                 `json:"issueType,omitempty"`  // Jira issue type (default: "Task")
+	Repository string                 `json:"repository,omitempty"` // GitHub repository as owner/name
+	Title      string                 `json:"title,omitempty"`      // Issue title template (default: "{{.Title}}")
+	Body       string                 `json:"body,omitempty"`       // Issue description template (default: the summary and requester)
+	Labels     []string               `json:"labels,omitempty"`     // Label templates
+	Assignees  []string               `json:"assignees,omitempty"`  // GitHub logins assigned to the issue
+	Fields     map[string]interface{} `json:"fields,omitempty"`     // Extra Jira fields, such as {"priority": {"name": "{{.Priority}}"}}
+}
+
+// DefaultIssueBody is the issue description template when issues.body is not set
+const DefaultIssueBody = "{{.Summary}}\n\n---\nFiled from Slack{{if .Requester}} for {{.Requester}}{{end}} on {{.Date}}."
+
+// IsPerUserAuth reports whether the server uses each requesting user's own credentials
+func (mcp *MCPServerConfig) IsPerUserAuth() bool {
+	return mcp.AuthMode == AuthModePerUser
+}
+
+// MCPOAuthConfig describes the OAuth2 client used to obtain per-user tokens for a server
+type MCPOAuthConfig struct {
+	ClientID     string   `json:"clientId,omitempty"`
+	ClientSecret string   `json:"clientSecret,omitempty"`
+	AuthURL      string   `json:"authUrl,omitempty"`    // Provider authorization endpoint
+	TokenURL     string   `json:"tokenUrl,omitempty"`   // Provider token endpoint
+	Scopes       []string `json:"scopes,omitempty"`     // Requested scopes
+	HeaderName   string   `json:"headerName,omitempty"` // Header carrying the user's token (default: "Authorization")
+}
+
+// MCPDockerConfig describes the container a stdio server runs in with the docker runtime.
+// The server's command and args, if set, override the image's command.
+type MCPDockerConfig struct {
+	Image   string   `json:"image,omitempty"`   // Image to run (required)
+	Volumes []string `json:"volumes,omitempty"` // Bind mounts as "host:container[:ro]"
+	Network string   `json:"network,omitempty"` // Network the container joins, e.g. "none" (default: the engine's default network)
+	Pull    string   `json:"pull,omitempty"`    // "always", "missing" or "never" (default: "missing")
+	Binary  string   `json:"binary,omitempty"`  // Container CLI, e.g. "podman" (default: "docker")
+	RunArgs []string `json:"runArgs,omitempty"` // Extra "docker run" flags placed before the image
+}
+
+// MCPRestartConfig controls how a stdio server whose process exits is restarted
+type MCPRestartConfig struct {
+	Enabled        *bool  `json:"enabled,omitempty"`        // Restart the server when its process exits (default: true)
+	MaxAttempts    int    `json:"maxAttempts,omitempty"`    // Consecutive restarts before giving up (default: 5)
+	InitialBackoff string `json:"initialBackoff,omitempty"` // Delay before the first restart, doubled after each (default: "1s")
+	MaxBackoff     string `json:"maxBackoff,omitempty"`     // Longest delay between restarts (default: "1m")
+	StableAfter    string `json:"stableAfter,omitempty"`    // Uptime after which the consecutive restart count resets (default: "5m")
+	KillOnCancel   *bool  `json:"killOnCancel,omitempty"`   // Kill and restart the process when a tool call is cancelled or times out, so abandoned work stops; other calls in progress keep it running (default: false)
+}
+
+// GetMaxAttempts returns the consecutive restarts before giving up, with default fallback
+func (r MCPRestartConfig) GetMaxAttempts() int {
+	if r.MaxAttempts > 0 {
+		return r.MaxAttempts
+	}
+	return 5
+}
+
+// GetInitialBackoff returns the delay before the first restart, with default fallback
+func (r MCPRestartConfig) GetInitialBackoff() time.Duration {
+	return durationOr(r.InitialBackoff, time.Second)
+}
+
+// GetMaxBackoff returns the longest delay between restarts, with default fallback
+func (r MCPRestartConfig) GetMaxBackoff() time.Duration {
+	return durationOr(r.MaxBackoff, time.Minute)
+}
+
+// GetStableAfter returns the uptime that resets the restart count, with default fallback
+func (r MCPRestartConfig) GetStableAfter() time.Duration {
+	return durationOr(r.StableAfter, 5*time.Minute)
+}
+
+// durationOr parses a positive duration, returning fallback when it is unset or invalid
+func durationOr(value string, fallback time.Duration) time.Duration {
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return d
+	}
+	return fallback
+}
+
+// KillsOnCancel reports whether a restarted stdio server's process is killed when
+// a tool call is cancelled or times out
+func (r MCPRestartConfig) KillsOnCancel() bool {
+	return r.KillOnCancel != nil && *r.KillOnCancel
+}
+
+// RestartsOnExit reports whether a stdio server is restarted when its process exits
+func (mcp *MCPServerConfig) RestartsOnExit() bool {
+	return mcp.Restart.Enabled == nil || *mcp.Restart.Enabled
+}
+
+// IsDocker reports whether the server runs in a container
+func (mcp *MCPServerConfig) IsDocker() bool {
+	return mcp.Runtime == RuntimeDocker
+}
+
+// InheritsEnv reports whether a stdio server inherits this process's whole
+// environment rather than only the base variables and envAllowlist
+func (mcp *MCPServerConfig) InheritsEnv() bool {
+	return mcp.InheritEnv == nil || *mcp.InheritEnv
 }
 
 // GetTransport returns the transport type, inferring from other fields if not explicitly set
@@ -84,7 +943,10 @@ func (mcp *MCPServerConfig) GetTransport() string {
 	if mcp.Transport != "" {
 		return mcp.Transport
 	}
-	if mcp.Command != "" {
+	if mcp.Builtin != "" {
+		return TransportBuiltin
+	}
+	if mcp.Command != "" || mcp.IsDocker() {
 		return "stdio" // Default: if command is specified, use stdio
 
 	}
@@ -103,24 +965,277 @@ func (mcp *MCPServerConfig) GetInitializeTimeout() int {
 	return 30 // Default timeout: 30 seconds
 }
 
+// MCP server authentication modes
+const (
+	AuthModeShared  = "shared"
+	AuthModePerUser = "per-user"
+)
+
+// Identity propagation modes
+const (
+	IdentityModeMeta     = "meta"
+	IdentityModeArgument = "argument"
+	IdentityModeHeader   = "header"
+)
+
+// MCPIdentityConfig controls how the requesting Slack user's identity is passed to an MCP server.
+// Identity is never forwarded unless Enabled is set, and email only when IncludeEmail is also set.
+type MCPIdentityConfig struct {
+	Enabled      bool   `json:"enabled,omitempty"`      // Forward the Slack user ID (default: false)
+	IncludeEmail bool   `json:"includeEmail,omitempty"` // Also forward the user's email (default: false)
+	Mode         string `json:"mode,omitempty"`         // "meta", "argument" or "header" (default: "meta")
+	ArgumentName string `json:"argumentName,omitempty"` // Tool argument used in argument mode (default: "slack_user")
+	HeaderPrefix string `json:"headerPrefix,omitempty"` // Header prefix used in header mode (default: "X-Slack-User-")
+}
+
+// GetMode returns the identity propagation mode with default fallback
+func (i *MCPIdentityConfig) GetMode() string {
+	if i.Mode != "" {
+		return i.Mode
+	}
+	return IdentityModeMeta
+}
+
+// GetArgumentName returns the argument name with default fallback
+func (i *MCPIdentityConfig) GetArgumentName() string {
+	if i.ArgumentName != "" {
+		return i.ArgumentName
+	}
+	return "slack_user"
+}
+
+// GetHeaderPrefix returns the header prefix with default fallback
+func (i *MCPIdentityConfig) GetHeaderPrefix() string {
+	if i.HeaderPrefix != "" {
+		return i.HeaderPrefix
+	}
+	return "X-Slack-User-"
+}
+
 // MCPToolsConfig contains tool filtering configuration
 type MCPToolsConfig struct {
-	AllowList []string `json:"allowList,omitempty"`
-	BlockList []string `json:"blockList,omitempty"`
+	AllowList []string                   `json:"allowList,omitempty"`
+	BlockList []string                   `json:"blockList,omitempty"`
+	Overrides map[string]MCPToolOverride `json:"overrides,omitempty"` // Keyed by the tool name on the server
+	Timeout   string                     `json:"timeout,omitempty"`   // Longest a call to one of the server's tools may run (default: timeouts.toolProcessingTimeout)
+	Defaults  map[string]interface{}     `json:"defaults,omitempty"`  // Argument name -> value of the server's tools that declare the argument, when the LLM omits it; may reference context variables
+	Fixed     map[string]interface{}     `json:"fixed,omitempty"`     // Argument name -> value always sent to the server's tools that declare the argument, replacing the LLM's; may reference context variables
+}
+
+// MCPToolOverride enriches how a tool is presented to the LLM
+type MCPToolOverride struct {
+	Description string            `json:"description,omitempty"` // Replaces the server-provided description
+	Examples    []string          `json:"examples,omitempty"`    // Usage examples appended to the description
+	Arguments   map[string]string `json:"arguments,omitempty"`   // Argument name -> hint appended to the argument's schema description
+	FewShot     []ToolCallExample `json:"fewShot,omitempty"`     // Example invocations shown in the tool prompt
+	Timeout     string            `json:"timeout,omitempty"`     // Longest a call to this tool may run (default: the server's tools.timeout)
+	// Argument name -> value used when the LLM omits the argument. Strings may
+	// reference context variables, e.g. "${slack.channel_id}" or "${user.email}".
+	Defaults map[string]interface{} `json:"defaults,omitempty"`
+	// Argument name -> value always sent, replacing any value from the LLM. The
+	// argument is removed from the schema the LLM sees.
+	Fixed map[string]interface{} `json:"fixed,omitempty"`
+}
+
+// Context variables that tool argument defaults can reference as "${name}". They
+// are resolved when the tool is called.
+const (
+	ContextVarChannelID = "slack.channel_id" // Channel of the request
+	ContextVarThreadTS  = "slack.thread_ts"  // Thread of the request
+	ContextVarMessageTS = "slack.message_ts" // Message that made the request
+	ContextVarUserID    = "user.id"          // Requesting user's ID
+	ContextVarUserEmail = "user.email"       // Requesting user's email, when the frontend knows it
+	ContextVarUserName  = "user.name"        // Requesting user's real name
+	ContextVarNow       = "now"              // Time of the call, RFC 3339 in UTC
+	ContextVarToday     = "today"            // Date of the call, YYYY-MM-DD in UTC
+)
+
+// ContextVars lists the context variables
+var ContextVars = []string{
+	ContextVarChannelID, ContextVarThreadTS, ContextVarMessageTS,
+	ContextVarUserID, ContextVarUserEmail, ContextVarUserName,
+	ContextVarNow, ContextVarToday,
+}
+
+// ContextVarPattern matches a context variable reference and captures its name
+var ContextVarPattern = regexp.MustCompile(`\$\{([^{}]+)\}`)
+
+// ToolCallExample is an example user request and the tool arguments it should produce
+type ToolCallExample struct {
+	Request string                 `json:"request"`
+	Args    map[string]interface{} `json:"args"`
 }
 
 // RAGConfig contains RAG system configuration
 type RAGConfig struct {
 	Enabled   bool                         `json:"enabled,omitempty"`
 	Provider  string                       `json:"provider,omitempty"`
-	ChunkSize int                          `json:"chunkSize,omitempty"`
+	ChunkSize int                          `json:"chunkSize,omitempty"` // Chunk size in characters when chunking.size is not set (default: 1000)
+	Chunking  RAGChunkingConfig            `json:"chunking,omitempty"`  // How documents are split into chunks
+	Citations bool                         `json:"citations,omitempty"` // Append source references to replies that used rag_search (default: false)
+	Search    RAGSearchConfig              `json:"search,omitempty"`    // Retrieval mode for the simple provider
+	Rerank    RAGRerankConfig              `json:"rerank,omitempty"`    // Optional rerank stage after retrieval
+	Answer    RAGAnswerConfig              `json:"answer,omitempty"`    // RAG-first answers that skip the tool-call round trip
+	Web       RAGWebConfig                 `json:"web,omitempty"`       // Limits for rag_ingest_url
+	Ingest    RAGIngestConfig              `json:"ingest,omitempty"`    // Parallelism of directory ingestion
+	Freshness RAGFreshnessConfig           `json:"freshness,omitempty"` // Recency ranking and stale-source warnings
+	GC        RAGGCConfig                  `json:"gc,omitempty"`        // Scheduled cleanup of orphaned files in the openai provider
+	Sources   []RAGSourceConfig            `json:"sources,omitempty"`   // Confluence/Notion/Google Drive sources synced into the knowledge base
 	Providers map[string]RAGProviderConfig `json:"providers,omitempty"`
+
+	// Namespaces splits the knowledge base into named collections scoped to channels
+	Namespaces       map[string]RAGNamespaceConfig `json:"namespaces,omitempty"`
+	DefaultNamespace string                        `json:"defaultNamespace,omitempty"` // Namespace searched in unmapped channels (default: documents outside every namespace)
 }
 
+// RAGNamespaceConfig maps a knowledge base namespace to the channels that search it
+type RAGNamespaceConfig struct {
+	Channels []string `json:"channels,omitempty"` // Channel IDs whose searches are scoped to this namespace
+}
+
+// NamespaceForChannel returns the namespace searched in a channel, or "" for the
+// documents outside every namespace
+func (r *RAGConfig) NamespaceForChannel(channelID string) string {
+	for name, namespace := range r.Namespaces {
+		for _, channel := range namespace.Channels {
+			if channel == channelID {
+				return name
+			}
+		}
+	}
+	return r.DefaultNamespace
+}
+
+// RAGSearchConfig controls how the simple provider retrieves chunks
+type RAGSearchConfig struct {
+	Mode              string  `json:"mode,omitempty"`              // "keyword", "vector" or "hybrid" (default: "keyword")
+	VectorWeight      float64 `json:"vectorWeight,omitempty"`      // Weight of the vector score in hybrid mode, 0-1 (default: 0.5)
+	Candidates        int     `json:"candidates,omitempty"`        // Chunks retrieved by each method before scores are fused (default: 50)
+	EmbeddingProvider string  `json:"embeddingProvider,omitempty"` // "openai" or "ollama" (default: "ollama" for the ollama LLM provider, otherwise "openai")
+	EmbeddingModel    string  `json:"embeddingModel,omitempty"`    // Embedding model (default: "text-embedding-3-small", or "nomic-embed-text" for ollama)
+}
+
+// RAGRerankConfig controls the optional rerank stage applied to search results
+type RAGRerankConfig struct {
+	Provider   string `json:"provider,omitempty"`   // "llm" or "crossEncoder"; empty disables reranking
+	URL        string `json:"url,omitempty"`        // Cross-encoder: Cohere-compatible rerank endpoint
+	Model      string `json:"model,omitempty"`      // Cross-encoder: rerank model name
+	APIKey     string `json:"apiKey,omitempty"`     // Cross-encoder: API key sent as a bearer token
+	Candidates int    `json:"candidates,omitempty"` // Results retrieved for reranking (default: 20)
+	TopN       int    `json:"topN,omitempty"`       // Results kept after reranking (default: 5)
+}
+
+// RAGFreshnessConfig favours recently ingested chunks and warns when answers rely on
+// old ones. Both are disabled when unset.
+type RAGFreshnessConfig struct {
+	HalfLife   string `json:"halfLife,omitempty"`   // Age at which a chunk's search score is halved, e.g. "2160h" (default: no decay)
+	StaleAfter string `json:"staleAfter,omitempty"` // Age past which sources used in an answer trigger a freshness warning (default: no warning)
+}
+
+// GetHalfLife returns the score half-life, or 0 when scores do not decay
+func (f RAGFreshnessConfig) GetHalfLife() time.Duration {
+	return durationOr(f.HalfLife, 0)
+}
+
+// GetStaleAfter returns the age at which sources are stale, or 0 when answers are not checked
+func (f RAGFreshnessConfig) GetStaleAfter() time.Duration {
+	return durationOr(f.StaleAfter, 0)
+}
+
+// RAGIngestConfig controls how the files of a directory are ingested
+type RAGIngestConfig struct {
+	Workers int `json:"workers,omitempty"` // Files ingested at once, whose embeddings are batched together (default: 4)
+}
+
+// RAGGCConfig schedules the removal of orphaned files and failed vector store
+// entries, which the openai provider keeps (and bills) after failed ingests
+type RAGGCConfig struct {
+	Enabled  bool   `json:"enabled,omitempty"`  // Run the cleanup on a schedule (default: false)
+	Interval string `json:"interval,omitempty"` // Time between runs (default: "24h")
+	MinAge   string `json:"minAge,omitempty"`   // Unattached files younger than this are kept, as their ingest may still be running (default: "24h")
+}
+
+// GetInterval returns the time between cleanups
+func (g RAGGCConfig) GetInterval() time.Duration {
+	return durationOr(g.Interval, 24*time.Hour)
+}
+
+// GetMinAge returns the age under which unattached files are kept
+func (g RAGGCConfig) GetMinAge() time.Duration {
+	return durationOr(g.MinAge, 24*time.Hour)
+}
+
+// RAGChunkingConfig controls how ingested documents are split into chunks
+type RAGChunkingConfig struct {
+	Strategy             string  `json:"strategy,omitempty"`             // "recursive", "sentence", "markdown" or "semantic" (default: "recursive")
+	Size                 int     `json:"size,omitempty"`                 // Maximum chunk size in unit (default: chunkSize characters, or 256 tokens)
+	Overlap              int     `json:"overlap,omitempty"`              // Text repeated between consecutive chunks, in unit (default: 20% of size)
+	Unit                 string  `json:"unit,omitempty"`                 // "characters" or "tokens" (default: "characters")
+	BreakpointPercentile float64 `json:"breakpointPercentile,omitempty"` // Semantic: percentile of sentence distances that ends a chunk (default: 90)
+}
+
+// RAGAnswerConfig controls RAG-first mode: questions about the knowledge base are
+// answered from retrieved contexts in a single LLM call instead of through rag_search
+type RAGAnswerConfig struct {
+	Enabled    bool     `json:"enabled,omitempty"`
+	Channels   []string `json:"channels,omitempty"`   // Channel IDs using RAG-first answers; empty means every channel
+	Classifier string   `json:"classifier,omitempty"` // "retrieval" (search score only) or "llm" (also ask the LLM) (default: "retrieval")
+	MinScore   float64  `json:"minScore,omitempty"`   // Minimum score of the best search result; the scale depends on the search mode (default: 0)
+	MaxResults int      `json:"maxResults,omitempty"` // Contexts included in the answer prompt (default: 5)
+}
+
+// AppliesToChannel reports whether RAG-first answers are used in a channel
+func (a *RAGAnswerConfig) AppliesToChannel(channelID string) bool {
+	if !a.Enabled {
+		return false
+	}
+	if len(a.Channels) == 0 {
+		return true
+	}
+	for _, channel := range a.Channels {
+		if channel == channelID {
+			return true
+		}
+	}
+	return false
+}
+
+// RAGWebConfig limits web page ingestion
+type RAGWebConfig struct {
+	MaxDepth       int      `json:"maxDepth,omitempty"`       // Maximum crawl depth from the start page (default: 2)
+	MaxPages       int      `json:"maxPages,omitempty"`       // Maximum pages fetched per rag_ingest_url call (default: 50)
+	AllowedDomains []string `json:"allowedDomains,omitempty"` // Hosts (and subdomains) that may be fetched; rag_ingest_url is only offered to the LLM when set
+}
+
+// RAGSourceConfig configures a connector that keeps pages from an external source in sync
+type RAGSourceConfig struct {
+	Name      string `json:"name"`                // Unique name, stored with each synced page
+	Type      string `json:"type"`                // "confluence", "notion" or "googleDrive"
+	Interval  string `json:"interval,omitempty"`  // Time between syncs (default: "1h")
+	Namespace string `json:"namespace,omitempty"` // Knowledge base namespace for the synced pages
+	BaseURL   string `json:"baseUrl,omitempty"`   // Confluence: site URL, e.g. https://example.atlassian.net/wiki
+	Username  string `json:"username,omitempty"`  // Confluence Cloud: account email (basic auth); empty sends the token as a bearer token
+	APIToken  string `json:"apiToken,omitempty"`  // Confluence API token / personal access token, or Notion integration token
+
+	Spaces      []string `json:"spaces,omitempty"`      // Confluence: space keys to sync
+	DatabaseIDs []string `json:"databaseIds,omitempty"` // Notion: databases to sync; empty syncs every page shared with the integration
+
+	CredentialsFile string   `json:"credentialsFile,omitempty"` // Google Drive: service account key file (JSON)
+	Subject         string   `json:"subject,omitempty"`         // Google Drive: user to impersonate with domain-wide delegation
+	FolderIDs       []string `json:"folderIds,omitempty"`       // Google Drive: folders to sync, including their subfolders
+}
+
+// RAG source connector types
+const (
+	RAGSourceConfluence  = "confluence"
+	RAGSourceNotion      = "notion"
+	RAGSourceGoogleDrive = "googleDrive"
+)
+
 // RAGProviderConfig contains RAG provider-specific settings
 // TODO: Refactor this to use a common interface for all RAG providers, can use environment variables to configure the different providers
 type RAGProviderConfig struct {
-	DatabasePath             string  `json:"databasePath,omitempty"`             // Simple provider: path to JSON database
+	DatabasePath             string  `json:"databasePath,omitempty"`             // Simple provider: path to SQLite database (JSON databases are migrated)
 	IndexName                string  `json:"indexName,omitempty"`                // OpenAI provider: vector store name
 	VectorStoreID            string  `json:"vectorStoreId,omitempty"`            // OpenAI provider: existing vector store ID
 	Dimensions               int     `json:"dimensions,omitempty"`               // OpenAI provider: embedding dimensions
@@ -144,12 +1259,27 @@ type MonitoringConfig struct {
 type TimeoutConfig struct {
 	HTTPRequestTimeout     string `json:"httpRequestTimeout,omitempty"`     // HTTP client timeout (default: "30s")
 	MCPInitTimeout         string `json:"mcpInitTimeout,omitempty"`         // MCP client initialization (default: "30s")
-	ToolProcessingTimeout  string `json:"toolProcessingTimeout,omitempty"`  // Tool call processing (default: "3m")
+	ToolProcessingTimeout  string `json:"toolProcessingTimeout,omitempty"`  // Longest a tool call may run, unless its server or tool sets a timeout (default: "3m")
+	ToolNoticeAfter        string `json:"toolNoticeAfter,omitempty"`        // Running time after which users are told a tool call is still running and offered to cancel it (default: "20s")
 	BridgeOperationTimeout string `json:"bridgeOperationTimeout,omitempty"` // Bridge operation timeout (default: "3m")
 	PingTimeout            string `json:"pingTimeout,omitempty"`            // Health check ping timeout (default: "5s")
 	ResponseProcessing     string `json:"responseProcessing,omitempty"`     // Slack response processing (default: "1m")
 }
 
+// GetToolTimeout returns the longest a call to a server's tool may run: the tool's
+// override, else the server's tools.timeout, else timeouts.toolProcessingTimeout
+func (c *Config) GetToolTimeout(serverName, toolName string) time.Duration {
+	fallback := durationOr(c.Timeouts.ToolProcessingTimeout, 3*time.Minute)
+	serverConf, ok := c.MCPServers[serverName]
+	if !ok {
+		return fallback
+	}
+	if override, ok := serverConf.Tools.Overrides[toolName]; ok && override.Timeout != "" {
+		return durationOr(override.Timeout, fallback)
+	}
+	return durationOr(serverConf.Tools.Timeout, fallback)
+}
+
 // RetryConfig contains retry and resilience settings
 type RetryConfig struct {
 	MaxAttempts          int    `json:"maxAttempts,omitempty"`          // Max retry attempts (default: 3)
@@ -166,22 +1296,278 @@ type ReloadConfig struct {
 }
 
 type ObservabilityConfig struct {
-	Enabled        bool   `json:"enabled,omitempty"`
-	Provider       string `json:"provider,omitempty"`
-	Endpoint       string `json:"endpoint,omitempty"`
-	PublicKey      string `json:"publicKey,omitempty"`
-	SecretKey      string `json:"secretKey,omitempty"`
-	ServiceName    string `json:"serviceName,omitempty"`
-	ServiceVersion string `json:"serviceVersion,omitempty"`
+	Enabled        bool                        `json:"enabled,omitempty"`
+	Provider       string                      `json:"provider,omitempty"`
+	Endpoint       string                      `json:"endpoint,omitempty"`
+	PublicKey      string                      `json:"publicKey,omitempty"`
+	SecretKey      string                      `json:"secretKey,omitempty"`
+	ServiceName    string                      `json:"serviceName,omitempty"`
+	ServiceVersion string                      `json:"serviceVersion,omitempty"`
+	Sampling       ObservabilitySamplingConfig `json:"sampling,omitempty"` // Share of traces exported, to control volume and cost
+}
+
+// ObservabilitySamplingConfig decides at the start of each trace whether it is
+// exported. Traces that are not sampled can still be exported when they fail.
+type ObservabilitySamplingConfig struct {
+	Percent            *float64           `json:"percent,omitempty"`            // Share of traces exported, 0-100 (default: 100)
+	Channels           map[string]float64 `json:"channels,omitempty"`           // Percent per channel ID, overriding percent
+	AlwaysSampleErrors *bool              `json:"alwaysSampleErrors,omitempty"` // Export traces with an error even when they were not sampled (default: true)
+}
+
+// DedupeConfig contains Slack event de-duplication settings. Redelivered events
+// are always ignored in memory; enabling de-duplication selects the provider,
+// which for multiple replicas is Redis.
+type DedupeConfig struct {
+	Enabled    bool   `json:"enabled,omitempty"`    // Use the configured provider instead of the in-memory default (default: false)
+	Provider   string `json:"provider,omitempty"`   // Dedupe store: "memory" or "redis" (default: "memory")
+	RedisURL   string `json:"redisUrl,omitempty"`   // Redis connection URL (default: "redis://localhost:6379/0")
+	KeyPrefix  string `json:"keyPrefix,omitempty"`  // Key prefix for claimed event IDs (default: "slackmcp:event:")
+	TTL        string `json:"ttl,omitempty"`        // How long a claimed event ID is remembered (default: "10m")
+	MaxEntries int    `json:"maxEntries,omitempty"` // Event IDs the in-memory store keeps, least recently seen dropped first (default: 10000)
+	ReplicaID  string `json:"replicaId,omitempty"`  // Identifier of this replica used in metrics (default: hostname)
+}
+
+// CredentialsConfig contains settings for the per-user credential vault and OAuth linking flow
+type CredentialsConfig struct {
+	StorePath     string `json:"storePath,omitempty"`     // Encrypted token store (default: "./credentials.json")
+	EncryptionKey string `json:"encryptionKey,omitempty"` // Secret used to encrypt stored tokens (required for per-user auth)
+	CallbackURL   string `json:"callbackUrl,omitempty"`   // Public OAuth redirect URL, e.g. "https://bot.example.com/oauth/callback"
+	ListenAddr    string `json:"listenAddr,omitempty"`    // Address for the OAuth callback listener (default: ":8090")
+}
+
+// MCPStartupConfig controls how MCP servers are initialized at startup
+type MCPStartupConfig struct {
+	Async         *bool  `json:"async,omitempty"`         // Connect to Slack first and initialize servers in the background (default: true)
+	NotifyChannel string `json:"notifyChannel,omitempty"` // Channel ID notified when a server finishes or fails to initialize
+}
+
+// MCPCircuitBreakerConfig stops calling the tools of an MCP server after several
+// consecutive calls failed because the server could not be reached. While the
+// circuit is open calls fail at once; after openDuration one call is let through to
+// probe the server, and its success closes the circuit.
+type MCPCircuitBreakerConfig struct {
+	Disabled         bool   `json:"disabled,omitempty"`         // Keep calling unreachable servers
+	FailureThreshold int    `json:"failureThreshold,omitempty"` // Consecutive unreachable calls that open the circuit (default: 3)
+	OpenDuration     string `json:"openDuration,omitempty"`     // How long calls fail at once before a probe (default: "30s")
+}
+
+// MCPAlertsConfig posts an alert with the server name, the error and a suggested
+// fix to an admin channel when an MCP server fails to initialize, is given up on
+// after crashing, or its circuit breaker opens
+type MCPAlertsConfig struct {
+	Channel string `json:"channel,omitempty"` // Admin channel ID alerted about servers that are down
+	Mention string `json:"mention,omitempty"` // Mention prepended to alerts, e.g. "<!subteam^S0123>" or "<!here>"
+}
+
+// SelfTestConfig posts a self-test summary to an admin channel once the bot has
+// started and every MCP server finished initializing: the connected workspace, the
+// LLM providers validated, the MCP servers and tools discovered, and the size of the
+// knowledge base
+type SelfTestConfig struct {
+	Channel         string `json:"channel,omitempty"`         // Admin channel ID the summary is posted to (disabled when empty)
+	ProviderTimeout string `json:"providerTimeout,omitempty"` // Time allowed for each LLM provider to answer a test prompt (default: "30s")
+}
+
+// IsAsync reports whether MCP servers are initialized in the background
+func (m *MCPStartupConfig) IsAsync() bool {
+	return m.Async == nil || *m.Async
+}
+
+// MCPConnectionsConfig tunes the HTTP connections to sse and http MCP servers. All
+// servers share one connection pool, except servers presenting a client
+// certificate, which get their own pool with the same settings.
+type MCPConnectionsConfig struct {
+	MaxIdleConns        int                `json:"maxIdleConns,omitempty"`        // Idle connections kept open across all servers (default: 100)
+	MaxIdleConnsPerHost int                `json:"maxIdleConnsPerHost,omitempty"` // Idle connections kept open per server host (default: 10)
+	MaxConnsPerHost     int                `json:"maxConnsPerHost,omitempty"`     // Open connections per server host, including SSE streams; 0 is unlimited (default: 0)
+	IdleConnTimeout     string             `json:"idleConnTimeout,omitempty"`     // How long an idle connection is kept open (default: "90s")
+	KeepAliveInterval   string             `json:"keepAliveInterval,omitempty"`   // Interval of TCP keep-alive probes on open connections (default: "30s")
+	Reconnect           MCPReconnectConfig `json:"reconnect,omitempty"`           // Reconnecting to an sse server after a failed tool call
+}
+
+// GetMaxIdleConns returns the idle connections kept across all servers, with default fallback
+func (m MCPConnectionsConfig) GetMaxIdleConns() int {
+	if m.MaxIdleConns > 0 {
+		return m.MaxIdleConns
+	}
+	return 100
+}
+
+// GetMaxIdleConnsPerHost returns the idle connections kept per server host, with default fallback
+func (m MCPConnectionsConfig) GetMaxIdleConnsPerHost() int {
+	if m.MaxIdleConnsPerHost > 0 {
+		return m.MaxIdleConnsPerHost
+	}
+	return 10
+}
+
+// GetIdleConnTimeout returns how long idle connections are kept, with default fallback
+func (m MCPConnectionsConfig) GetIdleConnTimeout() time.Duration {
+	return durationOr(m.IdleConnTimeout, 90*time.Second)
+}
+
+// GetKeepAliveInterval returns the TCP keep-alive interval, with default fallback
+func (m MCPConnectionsConfig) GetKeepAliveInterval() time.Duration {
+	return durationOr(m.KeepAliveInterval, 30*time.Second)
+}
+
+// MCPReconnectConfig controls how a client reconnects to an sse server. Delays are
+// randomized so that clients losing the same server do not reconnect in lockstep.
+type MCPReconnectConfig struct {
+	MaxAttempts    int      `json:"maxAttempts,omitempty"`    // Attempts before the tool call fails (default: 5)
+	InitialBackoff string   `json:"initialBackoff,omitempty"` // Delay after the first failed attempt, doubled after each (default: "1s")
+	MaxBackoff     string   `json:"maxBackoff,omitempty"`     // Longest delay between attempts (default: "30s")
+	Jitter         *float64 `json:"jitter,omitempty"`         // Share of each delay that is random, 0-1; the first attempt also waits up to jitter × initialBackoff (default: 0.5)
+}
+
+// GetMaxAttempts returns the reconnect attempts, with default fallback
+func (r MCPReconnectConfig) GetMaxAttempts() int {
+	if r.MaxAttempts > 0 {
+		return r.MaxAttempts
+	}
+	return 5
+}
+
+// GetInitialBackoff returns the delay after the first failed attempt, with default fallback
+func (r MCPReconnectConfig) GetInitialBackoff() time.Duration {
+	return durationOr(r.InitialBackoff, time.Second)
+}
+
+// GetMaxBackoff returns the longest delay between attempts, with default fallback
+func (r MCPReconnectConfig) GetMaxBackoff() time.Duration {
+	return durationOr(r.MaxBackoff, 30*time.Second)
+}
+
+// GetJitter returns the random share of each delay, with default fallback
+func (r MCPReconnectConfig) GetJitter() float64 {
+	if r.Jitter != nil {
+		return *r.Jitter
+	}
+	return 0.5
+}
+
+// ToolCollisionConfig controls how tools with the same name on different servers are handled
+type ToolCollisionConfig struct {
+	Strategy string   `json:"strategy,omitempty"` // "prefix", "priority" or "error" (default: "prefix")
+	Priority []string `json:"priority,omitempty"` // Server names in order of preference (priority strategy)
+}
+
+// MaintenanceConfig controls when the bot is unavailable: maintenance mode,
+// toggled in the config or at runtime by an admin, and recurring quiet hours.
+// While unavailable the bot replies with a notice, or serves only admins.
+type MaintenanceConfig struct {
+	Enabled    bool               `json:"enabled,omitempty"`    // Start in maintenance mode (default: false)
+	Message    string             `json:"message,omitempty"`    // Notice sent during maintenance (default: "I'm down for maintenance right now. Please try again later.")
+	AdminsOnly bool               `json:"adminsOnly,omitempty"` // Let security.adminUsers keep using the bot while unavailable (default: false)
+	QuietHours []QuietHoursWindow `json:"quietHours,omitempty"` // Recurring windows during which the bot is unavailable
+}
+
+// MemoryConfig configures long-term memory: durable facts about users and teams,
+// such as who owns a service or when deploys happen, are extracted from the
+// messages the bot answers and given to the LLM as context in later conversations
+type MemoryConfig struct {
+	Enabled       bool   `json:"enabled,omitempty"`       // Extract and recall facts (default: false)
+	StorePath     string `json:"storePath,omitempty"`     // JSON file the facts persist to; empty keeps them in memory (default: "")
+	MaxFacts      int    `json:"maxFacts,omitempty"`      // Facts kept, oldest dropped first (default: 1000)
+	MaxRecalled   int    `json:"maxRecalled,omitempty"`   // Facts given to the LLM per request, most relevant first (default: 10)
+	ExtractPrompt string `json:"extractPrompt,omitempty"` // Instructions for extracting facts from a message (default: built-in)
+}
+
+// JobsConfig lets the LLM schedule a tool call for later, such as "run the backup
+// check at 2am and post the results here". Jobs run as the user who asked for them
+// and post their results in the thread they were scheduled from.
+type JobsConfig struct {
+	Enabled   bool   `json:"enabled,omitempty"`   // Offer the schedule_tool_call tool (default: false)
+	StorePath string `json:"storePath,omitempty"` // JSON file the jobs persist to; empty keeps them in memory (default: "")
+	MaxJobs   int    `json:"maxJobs,omitempty"`   // Jobs waiting to run at once (default: 100)
+	MaxDelay  string `json:"maxDelay,omitempty"`  // How far ahead a job can be scheduled (default: "720h")
+	Timezone  string `json:"timezone,omitempty"`  // IANA timezone of times of day such as "02:00" (default: "UTC")
+}
+
+// QuietHoursWindow is a recurring time window in a timezone. A window whose end
+// is before its start runs past midnight into the next day.
+type QuietHoursWindow struct {
+	Start    string   `json:"start"`              // Start time, "HH:MM"
+	End      string   `json:"end"`                // End time, "HH:MM"
+	Days     []string `json:"days,omitempty"`     // Days the window starts on: "mon" to "sun" (default: every day)
+	Timezone string   `json:"timezone,omitempty"` // IANA timezone, e.g. "Europe/Berlin" (default: "UTC")
+	Message  string   `json:"message,omitempty"`  // Notice sent during the window (default: "I'm off for quiet hours right now. Please try again later.")
+}
+
+// ModerationConfig contains content safety settings applied to user prompts and LLM outputs
+type ModerationConfig struct {
+	Enabled      bool                `json:"enabled,omitempty"`      // Enable content moderation (default: false)
+	Provider     string              `json:"provider,omitempty"`     // Classifier: "openai" or "local" (default: "openai")
+	Model        string              `json:"model,omitempty"`        // OpenAI moderation model (default: "omni-moderation-latest")
+	APIKey       string              `json:"apiKey,omitempty"`       // OpenAI API key (default: the openai LLM provider key)
+	BaseURL      string              `json:"baseUrl,omitempty"`      // OpenAI API base URL (default: "https://api.openai.com/v1")
+	Patterns     map[string][]string `json:"patterns,omitempty"`     // Local classifier: category -> case-insensitive regular expressions
+	CheckInput   *bool               `json:"checkInput,omitempty"`   // Check user prompts (default: true)
+	CheckOutput  *bool               `json:"checkOutput,omitempty"`  // Check LLM responses (default: true)
+	Action       string              `json:"action,omitempty"`       // "block", "flag" or "annotate" (default: "block")
+	BlockMessage string              `json:"blockMessage,omitempty"` // Reply used when content is blocked
+	AdminChannel string              `json:"adminChannel,omitempty"` // Channel ID notified about flagged content (required for "flag")
+}
+
+// On-call providers of the who_is_on_call tool
+const (
+	OnCallProviderPagerDuty = "pagerduty"
+	OnCallProviderOpsgenie  = "opsgenie"
+)
+
+// OnCallConfig configures the native who_is_on_call tool
+type OnCallConfig struct {
+	Enabled   bool              `json:"enabled,omitempty"`   // Offer the who_is_on_call tool (default: false)
+	Provider  string            `json:"provider,omitempty"`  // "pagerduty" or "opsgenie"
+	APIKey    string            `json:"apiKey,omitempty"`    // PagerDuty REST API key or Opsgenie API key
+	URL       string            `json:"url,omitempty"`       // API URL (default: "https://api.pagerduty.com" or "https://api.opsgenie.com")
+	Schedules map[string]string `json:"schedules,omitempty"` // Names users ask about -> PagerDuty schedule ID or Opsgenie schedule name
+	CacheTTL  string            `json:"cacheTtl,omitempty"`  // How long an answer is reused (default: "1m")
+}
+
+// WASMToolConfig defines a tool implemented by a WASI WebAssembly module. The
+// module reads the tool arguments as JSON on stdin and writes the result to
+// stdout; it has no filesystem, network or environment access.
+type WASMToolConfig struct {
+	Path        string                 `json:"path"`                  // Path of the .wasm module
+	Description string                 `json:"description"`           // Description shown to the LLM
+	InputSchema map[string]interface{} `json:"inputSchema,omitempty"` // JSON schema of the arguments (default: any object)
+	Timeout     string                 `json:"timeout,omitempty"`     // How long a call may run (default: "5s")
+	MaxMemoryMB int                    `json:"maxMemoryMb,omitempty"` // Memory limit of the module (default: 16)
+}
+
+// HTTPToolConfig declares a REST endpoint as a tool. Arguments named in {braces}
+// in the URL fill the path; the others are sent as query parameters for GET and
+// DELETE requests and as a JSON body otherwise.
+type HTTPToolConfig struct {
+	Description string                 `json:"description"`           // Description shown to the LLM
+	Method      string                 `json:"method,omitempty"`      // HTTP method (default: "GET")
+	URL         string                 `json:"url"`                   // URL template, e.g. "https://api.internal/users/{user_id}"
+	Headers     map[string]string      `json:"headers,omitempty"`     // Request headers, e.g. Authorization
+	InputSchema map[string]interface{} `json:"inputSchema,omitempty"` // JSON schema of the arguments (default: any object)
+	Query       []string               `json:"query,omitempty"`       // Arguments always sent as query parameters, also for requests with a body
+	Extract     string                 `json:"extract,omitempty"`     // JSONPath of the part of the response returned, e.g. "$.items[*].name"
+	Timeout     string                 `json:"timeout,omitempty"`     // Request timeout (default: "30s")
+}
+
+// OpenAPIConfig imports operations of an OpenAPI 3 document as HTTP tools
+type OpenAPIConfig struct {
+	Spec       string            `json:"spec"`                 // URL or file path of the document (JSON or YAML)
+	BaseURL    string            `json:"baseUrl,omitempty"`    // API base URL (default: the document's first server)
+	Operations []string          `json:"operations"`           // Allowlist of operationIds or "METHOD /path", as path.Match patterns, e.g. "get*"
+	Headers    map[string]string `json:"headers,omitempty"`    // Headers added to every call, e.g. Authorization
+	ToolPrefix string            `json:"toolPrefix,omitempty"` // Prefix of the tool names (default: the API name and "_")
+	Timeout    string            `json:"timeout,omitempty"`    // Request timeout (default: "30s")
 }
 
 // SecurityConfig contains security and access control settings
 type SecurityConfig struct {
 	Enabled          bool     `json:"enabled,omitempty"`          // Enable/disable security (default: false)
 	StrictMode       bool     `json:"strictMode,omitempty"`       // Require both user AND channel whitelisting (default: false)
-	AllowedUsers     []string `json:"allowedUsers,omitempty"`     // List of allowed user IDs
-	AllowedChannels  []string `json:"allowedChannels,omitempty"`  // List of allowed channel IDs
-	AdminUsers       []string `json:"adminUsers,omitempty"`       // List of admin user IDs
+	AllowedUsers     []string `json:"allowedUsers,omitempty"`     // Allowed user IDs, user group handles (@sre-team) and channels whose members are allowed (#sre-private)
+	AllowedChannels  []string `json:"allowedChannels,omitempty"`  // Allowed channel IDs and channel name patterns (#prod-*)
+	AdminUsers       []string `json:"adminUsers,omitempty"`       // Admin user IDs, user group handles and channels, as in allowedUsers
+	LookupCacheTTL   string   `json:"lookupCacheTtl,omitempty"`   // How long resolved user groups, channel names and members are cached (default: "10m")
 	RejectionMessage string   `json:"rejectionMessage,omitempty"` // Custom message for unauthorized users
 	LogUnauthorized  *bool    `json:"logUnauthorized,omitempty"`  // Log unauthorized access attempts (default: true when security enabled; nil = use default)
 
@@ -191,6 +1577,78 @@ type SecurityConfig struct {
 	adminUsersMap      map[string]struct{} `json:"-"`
 }
 
+// SecurityDirectory resolves the Slack names used in the security lists. Lookups
+// that fail report no match, so an unreachable Slack API denies rather than grants.
+type SecurityDirectory interface {
+	// IsUserGroupMember reports whether the user belongs to the user group with the handle
+	IsUserGroupMember(handle, userID string) bool
+	// IsChannelMember reports whether the user is a member of the named channel
+	IsChannelMember(channelName, userID string) bool
+	// ChannelName returns the name of a channel, or "" when it cannot be resolved
+	ChannelName(channelID string) string
+}
+
+// UsesSlackNames reports whether any security list refers to user groups or
+// channels by name, which requires a SecurityDirectory to resolve
+func (s *SecurityConfig) UsesSlackNames() bool {
+	for _, list := range [][]string{s.AllowedUsers, s.AllowedChannels, s.AdminUsers} {
+		for _, entry := range list {
+			if isSlackName(entry) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isSlackName reports whether a security list entry is a "@handle" or "#channel" name rather than an ID
+func isSlackName(entry string) bool {
+	return strings.HasPrefix(entry, "@") || strings.HasPrefix(entry, "#")
+}
+
+// ApplyMCPServersFromEnv adds the MCP servers defined in MCP_SERVERS_JSON, given
+// either as a map of servers or as {"mcpServers": {...}}. Servers in a config file
+// loaded afterwards replace the ones with the same name.
+func (c *Config) ApplyMCPServersFromEnv() error {
+	value := os.Getenv(EnvMCPServersJSON)
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	servers, err := parseMCPServersJSON(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", EnvMCPServersJSON, err)
+	}
+	if c.MCPServers == nil {
+		c.MCPServers = make(map[string]MCPServerConfig, len(servers))
+	}
+	for name, server := range servers {
+		c.MCPServers[name] = server
+	}
+	return nil
+}
+
+// parseMCPServersJSON strictly decodes inline MCP server definitions
+func parseMCPServersJSON(value string) (map[string]MCPServerConfig, error) {
+	data := mcpServersJSONBody([]byte(value))
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var servers map[string]MCPServerConfig
+	if err := dec.Decode(&servers); err != nil {
+		return nil, err
+	}
+	return servers, nil
+}
+
+// mcpServersJSONBody unwraps {"mcpServers": {...}} so both accepted shapes of
+// MCP_SERVERS_JSON decode as a map of servers
+func mcpServersJSONBody(data []byte) []byte {
+	var wrapper map[string]json.RawMessage
+	if err := json.Unmarshal(data, &wrapper); err == nil && len(wrapper) == 1 && wrapper["mcpServers"] != nil {
+		return wrapper["mcpServers"]
+	}
+	return data
+}
+
 // parseCommaSeparatedList parses a comma-separated string into a slice of trimmed, non-empty strings
 // This helper eliminates code duplication in environment variable parsing
 func parseCommaSeparatedList(value string) []string {
@@ -231,6 +1689,12 @@ func (s *SecurityConfig) buildLookupMaps() {
 	}
 }
 
+// IsSlackFrontend reports whether the bot serves Slack, which needs the Slack
+// tokens and settings
+func (c *Config) IsSlackFrontend() bool {
+	return c.Frontend == "" || c.Frontend == FrontendSlack
+}
+
 // ApplyDefaults applies default values to the configuration
 func (c *Config) ApplyDefaults() {
 	c.applyVersionDefaults()
@@ -243,6 +1707,22 @@ func (c *Config) ApplyDefaults() {
 	c.applyMonitoringDefaults()
 	c.applyMCPDefaults()
 	c.applyObservabilityDefaults()
+	c.applyDedupeDefaults()
+	c.applyCredentialsDefaults()
+	c.applyModerationDefaults()
+	c.applyOnCallDefaults()
+	c.applyHookDefaults()
+	c.applyWASMToolDefaults()
+	c.applyHTTPToolDefaults()
+	c.applyOpenAPIDefaults()
+	c.applyMCPStartupDefaults()
+	c.applySelfTestDefaults()
+	c.applyToolCollisionDefaults()
+	c.applyMaintenanceDefaults()
+	c.applyMemoryDefaults()
+	c.applyJobsDefaults()
+	c.applyExperimentDefaults()
+	c.applySLODefaults()
 }
 
 // applyVersionDefaults sets default version if not specified
@@ -250,16 +1730,92 @@ func (c *Config) applyVersionDefaults() {
 	if c.Version == "" {
 		c.Version = "2.0"
 	}
-}
+}
+
+// applyLLMDefaults sets default LLM provider and configurations
+func (c *Config) applyLLMDefaults() {
+	if c.LLM.Provider == "" {
+		c.LLM.Provider = ProviderOpenAI
+	}
+
+	if c.LLM.AgentType == "" {
+		c.LLM.AgentType = AgentTypeConversational
+	}
+
+	if c.LLM.MaxAgentIterations <= 0 || c.LLM.MaxAgentIterations > 100 {
+		c.LLM.MaxAgentIterations = 20
+	}
+
+	if c.LLM.FewShot.MaxPerTool <= 0 {
+		c.LLM.FewShot.MaxPerTool = 2
+	}
+
+	if c.LLM.ToolSelection.TopK <= 0 {
+		c.LLM.ToolSelection.TopK = 10
+	}
+	if c.LLM.ToolSelection.Provider == "" {
+		c.LLM.ToolSelection.Provider = c.defaultEmbeddingProvider()
+	}
+	if c.LLM.ToolSelection.Model == "" {
+		c.LLM.ToolSelection.Model = defaultEmbeddingModel(c.LLM.ToolSelection.Provider)
+	}
+
+	if c.LLM.ThreadAffinity.MaxThreads == 0 {
+		c.LLM.ThreadAffinity.MaxThreads = 10000
+	}
+
+	if c.LLM.Routing.MaxCheapLength <= 0 {
+		c.LLM.Routing.MaxCheapLength = 200
+	}
+	if c.LLM.Routing.ToolIntentKeywords == nil {
+		c.LLM.Routing.ToolIntentKeywords = []string{"search", "find", "look up", "list", "create", "update", "delete", "run", "check"}
+	}
+	if c.LLM.Routing.Cheap.Provider == "" {
+		c.LLM.Routing.Cheap.Provider = c.LLM.Provider
+	}
+	if c.LLM.Routing.Powerful.Provider == "" {
+		c.LLM.Routing.Powerful.Provider = c.LLM.Provider
+	}
 
-// applyLLMDefaults sets default LLM provider and configurations
-func (c *Config) applyLLMDefaults() {
-	if c.LLM.Provider == "" {
-		c.LLM.Provider = ProviderOpenAI
+	if c.LLM.Intent.Classifier == "" {
+		c.LLM.Intent.Classifier = IntentClassifierHeuristic
+	}
+	if c.LLM.Intent.Provider == "" {
+		if c.LLM.Routing.Enabled {
+			c.LLM.Intent.Provider = c.LLM.Routing.Cheap.Provider
+			if c.LLM.Intent.Model == "" {
+				c.LLM.Intent.Model = c.LLM.Routing.Cheap.Model
+			}
+		} else {
+			c.LLM.Intent.Provider = c.LLM.Provider
+		}
+	}
+	if c.LLM.Intent.Timeout == "" {
+		c.LLM.Intent.Timeout = "5s"
+	}
+	if c.LLM.Intent.ChatWords == nil {
+		c.LLM.Intent.ChatWords = []string{"thanks", "thank", "you", "thx", "ty", "cheers", "hi", "hello", "hey", "bye",
+			"goodbye", "good", "morning", "afternoon", "evening", "night", "great", "nice", "cool", "awesome", "perfect", "lol"}
 	}
 
-	if c.LLM.MaxAgentIterations <= 0 || c.LLM.MaxAgentIterations > 100 {
-		c.LLM.MaxAgentIterations = 20
+	if c.LLM.Grounding.Provider == "" {
+		if c.LLM.Routing.Enabled {
+			c.LLM.Grounding.Provider = c.LLM.Routing.Cheap.Provider
+			if c.LLM.Grounding.Model == "" {
+				c.LLM.Grounding.Model = c.LLM.Routing.Cheap.Model
+			}
+		} else {
+			c.LLM.Grounding.Provider = c.LLM.Provider
+		}
+	}
+	if c.LLM.Grounding.Action == "" {
+		c.LLM.Grounding.Action = GroundingActionWarn
+	}
+	if c.LLM.Grounding.Warning == "" {
+		c.LLM.Grounding.Warning = "⚠️ Parts of this answer may not be supported by the sources I found. Please double-check them."
+	}
+	if c.LLM.Grounding.MaxSourceLength <= 0 {
+		c.LLM.Grounding.MaxSourceLength = 12000
 	}
 
 	// Ensure providers map exists
@@ -299,6 +1855,59 @@ func (c *Config) applyRAGDefaults() {
 	if c.RAG.ChunkSize == 0 {
 		c.RAG.ChunkSize = 1000
 	}
+	if c.RAG.Chunking.Strategy == "" {
+		c.RAG.Chunking.Strategy = RAGChunkRecursive
+	}
+	if c.RAG.Chunking.Unit == "" {
+		c.RAG.Chunking.Unit = RAGChunkUnitCharacters
+	}
+	if c.RAG.Search.Mode == "" {
+		c.RAG.Search.Mode = RAGSearchKeyword
+	}
+	if c.RAG.Search.VectorWeight == 0 {
+		c.RAG.Search.VectorWeight = 0.5
+	}
+	if c.RAG.Search.Candidates <= 0 {
+		c.RAG.Search.Candidates = 50
+	}
+	if c.RAG.Search.EmbeddingProvider == "" {
+		c.RAG.Search.EmbeddingProvider = c.defaultEmbeddingProvider()
+	}
+	if c.RAG.Search.EmbeddingModel == "" {
+		c.RAG.Search.EmbeddingModel = defaultEmbeddingModel(c.RAG.Search.EmbeddingProvider)
+	}
+	if c.RAG.Rerank.Candidates <= 0 {
+		c.RAG.Rerank.Candidates = 20
+	}
+	if c.RAG.Rerank.TopN <= 0 {
+		c.RAG.Rerank.TopN = 5
+	}
+	if c.RAG.Answer.Classifier == "" {
+		c.RAG.Answer.Classifier = RAGAnswerClassifierRetrieval
+	}
+	if c.RAG.Answer.MaxResults <= 0 {
+		c.RAG.Answer.MaxResults = 5
+	}
+	if c.RAG.Web.MaxDepth <= 0 {
+		c.RAG.Web.MaxDepth = 2
+	}
+	if c.RAG.Web.MaxPages <= 0 {
+		c.RAG.Web.MaxPages = 50
+	}
+	if c.RAG.Ingest.Workers <= 0 {
+		c.RAG.Ingest.Workers = 4
+	}
+	if c.RAG.GC.Interval == "" {
+		c.RAG.GC.Interval = "24h"
+	}
+	if c.RAG.GC.MinAge == "" {
+		c.RAG.GC.MinAge = "24h"
+	}
+	for i := range c.RAG.Sources {
+		if c.RAG.Sources[i].Interval == "" {
+			c.RAG.Sources[i].Interval = "1h"
+		}
+	}
 	if c.RAG.Providers == nil {
 		c.RAG.Providers = make(map[string]RAGProviderConfig)
 	}
@@ -315,14 +1924,186 @@ func (c *Config) applyRAGDefaults() {
 	}
 }
 
+// defaultEmbeddingProvider returns the embedding provider matching the LLM provider,
+// falling back to OpenAI for providers without an embeddings API
+func (c *Config) defaultEmbeddingProvider() string {
+	if c.LLM.Provider == ProviderOllama {
+		return ProviderOllama
+	}
+	return ProviderOpenAI
+}
+
+// defaultEmbeddingModel returns the default embedding model for a provider
+func defaultEmbeddingModel(provider string) string {
+	if provider == ProviderOllama {
+		return "nomic-embed-text"
+	}
+	return "text-embedding-3-small"
+}
+
 // applySlackDefaults sets default Slack configuration
 func (c *Config) applySlackDefaults() {
 	if c.Slack.MessageHistory == 0 {
 		c.Slack.MessageHistory = 50
 	}
+	if c.Slack.HistoryTokens.MaxTokens == 0 {
+		c.Slack.HistoryTokens.MaxTokens = 8000
+	}
+	if c.Slack.ToolHistory.MaxResultChars == 0 {
+		c.Slack.ToolHistory.MaxResultChars = 4000
+	}
+	if c.Slack.ToolHistory.MaxDiffLines == 0 {
+		c.Slack.ToolHistory.MaxDiffLines = 40
+	}
 	if c.Slack.ThinkingMessage == "" {
 		c.Slack.ThinkingMessage = "Thinking..."
 	}
+	if c.Slack.Workers.MaxConcurrent == 0 {
+		c.Slack.Workers.MaxConcurrent = 10
+	}
+	if c.Slack.Workers.MaxQueued == 0 {
+		c.Slack.Workers.MaxQueued = 100
+	}
+	if c.Slack.Workers.BusyMessage == "" {
+		c.Slack.Workers.BusyMessage = "I'm handling a lot right now, I'll get to this shortly."
+	}
+	if c.Slack.Workers.FullMessage == "" {
+		c.Slack.Workers.FullMessage = "I'm too busy to take this on right now. Please try again in a few minutes."
+	}
+	if c.Slack.WorkflowStep.CallbackID == "" {
+		c.Slack.WorkflowStep.CallbackID = "ask_mcp"
+	}
+	if c.Slack.Scratchpad.MaxThreads == 0 {
+		c.Slack.Scratchpad.MaxThreads = 500
+	}
+	if c.Slack.FeedbackReactions.Positive == nil {
+		c.Slack.FeedbackReactions.Positive = []string{"+1", "thumbsup", "white_check_mark", "heart"}
+	}
+	if c.Slack.FeedbackReactions.Negative == nil {
+		c.Slack.FeedbackReactions.Negative = []string{"-1", "thumbsdown", "x"}
+	}
+	for reaction, action := range c.Slack.ReactionActions {
+		if action.Action == ReactionActionExpand && action.Prompt == "" {
+			action.Prompt = "Expand on your previous answer with more detail, steps and examples."
+			c.Slack.ReactionActions[reaction] = action
+		}
+	}
+	if c.Frontend == "" {
+		c.Frontend = FrontendSlack
+	}
+	if c.Discord.APIURL == "" {
+		c.Discord.APIURL = "https://discord.com/api/v10"
+	}
+	if c.Teams.TenantID == "" {
+		c.Teams.TenantID = "botframework.com"
+	}
+	if c.Teams.ListenAddr == "" {
+		c.Teams.ListenAddr = ":3978"
+	}
+	if c.Teams.MessagesPath == "" {
+		c.Teams.MessagesPath = "/api/messages"
+	}
+	if c.Teams.OpenIDMetadataURL == "" {
+		c.Teams.OpenIDMetadataURL = "https://login.botframework.com/v1/.well-known/openidconfiguration"
+	}
+	if c.Teams.TokenURL == "" {
+		c.Teams.TokenURL = fmt.Sprintf("https://login.microsoftonline.com/%s/oauth2/v2.0/token", c.Teams.TenantID)
+	}
+	if c.Slack.Mode == "" {
+		c.Slack.Mode = SlackModeSocket
+	}
+	if c.Slack.HTTP.ListenAddr == "" {
+		c.Slack.HTTP.ListenAddr = ":3000"
+	}
+	if c.Slack.HTTP.EventsPath == "" {
+		c.Slack.HTTP.EventsPath = "/slack/events"
+	}
+	if c.Slack.HTTP.InteractivityPath == "" {
+		c.Slack.HTTP.InteractivityPath = "/slack/interactive"
+	}
+	if c.Slack.HTTP.CommandsPath == "" {
+		c.Slack.HTTP.CommandsPath = "/slack/commands"
+	}
+	if c.Slack.SlashCommand == "" {
+		c.Slack.SlashCommand = "/mcp"
+	}
+	if c.Slack.IntermediateMessages.Retention == "" {
+		c.Slack.IntermediateMessages.Retention = IntermediateKeep
+	}
+	if c.Slack.EditedPrompts == "" {
+		c.Slack.EditedPrompts = EditedPromptsIgnore
+	}
+	conversations := &c.Slack.Conversations
+	if conversations.DirectMessages == "" {
+		conversations.DirectMessages = ConversationReplyAll
+	}
+	if conversations.GroupMessages == "" {
+		conversations.GroupMessages = ConversationReplyMentions
+	}
+	if conversations.PrivateChannels == "" {
+		conversations.PrivateChannels = ConversationReplyMentions
+	}
+	if conversations.PublicChannels == "" {
+		conversations.PublicChannels = ConversationReplyMentions
+	}
+	if conversations.CacheTTL == "" {
+		conversations.CacheTTL = "1h"
+	}
+	incidents := &c.Slack.Incidents
+	if len(incidents.TimelineKeywords) == 0 {
+		incidents.TimelineKeywords = []string{"declared", "mitigated", "mitigation", "resolved", "rollback", "rolled back", "deployed", "root cause", "escalated", "paged", "status update"}
+	}
+	if incidents.TimelineReaction == "" {
+		incidents.TimelineReaction = "pushpin"
+	}
+	if incidents.MaxMessages == 0 {
+		incidents.MaxMessages = 1000
+	}
+	for i := range c.Slack.Digests {
+		digest := &c.Slack.Digests[i]
+		if digest.Timezone == "" {
+			digest.Timezone = "UTC"
+		}
+		if digest.Hours == 0 {
+			digest.Hours = 24
+		}
+		if digest.PostTo == "" {
+			digest.PostTo = digest.Channel
+		}
+		if digest.MaxMessages == 0 {
+			digest.MaxMessages = 500
+		}
+	}
+	for channelID, listener := range c.Slack.Listeners {
+		if listener.Cooldown == "" {
+			listener.Cooldown = "2m"
+			c.Slack.Listeners[channelID] = listener
+		}
+	}
+	if c.Slack.Assistant.PromptsTitle == "" {
+		c.Slack.Assistant.PromptsTitle = "Try asking"
+	}
+	if c.Slack.Assistant.ThinkingStatus == "" {
+		c.Slack.Assistant.ThinkingStatus = "is thinking..."
+	}
+	if c.Slack.Assistant.SearchingStatus == "" {
+		c.Slack.Assistant.SearchingStatus = "is searching the knowledge base..."
+	}
+	if c.Slack.Assistant.ToolStatus == "" {
+		c.Slack.Assistant.ToolStatus = "is running %s..."
+	}
+	if c.Slack.Outbound.QueueSize <= 0 {
+		c.Slack.Outbound.QueueSize = 100
+	}
+	if c.Slack.Outbound.MaxAttempts <= 0 {
+		c.Slack.Outbound.MaxAttempts = 5
+	}
+	if c.Slack.Outbound.BaseBackoff == "" {
+		c.Slack.Outbound.BaseBackoff = "1s"
+	}
+	if c.Slack.Outbound.MaxBackoff == "" {
+		c.Slack.Outbound.MaxBackoff = "30s"
+	}
 }
 
 // applySecurityDefaults sets default security configuration
@@ -342,6 +2123,10 @@ func (c *Config) applySecurityDefaults() {
 			c.Security.LogUnauthorized = &trueVal
 		}
 
+		if c.Security.LookupCacheTTL == "" {
+			c.Security.LookupCacheTTL = "10m"
+		}
+
 		// Build lookup maps for O(1) performance
 		c.Security.buildLookupMaps()
 	}
@@ -358,6 +2143,9 @@ func (c *Config) applyTimeoutDefaults() {
 	if c.Timeouts.ToolProcessingTimeout == "" {
 		c.Timeouts.ToolProcessingTimeout = "3m"
 	}
+	if c.Timeouts.ToolNoticeAfter == "" {
+		c.Timeouts.ToolNoticeAfter = "20s"
+	}
 	if c.Timeouts.BridgeOperationTimeout == "" {
 		c.Timeouts.BridgeOperationTimeout = "3m"
 	}
@@ -417,6 +2205,260 @@ func (c *Config) applyObservabilityDefaults() {
 	if c.Observability.ServiceVersion == "" {
 		c.Observability.ServiceVersion = "1.0.0"
 	}
+
+	// Export every trace, and failed traces whatever the sample
+	if c.Observability.Sampling.Percent == nil {
+		percent := 100.0
+		c.Observability.Sampling.Percent = &percent
+	}
+	if c.Observability.Sampling.AlwaysSampleErrors == nil {
+		trueVal := true
+		c.Observability.Sampling.AlwaysSampleErrors = &trueVal
+	}
+}
+
+// applyDedupeDefaults sets default event de-duplication configuration
+func (c *Config) applyDedupeDefaults() {
+	if c.Dedupe.Provider == "" {
+		c.Dedupe.Provider = DedupeProviderMemory
+	}
+	if c.Dedupe.RedisURL == "" {
+		c.Dedupe.RedisURL = "redis://localhost:6379/0"
+	}
+	if c.Dedupe.KeyPrefix == "" {
+		c.Dedupe.KeyPrefix = "slackmcp:event:"
+	}
+	if c.Dedupe.TTL == "" {
+		c.Dedupe.TTL = "10m"
+	}
+	if c.Dedupe.MaxEntries == 0 {
+		c.Dedupe.MaxEntries = 10000
+	}
+	if c.Dedupe.ReplicaID == "" {
+		if hostname, err := os.Hostname(); err == nil && hostname != "" {
+			c.Dedupe.ReplicaID = hostname
+		} else {
+			c.Dedupe.ReplicaID = "default"
+		}
+	}
+}
+
+// applyCredentialsDefaults sets default credential vault configuration
+func (c *Config) applyCredentialsDefaults() {
+	if c.Credentials.StorePath == "" {
+		c.Credentials.StorePath = "./credentials.json"
+	}
+	if c.Credentials.ListenAddr == "" {
+		c.Credentials.ListenAddr = ":8090"
+	}
+}
+
+// applyOnCallDefaults sets the on-call provider's API URL and cache duration
+func (c *Config) applyOnCallDefaults() {
+	if c.OnCall.URL == "" {
+		switch c.OnCall.Provider {
+		case OnCallProviderPagerDuty:
+			c.OnCall.URL = "https://api.pagerduty.com"
+		case OnCallProviderOpsgenie:
+			c.OnCall.URL = "https://api.opsgenie.com"
+		}
+	}
+	if c.OnCall.CacheTTL == "" {
+		c.OnCall.CacheTTL = "1m"
+	}
+}
+
+// applyWASMToolDefaults sets the timeout and memory limit of WASM tools
+func (c *Config) applyWASMToolDefaults() {
+	for name, tool := range c.WASMTools {
+		if tool.Timeout == "" {
+			tool.Timeout = "5s"
+		}
+		if tool.MaxMemoryMB == 0 {
+			tool.MaxMemoryMB = 16
+		}
+		if tool.InputSchema == nil {
+			tool.InputSchema = map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+		}
+		c.WASMTools[name] = tool
+	}
+}
+
+// applyHTTPToolDefaults sets the method, timeout and schema of HTTP tools
+func (c *Config) applyHTTPToolDefaults() {
+	for name, tool := range c.HTTPTools {
+		if tool.Method == "" {
+			tool.Method = "GET"
+		}
+		tool.Method = strings.ToUpper(tool.Method)
+		if tool.Timeout == "" {
+			tool.Timeout = "30s"
+		}
+		if tool.InputSchema == nil {
+			tool.InputSchema = map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+		}
+		c.HTTPTools[name] = tool
+	}
+}
+
+// applyOpenAPIDefaults sets the tool prefix and timeout of OpenAPI imports
+func (c *Config) applyOpenAPIDefaults() {
+	for name, api := range c.OpenAPI {
+		if api.ToolPrefix == "" {
+			api.ToolPrefix = name + "_"
+		}
+		if api.Timeout == "" {
+			api.Timeout = "30s"
+		}
+		c.OpenAPI[name] = api
+	}
+}
+
+// applyHookDefaults sets the timeout and failure handling of external hooks
+func (c *Config) applyHookDefaults() {
+	for i := range c.Hooks {
+		if c.Hooks[i].Timeout == "" {
+			c.Hooks[i].Timeout = "5s"
+		}
+		if c.Hooks[i].OnError == "" {
+			c.Hooks[i].OnError = HookOnErrorAllow
+		}
+	}
+}
+
+// applyModerationDefaults sets default content moderation configuration
+func (c *Config) applyModerationDefaults() {
+	if c.Moderation.Provider == "" {
+		c.Moderation.Provider = ModerationProviderOpenAI
+	}
+	if c.Moderation.Model == "" {
+		c.Moderation.Model = "omni-moderation-latest"
+	}
+	if c.Moderation.BaseURL == "" {
+		c.Moderation.BaseURL = "https://api.openai.com/v1"
+	}
+	if c.Moderation.CheckInput == nil {
+		checkInput := true
+		c.Moderation.CheckInput = &checkInput
+	}
+	if c.Moderation.CheckOutput == nil {
+		checkOutput := true
+		c.Moderation.CheckOutput = &checkOutput
+	}
+	if c.Moderation.Action == "" {
+		c.Moderation.Action = ModerationActionBlock
+	}
+	if c.Moderation.BlockMessage == "" {
+		c.Moderation.BlockMessage = "Sorry, I can't help with that request because it was flagged by the content policy."
+	}
+}
+
+// applyMCPStartupDefaults sets default MCP startup configuration
+func (c *Config) applyMCPStartupDefaults() {
+	if c.MCPStartup.Async == nil {
+		async := true
+		c.MCPStartup.Async = &async
+	}
+	if c.MCPCircuit.FailureThreshold == 0 {
+		c.MCPCircuit.FailureThreshold = 3
+	}
+	if c.MCPCircuit.OpenDuration == "" {
+		c.MCPCircuit.OpenDuration = "30s"
+	}
+}
+
+// applySelfTestDefaults sets the default time allowed to validate LLM providers
+func (c *Config) applySelfTestDefaults() {
+	if c.SelfTest.ProviderTimeout == "" {
+		c.SelfTest.ProviderTimeout = "30s"
+	}
+}
+
+// applyToolCollisionDefaults sets the default tool name collision strategy
+func (c *Config) applyToolCollisionDefaults() {
+	if c.ToolCollision.Strategy == "" {
+		c.ToolCollision.Strategy = ToolCollisionPrefix
+	}
+}
+
+// applyMaintenanceDefaults sets the notices and timezones of maintenance mode and quiet hours
+func (c *Config) applyMaintenanceDefaults() {
+	if c.Maintenance.Message == "" {
+		c.Maintenance.Message = "I'm down for maintenance right now. Please try again later."
+	}
+	for i := range c.Maintenance.QuietHours {
+		window := &c.Maintenance.QuietHours[i]
+		if window.Timezone == "" {
+			window.Timezone = "UTC"
+		}
+		if window.Message == "" {
+			window.Message = "I'm off for quiet hours right now. Please try again later."
+		}
+	}
+}
+
+// applyMemoryDefaults sets the limits of long-term memory
+func (c *Config) applyMemoryDefaults() {
+	if c.Memory.MaxFacts == 0 {
+		c.Memory.MaxFacts = 1000
+	}
+	if c.Memory.MaxRecalled == 0 {
+		c.Memory.MaxRecalled = 10
+	}
+}
+
+// applyJobsDefaults sets the limits of scheduled tool calls
+func (c *Config) applyJobsDefaults() {
+	if c.Jobs.MaxJobs == 0 {
+		c.Jobs.MaxJobs = 100
+	}
+	if c.Jobs.MaxDelay == "" {
+		c.Jobs.MaxDelay = "720h"
+	}
+	if c.Jobs.Timezone == "" {
+		c.Jobs.Timezone = "UTC"
+	}
+}
+
+// applyExperimentDefaults sets the rollback thresholds of the experiments
+func (c *Config) applyExperimentDefaults() {
+	for i := range c.Experiments {
+		rollback := &c.Experiments[i].Rollback
+		if rollback.Window == "" {
+			rollback.Window = "1h"
+		}
+		if rollback.MinSamples == 0 {
+			rollback.MinSamples = 10
+		}
+		if rollback.MaxNegativeRate == 0 {
+			rollback.MaxNegativeRate = 0.5
+		}
+		if rollback.MaxErrorRate == 0 {
+			rollback.MaxErrorRate = 0.2
+		}
+	}
+}
+
+// applySLODefaults sets the objectives and alert threshold of SLO tracking
+func (c *Config) applySLODefaults() {
+	if c.SLO.Window == "" {
+		c.SLO.Window = "1h"
+	}
+	if c.SLO.MinSamples == 0 {
+		c.SLO.MinSamples = 20
+	}
+	if c.SLO.LatencyP95 == "" {
+		c.SLO.LatencyP95 = "60s"
+	}
+	if c.SLO.ErrorRate == 0 {
+		c.SLO.ErrorRate = 0.05
+	}
+	if c.SLO.ToolFailureRate == 0 {
+		c.SLO.ToolFailureRate = 0.1
+	}
+	if c.SLO.BurnRate == 0 {
+		c.SLO.BurnRate = 2
+	}
 }
 
 // applyMCPDefaults initializes MCP servers map if nil
@@ -424,6 +2466,25 @@ func (c *Config) applyMCPDefaults() {
 	if c.MCPServers == nil {
 		c.MCPServers = make(map[string]MCPServerConfig)
 	}
+	for name, server := range c.MCPServers {
+		if server.Builtin != BuiltinIssues {
+			continue
+		}
+		issues := &server.Issues
+		if issues.Provider == IssueProviderGitHub && issues.URL == "" {
+			issues.URL = "https://api.github.com"
+		}
+		if issues.Provider == IssueProviderJira && issues.IssueType == "" {
+			issues.IssueType = "Task"
+		}
+		if issues.Title == "" {
+			issues.Title = "{{.Title}}"
+		}
+		if issues.Body == "" {
+			issues.Body = DefaultIssueBody
+		}
+		c.MCPServers[name] = server
+	}
 }
 
 // ApplyEnvironmentVariables applies environment variable overrides
@@ -435,6 +2496,33 @@ func (c *Config) ApplyEnvironmentVariables() {
 	if token := os.Getenv("SLACK_APP_TOKEN"); token != "" {
 		c.Slack.AppToken = token
 	}
+	if secret := os.Getenv("SLACK_SIGNING_SECRET"); secret != "" {
+		c.Slack.SigningSecret = secret
+	}
+	if mode := os.Getenv("SLACK_MODE"); mode != "" {
+		c.Slack.Mode = mode
+	}
+
+	// Discord configuration
+	if token := os.Getenv("DISCORD_BOT_TOKEN"); token != "" {
+		c.Discord.BotToken = token
+	}
+
+	// Mattermost configuration
+	if serverURL := os.Getenv("MATTERMOST_URL"); serverURL != "" {
+		c.Mattermost.URL = serverURL
+	}
+	if token := os.Getenv("MATTERMOST_BOT_TOKEN"); token != "" {
+		c.Mattermost.BotToken = token
+	}
+
+	// Microsoft Teams configuration
+	if appID := os.Getenv("TEAMS_APP_ID"); appID != "" {
+		c.Teams.AppID = appID
+	}
+	if password := os.Getenv("TEAMS_APP_PASSWORD"); password != "" {
+		c.Teams.AppPassword = password
+	}
 
 	// LLM provider override
 	if provider := os.Getenv("LLM_PROVIDER"); provider != "" {
@@ -516,6 +2604,59 @@ func (c *Config) ApplyEnvironmentVariables() {
 		c.Observability.ServiceVersion = serviceVersion
 	}
 
+	// Event de-duplication overrides
+	if enabled := os.Getenv("DEDUPE_ENABLED"); enabled != "" {
+		if val, err := strconv.ParseBool(enabled); err == nil {
+			c.Dedupe.Enabled = val
+		}
+	}
+	if provider := os.Getenv("DEDUPE_PROVIDER"); provider != "" {
+		c.Dedupe.Provider = provider
+	}
+	if redisURL := os.Getenv("DEDUPE_REDIS_URL"); redisURL != "" {
+		c.Dedupe.RedisURL = redisURL
+	}
+	if replicaID := os.Getenv("REPLICA_ID"); replicaID != "" {
+		c.Dedupe.ReplicaID = replicaID
+	}
+
+	// Credential vault overrides
+	if key := os.Getenv("CREDENTIALS_ENCRYPTION_KEY"); key != "" {
+		c.Credentials.EncryptionKey = key
+	}
+	if callbackURL := os.Getenv("CREDENTIALS_CALLBACK_URL"); callbackURL != "" {
+		c.Credentials.CallbackURL = callbackURL
+	}
+
+	// MCP startup overrides
+	if async := os.Getenv("MCP_ASYNC_STARTUP"); async != "" {
+		if val, err := strconv.ParseBool(async); err == nil {
+			c.MCPStartup.Async = &val
+		}
+	}
+	if notifyChannel := os.Getenv("MCP_STARTUP_NOTIFY_CHANNEL"); notifyChannel != "" {
+		c.MCPStartup.NotifyChannel = notifyChannel
+	}
+	if alertsChannel := os.Getenv("MCP_ALERTS_CHANNEL"); alertsChannel != "" {
+		c.MCPAlerts.Channel = alertsChannel
+	}
+	if selfTestChannel := os.Getenv("SELF_TEST_CHANNEL"); selfTestChannel != "" {
+		c.SelfTest.Channel = selfTestChannel
+	}
+
+	// Moderation overrides
+	if enabled := os.Getenv("MODERATION_ENABLED"); enabled != "" {
+		if val, err := strconv.ParseBool(enabled); err == nil {
+			c.Moderation.Enabled = val
+		}
+	}
+	if action := os.Getenv("MODERATION_ACTION"); action != "" {
+		c.Moderation.Action = action
+	}
+	if adminChannel := os.Getenv("MODERATION_ADMIN_CHANNEL"); adminChannel != "" {
+		c.Moderation.AdminChannel = adminChannel
+	}
+
 	// Security configuration overrides
 	if enabled := os.Getenv("SECURITY_ENABLED"); enabled != "" {
 		if val, err := strconv.ParseBool(enabled); err == nil {
@@ -566,6 +2707,13 @@ type SecurityResult struct {
 // ValidateAccess performs security validation based on the current configuration
 // Returns SecurityResult indicating whether access should be granted and the reason
 func (c *Config) ValidateAccess(userID, channelID string) SecurityResult {
+	return c.ValidateAccessWithDirectory(userID, channelID, nil)
+}
+
+// ValidateAccessWithDirectory is like ValidateAccess, but also matches the user
+// group handles and channel names in the security lists using the directory.
+// Without a directory, only IDs match.
+func (c *Config) ValidateAccessWithDirectory(userID, channelID string, directory SecurityDirectory) SecurityResult {
 	// Early return: security disabled
 	if !c.Security.Enabled {
 		return SecurityResult{
@@ -575,7 +2723,7 @@ func (c *Config) ValidateAccess(userID, channelID string) SecurityResult {
 	}
 
 	// Early return: admin access (admins always have access regardless of channel restrictions)
-	if c.isAdminUser(userID) {
+	if c.isAdminUser(userID, directory) {
 		return SecurityResult{
 			Allowed: true,
 			Reason:  "Admin user access",
@@ -583,8 +2731,8 @@ func (c *Config) ValidateAccess(userID, channelID string) SecurityResult {
 	}
 
 	// Check user and channel whitelists once
-	isUserAllowed := c.isUserAllowed(userID)
-	isChannelAllowed := c.isChannelAllowed(channelID)
+	isUserAllowed := c.isUserAllowed(userID, directory)
+	isChannelAllowed := c.isChannelAllowed(channelID, directory)
 
 	// Strict mode: both user AND channel must be whitelisted
 	if c.Security.StrictMode {
@@ -640,48 +2788,104 @@ func (c *Config) ValidateAccess(userID, channelID string) SecurityResult {
 	}
 }
 
-// isUserAllowed checks if a user ID is in the allowed users list
-func (c *Config) isUserAllowed(userID string) bool {
+// isUserAllowed checks if a user ID is in the allowed users list, directly or
+// through a user group or channel membership
+func (c *Config) isUserAllowed(userID string, directory SecurityDirectory) bool {
 	// Use map lookup if available (O(1)), otherwise fall back to slice iteration (O(n))
 	if c.Security.allowedUsersMap != nil {
-		_, exists := c.Security.allowedUsersMap[userID]
-		return exists
-	}
-	// Fallback for tests or edge cases where maps weren't built
-	for _, allowedUser := range c.Security.AllowedUsers {
-		if allowedUser == userID {
+		if _, exists := c.Security.allowedUsersMap[userID]; exists {
 			return true
 		}
+	} else {
+		// Fallback for tests or edge cases where maps weren't built
+		for _, allowedUser := range c.Security.AllowedUsers {
+			if allowedUser == userID {
+				return true
+			}
+		}
 	}
-	return false
+	return matchUserNames(c.Security.AllowedUsers, userID, directory)
 }
 
-// isChannelAllowed checks if a channel ID is in the allowed channels list
-func (c *Config) isChannelAllowed(channelID string) bool {
+// isChannelAllowed checks if a channel ID is in the allowed channels list, or the
+// channel's name matches one of its patterns
+func (c *Config) isChannelAllowed(channelID string, directory SecurityDirectory) bool {
 	// Use map lookup if available (O(1)), otherwise fall back to slice iteration (O(n))
 	if c.Security.allowedChannelsMap != nil {
-		_, exists := c.Security.allowedChannelsMap[channelID]
-		return exists
-	}
-	// Fallback for tests or edge cases where maps weren't built
-	for _, allowedChannel := range c.Security.AllowedChannels {
-		if allowedChannel == channelID {
+		if _, exists := c.Security.allowedChannelsMap[channelID]; exists {
 			return true
 		}
+	} else {
+		// Fallback for tests or edge cases where maps weren't built
+		for _, allowedChannel := range c.Security.AllowedChannels {
+			if allowedChannel == channelID {
+				return true
+			}
+		}
 	}
-	return false
+	return matchChannelPatterns(c.Security.AllowedChannels, channelID, directory)
 }
 
-// isAdminUser checks if a user ID is in the admin users list
-func (c *Config) isAdminUser(userID string) bool {
+// IsAdminUser reports whether the user is in security.adminUsers, directly or
+// through a user group or channel membership resolved by the directory (may be nil)
+func (c *Config) IsAdminUser(userID string, directory SecurityDirectory) bool {
+	return c.isAdminUser(userID, directory)
+}
+
+// isAdminUser checks if a user ID is in the admin users list, directly or
+// through a user group or channel membership
+func (c *Config) isAdminUser(userID string, directory SecurityDirectory) bool {
 	// Use map lookup if available (O(1)), otherwise fall back to slice iteration (O(n))
 	if c.Security.adminUsersMap != nil {
-		_, exists := c.Security.adminUsersMap[userID]
-		return exists
+		if _, exists := c.Security.adminUsersMap[userID]; exists {
+			return true
+		}
+	} else {
+		// Fallback for tests or edge cases where maps weren't built
+		for _, adminUser := range c.Security.AdminUsers {
+			if adminUser == userID {
+				return true
+			}
+		}
+	}
+	return matchUserNames(c.Security.AdminUsers, userID, directory)
+}
+
+// matchUserNames checks the user against the "@handle" user groups and the
+// "#channel" memberships of a list
+func matchUserNames(entries []string, userID string, directory SecurityDirectory) bool {
+	if directory == nil {
+		return false
+	}
+	for _, entry := range entries {
+		if handle, ok := strings.CutPrefix(entry, "@"); ok && directory.IsUserGroupMember(handle, userID) {
+			return true
+		}
+		if channelName, ok := strings.CutPrefix(entry, "#"); ok && directory.IsChannelMember(channelName, userID) {
+			return true
+		}
 	}
-	// Fallback for tests or edge cases where maps weren't built
-	for _, adminUser := range c.Security.AdminUsers {
-		if adminUser == userID {
+	return false
+}
+
+// matchChannelPatterns checks the channel's name against the "#pattern" entries
+// of a list. Patterns use path.Match syntax, e.g. "#prod-*".
+func matchChannelPatterns(entries []string, channelID string, directory SecurityDirectory) bool {
+	if directory == nil {
+		return false
+	}
+	channelName := ""
+	for _, entry := range entries {
+		pattern, ok := strings.CutPrefix(entry, "#")
+		if !ok {
+			continue
+		}
+		if channelName == "" {
+			if channelName = directory.ChannelName(channelID); channelName == "" {
+				return false
+			}
+		}
+		if matched, _ := path.Match(pattern, channelName); matched {
 			return true
 		}
 	}
@@ -1,8 +1,13 @@
 package config
 
 import (
+	"encoding/json"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestSecurityDefaults(t *testing.T) {
@@ -399,26 +404,26 @@ func TestHelperMethods(t *testing.T) {
 	}
 
 	// Test isUserAllowed
-	if !c.isUserAllowed("U123456789") {
+	if !c.isUserAllowed("U123456789", nil) {
 		t.Error("Expected U123456789 to be allowed")
 	}
-	if c.isUserAllowed("U999999999") {
+	if c.isUserAllowed("U999999999", nil) {
 		t.Error("Expected U999999999 to not be allowed")
 	}
 
 	// Test isChannelAllowed
-	if !c.isChannelAllowed("C123456789") {
+	if !c.isChannelAllowed("C123456789", nil) {
 		t.Error("Expected C123456789 to be allowed")
 	}
-	if c.isChannelAllowed("C999999999") {
+	if c.isChannelAllowed("C999999999", nil) {
 		t.Error("Expected C999999999 to not be allowed")
 	}
 
 	// Test isAdminUser
-	if !c.isAdminUser("A123456789") {
+	if !c.isAdminUser("A123456789", nil) {
 		t.Error("Expected A123456789 to be admin")
 	}
-	if c.isAdminUser("A999999999") {
+	if c.isAdminUser("A999999999", nil) {
 		t.Error("Expected A999999999 to not be admin")
 	}
 }
@@ -435,3 +440,1593 @@ func stringSlicesEqual(a, b []string) bool {
 	}
 	return true
 }
+
+func TestMCPStartupDefaults(t *testing.T) {
+	c := &Config{}
+	if !c.MCPStartup.IsAsync() {
+		t.Error("Expected async MCP startup when unset")
+	}
+
+	c.applyMCPStartupDefaults()
+	if c.MCPStartup.Async == nil || !*c.MCPStartup.Async {
+		t.Error("Expected async MCP startup to default to true")
+	}
+
+	blocking := false
+	c.MCPStartup.Async = &blocking
+	c.applyMCPStartupDefaults()
+	if c.MCPStartup.IsAsync() {
+		t.Error("Expected explicit blocking startup to be preserved")
+	}
+}
+
+func TestMCPCircuitBreakerDefaultsAndValidation(t *testing.T) {
+	c := &Config{}
+	c.applyMCPStartupDefaults()
+	if c.MCPCircuit.FailureThreshold != 3 || c.MCPCircuit.OpenDuration != "30s" {
+		t.Errorf("Expected circuit breaker defaults of 3 failures and 30s, got %d and %q", c.MCPCircuit.FailureThreshold, c.MCPCircuit.OpenDuration)
+	}
+
+	tests := []struct {
+		name    string
+		circuit MCPCircuitBreakerConfig
+		wantErr string
+	}{
+		{name: "defaults"},
+		{name: "negative threshold", circuit: MCPCircuitBreakerConfig{FailureThreshold: -1}, wantErr: "failureThreshold must be at least 1"},
+		{name: "invalid duration", circuit: MCPCircuitBreakerConfig{OpenDuration: "soon"}, wantErr: "invalid mcpCircuitBreaker.openDuration"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{UseStdIOClient: true, LLM: LLMConfig{Provider: ProviderOllama}, MCPCircuit: tt.circuit}
+			c.ApplyDefaults()
+			err := c.ValidateAfterDefaults()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Expected valid config, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSelfTestDefaultsAndValidation(t *testing.T) {
+	c := &Config{UseStdIOClient: true, LLM: LLMConfig{Provider: ProviderOllama}}
+	c.ApplyDefaults()
+	if c.SelfTest.ProviderTimeout != "30s" {
+		t.Errorf("Expected provider timeout to default to 30s, got %q", c.SelfTest.ProviderTimeout)
+	}
+	if err := c.ValidateAfterDefaults(); err != nil {
+		t.Fatalf("Expected valid config, got %v", err)
+	}
+
+	c.SelfTest.ProviderTimeout = "-1s"
+	if err := c.ValidateAfterDefaults(); err == nil || !strings.Contains(err.Error(), "invalid selfTest.providerTimeout") {
+		t.Errorf("Expected invalid provider timeout error, got %v", err)
+	}
+}
+
+func TestRAGSearchDefaults(t *testing.T) {
+	c := &Config{LLM: LLMConfig{Provider: ProviderOllama}}
+	c.applyRAGDefaults()
+
+	if c.RAG.Search.Mode != RAGSearchKeyword {
+		t.Errorf("Expected keyword search by default, got %q", c.RAG.Search.Mode)
+	}
+	if c.RAG.Search.EmbeddingProvider != ProviderOllama || c.RAG.Search.EmbeddingModel != "nomic-embed-text" {
+		t.Errorf("Expected ollama embeddings for the ollama LLM provider, got %s/%s",
+			c.RAG.Search.EmbeddingProvider, c.RAG.Search.EmbeddingModel)
+	}
+	if c.RAG.Rerank.Candidates != 20 || c.RAG.Rerank.TopN != 5 {
+		t.Errorf("Unexpected rerank defaults: candidates=%d topN=%d", c.RAG.Rerank.Candidates, c.RAG.Rerank.TopN)
+	}
+}
+
+func TestRAGNamespaces(t *testing.T) {
+	c := &Config{RAG: RAGConfig{
+		Enabled:  true,
+		Provider: "simple",
+		Namespaces: map[string]RAGNamespaceConfig{
+			"platform-docs": {Channels: []string{"C_PLATFORM", "C_SRE"}},
+			"hr-policies":   {Channels: []string{"C_HR"}},
+		},
+	}}
+	if err := c.validateRAGNamespaces(); err != nil {
+		t.Fatalf("Expected valid namespaces, got %v", err)
+	}
+	if ns := c.RAG.NamespaceForChannel("C_SRE"); ns != "platform-docs" {
+		t.Errorf("Expected platform-docs for C_SRE, got %q", ns)
+	}
+	if ns := c.RAG.NamespaceForChannel("C_OTHER"); ns != "" {
+		t.Errorf("Expected unmapped channel to search everything, got %q", ns)
+	}
+
+	c.RAG.DefaultNamespace = "hr-policies"
+	if ns := c.RAG.NamespaceForChannel("C_OTHER"); ns != "hr-policies" {
+		t.Errorf("Expected default namespace for unmapped channel, got %q", ns)
+	}
+
+	c.RAG.DefaultNamespace = "missing"
+	if err := c.validateRAGNamespaces(); err == nil {
+		t.Error("Expected error for undefined default namespace")
+	}
+
+	c.RAG.DefaultNamespace = ""
+	c.RAG.Namespaces["hr-policies"] = RAGNamespaceConfig{Channels: []string{"C_HR", "C_SRE"}}
+	if err := c.validateRAGNamespaces(); err == nil {
+		t.Error("Expected error for channel mapped to two namespaces")
+	}
+}
+
+func TestRAGSourcesValidation(t *testing.T) {
+	c := &Config{RAG: RAGConfig{
+		Enabled:  true,
+		Provider: "simple",
+		Sources: []RAGSourceConfig{
+			{Name: "eng-wiki", Type: RAGSourceConfluence, BaseURL: "https://example.atlassian.net/wiki", APIToken: "token", Spaces: []string{"ENG"}},
+			{Name: "handbook", Type: RAGSourceNotion, APIToken: "secret_token"},
+		},
+	}}
+	c.applyRAGDefaults()
+	if c.RAG.Sources[0].Interval != "1h" {
+		t.Errorf("Expected default interval 1h, got %q", c.RAG.Sources[0].Interval)
+	}
+	if err := c.validateRAGSources(); err != nil {
+		t.Fatalf("Expected valid sources, got %v", err)
+	}
+
+	c.RAG.Sources[1].Interval = "10s"
+	if err := c.validateRAGSources(); err == nil {
+		t.Error("Expected error for interval below one minute")
+	}
+	c.RAG.Sources[1].Interval = "1h"
+
+	c.RAG.Sources[0].Spaces = nil
+	if err := c.validateRAGSources(); err == nil {
+		t.Error("Expected error for confluence source without spaces")
+	}
+	c.RAG.Sources[0].Spaces = []string{"ENG"}
+
+	c.RAG.Sources[1].Name = "eng-wiki"
+	if err := c.validateRAGSources(); err == nil {
+		t.Error("Expected error for duplicate source names")
+	}
+	c.RAG.Sources[1].Name = "handbook"
+
+	drive := RAGSourceConfig{Name: "drive", Type: RAGSourceGoogleDrive, Interval: "1h", CredentialsFile: "/etc/drive.json"}
+	c.RAG.Sources = append(c.RAG.Sources, drive)
+	if err := c.validateRAGSources(); err == nil {
+		t.Error("Expected error for googleDrive source without folders")
+	}
+	c.RAG.Sources[2].FolderIDs = []string{"1AbC"}
+	if err := c.validateRAGSources(); err != nil {
+		t.Errorf("Expected googleDrive source without apiToken to be valid, got %v", err)
+	}
+}
+
+func TestRAGAnswerConfig(t *testing.T) {
+	c := &Config{RAG: RAGConfig{Enabled: true, Answer: RAGAnswerConfig{Enabled: true}}}
+	c.applyRAGDefaults()
+	if c.RAG.Answer.Classifier != RAGAnswerClassifierRetrieval || c.RAG.Answer.MaxResults != 5 {
+		t.Errorf("Unexpected answer defaults: %+v", c.RAG.Answer)
+	}
+	if !c.RAG.Answer.AppliesToChannel("C1") {
+		t.Error("Expected RAG-first answers in every channel when none are listed")
+	}
+
+	c.RAG.Answer.Channels = []string{"C2"}
+	if c.RAG.Answer.AppliesToChannel("C1") || !c.RAG.Answer.AppliesToChannel("C2") {
+		t.Error("Expected RAG-first answers only in the listed channels")
+	}
+	c.RAG.Answer.Enabled = false
+	if c.RAG.Answer.AppliesToChannel("C2") {
+		t.Error("Expected no RAG-first answers when disabled")
+	}
+}
+
+func TestRAGChunkingValidation(t *testing.T) {
+	c := &Config{RAG: RAGConfig{Enabled: true, Provider: "sqlite"}}
+	c.applyRAGDefaults()
+	if c.RAG.Chunking.Strategy != RAGChunkRecursive || c.RAG.Chunking.Unit != RAGChunkUnitCharacters {
+		t.Errorf("Unexpected chunking defaults: %+v", c.RAG.Chunking)
+	}
+	if err := c.validateRAGChunking(); err != nil {
+		t.Fatalf("Expected default chunking to be valid, got %v", err)
+	}
+
+	c.RAG.Chunking = RAGChunkingConfig{Strategy: RAGChunkMarkdown, Unit: RAGChunkUnitTokens, Size: 256, Overlap: 256}
+	if err := c.validateRAGChunking(); err == nil {
+		t.Error("Expected error for overlap not smaller than size")
+	}
+	c.RAG.Chunking.Overlap = 32
+	if err := c.validateRAGChunking(); err != nil {
+		t.Errorf("Expected markdown token chunking to be valid, got %v", err)
+	}
+
+	c.RAG.Chunking.Strategy = "paragraph"
+	if err := c.validateRAGChunking(); err == nil {
+		t.Error("Expected error for unknown strategy")
+	}
+
+	c.RAG.Chunking.Strategy = RAGChunkSemantic
+	if err := c.validateRAGChunking(); err != nil {
+		t.Errorf("Expected semantic chunking with an embedding provider to be valid, got %v", err)
+	}
+	c.RAG.Provider = "openai"
+	if err := c.validateRAGChunking(); err == nil {
+		t.Error("Expected error for semantic chunking with the openai provider")
+	}
+}
+
+func TestSlackAssistantValidation(t *testing.T) {
+	c := &Config{}
+	c.applySlackDefaults()
+	if c.Slack.Assistant.ThinkingStatus != "is thinking..." || c.Slack.Assistant.PromptsTitle != "Try asking" {
+		t.Errorf("Unexpected assistant defaults: %+v", c.Slack.Assistant)
+	}
+	if err := c.validateSlackAssistant(); err != nil {
+		t.Fatalf("Expected no suggested prompts to be valid, got %v", err)
+	}
+
+	prompt := SlackAssistantPrompt{Title: "Incidents", Message: "Which incidents are open?"}
+	c.Slack.Assistant.SuggestedPrompts = []SlackAssistantPrompt{prompt, {Title: "Missing message"}}
+	if err := c.validateSlackAssistant(); err == nil {
+		t.Error("Expected error for a prompt without a message")
+	}
+	c.Slack.Assistant.SuggestedPrompts = []SlackAssistantPrompt{prompt, prompt, prompt, prompt, prompt}
+	if err := c.validateSlackAssistant(); err == nil {
+		t.Error("Expected error for more than four suggested prompts")
+	}
+}
+
+func TestSlackIntermediateRetention(t *testing.T) {
+	c := &Config{}
+	c.applySlackDefaults()
+	c.Slack.IntermediateMessages.Channels = map[string]string{"C1": IntermediateCollapse}
+	if got := c.Slack.IntermediateMessages.RetentionForChannel("C1"); got != IntermediateCollapse {
+		t.Errorf("Expected collapse for C1, got %s", got)
+	}
+	if got := c.Slack.IntermediateMessages.RetentionForChannel("C2"); got != IntermediateKeep {
+		t.Errorf("Expected the default keep for C2, got %s", got)
+	}
+	if err := c.validateSlackIntermediateMessages(); err != nil {
+		t.Fatalf("Expected valid retention, got %v", err)
+	}
+
+	c.Slack.IntermediateMessages.Channels["C2"] = "archive"
+	if err := c.validateSlackIntermediateMessages(); err == nil {
+		t.Error("Expected error for unknown channel retention")
+	}
+}
+
+func TestLLMRoutingValidation(t *testing.T) {
+	c := &Config{}
+	c.LLM.Routing.Enabled = true
+	c.LLM.Routing.Cheap.Provider = ProviderOllama
+	c.ApplyDefaults()
+	if c.LLM.Routing.Powerful.Provider != ProviderOpenAI {
+		t.Errorf("Expected the powerful route to default to the LLM provider, got %s", c.LLM.Routing.Powerful.Provider)
+	}
+	if err := c.validateLLMRouting(); err != nil {
+		t.Fatalf("Expected valid routing, got %v", err)
+	}
+
+	c.LLM.Routing.Channels = map[string]string{"C1": "fast"}
+	if err := c.validateLLMRouting(); err == nil {
+		t.Error("Expected error for unknown channel route")
+	}
+
+	c.LLM.Routing.Channels = nil
+	c.LLM.Routing.Cheap.Provider = "gemini"
+	if err := c.validateLLMRouting(); err == nil {
+		t.Error("Expected error for an unconfigured route provider")
+	}
+}
+
+func TestLLMGroundingValidation(t *testing.T) {
+	c := &Config{}
+	c.LLM.Grounding.Enabled = true
+	c.ApplyDefaults()
+	if c.LLM.Grounding.Provider != ProviderOpenAI || c.LLM.Grounding.Action != GroundingActionWarn || c.LLM.Grounding.MaxSourceLength != 12000 {
+		t.Errorf("Unexpected grounding defaults: %+v", c.LLM.Grounding)
+	}
+	if err := c.validateLLMGrounding(); err != nil {
+		t.Fatalf("Expected valid grounding check, got %v", err)
+	}
+
+	c.LLM.Grounding.Action = "block"
+	if err := c.validateLLMGrounding(); err == nil || !strings.Contains(err.Error(), "grounding action") {
+		t.Errorf("Expected error for unknown action, got %v", err)
+	}
+
+	c.LLM.Grounding.Action = GroundingActionRegenerate
+	c.LLM.Grounding.Provider = "gemini"
+	if err := c.validateLLMGrounding(); err == nil {
+		t.Error("Expected error for an unconfigured grounding provider")
+	}
+
+	routed := &Config{}
+	routed.LLM.Routing.Enabled = true
+	routed.LLM.Routing.Cheap = LLMRouteConfig{Provider: ProviderOllama, Model: "llama3.2"}
+	routed.ApplyDefaults()
+	if routed.LLM.Grounding.Provider != ProviderOllama || routed.LLM.Grounding.Model != "llama3.2" {
+		t.Errorf("Expected the grounding check to default to the cheap route, got %+v", routed.LLM.Grounding)
+	}
+}
+
+func TestSlackReactionActionsValidation(t *testing.T) {
+	c := &Config{}
+	c.Slack.ReactionActions = map[string]SlackReactionAction{
+		"pushpin": {Action: ReactionActionCanvas},
+		"thread":  {Action: ReactionActionExpand},
+	}
+	c.ApplyDefaults()
+	if c.Slack.ReactionActions["thread"].Prompt == "" {
+		t.Error("Expected expand to default to a built-in prompt")
+	}
+	if err := c.validateSlackReactionActions(); err != nil {
+		t.Fatalf("Expected valid reaction actions, got %v", err)
+	}
+
+	c.Slack.ReactionActions["memo"] = SlackReactionAction{Action: ReactionActionPrompt}
+	if err := c.validateSlackReactionActions(); err == nil || !strings.Contains(err.Error(), "needs a prompt") {
+		t.Errorf("Expected error for a prompt action without a prompt, got %v", err)
+	}
+
+	c.Slack.ReactionActions["memo"] = SlackReactionAction{Action: "ticket"}
+	if err := c.validateSlackReactionActions(); err == nil || !strings.Contains(err.Error(), "unknown slack reactionActions action") {
+		t.Errorf("Expected error for an unknown action, got %v", err)
+	}
+}
+
+func TestSlackErrorMessagesValidation(t *testing.T) {
+	c := &Config{}
+	c.LLM.Provider = ProviderOllama
+	c.UseStdIOClient = true
+	c.Slack.ErrorMessages = map[string]string{ErrorKindServerUnavailable: "{server} is down."}
+	c.ApplyDefaults()
+	if err := c.ValidateAfterDefaults(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	c.Slack.ErrorMessages["llm_down"] = "The LLM is down."
+	if err := c.ValidateAfterDefaults(); err == nil || !strings.Contains(err.Error(), "unknown slack errorMessages kind 'llm_down'") {
+		t.Errorf("Expected error for an unknown error kind, got %v", err)
+	}
+}
+
+func TestToolDefaultsValidation(t *testing.T) {
+	c := &Config{}
+	c.LLM.Provider = ProviderOllama
+	c.UseStdIOClient = true
+	c.MCPServers = map[string]MCPServerConfig{"jira": {Command: "jira-mcp", Tools: MCPToolsConfig{
+		Defaults: map[string]interface{}{"reporter": "${user.email}", "due": "${today}"},
+		Overrides: map[string]MCPToolOverride{
+			"create_issue": {Defaults: map[string]interface{}{"labels": []interface{}{"slack", "${slack.channel_id}"}}},
+		},
+	}}}
+	c.ApplyDefaults()
+	if err := c.ValidateAfterDefaults(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	c.MCPServers["jira"].Tools.Overrides["create_issue"] = MCPToolOverride{Defaults: map[string]interface{}{
+		"fields": map[string]interface{}{"channel": "${slack.channel}"},
+	}}
+	err := c.ValidateAfterDefaults()
+	if err == nil || !strings.Contains(err.Error(), "defaults of tool 'create_issue': unknown context variable '${slack.channel}'") {
+		t.Errorf("Expected error for an unknown context variable, got %v", err)
+	}
+}
+
+func TestSlackCommandsValidation(t *testing.T) {
+	c := &Config{}
+	c.LLM.Provider = ProviderOllama
+	c.UseStdIOClient = true
+	c.Slack.Commands = map[string]SlackCommandTemplate{
+		"deploy-status": {Tool: "get_deployment", Params: []string{"service"}, Args: map[string]interface{}{"name": "${service}", "requester": "${user.email}"}},
+	}
+	c.ApplyDefaults()
+	if err := c.ValidateAfterDefaults(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	invalid := map[string]SlackCommandTemplate{
+		"jobs":         {Tool: "get_deployment"},
+		"Deploy":       {Tool: "get_deployment"},
+		"no-tool":      {},
+		"bad-param":    {Tool: "get_deployment", Params: []string{"today"}},
+		"twice":        {Tool: "get_deployment", Params: []string{"service", "service"}},
+		"unknown-vars": {Tool: "get_deployment", Params: []string{"service"}, Args: map[string]interface{}{"name": "${svc}"}},
+	}
+	for name, command := range invalid {
+		c.Slack.Commands = map[string]SlackCommandTemplate{name: command}
+		if err := c.ValidateAfterDefaults(); err == nil || !strings.Contains(err.Error(), "slack commands") {
+			t.Errorf("Expected error for command '%s', got %v", name, err)
+		}
+	}
+}
+
+func TestLLMIntentDefaultsAndValidation(t *testing.T) {
+	c := &Config{}
+	c.LLM.Provider = ProviderOllama
+	c.UseStdIOClient = true
+	c.LLM.Providers = map[string]LLMProviderConfig{ProviderOllama: {Model: "llama3"}}
+	c.LLM.Intent.Enabled = true
+	c.ApplyDefaults()
+	if c.LLM.Intent.Classifier != IntentClassifierHeuristic || c.LLM.Intent.Provider != ProviderOllama || c.LLM.Intent.Timeout != "5s" {
+		t.Errorf("Unexpected intent defaults: %+v", c.LLM.Intent)
+	}
+	if len(c.LLM.Intent.ChatWords) == 0 {
+		t.Error("Expected default chat words")
+	}
+	if err := c.ValidateAfterDefaults(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	c.LLM.Intent.Classifier = "embedding"
+	if err := c.ValidateAfterDefaults(); err == nil || !strings.Contains(err.Error(), "unknown llm intent classifier 'embedding'") {
+		t.Errorf("Expected error for an unknown classifier, got %v", err)
+	}
+	c.LLM.Intent.Classifier = IntentClassifierLLM
+	c.LLM.Intent.Provider = "local"
+	if err := c.ValidateAfterDefaults(); err == nil || !strings.Contains(err.Error(), "llm intent classifier uses provider 'local'") {
+		t.Errorf("Expected error for an unconfigured provider, got %v", err)
+	}
+	c.LLM.Intent.Provider = ProviderOllama
+	c.LLM.Intent.Timeout = "soon"
+	if err := c.ValidateAfterDefaults(); err == nil || !strings.Contains(err.Error(), "invalid timeout 'soon'") {
+		t.Errorf("Expected error for an invalid timeout, got %v", err)
+	}
+}
+
+// fakeDirectory resolves user groups, channel members and channel names from maps
+type fakeDirectory struct {
+	groups   map[string][]string
+	members  map[string][]string
+	channels map[string]string
+}
+
+func (d *fakeDirectory) IsUserGroupMember(handle, userID string) bool {
+	return containsString(d.groups[handle], userID)
+}
+
+func (d *fakeDirectory) IsChannelMember(channelName, userID string) bool {
+	return containsString(d.members[channelName], userID)
+}
+
+func (d *fakeDirectory) ChannelName(channelID string) string {
+	return d.channels[channelID]
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func TestValidateAccessWithSlackNames(t *testing.T) {
+	c := &Config{}
+	c.Security = SecurityConfig{
+		Enabled:         true,
+		StrictMode:      true,
+		AllowedUsers:    []string{"U1", "@sre-team", "#sre-private"},
+		AllowedChannels: []string{"C1", "#prod-*"},
+		AdminUsers:      []string{"@admins"},
+	}
+	c.applySecurityDefaults()
+	if !c.Security.UsesSlackNames() {
+		t.Fatal("Expected the security lists to use Slack names")
+	}
+	if err := c.validateSecurityLists(); err != nil {
+		t.Fatalf("Expected valid security lists, got %v", err)
+	}
+
+	directory := &fakeDirectory{
+		groups:   map[string][]string{"sre-team": {"U2"}, "admins": {"U9"}},
+		members:  map[string][]string{"sre-private": {"U3"}},
+		channels: map[string]string{"C2": "prod-payments", "C3": "random"},
+	}
+	tests := []struct {
+		user, channel string
+		allowed       bool
+	}{
+		{"U1", "C1", true},
+		{"U2", "C2", true},  // User group member in a channel matching the pattern
+		{"U3", "C2", true},  // Member of the private channel
+		{"U4", "C2", false}, // Not in any list
+		{"U2", "C3", false}, // Channel name does not match
+		{"U9", "C3", true},  // Admin through a user group
+	}
+	for _, tt := range tests {
+		if got := c.ValidateAccessWithDirectory(tt.user, tt.channel, directory).Allowed; got != tt.allowed {
+			t.Errorf("ValidateAccessWithDirectory(%s, %s) = %v, want %v", tt.user, tt.channel, got, tt.allowed)
+		}
+	}
+
+	// Without a directory only IDs match
+	if c.ValidateAccess("U2", "C2").Allowed {
+		t.Error("Expected names to be ignored without a directory")
+	}
+
+	c.Security.AllowedChannels = []string{"#prod-["}
+	if err := c.validateSecurityLists(); err == nil {
+		t.Error("Expected error for an invalid channel pattern")
+	}
+	c.Security.AllowedChannels = nil
+	c.Security.AllowedUsers = []string{"#sre-*"}
+	if err := c.validateSecurityLists(); err == nil {
+		t.Error("Expected error for a channel pattern in allowedUsers")
+	}
+}
+
+func TestQuietHoursValidation(t *testing.T) {
+	c := &Config{}
+	c.Maintenance.QuietHours = []QuietHoursWindow{{Start: "22:00", End: "07:00", Days: []string{"Fri", "sat"}}}
+	c.applyMaintenanceDefaults()
+	if c.Maintenance.QuietHours[0].Timezone != "UTC" {
+		t.Errorf("Expected the default timezone UTC, got %s", c.Maintenance.QuietHours[0].Timezone)
+	}
+	if err := c.validateQuietHours(); err != nil {
+		t.Fatalf("Expected valid quiet hours, got %v", err)
+	}
+
+	invalid := []QuietHoursWindow{
+		{Start: "25:00", End: "07:00", Timezone: "UTC"},
+		{Start: "09:00", End: "09:00", Timezone: "UTC"},
+		{Start: "22:00", End: "07:00", Days: []string{"someday"}, Timezone: "UTC"},
+		{Start: "22:00", End: "07:00", Timezone: "Mars/Olympus_Mons"},
+	}
+	for _, window := range invalid {
+		c.Maintenance.QuietHours = []QuietHoursWindow{window}
+		if err := c.validateQuietHours(); err == nil {
+			t.Errorf("Expected error for quiet hours %+v", window)
+		}
+	}
+}
+
+func TestMCPServerEnvIsolation(t *testing.T) {
+	inherit := false
+	server := MCPServerConfig{Command: "npx"}
+	if !server.InheritsEnv() {
+		t.Error("Expected stdio servers to inherit the environment by default")
+	}
+	server.InheritEnv = &inherit
+	if server.InheritsEnv() {
+		t.Error("Expected inheritEnv false to isolate the server")
+	}
+
+	c := &Config{}
+	c.LLM.Providers = map[string]LLMProviderConfig{ProviderOllama: {Model: "llama3"}}
+	c.LLM.Provider = ProviderOllama
+	c.UseStdIOClient = true
+	c.MCPServers = map[string]MCPServerConfig{"github": {Command: "npx", EnvAllowlist: []string{"GITHUB_*"}}}
+	c.ApplyDefaults()
+	if err := c.ValidateAfterDefaults(); err == nil {
+		t.Error("Expected error for envAllowlist without inheritEnv false")
+	}
+	c.MCPServers["github"] = MCPServerConfig{Command: "npx", EnvAllowlist: []string{"GITHUB_*"}, InheritEnv: &inherit}
+	if err := c.ValidateAfterDefaults(); err != nil {
+		t.Errorf("Expected a valid isolated server, got %v", err)
+	}
+}
+
+func TestMCPServerDockerRuntime(t *testing.T) {
+	c := &Config{}
+	c.LLM.Providers = map[string]LLMProviderConfig{ProviderOllama: {Model: "llama3"}}
+	c.LLM.Provider = ProviderOllama
+	c.UseStdIOClient = true
+	c.MCPServers = map[string]MCPServerConfig{"github": {Runtime: RuntimeDocker, Docker: MCPDockerConfig{Image: "mcp/github"}}}
+	c.ApplyDefaults()
+	if err := c.ValidateAfterDefaults(); err != nil {
+		t.Errorf("Expected a docker server without a command to be valid, got %v", err)
+	}
+	server := c.MCPServers["github"]
+	if transport := server.GetTransport(); transport != "stdio" {
+		t.Errorf("Expected docker servers to use stdio, got %s", transport)
+	}
+
+	invalid := map[string]MCPServerConfig{
+		"missing image":   {Runtime: RuntimeDocker, Command: "npx"},
+		"url server":      {Runtime: RuntimeDocker, URL: "http://localhost:8080/sse", Docker: MCPDockerConfig{Image: "mcp/github"}},
+		"bad volume":      {Runtime: RuntimeDocker, Docker: MCPDockerConfig{Image: "mcp/github", Volumes: []string{"/data"}}},
+		"bad pull":        {Runtime: RuntimeDocker, Docker: MCPDockerConfig{Image: "mcp/github", Pull: "sometimes"}},
+		"unknown runtime": {Runtime: "podman", Command: "npx"},
+	}
+	for name, server := range invalid {
+		c.MCPServers = map[string]MCPServerConfig{"github": server}
+		if err := c.ValidateAfterDefaults(); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+}
+
+func TestMCPServerRestartPolicy(t *testing.T) {
+	c := &Config{}
+	c.LLM.Providers = map[string]LLMProviderConfig{ProviderOllama: {Model: "llama3"}}
+	c.LLM.Provider = ProviderOllama
+	c.UseStdIOClient = true
+	c.MCPServers = map[string]MCPServerConfig{"github": {Command: "npx"}}
+	c.ApplyDefaults()
+
+	server := c.MCPServers["github"]
+	if !server.RestartsOnExit() {
+		t.Error("Expected stdio servers to be restarted by default")
+	}
+	restart := server.Restart
+	if restart.GetMaxAttempts() != 5 || restart.GetInitialBackoff() != time.Second || restart.GetMaxBackoff() != time.Minute || restart.GetStableAfter() != 5*time.Minute {
+		t.Errorf("Unexpected restart defaults: %+v", restart)
+	}
+	if err := c.ValidateAfterDefaults(); err != nil {
+		t.Errorf("Expected default restart policy to be valid, got %v", err)
+	}
+
+	server.Restart.MaxBackoff = "soon"
+	c.MCPServers["github"] = server
+	if err := c.ValidateAfterDefaults(); err == nil {
+		t.Error("Expected error for an invalid restart backoff")
+	}
+}
+
+func TestToolTimeouts(t *testing.T) {
+	c := &Config{}
+	c.LLM.Providers = map[string]LLMProviderConfig{ProviderOllama: {Model: "llama3"}}
+	c.LLM.Provider = ProviderOllama
+	c.UseStdIOClient = true
+	c.MCPServers = map[string]MCPServerConfig{
+		"github": {Command: "npx", Tools: MCPToolsConfig{
+			Timeout:   "30s",
+			Overrides: map[string]MCPToolOverride{"search_code": {Timeout: "2m"}},
+		}},
+		"jira": {Command: "npx"},
+	}
+	c.ApplyDefaults()
+	if err := c.ValidateAfterDefaults(); err != nil {
+		t.Fatalf("Expected tool timeouts to be valid, got %v", err)
+	}
+
+	if got := c.GetToolTimeout("github", "search_code"); got != 2*time.Minute {
+		t.Errorf("Expected the tool's own timeout, got %s", got)
+	}
+	if got := c.GetToolTimeout("github", "get_issue"); got != 30*time.Second {
+		t.Errorf("Expected the server's timeout, got %s", got)
+	}
+	if got := c.GetToolTimeout("jira", "get_issue"); got != 3*time.Minute {
+		t.Errorf("Expected the default timeout, got %s", got)
+	}
+	if got := c.GetToolTimeout("rag", "rag_search"); got != 3*time.Minute {
+		t.Errorf("Expected the default timeout for a native tool, got %s", got)
+	}
+	if c.MCPServers["github"].Restart.KillsOnCancel() {
+		t.Error("Expected abandoned calls to keep stdio servers running by default")
+	}
+
+	server := c.MCPServers["github"]
+	server.Tools.Overrides["search_code"] = MCPToolOverride{Timeout: "0s"}
+	if err := c.ValidateAfterDefaults(); err == nil {
+		t.Error("Expected error for a zero tool timeout")
+	}
+}
+
+func TestMCPServerBuiltin(t *testing.T) {
+	c := &Config{}
+	c.LLM.Providers = map[string]LLMProviderConfig{ProviderOllama: {Model: "llama3"}}
+	c.LLM.Provider = ProviderOllama
+	c.UseStdIOClient = true
+	c.MCPServers = map[string]MCPServerConfig{
+		"files": {Builtin: BuiltinFilesystem, Args: []string{"/data"}},
+		"time":  {Builtin: BuiltinTime},
+	}
+	c.ApplyDefaults()
+	if err := c.ValidateAfterDefaults(); err != nil {
+		t.Errorf("Expected builtin servers to be valid, got %v", err)
+	}
+	server := c.MCPServers["time"]
+	if transport := server.GetTransport(); transport != TransportBuiltin {
+		t.Errorf("Expected builtin servers to use the builtin transport, got %s", transport)
+	}
+
+	invalid := map[string]MCPServerConfig{
+		"unknown builtin":         {Builtin: "shell"},
+		"filesystem without dirs": {Builtin: BuiltinFilesystem},
+		"builtin with command":    {Builtin: BuiltinTime, Command: "npx"},
+		"builtin with transport":  {Builtin: BuiltinTime, Transport: "stdio"},
+		"builtin transport only":  {Transport: TransportBuiltin},
+		"builtin per-user auth":   {Builtin: BuiltinFetch, AuthMode: AuthModePerUser},
+		"builtin header identity": {Builtin: BuiltinFetch, Identity: MCPIdentityConfig{Enabled: true, Mode: IdentityModeHeader}},
+	}
+	for name, server := range invalid {
+		c.MCPServers = map[string]MCPServerConfig{"builtin": server}
+		if err := c.ValidateAfterDefaults(); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+}
+
+func TestHookValidation(t *testing.T) {
+	newConfig := func(hook HookConfig) *Config {
+		c := &Config{Hooks: []HookConfig{hook}}
+		c.LLM.Providers = map[string]LLMProviderConfig{ProviderOllama: {Model: "llama3"}}
+		c.LLM.Provider = ProviderOllama
+		c.UseStdIOClient = true
+		c.ApplyDefaults()
+		return c
+	}
+
+	c := newConfig(HookConfig{Name: "policy", Events: []string{HookEventBeforeToolCall}, URL: "https://policy.example.com/hook"})
+	if err := c.ValidateAfterDefaults(); err != nil {
+		t.Fatalf("Expected the hook to be valid, got %v", err)
+	}
+	if c.Hooks[0].Timeout != "5s" || c.Hooks[0].OnError != HookOnErrorAllow {
+		t.Errorf("Expected default timeout and onError, got %q and %q", c.Hooks[0].Timeout, c.Hooks[0].OnError)
+	}
+
+	invalid := map[string]HookConfig{
+		"requires either a command or a url": {Name: "both", Events: []string{HookEventAfterResponse}, Command: "check", URL: "https://example.com"},
+		"unknown event 'before_reply'":       {Name: "event", Events: []string{"before_reply"}, Command: "check"},
+		"subscribes to no events":            {Name: "none", Command: "check"},
+		"unknown onError 'retry'":            {Name: "retry", Events: []string{HookEventAfterResponse}, Command: "check", OnError: "retry"},
+	}
+	for want, hook := range invalid {
+		err := newConfig(hook).ValidateAfterDefaults()
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected an error containing %q, got %v", want, err)
+		}
+	}
+}
+
+func TestWASMToolDefaults(t *testing.T) {
+	c := &Config{WASMTools: map[string]WASMToolConfig{"format_json": {Path: "tools/format.wasm", Description: "Pretty-print JSON"}}}
+	c.LLM.Providers = map[string]LLMProviderConfig{ProviderOllama: {Model: "llama3"}}
+	c.LLM.Provider = ProviderOllama
+	c.UseStdIOClient = true
+	c.ApplyDefaults()
+	if err := c.ValidateAfterDefaults(); err != nil {
+		t.Fatalf("Expected the WASM tool to be valid, got %v", err)
+	}
+	tool := c.WASMTools["format_json"]
+	if tool.Timeout != "5s" || tool.MaxMemoryMB != 16 || tool.InputSchema["type"] != "object" {
+		t.Errorf("Expected default timeout, memory limit and schema, got %+v", tool)
+	}
+
+	c.WASMTools["format_json"] = WASMToolConfig{Path: "tools/format.wasm", Timeout: "5s", MaxMemoryMB: 16}
+	if err := c.ValidateAfterDefaults(); err == nil || !strings.Contains(err.Error(), "requires a description") {
+		t.Errorf("Expected a missing description to be reported, got %v", err)
+	}
+}
+
+func TestHTTPToolDefaults(t *testing.T) {
+	c := &Config{HTTPTools: map[string]HTTPToolConfig{
+		"get_user": {Description: "Look up a user", Method: "get", URL: "https://api.internal/users/{user_id}"},
+	}}
+	c.LLM.Providers = map[string]LLMProviderConfig{ProviderOllama: {Model: "llama3"}}
+	c.LLM.Provider = ProviderOllama
+	c.UseStdIOClient = true
+	c.ApplyDefaults()
+	if err := c.ValidateAfterDefaults(); err != nil {
+		t.Fatalf("Expected the HTTP tool to be valid, got %v", err)
+	}
+	if tool := c.HTTPTools["get_user"]; tool.Method != "GET" || tool.Timeout != "30s" {
+		t.Errorf("Expected the method to be upper-cased and the default timeout, got %+v", tool)
+	}
+
+	c.HTTPTools["get_user"] = HTTPToolConfig{Description: "Look up a user", Method: "GET", URL: "/users/{user_id}", Timeout: "30s"}
+	if err := c.ValidateAfterDefaults(); err == nil || !strings.Contains(err.Error(), "invalid url") {
+		t.Errorf("Expected a relative URL to be rejected, got %v", err)
+	}
+}
+
+func TestOpenAPIDefaults(t *testing.T) {
+	c := &Config{OpenAPI: map[string]OpenAPIConfig{
+		"billing": {Spec: "https://billing.internal/openapi.json", Operations: []string{"get*"}},
+	}}
+	c.LLM.Providers = map[string]LLMProviderConfig{ProviderOllama: {Model: "llama3"}}
+	c.LLM.Provider = ProviderOllama
+	c.UseStdIOClient = true
+	c.ApplyDefaults()
+	if err := c.ValidateAfterDefaults(); err != nil {
+		t.Fatalf("Expected the OpenAPI import to be valid, got %v", err)
+	}
+	if api := c.OpenAPI["billing"]; api.ToolPrefix != "billing_" || api.Timeout != "30s" {
+		t.Errorf("Expected the default tool prefix and timeout, got %+v", api)
+	}
+
+	c.OpenAPI["billing"] = OpenAPIConfig{Spec: "https://billing.internal/openapi.json", Timeout: "30s"}
+	if err := c.ValidateAfterDefaults(); err == nil || !strings.Contains(err.Error(), "operations allowlist") {
+		t.Errorf("Expected an import without an allowlist to be rejected, got %v", err)
+	}
+}
+
+func TestExperimentValidation(t *testing.T) {
+	newConfig := func(experiment ExperimentConfig) *Config {
+		c := &Config{Experiments: []ExperimentConfig{experiment}}
+		c.LLM.Providers = map[string]LLMProviderConfig{ProviderOllama: {Model: "llama3"}}
+		c.LLM.Provider = ProviderOllama
+		c.UseStdIOClient = true
+		c.ApplyDefaults()
+		return c
+	}
+
+	c := newConfig(ExperimentConfig{Name: "concise", Percent: 20, Variant: ExperimentVariantConfig{CustomPrompt: "Be brief."}})
+	if err := c.ValidateAfterDefaults(); err != nil {
+		t.Fatalf("Expected the experiment to be valid, got %v", err)
+	}
+	if len(c.Slack.FeedbackReactions.Positive) == 0 || len(c.Slack.FeedbackReactions.Negative) == 0 {
+		t.Errorf("Expected default feedback reactions, got %+v", c.Slack.FeedbackReactions)
+	}
+	if rollback := c.Experiments[0].Rollback; rollback.Window != "1h" || rollback.MinSamples != 10 || rollback.MaxNegativeRate != 0.5 || rollback.MaxErrorRate != 0.2 {
+		t.Errorf("Expected default rollback thresholds, got %+v", rollback)
+	}
+
+	tests := []struct {
+		experiment ExperimentConfig
+		want       string
+	}{
+		{ExperimentConfig{Percent: 20, Variant: ExperimentVariantConfig{Model: "llama3.1"}}, "requires a name"},
+		{ExperimentConfig{Name: "x", Percent: 120, Variant: ExperimentVariantConfig{Model: "llama3.1"}}, "between 0 and 100"},
+		{ExperimentConfig{Name: "x", Percent: 20}, "requires a variant"},
+		{ExperimentConfig{Name: "x", Percent: 20, Variant: ExperimentVariantConfig{Provider: "missing"}}, "not configured"},
+		{ExperimentConfig{Name: "x", Percent: 20, Variant: ExperimentVariantConfig{Model: "llama3.1"}, Rollback: ExperimentRollbackConfig{Enabled: true, Window: "soon"}}, "rollback window"},
+		{ExperimentConfig{Name: "x", Percent: 20, Variant: ExperimentVariantConfig{Model: "llama3.1"}, Rollback: ExperimentRollbackConfig{Enabled: true, MaxErrorRate: 1.5}}, "between 0 and 1"},
+	}
+	for _, tt := range tests {
+		if err := newConfig(tt.experiment).ValidateAfterDefaults(); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Expected an error containing %q for %+v, got %v", tt.want, tt.experiment, err)
+		}
+	}
+}
+
+func TestObservabilitySampling(t *testing.T) {
+	c := &Config{}
+	c.LLM.Provider = ProviderOllama
+	c.UseStdIOClient = true
+	c.ApplyDefaults()
+	sampling := c.Observability.Sampling
+	if sampling.Percent == nil || *sampling.Percent != 100 || sampling.AlwaysSampleErrors == nil || !*sampling.AlwaysSampleErrors {
+		t.Errorf("Expected every trace and failed traces to be sampled by default, got %+v", sampling)
+	}
+
+	c.Observability.Sampling.Channels = map[string]float64{"C1": 120}
+	if err := c.ValidateAfterDefaults(); err == nil || !strings.Contains(err.Error(), "sampling.channels.C1") {
+		t.Errorf("Expected a sampling.channels error, got %v", err)
+	}
+	negative := -1.0
+	c.Observability.Sampling = ObservabilitySamplingConfig{Percent: &negative}
+	if err := c.ValidateAfterDefaults(); err == nil || !strings.Contains(err.Error(), "sampling.percent") {
+		t.Errorf("Expected a sampling.percent error, got %v", err)
+	}
+}
+
+func TestSLOValidation(t *testing.T) {
+	c := &Config{}
+	c.LLM.Provider = ProviderOllama
+	c.UseStdIOClient = true
+	c.SLO.Enabled = true
+	c.ApplyDefaults()
+	if c.SLO.Window != "1h" || c.SLO.LatencyP95 != "60s" || c.SLO.ErrorRate != 0.05 || c.SLO.ToolFailureRate != 0.1 || c.SLO.BurnRate != 2 {
+		t.Errorf("Expected default objectives, got %+v", c.SLO)
+	}
+	if err := c.ValidateAfterDefaults(); err != nil {
+		t.Fatalf("Expected the default objectives to be valid, got %v", err)
+	}
+
+	tests := []struct {
+		modify func(*SLOConfig)
+		want   string
+	}{
+		{func(s *SLOConfig) { s.LatencyP95 = "fast" }, "slo.latencyP95"},
+		{func(s *SLOConfig) { s.ErrorRate = 1 }, "slo.errorRate"},
+		{func(s *SLOConfig) { s.BurnRate = 0.5 }, "slo.burnRate"},
+	}
+	for _, tt := range tests {
+		c.ApplyDefaults()
+		original := c.SLO
+		tt.modify(&c.SLO)
+		if err := c.ValidateAfterDefaults(); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Expected an error containing %q, got %v", tt.want, err)
+		}
+		c.SLO = original
+	}
+}
+
+func TestSlackWorkers(t *testing.T) {
+	c := &Config{}
+	c.LLM.Provider = ProviderOllama
+	c.UseStdIOClient = true
+	c.ApplyDefaults()
+	if c.Slack.Workers.MaxConcurrent != 10 || c.Slack.Workers.MaxQueued != 100 || c.Slack.Workers.BusyMessage == "" || c.Slack.Workers.FullMessage == "" {
+		t.Errorf("Expected default workers, got %+v", c.Slack.Workers)
+	}
+
+	c.Slack.Workers.MaxConcurrent = -1
+	if err := c.ValidateAfterDefaults(); err != nil {
+		t.Errorf("Expected -1 to lift the limit, got %v", err)
+	}
+	c.Slack.Workers.MaxQueued = -2
+	if err := c.ValidateAfterDefaults(); err == nil || !strings.Contains(err.Error(), "maxQueued") {
+		t.Errorf("Expected a maxQueued error, got %v", err)
+	}
+}
+
+func TestAgentBudgetValidation(t *testing.T) {
+	newConfig := func(budget AgentBudgetConfig) *Config {
+		c := &Config{}
+		c.LLM.Provider = ProviderOllama
+		c.LLM.AgentBudget = budget
+		c.UseStdIOClient = true
+		c.ApplyDefaults()
+		return c
+	}
+
+	c := newConfig(AgentBudgetConfig{})
+	if err := c.ValidateAfterDefaults(); err != nil || c.LLM.AgentBudget.Enabled() {
+		t.Fatalf("Expected an unlimited budget by default, got %v", err)
+	}
+	c = newConfig(AgentBudgetConfig{MaxToolCalls: 5, MaxTokens: 20000, MaxDuration: "90s"})
+	if err := c.ValidateAfterDefaults(); err != nil {
+		t.Fatalf("Expected the budget to be valid, got %v", err)
+	}
+	if !c.LLM.AgentBudget.Enabled() || c.LLM.AgentBudget.GetMaxDuration() != 90*time.Second {
+		t.Errorf("Expected a 90s budget, got %+v", c.LLM.AgentBudget)
+	}
+
+	tests := []struct {
+		budget AgentBudgetConfig
+		want   string
+	}{
+		{AgentBudgetConfig{MaxToolCalls: -1}, "maxToolCalls"},
+		{AgentBudgetConfig{MaxTokens: -1}, "maxTokens"},
+		{AgentBudgetConfig{MaxDuration: "soon"}, "invalid maxDuration"},
+	}
+	for _, tt := range tests {
+		if err := newConfig(tt.budget).ValidateAfterDefaults(); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Expected an error containing %q for %+v, got %v", tt.want, tt.budget, err)
+		}
+	}
+}
+
+func TestAgentTypeValidation(t *testing.T) {
+	c := &Config{}
+	c.LLM.Provider = ProviderOllama
+	c.UseStdIOClient = true
+	c.ApplyDefaults()
+	if c.LLM.AgentType != AgentTypeConversational {
+		t.Errorf("Expected the conversational agent by default, got %q", c.LLM.AgentType)
+	}
+	c.LLM.AgentType = AgentTypeTools
+	if err := c.ValidateAfterDefaults(); err != nil {
+		t.Errorf("Expected the tools agent to be valid, got %v", err)
+	}
+	c.LLM.AgentType = "react"
+	if err := c.ValidateAfterDefaults(); err == nil || !strings.Contains(err.Error(), "agentType") {
+		t.Errorf("Expected an agentType error, got %v", err)
+	}
+}
+
+func TestScratchpadDefaults(t *testing.T) {
+	c := &Config{}
+	c.LLM.Provider = ProviderOllama
+	c.UseStdIOClient = true
+	c.ApplyDefaults()
+	if c.Slack.Scratchpad.MaxThreads != 500 || !c.Slack.Scratchpad.Resumes() {
+		t.Errorf("Expected 500 threads and resumed follow-ups by default, got %+v", c.Slack.Scratchpad)
+	}
+
+	resume := false
+	c.Slack.Scratchpad = SlackScratchpadConfig{Enabled: true, MaxThreads: -1, Resume: &resume}
+	if c.Slack.Scratchpad.Resumes() {
+		t.Error("Expected resume to be disabled")
+	}
+	if err := c.ValidateAfterDefaults(); err == nil || !strings.Contains(err.Error(), "maxThreads") {
+		t.Errorf("Expected a maxThreads error, got %v", err)
+	}
+}
+
+func TestHistoryTokens(t *testing.T) {
+	c := &Config{}
+	c.LLM.Provider = ProviderOllama
+	c.UseStdIOClient = true
+	c.ApplyDefaults()
+	if c.Slack.HistoryTokens.MaxTokens != 8000 || c.Slack.HistoryTokens.Weight("tool") != 1 {
+		t.Errorf("Expected 8000 tokens and unweighted roles by default, got %+v", c.Slack.HistoryTokens)
+	}
+
+	c.Slack.HistoryTokens.RoleWeights = map[string]float64{"tool": 2}
+	if err := c.ValidateAfterDefaults(); err != nil || c.Slack.HistoryTokens.Weight("tool") != 2 {
+		t.Errorf("Expected a tool weight of 2 to be valid, got %v", err)
+	}
+	c.Slack.HistoryTokens.RoleWeights = map[string]float64{"system": 2}
+	if err := c.ValidateAfterDefaults(); err == nil || !strings.Contains(err.Error(), "historyTokens role") {
+		t.Errorf("Expected a historyTokens role error, got %v", err)
+	}
+	c.Slack.HistoryTokens = SlackHistoryTokensConfig{MaxTokens: -2}
+	if err := c.ValidateAfterDefaults(); err == nil || !strings.Contains(err.Error(), "maxTokens") {
+		t.Errorf("Expected a maxTokens error, got %v", err)
+	}
+	c.Slack.HistoryTokens = SlackHistoryTokensConfig{}
+	if c.Slack.ToolHistory.MaxResultChars != 4000 {
+		t.Errorf("Expected 4000 characters of tool results by default, got %d", c.Slack.ToolHistory.MaxResultChars)
+	}
+	c.Slack.ToolHistory.MaxResultChars = -2
+	if err := c.ValidateAfterDefaults(); err == nil || !strings.Contains(err.Error(), "maxResultChars") {
+		t.Errorf("Expected a maxResultChars error, got %v", err)
+	}
+	c.Slack.ToolHistory.MaxResultChars = 4000
+	if c.Slack.ToolHistory.DiffRepeated || c.Slack.ToolHistory.MaxDiffLines != 40 {
+		t.Errorf("Expected diffing off with 40 diff lines by default, got %+v", c.Slack.ToolHistory)
+	}
+	c.Slack.ToolHistory.MaxDiffLines = -1
+	if err := c.ValidateAfterDefaults(); err == nil || !strings.Contains(err.Error(), "maxDiffLines") {
+		t.Errorf("Expected a maxDiffLines error, got %v", err)
+	}
+}
+
+func TestMemoryDefaultsAndSlashCommand(t *testing.T) {
+	c := &Config{}
+	c.LLM.Provider = ProviderOllama
+	c.UseStdIOClient = true
+	c.ApplyDefaults()
+	if c.Memory.MaxFacts != 1000 || c.Memory.MaxRecalled != 10 {
+		t.Errorf("Expected 1000 facts and 10 recalled by default, got %+v", c.Memory)
+	}
+	if c.Slack.SlashCommand != "/mcp" || c.Slack.HTTP.CommandsPath != "/slack/commands" {
+		t.Errorf("Expected the /mcp command on /slack/commands by default, got %q on %q", c.Slack.SlashCommand, c.Slack.HTTP.CommandsPath)
+	}
+
+	c.Memory.MaxRecalled = -1
+	if err := c.ValidateAfterDefaults(); err == nil || !strings.Contains(err.Error(), "memory") {
+		t.Errorf("Expected a memory error, got %v", err)
+	}
+	c.Memory.MaxRecalled = 10
+	c.Slack.SlashCommand = "mcp"
+	if err := c.ValidateAfterDefaults(); err == nil || !strings.Contains(err.Error(), "slashCommand") {
+		t.Errorf("Expected a slashCommand error, got %v", err)
+	}
+}
+
+func TestThreadAffinityDefaults(t *testing.T) {
+	c := &Config{}
+	c.LLM.Provider = ProviderOllama
+	c.UseStdIOClient = true
+	c.LLM.ThreadAffinity.Enabled = true
+	c.ApplyDefaults()
+	if c.LLM.ThreadAffinity.MaxThreads != 10000 {
+		t.Errorf("Expected 10000 threads by default, got %d", c.LLM.ThreadAffinity.MaxThreads)
+	}
+	c.LLM.ThreadAffinity.MaxThreads = -1
+	if err := c.ValidateAfterDefaults(); err == nil || !strings.Contains(err.Error(), "maxThreads") {
+		t.Errorf("Expected a maxThreads error, got %v", err)
+	}
+}
+
+func TestJobsValidation(t *testing.T) {
+	c := &Config{}
+	c.LLM.Provider = ProviderOllama
+	c.UseStdIOClient = true
+	c.Jobs.Enabled = true
+	c.ApplyDefaults()
+	if c.Jobs.MaxJobs != 100 || c.Jobs.MaxDelay != "720h" || c.Jobs.Timezone != "UTC" {
+		t.Errorf("Expected 100 jobs up to 720h ahead in UTC by default, got %+v", c.Jobs)
+	}
+	if err := c.ValidateAfterDefaults(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	c.Jobs.MaxDelay = "-1h"
+	if err := c.ValidateAfterDefaults(); err == nil || !strings.Contains(err.Error(), "maxDelay") {
+		t.Errorf("Expected a maxDelay error, got %v", err)
+	}
+	c.Jobs.MaxDelay = "48h"
+	c.Jobs.Timezone = "Mars/Olympus"
+	if err := c.ValidateAfterDefaults(); err == nil || !strings.Contains(err.Error(), "timezone") {
+		t.Errorf("Expected a timezone error, got %v", err)
+	}
+}
+
+func TestRAGFreshness(t *testing.T) {
+	c := &Config{}
+	c.LLM.Provider = ProviderOllama
+	c.UseStdIOClient = true
+	c.RAG.Enabled = true
+	c.ApplyDefaults()
+	if c.RAG.Freshness.GetHalfLife() != 0 || c.RAG.Freshness.GetStaleAfter() != 0 {
+		t.Errorf("Expected freshness to be disabled by default, got %+v", c.RAG.Freshness)
+	}
+
+	c.RAG.Freshness = RAGFreshnessConfig{HalfLife: "2160h", StaleAfter: "4320h"}
+	if err := c.ValidateAfterDefaults(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if c.RAG.Freshness.GetHalfLife() != 90*24*time.Hour || c.RAG.Freshness.GetStaleAfter() != 180*24*time.Hour {
+		t.Errorf("Unexpected freshness durations: %+v", c.RAG.Freshness)
+	}
+
+	c.RAG.Freshness.StaleAfter = "90d"
+	if err := c.ValidateAfterDefaults(); err == nil || !strings.Contains(err.Error(), "freshness.staleAfter") {
+		t.Errorf("Expected a staleAfter error, got %v", err)
+	}
+}
+
+func TestRAGGCValidation(t *testing.T) {
+	c := &Config{}
+	c.LLM.Provider = ProviderOllama
+	c.UseStdIOClient = true
+	c.RAG.Enabled = true
+	c.RAG.Provider = "openai"
+	c.ApplyDefaults()
+	if c.RAG.GC.Enabled || c.RAG.GC.GetInterval() != 24*time.Hour || c.RAG.GC.GetMinAge() != 24*time.Hour {
+		t.Errorf("Unexpected gc defaults: %+v", c.RAG.GC)
+	}
+
+	c.RAG.GC.Enabled = true
+	if err := c.ValidateAfterDefaults(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	c.RAG.GC.MinAge = "1 day"
+	if err := c.ValidateAfterDefaults(); err == nil || !strings.Contains(err.Error(), "gc.minAge") {
+		t.Errorf("Expected a minAge error, got %v", err)
+	}
+
+	c.RAG.GC.MinAge = "24h"
+	c.RAG.Provider = "sqlite"
+	if err := c.ValidateAfterDefaults(); err == nil || !strings.Contains(err.Error(), "only supported by the openai provider") {
+		t.Errorf("Expected a provider error, got %v", err)
+	}
+}
+
+func TestNetworkProxyValidation(t *testing.T) {
+	c := &Config{}
+	c.LLM.Provider = ProviderOllama
+	c.UseStdIOClient = true
+	c.ApplyDefaults()
+	for proxyURL, valid := range map[string]bool{
+		"":                                   true,
+		"http://proxy.corp.example.com:3128": true,
+		"socks5://127.0.0.1:1080":            true,
+		"ftp://proxy.corp.example.com":       false,
+		"proxy.corp.example.com:3128":        false,
+	} {
+		c.Network.ProxyURL = proxyURL
+		err := c.ValidateAfterDefaults()
+		if valid && err != nil {
+			t.Errorf("Expected proxyUrl %q to be valid, got %v", proxyURL, err)
+		}
+		if !valid && (err == nil || !strings.Contains(err.Error(), "proxyUrl")) {
+			t.Errorf("Expected a proxyUrl error for %q, got %v", proxyURL, err)
+		}
+	}
+}
+
+func TestMCPServerTLSValidation(t *testing.T) {
+	for _, tc := range []struct {
+		server  MCPServerConfig
+		wantErr string
+	}{
+		{MCPServerConfig{URL: "https://mcp.mesh.local/sse", TLS: MCPTLSConfig{CertFile: "tls.crt", KeyFile: "tls.key"}}, ""},
+		{MCPServerConfig{URL: "https://mcp.mesh.local/mcp", Transport: "http", TLS: MCPTLSConfig{Cert: "PEM", Key: "PEM", CAFile: "ca.crt"}}, ""},
+		{MCPServerConfig{URL: "https://mcp.mesh.local/sse", TLS: MCPTLSConfig{CertFile: "tls.crt"}}, "certFile and keyFile must be set together"},
+		{MCPServerConfig{URL: "https://mcp.mesh.local/sse", TLS: MCPTLSConfig{CertFile: "tls.crt", KeyFile: "tls.key", Key: "PEM"}}, "not both"},
+		{MCPServerConfig{Command: "server", TLS: MCPTLSConfig{CertFile: "tls.crt", KeyFile: "tls.key"}}, "requires an sse or http transport"},
+	} {
+		c := &Config{}
+		c.LLM.Provider = ProviderOllama
+		c.UseStdIOClient = true
+		c.MCPServers = map[string]MCPServerConfig{"mesh": tc.server}
+		c.ApplyDefaults()
+		err := c.ValidateAfterDefaults()
+		if tc.wantErr == "" && err != nil {
+			t.Errorf("Unexpected error for %+v: %v", tc.server.TLS, err)
+		}
+		if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+			t.Errorf("Expected error containing %q for %+v, got %v", tc.wantErr, tc.server.TLS, err)
+		}
+	}
+}
+
+func TestMCPConnectionsDefaults(t *testing.T) {
+	c := &Config{}
+	c.LLM.Provider = ProviderOllama
+	c.UseStdIOClient = true
+	c.ApplyDefaults()
+	connections := c.MCPConnections
+	if connections.GetMaxIdleConns() != 100 || connections.GetMaxIdleConnsPerHost() != 10 ||
+		connections.GetIdleConnTimeout() != 90*time.Second || connections.GetKeepAliveInterval() != 30*time.Second {
+		t.Errorf("Unexpected connection pool defaults: %+v", connections)
+	}
+	reconnect := connections.Reconnect
+	if reconnect.GetMaxAttempts() != 5 || reconnect.GetInitialBackoff() != time.Second ||
+		reconnect.GetMaxBackoff() != 30*time.Second || reconnect.GetJitter() != 0.5 {
+		t.Errorf("Unexpected reconnect defaults: %+v", reconnect)
+	}
+
+	noJitter := 0.0
+	c.MCPConnections.Reconnect.Jitter = &noJitter
+	if err := c.ValidateAfterDefaults(); err != nil || c.MCPConnections.Reconnect.GetJitter() != 0 {
+		t.Errorf("Expected jitter 0 to be accepted, got %v", err)
+	}
+	tooMuch := 1.5
+	c.MCPConnections.Reconnect.Jitter = &tooMuch
+	if err := c.ValidateAfterDefaults(); err == nil || !strings.Contains(err.Error(), "jitter") {
+		t.Errorf("Expected a jitter error, got %v", err)
+	}
+	c.MCPConnections.Reconnect.Jitter = nil
+	c.MCPConnections.IdleConnTimeout = "forever"
+	if err := c.ValidateAfterDefaults(); err == nil || !strings.Contains(err.Error(), "idleConnTimeout") {
+		t.Errorf("Expected an idleConnTimeout error, got %v", err)
+	}
+}
+
+func TestSlackPostProcessorValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		processor SlackPostProcessorConfig
+		wantErr   string
+	}{
+		{"append", SlackPostProcessorConfig{Type: PostProcessorAppend, Text: "AI-generated"}, ""},
+		{"append without text", SlackPostProcessorConfig{Type: PostProcessorAppend}, "needs text"},
+		{"strip", SlackPostProcessorConfig{Type: PostProcessorStrip, Pattern: `secret-\w+`}, ""},
+		{"strip with invalid pattern", SlackPostProcessorConfig{Type: PostProcessorStrip, Pattern: "("}, "invalid pattern"},
+		{"rewrite", SlackPostProcessorConfig{Type: PostProcessorRewriteLinks, Template: "https://go.corp/?u={url}"}, ""},
+		{"rewrite without placeholder", SlackPostProcessorConfig{Type: PostProcessorRewriteLinks, Template: "https://go.corp/"}, "{url}"},
+		{"unknown type", SlackPostProcessorConfig{Type: "prepend"}, "unknown slack post-processor type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{}
+			c.LLM.Provider = ProviderOllama
+			c.UseStdIOClient = true
+			c.Slack.PostProcessors = []SlackPostProcessorConfig{tt.processor}
+			c.ApplyDefaults()
+			err := c.ValidateAfterDefaults()
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+	if !(SlackPostProcessorConfig{Channels: []string{"C1"}}).AppliesTo("C1") || (SlackPostProcessorConfig{Channels: []string{"C1"}}).AppliesTo("C2") {
+		t.Error("Expected the processor to apply only to its channels")
+	}
+}
+
+func TestFrontendValidation(t *testing.T) {
+	t.Setenv("DISCORD_BOT_TOKEN", "")
+	t.Setenv("TEAMS_APP_ID", "")
+	t.Setenv("TEAMS_APP_PASSWORD", "")
+	t.Setenv("MATTERMOST_URL", "")
+	t.Setenv("MATTERMOST_BOT_TOKEN", "")
+	newConfig := func(frontend string) *Config {
+		c := &Config{Frontend: frontend}
+		c.LLM.Provider = ProviderOllama
+		c.ApplyDefaults()
+		return c
+	}
+
+	c := newConfig(FrontendDiscord)
+	if err := c.ValidateAfterDefaults(); err == nil || !strings.Contains(err.Error(), "DISCORD_BOT_TOKEN") {
+		t.Errorf("Expected a missing Discord token error, got %v", err)
+	}
+
+	// Slack tokens are not needed with the Discord frontend
+	c = newConfig(FrontendDiscord)
+	c.Discord.BotToken = "discord-token"
+	if err := c.ValidateAfterDefaults(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if c.IsSlackFrontend() || c.Discord.APIURL != "https://discord.com/api/v10" {
+		t.Errorf("Unexpected Discord defaults: %+v", c.Discord)
+	}
+
+	c = newConfig(FrontendTeams)
+	c.Teams.AppID = "app-id"
+	if err := c.ValidateAfterDefaults(); err == nil || !strings.Contains(err.Error(), "TEAMS_APP_PASSWORD") {
+		t.Errorf("Expected a missing Teams password error, got %v", err)
+	}
+	c.Teams.AppPassword = "secret"
+	if err := c.ValidateAfterDefaults(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if c.Teams.TokenURL != "https://login.microsoftonline.com/botframework.com/oauth2/v2.0/token" || c.Teams.ListenAddr != ":3978" {
+		t.Errorf("Unexpected Teams defaults: %+v", c.Teams)
+	}
+
+	c = newConfig(FrontendMattermost)
+	c.Mattermost.BotToken = "mm-token"
+	c.Mattermost.URL = "chat.example.com"
+	if err := c.ValidateAfterDefaults(); err == nil || !strings.Contains(err.Error(), "http or https URL") {
+		t.Errorf("Expected an invalid Mattermost URL error, got %v", err)
+	}
+	c.Mattermost.URL = "https://chat.example.com"
+	if err := c.ValidateAfterDefaults(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	c = newConfig("irc")
+	if err := c.ValidateAfterDefaults(); err == nil || !strings.Contains(err.Error(), "unknown frontend") {
+		t.Errorf("Expected an unknown frontend error, got %v", err)
+	}
+
+	if c := newConfig(""); c.Frontend != FrontendSlack || !c.IsSlackFrontend() {
+		t.Errorf("Expected the slack frontend by default, got %q", c.Frontend)
+	}
+}
+
+func TestSchemaFileIsUpToDate(t *testing.T) {
+	generated, err := SchemaJSON()
+	if err != nil {
+		t.Fatalf("Failed to generate schema: %v", err)
+	}
+	committed, err := os.ReadFile("../../schema/config-schema.json")
+	if err != nil {
+		t.Fatalf("Failed to read schema file: %v", err)
+	}
+	if strings.TrimSpace(string(committed)) != string(generated) {
+		t.Error("schema/config-schema.json is stale; regenerate it with the config schema command")
+	}
+
+	c := &Config{}
+	c.ApplyDefaults()
+	if err := c.ValidateConfig(); err != nil {
+		t.Errorf("Expected the default configuration to match the schema, got %v", err)
+	}
+}
+
+func TestCheckConfigFileReportsEveryProblem(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.json")
+	content := `{
+  "version": "2.0",
+  "slack": {"botToken": "xoxb-1", "appTokn": "xapp-1"},
+  "llm": {"maxAgentIterations": "5"},
+  "timeouts": {"pingTimeout": "5", "httpRequestTimeout": 30},
+  "mcpServers": {"files": {"builtin": "filesystem", "args": "/data"}}
+}`
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	problems := CheckConfigFile(file)
+	expected := []Problem{
+		{File: file, Path: "$.llm.maxAgentIterations", Message: "expected an integer, got a string", Suggestion: "remove the quotes: 5"},
+		{File: file, Path: "$.mcpServers.files.args", Message: "expected an array, got a string", Suggestion: "wrap the value in [ ]"},
+		{File: file, Path: "$.slack.appTokn", Message: "unknown key 'appTokn'", Suggestion: "did you mean 'appToken'?"},
+		{File: file, Path: "$.timeouts.httpRequestTimeout", Message: "expected a duration string, got a number", Suggestion: `write "30s" for 30 seconds`},
+		{File: file, Path: "$.timeouts.pingTimeout", Message: "invalid duration '5'", Suggestion: "add a unit, e.g. '5s'"},
+	}
+	if len(problems) != len(expected) {
+		t.Fatalf("Expected %d problems, got %d:\n%v", len(expected), len(problems), problems)
+	}
+	for i := range expected {
+		if problems[i] != expected[i] {
+			t.Errorf("Problem %d: expected %q, got %q", i, expected[i], problems[i])
+		}
+	}
+
+	if err := os.WriteFile(file, []byte(`{"version": "2.0",`+"\n"+`"slack": }`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	problems = CheckConfigFile(file)
+	if len(problems) != 1 || !strings.Contains(problems[0].Message, "line 2, column 10") {
+		t.Errorf("Expected a syntax error with its position, got %v", problems)
+	}
+}
+
+func TestClosestName(t *testing.T) {
+	names := []string{"botToken", "appToken", "signingSecret"}
+	if got := closestName("botTokn", names); got != "botToken" {
+		t.Errorf("Expected botToken, got %q", got)
+	}
+	if got := closestName("unrelated", names); got != "" {
+		t.Errorf("Expected no suggestion, got %q", got)
+	}
+}
+
+func TestMigrateLegacyMCPServers(t *testing.T) {
+	legacy := `{
+  "mcpServers": {
+    "github": {"command": "github-mcp-server", "args": ["stdio"], "env": {"GITHUB_TOKEN": "${GITHUB_TOKEN}"},
+               "initialize_timeout_seconds": 60, "allow_list": ["search_issues"]},
+    "remote": {"url": "https://mcp.example.com/sse"},
+    "api": {"url": "https://mcp.example.com/mcp", "mode": "http", "block_list": ["delete_repo"]}
+  }
+}`
+	migration, err := MigrateLegacyConfig([]byte(legacy))
+	if err != nil {
+		t.Fatalf("Migration failed: %v", err)
+	}
+	if migration.Format != LegacyFormatMCPServers {
+		t.Errorf("Expected the mcp-servers.json format, got %s", migration.Format)
+	}
+
+	github := migration.Config.MCPServers["github"]
+	if github.Transport != "stdio" || github.Command != "github-mcp-server" || github.Env["GITHUB_TOKEN"] != "${GITHUB_TOKEN}" {
+		t.Errorf("Unexpected github server: %+v", github)
+	}
+	if github.InitializeTimeoutSeconds == nil || *github.InitializeTimeoutSeconds != 60 {
+		t.Errorf("Expected initialize_timeout_seconds to be migrated, got %v", github.InitializeTimeoutSeconds)
+	}
+	if len(github.Tools.AllowList) != 1 || github.Tools.AllowList[0] != "search_issues" {
+		t.Errorf("Expected allow_list to be migrated, got %v", github.Tools.AllowList)
+	}
+	if transport := migration.Config.MCPServers["remote"].Transport; transport != "sse" {
+		t.Errorf("Expected a url-only server to use sse, got %s", transport)
+	}
+	api := migration.Config.MCPServers["api"]
+	if api.Transport != "http" || len(api.Tools.BlockList) != 1 {
+		t.Errorf("Expected mode and block_list to be migrated, got %+v", api)
+	}
+	if len(migration.Notes) != 3 {
+		t.Errorf("Expected a note per server, got %v", migration.Notes)
+	}
+
+	// The written file is in the current format and loads strictly
+	migrated, err := migration.JSON()
+	if err != nil {
+		t.Fatalf("Failed to render the migrated config: %v", err)
+	}
+	if !strings.HasPrefix(string(migrated), "{\n  \"$schema\": ") {
+		t.Errorf("Expected the schema reference first, got %s", migrated)
+	}
+	var raw interface{}
+	if err := json.Unmarshal(migrated, &raw); err != nil {
+		t.Fatalf("Migrated config is not JSON: %v", err)
+	}
+	var problems Problems
+	checkValue(raw, reflect.TypeOf(Config{}), "", "$", &problems)
+	if len(problems) > 0 {
+		t.Errorf("Expected the migrated config to be valid, got:\n%v", problems)
+	}
+}
+
+func TestMigrateLegacyFlatConfig(t *testing.T) {
+	legacy := `{
+  "slack_bot_token": "${BOT}",
+  "llm_provider": "anthropic",
+  "use_agent": true,
+  "custom_prompt": "Be brief.",
+  "llm_providers": {"anthropic": {"model": "claude-sonnet-4", "api_key": "${KEY}", "max_tokens": 2048}},
+  "servers": {"fs": {"command": "npx", "args": ["-y", "server-filesystem"]}}
+}`
+	migration, err := MigrateLegacyConfig([]byte(legacy))
+	if err != nil {
+		t.Fatalf("Migration failed: %v", err)
+	}
+	cfg := migration.Config
+	if migration.Format != LegacyFormatFlat {
+		t.Errorf("Expected the flat config.json format, got %s", migration.Format)
+	}
+	if cfg.Slack.BotToken != "${BOT}" || cfg.Slack.AppToken != "${SLACK_APP_TOKEN}" {
+		t.Errorf("Unexpected Slack tokens: %+v", cfg.Slack)
+	}
+	if cfg.LLM.Provider != ProviderAnthropic || !cfg.LLM.UseAgent || cfg.LLM.CustomPrompt != "Be brief." {
+		t.Errorf("Unexpected LLM settings: %+v", cfg.LLM)
+	}
+	anthropic := cfg.LLM.Providers[ProviderAnthropic]
+	if anthropic.Model != "claude-sonnet-4" || anthropic.APIKey != "${KEY}" || anthropic.MaxTokens != 2048 {
+		t.Errorf("Unexpected anthropic provider: %+v", anthropic)
+	}
+	if _, ok := cfg.LLM.Providers[ProviderOpenAI]; !ok {
+		t.Error("Expected the base providers to be kept")
+	}
+	if cfg.MCPServers["fs"].Transport != "stdio" {
+		t.Errorf("Expected the fs server to be migrated, got %+v", cfg.MCPServers)
+	}
+
+	if _, err := MigrateLegacyConfig([]byte(`{"version": "2.0"}`)); err == nil {
+		t.Error("Expected an error for a file in neither legacy format")
+	}
+}
+
+func TestMCPServersFromEnv(t *testing.T) {
+	t.Setenv("SLACK_BOT_TOKEN", "xoxb-test")
+	t.Setenv("SLACK_APP_TOKEN", "xapp-test")
+	t.Setenv("OPENAI_API_KEY", "sk-test")
+	t.Setenv(EnvMCPServersJSON, `{"time": {"builtin": "time"}, "github": {"url": "https://mcp.example.com/mcp", "transport": "http"}}`)
+
+	cfg, err := LoadConfig("", nil)
+	if err != nil {
+		t.Fatalf("Expected an environment-only config to load, got %v", err)
+	}
+	if len(cfg.MCPServers) != 2 || cfg.MCPServers["time"].Builtin != BuiltinTime {
+		t.Errorf("Expected the servers from %s, got %+v", EnvMCPServersJSON, cfg.MCPServers)
+	}
+
+	// A config file replaces servers with the same name and keeps the others
+	file := filepath.Join(t.TempDir(), "config.json")
+	content := `{"version": "2.0", "mcpServers": {"github": {"command": "github-mcp-server"}}}`
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = LoadConfig(file, nil)
+	if err != nil {
+		t.Fatalf("Expected the config file to load, got %v", err)
+	}
+	if cfg.MCPServers["github"].Command != "github-mcp-server" || cfg.MCPServers["github"].URL != "" {
+		t.Errorf("Expected the file's github server to win, got %+v", cfg.MCPServers["github"])
+	}
+	if _, ok := cfg.MCPServers["time"]; !ok {
+		t.Error("Expected the time server from the environment to be kept")
+	}
+
+	t.Setenv(EnvMCPServersJSON, `{"mcpServers": {"time": {"builtin": "time"}}}`)
+	if cfg, err = LoadConfig("", nil); err != nil || len(cfg.MCPServers) != 1 {
+		t.Errorf("Expected the mcpServers wrapper to be accepted, got %v", err)
+	}
+
+	t.Setenv(EnvMCPServersJSON, `{"time": {"builtn": "time"}}`)
+	if _, err := LoadConfig("", nil); err == nil || !strings.Contains(err.Error(), EnvMCPServersJSON) {
+		t.Errorf("Expected an error naming %s for an unknown key, got %v", EnvMCPServersJSON, err)
+	}
+	if problems := CheckConfigFile(""); len(problems) != 1 || problems[0].Path != EnvMCPServersJSON+".time.builtn" {
+		t.Errorf("Expected the unknown key to be reported with its path, got %v", problems)
+	}
+}
+
+func TestConfigOverlays(t *testing.T) {
+	t.Setenv("SLACK_BOT_TOKEN", "")
+	t.Setenv("OPENAI_API_KEY", "")
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	base := write("config.json", `{
+  "version": "2.0",
+  "slack": {"botToken": "xoxb-shared", "appToken": "xapp-shared"},
+  "llm": {"provider": "openai", "providers": {"openai": {"model": "gpt-4o", "apiKey": "sk-shared"}}},
+  "mcpServers": {
+    "github": {"url": "https://staging.example.com/mcp", "transport": "http", "tools": {"allowList": ["a", "b"]}},
+    "calc": {"builtin": "calculator"}
+  }
+}`)
+	if path := ProfilePath(base, "prod"); path != filepath.Join(dir, "config.prod.json") {
+		t.Errorf("Unexpected profile path %s", path)
+	}
+	prod := write("config.prod.json", `{
+  "slack": {"botToken": "xoxb-prod"},
+  "mcpServers": {"github": {"url": "https://prod.example.com/mcp", "tools": {"allowList": ["a"]}}, "calc": null}
+}`)
+	extra := write("extra.json", `{"llm": {"providers": {"openai": {"model": "gpt-4.1"}}}}`)
+
+	cfg, err := LoadConfig(base, nil, prod, extra)
+	if err != nil {
+		t.Fatalf("Expected the overlays to load, got %v", err)
+	}
+	if cfg.Slack.BotToken != "xoxb-prod" || cfg.Slack.AppToken != "xapp-shared" {
+		t.Errorf("Expected the overlay to replace only the bot token, got %+v", cfg.Slack)
+	}
+	github := cfg.MCPServers["github"]
+	if github.URL != "https://prod.example.com/mcp" || github.Transport != "http" {
+		t.Errorf("Expected the github server to be merged, got %+v", github)
+	}
+	if len(github.Tools.AllowList) != 1 {
+		t.Errorf("Expected arrays to be replaced, got %v", github.Tools.AllowList)
+	}
+	if _, ok := cfg.MCPServers["calc"]; ok {
+		t.Error("Expected null to remove the calc server")
+	}
+	if openai := cfg.LLM.Providers[ProviderOpenAI]; openai.Model != "gpt-4.1" || openai.APIKey != "sk-shared" {
+		t.Errorf("Expected later overlays to apply last, got %+v", openai)
+	}
+
+	bad := write("bad.json", `{"slack": {"botTokn": "x"}}`)
+	problems := CheckConfigFile(base, bad)
+	if len(problems) != 1 || problems[0].File != bad || problems[0].Path != "$.slack.botTokn" {
+		t.Errorf("Expected the overlay's unknown key to be reported with its file, got %v", problems)
+	}
+	if _, err := LoadConfig(base, nil, filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("Expected an error for a missing overlay")
+	}
+}
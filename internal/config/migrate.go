@@ -0,0 +1,254 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Legacy formats recognized by MigrateLegacyConfig
+const (
+	LegacyFormatMCPServers = "mcp-servers.json" // Only an "mcpServers" map
+	LegacyFormatFlat       = "flat config.json" // snake_case settings with a "servers" map
+)
+
+// Migration is a legacy configuration converted to the current format
+type Migration struct {
+	Format string   // Legacy format that was detected
+	Config *Config  // Converted configuration
+	Notes  []string // What was migrated, in order
+}
+
+// legacyMCPServer holds the snake_case and "mode" fields of legacy MCP server
+// entries; fields already in the current format are read into MCPServerConfig
+type legacyMCPServer struct {
+	Mode                     string   `json:"mode"`
+	InitializeTimeoutSeconds *int     `json:"initialize_timeout_seconds"`
+	AllowList                []string `json:"allow_list"`
+	BlockList                []string `json:"block_list"`
+}
+
+// legacyFlatConfig is the snake_case config.json used before version 2.0
+type legacyFlatConfig struct {
+	SlackBotToken     string                       `json:"slack_bot_token"`
+	SlackAppToken     string                       `json:"slack_app_token"`
+	LLMProvider       string                       `json:"llm_provider"`
+	UseNativeTools    *bool                        `json:"use_native_tools"`
+	UseAgent          *bool                        `json:"use_agent"`
+	CustomPrompt      string                       `json:"custom_prompt"`
+	ReplaceToolPrompt *bool                        `json:"replace_tool_prompt"`
+	LLMProviders      map[string]legacyLLMProvider `json:"llm_providers"`
+	Servers           map[string]json.RawMessage   `json:"servers"`
+}
+
+// legacyLLMProvider is a provider entry of the legacy flat config.json
+type legacyLLMProvider struct {
+	Model       string  `json:"model"`
+	APIKey      string  `json:"api_key"`
+	BaseURL     string  `json:"base_url"`
+	Temperature float64 `json:"temperature"`
+	MaxTokens   int     `json:"max_tokens"`
+}
+
+// migrationBase returns the configuration legacy settings are merged into,
+// with tokens and API keys read from the environment
+func migrationBase() *Config {
+	return &Config{
+		Version: "2.0",
+		Slack: SlackConfig{
+			BotToken: "${SLACK_BOT_TOKEN}",
+			AppToken: "${SLACK_APP_TOKEN}",
+		},
+		LLM: LLMConfig{
+			Provider: ProviderOpenAI,
+			Providers: map[string]LLMProviderConfig{
+				ProviderOpenAI:    {Model: "gpt-4o", APIKey: "${OPENAI_API_KEY}", Temperature: 0.7},
+				ProviderAnthropic: {Model: "claude-3-5-sonnet-20241022", APIKey: "${ANTHROPIC_API_KEY}", Temperature: 0.7},
+				ProviderOllama:    {Model: "llama3", BaseURL: "http://localhost:11434", Temperature: 0.7},
+			},
+		},
+		MCPServers: map[string]MCPServerConfig{},
+		Monitoring: MonitoringConfig{
+			Enabled:      true,
+			MetricsPort:  8080,
+			LoggingLevel: "info",
+		},
+	}
+}
+
+// MigrateLegacyConfig converts a legacy mcp-servers.json or snake_case
+// config.json into the current format. Settings the legacy file does not have
+// are filled from a base configuration that reads secrets from the environment.
+func MigrateLegacyConfig(data []byte) (*Migration, error) {
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("failed to parse legacy config: %w", err)
+	}
+
+	migration := &Migration{Config: migrationBase()}
+	var servers map[string]json.RawMessage
+	switch {
+	case keys["mcpServers"] != nil:
+		migration.Format = LegacyFormatMCPServers
+		if err := json.Unmarshal(keys["mcpServers"], &servers); err != nil {
+			return nil, fmt.Errorf("failed to parse mcpServers: %w", err)
+		}
+	case keys["servers"] != nil:
+		migration.Format = LegacyFormatFlat
+		var flat legacyFlatConfig
+		if err := json.Unmarshal(data, &flat); err != nil {
+			return nil, fmt.Errorf("failed to parse legacy config: %w", err)
+		}
+		migration.migrateFlatSettings(flat)
+		servers = flat.Servers
+	default:
+		return nil, fmt.Errorf("could not detect the legacy format: expected a \"mcpServers\" or \"servers\" field")
+	}
+
+	names := make([]string, 0, len(servers))
+	for name := range servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		server, err := migrateMCPServer(servers[name])
+		if err != nil {
+			return nil, fmt.Errorf("failed to migrate MCP server '%s': %w", name, err)
+		}
+		migration.Config.MCPServers[name] = server
+		migration.Notes = append(migration.Notes, fmt.Sprintf("Migrated MCP server: %s (%s)", name, server.GetTransport()))
+	}
+	return migration, nil
+}
+
+// migrateFlatSettings copies the Slack and LLM settings of a flat legacy config
+func (m *Migration) migrateFlatSettings(flat legacyFlatConfig) {
+	cfg := m.Config
+	if flat.SlackBotToken != "" {
+		cfg.Slack.BotToken = flat.SlackBotToken
+	}
+	if flat.SlackAppToken != "" {
+		cfg.Slack.AppToken = flat.SlackAppToken
+	}
+	if flat.LLMProvider != "" {
+		cfg.LLM.Provider = flat.LLMProvider
+	}
+	if flat.UseNativeTools != nil {
+		cfg.LLM.UseNativeTools = *flat.UseNativeTools
+	}
+	if flat.UseAgent != nil {
+		cfg.LLM.UseAgent = *flat.UseAgent
+	}
+	if flat.ReplaceToolPrompt != nil {
+		cfg.LLM.ReplaceToolPrompt = *flat.ReplaceToolPrompt
+	}
+	cfg.LLM.CustomPrompt = flat.CustomPrompt
+
+	names := make([]string, 0, len(flat.LLMProviders))
+	for name := range flat.LLMProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		provider := flat.LLMProviders[name]
+		cfg.LLM.Providers[name] = LLMProviderConfig{
+			Model:       provider.Model,
+			APIKey:      provider.APIKey,
+			BaseURL:     provider.BaseURL,
+			Temperature: provider.Temperature,
+			MaxTokens:   provider.MaxTokens,
+		}
+		m.Notes = append(m.Notes, fmt.Sprintf("Migrated LLM provider: %s", name))
+	}
+}
+
+// migrateMCPServer converts a legacy MCP server entry. "mode" becomes the
+// transport, which is otherwise inferred from command or url.
+func migrateMCPServer(data json.RawMessage) (MCPServerConfig, error) {
+	var server MCPServerConfig
+	if err := json.Unmarshal(data, &server); err != nil {
+		return server, err
+	}
+	var legacy legacyMCPServer
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return server, err
+	}
+
+	switch {
+	case legacy.Mode != "":
+		server.Transport = legacy.Mode
+	case server.Transport != "" || server.Builtin != "":
+	case server.URL != "" && server.Command == "":
+		server.Transport = "sse"
+	default:
+		server.Transport = "stdio"
+	}
+	if legacy.InitializeTimeoutSeconds != nil {
+		server.InitializeTimeoutSeconds = legacy.InitializeTimeoutSeconds
+	}
+	if len(legacy.AllowList) > 0 {
+		server.Tools.AllowList = legacy.AllowList
+	}
+	if len(legacy.BlockList) > 0 {
+		server.Tools.BlockList = legacy.BlockList
+	}
+	return server, nil
+}
+
+// JSON returns the migrated configuration as indented JSON in field order,
+// leaving out the sections the migration did not set
+func (m *Migration) JSON() ([]byte, error) {
+	data, err := json.Marshal(m.Config)
+	if err != nil {
+		return nil, err
+	}
+	pruned, err := pruneEmpty(data)
+	if err != nil {
+		return nil, err
+	}
+	// Add the schema reference first, for editor support
+	pruned = append([]byte(`{"$schema":`+strconv.Quote(SchemaID)+","), pruned[1:]...)
+	var out bytes.Buffer
+	if err := json.Indent(&out, pruned, "", "  "); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// pruneEmpty drops null members and empty objects from a JSON object, keeping
+// the order of the remaining members
+func pruneEmpty(object json.RawMessage) (json.RawMessage, error) {
+	dec := json.NewDecoder(bytes.NewReader(object))
+	if _, err := dec.Token(); err != nil { // Opening brace
+		return nil, err
+	}
+	var out bytes.Buffer
+	out.WriteByte('{')
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		if value[0] == '{' {
+			if value, err = pruneEmpty(value); err != nil {
+				return nil, err
+			}
+		}
+		if string(value) == "null" || string(value) == "{}" {
+			continue
+		}
+		if out.Len() > 1 {
+			out.WriteByte(',')
+		}
+		out.WriteString(strconv.Quote(key.(string)) + ":")
+		out.Write(value)
+	}
+	out.WriteByte('}')
+	return out.Bytes(), nil
+}
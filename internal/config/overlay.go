@@ -0,0 +1,65 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// EnvConfigProfile names the environment variable selecting a config profile
+// when --config-profile is not given
+const EnvConfigProfile = "CONFIG_PROFILE"
+
+// ProfilePath returns the overlay file of a profile next to the base config,
+// e.g. config.prod.json for config.json and the profile "prod"
+func ProfilePath(configFile, profile string) string {
+	ext := filepath.Ext(configFile)
+	return strings.TrimSuffix(configFile, ext) + "." + profile + ext
+}
+
+// mergeJSON applies an overlay to a base value as a JSON Merge Patch (RFC 7386):
+// objects are merged key by key, null removes a key, and any other value,
+// including an array, replaces the base value
+func mergeJSON(base, overlay interface{}) interface{} {
+	patch, ok := overlay.(map[string]interface{})
+	if !ok {
+		return overlay
+	}
+	target, ok := base.(map[string]interface{})
+	if !ok {
+		target = map[string]interface{}{}
+	}
+	for key, value := range patch {
+		if value == nil {
+			delete(target, key)
+			continue
+		}
+		target[key] = mergeJSON(target[key], value)
+	}
+	return target
+}
+
+// mergeConfigFiles deep-merges overlay files, in order, into the base config data
+func mergeConfigFiles(configData []byte, overlays []string) ([]byte, error) {
+	var merged interface{}
+	if err := json.Unmarshal(configData, &merged); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	for _, overlay := range overlays {
+		data, err := os.ReadFile(overlay)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config overlay: %w", err)
+		}
+		var patch interface{}
+		if err := json.Unmarshal(data, &patch); err != nil {
+			return nil, fmt.Errorf("failed to parse config overlay %s: %w", overlay, err)
+		}
+		if _, ok := patch.(map[string]interface{}); !ok {
+			return nil, fmt.Errorf("config overlay %s must be a JSON object", overlay)
+		}
+		merged = mergeJSON(merged, patch)
+	}
+	return json.Marshal(merged)
+}
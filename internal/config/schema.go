@@ -0,0 +1,148 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// SchemaID is the URL configuration files reference in their "$schema" key
+const SchemaID = "https://github.com/tuannvm/slack-mcp-client/schema/config-schema.json"
+
+// durationPattern matches Go duration strings such as "500ms", "30s" or "1h30m"
+const durationPattern = `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+
+// durationSuffixes end the names of the string fields that hold Go durations
+var durationSuffixes = []string{"timeout", "backoff", "interval", "ttl", "after", "cooldown", "duration", "life"}
+
+// isDurationField reports whether a string field holds a Go duration, judged by
+// its JSON name (e.g. pingTimeout, maxBackoff, lookupCacheTtl)
+func isDurationField(name string) bool {
+	name = strings.ToLower(name)
+	for _, suffix := range durationSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonField is a struct field as it appears in a configuration file
+type jsonField struct {
+	name  string
+	field reflect.StructField
+	value reflect.Value // Default value of the field; invalid inside maps and slices
+}
+
+// jsonFields lists the fields encoding/json reads into a struct, with their
+// default values when v is valid
+func jsonFields(t reflect.Type, v reflect.Value) []jsonField {
+	var fields []jsonField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		var value reflect.Value
+		if v.IsValid() {
+			value = v.Field(i)
+		}
+		fields = append(fields, jsonField{name: name, field: field, value: value})
+	}
+	return fields
+}
+
+// Schema returns a JSON Schema (draft-07) for the configuration file, generated
+// from the Config struct. Unknown keys are rejected, duration strings must use Go
+// syntax, and scalar defaults come from ApplyDefaults.
+func Schema() map[string]interface{} {
+	defaults := &Config{}
+	defaults.ApplyDefaults()
+
+	schema := typeSchema(reflect.TypeOf(Config{}), reflect.ValueOf(defaults).Elem(), "")
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["$id"] = SchemaID
+	schema["title"] = "Slack MCP Client Configuration"
+	schema["description"] = "Configuration file for the Slack MCP Client (generated with the config schema command)"
+	schema["properties"].(map[string]interface{})["$schema"] = map[string]interface{}{
+		"type":        "string",
+		"description": "JSON Schema reference for editor support",
+	}
+	return schema
+}
+
+// SchemaJSON returns the configuration schema as indented JSON
+func SchemaJSON() ([]byte, error) {
+	return json.MarshalIndent(Schema(), "", "  ")
+}
+
+// typeSchema describes a Go type. name is the JSON name of the field holding it,
+// used to recognize durations; value is its default, when known.
+func typeSchema(t reflect.Type, value reflect.Value, name string) map[string]interface{} {
+	// null leaves the default in place, which is how pointers, slices and maps are unset
+	nullable := t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Map
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		if value.IsValid() {
+			if value.IsNil() {
+				value = reflect.Value{}
+			} else {
+				value = value.Elem()
+			}
+		}
+	}
+
+	schema := map[string]interface{}{}
+	switch t.Kind() {
+	case reflect.String:
+		schema["type"] = "string"
+		if isDurationField(name) {
+			schema["pattern"] = durationPattern
+			schema["description"] = "Go duration such as \"500ms\", \"30s\" or \"1h30m\""
+		}
+	case reflect.Bool:
+		schema["type"] = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		schema["type"] = "integer"
+	case reflect.Float32, reflect.Float64:
+		schema["type"] = "number"
+	case reflect.Slice, reflect.Array:
+		schema["type"] = "array"
+		schema["items"] = typeSchema(t.Elem(), reflect.Value{}, name)
+	case reflect.Map:
+		schema["type"] = "object"
+		if t.Elem().Kind() != reflect.Interface {
+			schema["additionalProperties"] = typeSchema(t.Elem(), reflect.Value{}, "")
+		}
+	case reflect.Struct:
+		properties := map[string]interface{}{}
+		for _, f := range jsonFields(t, value) {
+			properties[f.name] = typeSchema(f.field.Type, f.value, f.name)
+		}
+		schema["type"] = "object"
+		schema["properties"] = properties
+		schema["additionalProperties"] = false
+	case reflect.Interface:
+		// Any JSON value
+	}
+
+	if kind, ok := schema["type"].(string); ok && nullable {
+		schema["type"] = []string{kind, "null"}
+	}
+	if value.IsValid() && t.Kind() != reflect.Struct && !value.IsZero() {
+		switch t.Kind() {
+		case reflect.String, reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+			schema["default"] = value.Interface()
+		}
+	}
+	return schema
+}
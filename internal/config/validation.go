@@ -5,27 +5,125 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
-	"path/filepath"
+	"path"
+	"regexp"
+	"slices"
 	"strings"
+	"text/template"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/santhosh-tekuri/jsonschema/v5"
 	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/schedule"
 )
 
 // ValidateAfterDefaults validates configuration after defaults and env substitution
 func (c *Config) ValidateAfterDefaults() error {
-	if !c.UseStdIOClient {
+	switch c.Frontend {
+	case FrontendSlack, "":
+	case FrontendDiscord:
+		if !c.UseStdIOClient && (c.Discord.BotToken == "" || strings.HasPrefix(c.Discord.BotToken, "${")) {
+			return fmt.Errorf("DISCORD_BOT_TOKEN environment variable not set")
+		}
+	case FrontendTeams:
+		if !c.UseStdIOClient && (c.Teams.AppID == "" || strings.HasPrefix(c.Teams.AppID, "${")) {
+			return fmt.Errorf("TEAMS_APP_ID environment variable not set")
+		}
+		if !c.UseStdIOClient && (c.Teams.AppPassword == "" || strings.HasPrefix(c.Teams.AppPassword, "${")) {
+			return fmt.Errorf("TEAMS_APP_PASSWORD environment variable not set")
+		}
+	case FrontendMattermost:
+		if !c.UseStdIOClient && (c.Mattermost.URL == "" || strings.HasPrefix(c.Mattermost.URL, "${")) {
+			return fmt.Errorf("MATTERMOST_URL environment variable not set")
+		}
+		if !c.UseStdIOClient && (c.Mattermost.BotToken == "" || strings.HasPrefix(c.Mattermost.BotToken, "${")) {
+			return fmt.Errorf("MATTERMOST_BOT_TOKEN environment variable not set")
+		}
+		if c.Mattermost.URL != "" && !strings.HasPrefix(c.Mattermost.URL, "${") {
+			if u, err := url.Parse(c.Mattermost.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("mattermost url must be an http or https URL, got '%s'", c.Mattermost.URL)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown frontend '%s' (expected \"slack\", \"discord\", \"teams\" or \"mattermost\")", c.Frontend)
+	}
+
+	if !c.UseStdIOClient && c.IsSlackFrontend() {
 		// Validate required fields after environment substitution
 		if c.Slack.BotToken == "" || strings.HasPrefix(c.Slack.BotToken, "${") {
 			return fmt.Errorf("SLACK_BOT_TOKEN environment variable not set")
 		}
-		if c.Slack.AppToken == "" || strings.HasPrefix(c.Slack.AppToken, "${") {
-			return fmt.Errorf("SLACK_APP_TOKEN environment variable not set")
+		switch c.Slack.Mode {
+		case SlackModeHTTP:
+			if c.Slack.SigningSecret == "" || strings.HasPrefix(c.Slack.SigningSecret, "${") {
+				return fmt.Errorf("SLACK_SIGNING_SECRET environment variable not set for http mode")
+			}
+		case SlackModeSocket, "":
+			if c.Slack.AppToken == "" || strings.HasPrefix(c.Slack.AppToken, "${") {
+				return fmt.Errorf("SLACK_APP_TOKEN environment variable not set")
+			}
+		default:
+			return fmt.Errorf("unknown slack mode '%s' (expected \"socket\" or \"http\")", c.Slack.Mode)
+		}
+	}
+
+	// Validate outbound message queue durations
+	if c.Slack.Outbound.BaseBackoff != "" {
+		if _, err := time.ParseDuration(c.Slack.Outbound.BaseBackoff); err != nil {
+			return fmt.Errorf("invalid slack outbound baseBackoff '%s': %w", c.Slack.Outbound.BaseBackoff, err)
+		}
+	}
+	if c.Slack.Outbound.MaxBackoff != "" {
+		if _, err := time.ParseDuration(c.Slack.Outbound.MaxBackoff); err != nil {
+			return fmt.Errorf("invalid slack outbound maxBackoff '%s': %w", c.Slack.Outbound.MaxBackoff, err)
 		}
 	}
 
+	// Validate assistant suggested prompts
+	if err := c.validateSlackAssistant(); err != nil {
+		return err
+	}
+
+	// Validate intermediate message retention
+	if err := c.validateSlackIntermediateMessages(); err != nil {
+		return err
+	}
+
+	switch c.Slack.EditedPrompts {
+	case "", EditedPromptsIgnore, EditedPromptsReanswer:
+	default:
+		return fmt.Errorf("unknown slack editedPrompts '%s' (use ignore or reanswer)", c.Slack.EditedPrompts)
+	}
+	if err := c.validateSlackConversations(); err != nil {
+		return err
+	}
+	if err := c.validateSlackReactionActions(); err != nil {
+		return err
+	}
+	if err := c.validateSlackCommands(); err != nil {
+		return err
+	}
+	for kind := range c.Slack.ErrorMessages {
+		if !slices.Contains(ErrorKinds, kind) {
+			return fmt.Errorf("unknown slack errorMessages kind '%s' (use %s)", kind, strings.Join(ErrorKinds, ", "))
+		}
+	}
+	if err := c.validateSlackListeners(); err != nil {
+		return err
+	}
+	if err := c.validateSlackIncidents(); err != nil {
+		return err
+	}
+	if err := c.validateSlackDigests(); err != nil {
+		return err
+	}
+	if err := c.validateSlackPostProcessors(); err != nil {
+		return err
+	}
+
 	// Validate LLM provider exists
 	if _, exists := c.LLM.Providers[c.LLM.Provider]; !exists {
 		return fmt.Errorf("LLM provider '%s' not configured", c.LLM.Provider)
@@ -58,12 +156,1147 @@ func (c *Config) ValidateAfterDefaults() error {
 			}
 		}
 	}
+	sampling := c.Observability.Sampling
+	if sampling.Percent != nil && (*sampling.Percent < 0 || *sampling.Percent > 100) {
+		return fmt.Errorf("observability.sampling.percent must be between 0 and 100, got %v", *sampling.Percent)
+	}
+	for channelID, percent := range sampling.Channels {
+		if percent < 0 || percent > 100 {
+			return fmt.Errorf("observability.sampling.channels.%s must be between 0 and 100, got %v", channelID, percent)
+		}
+	}
+
+	// Validate MCP server identity propagation
+	for name, server := range c.MCPServers {
+		if !server.Identity.Enabled {
+			continue
+		}
+		switch server.Identity.GetMode() {
+		case IdentityModeMeta, IdentityModeArgument:
+		case IdentityModeHeader:
+			if !server.isRemote() {
+				return fmt.Errorf("mcp server '%s': identity mode 'header' requires an sse or http transport", name)
+			}
+		default:
+			return fmt.Errorf("mcp server '%s': unknown identity mode '%s'", name, server.Identity.Mode)
+		}
+	}
+
+	// Validate stdio environment isolation
+	for name, server := range c.MCPServers {
+		if len(server.EnvAllowlist) > 0 && server.InheritsEnv() {
+			return fmt.Errorf("mcp server '%s': envAllowlist only applies with \"inheritEnv\": false", name)
+		}
+	}
+
+	// Validate tool call timeouts
+	for field, value := range map[string]string{"toolProcessingTimeout": c.Timeouts.ToolProcessingTimeout, "toolNoticeAfter": c.Timeouts.ToolNoticeAfter} {
+		if parsed, err := time.ParseDuration(value); err != nil || parsed <= 0 {
+			return fmt.Errorf("invalid timeouts.%s '%s'", field, value)
+		}
+	}
+
+	// Validate the circuit breaker of unreachable servers
+	if c.MCPCircuit.FailureThreshold < 1 {
+		return fmt.Errorf("mcpCircuitBreaker.failureThreshold must be at least 1, got %d", c.MCPCircuit.FailureThreshold)
+	}
+	if parsed, err := time.ParseDuration(c.MCPCircuit.OpenDuration); err != nil || parsed <= 0 {
+		return fmt.Errorf("invalid mcpCircuitBreaker.openDuration '%s'", c.MCPCircuit.OpenDuration)
+	}
+
+	// Validate the startup self-test
+	if parsed, err := time.ParseDuration(c.SelfTest.ProviderTimeout); err != nil || parsed <= 0 {
+		return fmt.Errorf("invalid selfTest.providerTimeout '%s'", c.SelfTest.ProviderTimeout)
+	}
+
+	// Validate built-in servers, container runtimes, tool timeouts, argument defaults and restart policies
+	for name, server := range c.MCPServers {
+		if err := server.validateBuiltin(); err != nil {
+			return fmt.Errorf("mcp server '%s': %w", name, err)
+		}
+		if err := server.validateRuntime(); err != nil {
+			return fmt.Errorf("mcp server '%s': %w", name, err)
+		}
+		if err := server.Tools.validateTimeouts(); err != nil {
+			return fmt.Errorf("mcp server '%s': %w", name, err)
+		}
+		if err := server.Tools.validateDefaults(); err != nil {
+			return fmt.Errorf("mcp server '%s': %w", name, err)
+		}
+		if err := server.Restart.validate(); err != nil {
+			return fmt.Errorf("mcp server '%s': %w", name, err)
+		}
+		if err := server.validateTLS(); err != nil {
+			return fmt.Errorf("mcp server '%s': %w", name, err)
+		}
+	}
+
+	// Validate MCP connection tuning
+	if err := c.MCPConnections.validate(); err != nil {
+		return err
+	}
+
+	// Validate per-user authentication
+	for name, server := range c.MCPServers {
+		switch server.AuthMode {
+		case "", AuthModeShared:
+			continue
+		case AuthModePerUser:
+		default:
+			return fmt.Errorf("mcp server '%s': unknown authMode '%s'", name, server.AuthMode)
+		}
+		if server.Disabled {
+			continue
+		}
+		if !server.isRemote() {
+			return fmt.Errorf("mcp server '%s': authMode 'per-user' requires an sse or http transport", name)
+		}
+		if server.OAuth.ClientID == "" || server.OAuth.AuthURL == "" || server.OAuth.TokenURL == "" {
+			return fmt.Errorf("mcp server '%s': authMode 'per-user' requires oauth clientId, authUrl and tokenUrl", name)
+		}
+		if c.Credentials.EncryptionKey == "" || strings.HasPrefix(c.Credentials.EncryptionKey, "${") {
+			return fmt.Errorf("CREDENTIALS_ENCRYPTION_KEY environment variable not set (required by per-user server '%s')", name)
+		}
+		if c.Credentials.CallbackURL == "" {
+			return fmt.Errorf("credentials.callbackUrl must be set (required by per-user server '%s')", name)
+		}
+	}
+
+	// Validate tool selection
+	if c.LLM.ToolSelection.Enabled {
+		switch c.LLM.ToolSelection.Provider {
+		case ProviderOpenAI, ProviderOllama:
+		default:
+			return fmt.Errorf("unsupported toolSelection embedding provider '%s' (use openai or ollama)", c.LLM.ToolSelection.Provider)
+		}
+	}
+
+	// Validate security lists
+	if err := c.validateSecurityLists(); err != nil {
+		return err
+	}
+
+	// Validate quiet hours
+	if err := c.validateQuietHours(); err != nil {
+		return err
+	}
+
+	// Validate model routing
+	if err := c.validateLLMRouting(); err != nil {
+		return err
+	}
+	if c.LLM.ThreadAffinity.MaxThreads < 0 {
+		return fmt.Errorf("llm threadAffinity maxThreads must not be negative")
+	}
+
+	// Validate the grounding check
+	if err := c.validateLLMGrounding(); err != nil {
+		return err
+	}
+
+	// Validate the intent classifier
+	if err := c.validateLLMIntent(); err != nil {
+		return err
+	}
+
+	// Validate the history token budget
+	if c.Slack.HistoryTokens.MaxTokens < -1 {
+		return fmt.Errorf("slack historyTokens maxTokens must be positive, or -1 to disable the budget")
+	}
+	for role, weight := range c.Slack.HistoryTokens.RoleWeights {
+		switch role {
+		case "user", "assistant", "tool":
+		default:
+			return fmt.Errorf("unknown slack historyTokens role '%s' (use user, assistant or tool)", role)
+		}
+		if weight < 0 {
+			return fmt.Errorf("slack historyTokens weight of role '%s' must not be negative", role)
+		}
+	}
+
+	if c.Slack.ToolHistory.MaxResultChars < -1 {
+		return fmt.Errorf("slack toolHistory maxResultChars must be positive, or -1 to keep whole results")
+	}
+	if c.Slack.ToolHistory.MaxDiffLines < 0 {
+		return fmt.Errorf("slack toolHistory maxDiffLines must not be negative")
+	}
+
+	if c.Slack.Workers.MaxConcurrent < -1 || c.Slack.Workers.MaxQueued < -1 {
+		return fmt.Errorf("slack workers maxConcurrent and maxQueued must be positive, or -1 for no limit")
+	}
+
+	// Validate agent scratchpads
+	if c.Slack.Scratchpad.MaxThreads < 0 {
+		return fmt.Errorf("slack scratchpad maxThreads must not be negative")
+	}
+
+	// Validate long-term memory
+	if c.Memory.MaxFacts < 0 || c.Memory.MaxRecalled < 0 {
+		return fmt.Errorf("memory maxFacts and maxRecalled must not be negative")
+	}
+
+	// Validate scheduled tool calls
+	if c.Jobs.MaxJobs < 0 {
+		return fmt.Errorf("jobs maxJobs must not be negative")
+	}
+	if maxDelay, err := time.ParseDuration(c.Jobs.MaxDelay); c.Jobs.Enabled && (err != nil || maxDelay <= 0) {
+		return fmt.Errorf("invalid jobs maxDelay '%s': must be a positive duration such as \"720h\"", c.Jobs.MaxDelay)
+	}
+	if _, err := time.LoadLocation(c.Jobs.Timezone); c.Jobs.Enabled && err != nil {
+		return fmt.Errorf("invalid jobs timezone '%s': %w", c.Jobs.Timezone, err)
+	}
+	if !strings.HasPrefix(c.Slack.SlashCommand, "/") {
+		return fmt.Errorf("slack slashCommand '%s' must start with '/'", c.Slack.SlashCommand)
+	}
+
+	// Validate the outbound proxy
+	if c.Network.ProxyURL != "" {
+		proxyURL, err := url.Parse(c.Network.ProxyURL)
+		if err != nil || proxyURL.Host == "" {
+			return fmt.Errorf("invalid network proxyUrl '%s'", c.Network.ProxyURL)
+		}
+		switch proxyURL.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return fmt.Errorf("unsupported network proxyUrl scheme '%s' (use http, https or socks5)", proxyURL.Scheme)
+		}
+	}
+
+	// Validate the agent implementation
+	if c.LLM.AgentType != AgentTypeConversational && c.LLM.AgentType != AgentTypeTools {
+		return fmt.Errorf("invalid llm agentType '%s' (use %s or %s)", c.LLM.AgentType, AgentTypeConversational, AgentTypeTools)
+	}
+
+	// Validate agent budgets
+	if err := c.validateAgentBudget(); err != nil {
+		return err
+	}
+
+	// Validate RAG retrieval
+	if c.RAG.Enabled {
+		switch c.RAG.Search.Mode {
+		case RAGSearchKeyword:
+		case RAGSearchVector, RAGSearchHybrid:
+			if c.RAG.Provider != "simple" && c.RAG.Provider != "sqlite" {
+				return fmt.Errorf("rag search mode '%s' requires the simple provider", c.RAG.Search.Mode)
+			}
+			switch c.RAG.Search.EmbeddingProvider {
+			case ProviderOpenAI, ProviderOllama:
+			default:
+				return fmt.Errorf("unsupported rag embedding provider '%s' (use openai or ollama)", c.RAG.Search.EmbeddingProvider)
+			}
+		default:
+			return fmt.Errorf("invalid rag search mode '%s' (use keyword, vector or hybrid)", c.RAG.Search.Mode)
+		}
+		if c.RAG.Search.VectorWeight < 0 || c.RAG.Search.VectorWeight > 1 {
+			return fmt.Errorf("rag search vectorWeight must be between 0 and 1")
+		}
+		if err := c.validateRAGChunking(); err != nil {
+			return err
+		}
+		if err := c.validateRAGNamespaces(); err != nil {
+			return err
+		}
+		if err := c.validateRAGSources(); err != nil {
+			return err
+		}
+		for field, value := range map[string]string{"halfLife": c.RAG.Freshness.HalfLife, "staleAfter": c.RAG.Freshness.StaleAfter} {
+			if value == "" {
+				continue
+			}
+			if parsed, err := time.ParseDuration(value); err != nil || parsed <= 0 {
+				return fmt.Errorf("invalid rag freshness.%s '%s'", field, value)
+			}
+		}
+		for field, value := range map[string]string{"interval": c.RAG.GC.Interval, "minAge": c.RAG.GC.MinAge} {
+			if value == "" {
+				continue
+			}
+			if parsed, err := time.ParseDuration(value); err != nil || parsed <= 0 {
+				return fmt.Errorf("invalid rag gc.%s '%s'", field, value)
+			}
+		}
+		if c.RAG.GC.Enabled && c.RAG.Provider != "openai" {
+			return fmt.Errorf("rag gc is only supported by the openai provider, not '%s'", c.RAG.Provider)
+		}
+		switch c.RAG.Rerank.Provider {
+		case "", RAGRerankLLM:
+		case RAGRerankCrossEncoder:
+			if c.RAG.Rerank.URL == "" {
+				return fmt.Errorf("rag rerank provider '%s' requires a url", RAGRerankCrossEncoder)
+			}
+		default:
+			return fmt.Errorf("invalid rag rerank provider '%s' (use llm or crossEncoder)", c.RAG.Rerank.Provider)
+		}
+		switch c.RAG.Answer.Classifier {
+		case RAGAnswerClassifierRetrieval, RAGAnswerClassifierLLM:
+		default:
+			return fmt.Errorf("invalid rag answer classifier '%s' (use retrieval or llm)", c.RAG.Answer.Classifier)
+		}
+	}
+
+	// Validate tool name collision handling
+	switch c.ToolCollision.Strategy {
+	case "", ToolCollisionPrefix, ToolCollisionPriority, ToolCollisionError:
+	default:
+		return fmt.Errorf("unknown toolCollision strategy '%s'", c.ToolCollision.Strategy)
+	}
+	for _, serverName := range c.ToolCollision.Priority {
+		if _, exists := c.MCPServers[serverName]; !exists {
+			return fmt.Errorf("toolCollision priority references unknown mcp server '%s'", serverName)
+		}
+	}
+
+	// Validate content moderation configuration
+	if c.Moderation.Enabled {
+		switch c.Moderation.Provider {
+		case ModerationProviderOpenAI:
+			if c.Moderation.APIKey == "" && c.LLM.Providers[ProviderOpenAI].APIKey == "" {
+				return fmt.Errorf("moderation provider 'openai' requires moderation.apiKey or an openai LLM provider API key")
+			}
+		case ModerationProviderLocal:
+			if len(c.Moderation.Patterns) == 0 {
+				return fmt.Errorf("moderation provider 'local' requires at least one pattern")
+			}
+			for category, patterns := range c.Moderation.Patterns {
+				for _, pattern := range patterns {
+					if _, err := regexp.Compile(pattern); err != nil {
+						return fmt.Errorf("invalid moderation pattern for category '%s': %w", category, err)
+					}
+				}
+			}
+		default:
+			return fmt.Errorf("unknown moderation provider '%s'", c.Moderation.Provider)
+		}
+		switch c.Moderation.Action {
+		case ModerationActionBlock, ModerationActionAnnotate:
+		case ModerationActionFlag:
+			if c.Moderation.AdminChannel == "" {
+				return fmt.Errorf("moderation action 'flag' requires moderation.adminChannel")
+			}
+		default:
+			return fmt.Errorf("unknown moderation action '%s'", c.Moderation.Action)
+		}
+	}
+
+	// Validate the on-call lookup configuration
+	if c.OnCall.Enabled {
+		switch c.OnCall.Provider {
+		case OnCallProviderPagerDuty, OnCallProviderOpsgenie:
+		default:
+			return fmt.Errorf("unknown onCall provider '%s' (use pagerduty or opsgenie)", c.OnCall.Provider)
+		}
+		if c.OnCall.APIKey == "" || strings.HasPrefix(c.OnCall.APIKey, "${") {
+			return fmt.Errorf("onCall requires an apiKey")
+		}
+		if len(c.OnCall.Schedules) == 0 {
+			return fmt.Errorf("onCall requires at least one schedule")
+		}
+		if _, err := time.ParseDuration(c.OnCall.CacheTTL); err != nil {
+			return fmt.Errorf("invalid onCall cacheTtl '%s': %w", c.OnCall.CacheTTL, err)
+		}
+	}
+
+	// Middleware names are resolved when the chain is built, since plugins register at startup
+	for i, m := range c.Middlewares {
+		if strings.TrimSpace(m.Name) == "" {
+			return fmt.Errorf("middlewares[%d] requires a name", i)
+		}
+	}
+
+	// Validate WASM tools
+	for name, tool := range c.WASMTools {
+		if tool.Path == "" {
+			return fmt.Errorf("wasm tool '%s' requires a path", name)
+		}
+		if tool.Description == "" {
+			return fmt.Errorf("wasm tool '%s' requires a description", name)
+		}
+		if timeout, err := time.ParseDuration(tool.Timeout); err != nil || timeout <= 0 {
+			return fmt.Errorf("wasm tool '%s' has an invalid timeout '%s'", name, tool.Timeout)
+		}
+		if tool.MaxMemoryMB < 0 || tool.MaxMemoryMB > 4096 {
+			return fmt.Errorf("wasm tool '%s' maxMemoryMb must be between 1 and 4096", name)
+		}
+	}
+
+	// Validate HTTP tools
+	for name, tool := range c.HTTPTools {
+		if tool.Description == "" {
+			return fmt.Errorf("http tool '%s' requires a description", name)
+		}
+		switch tool.Method {
+		case "GET", "POST", "PUT", "PATCH", "DELETE":
+		default:
+			return fmt.Errorf("http tool '%s' has unsupported method '%s'", name, tool.Method)
+		}
+		if u, err := url.Parse(tool.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("http tool '%s' has an invalid url '%s'", name, tool.URL)
+		}
+		if timeout, err := time.ParseDuration(tool.Timeout); err != nil || timeout <= 0 {
+			return fmt.Errorf("http tool '%s' has an invalid timeout '%s'", name, tool.Timeout)
+		}
+	}
+
+	// Validate OpenAPI imports
+	for name, api := range c.OpenAPI {
+		if api.Spec == "" {
+			return fmt.Errorf("openApi '%s' requires a spec", name)
+		}
+		if len(api.Operations) == 0 {
+			return fmt.Errorf("openApi '%s' requires an operations allowlist", name)
+		}
+		for _, pattern := range api.Operations {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("openApi '%s' has an invalid operation pattern '%s': %w", name, pattern, err)
+			}
+		}
+		if api.BaseURL != "" {
+			if u, err := url.Parse(api.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+				return fmt.Errorf("openApi '%s' has an invalid baseUrl '%s'", name, api.BaseURL)
+			}
+		}
+		if timeout, err := time.ParseDuration(api.Timeout); err != nil || timeout <= 0 {
+			return fmt.Errorf("openApi '%s' has an invalid timeout '%s'", name, api.Timeout)
+		}
+	}
+
+	// Validate experiments
+	experimentNames := make(map[string]bool, len(c.Experiments))
+	rollbackStores := make(map[string]string, len(c.Experiments))
+	for _, experiment := range c.Experiments {
+		if experiment.Name == "" {
+			return fmt.Errorf("every experiment requires a name")
+		}
+		if experimentNames[experiment.Name] {
+			return fmt.Errorf("experiment '%s' is defined more than once", experiment.Name)
+		}
+		experimentNames[experiment.Name] = true
+		if experiment.Percent < 0 || experiment.Percent > 100 {
+			return fmt.Errorf("experiment '%s' has an invalid percent %d: must be between 0 and 100", experiment.Name, experiment.Percent)
+		}
+		variant := experiment.Variant
+		if variant.CustomPrompt == "" && variant.Provider == "" && variant.Model == "" {
+			return fmt.Errorf("experiment '%s' requires a variant customPrompt, provider or model", experiment.Name)
+		}
+		if variant.Provider != "" {
+			if _, exists := c.LLM.Providers[variant.Provider]; !exists {
+				return fmt.Errorf("experiment '%s' uses provider '%s', which is not configured in llm.providers", experiment.Name, variant.Provider)
+			}
+		}
+		rollback := experiment.Rollback
+		if window, err := time.ParseDuration(rollback.Window); rollback.Enabled && (err != nil || window <= 0) {
+			return fmt.Errorf("experiment '%s' has an invalid rollback window '%s'", experiment.Name, rollback.Window)
+		}
+		if rollback.MinSamples < 0 {
+			return fmt.Errorf("experiment '%s' rollback minSamples must not be negative", experiment.Name)
+		}
+		for field, rate := range map[string]float64{"maxNegativeRate": rollback.MaxNegativeRate, "maxErrorRate": rollback.MaxErrorRate} {
+			if rate < 0 || rate > 1 {
+				return fmt.Errorf("experiment '%s' rollback %s must be between 0 and 1", experiment.Name, field)
+			}
+		}
+		if other, ok := rollbackStores[rollback.StorePath]; ok && rollback.StorePath != "" {
+			return fmt.Errorf("experiments '%s' and '%s' use the same rollback storePath '%s'", other, experiment.Name, rollback.StorePath)
+		}
+		rollbackStores[rollback.StorePath] = experiment.Name
+	}
+
+	// Validate SLO tracking
+	if c.SLO.Enabled {
+		for field, value := range map[string]string{"window": c.SLO.Window, "latencyP95": c.SLO.LatencyP95} {
+			if duration, err := time.ParseDuration(value); err != nil || duration <= 0 {
+				return fmt.Errorf("slo.%s '%s' is not a valid positive duration", field, value)
+			}
+		}
+	}
+	if c.SLO.MinSamples < 0 {
+		return fmt.Errorf("slo.minSamples must not be negative")
+	}
+	for field, rate := range map[string]float64{"errorRate": c.SLO.ErrorRate, "toolFailureRate": c.SLO.ToolFailureRate} {
+		if rate <= 0 || rate >= 1 {
+			return fmt.Errorf("slo.%s must be between 0 and 1, exclusive", field)
+		}
+	}
+	if c.SLO.BurnRate < 1 {
+		return fmt.Errorf("slo.burnRate must be at least 1")
+	}
+
+	// Validate external hooks
+	if err := c.validateHooks(); err != nil {
+		return err
+	}
+
+	// Validate event de-duplication configuration
+	if c.Dedupe.Enabled {
+		switch c.Dedupe.Provider {
+		case DedupeProviderMemory:
+		case DedupeProviderRedis:
+			if c.Dedupe.RedisURL == "" || strings.HasPrefix(c.Dedupe.RedisURL, "${") {
+				return fmt.Errorf("DEDUPE_REDIS_URL environment variable not set for redis dedupe provider")
+			}
+		default:
+			return fmt.Errorf("unknown dedupe provider '%s'", c.Dedupe.Provider)
+		}
+	}
+	// Redeliveries are de-duplicated in memory even when dedupe is not enabled
+	if _, err := time.ParseDuration(c.Dedupe.TTL); err != nil {
+		return fmt.Errorf("invalid dedupe ttl '%s': %w", c.Dedupe.TTL, err)
+	}
+	if c.Dedupe.MaxEntries < 0 {
+		return fmt.Errorf("dedupe maxEntries must not be negative")
+	}
+
+	return nil
+}
+
+// ragNamespaceName matches valid knowledge base namespace names
+var ragNamespaceName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// validateSecurityLists checks the channel name patterns and the lookup cache TTL
+func (c *Config) validateSecurityLists() error {
+	if !c.Security.Enabled {
+		return nil
+	}
+	if c.Security.LookupCacheTTL != "" {
+		if _, err := time.ParseDuration(c.Security.LookupCacheTTL); err != nil {
+			return fmt.Errorf("invalid security lookupCacheTtl '%s': %w", c.Security.LookupCacheTTL, err)
+		}
+	}
+	for _, entry := range c.Security.AllowedChannels {
+		if pattern, ok := strings.CutPrefix(entry, "#"); ok {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid security allowedChannels pattern '%s': %w", entry, err)
+			}
+		}
+	}
+	for _, list := range [][]string{c.Security.AllowedUsers, c.Security.AdminUsers} {
+		for _, entry := range list {
+			if strings.HasPrefix(entry, "#") && strings.ContainsAny(entry, "*?[") {
+				return fmt.Errorf("security user entry '%s' must name a single channel; patterns are only supported in allowedChannels", entry)
+			}
+		}
+	}
+	return nil
+}
+
+// validateQuietHours checks the times, days and timezone of each quiet hours window
+func (c *Config) validateQuietHours() error {
+	for i, window := range c.Maintenance.QuietHours {
+		for _, clock := range []string{window.Start, window.End} {
+			if _, err := time.Parse("15:04", clock); err != nil {
+				return fmt.Errorf("maintenance quietHours %d: invalid time '%s' (use HH:MM)", i+1, clock)
+			}
+		}
+		if window.Start == window.End {
+			return fmt.Errorf("maintenance quietHours %d: start and end are both %s", i+1, window.Start)
+		}
+		for _, day := range window.Days {
+			if _, ok := Weekdays[strings.ToLower(day)]; !ok {
+				return fmt.Errorf("maintenance quietHours %d: unknown day '%s' (use mon to sun)", i+1, day)
+			}
+		}
+		if _, err := time.LoadLocation(window.Timezone); err != nil {
+			return fmt.Errorf("maintenance quietHours %d: unknown timezone '%s': %w", i+1, window.Timezone, err)
+		}
+	}
+	return nil
+}
+
+// validateSlackReactionActions checks the action of each quick action reaction
+func (c *Config) validateSlackReactionActions() error {
+	for reaction, action := range c.Slack.ReactionActions {
+		switch action.Action {
+		case ReactionActionRegenerate, ReactionActionExpand, ReactionActionCanvas:
+		case ReactionActionPrompt:
+			if strings.TrimSpace(action.Prompt) == "" {
+				return fmt.Errorf("slack reactionActions '%s' needs a prompt", reaction)
+			}
+		default:
+			return fmt.Errorf("unknown slack reactionActions action '%s' for '%s' (use regenerate, expand, canvas or prompt)", action.Action, reaction)
+		}
+	}
+	return nil
+}
+
+// validateSlackCommands checks the names, tools and argument templates of the
+// slash command's command templates
+func (c *Config) validateSlackCommands() error {
+	for name, command := range c.Slack.Commands {
+		if !SlackCommandNamePattern.MatchString(name) {
+			return fmt.Errorf("slack commands name '%s' must be lowercase letters, digits, '-' and '_'", name)
+		}
+		if slices.Contains(ReservedSlackCommands, name) {
+			return fmt.Errorf("slack commands name '%s' is a built-in subcommand", name)
+		}
+		if strings.TrimSpace(command.Tool) == "" {
+			return fmt.Errorf("slack commands '%s' needs a tool", name)
+		}
+		vars := slices.Clone(ContextVars)
+		for _, param := range command.Params {
+			if !SlackCommandNamePattern.MatchString(param) || slices.Contains(ContextVars, param) {
+				return fmt.Errorf("slack commands '%s' has an invalid param '%s'", name, param)
+			}
+			if slices.Contains(vars, param) {
+				return fmt.Errorf("slack commands '%s' has param '%s' twice", name, param)
+			}
+			vars = append(vars, param)
+		}
+		if err := validateContextVars(command.Args, vars); err != nil {
+			return fmt.Errorf("slack commands '%s': %w", name, err)
+		}
+	}
+	return nil
+}
+
+// validateLLMRouting checks that both routes use configured providers and that
+// channels are routed to a known route
+func (c *Config) validateLLMRouting() error {
+	if !c.LLM.Routing.Enabled {
+		return nil
+	}
+	routes := map[string]LLMRouteConfig{RouteCheap: c.LLM.Routing.Cheap, RoutePowerful: c.LLM.Routing.Powerful}
+	for name, route := range routes {
+		if _, exists := c.LLM.Providers[route.Provider]; !exists {
+			return fmt.Errorf("llm routing %s route uses provider '%s', which is not configured", name, route.Provider)
+		}
+	}
+	for channelID, route := range c.LLM.Routing.Channels {
+		if _, exists := routes[route]; !exists {
+			return fmt.Errorf("unknown llm routing route '%s' for channel %s (use cheap or powerful)", route, channelID)
+		}
+	}
+	return nil
+}
+
+// validateLLMGrounding checks the grounding check's provider and action
+func (c *Config) validateLLMGrounding() error {
+	grounding := c.LLM.Grounding
+	if !grounding.Enabled {
+		return nil
+	}
+	if _, exists := c.LLM.Providers[grounding.Provider]; !exists {
+		return fmt.Errorf("llm grounding uses provider '%s', which is not configured", grounding.Provider)
+	}
+	switch grounding.Action {
+	case GroundingActionWarn, GroundingActionRegenerate:
+	default:
+		return fmt.Errorf("unknown llm grounding action '%s' (use warn or regenerate)", grounding.Action)
+	}
+	return nil
+}
+
+// validateLLMIntent checks the intent classifier and its provider
+func (c *Config) validateLLMIntent() error {
+	intent := c.LLM.Intent
+	if !intent.Enabled {
+		return nil
+	}
+	switch intent.Classifier {
+	case IntentClassifierHeuristic:
+		return nil
+	case IntentClassifierLLM:
+	default:
+		return fmt.Errorf("unknown llm intent classifier '%s' (use heuristic or llm)", intent.Classifier)
+	}
+	if _, exists := c.LLM.Providers[intent.Provider]; !exists {
+		return fmt.Errorf("llm intent classifier uses provider '%s', which is not configured", intent.Provider)
+	}
+	if d, err := time.ParseDuration(intent.Timeout); err != nil || d <= 0 {
+		return fmt.Errorf("llm intent has an invalid timeout '%s'", intent.Timeout)
+	}
+	return nil
+}
+
+// validateAgentBudget checks that the agent's limits are not negative
+func (c *Config) validateAgentBudget() error {
+	budget := c.LLM.AgentBudget
+	if budget.MaxToolCalls < 0 {
+		return fmt.Errorf("llm agentBudget maxToolCalls must not be negative")
+	}
+	if budget.MaxTokens < 0 {
+		return fmt.Errorf("llm agentBudget maxTokens must not be negative")
+	}
+	if budget.MaxDuration != "" {
+		if d, err := time.ParseDuration(budget.MaxDuration); err != nil || d <= 0 {
+			return fmt.Errorf("llm agentBudget has an invalid maxDuration '%s'", budget.MaxDuration)
+		}
+	}
+	return nil
+}
+
+// validateSlackIntermediateMessages checks the default and per-channel retention
+func (c *Config) validateSlackIntermediateMessages() error {
+	retentions := map[string]string{"": c.Slack.IntermediateMessages.Retention}
+	for channelID, retention := range c.Slack.IntermediateMessages.Channels {
+		retentions[channelID] = retention
+	}
+	for channelID, retention := range retentions {
+		switch retention {
+		case "", IntermediateKeep, IntermediateDelete, IntermediateCollapse:
+			continue
+		}
+		if channelID == "" {
+			return fmt.Errorf("unknown slack intermediateMessages retention '%s' (use keep, delete or collapse)", retention)
+		}
+		return fmt.Errorf("unknown slack intermediateMessages retention '%s' for channel %s (use keep, delete or collapse)", retention, channelID)
+	}
+	return nil
+}
+
+// validateSlackConversations checks the reply mode of each conversation type
+func (c *Config) validateSlackConversations() error {
+	conversations := c.Slack.Conversations
+	modes := []struct{ name, mode string }{
+		{"directMessages", conversations.DirectMessages},
+		{"groupMessages", conversations.GroupMessages},
+		{"privateChannels", conversations.PrivateChannels},
+		{"publicChannels", conversations.PublicChannels},
+	}
+	for _, m := range modes {
+		switch m.mode {
+		case "", ConversationReplyAll, ConversationReplyMentions, ConversationReplyOff:
+		default:
+			return fmt.Errorf("unknown slack conversations %s '%s' (use all, mentions or off)", m.name, m.mode)
+		}
+	}
+	if conversations.CacheTTL != "" {
+		if _, err := time.ParseDuration(conversations.CacheTTL); err != nil {
+			return fmt.Errorf("invalid slack conversations cacheTtl '%s': %w", conversations.CacheTTL, err)
+		}
+	}
+	return nil
+}
+
+// validateSlackListeners checks that every channel listener has a valid trigger
+func (c *Config) validateSlackListeners() error {
+	for channelID, listener := range c.Slack.Listeners {
+		if len(listener.Keywords) == 0 && !listener.Questions && len(listener.Patterns) == 0 {
+			return fmt.Errorf("slack listener for channel %s needs keywords, questions or patterns", channelID)
+		}
+		for _, pattern := range listener.Patterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("invalid slack listener pattern for channel %s: %w", channelID, err)
+			}
+		}
+		if listener.Cooldown != "" {
+			if _, err := time.ParseDuration(listener.Cooldown); err != nil {
+				return fmt.Errorf("invalid slack listener cooldown '%s' for channel %s: %w", listener.Cooldown, channelID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateSlackPostProcessors checks that each post-processor has the settings
+// its type needs
+func (c *Config) validateSlackPostProcessors() error {
+	for i, processor := range c.Slack.PostProcessors {
+		switch processor.Type {
+		case PostProcessorAppend:
+			if strings.TrimSpace(processor.Text) == "" {
+				return fmt.Errorf("slack post-processor %d needs text", i)
+			}
+		case PostProcessorStrip:
+			if processor.Pattern == "" {
+				return fmt.Errorf("slack post-processor %d needs a pattern", i)
+			}
+		case PostProcessorRewriteLinks:
+			if !strings.Contains(processor.Template, "{url}") {
+				return fmt.Errorf("slack post-processor %d needs a template containing {url}", i)
+			}
+		default:
+			return fmt.Errorf("unknown slack post-processor type '%s' (use append, strip or rewriteLinks)", processor.Type)
+		}
+		if processor.Pattern != "" {
+			if _, err := regexp.Compile(processor.Pattern); err != nil {
+				return fmt.Errorf("invalid pattern for slack post-processor %d: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateSlackIncidents checks the incident channel pattern
+func (c *Config) validateSlackIncidents() error {
+	incidents := c.Slack.Incidents
+	if incidents.ChannelPattern != "" {
+		if _, err := regexp.Compile(incidents.ChannelPattern); err != nil {
+			return fmt.Errorf("invalid slack incidents channelPattern: %w", err)
+		}
+	}
+	if incidents.MaxMessages < 0 {
+		return fmt.Errorf("slack incidents maxMessages must not be negative")
+	}
+	return nil
+}
+
+// validateSlackDigests checks the channel, schedule and time zone of each digest
+func (c *Config) validateSlackDigests() error {
+	for i, digest := range c.Slack.Digests {
+		if digest.Channel == "" {
+			return fmt.Errorf("slack digest %d needs a channel", i)
+		}
+		if _, err := schedule.ParseCron(digest.Schedule); err != nil {
+			return fmt.Errorf("invalid schedule for slack digest of channel %s: %w", digest.Channel, err)
+		}
+		if digest.Timezone != "" {
+			if _, err := time.LoadLocation(digest.Timezone); err != nil {
+				return fmt.Errorf("invalid timezone '%s' for slack digest of channel %s: %w", digest.Timezone, digest.Channel, err)
+			}
+		}
+		if digest.Hours < 0 || digest.MaxMessages < 0 {
+			return fmt.Errorf("slack digest of channel %s: hours and maxMessages must not be negative", digest.Channel)
+		}
+	}
+	return nil
+}
+
+// validateSlackAssistant checks the suggested prompts against Slack's limits
+func (c *Config) validateSlackAssistant() error {
+	if len(c.Slack.Assistant.SuggestedPrompts) > MaxAssistantSuggestedPrompts {
+		return fmt.Errorf("slack assistant allows at most %d suggested prompts, got %d",
+			MaxAssistantSuggestedPrompts, len(c.Slack.Assistant.SuggestedPrompts))
+	}
+	for i, prompt := range c.Slack.Assistant.SuggestedPrompts {
+		if prompt.Title == "" || prompt.Message == "" {
+			return fmt.Errorf("slack assistant suggested prompt %d requires a title and a message", i+1)
+		}
+	}
+	return nil
+}
+
+// validateRAGNamespaces checks namespace names and that each channel maps to at
+// most one namespace
+func (c *Config) validateRAGNamespaces() error {
+	if len(c.RAG.Namespaces) == 0 {
+		if c.RAG.DefaultNamespace != "" {
+			return fmt.Errorf("rag defaultNamespace '%s' is not defined in rag.namespaces", c.RAG.DefaultNamespace)
+		}
+		return nil
+	}
+	if c.RAG.Provider == "openai" {
+		return fmt.Errorf("rag namespaces are not supported by the openai provider")
+	}
+	if _, ok := c.RAG.Namespaces[c.RAG.DefaultNamespace]; c.RAG.DefaultNamespace != "" && !ok {
+		return fmt.Errorf("rag defaultNamespace '%s' is not defined in rag.namespaces", c.RAG.DefaultNamespace)
+	}
+	channels := make(map[string]string)
+	for name, namespace := range c.RAG.Namespaces {
+		if !ragNamespaceName.MatchString(name) {
+			return fmt.Errorf("invalid rag namespace name '%s' (use letters, digits, '.', '_' and '-')", name)
+		}
+		for _, channel := range namespace.Channels {
+			if other, exists := channels[channel]; exists {
+				return fmt.Errorf("channel '%s' is mapped to rag namespaces '%s' and '%s'", channel, other, name)
+			}
+			channels[channel] = name
+		}
+	}
+	return nil
+}
+
+// validateRAGChunking checks the chunking strategy and sizes
+func (c *Config) validateRAGChunking() error {
+	chunking := c.RAG.Chunking
+	switch chunking.Strategy {
+	case RAGChunkRecursive, RAGChunkSentence, RAGChunkMarkdown:
+	case RAGChunkSemantic:
+		switch c.RAG.Search.EmbeddingProvider {
+		case ProviderOpenAI, ProviderOllama:
+		default:
+			return fmt.Errorf("rag chunking strategy 'semantic' requires an openai or ollama embeddingProvider")
+		}
+	default:
+		return fmt.Errorf("invalid rag chunking strategy '%s' (use recursive, sentence, markdown or semantic)", chunking.Strategy)
+	}
+	if c.RAG.Provider == "openai" && chunking.Strategy != RAGChunkRecursive {
+		return fmt.Errorf("rag chunking strategy '%s' is not supported by the openai provider", chunking.Strategy)
+	}
+	switch chunking.Unit {
+	case RAGChunkUnitCharacters, RAGChunkUnitTokens:
+	default:
+		return fmt.Errorf("invalid rag chunking unit '%s' (use characters or tokens)", chunking.Unit)
+	}
+	if chunking.Size < 0 || chunking.Overlap < 0 || (chunking.Size > 0 && chunking.Overlap >= chunking.Size) {
+		return fmt.Errorf("rag chunking overlap must be smaller than the chunk size")
+	}
+	if chunking.BreakpointPercentile < 0 || chunking.BreakpointPercentile > 100 {
+		return fmt.Errorf("rag chunking breakpointPercentile must be between 0 and 100")
+	}
+	return nil
+}
+
+// validateRAGSources checks each synced source's type, interval and credentials
+func (c *Config) validateRAGSources() error {
+	names := make(map[string]bool)
+	for i, source := range c.RAG.Sources {
+		if source.Name == "" {
+			return fmt.Errorf("rag source %d: name is required", i)
+		}
+		if names[source.Name] {
+			return fmt.Errorf("rag source '%s' is defined more than once", source.Name)
+		}
+		names[source.Name] = true
+
+		if interval, err := time.ParseDuration(source.Interval); err != nil || interval < time.Minute {
+			return fmt.Errorf("rag source '%s': invalid interval '%s' (minimum 1m)", source.Name, source.Interval)
+		}
+		if _, ok := c.RAG.Namespaces[source.Namespace]; source.Namespace != "" && !ok {
+			return fmt.Errorf("rag source '%s': namespace '%s' is not defined in rag.namespaces", source.Name, source.Namespace)
+		}
+		if source.Type != RAGSourceGoogleDrive && (source.APIToken == "" || strings.HasPrefix(source.APIToken, "${")) {
+			return fmt.Errorf("rag source '%s': apiToken is required", source.Name)
+		}
+		switch source.Type {
+		case RAGSourceConfluence:
+			if source.BaseURL == "" {
+				return fmt.Errorf("rag source '%s': baseUrl is required for confluence", source.Name)
+			}
+			if len(source.Spaces) == 0 {
+				return fmt.Errorf("rag source '%s': at least one space is required for confluence", source.Name)
+			}
+		case RAGSourceNotion:
+		case RAGSourceGoogleDrive:
+			if source.CredentialsFile == "" {
+				return fmt.Errorf("rag source '%s': credentialsFile is required for googleDrive", source.Name)
+			}
+			if len(source.FolderIDs) == 0 {
+				return fmt.Errorf("rag source '%s': at least one folder is required for googleDrive", source.Name)
+			}
+		default:
+			return fmt.Errorf("rag source '%s': unsupported type '%s' (use confluence, notion or googleDrive)", source.Name, source.Type)
+		}
+	}
+	return nil
+}
 
+// isRemote reports whether the server is reached over the network, with the sse or http transport
+func (mcp *MCPServerConfig) isRemote() bool {
+	transport := mcp.GetTransport()
+	return transport != "stdio" && transport != TransportBuiltin
+}
+
+// validateTLS checks that a client certificate comes with its key, from either
+// files or inline PEM, and is only set for remote servers
+func (mcp *MCPServerConfig) validateTLS() error {
+	settings := mcp.TLS
+	if !settings.Enabled() {
+		return nil
+	}
+	if !mcp.isRemote() {
+		return fmt.Errorf("tls requires an sse or http transport")
+	}
+	files := settings.CertFile != "" || settings.KeyFile != ""
+	inline := settings.Cert != "" || settings.Key != ""
+	switch {
+	case files && inline:
+		return fmt.Errorf("tls takes certFile and keyFile, or cert and key, not both")
+	case files && (settings.CertFile == "" || settings.KeyFile == ""):
+		return fmt.Errorf("tls certFile and keyFile must be set together")
+	case inline && (settings.Cert == "" || settings.Key == ""):
+		return fmt.Errorf("tls cert and key must be set together")
+	}
 	return nil
 }
 
-// ValidateConfig performs comprehensive validation of the configuration structure
-// using the JSON schema at schema/config-schema.json
+// validateBuiltin checks the name and arguments of a built-in server
+func (mcp *MCPServerConfig) validateBuiltin() error {
+	if mcp.Builtin == "" {
+		if mcp.Transport == TransportBuiltin {
+			return fmt.Errorf("transport 'builtin' requires a builtin server name")
+		}
+		return nil
+	}
+	if mcp.Command != "" || mcp.URL != "" || mcp.IsDocker() {
+		return fmt.Errorf("builtin servers cannot also set command, url or runtime 'docker'")
+	}
+	if mcp.GetTransport() != TransportBuiltin {
+		return fmt.Errorf("builtin servers use the 'builtin' transport, not '%s'", mcp.Transport)
+	}
+	switch mcp.Builtin {
+	case BuiltinFilesystem:
+		if len(mcp.Args) == 0 {
+			return fmt.Errorf("builtin 'filesystem' requires the directories it may read in args")
+		}
+	case BuiltinIssues:
+		return mcp.Issues.Validate()
+	case BuiltinFetch, BuiltinTime, BuiltinCalculator:
+	default:
+		return fmt.Errorf("unknown builtin server '%s' (use filesystem, fetch, time, calculator or issues)", mcp.Builtin)
+	}
+	return nil
+}
+
+// Validate checks the issue tracker and templates of the builtin issues server
+func (issues *MCPIssuesConfig) Validate() error {
+	switch issues.Provider {
+	case IssueProviderJira:
+		if issues.URL == "" || issues.Project == "" {
+			return fmt.Errorf("builtin 'issues' with provider 'jira' requires url and project")
+		}
+	case IssueProviderGitHub:
+		if owner, name, ok := strings.Cut(issues.Repository, "/"); !ok || owner == "" || name == "" {
+			return fmt.Errorf("builtin 'issues' with provider 'github' requires repository as owner/name")
+		}
+	default:
+		return fmt.Errorf("unknown issues provider '%s' (use jira or github)", issues.Provider)
+	}
+	if issues.Token == "" {
+		return fmt.Errorf("builtin 'issues' requires a token")
+	}
+	templates := append([]string{issues.Title, issues.Body}, issues.Labels...)
+	for _, text := range templates {
+		if _, err := template.New("issue").Parse(text); err != nil {
+			return fmt.Errorf("invalid issues template '%s': %w", text, err)
+		}
+	}
+	return nil
+}
+
+// validateRuntime checks the runtime a stdio server is launched with
+func (mcp *MCPServerConfig) validateRuntime() error {
+	switch mcp.Runtime {
+	case "", RuntimeProcess:
+		return nil
+	case RuntimeDocker:
+	default:
+		return fmt.Errorf("unknown runtime '%s' (use process or docker)", mcp.Runtime)
+	}
+	if mcp.URL != "" || mcp.GetTransport() != "stdio" {
+		return fmt.Errorf("runtime 'docker' requires the stdio transport")
+	}
+	if mcp.Docker.Image == "" {
+		return fmt.Errorf("docker.image is required with runtime 'docker'")
+	}
+	switch mcp.Docker.Pull {
+	case "", "always", "missing", "never":
+	default:
+		return fmt.Errorf("unknown docker.pull policy '%s' (use always, missing or never)", mcp.Docker.Pull)
+	}
+	for _, volume := range mcp.Docker.Volumes {
+		if !strings.Contains(volume, ":") {
+			return fmt.Errorf("invalid docker volume '%s' (use host:container[:ro])", volume)
+		}
+	}
+	return nil
+}
+
+// validateTimeouts checks the server's and its tools' call timeouts
+func (t MCPToolsConfig) validateTimeouts() error {
+	if t.Timeout != "" {
+		if parsed, err := time.ParseDuration(t.Timeout); err != nil || parsed <= 0 {
+			return fmt.Errorf("invalid tools.timeout '%s'", t.Timeout)
+		}
+	}
+	for tool, override := range t.Overrides {
+		if override.Timeout == "" {
+			continue
+		}
+		if parsed, err := time.ParseDuration(override.Timeout); err != nil || parsed <= 0 {
+			return fmt.Errorf("invalid timeout '%s' of tool '%s'", override.Timeout, tool)
+		}
+	}
+	return nil
+}
+
+// validateDefaults checks that the argument defaults and fixed arguments of the
+// server and its tools only reference known context variables
+func (t MCPToolsConfig) validateDefaults() error {
+	if err := validateContextVars(t.Defaults, ContextVars); err != nil {
+		return fmt.Errorf("tools.defaults: %w", err)
+	}
+	if err := validateContextVars(t.Fixed, ContextVars); err != nil {
+		return fmt.Errorf("tools.fixed: %w", err)
+	}
+	for tool, override := range t.Overrides {
+		if err := validateContextVars(override.Defaults, ContextVars); err != nil {
+			return fmt.Errorf("defaults of tool '%s': %w", tool, err)
+		}
+		if err := validateContextVars(override.Fixed, ContextVars); err != nil {
+			return fmt.Errorf("fixed arguments of tool '%s': %w", tool, err)
+		}
+	}
+	return nil
+}
+
+// validateContextVars checks that a value, and the values of the maps and lists it
+// holds, only reference the given variables
+func validateContextVars(value interface{}, vars []string) error {
+	switch v := value.(type) {
+	case string:
+		for _, match := range ContextVarPattern.FindAllStringSubmatch(v, -1) {
+			if !slices.Contains(vars, match[1]) {
+				return fmt.Errorf("unknown context variable '${%s}' (use %s)", match[1], strings.Join(vars, ", "))
+			}
+		}
+	case map[string]interface{}:
+		for _, item := range v {
+			if err := validateContextVars(item, vars); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if err := validateContextVars(item, vars); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validate checks the restart attempts and backoff durations
+func (r MCPRestartConfig) validate() error {
+	if r.MaxAttempts < 0 {
+		return fmt.Errorf("restart.maxAttempts must not be negative")
+	}
+	durations := []struct{ field, value string }{
+		{"initialBackoff", r.InitialBackoff},
+		{"maxBackoff", r.MaxBackoff},
+		{"stableAfter", r.StableAfter},
+	}
+	for _, d := range durations {
+		if d.value == "" {
+			continue
+		}
+		if parsed, err := time.ParseDuration(d.value); err != nil || parsed <= 0 {
+			return fmt.Errorf("invalid restart.%s '%s'", d.field, d.value)
+		}
+	}
+	return nil
+}
+
+// validate checks the connection pool limits and reconnect settings
+func (m MCPConnectionsConfig) validate() error {
+	if m.MaxIdleConns < 0 || m.MaxIdleConnsPerHost < 0 || m.MaxConnsPerHost < 0 {
+		return fmt.Errorf("mcpConnections connection limits must not be negative")
+	}
+	if m.Reconnect.MaxAttempts < 0 {
+		return fmt.Errorf("mcpConnections reconnect.maxAttempts must not be negative")
+	}
+	if jitter := m.Reconnect.GetJitter(); jitter < 0 || jitter > 1 {
+		return fmt.Errorf("mcpConnections reconnect.jitter must be between 0 and 1")
+	}
+	durations := []struct{ field, value string }{
+		{"idleConnTimeout", m.IdleConnTimeout},
+		{"keepAliveInterval", m.KeepAliveInterval},
+		{"reconnect.initialBackoff", m.Reconnect.InitialBackoff},
+		{"reconnect.maxBackoff", m.Reconnect.MaxBackoff},
+	}
+	for _, d := range durations {
+		if d.value == "" {
+			continue
+		}
+		if parsed, err := time.ParseDuration(d.value); err != nil || parsed <= 0 {
+			return fmt.Errorf("invalid mcpConnections.%s '%s'", d.field, d.value)
+		}
+	}
+	return nil
+}
+
+// ValidateConfig validates the configuration against the JSON schema generated
+// from the Config struct (see Schema)
 func (c *Config) ValidateConfig() error {
 	// Convert config to JSON for validation
 	configJSON, err := json.Marshal(c)
@@ -71,14 +1304,16 @@ func (c *Config) ValidateConfig() error {
 		return fmt.Errorf("failed to marshal config for validation: %w", err)
 	}
 
-	// Find the schema file relative to the binary
-	schemaPath, err := findSchemaPath()
+	// Compile the generated schema
+	schemaJSON, err := SchemaJSON()
 	if err != nil {
-		return fmt.Errorf("failed to find schema file: %w", err)
+		return fmt.Errorf("failed to generate JSON schema: %w", err)
 	}
-
-	// Load the JSON schema
-	schema, err := jsonschema.Compile(schemaPath)
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(SchemaID, bytes.NewReader(schemaJSON)); err != nil {
+		return fmt.Errorf("failed to load JSON schema: %w", err)
+	}
+	schema, err := compiler.Compile(SchemaID)
 	if err != nil {
 		return fmt.Errorf("failed to compile JSON schema: %w", err)
 	}
@@ -97,25 +1332,6 @@ func (c *Config) ValidateConfig() error {
 	return nil
 }
 
-// findSchemaPath locates the config schema file
-func findSchemaPath() (string, error) {
-	// Try various paths relative to working directory and executable
-	possiblePaths := []string{
-		"schema/config-schema.json",
-		"./schema/config-schema.json",
-		"../schema/config-schema.json",
-		"../../schema/config-schema.json",
-	}
-
-	for _, path := range possiblePaths {
-		if _, err := os.Stat(path); err == nil {
-			return filepath.Abs(path)
-		}
-	}
-
-	return "", fmt.Errorf("config schema file not found in any of: %v", possiblePaths)
-}
-
 // isLegacyFormat checks if the configuration data is in legacy mcp-servers.json format
 func isLegacyFormat(configData []byte) bool {
 	var rawConfig map[string]interface{}
@@ -144,13 +1360,18 @@ func loadLegacyConfigFile(cfg *Config, configData []byte, logger *logging.Logger
 		return fmt.Errorf("failed to parse legacy config file: %w", err)
 	}
 
-	// Convert to new format by setting the MCP servers
-	cfg.MCPServers = legacyConfig.McpServers
+	// Convert to new format by setting the MCP servers, keeping any from MCP_SERVERS_JSON
+	if cfg.MCPServers == nil {
+		cfg.MCPServers = make(map[string]MCPServerConfig, len(legacyConfig.McpServers))
+	}
+	for name, server := range legacyConfig.McpServers {
+		cfg.MCPServers[name] = server
+	}
 
 	if logger != nil {
 		logger.InfoKV("Successfully converted legacy configuration",
 			"mcpServersCount", len(cfg.MCPServers))
-		logger.Warn("Consider migrating to new config.json format using --migrate-config flag")
+		logger.Warn("Consider migrating to new config.json format with the config migrate command")
 	}
 
 	return nil
@@ -179,6 +1400,7 @@ func (c *Config) SubstituteEnvironmentVariables() {
 	// Substitute in Slack configuration
 	c.Slack.BotToken = substituteEnvVars(c.Slack.BotToken)
 	c.Slack.AppToken = substituteEnvVars(c.Slack.AppToken)
+	c.Slack.SigningSecret = substituteEnvVars(c.Slack.SigningSecret)
 
 	// Substitute in LLM provider configurations
 	for name, provider := range c.LLM.Providers {
@@ -194,6 +1416,39 @@ func (c *Config) SubstituteEnvironmentVariables() {
 	c.Observability.ServiceName = substituteEnvVars(c.Observability.ServiceName)
 	c.Observability.ServiceVersion = substituteEnvVars(c.Observability.ServiceVersion)
 
+	// Substitute in MCP server OAuth clients and client certificates
+	for name, server := range c.MCPServers {
+		server.OAuth.ClientID = substituteEnvVars(server.OAuth.ClientID)
+		server.OAuth.ClientSecret = substituteEnvVars(server.OAuth.ClientSecret)
+		server.TLS.Cert = substituteEnvVars(server.TLS.Cert)
+		server.TLS.Key = substituteEnvVars(server.TLS.Key)
+		c.MCPServers[name] = server
+	}
+
+	// Substitute in Credentials configuration
+	c.Credentials.EncryptionKey = substituteEnvVars(c.Credentials.EncryptionKey)
+	c.Credentials.CallbackURL = substituteEnvVars(c.Credentials.CallbackURL)
+
+	// Substitute in Moderation configuration
+	c.Moderation.APIKey = substituteEnvVars(c.Moderation.APIKey)
+
+	// Substitute in Dedupe configuration
+	c.Dedupe.RedisURL = substituteEnvVars(c.Dedupe.RedisURL)
+
+	// Substitute in network configuration, e.g. proxy credentials
+	c.Network.ProxyURL = substituteEnvVars(c.Network.ProxyURL)
+	c.Network.CABundle = substituteEnvVars(c.Network.CABundle)
+
+	// Substitute in RAG configuration
+	c.RAG.Rerank.APIKey = substituteEnvVars(c.RAG.Rerank.APIKey)
+	for i := range c.RAG.Sources {
+		c.RAG.Sources[i].BaseURL = substituteEnvVars(c.RAG.Sources[i].BaseURL)
+		c.RAG.Sources[i].Username = substituteEnvVars(c.RAG.Sources[i].Username)
+		c.RAG.Sources[i].APIToken = substituteEnvVars(c.RAG.Sources[i].APIToken)
+		c.RAG.Sources[i].CredentialsFile = substituteEnvVars(c.RAG.Sources[i].CredentialsFile)
+		c.RAG.Sources[i].Subject = substituteEnvVars(c.RAG.Sources[i].Subject)
+	}
+
 }
 
 // substituteEnvVars replaces ${VAR_NAME} patterns with environment variable values
@@ -207,8 +1462,25 @@ func substituteEnvVars(input string) string {
 	return input
 }
 
-// LoadConfig loads configuration from file and environment variables
-func LoadConfig(configFile string, logger *logging.Logger) (*Config, error) {
+// LoadConfig loads configuration from file and environment variables. Overlay
+// files are deep-merged into the config file in order (see mergeJSON).
+func LoadConfig(configFile string, logger *logging.Logger, overlays ...string) (*Config, error) {
+	cfg, err := ReadConfig(configFile, logger, overlays...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate configuration
+	if err := cfg.ValidateAfterDefaults(); err != nil {
+		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// ReadConfig is LoadConfig without the validation, for callers that adjust the
+// configuration first, e.g. the chat subcommand, which needs no frontend tokens
+func ReadConfig(configFile string, logger *logging.Logger, overlays ...string) (*Config, error) {
 	// Load .env file if it exists
 	if err := godotenv.Load(); err != nil {
 		if logger != nil {
@@ -225,10 +1497,13 @@ func LoadConfig(configFile string, logger *logging.Logger) (*Config, error) {
 	// Apply environment variable overrides BEFORE loading config file
 	// This ensures config file has highest priority
 	cfg.ApplyEnvironmentVariables()
+	if err := cfg.ApplyMCPServersFromEnv(); err != nil {
+		return nil, err
+	}
 
 	// Read config file if provided - this will override environment variables
-	if configFile != "" {
-		if err := loadConfigFile(cfg, configFile, logger); err != nil {
+	if configFile != "" || len(overlays) > 0 {
+		if err := loadConfigFile(cfg, configFile, overlays, logger); err != nil {
 			return nil, err
 		}
 	}
@@ -236,25 +1511,37 @@ func LoadConfig(configFile string, logger *logging.Logger) (*Config, error) {
 	// Perform environment variable substitution (for ${VAR} placeholders only)
 	cfg.SubstituteEnvironmentVariables()
 
-	// Validate configuration
-	if err := cfg.ValidateAfterDefaults(); err != nil {
-		return nil, fmt.Errorf("configuration validation failed: %w", err)
-	}
-
 	return cfg, nil
 }
 
-// loadConfigFile loads configuration from a file
-func loadConfigFile(cfg *Config, configFile string, logger *logging.Logger) error {
-	// Ensure the file exists
-	if _, err := os.Stat(configFile); os.IsNotExist(err) {
-		return fmt.Errorf("config file does not exist: %s", configFile)
+// loadConfigFile loads configuration from a file and its overlays. Without a
+// file, the overlays are merged into an empty configuration.
+func loadConfigFile(cfg *Config, configFile string, overlays []string, logger *logging.Logger) error {
+	configData := []byte("{}")
+	if configFile != "" {
+		// Ensure the file exists
+		if _, err := os.Stat(configFile); os.IsNotExist(err) {
+			return fmt.Errorf("config file does not exist: %s", configFile)
+		}
+
+		// Read and parse the config file
+		data, err := os.ReadFile(configFile)
+		if err != nil {
+			return fmt.Errorf("failed to read config file: %s", err)
+		}
+		configData = data
 	}
 
-	// Read and parse the config file
-	configData, err := os.ReadFile(configFile)
-	if err != nil {
-		return fmt.Errorf("failed to read config file: %s", err)
+	// Merge overlays into the config file
+	if len(overlays) > 0 {
+		merged, err := mergeConfigFiles(configData, overlays)
+		if err != nil {
+			return err
+		}
+		configData = merged
+		if logger != nil {
+			logger.InfoKV("Applied config overlays", "files", strings.Join(overlays, ","))
+		}
 	}
 
 	// Check if this is a legacy format
@@ -279,3 +1566,41 @@ func loadConfigFile(cfg *Config, configFile string, logger *logging.Logger) erro
 
 	return nil
 }
+
+// validateHooks checks that each hook runs one thing at known events
+func (c *Config) validateHooks() error {
+	for i, hook := range c.Hooks {
+		name := hook.Name
+		if name == "" {
+			name = fmt.Sprintf("hooks[%d]", i)
+		}
+		if (hook.Command == "") == (hook.URL == "") {
+			return fmt.Errorf("hook '%s' requires either a command or a url", name)
+		}
+		if hook.URL != "" {
+			if u, err := url.Parse(hook.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+				return fmt.Errorf("hook '%s' has an invalid url '%s'", name, hook.URL)
+			}
+		}
+		if len(hook.Events) == 0 {
+			return fmt.Errorf("hook '%s' subscribes to no events", name)
+		}
+		for _, event := range hook.Events {
+			switch event {
+			case HookEventMessageReceived, HookEventBeforeToolCall, HookEventAfterResponse:
+			default:
+				return fmt.Errorf("hook '%s' has unknown event '%s' (use %s, %s or %s)", name, event,
+					HookEventMessageReceived, HookEventBeforeToolCall, HookEventAfterResponse)
+			}
+		}
+		if timeout, err := time.ParseDuration(hook.Timeout); err != nil || timeout <= 0 {
+			return fmt.Errorf("hook '%s' has an invalid timeout '%s'", name, hook.Timeout)
+		}
+		switch hook.OnError {
+		case HookOnErrorAllow, HookOnErrorDeny:
+		default:
+			return fmt.Errorf("hook '%s' has unknown onError '%s' (use allow or deny)", name, hook.OnError)
+		}
+	}
+	return nil
+}
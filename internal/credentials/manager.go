@@ -0,0 +1,232 @@
+package credentials
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+// linkStateTTL bounds how long an authorization link stays valid
+const linkStateTTL = 10 * time.Minute
+
+// pendingLink is an authorization flow started by a user
+type pendingLink struct {
+	userID  string
+	server  string
+	expires time.Time
+}
+
+// Manager runs the OAuth linking flow and hands out per-user tokens to MCP clients
+type Manager struct {
+	vault      Vault
+	oauth      map[string]*oauth2.Config // server name -> OAuth client
+	headers    map[string]string         // server name -> header carrying the token
+	listenAddr string
+	logger     *logging.Logger
+
+	mu      sync.Mutex
+	pending map[string]pendingLink // state -> pending link
+	notify  func(userID, text string)
+	server  *http.Server
+}
+
+// NewManager creates a Manager for all servers configured with per-user auth.
+// It returns nil when no server uses per-user auth.
+func NewManager(cfg *config.Config, logger *logging.Logger) (*Manager, error) {
+	oauthConfigs := make(map[string]*oauth2.Config)
+	headers := make(map[string]string)
+	for name, server := range cfg.MCPServers {
+		if server.Disabled || !server.IsPerUserAuth() {
+			continue
+		}
+		oauthConfigs[name] = &oauth2.Config{
+			ClientID:     server.OAuth.ClientID,
+			ClientSecret: server.OAuth.ClientSecret,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  server.OAuth.AuthURL,
+				TokenURL: server.OAuth.TokenURL,
+			},
+			RedirectURL: cfg.Credentials.CallbackURL,
+			Scopes:      server.OAuth.Scopes,
+		}
+		headers[name] = server.OAuth.HeaderName
+		if headers[name] == "" {
+			headers[name] = "Authorization"
+		}
+	}
+	if len(oauthConfigs) == 0 {
+		return nil, nil
+	}
+
+	vault, err := NewFileVault(cfg.Credentials.StorePath, cfg.Credentials.EncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Manager{
+		vault:      vault,
+		oauth:      oauthConfigs,
+		headers:    headers,
+		listenAddr: cfg.Credentials.ListenAddr,
+		logger:     logger.WithName("credentials"),
+		pending:    make(map[string]pendingLink),
+		notify:     func(string, string) {},
+	}, nil
+}
+
+// SetNotifier sets the function used to tell a user their account was linked
+func (m *Manager) SetNotifier(notify func(userID, text string)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.notify = notify
+}
+
+// Servers returns the names of servers that require per-user credentials
+func (m *Manager) Servers() []string {
+	servers := make([]string, 0, len(m.oauth))
+	for name := range m.oauth {
+		servers = append(servers, name)
+	}
+	sort.Strings(servers)
+	return servers
+}
+
+// AuthURL starts a linking flow for the user and returns the URL they should open
+func (m *Manager) AuthURL(userID, server string) (string, error) {
+	oauthConfig, ok := m.oauth[server]
+	if !ok {
+		return "", fmt.Errorf("server '%s' does not use per-user credentials", server)
+	}
+
+	stateBytes := make([]byte, 16)
+	if _, err := rand.Read(stateBytes); err != nil {
+		return "", fmt.Errorf("failed to generate state: %w", err)
+	}
+	state := hex.EncodeToString(stateBytes)
+
+	m.mu.Lock()
+	now := time.Now()
+	for s, link := range m.pending {
+		if now.After(link.expires) {
+			delete(m.pending, s)
+		}
+	}
+	m.pending[state] = pendingLink{userID: userID, server: server, expires: now.Add(linkStateTTL)}
+	m.mu.Unlock()
+
+	return oauthConfig.AuthCodeURL(state, oauth2.AccessTypeOffline), nil
+}
+
+// Unlink removes the user's stored credentials for a server
+func (m *Manager) Unlink(userID, server string) error {
+	return m.vault.Delete(userID, server)
+}
+
+// Linked returns the servers the user has linked
+func (m *Manager) Linked(userID string) ([]string, error) {
+	servers, err := m.vault.List(userID)
+	sort.Strings(servers)
+	return servers, err
+}
+
+// AuthHeader returns the header name and value carrying the user's token for a
+// server, refreshing and persisting the token if it has expired.
+func (m *Manager) AuthHeader(ctx context.Context, server, userID string) (string, string, error) {
+	oauthConfig, ok := m.oauth[server]
+	if !ok {
+		return "", "", fmt.Errorf("server '%s' does not use per-user credentials", server)
+	}
+	token, err := m.vault.Get(userID, server)
+	if err != nil {
+		return "", "", err
+	}
+
+	fresh, err := oauthConfig.TokenSource(ctx, token).Token()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to refresh token for '%s': %w", server, err)
+	}
+	if fresh.AccessToken != token.AccessToken {
+		if err := m.vault.Put(userID, server, fresh); err != nil {
+			m.logger.WarnKV("Failed to persist refreshed token", "server", server, "error", err)
+		}
+	}
+
+	return m.headers[server], fresh.Type() + " " + fresh.AccessToken, nil
+}
+
+// Start serves the OAuth callback endpoint in the background
+func (m *Manager) Start() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth/callback", m.HandleCallback)
+	m.server = &http.Server{
+		Addr:              m.listenAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		m.logger.InfoKV("Starting OAuth callback listener", "addr", m.listenAddr, "servers", m.Servers())
+		if err := m.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			m.logger.ErrorKV("OAuth callback listener failed", "error", err)
+		}
+	}()
+}
+
+// Close stops the callback listener
+func (m *Manager) Close() error {
+	if m.server == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return m.server.Shutdown(ctx)
+}
+
+// HandleCallback completes a linking flow by exchanging the authorization code
+func (m *Manager) HandleCallback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	state := query.Get("state")
+
+	m.mu.Lock()
+	link, ok := m.pending[state]
+	delete(m.pending, state)
+	notify := m.notify
+	m.mu.Unlock()
+
+	if !ok || time.Now().After(link.expires) {
+		http.Error(w, "This link has expired. Ask the bot for a new one.", http.StatusBadRequest)
+		return
+	}
+	if errCode := query.Get("error"); errCode != "" {
+		m.logger.WarnKV("OAuth authorization was denied", "server", link.server, "user", link.userID, "error", errCode)
+		http.Error(w, "Authorization was not granted: "+errCode, http.StatusBadRequest)
+		return
+	}
+
+	token, err := m.oauth[link.server].Exchange(r.Context(), query.Get("code"))
+	if err != nil {
+		m.logger.ErrorKV("OAuth code exchange failed", "server", link.server, "user", link.userID, "error", err)
+		http.Error(w, "Failed to complete authorization.", http.StatusBadGateway)
+		return
+	}
+	if err := m.vault.Put(link.userID, link.server, token); err != nil {
+		m.logger.ErrorKV("Failed to store linked token", "server", link.server, "user", link.userID, "error", err)
+		http.Error(w, "Failed to store credentials.", http.StatusInternalServerError)
+		return
+	}
+
+	m.logger.InfoKV("Linked user credentials", "server", link.server, "user", link.userID)
+	notify(link.userID, fmt.Sprintf("Your *%s* account is now linked. Tools from this server will run with your credentials.", link.server))
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = fmt.Fprintf(w, "Your %s account is linked. You can close this window and return to Slack.", link.server)
+}
@@ -0,0 +1,161 @@
+// Package credentials stores per-user tokens for MCP servers and implements the
+// OAuth flow users go through (via DM) to link their own accounts.
+package credentials
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+// ErrNotLinked is returned when a user has not linked an account for a server
+var ErrNotLinked = errors.New("no linked credentials")
+
+// Vault stores OAuth tokens per Slack user and MCP server
+type Vault interface {
+	Get(userID, server string) (*oauth2.Token, error)
+	Put(userID, server string, token *oauth2.Token) error
+	Delete(userID, server string) error
+	List(userID string) ([]string, error)
+}
+
+// FileVault is a Vault persisted to a single AES-GCM encrypted JSON file
+type FileVault struct {
+	path    string
+	aead    cipher.AEAD
+	mu      sync.Mutex
+	entries map[string]map[string]*oauth2.Token // userID -> server -> token
+}
+
+// NewFileVault opens (or creates) an encrypted vault file. The encryption key is
+// derived from the given secret with SHA-256.
+func NewFileVault(path, secret string) (*FileVault, error) {
+	if secret == "" {
+		return nil, fmt.Errorf("credential vault encryption key must be set")
+	}
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+
+	v := &FileVault{
+		path:    path,
+		aead:    aead,
+		entries: make(map[string]map[string]*oauth2.Token),
+	}
+	if err := v.load(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Get implements Vault
+func (v *FileVault) Get(userID, server string) (*oauth2.Token, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	token, ok := v.entries[userID][server]
+	if !ok {
+		return nil, ErrNotLinked
+	}
+	return token, nil
+}
+
+// Put implements Vault
+func (v *FileVault) Put(userID, server string, token *oauth2.Token) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.entries[userID] == nil {
+		v.entries[userID] = make(map[string]*oauth2.Token)
+	}
+	v.entries[userID][server] = token
+	return v.save()
+}
+
+// Delete implements Vault
+func (v *FileVault) Delete(userID, server string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if _, ok := v.entries[userID][server]; !ok {
+		return ErrNotLinked
+	}
+	delete(v.entries[userID], server)
+	if len(v.entries[userID]) == 0 {
+		delete(v.entries, userID)
+	}
+	return v.save()
+}
+
+// List implements Vault
+func (v *FileVault) List(userID string) ([]string, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	servers := make([]string, 0, len(v.entries[userID]))
+	for server := range v.entries[userID] {
+		servers = append(servers, server)
+	}
+	return servers, nil
+}
+
+// load decrypts the vault file if it exists
+func (v *FileVault) load() error {
+	data, err := os.ReadFile(v.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read credential vault: %w", err)
+	}
+
+	nonceSize := v.aead.NonceSize()
+	if len(data) < nonceSize {
+		return fmt.Errorf("credential vault is corrupted")
+	}
+	plaintext, err := v.aead.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt credential vault (wrong encryption key?): %w", err)
+	}
+	if err := json.Unmarshal(plaintext, &v.entries); err != nil {
+		return fmt.Errorf("failed to parse credential vault: %w", err)
+	}
+	return nil
+}
+
+// save encrypts and atomically writes the vault file; callers must hold mu
+func (v *FileVault) save() error {
+	plaintext, err := json.Marshal(v.entries)
+	if err != nil {
+		return fmt.Errorf("failed to marshal credential vault: %w", err)
+	}
+	nonce := make([]byte, v.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	data := v.aead.Seal(nonce, nonce, plaintext, nil)
+
+	if err := os.MkdirAll(filepath.Dir(v.path), 0700); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	tmp := v.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write credential vault: %w", err)
+	}
+	if err := os.Rename(tmp, v.path); err != nil {
+		return fmt.Errorf("failed to replace credential vault: %w", err)
+	}
+	return nil
+}
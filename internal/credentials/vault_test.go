@@ -0,0 +1,53 @@
+package credentials
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func TestFileVaultRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.json")
+
+	vault, err := NewFileVault(path, "secret")
+	require.NoError(t, err)
+
+	_, err = vault.Get("U1", "github")
+	assert.True(t, errors.Is(err, ErrNotLinked))
+
+	require.NoError(t, vault.Put("U1", "github", &oauth2.Token{AccessToken: "abc", RefreshToken: "def"}))
+
+	reopened, err := NewFileVault(path, "secret")
+	require.NoError(t, err)
+	token, err := reopened.Get("U1", "github")
+	require.NoError(t, err)
+	assert.Equal(t, "abc", token.AccessToken)
+	assert.Equal(t, "def", token.RefreshToken)
+
+	servers, err := reopened.List("U1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"github"}, servers)
+
+	require.NoError(t, reopened.Delete("U1", "github"))
+	assert.True(t, errors.Is(reopened.Delete("U1", "github"), ErrNotLinked))
+}
+
+func TestFileVaultWrongKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.json")
+
+	vault, err := NewFileVault(path, "secret")
+	require.NoError(t, err)
+	require.NoError(t, vault.Put("U1", "github", &oauth2.Token{AccessToken: "abc"}))
+
+	_, err = NewFileVault(path, "other-secret")
+	assert.Error(t, err)
+}
+
+func TestNewFileVaultRequiresKey(t *testing.T) {
+	_, err := NewFileVault(filepath.Join(t.TempDir(), "credentials.json"), "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,103 @@
+// Package dedupe provides shared Slack event de-duplication so that multiple
+// replicas of the client can consume the same event stream concurrently while
+// each event is processed exactly once.
+package dedupe
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+// Store claims event IDs. Claim returns true only for the first caller that
+// claims a given ID within the TTL window.
+type Store interface {
+	Claim(ctx context.Context, eventID string) (bool, error)
+	Close() error
+}
+
+// NewStore creates the store selected by the dedupe configuration
+func NewStore(cfg config.DedupeConfig, logger *logging.Logger) (Store, error) {
+	ttl, err := time.ParseDuration(cfg.TTL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dedupe ttl '%s': %w", cfg.TTL, err)
+	}
+
+	switch cfg.Provider {
+	case config.DedupeProviderRedis:
+		logger.InfoKV("Using Redis event de-duplication", "replica", cfg.ReplicaID, "ttl", ttl)
+		return NewRedisStore(cfg.RedisURL, cfg.KeyPrefix, ttl)
+	case config.DedupeProviderMemory, "":
+		logger.InfoKV("Using in-memory event de-duplication", "replica", cfg.ReplicaID, "ttl", ttl, "maxEntries", cfg.MaxEntries)
+		return NewMemoryStore(ttl, cfg.MaxEntries), nil
+	default:
+		return nil, fmt.Errorf("unknown dedupe provider '%s'", cfg.Provider)
+	}
+}
+
+// MemoryStore is a process-local Store. It protects against Slack redelivering
+// an event to the same replica but does not coordinate between replicas. It
+// remembers at most maxEntries event IDs, dropping the least recently seen.
+type MemoryStore struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	order      *list.List               // Claimed events, most recently seen first
+	claimed    map[string]*list.Element // Elements hold *memoryClaim
+	now        func() time.Time
+}
+
+// memoryClaim is an event ID remembered by a MemoryStore
+type memoryClaim struct {
+	eventID string
+	expiry  time.Time
+}
+
+// NewMemoryStore creates a new in-memory store. maxEntries <= 0 means no limit.
+func NewMemoryStore(ttl time.Duration, maxEntries int) *MemoryStore {
+	return &MemoryStore{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		order:      list.New(),
+		claimed:    make(map[string]*list.Element),
+		now:        time.Now,
+	}
+}
+
+// Claim implements Store
+func (m *MemoryStore) Claim(_ context.Context, eventID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	if element, exists := m.claimed[eventID]; exists {
+		claim := element.Value.(*memoryClaim)
+		if !now.After(claim.expiry) {
+			m.order.MoveToFront(element)
+			return false, nil
+		}
+		m.order.Remove(element)
+		delete(m.claimed, eventID)
+	}
+
+	m.claimed[eventID] = m.order.PushFront(&memoryClaim{eventID: eventID, expiry: now.Add(m.ttl)})
+	for oldest := m.order.Back(); oldest != nil; oldest = m.order.Back() {
+		claim := oldest.Value.(*memoryClaim)
+		if !now.After(claim.expiry) && (m.maxEntries <= 0 || m.order.Len() <= m.maxEntries) {
+			break
+		}
+		m.order.Remove(oldest)
+		delete(m.claimed, claim.eventID)
+	}
+	return true, nil
+}
+
+// Close implements Store
+func (m *MemoryStore) Close() error {
+	return nil
+}
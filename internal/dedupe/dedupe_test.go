@@ -0,0 +1,61 @@
+package dedupe
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStoreClaim(t *testing.T) {
+	store := NewMemoryStore(time.Minute, 0)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	ok, err := store.Claim(ctx, "Ev1")
+	assert.NoError(t, err)
+	assert.True(t, ok, "first claim should succeed")
+
+	ok, err = store.Claim(ctx, "Ev1")
+	assert.NoError(t, err)
+	assert.False(t, ok, "duplicate claim should be rejected")
+
+	ok, err = store.Claim(ctx, "Ev2")
+	assert.NoError(t, err)
+	assert.True(t, ok, "different event should be claimable")
+
+	// After the TTL the event ID may be claimed again
+	now = now.Add(2 * time.Minute)
+	ok, err = store.Claim(ctx, "Ev1")
+	assert.NoError(t, err)
+	assert.True(t, ok, "claim should succeed after ttl expiry")
+}
+
+func TestMemoryStoreEvictsLeastRecentlySeen(t *testing.T) {
+	store := NewMemoryStore(time.Hour, 2)
+	ctx := context.Background()
+
+	for _, id := range []string{"Ev1", "Ev2"} {
+		ok, err := store.Claim(ctx, id)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	}
+	// A redelivery of Ev1 makes Ev2 the least recently seen
+	ok, err := store.Claim(ctx, "Ev1")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = store.Claim(ctx, "Ev3")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 2, store.order.Len())
+
+	ok, err = store.Claim(ctx, "Ev1")
+	assert.NoError(t, err)
+	assert.False(t, ok, "recently seen event should still be remembered")
+	ok, err = store.Claim(ctx, "Ev2")
+	assert.NoError(t, err)
+	assert.True(t, ok, "evicted event is claimable again")
+}
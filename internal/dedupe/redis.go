@@ -0,0 +1,52 @@
+package dedupe
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore is a Store shared between replicas, backed by Redis SETNX
+type RedisStore struct {
+	client    *redis.Client
+	keyPrefix string
+	ttl       time.Duration
+}
+
+// NewRedisStore connects to Redis using the given URL
+func NewRedisStore(redisURL, keyPrefix string, ttl time.Duration) (*RedisStore, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis url: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	return &RedisStore{
+		client:    client,
+		keyPrefix: keyPrefix,
+		ttl:       ttl,
+	}, nil
+}
+
+// Claim implements Store
+func (r *RedisStore) Claim(ctx context.Context, eventID string) (bool, error) {
+	ok, err := r.client.SetNX(ctx, r.keyPrefix+eventID, 1, r.ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim event %s: %w", eventID, err)
+	}
+	return ok, nil
+}
+
+// Close implements Store
+func (r *RedisStore) Close() error {
+	return r.client.Close()
+}
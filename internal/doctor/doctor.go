@@ -0,0 +1,225 @@
+// Package doctor diagnoses the environment of the bot: the Slack tokens and
+// their scopes, the LLM providers and their models, the MCP servers and the
+// knowledge base. Every problem found comes with a hint on how to fix it.
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tuannvm/slack-mcp-client/internal/app"
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+
+	slackbot "github.com/tuannvm/slack-mcp-client/internal/slack"
+)
+
+// Status is the outcome of a check
+type Status int
+
+// Outcomes of a check, from best to worst
+const (
+	StatusSkip Status = iota // Not applicable to this configuration
+	StatusPass
+	StatusWarn // Works, but something is likely to go wrong
+	StatusFail
+)
+
+// String returns the label printed in the report
+func (s Status) String() string {
+	switch s {
+	case StatusPass:
+		return "PASS"
+	case StatusWarn:
+		return "WARN"
+	case StatusFail:
+		return "FAIL"
+	default:
+		return "SKIP"
+	}
+}
+
+// ANSI colors of the statuses
+var statusColors = map[Status]string{
+	StatusSkip: "\033[90m",
+	StatusPass: "\033[32m",
+	StatusWarn: "\033[33m",
+	StatusFail: "\033[31m",
+}
+
+// Result is the outcome of one check
+type Result struct {
+	Area   string // "Slack", "LLM", "MCP" or "RAG"
+	Name   string // What was checked, e.g. "bot token" or a server name
+	Status Status
+	Detail string // What was found
+	Hint   string // How to fix a failure or warning
+}
+
+// Report is the outcome of every check
+type Report struct {
+	Results []Result
+}
+
+func (r *Report) add(area, name string, status Status, detail, hint string) {
+	r.Results = append(r.Results, Result{Area: area, Name: name, Status: status, Detail: detail, Hint: hint})
+}
+
+// Failed reports whether a check failed
+func (r *Report) Failed() bool {
+	for _, result := range r.Results {
+		if result.Status == StatusFail {
+			return true
+		}
+	}
+	return false
+}
+
+// Print writes the report grouped by area, with colored statuses when color is set
+func (r *Report) Print(w io.Writer, color bool) {
+	counts := make(map[Status]int)
+	area := ""
+	for _, result := range r.Results {
+		if result.Area != area {
+			if area != "" {
+				fmt.Fprintln(w)
+			}
+			area = result.Area
+			fmt.Fprintln(w, area)
+		}
+		counts[result.Status]++
+		label := result.Status.String()
+		if color {
+			label = statusColors[result.Status] + label + "\033[0m"
+		}
+		fmt.Fprintf(w, "  %s  %s: %s\n", label, result.Name, result.Detail)
+		if result.Hint != "" && (result.Status == StatusWarn || result.Status == StatusFail) {
+			fmt.Fprintf(w, "        → %s\n", result.Hint)
+		}
+	}
+	fmt.Fprintf(w, "\n%d passed, %d warning(s), %d failed, %d skipped\n",
+		counts[StatusPass], counts[StatusWarn], counts[StatusFail], counts[StatusSkip])
+}
+
+// Checker runs the checks against a configuration
+type Checker struct {
+	cfg        *config.Config
+	logger     *logging.Logger
+	httpClient *http.Client
+	slackURL   string // Base URL of the Slack Web API
+}
+
+// NewChecker creates a Checker for a loaded configuration
+func NewChecker(cfg *config.Config, logger *logging.Logger) *Checker {
+	return &Checker{
+		cfg:        cfg,
+		logger:     logger,
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+		slackURL:   "https://slack.com/api/",
+	}
+}
+
+// Run checks the frontend, the LLM providers, the MCP servers and the knowledge base
+func (c *Checker) Run(ctx context.Context) *Report {
+	report := &Report{}
+	c.checkFrontend(ctx, report)
+	c.checkLLMProviders(ctx, report)
+	c.checkMCPServers(report)
+	c.checkRAG(ctx, report)
+	return report
+}
+
+// checkMCPServers starts each enabled MCP server and discovers its tools
+func (c *Checker) checkMCPServers(report *Report) {
+	const area = "MCP"
+	if len(c.cfg.MCPServers) == 0 {
+		report.add(area, "servers", StatusSkip, "no MCP servers configured", "")
+		return
+	}
+	names := make([]string, 0, len(c.cfg.MCPServers))
+	for name := range c.cfg.MCPServers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		server := c.cfg.MCPServers[name]
+		if server.Disabled {
+			report.add(area, name, StatusSkip, "disabled", "")
+			continue
+		}
+		started := time.Now()
+		tools, err := app.CheckMCPServer(c.logger, c.cfg, name)
+		elapsed := time.Since(started).Round(time.Millisecond)
+		switch {
+		case err != nil:
+			report.add(area, name, StatusFail, fmt.Sprintf("failed to start: %v", err), mcpHint(server, err))
+		case tools == 0:
+			report.add(area, name, StatusWarn, fmt.Sprintf("started in %s but exposes no tools", elapsed),
+				"Check the server's tools.allowList and tools.blockList")
+		default:
+			report.add(area, name, StatusPass, fmt.Sprintf("started in %s with %d tool(s)", elapsed, tools), "")
+		}
+	}
+}
+
+// mcpHint suggests how to fix an MCP server that failed to start
+func mcpHint(server config.MCPServerConfig, err error) string {
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "deadline exceeded") || strings.Contains(message, "timeout"):
+		return "The server did not answer in time; raise initializeTimeoutSeconds or check that it starts on its own"
+	case strings.Contains(message, "executable file not found") || strings.Contains(message, "no such file"):
+		return fmt.Sprintf("Install %q or fix the command's path", server.Command)
+	case server.URL != "":
+		return fmt.Sprintf("Check that the server is running at %s and that its transport is %q", server.URL, server.Transport)
+	case server.Command != "":
+		return fmt.Sprintf("Run %q by hand to see why it exits", strings.TrimSpace(server.Command+" "+strings.Join(server.Args, " ")))
+	default:
+		return "Check the server's configuration with the config validate command"
+	}
+}
+
+// checkRAG opens the knowledge base and reads its statistics
+func (c *Checker) checkRAG(ctx context.Context, report *Report) {
+	const area = "RAG"
+	if !c.cfg.RAG.Enabled {
+		report.add(area, "knowledge base", StatusSkip, "RAG is disabled", "")
+		return
+	}
+	name := c.cfg.RAG.Provider + " store"
+	hint := "Check rag.providers." + c.cfg.RAG.Provider + " in the configuration"
+	switch c.cfg.RAG.Provider {
+	case "simple", "sqlite", "json":
+		hint = "Check that rag.providers." + c.cfg.RAG.Provider + ".databasePath is readable and writable"
+	case "openai":
+		hint = "Check the OpenAI API key and rag.providers.openai.vectorStoreId"
+	}
+
+	ragClient, err := slackbot.NewRAGClient(c.cfg)
+	if err != nil {
+		report.add(area, name, StatusFail, fmt.Sprintf("cannot open: %v", err), hint)
+		return
+	}
+	defer func() {
+		if err := ragClient.GetProvider().Close(); err != nil {
+			c.logger.ErrorKV("Failed to close RAG client", "error", err)
+		}
+	}()
+	stats, err := ragClient.GetProvider().GetStats(ctx)
+	if err != nil {
+		report.add(area, name, StatusFail, fmt.Sprintf("cannot read statistics: %v", err), hint)
+		return
+	}
+	if stats.TotalFiles == 0 {
+		report.add(area, name, StatusWarn, "accessible but empty",
+			"Ingest documents with the rag ingest command, or configure rag.sources")
+		return
+	}
+	report.add(area, name, StatusPass, fmt.Sprintf("%d document(s), %d chunk(s)", stats.TotalFiles, stats.TotalChunks), "")
+}
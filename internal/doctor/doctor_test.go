@@ -0,0 +1,156 @@
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+// newTestAPIs fakes the Slack Web API and the OpenAI and Ollama model endpoints
+func newTestAPIs(t *testing.T, scopes string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization := r.Header.Get("Authorization")
+		switch r.URL.Path {
+		case "/slack/auth.test":
+			if authorization != "Bearer xoxb-good" {
+				_, _ = w.Write([]byte(`{"ok": false, "error": "invalid_auth"}`))
+				return
+			}
+			w.Header().Set("X-OAuth-Scopes", scopes)
+			_, _ = w.Write([]byte(`{"ok": true, "user": "helper", "team": "Acme", "team_id": "T1"}`))
+		case "/slack/apps.connections.open":
+			if authorization != "Bearer xapp-good" {
+				_, _ = w.Write([]byte(`{"ok": false, "error": "not_allowed_token_type"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"ok": true, "url": "wss://example.com"}`))
+		case "/openai/models/gpt-4o":
+			if authorization != "Bearer sk-good" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"id": "gpt-4o"}`))
+		case "/api/tags":
+			_, _ = w.Write([]byte(`{"models": [{"name": "llama3:latest"}, {"name": "qwen3:8b"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestChecker(server *httptest.Server, cfg *config.Config) *Checker {
+	checker := NewChecker(cfg, logging.New("test", logging.LevelError))
+	checker.slackURL = server.URL + "/slack/"
+	return checker
+}
+
+// statuses returns the status of each check by area and name
+func statuses(report *Report) map[string]Status {
+	result := make(map[string]Status)
+	for _, r := range report.Results {
+		result[r.Area+"/"+r.Name] = r.Status
+	}
+	return result
+}
+
+func TestDoctorHealthyEnvironment(t *testing.T) {
+	server := newTestAPIs(t, "app_mentions:read,chat:write,im:history,im:read,channels:history,groups:history,mpim:history,users:read,reactions:read")
+	cfg := &config.Config{
+		Slack: config.SlackConfig{BotToken: "xoxb-good", AppToken: "xapp-good"},
+		LLM: config.LLMConfig{Provider: "openai", Providers: map[string]config.LLMProviderConfig{
+			"openai":    {Model: "gpt-4o", APIKey: "sk-good", BaseURL: server.URL + "/openai"},
+			"anthropic": {Model: "claude-3-5-sonnet-20241022"},
+			"ollama":    {Model: "llama3", BaseURL: server.URL},
+		}},
+		MCPServers: map[string]config.MCPServerConfig{
+			"time": {Builtin: "time"},
+			"old":  {Command: "old-server", Disabled: true},
+		},
+		RAG: config.RAGConfig{Enabled: true, Provider: "simple", Providers: map[string]config.RAGProviderConfig{
+			"simple": {DatabasePath: filepath.Join(t.TempDir(), "knowledge.json")},
+		}},
+	}
+
+	report := newTestChecker(server, cfg).Run(context.Background())
+	assert.False(t, report.Failed())
+	assert.Equal(t, map[string]Status{
+		"Slack/bot token":      StatusPass,
+		"Slack/scopes":         StatusPass,
+		"Slack/app token":      StatusPass,
+		"LLM/anthropic":        StatusSkip,
+		"LLM/ollama":           StatusPass,
+		"LLM/openai (primary)": StatusPass,
+		"MCP/old":              StatusSkip,
+		"MCP/time":             StatusPass,
+		"RAG/simple store":     StatusWarn, // Empty
+	}, statuses(report))
+
+	var out bytes.Buffer
+	report.Print(&out, false)
+	assert.Contains(t, out.String(), "Slack\n  PASS  bot token: authenticated as @helper in Acme (T1)\n")
+	assert.Contains(t, out.String(), "\n6 passed, 1 warning(s), 0 failed, 2 skipped\n")
+	assert.NotContains(t, out.String(), "\033[")
+}
+
+func TestDoctorReportsProblems(t *testing.T) {
+	server := newTestAPIs(t, "chat:write,app_mentions:read")
+	cfg := &config.Config{
+		Slack: config.SlackConfig{BotToken: "xoxb-good", AppToken: "xoxb-good"},
+		LLM: config.LLMConfig{Provider: "ollama", Providers: map[string]config.LLMProviderConfig{
+			"openai": {Model: "gpt-4o", APIKey: "sk-bad", BaseURL: server.URL + "/openai"},
+			"ollama": {Model: "mistral", BaseURL: server.URL},
+		}},
+		MCPServers: map[string]config.MCPServerConfig{
+			"missing": {Command: "/nonexistent/mcp-server"},
+		},
+	}
+
+	report := newTestChecker(server, cfg).Run(context.Background())
+	assert.True(t, report.Failed())
+	assert.Equal(t, map[string]Status{
+		"Slack/bot token":      StatusPass,
+		"Slack/scopes":         StatusFail,
+		"Slack/app token":      StatusFail,
+		"LLM/ollama (primary)": StatusFail,
+		"LLM/openai":           StatusWarn, // Not the primary provider
+		"MCP/missing":          StatusFail,
+		"RAG/knowledge base":   StatusSkip,
+	}, statuses(report))
+
+	var out bytes.Buffer
+	report.Print(&out, true)
+	assert.Contains(t, out.String(), "\033[31mFAIL\033[0m  scopes: missing im:history, im:read\n")
+	assert.Contains(t, out.String(), "→ slack.appToken must be an xapp- app-level token, not a bot token\n")
+	assert.Contains(t, out.String(), `→ Run "ollama pull mistral"`)
+}
+
+func TestDoctorSkipsOtherFrontends(t *testing.T) {
+	server := newTestAPIs(t, "")
+	cfg := &config.Config{
+		Frontend: config.FrontendDiscord,
+		LLM: config.LLMConfig{Provider: "openai", Providers: map[string]config.LLMProviderConfig{
+			"openai": {Model: "gpt-4o"},
+		}},
+	}
+
+	report := newTestChecker(server, cfg).Run(context.Background())
+	assert.Equal(t, map[string]Status{
+		"Slack/tokens":         StatusSkip,
+		"LLM/openai (primary)": StatusFail, // No API key
+		"MCP/servers":          StatusSkip,
+		"RAG/knowledge base":   StatusSkip,
+	}, statuses(report))
+	require.Len(t, report.Results, 4)
+	assert.Equal(t, "Set OPENAI_API_KEY or llm.providers.openai.apiKey", report.Results[1].Hint)
+}
@@ -0,0 +1,165 @@
+package doctor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+// Default API base URLs of the LLM providers
+const (
+	defaultOpenAIURL    = "https://api.openai.com/v1"
+	defaultAnthropicURL = "https://api.anthropic.com/v1"
+	defaultOllamaURL    = "http://localhost:11434"
+)
+
+// checkLLMProviders checks that each configured provider is reachable and serves
+// its model
+func (c *Checker) checkLLMProviders(ctx context.Context, report *Report) {
+	const area = "LLM"
+	if len(c.cfg.LLM.Providers) == 0 {
+		report.add(area, "providers", StatusFail, "no LLM providers configured",
+			"Add a provider under llm.providers, e.g. openai with OPENAI_API_KEY set")
+		return
+	}
+	names := make([]string, 0, len(c.cfg.LLM.Providers))
+	for name := range c.cfg.LLM.Providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		provider := c.cfg.LLM.Providers[name]
+		if name == c.cfg.LLM.Provider {
+			status, detail, hint := c.checkLLMProvider(ctx, name, provider)
+			report.add(area, name+" (primary)", status, detail, hint)
+			continue
+		}
+		if !configuredProvider(name, provider) {
+			report.add(area, name, StatusSkip, "not configured", "")
+			continue
+		}
+		// Other providers only serve routing, experiments or embeddings
+		status, detail, hint := c.checkLLMProvider(ctx, name, provider)
+		if status == StatusFail {
+			status = StatusWarn
+		}
+		report.add(area, name, status, detail, hint)
+	}
+}
+
+// configuredProvider reports whether a provider was set up beyond the defaults,
+// which list every provider type
+func configuredProvider(name string, provider config.LLMProviderConfig) bool {
+	if name == config.ProviderOllama {
+		return provider.BaseURL != defaultOllamaURL
+	}
+	return provider.APIKey != "" || provider.BaseURL != ""
+}
+
+// checkLLMProvider checks one provider and returns the outcome
+func (c *Checker) checkLLMProvider(ctx context.Context, name string, provider config.LLMProviderConfig) (Status, string, string) {
+	if provider.Model == "" {
+		return StatusFail, "no model configured", fmt.Sprintf("Set llm.providers.%s.model", name)
+	}
+	switch name {
+	case config.ProviderOpenAI:
+		if provider.APIKey == "" && provider.BaseURL == "" {
+			return StatusFail, "no API key", "Set OPENAI_API_KEY or llm.providers.openai.apiKey"
+		}
+		return c.checkModelEndpoint(ctx, name, provider, orDefault(provider.BaseURL, defaultOpenAIURL), func(req *http.Request) {
+			if provider.APIKey != "" {
+				req.Header.Set("Authorization", "Bearer "+provider.APIKey)
+			}
+		})
+	case config.ProviderAnthropic:
+		if provider.APIKey == "" {
+			return StatusFail, "no API key", "Set ANTHROPIC_API_KEY or llm.providers.anthropic.apiKey"
+		}
+		return c.checkModelEndpoint(ctx, name, provider, orDefault(provider.BaseURL, defaultAnthropicURL), func(req *http.Request) {
+			req.Header.Set("x-api-key", provider.APIKey)
+			req.Header.Set("anthropic-version", "2023-06-01")
+		})
+	case config.ProviderOllama:
+		return c.checkOllama(ctx, provider)
+	default:
+		return StatusSkip, "no check for this provider type", ""
+	}
+}
+
+// checkModelEndpoint looks up the model with the models endpoint of an OpenAI or
+// Anthropic compatible API
+func (c *Checker) checkModelEndpoint(ctx context.Context, name string, provider config.LLMProviderConfig, baseURL string, authorize func(*http.Request)) (Status, string, string) {
+	endpoint := strings.TrimRight(baseURL, "/") + "/models/" + provider.Model
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return StatusFail, fmt.Sprintf("invalid base URL: %v", err), fmt.Sprintf("Check llm.providers.%s.baseUrl", name)
+	}
+	authorize(req)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return StatusFail, fmt.Sprintf("cannot reach %s: %v", baseURL, err),
+			fmt.Sprintf("Check llm.providers.%s.baseUrl and the network and proxy settings", name)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return StatusPass, fmt.Sprintf("model %s available", provider.Model), ""
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return StatusFail, fmt.Sprintf("API key rejected (status %d)", resp.StatusCode),
+			fmt.Sprintf("Check the API key of the %s provider", name)
+	case resp.StatusCode == http.StatusNotFound:
+		return StatusFail, fmt.Sprintf("model %s not found", provider.Model),
+			fmt.Sprintf("Set llm.providers.%s.model to a model your account can use", name)
+	default:
+		return StatusWarn, fmt.Sprintf("reachable, but the model lookup returned status %d", resp.StatusCode),
+			"The API may not support model lookups; send a test message with the chat command"
+	}
+}
+
+// checkOllama checks that the Ollama server runs and has pulled the model
+func (c *Checker) checkOllama(ctx context.Context, provider config.LLMProviderConfig) (Status, string, string) {
+	baseURL := orDefault(provider.BaseURL, defaultOllamaURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(baseURL, "/")+"/api/tags", nil)
+	if err != nil {
+		return StatusFail, fmt.Sprintf("invalid base URL: %v", err), "Check llm.providers.ollama.baseUrl"
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return StatusFail, fmt.Sprintf("cannot reach %s: %v", baseURL, err),
+			"Start Ollama with \"ollama serve\", or set llm.providers.ollama.baseUrl to where it runs"
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return StatusFail, fmt.Sprintf("%s returned status %d", baseURL, resp.StatusCode), "Check llm.providers.ollama.baseUrl"
+	}
+
+	var tags struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return StatusFail, fmt.Sprintf("invalid response from %s: %v", baseURL, err), "Check that baseUrl points to an Ollama server"
+	}
+	for _, model := range tags.Models {
+		if model.Name == provider.Model || model.Name == provider.Model+":latest" {
+			return StatusPass, fmt.Sprintf("model %s available", provider.Model), ""
+		}
+	}
+	return StatusFail, fmt.Sprintf("model %s not pulled", provider.Model), fmt.Sprintf("Run \"ollama pull %s\"", provider.Model)
+}
+
+// orDefault returns value, or fallback when it is empty
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
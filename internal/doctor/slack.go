@@ -0,0 +1,143 @@
+package doctor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// requiredSlackScopes are the bot scopes without which the bot cannot answer
+var requiredSlackScopes = []string{"app_mentions:read", "chat:write", "im:history", "im:read"}
+
+// recommendedSlackScopes are the bot scopes that features rely on
+var recommendedSlackScopes = []string{"channels:history", "groups:history", "mpim:history", "users:read", "reactions:read"}
+
+// slackResponse is the part of a Web API response the checks read
+type slackResponse struct {
+	OK     bool   `json:"ok"`
+	Error  string `json:"error"`
+	User   string `json:"user"`
+	Team   string `json:"team"`
+	TeamID string `json:"team_id"`
+}
+
+// checkFrontend checks the tokens of the Slack frontend. Other frontends are
+// checked when the bot connects.
+func (c *Checker) checkFrontend(ctx context.Context, report *Report) {
+	const area = "Slack"
+	if !c.cfg.IsSlackFrontend() {
+		report.add(area, "tokens", StatusSkip, fmt.Sprintf("the %s frontend is configured", c.cfg.Frontend), "")
+		return
+	}
+
+	// The bot token and its scopes
+	switch response, scopes, err := c.callSlack(ctx, "auth.test", c.cfg.Slack.BotToken); {
+	case c.cfg.Slack.BotToken == "":
+		report.add(area, "bot token", StatusFail, "not set",
+			"Set SLACK_BOT_TOKEN or slack.botToken to the xoxb- token under OAuth & Permissions")
+	case err != nil:
+		report.add(area, "bot token", StatusFail, fmt.Sprintf("cannot reach Slack: %v", err),
+			"Check the network and the proxy settings")
+	case !response.OK:
+		report.add(area, "bot token", StatusFail, "rejected: "+response.Error, slackTokenHint(response.Error, "bot"))
+	default:
+		report.add(area, "bot token", StatusPass, fmt.Sprintf("authenticated as @%s in %s (%s)", response.User, response.Team, response.TeamID), "")
+		c.checkSlackScopes(report, scopes)
+	}
+
+	// The app-level token opens Socket Mode connections
+	switch response, _, err := c.callSlack(ctx, "apps.connections.open", c.cfg.Slack.AppToken); {
+	case c.cfg.Slack.AppToken == "":
+		report.add(area, "app token", StatusFail, "not set",
+			"Set SLACK_APP_TOKEN or slack.appToken to an xapp- app-level token with the connections:write scope")
+	case err != nil:
+		report.add(area, "app token", StatusFail, fmt.Sprintf("cannot reach Slack: %v", err),
+			"Check the network and the proxy settings")
+	case !response.OK:
+		report.add(area, "app token", StatusFail, "rejected: "+response.Error, slackTokenHint(response.Error, "app"))
+	default:
+		report.add(area, "app token", StatusPass, "Socket Mode connections allowed", "")
+	}
+}
+
+// checkSlackScopes compares the scopes granted to the bot token with the ones it needs
+func (c *Checker) checkSlackScopes(report *Report, header string) {
+	const area = "Slack"
+	if header == "" {
+		report.add(area, "scopes", StatusSkip, "Slack did not list the token's scopes", "")
+		return
+	}
+	granted := make(map[string]bool)
+	for _, scope := range strings.Split(header, ",") {
+		granted[strings.TrimSpace(scope)] = true
+	}
+	missing := func(scopes []string) []string {
+		var result []string
+		for _, scope := range scopes {
+			if !granted[scope] {
+				result = append(result, scope)
+			}
+		}
+		return result
+	}
+
+	hint := "Add the scopes under OAuth & Permissions and reinstall the app"
+	if required := missing(requiredSlackScopes); len(required) > 0 {
+		report.add(area, "scopes", StatusFail, "missing "+strings.Join(required, ", "), hint)
+		return
+	}
+	if recommended := missing(recommendedSlackScopes); len(recommended) > 0 {
+		report.add(area, "scopes", StatusWarn, "missing "+strings.Join(recommended, ", ")+", which some features need", hint)
+		return
+	}
+	report.add(area, "scopes", StatusPass, fmt.Sprintf("%d scope(s) granted", len(granted)), "")
+}
+
+// callSlack calls a Web API method with a token and returns its response and the
+// scopes granted to the token
+func (c *Checker) callSlack(ctx context.Context, method, token string) (*slackResponse, string, error) {
+	if token == "" {
+		return nil, "", nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.slackURL+method, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("%s returned status %d", method, resp.StatusCode)
+	}
+
+	var response slackResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, "", fmt.Errorf("invalid %s response: %w", method, err)
+	}
+	return &response, resp.Header.Get("X-OAuth-Scopes"), nil
+}
+
+// slackTokenHint suggests how to fix a token Slack rejected with code
+func slackTokenHint(code, token string) string {
+	switch code {
+	case "invalid_auth", "not_authed", "token_revoked", "token_expired", "account_inactive":
+		if token == "app" {
+			return "Create a new app-level token with the connections:write scope under Basic Information"
+		}
+		return "Reinstall the app and copy the new Bot User OAuth Token"
+	case "not_allowed_token_type":
+		if token == "app" {
+			return "slack.appToken must be an xapp- app-level token, not a bot token"
+		}
+		return "slack.botToken must be an xoxb- bot token"
+	case "missing_scope":
+		return "Add the connections:write scope to the app-level token"
+	default:
+		return "See https://api.slack.com/methods for the meaning of the error"
+	}
+}
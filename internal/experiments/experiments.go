@@ -0,0 +1,462 @@
+// Package experiments runs A/B experiments on the bot's answers. Each experiment
+// sends a share of conversations to a variant with another system prompt or model,
+// and feedback reactions on the answers are counted per arm, so the variant can
+// be compared with the unchanged control arm before it is rolled out. A variant
+// that gets too much negative feedback or fails too often can be rolled back to
+// the control arm automatically, and the rollback persisted so it outlasts restarts.
+package experiments
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/monitoring"
+)
+
+// Arms of an experiment
+const (
+	ArmControl = "control"
+	ArmVariant = "variant"
+)
+
+// maxFeedbackSeen bounds the reactions remembered to count each user's feedback on
+// an answer once
+const maxFeedbackSeen = 10000
+
+// Assignment is the experiment arm that answers a conversation. The variant's
+// overrides are empty in the control arm.
+type Assignment struct {
+	Experiment   string
+	Arm          string
+	CustomPrompt string // System prompt override
+	Provider     string // Key of llm.providers override
+	Model        string // Model override
+}
+
+// Result is the feedback collected by an experiment arm
+type Result struct {
+	Experiment   string
+	Arm          string
+	Interactions int // Prompts answered
+	Positive     int // Positive feedback reactions
+	Negative     int // Negative feedback reactions
+	Errors       int // Prompts that failed
+}
+
+// Score is the share of feedback that was positive, or 0 without feedback
+func (r Result) Score() float64 {
+	if r.Positive+r.Negative == 0 {
+		return 0
+	}
+	return float64(r.Positive) / float64(r.Positive+r.Negative)
+}
+
+// Rollback is the automatic rollback of an experiment's variant to the control arm,
+// or the resumption of a variant that was changed since it was rolled back
+type Rollback struct {
+	Experiment   string
+	AdminChannel string // Channel ID to alert, empty when none is configured
+	Reason       string
+	Time         time.Time
+	Persisted    bool  // Saved to the rollback's store, so it lasts until the variant is changed
+	Resumed      bool  // The variant was changed since its persisted rollback and answers again
+	Err          error // Failure to save or clear the persisted rollback
+}
+
+// rollbackRecord is a rollback as persisted, with the variant it rolled back
+type rollbackRecord struct {
+	Reason  string                         `json:"reason"`
+	Time    time.Time                      `json:"time"`
+	Variant config.ExperimentVariantConfig `json:"variant"`
+}
+
+type armKey struct {
+	experiment string
+	arm        string
+}
+
+// eventKind is what happened in a conversation of a variant
+type eventKind int
+
+const (
+	eventAnswer eventKind = iota
+	eventPositive
+	eventNegative
+	eventError
+)
+
+// variantEvent is an answer, feedback reaction or failure in the rollback window
+type variantEvent struct {
+	time time.Time
+	kind eventKind
+}
+
+// Manager assigns conversations to experiment arms and aggregates their feedback
+type Manager struct {
+	experiments []config.ExperimentConfig
+	windows     map[string]time.Duration // Rollback windows, by experiment
+	now         func() time.Time
+
+	mu         sync.Mutex
+	results    map[armKey]*Result
+	seen       map[string]bool           // Feedback already counted, by message, user and sentiment
+	events     map[string][]variantEvent // Variant events in the rollback window, by experiment
+	rolledBack map[string]Rollback       // Variants rolled back, by experiment
+	onRollback func(Rollback)
+	resumed    []Rollback // Variants resumed at startup, told to the rollback handler once set
+}
+
+// NewManager returns a Manager for the enabled experiments, or nil when there are
+// none. Variants rolled back in an earlier run stay rolled back unless they were
+// changed since.
+func NewManager(experiments []config.ExperimentConfig) (*Manager, error) {
+	m := &Manager{
+		windows:    make(map[string]time.Duration),
+		now:        time.Now,
+		results:    make(map[armKey]*Result),
+		seen:       make(map[string]bool),
+		events:     make(map[string][]variantEvent),
+		rolledBack: make(map[string]Rollback),
+	}
+	for _, experiment := range experiments {
+		if experiment.Disabled {
+			continue
+		}
+		m.experiments = append(m.experiments, experiment)
+		if experiment.Rollback.Enabled {
+			m.windows[experiment.Name], _ = time.ParseDuration(experiment.Rollback.Window) // Validated with the config
+		}
+		for _, arm := range []string{ArmControl, ArmVariant} {
+			m.results[armKey{experiment.Name, arm}] = &Result{Experiment: experiment.Name, Arm: arm}
+		}
+		if err := m.restore(experiment); err != nil {
+			return nil, err
+		}
+	}
+	if len(m.experiments) == 0 {
+		return nil, nil
+	}
+	return m, nil
+}
+
+// restore reads the persisted rollback of an experiment. A rollback of the same
+// variant is kept; a changed variant resumes and its rollback is cleared.
+func (m *Manager) restore(experiment config.ExperimentConfig) error {
+	path := experiment.Rollback.StorePath
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read rollback of experiment %s: %w", experiment.Name, err)
+	}
+	var record rollbackRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return fmt.Errorf("failed to parse rollback of experiment %s: %w", experiment.Name, err)
+	}
+	rollback := Rollback{Experiment: experiment.Name, AdminChannel: experiment.Rollback.AdminChannel, Reason: record.Reason, Time: record.Time, Persisted: true}
+	if sameVariant(record.Variant, experiment.Variant) {
+		m.rolledBack[experiment.Name] = rollback
+		return nil
+	}
+	rollback.Resumed = true
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		rollback.Err = fmt.Errorf("failed to clear rollback of experiment %s: %w", experiment.Name, err)
+	}
+	m.resumed = append(m.resumed, rollback)
+	return nil
+}
+
+// sameVariant reports whether two variant configurations are equal
+func sameVariant(a, b config.ExperimentVariantConfig) bool {
+	first, _ := json.Marshal(a)
+	second, _ := json.Marshal(b)
+	return bytes.Equal(first, second)
+}
+
+// persist saves a rollback to the experiment's store, when it has one
+func persist(experiment config.ExperimentConfig, rollback Rollback) error {
+	path := experiment.Rollback.StorePath
+	data, err := json.Marshal(rollbackRecord{Reason: rollback.Reason, Time: rollback.Time, Variant: experiment.Variant})
+	if err != nil {
+		return fmt.Errorf("failed to marshal rollback: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write rollback: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace rollback: %w", err)
+	}
+	return nil
+}
+
+// SetRollbackHandler sets the function told about automatic rollbacks, and tells
+// it about the variants resumed at startup
+func (m *Manager) SetRollbackHandler(handler func(Rollback)) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.onRollback = handler
+	resumed := m.resumed
+	m.resumed = nil
+	m.mu.Unlock()
+	for i := range resumed {
+		m.notify(&resumed[i])
+	}
+}
+
+// Assign returns the arm that answers a prompt in the conversation, and counts the
+// interaction. A conversation belongs to the first experiment running in its
+// channel and always gets the same arm, so its answers stay consistent. Once a
+// variant is rolled back, its conversations are answered by the control arm.
+func (m *Manager) Assign(channelID, conversationID string) (Assignment, bool) {
+	assignment, ok := m.lookup(channelID, conversationID)
+	if !ok {
+		return Assignment{}, false
+	}
+	m.mu.Lock()
+	assignment = m.current(assignment)
+	m.results[armKey{assignment.Experiment, assignment.Arm}].Interactions++
+	rollback := m.observe(assignment, eventAnswer)
+	m.mu.Unlock()
+	monitoring.ExperimentInteractions.WithLabelValues(assignment.Experiment, assignment.Arm).Inc()
+	m.notify(rollback)
+	return assignment, true
+}
+
+// Feedback counts a reaction on an answer in the conversation towards its arm. A
+// user's feedback of the same sentiment on the same message is counted once, and
+// feedback in a conversation of a rolled back variant counts towards control.
+func (m *Manager) Feedback(channelID, conversationID, messageTS, userID string, positive bool) (Assignment, bool) {
+	assignment, ok := m.lookup(channelID, conversationID)
+	if !ok {
+		return Assignment{}, false
+	}
+	sentiment := "negative"
+	if positive {
+		sentiment = "positive"
+	}
+
+	m.mu.Lock()
+	assignment = m.current(assignment)
+	key := channelID + ":" + messageTS + ":" + userID + ":" + sentiment
+	if m.seen[key] {
+		m.mu.Unlock()
+		return assignment, false
+	}
+	if len(m.seen) >= maxFeedbackSeen {
+		m.seen = make(map[string]bool)
+	}
+	m.seen[key] = true
+	result := m.results[armKey{assignment.Experiment, assignment.Arm}]
+	kind := eventNegative
+	if positive {
+		result.Positive++
+		kind = eventPositive
+	} else {
+		result.Negative++
+	}
+	rollback := m.observe(assignment, kind)
+	m.mu.Unlock()
+
+	monitoring.ExperimentFeedback.WithLabelValues(assignment.Experiment, assignment.Arm, sentiment).Inc()
+	m.notify(rollback)
+	return assignment, true
+}
+
+// Error counts a failed prompt towards the arm of the request in ctx
+func (m *Manager) Error(ctx context.Context) {
+	assignment, ok := FromContext(ctx)
+	if m == nil || !ok {
+		return
+	}
+	m.mu.Lock()
+	result, exists := m.results[armKey{assignment.Experiment, assignment.Arm}]
+	if !exists {
+		m.mu.Unlock()
+		return
+	}
+	result.Errors++
+	rollback := m.observe(assignment, eventError)
+	m.mu.Unlock()
+	m.notify(rollback)
+}
+
+// Rollbacks returns the variants rolled back, by experiment name
+func (m *Manager) Rollbacks() []Rollback {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	rollbacks := make([]Rollback, 0, len(m.rolledBack))
+	for _, rollback := range m.rolledBack {
+		rollbacks = append(rollbacks, rollback)
+	}
+	sort.Slice(rollbacks, func(i, j int) bool { return rollbacks[i].Experiment < rollbacks[j].Experiment })
+	return rollbacks
+}
+
+// current returns the control arm for a conversation of a rolled back variant.
+// It is called with m.mu held.
+func (m *Manager) current(assignment Assignment) Assignment {
+	if _, rolledBack := m.rolledBack[assignment.Experiment]; rolledBack {
+		return Assignment{Experiment: assignment.Experiment, Arm: ArmControl}
+	}
+	return assignment
+}
+
+// observe records an event of a variant with rollback enabled, and rolls the
+// variant back when a rate in the window exceeds its limit. It is called with
+// m.mu held and returns the rollback, if any.
+func (m *Manager) observe(assignment Assignment, kind eventKind) *Rollback {
+	window, watched := m.windows[assignment.Experiment]
+	if !watched || assignment.Arm != ArmVariant {
+		return nil
+	}
+	if _, rolledBack := m.rolledBack[assignment.Experiment]; rolledBack {
+		return nil
+	}
+	now := m.now()
+	events := append(m.events[assignment.Experiment], variantEvent{time: now, kind: kind})
+	start := 0
+	for start < len(events) && now.Sub(events[start].time) > window {
+		start++
+	}
+	events = events[start:]
+	m.events[assignment.Experiment] = events
+
+	counts := make(map[eventKind]int)
+	for _, event := range events {
+		counts[event.kind]++
+	}
+	limits := m.experiment(assignment.Experiment).Rollback
+	var reason string
+	if answers := counts[eventAnswer]; answers >= max(limits.MinSamples, 1) && float64(counts[eventError]) > limits.MaxErrorRate*float64(answers) {
+		reason = fmt.Sprintf("%d of %d prompts failed in the last %s (limit %.0f%%)", counts[eventError], answers, window, 100*limits.MaxErrorRate)
+	} else if feedback := counts[eventPositive] + counts[eventNegative]; feedback >= max(limits.MinSamples, 1) && float64(counts[eventNegative]) > limits.MaxNegativeRate*float64(feedback) {
+		reason = fmt.Sprintf("%d of %d feedback reactions were negative in the last %s (limit %.0f%%)", counts[eventNegative], feedback, window, 100*limits.MaxNegativeRate)
+	}
+	if reason == "" {
+		return nil
+	}
+
+	rollback := Rollback{Experiment: assignment.Experiment, AdminChannel: limits.AdminChannel, Reason: reason, Time: now}
+	if limits.StorePath != "" {
+		if err := persist(m.experiment(assignment.Experiment), rollback); err != nil {
+			rollback.Err = err
+		} else {
+			rollback.Persisted = true
+		}
+	}
+	m.rolledBack[assignment.Experiment] = rollback
+	delete(m.events, assignment.Experiment)
+	monitoring.ExperimentRollbacks.WithLabelValues(assignment.Experiment).Inc()
+	return &rollback
+}
+
+// notify tells the rollback handler about a rollback
+func (m *Manager) notify(rollback *Rollback) {
+	if rollback == nil {
+		return
+	}
+	m.mu.Lock()
+	handler := m.onRollback
+	m.mu.Unlock()
+	if handler != nil {
+		handler(*rollback)
+	}
+}
+
+// experiment returns the configuration of an enabled experiment
+func (m *Manager) experiment(name string) config.ExperimentConfig {
+	for _, experiment := range m.experiments {
+		if experiment.Name == name {
+			return experiment
+		}
+	}
+	return config.ExperimentConfig{}
+}
+
+// Results returns the feedback of every arm since startup, by experiment and arm
+func (m *Manager) Results() []Result {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	results := make([]Result, 0, len(m.results))
+	for _, result := range m.results {
+		results = append(results, *result)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Experiment != results[j].Experiment {
+			return results[i].Experiment < results[j].Experiment
+		}
+		return results[i].Arm < results[j].Arm
+	})
+	return results
+}
+
+// lookup returns the arm a conversation was assigned, whether or not its variant
+// was rolled back, without counting it
+func (m *Manager) lookup(channelID, conversationID string) (Assignment, bool) {
+	if m == nil {
+		return Assignment{}, false
+	}
+	for _, experiment := range m.experiments {
+		if len(experiment.Channels) > 0 && !slices.Contains(experiment.Channels, channelID) {
+			continue
+		}
+		if bucket(experiment.Name, channelID, conversationID) >= experiment.Percent {
+			return Assignment{Experiment: experiment.Name, Arm: ArmControl}, true
+		}
+		return Assignment{
+			Experiment:   experiment.Name,
+			Arm:          ArmVariant,
+			CustomPrompt: experiment.Variant.CustomPrompt,
+			Provider:     experiment.Variant.Provider,
+			Model:        experiment.Variant.Model,
+		}, true
+	}
+	return Assignment{}, false
+}
+
+// bucket maps a conversation to 0-99. The experiment name is part of the hash, so
+// the variants of different experiments get different conversations.
+func bucket(experiment, channelID, conversationID string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(experiment + "\x00" + channelID + "\x00" + conversationID))
+	return int(h.Sum32() % 100)
+}
+
+type assignmentKey struct{}
+
+// WithAssignment returns a context that carries the experiment arm of the request
+func WithAssignment(ctx context.Context, assignment Assignment) context.Context {
+	return context.WithValue(ctx, assignmentKey{}, assignment)
+}
+
+// FromContext returns the experiment arm of the request, if any
+func FromContext(ctx context.Context) (Assignment, bool) {
+	assignment, ok := ctx.Value(assignmentKey{}).(Assignment)
+	return assignment, ok
+}
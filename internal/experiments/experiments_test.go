@@ -0,0 +1,233 @@
+package experiments
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+func TestNewManagerWithoutExperiments(t *testing.T) {
+	m, err := NewManager(nil)
+	require.NoError(t, err)
+	assert.Nil(t, m)
+	m, err = NewManager([]config.ExperimentConfig{{Name: "off", Percent: 50, Disabled: true}})
+	require.NoError(t, err)
+	assert.Nil(t, m)
+
+	// A nil manager assigns nothing
+	_, ok := m.Assign("C1", "100.1")
+	assert.False(t, ok)
+	assert.Empty(t, m.Results())
+}
+
+func TestAssignSplitsConversations(t *testing.T) {
+	m, err := NewManager([]config.ExperimentConfig{{
+		Name:    "concise",
+		Percent: 30,
+		Variant: config.ExperimentVariantConfig{CustomPrompt: "Answer in two sentences.", Model: "gpt-4.1"},
+	}})
+	require.NoError(t, err)
+
+	variants := 0
+	for i := 0; i < 1000; i++ {
+		conversation := fmt.Sprintf("%d.1", 1000+i)
+		assignment, ok := m.Assign("C1", conversation)
+		require.True(t, ok)
+		assert.Equal(t, "concise", assignment.Experiment)
+
+		// A conversation keeps its arm
+		again, _ := m.Assign("C1", conversation)
+		assert.Equal(t, assignment, again)
+
+		if assignment.Arm == ArmVariant {
+			variants++
+			assert.Equal(t, "Answer in two sentences.", assignment.CustomPrompt)
+			assert.Equal(t, "gpt-4.1", assignment.Model)
+		} else {
+			assert.Equal(t, Assignment{Experiment: "concise", Arm: ArmControl}, assignment)
+		}
+	}
+	assert.InDelta(t, 300, variants, 50)
+
+	results := m.Results()
+	require.Len(t, results, 2)
+	assert.Equal(t, ArmControl, results[0].Arm)
+	assert.Equal(t, 2000, results[0].Interactions+results[1].Interactions)
+}
+
+func TestAssignHonorsChannelsAndPercentBounds(t *testing.T) {
+	m, err := NewManager([]config.ExperimentConfig{
+		{Name: "ops-only", Percent: 100, Channels: []string{"C-OPS"}, Variant: config.ExperimentVariantConfig{Model: "gpt-4.1"}},
+		{Name: "nobody", Percent: 0, Variant: config.ExperimentVariantConfig{Model: "gpt-4.1"}},
+	})
+	require.NoError(t, err)
+
+	assignment, ok := m.Assign("C-OPS", "100.1")
+	require.True(t, ok)
+	assert.Equal(t, "ops-only", assignment.Experiment)
+	assert.Equal(t, ArmVariant, assignment.Arm)
+
+	// Other channels fall through to the next experiment
+	assignment, ok = m.Assign("C1", "100.1")
+	require.True(t, ok)
+	assert.Equal(t, "nobody", assignment.Experiment)
+	assert.Equal(t, ArmControl, assignment.Arm)
+}
+
+func TestFeedbackIsCountedPerArm(t *testing.T) {
+	m, err := NewManager([]config.ExperimentConfig{{Name: "all", Percent: 100, Variant: config.ExperimentVariantConfig{Model: "gpt-4.1"}}})
+	require.NoError(t, err)
+	m.Assign("C1", "100.1")
+
+	_, counted := m.Feedback("C1", "100.1", "100.2", "U1", true)
+	assert.True(t, counted)
+	_, counted = m.Feedback("C1", "100.1", "100.2", "U1", true)
+	assert.False(t, counted, "the same user's feedback on a message counts once")
+	_, counted = m.Feedback("C1", "100.1", "100.2", "U2", true)
+	assert.True(t, counted)
+	_, counted = m.Feedback("C1", "100.1", "100.3", "U1", false)
+	assert.True(t, counted)
+
+	results := m.Results()
+	assert.Equal(t, Result{Experiment: "all", Arm: ArmVariant, Interactions: 1, Positive: 2, Negative: 1}, results[1])
+	assert.InDelta(t, 2.0/3, results[1].Score(), 0.001)
+	assert.Zero(t, results[0].Score())
+}
+
+// rollbackManager returns a manager sending every conversation to a variant with
+// rollback enabled, and the clock it uses
+func rollbackManager(t *testing.T) (*Manager, *time.Time, *[]Rollback) {
+	t.Helper()
+	experiment := config.ExperimentConfig{
+		Name:     "concise",
+		Percent:  100,
+		Variant:  config.ExperimentVariantConfig{CustomPrompt: "Answer in two sentences."},
+		Rollback: config.ExperimentRollbackConfig{Enabled: true, AdminChannel: "C-ADMIN"},
+	}
+	cfg := &config.Config{Experiments: []config.ExperimentConfig{experiment}}
+	cfg.ApplyDefaults()
+	m, err := NewManager(cfg.Experiments)
+	require.NoError(t, err)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+	var rollbacks []Rollback
+	m.SetRollbackHandler(func(rollback Rollback) { rollbacks = append(rollbacks, rollback) })
+	return m, &now, &rollbacks
+}
+
+func TestRollbackOnNegativeFeedback(t *testing.T) {
+	m, now, rollbacks := rollbackManager(t)
+
+	// Negative feedback outside the window does not count
+	for i := 0; i < 8; i++ {
+		m.Assign("C1", fmt.Sprintf("%d.1", i))
+		m.Feedback("C1", fmt.Sprintf("%d.1", i), fmt.Sprintf("%d.2", i), "U1", false)
+	}
+	*now = now.Add(2 * time.Hour)
+	for i := 0; i < 9; i++ {
+		m.Feedback("C1", fmt.Sprintf("%d.1", i), fmt.Sprintf("%d.3", i), "U1", i%2 == 0)
+	}
+	assert.Empty(t, *rollbacks, "4 negative of 9 reactions is under both the rate and the sample floor")
+	m.Feedback("C1", "1.1", "200.3", "U1", false)
+	assert.Empty(t, *rollbacks, "5 negative of 10 reactions is not over 50%")
+	m.Feedback("C1", "3.1", "200.4", "U1", false)
+	require.Len(t, *rollbacks, 1)
+	rollback := (*rollbacks)[0]
+	assert.Equal(t, "concise", rollback.Experiment)
+	assert.Equal(t, "C-ADMIN", rollback.AdminChannel)
+	assert.Equal(t, "6 of 11 feedback reactions were negative in the last 1h0m0s (limit 50%)", rollback.Reason)
+	assert.Equal(t, []Rollback{rollback}, m.Rollbacks())
+
+	// The variant's conversations are answered by control from now on
+	assignment, ok := m.Assign("C1", "1.1")
+	require.True(t, ok)
+	assert.Equal(t, Assignment{Experiment: "concise", Arm: ArmControl}, assignment)
+	assignment, _ = m.Feedback("C1", "1.1", "200.5", "U1", false)
+	assert.Equal(t, ArmControl, assignment.Arm)
+	assert.Len(t, *rollbacks, 1)
+}
+
+func TestRollbackOnErrors(t *testing.T) {
+	m, _, rollbacks := rollbackManager(t)
+
+	var ctx context.Context
+	for i := 0; i < 10; i++ {
+		assignment, _ := m.Assign("C1", fmt.Sprintf("%d.1", i))
+		ctx = WithAssignment(context.Background(), assignment)
+	}
+	m.Error(ctx)
+	m.Error(ctx)
+	assert.Empty(t, *rollbacks, "2 of 10 failures is not over 20%")
+	m.Error(ctx)
+	require.Len(t, *rollbacks, 1)
+	assert.Equal(t, "3 of 10 prompts failed in the last 1h0m0s (limit 20%)", (*rollbacks)[0].Reason)
+
+	results := m.Results()
+	assert.Equal(t, Result{Experiment: "concise", Arm: ArmVariant, Interactions: 10, Errors: 3}, results[1])
+
+	// Errors outside an experiment and on a nil manager are ignored
+	m.Error(context.Background())
+	var none *Manager
+	none.Error(ctx)
+	assert.Nil(t, none.Rollbacks())
+}
+
+func TestRollbackPersistsUntilTheVariantChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rollback.json")
+	cfg := &config.Config{Experiments: []config.ExperimentConfig{{
+		Name:     "concise",
+		Percent:  100,
+		Variant:  config.ExperimentVariantConfig{CustomPrompt: "Answer in two sentences."},
+		Rollback: config.ExperimentRollbackConfig{Enabled: true, MinSamples: 1, AdminChannel: "C-ADMIN", StorePath: path},
+	}}}
+	cfg.ApplyDefaults()
+	m, err := NewManager(cfg.Experiments)
+	require.NoError(t, err)
+	var rollbacks []Rollback
+	m.SetRollbackHandler(func(rollback Rollback) { rollbacks = append(rollbacks, rollback) })
+	assignment, _ := m.Assign("C1", "1.1")
+	m.Error(WithAssignment(context.Background(), assignment))
+	require.Len(t, rollbacks, 1)
+	assert.True(t, rollbacks[0].Persisted)
+	assert.NoError(t, rollbacks[0].Err)
+
+	// A restart keeps the variant rolled back
+	restarted, err := NewManager(cfg.Experiments)
+	require.NoError(t, err)
+	restarted.SetRollbackHandler(func(rollback Rollback) { rollbacks = append(rollbacks, rollback) })
+	assignment, _ = restarted.Assign("C1", "1.1")
+	assert.Equal(t, ArmControl, assignment.Arm)
+	require.Len(t, restarted.Rollbacks(), 1)
+	assert.Equal(t, rollbacks[0].Reason, restarted.Rollbacks()[0].Reason)
+	assert.Len(t, rollbacks, 1)
+
+	// A changed variant resumes, and the handler is told
+	cfg.Experiments[0].Variant.CustomPrompt = "Answer in three sentences."
+	changed, err := NewManager(cfg.Experiments)
+	require.NoError(t, err)
+	changed.SetRollbackHandler(func(rollback Rollback) { rollbacks = append(rollbacks, rollback) })
+	require.Len(t, rollbacks, 2)
+	assert.True(t, rollbacks[1].Resumed)
+	assert.Equal(t, "C-ADMIN", rollbacks[1].AdminChannel)
+	assignment, _ = changed.Assign("C1", "1.1")
+	assert.Equal(t, ArmVariant, assignment.Arm)
+	assert.Empty(t, changed.Rollbacks())
+	assert.NoFileExists(t, path)
+}
+
+func TestAssignmentContext(t *testing.T) {
+	_, ok := FromContext(context.Background())
+	assert.False(t, ok)
+
+	ctx := WithAssignment(context.Background(), Assignment{Experiment: "all", Arm: ArmVariant})
+	assignment, ok := FromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, ArmVariant, assignment.Arm)
+}
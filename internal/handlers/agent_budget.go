@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/tmc/langchaingo/tools"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/llm"
+	"github.com/tuannvm/slack-mcp-client/internal/monitoring"
+	"github.com/tuannvm/slack-mcp-client/pkg/middleware"
+)
+
+// Limits of an agent budget
+const (
+	BudgetToolCalls = "tool_calls"
+	BudgetTokens    = "tokens"
+	BudgetDuration  = "duration"
+)
+
+// maxBudgetStepOutput bounds each tool result included in the partial progress summary
+const maxBudgetStepOutput = 2000
+
+// BudgetExceededError is the cause of an agent run stopped by its budget
+type BudgetExceededError struct {
+	Limit string // BudgetToolCalls, BudgetTokens or BudgetDuration
+	Value string // The configured limit
+}
+
+func (e *BudgetExceededError) Error() string {
+	return "agent budget exceeded: " + e.describe()
+}
+
+// describe names the limit in a sentence, such as "tool calls limit of 5 reached"
+func (e *BudgetExceededError) describe() string {
+	return fmt.Sprintf("%s limit of %s reached", strings.ReplaceAll(e.Limit, "_", " "), e.Value)
+}
+
+// budgetStep is a tool call the agent made before it was stopped
+type budgetStep struct {
+	tool   string
+	input  string
+	output string
+}
+
+// agentBudget enforces the limits of a single agent interaction by cancelling its
+// context, and records the tool calls so their progress can be summarized
+type agentBudget struct {
+	cfg    config.AgentBudgetConfig
+	cancel context.CancelCauseFunc
+
+	mu        sync.Mutex
+	toolCalls int
+	tokens    int
+	steps     []budgetStep
+}
+
+// newAgentBudget returns the context the agent runs with under the configured
+// limits, and the budget that cancels it. The context is cancelled with a
+// *BudgetExceededError cause when a limit is reached.
+func newAgentBudget(ctx context.Context, cfg config.AgentBudgetConfig) (context.Context, *agentBudget, context.CancelFunc) {
+	ctx, cancelCause := context.WithCancelCause(ctx)
+	budget := &agentBudget{cfg: cfg, cancel: cancelCause}
+	cancel := func() { cancelCause(context.Canceled) }
+	if d := cfg.GetMaxDuration(); d > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeoutCause(ctx, d, &BudgetExceededError{Limit: BudgetDuration, Value: d.String()})
+		cancel = func() {
+			cancelTimeout()
+			cancelCause(context.Canceled)
+		}
+	}
+	if cfg.MaxTokens > 0 {
+		ctx = llm.ContextWithUsage(ctx, budget.addTokens)
+	}
+	return ctx, budget, cancel
+}
+
+// addTokens counts the tokens of a model call and stops the agent once they
+// exceed the limit
+func (b *agentBudget) addTokens(tokens int) {
+	b.mu.Lock()
+	b.tokens += tokens
+	exceeded := b.tokens > b.cfg.MaxTokens
+	b.mu.Unlock()
+	if exceeded {
+		b.cancel(&BudgetExceededError{Limit: BudgetTokens, Value: fmt.Sprintf("%d", b.cfg.MaxTokens)})
+	}
+}
+
+// startToolCall counts a tool call, or stops the agent when the limit allows no more
+func (b *agentBudget) startToolCall() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.cfg.MaxToolCalls > 0 && b.toolCalls >= b.cfg.MaxToolCalls {
+		err := &BudgetExceededError{Limit: BudgetToolCalls, Value: fmt.Sprintf("%d", b.cfg.MaxToolCalls)}
+		b.cancel(err)
+		return err
+	}
+	b.toolCalls++
+	return nil
+}
+
+// record keeps a finished tool call for the partial progress summary
+func (b *agentBudget) record(tool, input, output string) {
+	if len(output) > maxBudgetStepOutput {
+		output = strings.ToValidUTF8(output[:maxBudgetStepOutput], "") + "..."
+	}
+	b.mu.Lock()
+	b.steps = append(b.steps, budgetStep{tool: tool, input: input, output: output})
+	b.mu.Unlock()
+}
+
+// exceeded returns the limit that stopped the agent running with ctx, if any
+func exceeded(ctx context.Context) *BudgetExceededError {
+	var budgetErr *BudgetExceededError
+	if errors.As(context.Cause(ctx), &budgetErr) {
+		return budgetErr
+	}
+	return nil
+}
+
+// progress describes the tool calls made so far for the summary prompt
+func (b *agentBudget) progress() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.steps) == 0 {
+		return "No tool calls completed."
+	}
+	var sb strings.Builder
+	for i, step := range b.steps {
+		fmt.Fprintf(&sb, "%d. %s %s\nResult: %s\n", i+1, step.tool, step.input, step.output)
+	}
+	return sb.String()
+}
+
+// budgetTool counts the agent's calls of a tool against its budget and records them
+type budgetTool struct {
+	tools.Tool
+	budget *agentBudget
+}
+
+func (t budgetTool) Call(ctx context.Context, input string) (string, error) {
+	if err := t.budget.startToolCall(); err != nil {
+		return "", err
+	}
+	output, err := t.Tool.Call(ctx, input)
+	if err != nil {
+		t.budget.record(t.Name(), input, "error: "+err.Error())
+	} else {
+		t.budget.record(t.Name(), input, output)
+	}
+	return output, err
+}
+
+// Schema implements llm.SchemaTool
+func (t budgetTool) Schema() map[string]interface{} {
+	return llm.ToolSchema(t.Tool)
+}
+
+// summarizeProgress asks the model for the partial answer of an agent run stopped
+// by its budget, from the tool results gathered before it stopped
+func (b *LLMMCPBridge) summarizeProgress(ctx context.Context, providerName string, call *middleware.LLMCall, budget *agentBudget, cause *BudgetExceededError) (string, error) {
+	monitoring.AgentBudgetExceeded.WithLabelValues(cause.Limit).Inc()
+	b.logger.WarnKV("Agent stopped by its budget", "limit", cause.Limit, "value", cause.Value, "provider", providerName)
+
+	summaryCall := *call
+	summaryCall.Prompt = fmt.Sprintf(`You were answering the request below with tools, but had to stop: the %s.
+
+Request:
+%s
+
+Tool calls made so far:
+%s
+Answer the request as well as you can from these results alone. Say clearly that the answer is partial, and list what is left to do.`,
+		cause.describe(), call.Prompt, budget.progress())
+
+	choice, err := b.llmRegistry.GenerateChatCompletion(ctx, providerName, llmMessages(&summaryCall), llm.ProviderOptions{Model: call.Model})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s\n\n_Stopped early: the %s._", choice.Content, cause.describe()), nil
+}
+
+// budgetTools wraps the agent's tools so their calls count against the budget
+func budgetTools(toolArr []tools.Tool, budget *agentBudget) []tools.Tool {
+	wrapped := make([]tools.Tool, len(toolArr))
+	for i, tool := range toolArr {
+		wrapped[i] = budgetTool{Tool: tool, budget: budget}
+	}
+	return wrapped
+}
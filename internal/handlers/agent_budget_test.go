@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/tools"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+type echoTool struct{}
+
+func (echoTool) Name() string        { return "echo" }
+func (echoTool) Description() string { return "Echo the input" }
+func (echoTool) Call(_ context.Context, input string) (string, error) {
+	return "echo " + input, nil
+}
+
+func TestAgentBudgetToolCalls(t *testing.T) {
+	ctx, budget, cancel := newAgentBudget(context.Background(), config.AgentBudgetConfig{MaxToolCalls: 2})
+	defer cancel()
+	tool := budgetTools([]tools.Tool{echoTool{}}, budget)[0]
+
+	for _, input := range []string{`{"n":1}`, `{"n":2}`} {
+		_, err := tool.Call(ctx, input)
+		require.NoError(t, err)
+	}
+	assert.Nil(t, exceeded(ctx))
+
+	// The call past the limit is refused and stops the agent
+	_, err := tool.Call(ctx, `{"n":3}`)
+	var budgetErr *BudgetExceededError
+	require.True(t, errors.As(err, &budgetErr))
+	assert.Equal(t, BudgetToolCalls, budgetErr.Limit)
+	require.Error(t, ctx.Err())
+	assert.Equal(t, budgetErr, exceeded(ctx))
+	assert.Equal(t, "1. echo {\"n\":1}\nResult: echo {\"n\":1}\n2. echo {\"n\":2}\nResult: echo {\"n\":2}\n", budget.progress())
+}
+
+func TestAgentBudgetTokensAndDuration(t *testing.T) {
+	ctx, budget, cancel := newAgentBudget(context.Background(), config.AgentBudgetConfig{MaxTokens: 100})
+	defer cancel()
+	budget.addTokens(60)
+	assert.NoError(t, ctx.Err())
+	budget.addTokens(60)
+	require.NotNil(t, exceeded(ctx))
+	assert.Equal(t, BudgetTokens, exceeded(ctx).Limit)
+
+	ctx, _, cancel = newAgentBudget(context.Background(), config.AgentBudgetConfig{MaxDuration: "10ms"})
+	defer cancel()
+	<-ctx.Done()
+	require.NotNil(t, exceeded(ctx))
+	assert.Equal(t, "agent budget exceeded: duration limit of 10ms reached", exceeded(ctx).Error())
+
+	// Cancelling the interaction itself is not a budget stop
+	ctx, _, cancel = newAgentBudget(context.Background(), config.AgentBudgetConfig{MaxDuration: "1m"})
+	cancel()
+	assert.Nil(t, exceeded(ctx))
+}
+
+func TestAgentBudgetTruncatesRecordedOutput(t *testing.T) {
+	_, budget, cancel := newAgentBudget(context.Background(), config.AgentBudgetConfig{MaxDuration: time.Minute.String()})
+	defer cancel()
+	budget.record("search", "{}", strings.Repeat("x", maxBudgetStepOutput+10))
+	assert.Contains(t, budget.progress(), strings.Repeat("x", maxBudgetStepOutput)+"...\n")
+}
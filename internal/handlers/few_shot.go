@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/hooks"
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+)
+
+// maxExampleRequestLength bounds how much of a user request is kept in a learned example
+const maxExampleRequestLength = 200
+
+// exampleStore keeps the most recent successful tool calls per channel and tool
+// so they can be shown to the LLM as few-shot examples. Examples hold the user's
+// request, so they are only shown in the channel they were learned in.
+type exampleStore struct {
+	mu       sync.Mutex
+	examples map[string]map[string][]config.ToolCallExample // channel ID -> tool name -> most recent first
+}
+
+// record remembers a successful call in a channel, keeping at most limit distinct
+// examples per tool
+func (s *exampleStore) record(channelID, toolName, request string, args map[string]interface{}, limit int) {
+	argsJSON, err := json.Marshal(args)
+	if err != nil {
+		return
+	}
+	example := config.ToolCallExample{Request: logging.TruncateForLog(strings.TrimSpace(request), maxExampleRequestLength), Args: args}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.examples == nil {
+		s.examples = make(map[string]map[string][]config.ToolCallExample)
+	}
+	channel := s.examples[channelID]
+	if channel == nil {
+		channel = make(map[string][]config.ToolCallExample)
+		s.examples[channelID] = channel
+	}
+	examples := []config.ToolCallExample{example}
+	for _, existing := range channel[toolName] {
+		if len(examples) >= limit {
+			break
+		}
+		existingJSON, _ := json.Marshal(existing.Args)
+		if string(existingJSON) == string(argsJSON) {
+			continue
+		}
+		examples = append(examples, existing)
+	}
+	channel[toolName] = examples
+}
+
+// get returns the examples for a tool learned in a channel
+func (s *exampleStore) get(channelID, toolName string) []config.ToolCallExample {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.examples[channelID][toolName]
+}
+
+// learnExample records a successful tool call as an example for the request's
+// channel. Calls made outside a conversation are not learned.
+func (b *LLMMCPBridge) learnExample(ctx context.Context, toolName, request string, args map[string]interface{}) {
+	if !b.cfg.LLM.FewShot.Learn {
+		return
+	}
+	if channelID, _, ok := hooks.ConversationFromContext(ctx); ok && channelID != "" {
+		b.learnedExamples.record(channelID, toolName, request, args, b.cfg.LLM.FewShot.MaxPerTool)
+	}
+}
+
+// toolExamples returns the configured examples for a tool followed by the ones
+// learned in the request's channel
+func (b *LLMMCPBridge) toolExamples(ctx context.Context, name string, tool mcp.ToolInfo) []config.ToolCallExample {
+	var examples []config.ToolCallExample
+	if serverConf, ok := b.cfg.MCPServers[tool.ServerName]; ok {
+		examples = append(examples, serverConf.Tools.Overrides[tool.RemoteName].FewShot...)
+	}
+	if b.cfg.LLM.FewShot.Learn {
+		if channelID, _, ok := hooks.ConversationFromContext(ctx); ok && channelID != "" {
+			examples = append(examples, b.learnedExamples.get(channelID, name)...)
+		}
+	}
+	return examples
+}
+
+// writeToolExamples renders a tool's few-shot examples in the tool call format
+func writeToolExamples(builder *strings.Builder, name string, examples []config.ToolCallExample) {
+	if len(examples) == 0 {
+		return
+	}
+	builder.WriteString("  Example calls:\n")
+	for _, example := range examples {
+		call, err := json.Marshal(ToolCall{Tool: name, Args: example.Args})
+		if err != nil {
+			continue
+		}
+		if example.Request != "" {
+			builder.WriteString(fmt.Sprintf("    User: %q\n", example.Request))
+		}
+		builder.WriteString(fmt.Sprintf("    %s\n", string(call)))
+	}
+}
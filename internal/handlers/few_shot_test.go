@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/hooks"
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+)
+
+func TestExampleStoreRecord(t *testing.T) {
+	var store exampleStore
+
+	store.record("C1", "search", "find bugs", map[string]interface{}{"q": "bug"}, 2)
+	store.record("C1", "search", "find bugs again", map[string]interface{}{"q": "bug"}, 2)
+	store.record("C1", "search", "find features", map[string]interface{}{"q": "feature"}, 2)
+	store.record("C1", "search", "find docs", map[string]interface{}{"q": "docs"}, 2)
+
+	examples := store.get("C1", "search")
+	if assert.Len(t, examples, 2) {
+		assert.Equal(t, "find docs", examples[0].Request)
+		assert.Equal(t, "find features", examples[1].Request)
+	}
+	assert.Empty(t, store.get("C1", "other"))
+	assert.Empty(t, store.get("C2", "search"), "examples stay in their channel")
+}
+
+func TestGenerateToolPromptIncludesExamples(t *testing.T) {
+	cfg := &config.Config{
+		LLM: config.LLMConfig{FewShot: config.FewShotConfig{Learn: true, MaxPerTool: 2}},
+		MCPServers: map[string]config.MCPServerConfig{
+			"github": {Tools: config.MCPToolsConfig{Overrides: map[string]config.MCPToolOverride{
+				"search": {FewShot: []config.ToolCallExample{{Request: "open bugs", Args: map[string]interface{}{"q": "is:open label:bug"}}}},
+			}}},
+		},
+	}
+	tools := map[string]mcp.ToolInfo{
+		"github_search": {ServerName: "github", ToolName: "github_search", RemoteName: "search", ToolDescription: "Search"},
+	}
+	bridge := NewLLMMCPBridge(map[string]mcp.MCPClientInterface{}, log.New(os.Stderr, "", 0), tools, nil, cfg)
+	bridge.learnedExamples.record("C1", "github_search", "my PRs", map[string]interface{}{"q": "is:pr author:@me"}, 2)
+
+	prompt := bridge.generateToolPrompt(hooks.ContextWithConversation(context.Background(), "C1", ""))
+	assert.Contains(t, prompt, "Example calls:")
+	assert.Contains(t, prompt, `User: "open bugs"`)
+	assert.Contains(t, prompt, `{"tool":"github_search","args":{"q":"is:open label:bug"}}`)
+	assert.Contains(t, prompt, `{"tool":"github_search","args":{"q":"is:pr author:@me"}}`)
+
+	// Another channel sees the configured examples only
+	prompt = bridge.generateToolPrompt(hooks.ContextWithConversation(context.Background(), "C2", ""))
+	assert.Contains(t, prompt, `User: "open bugs"`)
+	assert.NotContains(t, prompt, "my PRs")
+}
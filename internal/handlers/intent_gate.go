@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/intent"
+	"github.com/tuannvm/slack-mcp-client/internal/llm"
+	"github.com/tuannvm/slack-mcp-client/internal/monitoring"
+)
+
+// ragSearchToolName is the knowledge base tool offered for knowledge base questions
+const ragSearchToolName = "rag_search"
+
+// SetIntentClassifier enables the intent gate, which offers no tools for plain chat
+// and only the knowledge base search for knowledge base questions
+func (b *LLMMCPBridge) SetIntentClassifier(classifier *intent.Classifier) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.intentClassifier = classifier
+}
+
+// IntentCompletion sends a prompt of the llm intent classifier to its model
+func (b *LLMMCPBridge) IntentCompletion(ctx context.Context, prompt string) (string, error) {
+	if b.llmRegistry == nil {
+		return "", fmt.Errorf("no LLM provider registry")
+	}
+	intentCfg := b.cfg.LLM.Intent
+	timeout, _ := time.ParseDuration(intentCfg.Timeout) // Validated with the config
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	response, err := b.llmRegistry.GenerateCompletion(ctx, intentCfg.Provider, prompt,
+		llm.ProviderOptions{Model: intentCfg.Model, Temperature: 0, MaxTokens: 5})
+	if err != nil {
+		return "", err
+	}
+	return response.Content, nil
+}
+
+// ClassifyIntent decides whether the user's message is plain chat, a knowledge base
+// question or a tool request, and returns a context that limits the tool prompt and
+// native tool list to what it needs. Call it after SelectTools, so it only narrows
+// the selected tools, and before RouteRequest. When the gate is disabled every tool
+// stays available.
+func (b *LLMMCPBridge) ClassifyIntent(ctx context.Context, query, channelID string) context.Context {
+	b.mu.RLock()
+	classifier := b.intentClassifier
+	b.mu.RUnlock()
+	if classifier == nil {
+		return ctx
+	}
+
+	availableTools := b.toolsFor(ctx)
+	toolNames := make([]string, 0, len(availableTools))
+	for name := range availableTools {
+		toolNames = append(toolNames, name)
+	}
+	_, ragAvailable := availableTools[ragSearchToolName]
+	decision := classifier.Classify(ctx, query, toolNames, ragAvailable)
+	monitoring.IntentDecisions.WithLabelValues(decision.Intent, decision.Source).Inc()
+	b.logger.DebugKV("Classified request intent", "channel", channelID, "intent", decision.Intent, "source", decision.Source)
+
+	switch decision.Intent {
+	case config.IntentChat:
+		return context.WithValue(ctx, selectedToolsContextKey{}, []string{})
+	case config.IntentRAG:
+		return context.WithValue(ctx, selectedToolsContextKey{}, []string{ragSearchToolName})
+	default:
+		return ctx
+	}
+}
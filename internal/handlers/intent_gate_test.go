@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/intent"
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+)
+
+func TestClassifyIntent(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.LLM.Intent = config.LLMIntentConfig{Enabled: true, RAGKeywords: []string{"runbook"}}
+	cfg.ApplyDefaults()
+	tools := map[string]mcp.ToolInfo{
+		"get_pods":   {ToolName: "get_pods", ToolDescription: "List pods"},
+		"rag_search": {ToolName: "rag_search", ToolDescription: "Search the knowledge base", ServerName: RAGServerName},
+		"rag_ingest": {ToolName: "rag_ingest", ToolDescription: "Add a file to the knowledge base", ServerName: RAGServerName},
+	}
+	bridge := NewLLMMCPBridge(map[string]mcp.MCPClientInterface{}, log.New(os.Stderr, "", 0), tools, nil, cfg)
+
+	// Without a classifier every tool is offered
+	ctx := bridge.ClassifyIntent(context.Background(), "thanks!", "C123")
+	assert.Len(t, bridge.toolsFor(ctx), 3)
+
+	bridge.SetIntentClassifier(intent.NewClassifier(cfg.LLM.Intent, nil))
+	ctx = bridge.ClassifyIntent(context.Background(), "thanks!", "C123")
+	assert.Empty(t, bridge.toolsFor(ctx))
+	assert.Empty(t, bridge.generateToolPrompt(ctx), "Chat gets no tool prompt")
+
+	ctx = bridge.ClassifyIntent(context.Background(), "Where is the failover runbook?", "C123")
+	assert.Equal(t, []string{"rag_search"}, toolNames(bridge.toolsFor(ctx)))
+
+	ctx = bridge.ClassifyIntent(context.Background(), "List the pods in prod", "C123")
+	assert.Len(t, bridge.toolsFor(ctx), 3)
+
+	// Knowledge base questions only get the knowledge base when it was selected
+	selected := context.WithValue(context.Background(), selectedToolsContextKey{}, []string{"get_pods"})
+	ctx = bridge.ClassifyIntent(selected, "Where is the failover runbook?", "C123")
+	assert.Equal(t, []string{"get_pods"}, toolNames(bridge.toolsFor(ctx)))
+}
+
+// toolNames returns the names of the tools
+func toolNames(tools map[string]mcp.ToolInfo) []string {
+	names := make([]string, 0, len(tools))
+	for name := range tools {
+		names = append(names, name)
+	}
+	return names
+}
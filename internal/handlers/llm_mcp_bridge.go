@@ -5,19 +5,27 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/tmc/langchaingo/callbacks"
 	"github.com/tmc/langchaingo/llms"
 	"github.com/tmc/langchaingo/tools"
+	"github.com/tuannvm/slack-mcp-client/internal/affinity"
+	"github.com/tuannvm/slack-mcp-client/internal/intent"
 	"github.com/tuannvm/slack-mcp-client/internal/llm"
 	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+	"github.com/tuannvm/slack-mcp-client/internal/memory"
+	"github.com/tuannvm/slack-mcp-client/internal/routing"
+	"github.com/tuannvm/slack-mcp-client/internal/toolselect"
+	"github.com/tuannvm/slack-mcp-client/pkg/middleware"
 
 	customErrors "github.com/tuannvm/slack-mcp-client/internal/common/errors"
 	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
@@ -33,11 +41,65 @@ type LLMMCPBridge struct {
 	availableTools map[string]mcp.ToolInfo // Map of tool names to info about the tool
 	llmRegistry    *llm.ProviderRegistry   // LLM provider registry
 	cfg            *config.Config          // Configuration
+
+	learnedExamples  exampleStore         // Successful tool calls used as few-shot examples
+	toolSelector     *toolselect.Selector // Optional pre-filter of the tools sent to the LLM
+	router           *routing.Router      // Optional choice between a cheap and a powerful model
+	intentClassifier *intent.Classifier   // Optional gate that offers fewer tools for chat and knowledge base questions
+	middlewares      *middleware.Chain    // Optional hooks around tool calls and LLM calls
+	memory           *memory.Store        // Optional long-term facts about users and teams
+	pinned           *routing.Decision    // Optional model answering every request, set with PinModel
+	affinity         *affinity.Store      // Optional model of each thread, which later turns of the thread keep
+
+	// mu guards mcpClients, availableTools, toolSelector, router, intentClassifier, middlewares, memory, pinned and affinity; the maps are replaced (never modified)
+	// when a server finishes initializing after the bridge was created
+	mu sync.RWMutex
+}
+
+// getAvailableTools returns the current tool map
+func (b *LLMMCPBridge) getAvailableTools() map[string]mcp.ToolInfo {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.availableTools
+}
+
+// getClients returns the current client map
+func (b *LLMMCPBridge) getClients() map[string]mcp.MCPClientInterface {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.mcpClients
 }
 
-// generateToolPrompt generates the prompt string for available tools
-func (b *LLMMCPBridge) generateToolPrompt() string {
+// RegisterServer adds a server that became available after the bridge was created,
+// e.g. a slow MCP server that finished initializing in the background. availableTools
+// is the complete tool map after tool name collisions have been resolved.
+func (b *LLMMCPBridge) RegisterServer(serverName string, client mcp.MCPClientInterface, availableTools map[string]mcp.ToolInfo) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	clients := make(map[string]mcp.MCPClientInterface, len(b.mcpClients)+1)
+	for name, c := range b.mcpClients {
+		clients[name] = c
+	}
+	clients[serverName] = client
+
+	connectedTools := make(map[string]mcp.ToolInfo, len(availableTools))
+	for name, tool := range availableTools {
+		if c, exists := clients[tool.ServerName]; exists {
+			tool.Client = c
+		}
+		connectedTools[name] = b.enrichTool(tool)
+	}
+
+	b.mcpClients = clients
+	b.availableTools = connectedTools
+	b.logger.InfoKV("Registered MCP server", "server", serverName, "total_tools", len(connectedTools))
+}
+
+// generateToolPrompt generates the prompt string for the tools available to the request
+func (b *LLMMCPBridge) generateToolPrompt(ctx context.Context) string {
 	var promptBuilder strings.Builder
+	availableTools := b.toolsFor(ctx)
 
 	// Add custom prompt first if provided
 	if b.cfg.LLM.CustomPrompt != "" {
@@ -50,7 +112,7 @@ func (b *LLMMCPBridge) generateToolPrompt() string {
 		}
 	}
 
-	if len(b.availableTools) == 0 {
+	if len(availableTools) == 0 {
 		// If no tools but we have custom prompt, return custom prompt only
 		if b.cfg.LLM.CustomPrompt != "" {
 			return b.cfg.LLM.CustomPrompt
@@ -61,7 +123,7 @@ func (b *LLMMCPBridge) generateToolPrompt() string {
 	promptBuilder.WriteString("You have access to the following tools. Analyze the user's request to determine if a tool is needed.\n\n")
 
 	// Debug: log the available tools
-	b.logger.DebugKV("Generating tool prompt", "tool_count", len(b.availableTools))
+	b.logger.DebugKV("Generating tool prompt", "tool_count", len(availableTools))
 
 	// Clear instructions on how to format the JSON response
 	promptBuilder.WriteString("TOOL USAGE INSTRUCTIONS:\n")
@@ -73,7 +135,7 @@ func (b *LLMMCPBridge) generateToolPrompt() string {
 
 	promptBuilder.WriteString("Available Tools:\n")
 
-	for name, toolInfo := range b.availableTools {
+	for name, toolInfo := range availableTools {
 		promptBuilder.WriteString(fmt.Sprintf("\nTool Name: %s\n", name))
 		promptBuilder.WriteString(fmt.Sprintf("  Description: %s\n", toolInfo.ToolDescription))
 
@@ -87,6 +149,7 @@ func (b *LLMMCPBridge) generateToolPrompt() string {
 		} else {
 			promptBuilder.WriteString(fmt.Sprintf("  Input Schema (JSON):\n  %s\n", string(schemaBytes)))
 		}
+		writeToolExamples(&promptBuilder, name, b.toolExamples(ctx, name, toolInfo))
 	}
 
 	// Add example formats for clarity
@@ -140,14 +203,18 @@ func NewLLMMCPBridgeWithLogLevel(mcpClients map[string]mcp.MCPClientInterface, s
 		connectedTools[toolName] = connectedTool
 	}
 
-	return &LLMMCPBridge{
-		mcpClients:     mcpClients,
-		logger:         structLogger,
-		stdLogger:      stdLogger,
-		availableTools: connectedTools,
-		llmRegistry:    llmRegistry,
-		cfg:            cfg,
+	bridge := &LLMMCPBridge{
+		mcpClients:  mcpClients,
+		logger:      structLogger,
+		stdLogger:   stdLogger,
+		llmRegistry: llmRegistry,
+		cfg:         cfg,
 	}
+	for toolName, tool := range connectedTools {
+		connectedTools[toolName] = bridge.enrichTool(tool)
+	}
+	bridge.availableTools = connectedTools
+	return bridge
 }
 
 // getClientNames is a helper function to get client names for debugging
@@ -226,27 +293,36 @@ func NewLLMMCPBridgeFromClientsWithLogLevel(mcpClients interface{}, stdLogger *l
 
 // ProcessLLMResponse processes an LLM response, expecting a specific JSON tool call format.
 // It no longer uses natural language detection.
-func (b *LLMMCPBridge) ProcessLLMResponse(ctx context.Context, llmResponse *llms.ContentChoice, _ string, extraArgs map[string]interface{}) (string, error) {
-	var toolCall *ToolCall
-	var err error
-	funcCall := llmResponse.FuncCall
-	// Check for a tool call in JSON format
-	if len(llmResponse.ToolCalls) > 0 {
-		funcCall = llmResponse.ToolCalls[0].FunctionCall
+func (b *LLMMCPBridge) ProcessLLMResponse(ctx context.Context, llmResponse *llms.ContentChoice, userPrompt string, extraArgs map[string]interface{}) (string, error) {
+	// Prefer the provider's structured tool call, then look for one in the text
+	toolCall, err := b.nativeToolCall(llmResponse)
+	if err != nil {
+		return "", err
 	}
-
-	if funcCall != nil {
-		toolCall, err = b.getToolCall(funcCall)
-		if err != nil {
-			return "", err
-		}
-	} else {
+	if toolCall == nil {
 		toolCall = b.detectSpecificJSONToolCall(llmResponse.Content)
 	}
 
 	if toolCall != nil {
+		// Keep the LLM's own arguments (before extra arguments are added) for few-shot learning
+		llmArgs := make(map[string]interface{}, len(toolCall.Args))
+		for k, v := range toolCall.Args {
+			llmArgs[k] = v
+		}
+
 		// Execute the tool call
 		result, err := b.executeToolCall(ctx, toolCall, extraArgs)
+		var timeoutErr *mcp.ToolTimeoutError
+		if errors.As(err, &timeoutErr) {
+			// A timed-out call is reported to the user rather than handed to the LLM
+			b.logger.WarnKV("Tool call timed out", "tool", toolCall.Tool, "timeout", timeoutErr.Timeout)
+			return "", err
+		}
+		if mcp.IsServerUnavailable(err) {
+			// So is a call whose server cannot be reached, which the LLM cannot work around
+			b.logger.WarnKV("Tool server unavailable", "tool", toolCall.Tool, "error", err)
+			return "", err
+		}
 		if err != nil {
 			// Check if it's already a domain error
 			var errorMessage string
@@ -267,6 +343,7 @@ func (b *LLMMCPBridge) ProcessLLMResponse(ctx context.Context, llmResponse *llms
 
 			return errorMessage, nil
 		}
+		b.learnExample(ctx, toolCall.Tool, userPrompt, llmArgs)
 		return result, nil
 	}
 
@@ -280,6 +357,15 @@ type ToolCall struct {
 	Args map[string]interface{} `json:"args"`
 }
 
+// DetectToolCall returns the tool call in an LLM response, as ProcessLLMResponse
+// finds it, or nil when the response does not call a tool
+func (b *LLMMCPBridge) DetectToolCall(llmResponse *llms.ContentChoice) *ToolCall {
+	if toolCall, err := b.nativeToolCall(llmResponse); err == nil && toolCall != nil {
+		return toolCall
+	}
+	return b.detectSpecificJSONToolCall(llmResponse.Content)
+}
+
 // detectSpecificJSONToolCall attempts to find and parse the *specific* JSON tool call structure.
 // It validates the tool name against available tools.
 func (b *LLMMCPBridge) detectSpecificJSONToolCall(response string) *ToolCall {
@@ -294,6 +380,10 @@ func (b *LLMMCPBridge) detectSpecificJSONToolCall(response string) *ToolCall {
 		return toolCall
 	}
 
+	if toolCall := b.tryNativeFormatParsing(response); toolCall != nil {
+		return toolCall
+	}
+
 	if toolCall := b.tryRegexJSONExtraction(response); toolCall != nil {
 		return toolCall
 	}
@@ -302,18 +392,6 @@ func (b *LLMMCPBridge) detectSpecificJSONToolCall(response string) *ToolCall {
 	return nil
 }
 
-func (b *LLMMCPBridge) getToolCall(funcCall *llms.FunctionCall) (*ToolCall, error) {
-	var args map[string]interface{}
-	err := json.Unmarshal([]byte(funcCall.Arguments), &args)
-	if err != nil {
-		return nil, customErrors.NewMCPError("invalid_json_args", fmt.Sprintf("Args not valid json for call '%s'", funcCall.Name))
-	}
-	return &ToolCall{
-		Tool: funcCall.Name,
-		Args: args,
-	}, nil
-}
-
 // tryDirectJSONParsing attempts direct JSON parsing of the entire response
 func (b *LLMMCPBridge) tryDirectJSONParsing(response string) *ToolCall {
 	b.logger.DebugKV("Attempting direct JSON parsing")
@@ -386,7 +464,7 @@ func (b *LLMMCPBridge) constructToolCall(toolName string, argsJSON string) *Tool
 			Args: args,
 		}
 
-		if _, exists := b.availableTools[toolCall.Tool]; exists {
+		if _, exists := b.getAvailableTools()[toolCall.Tool]; exists {
 			b.logger.DebugKV("Manual JSON construction successful", "tool", toolCall.Tool)
 			return toolCall
 		}
@@ -404,7 +482,7 @@ func (b *LLMMCPBridge) constructToolCall(toolName string, argsJSON string) *Tool
 			Args: simpleArgs,
 		}
 
-		if _, exists := b.availableTools[toolCall.Tool]; exists {
+		if _, exists := b.getAvailableTools()[toolCall.Tool]; exists {
 			b.logger.DebugKV("Simplified key-value extraction successful", "tool", toolCall.Tool)
 			return toolCall
 		}
@@ -416,7 +494,7 @@ func (b *LLMMCPBridge) constructToolCall(toolName string, argsJSON string) *Tool
 // isValidToolCall validates if a tool call has the required fields and refers to an available tool
 func (b *LLMMCPBridge) isValidToolCall(toolCall ToolCall) bool {
 	if toolCall.Tool != "" && toolCall.Args != nil {
-		if _, exists := b.availableTools[toolCall.Tool]; exists {
+		if _, exists := b.getAvailableTools()[toolCall.Tool]; exists {
 			return true
 		}
 
@@ -427,8 +505,8 @@ func (b *LLMMCPBridge) isValidToolCall(toolCall ToolCall) bool {
 
 // getClientForTool returns the appropriate client for a given tool (using the new map)
 func (b *LLMMCPBridge) getClientForTool(toolName string) mcp.MCPClientInterface {
-	if toolInfo, exists := b.availableTools[toolName]; exists {
-		if client, clientExists := b.mcpClients[toolInfo.ServerName]; clientExists {
+	if toolInfo, exists := b.getAvailableTools()[toolName]; exists {
+		if client, clientExists := b.getClients()[toolInfo.ServerName]; clientExists {
 			return client
 		}
 
@@ -439,6 +517,23 @@ func (b *LLMMCPBridge) getClientForTool(toolName string) mcp.MCPClientInterface
 	return nil // Return nil, executeToolCall should handle this
 }
 
+// Tools returns the tools available to the LLM, keyed by name. The map is shared
+// and must not be modified.
+func (b *LLMMCPBridge) Tools() map[string]mcp.ToolInfo {
+	return b.getAvailableTools()
+}
+
+// HasTool reports whether a tool is available to the LLM
+func (b *LLMMCPBridge) HasTool(toolName string) bool {
+	_, exists := b.getAvailableTools()[toolName]
+	return exists
+}
+
+// CallTool calls a tool through the middlewares, as when the LLM calls it
+func (b *LLMMCPBridge) CallTool(ctx context.Context, toolName string, args map[string]interface{}) (string, error) {
+	return b.executeToolCall(ctx, &ToolCall{Tool: toolName, Args: args}, nil)
+}
+
 // executeToolCall executes a detected tool call (using the new ToolCall struct)
 func (b *LLMMCPBridge) executeToolCall(ctx context.Context, toolCall *ToolCall, extraArgs map[string]interface{}) (string, error) {
 	for k, v := range extraArgs {
@@ -454,21 +549,32 @@ func (b *LLMMCPBridge) executeToolCall(ctx context.Context, toolCall *ToolCall,
 	client := b.getClientForTool(toolCall.Tool)
 	if client == nil {
 		b.logger.ErrorKV("No MCP client available", "tool", toolCall.Tool)
-		return "", customErrors.NewMCPError("client_not_found", fmt.Sprintf("No MCP client available for tool '%s'", toolCall.Tool))
+		return "", customErrors.NewMCPError("client_not_found", fmt.Sprintf("No MCP client available for tool '%s'", toolCall.Tool)).
+			WithData("tool_name", toolCall.Tool)
 	}
 
-	serverName := b.availableTools[toolCall.Tool].ServerName // Get server name for logging
+	toolInfo := b.getAvailableTools()[toolCall.Tool]
+	serverName := toolInfo.ServerName // Get server name for logging
+	toolCall.Args = mcp.ApplyArgDefaults(ctx, toolInfo.ArgDefaults, toolInfo.ArgFixed, toolCall.Args)
 	b.logger.InfoKV("Calling MCP tool",
 		"tool", toolCall.Tool,
 		"server", serverName,
+		"timeout", toolInfo.Timeout,
 		"args", fmt.Sprintf("%v", toolCall.Args))
 
-	// Call the tool directly with the tool name and args
-	result, err := client.CallTool(ctx, toolCall.Tool, toolCall.Args)
+	// Call the tool through the middlewares, cancelling it once its timeout passes
+	call := &middleware.ToolCall{Tool: toolCall.Tool, Server: serverName, Args: toolCall.Args}
+	result, err := b.getMiddlewares().RunTool(ctx, call, func(ctx context.Context, call *middleware.ToolCall) (string, error) {
+		return mcp.CallToolWithTimeout(ctx, client, toolCall.Tool, toolInfo.Timeout, call.Args)
+	})
 	if err != nil {
 		// Create a domain-specific error with additional context
-		domainErr := customErrors.WrapMCPError(err, "tool_execution_failed",
-			fmt.Sprintf("Failed to execute MCP tool '%s'", toolCall.Tool))
+		code, message := "tool_execution_failed", fmt.Sprintf("Failed to execute MCP tool '%s'", toolCall.Tool)
+		var timeoutErr *mcp.ToolTimeoutError
+		if errors.As(err, &timeoutErr) {
+			code, message = "tool_timeout", fmt.Sprintf("MCP tool '%s' timed out", toolCall.Tool)
+		}
+		domainErr := customErrors.WrapMCPError(err, code, message)
 
 		// Add additional context data
 		domainErr = domainErr.WithData("tool_name", toolCall.Tool)
@@ -539,55 +645,97 @@ func (b *LLMMCPBridge) extractSimpleKeyValuePairs(text string) (map[string]inter
 	return result, len(result) > 0
 }
 
-func (b *LLMMCPBridge) CallLLMAgent(userDisplayName, systemPrompt, prompt, contextHistory string, callbackHandler callbacks.Handler) (string, error) {
+// CallLLMAgent runs the agent on the prompt. The call derives from parentCtx, so
+// tracing spans nest under the interaction, cancelling it stops the agent, and
+// request-scoped values such as the requesting user's identity reach tool calls.
+func (b *LLMMCPBridge) CallLLMAgent(parentCtx context.Context, userDisplayName, systemPrompt, prompt, contextHistory string, callbackHandler callbacks.Handler) (string, error) {
 	// Create a context with an appropriate timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+	ctx, cancel := context.WithTimeout(parentCtx, 3*time.Minute)
 	defer cancel()
 
-	toolArr := make([]tools.Tool, 0, len(b.availableTools))
-	for _, t := range b.availableTools {
+	chain := b.getMiddlewares()
+	availableTools := b.toolsFor(parentCtx)
+	toolArr := make([]tools.Tool, 0, len(availableTools))
+	for _, t := range availableTools {
+		if chain.Len() > 0 {
+			toolArr = append(toolArr, middlewareTool{ToolInfo: &t, chain: chain})
+			continue
+		}
 		toolArr = append(toolArr, &t)
 	}
 
-	// Prepare messages with system prompt and context history
-	history := []llm.RequestMessage{}
-
-	// Add conversation context if provided
-	if contextHistory != "" {
-		history = append(history, llm.RequestMessage{
-			Role:    "system",
-			Content: "Previous conversation: " + contextHistory,
-		})
-	}
-
-	// --- Use the specified provider via the registry ---
-	providerName := b.cfg.LLM.Provider
+	// --- Use the provider chosen for the request via the registry ---
+	route := b.routeFor(ctx)
+	providerName := route.Provider
 	b.logger.InfoKV("Attempting to use LLM provider for chat completion", "provider", providerName)
 
-	completion, err := b.llmRegistry.GenerateAgentCompletion(ctx, providerName, userDisplayName, systemPrompt, prompt, history, toolArr, callbackHandler, b.cfg.LLM.MaxAgentIterations)
+	// Prepare the system prompt, the conversation context if provided and the prompt
+	call := &middleware.LLMCall{
+		Provider:     providerName,
+		Model:        route.Model,
+		Agent:        true,
+		SystemPrompt: systemPrompt,
+		History:      historyMessages(ctx, contextHistory),
+		Prompt:       prompt,
+	}
+
+	start := time.Now()
+	choice, err := chain.RunLLM(ctx, call, func(ctx context.Context, call *middleware.LLMCall) (*llms.ContentChoice, error) {
+		agentCtx, agentTools := ctx, toolArr
+		var budget *agentBudget
+		if b.cfg.LLM.AgentBudget.Enabled() {
+			var cancelBudget context.CancelFunc
+			agentCtx, budget, cancelBudget = newAgentBudget(ctx, b.cfg.LLM.AgentBudget)
+			defer cancelBudget()
+			agentTools = budgetTools(toolArr, budget)
+		}
+		completion, err := b.llmRegistry.GenerateAgentCompletion(llm.ContextWithModel(agentCtx, route.Model), providerName, userDisplayName,
+			call.SystemPrompt, call.Prompt, requestMessages(call.History), agentTools, callbackHandler, b.cfg.LLM.MaxAgentIterations)
+		if err != nil {
+			// A run stopped by its budget still answers with what it found so far
+			if cause := exceeded(agentCtx); budget != nil && cause != nil && ctx.Err() == nil {
+				completion, err = b.summarizeProgress(ctx, providerName, call, budget, cause)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		return &llms.ContentChoice{Content: completion}, nil
+	})
+	observeRoute(route, start, err)
 	if err != nil {
 		// Error already logged by registry method potentially, but log here too for context
 		b.logger.ErrorKV("GenerateAgentCompletion failed", "provider", providerName, "error", err)
 		return "", customErrors.WrapSlackError(err, "llm_request_failed", fmt.Sprintf("LLM request failed for provider '%s'", providerName))
 	}
+	b.rememberRoute(ctx, route)
 
-	return completion, nil
+	return choice.Content, nil
 }
 
 // CallLLM generates a text completion using the specified provider from the registry.
-func (b *LLMMCPBridge) CallLLM(prompt, contextHistory string) (*llms.ContentChoice, error) {
+// The call derives from parentCtx, so tracing spans nest under the interaction,
+// cancelling it stops the request, and the tools selected for the request are the
+// ones offered to the LLM.
+func (b *LLMMCPBridge) CallLLM(parentCtx context.Context, prompt, contextHistory string) (*llms.ContentChoice, error) {
 	// Create a context with appropriate timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+	ctx, cancel := context.WithTimeout(parentCtx, 3*time.Minute)
 	defer cancel()
 
-	// Get the provider name from config
-	providerName := b.cfg.LLM.Provider
+	// Get the provider chosen for the request, which is the configured one unless routing is enabled
+	route := b.routeFor(ctx)
+	providerName := route.Provider
 
-	// Prepare messages with system prompt and context history
-	messages := []llm.RequestMessage{}
+	// Prepare the system prompt, the conversation context if provided and the prompt
+	call := &middleware.LLMCall{
+		Provider: providerName,
+		Model:    route.Model,
+		History:  historyMessages(ctx, contextHistory),
+		Prompt:   prompt,
+	}
 	// Build options based on the config (provider might override or use these)
 	// Note: TargetProvider is removed as it's handled by config/factory
-	options := llm.ProviderOptions{}
+	options := llm.ProviderOptions{Model: route.Model}
 
 	// Safely access configuration if available
 	if b.cfg != nil && b.cfg.LLM.Providers != nil {
@@ -597,51 +745,40 @@ func (b *LLMMCPBridge) CallLLM(prompt, contextHistory string) (*llms.ContentChoi
 		}
 	}
 
+	structured := false
 	if !b.cfg.LLM.UseNativeTools {
 		// Generate the system prompt with tool information
-		systemPrompt := b.generateToolPrompt()
+		call.SystemPrompt = b.generateToolPrompt(ctx)
 
-		// Add system prompt with tool info if available
-		if systemPrompt != "" {
-			messages = append(messages, llm.RequestMessage{
-				Role:    "system",
-				Content: systemPrompt,
-			})
+		// Constrain the response to a tool call or an answer instead of extracting JSON from free text
+		if b.cfg.LLM.StructuredOutput && len(b.toolsFor(ctx)) > 0 {
+			options.ResponseSchema = toolCallSchema
+			structured = true
 		}
 	} else {
 		tools := []llms.Tool{}
-		for name, tool := range b.availableTools {
+		for name, tool := range b.toolsFor(ctx) {
 			tools = append(tools, llms.Tool{
 				Type: "function",
 				Function: &llms.FunctionDefinition{
 					Name:        name,
 					Description: tool.ToolDescription,
-					Parameters:  tool.InputSchema,
+					Parameters:  nativeToolSchema(tool.InputSchema),
 				},
 			})
 		}
 		options.Tools = tools
 	}
 
-	// Add conversation context if provided
-	if contextHistory != "" {
-		messages = append(messages, llm.RequestMessage{
-			Role:    "system",
-			Content: "Previous conversation: " + contextHistory,
-		})
-	}
-
-	// Add the user's prompt
-	messages = append(messages, llm.RequestMessage{
-		Role:    "user",
-		Content: prompt,
-	})
-
 	// --- Use the specified provider via the registry ---
 	b.logger.InfoKV("Attempting to use LLM provider for chat completion", "provider", providerName)
 
-	// Call the registry's method which includes availability check
-	completion, err := b.llmRegistry.GenerateChatCompletion(ctx, providerName, messages, options)
+	// Call the registry's method, which includes availability check, through the middlewares
+	start := time.Now()
+	completion, err := b.getMiddlewares().RunLLM(ctx, call, func(ctx context.Context, call *middleware.LLMCall) (*llms.ContentChoice, error) {
+		return b.llmRegistry.GenerateChatCompletion(ctx, providerName, llmMessages(call), options)
+	})
+	observeRoute(route, start, err)
 	if err != nil {
 		// Error already logged by registry method potentially, but log here too for context
 		b.logger.ErrorKV("GenerateChatCompletion failed", "provider", providerName, "error", err)
@@ -649,6 +786,10 @@ func (b *LLMMCPBridge) CallLLM(prompt, contextHistory string) (*llms.ContentChoi
 	}
 
 	b.logger.InfoKV("Successfully received chat completion", "provider", providerName)
+	b.rememberRoute(ctx, route)
+	if structured {
+		b.applyStructuredToolCall(completion)
+	}
 
 	return completion, nil
 }
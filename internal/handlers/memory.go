@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/tuannvm/slack-mcp-client/internal/llm"
+	"github.com/tuannvm/slack-mcp-client/internal/memory"
+	"github.com/tuannvm/slack-mcp-client/pkg/middleware"
+)
+
+// memoryContextKey is the context key for the facts recalled for a request
+type memoryContextKey struct{}
+
+// defaultExtractPrompt tells the model which facts are worth remembering
+const defaultExtractPrompt = `Extract durable facts from the Slack message below that will still be true and useful in later conversations, such as who owns a service, team processes and schedules, or the speaker's role and preferences. Ignore questions, requests, greetings and anything temporary or specific to the current task. Most messages contain no such facts.`
+
+// memorySchema is the response the extraction call must return
+var memorySchema = &llm.ResponseSchema{
+	Name:        "memory_facts",
+	Description: "durable facts stated in a message",
+	Strict:      true,
+	Schema: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"facts": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"about": map[string]interface{}{
+							"type":        "string",
+							"enum":        []string{"speaker", "team"},
+							"description": "\"speaker\" for a fact about the speaker, \"team\" for anything else",
+						},
+						"fact": map[string]interface{}{
+							"type":        "string",
+							"description": "The fact as a short standalone sentence, naming people instead of using pronouns",
+						},
+					},
+					"required":             []string{"about", "fact"},
+					"additionalProperties": false,
+				},
+			},
+		},
+		"required":             []string{"facts"},
+		"additionalProperties": false,
+	},
+}
+
+// extractedFacts is the response described by memorySchema
+type extractedFacts struct {
+	Facts []struct {
+		About string `json:"about"`
+		Fact  string `json:"fact"`
+	} `json:"facts"`
+}
+
+// SetMemory enables long-term memory: facts are recalled into the context of
+// requests and learned from the messages the bot answers
+func (b *LLMMCPBridge) SetMemory(store *memory.Store) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.memory = store
+}
+
+// getMemory returns the memory store, or nil when memory is disabled
+func (b *LLMMCPBridge) getMemory() *memory.Store {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.memory
+}
+
+// RecallMemory picks the remembered facts about the user, and about the team from
+// the channel, most relevant to the user's message and returns a context whose
+// LLM calls are given them. It is a no-op when memory is disabled or nothing is
+// remembered.
+func (b *LLMMCPBridge) RecallMemory(ctx context.Context, query, userID, channelID string) context.Context {
+	store := b.getMemory()
+	if store == nil {
+		return ctx
+	}
+	facts := store.Recall(userID, channelID, query, b.cfg.Memory.MaxRecalled)
+	if len(facts) == 0 {
+		return ctx
+	}
+	b.logger.DebugKV("Recalled facts for request", "user", userID, "facts", len(facts))
+	return context.WithValue(ctx, memoryContextKey{}, facts)
+}
+
+// memoryMessages returns the facts recalled for the request in ctx as history
+func memoryMessages(ctx context.Context) []middleware.Message {
+	facts, ok := ctx.Value(memoryContextKey{}).([]memory.Fact)
+	if !ok || len(facts) == 0 {
+		return nil
+	}
+	var sb strings.Builder
+	sb.WriteString("Facts remembered from earlier conversations. Use them when relevant, but prefer newer information from the user or the tools:\n")
+	for _, fact := range facts {
+		fmt.Fprintf(&sb, "- %s (learned %s)\n", fact.Text, fact.Created.Format("2006-01-02"))
+	}
+	return []middleware.Message{{Role: "system", Content: sb.String()}}
+}
+
+// ExtractMemory asks the model for the durable facts stated in a message and
+// stores the new ones. Facts about the speaker are scoped to source.UserID, all
+// others to the team of source.ChannelID. It returns the facts added.
+func (b *LLMMCPBridge) ExtractMemory(ctx context.Context, message, speakerName string, source memory.Source) ([]memory.Fact, error) {
+	store := b.getMemory()
+	if store == nil || strings.TrimSpace(message) == "" {
+		return nil, nil
+	}
+
+	instructions := b.cfg.Memory.ExtractPrompt
+	if instructions == "" {
+		instructions = defaultExtractPrompt
+	}
+	messages := []llm.RequestMessage{
+		{Role: "system", Content: instructions},
+		{Role: "user", Content: fmt.Sprintf("Speaker: %s\nMessage:\n%s", speakerName, message)},
+	}
+	providerName := b.cfg.LLM.Provider
+	choice, err := b.llmRegistry.GenerateChatCompletion(ctx, providerName, messages, llm.ProviderOptions{ResponseSchema: memorySchema})
+	if err != nil {
+		return nil, err
+	}
+
+	var extracted extractedFacts
+	if err := llm.DecodeJSON(choice.Content, &extracted); err != nil {
+		return nil, fmt.Errorf("failed to parse extracted facts: %w", err)
+	}
+
+	var added []memory.Fact
+	for _, candidate := range extracted.Facts {
+		scope := memory.ScopeTeam
+		if candidate.About == "speaker" {
+			scope = source.UserID
+		}
+		fact, isNew, err := store.Add(memory.Fact{Scope: scope, Text: candidate.Fact, Source: source})
+		if err != nil {
+			return added, err
+		}
+		if isNew {
+			added = append(added, fact)
+		}
+	}
+	if len(added) > 0 {
+		b.logger.InfoKV("Remembered facts", "channel", source.ChannelID, "user", source.UserID, "facts", len(added))
+	}
+	return added, nil
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/memory"
+)
+
+func TestRecallMemoryAddsFactsToHistory(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.ApplyDefaults()
+	bridge := &LLMMCPBridge{cfg: cfg, logger: logging.New("test", logging.LevelError)}
+
+	// Without memory the context is unchanged
+	ctx := bridge.RecallMemory(context.Background(), "who owns billing?", "U1", "C1")
+	assert.Nil(t, memoryMessages(ctx))
+
+	store, err := memory.NewStore("", 0)
+	require.NoError(t, err)
+	created := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	_, _, err = store.Add(memory.Fact{Scope: memory.ScopeTeam, Text: "Alice owns the billing service", Source: memory.Source{ChannelID: "C1"}, Created: created})
+	require.NoError(t, err)
+	_, _, err = store.Add(memory.Fact{Scope: memory.ScopeTeam, Text: "Dave owns the billing database", Source: memory.Source{ChannelID: "C2"}, Created: created})
+	require.NoError(t, err)
+	_, _, err = store.Add(memory.Fact{Scope: "U2", Text: "Carol prefers answers in French", Created: created})
+	require.NoError(t, err)
+	bridge.SetMemory(store)
+
+	messages := memoryMessages(bridge.RecallMemory(context.Background(), "who owns billing?", "U1", "C1"))
+	require.Len(t, messages, 1)
+	assert.Equal(t, "system", messages[0].Role)
+	assert.Contains(t, messages[0].Content, "- Alice owns the billing service (learned 2026-10-01)\n")
+	assert.NotContains(t, messages[0].Content, "Carol")
+	assert.NotContains(t, messages[0].Content, "Dave", "team facts of other channels are not recalled")
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/llm"
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+	"github.com/tuannvm/slack-mcp-client/pkg/middleware"
+)
+
+// NewMiddlewareChain creates the enabled middlewares of the configuration, in order,
+// followed by the inner middlewares
+func NewMiddlewareChain(configs []config.MiddlewareConfig, logger *logging.Logger, inner ...middleware.Middleware) (*middleware.Chain, error) {
+	middlewares := make([]middleware.Middleware, 0, len(configs)+len(inner))
+	for _, mc := range configs {
+		if mc.Disabled {
+			continue
+		}
+		m, err := middleware.New(mc.Name, mc.Config, logger.WithName("middleware-"+mc.Name))
+		if err != nil {
+			return nil, err
+		}
+		middlewares = append(middlewares, m)
+	}
+	return middleware.NewChain(append(middlewares, inner...)...), nil
+}
+
+// SetMiddlewares runs the bridge's tool calls and LLM calls through the chain
+func (b *LLMMCPBridge) SetMiddlewares(chain *middleware.Chain) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.middlewares = chain
+}
+
+// getMiddlewares returns the current middleware chain, nil when none is set
+func (b *LLMMCPBridge) getMiddlewares() *middleware.Chain {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.middlewares
+}
+
+// middlewareTool runs the agent's calls of a tool through the middleware chain,
+// which the agent would otherwise bypass by calling the tool directly
+type middlewareTool struct {
+	*mcp.ToolInfo
+	chain *middleware.Chain
+}
+
+func (t middlewareTool) Call(ctx context.Context, input string) (string, error) {
+	var args map[string]interface{}
+	if err := json.Unmarshal([]byte(input), &args); err != nil {
+		return t.ToolInfo.Call(ctx, input) // Reports the invalid input
+	}
+	// Fill in the defaults first, so the middlewares see the arguments the tool gets
+	args = mcp.ApplyArgDefaults(ctx, t.ArgDefaults, t.ArgFixed, args)
+	call := &middleware.ToolCall{Tool: t.ToolName, Server: t.ServerName, Args: args}
+	return t.chain.RunTool(ctx, call, func(ctx context.Context, call *middleware.ToolCall) (string, error) {
+		input, err := json.Marshal(call.Args)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal input: %w", err)
+		}
+		return t.ToolInfo.Call(ctx, string(input))
+	})
+}
+
+// llmMessages returns the chat messages of an LLM call
+func llmMessages(call *middleware.LLMCall) []llm.RequestMessage {
+	messages := make([]llm.RequestMessage, 0, len(call.History)+2)
+	if call.SystemPrompt != "" {
+		messages = append(messages, llm.RequestMessage{Role: "system", Content: call.SystemPrompt})
+	}
+	messages = append(messages, requestMessages(call.History)...)
+	return append(messages, llm.RequestMessage{Role: "user", Content: call.Prompt})
+}
+
+// requestMessages converts middleware messages to provider messages
+func requestMessages(history []middleware.Message) []llm.RequestMessage {
+	messages := make([]llm.RequestMessage, 0, len(history))
+	for _, m := range history {
+		messages = append(messages, llm.RequestMessage{Role: m.Role, Content: m.Content, ToolName: m.ToolName, ToolArgs: m.ToolArgs, ToolCallID: m.ToolCallID})
+	}
+	return messages
+}
+
+// contextHistoryMessages returns the earlier conversation as the history of an LLM call
+func contextHistoryMessages(contextHistory string) []middleware.Message {
+	if contextHistory == "" {
+		return nil
+	}
+	return []middleware.Message{{Role: "system", Content: "Previous conversation: " + contextHistory}}
+}
+
+// toolResultsContextKey is the context key for the earlier tool results of a conversation
+type toolResultsContextKey struct{}
+
+// ContextWithToolResults returns a context whose LLM calls are given the earlier
+// tool results of the conversation as "tool" messages, after the conversation
+// context, instead of as text in it
+func ContextWithToolResults(ctx context.Context, results []middleware.Message) context.Context {
+	if len(results) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, toolResultsContextKey{}, results)
+}
+
+// ToolResultsFromContext returns the tool results set by ContextWithToolResults
+func ToolResultsFromContext(ctx context.Context) []middleware.Message {
+	results, _ := ctx.Value(toolResultsContextKey{}).([]middleware.Message)
+	return results
+}
+
+// historyMessages returns the history of an LLM call: the recalled facts, the
+// conversation context and the earlier tool results
+func historyMessages(ctx context.Context, contextHistory string) []middleware.Message {
+	history := append(memoryMessages(ctx), contextHistoryMessages(contextHistory)...)
+	return append(history, ToolResultsFromContext(ctx)...)
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+	"github.com/tuannvm/slack-mcp-client/pkg/middleware"
+)
+
+// echoClient answers tool calls with the "q" argument
+type echoClient struct{}
+
+func (echoClient) CallTool(_ context.Context, _ string, args map[string]interface{}) (string, error) {
+	return "result for " + args["q"].(string), nil
+}
+
+func TestToolCallsRunThroughMiddlewares(t *testing.T) {
+	logger := logging.New("test", logging.LevelError)
+	chain, err := NewMiddlewareChain([]config.MiddlewareConfig{
+		{Name: "redaction", Config: map[string]interface{}{"skipDefaults": true, "patterns": []string{"secret"}}},
+		{Name: "rbac", Config: map[string]interface{}{"defaultDeny": true}, Disabled: true},
+	}, logger)
+	require.NoError(t, err)
+	assert.Equal(t, 1, chain.Len(), "disabled middlewares are skipped")
+
+	tool := mcp.ToolInfo{ServerName: "docs", ToolName: "search", Client: echoClient{}}
+	bridge := &LLMMCPBridge{
+		logger:         logger,
+		cfg:            &config.Config{},
+		mcpClients:     map[string]mcp.MCPClientInterface{"docs": echoClient{}},
+		availableTools: map[string]mcp.ToolInfo{"search": tool},
+	}
+	bridge.SetMiddlewares(chain)
+
+	result, err := bridge.executeToolCall(context.Background(), &ToolCall{Tool: "search", Args: map[string]interface{}{"q": "secret"}}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "result for [REDACTED]", result)
+
+	// The agent calls tools directly, so its tools are wrapped
+	result, err = middlewareTool{ToolInfo: &tool, chain: chain}.Call(context.Background(), `{"q": "secret"}`)
+	require.NoError(t, err)
+	assert.Equal(t, "result for [REDACTED]", result)
+
+	_, err = NewMiddlewareChain([]config.MiddlewareConfig{{Name: "unknown"}}, logger)
+	assert.ErrorContains(t, err, "unknown middleware")
+}
+
+func TestToolCallsGetArgDefaults(t *testing.T) {
+	logger := logging.New("test", logging.LevelError)
+	chain, err := NewMiddlewareChain([]config.MiddlewareConfig{{Name: "redaction", Config: map[string]interface{}{"skipDefaults": true, "patterns": []string{"C-SECRET"}}}}, logger)
+	require.NoError(t, err)
+
+	tool := mcp.ToolInfo{ServerName: "docs", ToolName: "search", Client: echoClient{}, ArgDefaults: map[string]interface{}{"q": "${slack.channel_id}"}}
+	bridge := &LLMMCPBridge{
+		logger:         logger,
+		cfg:            &config.Config{},
+		mcpClients:     map[string]mcp.MCPClientInterface{"docs": echoClient{}},
+		availableTools: map[string]mcp.ToolInfo{"search": tool},
+	}
+	bridge.SetMiddlewares(chain)
+	ctx := mcp.ContextWithVars(context.Background(), map[string]string{config.ContextVarChannelID: "C-SECRET"})
+
+	// The middlewares see the defaults
+	result, err := bridge.executeToolCall(ctx, &ToolCall{Tool: "search"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "result for [REDACTED]", result)
+
+	result, err = middlewareTool{ToolInfo: &tool, chain: chain}.Call(ctx, `{}`)
+	require.NoError(t, err)
+	assert.Equal(t, "result for [REDACTED]", result)
+
+	result, err = tool.Call(ctx, `{"q": "pods"}`)
+	require.NoError(t, err)
+	assert.Equal(t, "result for pods", result)
+}
+
+func TestLLMMessages(t *testing.T) {
+	messages := llmMessages(&middleware.LLMCall{
+		History: contextHistoryMessages("User: hi"),
+		Prompt:  "What changed?",
+	})
+	require.Len(t, messages, 2, "an empty system prompt is left out")
+	assert.Equal(t, "Previous conversation: User: hi", messages[0].Content)
+	assert.Equal(t, "user", messages[1].Role)
+	assert.Equal(t, "What changed?", messages[1].Content)
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/tuannvm/slack-mcp-client/internal/affinity"
+	"github.com/tuannvm/slack-mcp-client/internal/hooks"
+	"github.com/tuannvm/slack-mcp-client/internal/monitoring"
+	"github.com/tuannvm/slack-mcp-client/internal/routing"
+)
+
+// SetThreadAffinity keeps each thread on the provider and model that first
+// answered it, remembered in store
+func (b *LLMMCPBridge) SetThreadAffinity(store *affinity.Store) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.affinity = store
+}
+
+// getAffinity returns the thread model store, or nil when threads are not kept on a model
+func (b *LLMMCPBridge) getAffinity() *affinity.Store {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.affinity
+}
+
+// ThreadModel returns the provider and model that answer a thread, when thread
+// affinity is enabled and the thread was answered
+func (b *LLMMCPBridge) ThreadModel(channelID, threadTS string) (affinity.Model, bool) {
+	store := b.getAffinity()
+	if store == nil {
+		return affinity.Model{}, false
+	}
+	return store.Get(affinity.Key(channelID, threadTS))
+}
+
+// SetThreadModel answers the following turns of a thread with provider and model,
+// or the provider's configured model when model is empty. An empty provider
+// forgets the thread's model, so its next turn is routed like a new thread.
+func (b *LLMMCPBridge) SetThreadModel(channelID, threadTS, provider, model string) error {
+	store := b.getAffinity()
+	if store == nil {
+		return nil
+	}
+	key := affinity.Key(channelID, threadTS)
+	if provider == "" {
+		_, err := store.Delete(key)
+		return err
+	}
+	return store.Override(key, provider, b.effectiveModel(provider, model))
+}
+
+// threadRoute returns the remembered model of the request's thread while its
+// provider is available
+func (b *LLMMCPBridge) threadRoute(ctx context.Context) (routing.Decision, bool) {
+	store := b.getAffinity()
+	key, ok := threadKey(ctx)
+	if store == nil || !ok {
+		return routing.Decision{}, false
+	}
+	remembered, ok := store.Get(key)
+	if !ok {
+		return routing.Decision{}, false
+	}
+	if !b.providerAvailable(remembered.Provider) {
+		b.logger.WarnKV("The provider of the thread is unavailable, choosing another", "thread", key, "provider", remembered.Provider, "model", remembered.Model)
+		monitoring.LLMThreadAffinity.WithLabelValues("unavailable").Inc()
+		return routing.Decision{}, false
+	}
+	monitoring.LLMThreadAffinity.WithLabelValues("kept").Inc()
+	return routing.Decision{Provider: remembered.Provider, Model: remembered.Model}, true
+}
+
+// rememberRoute records the model that answered the request's thread
+func (b *LLMMCPBridge) rememberRoute(ctx context.Context, route routing.Decision) {
+	store := b.getAffinity()
+	key, ok := threadKey(ctx)
+	if store == nil || !ok {
+		return
+	}
+	if err := store.Record(key, route.Provider, b.effectiveModel(route.Provider, route.Model)); err != nil {
+		b.logger.WarnKV("Failed to save the model of the thread", "thread", key, "error", err)
+	}
+}
+
+// effectiveModel returns model, or the provider's configured model when it is
+// empty, so a thread keeps its model when the configuration changes
+func (b *LLMMCPBridge) effectiveModel(provider, model string) string {
+	if model == "" && b.cfg != nil {
+		return b.cfg.LLM.Providers[provider].Model
+	}
+	return model
+}
+
+// providerAvailable reports whether a provider is configured and ready
+func (b *LLMMCPBridge) providerAvailable(name string) bool {
+	if b.cfg != nil {
+		if _, ok := b.cfg.LLM.Providers[name]; !ok {
+			return false
+		}
+	}
+	if b.llmRegistry == nil {
+		return true
+	}
+	provider, err := b.llmRegistry.GetProvider(name)
+	return err == nil && provider.IsAvailable()
+}
+
+// threadKey returns the thread of the request, set by the frontend
+func threadKey(ctx context.Context) (string, bool) {
+	channelID, threadTS, ok := hooks.ConversationFromContext(ctx)
+	if !ok || channelID == "" {
+		return "", false
+	}
+	return affinity.Key(channelID, threadTS), true
+}
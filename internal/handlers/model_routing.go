@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/tuannvm/slack-mcp-client/internal/experiments"
+	"github.com/tuannvm/slack-mcp-client/internal/monitoring"
+	"github.com/tuannvm/slack-mcp-client/internal/routing"
+)
+
+// routeContextKey is the context key for the model chosen for a request
+type routeContextKey struct{}
+
+// SetRouter enables per-message routing between a cheap and a powerful model
+func (b *LLMMCPBridge) SetRouter(router *routing.Router) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.router = router
+}
+
+// PinModel answers every request with provider and model, overriding routing
+// and experiments, e.g. after /model in the chat REPL. An empty provider
+// restores the configured choice; an empty model uses the provider's own.
+func (b *LLMMCPBridge) PinModel(provider, model string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if provider == "" {
+		b.pinned = nil
+		return
+	}
+	b.pinned = &routing.Decision{Provider: provider, Model: model}
+}
+
+// RouteRequest chooses the model that answers the user's message and returns a
+// context that the LLM calls of the request use. Call it after SelectTools, so
+// tool intent is judged against the tools offered. When routing is disabled the
+// configured provider is used.
+func (b *LLMMCPBridge) RouteRequest(ctx context.Context, query, channelID string) context.Context {
+	b.mu.RLock()
+	router := b.router
+	b.mu.RUnlock()
+	if router == nil {
+		return ctx
+	}
+
+	availableTools := b.toolsFor(ctx)
+	toolNames := make([]string, 0, len(availableTools))
+	for name := range availableTools {
+		toolNames = append(toolNames, name)
+	}
+	decision := router.Route(query, channelID, toolNames)
+	monitoring.LLMRouteDecisions.WithLabelValues(decision.Route, decision.Reason).Inc()
+	b.logger.DebugKV("Routed request", "channel", channelID, "route", decision.Route, "reason", decision.Reason,
+		"provider", decision.Provider, "model", decision.Model)
+	return context.WithValue(ctx, routeContextKey{}, decision)
+}
+
+// routeFor returns the model chosen for the request in ctx. Without a routing
+// decision it is the configured provider and model. An experiment variant that
+// changes the provider or model overrides the decision, the remembered model of
+// the thread overrides both while its provider is available, and a pinned model
+// overrides everything.
+func (b *LLMMCPBridge) routeFor(ctx context.Context) routing.Decision {
+	b.mu.RLock()
+	pinned := b.pinned
+	b.mu.RUnlock()
+	if pinned != nil {
+		return *pinned
+	}
+	if decision, ok := b.threadRoute(ctx); ok {
+		return decision
+	}
+	decision, ok := ctx.Value(routeContextKey{}).(routing.Decision)
+	if !ok {
+		decision = routing.Decision{Provider: b.cfg.LLM.Provider}
+	}
+	if assignment, ok := experiments.FromContext(ctx); ok && (assignment.Provider != "" || assignment.Model != "") {
+		if assignment.Provider != "" {
+			decision.Provider = assignment.Provider
+		}
+		decision.Model = assignment.Model
+	}
+	return decision
+}
+
+// observeRoute records the outcome and duration of an LLM call on a routed request
+func observeRoute(decision routing.Decision, start time.Time, err error) {
+	if decision.Route == "" {
+		return
+	}
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+	}
+	monitoring.LLMRouteRequests.WithLabelValues(decision.Route, decision.Model, outcome).Inc()
+	monitoring.LLMRouteDuration.WithLabelValues(decision.Route, decision.Model).Observe(time.Since(start).Seconds())
+}
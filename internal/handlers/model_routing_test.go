@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/affinity"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/experiments"
+	"github.com/tuannvm/slack-mcp-client/internal/hooks"
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+	"github.com/tuannvm/slack-mcp-client/internal/routing"
+)
+
+func TestRouteRequest(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.LLM.Routing = config.LLMRoutingConfig{
+		Enabled:  true,
+		Cheap:    config.LLMRouteConfig{Model: "gpt-4o-mini"},
+		Powerful: config.LLMRouteConfig{Model: "gpt-4o"},
+	}
+	cfg.ApplyDefaults()
+	tools := map[string]mcp.ToolInfo{
+		"get_weather": {ToolName: "get_weather", ToolDescription: "Get the weather"},
+		"list_issues": {ToolName: "list_issues", ToolDescription: "List issues"},
+	}
+	bridge := NewLLMMCPBridge(map[string]mcp.MCPClientInterface{}, log.New(os.Stderr, "", 0), tools, nil, cfg)
+
+	// Without a router the configured provider and model are used
+	ctx := bridge.RouteRequest(context.Background(), "hello", "C123")
+	assert.Equal(t, routing.Decision{Provider: config.ProviderOpenAI}, bridge.routeFor(ctx))
+
+	bridge.SetRouter(routing.NewRouter(cfg.LLM.Routing))
+	ctx = bridge.RouteRequest(context.Background(), "hello", "C123")
+	assert.Equal(t, "gpt-4o-mini", bridge.routeFor(ctx).Model)
+
+	// Tool intent is judged against the tools offered for the request
+	ctx = bridge.RouteRequest(context.Background(), "get weather for Paris", "C123")
+	assert.Equal(t, config.RoutePowerful, bridge.routeFor(ctx).Route)
+	selected := context.WithValue(context.Background(), selectedToolsContextKey{}, []string{"list_issues"})
+	ctx = bridge.RouteRequest(selected, "get weather for Paris", "C123")
+	assert.Equal(t, config.RouteCheap, bridge.routeFor(ctx).Route)
+}
+
+func TestRouteForExperimentVariant(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.LLM.Routing = config.LLMRoutingConfig{Enabled: true, Cheap: config.LLMRouteConfig{Model: "gpt-4o-mini"}}
+	cfg.ApplyDefaults()
+	bridge := NewLLMMCPBridge(map[string]mcp.MCPClientInterface{}, log.New(os.Stderr, "", 0), nil, nil, cfg)
+	bridge.SetRouter(routing.NewRouter(cfg.LLM.Routing))
+	ctx := bridge.RouteRequest(context.Background(), "hello", "C123")
+
+	// A variant that only changes the system prompt keeps the routed model
+	variant := experiments.WithAssignment(ctx, experiments.Assignment{Experiment: "tone", Arm: experiments.ArmVariant, CustomPrompt: "Be brief."})
+	assert.Equal(t, "gpt-4o-mini", bridge.routeFor(variant).Model)
+
+	variant = experiments.WithAssignment(ctx, experiments.Assignment{Experiment: "model", Arm: experiments.ArmVariant, Model: "gpt-4.1"})
+	decision := bridge.routeFor(variant)
+	assert.Equal(t, config.ProviderOpenAI, decision.Provider)
+	assert.Equal(t, "gpt-4.1", decision.Model)
+	assert.Equal(t, config.RouteCheap, decision.Route)
+
+	variant = experiments.WithAssignment(ctx, experiments.Assignment{Experiment: "provider", Arm: experiments.ArmVariant, Provider: config.ProviderAnthropic})
+	assert.Equal(t, routing.Decision{Route: config.RouteCheap, Reason: routing.ReasonDefault, Provider: config.ProviderAnthropic}, bridge.routeFor(variant))
+}
+
+func TestPinModel(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.LLM.Routing = config.LLMRoutingConfig{Enabled: true, Cheap: config.LLMRouteConfig{Model: "gpt-4o-mini"}}
+	cfg.ApplyDefaults()
+	bridge := NewLLMMCPBridge(map[string]mcp.MCPClientInterface{}, log.New(os.Stderr, "", 0), nil, nil, cfg)
+	bridge.SetRouter(routing.NewRouter(cfg.LLM.Routing))
+	ctx := bridge.RouteRequest(context.Background(), "hello", "C123")
+	variant := experiments.WithAssignment(ctx, experiments.Assignment{Experiment: "model", Arm: experiments.ArmVariant, Model: "gpt-4.1"})
+
+	// A pinned model overrides routing and experiments
+	bridge.PinModel(config.ProviderOllama, "llama3")
+	assert.Equal(t, routing.Decision{Provider: config.ProviderOllama, Model: "llama3"}, bridge.routeFor(variant))
+
+	bridge.PinModel("", "")
+	assert.Equal(t, "gpt-4.1", bridge.routeFor(variant).Model)
+}
+
+func TestThreadAffinity(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.LLM.Routing = config.LLMRoutingConfig{Enabled: true, Cheap: config.LLMRouteConfig{Model: "gpt-4o-mini"}}
+	cfg.ApplyDefaults()
+	bridge := NewLLMMCPBridge(map[string]mcp.MCPClientInterface{}, log.New(os.Stderr, "", 0), nil, nil, cfg)
+	bridge.SetRouter(routing.NewRouter(cfg.LLM.Routing))
+	store, err := affinity.NewStore("", 0)
+	require.NoError(t, err)
+	bridge.SetThreadAffinity(store)
+	thread := hooks.ContextWithConversation(context.Background(), "C1", "100.1")
+
+	// The model that answered the thread is remembered, with the provider's model when routing left it empty
+	bridge.rememberRoute(thread, routing.Decision{Provider: config.ProviderOpenAI})
+	model, ok := bridge.ThreadModel("C1", "100.1")
+	require.True(t, ok)
+	assert.Equal(t, "gpt-4o", model.Model)
+
+	// Later turns keep it over routing and experiments, other threads are routed
+	ctx := bridge.RouteRequest(thread, "hello", "C1")
+	variant := experiments.WithAssignment(ctx, experiments.Assignment{Experiment: "provider", Arm: experiments.ArmVariant, Provider: config.ProviderAnthropic})
+	assert.Equal(t, routing.Decision{Provider: config.ProviderOpenAI, Model: "gpt-4o"}, bridge.routeFor(variant))
+	other := bridge.RouteRequest(hooks.ContextWithConversation(context.Background(), "C1", "200.1"), "hello", "C1")
+	assert.Equal(t, "gpt-4o-mini", bridge.routeFor(other).Model)
+
+	// A user can switch the thread to another model, or back to routing
+	require.NoError(t, bridge.SetThreadModel("C1", "100.1", config.ProviderOllama, ""))
+	assert.Equal(t, routing.Decision{Provider: config.ProviderOllama, Model: cfg.LLM.Providers[config.ProviderOllama].Model}, bridge.routeFor(ctx))
+	require.NoError(t, bridge.SetThreadModel("C1", "100.1", "", ""))
+	assert.Equal(t, "gpt-4o-mini", bridge.routeFor(ctx).Model)
+
+	// A thread whose provider is no longer configured is routed again
+	require.NoError(t, store.Record(affinity.Key("C1", "100.1"), "removed", "old-model"))
+	assert.Equal(t, config.ProviderOpenAI, bridge.routeFor(ctx).Provider)
+
+	// A pinned model still overrides everything
+	bridge.PinModel(config.ProviderAnthropic, "claude-sonnet-4-5")
+	assert.Equal(t, config.ProviderAnthropic, bridge.routeFor(ctx).Provider)
+}
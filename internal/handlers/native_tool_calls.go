@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+
+	customErrors "github.com/tuannvm/slack-mcp-client/internal/common/errors"
+	"github.com/tuannvm/slack-mcp-client/internal/llm"
+)
+
+// codeBlockPattern matches fenced code blocks that may hold a JSON tool call
+var codeBlockPattern = regexp.MustCompile("```(?:json)?\\s*([\\[{][\\s\\S]*?[\\]}])\\s*```")
+
+// nativeToolCallJSON covers the tool call shapes providers use when the call is
+// returned as JSON text rather than as a structured tool call:
+//
+//	Anthropic content block: {"type": "tool_use", "name": ..., "input": {...}}
+//	Gemini function call:    {"functionCall": {"name": ..., "args": {...}}}
+//	OpenAI tool call:        {"type": "function", "function": {"name": ..., "arguments": "..."}}
+//	Llama and Ollama models: {"name": ..., "arguments" or "parameters": {...}}
+//
+// Responses that wrap these in "content", "parts" or "tool_calls" arrays, or in a
+// top-level array, are searched too.
+type nativeToolCallJSON struct {
+	Type       string          `json:"type"`
+	Name       string          `json:"name"`
+	Input      json.RawMessage `json:"input"`
+	Arguments  json.RawMessage `json:"arguments"`
+	Parameters json.RawMessage `json:"parameters"`
+	Args       json.RawMessage `json:"args"`
+
+	FunctionCall      *nativeToolCallJSON `json:"functionCall"`
+	FunctionCallSnake *nativeToolCallJSON `json:"function_call"`
+	Function          *nativeToolCallJSON `json:"function"`
+
+	Content   json.RawMessage   `json:"content"`
+	Parts     []json.RawMessage `json:"parts"`
+	ToolCalls []json.RawMessage `json:"tool_calls"`
+}
+
+// nativeToolSchema returns a tool's input schema for native tool calling
+func nativeToolSchema(schema map[string]interface{}) map[string]interface{} {
+	return llm.ObjectSchema(schema)
+}
+
+// nativeToolCall returns the first tool call of a structured response. OpenAI, and
+// Gemini through its OpenAI-compatible endpoint, fill ToolCalls; legacy function
+// calling fills FuncCall; Anthropic tool_use blocks are merged into ToolCalls by the
+// LLM provider. It returns nil when the response has no structured tool call.
+func (b *LLMMCPBridge) nativeToolCall(choice *llms.ContentChoice) (*ToolCall, error) {
+	funcCall := choice.FuncCall
+	for _, toolCall := range choice.ToolCalls {
+		if toolCall.FunctionCall != nil && toolCall.FunctionCall.Name != "" {
+			funcCall = toolCall.FunctionCall
+			break
+		}
+	}
+	if funcCall == nil {
+		return nil, nil
+	}
+	if len(choice.ToolCalls) > 1 {
+		b.logger.DebugKV("Response has several tool calls, executing the first", "tool", funcCall.Name, "count", len(choice.ToolCalls))
+	}
+	return b.getToolCall(funcCall)
+}
+
+// tryNativeFormatParsing looks for a provider's native tool call format in the
+// response text, and in its code blocks
+func (b *LLMMCPBridge) tryNativeFormatParsing(response string) *ToolCall {
+	b.logger.DebugKV("Searching for native tool call formats")
+	candidates := []string{strings.TrimSpace(response)}
+	for _, match := range codeBlockPattern.FindAllStringSubmatch(response, -1) {
+		candidates = append(candidates, match[1])
+	}
+
+	for _, candidate := range candidates {
+		toolCall := findNativeToolCall(json.RawMessage(candidate), 0)
+		if toolCall != nil && b.isValidToolCall(*toolCall) {
+			b.logger.DebugKV("Native tool call format parsing successful", "tool", toolCall.Tool)
+			return toolCall
+		}
+	}
+	return nil
+}
+
+// findNativeToolCall searches a JSON value for a tool call, descending into wrapper
+// arrays and objects up to a few levels deep
+func findNativeToolCall(raw json.RawMessage, depth int) *ToolCall {
+	raw = bytes.TrimSpace(raw)
+	if depth > 4 || len(raw) == 0 {
+		return nil
+	}
+
+	if raw[0] == '[' {
+		var items []json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return nil
+		}
+		for _, item := range items {
+			if toolCall := findNativeToolCall(item, depth+1); toolCall != nil {
+				return toolCall
+			}
+		}
+		return nil
+	}
+
+	var block nativeToolCallJSON
+	if raw[0] != '{' || json.Unmarshal(raw, &block) != nil {
+		return nil
+	}
+
+	for _, nested := range []*nativeToolCallJSON{block.FunctionCall, block.FunctionCallSnake, block.Function} {
+		if nested != nil && nested.Name != "" {
+			if args, ok := decodeToolArgs(nested.Args, nested.Arguments, nested.Parameters, nested.Input); ok {
+				return &ToolCall{Tool: nested.Name, Args: args}
+			}
+		}
+	}
+	if block.Name != "" && (block.Type == "" || block.Type == "tool_use" || block.Type == "function") {
+		if args, ok := decodeToolArgs(block.Input, block.Arguments, block.Parameters, block.Args); ok {
+			return &ToolCall{Tool: block.Name, Args: args}
+		}
+	}
+
+	wrapped := append(append([]json.RawMessage{block.Content}, block.Parts...), block.ToolCalls...)
+	for _, item := range wrapped {
+		if toolCall := findNativeToolCall(item, depth+1); toolCall != nil {
+			return toolCall
+		}
+	}
+	return nil
+}
+
+// decodeToolArgs decodes the first present arguments value. Arguments may be an
+// object or a JSON-encoded string, as OpenAI sends them; a call without arguments
+// decodes to an empty map.
+func decodeToolArgs(candidates ...json.RawMessage) (map[string]interface{}, bool) {
+	for _, raw := range candidates {
+		raw = bytes.TrimSpace(raw)
+		if len(raw) == 0 {
+			continue
+		}
+		if raw[0] == '"' {
+			var encoded string
+			if err := json.Unmarshal(raw, &encoded); err != nil {
+				return nil, false
+			}
+			raw = []byte(encoded)
+		}
+		args, err := parseToolArgs(string(raw))
+		return args, err == nil
+	}
+	return nil, false
+}
+
+// parseToolArgs parses tool call arguments. Gemini sends an empty string, and some
+// models send null, for tools without parameters.
+func parseToolArgs(arguments string) (map[string]interface{}, error) {
+	arguments = strings.TrimSpace(arguments)
+	if arguments == "" || arguments == "null" {
+		return map[string]interface{}{}, nil
+	}
+	var args map[string]interface{}
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return nil, err
+	}
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+	return args, nil
+}
+
+// getToolCall converts a structured function call into a ToolCall
+func (b *LLMMCPBridge) getToolCall(funcCall *llms.FunctionCall) (*ToolCall, error) {
+	args, err := parseToolArgs(funcCall.Arguments)
+	if err != nil {
+		return nil, customErrors.NewMCPError("invalid_json_args", "Args not valid json for call '"+funcCall.Name+"'").
+			WithData("tool_name", funcCall.Name)
+	}
+	return &ToolCall{
+		Tool: funcCall.Name,
+		Args: args,
+	}, nil
+}
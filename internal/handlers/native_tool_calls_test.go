@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"log"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+)
+
+func newNativeToolBridge() *LLMMCPBridge {
+	tools := map[string]mcp.ToolInfo{
+		"get_weather": {ToolName: "get_weather", ToolDescription: "Get the weather"},
+		"list_alerts": {ToolName: "list_alerts", ToolDescription: "List firing alerts"},
+	}
+	return NewLLMMCPBridge(map[string]mcp.MCPClientInterface{}, log.New(os.Stderr, "", 0), tools, nil, &config.Config{})
+}
+
+func TestNativeToolCallFromStructuredResponse(t *testing.T) {
+	bridge := newNativeToolBridge()
+
+	// Anthropic tool_use blocks arrive in ToolCalls alongside the text block
+	toolCall, err := bridge.nativeToolCall(&llms.ContentChoice{
+		Content: "Let me check the weather.",
+		ToolCalls: []llms.ToolCall{{ID: "toolu_1", FunctionCall: &llms.FunctionCall{
+			Name: "get_weather", Arguments: `{"city":"Hanoi"}`,
+		}}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &ToolCall{Tool: "get_weather", Args: map[string]interface{}{"city": "Hanoi"}}, toolCall)
+
+	// Gemini sends empty arguments for tools without parameters
+	toolCall, err = bridge.nativeToolCall(&llms.ContentChoice{
+		ToolCalls: []llms.ToolCall{{FunctionCall: &llms.FunctionCall{Name: "list_alerts"}}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{}, toolCall.Args)
+
+	toolCall, err = bridge.nativeToolCall(&llms.ContentChoice{Content: "No tools needed."})
+	require.NoError(t, err)
+	assert.Nil(t, toolCall)
+
+	_, err = bridge.nativeToolCall(&llms.ContentChoice{FuncCall: &llms.FunctionCall{Name: "get_weather", Arguments: "{city"}})
+	assert.Error(t, err)
+}
+
+func TestDetectNativeToolCallFormats(t *testing.T) {
+	bridge := newNativeToolBridge()
+	tests := map[string]string{
+		"anthropic content block": `{"type":"tool_use","id":"toolu_1","name":"get_weather","input":{"city":"Hanoi"}}`,
+		"anthropic message":       `{"role":"assistant","content":[{"type":"text","text":"Checking."},{"type":"tool_use","name":"get_weather","input":{"city":"Hanoi"}}]}`,
+		"gemini function call":    `{"functionCall":{"name":"get_weather","args":{"city":"Hanoi"}}}`,
+		"gemini parts":            "```json\n{\"parts\":[{\"functionCall\":{\"name\":\"get_weather\",\"args\":{\"city\":\"Hanoi\"}}}]}\n```",
+		"openai tool calls":       `{"tool_calls":[{"type":"function","function":{"name":"get_weather","arguments":"{\"city\":\"Hanoi\"}"}}]}`,
+		"llama":                   `[{"name":"get_weather","parameters":{"city":"Hanoi"}}]`,
+	}
+	for name, response := range tests {
+		t.Run(name, func(t *testing.T) {
+			toolCall := bridge.detectSpecificJSONToolCall(response)
+			require.NotNil(t, toolCall)
+			assert.Equal(t, "get_weather", toolCall.Tool)
+			assert.Equal(t, map[string]interface{}{"city": "Hanoi"}, toolCall.Args)
+		})
+	}
+
+	assert.Nil(t, bridge.detectSpecificJSONToolCall(`{"functionCall":{"name":"delete_cluster","args":{}}}`))
+	assert.Nil(t, bridge.detectSpecificJSONToolCall(`{"name":"Hanoi","population":8000000}`))
+}
+
+func TestNativeToolSchema(t *testing.T) {
+	assert.Equal(t, "object", nativeToolSchema(nil)["type"])
+	schema := nativeToolSchema(map[string]interface{}{"properties": map[string]interface{}{}})
+	assert.Equal(t, "object", schema["type"])
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+)
+
+// RAGServerName identifies the knowledge base client among the bridge's clients
+const RAGServerName = "rag"
+
+// nativeServer is a registered built-in and the tools it serves
+type nativeServer struct {
+	client mcp.MCPClientInterface
+	tools  map[string]mcp.ToolInfo
+}
+
+// NativeToolRegistry collects the tools that run in this process instead of an
+// MCP server, such as the knowledge base, on-call lookups, WASM and HTTP tools.
+// Each built-in registers its client together with the tools it serves, so its
+// tools are declared once and reach the bridge the same way.
+type NativeToolRegistry struct {
+	servers map[string]nativeServer
+	order   []string // Server names in registration order
+	logger  *logging.Logger
+}
+
+// NewNativeToolRegistry creates an empty registry
+func NewNativeToolRegistry(logger *logging.Logger) *NativeToolRegistry {
+	return &NativeToolRegistry{
+		servers: make(map[string]nativeServer),
+		logger:  logger.WithName("native-tools"),
+	}
+}
+
+// Register adds a built-in's client and the tools it serves. Tools are given the
+// server name, so calls reach the client. Registering a server name again
+// replaces the earlier registration.
+func (r *NativeToolRegistry) Register(serverName string, client mcp.MCPClientInterface, tools map[string]mcp.ToolInfo) {
+	if _, exists := r.servers[serverName]; !exists {
+		r.order = append(r.order, serverName)
+	}
+	named := make(map[string]mcp.ToolInfo, len(tools))
+	for name, tool := range tools {
+		tool.ServerName = serverName
+		named[name] = tool
+	}
+	r.servers[serverName] = nativeServer{client: client, tools: named}
+	r.logger.DebugKV("Registered native tools", "server", serverName, "tool_count", len(named))
+}
+
+// AddTo adds the registered clients to clients and their tools to tools. Tool
+// names already in tools, such as those of MCP servers, keep their tool, and
+// the native tool is skipped with a warning. It returns the number of tools added.
+func (r *NativeToolRegistry) AddTo(clients map[string]interface{}, tools map[string]mcp.ToolInfo) int {
+	added := 0
+	for _, serverName := range r.order {
+		server := r.servers[serverName]
+		clients[serverName] = server.client
+		names := make([]string, 0, len(server.tools))
+		for name := range server.tools {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if existing, exists := tools[name]; exists {
+				r.logger.WarnKV("Native tool name is already used, skipping it", "tool", name, "server", serverName, "used_by", existing.ServerName)
+				continue
+			}
+			tools[name] = server.tools[name]
+			added++
+		}
+	}
+	return added
+}
+
+// Len returns the number of registered servers
+func (r *NativeToolRegistry) Len() int {
+	return len(r.servers)
+}
+
+// RAGToolInfos describes the knowledge base tools, with the crawl limits of
+// rag_ingest_url. rag_ingest_url is left out unless allowed domains are
+// configured, so the LLM cannot be steered into fetching arbitrary hosts.
+func RAGToolInfos(web config.RAGWebConfig) map[string]mcp.ToolInfo {
+	tools := map[string]mcp.ToolInfo{
+		"rag_search": {
+			ToolName:        "rag_search",
+			ToolDescription: "Search the RAG knowledge base for relevant information. Use the optional filters when the user limits where to look, such as \"only in the runbooks\"",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"query": map[string]interface{}{
+						"type":        "string",
+						"description": "The search query to find relevant information",
+					},
+					"file_glob": map[string]interface{}{
+						"type":        "string",
+						"description": "Optional glob on the file name, or on the path or URL when it contains a /, e.g. \"*runbook*\" or \"*/postmortems/*\". * matches any text and case is ignored",
+					},
+					"source_type": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"file", "url", "connector"},
+						"description": "Optional source of the documents: ingested files, web pages, or pages synced from Confluence, Notion or Google Drive",
+					},
+					"ingested_after": map[string]interface{}{
+						"type":        "string",
+						"description": "Optional date (YYYY-MM-DD) or RFC 3339 time; only documents ingested at or after it",
+					},
+					"ingested_before": map[string]interface{}{
+						"type":        "string",
+						"description": "Optional date (YYYY-MM-DD) or RFC 3339 time; only documents ingested before it",
+					},
+					"namespace": map[string]interface{}{
+						"type":        "string",
+						"description": "Optional knowledge base namespace to search (channels scoped to a namespace can only search their own)",
+					},
+				},
+				"required": []string{"query"},
+			},
+			ServerName: RAGServerName,
+		},
+		"rag_ingest": {
+			ToolName:        "rag_ingest",
+			ToolDescription: "Ingest a file into the RAG knowledge base",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"file_path": map[string]interface{}{
+						"type":        "string",
+						"description": "Path to the file to ingest",
+					},
+					"metadata": map[string]interface{}{
+						"type":        "object",
+						"description": "Optional metadata for the file",
+					},
+					"namespace": map[string]interface{}{
+						"type":        "string",
+						"description": "Optional knowledge base namespace to store the file in (defaults to the channel's namespace)",
+					},
+				},
+				"required": []string{"file_path"},
+			},
+			ServerName: RAGServerName,
+		},
+		"rag_ingest_url": {
+			ToolName:        "rag_ingest_url",
+			ToolDescription: "Fetch a web page, or crawl the pages it links to on the same site, and ingest their text into the RAG knowledge base",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"url": map[string]interface{}{
+						"type":        "string",
+						"description": "The http or https URL of the page to ingest",
+					},
+					"depth": map[string]interface{}{
+						"type":        "integer",
+						"description": fmt.Sprintf("Link depth to crawl from the page on the same site, 0 for only the page (max %d)", web.MaxDepth),
+					},
+					"max_pages": map[string]interface{}{
+						"type":        "integer",
+						"description": fmt.Sprintf("Maximum number of pages to fetch (max %d)", web.MaxPages),
+					},
+					"namespace": map[string]interface{}{
+						"type":        "string",
+						"description": "Optional knowledge base namespace to store the pages in (defaults to the channel's namespace)",
+					},
+				},
+				"required": []string{"url"},
+			},
+			ServerName: RAGServerName,
+		},
+		"rag_stats": {
+			ToolName:        "rag_stats",
+			ToolDescription: "Get statistics about the RAG knowledge base",
+			InputSchema: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+			},
+			ServerName: RAGServerName,
+		},
+	}
+	if len(web.AllowedDomains) == 0 {
+		delete(tools, "rag_ingest_url")
+	}
+	return tools
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+)
+
+// echoServer is a native tool server that echoes the tool name
+type echoServer struct{}
+
+func (echoServer) CallTool(_ context.Context, toolName string, _ map[string]interface{}) (string, error) {
+	return toolName, nil
+}
+
+func TestNativeToolRegistry(t *testing.T) {
+	registry := NewNativeToolRegistry(logging.New("test", logging.LevelError))
+	registry.Register(RAGServerName, echoServer{}, RAGToolInfos(config.RAGWebConfig{MaxDepth: 2, MaxPages: 20, AllowedDomains: []string{"docs.example.com"}}))
+	registry.Register("oncall", echoServer{}, map[string]mcp.ToolInfo{"who_is_on_call": {ToolName: "who_is_on_call"}})
+
+	clients := map[string]interface{}{"github": echoServer{}}
+	tools := map[string]mcp.ToolInfo{"rag_stats": {ToolName: "rag_stats", ServerName: "github"}}
+	assert.Equal(t, 4, registry.AddTo(clients, tools))
+
+	assert.Len(t, clients, 3)
+	assert.Equal(t, "github", tools["rag_stats"].ServerName, "MCP tools keep their name")
+	assert.Equal(t, RAGServerName, tools["rag_search"].ServerName)
+	assert.Equal(t, "oncall", tools["who_is_on_call"].ServerName, "tools are given the server name")
+	depth := tools["rag_ingest_url"].InputSchema["properties"].(map[string]interface{})["depth"].(map[string]interface{})
+	assert.Contains(t, depth["description"], "(max 2)")
+
+	// Without allowed domains, the LLM is not offered URL ingestion
+	assert.NotContains(t, RAGToolInfos(config.RAGWebConfig{}), "rag_ingest_url")
+}
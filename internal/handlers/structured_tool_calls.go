@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"encoding/json"
+
+	"github.com/tmc/langchaingo/llms"
+
+	"github.com/tuannvm/slack-mcp-client/internal/llm"
+)
+
+// toolCallSchema constrains the response of a prompt-based tool call to either a
+// tool call or an answer. Arguments are a JSON-encoded string because strict
+// schemas cannot describe the free-form arguments of every tool.
+var toolCallSchema = &llm.ResponseSchema{
+	Name:        "tool_call",
+	Description: "either a tool call or a direct answer to the user",
+	Strict:      true,
+	Schema: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"tool": map[string]interface{}{
+				"type":        "string",
+				"description": "Name of the tool to call, or an empty string to answer directly",
+			},
+			"args": map[string]interface{}{
+				"type":        "string",
+				"description": "JSON object of tool arguments encoded as a string, or \"{}\"",
+			},
+			"answer": map[string]interface{}{
+				"type":        "string",
+				"description": "The answer to the user when no tool is called, otherwise an empty string",
+			},
+		},
+		"required":             []string{"tool", "args", "answer"},
+		"additionalProperties": false,
+	},
+}
+
+// structuredToolResponse is the response described by toolCallSchema
+type structuredToolResponse struct {
+	Tool   string `json:"tool"`
+	Args   string `json:"args"`
+	Answer string `json:"answer"`
+}
+
+// applyStructuredToolCall rewrites a response produced with toolCallSchema: tool
+// calls become the canonical {"tool": ..., "args": {...}} JSON and answers become
+// plain text. Responses that do not match the schema are left for the legacy
+// JSON detection.
+func (b *LLMMCPBridge) applyStructuredToolCall(choice *llms.ContentChoice) {
+	if choice == nil || len(choice.ToolCalls) > 0 || choice.FuncCall != nil {
+		return
+	}
+	var response structuredToolResponse
+	if err := llm.DecodeJSON(choice.Content, &response); err != nil {
+		b.logger.DebugKV("Response does not match the tool call schema", "error", err)
+		return
+	}
+	if response.Tool == "" {
+		if response.Answer != "" {
+			choice.Content = response.Answer
+		}
+		return
+	}
+
+	args, err := parseToolArgs(response.Args)
+	if err != nil {
+		b.logger.WarnKV("Structured tool call has invalid arguments", "tool", response.Tool, "error", err)
+		return
+	}
+	toolCall := ToolCall{Tool: response.Tool, Args: args}
+	if !b.isValidToolCall(toolCall) {
+		if response.Answer != "" {
+			choice.Content = response.Answer
+		}
+		return
+	}
+	content, err := json.Marshal(toolCall)
+	if err != nil {
+		return
+	}
+	choice.Content = string(content)
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tmc/langchaingo/llms"
+)
+
+func TestApplyStructuredToolCall(t *testing.T) {
+	bridge := newNativeToolBridge()
+	tests := map[string]struct {
+		content string
+		want    string
+	}{
+		"tool call": {
+			content: `{"tool":"get_weather","args":"{\"city\":\"Hanoi\"}","answer":""}`,
+			want:    `{"tool":"get_weather","args":{"city":"Hanoi"}}`,
+		},
+		"tool without arguments": {
+			content: `{"tool":"list_alerts","args":"","answer":""}`,
+			want:    `{"tool":"list_alerts","args":{}}`,
+		},
+		"answer": {
+			content: `{"tool":"","args":"{}","answer":"It is sunny."}`,
+			want:    "It is sunny.",
+		},
+		"unknown tool falls back to the answer": {
+			content: `{"tool":"delete_everything","args":"{}","answer":"I cannot do that."}`,
+			want:    "I cannot do that.",
+		},
+		"free text is left for legacy detection": {
+			content: "It is sunny.",
+			want:    "It is sunny.",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			choice := &llms.ContentChoice{Content: tt.content}
+			bridge.applyStructuredToolCall(choice)
+			assert.Equal(t, tt.want, choice.Content)
+		})
+	}
+
+	// The rewritten tool call is picked up by the regular detection
+	choice := &llms.ContentChoice{Content: `{"tool":"get_weather","args":"{\"city\":\"Hanoi\"}","answer":""}`}
+	bridge.applyStructuredToolCall(choice)
+	toolCall := bridge.detectSpecificJSONToolCall(choice.Content)
+	if assert.NotNil(t, toolCall) {
+		assert.Equal(t, "get_weather", toolCall.Tool)
+	}
+}
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+)
+
+// applyToolOverride merges the configured description, examples and argument hints
+// for a tool into the description and schema presented to the LLM. The tool's
+// schema is copied before it is modified.
+func applyToolOverride(tool mcp.ToolInfo, override config.MCPToolOverride) mcp.ToolInfo {
+	if override.Description != "" {
+		tool.ToolDescription = override.Description
+	}
+	if len(override.Examples) > 0 {
+		var builder strings.Builder
+		builder.WriteString(tool.ToolDescription)
+		builder.WriteString("\nExamples:")
+		for _, example := range override.Examples {
+			builder.WriteString("\n- ")
+			builder.WriteString(example)
+		}
+		tool.ToolDescription = builder.String()
+	}
+
+	if len(override.Arguments) > 0 {
+		schema := make(map[string]interface{}, len(tool.InputSchema))
+		for k, v := range tool.InputSchema {
+			schema[k] = v
+		}
+		originalProperties, _ := schema["properties"].(map[string]interface{})
+		properties := make(map[string]interface{}, len(originalProperties))
+		for k, v := range originalProperties {
+			properties[k] = v
+		}
+		for argName, hint := range override.Arguments {
+			originalProperty, _ := properties[argName].(map[string]interface{})
+			property := make(map[string]interface{}, len(originalProperty)+1)
+			for k, v := range originalProperty {
+				property[k] = v
+			}
+			if existing, _ := property["description"].(string); existing != "" {
+				property["description"] = existing + " (" + hint + ")"
+			} else {
+				property["description"] = hint
+			}
+			properties[argName] = property
+		}
+		schema["properties"] = properties
+		tool.InputSchema = schema
+	}
+
+	// Drop any schema serialized before the override was applied
+	tool.InputSchemaBytes = nil
+	return tool
+}
+
+// applyArgDefaults sets the argument defaults of a tool and tells the LLM about
+// them in the tool's schema, where the defaulted arguments become optional. Server
+// defaults only apply to tools that declare the argument; the tool's own defaults
+// replace them.
+func applyArgDefaults(tool mcp.ToolInfo, serverDefaults, toolDefaults map[string]interface{}) mcp.ToolInfo {
+	originalProperties, _ := tool.InputSchema["properties"].(map[string]interface{})
+	defaults := make(map[string]interface{}, len(serverDefaults)+len(toolDefaults))
+	for argName, value := range serverDefaults {
+		if _, declared := originalProperties[argName]; declared {
+			defaults[argName] = value
+		}
+	}
+	for argName, value := range toolDefaults {
+		defaults[argName] = value
+	}
+	if len(defaults) == 0 {
+		return tool
+	}
+	tool.ArgDefaults = defaults
+
+	schema := make(map[string]interface{}, len(tool.InputSchema))
+	for k, v := range tool.InputSchema {
+		schema[k] = v
+	}
+	properties := make(map[string]interface{}, len(originalProperties))
+	for k, v := range originalProperties {
+		properties[k] = v
+	}
+	for argName, value := range defaults {
+		originalProperty, ok := properties[argName].(map[string]interface{})
+		if !ok {
+			continue // Not declared by the tool, the LLM does not need to know
+		}
+		property := make(map[string]interface{}, len(originalProperty)+1)
+		for k, v := range originalProperty {
+			property[k] = v
+		}
+		hint := "defaults to " + describeArgDefault(value) + " when omitted"
+		if existing, _ := property["description"].(string); existing != "" {
+			property["description"] = existing + " (" + hint + ")"
+		} else {
+			property["description"] = hint
+		}
+		properties[argName] = property
+	}
+	schema["properties"] = properties
+	switch required := schema["required"].(type) {
+	case []interface{}:
+		schema["required"] = slices.DeleteFunc(slices.Clone(required), func(argName interface{}) bool {
+			name, _ := argName.(string)
+			_, defaulted := defaults[name]
+			return defaulted
+		})
+	case []string:
+		schema["required"] = slices.DeleteFunc(slices.Clone(required), func(argName string) bool {
+			_, defaulted := defaults[argName]
+			return defaulted
+		})
+	}
+	tool.InputSchema = schema
+	tool.InputSchemaBytes = nil
+	return tool
+}
+
+// applyArgFixed sets the fixed arguments of a tool and removes them from the
+// tool's schema, so the LLM cannot choose their values. Server fixed arguments
+// only apply to tools that declare the argument; the tool's own replace them.
+func applyArgFixed(tool mcp.ToolInfo, serverFixed, toolFixed map[string]interface{}) mcp.ToolInfo {
+	originalProperties, _ := tool.InputSchema["properties"].(map[string]interface{})
+	fixed := make(map[string]interface{}, len(serverFixed)+len(toolFixed))
+	for argName, value := range serverFixed {
+		if _, declared := originalProperties[argName]; declared {
+			fixed[argName] = value
+		}
+	}
+	for argName, value := range toolFixed {
+		fixed[argName] = value
+	}
+	if len(fixed) == 0 {
+		return tool
+	}
+	tool.ArgFixed = fixed
+
+	schema := make(map[string]interface{}, len(tool.InputSchema))
+	for k, v := range tool.InputSchema {
+		schema[k] = v
+	}
+	if originalProperties != nil {
+		properties := make(map[string]interface{}, len(originalProperties))
+		for k, v := range originalProperties {
+			if _, isFixed := fixed[k]; !isFixed {
+				properties[k] = v
+			}
+		}
+		schema["properties"] = properties
+	}
+	switch required := schema["required"].(type) {
+	case []interface{}:
+		schema["required"] = slices.DeleteFunc(slices.Clone(required), func(argName interface{}) bool {
+			name, _ := argName.(string)
+			_, isFixed := fixed[name]
+			return isFixed
+		})
+	case []string:
+		schema["required"] = slices.DeleteFunc(slices.Clone(required), func(argName string) bool {
+			_, isFixed := fixed[argName]
+			return isFixed
+		})
+	}
+	tool.InputSchema = schema
+	tool.InputSchemaBytes = nil
+	return tool
+}
+
+// describeArgDefault describes an argument default for the LLM, naming the context
+// variables it references
+func describeArgDefault(value interface{}) string {
+	if text, ok := value.(string); ok {
+		if config.ContextVarPattern.MatchString(text) {
+			return "the request's " + config.ContextVarPattern.ReplaceAllString(text, "$1")
+		}
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(encoded)
+}
+
+// enrichTool sets the tool's call timeout and argument defaults, and applies its
+// override from its server configuration, if any
+func (b *LLMMCPBridge) enrichTool(tool mcp.ToolInfo) mcp.ToolInfo {
+	if b.cfg == nil {
+		return tool
+	}
+	tool.Timeout = b.cfg.GetToolTimeout(tool.ServerName, tool.RemoteName)
+	serverConf, ok := b.cfg.MCPServers[tool.ServerName]
+	if !ok {
+		return tool
+	}
+	override, ok := serverConf.Tools.Overrides[tool.RemoteName]
+	tool = applyArgDefaults(tool, serverConf.Tools.Defaults, override.Defaults)
+	tool = applyArgFixed(tool, serverConf.Tools.Fixed, override.Fixed)
+	if !ok {
+		return tool
+	}
+	b.logger.DebugKV("Applying tool override", "tool", tool.ToolName, "server", tool.ServerName)
+	return applyToolOverride(tool, override)
+}
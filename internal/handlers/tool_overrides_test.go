@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+)
+
+func TestApplyToolOverride(t *testing.T) {
+	original := mcp.ToolInfo{
+		ServerName:      "github",
+		ToolName:        "github_search",
+		RemoteName:      "search",
+		ToolDescription: "Search",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"q":    map[string]interface{}{"type": "string", "description": "Query"},
+				"repo": map[string]interface{}{"type": "string"},
+			},
+		},
+		InputSchemaBytes: []byte(`{}`),
+	}
+
+	enriched := applyToolOverride(original, config.MCPToolOverride{
+		Description: "Search GitHub issues and pull requests",
+		Examples:    []string{`{"q": "is:open label:bug"}`},
+		Arguments:   map[string]string{"q": "GitHub search syntax", "repo": "owner/name"},
+	})
+
+	assert.Equal(t, "Search GitHub issues and pull requests\nExamples:\n- {\"q\": \"is:open label:bug\"}", enriched.ToolDescription)
+	properties := enriched.InputSchema["properties"].(map[string]interface{})
+	assert.Equal(t, "Query (GitHub search syntax)", properties["q"].(map[string]interface{})["description"])
+	assert.Equal(t, "owner/name", properties["repo"].(map[string]interface{})["description"])
+	assert.Equal(t, "string", properties["repo"].(map[string]interface{})["type"])
+	assert.Nil(t, enriched.InputSchemaBytes)
+
+	// The original schema is not modified
+	originalProperties := original.InputSchema["properties"].(map[string]interface{})
+	assert.Equal(t, "Query", originalProperties["q"].(map[string]interface{})["description"])
+	assert.NotContains(t, originalProperties["repo"], "description")
+	assert.Equal(t, "Search", original.ToolDescription)
+}
+
+func TestEnrichToolWithoutOverride(t *testing.T) {
+	bridge := &LLMMCPBridge{cfg: &config.Config{MCPServers: map[string]config.MCPServerConfig{"github": {}}}}
+	tool := mcp.ToolInfo{ServerName: "github", RemoteName: "search", ToolDescription: "Search"}
+	assert.Equal(t, "Search", bridge.enrichTool(tool).ToolDescription)
+}
+
+func TestEnrichToolArgDefaults(t *testing.T) {
+	bridge := &LLMMCPBridge{logger: logging.New("test", logging.LevelError), cfg: &config.Config{MCPServers: map[string]config.MCPServerConfig{
+		"jira": {Tools: config.MCPToolsConfig{
+			Defaults: map[string]interface{}{"reporter": "${user.email}", "channel": "${slack.channel_id}"},
+			Overrides: map[string]config.MCPToolOverride{
+				"create_issue": {Defaults: map[string]interface{}{"reporter": "bot@example.com", "labels": []interface{}{"slack"}}},
+			},
+		}},
+	}}}
+	original := mcp.ToolInfo{
+		ServerName: "jira",
+		ToolName:   "create_issue",
+		RemoteName: "create_issue",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"summary":  map[string]interface{}{"type": "string"},
+				"reporter": map[string]interface{}{"type": "string", "description": "Reporter email"},
+				"labels":   map[string]interface{}{"type": "array"},
+			},
+			"required": []interface{}{"summary", "reporter"},
+		},
+	}
+
+	enriched := bridge.enrichTool(original)
+	// The server's channel default is left out, the tool does not declare it
+	assert.Equal(t, map[string]interface{}{"reporter": "bot@example.com", "labels": []interface{}{"slack"}}, enriched.ArgDefaults)
+	properties := enriched.InputSchema["properties"].(map[string]interface{})
+	assert.Equal(t, `Reporter email (defaults to "bot@example.com" when omitted)`, properties["reporter"].(map[string]interface{})["description"])
+	assert.Equal(t, `defaults to ["slack"] when omitted`, properties["labels"].(map[string]interface{})["description"])
+	assert.Equal(t, []interface{}{"summary"}, enriched.InputSchema["required"])
+	assert.Equal(t, []interface{}{"summary", "reporter"}, original.InputSchema["required"])
+
+	// Tools without an override get the server's defaults
+	original.ToolName, original.RemoteName = "update_issue", "update_issue"
+	enriched = bridge.enrichTool(original)
+	assert.Equal(t, map[string]interface{}{"reporter": "${user.email}"}, enriched.ArgDefaults)
+	properties = enriched.InputSchema["properties"].(map[string]interface{})
+	assert.Equal(t, "Reporter email (defaults to the request's user.email when omitted)", properties["reporter"].(map[string]interface{})["description"])
+}
+
+func TestEnrichToolArgFixed(t *testing.T) {
+	bridge := &LLMMCPBridge{logger: logging.New("test", logging.LevelError), cfg: &config.Config{MCPServers: map[string]config.MCPServerConfig{
+		"jira": {Tools: config.MCPToolsConfig{
+			Defaults: map[string]interface{}{"reporter": "bot@example.com"},
+			Fixed:    map[string]interface{}{"reporter": "${user.email}", "channel": "${slack.channel_id}"},
+		}},
+	}}}
+	original := mcp.ToolInfo{
+		ServerName: "jira",
+		ToolName:   "create_issue",
+		RemoteName: "create_issue",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"summary":  map[string]interface{}{"type": "string"},
+				"reporter": map[string]interface{}{"type": "string"},
+			},
+			"required": []interface{}{"summary", "reporter"},
+		},
+	}
+
+	enriched := bridge.enrichTool(original)
+	// The server's channel is left out, the tool does not declare it
+	assert.Equal(t, map[string]interface{}{"reporter": "${user.email}"}, enriched.ArgFixed)
+	assert.Equal(t, map[string]interface{}{"summary": map[string]interface{}{"type": "string"}}, enriched.InputSchema["properties"])
+	assert.Equal(t, []interface{}{"summary"}, enriched.InputSchema["required"])
+	assert.Contains(t, original.InputSchema["properties"], "reporter")
+}
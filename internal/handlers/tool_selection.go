@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+	"github.com/tuannvm/slack-mcp-client/internal/toolselect"
+)
+
+// selectedToolsContextKey is the context key for the tools chosen for a request
+type selectedToolsContextKey struct{}
+
+// SetToolSelector enables embedding-based pre-filtering of the tools sent to the LLM
+func (b *LLMMCPBridge) SetToolSelector(selector *toolselect.Selector) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.toolSelector = selector
+}
+
+// IndexTools embeds the descriptions of all current tools so the first request
+// does not pay for it. It is a no-op when tool selection is disabled.
+func (b *LLMMCPBridge) IndexTools(ctx context.Context) error {
+	b.mu.RLock()
+	selector := b.toolSelector
+	b.mu.RUnlock()
+	if selector == nil {
+		return nil
+	}
+	return selector.Index(ctx, b.getAvailableTools())
+}
+
+// SelectTools picks the tools most relevant to the user's message and returns a
+// context that limits the tool prompt and native tool list to them. On failure, or
+// when tool selection is disabled, every tool stays available.
+func (b *LLMMCPBridge) SelectTools(ctx context.Context, query, channelID string) context.Context {
+	b.mu.RLock()
+	selector := b.toolSelector
+	b.mu.RUnlock()
+	if selector == nil {
+		return ctx
+	}
+
+	selection := b.cfg.LLM.ToolSelection
+	availableTools := b.getAvailableTools()
+	names, err := selector.Select(ctx, query, availableTools, selection.TopKForChannel(channelID), selection.AlwaysInclude)
+	if err != nil {
+		b.logger.WarnKV("Tool selection failed, sending all tools", "error", err)
+		return ctx
+	}
+
+	b.logger.DebugKV("Selected tools for request", "channel", channelID, "selected", len(names), "total", len(availableTools))
+	return context.WithValue(ctx, selectedToolsContextKey{}, names)
+}
+
+// toolsFor returns the tools offered to the LLM for the request in ctx
+func (b *LLMMCPBridge) toolsFor(ctx context.Context) map[string]mcp.ToolInfo {
+	availableTools := b.getAvailableTools()
+	names, ok := ctx.Value(selectedToolsContextKey{}).([]string)
+	if !ok {
+		return availableTools
+	}
+
+	selected := make(map[string]mcp.ToolInfo, len(names))
+	for _, name := range names {
+		if tool, exists := availableTools[name]; exists {
+			selected[name] = tool
+		}
+	}
+	return selected
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+)
+
+func TestToolsForSelectedContext(t *testing.T) {
+	tools := map[string]mcp.ToolInfo{
+		"get_weather": {ToolName: "get_weather", ToolDescription: "Get the weather"},
+		"list_issues": {ToolName: "list_issues", ToolDescription: "List issues"},
+	}
+	bridge := NewLLMMCPBridge(map[string]mcp.MCPClientInterface{}, log.New(os.Stderr, "", 0), tools, nil, &config.Config{})
+
+	// Without a selection every tool is offered
+	assert.Len(t, bridge.toolsFor(context.Background()), 2)
+	// SelectTools is a no-op without a selector
+	ctx := bridge.SelectTools(context.Background(), "weather", "C123")
+	assert.Len(t, bridge.toolsFor(ctx), 2)
+
+	ctx = context.WithValue(context.Background(), selectedToolsContextKey{}, []string{"get_weather", "removed_tool"})
+	assert.Equal(t, []string{"get_weather"}, keys(bridge.toolsFor(ctx)))
+
+	prompt := bridge.generateToolPrompt(ctx)
+	assert.Contains(t, prompt, "Tool Name: get_weather")
+	assert.NotContains(t, prompt, "list_issues")
+}
+
+func keys(tools map[string]mcp.ToolInfo) []string {
+	names := make([]string, 0, len(tools))
+	for name := range tools {
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,265 @@
+// Package hooks runs user-supplied executables and webhooks at defined points of a
+// request: when a message is received, before a tool is called and before a
+// response is posted. Each hook receives a JSON payload and answers with a JSON
+// decision that allows, denies or modifies what is about to happen, so custom
+// policy needs no recompiling.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+)
+
+// Actions of a hook decision
+const (
+	ActionAllow  = "allow"
+	ActionDeny   = "deny"
+	ActionModify = "modify"
+)
+
+// maxOutput bounds how much of a hook's answer is read
+const maxOutput = 1 << 20
+
+// errOutputTooLarge stops a command hook writing more than maxOutput bytes
+var errOutputTooLarge = fmt.Errorf("output exceeds %d bytes", maxOutput)
+
+// Payload is the JSON document sent to a hook
+type Payload struct {
+	Event     string                 `json:"event"`
+	Hook      string                 `json:"hook"`
+	UserID    string                 `json:"userId,omitempty"`
+	UserEmail string                 `json:"userEmail,omitempty"`
+	Channel   string                 `json:"channel,omitempty"`
+	ThreadTS  string                 `json:"threadTs,omitempty"`
+	Text      string                 `json:"text,omitempty"`   // The message or response (message_received, after_response)
+	Tool      string                 `json:"tool,omitempty"`   // before_tool_call
+	Server    string                 `json:"server,omitempty"` // before_tool_call
+	Args      map[string]interface{} `json:"args,omitempty"`   // before_tool_call
+}
+
+// Decision is a hook's JSON answer. An empty answer allows the event unchanged.
+type Decision struct {
+	Action  string                 `json:"action"`            // "allow" (default), "deny" or "modify"
+	Message string                 `json:"message,omitempty"` // Why the event was denied, shown to the user
+	Text    *string                `json:"text,omitempty"`    // Replacement message or response (modify)
+	Args    map[string]interface{} `json:"args,omitempty"`    // Replacement tool arguments (modify)
+}
+
+// Result is the outcome of running all hooks of an event
+type Result struct {
+	Denied  bool
+	Message string                 // Denial reason, empty when the hook gave none
+	Text    string                 // Message or response to use
+	Args    map[string]interface{} // Tool arguments to use
+}
+
+// Runner runs the configured hooks. A nil Runner allows every event.
+type Runner struct {
+	hooks      []config.HookConfig
+	httpClient *http.Client
+	logger     *logging.Logger
+}
+
+// NewRunner creates a runner of the hooks, which are validated with the configuration
+func NewRunner(hooks []config.HookConfig, logger *logging.Logger) *Runner {
+	return &Runner{hooks: hooks, httpClient: &http.Client{}, logger: logger}
+}
+
+// Handles reports whether any hook subscribes to the event
+func (r *Runner) Handles(event string) bool {
+	if r == nil {
+		return false
+	}
+	for _, hook := range r.hooks {
+		if slices.Contains(hook.Events, event) {
+			return true
+		}
+	}
+	return false
+}
+
+type conversationContextKey struct{}
+
+type conversation struct {
+	channelID string
+	threadTS  string
+}
+
+// ContextWithConversation returns a context whose hook payloads name the conversation
+func ContextWithConversation(ctx context.Context, channelID, threadTS string) context.Context {
+	return context.WithValue(ctx, conversationContextKey{}, conversation{channelID: channelID, threadTS: threadTS})
+}
+
+// ConversationFromContext returns the channel and thread set by ContextWithConversation
+func ConversationFromContext(ctx context.Context) (channelID, threadTS string, ok bool) {
+	conv, ok := ctx.Value(conversationContextKey{}).(conversation)
+	return conv.channelID, conv.threadTS, ok
+}
+
+// Run runs the hooks subscribed to payload.Event in order. A hook that modifies the
+// payload passes the modified text or arguments to the next one; the first denial
+// stops the event.
+func (r *Runner) Run(ctx context.Context, payload Payload) Result {
+	result := Result{Text: payload.Text, Args: payload.Args}
+	if r == nil {
+		return result
+	}
+	if identity, ok := mcp.IdentityFromContext(ctx); ok {
+		payload.UserID, payload.UserEmail = identity.UserID, identity.Email
+	}
+	if conv, ok := ctx.Value(conversationContextKey{}).(conversation); ok {
+		payload.Channel, payload.ThreadTS = conv.channelID, conv.threadTS
+	}
+
+	for _, hook := range r.hooks {
+		if !slices.Contains(hook.Events, payload.Event) {
+			continue
+		}
+		payload.Hook = hook.Name
+		payload.Text, payload.Args = result.Text, result.Args
+
+		decision, err := r.call(ctx, hook, payload)
+		if err != nil {
+			r.logger.WarnKV("Hook failed", "hook", hook.Name, "event", payload.Event, "on_error", hook.OnError, "error", err)
+			if hook.OnError == config.HookOnErrorDeny {
+				return Result{Denied: true}
+			}
+			continue
+		}
+		switch decision.Action {
+		case ActionAllow, "":
+		case ActionDeny:
+			r.logger.InfoKV("Hook denied event", "hook", hook.Name, "event", payload.Event, "user", payload.UserID, "reason", decision.Message)
+			return Result{Denied: true, Message: decision.Message}
+		case ActionModify:
+			r.logger.DebugKV("Hook modified event", "hook", hook.Name, "event", payload.Event)
+			if decision.Text != nil {
+				result.Text = *decision.Text
+			}
+			if decision.Args != nil {
+				result.Args = decision.Args
+			}
+		default:
+			r.logger.WarnKV("Hook returned an unknown action", "hook", hook.Name, "event", payload.Event, "action", decision.Action)
+			if hook.OnError == config.HookOnErrorDeny {
+				return Result{Denied: true}
+			}
+		}
+	}
+	return result
+}
+
+// call runs one hook and decodes its decision
+func (r *Runner) call(ctx context.Context, hook config.HookConfig, payload Payload) (Decision, error) {
+	timeout, err := time.ParseDuration(hook.Timeout)
+	if err != nil {
+		return Decision{}, fmt.Errorf("invalid timeout: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return Decision{}, fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	var output []byte
+	if hook.Command != "" {
+		output, err = runCommand(ctx, hook, body)
+	} else {
+		output, err = r.post(ctx, hook, body)
+	}
+	if err != nil {
+		return Decision{}, err
+	}
+
+	var decision Decision
+	if len(bytes.TrimSpace(output)) == 0 {
+		return decision, nil
+	}
+	if err := json.Unmarshal(output, &decision); err != nil {
+		return Decision{}, fmt.Errorf("invalid decision: %w", err)
+	}
+	return decision, nil
+}
+
+// runCommand runs an executable hook with the payload on stdin and returns its stdout
+func runCommand(ctx context.Context, hook config.HookConfig, payload []byte) ([]byte, error) {
+	// A hook writing past maxOutput is killed rather than buffered
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	cmd := exec.CommandContext(runCtx, hook.Command, hook.Args...)
+	cmd.Stdin = bytes.NewReader(payload)
+	stdout := &limitedWriter{limit: maxOutput, exceeded: cancel}
+	stderr := &limitedWriter{limit: maxOutput, exceeded: cancel}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		switch {
+		case stdout.overflowed || stderr.overflowed:
+			return nil, errOutputTooLarge
+		case errors.Is(ctx.Err(), context.DeadlineExceeded):
+			return nil, fmt.Errorf("timed out after %s", hook.Timeout)
+		case ctx.Err() != nil:
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("%w: %s", err, logging.TruncateForLog(strings.TrimSpace(stderr.buf.String()), 300))
+	}
+	return stdout.buf.Bytes(), nil
+}
+
+// limitedWriter buffers up to limit bytes. The write that would pass the limit
+// fails and calls exceeded.
+type limitedWriter struct {
+	buf        bytes.Buffer
+	limit      int
+	exceeded   func()
+	overflowed bool
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if w.buf.Len()+len(p) > w.limit {
+		w.overflowed = true
+		w.exceeded()
+		return 0, errOutputTooLarge
+	}
+	return w.buf.Write(p)
+}
+
+// post sends the payload to a webhook hook and returns the response body
+func (r *Runner) post(ctx context.Context, hook config.HookConfig, payload []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range hook.Headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxOutput))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("webhook returned %s: %s", resp.Status, logging.TruncateForLog(strings.TrimSpace(string(body)), 300))
+	}
+	return body, nil
+}
@@ -0,0 +1,168 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+	"github.com/tuannvm/slack-mcp-client/pkg/middleware"
+)
+
+// TestHelperHook is not a real test: the command hook tests run the test binary as
+// a hook that upper-cases the text it receives
+func TestHelperHook(t *testing.T) {
+	if os.Getenv("HOOK_HELPER") != "1" {
+		t.Skip("helper process")
+	}
+	var payload Payload
+	if err := json.NewDecoder(os.Stdin).Decode(&payload); err != nil {
+		os.Exit(2)
+	}
+	text := strings.ToUpper(payload.Text) + " (" + payload.UserID + " in " + payload.Channel + ")"
+	_ = json.NewEncoder(os.Stdout).Encode(Decision{Action: ActionModify, Text: &text})
+	os.Exit(0)
+}
+
+// TestHelperFloodHook is not a real test: it writes to stdout until it is stopped
+func TestHelperFloodHook(t *testing.T) {
+	if os.Getenv("HOOK_HELPER") != "flood" {
+		t.Skip("helper process")
+	}
+	chunk := []byte(strings.Repeat("x", 64<<10))
+	for {
+		if _, err := os.Stdout.Write(chunk); err != nil {
+			os.Exit(1)
+		}
+	}
+}
+
+func testContext() context.Context {
+	ctx := mcp.ContextWithIdentity(context.Background(), mcp.Identity{UserID: "U1", Email: "a@example.com"})
+	return ContextWithConversation(ctx, "C1", "100.1")
+}
+
+func newTestRunner(hooks ...config.HookConfig) *Runner {
+	for i := range hooks {
+		if hooks[i].Timeout == "" {
+			hooks[i].Timeout = "5s"
+		}
+		if hooks[i].OnError == "" {
+			hooks[i].OnError = config.HookOnErrorAllow
+		}
+	}
+	return NewRunner(hooks, logging.New("test", logging.LevelError))
+}
+
+func TestCommandHook(t *testing.T) {
+	t.Setenv("HOOK_HELPER", "1")
+	runner := newTestRunner(config.HookConfig{
+		Name:    "upper",
+		Events:  []string{config.HookEventMessageReceived},
+		Command: os.Args[0],
+		Args:    []string{"-test.run=^TestHelperHook$"},
+	})
+
+	result := runner.Run(testContext(), Payload{Event: config.HookEventMessageReceived, Text: "hello"})
+	assert.False(t, result.Denied)
+	assert.Equal(t, "HELLO (U1 in C1)", result.Text)
+
+	// Hooks only run for their events
+	result = runner.Run(testContext(), Payload{Event: config.HookEventAfterResponse, Text: "hello"})
+	assert.Equal(t, "hello", result.Text)
+}
+
+func TestWebhookDecisions(t *testing.T) {
+	var received []Payload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		var payload Payload
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		received = append(received, payload)
+		switch {
+		case strings.Contains(payload.Text, "password"):
+			_, _ = fmt.Fprint(w, `{"action": "deny", "message": "Please do not share passwords."}`)
+		case payload.Tool == "deploy":
+			_, _ = fmt.Fprint(w, `{"action": "modify", "args": {"env": "staging"}}`)
+		}
+	}))
+	defer server.Close()
+
+	runner := newTestRunner(config.HookConfig{
+		Name:    "policy",
+		Events:  []string{config.HookEventMessageReceived, config.HookEventBeforeToolCall},
+		URL:     server.URL,
+		Headers: map[string]string{"Authorization": "Bearer secret"},
+	})
+
+	result := runner.Run(testContext(), Payload{Event: config.HookEventMessageReceived, Text: "hi"})
+	assert.False(t, result.Denied, "an empty answer allows the event")
+	assert.Equal(t, "hi", result.Text)
+
+	result = runner.Run(testContext(), Payload{Event: config.HookEventMessageReceived, Text: "my password is hunter2"})
+	assert.True(t, result.Denied)
+	assert.Equal(t, "Please do not share passwords.", result.Message)
+
+	require.Len(t, received, 2)
+	assert.Equal(t, "policy", received[0].Hook)
+	assert.Equal(t, "a@example.com", received[0].UserEmail)
+	assert.Equal(t, "100.1", received[0].ThreadTS)
+
+	// Tool call hooks run as a middleware
+	chain := middleware.NewChain(runner.Middleware())
+	output, err := chain.RunTool(testContext(), &middleware.ToolCall{Tool: "deploy", Args: map[string]interface{}{"env": "prod"}},
+		func(_ context.Context, call *middleware.ToolCall) (string, error) {
+			return "deployed to " + call.Args["env"].(string), nil
+		})
+	require.NoError(t, err)
+	assert.Equal(t, "deployed to staging", output)
+	assert.Nil(t, newTestRunner().Middleware(), "no middleware without tool call hooks")
+}
+
+func TestHookFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	failing := config.HookConfig{Name: "flaky", Events: []string{config.HookEventAfterResponse}, URL: server.URL}
+	result := newTestRunner(failing).Run(testContext(), Payload{Event: config.HookEventAfterResponse, Text: "answer"})
+	assert.False(t, result.Denied, "failures allow the event by default")
+	assert.Equal(t, "answer", result.Text)
+
+	failing.OnError = config.HookOnErrorDeny
+	result = newTestRunner(failing).Run(testContext(), Payload{Event: config.HookEventAfterResponse, Text: "answer"})
+	assert.True(t, result.Denied)
+
+	var nilRunner *Runner
+	assert.False(t, nilRunner.Handles(config.HookEventAfterResponse))
+	assert.Equal(t, "answer", nilRunner.Run(context.Background(), Payload{Text: "answer"}).Text)
+}
+
+func TestCommandHookFailures(t *testing.T) {
+	t.Setenv("HOOK_HELPER", "flood")
+	flood := config.HookConfig{Name: "flood", Command: os.Args[0], Args: []string{"-test.run=^TestHelperFloodHook$"}, Timeout: "5s"}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := runCommand(ctx, flood, []byte("{}"))
+	assert.ErrorIs(t, err, errOutputTooLarge)
+	require.NoError(t, ctx.Err(), "the hook is stopped when its output passes the limit")
+
+	// A cancelled request is not reported as a timeout
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	_, err = runCommand(ctx, flood, []byte("{}"))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NotContains(t, err.Error(), "timed out")
+}
@@ -0,0 +1,38 @@
+package hooks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/pkg/middleware"
+)
+
+// toolHooks runs the before_tool_call hooks as a bridge middleware
+type toolHooks struct {
+	middleware.Base
+	runner *Runner
+}
+
+// Middleware returns a middleware that runs the before_tool_call hooks, or nil
+// when no hook subscribes to them
+func (r *Runner) Middleware() middleware.Middleware {
+	if !r.Handles(config.HookEventBeforeToolCall) {
+		return nil
+	}
+	return toolHooks{runner: r}
+}
+
+func (h toolHooks) WrapTool(next middleware.ToolHandler) middleware.ToolHandler {
+	return func(ctx context.Context, call *middleware.ToolCall) (string, error) {
+		result := h.runner.Run(ctx, Payload{Event: config.HookEventBeforeToolCall, Tool: call.Tool, Server: call.Server, Args: call.Args})
+		if result.Denied {
+			if result.Message != "" {
+				return "", fmt.Errorf("tool call '%s' was denied by policy: %s", call.Tool, result.Message)
+			}
+			return "", fmt.Errorf("tool call '%s' was denied by policy", call.Tool)
+		}
+		call.Args = result.Args
+		return next(ctx, call)
+	}
+}
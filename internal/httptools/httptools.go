@@ -0,0 +1,223 @@
+// Package httptools exposes REST endpoints declared in the configuration as tools,
+// covering internal APIs that have no MCP server
+package httptools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+)
+
+const (
+	// ServerName identifies the HTTP tools client among the bridge's clients
+	ServerName = "httptools"
+
+	// maxResponse bounds how much of a response is read
+	maxResponse = 1 << 20
+)
+
+// placeholder matches the {name} argument placeholders of a URL template
+var placeholder = regexp.MustCompile(`\{([A-Za-z0-9_\-]+)\}`)
+
+// tool is a configured endpoint with its parsed settings
+type tool struct {
+	cfg     config.HTTPToolConfig
+	timeout time.Duration
+	extract []pathStep // nil when the whole response is returned
+}
+
+// Client calls the configured endpoints
+type Client struct {
+	tools      map[string]tool
+	httpClient *http.Client
+}
+
+// NewClient checks the tool definitions and returns a client that calls them
+func NewClient(tools map[string]config.HTTPToolConfig) (*Client, error) {
+	c := &Client{tools: make(map[string]tool, len(tools)), httpClient: &http.Client{}}
+	for name, cfg := range tools {
+		timeout, err := time.ParseDuration(cfg.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("http tool '%s': invalid timeout '%s': %w", name, cfg.Timeout, err)
+		}
+		t := tool{cfg: cfg, timeout: timeout}
+		if cfg.Extract != "" {
+			if t.extract, err = parsePath(cfg.Extract); err != nil {
+				return nil, fmt.Errorf("http tool '%s': %w", name, err)
+			}
+		}
+		c.tools[name] = t
+	}
+	return c, nil
+}
+
+// ToolInfos describes the configured tools to the bridge
+func ToolInfos(tools map[string]config.HTTPToolConfig) map[string]mcp.ToolInfo {
+	infos := make(map[string]mcp.ToolInfo, len(tools))
+	for name, cfg := range tools {
+		infos[name] = mcp.ToolInfo{
+			ToolName:        name,
+			ToolDescription: cfg.Description,
+			InputSchema:     cfg.InputSchema,
+			ServerName:      ServerName,
+		}
+	}
+	return infos
+}
+
+// CallTool implements the bridge's client interface
+func (c *Client) CallTool(ctx context.Context, toolName string, args map[string]interface{}) (string, error) {
+	t, ok := c.tools[toolName]
+	if !ok {
+		return "", fmt.Errorf("unknown HTTP tool: %s. Available tools: %s", toolName, strings.Join(c.toolNames(), ", "))
+	}
+
+	req, err := t.request(ctx, args)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+	resp, err := c.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", fmt.Errorf("request to %s failed: %w", req.URL.Redacted(), err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponse))
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("%s %s returned %s: %s", t.cfg.Method, req.URL.Redacted(), resp.Status, logging.TruncateForLog(strings.TrimSpace(string(body)), 500))
+	}
+	if t.extract == nil {
+		return string(body), nil
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return "", fmt.Errorf("response is not JSON, so '%s' cannot be extracted: %w", t.cfg.Extract, err)
+	}
+	switch result := extract(doc, t.extract).(type) {
+	case nil:
+		return "null", nil
+	case string:
+		return result, nil
+	default:
+		data, err := json.Marshal(result)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode extracted result: %w", err)
+		}
+		return string(data), nil
+	}
+}
+
+// request builds the request of a call: arguments fill the URL placeholders and
+// the rest become query parameters or the JSON body
+func (t tool) request(ctx context.Context, args map[string]interface{}) (*http.Request, error) {
+	remaining := make(map[string]interface{}, len(args))
+	for name, value := range args {
+		remaining[name] = value
+	}
+
+	var missing, invalid []string
+	target := placeholder.ReplaceAllStringFunc(t.cfg.URL, func(match string) string {
+		name := match[1 : len(match)-1]
+		value, ok := remaining[name]
+		if !ok || value == nil {
+			missing = append(missing, name)
+			return match
+		}
+		delete(remaining, name)
+		// PathEscape keeps dots, so "." and ".." would move the request to
+		// another path, still sent with the tool's headers
+		segment := argString(value)
+		if segment == "" || segment == "." || segment == ".." {
+			invalid = append(invalid, name)
+			return match
+		}
+		return url.PathEscape(segment)
+	})
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing required arguments: %s", strings.Join(missing, ", "))
+	}
+	if len(invalid) > 0 {
+		return nil, fmt.Errorf("arguments cannot be empty, \".\" or \"..\": %s", strings.Join(invalid, ", "))
+	}
+
+	queryArgs := make(map[string]interface{})
+	for name, value := range remaining {
+		if slices.Contains(t.cfg.Query, name) || t.cfg.Method == http.MethodGet || t.cfg.Method == http.MethodDelete {
+			queryArgs[name] = value
+			delete(remaining, name)
+		}
+	}
+	if len(queryArgs) > 0 {
+		u, err := url.Parse(target)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URL: %w", err)
+		}
+		query := u.Query()
+		for name, value := range queryArgs {
+			query.Set(name, argString(value))
+		}
+		u.RawQuery = query.Encode()
+		target = u.String()
+	}
+
+	var body io.Reader
+	if t.cfg.Method != http.MethodGet && t.cfg.Method != http.MethodDelete {
+		data, err := json.Marshal(remaining)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode body: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, t.cfg.Method, target, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, value := range t.cfg.Headers {
+		req.Header.Set(name, value)
+	}
+	return req, nil
+}
+
+// argString formats an argument for a URL: strings as they are, other values as JSON
+func argString(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+func (c *Client) toolNames() []string {
+	names := make([]string, 0, len(c.tools))
+	for name := range c.tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
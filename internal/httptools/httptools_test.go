@@ -0,0 +1,98 @@
+package httptools
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+func TestHTTPTools(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		switch {
+		case r.Method == http.MethodGet && r.URL.EscapedPath() == "/teams/platform%20eng/members":
+			assert.Equal(t, "active", r.URL.Query().Get("status"))
+			_, _ = io.WriteString(w, `{"members": [{"name": "Ana", "role": "lead"}, {"name": "Bo"}]}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/tickets":
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, map[string]interface{}{"title": "Disk full", "priority": float64(2)}, body)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = io.WriteString(w, `{"id": "T-7"}`)
+		default:
+			http.Error(w, "no such route", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	headers := map[string]string{"Authorization": "Bearer token"}
+	client, err := NewClient(map[string]config.HTTPToolConfig{
+		"team_members":  {Method: "GET", URL: server.URL + "/teams/{team}/members", Headers: headers, Extract: "$.members[*].name", Timeout: "5s"},
+		"team_lead":     {Method: "GET", URL: server.URL + "/teams/{team}/members", Headers: headers, Extract: "$.members[0]['name']", Timeout: "5s"},
+		"create_ticket": {Method: "POST", URL: server.URL + "/tickets", Headers: headers, Timeout: "5s"},
+		"missing":       {Method: "GET", URL: server.URL + "/nowhere", Headers: headers, Timeout: "5s"},
+	})
+	require.NoError(t, err)
+
+	result, err := client.CallTool(context.Background(), "team_members", map[string]interface{}{"team": "platform eng", "status": "active"})
+	require.NoError(t, err)
+	assert.Equal(t, `["Ana","Bo"]`, result)
+
+	result, err = client.CallTool(context.Background(), "team_lead", map[string]interface{}{"team": "platform eng", "status": "active"})
+	require.NoError(t, err)
+	assert.Equal(t, "Ana", result)
+
+	result, err = client.CallTool(context.Background(), "create_ticket", map[string]interface{}{"title": "Disk full", "priority": 2})
+	require.NoError(t, err)
+	assert.Equal(t, `{"id": "T-7"}`, result)
+
+	_, err = client.CallTool(context.Background(), "team_members", map[string]interface{}{})
+	assert.EqualError(t, err, "missing required arguments: team")
+
+	// Dot segments would reach another endpoint with the tool's headers
+	for _, team := range []string{"..", ".", ""} {
+		_, err = client.CallTool(context.Background(), "team_members", map[string]interface{}{"team": team})
+		assert.EqualError(t, err, `arguments cannot be empty, "." or "..": team`, team)
+	}
+
+	_, err = client.CallTool(context.Background(), "missing", nil)
+	assert.ErrorContains(t, err, "returned 404 Not Found: no such route")
+}
+
+func TestParsePath(t *testing.T) {
+	doc := map[string]interface{}{
+		"data": map[string]interface{}{
+			"items": []interface{}{
+				map[string]interface{}{"id": 1.0, "tags": []interface{}{"a", "b"}},
+				map[string]interface{}{"id": 2.0},
+			},
+		},
+	}
+	cases := map[string]interface{}{
+		"$":                       doc,
+		"$.data.items[-1].id":     2.0,
+		"$.data.items[*].id":      []interface{}{1.0, 2.0},
+		"$.data.items[*].tags":    []interface{}{[]interface{}{"a", "b"}},
+		"$['data'].items[5]":      nil,
+		"$.data.missing.id":       nil,
+		`$.data.items[0]["tags"]`: []interface{}{"a", "b"},
+	}
+	for path, want := range cases {
+		steps, err := parsePath(path)
+		require.NoError(t, err, path)
+		assert.Equal(t, want, extract(doc, steps), path)
+	}
+
+	for _, path := range []string{"data.items", "$.data[", "$..items", "$[name]"} {
+		_, err := parsePath(path)
+		assert.Error(t, err, path)
+	}
+}
@@ -0,0 +1,122 @@
+package httptools
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// pathStep is one step of a JSONPath: a field, an array index or a wildcard
+type pathStep struct {
+	field    string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// parsePath parses the JSONPath subset supported by extract: "$", ".field",
+// "['field']", "[n]" (negative counts from the end) and "[*]" or ".*"
+func parsePath(path string) ([]pathStep, error) {
+	rest := strings.TrimSpace(path)
+	if !strings.HasPrefix(rest, "$") {
+		return nil, fmt.Errorf("invalid JSONPath '%s': must start with $", path)
+	}
+	rest = rest[1:]
+
+	var steps []pathStep
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			name := rest[:end]
+			rest = rest[end:]
+			switch name {
+			case "":
+				return nil, fmt.Errorf("invalid JSONPath '%s': empty field name", path)
+			case "*":
+				steps = append(steps, pathStep{wildcard: true})
+			default:
+				steps = append(steps, pathStep{field: name})
+			}
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid JSONPath '%s': missing ]", path)
+			}
+			selector := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+			switch {
+			case selector == "*":
+				steps = append(steps, pathStep{wildcard: true})
+			case len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0]:
+				steps = append(steps, pathStep{field: selector[1 : len(selector)-1]})
+			default:
+				index, err := strconv.Atoi(selector)
+				if err != nil {
+					return nil, fmt.Errorf("invalid JSONPath '%s': unsupported selector [%s]", path, selector)
+				}
+				steps = append(steps, pathStep{index: index, isIndex: true})
+			}
+		default:
+			return nil, fmt.Errorf("invalid JSONPath '%s': unexpected '%c'", path, rest[0])
+		}
+	}
+	return steps, nil
+}
+
+// extract returns the part of a decoded JSON document the path selects. After a
+// wildcard the result is the list of matches; missing fields yield nil.
+func extract(doc interface{}, steps []pathStep) interface{} {
+	if len(steps) == 0 {
+		return doc
+	}
+	step, rest := steps[0], steps[1:]
+	switch {
+	case step.wildcard:
+		var items []interface{}
+		switch v := doc.(type) {
+		case []interface{}:
+			items = v
+		case map[string]interface{}:
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys) // Deterministic order for the LLM
+			for _, key := range keys {
+				items = append(items, v[key])
+			}
+		}
+		matches := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			if match := extract(item, rest); match != nil {
+				matches = append(matches, match)
+			}
+		}
+		return matches
+	case step.isIndex:
+		list, ok := doc.([]interface{})
+		if !ok {
+			return nil
+		}
+		index := step.index
+		if index < 0 {
+			index += len(list)
+		}
+		if index < 0 || index >= len(list) {
+			return nil
+		}
+		return extract(list[index], rest)
+	default:
+		object, ok := doc.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		return extract(object[step.field], rest)
+	}
+}
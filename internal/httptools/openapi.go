@@ -0,0 +1,322 @@
+package httptools
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+const (
+	// maxRefDepth bounds $ref resolution, which also cuts recursive schemas
+	maxRefDepth = 8
+	// maxToolName is the longest tool name LLM providers accept
+	maxToolName = 64
+	// maxDescription bounds the description imported from an operation
+	maxDescription = 1024
+)
+
+// openAPIMethods are the operations of a path item that can be imported
+var openAPIMethods = []string{"get", "post", "put", "patch", "delete"}
+
+// invalidToolNameChars matches characters that LLM providers reject in tool names
+var invalidToolNameChars = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// ImportOpenAPI loads an OpenAPI 3 document and returns its allowlisted operations
+// as HTTP tools, keyed by tool name. Operations that cannot be called with JSON
+// arguments, such as those with form bodies, are skipped and reported in skipped.
+func ImportOpenAPI(ctx context.Context, api config.OpenAPIConfig) (tools map[string]config.HTTPToolConfig, skipped []string, err error) {
+	data, err := readSpec(ctx, api.Spec)
+	if err != nil {
+		return nil, nil, err
+	}
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse OpenAPI document: %w", err)
+	}
+	if version, _ := doc["openapi"].(string); !strings.HasPrefix(version, "3.") {
+		return nil, nil, fmt.Errorf("unsupported OpenAPI document: expected version 3.x, got %q", version)
+	}
+
+	baseURL, err := resolveBaseURL(api, doc)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	paths, _ := doc["paths"].(map[string]interface{})
+	tools = make(map[string]config.HTTPToolConfig)
+	for _, route := range sortedKeys(paths) {
+		item, _ := resolve(doc, paths[route], 0).(map[string]interface{})
+		for _, method := range openAPIMethods {
+			operation, ok := item[method].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			operationID, _ := operation["operationId"].(string)
+			key := strings.ToUpper(method) + " " + route
+			if !allowed(api.Operations, operationID, key) {
+				continue
+			}
+			name := operationID
+			if name == "" {
+				name = method + "_" + route
+			}
+			name = toolName(api.ToolPrefix, name)
+
+			tool, err := operationTool(doc, item, operation, method, baseURL+route)
+			if err != nil {
+				skipped = append(skipped, fmt.Sprintf("%s (%s)", key, err))
+				continue
+			}
+			tool.Headers = api.Headers
+			tool.Timeout = api.Timeout
+			if _, exists := tools[name]; exists {
+				skipped = append(skipped, fmt.Sprintf("%s (duplicate tool name %s)", key, name))
+				continue
+			}
+			tools[name] = tool
+		}
+	}
+	return tools, skipped, nil
+}
+
+// readSpec reads the document from a URL or a file
+func readSpec(ctx context.Context, spec string) ([]byte, error) {
+	if !strings.HasPrefix(spec, "http://") && !strings.HasPrefix(spec, "https://") {
+		data, err := os.ReadFile(spec)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read OpenAPI document: %w", err)
+		}
+		return data, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, spec, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document URL: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch OpenAPI document: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch OpenAPI document: %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 10*maxResponse))
+}
+
+// resolveBaseURL returns the configured base URL or the document's first server,
+// resolved against the document's URL when relative
+func resolveBaseURL(api config.OpenAPIConfig, doc map[string]interface{}) (string, error) {
+	base := api.BaseURL
+	if base == "" {
+		if servers, ok := doc["servers"].([]interface{}); ok && len(servers) > 0 {
+			if server, ok := servers[0].(map[string]interface{}); ok {
+				base, _ = server["url"].(string)
+			}
+		}
+	}
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("invalid base URL '%s': %w", base, err)
+	}
+	if !u.IsAbs() {
+		specURL, err := url.Parse(api.Spec)
+		if err != nil || !specURL.IsAbs() {
+			return "", fmt.Errorf("the document has no absolute server URL; set baseUrl")
+		}
+		u = specURL.ResolveReference(u)
+	}
+	return strings.TrimRight(u.String(), "/"), nil
+}
+
+// allowed reports whether an operation matches the allowlist by operationId or "METHOD /path"
+func allowed(patterns []string, operationID, key string) bool {
+	for _, pattern := range patterns {
+		if operationID != "" {
+			if matched, _ := path.Match(pattern, operationID); matched {
+				return true
+			}
+		}
+		if pattern == key {
+			return true
+		}
+	}
+	return false
+}
+
+// toolName builds a tool name LLM providers accept from the prefix and the operation
+func toolName(prefix, operation string) string {
+	name := strings.Trim(invalidToolNameChars.ReplaceAllString(prefix+operation, "_"), "_")
+	if len(name) > maxToolName {
+		name = name[:maxToolName]
+	}
+	return name
+}
+
+// operationTool converts an operation into an HTTP tool without headers and timeout
+func operationTool(doc, item, operation map[string]interface{}, method, target string) (config.HTTPToolConfig, error) {
+	properties := map[string]interface{}{}
+	var required, query []string
+
+	// Operation parameters override path-level ones with the same name and location
+	parameters := map[string]map[string]interface{}{}
+	for _, source := range []interface{}{item["parameters"], operation["parameters"]} {
+		list, _ := source.([]interface{})
+		for _, p := range list {
+			parameter, ok := resolve(doc, p, 0).(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := parameter["name"].(string)
+			in, _ := parameter["in"].(string)
+			parameters[in+" "+name] = parameter
+		}
+	}
+	for _, key := range sortedKeys(parameters) {
+		parameter := parameters[key]
+		name, _ := parameter["name"].(string)
+		in, _ := parameter["in"].(string)
+		if in != "path" && in != "query" {
+			continue // Headers and cookies come from the configuration
+		}
+		schema, _ := resolve(doc, parameter["schema"], 0).(map[string]interface{})
+		if schema == nil {
+			schema = map[string]interface{}{"type": "string"}
+		}
+		schema = copySchema(schema)
+		if description, ok := parameter["description"].(string); ok && schema["description"] == nil {
+			schema["description"] = description
+		}
+		properties[name] = schema
+		if isRequired, _ := parameter["required"].(bool); isRequired || in == "path" {
+			required = append(required, name)
+		}
+		if in == "query" {
+			query = append(query, name)
+		}
+	}
+
+	if body, ok := resolve(doc, operation["requestBody"], 0).(map[string]interface{}); ok {
+		content, _ := body["content"].(map[string]interface{})
+		media, ok := content["application/json"].(map[string]interface{})
+		if !ok {
+			return config.HTTPToolConfig{}, fmt.Errorf("request body is not JSON")
+		}
+		schema, _ := resolve(doc, media["schema"], 0).(map[string]interface{})
+		bodyProperties, _ := schema["properties"].(map[string]interface{})
+		if bodyProperties == nil {
+			return config.HTTPToolConfig{}, fmt.Errorf("request body is not a JSON object")
+		}
+		for name, property := range bodyProperties {
+			if _, exists := properties[name]; exists {
+				return config.HTTPToolConfig{}, fmt.Errorf("body property '%s' clashes with a parameter", name)
+			}
+			properties[name] = resolve(doc, property, 0)
+		}
+		if bodyRequired, _ := body["required"].(bool); bodyRequired {
+			for _, name := range toStrings(schema["required"]) {
+				required = append(required, name)
+			}
+		}
+	}
+
+	inputSchema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		inputSchema["required"] = required
+	}
+	return config.HTTPToolConfig{
+		Description: operationDescription(operation, strings.ToUpper(method), target),
+		Method:      strings.ToUpper(method),
+		URL:         target,
+		InputSchema: inputSchema,
+		Query:       query,
+	}, nil
+}
+
+// operationDescription joins an operation's summary and description
+func operationDescription(operation map[string]interface{}, method, target string) string {
+	var parts []string
+	for _, field := range []string{"summary", "description"} {
+		if text, ok := operation[field].(string); ok && strings.TrimSpace(text) != "" {
+			parts = append(parts, strings.TrimSpace(text))
+		}
+	}
+	if len(parts) == 0 {
+		parts = append(parts, method+" "+target)
+	}
+	description := strings.Join(parts, "\n")
+	if len(description) > maxDescription {
+		description = description[:maxDescription] + "..."
+	}
+	return description
+}
+
+// resolve replaces local $ref references ("#/components/...") with what they point
+// to, recursively. References deeper than maxRefDepth become an untyped object.
+func resolve(doc map[string]interface{}, value interface{}, depth int) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if ref, ok := v["$ref"].(string); ok {
+			if depth >= maxRefDepth || !strings.HasPrefix(ref, "#/") {
+				return map[string]interface{}{"type": "object"}
+			}
+			var target interface{} = doc
+			for _, segment := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+				object, _ := target.(map[string]interface{})
+				target = object[strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~")]
+			}
+			return resolve(doc, target, depth+1)
+		}
+		resolved := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			resolved[key] = resolve(doc, item, depth)
+		}
+		return resolved
+	case []interface{}:
+		resolved := make([]interface{}, len(v))
+		for i, item := range v {
+			resolved[i] = resolve(doc, item, depth)
+		}
+		return resolved
+	default:
+		return value
+	}
+}
+
+func copySchema(schema map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(schema)+1)
+	for key, value := range schema {
+		copied[key] = value
+	}
+	return copied
+}
+
+func toStrings(value interface{}) []string {
+	list, _ := value.([]interface{})
+	strs := make([]string, 0, len(list))
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			strs = append(strs, s)
+		}
+	}
+	return strs
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
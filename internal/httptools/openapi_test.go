@@ -0,0 +1,165 @@
+package httptools
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+const petstoreSpec = `
+openapi: 3.0.3
+info:
+  title: Pets
+  version: "1"
+servers:
+  - url: /api
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      summary: List pets
+      parameters:
+        - $ref: '#/components/parameters/Limit'
+    post:
+      operationId: createPet
+      summary: Create a pet
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/NewPet'
+  /pets/{petId}:
+    parameters:
+      - name: petId
+        in: path
+        schema:
+          type: string
+    get:
+      operationId: getPet
+      description: Get a pet by ID
+    delete:
+      operationId: deletePet
+  /pets/{petId}/photo:
+    put:
+      operationId: uploadPhoto
+      parameters:
+        - name: petId
+          in: path
+          required: true
+          schema:
+            type: string
+      requestBody:
+        content:
+          image/png: {}
+components:
+  parameters:
+    Limit:
+      name: limit
+      in: query
+      description: Maximum pets returned
+      schema:
+        type: integer
+  schemas:
+    NewPet:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+        tags:
+          type: array
+          items:
+            type: string
+`
+
+func TestImportOpenAPI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/openapi.yaml":
+			assert.Empty(t, r.Header.Get("Authorization"), "the document is fetched without the API's credentials")
+			_, _ = io.WriteString(w, petstoreSpec)
+		case r.Method == http.MethodGet && r.URL.Path == "/api/pets":
+			assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+			assert.Equal(t, "2", r.URL.Query().Get("limit"))
+			_, _ = io.WriteString(w, `[{"name": "Rex"}]`)
+		case r.Method == http.MethodPost && r.URL.Path == "/api/pets":
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, map[string]interface{}{"name": "Tom"}, body)
+			_, _ = io.WriteString(w, `{"id": "p1"}`)
+		default:
+			http.Error(w, "no such route", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	api := config.OpenAPIConfig{
+		Spec:       server.URL + "/openapi.yaml",
+		Operations: []string{"*Pet*", "GET /pets/{petId}", "uploadPhoto"},
+		Headers:    map[string]string{"Authorization": "Bearer token"},
+		ToolPrefix: "pets_",
+		Timeout:    "5s",
+	}
+	tools, skipped, err := ImportOpenAPI(context.Background(), api)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"PUT /pets/{petId}/photo (request body is not JSON)"}, skipped)
+	require.Len(t, tools, 4)
+
+	list := tools["pets_listPets"]
+	assert.Equal(t, "GET", list.Method)
+	assert.Equal(t, server.URL+"/api/pets", list.URL)
+	assert.Equal(t, "List pets", list.Description)
+	assert.Equal(t, []string{"limit"}, list.Query)
+	assert.Equal(t, map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"limit": map[string]interface{}{"type": "integer", "description": "Maximum pets returned"},
+		},
+	}, list.InputSchema)
+
+	create := tools["pets_createPet"]
+	assert.Equal(t, "POST", create.Method)
+	assert.Equal(t, []string{"name"}, create.InputSchema["required"])
+	assert.Contains(t, create.InputSchema["properties"], "tags")
+
+	get := tools["pets_getPet"]
+	assert.Equal(t, server.URL+"/api/pets/{petId}", get.URL)
+	assert.Equal(t, "Get a pet by ID", get.Description)
+	assert.Equal(t, []string{"petId"}, get.InputSchema["required"])
+	assert.Contains(t, tools, "pets_deletePet")
+
+	client, err := NewClient(tools)
+	require.NoError(t, err)
+	result, err := client.CallTool(context.Background(), "pets_listPets", map[string]interface{}{"limit": 2})
+	require.NoError(t, err)
+	assert.Equal(t, `[{"name": "Rex"}]`, result)
+
+	result, err = client.CallTool(context.Background(), "pets_createPet", map[string]interface{}{"name": "Tom"})
+	require.NoError(t, err)
+	assert.Equal(t, `{"id": "p1"}`, result)
+}
+
+func TestImportOpenAPIRejectsSwagger(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, `{"swagger": "2.0", "paths": {}}`)
+	}))
+	defer server.Close()
+
+	_, _, err := ImportOpenAPI(context.Background(), config.OpenAPIConfig{Spec: server.URL, Operations: []string{"*"}})
+	assert.EqualError(t, err, `unsupported OpenAPI document: expected version 3.x, got ""`)
+}
+
+func TestToolName(t *testing.T) {
+	assert.Equal(t, "api_get_pets_petId", toolName("api_", "get_/pets/{petId}"))
+	long := toolName("", "a123456789b123456789c123456789d123456789e123456789f123456789g123456789")
+	assert.Len(t, long, maxToolName)
+}
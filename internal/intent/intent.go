@@ -0,0 +1,152 @@
+// Package intent classifies messages before the LLM is prompted, as plain chat, a
+// knowledge base question or a tool request, so each is prompted with only what it
+// needs.
+package intent
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+// Sources of a decision, reported in the intent metrics
+const (
+	SourceHeuristic = "heuristic"
+	SourceLLM       = "llm"
+	SourceFallback  = "fallback" // The LLM classifier failed or gave no intent
+)
+
+// classifierPrompt asks a model for the intent of a message; the options and the
+// message are filled in
+const classifierPrompt = "Classify the following Slack message by what is needed to answer it. " +
+	"Reply with only one word:\n%s\nMessage: %s"
+
+// maxPromptTools limits the tool names listed in the classifier prompt
+const maxPromptTools = 30
+
+// Decision is the intent of a message
+type Decision struct {
+	Intent string // config.IntentChat, config.IntentRAG or config.IntentTools
+	Source string // How the intent was decided
+}
+
+// CompleteFunc sends a prompt to the classifier model and returns its reply
+type CompleteFunc func(ctx context.Context, prompt string) (string, error)
+
+// Classifier decides the intent of messages from chat words and keywords, and asks
+// a model about the rest when it is configured to
+type Classifier struct {
+	cfg         config.LLMIntentConfig
+	chatWords   map[string]bool
+	ragKeywords *regexp.Regexp // nil when no keywords are configured
+	complete    CompleteFunc   // nil for the heuristic classifier
+}
+
+var (
+	// emojiPattern matches Slack emoji codes such as :+1: and user mentions
+	emojiPattern = regexp.MustCompile(`:[a-z0-9_+'-]+:|<[@#!][^>]*>`)
+	// wordPattern matches the words of a message
+	wordPattern = regexp.MustCompile(`[\p{L}\p{N}]+`)
+)
+
+// NewClassifier creates a Classifier from the intent configuration. complete is
+// only used by the llm classifier.
+func NewClassifier(cfg config.LLMIntentConfig, complete CompleteFunc) *Classifier {
+	classifier := &Classifier{cfg: cfg, chatWords: make(map[string]bool, len(cfg.ChatWords))}
+	for _, word := range cfg.ChatWords {
+		classifier.chatWords[strings.ToLower(strings.TrimSpace(word))] = true
+	}
+	var patterns []string
+	for _, keyword := range cfg.RAGKeywords {
+		if keyword = strings.TrimSpace(keyword); keyword != "" {
+			patterns = append(patterns, regexp.QuoteMeta(strings.ToLower(keyword)))
+		}
+	}
+	if len(patterns) > 0 {
+		classifier.ragKeywords = regexp.MustCompile(`\b(` + strings.Join(patterns, "|") + `)\b`)
+	}
+	if cfg.Classifier == config.IntentClassifierLLM {
+		classifier.complete = complete
+	}
+	return classifier
+}
+
+// Classify decides the intent of a message. toolNames are the tools offered for the
+// request; a message naming one of them is never a knowledge base question. Without
+// the knowledge base search, no message is.
+func (c *Classifier) Classify(ctx context.Context, message string, toolNames []string, ragAvailable bool) Decision {
+	lower := strings.ToLower(message)
+	if c.isChat(lower) {
+		return Decision{Intent: config.IntentChat, Source: SourceHeuristic}
+	}
+	if ragAvailable && c.ragKeywords != nil && c.ragKeywords.MatchString(lower) && !namesTool(lower, toolNames) {
+		return Decision{Intent: config.IntentRAG, Source: SourceHeuristic}
+	}
+	if c.complete == nil {
+		return Decision{Intent: config.IntentTools, Source: SourceHeuristic}
+	}
+
+	reply, err := c.complete(ctx, c.prompt(message, toolNames, ragAvailable))
+	if err != nil {
+		return Decision{Intent: config.IntentTools, Source: SourceFallback}
+	}
+	intent := parseIntent(reply)
+	if intent == "" || (intent == config.IntentRAG && !ragAvailable) {
+		return Decision{Intent: config.IntentTools, Source: SourceFallback}
+	}
+	return Decision{Intent: intent, Source: SourceLLM}
+}
+
+// isChat reports whether the message is made only of chat words and emoji
+func (c *Classifier) isChat(lower string) bool {
+	for _, word := range wordPattern.FindAllString(emojiPattern.ReplaceAllString(lower, " "), -1) {
+		if !c.chatWords[word] {
+			return false
+		}
+	}
+	return true
+}
+
+// prompt asks the classifier model for the intent of the message
+func (c *Classifier) prompt(message string, toolNames []string, ragAvailable bool) string {
+	options := []string{"- " + config.IntentChat + ": greetings, thanks, small talk or questions answered from general knowledge"}
+	if ragAvailable {
+		options = append(options, "- "+config.IntentRAG+": questions that internal documentation such as runbooks, guides or policies could answer")
+	}
+	tools := "- " + config.IntentTools + ": requests to fetch live data or take an action"
+	if len(toolNames) > 0 {
+		if len(toolNames) > maxPromptTools {
+			toolNames = toolNames[:maxPromptTools]
+		}
+		tools += ", with tools such as " + strings.Join(toolNames, ", ")
+	}
+	options = append(options, tools)
+	return fmt.Sprintf(classifierPrompt, strings.Join(options, "\n")+"\n", message)
+}
+
+// parseIntent returns the intent a classifier reply starts with, or "" when it
+// names none
+func parseIntent(reply string) string {
+	reply = strings.ToLower(strings.TrimSpace(reply))
+	reply = strings.TrimLeft(reply, "\"'*`-: ")
+	for _, intent := range []string{config.IntentChat, config.IntentRAG, config.IntentTools} {
+		if strings.HasPrefix(reply, intent) {
+			return intent
+		}
+	}
+	return ""
+}
+
+// namesTool reports whether the message names one of the tools
+func namesTool(lower string, toolNames []string) bool {
+	for _, name := range toolNames {
+		name = strings.ToLower(name)
+		if strings.Contains(lower, name) || strings.Contains(lower, strings.ReplaceAll(name, "_", " ")) {
+			return true
+		}
+	}
+	return false
+}
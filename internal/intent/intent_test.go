@@ -0,0 +1,78 @@
+package intent
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+func newTestClassifier(classifier string, complete CompleteFunc) *Classifier {
+	cfg := &config.Config{}
+	cfg.LLM.Intent = config.LLMIntentConfig{Enabled: true, Classifier: classifier, RAGKeywords: []string{"runbook", "how do i"}}
+	cfg.ApplyDefaults()
+	return NewClassifier(cfg.LLM.Intent, complete)
+}
+
+func TestClassifyHeuristic(t *testing.T) {
+	classifier := newTestClassifier(config.IntentClassifierHeuristic, nil)
+	tools := []string{"get_pods", "jira"}
+
+	tests := []struct {
+		name    string
+		message string
+		rag     bool
+		intent  string
+	}{
+		{"thanks", "Thanks!", true, config.IntentChat},
+		{"greeting with emoji", "hey :wave: good morning 🙂", true, config.IntentChat},
+		{"emoji only", ":+1:", true, config.IntentChat},
+		{"thanks with a request", "thanks, now list the pods", true, config.IntentTools},
+		{"affirmative follow-up", "ok", true, config.IntentTools},
+		{"knowledge base keyword", "How do I rotate the database credentials?", true, config.IntentRAG},
+		{"keyword without knowledge base", "Where is the runbook for failovers?", false, config.IntentTools},
+		{"keyword naming a tool", "Which runbook covers get pods errors?", true, config.IntentTools},
+		{"question", "What is the status of the deployment?", true, config.IntentTools},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := classifier.Classify(context.Background(), tt.message, tools, tt.rag)
+			assert.Equal(t, Decision{Intent: tt.intent, Source: SourceHeuristic}, decision)
+		})
+	}
+}
+
+func TestClassifyLLM(t *testing.T) {
+	var prompts []string
+	reply, replyErr := "", error(nil)
+	classifier := newTestClassifier(config.IntentClassifierLLM, func(_ context.Context, prompt string) (string, error) {
+		prompts = append(prompts, prompt)
+		return reply, replyErr
+	})
+
+	// The heuristic answers what it can without asking the model
+	assert.Equal(t, Decision{Intent: config.IntentChat, Source: SourceHeuristic}, classifier.Classify(context.Background(), "thank you", nil, true))
+	assert.Empty(t, prompts)
+
+	reply = "Chat."
+	assert.Equal(t, Decision{Intent: config.IntentChat, Source: SourceLLM}, classifier.Classify(context.Background(), "What does MCP stand for?", []string{"get_pods"}, true))
+	require.Len(t, prompts, 1)
+	assert.Contains(t, prompts[0], "- rag: questions that internal documentation")
+	assert.Contains(t, prompts[0], "with tools such as get_pods")
+	assert.Contains(t, prompts[0], "Message: What does MCP stand for?")
+
+	reply = "**rag**"
+	assert.Equal(t, Decision{Intent: config.IntentRAG, Source: SourceLLM}, classifier.Classify(context.Background(), "What is our on-call policy?", nil, true))
+
+	// Without the knowledge base, or when the model fails, every tool is offered
+	assert.Equal(t, Decision{Intent: config.IntentTools, Source: SourceFallback}, classifier.Classify(context.Background(), "What is our on-call policy?", nil, false))
+	assert.NotContains(t, prompts[len(prompts)-1], "- rag:")
+	reply = "I am not sure"
+	assert.Equal(t, Decision{Intent: config.IntentTools, Source: SourceFallback}, classifier.Classify(context.Background(), "Restart it", nil, true))
+	replyErr = errors.New("context deadline exceeded")
+	assert.Equal(t, Decision{Intent: config.IntentTools, Source: SourceFallback}, classifier.Classify(context.Background(), "Restart it", nil, true))
+}
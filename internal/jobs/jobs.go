@@ -0,0 +1,180 @@
+// Package jobs keeps the tool calls the LLM scheduled to run later, such as "run
+// the backup check at 2am and post the results here". A job remembers the user who
+// asked for it and the thread it was scheduled from, so it can run as that user
+// and post its result there. Jobs persist to a JSON file so they survive restarts.
+package jobs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/filestore"
+)
+
+// Job is a tool call scheduled to run at a later time
+type Job struct {
+	ID        string                 `json:"id"`
+	Tool      string                 `json:"tool"`
+	Args      map[string]interface{} `json:"args,omitempty"`
+	Note      string                 `json:"note,omitempty"` // What the result is for, posted with it
+	RunAt     time.Time              `json:"runAt"`
+	ChannelID string                 `json:"channel"`
+	ThreadTS  string                 `json:"threadTs,omitempty"`
+	UserID    string                 `json:"user"` // User who asked for the job, whom it runs as
+	Created   time.Time              `json:"created"`
+}
+
+// file is the persisted form of a store
+type file struct {
+	NextID int   `json:"nextId"`
+	Jobs   []Job `json:"jobs"`
+}
+
+// Store keeps the jobs waiting to run. With a path, it is persisted to a JSON file
+// so jobs survive restarts.
+type Store struct {
+	path    string
+	maxJobs int
+	now     func() time.Time
+
+	mu     sync.Mutex
+	nextID int
+	jobs   []Job
+}
+
+// NewStore opens (or creates) a store that holds at most maxJobs jobs. An empty
+// path keeps the jobs in memory only.
+func NewStore(path string, maxJobs int) (*Store, error) {
+	s := &Store{path: path, maxJobs: maxJobs, now: time.Now, nextID: 1}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Add stores a job and returns it with its ID
+func (s *Store) Add(job Job) (Job, error) {
+	if job.Tool == "" || job.ChannelID == "" {
+		return Job{}, fmt.Errorf("job needs a tool and a channel")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.maxJobs > 0 && len(s.jobs) >= s.maxJobs {
+		return Job{}, fmt.Errorf("%d jobs are already scheduled, which is the limit", len(s.jobs))
+	}
+	job.ID = strconv.Itoa(s.nextID)
+	s.nextID++
+	if job.Created.IsZero() {
+		job.Created = s.now()
+	}
+	s.jobs = append(s.jobs, job)
+	return job, s.save()
+}
+
+// Get returns the job with the ID
+func (s *Store) Get(id string) (Job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, job := range s.jobs {
+		if job.ID == id {
+			return job, true
+		}
+	}
+	return Job{}, false
+}
+
+// Delete removes the job with the ID, so it does not run, and reports whether it existed
+func (s *Store) Delete(id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, job := range s.jobs {
+		if job.ID == id {
+			s.jobs = append(s.jobs[:i:i], s.jobs[i+1:]...)
+			return true, s.save()
+		}
+	}
+	return false, nil
+}
+
+// List returns the jobs waiting to run, the next one first. An empty userID lists
+// the jobs of every user.
+func (s *Store) List(userID string) []Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var jobs []Job
+	for _, job := range s.jobs {
+		if userID == "" || job.UserID == userID {
+			jobs = append(jobs, job)
+		}
+	}
+	sort.SliceStable(jobs, func(i, j int) bool { return jobs[i].RunAt.Before(jobs[j].RunAt) })
+	return jobs
+}
+
+// Len returns the number of jobs waiting to run
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.jobs)
+}
+
+// TakeDue removes and returns the jobs due at now, the earliest first. A job is
+// removed before it runs, so a restart while it runs does not run it twice.
+func (s *Store) TakeDue(now time.Time) ([]Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var due, waiting []Job
+	for _, job := range s.jobs {
+		if job.RunAt.After(now) {
+			waiting = append(waiting, job)
+		} else {
+			due = append(due, job)
+		}
+	}
+	if len(due) == 0 {
+		return nil, nil
+	}
+	s.jobs = waiting
+	sort.SliceStable(due, func(i, j int) bool { return due[i].RunAt.Before(due[j].RunAt) })
+	return due, s.save()
+}
+
+// load reads the store file if it exists
+func (s *Store) load() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read job store: %w", err)
+	}
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("failed to parse job store: %w", err)
+	}
+	s.jobs = f.Jobs
+	if f.NextID > s.nextID {
+		s.nextID = f.NextID
+	}
+	return nil
+}
+
+// save atomically writes the store file; callers must hold mu
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+	if err := filestore.WriteJSON(s.path, file{NextID: s.nextID, Jobs: s.jobs}); err != nil {
+		return fmt.Errorf("failed to save job store: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package jobs
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/hooks"
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+)
+
+func TestStorePersistsAndTakesDueJobs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.json")
+	store, err := NewStore(path, 2)
+	require.NoError(t, err)
+	now := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	late, err := store.Add(Job{Tool: "backup_check", RunAt: now.Add(2 * time.Hour), ChannelID: "C1", UserID: "U1"})
+	require.NoError(t, err)
+	early, err := store.Add(Job{Tool: "list_alerts", RunAt: now.Add(time.Hour), ChannelID: "C1", UserID: "U2"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "2"}, []string{late.ID, early.ID})
+	_, err = store.Add(Job{Tool: "list_alerts", RunAt: now, ChannelID: "C1"})
+	assert.ErrorContains(t, err, "2 jobs are already scheduled")
+
+	// The next job is listed first; users see their own jobs
+	list := store.List("")
+	require.Len(t, list, 2)
+	assert.Equal(t, "2", list[0].ID)
+	assert.Len(t, store.List("U1"), 1)
+
+	// Jobs survive a restart, and due jobs are taken once
+	reopened, err := NewStore(path, 2)
+	require.NoError(t, err)
+	due, err := reopened.TakeDue(now.Add(90 * time.Minute))
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, "list_alerts", due[0].Tool)
+	due, err = reopened.TakeDue(now.Add(90 * time.Minute))
+	require.NoError(t, err)
+	assert.Empty(t, due)
+
+	deleted, err := reopened.Delete("1")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	job, err := reopened.Add(Job{Tool: "backup_check", RunAt: now, ChannelID: "C1"})
+	require.NoError(t, err)
+	assert.Equal(t, "3", job.ID, "IDs are not reused")
+}
+
+// newTestScheduler returns a scheduler in Paris time at 22:30 and its store
+func newTestScheduler(t *testing.T) (*Scheduler, *Store) {
+	t.Helper()
+	store, err := NewStore("", 0)
+	require.NoError(t, err)
+	scheduler := NewScheduler(store, config.JobsConfig{MaxDelay: "48h", Timezone: "Europe/Paris"}, "/mcp")
+	now := time.Date(2026, 10, 1, 20, 30, 0, 0, time.UTC)
+	scheduler.now = func() time.Time { return now }
+	scheduler.SetToolLookup(func(name string) bool { return name == "backup_check" })
+	return scheduler, store
+}
+
+func conversationContext() context.Context {
+	ctx := mcp.ContextWithIdentity(context.Background(), mcp.Identity{UserID: "U1"})
+	return hooks.ContextWithConversation(ctx, "C1", "100.1")
+}
+
+func TestSchedulerSchedulesInTheConversation(t *testing.T) {
+	scheduler, store := newTestScheduler(t)
+
+	reply, err := scheduler.CallTool(conversationContext(), ToolName, map[string]interface{}{
+		"tool":      "backup_check",
+		"arguments": `{"cluster": "prod"}`,
+		"at":        "02:00",
+		"note":      "nightly backup check",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Scheduled job 1: backup_check runs at 2026-10-02 02:00 CEST (in 3h30m) and its result will be posted in this conversation. It can be listed and cancelled with `/mcp jobs`.", reply)
+
+	job, ok := store.Get("1")
+	require.True(t, ok)
+	assert.Equal(t, map[string]interface{}{"cluster": "prod"}, job.Args)
+	assert.Equal(t, "C1", job.ChannelID)
+	assert.Equal(t, "100.1", job.ThreadTS)
+	assert.Equal(t, "U1", job.UserID)
+	assert.Equal(t, "nightly backup check", job.Note)
+	assert.True(t, job.RunAt.Equal(time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestSchedulerRejectsInvalidCalls(t *testing.T) {
+	scheduler, store := newTestScheduler(t)
+
+	tests := []struct {
+		args map[string]interface{}
+		want string
+	}{
+		{map[string]interface{}{"tool": "drop_tables", "in": "1h"}, "unknown tool 'drop_tables'"},
+		{map[string]interface{}{"tool": ToolName, "in": "1h"}, "cannot be scheduled"},
+		{map[string]interface{}{"tool": "backup_check"}, "either in or at is required"},
+		{map[string]interface{}{"tool": "backup_check", "in": "soon"}, "invalid delay 'soon'"},
+		{map[string]interface{}{"tool": "backup_check", "at": "2026-10-01T08:00:00Z"}, "has already passed"},
+		{map[string]interface{}{"tool": "backup_check", "in": "72h"}, "at most 48h ahead"},
+		{map[string]interface{}{"tool": "backup_check", "in": "1h", "arguments": "[1]"}, "must be a JSON object"},
+	}
+	for _, tt := range tests {
+		_, err := scheduler.CallTool(conversationContext(), ToolName, tt.args)
+		assert.ErrorContains(t, err, tt.want)
+	}
+
+	_, err := scheduler.CallTool(context.Background(), ToolName, map[string]interface{}{"tool": "backup_check", "in": "1h"})
+	assert.ErrorContains(t, err, "only be scheduled from a conversation")
+	assert.Zero(t, store.Len())
+}
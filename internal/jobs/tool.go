@@ -0,0 +1,229 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/hooks"
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+)
+
+const (
+	// ServerName identifies the scheduler among the bridge's clients
+	ServerName = "jobs"
+	// ToolName is the name of the tool that schedules a tool call
+	ToolName = "schedule_tool_call"
+)
+
+// Scheduler is the native tool the LLM schedules tool calls with. Jobs are
+// scheduled in the conversation and as the user of the request.
+type Scheduler struct {
+	store    *Store
+	maxDelay time.Duration
+	location *time.Location
+	command  string // Slash command the jobs are listed and cancelled with
+	now      func() time.Time
+
+	mu      sync.RWMutex
+	hasTool func(name string) bool
+}
+
+// NewScheduler returns the scheduling tool for the jobs of the store. command is
+// the slash command users list and cancel jobs with.
+func NewScheduler(store *Store, cfg config.JobsConfig, command string) *Scheduler {
+	s := &Scheduler{store: store, command: command, now: time.Now, location: time.UTC}
+	s.maxDelay, _ = time.ParseDuration(cfg.MaxDelay) // Validated with the config
+	if location, err := time.LoadLocation(cfg.Timezone); err == nil {
+		s.location = location
+	}
+	return s
+}
+
+// SetToolLookup sets how the scheduler checks that a tool exists. Until it is
+// set, any tool can be scheduled.
+func (s *Scheduler) SetToolLookup(hasTool func(name string) bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hasTool = hasTool
+}
+
+// ToolInfo describes the scheduling tool to the LLM
+func ToolInfo() mcp.ToolInfo {
+	return mcp.ToolInfo{
+		ToolName: ToolName,
+		ToolDescription: "Schedule a tool call to run later, when the user asks to run something at a time or after a delay, " +
+			"such as \"run the backup check at 2am and post the results here\". The result is posted in this conversation. " +
+			"Pass either in, a delay such as \"30m\" or \"2h\", or at, a time of day such as \"02:00\" or an RFC 3339 timestamp.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"tool": map[string]interface{}{
+					"type":        "string",
+					"description": "The name of the tool to call",
+				},
+				"arguments": map[string]interface{}{
+					"type":        "object",
+					"description": "The arguments to call the tool with",
+				},
+				"in": map[string]interface{}{
+					"type":        "string",
+					"description": "How long from now to run the tool, such as \"30m\" or \"2h\"",
+				},
+				"at": map[string]interface{}{
+					"type":        "string",
+					"description": "When to run the tool: a time of day such as \"02:00\", which is the next time it comes, or an RFC 3339 timestamp",
+				},
+				"note": map[string]interface{}{
+					"type":        "string",
+					"description": "A short description of what the result is for, posted with it",
+				},
+			},
+			"required": []string{"tool"},
+		},
+		ServerName: ServerName,
+	}
+}
+
+// CallTool implements the bridge's client interface
+func (s *Scheduler) CallTool(ctx context.Context, toolName string, args map[string]interface{}) (string, error) {
+	if toolName != ToolName {
+		return "", fmt.Errorf("unknown jobs tool: %s. Available tools: %s", toolName, ToolName)
+	}
+	channelID, threadTS, ok := hooks.ConversationFromContext(ctx)
+	identity, hasIdentity := mcp.IdentityFromContext(ctx)
+	if !ok || channelID == "" || !hasIdentity {
+		return "", fmt.Errorf("tool calls can only be scheduled from a conversation")
+	}
+
+	tool, _ := args["tool"].(string)
+	tool = strings.TrimSpace(tool)
+	switch {
+	case tool == "":
+		return "", fmt.Errorf("the tool to call is required")
+	case tool == ToolName:
+		return "", fmt.Errorf("%s cannot be scheduled", ToolName)
+	case !s.toolExists(tool):
+		return "", fmt.Errorf("unknown tool '%s'", tool)
+	}
+	toolArgs, err := toolArguments(args["arguments"])
+	if err != nil {
+		return "", err
+	}
+	runAt, err := s.runAt(args)
+	if err != nil {
+		return "", err
+	}
+	note, _ := args["note"].(string)
+
+	job, err := s.store.Add(Job{
+		Tool:      tool,
+		Args:      toolArgs,
+		Note:      strings.TrimSpace(note),
+		RunAt:     runAt,
+		ChannelID: channelID,
+		ThreadTS:  threadTS,
+		UserID:    identity.UserID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to schedule the tool call: %w", err)
+	}
+	return fmt.Sprintf("Scheduled job %s: %s runs at %s (in %s) and its result will be posted in this conversation. It can be listed and cancelled with `%s jobs`.",
+		job.ID, tool, s.FormatTime(runAt), formatDelay(runAt.Sub(s.now())), s.command), nil
+}
+
+// FormatTime shows a job time in the scheduler's timezone
+func (s *Scheduler) FormatTime(t time.Time) string {
+	return t.In(s.location).Format("2006-01-02 15:04 MST")
+}
+
+// formatDelay shows a delay in minutes, such as "3h30m"
+func formatDelay(d time.Duration) string {
+	text := strings.TrimSuffix(d.Round(time.Minute).String(), "0s")
+	if strings.HasSuffix(text, "h0m") {
+		text = strings.TrimSuffix(text, "0m")
+	}
+	return text
+}
+
+// toolExists reports whether a tool can be scheduled
+func (s *Scheduler) toolExists(name string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.hasTool == nil || s.hasTool(name)
+}
+
+// runAt returns when a job runs, from a delay or a time
+func (s *Scheduler) runAt(args map[string]interface{}) (time.Time, error) {
+	now := s.now()
+	in, _ := args["in"].(string)
+	at, _ := args["at"].(string)
+	var runAt time.Time
+	switch {
+	case strings.TrimSpace(in) != "":
+		delay, err := time.ParseDuration(strings.TrimSpace(in))
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid delay '%s', use a duration such as \"30m\" or \"2h\"", in)
+		}
+		runAt = now.Add(delay)
+	case strings.TrimSpace(at) != "":
+		var err error
+		if runAt, err = s.parseTime(strings.TrimSpace(at), now); err != nil {
+			return time.Time{}, err
+		}
+	default:
+		return time.Time{}, fmt.Errorf("either in or at is required")
+	}
+
+	if !runAt.After(now) {
+		return time.Time{}, fmt.Errorf("%s has already passed", s.FormatTime(runAt))
+	}
+	if runAt.Sub(now) > s.maxDelay {
+		return time.Time{}, fmt.Errorf("tool calls can be scheduled at most %s ahead", formatDelay(s.maxDelay))
+	}
+	return runAt, nil
+}
+
+// parseTime parses an RFC 3339 timestamp, or a time of day that is the next time
+// it comes in the scheduler's timezone
+func (s *Scheduler) parseTime(at string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, at); err == nil {
+		return t, nil
+	}
+	clock, err := time.Parse("15:04", at)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time '%s', use a time of day such as \"02:00\" or an RFC 3339 timestamp", at)
+	}
+	local := now.In(s.location)
+	t := time.Date(local.Year(), local.Month(), local.Day(), clock.Hour(), clock.Minute(), 0, 0, s.location)
+	if !t.After(now) {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// toolArguments returns the arguments of the scheduled call, which models pass as
+// an object or as a JSON string
+func toolArguments(value interface{}) (map[string]interface{}, error) {
+	switch args := value.(type) {
+	case nil:
+		return nil, nil
+	case map[string]interface{}:
+		return args, nil
+	case string:
+		if strings.TrimSpace(args) == "" {
+			return nil, nil
+		}
+		var parsed map[string]interface{}
+		if err := json.Unmarshal([]byte(args), &parsed); err != nil {
+			return nil, fmt.Errorf("the arguments must be a JSON object: %w", err)
+		}
+		return parsed, nil
+	default:
+		return nil, fmt.Errorf("the arguments must be a JSON object")
+	}
+}
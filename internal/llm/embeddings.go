@@ -0,0 +1,65 @@
+package llm
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/tmc/langchaingo/embeddings"
+	"github.com/tmc/langchaingo/llms/ollama"
+	"github.com/tmc/langchaingo/llms/openai"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+// NewEmbedder creates an embedder for the given provider ("openai" or "ollama") and
+// model, using the API key and base URL from the provider's LLM configuration
+func NewEmbedder(provider, model string, providerConfig config.LLMProviderConfig) (embeddings.Embedder, error) {
+	var client embeddings.EmbedderClient
+	var err error
+	switch provider {
+	case config.ProviderOpenAI:
+		opts := []openai.Option{openai.WithEmbeddingModel(model)}
+		if providerConfig.APIKey != "" {
+			opts = append(opts, openai.WithToken(providerConfig.APIKey))
+		}
+		if providerConfig.BaseURL != "" {
+			opts = append(opts, openai.WithBaseURL(providerConfig.BaseURL))
+		}
+		client, err = openai.New(opts...)
+	case config.ProviderOllama:
+		opts := []ollama.Option{ollama.WithModel(model)}
+		if providerConfig.BaseURL != "" {
+			opts = append(opts, ollama.WithServerURL(providerConfig.BaseURL))
+		}
+		client, err = ollama.New(opts...)
+	default:
+		return nil, fmt.Errorf("unsupported embedding provider '%s'", provider)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s embedding client: %w", provider, err)
+	}
+
+	embedder, err := embeddings.NewEmbedder(client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create embedder: %w", err)
+	}
+	return embedder, nil
+}
+
+// CosineSimilarity returns the cosine similarity of two vectors, or 0 if either is
+// empty or their lengths differ
+func CosineSimilarity(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
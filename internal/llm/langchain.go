@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/tmc/langchaingo/agents"
 	"github.com/tmc/langchaingo/callbacks"
@@ -14,6 +15,7 @@ import (
 
 	"github.com/tuannvm/slack-mcp-client/internal/common/errors"
 	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
 )
 
 const (
@@ -25,8 +27,16 @@ const (
 type LangChainProvider struct {
 	llm          llms.Model
 	providerType string // The underlying provider type (e.g., "openai", "ollama")
+	agentType    string // The agent implementation, "conversational" or "tools"
 	modelName    string // The specific model configured (e.g., "gpt-4o", "llama3")
 	logger       *logging.Logger
+
+	factory          LangChainModelFactory
+	config           map[string]interface{}
+	structuredMu     sync.Mutex
+	structuredModels map[string]llms.Model // Schema-constrained models by schema
+	modelsMu         sync.Mutex
+	models           map[string]llms.Model // Clients for other models of the provider, by model name
 }
 
 // LangChainModelFactory defines an interface for creating LangChain model instances
@@ -104,11 +114,21 @@ func NewLangChainProviderFactory(config map[string]interface{}, logger *logging.
 		return nil, fmt.Errorf("failed to initialize langchain %s client: %w", underlyingProviderType, err)
 	}
 
+	// Providers without native tool calling keep the conversational agent
+	requestedAgentType, _ := config["agent_type"].(string)
+	agentType := resolveAgentType(requestedAgentType, underlyingProviderType)
+	if agentType != requestedAgentType {
+		providerLogger.WarnKV("Tool-calling agent not supported by this provider, using the conversational agent", "type", underlyingProviderType)
+	}
+
 	return &LangChainProvider{
 		llm:          llmClient,
 		providerType: underlyingProviderType,
+		agentType:    agentType,
 		modelName:    modelName,
 		logger:       providerLogger, // Assign the named logger
+		factory:      factory,
+		config:       config,
 	}, nil
 }
 
@@ -121,12 +141,24 @@ func (p *LangChainProvider) GenerateCompletion(ctx context.Context, prompt strin
 	p.logger.DebugKV("Calling LangChainGo GenerateCompletion", "prompt_length", len(prompt))
 	callOptions := p.buildOptions(options)
 
+	model := p.llm
+	if options.ResponseSchema != nil {
+		if structured := p.structuredModel(options.ResponseSchema); structured != nil {
+			model = structured
+		} else {
+			prompt += options.ResponseSchema.instructions()
+			if jsonModeProviders[p.providerType] {
+				callOptions = append(callOptions, llms.WithJSONMode())
+			}
+		}
+	}
+
 	msg := llms.MessageContent{
 		Role:  llms.ChatMessageTypeHuman,
 		Parts: []llms.ContentPart{llms.TextContent{Text: prompt}},
 	}
 
-	resp, err := p.llm.GenerateContent(ctx, []llms.MessageContent{msg}, callOptions...)
+	resp, err := model.GenerateContent(ctx, []llms.MessageContent{msg}, callOptions...)
 	if err != nil {
 		p.logger.ErrorKV("LangChainGo GenerateContent request failed", "error", err)
 		return nil, errors.WrapLLMError(err, "request_failed", "Failed to generate completion from LangChainGo")
@@ -136,8 +168,101 @@ func (p *LangChainProvider) GenerateCompletion(ctx context.Context, prompt strin
 	if len(choices) < 1 {
 		return nil, fmt.Errorf("empty response from model")
 	}
-	c1 := choices[0]
-	return c1, nil
+	return mergeChoices(choices), nil
+}
+
+// structuredModel returns a model constrained to the schema, or nil when the
+// provider cannot enforce schemas natively. Models are created once per schema.
+func (p *LangChainProvider) structuredModel(schema *ResponseSchema) llms.Model {
+	factory, ok := p.factory.(StructuredModelFactory)
+	if !ok {
+		return nil
+	}
+	key := schema.cacheKey()
+	p.structuredMu.Lock()
+	defer p.structuredMu.Unlock()
+	if model, exists := p.structuredModels[key]; exists {
+		return model
+	}
+	model, err := factory.CreateStructured(p.config, schema, p.logger)
+	if err != nil {
+		p.logger.WarnKV("Structured output unavailable, falling back to JSON instructions",
+			"schema", schema.Name, "error", err)
+	}
+	if p.structuredModels == nil {
+		p.structuredModels = make(map[string]llms.Model)
+	}
+	// A failed creation is cached as nil so it is not retried on every request
+	p.structuredModels[key] = model
+	return model
+}
+
+// modelFor returns a client for another model of the same provider, used when a
+// request overrides the model but cannot pass it as a call option. Clients are
+// created once per model; on failure the configured model is used.
+func (p *LangChainProvider) modelFor(modelName string) llms.Model {
+	if modelName == "" || modelName == p.modelName || p.factory == nil {
+		return p.llm
+	}
+	p.modelsMu.Lock()
+	defer p.modelsMu.Unlock()
+	if model, exists := p.models[modelName]; exists {
+		return model
+	}
+	config := make(map[string]interface{}, len(p.config))
+	for key, value := range p.config {
+		config[key] = value
+	}
+	config["model"] = modelName
+	model, err := p.factory.Create(config, p.logger)
+	if err != nil {
+		p.logger.WarnKV("Failed to create model client, using the configured model", "model", modelName, "error", err)
+		model = p.llm
+	}
+	if p.models == nil {
+		p.models = make(map[string]llms.Model)
+	}
+	p.models[modelName] = model
+	return model
+}
+
+// mergeChoices combines the choices of a response into one. Anthropic returns a
+// choice per content block, so text, thinking and tool_use blocks arrive separately;
+// other providers return a single choice, which is returned unchanged.
+func mergeChoices(choices []*llms.ContentChoice) *llms.ContentChoice {
+	if len(choices) == 1 {
+		return choices[0]
+	}
+	merged := &llms.ContentChoice{}
+	var texts []string
+	for _, choice := range choices {
+		if choice == nil {
+			continue
+		}
+		if choice.Content != "" {
+			texts = append(texts, choice.Content)
+		}
+		merged.ToolCalls = append(merged.ToolCalls, choice.ToolCalls...)
+		if merged.FuncCall == nil {
+			merged.FuncCall = choice.FuncCall
+		}
+		if merged.StopReason == "" {
+			merged.StopReason = choice.StopReason
+		}
+		if merged.ReasoningContent == "" {
+			merged.ReasoningContent = choice.ReasoningContent
+		}
+		if merged.GenerationInfo == nil {
+			merged.GenerationInfo = make(map[string]any, len(choice.GenerationInfo))
+		}
+		for key, value := range choice.GenerationInfo {
+			if existing, exists := merged.GenerationInfo[key]; !exists || existing == nil || existing == "" {
+				merged.GenerationInfo[key] = value
+			}
+		}
+	}
+	merged.Content = strings.Join(texts, "\n")
+	return merged
 }
 
 // GenerateChatCompletion generates a chat completion using LangChainGo
@@ -153,7 +278,7 @@ func (p *LangChainProvider) GenerateChatCompletion(ctx context.Context, messages
 	// Convert our message format to a single prompt string
 	var promptBuilder strings.Builder
 	for _, msg := range messages {
-		promptBuilder.WriteString(fmt.Sprintf("%s: %s\n", strings.ToUpper(msg.Role), msg.Content))
+		promptBuilder.WriteString(formatMessage(msg))
 	}
 	prompt := promptBuilder.String()
 	// Add one final assistant prefix to indicate where the response should go
@@ -185,10 +310,23 @@ func (p *LangChainProvider) GenerateAgentCompletion(ctx context.Context,
 	// Convert our message format to a single prompt string
 	var historyBuilder strings.Builder
 	for _, msg := range history {
-		historyBuilder.WriteString(fmt.Sprintf("%s: %s\n", strings.ToUpper(msg.Role), msg.Content))
+		historyBuilder.WriteString(formatMessage(msg))
+	}
+
+	if callbackHandler != nil {
+		llmTools = callbackTools(llmTools, callbackHandler)
+	}
+
+	model := p.modelFor(ModelFromContext(ctx))
+	if report := usageFromContext(ctx); report != nil {
+		model = usageModel{Model: model, report: report}
+	}
+
+	if p.agentType == config.AgentTypeTools {
+		return p.runToolsAgent(ctx, model, userDisplayName, systemPrompt, prompt, history, llmTools, callbackHandler, maxAgentIterations)
 	}
 
-	ag := agents.NewConversationalAgent(p.llm, llmTools, agents.WithCallbacksHandler(callbackHandler),
+	ag := agents.NewConversationalAgent(model, llmTools, agents.WithCallbacksHandler(callbackHandler),
 		// Based on the default prompt prefix, with the user provided prefix.
 		agents.WithPromptPrefix(fmt.Sprintf(`%s
 You may invoke multiple tools as needed to solve a problem. Use any and all tools at your disposal. Tools can only be invoked one at a time.
@@ -244,7 +382,7 @@ Thought:{{.agent_scratchpad}}
 `, historyBuilder.String())),
 	)
 
-	e := agents.NewExecutor(ag, agents.WithMaxIterations(maxAgentIterations))
+	e := agents.NewExecutor(ag, agents.WithMaxIterations(maxAgentIterations), agents.WithCallbacksHandler(callbackHandler))
 
 	call, err := e.Call(ctx, map[string]any{
 		"input": prompt,
@@ -260,6 +398,57 @@ Thought:{{.agent_scratchpad}}
 	return output.(string), nil
 }
 
+// usageModel reports the tokens of each of the agent's model calls. The agent's
+// chains call the model directly, so callbacks do not see the usage.
+type usageModel struct {
+	llms.Model
+	report func(tokens int)
+}
+
+func (m usageModel) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	resp, err := m.Model.GenerateContent(ctx, messages, options...)
+	if resp != nil {
+		tokens := 0
+		for _, choice := range resp.Choices {
+			tokens += TokensUsed(choice.GenerationInfo)
+		}
+		m.report(tokens)
+	}
+	return resp, err
+}
+
+// callbackTool reports the agent's calls of a tool to the callbacks handler, as
+// langchaingo's own tools do, so the handler sees each tool's result
+type callbackTool struct {
+	tools.Tool
+	handler callbacks.Handler
+}
+
+func (t callbackTool) Call(ctx context.Context, input string) (string, error) {
+	t.handler.HandleToolStart(ctx, input)
+	output, err := t.Tool.Call(ctx, input)
+	if err != nil {
+		t.handler.HandleToolError(ctx, err)
+		return output, err
+	}
+	t.handler.HandleToolEnd(ctx, output)
+	return output, nil
+}
+
+// Schema implements SchemaTool
+func (t callbackTool) Schema() map[string]interface{} {
+	return ToolSchema(t.Tool)
+}
+
+// callbackTools wraps the agent's tools so their calls reach the handler
+func callbackTools(llmTools []tools.Tool, handler callbacks.Handler) []tools.Tool {
+	wrapped := make([]tools.Tool, len(llmTools))
+	for i, tool := range llmTools {
+		wrapped[i] = callbackTool{Tool: tool, handler: handler}
+	}
+	return wrapped
+}
+
 // GetInfo returns information about the provider.
 func (p *LangChainProvider) GetInfo() ProviderInfo {
 	displayName := fmt.Sprintf("LangChain (%s - %s)", p.providerType, p.modelName)
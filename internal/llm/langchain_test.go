@@ -0,0 +1,107 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tmc/langchaingo/callbacks"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/tools"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+)
+
+func TestMergeChoicesCombinesAnthropicContentBlocks(t *testing.T) {
+	merged := mergeChoices([]*llms.ContentChoice{
+		{GenerationInfo: map[string]any{"ThinkingContent": "The user wants the weather."}},
+		{Content: "Let me check.", StopReason: "tool_use", GenerationInfo: map[string]any{"ThinkingContent": "", "OutputTokens": 12}},
+		{ToolCalls: []llms.ToolCall{{ID: "toolu_1", FunctionCall: &llms.FunctionCall{Name: "get_weather", Arguments: "{}"}}}},
+	})
+	assert.Equal(t, "Let me check.", merged.Content)
+	assert.Equal(t, "tool_use", merged.StopReason)
+	assert.Len(t, merged.ToolCalls, 1)
+	assert.Equal(t, "The user wants the weather.", merged.GenerationInfo["ThinkingContent"])
+	assert.Equal(t, 12, merged.GenerationInfo["OutputTokens"])
+
+	single := &llms.ContentChoice{Content: "Hi"}
+	assert.Same(t, single, mergeChoices([]*llms.ContentChoice{single}))
+}
+
+// countingFactory records the models it creates clients for
+type countingFactory struct {
+	OllamaModelFactory
+	models []string
+}
+
+func (f *countingFactory) Create(config map[string]interface{}, _ *logging.Logger) (llms.Model, error) {
+	f.models = append(f.models, config["model"].(string))
+	return &recordingModel{}, nil
+}
+
+func TestModelForOverride(t *testing.T) {
+	factory := &countingFactory{}
+	configured := &recordingModel{}
+	provider := &LangChainProvider{
+		llm:       configured,
+		modelName: "gpt-4o",
+		logger:    logging.New("test", logging.LevelError),
+		factory:   factory,
+		config:    map[string]interface{}{"type": ProviderTypeOpenAI, "model": "gpt-4o"},
+	}
+
+	assert.Same(t, configured, provider.modelFor(ModelFromContext(context.Background())))
+	assert.Same(t, configured, provider.modelFor("gpt-4o"))
+
+	ctx := ContextWithModel(context.Background(), "gpt-4o-mini")
+	mini := provider.modelFor(ModelFromContext(ctx))
+	assert.NotSame(t, configured, mini)
+	assert.Same(t, mini, provider.modelFor("gpt-4o-mini"))
+	assert.Equal(t, []string{"gpt-4o-mini"}, factory.models)
+	assert.Equal(t, "gpt-4o", provider.config["model"])
+}
+
+func TestTokensUsed(t *testing.T) {
+	assert.Equal(t, 30, TokensUsed(map[string]any{"PromptTokens": 10, "CompletionTokens": 20, "TotalTokens": 30}))
+	assert.Equal(t, 15, TokensUsed(map[string]any{"InputTokens": 10, "OutputTokens": 5}))
+	assert.Equal(t, 0, TokensUsed(nil))
+}
+
+type toolRecorder struct {
+	callbacks.SimpleHandler
+	events []string
+}
+
+func (r *toolRecorder) HandleToolStart(_ context.Context, input string) {
+	r.events = append(r.events, "start "+input)
+}
+func (r *toolRecorder) HandleToolEnd(_ context.Context, output string) {
+	r.events = append(r.events, "end "+output)
+}
+func (r *toolRecorder) HandleToolError(_ context.Context, err error) {
+	r.events = append(r.events, "error "+err.Error())
+}
+
+type upperTool struct{}
+
+func (upperTool) Name() string        { return "upper" }
+func (upperTool) Description() string { return "Upper-case the input" }
+func (upperTool) Call(_ context.Context, input string) (string, error) {
+	if input == "" {
+		return "", fmt.Errorf("empty input")
+	}
+	return strings.ToUpper(input), nil
+}
+
+func TestCallbackToolsReportCalls(t *testing.T) {
+	recorder := &toolRecorder{}
+	tool := callbackTools([]tools.Tool{upperTool{}}, recorder)[0]
+	output, err := tool.Call(context.Background(), "pods")
+	assert.NoError(t, err)
+	assert.Equal(t, "PODS", output)
+	_, err = tool.Call(context.Background(), "")
+	assert.Error(t, err)
+	assert.Equal(t, []string{"start pods", "end PODS", "start ", "error empty input"}, recorder.events)
+}
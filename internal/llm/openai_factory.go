@@ -34,6 +34,28 @@ func (f *OpenAIModelFactory) Validate(config map[string]interface{}) error {
 
 // Create returns a new OpenAI LangChain model instance
 func (f *OpenAIModelFactory) Create(config map[string]interface{}, logger *logging.Logger) (llms.Model, error) {
+	return f.create(config, logger)
+}
+
+// CreateStructured returns an OpenAI model whose responses are constrained to the
+// JSON schema using OpenAI structured outputs
+func (f *OpenAIModelFactory) CreateStructured(config map[string]interface{}, schema *ResponseSchema, logger *logging.Logger) (llms.Model, error) {
+	property, err := schema.openAIProperty()
+	if err != nil {
+		return nil, err
+	}
+	return f.create(config, logger, openai.WithResponseFormat(&openai.ResponseFormat{
+		Type: "json_schema",
+		JSONSchema: &openai.ResponseFormatJSONSchema{
+			Name:   schema.Name,
+			Strict: schema.Strict,
+			Schema: property,
+		},
+	}))
+}
+
+// create builds the OpenAI client with any extra options appended
+func (f *OpenAIModelFactory) create(config map[string]interface{}, logger *logging.Logger, extra ...openai.Option) (llms.Model, error) {
 	modelName, _ := config["model"].(string) // Already validated in parent factory
 	apiKey, _ := config["api_key"].(string)  // API key is optional if base_url points to compatible API
 	baseURL, _ := config["base_url"].(string)
@@ -76,6 +98,8 @@ func (f *OpenAIModelFactory) Create(config map[string]interface{}, logger *loggi
 		logger.InfoKV("Configuring LangChain with OpenAI (default endpoint)", "model", modelName)
 	}
 
+	opts = append(opts, extra...)
+
 	llmClient, err := openai.New(opts...)
 	if err != nil {
 		logger.ErrorKV("Failed to initialize LangChainGo OpenAI client", "error", err)
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/mark3labs/mcp-go/mcp"
+	"strings"
 	"sync"
 
 	"github.com/tmc/langchaingo/callbacks"
@@ -78,10 +79,24 @@ type ProviderInfo struct {
 	Configuration map[string]string // Non-sensitive configuration details (e.g., model, base URL)
 }
 
-// RequestMessage represents a single message in a chat request
+// RequestMessage represents a single message in a chat request. A "tool" message
+// is the result of an earlier tool call, which providers with native tool calling
+// receive as the call and its result.
 type RequestMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role       string `json:"role"`
+	Content    string `json:"content"`
+	ToolName   string `json:"toolName,omitempty"`   // Tool that was called, for "tool" messages
+	ToolArgs   string `json:"toolArgs,omitempty"`   // Arguments of the call as JSON, for "tool" messages
+	ToolCallID string `json:"toolCallId,omitempty"` // Pairs a "tool" message with its call
+}
+
+// formatMessage renders a message for providers that take the conversation as
+// text, naming the tool of "tool" messages
+func formatMessage(msg RequestMessage) string {
+	if msg.Role == "tool" && msg.ToolName != "" {
+		return fmt.Sprintf("TOOL (%s, args: %s): %s\n", msg.ToolName, msg.ToolArgs, msg.Content)
+	}
+	return fmt.Sprintf("%s: %s\n", strings.ToUpper(msg.Role), msg.Content)
 }
 
 // ProviderOptions contains options for LLM requests
@@ -91,6 +106,69 @@ type ProviderOptions struct {
 	MaxTokens      int     // Maximum number of tokens to generate
 	TargetProvider string  // For gateway providers: specifies the underlying provider (e.g., "openai", "ollama")
 	Tools          []llms.Tool
+	ResponseSchema *ResponseSchema // Constrains the response to JSON matching the schema
+}
+
+// modelContextKey is the context key for a per-request model override
+type modelContextKey struct{}
+
+// ContextWithModel overrides the provider's configured model for requests made
+// with the returned context. It is how agent completions, which take no
+// ProviderOptions, are routed to another model.
+func ContextWithModel(ctx context.Context, model string) context.Context {
+	if model == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, modelContextKey{}, model)
+}
+
+// ModelFromContext returns the model override set by ContextWithModel, if any
+func ModelFromContext(ctx context.Context) string {
+	model, _ := ctx.Value(modelContextKey{}).(string)
+	return model
+}
+
+// usageContextKey is the context key for the token usage reporter of a request
+type usageContextKey struct{}
+
+// ContextWithUsage reports the tokens of every model call an agent completion
+// made with the returned context spends, so the caller can enforce a budget
+func ContextWithUsage(ctx context.Context, report func(tokens int)) context.Context {
+	return context.WithValue(ctx, usageContextKey{}, report)
+}
+
+// usageFromContext returns the reporter set by ContextWithUsage, if any
+func usageFromContext(ctx context.Context) func(tokens int) {
+	report, _ := ctx.Value(usageContextKey{}).(func(tokens int))
+	return report
+}
+
+// TokensUsed returns the tokens a response spent according to its generation
+// info. OpenAI reports PromptTokens and CompletionTokens, Anthropic InputTokens
+// and OutputTokens.
+func TokensUsed(info map[string]any) int {
+	if total := intFromInfo(info, "TotalTokens"); total > 0 {
+		return total
+	}
+	if tokens := intFromInfo(info, "PromptTokens") + intFromInfo(info, "CompletionTokens"); tokens > 0 {
+		return tokens
+	}
+	return intFromInfo(info, "InputTokens") + intFromInfo(info, "OutputTokens")
+}
+
+// intFromInfo returns a numeric generation info value, or 0
+func intFromInfo(info map[string]any, key string) int {
+	switch v := info[key].(type) {
+	case int:
+		return v
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	}
+	return 0
 }
 
 // LLMProvider defines the interface for language model providers
@@ -0,0 +1,100 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/llms/openai"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+)
+
+// ResponseSchema describes the JSON a response must conform to
+type ResponseSchema struct {
+	Name        string                 // Identifier sent to the provider, e.g. "tool_call"
+	Description string                 // What the response represents
+	Schema      map[string]interface{} // JSON schema of the response object
+	// Strict requests exact schema adherence. Strict schemas must list every property
+	// as required and set additionalProperties to false.
+	Strict bool
+}
+
+// StructuredModelFactory is implemented by model factories whose provider can
+// constrain responses to a JSON schema natively
+type StructuredModelFactory interface {
+	// CreateStructured returns a model instance that only produces JSON matching the schema
+	CreateStructured(config map[string]interface{}, schema *ResponseSchema, logger *logging.Logger) (llms.Model, error)
+}
+
+// jsonModeProviders accept a per-request JSON mode, used when a provider cannot
+// enforce the schema itself
+var jsonModeProviders = map[string]bool{
+	ProviderTypeOpenAI: true,
+	ProviderTypeOllama: true,
+}
+
+// instructions describes the schema in the prompt, for providers without native
+// structured output and as a hint for those with it
+func (s *ResponseSchema) instructions() string {
+	schema, err := json.Marshal(s.Schema)
+	if err != nil {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\n\nRespond with a single JSON object and nothing else")
+	if s.Description != "" {
+		b.WriteString(" (" + s.Description + ")")
+	}
+	b.WriteString(". The object must match this JSON schema:\n")
+	b.Write(schema)
+	b.WriteString("\n")
+	return b.String()
+}
+
+// cacheKey identifies the schema, including its content, for caching models
+func (s *ResponseSchema) cacheKey() string {
+	schema, _ := json.Marshal(s.Schema)
+	return fmt.Sprintf("%s:%t:%s", s.Name, s.Strict, schema)
+}
+
+// openAIProperty converts the schema to the type used by OpenAI structured outputs
+func (s *ResponseSchema) openAIProperty() (*openai.ResponseFormatJSONSchemaProperty, error) {
+	data, err := json.Marshal(s.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode response schema '%s': %w", s.Name, err)
+	}
+	var property openai.ResponseFormatJSONSchemaProperty
+	if err := json.Unmarshal(data, &property); err != nil {
+		return nil, fmt.Errorf("unsupported response schema '%s': %w", s.Name, err)
+	}
+	return &property, nil
+}
+
+// codeFencePattern matches a Markdown code block, optionally tagged as JSON
+var codeFencePattern = regexp.MustCompile("(?s)```(?:json)?\\s*(.*?)\\s*```")
+
+// DecodeJSON decodes a structured response into v. Providers without native
+// structured output sometimes wrap the JSON in a code block or surround it with
+// prose, so the first JSON object in the content is used.
+func DecodeJSON(content string, v interface{}) error {
+	content = strings.TrimSpace(content)
+	if match := codeFencePattern.FindStringSubmatch(content); match != nil {
+		content = match[1]
+	}
+	if err := json.Unmarshal([]byte(content), v); err == nil {
+		return nil
+	}
+
+	start := strings.Index(content, "{")
+	if start < 0 {
+		return fmt.Errorf("response does not contain a JSON object")
+	}
+	decoder := json.NewDecoder(strings.NewReader(content[start:]))
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("failed to decode JSON response: %w", err)
+	}
+	return nil
+}
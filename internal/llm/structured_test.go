@@ -0,0 +1,109 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+)
+
+var testSchema = &ResponseSchema{
+	Name:   "verdict",
+	Strict: true,
+	Schema: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"ok":   map[string]interface{}{"type": "boolean"},
+			"tags": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+		},
+		"required":             []string{"ok", "tags"},
+		"additionalProperties": false,
+	},
+}
+
+func TestDecodeJSON(t *testing.T) {
+	var reply struct {
+		OK bool `json:"ok"`
+	}
+	for name, content := range map[string]string{
+		"plain":      `{"ok": true}`,
+		"code block": "```json\n{\"ok\": true}\n```",
+		"with prose": `Sure! {"ok": true} Let me know if you need more.`,
+	} {
+		reply.OK = false
+		require.NoError(t, DecodeJSON(content, &reply), name)
+		assert.True(t, reply.OK, name)
+	}
+	assert.Error(t, DecodeJSON("yes", &reply))
+}
+
+func TestResponseSchemaOpenAIProperty(t *testing.T) {
+	property, err := testSchema.openAIProperty()
+	require.NoError(t, err)
+	assert.Equal(t, "object", property.Type)
+	assert.Equal(t, []string{"ok", "tags"}, property.Required)
+	assert.Equal(t, "string", property.Properties["tags"].Items.Type)
+	assert.False(t, property.AdditionalProperties)
+}
+
+// recordingModel records the messages and options of the last call
+type recordingModel struct {
+	prompt  string
+	options llms.CallOptions
+}
+
+func (m *recordingModel) GenerateContent(_ context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	m.prompt = messages[0].Parts[0].(llms.TextContent).Text
+	for _, option := range options {
+		option(&m.options)
+	}
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{Content: `{"ok":true,"tags":[]}`}}}, nil
+}
+
+func (m *recordingModel) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
+
+// structuredFactory returns a separate model for schema-constrained requests
+type structuredFactory struct {
+	OllamaModelFactory
+	model   *recordingModel
+	created int
+}
+
+func (f *structuredFactory) CreateStructured(map[string]interface{}, *ResponseSchema, *logging.Logger) (llms.Model, error) {
+	f.created++
+	return f.model, nil
+}
+
+func TestGenerateCompletionWithResponseSchema(t *testing.T) {
+	logger := logging.New("test", logging.LevelError)
+
+	// Without native support the schema is described in the prompt and JSON mode is requested
+	model := &recordingModel{}
+	provider := &LangChainProvider{llm: model, providerType: ProviderTypeOllama, logger: logger, factory: &OllamaModelFactory{}}
+	_, err := provider.GenerateCompletion(context.Background(), "Is it ok?", ProviderOptions{ResponseSchema: testSchema})
+	require.NoError(t, err)
+	assert.Contains(t, model.prompt, `"additionalProperties":false`)
+	assert.True(t, model.options.JSONMode)
+
+	// With native support the structured model is used and created once
+	structured := &structuredFactory{model: &recordingModel{}}
+	model = &recordingModel{}
+	provider = &LangChainProvider{llm: model, providerType: ProviderTypeOpenAI, logger: logger, factory: structured}
+	for i := 0; i < 2; i++ {
+		_, err = provider.GenerateCompletion(context.Background(), "Is it ok?", ProviderOptions{ResponseSchema: testSchema})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, structured.created)
+	assert.Equal(t, "Is it ok?", structured.model.prompt)
+	assert.Empty(t, model.prompt)
+
+	_, err = provider.GenerateCompletion(context.Background(), "Hello", ProviderOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "Hello", model.prompt)
+}
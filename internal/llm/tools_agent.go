@@ -0,0 +1,225 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/tmc/langchaingo/agents"
+	"github.com/tmc/langchaingo/callbacks"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/schema"
+	"github.com/tmc/langchaingo/tools"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/errors"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+// toolCallingProviders are the provider types whose langchaingo clients support
+// native tool calling, which the tool-calling agent needs
+var toolCallingProviders = map[string]bool{
+	ProviderTypeOpenAI:    true,
+	ProviderTypeAnthropic: true,
+}
+
+// resolveAgentType returns the agent implementation a provider runs: providers
+// without native tool calling keep the conversational agent
+func resolveAgentType(agentType, providerType string) string {
+	if agentType == config.AgentTypeTools && !toolCallingProviders[providerType] {
+		return config.AgentTypeConversational
+	}
+	return agentType
+}
+
+// SchemaTool is implemented by agent tools that declare the JSON schema of their
+// input, which the tool-calling agent sends to the model
+type SchemaTool interface {
+	Schema() map[string]interface{}
+}
+
+// ToolSchema returns the input schema of an agent tool, or an empty object
+// schema when the tool declares none
+func ToolSchema(tool tools.Tool) map[string]interface{} {
+	if schemaTool, ok := tool.(SchemaTool); ok {
+		return ObjectSchema(schemaTool.Schema())
+	}
+	return ObjectSchema(nil)
+}
+
+// ObjectSchema returns a tool's input schema for native tool calling. Anthropic
+// and Gemini reject tools whose schema is missing or is not an object schema, which
+// MCP servers omit for tools without parameters.
+func ObjectSchema(schema map[string]interface{}) map[string]interface{} {
+	if len(schema) == 0 {
+		return map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+	}
+	if _, ok := schema["type"]; !ok {
+		withType := make(map[string]interface{}, len(schema)+1)
+		for key, value := range schema {
+			withType[key] = value
+		}
+		withType["type"] = "object"
+		return withType
+	}
+	return schema
+}
+
+// agentTemperature returns the temperature of the provider's configuration, or the
+// conversational agent's 0.1 when none is set
+func (p *LangChainProvider) agentTemperature() float64 {
+	if temperature, ok := p.config["temperature"].(float64); ok && temperature > 0 {
+		return temperature
+	}
+	return 0.1
+}
+
+// runToolsAgent answers with the provider's native tool calling: the model is given
+// the tools' schemas, the tools it calls are run and their results sent back, until
+// it answers without calling a tool. It reports to the callbacks handler like the
+// conversational agent does, so intermediate messages and scratchpads work the same.
+func (p *LangChainProvider) runToolsAgent(ctx context.Context,
+	model llms.Model,
+	userDisplayName string,
+	systemPrompt string,
+	prompt string,
+	history []RequestMessage,
+	llmTools []tools.Tool,
+	callbackHandler callbacks.Handler,
+	maxAgentIterations int,
+) (string, error) {
+	byName := make(map[string]tools.Tool, len(llmTools))
+	definitions := make([]llms.Tool, 0, len(llmTools))
+	for _, tool := range llmTools {
+		byName[tool.Name()] = tool
+		definitions = append(definitions, llms.Tool{
+			Type: "function",
+			Function: &llms.FunctionDefinition{
+				Name:        tool.Name(),
+				Description: tool.Description(),
+				Parameters:  ToolSchema(tool),
+			},
+		})
+	}
+
+	system := strings.TrimSpace(fmt.Sprintf("%s\n\nThe user you are interacting with is named %q. Use the tools when they help answer, one or several as needed.", systemPrompt, userDisplayName))
+	messages := []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeSystem, system)}
+	for _, msg := range history {
+		messages = append(messages, historyMessages(msg)...)
+	}
+	messages = append(messages, llms.TextParts(llms.ChatMessageTypeHuman, prompt))
+
+	options := []llms.CallOption{llms.WithTemperature(p.agentTemperature())}
+	if len(definitions) > 0 {
+		options = append(options, llms.WithTools(definitions))
+	}
+
+	for i := 0; i < maxAgentIterations; i++ {
+		resp, err := model.GenerateContent(ctx, messages, options...)
+		if err != nil {
+			p.logger.ErrorKV("LangChainGo tool-calling agent request failed", "error", err)
+			return "", errors.WrapLLMError(err, "request_failed", "Failed to generate completion from LangChainGo")
+		}
+		if len(resp.Choices) == 0 {
+			return "", fmt.Errorf("empty response from model")
+		}
+		choice := mergeChoices(resp.Choices)
+		if callbackHandler != nil && choice.Content != "" {
+			callbackHandler.HandleChainEnd(ctx, map[string]any{"text": choice.Content})
+		}
+
+		if len(choice.ToolCalls) == 0 {
+			if callbackHandler != nil {
+				callbackHandler.HandleAgentFinish(ctx, schema.AgentFinish{ReturnValues: map[string]any{"output": choice.Content}, Log: choice.Content})
+			}
+			return choice.Content, nil
+		}
+
+		// Each call and its result are sent back as their own pair of messages,
+		// which is the shape both the OpenAI and Anthropic clients accept
+		for _, call := range choice.ToolCalls {
+			if call.FunctionCall == nil {
+				continue
+			}
+			if strings.TrimSpace(call.FunctionCall.Arguments) == "" {
+				call.FunctionCall.Arguments = "{}"
+			}
+			if callbackHandler != nil {
+				callbackHandler.HandleAgentAction(ctx, schema.AgentAction{
+					Tool:      call.FunctionCall.Name,
+					ToolInput: call.FunctionCall.Arguments,
+					Log:       choice.Content,
+					ToolID:    call.ID,
+				})
+			}
+			observation, err := callAgentTool(ctx, byName, call)
+			if err != nil {
+				return "", err
+			}
+			messages = append(messages,
+				llms.MessageContent{Role: llms.ChatMessageTypeAI, Parts: []llms.ContentPart{call}},
+				llms.MessageContent{Role: llms.ChatMessageTypeTool, Parts: []llms.ContentPart{llms.ToolCallResponse{
+					ToolCallID: call.ID,
+					Name:       call.FunctionCall.Name,
+					Content:    observation,
+				}}},
+			)
+		}
+	}
+
+	if callbackHandler != nil {
+		callbackHandler.HandleAgentFinish(ctx, schema.AgentFinish{ReturnValues: map[string]any{"output": agents.ErrNotFinished.Error()}})
+	}
+	return "", errors.WrapLLMError(agents.ErrNotFinished, "request_failed", "Failed to generate completion from LangChainGo")
+}
+
+// callAgentTool runs a tool the model called and returns what to tell the model.
+// A failed tool is reported to the model so it can recover, unless the request
+// itself was stopped, which ends the run.
+func callAgentTool(ctx context.Context, byName map[string]tools.Tool, call llms.ToolCall) (string, error) {
+	tool, ok := byName[call.FunctionCall.Name]
+	if !ok {
+		return fmt.Sprintf("%s is not a valid tool, try another one", call.FunctionCall.Name), nil
+	}
+	output, err := tool.Call(ctx, call.FunctionCall.Arguments)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", err
+		}
+		return "Error: " + err.Error(), nil
+	}
+	return output, nil
+}
+
+// historyMessages converts a history message to langchaingo messages. An earlier
+// tool result becomes the model's call and the tool's response, as in the loop
+// below, so the model sees it the way it sees its own tool calls.
+func historyMessages(msg RequestMessage) []llms.MessageContent {
+	if msg.Role != "tool" || msg.ToolCallID == "" {
+		return []llms.MessageContent{llms.TextParts(chatMessageType(msg.Role), msg.Content)}
+	}
+	args := msg.ToolArgs
+	if strings.TrimSpace(args) == "" {
+		args = "{}"
+	}
+	call := llms.ToolCall{ID: msg.ToolCallID, Type: "function", FunctionCall: &llms.FunctionCall{Name: msg.ToolName, Arguments: args}}
+	return []llms.MessageContent{
+		{Role: llms.ChatMessageTypeAI, Parts: []llms.ContentPart{call}},
+		{Role: llms.ChatMessageTypeTool, Parts: []llms.ContentPart{llms.ToolCallResponse{
+			ToolCallID: msg.ToolCallID,
+			Name:       msg.ToolName,
+			Content:    msg.Content,
+		}}},
+	}
+}
+
+// chatMessageType maps a request message role to a langchaingo message type
+func chatMessageType(role string) llms.ChatMessageType {
+	switch role {
+	case "assistant":
+		return llms.ChatMessageTypeAI
+	case "system":
+		return llms.ChatMessageTypeSystem
+	default:
+		return llms.ChatMessageTypeHuman
+	}
+}
@@ -0,0 +1,142 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/schema"
+	"github.com/tmc/langchaingo/tools"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+// scriptedModel answers with its responses in turn and records the requests
+type scriptedModel struct {
+	responses []*llms.ContentChoice
+	requests  [][]llms.MessageContent
+	options   llms.CallOptions
+}
+
+func (m *scriptedModel) GenerateContent(_ context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	m.requests = append(m.requests, append([]llms.MessageContent(nil), messages...))
+	for _, option := range options {
+		option(&m.options)
+	}
+	choice := m.responses[0]
+	if len(m.responses) > 1 {
+		m.responses = m.responses[1:]
+	}
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{choice}}, nil
+}
+
+func (m *scriptedModel) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
+
+// schemaTool is upperTool with a declared input schema
+type schemaTool struct{ upperTool }
+
+func (schemaTool) Schema() map[string]interface{} {
+	return map[string]interface{}{"properties": map[string]interface{}{"text": map[string]interface{}{"type": "string"}}}
+}
+
+type agentRecorder struct {
+	toolRecorder
+	actions []schema.AgentAction
+	texts   []string
+}
+
+func (r *agentRecorder) HandleAgentAction(_ context.Context, action schema.AgentAction) {
+	r.actions = append(r.actions, action)
+}
+
+func (r *agentRecorder) HandleChainEnd(_ context.Context, outputs map[string]any) {
+	r.texts = append(r.texts, outputs["text"].(string))
+}
+
+func toolCall(id, name, arguments string) llms.ToolCall {
+	return llms.ToolCall{ID: id, Type: "function", FunctionCall: &llms.FunctionCall{Name: name, Arguments: arguments}}
+}
+
+func TestToolsAgentRunsToolCalls(t *testing.T) {
+	model := &scriptedModel{responses: []*llms.ContentChoice{
+		{Content: "Let me shout.", ToolCalls: []llms.ToolCall{toolCall("call_1", "upper", `pods`), toolCall("call_2", "missing", "")}},
+		{Content: "PODS it is."},
+	}}
+	provider := &LangChainProvider{llm: model, providerType: ProviderTypeOpenAI, agentType: config.AgentTypeTools, logger: logging.New("test", logging.LevelError),
+		config: map[string]interface{}{"temperature": 0.4}}
+	recorder := &agentRecorder{}
+
+	answer, err := provider.GenerateAgentCompletion(context.Background(), "Ada", "Be helpful.", "shout pods",
+		[]RequestMessage{{Role: "user", Content: "hi"}, {Role: "assistant", Content: "hello"}}, []tools.Tool{schemaTool{}}, recorder, 5)
+	require.NoError(t, err)
+	assert.Equal(t, "PODS it is.", answer)
+
+	// The provider's temperature applies, and the tools are declared with their schemas
+	assert.Equal(t, 0.4, model.options.Temperature)
+	require.Len(t, model.options.Tools, 1)
+	assert.Equal(t, "object", model.options.Tools[0].Function.Parameters.(map[string]interface{})["type"])
+
+	// Each call and its result follow the conversation, one pair of messages per call
+	require.Len(t, model.requests, 2)
+	second := model.requests[1]
+	require.Len(t, second, 8)
+	assert.Equal(t, llms.ChatMessageTypeSystem, second[0].Role)
+	assert.Contains(t, second[0].Parts[0].(llms.TextContent).Text, `named "Ada"`)
+	assert.Equal(t, llms.ChatMessageTypeAI, second[2].Role)
+	assert.Equal(t, llms.ChatMessageTypeAI, second[4].Role)
+	assert.Equal(t, llms.ToolCallResponse{ToolCallID: "call_1", Name: "upper", Content: "PODS"}, second[5].Parts[0])
+	assert.Equal(t, "{}", second[6].Parts[0].(llms.ToolCall).FunctionCall.Arguments)
+	assert.Equal(t, "missing is not a valid tool, try another one", second[7].Parts[0].(llms.ToolCallResponse).Content)
+
+	// The handler sees the run like a conversational agent run
+	assert.Equal(t, []string{"Let me shout.", "PODS it is."}, recorder.texts)
+	require.Len(t, recorder.actions, 2)
+	assert.Equal(t, schema.AgentAction{Tool: "upper", ToolInput: "pods", Log: "Let me shout.", ToolID: "call_1"}, recorder.actions[0])
+	assert.Equal(t, []string{"start pods", "end PODS"}, recorder.events)
+}
+
+// failingTool always fails
+type failingTool struct{ upperTool }
+
+func (failingTool) Call(context.Context, string) (string, error) {
+	return "", fmt.Errorf("permission denied")
+}
+
+func TestToolsAgentStopsAtMaxIterations(t *testing.T) {
+	model := &scriptedModel{responses: []*llms.ContentChoice{{ToolCalls: []llms.ToolCall{toolCall("call_1", "upper", "{}")}}}}
+	provider := &LangChainProvider{llm: model, providerType: ProviderTypeAnthropic, agentType: config.AgentTypeTools, logger: logging.New("test", logging.LevelError)}
+
+	_, err := provider.GenerateAgentCompletion(context.Background(), "Ada", "", "loop", nil, []tools.Tool{failingTool{}}, nil, 3)
+	require.Error(t, err)
+	assert.Len(t, model.requests, 3)
+	assert.Equal(t, 0.1, model.options.Temperature, "without a configured temperature")
+	// A failing tool is reported to the model instead of ending the run
+	assert.Equal(t, "Error: permission denied", model.requests[1][3].Parts[0].(llms.ToolCallResponse).Content)
+}
+
+func TestToolsAgentFallsBackWithoutToolCalling(t *testing.T) {
+	provider, err := NewLangChainProviderFactory(map[string]interface{}{
+		"type": ProviderTypeOllama, "model": "llama3.1", "base_url": "http://localhost:11434", "agent_type": config.AgentTypeTools,
+	}, logging.New("test", logging.LevelError))
+	require.NoError(t, err)
+	assert.Equal(t, config.AgentTypeConversational, provider.(*LangChainProvider).agentType)
+}
+
+func TestHistoryMessagesSendsToolResultsAsToolCalls(t *testing.T) {
+	text := historyMessages(RequestMessage{Role: "assistant", Content: "hi"})
+	require.Len(t, text, 1)
+	assert.Equal(t, llms.ChatMessageTypeAI, text[0].Role)
+
+	messages := historyMessages(RequestMessage{Role: "tool", Content: "ok", ToolName: "get_status", ToolCallID: "history_2"})
+	require.Len(t, messages, 2)
+	assert.Equal(t, llms.ChatMessageTypeAI, messages[0].Role)
+	assert.Equal(t, llms.ToolCall{ID: "history_2", Type: "function", FunctionCall: &llms.FunctionCall{Name: "get_status", Arguments: "{}"}}, messages[0].Parts[0])
+	assert.Equal(t, llms.ChatMessageTypeTool, messages[1].Role)
+	assert.Equal(t, llms.ToolCallResponse{ToolCallID: "history_2", Name: "get_status", Content: "ok"}, messages[1].Parts[0])
+}
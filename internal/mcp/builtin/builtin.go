@@ -0,0 +1,35 @@
+// Package builtin provides MCP servers written in Go that run inside the client,
+// so basic tools work without npm, npx or python in the image. They are configured
+// like external servers with "builtin" set to the server's name.
+package builtin
+
+import (
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+// version is reported by the built-in servers when they are initialized
+const version = "1.0.0"
+
+// New creates the built-in server named by the server's builtin setting. Its args
+// configure it: the directories the filesystem server may read, or the domains
+// the fetch server may reach. The issues server is configured by its issues setting.
+func New(serverCfg config.MCPServerConfig) (*server.MCPServer, error) {
+	switch serverCfg.Builtin {
+	case config.BuiltinFilesystem:
+		return NewFilesystemServer(serverCfg.Args)
+	case config.BuiltinFetch:
+		return NewFetchServer(serverCfg.Args), nil
+	case config.BuiltinTime:
+		return NewTimeServer(), nil
+	case config.BuiltinCalculator:
+		return NewCalculatorServer(), nil
+	case config.BuiltinIssues:
+		return NewIssuesServer(serverCfg.Issues)
+	default:
+		return nil, fmt.Errorf("unknown builtin server '%s'", serverCfg.Builtin)
+	}
+}
@@ -0,0 +1,63 @@
+package builtin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+// callTool calls a tool of a built-in server through an in-process client and
+// returns its text and whether it reported an error
+func callTool(t *testing.T, s *server.MCPServer, name string, args map[string]interface{}) (string, bool) {
+	t.Helper()
+	ctx := context.Background()
+	c, err := client.NewInProcessClient(s)
+	require.NoError(t, err)
+	require.NoError(t, c.Start(ctx))
+	defer func() { _ = c.Close() }()
+
+	initReq := mcp.InitializeRequest{}
+	initReq.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	_, err = c.Initialize(ctx, initReq)
+	require.NoError(t, err)
+
+	req := mcp.CallToolRequest{}
+	req.Params.Name = name
+	req.Params.Arguments = args
+	result, err := c.CallTool(ctx, req)
+	require.NoError(t, err)
+	require.NotEmpty(t, result.Content)
+	return result.Content[0].(mcp.TextContent).Text, result.IsError
+}
+
+func TestNew(t *testing.T) {
+	for _, name := range []string{"fetch", "time", "calculator"} {
+		s, err := New(config.MCPServerConfig{Builtin: name})
+		require.NoError(t, err, name)
+		assert.NotNil(t, s)
+	}
+
+	_, err := New(config.MCPServerConfig{Builtin: "filesystem"})
+	assert.Error(t, err, "filesystem requires directories")
+	_, err = New(config.MCPServerConfig{Builtin: "issues"})
+	assert.Error(t, err, "issues requires a tracker")
+	_, err = New(config.MCPServerConfig{Builtin: "shell"})
+	assert.Error(t, err)
+}
+
+func TestCalculatorServer(t *testing.T) {
+	text, isError := callTool(t, NewCalculatorServer(), "calculate", map[string]interface{}{"expression": "(1200 * 1.08) / 12"})
+	assert.False(t, isError)
+	assert.Equal(t, "108", text)
+
+	text, isError = callTool(t, NewCalculatorServer(), "calculate", map[string]interface{}{"expression": "1 / 0"})
+	assert.True(t, isError)
+	assert.Contains(t, text, "division by zero")
+}
@@ -0,0 +1,276 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// NewCalculatorServer creates a server that evaluates arithmetic expressions,
+// which LLMs often get wrong
+func NewCalculatorServer() *server.MCPServer {
+	s := server.NewMCPServer("calculator", version, server.WithToolCapabilities(false))
+	s.AddTool(mcp.NewTool("calculate",
+		mcp.WithDescription("Evaluate an arithmetic expression exactly. Supports + - * / % ^, parentheses, "+
+			"the constants pi and e, and the functions sqrt, abs, round, floor, ceil, ln, log10, sin, cos, tan, min and max."),
+		mcp.WithString("expression", mcp.Required(), mcp.Description("Expression to evaluate, e.g. (1200 * 1.08) / 12")),
+		mcp.WithReadOnlyHintAnnotation(true),
+	), calculate)
+	return s
+}
+
+func calculate(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	expression, err := request.RequireString("expression")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	value, err := Evaluate(expression)
+	if err != nil {
+		return mcp.NewToolResultErrorf("cannot evaluate '%s': %v", expression, err), nil
+	}
+	return mcp.NewToolResultText(strconv.FormatFloat(value, 'g', -1, 64)), nil
+}
+
+// Evaluate computes the value of an arithmetic expression. ^ binds tighter than
+// unary minus and is right associative, so -2^2 is -4 and 2^3^2 is 512.
+func Evaluate(expression string) (float64, error) {
+	p := &parser{input: expression}
+	value, err := p.expression()
+	if err != nil {
+		return 0, err
+	}
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		return 0, fmt.Errorf("unexpected '%c' at position %d", p.input[p.pos], p.pos+1)
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, fmt.Errorf("result is not a finite number")
+	}
+	return value, nil
+}
+
+// parser is a recursive descent parser over the grammar:
+//
+//	expression = term {("+" | "-") term}
+//	term       = unary {("*" | "/" | "%") unary}
+//	unary      = ("+" | "-") unary | power
+//	power      = primary ["^" unary]
+//	primary    = number | name | name "(" expression {"," expression} ")" | "(" expression ")"
+type parser struct {
+	input string
+	pos   int
+}
+
+func (p *parser) skipSpaces() {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// consume skips spaces and reports whether the next character is c, consuming it
+func (p *parser) consume(c byte) bool {
+	p.skipSpaces()
+	if p.pos < len(p.input) && p.input[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expression() (float64, error) {
+	value, err := p.term()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		switch {
+		case p.consume('+'):
+			right, err := p.term()
+			if err != nil {
+				return 0, err
+			}
+			value += right
+		case p.consume('-'):
+			right, err := p.term()
+			if err != nil {
+				return 0, err
+			}
+			value -= right
+		default:
+			return value, nil
+		}
+	}
+}
+
+func (p *parser) term() (float64, error) {
+	value, err := p.unary()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		var op byte
+		switch {
+		case p.consume('*'):
+			op = '*'
+		case p.consume('/'):
+			op = '/'
+		case p.consume('%'):
+			op = '%'
+		default:
+			return value, nil
+		}
+		right, err := p.unary()
+		if err != nil {
+			return 0, err
+		}
+		switch op {
+		case '*':
+			value *= right
+		case '/':
+			if right == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			value /= right
+		case '%':
+			if right == 0 {
+				return 0, fmt.Errorf("modulo by zero")
+			}
+			value = math.Mod(value, right)
+		}
+	}
+}
+
+func (p *parser) unary() (float64, error) {
+	if p.consume('-') {
+		value, err := p.unary()
+		return -value, err
+	}
+	if p.consume('+') {
+		return p.unary()
+	}
+	return p.power()
+}
+
+func (p *parser) power() (float64, error) {
+	base, err := p.primary()
+	if err != nil {
+		return 0, err
+	}
+	if !p.consume('^') {
+		return base, nil
+	}
+	exponent, err := p.unary()
+	if err != nil {
+		return 0, err
+	}
+	return math.Pow(base, exponent), nil
+}
+
+func (p *parser) primary() (float64, error) {
+	p.skipSpaces()
+	if p.pos >= len(p.input) {
+		return 0, fmt.Errorf("unexpected end of expression")
+	}
+	if p.consume('(') {
+		value, err := p.expression()
+		if err != nil {
+			return 0, err
+		}
+		if !p.consume(')') {
+			return 0, fmt.Errorf("missing ')'")
+		}
+		return value, nil
+	}
+
+	start := p.pos
+	c := rune(p.input[p.pos])
+	switch {
+	case unicode.IsDigit(c) || c == '.':
+		for p.pos < len(p.input) && (unicode.IsDigit(rune(p.input[p.pos])) || p.input[p.pos] == '.' || p.input[p.pos] == '_') {
+			p.pos++
+		}
+		// Exponent, as in 1.5e3
+		if p.pos < len(p.input) && (p.input[p.pos] == 'e' || p.input[p.pos] == 'E') {
+			next := p.pos + 1
+			if next < len(p.input) && (p.input[next] == '+' || p.input[next] == '-') {
+				next++
+			}
+			if next < len(p.input) && unicode.IsDigit(rune(p.input[next])) {
+				p.pos = next
+				for p.pos < len(p.input) && unicode.IsDigit(rune(p.input[p.pos])) {
+					p.pos++
+				}
+			}
+		}
+		value, err := strconv.ParseFloat(strings.ReplaceAll(p.input[start:p.pos], "_", ""), 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number '%s'", p.input[start:p.pos])
+		}
+		return value, nil
+	case unicode.IsLetter(c):
+		for p.pos < len(p.input) && (unicode.IsLetter(rune(p.input[p.pos])) || unicode.IsDigit(rune(p.input[p.pos]))) {
+			p.pos++
+		}
+		return p.name(strings.ToLower(p.input[start:p.pos]))
+	default:
+		return 0, fmt.Errorf("unexpected '%c' at position %d", c, p.pos+1)
+	}
+}
+
+// name evaluates a constant or a function call
+func (p *parser) name(name string) (float64, error) {
+	switch name {
+	case "pi":
+		return math.Pi, nil
+	case "e":
+		return math.E, nil
+	}
+
+	if !p.consume('(') {
+		return 0, fmt.Errorf("unknown name '%s'", name)
+	}
+	var args []float64
+	for {
+		value, err := p.expression()
+		if err != nil {
+			return 0, err
+		}
+		args = append(args, value)
+		if p.consume(')') {
+			break
+		}
+		if !p.consume(',') {
+			return 0, fmt.Errorf("missing ')' after the arguments of %s", name)
+		}
+	}
+
+	if name == "min" || name == "max" {
+		result := args[0]
+		for _, arg := range args[1:] {
+			if name == "min" {
+				result = math.Min(result, arg)
+			} else {
+				result = math.Max(result, arg)
+			}
+		}
+		return result, nil
+	}
+	functions := map[string]func(float64) float64{
+		"sqrt": math.Sqrt, "abs": math.Abs, "round": math.Round, "floor": math.Floor, "ceil": math.Ceil,
+		"ln": math.Log, "log10": math.Log10, "sin": math.Sin, "cos": math.Cos, "tan": math.Tan,
+	}
+	fn, ok := functions[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown function '%s'", name)
+	}
+	if len(args) != 1 {
+		return 0, fmt.Errorf("%s takes one argument", name)
+	}
+	return fn(args[0]), nil
+}
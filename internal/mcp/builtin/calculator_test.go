@@ -0,0 +1,38 @@
+package builtin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		expression string
+		want       float64
+	}{
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"10 / 4", 2.5},
+		{"10 % 4", 2},
+		{"-2^2", -4},
+		{"2^3^2", 512},
+		{"2 * -3", -6},
+		{"1.5e3 + 1_000", 2500},
+		{"sqrt(16) + abs(-2)", 6},
+		{"max(1, 7, 3) - min(4, 2)", 5},
+		{"round(2 * pi)", 6},
+		{"ln(e)", 1},
+	}
+	for _, tt := range tests {
+		got, err := Evaluate(tt.expression)
+		if assert.NoError(t, err, tt.expression) {
+			assert.InDelta(t, tt.want, got, 1e-9, tt.expression)
+		}
+	}
+
+	for _, expression := range []string{"", "1 +", "(1 + 2", "2 ** 3", "foo(1)", "x", "1 / 0", "sqrt(1, 2)", "sqrt(-1)"} {
+		_, err := Evaluate(expression)
+		assert.Error(t, err, expression)
+	}
+}
@@ -0,0 +1,82 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// clock answers date and time questions, which LLMs cannot know on their own
+type clock struct {
+	now func() time.Time
+}
+
+// NewTimeServer creates a server that tells the current time and converts times between timezones
+func NewTimeServer() *server.MCPServer {
+	return newTimeServer(&clock{now: time.Now})
+}
+
+func newTimeServer(c *clock) *server.MCPServer {
+	s := server.NewMCPServer("time", version, server.WithToolCapabilities(false))
+	s.AddTool(mcp.NewTool("get_current_time",
+		mcp.WithDescription("Get the current date and time in a timezone"),
+		mcp.WithString("timezone", mcp.Description("IANA timezone name such as America/New_York (default UTC)")),
+		mcp.WithReadOnlyHintAnnotation(true),
+	), c.currentTime)
+	s.AddTool(mcp.NewTool("convert_time",
+		mcp.WithDescription("Convert a time of day today from one timezone to another"),
+		mcp.WithString("time", mcp.Required(), mcp.Description("Time of day in 24-hour HH:MM format")),
+		mcp.WithString("source_timezone", mcp.Required(), mcp.Description("IANA timezone name of the given time")),
+		mcp.WithString("target_timezone", mcp.Required(), mcp.Description("IANA timezone name to convert to")),
+		mcp.WithReadOnlyHintAnnotation(true),
+	), c.convertTime)
+	return s
+}
+
+// describeTime formats a time with its weekday and timezone
+func describeTime(t time.Time) string {
+	return fmt.Sprintf("%s %s (%s, UTC%s)", t.Weekday(), t.Format("2006-01-02 15:04:05"), t.Location(), t.Format("-07:00"))
+}
+
+func (c *clock) currentTime(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name := request.GetString("timezone", "UTC")
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return mcp.NewToolResultErrorf("unknown timezone '%s'", name), nil
+	}
+	return mcp.NewToolResultText(describeTime(c.now().In(location))), nil
+}
+
+func (c *clock) convertTime(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	clockTime, err := request.RequireString("time")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	sourceName, err := request.RequireString("source_timezone")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	targetName, err := request.RequireString("target_timezone")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	source, err := time.LoadLocation(sourceName)
+	if err != nil {
+		return mcp.NewToolResultErrorf("unknown timezone '%s'", sourceName), nil
+	}
+	target, err := time.LoadLocation(targetName)
+	if err != nil {
+		return mcp.NewToolResultErrorf("unknown timezone '%s'", targetName), nil
+	}
+	parsed, err := time.Parse("15:04", clockTime)
+	if err != nil {
+		return mcp.NewToolResultErrorf("invalid time '%s' (use HH:MM)", clockTime), nil
+	}
+
+	today := c.now().In(source)
+	sourceTime := time.Date(today.Year(), today.Month(), today.Day(), parsed.Hour(), parsed.Minute(), 0, 0, source)
+	return mcp.NewToolResultText(fmt.Sprintf("%s\n%s", describeTime(sourceTime), describeTime(sourceTime.In(target)))), nil
+}
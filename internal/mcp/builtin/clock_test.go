@@ -0,0 +1,26 @@
+package builtin
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeServer(t *testing.T) {
+	now := time.Date(2026, 10, 16, 14, 30, 0, 0, time.UTC)
+	s := newTimeServer(&clock{now: func() time.Time { return now }})
+
+	text, isError := callTool(t, s, "get_current_time", map[string]interface{}{"timezone": "Asia/Tokyo"})
+	assert.False(t, isError)
+	assert.Equal(t, "Friday 2026-10-16 23:30:00 (Asia/Tokyo, UTC+09:00)", text)
+
+	text, isError = callTool(t, s, "convert_time", map[string]interface{}{
+		"time": "09:00", "source_timezone": "America/New_York", "target_timezone": "Europe/Berlin",
+	})
+	assert.False(t, isError)
+	assert.Equal(t, "Friday 2026-10-16 09:00:00 (America/New_York, UTC-04:00)\nFriday 2026-10-16 15:00:00 (Europe/Berlin, UTC+02:00)", text)
+
+	_, isError = callTool(t, s, "get_current_time", map[string]interface{}{"timezone": "Mars/Base"})
+	assert.True(t, isError)
+}
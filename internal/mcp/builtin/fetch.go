@@ -0,0 +1,167 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/tuannvm/slack-mcp-client/internal/network"
+	"github.com/tuannvm/slack-mcp-client/internal/rag"
+)
+
+const (
+	fetchTimeout       = 30 * time.Second
+	fetchMaxBodyBytes  = 5 << 20
+	fetchDefaultLength = 5000
+	fetchUserAgent     = "slack-mcp-client-fetch/1.0"
+)
+
+// fetcher fetches web pages and returns them as text. Without allowed domains it
+// reaches any public host but refuses private, loopback and link-local addresses,
+// so the LLM cannot be used to probe the network the client runs in.
+type fetcher struct {
+	httpClient     *http.Client
+	allowedDomains []string
+}
+
+// NewFetchServer creates a server that fetches URLs on the allowed domains and
+// their subdomains, or on any public host when none are given
+func NewFetchServer(allowedDomains []string) *server.MCPServer {
+	return newFetchServer(newFetcher(network.BaseTransport(), allowedDomains, len(allowedDomains) == 0))
+}
+
+// newFetcher creates a fetcher whose requests use a copy of base, with its proxy
+func newFetcher(base *http.Transport, allowedDomains []string, publicOnly bool) *fetcher {
+	f := &fetcher{allowedDomains: allowedDomains}
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	transport := base.Clone()
+	transport.DialContext = dialer.DialContext
+	if publicOnly {
+		network.RestrictToPublic(transport, dialer)
+	}
+	f.httpClient = &http.Client{
+		Timeout:   fetchTimeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return fmt.Errorf("stopped after 10 redirects")
+			}
+			return f.checkURL(req.URL)
+		},
+	}
+	return f
+}
+
+func newFetchServer(f *fetcher) *server.MCPServer {
+	s := server.NewMCPServer("fetch", version, server.WithToolCapabilities(false))
+	s.AddTool(mcp.NewTool("fetch",
+		mcp.WithDescription("Fetch a URL and return its content as text. HTML pages are converted to readable text. Long content is returned in parts; call again with start_index to read on."),
+		mcp.WithString("url", mcp.Required(), mcp.Description("The http or https URL to fetch")),
+		mcp.WithNumber("max_length", mcp.Description(fmt.Sprintf("Maximum number of characters to return (default %d)", fetchDefaultLength))),
+		mcp.WithNumber("start_index", mcp.Description("Character offset to start from, to continue reading truncated content (default 0)")),
+		mcp.WithReadOnlyHintAnnotation(true),
+	), f.fetch)
+	return s
+}
+
+// checkURL refuses non-http(s) URLs and hosts outside the allowed domains
+func (f *fetcher) checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("only http and https URLs can be fetched")
+	}
+	if len(f.allowedDomains) == 0 {
+		return nil
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, domain := range f.allowedDomains {
+		domain = strings.ToLower(domain)
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return nil
+		}
+	}
+	return fmt.Errorf("host %s is not an allowed domain", host)
+}
+
+func (f *fetcher) fetch(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	rawURL, err := request.RequireString("url")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	maxLength := request.GetInt("max_length", fetchDefaultLength)
+	startIndex := request.GetInt("start_index", 0)
+	if maxLength <= 0 || startIndex < 0 {
+		return mcp.NewToolResultError("max_length must be positive and start_index must not be negative"), nil
+	}
+
+	pageURL, err := url.Parse(rawURL)
+	if err != nil {
+		return mcp.NewToolResultErrorf("invalid URL '%s': %v", rawURL, err), nil
+	}
+	if err := f.checkURL(pageURL); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	text, err := f.get(ctx, pageURL)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(page(text, startIndex, maxLength)), nil
+}
+
+// get downloads a URL and returns its content as text
+func (f *fetcher) get(ctx context.Context, pageURL *url.URL) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", fetchUserAgent)
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch %s: %w", pageURL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode >= 400 {
+		return "", fmt.Errorf("failed to fetch %s: %s", pageURL, resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, fetchMaxBodyBytes))
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", pageURL, err)
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch {
+	case mediaType == "text/html" || mediaType == "application/xhtml+xml":
+		return rag.HTMLToText(string(body))
+	case strings.HasPrefix(mediaType, "text/") || strings.HasSuffix(mediaType, "json") || strings.HasSuffix(mediaType, "xml") || mediaType == "":
+		if !utf8.Valid(body) {
+			return "", fmt.Errorf("%s is not text", pageURL)
+		}
+		return string(body), nil
+	default:
+		return "", fmt.Errorf("unsupported content type %s at %s", mediaType, pageURL)
+	}
+}
+
+// page returns up to maxLength characters of text from startIndex, noting where
+// to continue when there is more
+func page(text string, startIndex, maxLength int) string {
+	runes := []rune(text)
+	if startIndex >= len(runes) {
+		return fmt.Sprintf("No more content: the text is %d characters long.", len(runes))
+	}
+	end := startIndex + maxLength
+	if end >= len(runes) {
+		return string(runes[startIndex:])
+	}
+	return fmt.Sprintf("%s\n\n[Content truncated. Call fetch with start_index=%d to read more.]", string(runes[startIndex:end]), end)
+}
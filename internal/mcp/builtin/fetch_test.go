@@ -0,0 +1,86 @@
+package builtin
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/network"
+)
+
+func TestFetchServer(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/page":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = fmt.Fprint(w, "<html><body><nav>menu</nav><main><h1>Status</h1><p>All systems go</p></main></body></html>")
+		case "/long":
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = fmt.Fprint(w, strings.Repeat("a", 30))
+		case "/image":
+			w.Header().Set("Content-Type", "image/png")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	s := newFetchServer(newFetcher(network.BaseTransport(), nil, false))
+	text, isError := callTool(t, s, "fetch", map[string]interface{}{"url": ts.URL + "/page"})
+	assert.False(t, isError)
+	assert.Contains(t, text, "All systems go")
+
+	text, isError = callTool(t, s, "fetch", map[string]interface{}{"url": ts.URL + "/long", "max_length": 20})
+	assert.False(t, isError)
+	assert.Contains(t, text, "start_index=20")
+	text, _ = callTool(t, s, "fetch", map[string]interface{}{"url": ts.URL + "/long", "start_index": 20})
+	assert.Equal(t, strings.Repeat("a", 10), text)
+
+	for _, path := range []string{"/image", "/missing"} {
+		_, isError = callTool(t, s, "fetch", map[string]interface{}{"url": ts.URL + path})
+		assert.True(t, isError, path)
+	}
+	_, isError = callTool(t, s, "fetch", map[string]interface{}{"url": "file:///etc/passwd"})
+	assert.True(t, isError)
+
+	// Without allowed domains, private addresses are refused
+	text, isError = callTool(t, NewFetchServer(nil), "fetch", map[string]interface{}{"url": ts.URL + "/page"})
+	assert.True(t, isError)
+	assert.Contains(t, text, "not public")
+
+	// With allowed domains, other hosts are refused
+	text, isError = callTool(t, NewFetchServer([]string{"example.com"}), "fetch", map[string]interface{}{"url": ts.URL + "/page"})
+	assert.True(t, isError)
+	assert.Contains(t, text, "not an allowed domain")
+}
+
+func TestFetchThroughProxyRefusesPrivateHosts(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = fmt.Fprint(w, "proxied page")
+	}))
+	defer proxy.Close()
+	proxyURL, err := url.Parse(proxy.URL)
+	require.NoError(t, err)
+
+	// The proxy makes the only connection, to a private address of its own
+	s := newFetchServer(newFetcher(&http.Transport{Proxy: http.ProxyURL(proxyURL)}, nil, true))
+	text, isError := callTool(t, s, "fetch", map[string]interface{}{"url": "http://93.184.216.34/status"})
+	assert.False(t, isError)
+	assert.Equal(t, "proxied page", text)
+
+	for _, target := range []string{"http://169.254.169.254/latest/meta-data", "http://localhost:8080/", "http://192.168.1.1/"} {
+		text, isError = callTool(t, s, "fetch", map[string]interface{}{"url": target})
+		assert.True(t, isError, target)
+		assert.Contains(t, text, "not public", target)
+	}
+	assert.Equal(t, []string{"http://93.184.216.34/status"}, proxied)
+}
@@ -0,0 +1,231 @@
+package builtin
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	maxReadBytes     = 1 << 20 // Largest file read_file returns
+	maxSearchResults = 200
+)
+
+// filesystem serves read-only access to a set of root directories. Paths are
+// resolved through symlinks before they are checked, so a link cannot lead outside
+// the roots.
+type filesystem struct {
+	roots []string // Absolute paths with symlinks resolved
+}
+
+// NewFilesystemServer creates a read-only filesystem server limited to the directories
+func NewFilesystemServer(directories []string) (*server.MCPServer, error) {
+	if len(directories) == 0 {
+		return nil, fmt.Errorf("filesystem server requires at least one directory")
+	}
+	fsys := &filesystem{}
+	for _, dir := range directories {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, fmt.Errorf("invalid directory '%s': %w", dir, err)
+		}
+		root, err := filepath.EvalSymlinks(abs)
+		if err != nil {
+			return nil, fmt.Errorf("directory '%s' is not accessible: %w", dir, err)
+		}
+		info, err := os.Stat(root)
+		if err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("'%s' is not a directory", dir)
+		}
+		fsys.roots = append(fsys.roots, root)
+	}
+
+	s := server.NewMCPServer("filesystem", version, server.WithToolCapabilities(false))
+	s.AddTool(mcp.NewTool("list_allowed_directories",
+		mcp.WithDescription("List the directories this server can read"),
+		mcp.WithReadOnlyHintAnnotation(true),
+	), fsys.listAllowedDirectories)
+	s.AddTool(mcp.NewTool("list_directory",
+		mcp.WithDescription("List the files and subdirectories of a directory"),
+		mcp.WithString("path", mcp.Required(), mcp.Description("Directory path, absolute or relative to the first allowed directory")),
+		mcp.WithReadOnlyHintAnnotation(true),
+	), fsys.listDirectory)
+	s.AddTool(mcp.NewTool("read_file",
+		mcp.WithDescription(fmt.Sprintf("Read a text file of up to %d KB", maxReadBytes>>10)),
+		mcp.WithString("path", mcp.Required(), mcp.Description("File path, absolute or relative to the first allowed directory")),
+		mcp.WithReadOnlyHintAnnotation(true),
+	), fsys.readFile)
+	s.AddTool(mcp.NewTool("search_files",
+		mcp.WithDescription("Recursively find files and directories whose name matches a glob pattern such as *.md"),
+		mcp.WithString("path", mcp.Required(), mcp.Description("Directory to search")),
+		mcp.WithString("pattern", mcp.Required(), mcp.Description("Glob pattern matched against names, e.g. *.go or README*")),
+		mcp.WithReadOnlyHintAnnotation(true),
+	), fsys.searchFiles)
+	s.AddTool(mcp.NewTool("get_file_info",
+		mcp.WithDescription("Get the type, size, permissions and modification time of a file or directory"),
+		mcp.WithString("path", mcp.Required(), mcp.Description("File or directory path")),
+		mcp.WithReadOnlyHintAnnotation(true),
+	), fsys.getFileInfo)
+	return s, nil
+}
+
+// resolve returns the real path of a path inside the roots. Relative paths are
+// resolved against the first root.
+func (f *filesystem) resolve(path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(f.roots[0], path)
+	}
+	resolved, err := filepath.EvalSymlinks(filepath.Clean(path))
+	if err != nil {
+		return "", fmt.Errorf("cannot access '%s': %w", path, err)
+	}
+	for _, root := range f.roots {
+		if rel, err := filepath.Rel(root, resolved); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return resolved, nil
+		}
+	}
+	return "", fmt.Errorf("access denied: '%s' is outside the allowed directories", path)
+}
+
+func (f *filesystem) listAllowedDirectories(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return mcp.NewToolResultText(strings.Join(f.roots, "\n")), nil
+}
+
+func (f *filesystem) listDirectory(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	dir, err := f.resolve(path)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return mcp.NewToolResultErrorf("cannot list '%s': %v", path, err), nil
+	}
+
+	var listing strings.Builder
+	for _, entry := range entries {
+		if entry.IsDir() {
+			fmt.Fprintf(&listing, "[DIR] %s\n", entry.Name())
+		} else {
+			fmt.Fprintf(&listing, "[FILE] %s\n", entry.Name())
+		}
+	}
+	if listing.Len() == 0 {
+		return mcp.NewToolResultText("(empty directory)"), nil
+	}
+	return mcp.NewToolResultText(strings.TrimSuffix(listing.String(), "\n")), nil
+}
+
+func (f *filesystem) readFile(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	file, err := f.resolve(path)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return mcp.NewToolResultErrorf("cannot read '%s': %v", path, err), nil
+	}
+	if info.IsDir() {
+		return mcp.NewToolResultErrorf("'%s' is a directory", path), nil
+	}
+	if info.Size() > maxReadBytes {
+		return mcp.NewToolResultErrorf("'%s' is %d KB, larger than the %d KB limit", path, info.Size()>>10, maxReadBytes>>10), nil
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return mcp.NewToolResultErrorf("cannot read '%s': %v", path, err), nil
+	}
+	if bytes.IndexByte(content, 0) >= 0 {
+		return mcp.NewToolResultErrorf("'%s' is a binary file", path), nil
+	}
+	return mcp.NewToolResultText(string(content)), nil
+}
+
+func (f *filesystem) searchFiles(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	pattern, err := request.RequireString("pattern")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return mcp.NewToolResultErrorf("invalid pattern '%s': %v", pattern, err), nil
+	}
+	dir, err := f.resolve(path)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// WalkDir does not follow symlinks, so the search stays inside the root
+	var matches []string
+	truncated := false
+	walkErr := filepath.WalkDir(dir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip unreadable entries
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if p == dir {
+			return nil
+		}
+		if ok, _ := filepath.Match(pattern, entry.Name()); ok {
+			if len(matches) == maxSearchResults {
+				truncated = true
+				return fs.SkipAll
+			}
+			matches = append(matches, p)
+		}
+		return nil
+	})
+	if walkErr != nil {
+		return mcp.NewToolResultErrorf("search failed: %v", walkErr), nil
+	}
+	if len(matches) == 0 {
+		return mcp.NewToolResultText("No matches found"), nil
+	}
+	sort.Strings(matches)
+	result := strings.Join(matches, "\n")
+	if truncated {
+		result += fmt.Sprintf("\n(showing the first %d matches)", maxSearchResults)
+	}
+	return mcp.NewToolResultText(result), nil
+}
+
+func (f *filesystem) getFileInfo(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	file, err := f.resolve(path)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return mcp.NewToolResultErrorf("cannot access '%s': %v", path, err), nil
+	}
+	kind := "file"
+	if info.IsDir() {
+		kind = "directory"
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("path: %s\ntype: %s\nsize: %d bytes\nmodified: %s\npermissions: %s",
+		file, kind, info.Size(), info.ModTime().UTC().Format("2006-01-02T15:04:05Z"), info.Mode().Perm())), nil
+}
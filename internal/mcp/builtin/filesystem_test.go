@@ -0,0 +1,47 @@
+package builtin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilesystemServer(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "docs"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "docs", "runbook.md"), []byte("# Runbook"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "image.bin"), []byte{0x89, 0x00, 0x01}, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0o644))
+	require.NoError(t, os.Symlink(filepath.Join(outside, "secret.txt"), filepath.Join(root, "link.txt")))
+
+	s, err := NewFilesystemServer([]string{root})
+	require.NoError(t, err)
+
+	text, isError := callTool(t, s, "read_file", map[string]interface{}{"path": "docs/runbook.md"})
+	assert.False(t, isError)
+	assert.Equal(t, "# Runbook", text)
+
+	text, isError = callTool(t, s, "list_directory", map[string]interface{}{"path": root})
+	assert.False(t, isError)
+	assert.Contains(t, text, "[DIR] docs")
+	assert.Contains(t, text, "[FILE] image.bin")
+
+	text, isError = callTool(t, s, "search_files", map[string]interface{}{"path": ".", "pattern": "*.md"})
+	assert.False(t, isError)
+	assert.Contains(t, text, filepath.Join("docs", "runbook.md"))
+
+	// Paths outside the root, directly or through a symlink or "..", are refused
+	for _, path := range []string{filepath.Join(outside, "secret.txt"), "link.txt", "../" + filepath.Base(outside) + "/secret.txt"} {
+		text, isError = callTool(t, s, "read_file", map[string]interface{}{"path": path})
+		assert.True(t, isError, path)
+		assert.NotContains(t, text, "secret\n", path)
+	}
+
+	text, isError = callTool(t, s, "read_file", map[string]interface{}{"path": "image.bin"})
+	assert.True(t, isError)
+	assert.Contains(t, text, "binary")
+}
@@ -0,0 +1,256 @@
+package builtin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+const (
+	issuesTimeout      = 30 * time.Second
+	issuesMaxBodyBytes = 1 << 20
+)
+
+// issueData is what the issue templates are rendered with
+type issueData struct {
+	Title     string
+	Summary   string
+	Priority  string
+	Requester string
+	Date      string
+}
+
+// issueTracker files issues in Jira or GitHub from the configured templates
+type issueTracker struct {
+	cfg        config.MCPIssuesConfig
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// NewIssuesServer creates a server whose create_issue tool files a conversation
+// summary as an issue in the configured Jira project or GitHub repository
+func NewIssuesServer(cfg config.MCPIssuesConfig) (*server.MCPServer, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return newIssuesServer(&issueTracker{cfg: cfg, httpClient: &http.Client{Timeout: issuesTimeout}, now: time.Now}), nil
+}
+
+func newIssuesServer(t *issueTracker) *server.MCPServer {
+	destination := "the Jira project " + t.cfg.Project
+	if t.cfg.Provider == config.IssueProviderGitHub {
+		destination = "the GitHub repository " + t.cfg.Repository
+	}
+	s := server.NewMCPServer("issues", version, server.WithToolCapabilities(false))
+	s.AddTool(mcp.NewTool("create_issue",
+		mcp.WithDescription(fmt.Sprintf("File an issue (ticket) in %s. Use it when the user asks to file, open or create a ticket or issue. Summarize the relevant conversation: what happened, the impact, what was tried and what is asked for.", destination)),
+		mcp.WithString("title", mcp.Required(), mcp.Description("Short, specific issue title")),
+		mcp.WithString("summary", mcp.Required(), mcp.Description("Summary of the conversation that the issue is about, in markdown")),
+		mcp.WithString("priority", mcp.Description("Priority the user asked for, such as High or Low")),
+		mcp.WithString("requester", mcp.Description("Name of the person asking for the issue")),
+		mcp.WithDestructiveHintAnnotation(false),
+	), t.createIssue)
+	return s
+}
+
+func (t *issueTracker) createIssue(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	title, err := request.RequireString("title")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	summary, err := request.RequireString("summary")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	data := issueData{
+		Title:     strings.TrimSpace(title),
+		Summary:   strings.TrimSpace(summary),
+		Priority:  request.GetString("priority", ""),
+		Requester: request.GetString("requester", ""),
+		Date:      t.now().UTC().Format("2006-01-02"),
+	}
+
+	var key, link string
+	if t.cfg.Provider == config.IssueProviderGitHub {
+		key, link, err = t.createGitHubIssue(ctx, data)
+	} else {
+		key, link, err = t.createJiraIssue(ctx, data)
+	}
+	if err != nil {
+		return mcp.NewToolResultErrorf("failed to create the issue: %v", err), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Created issue %s: %s", key, link)), nil
+}
+
+// createJiraIssue creates an issue with the Jira REST API and returns its key and link
+func (t *issueTracker) createJiraIssue(ctx context.Context, data issueData) (string, string, error) {
+	fields := map[string]interface{}{
+		"project":   map[string]string{"key": t.cfg.Project},
+		"issuetype": map[string]string{"name": t.cfg.IssueType},
+	}
+	for name, value := range t.cfg.Fields {
+		rendered, err := renderValue(value, data)
+		if err != nil {
+			return "", "", fmt.Errorf("field %s: %w", name, err)
+		}
+		fields[name] = rendered
+	}
+	summary, description, labels, err := t.render(data)
+	if err != nil {
+		return "", "", err
+	}
+	fields["summary"] = summary
+	fields["description"] = description
+	if len(labels) > 0 {
+		fields["labels"] = labels
+	}
+
+	var created struct {
+		Key string `json:"key"`
+	}
+	base := strings.TrimRight(t.cfg.URL, "/")
+	if err := t.post(ctx, base+"/rest/api/2/issue", map[string]interface{}{"fields": fields}, &created); err != nil {
+		return "", "", err
+	}
+	return created.Key, base + "/browse/" + created.Key, nil
+}
+
+// createGitHubIssue creates an issue with the GitHub REST API and returns its number and link
+func (t *issueTracker) createGitHubIssue(ctx context.Context, data issueData) (string, string, error) {
+	title, body, labels, err := t.render(data)
+	if err != nil {
+		return "", "", err
+	}
+	issue := map[string]interface{}{"title": title, "body": body}
+	if len(labels) > 0 {
+		issue["labels"] = labels
+	}
+	if len(t.cfg.Assignees) > 0 {
+		issue["assignees"] = t.cfg.Assignees
+	}
+
+	var created struct {
+		Number  int    `json:"number"`
+		HTMLURL string `json:"html_url"`
+	}
+	url := fmt.Sprintf("%s/repos/%s/issues", strings.TrimRight(t.cfg.URL, "/"), t.cfg.Repository)
+	if err := t.post(ctx, url, issue, &created); err != nil {
+		return "", "", err
+	}
+	return fmt.Sprintf("%s#%d", t.cfg.Repository, created.Number), created.HTMLURL, nil
+}
+
+// render renders the title, body and label templates. Labels that render empty are dropped.
+func (t *issueTracker) render(data issueData) (string, string, []string, error) {
+	title, err := renderTemplate(t.cfg.Title, data)
+	if err != nil {
+		return "", "", nil, err
+	}
+	body, err := renderTemplate(t.cfg.Body, data)
+	if err != nil {
+		return "", "", nil, err
+	}
+	var labels []string
+	for _, label := range t.cfg.Labels {
+		rendered, err := renderTemplate(label, data)
+		if err != nil {
+			return "", "", nil, err
+		}
+		if rendered = strings.TrimSpace(rendered); rendered != "" {
+			labels = append(labels, rendered)
+		}
+	}
+	return strings.TrimSpace(title), body, labels, nil
+}
+
+// post sends a JSON request to the tracker and decodes its JSON response
+func (t *issueTracker) post(ctx context.Context, url string, payload interface{}, response interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	switch {
+	case t.cfg.Provider == config.IssueProviderGitHub:
+		req.Header.Set("Authorization", "Bearer "+t.cfg.Token)
+		req.Header.Set("Accept", "application/vnd.github+json")
+	case t.cfg.User != "":
+		req.SetBasicAuth(t.cfg.User, t.cfg.Token)
+	default:
+		req.Header.Set("Authorization", "Bearer "+t.cfg.Token)
+	}
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, issuesMaxBodyBytes))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned %s: %s", t.cfg.Provider, resp.Status, strings.TrimSpace(string(respBody)))
+	}
+	return json.Unmarshal(respBody, response)
+}
+
+// renderTemplate renders one issue template
+func renderTemplate(text string, data issueData) (string, error) {
+	tmpl, err := template.New("issue").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// renderValue renders the strings in a Jira field value, which may be nested in
+// objects and lists
+func renderValue(value interface{}, data issueData) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return renderTemplate(v, data)
+	case map[string]interface{}:
+		rendered := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			r, err := renderValue(item, data)
+			if err != nil {
+				return nil, err
+			}
+			rendered[key] = r
+		}
+		return rendered, nil
+	case []interface{}:
+		rendered := make([]interface{}, len(v))
+		for i, item := range v {
+			r, err := renderValue(item, data)
+			if err != nil {
+				return nil, err
+			}
+			rendered[i] = r
+		}
+		return rendered, nil
+	default:
+		return value, nil
+	}
+}
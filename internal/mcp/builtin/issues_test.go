@@ -0,0 +1,120 @@
+package builtin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+// newIssuesTestServer creates an issues server for the tracker at url, with the
+// configuration defaults applied
+func newIssuesTestServer(t *testing.T, issues config.MCPIssuesConfig) *issueTracker {
+	t.Helper()
+	cfg := &config.Config{MCPServers: map[string]config.MCPServerConfig{"issues": {Builtin: config.BuiltinIssues, Issues: issues}}}
+	cfg.ApplyDefaults()
+	issues = cfg.MCPServers["issues"].Issues
+	require.NoError(t, issues.Validate())
+	return &issueTracker{cfg: issues, httpClient: http.DefaultClient, now: func() time.Time { return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC) }}
+}
+
+func TestCreateJiraIssue(t *testing.T) {
+	var received map[string]map[string]interface{}
+	tracker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/rest/api/2/issue", r.URL.Path)
+		user, token, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "bot@example.com", user)
+		assert.Equal(t, "secret", token)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id": "10001", "key": "OPS-42"}`))
+	}))
+	defer tracker.Close()
+
+	s := newIssuesServer(newIssuesTestServer(t, config.MCPIssuesConfig{
+		Provider: config.IssueProviderJira,
+		URL:      tracker.URL + "/",
+		Token:    "secret",
+		User:     "bot@example.com",
+		Project:  "OPS",
+		Title:    "[Slack] {{.Title}}",
+		Labels:   []string{"slack", "{{.Priority}}"},
+		Fields:   map[string]interface{}{"priority": map[string]interface{}{"name": "{{or .Priority \"Medium\"}}"}},
+	}))
+
+	text, isError := callTool(t, s, "create_issue", map[string]interface{}{
+		"title":     "Checkout returns 500",
+		"summary":   "Payments fail since the deploy.",
+		"requester": "Ada",
+	})
+	require.False(t, isError, text)
+	assert.Equal(t, "Created issue OPS-42: "+tracker.URL+"/browse/OPS-42", text)
+
+	fields := received["fields"]
+	assert.Equal(t, "[Slack] Checkout returns 500", fields["summary"])
+	assert.Equal(t, "Payments fail since the deploy.\n\n---\nFiled from Slack for Ada on 2024-05-01.", fields["description"])
+	assert.Equal(t, map[string]interface{}{"key": "OPS"}, fields["project"])
+	assert.Equal(t, map[string]interface{}{"name": "Task"}, fields["issuetype"])
+	assert.Equal(t, map[string]interface{}{"name": "Medium"}, fields["priority"])
+	assert.Equal(t, []interface{}{"slack"}, fields["labels"], "labels that render empty are dropped")
+}
+
+func TestCreateGitHubIssue(t *testing.T) {
+	var received map[string]interface{}
+	tracker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/repos/acme/api/issues", r.URL.Path)
+		assert.Equal(t, "Bearer ghp_token", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"number": 7, "html_url": "https://github.com/acme/api/issues/7"}`))
+	}))
+	defer tracker.Close()
+
+	s := newIssuesServer(newIssuesTestServer(t, config.MCPIssuesConfig{
+		Provider:   config.IssueProviderGitHub,
+		URL:        tracker.URL,
+		Token:      "ghp_token",
+		Repository: "acme/api",
+		Body:       "{{.Summary}}",
+		Labels:     []string{"from-slack"},
+		Assignees:  []string{"octocat"},
+	}))
+
+	text, isError := callTool(t, s, "create_issue", map[string]interface{}{"title": "Flaky test", "summary": "TestLogin fails 1 in 10 runs."})
+	require.False(t, isError, text)
+	assert.Equal(t, "Created issue acme/api#7: https://github.com/acme/api/issues/7", text)
+	assert.Equal(t, "Flaky test", received["title"])
+	assert.Equal(t, "TestLogin fails 1 in 10 runs.", received["body"])
+	assert.Equal(t, []interface{}{"from-slack"}, received["labels"])
+	assert.Equal(t, []interface{}{"octocat"}, received["assignees"])
+}
+
+func TestCreateIssueReportsTrackerErrors(t *testing.T) {
+	tracker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message": "Bad credentials"}`, http.StatusUnauthorized)
+	}))
+	defer tracker.Close()
+
+	s := newIssuesServer(newIssuesTestServer(t, config.MCPIssuesConfig{
+		Provider: config.IssueProviderGitHub, URL: tracker.URL, Token: "bad", Repository: "acme/api",
+	}))
+	text, isError := callTool(t, s, "create_issue", map[string]interface{}{"title": "x", "summary": "y"})
+	assert.True(t, isError)
+	assert.Contains(t, text, "401 Unauthorized")
+	assert.Contains(t, text, "Bad credentials")
+}
+
+func TestIssuesConfigValidation(t *testing.T) {
+	assert.Error(t, (&config.MCPIssuesConfig{Provider: "linear", Token: "t"}).Validate())
+	assert.Error(t, (&config.MCPIssuesConfig{Provider: "jira", Token: "t", URL: "https://x.atlassian.net"}).Validate(), "project is required")
+	assert.Error(t, (&config.MCPIssuesConfig{Provider: "github", Token: "t", Repository: "api"}).Validate(), "repository needs an owner")
+	assert.Error(t, (&config.MCPIssuesConfig{Provider: "github", Repository: "acme/api"}).Validate(), "token is required")
+	assert.Error(t, (&config.MCPIssuesConfig{Provider: "github", Token: "t", Repository: "acme/api", Title: "{{.Title"}).Validate())
+}
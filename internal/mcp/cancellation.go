@@ -0,0 +1,50 @@
+package mcp
+
+import (
+	"context"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// cancelNotifyTimeout bounds sending the cancellation of an abandoned request
+const cancelNotifyTimeout = time.Second
+
+// cancellingTransport sends the server a notifications/cancelled message for each
+// request abandoned because its context ended, so the server can stop working on
+// it instead of finishing a result nobody reads
+type cancellingTransport struct {
+	transport.Interface
+}
+
+func newCancellingTransport(inner transport.Interface) *cancellingTransport {
+	return &cancellingTransport{Interface: inner}
+}
+
+func (t *cancellingTransport) SendRequest(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+	response, err := t.Interface.SendRequest(ctx, request)
+	// The initialize request must not be cancelled
+	if err != nil && ctx.Err() != nil && request.Method != string(mcp.MethodInitialize) {
+		notifyCtx, cancel := context.WithTimeout(context.Background(), cancelNotifyTimeout)
+		defer cancel()
+		_ = t.Interface.SendNotification(notifyCtx, mcp.JSONRPCNotification{
+			JSONRPC: mcp.JSONRPC_VERSION,
+			Notification: mcp.Notification{
+				Method: "notifications/cancelled",
+				Params: mcp.NotificationParams{AdditionalFields: map[string]any{
+					"requestId": request.ID,
+					"reason":    ctx.Err().Error(),
+				}},
+			},
+		})
+	}
+	return response, err
+}
+
+// SetRequestHandler keeps server-to-client requests, such as pings, working
+func (t *cancellingTransport) SetRequestHandler(handler transport.RequestHandler) {
+	if bidirectional, ok := t.Interface.(transport.BidirectionalInterface); ok {
+		bidirectional.SetRequestHandler(handler)
+	}
+}
@@ -13,10 +13,13 @@ import (
 	"time"
 
 	"github.com/mark3labs/mcp-go/client"
+	mcptransport "github.com/mark3labs/mcp-go/client/transport"
 	"github.com/mark3labs/mcp-go/mcp"
 
 	customErrors "github.com/tuannvm/slack-mcp-client/internal/common/errors"
 	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/mcp/builtin"
 )
 
 // MCPClientInterface defines the interface for an MCP client
@@ -32,6 +35,8 @@ type Client struct {
 	serverAddr  string
 	serverName  string
 	initialized bool // Track if the client has been successfully initialized
+	identity    config.MCPIdentityConfig
+	credentials CredentialSource // Per-user credentials (nil for shared credentials)
 
 	closeOnce sync.Once  // Ensures close logic runs only once
 	closeMu   sync.Mutex // Protects access during close
@@ -40,7 +45,10 @@ type Client struct {
 // NewClient creates a new MCP client handler.
 // For stdio mode, addressOrCommand should be the command path, and args should be provided.
 // For http/sse modes, addressOrCommand is the URL, and args is ignored.
-func NewClient(transport, addressOrCommand string, serverName string, args []string, env map[string]string, resolvedHeaders map[string]string, stdLogger *logging.Logger) (*Client, error) {
+// envPolicy limits the variables of this process that a stdio server inherits,
+// and restart controls how a stdio server whose process exits is restarted.
+// remote sets the HTTP client and reconnect policy of the sse and http transports.
+func NewClient(transport, addressOrCommand string, serverName string, args []string, env map[string]string, envPolicy EnvPolicy, restart RestartPolicy, resolvedHeaders map[string]string, remote RemoteOptions, stdLogger *logging.Logger) (*Client, error) {
 	// Determine log level from environment variable
 	logLevel := logging.LevelInfo // Default to INFO
 	if envLevel := os.Getenv("LOG_LEVEL"); envLevel != "" {
@@ -59,22 +67,18 @@ func NewClient(transport, addressOrCommand string, serverName string, args []str
 	switch transportLower {
 	case "stdio":
 		// Build environment slice
-		processEnv := os.Environ()
-		envMap := make(map[string]string)
-		for _, e := range processEnv {
-			parts := strings.SplitN(e, "=", 2)
-			if len(parts) == 2 {
-				envMap[parts[0]] = parts[1]
-			}
-		}
-		for k, v := range env {
-			envMap[k] = v
+		finalEnv := buildStdioEnv(os.Environ(), envPolicy, env)
+		if envPolicy.Isolated {
+			mcpLogger.DebugKV("Starting stdio server with an isolated environment", "server", serverName, "variables", len(finalEnv))
 		}
-		finalEnv := make([]string, 0, len(envMap))
-		for k, v := range envMap {
-			finalEnv = append(finalEnv, fmt.Sprintf("%s=%s", k, v))
+		if restart.MaxAttempts > 0 {
+			mcpClient, err = NewSupervisedStdioClient(serverName, addressOrCommand, args, finalEnv, restart, mcpLogger)
+		} else {
+			stdioTransport := mcptransport.NewStdioWithOptions(addressOrCommand, finalEnv, args, mcptransport.WithCommandFunc(stdioCommand))
+			if err = stdioTransport.Start(context.Background()); err == nil {
+				mcpClient = client.NewClient(newCancellingTransport(stdioTransport))
+			}
 		}
-		mcpClient, err = client.NewStdioMCPClient(addressOrCommand, finalEnv, args...)
 		if err != nil {
 			return nil, customErrors.WrapMCPError(err, "client_creation", fmt.Sprintf("Failed to create MCP client for %s", addressOrCommand))
 		}
@@ -84,7 +88,7 @@ func NewClient(transport, addressOrCommand string, serverName string, args []str
 		for k, v := range resolvedHeaders {
 			hdr.Set(k, v)
 		}
-		mcpClient, err = NewSSEMCPClientWithRetry(addressOrCommand, hdr, mcpLogger)
+		mcpClient, err = NewSSEMCPClientWithRetry(addressOrCommand, hdr, remote, mcpLogger)
 		if err != nil {
 			return nil, customErrors.WrapMCPError(err, "client_creation", fmt.Sprintf("Failed to create MCP client for %s", addressOrCommand))
 		}
@@ -92,8 +96,14 @@ func NewClient(transport, addressOrCommand string, serverName string, args []str
 		if err != nil {
 			return nil, customErrors.WrapMCPError(err, "client_start", fmt.Sprintf("Failed to start MCP client for %s", addressOrCommand))
 		}
+	case config.TransportBuiltin:
+		return nil, customErrors.NewMCPError("invalid_transport", "Builtin servers are created with NewBuiltinClient")
 	case "http":
-		mcpClient, err = client.NewStreamableHttpClient(addressOrCommand)
+		options := []mcptransport.StreamableHTTPCOption{mcptransport.WithHTTPHeaderFunc(requestHeaderFunc)}
+		if remote.HTTPClient != nil {
+			options = append(options, mcptransport.WithHTTPBasicClient(remote.HTTPClient))
+		}
+		mcpClient, err = client.NewStreamableHttpClient(addressOrCommand, options...)
 		if err != nil {
 			return nil, customErrors.WrapMCPError(err, "client_creation", fmt.Sprintf("Failed to create MCP client for %s", addressOrCommand))
 		}
@@ -120,6 +130,39 @@ func NewClient(transport, addressOrCommand string, serverName string, args []str
 	return wrapperClient, nil
 }
 
+// NewBuiltinClient creates a client of a built-in server, which runs in process
+func NewBuiltinClient(serverName string, serverCfg config.MCPServerConfig) (*Client, error) {
+	logLevel := logging.LevelInfo
+	if envLevel := os.Getenv("LOG_LEVEL"); envLevel != "" {
+		logLevel = logging.ParseLevel(envLevel)
+	}
+	mcpLogger := logging.New("mcp-client", logLevel)
+	mcpLogger.InfoKV("Creating new MCP client", "transport", config.TransportBuiltin, "builtin", serverCfg.Builtin)
+
+	builtinServer, err := builtin.New(serverCfg)
+	if err != nil {
+		return nil, customErrors.WrapMCPError(err, "client_creation", fmt.Sprintf("Failed to create builtin server %s", serverCfg.Builtin))
+	}
+	inProcessClient, _ := client.NewInProcessClient(builtinServer)
+	if err := inProcessClient.Start(context.Background()); err != nil {
+		return nil, customErrors.WrapMCPError(err, "client_start", fmt.Sprintf("Failed to start builtin server %s", serverCfg.Builtin))
+	}
+	return &Client{
+		logger:     mcpLogger,
+		client:     inProcessClient,
+		serverAddr: serverCfg.Builtin,
+		serverName: serverName,
+	}, nil
+}
+
+// OnServerEvent registers a handler for the process events of a supervised stdio
+// server. It is a no-op for other servers.
+func (c *Client) OnServerEvent(handler func(ServerEvent)) {
+	if supervised, ok := c.client.(*SupervisedStdioClient); ok {
+		supervised.OnEvent(handler)
+	}
+}
+
 // StartListener connects to the MCP server and listens for events.
 // This should be run in a goroutine.
 func (c *Client) StartListener(_ context.Context) error { // nolint:revive // Using underscore for unused parameter
@@ -190,6 +233,11 @@ func (c *Client) CallTool(ctx context.Context, toolName string, args map[string]
 	req := mcp.CallToolRequest{}
 	// Set the tool name and arguments in the params field
 	req.Params.Name = toolName
+	ctx, args = c.applyIdentity(ctx, &req, args)
+	ctx, err := c.applyUserCredentials(ctx)
+	if err != nil {
+		return "", err
+	}
 	req.Params.Arguments = args
 
 	// Call the tool using the official client
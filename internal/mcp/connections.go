@@ -0,0 +1,66 @@
+package mcp
+
+import (
+	"crypto/rand"
+	"math/big"
+	"net"
+	"net/http"
+	"time"
+)
+
+// HTTPOptions tunes the connection pool of the HTTP client used for sse and http servers
+type HTTPOptions struct {
+	MaxIdleConns        int           // Idle connections kept open across all hosts
+	MaxIdleConnsPerHost int           // Idle connections kept open per host
+	MaxConnsPerHost     int           // Open connections per host; 0 is unlimited
+	IdleConnTimeout     time.Duration // How long an idle connection is kept open
+	KeepAlive           time.Duration // Interval of TCP keep-alive probes
+}
+
+// Apply sizes the connection pool of a transport and sets its TCP keep-alive.
+// Clients of SSE servers must not set an overall timeout, as streams stay open for
+// the life of the connection.
+func (o HTTPOptions) Apply(transport *http.Transport) {
+	transport.MaxIdleConns = o.MaxIdleConns
+	transport.MaxIdleConnsPerHost = o.MaxIdleConnsPerHost
+	transport.MaxConnsPerHost = o.MaxConnsPerHost
+	transport.IdleConnTimeout = o.IdleConnTimeout
+	transport.DialContext = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: o.KeepAlive}).DialContext
+}
+
+// ReconnectPolicy controls how a client reconnects to an sse server after a
+// failed tool call. The zero value makes a single attempt without waiting.
+type ReconnectPolicy struct {
+	MaxAttempts    int           // Attempts before giving up
+	InitialBackoff time.Duration // Delay after the first failed attempt, doubled after each
+	MaxBackoff     time.Duration // Longest delay between attempts
+	Jitter         float64       // Share of each delay that is random, 0-1
+}
+
+// delay returns the wait before an attempt, counting from 1. The first attempt
+// waits only its random share of InitialBackoff, so clients that lost the same
+// server spread their reconnects instead of arriving together.
+func (p ReconnectPolicy) delay(attempt int, random func() float64) time.Duration {
+	if attempt <= 1 {
+		return time.Duration(p.Jitter * random() * float64(p.InitialBackoff))
+	}
+	backoff := RestartPolicy{InitialBackoff: p.InitialBackoff, MaxBackoff: p.MaxBackoff}.backoff(attempt - 1)
+	return time.Duration(float64(backoff) * (1 - p.Jitter*random()))
+}
+
+// RemoteOptions are the connection settings of an sse or http server
+type RemoteOptions struct {
+	HTTPClient *http.Client // Client whose connection pool is used; nil uses the library's default client
+	HTTP       HTTPOptions  // Pool settings, for servers that need their own client
+	Reconnect  ReconnectPolicy
+}
+
+// reconnectRandom returns a random number in [0, 1) for reconnect delays. It uses
+// crypto/rand, like the HTTP client's retry jitter.
+func reconnectRandom() float64 {
+	n, err := rand.Int(rand.Reader, big.NewInt(1<<53))
+	if err != nil {
+		return 0.5
+	}
+	return float64(n.Int64()) / (1 << 53)
+}
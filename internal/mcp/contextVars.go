@@ -0,0 +1,109 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+type contextVarsKey struct{}
+
+// ContextWithVars returns a context carrying the values of the request's context
+// variables, such as config.ContextVarChannelID, which tool argument defaults
+// reference. Values of the parent context are kept unless replaced.
+func ContextWithVars(ctx context.Context, vars map[string]string) context.Context {
+	merged := make(map[string]string, len(vars))
+	for name, value := range VarsFromContext(ctx) {
+		merged[name] = value
+	}
+	for name, value := range vars {
+		merged[name] = value
+	}
+	return context.WithValue(ctx, contextVarsKey{}, merged)
+}
+
+// VarsFromContext returns the context variables of the request, without the ones
+// resolved at call time such as config.ContextVarNow
+func VarsFromContext(ctx context.Context) map[string]string {
+	vars, _ := ctx.Value(contextVarsKey{}).(map[string]string)
+	return vars
+}
+
+// ApplyArgDefaults returns the arguments of a tool call with the defaults of the
+// arguments the LLM omitted, and the fixed arguments in place of whatever the LLM
+// sent. Context variables are resolved for the request in ctx; a value that is
+// only a variable without a value is left out, and a fixed one also removes the
+// LLM's value. The caller's arguments are not modified.
+func ApplyArgDefaults(ctx context.Context, defaults, fixed map[string]interface{}, args map[string]interface{}) map[string]interface{} {
+	if len(defaults) == 0 && len(fixed) == 0 {
+		return args
+	}
+	vars := callVars(ctx, time.Now())
+	withDefaults := make(map[string]interface{}, len(args)+len(defaults))
+	for name, value := range args {
+		withDefaults[name] = value
+	}
+	for name, value := range defaults {
+		if existing, ok := withDefaults[name]; ok && existing != nil {
+			continue
+		}
+		if resolved, ok := expandVars(value, vars); ok {
+			withDefaults[name] = resolved
+		}
+	}
+	for name, value := range fixed {
+		delete(withDefaults, name)
+		if resolved, ok := expandVars(value, vars); ok {
+			withDefaults[name] = resolved
+		}
+	}
+	return withDefaults
+}
+
+// callVars returns the context variables of a call made at now
+func callVars(ctx context.Context, now time.Time) map[string]string {
+	vars := make(map[string]string)
+	for name, value := range VarsFromContext(ctx) {
+		vars[name] = value
+	}
+	now = now.UTC()
+	vars[config.ContextVarNow] = now.Format(time.RFC3339)
+	vars[config.ContextVarToday] = now.Format(time.DateOnly)
+	return vars
+}
+
+// expandVars replaces the context variable references in a value, and in the
+// values of the maps and lists it holds. It returns false for a string that is a
+// single reference to a variable without a value.
+func expandVars(value interface{}, vars map[string]string) (interface{}, bool) {
+	switch v := value.(type) {
+	case string:
+		if match := config.ContextVarPattern.FindStringSubmatch(v); match != nil && match[0] == v {
+			resolved := vars[match[1]]
+			return resolved, resolved != ""
+		}
+		return config.ContextVarPattern.ReplaceAllStringFunc(v, func(reference string) string {
+			return vars[strings.TrimSuffix(strings.TrimPrefix(reference, "${"), "}")]
+		}), true
+	case map[string]interface{}:
+		expanded := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			if resolved, ok := expandVars(item, vars); ok {
+				expanded[key] = resolved
+			}
+		}
+		return expanded, true
+	case []interface{}:
+		expanded := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			if resolved, ok := expandVars(item, vars); ok {
+				expanded = append(expanded, resolved)
+			}
+		}
+		return expanded, true
+	default:
+		return value, true
+	}
+}
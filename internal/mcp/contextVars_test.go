@@ -0,0 +1,51 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyArgDefaults(t *testing.T) {
+	ctx := ContextWithVars(context.Background(), map[string]string{
+		"slack.channel_id": "C123",
+		"slack.thread_ts":  "1700000000.000100",
+		"user.email":       "",
+	})
+	ctx = ContextWithVars(ctx, map[string]string{"user.id": "U42"})
+	defaults := map[string]interface{}{
+		"channel":  "${slack.channel_id}",
+		"link":     "slack://channel/${slack.channel_id}/${slack.thread_ts}",
+		"reporter": "${user.email}",
+		"limit":    10,
+		"labels":   []interface{}{"from-slack", "${user.id}", "${user.email}"},
+		"query":    "default",
+	}
+	args := map[string]interface{}{"query": "is:open", "limit": nil}
+
+	withDefaults := ApplyArgDefaults(ctx, defaults, nil, args)
+	assert.Equal(t, map[string]interface{}{
+		"channel": "C123",
+		"link":    "slack://channel/C123/1700000000.000100",
+		"limit":   10,
+		"labels":  []interface{}{"from-slack", "U42"},
+		"query":   "is:open",
+	}, withDefaults)
+	assert.Equal(t, map[string]interface{}{"query": "is:open", "limit": nil}, args, "The arguments are not modified")
+
+	assert.Equal(t, args, ApplyArgDefaults(ctx, nil, nil, args))
+
+	// Fixed arguments replace the LLM's values, and remove them when unresolved
+	fixed := map[string]interface{}{"channel": "${slack.channel_id}", "reporter": "${user.email}"}
+	withFixed := ApplyArgDefaults(ctx, map[string]interface{}{"channel": "C-DEFAULT"}, fixed, map[string]interface{}{"channel": "C-OTHER", "reporter": "someone@example.com", "query": "is:open"})
+	assert.Equal(t, map[string]interface{}{"channel": "C123", "query": "is:open"}, withFixed)
+}
+
+func TestCallVarsResolveTime(t *testing.T) {
+	now := time.Date(2026, 3, 14, 23, 30, 0, 0, time.FixedZone("PDT", -7*60*60))
+	vars := callVars(context.Background(), now)
+	assert.Equal(t, "2026-03-15T06:30:00Z", vars["now"])
+	assert.Equal(t, "2026-03-15", vars["today"])
+}
@@ -0,0 +1,48 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	customErrors "github.com/tuannvm/slack-mcp-client/internal/common/errors"
+	"github.com/tuannvm/slack-mcp-client/internal/credentials"
+)
+
+// CredentialSource provides the requesting user's own credentials for a server
+type CredentialSource interface {
+	AuthHeader(ctx context.Context, server, userID string) (string, string, error)
+}
+
+// SetCredentialSource makes the client call tools with each requesting user's credentials
+func (c *Client) SetCredentialSource(source CredentialSource) {
+	c.credentials = source
+}
+
+// applyUserCredentials attaches the requesting user's token to the request context.
+// It fails when the user has not linked an account, so the call is never made with
+// someone else's (or no) credentials.
+func (c *Client) applyUserCredentials(ctx context.Context) (context.Context, error) {
+	if c.credentials == nil {
+		return ctx, nil
+	}
+
+	identity, ok := IdentityFromContext(ctx)
+	if !ok {
+		return ctx, customErrors.NewMCPError("credentials_required",
+			fmt.Sprintf("Server '%s' requires per-user credentials but the requesting user is unknown", c.serverName))
+	}
+
+	header, value, err := c.credentials.AuthHeader(ctx, c.serverName, identity.UserID)
+	if errors.Is(err, credentials.ErrNotLinked) {
+		return ctx, customErrors.NewMCPError("credentials_required",
+			fmt.Sprintf("You have not linked your %s account yet. Send me a DM with `link %s` to connect it.", c.serverName, c.serverName)).
+			WithData("server", c.serverName)
+	}
+	if err != nil {
+		return ctx, customErrors.WrapMCPError(err, "credentials_unavailable",
+			fmt.Sprintf("Failed to load your credentials for '%s'", c.serverName))
+	}
+
+	return withRequestHeaders(ctx, map[string]string{header: value}), nil
+}
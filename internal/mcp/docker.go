@@ -0,0 +1,46 @@
+package mcp
+
+import (
+	"sort"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+// dockerServerLabel marks the containers of stdio servers so they can be found with "docker ps --filter label=..."
+const dockerServerLabel = "slack-mcp-client.server"
+
+// DockerCommand wraps a stdio server's command in "docker run". The container is
+// removed when the server exits, and its stdin stays attached so the MCP session
+// runs over it. The server's env is forwarded by name only, so values reach the
+// container through the docker CLI's environment rather than its arguments.
+func DockerCommand(docker config.MCPDockerConfig, serverName, command string, args []string, env map[string]string) (string, []string) {
+	binary := docker.Binary
+	if binary == "" {
+		binary = "docker"
+	}
+
+	runArgs := []string{"run", "--rm", "-i", "--label", dockerServerLabel + "=" + serverName}
+	if docker.Network != "" {
+		runArgs = append(runArgs, "--network", docker.Network)
+	}
+	if docker.Pull != "" {
+		runArgs = append(runArgs, "--pull", docker.Pull)
+	}
+	for _, volume := range docker.Volumes {
+		runArgs = append(runArgs, "-v", volume)
+	}
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		runArgs = append(runArgs, "-e", name)
+	}
+	runArgs = append(runArgs, docker.RunArgs...)
+	runArgs = append(runArgs, docker.Image)
+	if command != "" {
+		runArgs = append(runArgs, command)
+	}
+	return binary, append(runArgs, args...)
+}
@@ -0,0 +1,39 @@
+package mcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+func TestDockerCommand(t *testing.T) {
+	docker := config.MCPDockerConfig{
+		Image:   "mcp/github:latest",
+		Volumes: []string{"/srv/data:/data:ro"},
+		Network: "none",
+		Pull:    "always",
+		RunArgs: []string{"--memory", "512m"},
+	}
+	env := map[string]string{"GITHUB_TOKEN": "ghp-secret", "DEBUG": "1"}
+
+	binary, args := DockerCommand(docker, "github", "node", []string{"dist/index.js"}, env)
+	assert.Equal(t, "docker", binary)
+	assert.Equal(t, []string{
+		"run", "--rm", "-i", "--label", "slack-mcp-client.server=github",
+		"--network", "none",
+		"--pull", "always",
+		"-v", "/srv/data:/data:ro",
+		"-e", "DEBUG",
+		"-e", "GITHUB_TOKEN",
+		"--memory", "512m",
+		"mcp/github:latest", "node", "dist/index.js",
+	}, args)
+	assert.NotContains(t, args, "ghp-secret", "env values must not appear in the arguments")
+
+	// Without a command the image's own command runs
+	binary, args = DockerCommand(config.MCPDockerConfig{Image: "mcp/time", Binary: "podman"}, "time", "", nil, nil)
+	assert.Equal(t, "podman", binary)
+	assert.Equal(t, []string{"run", "--rm", "-i", "--label", "slack-mcp-client.server=time", "mcp/time"}, args)
+}
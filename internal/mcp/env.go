@@ -0,0 +1,90 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// EnvPolicy controls which variables of this process a stdio server inherits.
+// The zero value inherits the whole environment.
+type EnvPolicy struct {
+	Isolated  bool     // Inherit only the base variables and the allowlist
+	Allowlist []string // Variables passed to an isolated server; "PREFIX_*" matches by prefix
+}
+
+// baseEnvVars are passed to isolated servers so their command can be found and run
+var baseEnvVars = []string{
+	"PATH", "HOME", "USER", "LOGNAME", "SHELL", "TMPDIR", "TZ", "LANG", "LC_ALL", "LC_CTYPE",
+	// Windows needs these to start most programs
+	"SYSTEMROOT", "WINDIR", "COMSPEC", "PATHEXT", "TEMP", "TMP", "USERPROFILE", "APPDATA", "LOCALAPPDATA", "PROGRAMDATA",
+}
+
+// allows reports whether the policy passes a variable of this process to the server
+func (p EnvPolicy) allows(name string) bool {
+	if !p.Isolated {
+		return true
+	}
+	for _, base := range baseEnvVars {
+		if envNameEqual(name, base) {
+			return true
+		}
+	}
+	for _, pattern := range p.Allowlist {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if envNameHasPrefix(name, prefix) {
+				return true
+			}
+		} else if envNameEqual(name, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// buildStdioEnv builds the environment of a stdio server: the variables of this
+// process the policy allows, overridden by the server's configured env
+func buildStdioEnv(processEnv []string, policy EnvPolicy, env map[string]string) []string {
+	envMap := make(map[string]string)
+	for _, e := range processEnv {
+		parts := strings.SplitN(e, "=", 2)
+		if len(parts) == 2 && policy.allows(parts[0]) {
+			envMap[parts[0]] = parts[1]
+		}
+	}
+	for k, v := range env {
+		envMap[k] = v
+	}
+	finalEnv := make([]string, 0, len(envMap))
+	for k, v := range envMap {
+		finalEnv = append(finalEnv, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(finalEnv)
+	return finalEnv
+}
+
+// stdioCommand starts a stdio server with exactly the environment built for it.
+// The library's default appends this process's environment, which would undo isolation.
+func stdioCommand(ctx context.Context, command string, env []string, args []string) (*exec.Cmd, error) {
+	cmd := exec.CommandContext(ctx, command, args...)
+	cmd.Env = env
+	return cmd, nil
+}
+
+// envNameEqual compares variable names, ignoring case on Windows where names are case-insensitive
+func envNameEqual(a, b string) bool {
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+func envNameHasPrefix(name, prefix string) bool {
+	if runtime.GOOS == "windows" {
+		return len(name) >= len(prefix) && strings.EqualFold(name[:len(prefix)], prefix)
+	}
+	return strings.HasPrefix(name, prefix)
+}
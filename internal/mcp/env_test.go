@@ -0,0 +1,54 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildStdioEnv(t *testing.T) {
+	processEnv := []string{
+		"PATH=/usr/bin",
+		"HOME=/home/bot",
+		"SLACK_BOT_TOKEN=xoxb-secret",
+		"GITHUB_TOKEN=ghp-secret",
+		"AWS_REGION=eu-west-1",
+		"AWS_PROFILE=prod",
+		"MALFORMED",
+	}
+	env := map[string]string{"GITHUB_TOKEN": "ghp-scoped", "EXTRA": "1"}
+
+	// By default the whole environment is inherited
+	assert.Equal(t, []string{
+		"AWS_PROFILE=prod",
+		"AWS_REGION=eu-west-1",
+		"EXTRA=1",
+		"GITHUB_TOKEN=ghp-scoped",
+		"HOME=/home/bot",
+		"PATH=/usr/bin",
+		"SLACK_BOT_TOKEN=xoxb-secret",
+	}, buildStdioEnv(processEnv, EnvPolicy{}, env))
+
+	// An isolated server gets the base variables, the allowlist and its own env
+	isolated := EnvPolicy{Isolated: true, Allowlist: []string{"AWS_*"}}
+	assert.Equal(t, []string{
+		"AWS_PROFILE=prod",
+		"AWS_REGION=eu-west-1",
+		"EXTRA=1",
+		"GITHUB_TOKEN=ghp-scoped",
+		"HOME=/home/bot",
+		"PATH=/usr/bin",
+	}, buildStdioEnv(processEnv, isolated, env))
+
+	assert.Equal(t, []string{"HOME=/home/bot", "PATH=/usr/bin"},
+		buildStdioEnv(processEnv, EnvPolicy{Isolated: true}, nil))
+}
+
+func TestStdioCommandUsesOnlyBuiltEnv(t *testing.T) {
+	t.Setenv("SLACK_BOT_TOKEN", "xoxb-secret")
+	cmd, err := stdioCommand(context.Background(), "server", []string{"PATH=/usr/bin"}, []string{"--stdio"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"PATH=/usr/bin"}, cmd.Env)
+	assert.Equal(t, []string{"server", "--stdio"}, cmd.Args)
+}
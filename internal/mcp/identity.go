@@ -0,0 +1,102 @@
+package mcp
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+// Identity describes the Slack user on whose behalf a tool is being called
+type Identity struct {
+	UserID string
+	Email  string
+}
+
+type identityContextKey struct{}
+
+type requestHeadersContextKey struct{}
+
+// ContextWithIdentity returns a context carrying the requesting user's identity.
+// Servers only receive it when identity propagation is enabled in their config.
+func ContextWithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityContextKey{}, identity)
+}
+
+// IdentityFromContext returns the requesting user's identity, if any
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityContextKey{}).(Identity)
+	return identity, ok && identity.UserID != ""
+}
+
+// SetIdentityConfig configures how the requesting user's identity is forwarded to this server
+func (c *Client) SetIdentityConfig(identityConfig config.MCPIdentityConfig) {
+	c.identity = identityConfig
+}
+
+// applyIdentity injects the requesting user's identity into the tool call according
+// to the server's identity configuration and returns the context and arguments to use for the call.
+func (c *Client) applyIdentity(ctx context.Context, req *mcp.CallToolRequest, args map[string]interface{}) (context.Context, map[string]interface{}) {
+	if !c.identity.Enabled {
+		return ctx, args
+	}
+	identity, ok := IdentityFromContext(ctx)
+	if !ok {
+		return ctx, args
+	}
+
+	values := map[string]string{"user_id": identity.UserID}
+	if c.identity.IncludeEmail && identity.Email != "" {
+		values["user_email"] = identity.Email
+	}
+
+	switch c.identity.GetMode() {
+	case config.IdentityModeArgument:
+		userArg := make(map[string]interface{}, len(values))
+		for k, v := range values {
+			userArg[k] = v
+		}
+		// Copy so the caller's arguments are not modified
+		withIdentity := make(map[string]interface{}, len(args)+1)
+		for k, v := range args {
+			withIdentity[k] = v
+		}
+		withIdentity[c.identity.GetArgumentName()] = userArg
+		args = withIdentity
+	case config.IdentityModeHeader:
+		headers := map[string]string{c.identity.GetHeaderPrefix() + "Id": identity.UserID}
+		if email, ok := values["user_email"]; ok {
+			headers[c.identity.GetHeaderPrefix()+"Email"] = email
+		}
+		ctx = withRequestHeaders(ctx, headers)
+	default:
+		fields := make(map[string]any, len(values))
+		for k, v := range values {
+			fields["slack/"+k] = v
+		}
+		req.Params.Meta = &mcp.Meta{AdditionalFields: fields}
+	}
+
+	c.logger.DebugKV("Forwarding user identity to MCP server", "server", c.serverName, "mode", c.identity.GetMode())
+	return ctx, args
+}
+
+// withRequestHeaders returns a context carrying extra HTTP headers for the next request,
+// merged with any headers already present
+func withRequestHeaders(ctx context.Context, headers map[string]string) context.Context {
+	merged := make(map[string]string, len(headers))
+	for k, v := range requestHeaderFunc(ctx) {
+		merged[k] = v
+	}
+	for k, v := range headers {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, requestHeadersContextKey{}, merged)
+}
+
+// requestHeaderFunc supplies per-request headers (identity, user credentials) for HTTP-based transports
+func requestHeaderFunc(ctx context.Context) map[string]string {
+	headers, _ := ctx.Value(requestHeadersContextKey{}).(map[string]string)
+	return headers
+}
@@ -0,0 +1,85 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+func TestApplyIdentity(t *testing.T) {
+	identity := Identity{UserID: "U123", Email: "user@example.com"}
+
+	tests := []struct {
+		name        string
+		cfg         config.MCPIdentityConfig
+		withUser    bool
+		wantMeta    map[string]any
+		wantArg     map[string]interface{}
+		wantHeaders map[string]string
+	}{
+		{
+			name:     "disabled forwards nothing",
+			cfg:      config.MCPIdentityConfig{},
+			withUser: true,
+		},
+		{
+			name:     "no identity in context",
+			cfg:      config.MCPIdentityConfig{Enabled: true},
+			withUser: false,
+		},
+		{
+			name:     "meta mode without email",
+			cfg:      config.MCPIdentityConfig{Enabled: true},
+			withUser: true,
+			wantMeta: map[string]any{"slack/user_id": "U123"},
+		},
+		{
+			name:     "argument mode with email",
+			cfg:      config.MCPIdentityConfig{Enabled: true, IncludeEmail: true, Mode: config.IdentityModeArgument, ArgumentName: "caller"},
+			withUser: true,
+			wantArg:  map[string]interface{}{"user_id": "U123", "user_email": "user@example.com"},
+		},
+		{
+			name:        "header mode",
+			cfg:         config.MCPIdentityConfig{Enabled: true, IncludeEmail: true, Mode: config.IdentityModeHeader},
+			withUser:    true,
+			wantHeaders: map[string]string{"X-Slack-User-Id": "U123", "X-Slack-User-Email": "user@example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{logger: logging.New("test", logging.LevelError), serverName: "test", identity: tt.cfg}
+			ctx := context.Background()
+			if tt.withUser {
+				ctx = ContextWithIdentity(ctx, identity)
+			}
+			req := mcp.CallToolRequest{}
+			args := map[string]interface{}{"query": "x"}
+
+			ctx, gotArgs := c.applyIdentity(ctx, &req, args)
+
+			if tt.wantMeta != nil {
+				assert.NotNil(t, req.Params.Meta)
+				assert.Equal(t, tt.wantMeta, req.Params.Meta.AdditionalFields)
+			} else {
+				assert.Nil(t, req.Params.Meta)
+			}
+
+			if tt.wantArg != nil {
+				assert.Equal(t, tt.wantArg, gotArgs[tt.cfg.GetArgumentName()])
+				_, mutated := args[tt.cfg.GetArgumentName()]
+				assert.False(t, mutated, "caller arguments must not be modified")
+			} else {
+				assert.Equal(t, args, gotArgs)
+			}
+
+			assert.Equal(t, tt.wantHeaders, requestHeaderFunc(ctx))
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/tuannvm/slack-mcp-client/internal/monitoring"
 )
@@ -11,10 +13,16 @@ import (
 type ToolInfo struct {
 	ServerName       string
 	ToolName         string
+	RemoteName       string // Tool name on the MCP server (ToolName may be prefixed)
 	ToolDescription  string
 	InputSchema      map[string]interface{}
 	InputSchemaBytes []byte
 	Client           MCPClientInterface
+	Timeout          time.Duration // Longest a call may run, no limit when zero
+	// Argument values used when the LLM omits them; strings may reference context variables
+	ArgDefaults map[string]interface{}
+	// Argument values always sent, replacing the LLM's; strings may reference context variables
+	ArgFixed map[string]interface{}
 }
 
 func (t *ToolInfo) Name() string {
@@ -28,6 +36,11 @@ func (t *ToolInfo) Description() string {
 	return t.ToolDescription + "\n The input schema is: " + string(t.InputSchemaBytes)
 }
 
+// Schema returns the tool's input schema, which tool-calling agents send to the model
+func (t *ToolInfo) Schema() map[string]interface{} {
+	return t.InputSchema
+}
+
 func (t *ToolInfo) Call(ctx context.Context, input string) (string, error) {
 	var args map[string]interface{}
 	err := json.Unmarshal([]byte(input), &args)
@@ -44,7 +57,8 @@ func (t *ToolInfo) Call(ctx context.Context, input string) (string, error) {
 		}).Inc()
 	}()
 
-	res, err := t.Client.CallTool(ctx, t.Name(), args)
+	args = ApplyArgDefaults(ctx, t.ArgDefaults, t.ArgFixed, args)
+	res, err := CallToolWithTimeout(ctx, t.Client, t.Name(), t.Timeout, args)
 	if err != nil {
 		isError = "true"
 		return "", fmt.Errorf("while calling tool %s: %w", t.Name(), err)
@@ -0,0 +1,50 @@
+package mcp
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+
+	customErrors "github.com/tuannvm/slack-mcp-client/internal/common/errors"
+)
+
+// serverUnavailableMessages are parts of the errors of transports that cannot reach
+// their server, for the errors that do not wrap a typed cause
+var serverUnavailableMessages = []string{
+	"connection refused",
+	"connection reset",
+	"broken pipe",
+	"no such host",
+	"transport has been closed",
+	"transport not started",
+	"stdio client not started",
+	"file already closed",
+}
+
+// IsServerUnavailable reports whether a tool call failed because its server could
+// not be reached, such as a stdio server whose process exited or an HTTP server
+// refusing connections, rather than because the tool itself failed
+func IsServerUnavailable(err error) bool {
+	if err == nil {
+		return false
+	}
+	for cause := err; cause != nil; cause = errors.Unwrap(cause) {
+		if domainErr, ok := cause.(*customErrors.DomainError); ok && (domainErr.Code == "client_nil" || domainErr.Code == "client_not_initialized") {
+			return true
+		}
+	}
+	for _, target := range []error{io.EOF, io.ErrUnexpectedEOF, os.ErrClosed, syscall.ECONNREFUSED, syscall.ECONNRESET, syscall.EPIPE} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	message := strings.ToLower(err.Error())
+	for _, part := range serverUnavailableMessages {
+		if strings.Contains(message, part) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,27 @@
+package mcp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	customErrors "github.com/tuannvm/slack-mcp-client/internal/common/errors"
+)
+
+func TestIsServerUnavailable(t *testing.T) {
+	assert.False(t, IsServerUnavailable(nil))
+	assert.False(t, IsServerUnavailable(errors.New("namespace not found")))
+	assert.False(t, IsServerUnavailable(&ToolTimeoutError{Tool: "get_pods"}))
+
+	assert.True(t, IsServerUnavailable(fmt.Errorf("failed to write request: %w", syscall.EPIPE)))
+	assert.True(t, IsServerUnavailable(fmt.Errorf("reading response: %w", io.EOF)))
+	assert.True(t, IsServerUnavailable(errors.New("failed to send request: dial tcp 127.0.0.1:8080: connect: connection refused")))
+	assert.True(t, IsServerUnavailable(errors.New("transport has been closed")))
+
+	notInitialized := customErrors.WrapMCPError(errors.New("initialize: EOF"), "client_not_initialized", "MCP client not initialized before tool call")
+	assert.True(t, IsServerUnavailable(customErrors.WrapMCPError(notInitialized, "tool_execution_failed", "Failed to execute MCP tool 'get_pods'")))
+}
@@ -14,16 +14,13 @@ import (
 	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
 )
 
-const (
-	maxReconnectAttempts = 5
-	baseBackoffDuration  = time.Second
-)
-
 type SSEMCPClientWithRetry struct {
 	*client.Client
 
 	serverAddr string
 	headers    http.Header
+	httpClient *http.Client // nil uses the library's default client
+	reconnect  ReconnectPolicy
 	log        *logging.Logger
 
 	ctx    context.Context
@@ -37,7 +34,7 @@ type SSEMCPClientWithRetry struct {
 	reconnectDoneCh       chan struct{}
 }
 
-func NewSSEMCPClientWithRetry(serverAddr string, hdr http.Header, log *logging.Logger) (*SSEMCPClientWithRetry, error) {
+func NewSSEMCPClientWithRetry(serverAddr string, hdr http.Header, remote RemoteOptions, log *logging.Logger) (*SSEMCPClientWithRetry, error) {
 	// Convert http.Header to map[string]string for the client library
 	headerMap := make(map[string]string)
 	for key, values := range hdr {
@@ -46,7 +43,7 @@ func NewSSEMCPClientWithRetry(serverAddr string, hdr http.Header, log *logging.L
 		}
 	}
 
-	sseClient, err := client.NewSSEMCPClient(serverAddr, client.WithHeaders(headerMap))
+	sseClient, err := client.NewSSEMCPClient(serverAddr, sseOptions(headerMap, remote.HTTPClient)...)
 	if err != nil {
 		return nil, err
 	}
@@ -57,6 +54,8 @@ func NewSSEMCPClientWithRetry(serverAddr string, hdr http.Header, log *logging.L
 		Client:     sseClient,
 		serverAddr: serverAddr,
 		headers:    hdr,
+		httpClient: remote.HTTPClient,
+		reconnect:  remote.Reconnect,
 		log:        log,
 		ctx:        ctx,
 		cancel:     cancel,
@@ -65,6 +64,15 @@ func NewSSEMCPClientWithRetry(serverAddr string, hdr http.Header, log *logging.L
 	return c, nil
 }
 
+// sseOptions returns the SSE transport options for the headers and HTTP client
+func sseOptions(headers map[string]string, httpClient *http.Client) []transport.ClientOption {
+	options := []transport.ClientOption{client.WithHeaders(headers), transport.WithHeaderFunc(requestHeaderFunc)}
+	if httpClient != nil {
+		options = append(options, transport.WithHTTPClient(httpClient))
+	}
+	return options
+}
+
 func (c *SSEMCPClientWithRetry) Start(ctx context.Context) error {
 	return c.Client.Start(ctx)
 }
@@ -135,7 +143,7 @@ func (c *SSEMCPClientWithRetry) connect() error {
 		}
 	}
 
-	sseClient, err := client.NewSSEMCPClient(c.serverAddr, client.WithHeaders(headerMap))
+	sseClient, err := client.NewSSEMCPClient(c.serverAddr, sseOptions(headerMap, c.httpClient)...)
 	if err != nil {
 		return err
 	}
@@ -179,8 +187,20 @@ func (c *SSEMCPClientWithRetry) sharedReconnect(ctx context.Context) error {
 		var success bool
 		var err error
 
+		maxAttempts := c.reconnect.MaxAttempts
+		if maxAttempts < 1 {
+			maxAttempts = 1
+		}
+
 	reconnectLoop:
-		for attempt := 1; attempt <= maxReconnectAttempts; attempt++ {
+		for attempt := 1; attempt <= maxAttempts; attempt++ {
+			select {
+			case <-time.After(c.reconnect.delay(attempt, reconnectRandom)):
+			case <-c.ctx.Done():
+				err = c.ctx.Err()
+				break reconnectLoop
+			}
+
 			err = c.connect()
 			if err == nil {
 				success = true
@@ -188,13 +208,6 @@ func (c *SSEMCPClientWithRetry) sharedReconnect(ctx context.Context) error {
 			}
 
 			c.log.InfoKV("Reconnect failed", "attempt", attempt, "error", err)
-
-			select {
-			case <-time.After(time.Duration(attempt) * baseBackoffDuration):
-			case <-c.ctx.Done():
-				err = c.ctx.Err()
-				break reconnectLoop
-			}
 		}
 
 		c.reconnectMu.Lock()
@@ -3,6 +3,7 @@ package mcp
 import (
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -12,9 +13,44 @@ func TestNewSSEMCPClientWithRetry_HeadersPropagation(t *testing.T) {
 	headers.Set("Authorization", "Bearer some-token")
 	headers.Set("Custom-Header", "custom-value")
 
-	client, err := NewSSEMCPClientWithRetry("http://example.com", headers, nil)
+	client, err := NewSSEMCPClientWithRetry("http://example.com", headers, RemoteOptions{}, nil)
 	assert.NoError(t, err)
 	assert.NotNil(t, client)
 	assert.Equal(t, "Bearer some-token", client.headers.Get("Authorization"))
 	assert.Equal(t, "custom-value", client.headers.Get("Custom-Header"))
 }
+
+func TestReconnectPolicyDelay(t *testing.T) {
+	policy := ReconnectPolicy{MaxAttempts: 5, InitialBackoff: time.Second, MaxBackoff: 5 * time.Second, Jitter: 0.5}
+	fixed := func(value float64) func() float64 { return func() float64 { return value } }
+
+	// The first attempt waits up to jitter × initialBackoff
+	assert.Equal(t, time.Duration(0), policy.delay(1, fixed(0)))
+	assert.Equal(t, 400*time.Millisecond, policy.delay(1, fixed(0.8)))
+
+	// Later attempts back off exponentially, less up to half of each delay
+	assert.Equal(t, time.Second, policy.delay(2, fixed(0)))
+	assert.Equal(t, 2*time.Second, policy.delay(3, fixed(0)))
+	assert.Equal(t, 2*time.Second, policy.delay(4, fixed(1)))
+	assert.Equal(t, 5*time.Second, policy.delay(10, fixed(0)))
+
+	// Without jitter every client waits the same
+	policy.Jitter = 0
+	assert.Equal(t, time.Duration(0), policy.delay(1, fixed(0.9)))
+	assert.Equal(t, 4*time.Second, policy.delay(4, fixed(0.9)))
+
+	for i := 0; i < 100; i++ {
+		value := reconnectRandom()
+		assert.True(t, value >= 0 && value < 1)
+	}
+}
+
+func TestHTTPOptionsApply(t *testing.T) {
+	transport := &http.Transport{}
+	HTTPOptions{MaxIdleConns: 100, MaxIdleConnsPerHost: 10, MaxConnsPerHost: 20, IdleConnTimeout: time.Minute, KeepAlive: 15 * time.Second}.Apply(transport)
+	assert.Equal(t, 100, transport.MaxIdleConns)
+	assert.Equal(t, 10, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, 20, transport.MaxConnsPerHost)
+	assert.Equal(t, time.Minute, transport.IdleConnTimeout)
+	assert.NotNil(t, transport.DialContext)
+}
@@ -0,0 +1,416 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/monitoring"
+)
+
+// RestartPolicy controls how a stdio server whose process exits is restarted.
+// The zero value never restarts it.
+type RestartPolicy struct {
+	MaxAttempts       int           // Consecutive restarts before giving up
+	InitialBackoff    time.Duration // Delay before the first restart, doubled after each
+	MaxBackoff        time.Duration // Longest delay between restarts
+	StableAfter       time.Duration // Uptime after which the consecutive restart count resets
+	InitializeTimeout time.Duration // Time a restarted server has to answer the initialize request
+	KillOnCancel      bool          // Kill and restart the process when a tool call is abandoned and no other call is running
+}
+
+// backoff returns the delay before a restart attempt, counting from 1
+func (p RestartPolicy) backoff(attempt int) time.Duration {
+	delay := p.InitialBackoff
+	for i := 1; i < attempt && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	return delay
+}
+
+// Events of a supervised stdio server
+const (
+	ServerEventCrashed   = "crashed"   // The process exited; a restart follows
+	ServerEventRestarted = "restarted" // A new process is running and initialized
+	ServerEventGaveUp    = "gave_up"   // The server crashed too often and stays down
+)
+
+// ServerEvent reports a change in a supervised server's process
+type ServerEvent struct {
+	Server  string
+	Type    string // ServerEventCrashed, ServerEventRestarted or ServerEventGaveUp
+	Attempt int    // Consecutive restart attempt the event belongs to
+	Err     error  // Why the process exited
+}
+
+// SupervisedStdioClient runs a stdio server and restarts its process with backoff
+// when it exits. A restarted server is initialized again before it serves calls.
+type SupervisedStdioClient struct {
+	*client.Client
+
+	serverName string
+	command    string
+	args       []string
+	env        []string
+	policy     RestartPolicy
+	log        *logging.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mutex      sync.RWMutex
+	stdout     *os.File              // Read end of the current process's stdout
+	process    *os.Process           // The current process
+	killed     error                 // Why the current process was killed on purpose, if it was
+	calls      int                   // Tool calls in progress on the current process
+	initResult *mcp.InitializeResult // Result of initializing the current process
+	startedAt  time.Time
+	down       error // Why the server is not serving calls, nil while it runs
+
+	handlerMu sync.RWMutex
+	onEvent   func(ServerEvent)
+}
+
+// NewSupervisedStdioClient starts a stdio server and supervises its process.
+// env is the complete environment of the process.
+func NewSupervisedStdioClient(serverName, command string, args, env []string, policy RestartPolicy, log *logging.Logger) (*SupervisedStdioClient, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &SupervisedStdioClient{
+		serverName: serverName,
+		command:    command,
+		args:       args,
+		env:        env,
+		policy:     policy,
+		log:        log,
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+
+	exited, err := c.start(false)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	go c.supervise(exited)
+	return c, nil
+}
+
+// OnEvent registers the handler called, on the supervising goroutine, for each event
+func (c *SupervisedStdioClient) OnEvent(handler func(ServerEvent)) {
+	c.handlerMu.Lock()
+	defer c.handlerMu.Unlock()
+	c.onEvent = handler
+}
+
+// Initialize initializes the first process. Restarted processes are initialized
+// by the supervisor, so later calls return the current process's result.
+func (c *SupervisedStdioClient) Initialize(ctx context.Context, request mcp.InitializeRequest) (*mcp.InitializeResult, error) {
+	c.mutex.RLock()
+	current, result := c.Client, c.initResult
+	c.mutex.RUnlock()
+	if result != nil {
+		return result, nil
+	}
+
+	result, err := current.Initialize(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	c.mutex.Lock()
+	if c.Client == current {
+		c.initResult = result
+	}
+	c.mutex.Unlock()
+	return result, nil
+}
+
+// CallTool calls a tool on the current process. With the KillOnCancel policy, a
+// call abandoned because its context ended kills the process, which is then
+// restarted, so that a server ignoring the cancellation notice stops its work.
+// The process is kept while other calls to it are still running.
+func (c *SupervisedStdioClient) CallTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	current, err := c.beginCall()
+	if err != nil {
+		return nil, err
+	}
+	result, err := current.CallTool(ctx, request)
+	c.endCall(current)
+	if err != nil && ctx.Err() != nil && c.policy.KillOnCancel {
+		c.kill(current, fmt.Errorf("killed after the call to tool '%s' was abandoned: %w", request.Params.Name, ctx.Err()))
+	}
+	return result, err
+}
+
+// beginCall returns the current process's client and counts a call to it
+func (c *SupervisedStdioClient) beginCall() (*client.Client, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.down != nil {
+		return nil, c.down
+	}
+	c.calls++
+	return c.Client, nil
+}
+
+// endCall counts a call to stdioClient as finished, unless the process has been
+// replaced since, which resets the count
+func (c *SupervisedStdioClient) endCall(stdioClient *client.Client) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.Client == stdioClient && c.calls > 0 {
+		c.calls--
+	}
+}
+
+// kill terminates the process served by stdioClient, if it is still the current
+// one and no tool call to it is running
+func (c *SupervisedStdioClient) kill(stdioClient *client.Client, reason error) {
+	c.mutex.Lock()
+	if c.Client != stdioClient || c.process == nil || c.killed != nil {
+		c.mutex.Unlock()
+		return
+	}
+	if c.calls > 0 {
+		calls := c.calls
+		c.mutex.Unlock()
+		c.log.DebugKV("Keeping MCP server process with calls in progress", "server", c.serverName, "calls", calls, "reason", reason)
+		return
+	}
+	process := c.process
+	c.killed = reason
+	c.mutex.Unlock()
+
+	c.log.WarnKV("Killing MCP server process", "server", c.serverName, "reason", reason)
+	if err := process.Kill(); err != nil {
+		c.log.DebugKV("Failed to kill MCP server process", "server", c.serverName, "error", err)
+	}
+}
+
+func (c *SupervisedStdioClient) ListTools(ctx context.Context, request mcp.ListToolsRequest) (*mcp.ListToolsResult, error) {
+	current, err := c.current()
+	if err != nil {
+		return nil, err
+	}
+	return current.ListTools(ctx, request)
+}
+
+func (c *SupervisedStdioClient) Ping(ctx context.Context) error {
+	current, err := c.current()
+	if err != nil {
+		return err
+	}
+	return current.Ping(ctx)
+}
+
+// Close stops supervising and terminates the server process
+func (c *SupervisedStdioClient) Close() error {
+	c.cancel()
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.Client == nil {
+		return nil
+	}
+	err := c.Client.Close()
+	_ = c.stdout.Close()
+	return err
+}
+
+// current returns the client of the running process, or why there is none
+func (c *SupervisedStdioClient) current() (*client.Client, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	if c.down != nil {
+		return nil, c.down
+	}
+	return c.Client, nil
+}
+
+// start launches a new server process and makes it the current one. The returned
+// channel receives the result of waiting for the process.
+func (c *SupervisedStdioClient) start(initialize bool) (<-chan error, error) {
+	// The pipes are created here rather than with cmd.StdoutPipe, whose read end
+	// Wait closes under the transport while it may still be reading
+	stdinR, stdinW, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdin pipe: %w", err)
+	}
+	stdoutR, stdoutW, err := os.Pipe()
+	if err != nil {
+		_ = stdinR.Close()
+		_ = stdinW.Close()
+		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+
+	cmd := exec.CommandContext(c.ctx, c.command, c.args...)
+	cmd.Env = c.env
+	cmd.Stdin = stdinR
+	cmd.Stdout = stdoutW
+	cmd.Stderr = os.Stderr
+	startErr := cmd.Start()
+	// The child holds its own copies of these ends
+	_ = stdinR.Close()
+	_ = stdoutW.Close()
+	if startErr != nil {
+		_ = stdinW.Close()
+		_ = stdoutR.Close()
+		return nil, fmt.Errorf("failed to start command: %w", startErr)
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	stdioClient := client.NewClient(newCancellingTransport(transport.NewIO(stdoutR, stdinW, nil)))
+	if err := stdioClient.Start(c.ctx); err != nil {
+		_ = stdioClient.Close()
+		_ = stdoutR.Close()
+		return nil, fmt.Errorf("failed to start stdio transport: %w", err)
+	}
+
+	var initResult *mcp.InitializeResult
+	if initialize {
+		initCtx, cancel := context.WithTimeout(c.ctx, c.policy.InitializeTimeout)
+		initReq := mcp.InitializeRequest{}
+		initReq.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+		initResult, err = stdioClient.Initialize(initCtx, initReq)
+		cancel()
+		if err != nil {
+			// Closing stdin makes a well-behaved server exit; kill it otherwise
+			_ = stdioClient.Close()
+			select {
+			case <-exited:
+			case <-time.After(time.Second):
+				_ = cmd.Process.Kill()
+				<-exited
+			}
+			_ = stdoutR.Close()
+			return nil, fmt.Errorf("failed to initialize: %w", err)
+		}
+	}
+
+	c.mutex.Lock()
+	previous, previousStdout := c.Client, c.stdout
+	c.Client, c.stdout, c.process = stdioClient, stdoutR, cmd.Process
+	c.killed = nil
+	c.calls = 0
+	c.initResult = initResult
+	c.startedAt = time.Now()
+	c.down = nil
+	c.mutex.Unlock()
+	if previous != nil {
+		// The previous process has exited; release its pipes
+		_ = previous.Close()
+		_ = previousStdout.Close()
+	}
+	monitoring.MCPServerUp.WithLabelValues(c.serverName).Set(1)
+	return exited, nil
+}
+
+// supervise waits for the server process to exit and restarts it, until the
+// client is closed or the restart attempts run out
+func (c *SupervisedStdioClient) supervise(exited <-chan error) {
+	attempt := 0
+	for {
+		var exitErr error
+		select {
+		case exitErr = <-exited:
+		case <-c.ctx.Done():
+			return
+		}
+		if c.ctx.Err() != nil {
+			return // Closed on purpose
+		}
+		if exitErr == nil {
+			exitErr = fmt.Errorf("process exited")
+		}
+
+		c.mutex.Lock()
+		uptime := time.Since(c.startedAt)
+		killed := c.killed
+		if killed != nil {
+			exitErr = killed
+		}
+		c.down = fmt.Errorf("MCP server '%s' is restarting after its process exited: %w", c.serverName, exitErr)
+		c.mutex.Unlock()
+		// A process killed on purpose did not crash, so it does not count towards giving up
+		if uptime >= c.policy.StableAfter || killed != nil {
+			attempt = 0
+		}
+		monitoring.MCPServerCrashes.WithLabelValues(c.serverName).Inc()
+		monitoring.MCPServerUp.WithLabelValues(c.serverName).Set(0)
+		c.log.WarnKV("MCP server process exited", "server", c.serverName, "error", exitErr, "uptime", uptime)
+
+		var ok bool
+		if exited, ok = c.restart(&attempt, exitErr); !ok {
+			return
+		}
+	}
+}
+
+// restart starts a new process with backoff, counting consecutive attempts. It
+// reports false when the client was closed or the server was given up on.
+func (c *SupervisedStdioClient) restart(attempt *int, exitErr error) (<-chan error, bool) {
+	for first := true; ; first = false {
+		*attempt++
+		if *attempt > c.policy.MaxAttempts {
+			c.mutex.Lock()
+			c.down = fmt.Errorf("MCP server '%s' is down after %d restart attempts: %w", c.serverName, c.policy.MaxAttempts, exitErr)
+			c.mutex.Unlock()
+			monitoring.MCPServerRestarts.WithLabelValues(c.serverName, "gave_up").Inc()
+			c.log.ErrorKV("MCP server keeps crashing, giving up", "server", c.serverName, "attempts", c.policy.MaxAttempts, "error", exitErr)
+			c.emit(ServerEvent{Server: c.serverName, Type: ServerEventGaveUp, Attempt: *attempt - 1, Err: exitErr})
+			return nil, false
+		}
+		if first {
+			c.emit(ServerEvent{Server: c.serverName, Type: ServerEventCrashed, Attempt: *attempt, Err: exitErr})
+		}
+
+		delay := c.policy.backoff(*attempt)
+		c.log.InfoKV("Restarting MCP server", "server", c.serverName, "attempt", *attempt, "delay", delay)
+		select {
+		case <-time.After(delay):
+		case <-c.ctx.Done():
+			return nil, false
+		}
+
+		exited, err := c.start(true)
+		if err == nil {
+			monitoring.MCPServerRestarts.WithLabelValues(c.serverName, "success").Inc()
+			c.log.InfoKV("MCP server restarted", "server", c.serverName, "attempt", *attempt)
+			c.emit(ServerEvent{Server: c.serverName, Type: ServerEventRestarted, Attempt: *attempt})
+			return exited, true
+		}
+		if c.ctx.Err() != nil {
+			return nil, false
+		}
+		monitoring.MCPServerRestarts.WithLabelValues(c.serverName, "failure").Inc()
+		c.log.WarnKV("MCP server restart failed", "server", c.serverName, "attempt", *attempt, "error", err)
+		exitErr = err
+	}
+}
+
+func (c *SupervisedStdioClient) emit(event ServerEvent) {
+	c.handlerMu.RLock()
+	handler := c.onEvent
+	c.handlerMu.RUnlock()
+	if handler != nil {
+		handler(event)
+	}
+}
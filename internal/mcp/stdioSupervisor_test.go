@@ -0,0 +1,212 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+)
+
+// TestHelperMCPServer is not a real test: the supervisor tests run the test binary
+// as a stdio server with a tool reporting its pid and one crashing it
+func TestHelperMCPServer(t *testing.T) {
+	if os.Getenv("MCP_HELPER_SERVER") != "1" {
+		t.Skip("helper process")
+	}
+	s := server.NewMCPServer("helper", "1.0.0")
+	s.AddTool(mcp.NewTool("pid"), func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(fmt.Sprint(os.Getpid())), nil
+	})
+	s.AddTool(mcp.NewTool("crash"), func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		time.AfterFunc(50*time.Millisecond, func() { os.Exit(1) })
+		return mcp.NewToolResultText("bye"), nil
+	})
+	s.AddTool(mcp.NewTool("hang"), func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Ignores cancellation, like a server stuck in a long computation
+		time.Sleep(time.Hour)
+		return mcp.NewToolResultText("late"), nil
+	})
+	_ = server.ServeStdio(s)
+	os.Exit(0)
+}
+
+func TestRestartPolicyBackoff(t *testing.T) {
+	policy := RestartPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	assert.Equal(t, time.Second, policy.backoff(1))
+	assert.Equal(t, 2*time.Second, policy.backoff(2))
+	assert.Equal(t, 4*time.Second, policy.backoff(3))
+	assert.Equal(t, 5*time.Second, policy.backoff(4))
+	assert.Equal(t, 5*time.Second, policy.backoff(10))
+}
+
+func TestSupervisedStdioClientRestarts(t *testing.T) {
+	policy := RestartPolicy{
+		MaxAttempts:       2,
+		InitialBackoff:    10 * time.Millisecond,
+		MaxBackoff:        20 * time.Millisecond,
+		StableAfter:       time.Hour,
+		InitializeTimeout: 5 * time.Second,
+	}
+	env := append(os.Environ(), "MCP_HELPER_SERVER=1")
+	c, err := NewSupervisedStdioClient("helper", os.Args[0], []string{"-test.run=^TestHelperMCPServer$"}, env, policy, logging.New("test", logging.LevelError))
+	require.NoError(t, err)
+	defer func() { _ = c.Close() }()
+
+	events := make(chan ServerEvent, 10)
+	c.OnEvent(func(event ServerEvent) { events <- event })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	initReq := mcp.InitializeRequest{}
+	initReq.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	_, err = c.Initialize(ctx, initReq)
+	require.NoError(t, err)
+
+	call := func(name string) (string, error) {
+		req := mcp.CallToolRequest{}
+		req.Params.Name = name
+		result, err := c.CallTool(ctx, req)
+		if err != nil {
+			return "", err
+		}
+		return result.Content[0].(mcp.TextContent).Text, nil
+	}
+	nextEvent := func() ServerEvent {
+		select {
+		case event := <-events:
+			return event
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for a server event")
+			return ServerEvent{}
+		}
+	}
+
+	pid, err := call("pid")
+	require.NoError(t, err)
+
+	for attempt := 1; attempt <= policy.MaxAttempts; attempt++ {
+		_, err = call("crash")
+		require.NoError(t, err)
+
+		crashed := nextEvent()
+		assert.Equal(t, ServerEventCrashed, crashed.Type)
+		assert.Equal(t, attempt, crashed.Attempt)
+		assert.Error(t, crashed.Err)
+		restarted := nextEvent()
+		assert.Equal(t, ServerEventRestarted, restarted.Type)
+
+		// The restarted process is a new one and serves calls again
+		newPid, err := call("pid")
+		require.NoError(t, err)
+		assert.NotEqual(t, pid, newPid)
+		pid = newPid
+	}
+
+	// Crashing once more than the policy allows gives up on the server
+	_, err = call("crash")
+	require.NoError(t, err)
+	gaveUp := nextEvent()
+	assert.Equal(t, ServerEventGaveUp, gaveUp.Type)
+	assert.Equal(t, policy.MaxAttempts, gaveUp.Attempt)
+	_, err = call("pid")
+	assert.ErrorContains(t, err, "is down after 2 restart attempts")
+}
+
+func TestSupervisedStdioClientKillsOnCancel(t *testing.T) {
+	policy := RestartPolicy{
+		MaxAttempts:       1,
+		InitialBackoff:    10 * time.Millisecond,
+		MaxBackoff:        20 * time.Millisecond,
+		StableAfter:       time.Hour,
+		InitializeTimeout: 5 * time.Second,
+		KillOnCancel:      true,
+	}
+	env := append(os.Environ(), "MCP_HELPER_SERVER=1")
+	c, err := NewSupervisedStdioClient("helper", os.Args[0], []string{"-test.run=^TestHelperMCPServer$"}, env, policy, logging.New("test", logging.LevelError))
+	require.NoError(t, err)
+	defer func() { _ = c.Close() }()
+
+	events := make(chan ServerEvent, 10)
+	c.OnEvent(func(event ServerEvent) { events <- event })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	initReq := mcp.InitializeRequest{}
+	initReq.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	_, err = c.Initialize(ctx, initReq)
+	require.NoError(t, err)
+
+	call := func(ctx context.Context, name string) (string, error) {
+		req := mcp.CallToolRequest{}
+		req.Params.Name = name
+		result, err := c.CallTool(ctx, req)
+		if err != nil {
+			return "", err
+		}
+		return result.Content[0].(mcp.TextContent).Text, nil
+	}
+	pid, err := call(ctx, "pid")
+	require.NoError(t, err)
+
+	// Killing the process twice does not use up the single restart attempt
+	for i := 0; i < 2; i++ {
+		callCtx, callCancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		_, err = call(callCtx, "hang")
+		callCancel()
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		var crashed, restarted ServerEvent
+		select {
+		case crashed = <-events:
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for the process to be killed")
+		}
+		assert.ErrorContains(t, crashed.Err, "call to tool 'hang' was abandoned")
+		select {
+		case restarted = <-events:
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for the restart")
+		}
+		assert.Equal(t, ServerEventRestarted, restarted.Type)
+
+		newPid, err := call(ctx, "pid")
+		require.NoError(t, err)
+		assert.NotEqual(t, pid, newPid)
+		pid = newPid
+	}
+
+	// A call abandoned while another is running keeps the process for it; the
+	// process is killed once the last call is abandoned
+	slowCtx, slowCancel := context.WithTimeout(ctx, time.Second)
+	defer slowCancel()
+	slowErr := make(chan error, 1)
+	go func() {
+		_, err := call(slowCtx, "hang")
+		slowErr <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	callCtx, callCancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	_, err = call(callCtx, "hang")
+	callCancel()
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	select {
+	case event := <-events:
+		t.Fatalf("process killed while a call was running: %v", event)
+	case <-time.After(200 * time.Millisecond):
+	}
+	assert.ErrorIs(t, <-slowErr, context.DeadlineExceeded)
+	select {
+	case crashed := <-events:
+		assert.ErrorContains(t, crashed.Err, "call to tool 'hang' was abandoned")
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the process to be killed")
+	}
+}
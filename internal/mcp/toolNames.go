@@ -0,0 +1,80 @@
+package mcp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+// ToolCollision describes a tool name offered by more than one server
+type ToolCollision struct {
+	Tool    string
+	Kept    string // Server whose tool is used
+	Dropped string // Server whose tool is ignored
+}
+
+// String returns a human-readable description of the collision
+func (c ToolCollision) String() string {
+	return fmt.Sprintf("Tool '%s' is offered by '%s' and '%s'; using '%s'", c.Tool, c.Kept, c.Dropped, c.Kept)
+}
+
+// ExposedToolName returns the name a server's tool is exposed to the LLM under.
+// The prefix strategy namespaces every tool with its server name; the other
+// strategies keep the tool's own name.
+func ExposedToolName(cfg config.ToolCollisionConfig, serverName, toolName string) string {
+	if cfg.Strategy == config.ToolCollisionPrefix || cfg.Strategy == "" {
+		return fmt.Sprintf("%s_%s", serverName, toolName)
+	}
+	return toolName
+}
+
+// MergeTools adds a server's tools to the tool map, resolving name collisions
+// with the configured strategy. With the priority strategy the server listed
+// first wins; otherwise the tool registered first is kept. The error strategy
+// returns an error and leaves tools unchanged if any name collides.
+func MergeTools(cfg config.ToolCollisionConfig, tools map[string]ToolInfo, incoming map[string]ToolInfo) ([]ToolCollision, error) {
+	var collisions []ToolCollision
+	for name, tool := range incoming {
+		existing, exists := tools[name]
+		if !exists || existing.ServerName == tool.ServerName {
+			continue
+		}
+		collision := ToolCollision{Tool: name, Kept: existing.ServerName, Dropped: tool.ServerName}
+		if cfg.Strategy == config.ToolCollisionPriority && serverRank(cfg, tool.ServerName) < serverRank(cfg, existing.ServerName) {
+			collision.Kept, collision.Dropped = tool.ServerName, existing.ServerName
+		}
+		collisions = append(collisions, collision)
+	}
+
+	if cfg.Strategy == config.ToolCollisionError && len(collisions) > 0 {
+		descriptions := make([]string, 0, len(collisions))
+		for _, collision := range collisions {
+			descriptions = append(descriptions, fmt.Sprintf("'%s' (%s, %s)", collision.Tool, collision.Kept, collision.Dropped))
+		}
+		return collisions, fmt.Errorf("tool name collision: %s", strings.Join(descriptions, ", "))
+	}
+
+	dropped := make(map[string]bool, len(collisions))
+	for _, collision := range collisions {
+		if incoming[collision.Tool].ServerName == collision.Dropped {
+			dropped[collision.Tool] = true
+		}
+	}
+	for name, tool := range incoming {
+		if !dropped[name] {
+			tools[name] = tool
+		}
+	}
+	return collisions, nil
+}
+
+// serverRank returns the position of a server in the priority list; unlisted servers rank last
+func serverRank(cfg config.ToolCollisionConfig, serverName string) int {
+	for i, name := range cfg.Priority {
+		if name == serverName {
+			return i
+		}
+	}
+	return len(cfg.Priority)
+}
@@ -0,0 +1,68 @@
+package mcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+func serverTools(serverName string, names ...string) map[string]ToolInfo {
+	tools := make(map[string]ToolInfo, len(names))
+	for _, name := range names {
+		tools[name] = ToolInfo{ServerName: serverName, ToolName: name}
+	}
+	return tools
+}
+
+func TestExposedToolName(t *testing.T) {
+	assert.Equal(t, "github_search", ExposedToolName(config.ToolCollisionConfig{}, "github", "search"))
+	assert.Equal(t, "github_search", ExposedToolName(config.ToolCollisionConfig{Strategy: config.ToolCollisionPrefix}, "github", "search"))
+	assert.Equal(t, "search", ExposedToolName(config.ToolCollisionConfig{Strategy: config.ToolCollisionPriority}, "github", "search"))
+	assert.Equal(t, "search", ExposedToolName(config.ToolCollisionConfig{Strategy: config.ToolCollisionError}, "github", "search"))
+}
+
+func TestMergeToolsKeepsFirst(t *testing.T) {
+	tools := serverTools("github", "search", "issues")
+
+	collisions, err := MergeTools(config.ToolCollisionConfig{Strategy: config.ToolCollisionPrefix}, tools, serverTools("gitlab", "search", "pipelines"))
+	require.NoError(t, err)
+	require.Len(t, collisions, 1)
+	assert.Equal(t, ToolCollision{Tool: "search", Kept: "github", Dropped: "gitlab"}, collisions[0])
+	assert.Equal(t, "github", tools["search"].ServerName)
+	assert.Equal(t, "gitlab", tools["pipelines"].ServerName)
+}
+
+func TestMergeToolsPriority(t *testing.T) {
+	cfg := config.ToolCollisionConfig{Strategy: config.ToolCollisionPriority, Priority: []string{"gitlab", "github"}}
+	tools := serverTools("github", "search")
+
+	collisions, err := MergeTools(cfg, tools, serverTools("gitlab", "search"))
+	require.NoError(t, err)
+	require.Len(t, collisions, 1)
+	assert.Equal(t, "gitlab", collisions[0].Kept)
+	assert.Equal(t, "gitlab", tools["search"].ServerName)
+
+	// An unlisted server never displaces a listed one
+	collisions, err = MergeTools(cfg, tools, serverTools("other", "search"))
+	require.NoError(t, err)
+	require.Len(t, collisions, 1)
+	assert.Equal(t, "gitlab", tools["search"].ServerName)
+}
+
+func TestMergeToolsError(t *testing.T) {
+	cfg := config.ToolCollisionConfig{Strategy: config.ToolCollisionError}
+	tools := serverTools("github", "search")
+
+	_, err := MergeTools(cfg, tools, serverTools("gitlab", "search", "pipelines"))
+	assert.Error(t, err)
+	_, added := tools["pipelines"]
+	assert.False(t, added, "tools must be unchanged on error")
+
+	collisions, err := MergeTools(cfg, tools, serverTools("gitlab", "pipelines"))
+	require.NoError(t, err)
+	assert.Empty(t, collisions)
+	assert.Contains(t, tools, "pipelines")
+}
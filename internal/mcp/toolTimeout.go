@@ -0,0 +1,65 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ToolTimeoutError is returned for a tool call that did not finish within its timeout
+type ToolTimeoutError struct {
+	Tool    string
+	Timeout time.Duration
+}
+
+func (e *ToolTimeoutError) Error() string {
+	return fmt.Sprintf("tool '%s' did not finish within %s and was cancelled", e.Tool, e.Timeout)
+}
+
+// SlowToolFunc is called when a tool call is still running after the notice
+// threshold. The function it returns, if any, is called once the call ends.
+type SlowToolFunc func(toolName string, timeout time.Duration) (finished func())
+
+type slowToolNotice struct {
+	after  time.Duration
+	notify SlowToolFunc
+}
+
+type slowToolNoticeKey struct{}
+
+// WithSlowToolNotice returns a context whose tool calls report to notify when they
+// run longer than after
+func WithSlowToolNotice(ctx context.Context, after time.Duration, notify SlowToolFunc) context.Context {
+	return context.WithValue(ctx, slowToolNoticeKey{}, slowToolNotice{after: after, notify: notify})
+}
+
+// CallToolWithTimeout calls a tool, cancelling the call when it runs longer than
+// timeout (no limit when zero), and reports slow calls to the context's SlowToolFunc
+func CallToolWithTimeout(ctx context.Context, client MCPClientInterface, toolName string, timeout time.Duration, args map[string]interface{}) (string, error) {
+	callCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	if notice, ok := ctx.Value(slowToolNoticeKey{}).(slowToolNotice); ok && notice.after > 0 {
+		finished := make(chan func(), 1)
+		timer := time.AfterFunc(notice.after, func() { finished <- notice.notify(toolName, timeout) })
+		defer func() {
+			if timer.Stop() {
+				return
+			}
+			if done := <-finished; done != nil {
+				done()
+			}
+		}()
+	}
+
+	result, err := client.CallTool(callCtx, toolName, args)
+	if err != nil && ctx.Err() == nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+		return "", &ToolTimeoutError{Tool: toolName, Timeout: timeout}
+	}
+	return result, err
+}
@@ -0,0 +1,64 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sleepyClient answers tool calls after a delay, unless the call is cancelled first
+type sleepyClient struct {
+	delay time.Duration
+}
+
+func (c sleepyClient) CallTool(ctx context.Context, _ string, _ map[string]interface{}) (string, error) {
+	select {
+	case <-time.After(c.delay):
+		return "done", nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func TestCallToolWithTimeout(t *testing.T) {
+	result, err := CallToolWithTimeout(context.Background(), sleepyClient{delay: time.Millisecond}, "search", time.Second, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "done", result)
+
+	_, err = CallToolWithTimeout(context.Background(), sleepyClient{delay: time.Second}, "search", 20*time.Millisecond, nil)
+	var timeoutErr *ToolTimeoutError
+	require.True(t, errors.As(err, &timeoutErr))
+	assert.Equal(t, "search", timeoutErr.Tool)
+	assert.Equal(t, 20*time.Millisecond, timeoutErr.Timeout)
+
+	// A call stopped by its caller did not time out
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	_, err = CallToolWithTimeout(ctx, sleepyClient{delay: time.Second}, "search", time.Minute, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.False(t, errors.As(err, &timeoutErr))
+}
+
+func TestCallToolWithTimeoutSlowNotice(t *testing.T) {
+	var notified, finished atomic.Int32
+	ctx := WithSlowToolNotice(context.Background(), 20*time.Millisecond, func(toolName string, timeout time.Duration) func() {
+		assert.Equal(t, "search", toolName)
+		assert.Equal(t, time.Minute, timeout)
+		notified.Add(1)
+		return func() { finished.Add(1) }
+	})
+
+	_, err := CallToolWithTimeout(ctx, sleepyClient{delay: time.Millisecond}, "search", time.Minute, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), notified.Load(), "fast calls are not announced")
+
+	_, err = CallToolWithTimeout(ctx, sleepyClient{delay: 100 * time.Millisecond}, "search", time.Minute, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), notified.Load())
+	assert.Equal(t, int32(1), finished.Load(), "the notice is withdrawn when the call ends")
+}
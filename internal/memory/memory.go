@@ -0,0 +1,220 @@
+// Package memory keeps long-term facts about users and teams, such as who owns a
+// service or when deploys happen, learned from conversations. Each fact records
+// where it was learned so it can be traced back and removed when it is wrong.
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/filestore"
+)
+
+// ScopeTeam is the scope of facts about the team rather than a single user. Team
+// facts are only recalled in the channel they were learned in, so what is said in
+// one channel does not reach the members of another.
+const ScopeTeam = "team"
+
+// Source is where a fact was learned
+type Source struct {
+	ChannelID string `json:"channel"`
+	ThreadTS  string `json:"threadTs,omitempty"`
+	MessageTS string `json:"messageTs,omitempty"`
+	UserID    string `json:"user"` // User whose message stated the fact
+}
+
+// Fact is a durable statement about a user or the team
+type Fact struct {
+	ID      string    `json:"id"`
+	Scope   string    `json:"scope"` // ScopeTeam, or the ID of the user the fact is about
+	Text    string    `json:"text"`
+	Source  Source    `json:"source"`
+	Created time.Time `json:"created"`
+}
+
+// file is the persisted form of a store
+type file struct {
+	NextID int    `json:"nextId"`
+	Facts  []Fact `json:"facts"`
+}
+
+// Store keeps facts, oldest first. With a path, it is persisted to a JSON file so
+// facts survive restarts.
+type Store struct {
+	path     string
+	maxFacts int
+	now      func() time.Time
+
+	mu     sync.Mutex
+	nextID int
+	facts  []Fact
+}
+
+// NewStore opens (or creates) a store that keeps at most maxFacts facts. An empty
+// path keeps the facts in memory only.
+func NewStore(path string, maxFacts int) (*Store, error) {
+	s := &Store{path: path, maxFacts: maxFacts, now: time.Now, nextID: 1}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Add stores a fact and returns it with its ID. A fact already known in the same
+// scope is not added again, and false is returned.
+func (s *Store) Add(fact Fact) (Fact, bool, error) {
+	fact.Text = strings.TrimSpace(fact.Text)
+	if fact.Text == "" || fact.Scope == "" {
+		return Fact{}, false, fmt.Errorf("fact needs a scope and text")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	normalized := normalize(fact.Text)
+	for _, existing := range s.facts {
+		if existing.Scope == fact.Scope && normalize(existing.Text) == normalized {
+			return existing, false, nil
+		}
+	}
+	fact.ID = strconv.Itoa(s.nextID)
+	s.nextID++
+	if fact.Created.IsZero() {
+		fact.Created = s.now()
+	}
+	s.facts = append(s.facts, fact)
+	if s.maxFacts > 0 && len(s.facts) > s.maxFacts {
+		s.facts = s.facts[len(s.facts)-s.maxFacts:]
+	}
+	return fact, true, s.save()
+}
+
+// Get returns the fact with the ID
+func (s *Store) Get(id string) (Fact, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, fact := range s.facts {
+		if fact.ID == id {
+			return fact, true
+		}
+	}
+	return Fact{}, false
+}
+
+// Delete removes the fact with the ID and reports whether it existed
+func (s *Store) Delete(id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, fact := range s.facts {
+		if fact.ID == id {
+			s.facts = append(s.facts[:i:i], s.facts[i+1:]...)
+			return true, s.save()
+		}
+	}
+	return false, nil
+}
+
+// List returns the facts about the user and the team facts learned in the
+// channel, newest first
+func (s *Store) List(userID, channelID string) []Fact {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var facts []Fact
+	for i := len(s.facts) - 1; i >= 0; i-- {
+		fact := s.facts[i]
+		if fact.Scope == userID || (fact.Scope == ScopeTeam && fact.Source.ChannelID == channelID) {
+			facts = append(facts, fact)
+		}
+	}
+	return facts
+}
+
+// Recall returns up to limit facts about the user and the team facts learned in
+// the channel, those sharing the most words with the query first and the newest
+// first among equals
+func (s *Store) Recall(userID, channelID, query string, limit int) []Fact {
+	facts := s.List(userID, channelID)
+	queryWords := words(query)
+	scores := make(map[string]int, len(facts))
+	for _, fact := range facts {
+		for word := range words(fact.Text) {
+			if queryWords[word] {
+				scores[fact.ID]++
+			}
+		}
+	}
+	sort.SliceStable(facts, func(i, j int) bool { return scores[facts[i].ID] > scores[facts[j].ID] })
+	if limit > 0 && len(facts) > limit {
+		facts = facts[:limit]
+	}
+	return facts
+}
+
+// load reads the store file if it exists
+func (s *Store) load() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read memory store: %w", err)
+	}
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("failed to parse memory store: %w", err)
+	}
+	s.facts = f.Facts
+	if f.NextID > s.nextID {
+		s.nextID = f.NextID
+	}
+	return nil
+}
+
+// save atomically writes the store file; callers must hold mu
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+	if err := filestore.WriteJSON(s.path, file{NextID: s.nextID, Facts: s.facts}); err != nil {
+		return fmt.Errorf("failed to save memory store: %w", err)
+	}
+	return nil
+}
+
+// normalize reduces a fact to its words, so facts differing only in case or
+// punctuation are the same
+func normalize(text string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(text), isSeparator), " ")
+}
+
+// stopWords are left out when matching facts to a query
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "at": true, "by": true, "do": true, "does": true,
+	"for": true, "how": true, "i": true, "in": true, "is": true, "it": true, "me": true, "my": true,
+	"of": true, "on": true, "or": true, "the": true, "to": true, "we": true, "what": true, "when": true,
+	"who": true, "with": true, "you": true,
+}
+
+// words returns the distinct lowercase words of a text, without stop words
+func words(text string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), isSeparator) {
+		if !stopWords[word] {
+			set[word] = true
+		}
+	}
+	return set
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
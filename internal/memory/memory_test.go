@@ -0,0 +1,105 @@
+package memory
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreAddSkipsKnownFacts(t *testing.T) {
+	store, err := NewStore("", 0)
+	require.NoError(t, err)
+
+	fact, added, err := store.Add(Fact{Scope: ScopeTeam, Text: "Deploys happen on Tuesdays.", Source: Source{ChannelID: "C1", UserID: "U1"}})
+	require.NoError(t, err)
+	assert.True(t, added)
+	assert.Equal(t, "1", fact.ID)
+	assert.False(t, fact.Created.IsZero())
+
+	// The same fact in other words is known; the same text about a user is not
+	known, added, err := store.Add(Fact{Scope: ScopeTeam, Text: "deploys happen on tuesdays"})
+	require.NoError(t, err)
+	assert.False(t, added)
+	assert.Equal(t, "1", known.ID)
+	_, added, err = store.Add(Fact{Scope: "U1", Text: "Deploys happen on Tuesdays."})
+	require.NoError(t, err)
+	assert.True(t, added)
+
+	_, _, err = store.Add(Fact{Scope: ScopeTeam, Text: "  "})
+	assert.Error(t, err)
+}
+
+func TestStoreListAndRecall(t *testing.T) {
+	store, err := NewStore("", 0)
+	require.NoError(t, err)
+	now := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { now = now.Add(time.Minute); return now }
+	for _, fact := range []Fact{
+		{Scope: ScopeTeam, Text: "Alice owns the billing service", Source: Source{ChannelID: "C1"}},
+		{Scope: "U1", Text: "Bob is on the payments team", Source: Source{ChannelID: "C2"}},
+		{Scope: "U2", Text: "Carol prefers answers in French", Source: Source{ChannelID: "C1"}},
+		{Scope: ScopeTeam, Text: "Deploys happen on Tuesdays", Source: Source{ChannelID: "C1"}},
+		{Scope: ScopeTeam, Text: "The merger closes in March", Source: Source{ChannelID: "C2"}},
+	} {
+		_, _, err := store.Add(fact)
+		require.NoError(t, err)
+	}
+
+	// Other users' facts and other channels' team facts are left out, newest first
+	var texts []string
+	for _, fact := range store.List("U1", "C1") {
+		texts = append(texts, fact.Text)
+	}
+	assert.Equal(t, []string{"Deploys happen on Tuesdays", "Bob is on the payments team", "Alice owns the billing service"}, texts)
+
+	recalled := store.Recall("U1", "C1", "Who owns billing?", 2)
+	require.Len(t, recalled, 2)
+	assert.Equal(t, "Alice owns the billing service", recalled[0].Text)
+	assert.Equal(t, "Deploys happen on Tuesdays", recalled[1].Text)
+}
+
+func TestStoreDeleteAndLimit(t *testing.T) {
+	store, err := NewStore("", 2)
+	require.NoError(t, err)
+	for _, text := range []string{"one", "two", "three"} {
+		_, _, err := store.Add(Fact{Scope: ScopeTeam, Text: text})
+		require.NoError(t, err)
+	}
+	_, ok := store.Get("1")
+	assert.False(t, ok, "the oldest fact is dropped past the limit")
+
+	deleted, err := store.Delete("2")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	deleted, err = store.Delete("2")
+	require.NoError(t, err)
+	assert.False(t, deleted)
+	assert.Len(t, store.List("", ""), 1)
+}
+
+func TestStorePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "memory", "facts.json")
+	store, err := NewStore(path, 0)
+	require.NoError(t, err)
+	source := Source{ChannelID: "C1", ThreadTS: "100.1", MessageTS: "100.2", UserID: "U1"}
+	_, _, err = store.Add(Fact{Scope: "U1", Text: "Bob is on call this week", Source: source})
+	require.NoError(t, err)
+	_, err = store.Delete("1")
+	require.NoError(t, err)
+	_, _, err = store.Add(Fact{Scope: "U1", Text: "Bob owns the API gateway", Source: source})
+	require.NoError(t, err)
+
+	reopened, err := NewStore(path, 0)
+	require.NoError(t, err)
+	fact, ok := reopened.Get("2")
+	require.True(t, ok)
+	assert.Equal(t, source, fact.Source)
+
+	// IDs of deleted facts are not reused
+	next, _, err := reopened.Add(Fact{Scope: ScopeTeam, Text: "Staging resets nightly"})
+	require.NoError(t, err)
+	assert.Equal(t, "3", next.ID)
+}
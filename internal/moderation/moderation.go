@@ -0,0 +1,84 @@
+// Package moderation classifies user prompts and LLM outputs against a content
+// safety policy, using either the OpenAI moderation endpoint or local patterns.
+package moderation
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+// Result is the outcome of classifying a piece of text
+type Result struct {
+	Flagged    bool
+	Categories []string // Categories that triggered the flag, sorted
+}
+
+// Classifier decides whether text violates the content policy
+type Classifier interface {
+	Classify(ctx context.Context, text string) (Result, error)
+}
+
+// NewClassifier creates the classifier selected by the moderation configuration
+func NewClassifier(cfg *config.Config, logger *logging.Logger) (Classifier, error) {
+	switch cfg.Moderation.Provider {
+	case config.ModerationProviderOpenAI, "":
+		apiKey := cfg.Moderation.APIKey
+		if apiKey == "" {
+			apiKey = cfg.LLM.Providers[config.ProviderOpenAI].APIKey
+		}
+		timeout, err := time.ParseDuration(cfg.Timeouts.HTTPRequestTimeout)
+		if err != nil {
+			timeout = 30 * time.Second
+		}
+		logger.InfoKV("Using OpenAI content moderation", "model", cfg.Moderation.Model)
+		return NewOpenAIClassifier(cfg.Moderation.BaseURL, apiKey, cfg.Moderation.Model, &http.Client{Timeout: timeout}), nil
+	case config.ModerationProviderLocal:
+		logger.InfoKV("Using local pattern content moderation", "categories", len(cfg.Moderation.Patterns))
+		return NewPatternClassifier(cfg.Moderation.Patterns)
+	default:
+		return nil, fmt.Errorf("unknown moderation provider '%s'", cfg.Moderation.Provider)
+	}
+}
+
+// PatternClassifier flags text matching any configured regular expression
+type PatternClassifier struct {
+	patterns map[string][]*regexp.Regexp
+}
+
+// NewPatternClassifier compiles the category patterns (matched case-insensitively)
+func NewPatternClassifier(patterns map[string][]string) (*PatternClassifier, error) {
+	compiled := make(map[string][]*regexp.Regexp, len(patterns))
+	for category, expressions := range patterns {
+		for _, expression := range expressions {
+			re, err := regexp.Compile("(?i)" + expression)
+			if err != nil {
+				return nil, fmt.Errorf("invalid moderation pattern for category '%s': %w", category, err)
+			}
+			compiled[category] = append(compiled[category], re)
+		}
+	}
+	return &PatternClassifier{patterns: compiled}, nil
+}
+
+// Classify implements Classifier
+func (p *PatternClassifier) Classify(_ context.Context, text string) (Result, error) {
+	var result Result
+	for category, expressions := range p.patterns {
+		for _, re := range expressions {
+			if re.MatchString(text) {
+				result.Categories = append(result.Categories, category)
+				break
+			}
+		}
+	}
+	sort.Strings(result.Categories)
+	result.Flagged = len(result.Categories) > 0
+	return result, nil
+}
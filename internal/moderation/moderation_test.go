@@ -0,0 +1,77 @@
+package moderation
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatternClassifier(t *testing.T) {
+	classifier, err := NewPatternClassifier(map[string][]string{
+		"credentials": {`password\s*[:=]`, `AKIA[0-9A-Z]{16}`},
+		"profanity":   {`\bdarn\b`},
+	})
+	require.NoError(t, err)
+
+	result, err := classifier.Classify(context.Background(), "my PASSWORD: hunter2, darn it")
+	require.NoError(t, err)
+	assert.True(t, result.Flagged)
+	assert.Equal(t, []string{"credentials", "profanity"}, result.Categories)
+
+	result, err = classifier.Classify(context.Background(), "what's the weather?")
+	require.NoError(t, err)
+	assert.False(t, result.Flagged)
+	assert.Empty(t, result.Categories)
+}
+
+func TestPatternClassifierInvalidPattern(t *testing.T) {
+	_, err := NewPatternClassifier(map[string][]string{"bad": {"("}})
+	assert.Error(t, err)
+}
+
+func TestOpenAIClassifier(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/moderations", r.URL.Path)
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+
+		var req moderationRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "omni-moderation-latest", req.Model)
+
+		flagged := req.Input == "bad"
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"results": []map[string]interface{}{{
+				"flagged":    flagged,
+				"categories": map[string]bool{"harassment": flagged, "violence": false},
+			}},
+		})
+	}))
+	defer server.Close()
+
+	classifier := NewOpenAIClassifier(server.URL+"/", "test-key", "omni-moderation-latest", server.Client())
+
+	result, err := classifier.Classify(context.Background(), "bad")
+	require.NoError(t, err)
+	assert.True(t, result.Flagged)
+	assert.Equal(t, []string{"harassment"}, result.Categories)
+
+	result, err = classifier.Classify(context.Background(), "good")
+	require.NoError(t, err)
+	assert.False(t, result.Flagged)
+}
+
+func TestOpenAIClassifierErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "invalid key", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	classifier := NewOpenAIClassifier(server.URL, "bad-key", "", server.Client())
+	_, err := classifier.Classify(context.Background(), "hello")
+	assert.Error(t, err)
+}
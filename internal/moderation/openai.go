@@ -0,0 +1,88 @@
+package moderation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// OpenAIClassifier calls the OpenAI moderation endpoint
+type OpenAIClassifier struct {
+	baseURL    string
+	apiKey     string
+	model      string
+	httpClient *http.Client
+}
+
+// NewOpenAIClassifier creates a classifier backed by the OpenAI moderation API
+func NewOpenAIClassifier(baseURL, apiKey, model string, httpClient *http.Client) *OpenAIClassifier {
+	return &OpenAIClassifier{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		apiKey:     apiKey,
+		model:      model,
+		httpClient: httpClient,
+	}
+}
+
+type moderationRequest struct {
+	Model string `json:"model,omitempty"`
+	Input string `json:"input"`
+}
+
+type moderationResponse struct {
+	Results []struct {
+		Flagged    bool            `json:"flagged"`
+		Categories map[string]bool `json:"categories"`
+	} `json:"results"`
+}
+
+// Classify implements Classifier
+func (o *OpenAIClassifier) Classify(ctx context.Context, text string) (Result, error) {
+	body, err := json.Marshal(moderationRequest{Model: o.model, Input: text})
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to marshal moderation request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.baseURL+"/moderations", bytes.NewReader(body))
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to create moderation request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+o.apiKey)
+
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("moderation request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return Result{}, fmt.Errorf("moderation request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+
+	var parsed moderationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return Result{}, fmt.Errorf("failed to decode moderation response: %w", err)
+	}
+
+	var result Result
+	for _, r := range parsed.Results {
+		if !r.Flagged {
+			continue
+		}
+		result.Flagged = true
+		for category, hit := range r.Categories {
+			if hit {
+				result.Categories = append(result.Categories, category)
+			}
+		}
+	}
+	sort.Strings(result.Categories)
+	return result, nil
+}
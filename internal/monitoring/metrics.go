@@ -14,6 +14,35 @@ const (
 
 	MetricLabelType  = "type"
 	MetricLabelModel = "model"
+
+	MetricLabelReplica = "replica"
+	MetricLabelOutcome = "outcome"
+
+	MetricLabelDirection = "direction"
+	MetricLabelCategory  = "category"
+
+	MetricLabelRoute  = "route"
+	MetricLabelReason = "reason"
+
+	MetricLabelMethod = "method"
+	MetricLabelFamily = "family"
+
+	MetricLabelExperiment = "experiment"
+	MetricLabelArm        = "arm"
+	MetricLabelSentiment  = "sentiment"
+
+	MetricLabelLimit = "limit"
+
+	MetricLabelProvider  = "provider"
+	MetricLabelSource    = "source"
+	MetricLabelOperation = "operation"
+
+	MetricLabelObjective = "objective"
+
+	MetricLabelAction = "action"
+
+	MetricLabelCommand = "command"
+	MetricLabelIntent  = "intent"
 )
 
 var (
@@ -32,11 +61,396 @@ var (
 		},
 		[]string{MetricLabelType, MetricLabelModel},
 	)
+	SlackEventsDeduped = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%sslack_events_total", prefix),
+			Help: "Total number of Slack events seen per replica, by de-duplication outcome (claimed, duplicate, error)",
+		},
+		[]string{MetricLabelReplica, MetricLabelOutcome},
+	)
+	SlackEventsDropped = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%sslack_events_dropped_total", prefix),
+			Help: "Total number of acknowledged events dropped because the event queue was full, by event type",
+		},
+		[]string{MetricLabelType},
+	)
+	SlackOutboundMessages = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%sslack_outbound_messages_total", prefix),
+			Help: "Total number of outbound Slack message delivery outcomes (sent, retried, dead_lettered)",
+		},
+		[]string{MetricLabelOutcome},
+	)
+	ModerationChecks = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%smoderation_checks_total", prefix),
+			Help: "Total number of content moderation checks by direction (input, output) and outcome (passed, flagged, error)",
+		},
+		[]string{MetricLabelDirection, MetricLabelOutcome},
+	)
+	ModerationFlagged = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%smoderation_flagged_total", prefix),
+			Help: "Total number of flagged content categories by direction (input, output)",
+		},
+		[]string{MetricLabelDirection, MetricLabelCategory},
+	)
+	GroundingChecks = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%sgrounding_checks_total", prefix),
+			Help: "Total number of answer grounding checks by source (tool, rag) and outcome (grounded, ungrounded, error)",
+		},
+		[]string{MetricLabelSource, MetricLabelOutcome},
+	)
+	LLMRouteDecisions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%sllm_route_decisions_total", prefix),
+			Help: "Total number of messages routed to each model route (cheap, powerful) by reason (channel, length, tool_intent, default)",
+		},
+		[]string{MetricLabelRoute, MetricLabelReason},
+	)
+	LLMRouteRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%sllm_route_requests_total", prefix),
+			Help: "Total number of LLM requests per model route (cheap, powerful) by outcome (success, error)",
+		},
+		[]string{MetricLabelRoute, MetricLabelModel, MetricLabelOutcome},
+	)
+	LLMRouteDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    fmt.Sprintf("%sllm_route_duration_seconds", prefix),
+			Help:    "Histogram of LLM request durations per model route (cheap, powerful)",
+			Buckets: prometheus.ExponentialBuckets(0.25, 2, 10),
+		},
+		[]string{MetricLabelRoute, MetricLabelModel},
+	)
+	IntentDecisions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%sintent_decisions_total", prefix),
+			Help: "Total number of messages classified by intent (chat, rag, tools) and source (heuristic, llm, fallback)",
+		},
+		[]string{MetricLabelIntent, MetricLabelSource},
+	)
+	LLMThreadAffinity = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%sllm_thread_affinity_total", prefix),
+			Help: "Total number of LLM calls in threads with a remembered model by outcome (kept, unavailable)",
+		},
+		[]string{MetricLabelOutcome},
+	)
+	ExperimentInteractions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%sexperiment_interactions_total", prefix),
+			Help: "Total number of prompts answered per experiment arm (control, variant)",
+		},
+		[]string{MetricLabelExperiment, MetricLabelArm},
+	)
+	ExperimentFeedback = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%sexperiment_feedback_total", prefix),
+			Help: "Total number of feedback reactions on answers per experiment arm (control, variant) by sentiment (positive, negative)",
+		},
+		[]string{MetricLabelExperiment, MetricLabelArm, MetricLabelSentiment},
+	)
+	ExperimentRollbacks = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%sexperiment_rollbacks_total", prefix),
+			Help: "Total number of experiment variants rolled back automatically",
+		},
+		[]string{MetricLabelExperiment},
+	)
+	SLOBurnRate = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: fmt.Sprintf("%sslo_burn_rate", prefix),
+			Help: "Rate at which each service level objective's error budget is spent over the SLO window, where 1 spends it exactly",
+		},
+		[]string{MetricLabelObjective},
+	)
+	SLOAlerts = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%sslo_alerts_total", prefix),
+			Help: "Total number of alerts fired because a service level objective's error budget burned too fast",
+		},
+		[]string{MetricLabelObjective},
+	)
+	PromptsWaiting = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: fmt.Sprintf("%sprompts_waiting", prefix),
+			Help: "Number of prompts waiting for a worker because all workers are busy",
+		},
+	)
+	PromptsDelayed = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%sprompts_delayed_total", prefix),
+			Help: "Total number of prompts that found every worker busy, by outcome (queued, rejected)",
+		},
+		[]string{MetricLabelOutcome},
+	)
+	AgentBudgetExceeded = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%sagent_budget_exceeded_total", prefix),
+			Help: "Total number of agent interactions stopped by their budget, by limit (tool_calls, tokens, duration)",
+		},
+		[]string{MetricLabelLimit},
+	)
+	MCPServerCrashes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%smcp_server_crashes_total", prefix),
+			Help: "Total number of times a supervised stdio MCP server process exited unexpectedly",
+		},
+		[]string{MetricLabelServer},
+	)
+	MCPServerRestarts = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%smcp_server_restarts_total", prefix),
+			Help: "Total number of stdio MCP server restart attempts by outcome (success, failure, gave_up)",
+		},
+		[]string{MetricLabelServer, MetricLabelOutcome},
+	)
+	MCPServerUp = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: fmt.Sprintf("%smcp_server_up", prefix),
+			Help: "Whether a supervised stdio MCP server process is running (1) or down (0)",
+		},
+		[]string{MetricLabelServer},
+	)
+	SlackAPIRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%sslack_api_requests_total", prefix),
+			Help: "Total number of Slack Web API calls by method and outcome (ok, rate_limited, error)",
+		},
+		[]string{MetricLabelMethod, MetricLabelOutcome},
+	)
+	SlackRateLimitRemaining = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: fmt.Sprintf("%sslack_rate_limit_remaining", prefix),
+			Help: "Estimated Slack Web API calls left in the current minute, by rate limit family (tier1-tier4, special) and method",
+		},
+		[]string{MetricLabelFamily, MetricLabelMethod},
+	)
+	SlackThreadFetches = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%sslack_thread_fetches_total", prefix),
+			Help: "Total number of thread reply fetches by type (full, incremental)",
+		},
+		[]string{MetricLabelType},
+	)
+	ToolResultDiffs = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%stool_result_diffs_total", prefix),
+			Help: "Total number of repeated tool calls diffed against the previous result by outcome (changed, unchanged)",
+		},
+		[]string{MetricLabelOutcome},
+	)
+	SlackDigests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%sslack_digests_total", prefix),
+			Help: "Total number of scheduled channel digests by outcome (posted, empty, error)",
+		},
+		[]string{MetricLabelOutcome},
+	)
+	SlackWorkflowSteps = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%sslack_workflow_steps_total", prefix),
+			Help: "Total number of Workflow Builder steps run by outcome (completed, error)",
+		},
+		[]string{MetricLabelOutcome},
+	)
+	UserErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%suser_errors_total", prefix),
+			Help: "Total number of errors users were told about by type (tool_timeout, server_unavailable, llm_rate_limited, ...)",
+		},
+		[]string{MetricLabelType},
+	)
+	MCPCircuitOpen = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: fmt.Sprintf("%smcp_circuit_open", prefix),
+			Help: "Whether the circuit breaker of each MCP server is open (1) because the server kept being unreachable",
+		},
+		[]string{MetricLabelServer},
+	)
+	MCPCircuitRejections = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%smcp_circuit_rejections_total", prefix),
+			Help: "Total number of tool calls failed at once because the circuit breaker of their MCP server was open",
+		},
+		[]string{MetricLabelServer},
+	)
+	MCPServerAlerts = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%smcp_server_alerts_total", prefix),
+			Help: "Total number of alerts posted to the admin channel about MCP servers that are down, by reason (init_failed, stopped, circuit_open)",
+		},
+		[]string{MetricLabelServer, MetricLabelReason},
+	)
+	SlackReactionActions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%sslack_reaction_actions_total", prefix),
+			Help: "Total number of quick actions run by reacting to answers by action (regenerate, expand, canvas, prompt) and outcome (completed, denied, error)",
+		},
+		[]string{MetricLabelAction, MetricLabelOutcome},
+	)
+	ScheduledJobs = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%sscheduled_jobs_total", prefix),
+			Help: "Total number of scheduled tool calls run by outcome (completed, failed, denied)",
+		},
+		[]string{MetricLabelOutcome},
+	)
+	CommandTemplateCalls = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%scommand_template_calls_total", prefix),
+			Help: "Total number of tool calls run by slash command templates by command and outcome (completed, failed, denied)",
+		},
+		[]string{MetricLabelCommand, MetricLabelOutcome},
+	)
+	ScheduledJobsPending = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: fmt.Sprintf("%sscheduled_jobs_pending", prefix),
+			Help: "Number of scheduled tool calls waiting to run",
+		},
+	)
+	RAGIngestions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%srag_ingestions_total", prefix),
+			Help: "Total number of documents ingested into the knowledge base by provider, source (file, url, connector) and outcome (ingested, unchanged, error)",
+		},
+		[]string{MetricLabelProvider, MetricLabelSource, MetricLabelOutcome},
+	)
+	RAGIngestedBytes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%srag_ingested_bytes_total", prefix),
+			Help: "Total number of bytes of documents ingested into the knowledge base by provider and source (file, url, connector)",
+		},
+		[]string{MetricLabelProvider, MetricLabelSource},
+	)
+	RAGIngestedChunks = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%srag_ingested_chunks_total", prefix),
+			Help: "Total number of chunks stored in the knowledge base by provider (providers that chunk on their server are not counted)",
+		},
+		[]string{MetricLabelProvider},
+	)
+	RAGSearchDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    fmt.Sprintf("%srag_search_duration_seconds", prefix),
+			Help:    "Histogram of knowledge base search durations, including reranking, by provider",
+			Buckets: prometheus.ExponentialBuckets(0.005, 2, 12),
+		},
+		[]string{MetricLabelProvider},
+	)
+	RAGSearchResults = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    fmt.Sprintf("%srag_search_results", prefix),
+			Help:    "Histogram of the number of results returned by knowledge base searches, by provider",
+			Buckets: []float64{0, 1, 2, 3, 5, 8, 13, 21},
+		},
+		[]string{MetricLabelProvider},
+	)
+	RAGProviderErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%srag_provider_errors_total", prefix),
+			Help: "Total number of failed knowledge base operations by provider and operation (search, ingest, stats, gc)",
+		},
+		[]string{MetricLabelProvider, MetricLabelOperation},
+	)
+	RAGStoreDocuments = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: fmt.Sprintf("%srag_store_documents", prefix),
+			Help: "Number of documents in the knowledge base, by provider",
+		},
+		[]string{MetricLabelProvider},
+	)
+	RAGStoreChunks = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: fmt.Sprintf("%srag_store_chunks", prefix),
+			Help: "Number of chunks in the knowledge base, by provider",
+		},
+		[]string{MetricLabelProvider},
+	)
+	RAGStoreSizeBytes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: fmt.Sprintf("%srag_store_size_bytes", prefix),
+			Help: "Storage size of the knowledge base in bytes, by provider (0 when the provider does not report it)",
+		},
+		[]string{MetricLabelProvider},
+	)
+	RAGStoreLastUpdated = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: fmt.Sprintf("%srag_store_last_updated_timestamp_seconds", prefix),
+			Help: "Unix time of the last change to the knowledge base, by provider",
+		},
+		[]string{MetricLabelProvider},
+	)
+	RAGGarbageCollected = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%srag_gc_removed_total", prefix),
+			Help: "Total number of items removed by knowledge base garbage collection, by provider and type (orphaned_file, failed_entry)",
+		},
+		[]string{MetricLabelProvider, MetricLabelType},
+	)
+	RAGGarbageCollectedBytes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%srag_gc_freed_bytes_total", prefix),
+			Help: "Total number of bytes of orphaned files removed by knowledge base garbage collection, by provider",
+		},
+		[]string{MetricLabelProvider},
+	)
 )
 
 func RegisterMetrics() {
 	prometheus.MustRegister(
 		ToolInvocations,
 		LLMTokensPerRequest,
+		SlackEventsDeduped,
+		SlackEventsDropped,
+		SlackOutboundMessages,
+		ModerationChecks,
+		ModerationFlagged,
+		GroundingChecks,
+		LLMRouteDecisions,
+		LLMRouteRequests,
+		LLMRouteDuration,
+		IntentDecisions,
+		LLMThreadAffinity,
+		ExperimentInteractions,
+		ExperimentFeedback,
+		ExperimentRollbacks,
+		SLOBurnRate,
+		SLOAlerts,
+		PromptsWaiting,
+		PromptsDelayed,
+		AgentBudgetExceeded,
+		MCPServerCrashes,
+		MCPServerRestarts,
+		MCPServerUp,
+		SlackAPIRequests,
+		SlackRateLimitRemaining,
+		SlackThreadFetches,
+		SlackDigests,
+		SlackWorkflowSteps,
+		SlackReactionActions,
+		UserErrors,
+		MCPCircuitOpen,
+		MCPCircuitRejections,
+		MCPServerAlerts,
+		ToolResultDiffs,
+		ScheduledJobs,
+		ScheduledJobsPending,
+		CommandTemplateCalls,
+		RAGIngestions,
+		RAGIngestedBytes,
+		RAGIngestedChunks,
+		RAGSearchDuration,
+		RAGSearchResults,
+		RAGProviderErrors,
+		RAGStoreDocuments,
+		RAGStoreChunks,
+		RAGStoreSizeBytes,
+		RAGStoreLastUpdated,
+		RAGGarbageCollected,
+		RAGGarbageCollectedBytes,
 	)
 }
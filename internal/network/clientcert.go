@@ -0,0 +1,146 @@
+package network
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+)
+
+// ClientCertificate is a client certificate for mutual TLS. When loaded from
+// files, it is reloaded on the next handshake after either file changes, so
+// rotated certificates are picked up without a restart.
+type ClientCertificate struct {
+	certFile string
+	keyFile  string
+	logger   *logging.Logger
+
+	mu       sync.Mutex
+	cert     *tls.Certificate
+	certTime time.Time // Modification times of the loaded files
+	keyTime  time.Time
+}
+
+// LoadClientCertificate loads a certificate and its key from PEM files
+func LoadClientCertificate(certFile, keyFile string, logger *logging.Logger) (*ClientCertificate, error) {
+	c := &ClientCertificate{certFile: certFile, keyFile: keyFile, logger: logger}
+	certTime, keyTime, err := c.modTimes()
+	if err != nil {
+		return nil, err
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client certificate: %w", err)
+	}
+	if err := checkValidity(&cert, time.Now()); err != nil {
+		return nil, err
+	}
+	c.cert, c.certTime, c.keyTime = &cert, certTime, keyTime
+	return c, nil
+}
+
+// ParseClientCertificate parses a certificate and its key from PEM contents
+func ParseClientCertificate(certPEM, keyPEM []byte) (*ClientCertificate, error) {
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse client certificate: %w", err)
+	}
+	if err := checkValidity(&cert, time.Now()); err != nil {
+		return nil, err
+	}
+	return &ClientCertificate{cert: &cert}, nil
+}
+
+// GetClientCertificate implements tls.Config.GetClientCertificate. If the files
+// changed but cannot be loaded, for example while only one of them has been
+// replaced, the previous certificate is kept and loading is retried next time.
+func (c *ClientCertificate) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.certFile == "" {
+		return c.cert, nil
+	}
+	certTime, keyTime, err := c.modTimes()
+	if err != nil || (certTime.Equal(c.certTime) && keyTime.Equal(c.keyTime)) {
+		return c.cert, nil
+	}
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err == nil {
+		err = checkValidity(&cert, time.Now())
+	}
+	if err != nil {
+		c.logger.WarnKV("Failed to reload client certificate, using the previous one", "cert_file", c.certFile, "error", err)
+		return c.cert, nil
+	}
+	c.cert, c.certTime, c.keyTime = &cert, certTime, keyTime
+	c.logger.InfoKV("Reloaded client certificate", "cert_file", c.certFile, "expires", cert.Leaf.NotAfter)
+	return c.cert, nil
+}
+
+// modTimes returns the modification times of the certificate and key files
+func (c *ClientCertificate) modTimes() (time.Time, time.Time, error) {
+	certInfo, err := os.Stat(c.certFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("failed to read client certificate: %w", err)
+	}
+	keyInfo, err := os.Stat(c.keyFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("failed to read client certificate key: %w", err)
+	}
+	return certInfo.ModTime(), keyInfo.ModTime(), nil
+}
+
+// checkValidity rejects a certificate that is not valid at now
+func checkValidity(cert *tls.Certificate, now time.Time) error {
+	if cert.Leaf == nil {
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			return fmt.Errorf("failed to parse client certificate: %w", err)
+		}
+		cert.Leaf = leaf
+	}
+	if now.Before(cert.Leaf.NotBefore) {
+		return fmt.Errorf("client certificate is not valid until %s", cert.Leaf.NotBefore.Format(time.RFC3339))
+	}
+	if now.After(cert.Leaf.NotAfter) {
+		return fmt.Errorf("client certificate expired on %s", cert.Leaf.NotAfter.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// MutualTLSTransport returns a copy of the base transport that presents cert to
+// servers requesting a client certificate and, when caFile is set, also trusts
+// the CAs in it
+func MutualTLSTransport(cert *ClientCertificate, caFile string) (*http.Transport, error) {
+	transport := BaseTransport().Clone()
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	if cert != nil {
+		transport.TLSClientConfig.GetClientCertificate = cert.GetClientCertificate
+	}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read server CA file: %w", err)
+		}
+		roots := transport.TLSClientConfig.RootCAs
+		if roots == nil {
+			if roots, err = x509.SystemCertPool(); err != nil {
+				roots = x509.NewCertPool()
+			}
+		} else {
+			roots = roots.Clone()
+		}
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in server CA file %s", caFile)
+		}
+		transport.TLSClientConfig.RootCAs = roots
+	}
+	return transport, nil
+}
@@ -0,0 +1,138 @@
+package network
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+)
+
+// testCA issues client certificates
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pool *x509.CertPool
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &testCA{cert: cert, key: key, pool: pool}
+}
+
+// issue returns the PEM certificate and key of a client certificate
+func (ca *testCA) issue(t *testing.T, name string, notAfter time.Time) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestMutualTLSReloadsRotatedCertificate(t *testing.T) {
+	ca := newTestCA(t)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: ca.pool}
+	server.StartTLS()
+	defer server.Close()
+
+	dir := t.TempDir()
+	certFile, keyFile, caFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), filepath.Join(dir, "ca.crt")
+	writePair := func(name string) {
+		certPEM, keyPEM := ca.issue(t, name, time.Now().Add(time.Hour))
+		require.NoError(t, os.WriteFile(certFile, certPEM, 0o600))
+		require.NoError(t, os.WriteFile(keyFile, keyPEM, 0o600))
+	}
+	writePair("slack-bot")
+	serverCA := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caFile, serverCA, 0o600))
+
+	cert, err := LoadClientCertificate(certFile, keyFile, logging.New("test", logging.LevelError))
+	require.NoError(t, err)
+	transport, err := MutualTLSTransport(cert, caFile)
+	require.NoError(t, err)
+	client := &http.Client{Transport: transport}
+	get := func() string {
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		var body [64]byte
+		n, _ := resp.Body.Read(body[:])
+		return string(body[:n])
+	}
+	assert.Equal(t, "slack-bot", get())
+
+	// A rotated certificate is presented on the next connection
+	writePair("slack-bot-rotated")
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, later, later))
+	require.NoError(t, os.Chtimes(keyFile, later, later))
+	transport.CloseIdleConnections()
+	assert.Equal(t, "slack-bot-rotated", get())
+
+	// A half-written rotation keeps the previous certificate
+	require.NoError(t, os.WriteFile(keyFile, []byte("partial"), 0o600))
+	require.NoError(t, os.Chtimes(keyFile, later.Add(time.Minute), later.Add(time.Minute)))
+	transport.CloseIdleConnections()
+	assert.Equal(t, "slack-bot-rotated", get())
+}
+
+func TestClientCertificateValidation(t *testing.T) {
+	ca := newTestCA(t)
+	certPEM, keyPEM := ca.issue(t, "slack-bot", time.Now().Add(time.Hour))
+	_, err := ParseClientCertificate(certPEM, keyPEM)
+	assert.NoError(t, err)
+
+	_, otherKey := ca.issue(t, "other", time.Now().Add(time.Hour))
+	_, err = ParseClientCertificate(certPEM, otherKey)
+	assert.ErrorContains(t, err, "failed to parse client certificate")
+
+	expiredCert, expiredKey := ca.issue(t, "slack-bot", time.Now().Add(-time.Minute))
+	_, err = ParseClientCertificate(expiredCert, expiredKey)
+	assert.ErrorContains(t, err, "client certificate expired")
+
+	_, err = LoadClientCertificate(filepath.Join(t.TempDir(), "missing.crt"), "missing.key", nil)
+	assert.ErrorContains(t, err, "failed to read client certificate")
+}
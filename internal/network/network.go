@@ -0,0 +1,190 @@
+// Package network applies proxy and TLS settings to outbound connections
+package network
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// Options are the proxy and TLS settings for outbound connections
+type Options struct {
+	ProxyURL           string   // http, https or socks5 proxy; empty uses HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+	NoProxy            []string // Hosts, domains and CIDRs reached without ProxyURL
+	CABundle           string   // PEM file of CA certificates trusted in addition to the system roots
+	InsecureSkipVerify []string // Hosts whose TLS certificates are not verified
+}
+
+// Transport sends requests through the configured proxy. Requests to hosts listed
+// in Options.InsecureSkipVerify use a second transport that skips certificate
+// verification.
+type Transport struct {
+	secure        *http.Transport
+	insecure      *http.Transport
+	insecureHosts map[string]bool
+}
+
+var (
+	// defaultTransport is http.DefaultTransport as it was before Configure
+	defaultTransport = http.DefaultTransport.(*http.Transport)
+
+	mu     sync.RWMutex
+	active *Transport
+)
+
+// NewTransport builds a transport from the options, starting from the settings of
+// http.DefaultTransport
+func NewTransport(options Options) (*Transport, error) {
+	proxy, err := proxyFunc(options)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig, err := newTLSConfig(options.CABundle)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Transport{secure: defaultTransport.Clone()}
+	t.secure.Proxy = proxy
+	t.secure.TLSClientConfig = tlsConfig
+	if len(options.InsecureSkipVerify) > 0 {
+		t.insecureHosts = make(map[string]bool, len(options.InsecureSkipVerify))
+		for _, host := range options.InsecureSkipVerify {
+			t.insecureHosts[strings.ToLower(host)] = true
+		}
+		t.insecure = t.secure.Clone()
+		t.insecure.TLSClientConfig.InsecureSkipVerify = true
+	}
+	return t, nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.insecure != nil && t.insecureHosts[strings.ToLower(req.URL.Hostname())] {
+		return t.insecure.RoundTrip(req)
+	}
+	return t.secure.RoundTrip(req)
+}
+
+// CloseIdleConnections closes the idle connections of both transports
+func (t *Transport) CloseIdleConnections() {
+	t.secure.CloseIdleConnections()
+	if t.insecure != nil {
+		t.insecure.CloseIdleConnections()
+	}
+}
+
+// Configure applies the options to every outbound HTTP connection by replacing
+// http.DefaultTransport, which the Slack, LLM, MCP and RAG clients use unless
+// given their own transport. Call it before creating those clients.
+func Configure(options Options) error {
+	t, err := NewTransport(options)
+	if err != nil {
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if active != nil {
+		active.CloseIdleConnections()
+	}
+	active = t
+	http.DefaultTransport = t
+	return nil
+}
+
+// BaseTransport returns the transport for verified connections, for clients that
+// need to adjust an *http.Transport, such as a custom dialer
+func BaseTransport() *http.Transport {
+	mu.RLock()
+	defer mu.RUnlock()
+	if active == nil {
+		return defaultTransport
+	}
+	return active.secure
+}
+
+// Derive returns a copy of the configured transport with its settings adjusted,
+// for example to size a separate connection pool. Hosts in
+// Options.InsecureSkipVerify still skip certificate verification.
+func Derive(adjust func(*http.Transport)) http.RoundTripper {
+	mu.RLock()
+	defer mu.RUnlock()
+	if active == nil {
+		transport := defaultTransport.Clone()
+		adjust(transport)
+		return transport
+	}
+	derived := &Transport{secure: active.secure.Clone(), insecureHosts: active.insecureHosts}
+	adjust(derived.secure)
+	if active.insecure != nil {
+		derived.insecure = active.insecure.Clone()
+		adjust(derived.insecure)
+	}
+	return derived
+}
+
+// WebsocketDialer returns a websocket dialer that uses the configured proxy and
+// trusted CAs
+func WebsocketDialer() *websocket.Dialer {
+	base := BaseTransport()
+	dialer := *websocket.DefaultDialer
+	dialer.Proxy = base.Proxy
+	if base.TLSClientConfig != nil {
+		dialer.TLSClientConfig = base.TLSClientConfig.Clone()
+	}
+	return &dialer
+}
+
+// proxyFunc returns the proxy selection for the options
+func proxyFunc(options Options) (func(*http.Request) (*url.URL, error), error) {
+	if options.ProxyURL == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+	proxyURL, err := url.Parse(options.ProxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme '%s' (use http, https or socks5)", proxyURL.Scheme)
+	}
+	selector := (&httpproxy.Config{
+		HTTPProxy:  options.ProxyURL,
+		HTTPSProxy: options.ProxyURL,
+		NoProxy:    strings.Join(options.NoProxy, ","),
+	}).ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return selector(req.URL)
+	}, nil
+}
+
+// newTLSConfig returns a TLS configuration trusting the system roots and, if set,
+// the certificates in caBundle
+func newTLSConfig(caBundle string) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caBundle == "" {
+		return config, nil
+	}
+	pem, err := os.ReadFile(caBundle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		roots = x509.NewCertPool()
+	}
+	if !roots.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in CA bundle %s", caBundle)
+	}
+	config.RootCAs = roots
+	return config, nil
+}
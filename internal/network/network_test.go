@@ -0,0 +1,131 @@
+package network
+
+import (
+	"encoding/pem"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransportUsesProxy(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+	}))
+	defer proxy.Close()
+
+	transport, err := NewTransport(Options{ProxyURL: proxy.URL, NoProxy: []string{".internal.example.com"}})
+	require.NoError(t, err)
+	client := &http.Client{Transport: transport}
+	resp, err := client.Get("http://api.example.com/v1/models")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, []string{"http://api.example.com/v1/models"}, proxied)
+
+	// Hosts in noProxy are reached directly
+	req, err := http.NewRequest(http.MethodGet, "http://mcp.internal.example.com/sse", nil)
+	require.NoError(t, err)
+	proxyURL, err := transport.secure.Proxy(req)
+	require.NoError(t, err)
+	assert.Nil(t, proxyURL)
+
+	_, err = NewTransport(Options{ProxyURL: "ftp://proxy.example.com"})
+	assert.ErrorContains(t, err, "unsupported proxy scheme")
+}
+
+func TestTransportTrust(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	get := func(options Options) error {
+		transport, err := NewTransport(options)
+		require.NoError(t, err)
+		resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+		if err == nil {
+			err = resp.Body.Close()
+		}
+		return err
+	}
+
+	assert.Error(t, get(Options{}), "the test server's certificate is not trusted by default")
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(bundle, certPEM, 0o600))
+	assert.NoError(t, get(Options{CABundle: bundle}))
+
+	assert.NoError(t, get(Options{InsecureSkipVerify: []string{"127.0.0.1"}}))
+	assert.Error(t, get(Options{InsecureSkipVerify: []string{"example.com"}}))
+
+	require.NoError(t, os.WriteFile(bundle, []byte("not a certificate"), 0o600))
+	_, err := NewTransport(Options{CABundle: bundle})
+	assert.ErrorContains(t, err, "no certificates found")
+}
+
+func TestDeriveKeepsInsecureHosts(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	configured, err := NewTransport(Options{InsecureSkipVerify: []string{"127.0.0.1"}})
+	require.NoError(t, err)
+	mu.Lock()
+	active = configured
+	mu.Unlock()
+	defer func() {
+		mu.Lock()
+		active = nil
+		mu.Unlock()
+	}()
+
+	derived := Derive(func(transport *http.Transport) { transport.MaxIdleConnsPerHost = 7 })
+	resp, err := (&http.Client{Transport: derived}).Get(server.URL)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, 7, derived.(*Transport).secure.MaxIdleConnsPerHost)
+	assert.Zero(t, configured.secure.MaxIdleConnsPerHost, "the configured transport is unchanged")
+}
+
+func TestIsPublicIP(t *testing.T) {
+	for _, ip := range []string{"127.0.0.1", "10.1.2.3", "192.168.0.1", "169.254.169.254", "::1", "fd00::1", "0.0.0.0"} {
+		assert.False(t, IsPublicIP(net.ParseIP(ip)), ip)
+	}
+	assert.True(t, IsPublicIP(net.ParseIP("93.184.216.34")))
+}
+
+func TestRestrictToPublicThroughProxy(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+	}))
+	defer proxy.Close()
+
+	proxiedTransport, err := NewTransport(Options{ProxyURL: proxy.URL})
+	require.NoError(t, err)
+	transport := proxiedTransport.secure.Clone()
+	RestrictToPublic(transport, &net.Dialer{})
+	client := &http.Client{Transport: transport}
+
+	// The proxy is on a private address, but public targets reach it
+	resp, err := client.Get("http://93.184.216.34/status")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, []string{"http://93.184.216.34/status"}, proxied)
+
+	// Private targets are refused before they are handed to the proxy
+	for _, target := range []string{"http://169.254.169.254/latest/meta-data", "http://localhost/admin", "http://10.0.0.1/"} {
+		_, err = client.Get(target)
+		assert.ErrorContains(t, err, "is not public", target)
+	}
+	assert.Len(t, proxied, 1)
+
+	// Without a proxy, the dialed address is checked
+	direct := &http.Transport{}
+	RestrictToPublic(direct, &net.Dialer{})
+	_, err = (&http.Client{Transport: direct}).Get(proxy.URL)
+	assert.ErrorContains(t, err, "is not public")
+}
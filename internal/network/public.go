@@ -0,0 +1,97 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"syscall"
+)
+
+// IsPublicIP reports whether an address is reachable on the public internet
+func IsPublicIP(ip net.IP) bool {
+	return !(ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsUnspecified() || ip.IsMulticast() || ip.IsInterfaceLocalMulticast())
+}
+
+// PublicOnlyControl is a net.Dialer Control that refuses private, loopback and
+// link-local addresses. The address is checked after DNS resolution, so a public
+// name that resolves to a private address is refused too.
+func PublicOnlyControl(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !IsPublicIP(ip) {
+		return fmt.Errorf("address %s is not public", host)
+	}
+	return nil
+}
+
+// RestrictToPublic makes a transport refuse private, loopback and link-local
+// addresses, dialing with dialer. Direct connections are checked after DNS
+// resolution. Through a proxy only the proxy is dialed, so the target host is
+// resolved and checked before the request is handed to the proxy instead. The
+// proxy itself was configured by the operator and may be on a private address.
+func RestrictToPublic(transport *http.Transport, dialer *net.Dialer) {
+	public := *dialer
+	public.Control = PublicOnlyControl
+	var proxies sync.Map // Addresses of the proxies requests were handed to
+	if proxy := transport.Proxy; proxy != nil {
+		transport.Proxy = func(req *http.Request) (*url.URL, error) {
+			proxyURL, err := proxy(req)
+			if err != nil || proxyURL == nil {
+				return proxyURL, err
+			}
+			if err := checkPublicHost(req.Context(), req.URL.Hostname()); err != nil {
+				return nil, err
+			}
+			proxies.Store(proxyAddress(proxyURL), true)
+			return proxyURL, nil
+		}
+	}
+	transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		if _, ok := proxies.Load(address); ok {
+			return dialer.DialContext(ctx, network, address)
+		}
+		return public.DialContext(ctx, network, address)
+	}
+}
+
+// checkPublicHost refuses a host that is, or resolves to, an address that is not public
+func checkPublicHost(ctx context.Context, host string) error {
+	if ip := net.ParseIP(host); ip != nil {
+		if !IsPublicIP(ip) {
+			return fmt.Errorf("address %s is not public", host)
+		}
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if !IsPublicIP(addr.IP) {
+			return fmt.Errorf("address %s of %s is not public", addr.IP, host)
+		}
+	}
+	return nil
+}
+
+// proxyAddress returns the host and port a proxy is dialed at
+func proxyAddress(proxyURL *url.URL) string {
+	port := proxyURL.Port()
+	if port == "" {
+		switch proxyURL.Scheme {
+		case "https":
+			port = "443"
+		case "socks5", "socks5h":
+			port = "1080"
+		default:
+			port = "80"
+		}
+	}
+	return net.JoinHostPort(proxyURL.Hostname(), port)
+}
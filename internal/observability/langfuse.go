@@ -81,13 +81,12 @@ func (p *LangfuseProvider) setupOpenTelemetry() func() {
 		return func() {}
 	}
 	// Create tracer provider
-	p.tracerProvider = trace.NewTracerProvider(
-		trace.WithBatcher(exporter),
+	p.tracerProvider = trace.NewTracerProvider(append(samplingOptions(p.config.Sampling, exporter),
 		trace.WithResource(resource.NewWithAttributes("",
 			attribute.String("service.name", p.getServiceName()),
 			attribute.String("service.version", p.getServiceVersion()),
 		)),
-	)
+	)...)
 
 	otel.SetTracerProvider(p.tracerProvider)
 	p.logger.InfoKV("Langfuse OpenTelemetry initialized", "endpoint", endpoint)
@@ -107,7 +106,7 @@ func (p *LangfuseProvider) StartTrace(ctx context.Context, name string, input st
 	if p.tracer == nil {
 		return ctx, OtelTrace.SpanFromContext(ctx)
 	}
-	spanCtx, span := p.tracer.Start(ctx, name)
+	spanCtx, span := p.tracer.Start(ctx, name, rootSpanOptions(metadata)...)
 
 	// Apply Langfuse trace-level attributes
 	span.SetAttributes(
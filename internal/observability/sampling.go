@@ -0,0 +1,160 @@
+package observability
+
+import (
+	"sync"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ChannelAttribute is the root span attribute the sampler reads the channel from
+const ChannelAttribute = "channel_id"
+
+// Limits of the spans held back until an unsampled trace ends
+const (
+	maxPendingTraces = 1000
+	maxPendingSpans  = 500
+)
+
+// samplingOptions returns the tracer provider options that sample traces as
+// configured and export them through exporter
+func samplingOptions(cfg config.ObservabilitySamplingConfig, exporter sdktrace.SpanExporter) []sdktrace.TracerProviderOption {
+	keepErrors := cfg.AlwaysSampleErrors == nil || *cfg.AlwaysSampleErrors
+	var processor sdktrace.SpanProcessor = sdktrace.NewBatchSpanProcessor(exporter)
+	if keepErrors {
+		processor = newErrorTraceProcessor(processor)
+	}
+	return []sdktrace.TracerProviderOption{
+		sdktrace.WithSampler(channelSampler{cfg: cfg, keepErrors: keepErrors}),
+		sdktrace.WithSpanProcessor(processor),
+	}
+}
+
+// rootSpanOptions passes the attributes the sampler decides on to the root span
+func rootSpanOptions(metadata map[string]string) []trace.SpanStartOption {
+	if channelID := metadata[ChannelAttribute]; channelID != "" {
+		return []trace.SpanStartOption{trace.WithAttributes(attribute.String(ChannelAttribute, channelID))}
+	}
+	return nil
+}
+
+// channelSampler samples a share of traces by trace ID, with a share per channel.
+// Spans follow the decision of their trace. Unsampled traces are still recorded
+// when failed traces are kept, so the errorTraceProcessor can export them.
+type channelSampler struct {
+	cfg        config.ObservabilitySamplingConfig
+	keepErrors bool
+}
+
+func (s channelSampler) ShouldSample(params sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	parent := trace.SpanContextFromContext(params.ParentContext)
+	sampled := parent.IsSampled()
+	if !parent.IsValid() {
+		sampled = sdktrace.TraceIDRatioBased(s.percent(params.Attributes)/100).ShouldSample(params).Decision == sdktrace.RecordAndSample
+	}
+	decision := sdktrace.Drop
+	switch {
+	case sampled:
+		decision = sdktrace.RecordAndSample
+	case s.keepErrors:
+		decision = sdktrace.RecordOnly
+	}
+	return sdktrace.SamplingResult{Decision: decision, Tracestate: parent.TraceState()}
+}
+
+// percent returns the share of traces sampled in the channel of the root span
+func (s channelSampler) percent(attributes []attribute.KeyValue) float64 {
+	for _, attr := range attributes {
+		if attr.Key == ChannelAttribute {
+			if percent, ok := s.cfg.Channels[attr.Value.AsString()]; ok {
+				return percent
+			}
+		}
+	}
+	if s.cfg.Percent == nil {
+		return 100
+	}
+	return *s.cfg.Percent
+}
+
+func (s channelSampler) Description() string {
+	return "ChannelSampler"
+}
+
+// pendingTrace is an unsampled trace whose spans are held back until it ends
+type pendingTrace struct {
+	spans  []sdktrace.ReadOnlySpan
+	failed bool
+}
+
+// errorTraceProcessor passes sampled spans on, and holds back the spans of
+// unsampled traces until their root span ends. A trace with an error is then
+// exported as if it had been sampled; the others are dropped.
+type errorTraceProcessor struct {
+	sdktrace.SpanProcessor
+
+	mu      sync.Mutex
+	pending map[trace.TraceID]*pendingTrace
+	order   []trace.TraceID // Pending traces, oldest first
+}
+
+func newErrorTraceProcessor(next sdktrace.SpanProcessor) *errorTraceProcessor {
+	return &errorTraceProcessor{SpanProcessor: next, pending: make(map[trace.TraceID]*pendingTrace)}
+}
+
+func (p *errorTraceProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	if s.SpanContext().IsSampled() {
+		p.SpanProcessor.OnEnd(s)
+		return
+	}
+
+	traceID := s.SpanContext().TraceID()
+	p.mu.Lock()
+	pending, ok := p.pending[traceID]
+	if !ok {
+		if len(p.order) >= maxPendingTraces {
+			// Drop the oldest trace, which most likely never ends
+			delete(p.pending, p.order[0])
+			p.order = p.order[1:]
+		}
+		pending = &pendingTrace{}
+		p.pending[traceID] = pending
+		p.order = append(p.order, traceID)
+	}
+	if len(pending.spans) < maxPendingSpans {
+		pending.spans = append(pending.spans, s)
+	}
+	pending.failed = pending.failed || s.Status().Code == codes.Error
+	if s.Parent().IsValid() {
+		p.mu.Unlock()
+		return
+	}
+	delete(p.pending, traceID)
+	for i, id := range p.order {
+		if id == traceID {
+			p.order = append(p.order[:i], p.order[i+1:]...)
+			break
+		}
+	}
+	p.mu.Unlock()
+
+	if pending.failed {
+		for _, span := range pending.spans {
+			p.SpanProcessor.OnEnd(sampledSpan{span})
+		}
+	}
+}
+
+// sampledSpan is a span of an unsampled trace marked as sampled, which span
+// processors otherwise drop
+type sampledSpan struct {
+	sdktrace.ReadOnlySpan
+}
+
+func (s sampledSpan) SpanContext() trace.SpanContext {
+	sc := s.ReadOnlySpan.SpanContext()
+	return sc.WithTraceFlags(sc.TraceFlags().WithSampled(true))
+}
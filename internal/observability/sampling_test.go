@@ -0,0 +1,78 @@
+package observability
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+// sampledTracer returns a tracer sampling as configured and the spans it exported
+func sampledTracer(t *testing.T, cfg config.ObservabilitySamplingConfig) (trace.Tracer, func() []string) {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(samplingOptions(cfg, exporter)...)
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+	return provider.Tracer(TracerName), func() []string {
+		require.NoError(t, provider.ForceFlush(context.Background()))
+		var names []string
+		for _, span := range exporter.GetSpans() {
+			names = append(names, span.Name)
+		}
+		return names
+	}
+}
+
+// runTrace records a trace with one child span, failing the child when failed is set
+func runTrace(tracer trace.Tracer, name, channelID string, failed bool) {
+	ctx, root := tracer.Start(context.Background(), name, rootSpanOptions(map[string]string{ChannelAttribute: channelID})...)
+	_, child := tracer.Start(ctx, name+"-child")
+	if failed {
+		child.RecordError(errors.New("boom"))
+		child.SetStatus(codes.Error, "boom")
+	}
+	child.End()
+	root.End()
+}
+
+func TestSamplingKeepsFailedTraces(t *testing.T) {
+	none := 0.0
+	tracer, exported := sampledTracer(t, config.ObservabilitySamplingConfig{
+		Percent:  &none,
+		Channels: map[string]float64{"C-OPS": 100},
+	})
+
+	runTrace(tracer, "dropped", "C1", false)
+	runTrace(tracer, "failed", "C1", true)
+	runTrace(tracer, "ops", "C-OPS", false)
+	assert.ElementsMatch(t, []string{"failed-child", "failed", "ops-child", "ops"}, exported())
+}
+
+func TestSamplingWithoutErrorTraces(t *testing.T) {
+	none, keepErrors := 0.0, false
+	tracer, exported := sampledTracer(t, config.ObservabilitySamplingConfig{Percent: &none, AlwaysSampleErrors: &keepErrors})
+
+	runTrace(tracer, "failed", "C1", true)
+	assert.Empty(t, exported())
+}
+
+func TestSamplingPercent(t *testing.T) {
+	half := 50.0
+	tracer, exported := sampledTracer(t, config.ObservabilitySamplingConfig{Percent: &half})
+
+	for i := 0; i < 400; i++ {
+		runTrace(tracer, "trace", "C1", false)
+	}
+	// Children follow their root, so spans are exported in pairs
+	spans := len(exported())
+	assert.Zero(t, spans%2)
+	assert.InDelta(t, 400, spans, 100)
+}
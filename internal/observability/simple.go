@@ -70,13 +70,12 @@ func (p *SimpleProvider) setupOpenTelemetry() func() {
 		return func() {}
 	}
 
-	p.tracerProvider = sdktrace.NewTracerProvider(
-		sdktrace.WithBatcher(exporter),
+	p.tracerProvider = sdktrace.NewTracerProvider(append(samplingOptions(p.config.Sampling, exporter),
 		sdktrace.WithResource(resource.NewWithAttributes("",
 			attribute.String("service.name", p.getServiceName()),
 			attribute.String("service.version", p.getServiceVersion()),
 		)),
-	)
+	)...)
 
 	otel.SetTracerProvider(p.tracerProvider)
 	p.logger.InfoKV("Simple OpenTelemetry initialized", "endpoint", endpoint)
@@ -96,7 +95,7 @@ func (p *SimpleProvider) StartTrace(ctx context.Context, name string, input stri
 	if p.tracer == nil {
 		return ctx, trace.SpanFromContext(ctx)
 	}
-	spanCtx, span := p.tracer.Start(ctx, name)
+	spanCtx, span := p.tracer.Start(ctx, name, rootSpanOptions(metadata)...)
 
 	// Apply basic attributes
 	span.SetAttributes(
@@ -0,0 +1,173 @@
+// Package oncall answers "who is on call" from PagerDuty or Opsgenie schedules
+// through a native tool, without an MCP server for the paging provider.
+package oncall
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+)
+
+const (
+	// ServerName identifies the on-call client among the bridge's clients
+	ServerName = "oncall"
+	// ToolName is the name of the on-call lookup tool
+	ToolName = "who_is_on_call"
+
+	requestTimeout = 15 * time.Second
+)
+
+// Shift is a person on call in a schedule
+type Shift struct {
+	Name  string
+	Email string
+	Level int       // Escalation level, 0 when the provider does not report one
+	Until time.Time // End of the shift, zero when the provider does not report one
+}
+
+// lookupFunc returns who is on call now in a schedule
+type lookupFunc func(ctx context.Context, schedule string) ([]Shift, error)
+
+// cachedAnswer is a recent answer for a schedule
+type cachedAnswer struct {
+	text    string
+	expires time.Time
+}
+
+// Client looks up who is on call in the configured schedules
+type Client struct {
+	cfg    config.OnCallConfig
+	lookup lookupFunc
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cachedAnswer // By schedule name
+}
+
+// NewClient creates a client for the configured provider
+func NewClient(cfg config.OnCallConfig) (*Client, error) {
+	ttl, err := time.ParseDuration(cfg.CacheTTL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid onCall cacheTtl '%s': %w", cfg.CacheTTL, err)
+	}
+	c := &Client{cfg: cfg, ttl: ttl, now: time.Now, cache: make(map[string]cachedAnswer)}
+	httpClient := &http.Client{Timeout: requestTimeout}
+	baseURL := strings.TrimRight(cfg.URL, "/")
+	switch cfg.Provider {
+	case config.OnCallProviderPagerDuty:
+		c.lookup = pagerDutyLookup(httpClient, baseURL, cfg.APIKey)
+	case config.OnCallProviderOpsgenie:
+		c.lookup = opsgenieLookup(httpClient, baseURL, cfg.APIKey)
+	default:
+		return nil, fmt.Errorf("unknown onCall provider '%s'", cfg.Provider)
+	}
+	return c, nil
+}
+
+// ToolInfo describes the on-call tool to the LLM, listing the schedules it can ask about
+func ToolInfo(cfg config.OnCallConfig) mcp.ToolInfo {
+	names := scheduleNames(cfg)
+	schedule := map[string]interface{}{
+		"type":        "string",
+		"description": "The team or schedule to look up",
+		"enum":        names,
+	}
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"schedule": schedule},
+	}
+	if len(names) > 1 {
+		schema["required"] = []string{"schedule"}
+	}
+	return mcp.ToolInfo{
+		ToolName:        ToolName,
+		ToolDescription: fmt.Sprintf("Look up who is on call right now for a team (%s). Use it whenever the user asks who is on call, on duty or to be paged.", strings.Join(names, ", ")),
+		InputSchema:     schema,
+		ServerName:      ServerName,
+	}
+}
+
+// CallTool implements the bridge's client interface
+func (c *Client) CallTool(ctx context.Context, toolName string, args map[string]interface{}) (string, error) {
+	if toolName != ToolName {
+		return "", fmt.Errorf("unknown on-call tool: %s. Available tools: %s", toolName, ToolName)
+	}
+	name, _ := args["schedule"].(string)
+	names := scheduleNames(c.cfg)
+	if name == "" && len(names) == 1 {
+		name = names[0]
+	}
+	schedule, ok := c.findSchedule(name)
+	if !ok {
+		return "", fmt.Errorf("unknown schedule '%s'. Available schedules: %s", name, strings.Join(names, ", "))
+	}
+
+	c.mu.Lock()
+	cached, exists := c.cache[schedule]
+	c.mu.Unlock()
+	if exists && c.now().Before(cached.expires) {
+		return cached.text, nil
+	}
+	shifts, err := c.lookup(ctx, c.cfg.Schedules[schedule])
+	if err != nil {
+		return "", fmt.Errorf("failed to look up the %s schedule: %w", schedule, err)
+	}
+	text := formatShifts(schedule, shifts)
+	c.mu.Lock()
+	c.cache[schedule] = cachedAnswer{text: text, expires: c.now().Add(c.ttl)}
+	c.mu.Unlock()
+	return text, nil
+}
+
+// findSchedule returns the configured name of a schedule, ignoring case
+func (c *Client) findSchedule(name string) (string, bool) {
+	for configured := range c.cfg.Schedules {
+		if strings.EqualFold(configured, strings.TrimSpace(name)) {
+			return configured, true
+		}
+	}
+	return "", false
+}
+
+// scheduleNames returns the configured schedule names in order
+func scheduleNames(cfg config.OnCallConfig) []string {
+	names := make([]string, 0, len(cfg.Schedules))
+	for name := range cfg.Schedules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// formatShifts describes who is on call, by escalation level
+func formatShifts(schedule string, shifts []Shift) string {
+	if len(shifts) == 0 {
+		return fmt.Sprintf("Nobody is on call for %s right now.", schedule)
+	}
+	sort.SliceStable(shifts, func(i, j int) bool { return shifts[i].Level < shifts[j].Level })
+	var answer strings.Builder
+	answer.WriteString(fmt.Sprintf("On call for %s:\n", schedule))
+	for _, shift := range shifts {
+		answer.WriteString("- ")
+		if shift.Level > 0 {
+			answer.WriteString(fmt.Sprintf("Level %d: ", shift.Level))
+		}
+		answer.WriteString(shift.Name)
+		if shift.Email != "" && shift.Email != shift.Name {
+			answer.WriteString(fmt.Sprintf(" (%s)", shift.Email))
+		}
+		if !shift.Until.IsZero() {
+			answer.WriteString(fmt.Sprintf(", until %s", shift.Until.UTC().Format("Mon Jan 2 15:04 UTC")))
+		}
+		answer.WriteString("\n")
+	}
+	return answer.String()
+}
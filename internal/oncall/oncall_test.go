@@ -0,0 +1,98 @@
+package oncall
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+func newTestClient(t *testing.T, provider, url string, schedules map[string]string) *Client {
+	t.Helper()
+	cfg := &config.Config{OnCall: config.OnCallConfig{Enabled: true, Provider: provider, APIKey: "key", URL: url, Schedules: schedules}}
+	cfg.ApplyDefaults()
+	client, err := NewClient(cfg.OnCall)
+	require.NoError(t, err)
+	return client
+}
+
+func TestPagerDutyOnCall(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "/oncalls", r.URL.Path)
+		assert.Equal(t, "PSCHED1", r.URL.Query().Get("schedule_ids[]"))
+		assert.Equal(t, "Token token=key", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"oncalls": [
+			{"escalation_level": 2, "end": null, "user": {"summary": "Grace Hopper", "name": "Grace Hopper", "email": "grace@example.com"}},
+			{"escalation_level": 1, "end": "2024-05-02T09:00:00Z", "user": {"summary": "Ada Lovelace", "name": "Ada Lovelace", "email": "ada@example.com"}}
+		]}`))
+	}))
+	defer server.Close()
+	client := newTestClient(t, config.OnCallProviderPagerDuty, server.URL, map[string]string{"platform": "PSCHED1", "database": "PSCHED2"})
+
+	answer, err := client.CallTool(context.Background(), ToolName, map[string]interface{}{"schedule": "Platform"})
+	require.NoError(t, err)
+	assert.Equal(t, "On call for platform:\n"+
+		"- Level 1: Ada Lovelace (ada@example.com), until Thu May 2 09:00 UTC\n"+
+		"- Level 2: Grace Hopper (grace@example.com)\n", answer)
+
+	// Answers are reused for the cache TTL
+	_, err = client.CallTool(context.Background(), ToolName, map[string]interface{}{"schedule": "platform"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, requests)
+	now := time.Now().Add(2 * time.Minute)
+	client.now = func() time.Time { return now }
+	_, err = client.CallTool(context.Background(), ToolName, map[string]interface{}{"schedule": "platform"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, requests)
+
+	_, err = client.CallTool(context.Background(), ToolName, map[string]interface{}{"schedule": "payments"})
+	assert.ErrorContains(t, err, "Available schedules: database, platform")
+	_, err = client.CallTool(context.Background(), ToolName, map[string]interface{}{})
+	assert.Error(t, err, "the schedule is required when there are several")
+}
+
+func TestOpsgenieOnCall(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v2/schedules/SRE Primary/on-calls", r.URL.Path)
+		assert.Equal(t, "name", r.URL.Query().Get("scheduleIdentifierType"))
+		assert.Equal(t, "GenieKey key", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"data": {"onCallRecipients": ["ada@example.com"]}}`))
+	}))
+	defer server.Close()
+	client := newTestClient(t, config.OnCallProviderOpsgenie, server.URL, map[string]string{"sre": "SRE Primary"})
+
+	// With a single schedule, it need not be named
+	answer, err := client.CallTool(context.Background(), ToolName, map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, "On call for sre:\n- ada@example.com\n", answer)
+}
+
+func TestOnCallErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error": "Unauthorized"}`, http.StatusUnauthorized)
+	}))
+	defer server.Close()
+	client := newTestClient(t, config.OnCallProviderPagerDuty, server.URL, map[string]string{"platform": "P1"})
+
+	_, err := client.CallTool(context.Background(), ToolName, map[string]interface{}{"schedule": "platform"})
+	assert.ErrorContains(t, err, "status 401")
+	_, err = client.CallTool(context.Background(), "page_someone", nil)
+	assert.Error(t, err)
+}
+
+func TestToolInfo(t *testing.T) {
+	info := ToolInfo(config.OnCallConfig{Schedules: map[string]string{"b": "2", "a": "1"}})
+	assert.Equal(t, ToolName, info.ToolName)
+	assert.Equal(t, ServerName, info.ServerName)
+	assert.Equal(t, []string{"schedule"}, info.InputSchema["required"])
+	schedule := info.InputSchema["properties"].(map[string]interface{})["schedule"].(map[string]interface{})
+	assert.Equal(t, []string{"a", "b"}, schedule["enum"])
+}
@@ -0,0 +1,96 @@
+package oncall
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// getJSON sends an authenticated GET request and decodes the JSON response
+func getJSON(ctx context.Context, httpClient *http.Client, requestURL string, headers map[string]string, response interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return err
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(snippet))
+	}
+	return json.NewDecoder(resp.Body).Decode(response)
+}
+
+// pagerDutyLookup looks up the current on-call entries of a PagerDuty schedule,
+// one per escalation level
+func pagerDutyLookup(httpClient *http.Client, baseURL, apiKey string) lookupFunc {
+	return func(ctx context.Context, scheduleID string) ([]Shift, error) {
+		query := url.Values{}
+		query.Set("schedule_ids[]", scheduleID)
+		query.Set("include[]", "users")
+		query.Set("earliest", "true")
+		var parsed struct {
+			OnCalls []struct {
+				EscalationLevel int    `json:"escalation_level"`
+				End             string `json:"end"`
+				User            struct {
+					Summary string `json:"summary"`
+					Name    string `json:"name"`
+					Email   string `json:"email"`
+				} `json:"user"`
+			} `json:"oncalls"`
+		}
+		err := getJSON(ctx, httpClient, baseURL+"/oncalls?"+query.Encode(), map[string]string{
+			"Authorization": "Token token=" + apiKey,
+			"Accept":        "application/vnd.pagerduty+json;version=2",
+		}, &parsed)
+		if err != nil {
+			return nil, err
+		}
+		shifts := make([]Shift, 0, len(parsed.OnCalls))
+		for _, onCall := range parsed.OnCalls {
+			shift := Shift{Name: onCall.User.Name, Email: onCall.User.Email, Level: onCall.EscalationLevel}
+			if shift.Name == "" {
+				shift.Name = onCall.User.Summary
+			}
+			shift.Until, _ = time.Parse(time.RFC3339, onCall.End) // Permanent shifts have no end
+			shifts = append(shifts, shift)
+		}
+		return shifts, nil
+	}
+}
+
+// opsgenieLookup looks up the current on-call participants of an Opsgenie schedule
+func opsgenieLookup(httpClient *http.Client, baseURL, apiKey string) lookupFunc {
+	return func(ctx context.Context, schedule string) ([]Shift, error) {
+		query := url.Values{}
+		query.Set("scheduleIdentifierType", "name")
+		query.Set("flat", "true")
+		var parsed struct {
+			Data struct {
+				OnCallRecipients []string `json:"onCallRecipients"`
+			} `json:"data"`
+		}
+		err := getJSON(ctx, httpClient, fmt.Sprintf("%s/v2/schedules/%s/on-calls?%s", baseURL, url.PathEscape(schedule), query.Encode()), map[string]string{
+			"Authorization": "GenieKey " + apiKey,
+		}, &parsed)
+		if err != nil {
+			return nil, err
+		}
+		shifts := make([]Shift, 0, len(parsed.Data.OnCallRecipients))
+		for _, recipient := range parsed.Data.OnCallRecipients {
+			shifts = append(shifts, Shift{Name: recipient, Email: recipient})
+		}
+		return shifts, nil
+	}
+}
@@ -0,0 +1,305 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tmc/langchaingo/embeddings"
+	"github.com/tmc/langchaingo/schema"
+	"github.com/tmc/langchaingo/textsplitter"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/tokens"
+	"github.com/tuannvm/slack-mcp-client/internal/llm"
+)
+
+// Chunking strategies
+const (
+	ChunkStrategyRecursive = "recursive" // Split on paragraphs, lines, then words
+	ChunkStrategySentence  = "sentence"  // Pack whole sentences into chunks
+	ChunkStrategyMarkdown  = "markdown"  // Split on Markdown headings, keeping the heading path
+	ChunkStrategySemantic  = "semantic"  // Split where the topic changes, using sentence embeddings
+)
+
+// Units in which chunk sizes are measured
+const (
+	ChunkUnitCharacters = "characters"
+	ChunkUnitTokens     = "tokens"
+)
+
+// Default chunk sizes per unit
+const (
+	DefaultChunkSizeCharacters    = 1000
+	DefaultChunkOverlapCharacters = 200
+	DefaultChunkSizeTokens        = 256
+	DefaultChunkOverlapTokens     = 50
+
+	// DefaultBreakpointPercentile starts a semantic chunk at the largest 10% of
+	// topic shifts between sentences
+	DefaultBreakpointPercentile = 90
+)
+
+// ChunkingOptions controls how documents are split into chunks before they are stored
+type ChunkingOptions struct {
+	Strategy string // One of the ChunkStrategy constants (default: recursive)
+	Size     int    // Maximum chunk size in Unit
+	Overlap  int    // Size of the text repeated between consecutive chunks, in Unit
+	Unit     string // ChunkUnitCharacters or ChunkUnitTokens (default: characters)
+
+	// BreakpointPercentile is the percentile of sentence-to-sentence distances above
+	// which a semantic chunk ends (default: 90)
+	BreakpointPercentile float64
+	// Embedder embeds sentences for the semantic strategy
+	Embedder embeddings.Embedder
+}
+
+// ChunkingConfigurer is implemented by providers whose chunking can be configured
+type ChunkingConfigurer interface {
+	SetChunking(options ChunkingOptions) error
+}
+
+// DefaultChunkingOptions returns the splitter used when nothing is configured
+func DefaultChunkingOptions() ChunkingOptions {
+	return ChunkingOptions{
+		Strategy: ChunkStrategyRecursive,
+		Size:     DefaultChunkSizeCharacters,
+		Overlap:  DefaultChunkOverlapCharacters,
+		Unit:     ChunkUnitCharacters,
+	}
+}
+
+// WithDefaults fills unset options with the defaults for the unit
+func (o ChunkingOptions) WithDefaults() ChunkingOptions {
+	if o.Strategy == "" {
+		o.Strategy = ChunkStrategyRecursive
+	}
+	if o.Unit == "" {
+		o.Unit = ChunkUnitCharacters
+	}
+	if o.Size <= 0 {
+		o.Size = DefaultChunkSizeCharacters
+		o.Overlap = DefaultChunkOverlapCharacters
+		if o.Unit == ChunkUnitTokens {
+			o.Size = DefaultChunkSizeTokens
+			o.Overlap = DefaultChunkOverlapTokens
+		}
+	} else if o.Overlap == 0 {
+		o.Overlap = o.Size / 5
+	}
+	if o.BreakpointPercentile <= 0 {
+		o.BreakpointPercentile = DefaultBreakpointPercentile
+	}
+	return o
+}
+
+// Validate checks the options after defaults are applied
+func (o ChunkingOptions) Validate() error {
+	switch o.Strategy {
+	case ChunkStrategyRecursive, ChunkStrategySentence, ChunkStrategyMarkdown:
+	case ChunkStrategySemantic:
+		if o.Embedder == nil {
+			return fmt.Errorf("the semantic chunking strategy requires an embedder")
+		}
+	default:
+		return fmt.Errorf("unknown chunking strategy '%s' (use recursive, sentence, markdown or semantic)", o.Strategy)
+	}
+	switch o.Unit {
+	case ChunkUnitCharacters, ChunkUnitTokens:
+	default:
+		return fmt.Errorf("unknown chunk size unit '%s' (use characters or tokens)", o.Unit)
+	}
+	if o.Overlap < 0 || o.Overlap >= o.Size {
+		return fmt.Errorf("chunk overlap must be at least 0 and smaller than the chunk size")
+	}
+	if o.BreakpointPercentile > 100 {
+		return fmt.Errorf("breakpoint percentile must be between 0 and 100")
+	}
+	return nil
+}
+
+// Split splits loaded documents into chunks, keeping each document's metadata and
+// adding the chunk index
+func (o ChunkingOptions) Split(ctx context.Context, docs []schema.Document) ([]schema.Document, error) {
+	var allChunks []schema.Document
+	for _, doc := range docs {
+		chunks, err := o.splitText(ctx, doc.PageContent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split document: %w", err)
+		}
+
+		index := 0
+		for _, chunk := range chunks {
+			if strings.TrimSpace(chunk) == "" {
+				continue
+			}
+			metadata := make(map[string]any, len(doc.Metadata)+1)
+			for key, value := range doc.Metadata {
+				metadata[key] = value
+			}
+			metadata["chunk_index"] = index
+			index++
+			allChunks = append(allChunks, schema.Document{PageContent: chunk, Metadata: metadata})
+		}
+	}
+	return allChunks, nil
+}
+
+// splitText splits a single text with the configured strategy
+func (o ChunkingOptions) splitText(ctx context.Context, text string) ([]string, error) {
+	length := o.lengthFunc()
+	switch o.Strategy {
+	case ChunkStrategySentence:
+		return packSentences(splitSentences(text), o.Size, o.Overlap, length), nil
+	case ChunkStrategyMarkdown:
+		return textsplitter.NewMarkdownTextSplitter(
+			textsplitter.WithChunkSize(o.Size),
+			textsplitter.WithChunkOverlap(o.Overlap),
+			textsplitter.WithHeadingHierarchy(true),
+			textsplitter.WithCodeBlocks(true),
+			textsplitter.WithLenFunc(length),
+		).SplitText(text)
+	case ChunkStrategySemantic:
+		return o.splitSemantic(ctx, text, length)
+	default:
+		return textsplitter.NewRecursiveCharacter(
+			textsplitter.WithChunkSize(o.Size),
+			textsplitter.WithChunkOverlap(o.Overlap),
+			textsplitter.WithLenFunc(length),
+		).SplitText(text)
+	}
+}
+
+// splitSemantic groups consecutive sentences and starts a new chunk where the
+// embedding distance between neighbouring sentences is in the top percentile, or
+// when the chunk would exceed the size limit
+func (o ChunkingOptions) splitSemantic(ctx context.Context, text string, length func(string) int) ([]string, error) {
+	sentences := splitSentences(text)
+	if len(sentences) < 2 {
+		return sentences, nil
+	}
+	vectors, err := o.Embedder.EmbedDocuments(ctx, sentences)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed sentences: %w", err)
+	}
+	if len(vectors) != len(sentences) {
+		return nil, fmt.Errorf("embedder returned %d vectors for %d sentences", len(vectors), len(sentences))
+	}
+
+	distances := make([]float64, len(sentences)-1)
+	for i := range distances {
+		distances[i] = 1 - llm.CosineSimilarity(vectors[i], vectors[i+1])
+	}
+	threshold := percentile(distances, o.BreakpointPercentile)
+
+	var chunks []string
+	var current []string
+	for i, sentence := range sentences {
+		if len(current) > 0 && (distances[i-1] > threshold || length(strings.Join(append(current, sentence), " ")) > o.Size) {
+			chunks = append(chunks, packSentences(current, o.Size, 0, length)...)
+			current = nil
+		}
+		current = append(current, sentence)
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, packSentences(current, o.Size, 0, length)...)
+	}
+	return chunks, nil
+}
+
+// lengthFunc returns the function measuring text in the configured unit
+func (o ChunkingOptions) lengthFunc() func(string) int {
+	if o.Unit == ChunkUnitTokens {
+		return tokens.Count
+	}
+	return utf8.RuneCountInString
+}
+
+// sentenceEnd matches the end of a sentence or paragraph
+var sentenceEnd = regexp.MustCompile(`([.!?]["')\]]*)\s+|\n\s*\n`)
+
+// splitSentences splits text into sentences, treating blank lines as boundaries
+func splitSentences(text string) []string {
+	var sentences []string
+	start := 0
+	for _, match := range sentenceEnd.FindAllStringSubmatchIndex(text, -1) {
+		end := match[1]
+		if match[2] >= 0 {
+			end = match[3] // Keep the punctuation, drop the whitespace
+		} else {
+			end = match[0]
+		}
+		if sentence := strings.TrimSpace(text[start:end]); sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+		start = match[1]
+	}
+	if sentence := strings.TrimSpace(text[start:]); sentence != "" {
+		sentences = append(sentences, sentence)
+	}
+	return sentences
+}
+
+// packSentences joins sentences into chunks of at most size, repeating trailing
+// sentences of up to overlap in the next chunk. A sentence longer than size is
+// split on words.
+func packSentences(sentences []string, size, overlap int, length func(string) int) []string {
+	var chunks []string
+	var current []string
+	for _, sentence := range sentences {
+		if length(sentence) > size {
+			if len(current) > 0 {
+				chunks = append(chunks, strings.Join(current, " "))
+				current = nil
+			}
+			long, err := textsplitter.NewRecursiveCharacter(
+				textsplitter.WithChunkSize(size),
+				textsplitter.WithChunkOverlap(overlap),
+				textsplitter.WithLenFunc(length),
+			).SplitText(sentence)
+			if err == nil {
+				chunks = append(chunks, long...)
+			}
+			continue
+		}
+		if len(current) > 0 && length(strings.Join(append(current, sentence), " ")) > size {
+			chunks = append(chunks, strings.Join(current, " "))
+			current = overlapTail(current, overlap, length)
+			// Drop overlap that would not leave room for the next sentence
+			for len(current) > 0 && length(strings.Join(append(current, sentence), " ")) > size {
+				current = current[1:]
+			}
+		}
+		current = append(current, sentence)
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, strings.Join(current, " "))
+	}
+	return chunks
+}
+
+// overlapTail returns the trailing sentences whose combined length fits in overlap
+func overlapTail(sentences []string, overlap int, length func(string) int) []string {
+	var tail []string
+	for i := len(sentences) - 1; i >= 0; i-- {
+		candidate := append([]string{sentences[i]}, tail...)
+		if length(strings.Join(candidate, " ")) > overlap {
+			break
+		}
+		tail = candidate
+	}
+	return tail
+}
+
+// percentile returns the p-th percentile of values, interpolating between ranks
+func percentile(values []float64, p float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
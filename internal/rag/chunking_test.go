@@ -0,0 +1,106 @@
+package rag
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/schema"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/tokens"
+)
+
+// topicEmbedder embeds sentences mentioning "deploy" and other sentences in orthogonal directions
+type topicEmbedder struct{}
+
+func (topicEmbedder) EmbedDocuments(_ context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		if strings.Contains(strings.ToLower(text), "deploy") {
+			vectors[i] = []float32{1, 0}
+		} else {
+			vectors[i] = []float32{0, 1}
+		}
+	}
+	return vectors, nil
+}
+
+func (e topicEmbedder) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	vectors, err := e.EmbedDocuments(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return vectors[0], nil
+}
+
+func chunkTexts(t *testing.T, options ChunkingOptions, text string) []string {
+	options = options.WithDefaults()
+	require.NoError(t, options.Validate())
+	chunks, err := options.Split(context.Background(), []schema.Document{{PageContent: text, Metadata: map[string]any{"file_name": "doc.md"}}})
+	require.NoError(t, err)
+	texts := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		assert.Equal(t, i, chunk.Metadata["chunk_index"])
+		assert.Equal(t, "doc.md", chunk.Metadata["file_name"])
+		texts[i] = chunk.PageContent
+	}
+	return texts
+}
+
+func TestChunkingOptionsDefaults(t *testing.T) {
+	assert.Equal(t, DefaultChunkingOptions().Size, ChunkingOptions{}.WithDefaults().Size)
+
+	tokens := ChunkingOptions{Unit: ChunkUnitTokens}.WithDefaults()
+	assert.Equal(t, DefaultChunkSizeTokens, tokens.Size)
+	assert.Equal(t, DefaultChunkOverlapTokens, tokens.Overlap)
+
+	sized := ChunkingOptions{Size: 500}.WithDefaults()
+	assert.Equal(t, 100, sized.Overlap)
+
+	assert.Error(t, ChunkingOptions{Strategy: ChunkStrategySemantic}.WithDefaults().Validate())
+	assert.Error(t, ChunkingOptions{Size: 100, Overlap: 100}.WithDefaults().Validate())
+}
+
+func TestSentenceChunking(t *testing.T) {
+	text := "Deploys run at noon. Rollbacks need approval! Who approves them? The on-call lead does."
+	chunks := chunkTexts(t, ChunkingOptions{Strategy: ChunkStrategySentence, Size: 45, Overlap: 25}, text)
+	assert.Equal(t, []string{
+		"Deploys run at noon. Rollbacks need approval!",
+		"Rollbacks need approval! Who approves them?",
+		"Who approves them? The on-call lead does.",
+	}, chunks)
+}
+
+func TestMarkdownChunkingKeepsHeadings(t *testing.T) {
+	text := "# Runbook\n\n## Deploy\n\nRun the pipeline.\n\n## Rollback\n\nRevert the release."
+	chunks := chunkTexts(t, ChunkingOptions{Strategy: ChunkStrategyMarkdown, Size: 60, Overlap: 0}, text)
+	require.Len(t, chunks, 3)
+	assert.Equal(t, "# Runbook", chunks[0])
+	assert.Equal(t, "# Runbook\n## Deploy\nRun the pipeline.", chunks[1])
+	assert.Equal(t, "# Runbook\n## Rollback\nRevert the release.", chunks[2])
+}
+
+func TestTokenChunkSizes(t *testing.T) {
+	text := strings.Repeat("deployment ", 300)
+	for _, chunk := range chunkTexts(t, ChunkingOptions{Size: 50, Overlap: 10, Unit: ChunkUnitTokens}, text) {
+		assert.LessOrEqual(t, tokens.Count(chunk), 50)
+	}
+}
+
+func TestSemanticChunkingSplitsOnTopicChange(t *testing.T) {
+	text := "Deploys run at noon. Deploy tags are signed. Vacation is 20 days. Holidays are extra."
+	chunks := chunkTexts(t, ChunkingOptions{Strategy: ChunkStrategySemantic, Size: 1000, Embedder: topicEmbedder{}}, text)
+	assert.Equal(t, []string{
+		"Deploys run at noon. Deploy tags are signed.",
+		"Vacation is 20 days. Holidays are extra.",
+	}, chunks)
+}
+
+func TestPercentile(t *testing.T) {
+	values := []float64{0.4, 0.1, 0.3, 0.2}
+	assert.InDelta(t, 0.1, percentile(values, 0), 1e-9)
+	assert.InDelta(t, 0.25, percentile(values, 50), 1e-9)
+	assert.InDelta(t, 0.4, percentile(values, 100), 1e-9)
+}
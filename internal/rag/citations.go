@@ -0,0 +1,124 @@
+// Package rag provides source citation tracking for knowledge base searches
+package rag
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Citation identifies the source of a chunk returned by rag_search
+type Citation struct {
+	FileName string
+	Page     string // Page number, if the source has pages
+	Chunk    string // Chunk index within the file
+	URL      string // Link to the source, if known
+}
+
+// String renders the citation as a Slack mrkdwn reference
+func (c Citation) String() string {
+	name := c.FileName
+	if name == "" {
+		name = "Unknown source"
+	}
+	if c.URL != "" {
+		name = fmt.Sprintf("<%s|%s>", c.URL, name)
+	}
+	switch {
+	case c.Page != "":
+		return fmt.Sprintf("%s, page %s", name, c.Page)
+	case c.Chunk != "":
+		return fmt.Sprintf("%s, chunk %s", name, c.Chunk)
+	default:
+		return name
+	}
+}
+
+// CitationCollector records the sources returned by searches made while answering
+// a single request. Each distinct source gets a stable 1-based number.
+type CitationCollector struct {
+	mu        sync.Mutex
+	citations []Citation
+	index     map[Citation]int
+}
+
+// citationContextKey is the context key for the request's CitationCollector
+type citationContextKey struct{}
+
+// WithCitationCollector returns a context in which rag_search records its sources
+func WithCitationCollector(ctx context.Context) (context.Context, *CitationCollector) {
+	collector := &CitationCollector{index: make(map[Citation]int)}
+	return context.WithValue(ctx, citationContextKey{}, collector), collector
+}
+
+// CitationsFromContext returns the collector for the request, or nil
+func CitationsFromContext(ctx context.Context) *CitationCollector {
+	collector, _ := ctx.Value(citationContextKey{}).(*CitationCollector)
+	return collector
+}
+
+// add records the source of a search result and returns its citation number
+func (c *CitationCollector) add(result SearchResult) int {
+	citation := Citation{
+		FileName: result.FileName,
+		Page:     result.Metadata["page"],
+		URL:      firstNonEmpty(result.Metadata["url"], result.Metadata["source_url"]),
+	}
+	if citation.Page == "" {
+		citation.Chunk = result.Metadata["chunk_index"]
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if n, ok := c.index[citation]; ok {
+		return n
+	}
+	c.citations = append(c.citations, citation)
+	c.index[citation] = len(c.citations)
+	return len(c.citations)
+}
+
+// citationMarker matches references such as [2] in a response
+var citationMarker = regexp.MustCompile(`\[(\d+)\]`)
+
+// Format returns a "Sources" footer for the response. If the response cites sources
+// by number only those are listed; otherwise every recorded source is. It returns
+// an empty string when no sources were recorded.
+func (c *CitationCollector) Format(response string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.citations) == 0 {
+		return ""
+	}
+
+	cited := make(map[int]bool)
+	for _, match := range citationMarker.FindAllStringSubmatch(response, -1) {
+		if n, err := strconv.Atoi(match[1]); err == nil && n >= 1 && n <= len(c.citations) {
+			cited[n] = true
+		}
+	}
+
+	var footer strings.Builder
+	footer.WriteString("*Sources*")
+	for i, citation := range c.citations {
+		n := i + 1
+		if len(cited) > 0 && !cited[n] {
+			continue
+		}
+		footer.WriteString(fmt.Sprintf("\n[%d] %s", n, citation))
+	}
+	return footer.String()
+}
+
+// firstNonEmpty returns the first non-empty string
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
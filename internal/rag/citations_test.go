@@ -0,0 +1,51 @@
+package rag
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticProvider returns fixed search results
+type staticProvider struct {
+	VectorProvider
+	results []SearchResult
+}
+
+func (p *staticProvider) Search(context.Context, string, SearchOptions) ([]SearchResult, error) {
+	return p.results, nil
+}
+
+func TestCitationsFromSearch(t *testing.T) {
+	client := &Client{provider: &staticProvider{results: []SearchResult{
+		{Content: "20 days of vacation", FileName: "handbook.pdf", Metadata: map[string]string{"page": "3", "chunk_index": "0"}},
+		{Content: "Vacation requests go to your manager", FileName: "handbook.pdf", Metadata: map[string]string{"page": "3", "chunk_index": "1"}},
+		{Content: "Freeze during holidays", FileName: "runbook", Metadata: map[string]string{"chunk_index": "4", "url": "https://wiki.example.com/runbook"}},
+	}}}
+
+	ctx, collector := WithCitationCollector(context.Background())
+	output, err := client.CallTool(ctx, "rag_search", map[string]interface{}{"query": "vacation"})
+	require.NoError(t, err)
+	// Chunks from the same page share a citation number
+	assert.Equal(t, 2, strings.Count(output, "--- Context [1] ---"))
+	assert.Contains(t, output, "--- Context [2] ---")
+
+	assert.Equal(t, "*Sources*\n[1] handbook.pdf, page 3\n[2] <https://wiki.example.com/runbook|runbook>, chunk 4",
+		collector.Format("You get 20 days."))
+	assert.Equal(t, "*Sources*\n[2] <https://wiki.example.com/runbook|runbook>, chunk 4",
+		collector.Format("Deploys are frozen [2]."))
+
+	// Without a collector the output is unchanged
+	output, err = client.CallTool(context.Background(), "rag_search", map[string]interface{}{"query": "vacation"})
+	require.NoError(t, err)
+	assert.Contains(t, output, "--- Context 3 ---")
+}
+
+func TestCitationCollectorEmpty(t *testing.T) {
+	_, collector := WithCitationCollector(context.Background())
+	assert.Empty(t, collector.Format("No tools were used."))
+	assert.Nil(t, CitationsFromContext(context.Background()))
+}
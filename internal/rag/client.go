@@ -4,13 +4,45 @@ package rag
 import (
 	"context"
 	"fmt"
+	"os"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/tmc/langchaingo/embeddings"
+
+	"github.com/tuannvm/slack-mcp-client/internal/monitoring"
 )
 
 // Client wraps vector providers to implement the MCP tool interface
 // This allows the LLM-MCP bridge to treat RAG as a regular MCP tool
 type Client struct {
 	provider VectorProvider
+
+	// Optional second-stage reranking of search results
+	reranker         Reranker
+	rerankCandidates int
+	rerankTopN       int
+
+	// Limits for rag_ingest_url
+	web WebOptions
+
+	// Files of a directory ingested at once; see SetIngestWorkers
+	ingestWorkers int
+
+	// Chunking options set on the provider, recorded with ingested documents
+	chunking ChunkingOptions
+
+	// Age at which search scores are halved; 0 disables recency decay
+	halfLife time.Duration
+
+	// Stops the refreshes of the vector-store size gauges; see StartStoreMetrics
+	stopMetrics context.CancelFunc
+	metricsDone chan struct{}
+
+	// Stops the scheduled garbage collection; see StartGarbageCollection
+	stopGC context.CancelFunc
+	gcDone chan struct{}
 }
 
 // NewClient creates a new RAG client with simple provider (legacy compatibility)
@@ -64,10 +96,12 @@ func (c *Client) CallTool(ctx context.Context, toolName string, args map[string]
 		return c.handleRAGSearch(ctx, args)
 	case "rag_ingest":
 		return c.handleRAGIngest(ctx, args)
+	case "rag_ingest_url":
+		return c.handleRAGIngestURL(ctx, args)
 	case "rag_stats":
 		return c.handleRAGStats(ctx, args)
 	default:
-		return "", fmt.Errorf("unknown RAG tool: %s. Available tools: rag_search, rag_ingest, rag_stats", toolName)
+		return "", fmt.Errorf("unknown RAG tool: %s. Available tools: rag_search, rag_ingest, rag_ingest_url, rag_stats", toolName)
 	}
 }
 
@@ -79,23 +113,98 @@ func (c *Client) handleRAGSearch(ctx context.Context, args map[string]interface{
 		return "", err
 	}
 
-	// Perform search using the provider
-	results, err := c.provider.Search(ctx, query, SearchOptions{})
+	filter, err := ParseSearchFilter(args)
+	if err != nil {
+		return "", err
+	}
+
+	// A channel confined to a namespace may only narrow its search to that namespace
+	requested, err := c.extractStringParam(args, "namespace", false)
+	if err != nil {
+		return "", err
+	}
+	namespace, err := resolveNamespace(ctx, requested)
+	if err != nil {
+		return "", err
+	}
+	ctx = WithNamespace(ctx, namespace)
+
+	results, err := c.retrieve(ctx, query, 0, filter)
 	if err != nil {
-		return "", fmt.Errorf("search failed: %w", err)
+		return "", err
 	}
 
 	// Format results for display
 	if len(results) == 0 {
 		return "No relevant context found for query: '" + query + "'", nil
 	}
+	return FormatResults(ctx, query, results), nil
+}
+
+// Retrieve searches the knowledge base in the request's namespace and reranks the
+// results when a reranker is configured
+func (c *Client) Retrieve(ctx context.Context, query string) ([]SearchResult, error) {
+	return c.retrieve(ctx, query, 0, SearchFilter{})
+}
+
+// retrieve searches for up to limit results (0 uses the provider's default) that
+// pass the filter. With a reranker the configured candidates are searched instead.
+// Scores decay with the age of each chunk when a half-life is set, before the
+// results are cut to size, so recent chunks ranked just below the cut move up.
+func (c *Client) retrieve(ctx context.Context, query string, limit int, filter SearchFilter) ([]SearchResult, error) {
+	// Perform search using the provider, fetching extra candidates for the reranker
+	// or the decay
+	options := SearchOptions{Limit: limit, Filter: filter}
+	options.Metadata = namespaceFilter(ctx)
+	keep := 0
+	switch {
+	case c.reranker != nil:
+		options.Limit = c.rerankCandidates
+		keep = c.rerankTopN
+	case c.halfLife > 0:
+		keep = limit
+		if keep <= 0 {
+			keep = defaultSearchLimit
+		}
+		options.Limit = keep * decayCandidateFactor
+	}
+	started := time.Now()
+	results, err := c.provider.Search(ctx, query, options)
+	if err != nil {
+		recordSearch(ProviderLabel(c.provider), started, 0, err)
+		return nil, fmt.Errorf("search failed: %w", err)
+	}
+	results = decay(c.rerank(ctx, query, results), c.halfLife, time.Now())
+	if keep > 0 && len(results) > keep {
+		results = results[:keep]
+	}
+	recordSearch(ProviderLabel(c.provider), started, len(results), nil)
+	return results, nil
+}
+
+// FormatResults renders search results as numbered contexts for an LLM prompt.
+// When the request tracks citations, contexts are numbered by source, and when it
+// tracks stale sources, old results are recorded for a freshness warning.
+func FormatResults(ctx context.Context, query string, results []SearchResult) string {
+	if stale := StaleSourcesFromContext(ctx); stale != nil {
+		stale.record(results, time.Now())
+	}
 
-	// Build response string
 	var response strings.Builder
 	response.WriteString(fmt.Sprintf("Found %d relevant context(s) for '%s':\n", len(results), query))
 
+	// When citations are tracked, number contexts by source so the answer can cite them
+	citations := CitationsFromContext(ctx)
+	if citations != nil {
+		response.WriteString("Cite the sources you use with their number in brackets, e.g. [1].\n")
+	}
+
 	for i, result := range results {
-		response.WriteString(fmt.Sprintf("--- Context %d ---\n", i+1))
+		if citations != nil {
+			response.WriteString(fmt.Sprintf("--- Context [%d] ---\n", citations.add(result)))
+		} else {
+			response.WriteString(fmt.Sprintf("--- Context %d ---\n", i+1))
+		}
 
 		// Add source information if available
 		if result.FileName != "" {
@@ -105,6 +214,9 @@ func (c *Client) handleRAGSearch(ctx context.Context, args map[string]interface{
 			}
 			response.WriteString("\n")
 		}
+		if ingested := ingestedAt(result); !ingested.IsZero() {
+			response.WriteString(fmt.Sprintf("Ingested: %s\n", ingested.Format("2006-01-02")))
+		}
 
 		// Add content
 		response.WriteString(fmt.Sprintf("Content: %s\n", result.Content))
@@ -115,7 +227,21 @@ func (c *Client) handleRAGSearch(ctx context.Context, args map[string]interface{
 		}
 	}
 
-	return response.String(), nil
+	return response.String()
+}
+
+// rerank reorders results with the configured reranker. If reranking fails the
+// search order is kept.
+func (c *Client) rerank(ctx context.Context, query string, results []SearchResult) []SearchResult {
+	if c.reranker == nil || len(results) == 0 {
+		return results
+	}
+	reranked, err := c.reranker.Rerank(ctx, query, results)
+	if err != nil {
+		fmt.Printf("Warning: reranking failed, using search order: %v\n", err)
+		reranked = results
+	}
+	return reranked
 }
 
 // handleRAGIngest processes document ingestion requests
@@ -140,21 +266,181 @@ func (c *Client) handleRAGIngest(ctx context.Context, args map[string]interface{
 		}
 	}
 
+	// Store the file in the requested namespace, or the request's namespace
+	requested, err := c.extractStringParam(args, "namespace", false)
+	if err != nil {
+		return "", err
+	}
+	namespace, err := resolveNamespace(ctx, requested)
+	if err != nil {
+		return "", err
+	}
+	if namespace != "" {
+		metadata[NamespaceMetadataKey] = namespace
+	}
+
+	if info, err := os.Stat(filePath); err == nil && info.IsDir() {
+		return c.ingestDirectory(ctx, filePath, metadata)
+	}
+
 	// Ingest the file
-	fileID, err := c.provider.IngestFile(ctx, filePath, metadata)
+	fileID, skipped, err := c.ingestFile(ctx, filePath, metadata)
 	if err != nil {
 		return "", fmt.Errorf("ingestion failed: %w", err)
 	}
+	if skipped {
+		return fmt.Sprintf("File unchanged since it was last ingested: %s", filePath), nil
+	}
 
+	if namespace != "" {
+		return fmt.Sprintf("Successfully ingested file: %s into namespace %s (ID: %s)", filePath, namespace, fileID), nil
+	}
 	return fmt.Sprintf("Successfully ingested file: %s (ID: %s)", filePath, fileID), nil
 }
 
+// ingestFile ingests a local file and records it in the ingestion metrics
+func (c *Client) ingestFile(ctx context.Context, filePath string, metadata map[string]string) (string, bool, error) {
+	fileID, skipped, err := c.storeFile(ctx, filePath, metadata)
+	outcome, size := IngestOutcomeIngested, 0
+	switch {
+	case err != nil:
+		outcome = IngestOutcomeError
+	case skipped:
+		outcome = IngestOutcomeUnchanged
+	default:
+		if info, statErr := os.Stat(filePath); statErr == nil {
+			size = int(info.Size())
+		}
+	}
+	RecordIngestion(ProviderLabel(c.provider), IngestSourceFile, outcome, size)
+	return fileID, skipped, err
+}
+
+// storeFile stores a local file. When the provider keeps a content index, the
+// file's content hash is stored with it and an unchanged file is skipped.
+func (c *Client) storeFile(ctx context.Context, filePath string, metadata map[string]string) (string, bool, error) {
+	index, ok := c.provider.(ContentIndex)
+	if !ok {
+		fileID, err := c.provider.IngestFile(ctx, filePath, metadata)
+		return fileID, false, err
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read file: %w", err)
+	}
+	metadata = c.withContentHash(metadata, data)
+	stored, err := index.DocumentMetadata(ctx, filePath, metadata[NamespaceMetadataKey])
+	if err != nil {
+		return "", false, err
+	}
+	if unchanged(stored, metadata) {
+		return "", true, nil
+	}
+	fileID, err := c.provider.IngestFile(ctx, filePath, metadata)
+	return fileID, false, err
+}
+
+// withContentHash returns a copy of metadata recording the content hash and the
+// chunking options
+func (c *Client) withContentHash(metadata map[string]string, content []byte) map[string]string {
+	withHash := make(map[string]string, len(metadata)+2)
+	for key, value := range metadata {
+		withHash[key] = value
+	}
+	withHash[ContentHashMetadataKey] = ContentHash(content)
+	withHash[ChunkingMetadataKey] = c.chunking.fingerprint()
+	return withHash
+}
+
+// handleRAGIngestURL fetches a web page, or shallow-crawls a site, and ingests the
+// readable text of each page with its URL
+func (c *Client) handleRAGIngestURL(ctx context.Context, args map[string]interface{}) (string, error) {
+	pageURL, err := c.extractStringParam(args, "url", true)
+	if err != nil {
+		return "", err
+	}
+	ingester, ok := c.provider.(DocumentIngester)
+	if !ok {
+		return "", fmt.Errorf("the configured RAG provider does not support URL ingestion")
+	}
+
+	maxDepth, maxPages := c.web.MaxDepth, c.web.MaxPages
+	if maxDepth <= 0 {
+		maxDepth = DefaultWebMaxDepth
+	}
+	if maxPages <= 0 {
+		maxPages = DefaultWebMaxPages
+	}
+	depth := extractIntParam(args, "depth", 0)
+	if depth < 0 || depth > maxDepth {
+		return "", fmt.Errorf("depth must be between 0 and %d", maxDepth)
+	}
+	pages := extractIntParam(args, "max_pages", maxPages)
+	if pages <= 0 || pages > maxPages {
+		pages = maxPages
+	}
+
+	requested, err := c.extractStringParam(args, "namespace", false)
+	if err != nil {
+		return "", err
+	}
+	namespace, err := resolveNamespace(ctx, requested)
+	if err != nil {
+		return "", err
+	}
+
+	fetched, err := NewWebFetcher(c.web.AllowedDomains).Crawl(ctx, pageURL, CrawlOptions{Depth: depth, MaxPages: pages})
+	if err != nil {
+		return "", fmt.Errorf("ingestion failed: %w", err)
+	}
+	if len(fetched) == 0 {
+		return "", fmt.Errorf("ingestion failed: no readable content found at %s", pageURL)
+	}
+
+	index, indexed := c.provider.(ContentIndex)
+	var response strings.Builder
+	ingested := 0
+	for _, page := range fetched {
+		metadata := map[string]string{"url": page.URL, "title": page.Title}
+		if namespace != "" {
+			metadata[NamespaceMetadataKey] = namespace
+		}
+		if indexed {
+			metadata = c.withContentHash(metadata, []byte(page.Text))
+			if stored, err := index.DocumentMetadata(ctx, page.URL, namespace); err == nil && unchanged(stored, metadata) {
+				RecordIngestion(ProviderLabel(c.provider), IngestSourceURL, IngestOutcomeUnchanged, 0)
+				ingested++
+				response.WriteString(fmt.Sprintf("- %s (unchanged)\n", page.URL))
+				continue
+			}
+		}
+		fileID, err := ingester.IngestDocument(ctx, page.URL, page.Title, page.Text, metadata)
+		if err != nil {
+			RecordIngestion(ProviderLabel(c.provider), IngestSourceURL, IngestOutcomeError, 0)
+			response.WriteString(fmt.Sprintf("- Failed: %s (%v)\n", page.URL, err))
+			continue
+		}
+		RecordIngestion(ProviderLabel(c.provider), IngestSourceURL, IngestOutcomeIngested, len(page.Text))
+		ingested++
+		response.WriteString(fmt.Sprintf("- %s (ID: %s)\n", page.URL, fileID))
+	}
+
+	summary := fmt.Sprintf("Successfully ingested %d of %d page(s) from %s", ingested, len(fetched), pageURL)
+	if namespace != "" {
+		summary += " into namespace " + namespace
+	}
+	return summary + ":\n" + response.String(), nil
+}
+
 // handleRAGStats returns statistics about the vector store
 func (c *Client) handleRAGStats(ctx context.Context, args map[string]interface{}) (string, error) {
 	stats, err := c.provider.GetStats(ctx)
 	if err != nil {
+		monitoring.RAGProviderErrors.WithLabelValues(ProviderLabel(c.provider), "stats").Inc()
 		return "", fmt.Errorf("failed to get stats: %w", err)
 	}
+	recordStoreStats(ProviderLabel(c.provider), stats)
 
 	var response strings.Builder
 	response.WriteString("RAG Vector Store Statistics:\n")
@@ -194,6 +480,77 @@ func (c *Client) extractStringParam(args map[string]interface{}, paramName strin
 	return strValue, nil
 }
 
+// SetReranker enables second-stage reranking: searches retrieve candidates results,
+// which the reranker reorders before the best topN are returned
+func (c *Client) SetReranker(reranker Reranker, candidates, topN int) {
+	c.reranker = reranker
+	c.rerankCandidates = candidates
+	c.rerankTopN = topN
+}
+
+// EnableVectorSearch configures embedding-based retrieval on the provider
+func (c *Client) EnableVectorSearch(embedder embeddings.Embedder, options HybridOptions) error {
+	searcher, ok := c.provider.(VectorSearcher)
+	if !ok {
+		return fmt.Errorf("provider does not support %s search", options.Mode)
+	}
+	searcher.SetEmbedder(embedder, options)
+	return nil
+}
+
+// SetChunking configures how the provider splits documents
+func (c *Client) SetChunking(options ChunkingOptions) error {
+	configurer, ok := c.provider.(ChunkingConfigurer)
+	if !ok {
+		return fmt.Errorf("provider does not support configuring chunking")
+	}
+	if err := configurer.SetChunking(options); err != nil {
+		return err
+	}
+	c.chunking = options
+	return nil
+}
+
+// Dedupe removes duplicated documents and chunks from the knowledge base
+func (c *Client) Dedupe(ctx context.Context) (DedupeResult, error) {
+	index, ok := c.provider.(ContentIndex)
+	if !ok {
+		return DedupeResult{}, fmt.Errorf("the configured RAG provider does not support deduplication")
+	}
+	return index.Dedupe(ctx)
+}
+
+// EmbedMissing embeds stored chunks that have no vector yet. It is a no-op unless
+// vector search is enabled.
+func (c *Client) EmbedMissing(ctx context.Context) (int, error) {
+	searcher, ok := c.provider.(VectorSearcher)
+	if !ok {
+		return 0, nil
+	}
+	return searcher.EmbedMissing(ctx)
+}
+
+// SetWebOptions limits the depth, page count and hosts of rag_ingest_url crawls
+func (c *Client) SetWebOptions(options WebOptions) {
+	c.web = options
+}
+
+// extractIntParam reads an optional numeric parameter, which arrives as float64
+// from JSON tool arguments
+func extractIntParam(args map[string]interface{}, paramName string, defaultValue int) int {
+	switch value := args[paramName].(type) {
+	case float64:
+		return int(value)
+	case int:
+		return value
+	case string:
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	}
+	return defaultValue
+}
+
 // GetProvider returns the underlying vector provider (for testing/debugging)
 func (c *Client) GetProvider() VectorProvider {
 	return c.provider
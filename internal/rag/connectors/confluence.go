@@ -0,0 +1,146 @@
+package connectors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/tuannvm/slack-mcp-client/internal/rag"
+)
+
+// confluencePageSize is the number of pages requested per listing call
+const confluencePageSize = 50
+
+// Confluence lists and reads pages through the Confluence REST API. It supports
+// Confluence Cloud (email and API token) and Server/Data Center (personal access token).
+type Confluence struct {
+	baseURL    string
+	username   string
+	token      string
+	spaces     []string
+	httpClient *http.Client
+}
+
+// NewConfluence creates a connector for the given spaces. baseURL is the site URL,
+// including /wiki for Confluence Cloud.
+func NewConfluence(baseURL, username, token string, spaces []string, httpClient *http.Client) *Confluence {
+	return &Confluence{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		username:   username,
+		token:      token,
+		spaces:     spaces,
+		httpClient: httpClient,
+	}
+}
+
+type confluenceContent struct {
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	Version struct {
+		Number int `json:"number"`
+	} `json:"version"`
+	Body struct {
+		Storage struct {
+			Value string `json:"value"`
+		} `json:"storage"`
+	} `json:"body"`
+	Links struct {
+		WebUI string `json:"webui"`
+	} `json:"_links"`
+}
+
+type confluenceContentList struct {
+	Results []confluenceContent `json:"results"`
+	Size    int                 `json:"size"`
+	Links   struct {
+		Next string `json:"next"`
+	} `json:"_links"`
+}
+
+// List implements Connector
+func (c *Confluence) List(ctx context.Context) ([]PageRef, error) {
+	var pages []PageRef
+	for _, space := range c.spaces {
+		for start := 0; ; start += confluencePageSize {
+			query := url.Values{
+				"spaceKey": {space},
+				"type":     {"page"},
+				"status":   {"current"},
+				"expand":   {"version"},
+				"start":    {strconv.Itoa(start)},
+				"limit":    {strconv.Itoa(confluencePageSize)},
+			}
+			var list confluenceContentList
+			if err := c.get(ctx, "/rest/api/content?"+query.Encode(), &list); err != nil {
+				return nil, fmt.Errorf("failed to list pages in space %s: %w", space, err)
+			}
+			for _, content := range list.Results {
+				pages = append(pages, c.pageRef(content))
+			}
+			if list.Links.Next == "" || len(list.Results) == 0 {
+				break
+			}
+		}
+	}
+	return pages, nil
+}
+
+// Content implements Connector
+func (c *Confluence) Content(ctx context.Context, page PageRef) (string, error) {
+	var content confluenceContent
+	if err := c.get(ctx, "/rest/api/content/"+url.PathEscape(page.ID)+"?expand=body.storage", &content); err != nil {
+		return "", fmt.Errorf("failed to read page %s: %w", page.ID, err)
+	}
+	return rag.HTMLToText(content.Body.Storage.Value)
+}
+
+// pageRef converts an API content item into a PageRef
+func (c *Confluence) pageRef(content confluenceContent) PageRef {
+	pageURL := c.baseURL + content.Links.WebUI
+	if content.Links.WebUI == "" {
+		pageURL = c.baseURL + "/pages/viewpage.action?pageId=" + content.ID
+	}
+	return PageRef{
+		ID:      content.ID,
+		Title:   content.Title,
+		URL:     pageURL,
+		Version: strconv.Itoa(content.Version.Number),
+	}
+}
+
+// get performs an authenticated GET request and decodes the JSON response
+func (c *Confluence) get(ctx context.Context, path string, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	return doJSON(c.httpClient, req, result)
+}
+
+// doJSON sends a request and decodes a successful JSON response
+func doJSON(httpClient *http.Client, req *http.Request, result interface{}) error {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
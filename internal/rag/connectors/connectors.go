@@ -0,0 +1,50 @@
+// Package connectors syncs pages from external sources such as Confluence, Notion
+// and Google Drive into the RAG knowledge base.
+package connectors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+// requestTimeout bounds a single API request made by a connector
+const requestTimeout = 30 * time.Second
+
+// PageRef identifies a page and its current version without its content
+type PageRef struct {
+	ID      string
+	Title   string
+	URL     string // Link to the page, used as its source in the knowledge base
+	Version string // Changes whenever the page content changes
+}
+
+// Connector lists the pages of an external source and fetches their content
+type Connector interface {
+	// List returns every page that should be in the knowledge base
+	List(ctx context.Context) ([]PageRef, error)
+	// Content returns the readable text of a page
+	Content(ctx context.Context, page PageRef) (string, error)
+}
+
+// New creates the connector for a configured source
+func New(source config.RAGSourceConfig) (Connector, error) {
+	httpClient := &http.Client{Timeout: requestTimeout}
+	switch source.Type {
+	case config.RAGSourceConfluence:
+		return NewConfluence(source.BaseURL, source.Username, source.APIToken, source.Spaces, httpClient), nil
+	case config.RAGSourceNotion:
+		return NewNotion(notionAPIURL, source.APIToken, source.DatabaseIDs, httpClient), nil
+	case config.RAGSourceGoogleDrive:
+		driveClient, err := GoogleServiceAccountClient(source.CredentialsFile, source.Subject, httpClient)
+		if err != nil {
+			return nil, err
+		}
+		return NewGoogleDrive(driveAPIURL, source.FolderIDs, driveClient), nil
+	default:
+		return nil, fmt.Errorf("unsupported rag source type '%s'", source.Type)
+	}
+}
@@ -0,0 +1,96 @@
+package connectors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfluenceListAndContent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, token, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "bot@example.com", user)
+		assert.Equal(t, "secret", token)
+
+		switch r.URL.Path {
+		case "/wiki/rest/api/content":
+			assert.Equal(t, "ENG", r.URL.Query().Get("spaceKey"))
+			if r.URL.Query().Get("start") == "0" {
+				_, _ = fmt.Fprint(w, `{"results":[{"id":"1","title":"Runbook","version":{"number":3},"_links":{"webui":"/spaces/ENG/pages/1/Runbook"}}],
+					"_links":{"next":"/rest/api/content?start=50"}}`)
+				return
+			}
+			_, _ = fmt.Fprint(w, `{"results":[{"id":"2","title":"Oncall","version":{"number":1},"_links":{}}],"_links":{}}`)
+		case "/wiki/rest/api/content/1":
+			assert.Equal(t, "body.storage", r.URL.Query().Get("expand"))
+			_, _ = fmt.Fprint(w, `{"id":"1","body":{"storage":{"value":"<h1>Rollback</h1><p>Run <code>argocd app rollback</code>.</p>"}}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	confluence := NewConfluence(server.URL+"/wiki/", "bot@example.com", "secret", []string{"ENG"}, server.Client())
+	pages, err := confluence.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, pages, 2)
+	assert.Equal(t, PageRef{ID: "1", Title: "Runbook", URL: server.URL + "/wiki/spaces/ENG/pages/1/Runbook", Version: "3"}, pages[0])
+	assert.Equal(t, server.URL+"/wiki/pages/viewpage.action?pageId=2", pages[1].URL)
+
+	text, err := confluence.Content(context.Background(), pages[0])
+	require.NoError(t, err)
+	assert.Equal(t, "Rollback\nRun argocd app rollback.", text)
+
+	_, err = confluence.Content(context.Background(), PageRef{ID: "404"})
+	assert.ErrorContains(t, err, "404")
+}
+
+func TestNotionListAndContent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret_token", r.Header.Get("Authorization"))
+		assert.Equal(t, notionVersion, r.Header.Get("Notion-Version"))
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/databases/db1/query":
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			if body["start_cursor"] == nil {
+				_, _ = fmt.Fprint(w, `{"results":[{"id":"p1","url":"https://notion.so/p1","last_edited_time":"2025-01-02T00:00:00.000Z",
+					"properties":{"Name":{"type":"title","title":[{"plain_text":"Leave "},{"plain_text":"policy"}]}}}],
+					"has_more":true,"next_cursor":"c2"}`)
+				return
+			}
+			_, _ = fmt.Fprint(w, `{"results":[{"id":"p2","url":"https://notion.so/p2","last_edited_time":"2025-01-03T00:00:00.000Z","properties":{}}],"has_more":false}`)
+		case r.URL.Path == "/v1/blocks/p1/children":
+			_, _ = fmt.Fprint(w, `{"results":[
+				{"id":"b1","type":"heading_1","has_children":false,"heading_1":{"rich_text":[{"plain_text":"Annual leave"}]}},
+				{"id":"b2","type":"toggle","has_children":true,"toggle":{"rich_text":[{"plain_text":"Details"}]}},
+				{"id":"b3","type":"child_page","has_children":true,"child_page":{"title":"Sub page"}},
+				{"id":"b4","type":"divider","has_children":false,"divider":{}}],"has_more":false}`)
+		case r.URL.Path == "/v1/blocks/b2/children":
+			_, _ = fmt.Fprint(w, `{"results":[{"id":"b5","type":"bulleted_list_item","has_children":false,
+				"bulleted_list_item":{"rich_text":[{"plain_text":"25 days per year"}]}}],"has_more":false}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	notion := NewNotion(server.URL+"/v1", "secret_token", []string{"db1"}, server.Client())
+	pages, err := notion.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, pages, 2)
+	assert.Equal(t, PageRef{ID: "p1", Title: "Leave policy", URL: "https://notion.so/p1", Version: "2025-01-02T00:00:00.000Z"}, pages[0])
+	assert.Equal(t, "Untitled", pages[1].Title)
+
+	text, err := notion.Content(context.Background(), pages[0])
+	require.NoError(t, err)
+	assert.Equal(t, "Annual leave\nDetails\n  25 days per year", text)
+}
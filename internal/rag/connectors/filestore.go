@@ -0,0 +1,143 @@
+package connectors
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/tuannvm/slack-mcp-client/internal/rag"
+)
+
+// fileStoreListLimit is the number of files read when recovering uploads made
+// before a restart
+const fileStoreListLimit = 100
+
+// uploadTag matches the source tag in the name of an uploaded document
+var uploadTag = regexp.MustCompile(`\[([0-9a-f]{12})\]\.txt$`)
+
+// unsafeFileChars are replaced in uploaded file names
+var unsafeFileChars = regexp.MustCompile(`[^\w .,()-]+`)
+
+// storedUpload is a document uploaded by a fileStore
+type storedUpload struct {
+	fileID    string
+	source    string // Empty for uploads recovered after a restart
+	version   string
+	connector string
+}
+
+// fileStore lets the syncer feed providers that can only ingest files, such as the
+// OpenAI vector store. Each document is uploaded as a text file whose name carries
+// a tag derived from its source, so the previous upload can be replaced. Versions
+// are only known for documents uploaded since startup: after a restart every
+// document is uploaded once more, replacing its previous copy.
+type fileStore struct {
+	provider rag.VectorProvider
+
+	mu        sync.Mutex
+	recovered bool
+	uploads   map[string]storedUpload // source tag -> upload
+}
+
+// newFileStore wraps a provider that does not implement Store
+func newFileStore(provider rag.VectorProvider) *fileStore {
+	return &fileStore{provider: provider, uploads: make(map[string]storedUpload)}
+}
+
+// DocumentVersions implements rag.DocumentVersioner for the documents uploaded
+// since startup
+func (f *fileStore) DocumentVersions(ctx context.Context, filter map[string]string) (map[string]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	versions := make(map[string]string)
+	for _, upload := range f.uploads {
+		if upload.source != "" && upload.connector == filter[connectorMetadataKey] {
+			versions[upload.source] = upload.version
+		}
+	}
+	return versions, nil
+}
+
+// DeleteDocument implements rag.DocumentVersioner
+func (f *fileStore) DeleteDocument(ctx context.Context, source, _ string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tag := sourceTag(source)
+	upload, ok := f.uploads[tag]
+	if !ok {
+		return fmt.Errorf("document not found: %s", source)
+	}
+	if err := f.provider.DeleteFile(ctx, upload.fileID); err != nil {
+		return err
+	}
+	delete(f.uploads, tag)
+	return nil
+}
+
+// IngestDocument implements rag.DocumentIngester by uploading the text as a file
+// and deleting the document's previous upload
+func (f *fileStore) IngestDocument(ctx context.Context, source, name, text string, metadata map[string]string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.recover(ctx)
+
+	tag := sourceTag(source)
+	dir, err := os.MkdirTemp("", "rag-sync-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	// Keep the source URL in the text, since file uploads carry no metadata
+	fileName := strings.TrimSpace(unsafeFileChars.ReplaceAllString(name, "_"))
+	path := filepath.Join(dir, fmt.Sprintf("%s [%s].txt", fileName, tag))
+	content := fmt.Sprintf("%s\nSource: %s\n\n%s", name, source, text)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		return "", fmt.Errorf("failed to write document: %w", err)
+	}
+
+	fileID, err := f.provider.IngestFile(ctx, path, metadata)
+	if err != nil {
+		return "", err
+	}
+	if previous, ok := f.uploads[tag]; ok {
+		if err := f.provider.DeleteFile(ctx, previous.fileID); err != nil {
+			fmt.Printf("Warning: failed to delete previous upload of %s: %v\n", source, err)
+		}
+	}
+	f.uploads[tag] = storedUpload{fileID: fileID, source: source, version: metadata["version"], connector: metadata[connectorMetadataKey]}
+	return fileID, nil
+}
+
+// recover finds documents uploaded before a restart, so that they are replaced
+// rather than duplicated when uploaded again
+func (f *fileStore) recover(ctx context.Context) {
+	if f.recovered {
+		return
+	}
+	files, err := f.provider.ListFiles(ctx, fileStoreListLimit)
+	if err != nil {
+		fmt.Printf("Warning: failed to list existing uploads: %v\n", err)
+		return
+	}
+	f.recovered = true
+	for _, file := range files {
+		if match := uploadTag.FindStringSubmatch(file.Name); match != nil {
+			if _, ok := f.uploads[match[1]]; !ok {
+				f.uploads[match[1]] = storedUpload{fileID: file.ID}
+			}
+		}
+	}
+}
+
+// sourceTag is a short stable identifier for a document source
+func sourceTag(source string) string {
+	sum := sha256.Sum256([]byte(source))
+	return hex.EncodeToString(sum[:6])
+}
@@ -0,0 +1,385 @@
+package connectors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/jwt"
+
+	"github.com/tuannvm/slack-mcp-client/internal/rag"
+)
+
+const (
+	driveAPIURL    = "https://www.googleapis.com/drive/v3"
+	driveScope     = "https://www.googleapis.com/auth/drive.readonly"
+	googleTokenURL = "https://oauth2.googleapis.com/token"
+
+	// driveMaxDownload bounds the size of a downloaded or exported file
+	driveMaxDownload = 50 << 20
+
+	driveFolderType = "application/vnd.google-apps.folder"
+	driveFileFields = "id,name,mimeType,modifiedTime,version,webViewLink,parents,trashed"
+)
+
+// driveExportTypes maps Google Workspace types to the format they are exported in
+var driveExportTypes = map[string]string{
+	"application/vnd.google-apps.document":     "text/plain",
+	"application/vnd.google-apps.presentation": "text/plain",
+}
+
+// driveDownloadTypes are the uploaded file types that are downloaded and read
+var driveDownloadTypes = map[string]bool{
+	"application/pdf": true,
+	"text/plain":      true,
+	"text/markdown":   true,
+}
+
+// GoogleDrive lists and reads the files in a set of Drive folders and their
+// subfolders. Google Docs and Slides are exported as text and PDFs are parsed.
+// After the first full listing, only the changes reported by the Drive changes
+// API are applied, so later syncs make a handful of requests.
+type GoogleDrive struct {
+	apiURL     string
+	folderIDs  []string
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	pageToken string               // Changes API position; empty before the first listing
+	folders   map[string][]string  // Synced folders and subfolders -> their parents
+	files     map[string]driveFile // Supported files in the synced folders
+}
+
+type driveFile struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	MimeType     string   `json:"mimeType"`
+	ModifiedTime string   `json:"modifiedTime"`
+	Version      string   `json:"version"`
+	WebViewLink  string   `json:"webViewLink"`
+	Parents      []string `json:"parents"`
+	Trashed      bool     `json:"trashed"`
+}
+
+type driveFileList struct {
+	Files         []driveFile `json:"files"`
+	NextPageToken string      `json:"nextPageToken"`
+}
+
+type driveChangeList struct {
+	Changes []struct {
+		FileID  string     `json:"fileId"`
+		Removed bool       `json:"removed"`
+		File    *driveFile `json:"file"`
+	} `json:"changes"`
+	NextPageToken     string `json:"nextPageToken"`
+	NewStartPageToken string `json:"newStartPageToken"`
+}
+
+// serviceAccountKey is the part of a Google service account key file used for auth
+type serviceAccountKey struct {
+	Type         string `json:"type"`
+	ClientEmail  string `json:"client_email"`
+	PrivateKey   string `json:"private_key"`
+	PrivateKeyID string `json:"private_key_id"`
+	TokenURI     string `json:"token_uri"`
+}
+
+// NewGoogleDrive creates a connector for the given folders. httpClient must add
+// the OAuth credentials, see GoogleServiceAccountClient.
+func NewGoogleDrive(apiURL string, folderIDs []string, httpClient *http.Client) *GoogleDrive {
+	return &GoogleDrive{
+		apiURL:     strings.TrimSuffix(apiURL, "/"),
+		folderIDs:  folderIDs,
+		httpClient: httpClient,
+	}
+}
+
+// GoogleServiceAccountClient returns an HTTP client that authenticates with the
+// service account key in credentialsFile, with read-only Drive access. A non-empty
+// subject impersonates that user through domain-wide delegation.
+func GoogleServiceAccountClient(credentialsFile, subject string, base *http.Client) (*http.Client, error) {
+	data, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials file: %w", err)
+	}
+	var key serviceAccountKey
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, fmt.Errorf("failed to parse credentials file: %w", err)
+	}
+	if key.Type != "service_account" || key.ClientEmail == "" || key.PrivateKey == "" {
+		return nil, fmt.Errorf("credentials file is not a service account key")
+	}
+	tokenURL := key.TokenURI
+	if tokenURL == "" {
+		tokenURL = googleTokenURL
+	}
+	conf := &jwt.Config{
+		Email:        key.ClientEmail,
+		PrivateKey:   []byte(key.PrivateKey),
+		PrivateKeyID: key.PrivateKeyID,
+		Subject:      subject,
+		Scopes:       []string{driveScope},
+		TokenURL:     tokenURL,
+	}
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, base)
+	client := oauth2.NewClient(ctx, conf.TokenSource(ctx))
+	client.Timeout = base.Timeout
+	return client, nil
+}
+
+// List implements Connector
+func (d *GoogleDrive) List(ctx context.Context) ([]PageRef, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.pageToken == "" {
+		if err := d.listAll(ctx); err != nil {
+			return nil, err
+		}
+	} else if err := d.applyChanges(ctx); err != nil {
+		return nil, err
+	}
+
+	pages := make([]PageRef, 0, len(d.files))
+	for _, file := range d.files {
+		pages = append(pages, file.pageRef())
+	}
+	return pages, nil
+}
+
+// listAll reads every folder from scratch and records the changes position to
+// continue from
+func (d *GoogleDrive) listAll(ctx context.Context) error {
+	// Take the position first, so changes made during the listing are not missed
+	var start struct {
+		StartPageToken string `json:"startPageToken"`
+	}
+	if err := d.get(ctx, "/changes/startPageToken?supportsAllDrives=true", &start); err != nil {
+		return fmt.Errorf("failed to get changes start token: %w", err)
+	}
+
+	d.folders = make(map[string][]string)
+	d.files = make(map[string]driveFile)
+	for _, folderID := range d.folderIDs {
+		if err := d.listFolder(ctx, folderID, nil); err != nil {
+			return fmt.Errorf("failed to list folder %s: %w", folderID, err)
+		}
+	}
+	d.pageToken = start.StartPageToken
+	return nil
+}
+
+// listFolder adds the supported files of a folder and its subfolders
+func (d *GoogleDrive) listFolder(ctx context.Context, folderID string, parents []string) error {
+	if _, ok := d.folders[folderID]; ok {
+		d.folders[folderID] = parents
+		return nil
+	}
+	d.folders[folderID] = parents
+	pageToken := ""
+	for {
+		query := url.Values{
+			"q":                         {fmt.Sprintf("'%s' in parents and trashed = false", strings.ReplaceAll(folderID, "'", `\'`))},
+			"fields":                    {"nextPageToken,files(" + driveFileFields + ")"},
+			"pageSize":                  {"1000"},
+			"supportsAllDrives":         {"true"},
+			"includeItemsFromAllDrives": {"true"},
+		}
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+		var list driveFileList
+		if err := d.get(ctx, "/files?"+query.Encode(), &list); err != nil {
+			return err
+		}
+		for _, file := range list.Files {
+			if err := d.add(ctx, file); err != nil {
+				return err
+			}
+		}
+		if list.NextPageToken == "" {
+			return nil
+		}
+		pageToken = list.NextPageToken
+	}
+}
+
+// add records a file found in a synced folder, descending into subfolders
+func (d *GoogleDrive) add(ctx context.Context, file driveFile) error {
+	if file.MimeType == driveFolderType {
+		return d.listFolder(ctx, file.ID, file.Parents)
+	}
+	if file.supported() {
+		d.files[file.ID] = file
+	} else {
+		delete(d.files, file.ID)
+	}
+	return nil
+}
+
+// applyChanges updates the known files with the changes made since the last sync
+func (d *GoogleDrive) applyChanges(ctx context.Context) error {
+	pageToken := d.pageToken
+	for {
+		query := url.Values{
+			"pageToken":                 {pageToken},
+			"fields":                    {"nextPageToken,newStartPageToken,changes(fileId,removed,file(" + driveFileFields + "))"},
+			"pageSize":                  {"1000"},
+			"supportsAllDrives":         {"true"},
+			"includeItemsFromAllDrives": {"true"},
+		}
+		var list driveChangeList
+		if err := d.get(ctx, "/changes?"+query.Encode(), &list); err != nil {
+			return fmt.Errorf("failed to list changes: %w", err)
+		}
+		for _, change := range list.Changes {
+			if change.Removed || change.File == nil || change.File.Trashed || !d.inSyncedFolder(*change.File) {
+				d.remove(change.FileID)
+				continue
+			}
+			if err := d.add(ctx, *change.File); err != nil {
+				return fmt.Errorf("failed to list folder %s: %w", change.FileID, err)
+			}
+		}
+		if list.NewStartPageToken != "" {
+			d.pageToken = list.NewStartPageToken
+			return nil
+		}
+		pageToken = list.NextPageToken
+	}
+}
+
+// remove forgets a file, or a folder and everything that was synced through it
+func (d *GoogleDrive) remove(id string) {
+	delete(d.files, id)
+	if _, ok := d.folders[id]; !ok || d.isRoot(id) {
+		// A configured folder stays synced, in case it is restored
+		return
+	}
+	delete(d.folders, id)
+
+	// Drop the subfolders that are no longer reachable, then their files
+	for removed := true; removed; {
+		removed = false
+		for folderID, parents := range d.folders {
+			if !d.isRoot(folderID) && !d.hasSyncedParent(parents) {
+				delete(d.folders, folderID)
+				removed = true
+			}
+		}
+	}
+	for fileID, file := range d.files {
+		if !d.inSyncedFolder(file) {
+			delete(d.files, fileID)
+		}
+	}
+}
+
+// inSyncedFolder reports whether the file is a configured folder or is in a synced one
+func (d *GoogleDrive) inSyncedFolder(file driveFile) bool {
+	return d.isRoot(file.ID) || d.hasSyncedParent(file.Parents)
+}
+
+// hasSyncedParent reports whether one of the parents is a synced folder
+func (d *GoogleDrive) hasSyncedParent(parents []string) bool {
+	for _, parent := range parents {
+		if _, ok := d.folders[parent]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isRoot reports whether a folder is one of the configured folders
+func (d *GoogleDrive) isRoot(id string) bool {
+	for _, root := range d.folderIDs {
+		if root == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Content implements Connector
+func (d *GoogleDrive) Content(ctx context.Context, page PageRef) (string, error) {
+	d.mu.Lock()
+	file, ok := d.files[page.ID]
+	d.mu.Unlock()
+	if !ok {
+		return "", fmt.Errorf("file %s is not in a synced folder", page.ID)
+	}
+
+	path := "/files/" + url.PathEscape(file.ID) + "?alt=media&supportsAllDrives=true"
+	if exportType, ok := driveExportTypes[file.MimeType]; ok {
+		path = "/files/" + url.PathEscape(file.ID) + "/export?mimeType=" + url.QueryEscape(exportType)
+	}
+	data, err := d.download(ctx, path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file %s: %w", file.ID, err)
+	}
+	if file.MimeType == "application/pdf" {
+		return rag.PDFToText(ctx, data)
+	}
+	return strings.TrimSpace(strings.TrimPrefix(string(data), "\ufeff")), nil
+}
+
+// download fetches a file's content
+func (d *GoogleDrive) download(ctx context.Context, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.apiURL+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, driveMaxDownload+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if len(data) > driveMaxDownload {
+		return nil, fmt.Errorf("file is larger than %d bytes", driveMaxDownload)
+	}
+	return data, nil
+}
+
+// get performs a Drive API GET request and decodes the JSON response
+func (d *GoogleDrive) get(ctx context.Context, path string, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.apiURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	return doJSON(d.httpClient, req, result)
+}
+
+// supported reports whether the file's type can be read as text
+func (f driveFile) supported() bool {
+	_, export := driveExportTypes[f.MimeType]
+	return export || driveDownloadTypes[f.MimeType]
+}
+
+// pageRef converts a file into a PageRef. The version changes on every edit.
+func (f driveFile) pageRef() PageRef {
+	link := f.WebViewLink
+	if link == "" {
+		link = "https://drive.google.com/file/d/" + f.ID + "/view"
+	}
+	version := f.Version
+	if version == "" {
+		version = f.ModifiedTime
+	}
+	return PageRef{ID: f.ID, Title: f.Name, URL: link, Version: version}
+}
@@ -0,0 +1,126 @@
+package connectors
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeServiceAccountKey writes a service account key file using tokenURL
+func writeServiceAccountKey(t *testing.T, tokenURL string) string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	privateKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	data, err := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"client_email":   "sync@project.iam.gserviceaccount.com",
+		"private_key":    string(privateKey),
+		"private_key_id": "key-1",
+		"token_uri":      tokenURL,
+	})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	require.NoError(t, os.WriteFile(path, data, 0600))
+	return path
+}
+
+func TestGoogleDriveListChangesAndContent(t *testing.T) {
+	changes := `{"changes":[],"newStartPageToken":"11"}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			require.NoError(t, r.ParseForm())
+			assert.Equal(t, "urn:ietf:params:oauth:grant-type:jwt-bearer", r.Form.Get("grant_type"))
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprint(w, `{"access_token":"drive-token","token_type":"Bearer","expires_in":3600}`)
+			return
+		}
+		assert.Equal(t, "Bearer drive-token", r.Header.Get("Authorization"))
+
+		switch r.URL.Path {
+		case "/drive/v3/changes/startPageToken":
+			_, _ = fmt.Fprint(w, `{"startPageToken":"10"}`)
+		case "/drive/v3/files":
+			switch r.URL.Query().Get("q") {
+			case "'root-folder' in parents and trashed = false":
+				_, _ = fmt.Fprint(w, `{"files":[
+					{"id":"doc","name":"Runbook","mimeType":"application/vnd.google-apps.document","version":"5","webViewLink":"https://docs.google.com/document/d/doc","parents":["root-folder"]},
+					{"id":"img","name":"Diagram","mimeType":"image/png","version":"1","parents":["root-folder"]},
+					{"id":"sub","name":"Archive","mimeType":"application/vnd.google-apps.folder","parents":["root-folder"]}]}`)
+			case "'sub' in parents and trashed = false":
+				_, _ = fmt.Fprint(w, `{"files":[{"id":"notes","name":"notes.md","mimeType":"text/markdown","version":"2","parents":["sub"]}]}`)
+			default:
+				_, _ = fmt.Fprint(w, `{"files":[]}`)
+			}
+		case "/drive/v3/changes":
+			assert.Equal(t, "10", r.URL.Query().Get("pageToken"))
+			_, _ = fmt.Fprint(w, changes)
+		case "/drive/v3/files/doc/export":
+			assert.Equal(t, "text/plain", r.URL.Query().Get("mimeType"))
+			_, _ = fmt.Fprint(w, "\ufeffRoll back with argocd.\r\n")
+		case "/drive/v3/files/notes":
+			assert.Equal(t, "media", r.URL.Query().Get("alt"))
+			_, _ = fmt.Fprint(w, "# Notes")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	httpClient, err := GoogleServiceAccountClient(writeServiceAccountKey(t, server.URL+"/token"), "", server.Client())
+	require.NoError(t, err)
+	drive := NewGoogleDrive(server.URL+"/drive/v3", []string{"root-folder"}, httpClient)
+	ctx := context.Background()
+
+	pages, err := drive.List(ctx)
+	require.NoError(t, err)
+	sortPages(pages)
+	require.Len(t, pages, 2)
+	assert.Equal(t, PageRef{ID: "doc", Title: "Runbook", URL: "https://docs.google.com/document/d/doc", Version: "5"}, pages[0])
+	assert.Equal(t, "https://drive.google.com/file/d/notes/view", pages[1].URL)
+
+	text, err := drive.Content(ctx, pages[0])
+	require.NoError(t, err)
+	assert.Equal(t, "Roll back with argocd.", text)
+	text, err = drive.Content(ctx, pages[1])
+	require.NoError(t, err)
+	assert.Equal(t, "# Notes", text)
+
+	// Later listings apply changes: an edit, and the subfolder moving out of the tree
+	changes = `{"changes":[
+		{"fileId":"doc","file":{"id":"doc","name":"Runbook v2","mimeType":"application/vnd.google-apps.document","version":"6","parents":["root-folder"]}},
+		{"fileId":"sub","file":{"id":"sub","name":"Archive","mimeType":"application/vnd.google-apps.folder","parents":["elsewhere"]}}],
+		"newStartPageToken":"11"}`
+	pages, err = drive.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, pages, 1)
+	assert.Equal(t, "Runbook v2", pages[0].Title)
+	assert.Equal(t, "6", pages[0].Version)
+	assert.Equal(t, "11", drive.pageToken)
+
+	_, err = drive.Content(ctx, PageRef{ID: "notes"})
+	assert.ErrorContains(t, err, "not in a synced folder")
+}
+
+func TestGoogleServiceAccountClientRejectsOtherCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"type":"authorized_user"}`), 0600))
+	_, err := GoogleServiceAccountClient(path, "", http.DefaultClient)
+	assert.ErrorContains(t, err, "not a service account key")
+}
+
+func sortPages(pages []PageRef) {
+	sort.Slice(pages, func(i, j int) bool { return pages[i].ID < pages[j].ID })
+}
@@ -0,0 +1,216 @@
+package connectors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	notionAPIURL  = "https://api.notion.com/v1"
+	notionVersion = "2022-06-28"
+
+	// notionMaxBlockDepth bounds how deeply nested blocks (toggles, lists) are read
+	notionMaxBlockDepth = 3
+)
+
+// Notion lists and reads pages through the Notion API, using an internal
+// integration token. Only pages shared with the integration are visible.
+type Notion struct {
+	apiURL      string
+	token       string
+	databaseIDs []string
+	httpClient  *http.Client
+}
+
+// NewNotion creates a connector for the pages in the given databases, or for every
+// page shared with the integration when databaseIDs is empty
+func NewNotion(apiURL, token string, databaseIDs []string, httpClient *http.Client) *Notion {
+	return &Notion{
+		apiURL:      strings.TrimSuffix(apiURL, "/"),
+		token:       token,
+		databaseIDs: databaseIDs,
+		httpClient:  httpClient,
+	}
+}
+
+type notionRichText struct {
+	PlainText string `json:"plain_text"`
+}
+
+type notionPage struct {
+	ID             string `json:"id"`
+	URL            string `json:"url"`
+	LastEditedTime string `json:"last_edited_time"`
+	Properties     map[string]struct {
+		Type  string           `json:"type"`
+		Title []notionRichText `json:"title"`
+	} `json:"properties"`
+}
+
+type notionPageList struct {
+	Results    []notionPage `json:"results"`
+	HasMore    bool         `json:"has_more"`
+	NextCursor string       `json:"next_cursor"`
+}
+
+type notionBlock struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	HasChildren bool   `json:"has_children"`
+	raw         map[string]json.RawMessage
+}
+
+type notionBlockList struct {
+	Results    []json.RawMessage `json:"results"`
+	HasMore    bool              `json:"has_more"`
+	NextCursor string            `json:"next_cursor"`
+}
+
+// List implements Connector
+func (n *Notion) List(ctx context.Context) ([]PageRef, error) {
+	if len(n.databaseIDs) == 0 {
+		body := map[string]interface{}{"filter": map[string]string{"property": "object", "value": "page"}}
+		return n.listPages(ctx, "/search", body)
+	}
+	var pages []PageRef
+	for _, databaseID := range n.databaseIDs {
+		databasePages, err := n.listPages(ctx, "/databases/"+url.PathEscape(databaseID)+"/query", map[string]interface{}{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query database %s: %w", databaseID, err)
+		}
+		pages = append(pages, databasePages...)
+	}
+	return pages, nil
+}
+
+// listPages follows the cursor of a search or database query
+func (n *Notion) listPages(ctx context.Context, path string, body map[string]interface{}) ([]PageRef, error) {
+	var pages []PageRef
+	body["page_size"] = 100
+	for {
+		var list notionPageList
+		if err := n.do(ctx, http.MethodPost, path, body, &list); err != nil {
+			return nil, err
+		}
+		for _, page := range list.Results {
+			pages = append(pages, PageRef{
+				ID:      page.ID,
+				Title:   page.title(),
+				URL:     page.URL,
+				Version: page.LastEditedTime,
+			})
+		}
+		if !list.HasMore || list.NextCursor == "" {
+			return pages, nil
+		}
+		body["start_cursor"] = list.NextCursor
+	}
+}
+
+// Content implements Connector
+func (n *Notion) Content(ctx context.Context, page PageRef) (string, error) {
+	var text strings.Builder
+	if err := n.appendBlocks(ctx, &text, page.ID, 0); err != nil {
+		return "", fmt.Errorf("failed to read page %s: %w", page.ID, err)
+	}
+	return strings.TrimSpace(text.String()), nil
+}
+
+// appendBlocks writes the text of a block's children, one block per line
+func (n *Notion) appendBlocks(ctx context.Context, text *strings.Builder, blockID string, depth int) error {
+	cursor := ""
+	for {
+		path := "/blocks/" + url.PathEscape(blockID) + "/children?page_size=100"
+		if cursor != "" {
+			path += "&start_cursor=" + url.QueryEscape(cursor)
+		}
+		var list notionBlockList
+		if err := n.do(ctx, http.MethodGet, path, nil, &list); err != nil {
+			return err
+		}
+		for _, raw := range list.Results {
+			var block notionBlock
+			if err := json.Unmarshal(raw, &block); err != nil {
+				return fmt.Errorf("failed to decode block: %w", err)
+			}
+			if err := json.Unmarshal(raw, &block.raw); err != nil {
+				return fmt.Errorf("failed to decode block: %w", err)
+			}
+			// Child pages and databases are synced as pages of their own
+			if block.Type == "child_page" || block.Type == "child_database" {
+				continue
+			}
+			if line := block.text(); line != "" {
+				text.WriteString(strings.Repeat("  ", depth) + line + "\n")
+			}
+			if block.HasChildren && depth+1 < notionMaxBlockDepth {
+				if err := n.appendBlocks(ctx, text, block.ID, depth+1); err != nil {
+					return err
+				}
+			}
+		}
+		if !list.HasMore || list.NextCursor == "" {
+			return nil
+		}
+		cursor = list.NextCursor
+	}
+}
+
+// text returns the plain text of a block's rich text, if its type has any
+func (b notionBlock) text() string {
+	var content struct {
+		RichText []notionRichText `json:"rich_text"`
+	}
+	if err := json.Unmarshal(b.raw[b.Type], &content); err != nil {
+		return ""
+	}
+	var text strings.Builder
+	for _, part := range content.RichText {
+		text.WriteString(part.PlainText)
+	}
+	return strings.TrimSpace(text.String())
+}
+
+// title returns the page's title property
+func (p notionPage) title() string {
+	for _, property := range p.Properties {
+		if property.Type != "title" {
+			continue
+		}
+		var title strings.Builder
+		for _, part := range property.Title {
+			title.WriteString(part.PlainText)
+		}
+		if title.Len() > 0 {
+			return title.String()
+		}
+	}
+	return "Untitled"
+}
+
+// do performs an authenticated Notion API request and decodes the JSON response
+func (n *Notion) do(ctx context.Context, method, path string, body interface{}, result interface{}) error {
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, n.apiURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+n.token)
+	req.Header.Set("Notion-Version", notionVersion)
+	req.Header.Set("Content-Type", "application/json")
+	return doJSON(n.httpClient, req, result)
+}
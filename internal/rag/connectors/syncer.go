@@ -0,0 +1,198 @@
+package connectors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/rag"
+)
+
+// connectorMetadataKey is the chunk metadata key naming the source a page was synced from
+const connectorMetadataKey = "connector"
+
+// errEmptyPage is returned for pages without text, which are not stored
+var errEmptyPage = errors.New("page has no text")
+
+// Store is the part of a RAG provider used by the syncer
+type Store interface {
+	rag.DocumentIngester
+	rag.DocumentVersioner
+}
+
+// source is a configured connector and its schedule
+type source struct {
+	name      string
+	namespace string
+	interval  time.Duration
+	connector Connector
+}
+
+// SyncStats summarizes one sync of a source
+type SyncStats struct {
+	Updated   int
+	Unchanged int
+	Deleted   int
+	Empty     int
+	Failed    int
+}
+
+// Syncer periodically copies pages from the configured sources into the knowledge
+// base. Pages whose version has not changed since the last sync are skipped, and
+// pages that no longer exist at the source are removed.
+type Syncer struct {
+	store    Store
+	provider string // Names the provider in the metrics
+	sources  []source
+	logger   *logging.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewSyncer creates a syncer for the configured sources. Providers that cannot
+// store documents directly (such as openai) are fed through file uploads.
+func NewSyncer(sources []config.RAGSourceConfig, provider rag.VectorProvider, logger *logging.Logger) (*Syncer, error) {
+	store, ok := provider.(Store)
+	if !ok {
+		store = newFileStore(provider)
+	}
+	s := &Syncer{store: store, provider: rag.ProviderLabel(provider), logger: logger.WithName("rag-sync")}
+	for _, cfg := range sources {
+		connector, err := New(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("rag source '%s': %w", cfg.Name, err)
+		}
+		interval, err := time.ParseDuration(cfg.Interval)
+		if err != nil {
+			return nil, fmt.Errorf("rag source '%s': invalid interval: %w", cfg.Name, err)
+		}
+		s.sources = append(s.sources, source{name: cfg.Name, namespace: cfg.Namespace, interval: interval, connector: connector})
+	}
+	return s, nil
+}
+
+// Start syncs every source now and then on its interval, until Close is called
+func (s *Syncer) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	for _, src := range s.sources {
+		s.wg.Add(1)
+		go func(src source) {
+			defer s.wg.Done()
+			ticker := time.NewTicker(src.interval)
+			defer ticker.Stop()
+			for {
+				s.syncAndLog(ctx, src)
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}(src)
+	}
+}
+
+// Close stops the scheduled syncs and waits for running ones to finish
+func (s *Syncer) Close() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+// syncAndLog syncs a source and logs the outcome
+func (s *Syncer) syncAndLog(ctx context.Context, src source) {
+	started := time.Now()
+	stats, err := s.sync(ctx, src)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.ErrorKV("Failed to sync knowledge base source", "source", src.name, "error", err)
+		}
+		return
+	}
+	s.logger.InfoKV("Synced knowledge base source", "source", src.name, "updated", stats.Updated,
+		"unchanged", stats.Unchanged, "deleted", stats.Deleted, "empty", stats.Empty, "failed", stats.Failed, "duration", time.Since(started))
+}
+
+// sync copies new and changed pages of a source into the store and removes pages
+// that are gone. A page that fails to load is skipped and retried next time.
+func (s *Syncer) sync(ctx context.Context, src source) (SyncStats, error) {
+	var stats SyncStats
+	pages, err := src.connector.List(ctx)
+	if err != nil {
+		return stats, err
+	}
+	stored, err := s.store.DocumentVersions(ctx, map[string]string{connectorMetadataKey: src.name})
+	if err != nil {
+		return stats, err
+	}
+
+	current := make(map[string]bool, len(pages))
+	for _, page := range pages {
+		current[page.URL] = true
+		if version, ok := stored[page.URL]; ok && version == page.Version {
+			rag.RecordIngestion(s.provider, rag.IngestSourceConnector, rag.IngestOutcomeUnchanged, 0)
+			stats.Unchanged++
+			continue
+		}
+		if err := s.ingest(ctx, src, page); err != nil {
+			if errors.Is(err, errEmptyPage) {
+				// Treat a page that was emptied like a deleted one
+				current[page.URL] = false
+				stats.Empty++
+				continue
+			}
+			if ctx.Err() != nil {
+				return stats, ctx.Err()
+			}
+			s.logger.WarnKV("Failed to sync page", "source", src.name, "page", page.URL, "error", err)
+			stats.Failed++
+			continue
+		}
+		stats.Updated++
+	}
+
+	for pageURL := range stored {
+		if current[pageURL] {
+			continue
+		}
+		if err := s.store.DeleteDocument(ctx, pageURL, src.namespace); err != nil {
+			s.logger.WarnKV("Failed to remove deleted page", "source", src.name, "page", pageURL, "error", err)
+			continue
+		}
+		stats.Deleted++
+	}
+	return stats, nil
+}
+
+// ingest fetches a page and stores it with its version
+func (s *Syncer) ingest(ctx context.Context, src source, page PageRef) error {
+	text, err := src.connector.Content(ctx, page)
+	if err != nil {
+		return err
+	}
+	if text == "" {
+		return errEmptyPage
+	}
+	metadata := map[string]string{
+		"url":                page.URL,
+		"title":              page.Title,
+		"version":            page.Version,
+		connectorMetadataKey: src.name,
+	}
+	if src.namespace != "" {
+		metadata[rag.NamespaceMetadataKey] = src.namespace
+	}
+	if _, err := s.store.IngestDocument(ctx, page.URL, page.Title, text, metadata); err != nil {
+		rag.RecordIngestion(s.provider, rag.IngestSourceConnector, rag.IngestOutcomeError, 0)
+		return err
+	}
+	rag.RecordIngestion(s.provider, rag.IngestSourceConnector, rag.IngestOutcomeIngested, len(text))
+	return nil
+}
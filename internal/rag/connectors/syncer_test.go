@@ -0,0 +1,159 @@
+package connectors
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/rag"
+)
+
+// fakeConnector serves pages from memory and counts content fetches
+type fakeConnector struct {
+	pages   []PageRef
+	content map[string]string
+	fetched int
+}
+
+func (f *fakeConnector) List(context.Context) ([]PageRef, error) {
+	return f.pages, nil
+}
+
+func (f *fakeConnector) Content(_ context.Context, page PageRef) (string, error) {
+	f.fetched++
+	return f.content[page.ID], nil
+}
+
+func TestSyncerTracksVersions(t *testing.T) {
+	provider, err := rag.NewSQLiteProvider(filepath.Join(t.TempDir(), "knowledge.db"))
+	require.NoError(t, err)
+	defer func() { _ = provider.Close() }()
+	ctx := context.Background()
+
+	connector := &fakeConnector{
+		pages: []PageRef{
+			{ID: "1", Title: "Runbook", URL: "https://wiki.example.com/1", Version: "1"},
+			{ID: "2", Title: "Oncall", URL: "https://wiki.example.com/2", Version: "1"},
+			{ID: "3", Title: "Empty", URL: "https://wiki.example.com/3", Version: "1"},
+		},
+		content: map[string]string{"1": "Rollback with argocd.", "2": "Page the primary on-call."},
+	}
+	syncer := &Syncer{store: provider, logger: logging.New("test", logging.LevelError)}
+	src := source{name: "wiki", namespace: "platform-docs", connector: connector}
+
+	stats, err := syncer.sync(ctx, src)
+	require.NoError(t, err)
+	assert.Equal(t, SyncStats{Updated: 2, Empty: 1}, stats)
+
+	results, err := provider.Search(ctx, "argocd", rag.SearchOptions{Metadata: map[string]string{rag.NamespaceMetadataKey: "platform-docs"}})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Runbook", results[0].FileName)
+	assert.Equal(t, "https://wiki.example.com/1", results[0].Metadata["url"])
+
+	// Unchanged pages are not fetched again
+	connector.fetched = 0
+	stats, err = syncer.sync(ctx, src)
+	require.NoError(t, err)
+	assert.Equal(t, SyncStats{Unchanged: 2, Empty: 1}, stats)
+	assert.Equal(t, 1, connector.fetched)
+
+	// Edited pages are re-ingested and removed pages are deleted
+	connector.pages = []PageRef{{ID: "1", Title: "Runbook", URL: "https://wiki.example.com/1", Version: "2"}}
+	connector.content["1"] = "Rollback with helm."
+	stats, err = syncer.sync(ctx, src)
+	require.NoError(t, err)
+	assert.Equal(t, SyncStats{Updated: 1, Deleted: 1}, stats)
+
+	versions, err := provider.DocumentVersions(ctx, map[string]string{connectorMetadataKey: "wiki"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"https://wiki.example.com/1": "2"}, versions)
+
+	results, err = provider.Search(ctx, "argocd", rag.SearchOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+// fileProvider is a provider that can only store uploaded files, like openai
+type fileProvider struct {
+	rag.VectorProvider
+	files  map[string]rag.FileInfo
+	texts  map[string]string
+	nextID int
+}
+
+func (f *fileProvider) IngestFile(_ context.Context, filePath string, _ map[string]string) (string, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", err
+	}
+	f.nextID++
+	id := fmt.Sprintf("file-%d", f.nextID)
+	f.files[id] = rag.FileInfo{ID: id, Name: filepath.Base(filePath)}
+	f.texts[id] = string(data)
+	return id, nil
+}
+
+func (f *fileProvider) DeleteFile(_ context.Context, fileID string) error {
+	delete(f.files, fileID)
+	return nil
+}
+
+func (f *fileProvider) ListFiles(context.Context, int) ([]rag.FileInfo, error) {
+	var files []rag.FileInfo
+	for _, file := range f.files {
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+func TestSyncerFeedsFileOnlyProviders(t *testing.T) {
+	provider := &fileProvider{files: map[string]rag.FileInfo{}, texts: map[string]string{}}
+	syncer, err := NewSyncer(nil, provider, logging.New("test", logging.LevelError))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	connector := &fakeConnector{
+		pages:   []PageRef{{ID: "1", Title: "Runbook: prod", URL: "https://drive.example.com/1", Version: "1"}},
+		content: map[string]string{"1": "Rollback with argocd."},
+	}
+	src := source{name: "drive", connector: connector}
+	stats, err := syncer.sync(ctx, src)
+	require.NoError(t, err)
+	assert.Equal(t, SyncStats{Updated: 1}, stats)
+	require.Len(t, provider.files, 1)
+	assert.Equal(t, "Runbook_ prod ["+sourceTag("https://drive.example.com/1")+"].txt", provider.files["file-1"].Name)
+	assert.Contains(t, provider.texts["file-1"], "Source: https://drive.example.com/1")
+
+	// After a restart the previous upload is found by name and replaced
+	syncer, err = NewSyncer(nil, provider, logging.New("test", logging.LevelError))
+	require.NoError(t, err)
+	stats, err = syncer.sync(ctx, src)
+	require.NoError(t, err)
+	assert.Equal(t, SyncStats{Updated: 1}, stats)
+	assert.Equal(t, []string{"file-2"}, fileIDs(provider))
+
+	stats, err = syncer.sync(ctx, src)
+	require.NoError(t, err)
+	assert.Equal(t, SyncStats{Unchanged: 1}, stats)
+
+	connector.pages = nil
+	stats, err = syncer.sync(ctx, src)
+	require.NoError(t, err)
+	assert.Equal(t, SyncStats{Deleted: 1}, stats)
+	assert.Empty(t, provider.files)
+}
+
+func fileIDs(provider *fileProvider) []string {
+	var ids []string
+	for id := range provider.files {
+		ids = append(ids, id)
+	}
+	return ids
+}
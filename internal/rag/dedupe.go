@@ -0,0 +1,54 @@
+package rag
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Metadata keys recorded with ingested documents
+const (
+	// ContentHashMetadataKey holds the SHA-256 of the document's content
+	ContentHashMetadataKey = "content_hash"
+	// ChunkingMetadataKey holds the chunking options the document was split with
+	ChunkingMetadataKey = "chunking"
+	// IngestedAtMetadataKey holds when the chunk was ingested, in RFC 3339 format
+	IngestedAtMetadataKey = "ingested_at"
+)
+
+// DedupeResult reports what Dedupe removed
+type DedupeResult struct {
+	Documents int // Documents whose content duplicated a more recently ingested document
+	Chunks    int // Chunks whose text repeated a chunk of another document
+}
+
+// ContentHash returns the hex SHA-256 of content
+func ContentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// chunkHash identifies a chunk's text within a namespace, ignoring surrounding whitespace
+func chunkHash(namespace, content string) string {
+	return namespace + ":" + ContentHash([]byte(strings.TrimSpace(content)))
+}
+
+// fingerprint summarizes the options that change how a document is split, so a
+// document is re-chunked when they change
+func (o ChunkingOptions) fingerprint() string {
+	o = o.WithDefaults()
+	fingerprint := fmt.Sprintf("%s/%d/%d/%s", o.Strategy, o.Size, o.Overlap, o.Unit)
+	if o.Strategy == ChunkStrategySemantic {
+		fingerprint += fmt.Sprintf("/%g", o.BreakpointPercentile)
+	}
+	return fingerprint
+}
+
+// unchanged reports whether a stored document has the same content and chunking as
+// the metadata about to be stored
+func unchanged(stored, metadata map[string]string) bool {
+	return stored != nil && stored[ContentHashMetadataKey] != "" &&
+		stored[ContentHashMetadataKey] == metadata[ContentHashMetadataKey] &&
+		stored[ChunkingMetadataKey] == metadata[ChunkingMetadataKey]
+}
@@ -0,0 +1,131 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writePDF writes a single-page PDF showing text
+func writePDF(t *testing.T, path, text string) {
+	t.Helper()
+	stream := fmt.Sprintf("BT /F1 12 Tf 72 720 Td (%s) Tj ET", text)
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(stream), stream),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	}
+	var pdf strings.Builder
+	pdf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = pdf.Len()
+		fmt.Fprintf(&pdf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := pdf.Len()
+	fmt.Fprintf(&pdf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&pdf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&pdf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	require.NoError(t, os.WriteFile(path, []byte(pdf.String()), 0644))
+}
+
+func TestIngestDirectorySkipsUnchangedFiles(t *testing.T) {
+	for _, provider := range []string{"simple", "json"} {
+		t.Run(provider, func(t *testing.T) {
+			docs := t.TempDir()
+			writePDF(t, filepath.Join(docs, "handbook.pdf"), "Employees receive 20 days of paid vacation.")
+			require.NoError(t, os.MkdirAll(filepath.Join(docs, "sre"), 0755))
+			writePDF(t, filepath.Join(docs, "sre", "runbook.pdf"), "Deployments are frozen in December.")
+			require.NoError(t, os.WriteFile(filepath.Join(docs, "notes.txt"), []byte("ignored"), 0644))
+
+			client, err := NewClientWithProvider(provider, map[string]interface{}{
+				"database_path": filepath.Join(t.TempDir(), "knowledge.db"),
+			})
+			require.NoError(t, err)
+			defer func() { _ = client.Close() }()
+			ctx := context.Background()
+			ingest := func() string {
+				output, err := client.CallTool(ctx, "rag_ingest", map[string]interface{}{"file_path": docs})
+				require.NoError(t, err)
+				return output
+			}
+
+			assert.Contains(t, ingest(), "Ingested 2 of 2 file(s)")
+			assert.Contains(t, ingest(), "Ingested 0 of 2 file(s)")
+
+			// A changed file is re-ingested in place
+			writePDF(t, filepath.Join(docs, "handbook.pdf"), "Employees receive 25 days of paid vacation.")
+			assert.Contains(t, ingest(), "Ingested 1 of 2 file(s)")
+			stats, err := client.GetProvider().GetStats(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, 2, stats.TotalFiles)
+
+			// Different chunking re-ingests every file
+			require.NoError(t, client.SetChunking(ChunkingOptions{Strategy: ChunkStrategySentence}))
+			assert.Contains(t, ingest(), "Ingested 2 of 2 file(s)")
+		})
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	for _, provider := range []string{"simple", "json"} {
+		t.Run(provider, func(t *testing.T) {
+			client, err := NewClientWithProvider(provider, map[string]interface{}{
+				"database_path": filepath.Join(t.TempDir(), "knowledge.db"),
+			})
+			require.NoError(t, err)
+			defer func() { _ = client.Close() }()
+			ingester := client.GetProvider().(DocumentIngester)
+			ctx := context.Background()
+
+			store := func(source, text, namespace string) {
+				metadata := client.withContentHash(map[string]string{}, []byte(text))
+				if namespace != "" {
+					metadata[NamespaceMetadataKey] = namespace
+				}
+				_, err := ingester.IngestDocument(ctx, source, source, text, metadata)
+				require.NoError(t, err)
+			}
+			store("docs/handbook.pdf", "Employees receive 20 days of paid vacation.", "")
+			store("/abs/docs/handbook.pdf", "Employees receive 20 days of paid vacation.", "")
+			store("hr/handbook.pdf", "Employees receive 20 days of paid vacation.", "hr")
+			// Chunks stored before content hashes were recorded
+			_, err = ingester.IngestDocument(ctx, "old/runbook.pdf", "runbook.pdf", "Deployments are frozen in December.", map[string]string{})
+			require.NoError(t, err)
+			_, err = ingester.IngestDocument(ctx, "runbook.pdf", "runbook.pdf", "Deployments are frozen in December.", map[string]string{})
+			require.NoError(t, err)
+
+			result, err := client.Dedupe(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, DedupeResult{Documents: 1, Chunks: 1}, result)
+
+			// The most recently ingested copy is kept, and the namespaced copy is separate
+			files, err := client.GetProvider().ListFiles(ctx, 0)
+			require.NoError(t, err)
+			var sources []string
+			for _, file := range files {
+				sources = append(sources, file.Metadata["file_path"])
+			}
+			assert.Len(t, sources, 3)
+			results, err := client.GetProvider().Search(ctx, "vacation", SearchOptions{})
+			require.NoError(t, err)
+			for _, result := range results {
+				assert.NotEqual(t, "docs/handbook.pdf", result.Metadata["file_path"])
+			}
+
+			result, err = client.Dedupe(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, DedupeResult{}, result)
+		})
+	}
+}
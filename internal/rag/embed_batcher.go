@@ -0,0 +1,164 @@
+package rag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tmc/langchaingo/embeddings"
+)
+
+// embedBatchWait is how long a batch waits for the chunks of documents ingested
+// alongside before it is sent
+const embedBatchWait = 20 * time.Millisecond
+
+// errEmbedBatcherClosed is returned for chunks embedded after the provider closed
+var errEmbedBatcherClosed = errors.New("embedding batcher is closed")
+
+// embedBatcher merges the embedding requests of documents ingested in parallel into
+// requests of up to size chunks, so a corpus of small documents takes a few large
+// API calls rather than one per document
+type embedBatcher struct {
+	embedder embeddings.Embedder
+	size     int
+	requests chan *embedRequest
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// embedRequest is the chunks of one document waiting for their vectors
+type embedRequest struct {
+	ctx     context.Context
+	texts   []string
+	vectors [][]float32
+	err     error
+	done    chan struct{}
+}
+
+// newEmbedBatcher starts a batcher sending up to size chunks per request
+func newEmbedBatcher(embedder embeddings.Embedder, size int) *embedBatcher {
+	b := &embedBatcher{embedder: embedder, size: size, requests: make(chan *embedRequest), stop: make(chan struct{})}
+	go b.run()
+	return b
+}
+
+// embed returns the vectors of texts, embedded with the texts of concurrent calls
+func (b *embedBatcher) embed(ctx context.Context, texts []string) ([][]float32, error) {
+	select {
+	case <-b.stop:
+		return nil, errEmbedBatcherClosed
+	default:
+	}
+	request := &embedRequest{ctx: ctx, texts: texts, done: make(chan struct{})}
+	select {
+	case b.requests <- request:
+	case <-b.stop:
+		return nil, errEmbedBatcherClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	select {
+	case <-request.done:
+		return request.vectors, request.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// close stops the batcher; batches already sent complete
+func (b *embedBatcher) close() {
+	b.stopOnce.Do(func() { close(b.stop) })
+}
+
+// run gathers requests into batches and sends each batch in its own goroutine, so
+// a slow API call does not hold up the next batch
+func (b *embedBatcher) run() {
+	for {
+		var batch []*embedRequest
+		select {
+		case <-b.stop:
+			return
+		case request := <-b.requests:
+			batch = append(batch, request)
+		}
+		texts := len(batch[0].texts)
+		wait := time.NewTimer(embedBatchWait)
+	gather:
+		for texts < b.size {
+			select {
+			case request := <-b.requests:
+				batch = append(batch, request)
+				texts += len(request.texts)
+			case <-wait.C:
+				break gather
+			case <-b.stop:
+				break gather
+			}
+		}
+		wait.Stop()
+		go b.send(batch)
+	}
+}
+
+// send embeds the texts of a batch, size chunks per API call, and hands each
+// request its vectors. A failed call fails every request of the batch.
+func (b *embedBatcher) send(batch []*embedRequest) {
+	ctx, cancel := batchContext(batch)
+	defer cancel()
+
+	var texts []string
+	for _, request := range batch {
+		texts = append(texts, request.texts...)
+	}
+	var vectors [][]float32
+	var err error
+	for start := 0; start < len(texts) && err == nil; start += b.size {
+		part := texts[start:min(start+b.size, len(texts))]
+		var embedded [][]float32
+		embedded, err = b.embedder.EmbedDocuments(ctx, part)
+		if err == nil && len(embedded) != len(part) {
+			err = fmt.Errorf("embedder returned %d vectors for %d chunks", len(embedded), len(part))
+		}
+		vectors = append(vectors, embedded...)
+	}
+
+	offset := 0
+	for _, request := range batch {
+		if err != nil {
+			request.err = err
+		} else {
+			request.vectors = vectors[offset : offset+len(request.texts)]
+		}
+		offset += len(request.texts)
+		close(request.done)
+	}
+}
+
+// batchContext returns the context of the API calls of a batch. It carries the
+// values of the first request, and is cancelled only once every request of the
+// batch is, so an ingest that is stopped does not fail the documents batched with it.
+func batchContext(batch []*embedRequest) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(batch[0].ctx))
+	var mu sync.Mutex
+	remaining := len(batch)
+	stops := make([]func() bool, 0, len(batch))
+	for _, request := range batch {
+		stops = append(stops, context.AfterFunc(request.ctx, func() {
+			mu.Lock()
+			remaining--
+			last := remaining == 0
+			mu.Unlock()
+			if last {
+				cancel()
+			}
+		}))
+	}
+	return ctx, func() {
+		for _, stop := range stops {
+			stop()
+		}
+		cancel()
+	}
+}
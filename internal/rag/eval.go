@@ -0,0 +1,192 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultEvalK is the number of results scored per question when the eval set does
+// not set k
+const DefaultEvalK = 5
+
+// EvalSet is a set of questions with the sources that should answer them, loaded
+// from YAML:
+//
+//	k: 5
+//	cases:
+//	  - question: How many vacation days do employees get?
+//	    expectedSources: [handbook.pdf]
+//	    namespace: hr-policies
+type EvalSet struct {
+	K     int        `yaml:"k"`
+	Cases []EvalCase `yaml:"cases"`
+}
+
+// EvalCase is a single question. A retrieved chunk matches an expected source when
+// its file name, path or URL equals it, or its path ends with it.
+type EvalCase struct {
+	Question        string   `yaml:"question"`
+	ExpectedSources []string `yaml:"expectedSources"`
+	Namespace       string   `yaml:"namespace"`
+}
+
+// EvalCaseResult is the outcome of one question
+type EvalCaseResult struct {
+	Question string
+	Rank     int     // Rank of the first relevant result within k, 0 when none
+	Recall   float64 // Fraction of the expected sources found within k
+	Latency  time.Duration
+	Sources  []string // Sources of the top k results
+	Err      error
+}
+
+// EvalReport summarizes a run over an eval set
+type EvalReport struct {
+	K           int
+	Cases       []EvalCaseResult
+	RecallAtK   float64
+	MRR         float64
+	MeanLatency time.Duration
+	P95Latency  time.Duration
+}
+
+// LoadEvalSet reads and validates an eval set
+func LoadEvalSet(path string) (*EvalSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read eval set: %w", err)
+	}
+	var set EvalSet
+	if err := yaml.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse eval set: %w", err)
+	}
+	if len(set.Cases) == 0 {
+		return nil, fmt.Errorf("eval set %s has no cases", path)
+	}
+	for i, evalCase := range set.Cases {
+		if strings.TrimSpace(evalCase.Question) == "" {
+			return nil, fmt.Errorf("eval case %d: question is required", i)
+		}
+		if len(evalCase.ExpectedSources) == 0 {
+			return nil, fmt.Errorf("eval case %d: expectedSources is required", i)
+		}
+	}
+	if set.K < 0 {
+		return nil, fmt.Errorf("eval set k must be positive")
+	}
+	return &set, nil
+}
+
+// Evaluate runs each question through retrieval, including reranking, and scores
+// the top k results. k overrides the eval set's k when positive.
+func (c *Client) Evaluate(ctx context.Context, set *EvalSet, k int) *EvalReport {
+	if k <= 0 {
+		k = set.K
+	}
+	if k <= 0 {
+		k = DefaultEvalK
+	}
+
+	report := &EvalReport{K: k}
+	var latencies []time.Duration
+	for _, evalCase := range set.Cases {
+		result := EvalCaseResult{Question: evalCase.Question}
+		start := time.Now()
+		results, err := c.retrieve(WithNamespace(ctx, evalCase.Namespace), evalCase.Question, k, SearchFilter{})
+		result.Latency = time.Since(start)
+		latencies = append(latencies, result.Latency)
+		if err != nil {
+			result.Err = err
+			report.Cases = append(report.Cases, result)
+			continue
+		}
+		if len(results) > k {
+			results = results[:k]
+		}
+
+		found := make(map[string]bool)
+		for i, searchResult := range results {
+			result.Sources = append(result.Sources, resultSource(searchResult))
+			for _, expected := range evalCase.ExpectedSources {
+				if sourceMatches(searchResult, expected) {
+					found[expected] = true
+					if result.Rank == 0 {
+						result.Rank = i + 1
+					}
+				}
+			}
+		}
+		result.Recall = float64(len(found)) / float64(len(evalCase.ExpectedSources))
+		if result.Rank > 0 {
+			report.MRR += 1 / float64(result.Rank)
+		}
+		report.RecallAtK += result.Recall
+		report.Cases = append(report.Cases, result)
+	}
+
+	n := float64(len(report.Cases))
+	report.RecallAtK /= n
+	report.MRR /= n
+	var total time.Duration
+	for _, latency := range latencies {
+		total += latency
+	}
+	report.MeanLatency = total / time.Duration(len(latencies))
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	report.P95Latency = latencies[(len(latencies)*95+99)/100-1]
+	return report
+}
+
+// String renders the report with one line per question followed by the summary
+func (r *EvalReport) String() string {
+	var out strings.Builder
+	for _, result := range r.Cases {
+		switch {
+		case result.Err != nil:
+			out.WriteString(fmt.Sprintf("ERROR  %8s  %s (%v)\n", formatLatency(result.Latency), result.Question, result.Err))
+		case result.Rank == 0:
+			out.WriteString(fmt.Sprintf("MISS   %8s  %s (got: %s)\n", formatLatency(result.Latency), result.Question, strings.Join(result.Sources, ", ")))
+		default:
+			out.WriteString(fmt.Sprintf("HIT %-2d %8s  %s\n", result.Rank, formatLatency(result.Latency), result.Question))
+		}
+	}
+	out.WriteString(fmt.Sprintf("\nQuestions: %d\nRecall@%d: %.3f\nMRR@%d: %.3f\nLatency: mean %s, p95 %s\n",
+		len(r.Cases), r.K, r.RecallAtK, r.K, r.MRR, formatLatency(r.MeanLatency), formatLatency(r.P95Latency)))
+	return out.String()
+}
+
+// formatLatency rounds a latency for display
+func formatLatency(latency time.Duration) string {
+	return latency.Round(100 * time.Microsecond).String()
+}
+
+// resultSource names the source of a result for display
+func resultSource(result SearchResult) string {
+	if url := result.Metadata["url"]; url != "" {
+		return url
+	}
+	if result.FileName != "" {
+		return result.FileName
+	}
+	return result.FileID
+}
+
+// sourceMatches reports whether a result comes from the expected source
+func sourceMatches(result SearchResult, expected string) bool {
+	candidates := []string{result.FileName, result.FileID, result.Metadata["file_name"], result.Metadata["url"]}
+	for _, candidate := range candidates {
+		if candidate != "" && strings.EqualFold(candidate, expected) {
+			return true
+		}
+	}
+	filePath := filepath.ToSlash(result.Metadata["file_path"])
+	expected = filepath.ToSlash(expected)
+	return filePath != "" && (filePath == expected || strings.HasSuffix(filePath, "/"+strings.TrimPrefix(expected, "/")))
+}
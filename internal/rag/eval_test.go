@@ -0,0 +1,62 @@
+package rag
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluate(t *testing.T) {
+	dir := t.TempDir()
+	client, err := NewClientWithProvider("simple", map[string]interface{}{"database_path": filepath.Join(dir, "knowledge.db")})
+	require.NoError(t, err)
+	defer func() { _ = client.Close() }()
+	ingester := client.GetProvider().(DocumentIngester)
+	ctx := context.Background()
+	for source, text := range map[string]string{
+		"kb/handbook.pdf":                 "Employees receive 20 days of paid vacation.",
+		"kb/runbook.pdf":                  "Deployments are frozen during the vacation season.",
+		"https://docs.example.com/oncall": "The on-call engineer acknowledges pages within five minutes.",
+	} {
+		_, err := ingester.IngestDocument(ctx, source, filepath.Base(source), text, map[string]string{"url": source})
+		require.NoError(t, err)
+	}
+
+	path := filepath.Join(dir, "eval.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`k: 2
+cases:
+  - question: paid vacation days
+    expectedSources: [handbook.pdf]
+  - question: vacation freeze
+    expectedSources: [runbook.pdf, handbook.pdf]
+  - question: how fast are pages acknowledged
+    expectedSources: [https://docs.example.com/oncall]
+  - question: expense reports
+    expectedSources: [expenses.pdf]
+`), 0644))
+	evalSet, err := LoadEvalSet(path)
+	require.NoError(t, err)
+
+	report := client.Evaluate(ctx, evalSet, 0)
+	require.Len(t, report.Cases, 4)
+	assert.Equal(t, 2, report.K)
+	assert.Equal(t, 1, report.Cases[0].Rank)
+	assert.Equal(t, 1.0, report.Cases[1].Recall)
+	assert.Equal(t, 1, report.Cases[2].Rank)
+	assert.Equal(t, 0, report.Cases[3].Rank)
+	assert.InDelta(t, 0.75, report.RecallAtK, 1e-9)
+	assert.Greater(t, report.MRR, 0.5)
+	assert.Contains(t, report.String(), "Recall@2: 0.750")
+	assert.Contains(t, report.String(), "MISS")
+}
+
+func TestLoadEvalSetValidation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "eval.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("cases:\n  - question: vacation\n"), 0644))
+	_, err := LoadEvalSet(path)
+	assert.ErrorContains(t, err, "expectedSources is required")
+}
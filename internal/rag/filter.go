@@ -0,0 +1,196 @@
+package rag
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// SearchFilter narrows a search to the chunks of some documents, such as "only the
+// runbooks". Zero fields match every chunk.
+type SearchFilter struct {
+	// FileGlob matches the file name, or the file path or URL when it contains a
+	// "/". "*" matches any text, "?" one character, and case is ignored.
+	FileGlob       string
+	SourceType     string    // IngestSourceFile, IngestSourceURL or IngestSourceConnector
+	IngestedAfter  time.Time // Chunks ingested at or after this time
+	IngestedBefore time.Time // Chunks ingested before this time
+}
+
+// IsZero reports whether the filter matches every chunk
+func (f SearchFilter) IsZero() bool {
+	return f == SearchFilter{}
+}
+
+// ParseSearchFilter reads the filter arguments of rag_search: file_glob,
+// source_type, ingested_after and ingested_before. Dates are YYYY-MM-DD (UTC
+// midnight) or RFC 3339 times.
+func ParseSearchFilter(args map[string]interface{}) (SearchFilter, error) {
+	var filter SearchFilter
+	text := func(name string) (string, error) {
+		value, ok := args[name]
+		if !ok || value == nil {
+			return "", nil
+		}
+		str, ok := value.(string)
+		if !ok {
+			return "", fmt.Errorf("parameter '%s' must be a string", name)
+		}
+		return strings.TrimSpace(str), nil
+	}
+
+	var err error
+	if filter.FileGlob, err = text("file_glob"); err != nil {
+		return filter, err
+	}
+	if filter.SourceType, err = text("source_type"); err != nil {
+		return filter, err
+	}
+	switch filter.SourceType {
+	case "", IngestSourceFile, IngestSourceURL, IngestSourceConnector:
+	default:
+		return filter, fmt.Errorf("invalid source_type '%s' (use %s, %s or %s)", filter.SourceType, IngestSourceFile, IngestSourceURL, IngestSourceConnector)
+	}
+	for name, target := range map[string]*time.Time{"ingested_after": &filter.IngestedAfter, "ingested_before": &filter.IngestedBefore} {
+		value, err := text(name)
+		if err != nil {
+			return filter, err
+		}
+		if value == "" {
+			continue
+		}
+		if *target, err = parseFilterTime(value); err != nil {
+			return filter, fmt.Errorf("invalid %s '%s' (use YYYY-MM-DD or an RFC 3339 time)", name, value)
+		}
+	}
+	if !filter.IngestedAfter.IsZero() && !filter.IngestedBefore.IsZero() && !filter.IngestedAfter.Before(filter.IngestedBefore) {
+		return filter, fmt.Errorf("ingested_after must be before ingested_before")
+	}
+	return filter, nil
+}
+
+// parseFilterTime parses a date or an RFC 3339 time
+func parseFilterTime(value string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// matches reports whether a chunk's metadata passes the filter. Chunks of unknown
+// age do not pass a date range.
+func (f SearchFilter) matches(metadata map[string]string) bool {
+	if f.FileGlob != "" {
+		target := metadata["file_name"]
+		if f.globsPath() {
+			target = metadata["file_path"]
+		}
+		if !globRegexp(f.FileGlob).MatchString(target) {
+			return false
+		}
+	}
+	if f.SourceType != "" && sourceTypeOf(metadata) != f.SourceType {
+		return false
+	}
+	if !f.IngestedAfter.IsZero() || !f.IngestedBefore.IsZero() {
+		ingestedAt, err := time.Parse(time.RFC3339, metadata[IngestedAtMetadataKey])
+		if err != nil {
+			return false
+		}
+		if !f.IngestedAfter.IsZero() && ingestedAt.Before(f.IngestedAfter) {
+			return false
+		}
+		if !f.IngestedBefore.IsZero() && !ingestedAt.Before(f.IngestedBefore) {
+			return false
+		}
+	}
+	return true
+}
+
+// sql returns SQL conditions (and their arguments) on the documents table "d"
+// applying the filter
+func (f SearchFilter) sql() (string, []interface{}) {
+	var filter strings.Builder
+	var args []interface{}
+	if f.FileGlob != "" {
+		column := "d.file_name"
+		if f.globsPath() {
+			column = "d.file_path"
+		}
+		filter.WriteString(` AND lower(` + column + `) LIKE ? ESCAPE '\'`)
+		args = append(args, globLike(f.FileGlob))
+	}
+	switch f.SourceType {
+	case IngestSourceFile:
+		filter.WriteString(` AND json_extract(d.metadata, '$.url') IS NULL AND json_extract(d.metadata, '$.connector') IS NULL`)
+	case IngestSourceURL:
+		filter.WriteString(` AND json_extract(d.metadata, '$.url') IS NOT NULL AND json_extract(d.metadata, '$.connector') IS NULL`)
+	case IngestSourceConnector:
+		filter.WriteString(` AND json_extract(d.metadata, '$.connector') IS NOT NULL`)
+	}
+	if !f.IngestedAfter.IsZero() {
+		filter.WriteString(` AND julianday(d.ingested_at) >= julianday(?)`)
+		args = append(args, f.IngestedAfter.UTC().Format(time.DateTime))
+	}
+	if !f.IngestedBefore.IsZero() {
+		filter.WriteString(` AND julianday(d.ingested_at) < julianday(?)`)
+		args = append(args, f.IngestedBefore.UTC().Format(time.DateTime))
+	}
+	return filter.String(), args
+}
+
+// globsPath reports whether the glob is matched against the path rather than the name
+func (f SearchFilter) globsPath() bool {
+	return strings.Contains(f.FileGlob, "/")
+}
+
+// sourceTypeOf tells where a chunk came from: a connector sync, a web page or a file
+func sourceTypeOf(metadata map[string]string) string {
+	switch {
+	case metadata["connector"] != "":
+		return IngestSourceConnector
+	case metadata["url"] != "":
+		return IngestSourceURL
+	default:
+		return IngestSourceFile
+	}
+}
+
+// globRegexp compiles a glob into a case-insensitive regular expression matching
+// the whole text
+func globRegexp(glob string) *regexp.Regexp {
+	var pattern strings.Builder
+	pattern.WriteString("(?is)^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			pattern.WriteString(".*")
+		case '?':
+			pattern.WriteString(".")
+		default:
+			pattern.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	pattern.WriteString("$")
+	return regexp.MustCompile(pattern.String())
+}
+
+// globLike converts a glob into a lowercase SQL LIKE pattern escaped with "\"
+func globLike(glob string) string {
+	var pattern strings.Builder
+	for _, r := range strings.ToLower(glob) {
+		switch r {
+		case '*':
+			pattern.WriteRune('%')
+		case '?':
+			pattern.WriteRune('_')
+		case '%', '_', '\\':
+			pattern.WriteRune('\\')
+			pattern.WriteRune(r)
+		default:
+			pattern.WriteRune(r)
+		}
+	}
+	return pattern.String()
+}
@@ -0,0 +1,158 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSearchFilter(t *testing.T) {
+	filter, err := ParseSearchFilter(map[string]interface{}{
+		"query":           "rollback",
+		"file_glob":       "*runbook*",
+		"source_type":     "url",
+		"ingested_after":  "2025-01-01",
+		"ingested_before": "2025-02-01T12:00:00Z",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, SearchFilter{
+		FileGlob:       "*runbook*",
+		SourceType:     IngestSourceURL,
+		IngestedAfter:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		IngestedBefore: time.Date(2025, 2, 1, 12, 0, 0, 0, time.UTC),
+	}, filter)
+
+	filter, err = ParseSearchFilter(map[string]interface{}{"query": "rollback"})
+	require.NoError(t, err)
+	assert.True(t, filter.IsZero())
+
+	for want, args := range map[string]map[string]interface{}{
+		"invalid source_type":                    {"source_type": "email"},
+		"invalid ingested_after":                 {"ingested_after": "last week"},
+		"ingested_after must be before":          {"ingested_after": "2025-02-01", "ingested_before": "2025-01-01"},
+		"parameter 'file_glob' must be a string": {"file_glob": 3.0},
+	} {
+		_, err := ParseSearchFilter(args)
+		assert.ErrorContains(t, err, want)
+	}
+}
+
+func TestGlobs(t *testing.T) {
+	assert.True(t, globRegexp("*Runbook*").MatchString("sre/deploy-runbook.pdf"))
+	assert.True(t, globRegexp("handbook-?.pdf").MatchString("handbook-2.pdf"))
+	assert.False(t, globRegexp("*.pdf").MatchString("notes.pdf.txt"))
+	assert.False(t, globRegexp("[a]*").MatchString("a.pdf"), "brackets are literal")
+	assert.Equal(t, `%runbook\_v_%`, globLike("*Runbook_v?*"))
+}
+
+func TestSearchFilters(t *testing.T) {
+	for _, provider := range []string{"simple", "json"} {
+		t.Run(provider, func(t *testing.T) {
+			client, err := NewClientWithProvider(provider, map[string]interface{}{
+				"database_path": filepath.Join(t.TempDir(), "knowledge.db"),
+			})
+			require.NoError(t, err)
+			defer func() { _ = client.Close() }()
+			ingester := client.GetProvider().(DocumentIngester)
+			ctx := context.Background()
+
+			store := func(source, name string, metadata map[string]string) {
+				_, err := ingester.IngestDocument(ctx, source, name, "Rollback the deployment with the release pipeline.", metadata)
+				require.NoError(t, err)
+			}
+			store("/srv/docs/runbooks/deploy-runbook.pdf", "deploy-runbook.pdf", map[string]string{})
+			store("/srv/docs/postmortems/2024-outage.pdf", "2024-outage.pdf", map[string]string{})
+			store("https://docs.example.com/deploy", "Deploying", map[string]string{"url": "https://docs.example.com/deploy"})
+			store("https://wiki.example.com/rollback", "Rollbacks", map[string]string{"url": "https://wiki.example.com/rollback", "connector": "eng-wiki"})
+			store("/srv/hr/handbook.pdf", "handbook.pdf", map[string]string{NamespaceMetadataKey: "hr"})
+
+			search := func(ctx context.Context, args map[string]interface{}) []string {
+				t.Helper()
+				filter, err := ParseSearchFilter(args)
+				require.NoError(t, err)
+				results, err := client.retrieve(ctx, "rollback deployment", 0, filter)
+				require.NoError(t, err)
+				var names []string
+				for _, result := range results {
+					names = append(names, result.FileName)
+				}
+				sort.Strings(names)
+				return names
+			}
+
+			assert.Len(t, search(ctx, map[string]interface{}{}), 5)
+			assert.Equal(t, []string{"deploy-runbook.pdf"}, search(ctx, map[string]interface{}{"file_glob": "*RUNBOOK*"}))
+			assert.Equal(t, []string{"2024-outage.pdf"}, search(ctx, map[string]interface{}{"file_glob": "*/postmortems/*"}))
+			assert.Equal(t, []string{"Deploying"}, search(ctx, map[string]interface{}{"source_type": "url"}))
+			assert.Equal(t, []string{"Rollbacks"}, search(ctx, map[string]interface{}{"source_type": "connector"}))
+			assert.Len(t, search(ctx, map[string]interface{}{"source_type": "file"}), 3)
+
+			tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")
+			assert.Empty(t, search(ctx, map[string]interface{}{"ingested_after": tomorrow}))
+			assert.Len(t, search(ctx, map[string]interface{}{"ingested_before": tomorrow}), 5)
+			assert.Equal(t, []string{"handbook.pdf"}, search(WithNamespace(ctx, "hr"), map[string]interface{}{"source_type": "file"}))
+		})
+	}
+}
+
+func TestSearchNamespaceArgument(t *testing.T) {
+	provider := &recordingProvider{}
+	client := &Client{provider: provider}
+
+	// Unscoped channels can narrow their search to a namespace
+	_, err := client.CallTool(context.Background(), "rag_search", map[string]interface{}{"query": "vacation", "namespace": "hr", "file_glob": "*handbook*"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{NamespaceMetadataKey: "hr"}, provider.searchOptions.Metadata)
+	assert.Equal(t, "*handbook*", provider.searchOptions.Filter.FileGlob)
+
+	// Channels confined to a namespace cannot search another one
+	_, err = client.CallTool(WithNamespaceScope(context.Background(), "platform", false), "rag_search", map[string]interface{}{"query": "vacation", "namespace": "hr"})
+	assert.ErrorContains(t, err, "namespace hr cannot be used from this channel")
+	_, err = client.CallTool(WithNamespaceScope(context.Background(), "platform", false), "rag_ingest", map[string]interface{}{"file_path": "handbook.pdf", "namespace": "hr"})
+	assert.ErrorContains(t, err, "namespace hr cannot be used from this channel")
+
+	// Unmapped channels only search the documents outside every namespace
+	unmapped := WithNamespaceScope(context.Background(), "", false)
+	_, err = client.CallTool(unmapped, "rag_search", map[string]interface{}{"query": "vacation", "namespace": "hr"})
+	assert.ErrorContains(t, err, "not mapped to a namespace")
+	_, err = client.CallTool(unmapped, "rag_search", map[string]interface{}{"query": "vacation"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{NamespaceMetadataKey: ""}, provider.searchOptions.Metadata)
+
+	// Admins may name any namespace
+	_, err = client.CallTool(WithNamespaceScope(context.Background(), "platform", true), "rag_search", map[string]interface{}{"query": "vacation", "namespace": "hr"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{NamespaceMetadataKey: "hr"}, provider.searchOptions.Metadata)
+}
+
+func TestOpenAISearchFilters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"object":"vector_store.search_results.page","has_more":false,"data":[
+			{"file_id":"file-1","filename":"deploy-runbook.pdf","score":0.9,"content":[{"type":"text","text":"Rollback steps"}]},
+			{"file_id":"file-2","filename":"handbook.pdf","score":0.8,"content":[{"type":"text","text":"Vacation policy"}]}]}`)
+	}))
+	defer server.Close()
+	provider := &OpenAIProvider{
+		client:        openai.NewClient(option.WithAPIKey("test"), option.WithBaseURL(server.URL), option.WithMaxRetries(0)),
+		vectorStoreID: "vs_kb",
+	}
+
+	results, err := provider.Search(context.Background(), "rollback", SearchOptions{Filter: SearchFilter{FileGlob: "*runbook*"}})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "deploy-runbook.pdf", results[0].FileName)
+
+	_, err = provider.Search(context.Background(), "rollback", SearchOptions{Filter: SearchFilter{SourceType: IngestSourceURL}})
+	assert.ErrorContains(t, err, "can only filter searches by file name")
+}
@@ -0,0 +1,123 @@
+// Package rag provides recency ranking and stale-source warnings for knowledge base searches
+package rag
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultSearchLimit is the number of results providers return when no limit is set
+	defaultSearchLimit = 10
+
+	// decayCandidateFactor is how many times the results kept are searched when
+	// scores decay, for recent chunks ranked below the kept results to move up
+	decayCandidateFactor = 3
+)
+
+// SetRecencyHalfLife makes search scores decay with the age of each chunk, halving
+// every halfLife. Zero disables decay.
+func (c *Client) SetRecencyHalfLife(halfLife time.Duration) {
+	c.halfLife = halfLife
+}
+
+// ingestedAt returns when a search result's document was ingested, or the zero
+// time when it is unknown
+func ingestedAt(result SearchResult) time.Time {
+	ingested, err := time.Parse(time.RFC3339, result.Metadata[IngestedAtMetadataKey])
+	if err != nil {
+		return time.Time{}
+	}
+	return ingested
+}
+
+// decay scales each result's score by 0.5^(age/halfLife) and reorders the results
+// by the new scores. Results of unknown age keep their score.
+func decay(results []SearchResult, halfLife time.Duration, now time.Time) []SearchResult {
+	if halfLife <= 0 || len(results) == 0 {
+		return results
+	}
+	for i := range results {
+		ingested := ingestedAt(results[i])
+		if ingested.IsZero() || !ingested.Before(now) {
+			continue
+		}
+		results[i].Score *= float32(math.Pow(0.5, float64(now.Sub(ingested))/float64(halfLife)))
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	return results
+}
+
+// StaleSource is a source returned while answering a request that was ingested
+// longer ago than the staleness threshold
+type StaleSource struct {
+	FileName   string
+	IngestedAt time.Time
+}
+
+// StaleSourceCollector records the stale sources returned by searches made while
+// answering a single request
+type StaleSourceCollector struct {
+	mu         sync.Mutex
+	staleAfter time.Duration
+	sources    []StaleSource
+	seen       map[string]bool
+}
+
+// staleSourcesContextKey is the context key for the request's StaleSourceCollector
+type staleSourcesContextKey struct{}
+
+// WithStaleSources returns a context in which the search results given to the LLM
+// are checked for sources ingested longer than staleAfter ago
+func WithStaleSources(ctx context.Context, staleAfter time.Duration) (context.Context, *StaleSourceCollector) {
+	collector := &StaleSourceCollector{staleAfter: staleAfter, seen: make(map[string]bool)}
+	return context.WithValue(ctx, staleSourcesContextKey{}, collector), collector
+}
+
+// StaleSourcesFromContext returns the collector for the request, or nil
+func StaleSourcesFromContext(ctx context.Context) *StaleSourceCollector {
+	collector, _ := ctx.Value(staleSourcesContextKey{}).(*StaleSourceCollector)
+	return collector
+}
+
+// record adds the stale results, once per source
+func (s *StaleSourceCollector) record(results []SearchResult, now time.Time) {
+	cutoff := now.Add(-s.staleAfter)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, result := range results {
+		ingested := ingestedAt(result)
+		if ingested.IsZero() || !ingested.Before(cutoff) || s.seen[result.FileName] {
+			continue
+		}
+		s.seen[result.FileName] = true
+		s.sources = append(s.sources, StaleSource{FileName: result.FileName, IngestedAt: ingested})
+	}
+}
+
+// Warning returns a freshness warning naming the stale sources, or an empty string
+// when none were recorded
+func (s *StaleSourceCollector) Warning() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.sources) == 0 {
+		return ""
+	}
+	var warning strings.Builder
+	warning.WriteString("⚠️ *Freshness warning*: this answer relies on sources that may be out of date:")
+	for _, source := range s.sources {
+		name := source.FileName
+		if name == "" {
+			name = "Unknown source"
+		}
+		warning.WriteString(fmt.Sprintf("\n• %s (ingested %s)", name, source.IngestedAt.Format("2006-01-02")))
+	}
+	return warning.String()
+}
@@ -0,0 +1,103 @@
+package rag
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ingested returns chunk metadata recording an ingestion time
+func ingested(at time.Time) map[string]string {
+	return map[string]string{IngestedAtMetadataKey: at.Format(time.RFC3339)}
+}
+
+func TestDecayFavoursRecentChunks(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	results := []SearchResult{
+		{FileName: "old-runbook.md", Score: 1.0, Metadata: ingested(now.Add(-60 * 24 * time.Hour))},
+		{FileName: "new-runbook.md", Score: 0.6, Metadata: ingested(now.Add(-24 * time.Hour))},
+		{FileName: "unknown.md", Score: 0.5},
+	}
+
+	decayed := decay(results, 30*24*time.Hour, now)
+	require.Len(t, decayed, 3)
+	assert.Equal(t, "new-runbook.md", decayed[0].FileName)
+	assert.Equal(t, "unknown.md", decayed[1].FileName, "chunks of unknown age keep their score")
+	assert.InDelta(t, 0.25, decayed[2].Score, 0.001, "two half-lives quarter the score")
+
+	// Without a half-life the search order is kept
+	results = []SearchResult{{FileName: "a", Score: 0.1, Metadata: ingested(now)}, {FileName: "b", Score: 0.9}}
+	assert.Equal(t, "a", decay(results, 0, now)[0].FileName)
+}
+
+// rankedProvider returns its results, best first, up to the search limit
+type rankedProvider struct {
+	staticProvider
+	limits []int
+}
+
+func (p *rankedProvider) Search(_ context.Context, _ string, options SearchOptions) ([]SearchResult, error) {
+	p.limits = append(p.limits, options.Limit)
+	results := append([]SearchResult(nil), p.results...)
+	if len(results) > options.Limit {
+		results = results[:options.Limit]
+	}
+	return results, nil
+}
+
+// searchOrder is a reranker that keeps the search order
+type searchOrder struct{}
+
+func (searchOrder) Rerank(_ context.Context, _ string, results []SearchResult) ([]SearchResult, error) {
+	return results, nil
+}
+
+func TestDecayAppliesBeforeTopK(t *testing.T) {
+	now := time.Now().UTC()
+	provider := &rankedProvider{staticProvider: staticProvider{results: []SearchResult{
+		{FileName: "old-runbook.md", Score: 1.0, Metadata: ingested(now.Add(-180 * 24 * time.Hour))},
+		{FileName: "old-faq.md", Score: 0.9, Metadata: ingested(now.Add(-180 * 24 * time.Hour))},
+		{FileName: "new-runbook.md", Score: 0.8, Metadata: ingested(now.Add(-time.Hour))},
+	}}}
+	client := &Client{provider: provider, halfLife: 30 * 24 * time.Hour}
+
+	// The recent chunk, third before decay, is searched and ranked first
+	results, err := client.retrieve(context.Background(), "runbook", 2, SearchFilter{})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "new-runbook.md", results[0].FileName)
+	assert.Equal(t, []int{2 * decayCandidateFactor}, provider.limits)
+
+	// The reranker's top N are kept after decay too
+	client.SetReranker(searchOrder{}, 3, 1)
+	results, err = client.retrieve(context.Background(), "runbook", 0, SearchFilter{})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "new-runbook.md", results[0].FileName)
+}
+
+func TestStaleSourcesWarning(t *testing.T) {
+	now := time.Now().UTC()
+	client := &Client{provider: &staticProvider{results: []SearchResult{
+		{Content: "Restart the pods", FileName: "runbook.md", Metadata: ingested(now.Add(-400 * 24 * time.Hour))},
+		{Content: "Then drain the node", FileName: "runbook.md", Metadata: ingested(now.Add(-400 * 24 * time.Hour))},
+		{Content: "Use the new dashboard", FileName: "dashboards.md", Metadata: ingested(now.Add(-time.Hour))},
+	}}}
+
+	ctx, stale := WithStaleSources(context.Background(), 90*24*time.Hour)
+	output, err := client.CallTool(ctx, "rag_search", map[string]interface{}{"query": "restart"})
+	require.NoError(t, err)
+	assert.Contains(t, output, "Ingested: "+now.Add(-time.Hour).Format("2006-01-02"))
+
+	// Each stale source is listed once; recent sources are not
+	warning := stale.Warning()
+	assert.Equal(t, "⚠️ *Freshness warning*: this answer relies on sources that may be out of date:\n• runbook.md (ingested "+
+		now.Add(-400*24*time.Hour).Format("2006-01-02")+")", warning)
+
+	_, fresh := WithStaleSources(context.Background(), 90*24*time.Hour)
+	assert.Empty(t, fresh.Warning())
+	assert.Nil(t, StaleSourcesFromContext(context.Background()))
+}
@@ -0,0 +1,81 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tuannvm/slack-mcp-client/internal/monitoring"
+)
+
+// Types of items removed by garbage collection, recorded in the metrics
+const (
+	GCTypeOrphanedFile = "orphaned_file"
+	GCTypeFailedEntry  = "failed_entry"
+)
+
+// GCOptions configures a garbage collection
+type GCOptions struct {
+	MinAge time.Duration // Unattached files younger than this are kept, as their ingest may still be running
+	DryRun bool          // Report what would be removed without removing it
+}
+
+// GCResult reports what a garbage collection removed, or would remove in a dry run
+type GCResult struct {
+	FailedEntries int   // Failed or cancelled vector store entries pruned
+	OrphanedFiles int   // Uploaded files no vector store uses
+	FreedBytes    int64 // Size of the orphaned files
+}
+
+// CollectGarbage removes failed vector store entries and orphaned files from the
+// knowledge base
+func (c *Client) CollectGarbage(ctx context.Context, options GCOptions) (GCResult, error) {
+	collector, ok := c.provider.(GarbageCollector)
+	if !ok {
+		return GCResult{}, fmt.Errorf("the configured RAG provider does not support garbage collection")
+	}
+	provider := ProviderLabel(c.provider)
+	result, err := collector.CollectGarbage(ctx, options)
+	if err != nil {
+		monitoring.RAGProviderErrors.WithLabelValues(provider, "gc").Inc()
+	}
+	if !options.DryRun {
+		// Count what was removed before a failure too
+		monitoring.RAGGarbageCollected.WithLabelValues(provider, GCTypeFailedEntry).Add(float64(result.FailedEntries))
+		monitoring.RAGGarbageCollected.WithLabelValues(provider, GCTypeOrphanedFile).Add(float64(result.OrphanedFiles))
+		monitoring.RAGGarbageCollectedBytes.WithLabelValues(provider).Add(float64(result.FreedBytes))
+	}
+	return result, err
+}
+
+// StartGarbageCollection collects garbage on every interval, starting one interval
+// from now, until StopGarbageCollection is called. done is called after each run.
+func (c *Client) StartGarbageCollection(interval time.Duration, options GCOptions, done func(GCResult, error)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.stopGC = cancel
+	c.gcDone = make(chan struct{})
+	go func() {
+		defer close(c.gcDone)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			result, err := c.CollectGarbage(ctx, options)
+			if ctx.Err() == nil {
+				done(result, err)
+			}
+		}
+	}()
+}
+
+// StopGarbageCollection stops the runs started by StartGarbageCollection
+func (c *Client) StopGarbageCollection() {
+	if c.stopGC != nil {
+		c.stopGC()
+		<-c.gcDone
+	}
+}
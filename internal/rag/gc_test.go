@@ -0,0 +1,88 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newGCTestServer serves an OpenAI account with the knowledge base vector store,
+// another vector store sharing a file, a failed ingest, two orphaned files and an
+// unattached file another application uploaded, and records the deletions it receives
+func newGCTestServer(t *testing.T) (*httptest.Server, *[]string) {
+	t.Helper()
+	old, recent := time.Now().Add(-48*time.Hour).Unix(), time.Now().Unix()
+	var mu sync.Mutex
+	var deleted []string
+	responses := map[string]string{
+		"/vector_stores": `{"object":"list","has_more":false,"data":[{"id":"vs_kb"},{"id":"vs_other"}]}`,
+		"/vector_stores/vs_kb/files": `{"object":"list","has_more":false,"data":[
+			{"id":"file-ok","status":"completed"},{"id":"file-failed","status":"failed"}]}`,
+		"/vector_stores/vs_other/files": `{"object":"list","has_more":false,"data":[{"id":"file-shared","status":"completed"}]}`,
+		"/files": fmt.Sprintf(`{"object":"list","has_more":false,"data":[
+			{"id":"file-ok","filename":"slack-mcp-client_ok.pdf","bytes":100,"created_at":%[1]d,"purpose":"assistants"},
+			{"id":"file-failed","filename":"slack-mcp-client_failed.pdf","bytes":200,"created_at":%[2]d,"purpose":"assistants"},
+			{"id":"file-shared","filename":"slack-mcp-client_shared.pdf","bytes":300,"created_at":%[1]d,"purpose":"assistants"},
+			{"id":"file-orphan","filename":"slack-mcp-client_orphan.pdf","bytes":400,"created_at":%[1]d,"purpose":"assistants"},
+			{"id":"file-uploading","filename":"slack-mcp-client_uploading.pdf","bytes":500,"created_at":%[2]d,"purpose":"assistants"},
+			{"id":"file-foreign","filename":"assistant-notes.pdf","bytes":600,"created_at":%[1]d,"purpose":"assistants"}]}`, old, recent),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodDelete {
+			mu.Lock()
+			deleted = append(deleted, r.URL.Path)
+			mu.Unlock()
+			id := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+			_, _ = fmt.Fprintf(w, `{"id":%q,"deleted":true}`, id)
+			return
+		}
+		response, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = fmt.Fprint(w, response)
+	}))
+	t.Cleanup(server.Close)
+	return server, &deleted
+}
+
+func TestOpenAICollectGarbage(t *testing.T) {
+	server, deleted := newGCTestServer(t)
+	provider := &OpenAIProvider{
+		client:        openai.NewClient(option.WithAPIKey("test"), option.WithBaseURL(server.URL), option.WithMaxRetries(0)),
+		vectorStoreID: "vs_kb",
+	}
+	client := &Client{provider: provider}
+
+	result, err := client.CollectGarbage(context.Background(), GCOptions{MinAge: 24 * time.Hour, DryRun: true})
+	require.NoError(t, err)
+	assert.Equal(t, GCResult{FailedEntries: 1, OrphanedFiles: 2, FreedBytes: 600}, result)
+	assert.Empty(t, *deleted, "a dry run removes nothing")
+
+	result, err = client.CollectGarbage(context.Background(), GCOptions{MinAge: 24 * time.Hour})
+	require.NoError(t, err)
+	assert.Equal(t, GCResult{FailedEntries: 1, OrphanedFiles: 2, FreedBytes: 600}, result)
+	sort.Strings(*deleted)
+	// Files in any vector store, recent uploads and the files of other applications
+	// are kept; the failed ingest goes whatever its age
+	assert.Equal(t, []string{"/files/file-failed", "/files/file-orphan", "/vector_stores/vs_kb/files/file-failed"}, *deleted)
+}
+
+func TestCollectGarbageUnsupported(t *testing.T) {
+	client := &Client{provider: &staticProvider{}}
+	_, err := client.CollectGarbage(context.Background(), GCOptions{})
+	assert.ErrorContains(t, err, "does not support garbage collection")
+}
@@ -0,0 +1,132 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// DefaultIngestWorkers is the number of files of a directory ingested at once when
+// SetIngestWorkers was not called
+const DefaultIngestWorkers = 4
+
+// IngestProgress reports how far the ingestion of a directory got
+type IngestProgress struct {
+	Total     int    // Files found in the directory
+	Done      int    // Files processed so far
+	Ingested  int    // Files stored
+	Unchanged int    // Files skipped since they have not changed
+	Failed    int    // Files that could not be ingested
+	File      string // File processed last
+	Err       error  // Why File could not be ingested
+}
+
+// ingestProgressContextKey is the context key for the ingestion progress callback
+type ingestProgressContextKey struct{}
+
+// WithIngestProgress returns a context in which directory ingestion calls report
+// after each file. Calls are not concurrent.
+func WithIngestProgress(ctx context.Context, report func(IngestProgress)) context.Context {
+	return context.WithValue(ctx, ingestProgressContextKey{}, report)
+}
+
+// ingestProgressFrom returns the progress callback of the context, or a no-op
+func ingestProgressFrom(ctx context.Context) func(IngestProgress) {
+	if report, ok := ctx.Value(ingestProgressContextKey{}).(func(IngestProgress)); ok {
+		return report
+	}
+	return func(IngestProgress) {}
+}
+
+// SetIngestWorkers sets how many files of a directory are ingested at once
+func (c *Client) SetIngestWorkers(workers int) {
+	c.ingestWorkers = workers
+}
+
+// ingestDirectory ingests the PDF files under dir with a pool of workers, skipping
+// files that have not changed since they were last ingested
+func (c *Client) ingestDirectory(ctx context.Context, dir string, metadata map[string]string) (string, error) {
+	var filePaths []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() && strings.EqualFold(filepath.Ext(path), ".pdf") {
+			filePaths = append(filePaths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to read directory: %w", err)
+	}
+	if len(filePaths) == 0 {
+		return "", fmt.Errorf("no PDF files found in %s", dir)
+	}
+
+	workers := c.ingestWorkers
+	if workers <= 0 {
+		workers = DefaultIngestWorkers
+	}
+	workers = min(workers, len(filePaths))
+
+	report := ingestProgressFrom(ctx)
+	progress := IngestProgress{Total: len(filePaths)}
+	failures := make([]error, len(filePaths))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	next := make(chan int)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				_, skipped, err := c.ingestFile(ctx, filePaths[i], metadata)
+
+				mu.Lock()
+				progress.Done++
+				progress.File, progress.Err = filePaths[i], err
+				switch {
+				case err != nil:
+					failures[i] = err
+					progress.Failed++
+				case skipped:
+					progress.Unchanged++
+				default:
+					progress.Ingested++
+				}
+				report(progress)
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := range filePaths {
+		if ctx.Err() != nil {
+			break
+		}
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("ingestion stopped after %d of %d file(s): %w", progress.Done, len(filePaths), err)
+	}
+
+	summary := fmt.Sprintf("Ingested %d of %d file(s) from %s", progress.Ingested, len(filePaths), dir)
+	if namespace := metadata[NamespaceMetadataKey]; namespace != "" {
+		summary += " into namespace " + namespace
+	}
+	summary += fmt.Sprintf(" (%d unchanged)", progress.Unchanged)
+	if progress.Failed == 0 {
+		return summary, nil
+	}
+	var listed strings.Builder
+	for i, err := range failures {
+		if err != nil {
+			listed.WriteString(fmt.Sprintf("- Failed: %s (%v)\n", filePaths[i], err))
+		}
+	}
+	return summary + ":\n" + listed.String(), nil
+}
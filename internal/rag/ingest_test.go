@@ -0,0 +1,144 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingEmbedder records the number of texts of each embedding request
+type countingEmbedder struct {
+	conceptEmbedder
+	mu    sync.Mutex
+	calls []int
+	err   error
+}
+
+func (e *countingEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	e.mu.Lock()
+	e.calls = append(e.calls, len(texts))
+	e.mu.Unlock()
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.conceptEmbedder.EmbedDocuments(ctx, texts)
+}
+
+func TestParallelIngestionBatchesEmbeddings(t *testing.T) {
+	docs := t.TempDir()
+	for i := range 12 {
+		writePDF(t, filepath.Join(docs, fmt.Sprintf("runbook-%02d.pdf", i)), fmt.Sprintf("Runbook %d: deploy with the release pipeline.", i))
+	}
+	provider, err := NewSQLiteProvider(filepath.Join(t.TempDir(), "knowledge.db"))
+	require.NoError(t, err)
+	client := &Client{provider: provider}
+	defer func() { _ = client.Close() }()
+	embedder := &countingEmbedder{conceptEmbedder: conceptEmbedder{concepts: [][]string{{"deploy"}, {"release"}}}}
+	require.NoError(t, client.EnableVectorSearch(embedder, HybridOptions{Mode: SearchModeVector, EmbeddingModel: "concepts"}))
+	client.SetIngestWorkers(6)
+
+	var reports []IngestProgress
+	ctx := WithIngestProgress(context.Background(), func(progress IngestProgress) {
+		reports = append(reports, progress)
+	})
+	output, err := client.CallTool(ctx, "rag_ingest", map[string]interface{}{"file_path": docs})
+	require.NoError(t, err)
+	assert.Contains(t, output, "Ingested 12 of 12 file(s)")
+
+	// Each file is reported once, in order of completion
+	require.Len(t, reports, 12)
+	for i, progress := range reports {
+		assert.Equal(t, i+1, progress.Done)
+		assert.Equal(t, 12, progress.Total)
+	}
+	assert.Equal(t, 12, reports[11].Ingested)
+
+	// The chunks of files ingested together share embedding requests, and every
+	// chunk is embedded
+	embedder.mu.Lock()
+	calls := embedder.calls
+	embedder.mu.Unlock()
+	assert.Less(t, len(calls), 12, "requests: %v", calls)
+	total := 0
+	for _, size := range calls {
+		assert.LessOrEqual(t, size, embedBatchSize)
+		total += size
+	}
+	assert.Equal(t, 12, total)
+	embedded, err := provider.EmbedMissing(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, embedded)
+}
+
+func TestEmbedBatcher(t *testing.T) {
+	embedder := &countingEmbedder{conceptEmbedder: conceptEmbedder{concepts: [][]string{{"a"}, {"b"}}}}
+	batcher := newEmbedBatcher(embedder, 2)
+	defer batcher.close()
+
+	// Requests larger than a batch are split into calls of at most the batch size
+	vectors, err := batcher.embed(context.Background(), []string{"a", "b", "ab"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]float32{{1, 0}, {0, 1}, {1, 1}}, vectors)
+	assert.Equal(t, []int{2, 1}, embedder.calls)
+
+	// A failed call fails the request
+	embedder.err = fmt.Errorf("rate limited")
+	_, err = batcher.embed(context.Background(), []string{"a"})
+	assert.ErrorContains(t, err, "rate limited")
+
+	batcher.close()
+	_, err = batcher.embed(context.Background(), []string{"a"})
+	assert.ErrorContains(t, err, "closed")
+}
+
+// gatedEmbedder blocks each call until it is released or its context is cancelled
+type gatedEmbedder struct {
+	conceptEmbedder
+	release chan struct{}
+}
+
+func (e *gatedEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	select {
+	case <-e.release:
+		return e.conceptEmbedder.EmbedDocuments(ctx, texts)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestEmbedBatcherOutlivesCancelledRequests(t *testing.T) {
+	embedder := &gatedEmbedder{conceptEmbedder: conceptEmbedder{concepts: [][]string{{"a"}, {"b"}}}, release: make(chan struct{})}
+	batcher := newEmbedBatcher(embedder, 10)
+	defer batcher.close()
+
+	// Two documents share a batch; the first is stopped while it is embedded
+	stopped, cancel := context.WithCancel(context.Background())
+	stoppedErr := make(chan error, 1)
+	go func() {
+		_, err := batcher.embed(stopped, []string{"a"})
+		stoppedErr <- err
+	}()
+	type result struct {
+		vectors [][]float32
+		err     error
+	}
+	kept := make(chan result, 1)
+	go func() {
+		vectors, err := batcher.embed(context.Background(), []string{"b"})
+		kept <- result{vectors, err}
+	}()
+	time.Sleep(2 * embedBatchWait)
+	cancel()
+	assert.ErrorIs(t, <-stoppedErr, context.Canceled)
+
+	close(embedder.release)
+	got := <-kept
+	require.NoError(t, got.err)
+	assert.Equal(t, [][]float32{{0, 1}}, got.vectors)
+}
@@ -0,0 +1,116 @@
+package rag
+
+import (
+	"context"
+	"time"
+
+	"github.com/tuannvm/slack-mcp-client/internal/monitoring"
+)
+
+// Sources of ingested documents, recorded in the ingestion metrics
+const (
+	IngestSourceFile      = "file"
+	IngestSourceURL       = "url"
+	IngestSourceConnector = "connector"
+)
+
+// Outcomes of an ingestion, recorded in the ingestion metrics
+const (
+	IngestOutcomeIngested  = "ingested"
+	IngestOutcomeUnchanged = "unchanged"
+	IngestOutcomeError     = "error"
+)
+
+// ProviderLabel names a vector provider in the metrics
+func ProviderLabel(provider VectorProvider) string {
+	switch provider.(type) {
+	case *SQLiteProvider:
+		return "sqlite"
+	case *SimpleProvider:
+		return "json"
+	case *OpenAIProvider:
+		return "openai"
+	default:
+		return "other"
+	}
+}
+
+// RecordIngestion counts an ingested document and its size in bytes
+func RecordIngestion(provider, source, outcome string, size int) {
+	monitoring.RAGIngestions.WithLabelValues(provider, source, outcome).Inc()
+	if outcome == IngestOutcomeError {
+		monitoring.RAGProviderErrors.WithLabelValues(provider, "ingest").Inc()
+		return
+	}
+	if outcome == IngestOutcomeIngested {
+		monitoring.RAGIngestedBytes.WithLabelValues(provider, source).Add(float64(size))
+	}
+}
+
+// recordChunks counts the chunks a provider stored for a document
+func recordChunks(provider string, chunks int) {
+	monitoring.RAGIngestedChunks.WithLabelValues(provider).Add(float64(chunks))
+}
+
+// recordSearch records the duration and result count of a search, or its failure
+func recordSearch(provider string, started time.Time, results int, err error) {
+	if err != nil {
+		monitoring.RAGProviderErrors.WithLabelValues(provider, "search").Inc()
+		return
+	}
+	monitoring.RAGSearchDuration.WithLabelValues(provider).Observe(time.Since(started).Seconds())
+	monitoring.RAGSearchResults.WithLabelValues(provider).Observe(float64(results))
+}
+
+// RefreshStoreMetrics reads the statistics of the vector store into the size gauges
+func (c *Client) RefreshStoreMetrics(ctx context.Context) error {
+	provider := ProviderLabel(c.provider)
+	stats, err := c.provider.GetStats(ctx)
+	if err != nil {
+		monitoring.RAGProviderErrors.WithLabelValues(provider, "stats").Inc()
+		return err
+	}
+	recordStoreStats(provider, stats)
+	return nil
+}
+
+// recordStoreStats sets the size gauges of a vector store
+func recordStoreStats(provider string, stats *VectorStoreStats) {
+	monitoring.RAGStoreDocuments.WithLabelValues(provider).Set(float64(stats.TotalFiles))
+	monitoring.RAGStoreChunks.WithLabelValues(provider).Set(float64(stats.TotalChunks))
+	monitoring.RAGStoreSizeBytes.WithLabelValues(provider).Set(float64(stats.StorageSizeBytes))
+	if !stats.LastUpdated.IsZero() {
+		monitoring.RAGStoreLastUpdated.WithLabelValues(provider).Set(float64(stats.LastUpdated.Unix()))
+	}
+}
+
+// StartStoreMetrics refreshes the size gauges of the vector store now and then on
+// every interval, until StopStoreMetrics is called
+func (c *Client) StartStoreMetrics(interval time.Duration, onError func(error)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.stopMetrics = cancel
+	c.metricsDone = make(chan struct{})
+	go func() {
+		defer close(c.metricsDone)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := c.RefreshStoreMetrics(ctx); err != nil && ctx.Err() == nil {
+				onError(err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// StopStoreMetrics stops the refreshes started by StartStoreMetrics
+func (c *Client) StopStoreMetrics() {
+	if c.stopMetrics != nil {
+		c.stopMetrics()
+		<-c.metricsDone
+	}
+}
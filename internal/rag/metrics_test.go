@@ -0,0 +1,49 @@
+package rag
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/monitoring"
+)
+
+func TestRAGMetrics(t *testing.T) {
+	provider, err := NewSQLiteProvider(filepath.Join(t.TempDir(), "knowledge.db"))
+	require.NoError(t, err)
+	defer func() { _ = provider.Close() }()
+	client := &Client{provider: provider}
+	ctx := context.Background()
+
+	chunks := testutil.ToFloat64(monitoring.RAGIngestedChunks.WithLabelValues("sqlite"))
+	_, err = provider.IngestDocument(ctx, "https://example.com/runbook", "Runbook", "Restart the deploy service with the restart command.", nil)
+	require.NoError(t, err)
+	assert.Equal(t, chunks+1, testutil.ToFloat64(monitoring.RAGIngestedChunks.WithLabelValues("sqlite")))
+
+	results, err := client.Retrieve(ctx, "restart deploy")
+	require.NoError(t, err)
+	require.NotEmpty(t, results)
+	assert.Positive(t, testutil.CollectAndCount(monitoring.RAGSearchDuration))
+	assert.Positive(t, testutil.CollectAndCount(monitoring.RAGSearchResults))
+
+	require.NoError(t, client.RefreshStoreMetrics(ctx))
+	assert.Equal(t, 1.0, testutil.ToFloat64(monitoring.RAGStoreDocuments.WithLabelValues("sqlite")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(monitoring.RAGStoreChunks.WithLabelValues("sqlite")))
+	assert.Positive(t, testutil.ToFloat64(monitoring.RAGStoreLastUpdated.WithLabelValues("sqlite")))
+
+	ingested := testutil.ToFloat64(monitoring.RAGIngestedBytes.WithLabelValues("sqlite", IngestSourceURL))
+	failures := testutil.ToFloat64(monitoring.RAGProviderErrors.WithLabelValues("sqlite", "ingest"))
+	RecordIngestion("sqlite", IngestSourceURL, IngestOutcomeIngested, 120)
+	RecordIngestion("sqlite", IngestSourceURL, IngestOutcomeUnchanged, 120)
+	RecordIngestion("sqlite", IngestSourceURL, IngestOutcomeError, 120)
+	assert.Equal(t, ingested+120, testutil.ToFloat64(monitoring.RAGIngestedBytes.WithLabelValues("sqlite", IngestSourceURL)))
+	assert.Equal(t, failures+1, testutil.ToFloat64(monitoring.RAGProviderErrors.WithLabelValues("sqlite", "ingest")))
+	assert.Positive(t, testutil.ToFloat64(monitoring.RAGIngestions.WithLabelValues("sqlite", IngestSourceURL, IngestOutcomeUnchanged)))
+
+	assert.Equal(t, "sqlite", ProviderLabel(provider))
+	assert.Equal(t, "json", ProviderLabel(NewSimpleProvider(filepath.Join(t.TempDir(), "knowledge.json"))))
+}
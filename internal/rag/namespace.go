@@ -0,0 +1,72 @@
+// Package rag provides knowledge base namespaces that scope searches to a collection
+package rag
+
+import (
+	"context"
+	"fmt"
+)
+
+// NamespaceMetadataKey is the chunk metadata key holding the chunk's namespace
+const NamespaceMetadataKey = "namespace"
+
+// namespaceContextKey is the context key for the request's namespace
+type namespaceContextKey struct{}
+
+// WithNamespace returns a context in which rag_search only returns chunks from the
+// namespace, and rag_ingest stores files in it unless told otherwise
+func WithNamespace(ctx context.Context, namespace string) context.Context {
+	if namespace == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, namespaceContextKey{}, namespace)
+}
+
+// NamespaceFromContext returns the request's namespace, or "" when searches are
+// not scoped
+func NamespaceFromContext(ctx context.Context) string {
+	namespace, _ := ctx.Value(namespaceContextKey{}).(string)
+	return namespace
+}
+
+// namespaceScopeKey is the context key marking requests confined to their namespace
+type namespaceScopeKey struct{}
+
+// namespaceScope records whether a confined request may still name other namespaces
+type namespaceScope struct {
+	admin bool
+}
+
+// WithNamespaceScope returns a context confined to a channel's namespace. The rag
+// tools search and ingest into it, and reject a namespace argument naming another
+// one unless admin is set. An empty namespace confines the request to the
+// documents outside every namespace.
+func WithNamespaceScope(ctx context.Context, namespace string, admin bool) context.Context {
+	return context.WithValue(WithNamespace(ctx, namespace), namespaceScopeKey{}, namespaceScope{admin: admin})
+}
+
+// namespaceFilter returns the metadata filter searches of the request apply, or
+// nil when they are not scoped
+func namespaceFilter(ctx context.Context) map[string]string {
+	namespace := NamespaceFromContext(ctx)
+	if _, confined := ctx.Value(namespaceScopeKey{}).(namespaceScope); namespace == "" && !confined {
+		return nil
+	}
+	return map[string]string{NamespaceMetadataKey: namespace}
+}
+
+// resolveNamespace returns the namespace a rag tool call uses: the requested one,
+// or the request's namespace when none is requested. A request confined to a
+// channel's namespace may not name another one unless an admin asked.
+func resolveNamespace(ctx context.Context, requested string) (string, error) {
+	namespace := NamespaceFromContext(ctx)
+	if requested == "" || requested == namespace {
+		return namespace, nil
+	}
+	if scope, confined := ctx.Value(namespaceScopeKey{}).(namespaceScope); confined && !scope.admin {
+		if namespace == "" {
+			return "", fmt.Errorf("namespace %s cannot be used from this channel, which is not mapped to a namespace", requested)
+		}
+		return "", fmt.Errorf("namespace %s cannot be used from this channel, which uses namespace %s", requested, namespace)
+	}
+	return requested, nil
+}
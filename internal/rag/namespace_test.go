@@ -0,0 +1,90 @@
+package rag
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingProvider records the arguments of ingest and search calls
+type recordingProvider struct {
+	VectorProvider
+	ingestMetadata map[string]string
+	searchOptions  SearchOptions
+}
+
+func (p *recordingProvider) IngestFile(_ context.Context, _ string, metadata map[string]string) (string, error) {
+	p.ingestMetadata = metadata
+	return "file_1", nil
+}
+
+func (p *recordingProvider) Search(_ context.Context, _ string, options SearchOptions) ([]SearchResult, error) {
+	p.searchOptions = options
+	return nil, nil
+}
+
+func TestNamespaceScopesSearchAndIngest(t *testing.T) {
+	provider := &recordingProvider{}
+	client := &Client{provider: provider}
+	ctx := WithNamespace(context.Background(), "hr-policies")
+
+	_, err := client.CallTool(ctx, "rag_search", map[string]interface{}{"query": "vacation"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{NamespaceMetadataKey: "hr-policies"}, provider.searchOptions.Metadata)
+
+	output, err := client.CallTool(ctx, "rag_ingest", map[string]interface{}{"file_path": "handbook.pdf"})
+	require.NoError(t, err)
+	assert.Equal(t, "hr-policies", provider.ingestMetadata[NamespaceMetadataKey])
+	assert.Contains(t, output, "into namespace hr-policies")
+
+	// An explicit namespace argument overrides the request's namespace when it is not confined
+	_, err = client.CallTool(ctx, "rag_ingest", map[string]interface{}{"file_path": "runbook.pdf", "namespace": "platform-docs"})
+	require.NoError(t, err)
+	assert.Equal(t, "platform-docs", provider.ingestMetadata[NamespaceMetadataKey])
+
+	// Without a namespace searches are not filtered
+	_, err = client.CallTool(context.Background(), "rag_search", map[string]interface{}{"query": "vacation"})
+	require.NoError(t, err)
+	assert.Nil(t, provider.searchOptions.Metadata)
+	assert.Empty(t, NamespaceFromContext(WithNamespace(context.Background(), "")))
+}
+
+func TestNamespaceFiltersStoredChunks(t *testing.T) {
+	documents := []SimpleDocument{
+		{ID: "file_0_chunk_0", Content: "Vacation requests need manager approval.", Metadata: map[string]string{"file_name": "handbook.pdf", "file_path": "kb/handbook.pdf", NamespaceMetadataKey: "hr-policies"}},
+		{ID: "file_1_chunk_0", Content: "Deployments pause during the vacation season.", Metadata: map[string]string{"file_name": "runbook.pdf", "file_path": "kb/runbook.pdf", NamespaceMetadataKey: "platform-docs"}},
+		{ID: "file_2_chunk_0", Content: "The office closes for the vacation week.", Metadata: map[string]string{"file_name": "office.pdf", "file_path": "kb/office.pdf"}},
+	}
+	data, err := json.Marshal(documents)
+	require.NoError(t, err)
+
+	for _, providerType := range []string{"simple", "json"} {
+		t.Run(providerType, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "knowledge.json")
+			require.NoError(t, os.WriteFile(path, data, 0644))
+			client, err := NewClientWithProvider(providerType, map[string]interface{}{"database_path": path})
+			require.NoError(t, err)
+			defer func() { _ = client.Close() }()
+
+			results, err := client.GetProvider().Search(context.Background(), "vacation", SearchOptions{
+				Metadata: map[string]string{NamespaceMetadataKey: "platform-docs"},
+			})
+			require.NoError(t, err)
+			require.Len(t, results, 1)
+			assert.Equal(t, "runbook.pdf", results[0].FileName)
+
+			// An empty namespace matches the documents outside every namespace
+			results, err = client.GetProvider().Search(context.Background(), "vacation", SearchOptions{
+				Metadata: map[string]string{NamespaceMetadataKey: ""},
+			})
+			require.NoError(t, err)
+			require.Len(t, results, 1)
+			assert.Equal(t, "office.pdf", results[0].FileName)
+		})
+	}
+}
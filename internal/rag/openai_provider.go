@@ -5,8 +5,10 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/openai/openai-go"
@@ -26,11 +28,19 @@ type OpenAIConfig struct {
 	VectorStoreMetadataValue string  // Value for the vector store metadata
 }
 
+// uploadedFilePrefix starts the name of every file the provider uploads. Search
+// results and file lists show the names without it.
+const uploadedFilePrefix = "slack-mcp-client_"
+
 // OpenAIProvider implements VectorProvider using OpenAI's VectorStore API with 2025 updates
 type OpenAIProvider struct {
 	client        openai.Client
 	vectorStoreID string
 	config        OpenAIConfig
+	chunking      *openai.StaticFileChunkingStrategyParam // nil uses OpenAI's automatic chunking
+
+	// Guards vectorStoreID, which is looked up by files ingested in parallel
+	mu sync.Mutex
 }
 
 // NewOpenAIProvider creates a new OpenAI vector provider instance
@@ -100,6 +110,9 @@ func NewOpenAIProvider(config map[string]interface{}) (VectorProvider, error) {
 
 // Initialize sets up the OpenAI vector store only
 func (o *OpenAIProvider) Initialize(ctx context.Context) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
 	// Find or create vector store
 	if o.config.VectorStoreID != "" {
 		// Use specific vector store ID
@@ -128,16 +141,11 @@ func (o *OpenAIProvider) Initialize(ctx context.Context) error {
 
 // IngestFile uploads a file to the OpenAI vector store
 func (o *OpenAIProvider) IngestFile(ctx context.Context, filePath string, metadata map[string]string) (string, error) {
-	// Check if vector store ID is empty
-	if o.vectorStoreID == "" {
-		// Use dynamic vector store
-		fmt.Printf("[RAG] OpenAI: Using dynamic vector store\n")
-		vectorStoreID, err := o.searchVectorStore(ctx, o.config.VectorStoreNameRegex)
-		if err != nil {
-			return "", fmt.Errorf("failed to search vector store: %w", err)
-		}
-		o.vectorStoreID = vectorStoreID
+	vectorStoreID, err := o.resolveVectorStore(ctx)
+	if err != nil {
+		return "", err
 	}
+
 	// Open the file for upload
 	file, err := os.Open(filePath)
 	if err != nil {
@@ -149,9 +157,10 @@ func (o *OpenAIProvider) IngestFile(ctx context.Context, filePath string, metada
 		}
 	}()
 
-	// Upload file with purpose "assistants" for vector store use
+	// Upload file with purpose "assistants" for vector store use, named so garbage
+	// collection can tell it from the files of other applications
 	uploadedFile, err := o.client.Files.New(ctx, openai.FileNewParams{
-		File:    file,
+		File:    openai.File(file, uploadedFilePrefix+filepath.Base(filePath), "application/octet-stream"),
 		Purpose: openai.FilePurposeAssistants,
 	})
 	if err != nil {
@@ -159,16 +168,20 @@ func (o *OpenAIProvider) IngestFile(ctx context.Context, filePath string, metada
 	}
 
 	// Attach file to vector store
-	vectorStoreFile, err := o.client.VectorStores.Files.New(ctx, o.vectorStoreID, openai.VectorStoreFileNewParams{
-		FileID: uploadedFile.ID,
-	})
+	params := openai.VectorStoreFileNewParams{FileID: uploadedFile.ID}
+	if o.chunking != nil {
+		params.ChunkingStrategy = openai.FileChunkingStrategyParamUnion{
+			OfStatic: &openai.StaticFileChunkingStrategyObjectParam{Static: *o.chunking},
+		}
+	}
+	vectorStoreFile, err := o.client.VectorStores.Files.New(ctx, vectorStoreID, params)
 	if err != nil {
 		return "", fmt.Errorf("failed to attach file to vector store: %w", err)
 	}
 
 	// Poll for completion
 	for {
-		vsFile, err := o.client.VectorStores.Files.Get(ctx, o.vectorStoreID, vectorStoreFile.ID)
+		vsFile, err := o.client.VectorStores.Files.Get(ctx, vectorStoreID, vectorStoreFile.ID)
 		if err != nil {
 			return "", fmt.Errorf("failed to check file status: %w", err)
 		}
@@ -190,6 +203,35 @@ func (o *OpenAIProvider) IngestFile(ctx context.Context, filePath string, metada
 	return uploadedFile.ID, nil
 }
 
+// SetChunking implements ChunkingConfigurer. OpenAI chunks files itself, so only the
+// recursive strategy is supported; its size and overlap are sent as a static chunking
+// strategy, converting characters to tokens at four characters per token. Without a
+// size, OpenAI's automatic chunking is kept.
+func (o *OpenAIProvider) SetChunking(options ChunkingOptions) error {
+	if options.Strategy != "" && options.Strategy != ChunkStrategyRecursive {
+		return fmt.Errorf("the openai provider does not support the %s chunking strategy", options.Strategy)
+	}
+	if options.Size <= 0 {
+		o.chunking = nil
+		return nil
+	}
+	size, overlap := int64(options.Size), int64(options.Overlap)
+	if overlap == 0 {
+		overlap = size / 5
+	}
+	if options.Unit != ChunkUnitTokens {
+		size, overlap = size/4, overlap/4
+	}
+	if size < 100 || size > 4096 {
+		return fmt.Errorf("openai chunk size must be between 100 and 4096 tokens, got %d", size)
+	}
+	if overlap < 0 || overlap > size/2 {
+		return fmt.Errorf("openai chunk overlap must not exceed half the chunk size")
+	}
+	o.chunking = &openai.StaticFileChunkingStrategyParam{MaxChunkSizeTokens: size, ChunkOverlapTokens: overlap}
+	return nil
+}
+
 // IngestFiles uploads multiple files to the OpenAI vector store
 func (o *OpenAIProvider) IngestFiles(ctx context.Context, filePaths []string, metadata map[string]string) ([]string, error) {
 	fileIDs := make([]string, 0, len(filePaths))
@@ -209,17 +251,12 @@ func (o *OpenAIProvider) IngestFiles(ctx context.Context, filePaths []string, me
 
 // DeleteFile removes a file from the vector store
 func (o *OpenAIProvider) DeleteFile(ctx context.Context, fileID string) error {
-	if o.vectorStoreID == "" {
-		// Use dynamic vector store
-		fmt.Printf("[RAG] OpenAI: Using dynamic vector store\n")
-		vectorStoreID, err := o.searchVectorStore(ctx, o.config.VectorStoreNameRegex)
-		if err != nil {
-			return fmt.Errorf("failed to search vector store: %w", err)
-		}
-		o.vectorStoreID = vectorStoreID
+	vectorStoreID, err := o.resolveVectorStore(ctx)
+	if err != nil {
+		return err
 	}
 	// Remove from vector store first
-	_, err := o.client.VectorStores.Files.Delete(ctx, o.vectorStoreID, fileID)
+	_, err = o.client.VectorStores.Files.Delete(ctx, vectorStoreID, fileID)
 	if err != nil {
 		return fmt.Errorf("failed to remove file from vector store: %w", err)
 	}
@@ -236,17 +273,12 @@ func (o *OpenAIProvider) DeleteFile(ctx context.Context, fileID string) error {
 
 // ListFiles lists all files in the vector store
 func (o *OpenAIProvider) ListFiles(ctx context.Context, limit int) ([]FileInfo, error) {
-	if o.vectorStoreID == "" {
-		// Use dynamic vector store
-		fmt.Printf("[RAG] OpenAI: Using dynamic vector store\n")
-		vectorStoreID, err := o.searchVectorStore(ctx, o.config.VectorStoreNameRegex)
-		if err != nil {
-			return nil, fmt.Errorf("failed to search vector store: %w", err)
-		}
-		o.vectorStoreID = vectorStoreID
+	vectorStoreID, err := o.resolveVectorStore(ctx)
+	if err != nil {
+		return nil, err
 	}
 	// List vector store files
-	vsFiles, err := o.client.VectorStores.Files.List(ctx, o.vectorStoreID, openai.VectorStoreFileListParams{
+	vsFiles, err := o.client.VectorStores.Files.List(ctx, vectorStoreID, openai.VectorStoreFileListParams{
 		Limit: openai.Int(int64(limit)),
 	})
 	if err != nil {
@@ -264,7 +296,7 @@ func (o *OpenAIProvider) ListFiles(ctx context.Context, limit int) ([]FileInfo,
 
 		files = append(files, FileInfo{
 			ID:         vsFile.ID,
-			Name:       file.Filename,
+			Name:       strings.TrimPrefix(file.Filename, uploadedFilePrefix),
 			Size:       int64(file.Bytes),
 			UploadedAt: time.Unix(file.CreatedAt, 0),
 			Status:     string(vsFile.Status),
@@ -277,12 +309,18 @@ func (o *OpenAIProvider) ListFiles(ctx context.Context, limit int) ([]FileInfo,
 
 // Search performs semantic search using OpenAI's Vector Store Search API (2025)
 func (o *OpenAIProvider) Search(ctx context.Context, query string, options SearchOptions) ([]SearchResult, error) {
-	fmt.Printf("[RAG] OpenAI: Vector Store search for query '%s' (vector_store: %s)\n", query, o.vectorStoreID)
 
-	vectorStoreID, err := o.searchVectorStore(ctx, o.config.VectorStoreNameRegex)
+	// OpenAI files carry no source type or ingestion date to filter on
+	filter := options.Filter
+	if filter.SourceType != "" || !filter.IngestedAfter.IsZero() || !filter.IngestedBefore.IsZero() {
+		return nil, fmt.Errorf("the openai provider can only filter searches by file name")
+	}
+
+	vectorStoreID, err := o.resolveVectorStore(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to search vector store: %w", err)
+		return nil, err
 	}
+	fmt.Printf("[RAG] OpenAI: Vector Store search for query '%s' (vector_store: %s)\n", query, vectorStoreID)
 
 	// Set up search parameters
 	limit := o.config.MaxResults
@@ -290,6 +328,10 @@ func (o *OpenAIProvider) Search(ctx context.Context, query string, options Searc
 		limit = 20
 	}
 
+	if filter.FileGlob != "" {
+		limit = 50 // Results are filtered by file name after the search
+	}
+
 	scoreThreshold := o.config.ScoreThreshold
 	if scoreThreshold <= 0 {
 		scoreThreshold = 0.5 // Use the default value defined in OpenAIConfig
@@ -317,6 +359,9 @@ func (o *OpenAIProvider) Search(ctx context.Context, query string, options Searc
 	results := make([]SearchResult, 0)
 
 	for i, result := range searchResults.Data {
+		if filter.FileGlob != "" && !globRegexp(filter.FileGlob).MatchString(strings.TrimPrefix(result.Filename, uploadedFilePrefix)) {
+			continue
+		}
 		// Extract content from the response
 		var content string
 		if len(result.Content) > 0 {
@@ -332,12 +377,13 @@ func (o *OpenAIProvider) Search(ctx context.Context, query string, options Searc
 			content = "No content available"
 		}
 
+		fileName := strings.TrimPrefix(result.Filename, uploadedFilePrefix)
 		searchResult := SearchResult{
 			Content:  content,
 			Score:    float32(result.Score),
-			FileName: result.Filename,
+			FileName: fileName,
 			Metadata: map[string]string{
-				"vector_store_id": o.vectorStoreID,
+				"vector_store_id": vectorStoreID,
 				"query":           query,
 				"result_index":    fmt.Sprintf("%d", i),
 				"score":           fmt.Sprintf("%.4f", result.Score),
@@ -349,8 +395,8 @@ func (o *OpenAIProvider) Search(ctx context.Context, query string, options Searc
 		if result.FileID != "" {
 			searchResult.Metadata["file_id"] = result.FileID
 		}
-		if result.Filename != "" {
-			searchResult.Metadata["file_name"] = result.Filename
+		if fileName != "" {
+			searchResult.Metadata["file_name"] = fileName
 		}
 
 		results = append(results, searchResult)
@@ -362,17 +408,12 @@ func (o *OpenAIProvider) Search(ctx context.Context, query string, options Searc
 
 // GetStats returns statistics about the vector store
 func (o *OpenAIProvider) GetStats(ctx context.Context) (*VectorStoreStats, error) {
-	if o.vectorStoreID == "" {
-		// Use dynamic vector store
-		fmt.Printf("[RAG] OpenAI: Using dynamic vector store\n")
-		vectorStoreID, err := o.searchVectorStore(ctx, o.config.VectorStoreNameRegex)
-		if err != nil {
-			return nil, fmt.Errorf("failed to search vector store: %w", err)
-		}
-		o.vectorStoreID = vectorStoreID
+	vectorStoreID, err := o.resolveVectorStore(ctx)
+	if err != nil {
+		return nil, err
 	}
 	// Get vector store details
-	vs, err := o.client.VectorStores.Get(ctx, o.vectorStoreID)
+	vs, err := o.client.VectorStores.Get(ctx, vectorStoreID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get vector store: %w", err)
 	}
@@ -390,6 +431,79 @@ func (o *OpenAIProvider) GetStats(ctx context.Context) (*VectorStoreStats, error
 	return stats, nil
 }
 
+// CollectGarbage prunes the failed and cancelled entries of the vector store and
+// deletes the files this provider uploaded that no vector store of the account
+// uses, such as files left behind by failed ingests. Only files named with
+// uploadedFilePrefix are deleted, so the files of other applications in the
+// account are never touched. Files attached to any vector store are kept, and
+// unattached files younger than options.MinAge are kept while their ingest may
+// still be running. The files of pruned entries are deleted regardless of their age.
+func (o *OpenAIProvider) CollectGarbage(ctx context.Context, options GCOptions) (GCResult, error) {
+	vectorStoreID, err := o.resolveVectorStore(ctx)
+	if err != nil {
+		return GCResult{}, err
+	}
+
+	// Files used by any vector store, and the failed entries of ours
+	attached := make(map[string]bool)
+	failed := make(map[string]bool)
+	stores := o.client.VectorStores.ListAutoPaging(ctx, openai.VectorStoreListParams{Limit: openai.Int(100)})
+	for stores.Next() {
+		storeID := stores.Current().ID
+		files := o.client.VectorStores.Files.ListAutoPaging(ctx, storeID, openai.VectorStoreFileListParams{Limit: openai.Int(100)})
+		for files.Next() {
+			file := files.Current()
+			if storeID == vectorStoreID && (file.Status == openai.VectorStoreFileStatusFailed || file.Status == openai.VectorStoreFileStatusCancelled) {
+				failed[file.ID] = true
+				continue
+			}
+			attached[file.ID] = true
+		}
+		if err := files.Err(); err != nil {
+			return GCResult{}, fmt.Errorf("failed to list files of vector store %s: %w", storeID, err)
+		}
+	}
+	if err := stores.Err(); err != nil {
+		return GCResult{}, fmt.Errorf("failed to list vector stores: %w", err)
+	}
+
+	var result GCResult
+	for fileID := range failed {
+		if !options.DryRun {
+			if _, err := o.client.VectorStores.Files.Delete(ctx, vectorStoreID, fileID); err != nil {
+				return result, fmt.Errorf("failed to remove failed file %s from vector store: %w", fileID, err)
+			}
+		}
+		result.FailedEntries++
+	}
+
+	cutoff := time.Now().Add(-options.MinAge)
+	files := o.client.Files.ListAutoPaging(ctx, openai.FileListParams{Purpose: openai.String("assistants")})
+	for files.Next() {
+		file := files.Current()
+		if !strings.HasPrefix(file.Filename, uploadedFilePrefix) {
+			continue
+		}
+		if attached[file.ID] || (!failed[file.ID] && time.Unix(file.CreatedAt, 0).After(cutoff)) {
+			continue
+		}
+		if !options.DryRun {
+			if _, err := o.client.Files.Delete(ctx, file.ID); err != nil {
+				return result, fmt.Errorf("failed to delete orphaned file %s: %w", file.ID, err)
+			}
+		}
+		result.OrphanedFiles++
+		result.FreedBytes += file.Bytes
+	}
+	if err := files.Err(); err != nil {
+		return result, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	fmt.Printf("[RAG] OpenAI: Garbage collection removed %d failed entries and %d orphaned files (%d bytes, dry run: %t)\n",
+		result.FailedEntries, result.OrphanedFiles, result.FreedBytes, options.DryRun)
+	return result, nil
+}
+
 // Close cleans up resources (no-op for OpenAI)
 func (o *OpenAIProvider) Close() error {
 	// OpenAI client doesn't need explicit cleanup
@@ -398,9 +512,28 @@ func (o *OpenAIProvider) Close() error {
 
 // GetVectorStoreID returns the OpenAI vector store ID
 func (o *OpenAIProvider) GetVectorStoreID() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
 	return o.vectorStoreID
 }
 
+// resolveVectorStore returns the ID of the vector store, looking it up by the
+// name regex on first use. The ID is locked since files are ingested in parallel.
+func (o *OpenAIProvider) resolveVectorStore(ctx context.Context) (string, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.vectorStoreID == "" {
+		// Use dynamic vector store
+		fmt.Printf("[RAG] OpenAI: Using dynamic vector store\n")
+		vectorStoreID, err := o.searchVectorStore(ctx, o.config.VectorStoreNameRegex)
+		if err != nil {
+			return "", fmt.Errorf("failed to search vector store: %w", err)
+		}
+		o.vectorStoreID = vectorStoreID
+	}
+	return o.vectorStoreID, nil
+}
+
 // findVectorStoreByName searches for an existing vector store by name
 func (o *OpenAIProvider) findVectorStoreByName(ctx context.Context, name string) (*openai.VectorStore, error) {
 	// List vector stores and search for matching name
@@ -4,6 +4,8 @@ package rag
 import (
 	"context"
 	"time"
+
+	"github.com/tmc/langchaingo/embeddings"
 )
 
 // VectorProvider defines the interface that all vector store providers must implement
@@ -28,6 +30,69 @@ type VectorProvider interface {
 	GetStats(ctx context.Context) (*VectorStoreStats, error)
 }
 
+// Search modes supported by providers that implement VectorSearcher
+const (
+	SearchModeKeyword = "keyword"
+	SearchModeVector  = "vector"
+	SearchModeHybrid  = "hybrid"
+)
+
+// HybridOptions configures embedding-based retrieval
+type HybridOptions struct {
+	Mode         string  // SearchModeKeyword, SearchModeVector or SearchModeHybrid
+	VectorWeight float64 // Weight of the vector score when fusing with the keyword score
+	Candidates   int     // Chunks retrieved by each method before fusion
+
+	// EmbeddingModel is stored with each vector; vectors from other models are
+	// ignored and re-embedded, since they are not comparable
+	EmbeddingModel string
+}
+
+// VectorSearcher is implemented by providers that can combine keyword search with
+// embedding similarity. SetEmbedder must be called before the provider is searched.
+type VectorSearcher interface {
+	SetEmbedder(embedder embeddings.Embedder, options HybridOptions)
+	// EmbedMissing embeds chunks stored without a vector, e.g. ingested from the CLI
+	EmbedMissing(ctx context.Context) (int, error)
+}
+
+// DocumentIngester is implemented by providers that can store text that was not
+// loaded from a local file, such as web pages. source identifies the document
+// (e.g. its URL) and name is shown as its file name.
+type DocumentIngester interface {
+	IngestDocument(ctx context.Context, source, name, text string, metadata map[string]string) (string, error)
+}
+
+// DocumentVersioner is implemented by providers that can report the "version"
+// metadata of documents stored with IngestDocument, so that syncing an external
+// source skips unchanged documents
+type DocumentVersioner interface {
+	// DocumentVersions returns the version of each stored document whose metadata
+	// matches filter, keyed by source
+	DocumentVersions(ctx context.Context, filter map[string]string) (map[string]string, error)
+	// DeleteDocument removes a document stored with IngestDocument
+	DeleteDocument(ctx context.Context, source, namespace string) error
+}
+
+// ContentIndex is implemented by providers that keep the metadata stored with each
+// document, so that re-ingesting an unchanged document can be skipped and duplicated
+// content can be removed
+type ContentIndex interface {
+	// DocumentMetadata returns the metadata stored with a document, or nil when the
+	// source is not stored in the namespace
+	DocumentMetadata(ctx context.Context, source, namespace string) (map[string]string, error)
+	// Dedupe removes duplicated documents and chunks within each namespace
+	Dedupe(ctx context.Context) (DedupeResult, error)
+}
+
+// GarbageCollector is implemented by providers that keep uploaded files apart
+// from the index searching them, so failed ingests can leave files behind
+type GarbageCollector interface {
+	// CollectGarbage removes failed entries from the vector store and the files no
+	// vector store uses
+	CollectGarbage(ctx context.Context, options GCOptions) (GCResult, error)
+}
+
 // FileInfo represents information about a file in the vector store
 type FileInfo struct {
 	ID         string
@@ -43,6 +108,7 @@ type SearchOptions struct {
 	Limit    int               // Maximum number of results
 	MinScore float32           // Minimum relevance score
 	Metadata map[string]string // Filter by metadata
+	Filter   SearchFilter      // Filter by file name, source type and ingestion date
 }
 
 // SearchResult represents a search result from the vector store
@@ -0,0 +1,166 @@
+// Package rag provides rerankers that reorder search results by relevance to the query
+package rag
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Reranker reorders search results, most relevant first, and sets their scores
+type Reranker interface {
+	Rerank(ctx context.Context, query string, results []SearchResult) ([]SearchResult, error)
+}
+
+// CompletionFunc generates a text completion for a prompt
+type CompletionFunc func(ctx context.Context, prompt string) (string, error)
+
+// LLMReranker asks an LLM to order the passages by relevance
+type LLMReranker struct {
+	complete CompletionFunc
+}
+
+// NewLLMReranker creates a reranker backed by the given completion function
+func NewLLMReranker(complete CompletionFunc) *LLMReranker {
+	return &LLMReranker{complete: complete}
+}
+
+// rankingArray matches the JSON array of passage numbers in the LLM's answer
+var rankingArray = regexp.MustCompile(`\[[\d,\s]*\]`)
+
+// Rerank implements Reranker. Passages the LLM leaves out keep their original
+// order after the ranked ones.
+func (r *LLMReranker) Rerank(ctx context.Context, query string, results []SearchResult) ([]SearchResult, error) {
+	if len(results) < 2 {
+		return results, nil
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString("Rank the passages below by how well they answer the query.\n")
+	prompt.WriteString("Respond with ONLY a JSON array of passage numbers, most relevant first, e.g. [3, 1, 2].\n\n")
+	prompt.WriteString(fmt.Sprintf("Query: %s\n", query))
+	for i, result := range results {
+		prompt.WriteString(fmt.Sprintf("\nPassage %d:\n%s\n", i+1, result.Content))
+	}
+
+	answer, err := r.complete(ctx, prompt.String())
+	if err != nil {
+		return nil, fmt.Errorf("LLM rerank failed: %w", err)
+	}
+	match := rankingArray.FindString(answer)
+	if match == "" {
+		return nil, fmt.Errorf("LLM rerank returned no ranking: %q", answer)
+	}
+	var ranking []int
+	if err := json.Unmarshal([]byte(match), &ranking); err != nil {
+		return nil, fmt.Errorf("failed to parse LLM ranking: %w", err)
+	}
+
+	order := make([]int, 0, len(results))
+	seen := make(map[int]bool, len(results))
+	for _, n := range ranking {
+		if n >= 1 && n <= len(results) && !seen[n-1] {
+			order = append(order, n-1)
+			seen[n-1] = true
+		}
+	}
+	for i := range results {
+		if !seen[i] {
+			order = append(order, i)
+		}
+	}
+
+	reranked := make([]SearchResult, len(order))
+	for rank, i := range order {
+		reranked[rank] = results[i]
+		reranked[rank].Score = 1 - float32(rank)/float32(len(order))
+	}
+	return reranked, nil
+}
+
+// CrossEncoderReranker scores passages with a cross-encoder model served behind a
+// Cohere-compatible rerank API (Cohere, Jina, vLLM, text-embeddings-inference)
+type CrossEncoderReranker struct {
+	url        string
+	model      string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewCrossEncoderReranker creates a reranker that POSTs to the given rerank endpoint
+func NewCrossEncoderReranker(url, model, apiKey string, httpClient *http.Client) *CrossEncoderReranker {
+	return &CrossEncoderReranker{url: url, model: model, apiKey: apiKey, httpClient: httpClient}
+}
+
+type rerankRequest struct {
+	Model     string   `json:"model,omitempty"`
+	Query     string   `json:"query"`
+	Documents []string `json:"documents"`
+}
+
+type rerankResponse struct {
+	Results []struct {
+		Index          int     `json:"index"`
+		RelevanceScore float32 `json:"relevance_score"`
+	} `json:"results"`
+}
+
+// Rerank implements Reranker
+func (r *CrossEncoderReranker) Rerank(ctx context.Context, query string, results []SearchResult) ([]SearchResult, error) {
+	if len(results) == 0 {
+		return results, nil
+	}
+
+	documents := make([]string, len(results))
+	for i, result := range results {
+		documents[i] = result.Content
+	}
+	body, err := json.Marshal(rerankRequest{Model: r.model, Query: query, Documents: documents})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal rerank request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rerank request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if r.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+r.apiKey)
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("rerank request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("rerank endpoint returned %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+
+	var parsed rerankResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode rerank response: %w", err)
+	}
+
+	reranked := make([]SearchResult, 0, len(parsed.Results))
+	for _, scored := range parsed.Results {
+		if scored.Index < 0 || scored.Index >= len(results) {
+			continue
+		}
+		result := results[scored.Index]
+		result.Score = scored.RelevanceScore
+		reranked = append(reranked, result)
+	}
+	sort.SliceStable(reranked, func(i, j int) bool {
+		return reranked[i].Score > reranked[j].Score
+	})
+	return reranked, nil
+}
@@ -0,0 +1,85 @@
+package rag
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var rerankResults = []SearchResult{
+	{Content: "Deployments are frozen in December.", FileName: "runbook.pdf"},
+	{Content: "Employees receive 20 days of paid vacation.", FileName: "handbook.pdf"},
+	{Content: "Remote work requires manager approval.", FileName: "handbook.pdf"},
+}
+
+func TestLLMReranker(t *testing.T) {
+	var prompt string
+	reranker := NewLLMReranker(func(_ context.Context, p string) (string, error) {
+		prompt = p
+		return "The ranking is [2, 3].", nil
+	})
+
+	results, err := reranker.Rerank(context.Background(), "how much vacation?", rerankResults)
+	require.NoError(t, err)
+	assert.Contains(t, prompt, "Query: how much vacation?")
+	assert.Contains(t, prompt, "Passage 3:\nRemote work requires manager approval.")
+
+	require.Len(t, results, 3)
+	assert.Equal(t, rerankResults[1].Content, results[0].Content)
+	assert.Equal(t, rerankResults[2].Content, results[1].Content)
+	assert.Equal(t, rerankResults[0].Content, results[2].Content, "unranked passages keep their order at the end")
+	assert.Greater(t, results[0].Score, results[1].Score)
+}
+
+func TestLLMRerankerErrors(t *testing.T) {
+	reranker := NewLLMReranker(func(context.Context, string) (string, error) {
+		return "I cannot rank these.", nil
+	})
+	_, err := reranker.Rerank(context.Background(), "q", rerankResults)
+	assert.Error(t, err)
+
+	reranker = NewLLMReranker(func(context.Context, string) (string, error) {
+		return "", errors.New("provider unavailable")
+	})
+	_, err = reranker.Rerank(context.Background(), "q", rerankResults)
+	assert.Error(t, err)
+}
+
+func TestCrossEncoderReranker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		var req rerankRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "rerank-v1", req.Model)
+		assert.Equal(t, "vacation", req.Query)
+		assert.Len(t, req.Documents, 3)
+		_, _ = w.Write([]byte(`{"results":[{"index":2,"relevance_score":0.2},{"index":1,"relevance_score":0.9}]}`))
+	}))
+	defer server.Close()
+
+	reranker := NewCrossEncoderReranker(server.URL, "rerank-v1", "secret", server.Client())
+	results, err := reranker.Rerank(context.Background(), "vacation", rerankResults)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, rerankResults[1].Content, results[0].Content)
+	assert.InDelta(t, 0.9, results[0].Score, 1e-6)
+	assert.Equal(t, rerankResults[2].Content, results[1].Content)
+}
+
+func TestCrossEncoderRerankerHTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "model not loaded", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	reranker := NewCrossEncoderReranker(server.URL, "", "", server.Client())
+	_, err := reranker.Rerank(context.Background(), "vacation", rerankResults)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "model not loaded")
+}
@@ -2,6 +2,7 @@
 package rag
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -9,16 +10,20 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/tmc/langchaingo/documentloaders"
 	"github.com/tmc/langchaingo/schema"
-	"github.com/tmc/langchaingo/textsplitter"
 )
 
 // SimpleProvider implements VectorProvider using JSON file storage
 type SimpleProvider struct {
-	dbPath    string
+	dbPath   string
+	chunking ChunkingOptions
+
+	// Guards documents, which are ingested in parallel and searched while they are
+	mu        sync.RWMutex
 	documents []SimpleDocument
 }
 
@@ -41,7 +46,7 @@ func NewSimpleProvider(dbPath string) *SimpleProvider {
 		dbPath = "./knowledge.json"
 	}
 
-	provider := &SimpleProvider{dbPath: dbPath}
+	provider := &SimpleProvider{dbPath: dbPath, chunking: DefaultChunkingOptions()}
 	provider.load()
 	return provider
 }
@@ -51,107 +56,199 @@ func (s *SimpleProvider) Initialize(ctx context.Context) error {
 	return nil
 }
 
-// IngestFile implements VectorProvider interface
+// IngestFile implements VectorProvider interface. Re-ingesting a path replaces its
+// previous chunks.
 func (s *SimpleProvider) IngestFile(ctx context.Context, filePath string, metadata map[string]string) (string, error) {
-	// Only support PDF files for now
-	if !strings.HasSuffix(strings.ToLower(filePath), ".pdf") {
-		return "", fmt.Errorf("simple provider only supports PDF files, got: %s", filePath)
+	pages, err := loadPDF(ctx, filePath)
+	if err != nil {
+		return "", err
 	}
-
-	// Load PDF using LangChain Go
-	file, err := os.Open(filePath)
+	allChunks, err := s.chunking.Split(ctx, pages)
 	if err != nil {
-		return "", fmt.Errorf("failed to open PDF file: %w", err)
+		return "", err
 	}
-	defer func() {
-		if err := file.Close(); err != nil {
-			fmt.Printf("Warning: failed to close file: %v\n", err)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Drop the chunks of a previous ingestion of the same file
+	s.removeDocument(filePath, metadata[NamespaceMetadataKey])
+
+	// Convert to our format and add to storage
+	fileID := s.nextFileID()
+
+	for i, chunk := range allChunks {
+		docMetadata := chunkMetadata(metadata, filePath, i, chunk)
+
+		doc := SimpleDocument{
+			ID:       fmt.Sprintf("%s_chunk_%d", fileID, i),
+			Content:  chunk.PageContent,
+			Metadata: docMetadata,
 		}
-	}()
 
-	loader := documentloaders.NewPDF(file, 0)
-	docs, err := loader.Load(ctx)
-	if err != nil {
-		return "", fmt.Errorf("failed to load PDF: %w", err)
+		s.documents = append(s.documents, doc)
 	}
 
-	if len(docs) == 0 {
-		return "", fmt.Errorf("no content found in PDF")
+	// Save to persistent storage
+	if err := s.save(); err != nil {
+		return "", fmt.Errorf("failed to save documents: %w", err)
 	}
+	recordChunks("json", len(allChunks))
 
-	// Split documents into chunks
-	splitter := textsplitter.NewRecursiveCharacter(
-		textsplitter.WithChunkSize(1000),
-		textsplitter.WithChunkOverlap(200),
-	)
+	return fileID, nil
+}
 
-	var allChunks []schema.Document
-	for _, doc := range docs {
-		chunks, err := splitter.SplitText(doc.PageContent)
-		if err != nil {
-			return "", fmt.Errorf("failed to split document: %w", err)
-		}
+// IngestDocument implements DocumentIngester. Re-ingesting a source replaces its
+// previous chunks.
+func (s *SimpleProvider) IngestDocument(ctx context.Context, source, name, text string, metadata map[string]string) (string, error) {
+	chunks, err := s.chunking.Split(ctx, []schema.Document{{PageContent: text}})
+	if err != nil {
+		return "", err
+	}
+	if len(chunks) == 0 {
+		return "", fmt.Errorf("no content found in %s", source)
+	}
 
-		// Convert text chunks to schema.Document
-		for i, chunk := range chunks {
-			chunkDoc := schema.Document{
-				PageContent: chunk,
-				Metadata:    make(map[string]interface{}),
-			}
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-			// Copy original metadata
-			for k, v := range doc.Metadata {
-				chunkDoc.Metadata[k] = v
-			}
+	// Drop the chunks of a previous ingestion of the same source
+	s.removeDocument(source, metadata[NamespaceMetadataKey])
 
-			// Add chunk index
-			chunkDoc.Metadata["chunk_index"] = i
+	fileID := s.nextFileID()
+	for i, chunk := range chunks {
+		docMetadata := chunkMetadata(metadata, source, i, chunk)
+		docMetadata["file_name"] = name
+		s.documents = append(s.documents, SimpleDocument{
+			ID:       fmt.Sprintf("%s_chunk_%d", fileID, i),
+			Content:  chunk.PageContent,
+			Metadata: docMetadata,
+		})
+	}
 
-			allChunks = append(allChunks, chunkDoc)
-		}
+	if err := s.save(); err != nil {
+		return "", fmt.Errorf("failed to save documents: %w", err)
 	}
+	recordChunks("json", len(chunks))
+	return fileID, nil
+}
 
-	// Convert to our format and add to storage
-	fileName := filepath.Base(filePath)
-	fileID := fmt.Sprintf("file_%d", len(s.documents))
+// DocumentVersions implements DocumentVersioner
+func (s *SimpleProvider) DocumentVersions(_ context.Context, filter map[string]string) (map[string]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	versions := make(map[string]string)
+	for _, doc := range s.documents {
+		if matchesMetadata(doc.Metadata, filter) {
+			versions[doc.Metadata["file_path"]] = doc.Metadata["version"]
+		}
+	}
+	return versions, nil
+}
 
-	for i, chunk := range allChunks {
-		docMetadata := make(map[string]string)
+// DeleteDocument implements DocumentVersioner
+func (s *SimpleProvider) DeleteDocument(_ context.Context, source, namespace string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.removeDocument(source, namespace) {
+		return fmt.Errorf("document not found: %s", source)
+	}
+	return s.save()
+}
 
-		// Copy provided metadata
-		for k, v := range metadata {
-			docMetadata[k] = v
+// DocumentMetadata implements ContentIndex
+func (s *SimpleProvider) DocumentMetadata(_ context.Context, source, namespace string) (map[string]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, doc := range s.documents {
+		if doc.Metadata["file_path"] == source && doc.Metadata[NamespaceMetadataKey] == namespace {
+			return doc.Metadata, nil
 		}
+	}
+	return nil, nil
+}
 
-		// Add file information
-		docMetadata["file_name"] = fileName
-		docMetadata["file_path"] = filePath
-		docMetadata["chunk_index"] = fmt.Sprintf("%d", i)
-
-		// Copy chunk metadata
-		for k, v := range chunk.Metadata {
-			if str, ok := v.(string); ok {
-				docMetadata[k] = str
-			} else {
-				docMetadata[k] = fmt.Sprintf("%v", v)
-			}
+// Dedupe implements ContentIndex. Within each namespace it keeps the most recently
+// ingested of the documents sharing a content hash, then removes chunks whose text
+// repeats a more recently ingested chunk.
+func (s *SimpleProvider) Dedupe(_ context.Context) (DedupeResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result DedupeResult
+
+	// Documents are appended as they are ingested, so walk them newest first
+	keptFiles := make(map[string]string) // namespace and content hash -> file ID
+	duplicateFiles := make(map[string]bool)
+	for i := len(s.documents) - 1; i >= 0; i-- {
+		doc := s.documents[i]
+		hash := doc.Metadata[ContentHashMetadataKey]
+		if hash == "" {
+			continue
 		}
-
-		doc := SimpleDocument{
-			ID:       fmt.Sprintf("%s_chunk_%d", fileID, i),
-			Content:  chunk.PageContent,
-			Metadata: docMetadata,
+		fileID := strings.Split(doc.ID, "_chunk_")[0]
+		key := doc.Metadata[NamespaceMetadataKey] + ":" + hash
+		if kept, ok := keptFiles[key]; !ok {
+			keptFiles[key] = fileID
+		} else if kept != fileID && !duplicateFiles[fileID] {
+			duplicateFiles[fileID] = true
+			result.Documents++
 		}
+	}
 
-		s.documents = append(s.documents, doc)
+	seenChunks := make(map[string]bool)
+	kept := make([]SimpleDocument, 0, len(s.documents))
+	for i := len(s.documents) - 1; i >= 0; i-- {
+		doc := s.documents[i]
+		if duplicateFiles[strings.Split(doc.ID, "_chunk_")[0]] {
+			continue
+		}
+		key := chunkHash(doc.Metadata[NamespaceMetadataKey], doc.Content)
+		if seenChunks[key] {
+			result.Chunks++
+			continue
+		}
+		seenChunks[key] = true
+		kept = append(kept, doc)
+	}
+	// Restore ingestion order
+	for i, j := 0, len(kept)-1; i < j; i, j = i+1, j-1 {
+		kept[i], kept[j] = kept[j], kept[i]
 	}
 
-	// Save to persistent storage
+	if result.Documents == 0 && result.Chunks == 0 {
+		return result, nil
+	}
+	s.documents = kept
 	if err := s.save(); err != nil {
-		return "", fmt.Errorf("failed to save documents: %w", err)
+		return result, fmt.Errorf("failed to save documents: %w", err)
 	}
+	return result, nil
+}
 
-	return fileID, nil
+// nextFileID returns a file ID not used by any stored chunk
+func (s *SimpleProvider) nextFileID() string {
+	next := 0
+	for _, doc := range s.documents {
+		var n int
+		if _, err := fmt.Sscanf(doc.ID, "file_%d_chunk_", &n); err == nil && n >= next {
+			next = n + 1
+		}
+	}
+	return fmt.Sprintf("file_%d", next)
+}
+
+// removeDocument drops the chunks stored for a source and reports whether any were
+func (s *SimpleProvider) removeDocument(source, namespace string) bool {
+	kept := s.documents[:0]
+	for _, doc := range s.documents {
+		if doc.Metadata["file_path"] != source || doc.Metadata[NamespaceMetadataKey] != namespace {
+			kept = append(kept, doc)
+		}
+	}
+	removed := len(kept) != len(s.documents)
+	s.documents = kept
+	return removed
 }
 
 // IngestFiles implements VectorProvider interface
@@ -173,6 +270,9 @@ func (s *SimpleProvider) IngestFiles(ctx context.Context, filePaths []string, me
 
 // DeleteFile implements VectorProvider interface
 func (s *SimpleProvider) DeleteFile(ctx context.Context, fileID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	// Remove all documents with matching file ID
 	var filteredDocs []SimpleDocument
 	removed := 0
@@ -201,6 +301,13 @@ func (s *SimpleProvider) DeleteFile(ctx context.Context, fileID string) error {
 
 // ListFiles implements VectorProvider interface
 func (s *SimpleProvider) ListFiles(ctx context.Context, limit int) ([]FileInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.listFiles(limit), nil
+}
+
+// listFiles groups the stored chunks by file; the caller holds mu
+func (s *SimpleProvider) listFiles(limit int) []FileInfo {
 	// Group documents by file
 	fileMap := make(map[string]*FileInfo)
 
@@ -234,11 +341,13 @@ func (s *SimpleProvider) ListFiles(ctx context.Context, limit int) ([]FileInfo,
 		}
 	}
 
-	return files, nil
+	return files
 }
 
 // Search implements VectorProvider interface with improved text search
 func (s *SimpleProvider) Search(ctx context.Context, query string, options SearchOptions) ([]SearchResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	if len(s.documents) == 0 {
 		return []SearchResult{}, nil
 	}
@@ -254,6 +363,9 @@ func (s *SimpleProvider) Search(ctx context.Context, query string, options Searc
 	queryTerms := strings.Fields(queryLower)
 
 	for _, doc := range s.documents {
+		if !matchesMetadata(doc.Metadata, options.Metadata) || !options.Filter.matches(doc.Metadata) {
+			continue
+		}
 		contentLower := strings.ToLower(doc.Content)
 		score := s.calculateRelevanceScore(contentLower, queryLower, queryTerms)
 
@@ -296,15 +408,23 @@ func (s *SimpleProvider) Search(ctx context.Context, query string, options Searc
 	return results, nil
 }
 
+// matchesMetadata reports whether the metadata contains every key/value in filter
+func matchesMetadata(metadata, filter map[string]string) bool {
+	for key, value := range filter {
+		if metadata[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
 // GetStats implements VectorProvider interface
 func (s *SimpleProvider) GetStats(ctx context.Context) (*VectorStoreStats, error) {
-	files, err := s.ListFiles(ctx, 0) // Get all files
-	if err != nil {
-		return nil, err
-	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
 	stats := &VectorStoreStats{
-		TotalFiles:  len(files),
+		TotalFiles:  len(s.listFiles(0)),
 		TotalChunks: len(s.documents),
 		LastUpdated: time.Now(),
 	}
@@ -422,9 +542,9 @@ func (s *SimpleProvider) save() error {
 	return nil
 }
 
-// Register the simple provider
+// Register the JSON provider, kept for deployments that do not want SQLite
 func init() {
-	RegisterVectorProvider("simple", func(config map[string]interface{}) (VectorProvider, error) {
+	RegisterVectorProvider("json", func(config map[string]interface{}) (VectorProvider, error) {
 		dbPath := "./knowledge.json"
 		if path, ok := config["database_path"].(string); ok && path != "" {
 			dbPath = path
@@ -432,3 +552,93 @@ func init() {
 		return NewSimpleProvider(dbPath), nil
 	})
 }
+
+// SetChunking implements ChunkingConfigurer
+func (s *SimpleProvider) SetChunking(options ChunkingOptions) error {
+	options = options.WithDefaults()
+	if err := options.Validate(); err != nil {
+		return err
+	}
+	s.chunking = options
+	return nil
+}
+
+// loadPDF loads the pages of a PDF file
+func loadPDF(ctx context.Context, filePath string) ([]schema.Document, error) {
+	// Only support PDF files for now
+	if !strings.HasSuffix(strings.ToLower(filePath), ".pdf") {
+		return nil, fmt.Errorf("simple provider only supports PDF files, got: %s", filePath)
+	}
+
+	// Load PDF using LangChain Go
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PDF file: %w", err)
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			fmt.Printf("Warning: failed to close file: %v\n", err)
+		}
+	}()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat PDF file: %w", err)
+	}
+
+	loader := documentloaders.NewPDF(file, info.Size())
+	docs, err := loader.Load(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load PDF: %w", err)
+	}
+
+	if len(docs) == 0 {
+		return nil, fmt.Errorf("no content found in PDF")
+	}
+
+	return docs, nil
+}
+
+// PDFToText extracts the text of a PDF held in memory, one page per paragraph
+func PDFToText(ctx context.Context, data []byte) (string, error) {
+	loader := documentloaders.NewPDF(bytes.NewReader(data), int64(len(data)))
+	docs, err := loader.Load(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to load PDF: %w", err)
+	}
+	pages := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		if text := strings.TrimSpace(doc.PageContent); text != "" {
+			pages = append(pages, text)
+		}
+	}
+	return strings.Join(pages, "\n\n"), nil
+}
+
+// chunkMetadata combines the caller's metadata, file information and the chunk's
+// own metadata into the string map stored with each chunk
+func chunkMetadata(metadata map[string]string, filePath string, index int, chunk schema.Document) map[string]string {
+	docMetadata := make(map[string]string)
+
+	// Copy provided metadata
+	for k, v := range metadata {
+		docMetadata[k] = v
+	}
+
+	// Add file information
+	docMetadata["file_name"] = filepath.Base(filePath)
+	docMetadata["file_path"] = filePath
+	docMetadata["chunk_index"] = fmt.Sprintf("%d", index)
+	docMetadata[IngestedAtMetadataKey] = time.Now().UTC().Format(time.RFC3339)
+
+	// Copy chunk metadata
+	for k, v := range chunk.Metadata {
+		if str, ok := v.(string); ok {
+			docMetadata[k] = str
+		} else {
+			docMetadata[k] = fmt.Sprintf("%v", v)
+		}
+	}
+
+	return docMetadata
+}
@@ -0,0 +1,46 @@
+package rag
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimpleProviderSearchesWhileIngesting(t *testing.T) {
+	provider := NewSimpleProvider(filepath.Join(t.TempDir(), "knowledge.json"))
+	ctx := t.Context()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			_, err := provider.IngestDocument(ctx, fmt.Sprintf("doc%d.md", i), "runbook", "restart the api deployment", nil)
+			assert.NoError(t, err)
+		}(i)
+		go func() {
+			defer wg.Done()
+			_, err := provider.Search(ctx, "restart", SearchOptions{Limit: 5})
+			assert.NoError(t, err)
+			_, err = provider.GetStats(ctx)
+			assert.NoError(t, err)
+			_, err = provider.DocumentVersions(ctx, nil)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	stats, err := provider.GetStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 10, stats.TotalChunks)
+	require.NoError(t, provider.DeleteDocument(ctx, "doc0.md", ""))
+	_, err = provider.Dedupe(ctx)
+	require.NoError(t, err)
+	files, err := provider.ListFiles(ctx, 0)
+	require.NoError(t, err)
+	assert.Len(t, files, 1, "files are grouped by name")
+}
@@ -0,0 +1,707 @@
+// Package rag provides an SQLite vector provider implementation using FTS5 full-text search
+package rag
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tmc/langchaingo/embeddings"
+	"github.com/tmc/langchaingo/schema"
+	_ "modernc.org/sqlite" // Pure Go SQLite driver with FTS5
+)
+
+// sqliteHeader is the magic string at the start of every SQLite database file
+var sqliteHeader = []byte("SQLite format 3\x00")
+
+// sqliteSchema creates the chunk table, its FTS5 index (kept in sync by triggers)
+// and the optional chunk embeddings
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS documents (
+	id INTEGER PRIMARY KEY,
+	file_id TEXT NOT NULL,
+	file_name TEXT NOT NULL,
+	file_path TEXT NOT NULL,
+	chunk_index INTEGER NOT NULL,
+	content TEXT NOT NULL,
+	metadata TEXT NOT NULL,
+	ingested_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_documents_file_id ON documents(file_id);
+CREATE INDEX IF NOT EXISTS idx_documents_file_path ON documents(file_path);
+CREATE INDEX IF NOT EXISTS idx_documents_content_hash ON documents(json_extract(metadata, '$.content_hash'));
+
+CREATE VIRTUAL TABLE IF NOT EXISTS documents_fts USING fts5(
+	content,
+	file_name,
+	content=documents,
+	content_rowid=id,
+	tokenize='porter unicode61'
+);
+
+CREATE TABLE IF NOT EXISTS embeddings (
+	document_id INTEGER PRIMARY KEY REFERENCES documents(id) ON DELETE CASCADE,
+	model TEXT NOT NULL,
+	vector BLOB NOT NULL
+);
+
+CREATE TRIGGER IF NOT EXISTS documents_fts_insert AFTER INSERT ON documents BEGIN
+	INSERT INTO documents_fts(rowid, content, file_name) VALUES (NEW.id, NEW.content, NEW.file_name);
+END;
+CREATE TRIGGER IF NOT EXISTS documents_fts_delete AFTER DELETE ON documents BEGIN
+	INSERT INTO documents_fts(documents_fts, rowid, content, file_name) VALUES ('delete', OLD.id, OLD.content, OLD.file_name);
+END;
+CREATE TRIGGER IF NOT EXISTS documents_fts_update AFTER UPDATE ON documents BEGIN
+	INSERT INTO documents_fts(documents_fts, rowid, content, file_name) VALUES ('delete', OLD.id, OLD.content, OLD.file_name);
+	INSERT INTO documents_fts(rowid, content, file_name) VALUES (NEW.id, NEW.content, NEW.file_name);
+END;
+`
+
+// SQLiteProvider implements VectorProvider using an SQLite database with an FTS5
+// index. Ingestion inserts only the new chunks and search is ranked with BM25.
+type SQLiteProvider struct {
+	dbPath string
+	db     *sql.DB
+
+	// Set by SetEmbedder and SetChunking before the provider is used
+	embedder embeddings.Embedder
+	batcher  *embedBatcher // Embeds the chunks of files ingested in parallel together
+	hybrid   HybridOptions
+	chunking ChunkingOptions
+}
+
+// NewSQLiteProvider opens (or creates) an SQLite knowledge base. A path ending in
+// ".json", or an existing JSON database at dbPath, is migrated into SQLite; the
+// original JSON file is kept with a ".migrated" suffix.
+func NewSQLiteProvider(dbPath string) (*SQLiteProvider, error) {
+	if dbPath == "" {
+		dbPath = "./knowledge.db"
+	}
+
+	var legacyJSON string
+	if strings.EqualFold(filepath.Ext(dbPath), ".json") {
+		legacyJSON = dbPath
+		dbPath = strings.TrimSuffix(dbPath, filepath.Ext(dbPath)) + ".db"
+	} else if isJSONDatabase(dbPath) {
+		legacyJSON = dbPath + ".json"
+		if err := os.Rename(dbPath, legacyJSON); err != nil {
+			return nil, fmt.Errorf("failed to move JSON database aside for migration: %w", err)
+		}
+	}
+
+	if dir := filepath.Dir(dbPath); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create directory: %w", err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SQLite database: %w", err)
+	}
+	// SQLite allows a single writer; serializing connections avoids SQLITE_BUSY
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to create SQLite schema: %w", err)
+	}
+
+	provider := &SQLiteProvider{dbPath: dbPath, db: db, chunking: DefaultChunkingOptions()}
+	if legacyJSON != "" {
+		if _, err := os.Stat(legacyJSON); err == nil {
+			migrated, err := provider.migrateJSON(legacyJSON)
+			if err != nil {
+				_ = db.Close()
+				return nil, err
+			}
+			fmt.Printf("Migrated %d chunks from %s to %s\n", migrated, legacyJSON, dbPath)
+		}
+	}
+
+	return provider, nil
+}
+
+// Initialize implements VectorProvider interface (schema is created when opening)
+func (s *SQLiteProvider) Initialize(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+// IngestFile implements VectorProvider interface. Re-ingesting a path replaces its
+// previous chunks.
+func (s *SQLiteProvider) IngestFile(ctx context.Context, filePath string, metadata map[string]string) (string, error) {
+	pages, err := loadPDF(ctx, filePath)
+	if err != nil {
+		return "", err
+	}
+	chunks, err := s.chunking.Split(ctx, pages)
+	if err != nil {
+		return "", err
+	}
+	return s.storeChunks(ctx, filePath, filepath.Base(filePath), chunks, metadata)
+}
+
+// SetChunking implements ChunkingConfigurer
+func (s *SQLiteProvider) SetChunking(options ChunkingOptions) error {
+	options = options.WithDefaults()
+	if err := options.Validate(); err != nil {
+		return err
+	}
+	s.chunking = options
+	return nil
+}
+
+// IngestDocument implements DocumentIngester. Re-ingesting a source replaces its
+// previous chunks.
+func (s *SQLiteProvider) IngestDocument(ctx context.Context, source, name, text string, metadata map[string]string) (string, error) {
+	chunks, err := s.chunking.Split(ctx, []schema.Document{{PageContent: text}})
+	if err != nil {
+		return "", err
+	}
+	if len(chunks) == 0 {
+		return "", fmt.Errorf("no content found in %s", source)
+	}
+	return s.storeChunks(ctx, source, name, chunks, metadata)
+}
+
+// DocumentVersions implements DocumentVersioner
+func (s *SQLiteProvider) DocumentVersions(ctx context.Context, filter map[string]string) (map[string]string, error) {
+	where, args := metadataFilter(filter)
+	rows, err := s.db.QueryContext(ctx, `SELECT d.file_path, COALESCE(MAX(json_extract(d.metadata, '$.version')), '')
+		FROM documents d WHERE 1 = 1`+where+` GROUP BY d.file_id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read document versions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	versions := make(map[string]string)
+	for rows.Next() {
+		var source, version string
+		if err := rows.Scan(&source, &version); err != nil {
+			return nil, fmt.Errorf("failed to read document version: %w", err)
+		}
+		versions[source] = version
+	}
+	return versions, rows.Err()
+}
+
+// DeleteDocument implements DocumentVersioner
+func (s *SQLiteProvider) DeleteDocument(ctx context.Context, source, namespace string) error {
+	return s.DeleteFile(ctx, sqliteFileID(source, namespace))
+}
+
+// DocumentMetadata implements ContentIndex
+func (s *SQLiteProvider) DocumentMetadata(ctx context.Context, source, namespace string) (map[string]string, error) {
+	var metadataJSON string
+	err := s.db.QueryRowContext(ctx, `SELECT metadata FROM documents WHERE file_id = ? ORDER BY chunk_index LIMIT 1`,
+		sqliteFileID(source, namespace)).Scan(&metadataJSON)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read document metadata: %w", err)
+	}
+	metadata := make(map[string]string)
+	if err := json.Unmarshal([]byte(metadataJSON), &metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse document metadata: %w", err)
+	}
+	return metadata, nil
+}
+
+// Dedupe implements ContentIndex. Within each namespace it keeps the most recently
+// ingested of the documents sharing a content hash, then removes chunks whose text
+// repeats a more recently ingested chunk.
+func (s *SQLiteProvider) Dedupe(ctx context.Context) (DedupeResult, error) {
+	var result DedupeResult
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return result, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	rows, err := tx.QueryContext(ctx, `SELECT file_id, COALESCE(MAX(json_extract(metadata, '$.namespace')), ''), MAX(json_extract(metadata, '$.content_hash'))
+		FROM documents WHERE json_extract(metadata, '$.content_hash') IS NOT NULL GROUP BY file_id ORDER BY MAX(id) DESC`)
+	if err != nil {
+		return result, fmt.Errorf("failed to read content hashes: %w", err)
+	}
+	var duplicateFiles []string
+	seen := make(map[string]bool)
+	for rows.Next() {
+		var fileID, namespace, hash string
+		if err := rows.Scan(&fileID, &namespace, &hash); err != nil {
+			_ = rows.Close()
+			return result, fmt.Errorf("failed to read content hash: %w", err)
+		}
+		if key := namespace + ":" + hash; seen[key] {
+			duplicateFiles = append(duplicateFiles, fileID)
+		} else {
+			seen[key] = true
+		}
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return result, fmt.Errorf("failed to read content hashes: %w", err)
+	}
+	for _, fileID := range duplicateFiles {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM documents WHERE file_id = ?`, fileID); err != nil {
+			return result, fmt.Errorf("failed to delete duplicate document: %w", err)
+		}
+	}
+	result.Documents = len(duplicateFiles)
+
+	rows, err = tx.QueryContext(ctx, `SELECT id, COALESCE(json_extract(metadata, '$.namespace'), ''), content FROM documents ORDER BY id DESC`)
+	if err != nil {
+		return result, fmt.Errorf("failed to read chunks: %w", err)
+	}
+	var duplicateChunks []int64
+	seen = make(map[string]bool)
+	for rows.Next() {
+		var id int64
+		var namespace, content string
+		if err := rows.Scan(&id, &namespace, &content); err != nil {
+			_ = rows.Close()
+			return result, fmt.Errorf("failed to read chunk: %w", err)
+		}
+		if key := chunkHash(namespace, content); seen[key] {
+			duplicateChunks = append(duplicateChunks, id)
+		} else {
+			seen[key] = true
+		}
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return result, fmt.Errorf("failed to read chunks: %w", err)
+	}
+	for _, id := range duplicateChunks {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM documents WHERE id = ?`, id); err != nil {
+			return result, fmt.Errorf("failed to delete duplicate chunk: %w", err)
+		}
+	}
+	result.Chunks = len(duplicateChunks)
+
+	if err := tx.Commit(); err != nil {
+		return result, fmt.Errorf("failed to commit deduplication: %w", err)
+	}
+	return result, nil
+}
+
+// storeChunks replaces the chunks stored for a source, embedding them when vector
+// search is enabled
+func (s *SQLiteProvider) storeChunks(ctx context.Context, source, name string, chunks []schema.Document, metadata map[string]string) (string, error) {
+	var err error
+
+	// Embed before the transaction so the database is not locked during API calls
+	var vectors [][]float32
+	if s.embedder != nil {
+		texts := make([]string, len(chunks))
+		for i, chunk := range chunks {
+			texts[i] = chunk.PageContent
+		}
+		vectors, err = s.batcher.embed(ctx, texts)
+		if err != nil || len(vectors) != len(chunks) {
+			fmt.Printf("Warning: failed to embed chunks of %s, they will be embedded later: %v\n", source, err)
+			vectors = nil
+		}
+	}
+
+	fileID := sqliteFileID(source, metadata[NamespaceMetadataKey])
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM documents WHERE file_id = ?`, fileID); err != nil {
+		return "", fmt.Errorf("failed to replace existing chunks: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO documents (file_id, file_name, file_path, chunk_index, content, metadata) VALUES (?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return "", fmt.Errorf("failed to prepare insert: %w", err)
+	}
+	defer func() { _ = stmt.Close() }()
+
+	for i, chunk := range chunks {
+		chunkMeta := chunkMetadata(metadata, source, i, chunk)
+		chunkMeta["file_name"] = name
+		metadataJSON, err := json.Marshal(chunkMeta)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal chunk metadata: %w", err)
+		}
+		result, err := stmt.ExecContext(ctx, fileID, name, source, i, chunk.PageContent, string(metadataJSON))
+		if err != nil {
+			return "", fmt.Errorf("failed to insert chunk: %w", err)
+		}
+		if vectors != nil {
+			documentID, err := result.LastInsertId()
+			if err != nil {
+				return "", fmt.Errorf("failed to read chunk ID: %w", err)
+			}
+			if err := s.storeVector(ctx, tx, documentID, vectors[i]); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to commit chunks: %w", err)
+	}
+	recordChunks("sqlite", len(chunks))
+	return fileID, nil
+}
+
+// IngestFiles implements VectorProvider interface
+func (s *SQLiteProvider) IngestFiles(ctx context.Context, filePaths []string, metadata map[string]string) ([]string, error) {
+	fileIDs := make([]string, 0, len(filePaths))
+
+	for _, filePath := range filePaths {
+		fileID, err := s.IngestFile(ctx, filePath, metadata)
+		if err != nil {
+			// Log error but continue with other files
+			fmt.Printf("Warning: failed to ingest %s: %v\n", filePath, err)
+			continue
+		}
+		fileIDs = append(fileIDs, fileID)
+	}
+
+	return fileIDs, nil
+}
+
+// DeleteFile implements VectorProvider interface
+func (s *SQLiteProvider) DeleteFile(ctx context.Context, fileID string) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM documents WHERE file_id = ?`, fileID)
+	if err != nil {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	if removed, err := result.RowsAffected(); err == nil && removed == 0 {
+		return fmt.Errorf("file not found: %s", fileID)
+	}
+	return nil
+}
+
+// ListFiles implements VectorProvider interface (Size is the number of chunks)
+func (s *SQLiteProvider) ListFiles(ctx context.Context, limit int) ([]FileInfo, error) {
+	query := `SELECT file_id, file_name, file_path, COALESCE(MAX(json_extract(metadata, '$.namespace')), ''), COUNT(*), MAX(ingested_at)
+		FROM documents GROUP BY file_id ORDER BY file_name`
+	args := []interface{}{}
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var files []FileInfo
+	for rows.Next() {
+		var info FileInfo
+		var filePath, namespace string
+		var uploadedAt sqliteTime
+		if err := rows.Scan(&info.ID, &info.Name, &filePath, &namespace, &info.Size, &uploadedAt); err != nil {
+			return nil, fmt.Errorf("failed to read file row: %w", err)
+		}
+		info.UploadedAt = uploadedAt.Time
+		info.Metadata = map[string]string{"file_path": filePath}
+		if namespace != "" {
+			info.Metadata[NamespaceMetadataKey] = namespace
+		}
+		info.Status = "completed"
+		files = append(files, info)
+	}
+	return files, rows.Err()
+}
+
+// Search implements VectorProvider interface. Keyword search uses FTS5 with BM25
+// ranking; vector and hybrid modes are enabled with SetEmbedder.
+func (s *SQLiteProvider) Search(ctx context.Context, query string, options SearchOptions) ([]SearchResult, error) {
+	limit := options.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+
+	queryTerms := searchTerms(query)
+	var scored []scoredChunk
+	var err error
+	switch s.hybrid.Mode {
+	case SearchModeVector:
+		scored, err = s.vectorSearch(ctx, query, options, limit)
+	case SearchModeHybrid:
+		scored, err = s.hybridSearch(ctx, query, queryTerms, options, limit)
+	default:
+		scored, err = s.keywordSearch(ctx, queryTerms, options, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	results := []SearchResult{}
+	for _, chunk := range scored {
+		if float32(chunk.score) < options.MinScore {
+			continue
+		}
+		chunk.result.Score = float32(chunk.score)
+		chunk.result.Highlights = matchedTerms(chunk.result.Content, queryTerms)
+		results = append(results, chunk.result)
+	}
+	return results, nil
+}
+
+// scoredChunk is a search candidate with its document row ID
+type scoredChunk struct {
+	id     int64
+	result SearchResult
+	score  float64
+}
+
+// keywordSearch returns the chunks matching any query term, ranked by BM25
+func (s *SQLiteProvider) keywordSearch(ctx context.Context, queryTerms []string, options SearchOptions, limit int) ([]scoredChunk, error) {
+	matchQuery := ftsMatchQuery(queryTerms)
+	if matchQuery == "" {
+		return nil, nil
+	}
+
+	// bm25() is lower for better matches; negate it so higher scores are better
+	filter, filterArgs := searchFilter(options)
+	sqlQuery := `SELECT d.id, d.content, d.file_path, d.file_name, d.metadata, d.ingested_at, -bm25(documents_fts) AS score
+		FROM documents_fts JOIN documents d ON d.id = documents_fts.rowid
+		WHERE documents_fts MATCH ?` + filter + ` ORDER BY score DESC LIMIT ?`
+	args := append(append([]interface{}{matchQuery}, filterArgs...), limit)
+
+	rows, err := s.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("search query failed: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var chunks []scoredChunk
+	for rows.Next() {
+		var chunk scoredChunk
+		var metadataJSON string
+		var ingestedAt sqliteTime
+		if err := rows.Scan(&chunk.id, &chunk.result.Content, &chunk.result.FileID, &chunk.result.FileName, &metadataJSON, &ingestedAt, &chunk.score); err != nil {
+			return nil, fmt.Errorf("failed to read search result: %w", err)
+		}
+		if err := json.Unmarshal([]byte(metadataJSON), &chunk.result.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to parse chunk metadata: %w", err)
+		}
+		setIngestedAt(&chunk.result, ingestedAt)
+		chunks = append(chunks, chunk)
+	}
+	return chunks, rows.Err()
+}
+
+// metadataFilter returns SQL conditions (and their arguments) requiring chunk
+// metadata to match every key/value pair
+func metadataFilter(metadata map[string]string) (string, []interface{}) {
+	var filter strings.Builder
+	var args []interface{}
+	for key, value := range metadata {
+		filter.WriteString(` AND COALESCE(json_extract(d.metadata, ?), '') = ?`)
+		args = append(args, "$."+jsonPathKey(key), value)
+	}
+	return filter.String(), args
+}
+
+// searchFilter returns the SQL conditions (and their arguments) of the metadata
+// and the filter of search options
+func searchFilter(options SearchOptions) (string, []interface{}) {
+	filter, args := metadataFilter(options.Metadata)
+	conditions, conditionArgs := options.Filter.sql()
+	return filter + conditions, append(args, conditionArgs...)
+}
+
+// GetStats implements VectorProvider interface
+func (s *SQLiteProvider) GetStats(ctx context.Context) (*VectorStoreStats, error) {
+	stats := &VectorStoreStats{}
+	var lastUpdated sqliteTime
+	row := s.db.QueryRowContext(ctx, `SELECT COUNT(DISTINCT file_id), COUNT(*), COALESCE(MAX(ingested_at), '') FROM documents`)
+	if err := row.Scan(&stats.TotalFiles, &stats.TotalChunks, &lastUpdated); err != nil {
+		return nil, fmt.Errorf("failed to read stats: %w", err)
+	}
+	stats.LastUpdated = lastUpdated.Time
+	if info, err := os.Stat(s.dbPath); err == nil {
+		stats.StorageSizeBytes = info.Size()
+	}
+	return stats, nil
+}
+
+// Close implements VectorProvider interface
+func (s *SQLiteProvider) Close() error {
+	if s.batcher != nil {
+		s.batcher.close()
+	}
+	return s.db.Close()
+}
+
+// migrateJSON imports a JSON knowledge base written by the JSON provider and
+// renames the JSON file so the import only happens once
+func (s *SQLiteProvider) migrateJSON(jsonPath string) (int, error) {
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read JSON database: %w", err)
+	}
+	var documents []SimpleDocument
+	if err := json.Unmarshal(data, &documents); err != nil {
+		return 0, fmt.Errorf("failed to parse JSON database: %w", err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.Prepare(`INSERT INTO documents (file_id, file_name, file_path, chunk_index, content, metadata) VALUES (?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare insert: %w", err)
+	}
+	defer func() { _ = stmt.Close() }()
+
+	for i, doc := range documents {
+		fileID := strings.Split(doc.ID, "_chunk_")[0]
+		var chunkIndex int
+		_, _ = fmt.Sscanf(doc.Metadata["chunk_index"], "%d", &chunkIndex)
+		metadataJSON, err := json.Marshal(doc.Metadata)
+		if err != nil {
+			return 0, fmt.Errorf("failed to marshal chunk metadata: %w", err)
+		}
+		if _, err := stmt.Exec(fileID, doc.Metadata["file_name"], doc.Metadata["file_path"], chunkIndex, doc.Content, string(metadataJSON)); err != nil {
+			return 0, fmt.Errorf("migration failed at document %d: %w", i, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit migration: %w", err)
+	}
+
+	if err := os.Rename(jsonPath, jsonPath+".migrated"); err != nil {
+		return 0, fmt.Errorf("failed to rename migrated JSON database: %w", err)
+	}
+	return len(documents), nil
+}
+
+// isJSONDatabase reports whether path is an existing JSON knowledge base rather
+// than an SQLite file, e.g. a "rag.db" written by an older version
+func isJSONDatabase(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil || len(data) == 0 || bytes.HasPrefix(data, sqliteHeader) {
+		return false
+	}
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte("["))
+}
+
+// sqliteFileID derives a stable file ID from the ingested path and namespace, so the
+// same file can be ingested into several namespaces
+func sqliteFileID(filePath, namespace string) string {
+	if !strings.Contains(filePath, "://") {
+		if absPath, err := filepath.Abs(filePath); err == nil {
+			filePath = absPath
+		}
+	}
+	if namespace != "" {
+		filePath = namespace + ":" + filePath
+	}
+	sum := sha256.Sum256([]byte(filePath))
+	return "file_" + hex.EncodeToString(sum[:6])
+}
+
+// searchTerms splits a query into lowercase terms without surrounding punctuation
+func searchTerms(query string) []string {
+	var terms []string
+	for _, term := range strings.Fields(strings.ToLower(query)) {
+		if term = strings.Trim(term, `.,;:!?()[]{}'"`); term != "" {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// ftsMatchQuery builds an FTS5 query matching any of the terms. Each term is quoted
+// so punctuation in user queries is not parsed as FTS5 syntax.
+func ftsMatchQuery(terms []string) string {
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+	}
+	return strings.Join(quoted, " OR ")
+}
+
+// jsonPathKey quotes a metadata key for use in a JSON path
+func jsonPathKey(key string) string {
+	return `"` + strings.ReplaceAll(key, `"`, `\"`) + `"`
+}
+
+// matchedTerms returns the query terms that appear in the content
+func matchedTerms(content string, queryTerms []string) []string {
+	var highlights []string
+	contentLower := strings.ToLower(content)
+
+	for _, term := range queryTerms {
+		if len(term) > 2 && strings.Contains(contentLower, term) {
+			highlights = append(highlights, term)
+		}
+	}
+
+	return highlights
+}
+
+// sqliteTime scans SQLite DATETIME values, which the driver may return as text
+type sqliteTime struct {
+	time.Time
+}
+
+// Scan implements sql.Scanner
+func (t *sqliteTime) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case time.Time:
+		t.Time = v
+	case string:
+		if v == "" {
+			return nil
+		}
+		parsed, err := time.Parse("2006-01-02 15:04:05", v)
+		if err != nil {
+			return fmt.Errorf("invalid timestamp %q: %w", v, err)
+		}
+		t.Time = parsed
+	case nil:
+	default:
+		return fmt.Errorf("unsupported timestamp type %T", value)
+	}
+	return nil
+}
+
+// setIngestedAt records the ingestion time of a chunk stored before its metadata
+// held one
+func setIngestedAt(result *SearchResult, ingestedAt sqliteTime) {
+	if ingestedAt.IsZero() || result.Metadata[IngestedAtMetadataKey] != "" {
+		return
+	}
+	if result.Metadata == nil {
+		result.Metadata = make(map[string]string)
+	}
+	result.Metadata[IngestedAtMetadataKey] = ingestedAt.UTC().Format(time.RFC3339)
+}
+
+// Register the SQLite provider, which also backs the default "simple" provider
+func init() {
+	factory := func(config map[string]interface{}) (VectorProvider, error) {
+		dbPath := "./knowledge.db"
+		if path, ok := config["database_path"].(string); ok && path != "" {
+			dbPath = path
+		}
+		return NewSQLiteProvider(dbPath)
+	}
+	RegisterVectorProvider("simple", factory)
+	RegisterVectorProvider("sqlite", factory)
+}
@@ -0,0 +1,90 @@
+package rag
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeJSONDatabase(t *testing.T, path string) {
+	t.Helper()
+	documents := []SimpleDocument{
+		{ID: "file_0_chunk_0", Content: "Employees receive 20 days of paid vacation per year.", Metadata: map[string]string{"file_name": "handbook.pdf", "file_path": "kb/handbook.pdf", "chunk_index": "0", "team": "hr"}},
+		{ID: "file_0_chunk_1", Content: "Remote work requires manager approval.", Metadata: map[string]string{"file_name": "handbook.pdf", "file_path": "kb/handbook.pdf", "chunk_index": "1", "team": "hr"}},
+		{ID: "file_1_chunk_0", Content: "Deployments are frozen during the vacation season.", Metadata: map[string]string{"file_name": "runbook.pdf", "file_path": "kb/runbook.pdf", "chunk_index": "0", "team": "sre"}},
+	}
+	data, err := json.Marshal(documents)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0644))
+}
+
+func TestSQLiteProviderMigratesJSON(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "knowledge.json")
+	writeJSONDatabase(t, jsonPath)
+
+	provider, err := NewSQLiteProvider(jsonPath)
+	require.NoError(t, err)
+	defer func() { _ = provider.Close() }()
+
+	assert.FileExists(t, filepath.Join(dir, "knowledge.db"))
+	assert.FileExists(t, jsonPath+".migrated")
+	assert.NoFileExists(t, jsonPath)
+
+	ctx := context.Background()
+	stats, err := provider.GetStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.TotalFiles)
+	assert.Equal(t, 3, stats.TotalChunks)
+
+	files, err := provider.ListFiles(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	assert.Equal(t, "file_0", files[0].ID)
+	assert.Equal(t, int64(2), files[0].Size)
+
+	results, err := provider.Search(ctx, "paid vacation?", SearchOptions{})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "handbook.pdf", results[0].FileName)
+	assert.Equal(t, "hr", results[0].Metadata["team"])
+	assert.Greater(t, results[0].Score, results[1].Score)
+	assert.Contains(t, results[0].Highlights, "vacation")
+
+	results, err = provider.Search(ctx, "vacation", SearchOptions{Metadata: map[string]string{"team": "sre"}})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "runbook.pdf", results[0].FileName)
+
+	require.NoError(t, provider.DeleteFile(ctx, "file_0"))
+	assert.Error(t, provider.DeleteFile(ctx, "file_0"))
+	results, err = provider.Search(ctx, "vacation", SearchOptions{})
+	require.NoError(t, err)
+	assert.Len(t, results, 1)
+}
+
+func TestSQLiteProviderMigratesJSONAtDBPath(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "rag.db")
+	writeJSONDatabase(t, dbPath)
+
+	provider, err := NewSQLiteProvider(dbPath)
+	require.NoError(t, err)
+	defer func() { _ = provider.Close() }()
+
+	assert.FileExists(t, dbPath+".json.migrated")
+	stats, err := provider.GetStats(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, stats.TotalChunks)
+}
+
+func TestFTSMatchQuery(t *testing.T) {
+	assert.Equal(t, `"what's" OR "the" OR "policy"`, ftsMatchQuery(searchTerms("What's the policy?")))
+	assert.Equal(t, "", ftsMatchQuery(searchTerms("? !")))
+	assert.Equal(t, `"a""b"`, ftsMatchQuery([]string{`a"b`}))
+}
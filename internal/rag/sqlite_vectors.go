@@ -0,0 +1,227 @@
+package rag
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/tmc/langchaingo/embeddings"
+
+	"github.com/tuannvm/slack-mcp-client/internal/llm"
+)
+
+// embedBatchSize bounds the number of chunks sent per embedding request
+const embedBatchSize = 64
+
+// SetEmbedder implements VectorSearcher. Chunks ingested afterwards are embedded
+// as they are stored, in batches shared by the files ingested at the same time.
+func (s *SQLiteProvider) SetEmbedder(embedder embeddings.Embedder, options HybridOptions) {
+	if s.batcher != nil {
+		s.batcher.close()
+	}
+	s.embedder = embedder
+	s.batcher = newEmbedBatcher(embedder, embedBatchSize)
+	s.hybrid = options
+}
+
+// EmbedMissing implements VectorSearcher
+func (s *SQLiteProvider) EmbedMissing(ctx context.Context) (int, error) {
+	if s.embedder == nil {
+		return 0, nil
+	}
+
+	embedded := 0
+	for {
+		rows, err := s.db.QueryContext(ctx, `SELECT d.id, d.content FROM documents d
+			LEFT JOIN embeddings e ON e.document_id = d.id AND e.model = ?
+			WHERE e.document_id IS NULL ORDER BY d.id LIMIT ?`, s.hybrid.EmbeddingModel, embedBatchSize)
+		if err != nil {
+			return embedded, fmt.Errorf("failed to find chunks without embeddings: %w", err)
+		}
+		var ids []int64
+		var texts []string
+		for rows.Next() {
+			var id int64
+			var content string
+			if err := rows.Scan(&id, &content); err != nil {
+				_ = rows.Close()
+				return embedded, fmt.Errorf("failed to read chunk: %w", err)
+			}
+			ids = append(ids, id)
+			texts = append(texts, content)
+		}
+		_ = rows.Close()
+		if len(ids) == 0 {
+			return embedded, nil
+		}
+
+		vectors, err := s.embedder.EmbedDocuments(ctx, texts)
+		if err != nil {
+			return embedded, fmt.Errorf("failed to embed chunks: %w", err)
+		}
+		if len(vectors) != len(ids) {
+			return embedded, fmt.Errorf("embedder returned %d vectors for %d chunks", len(vectors), len(ids))
+		}
+
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return embedded, fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		for i, id := range ids {
+			if err := s.storeVector(ctx, tx, id, vectors[i]); err != nil {
+				_ = tx.Rollback()
+				return embedded, err
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			return embedded, fmt.Errorf("failed to commit embeddings: %w", err)
+		}
+		embedded += len(ids)
+	}
+}
+
+// storeVector saves (or replaces) the embedding of a chunk
+func (s *SQLiteProvider) storeVector(ctx context.Context, tx *sql.Tx, documentID int64, vector []float32) error {
+	_, err := tx.ExecContext(ctx, `INSERT OR REPLACE INTO embeddings (document_id, model, vector) VALUES (?, ?, ?)`,
+		documentID, s.hybrid.EmbeddingModel, encodeVector(vector))
+	if err != nil {
+		return fmt.Errorf("failed to store embedding: %w", err)
+	}
+	return nil
+}
+
+// vectorSearch returns the chunks most similar to the query by cosine similarity
+func (s *SQLiteProvider) vectorSearch(ctx context.Context, query string, options SearchOptions, limit int) ([]scoredChunk, error) {
+	if s.embedder == nil {
+		return nil, fmt.Errorf("vector search requires an embedder")
+	}
+	queryVector, err := s.embedder.EmbedQuery(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+
+	filter, filterArgs := searchFilter(options)
+	rows, err := s.db.QueryContext(ctx, `SELECT d.id, d.content, d.file_path, d.file_name, d.metadata, d.ingested_at, e.vector
+		FROM embeddings e JOIN documents d ON d.id = e.document_id
+		WHERE e.model = ?`+filter, append([]interface{}{s.hybrid.EmbeddingModel}, filterArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("vector search query failed: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var chunks []scoredChunk
+	for rows.Next() {
+		var chunk scoredChunk
+		var metadataJSON string
+		var vector []byte
+		var ingestedAt sqliteTime
+		if err := rows.Scan(&chunk.id, &chunk.result.Content, &chunk.result.FileID, &chunk.result.FileName, &metadataJSON, &ingestedAt, &vector); err != nil {
+			return nil, fmt.Errorf("failed to read search result: %w", err)
+		}
+		chunk.score = llm.CosineSimilarity(queryVector, decodeVector(vector))
+		if chunk.score <= 0 {
+			continue
+		}
+		if err := json.Unmarshal([]byte(metadataJSON), &chunk.result.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to parse chunk metadata: %w", err)
+		}
+		setIngestedAt(&chunk.result, ingestedAt)
+		chunks = append(chunks, chunk)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sortChunks(chunks)
+	if len(chunks) > limit {
+		chunks = chunks[:limit]
+	}
+	return chunks, nil
+}
+
+// hybridSearch fuses keyword and vector candidates. Each score is normalized by the
+// best score of its method, then weighted by VectorWeight. If the query cannot be
+// embedded the keyword results are returned.
+func (s *SQLiteProvider) hybridSearch(ctx context.Context, query string, queryTerms []string, options SearchOptions, limit int) ([]scoredChunk, error) {
+	candidates := s.hybrid.Candidates
+	if candidates < limit {
+		candidates = limit
+	}
+
+	keyword, err := s.keywordSearch(ctx, queryTerms, options, candidates)
+	if err != nil {
+		return nil, err
+	}
+	vector, err := s.vectorSearch(ctx, query, options, candidates)
+	if err != nil {
+		fmt.Printf("Warning: vector search failed, using keyword results: %v\n", err)
+		if len(keyword) > limit {
+			keyword = keyword[:limit]
+		}
+		return keyword, nil
+	}
+
+	fused := make(map[int64]*scoredChunk)
+	addScores := func(chunks []scoredChunk, weight float64) {
+		if len(chunks) == 0 {
+			return
+		}
+		best := chunks[0].score
+		for _, chunk := range chunks {
+			normalized := 0.0
+			if best > 0 {
+				normalized = chunk.score / best
+			}
+			if existing, ok := fused[chunk.id]; ok {
+				existing.score += weight * normalized
+				continue
+			}
+			chunk.score = weight * normalized
+			fused[chunk.id] = &chunk
+		}
+	}
+	addScores(keyword, 1-s.hybrid.VectorWeight)
+	addScores(vector, s.hybrid.VectorWeight)
+
+	chunks := make([]scoredChunk, 0, len(fused))
+	for _, chunk := range fused {
+		chunks = append(chunks, *chunk)
+	}
+	sortChunks(chunks)
+	if len(chunks) > limit {
+		chunks = chunks[:limit]
+	}
+	return chunks, nil
+}
+
+// sortChunks orders chunks by descending score, then by row ID for stability
+func sortChunks(chunks []scoredChunk) {
+	sort.Slice(chunks, func(i, j int) bool {
+		if chunks[i].score != chunks[j].score {
+			return chunks[i].score > chunks[j].score
+		}
+		return chunks[i].id < chunks[j].id
+	})
+}
+
+// encodeVector serializes a vector as little-endian float32 values
+func encodeVector(vector []float32) []byte {
+	data := make([]byte, 4*len(vector))
+	for i, value := range vector {
+		binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(value))
+	}
+	return data
+}
+
+// decodeVector is the inverse of encodeVector
+func decodeVector(data []byte) []float32 {
+	vector := make([]float32, len(data)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:]))
+	}
+	return vector
+}
@@ -0,0 +1,107 @@
+package rag
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// conceptEmbedder maps words to shared concept dimensions, so synonyms that
+// keyword search cannot match are close in vector space
+type conceptEmbedder struct {
+	concepts [][]string
+}
+
+func (e *conceptEmbedder) embed(text string) []float32 {
+	text = strings.ToLower(text)
+	vector := make([]float32, len(e.concepts))
+	for i, words := range e.concepts {
+		for _, word := range words {
+			vector[i] += float32(strings.Count(text, word))
+		}
+	}
+	return vector
+}
+
+func (e *conceptEmbedder) EmbedDocuments(_ context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = e.embed(text)
+	}
+	return vectors, nil
+}
+
+func (e *conceptEmbedder) EmbedQuery(_ context.Context, text string) ([]float32, error) {
+	return e.embed(text), nil
+}
+
+func newVectorTestProvider(t *testing.T, mode string) *SQLiteProvider {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "knowledge.json")
+	writeJSONDatabase(t, path)
+	provider, err := NewSQLiteProvider(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = provider.Close() })
+
+	provider.SetEmbedder(&conceptEmbedder{concepts: [][]string{
+		{"vacation", "holiday", "time off"},
+		{"remote", "home"},
+		{"deploy"},
+	}}, HybridOptions{Mode: mode, VectorWeight: 0.5, Candidates: 10, EmbeddingModel: "concepts"})
+
+	embedded, err := provider.EmbedMissing(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 3, embedded)
+	return provider
+}
+
+func TestSQLiteProviderEmbedMissingIsIncremental(t *testing.T) {
+	provider := newVectorTestProvider(t, SearchModeVector)
+
+	embedded, err := provider.EmbedMissing(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, embedded)
+}
+
+func TestSQLiteProviderVectorSearch(t *testing.T) {
+	provider := newVectorTestProvider(t, SearchModeVector)
+
+	results, err := provider.Search(context.Background(), "working from home", SearchOptions{})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Remote work requires manager approval.", results[0].Content)
+	assert.Equal(t, "handbook.pdf", results[0].FileName)
+}
+
+func TestSQLiteProviderHybridSearch(t *testing.T) {
+	provider := newVectorTestProvider(t, SearchModeHybrid)
+	ctx := context.Background()
+
+	// "holiday" has no keyword match, so the results come from the vector side
+	results, err := provider.Search(ctx, "holiday", SearchOptions{})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	for _, result := range results {
+		assert.Contains(t, result.Content, "vacation")
+	}
+
+	// Keyword and vector scores add up for chunks matched by both
+	results, err = provider.Search(ctx, "paid holiday", SearchOptions{})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "Employees receive 20 days of paid vacation per year.", results[0].Content)
+
+	results, err = provider.Search(ctx, "holiday", SearchOptions{Metadata: map[string]string{"team": "sre"}})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "runbook.pdf", results[0].FileName)
+}
+
+func TestVectorEncoding(t *testing.T) {
+	vector := []float32{0.5, -1.25, 3}
+	assert.Equal(t, vector, decodeVector(encodeVector(vector)))
+}
@@ -0,0 +1,280 @@
+// Package rag provides web page fetching and shallow crawling for URL ingestion
+package rag
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+
+	"github.com/tuannvm/slack-mcp-client/internal/network"
+)
+
+// Defaults for URL ingestion when no limits are configured
+const (
+	DefaultWebMaxDepth = 2
+	DefaultWebMaxPages = 50
+
+	webFetchTimeout = 30 * time.Second
+	webMaxBodyBytes = 5 << 20
+	webUserAgent    = "slack-mcp-client-rag/1.0"
+)
+
+// WebOptions limits what rag_ingest_url may fetch
+type WebOptions struct {
+	MaxDepth       int      // Maximum link depth followed from the start page
+	MaxPages       int      // Maximum pages fetched per call
+	AllowedDomains []string // Hosts that may be fetched (and their subdomains); empty allows any public host
+}
+
+// CrawlOptions configures a single crawl
+type CrawlOptions struct {
+	Depth    int // Link depth to follow; 0 fetches only the start page
+	MaxPages int // Maximum pages to fetch, including ones that fail or have no text
+}
+
+// WebPage is the readable content of a fetched page
+type WebPage struct {
+	URL   string
+	Title string
+	Text  string
+}
+
+// WebFetcher fetches pages and extracts their readable text. Crawls only follow
+// links on the start page's host. Without allowed domains it reaches any public
+// host but refuses private, loopback and link-local addresses.
+type WebFetcher struct {
+	httpClient     *http.Client
+	allowedDomains []string
+}
+
+// NewWebFetcher creates a fetcher restricted to the allowed domains, or to public
+// addresses when none are given
+func NewWebFetcher(allowedDomains []string) *WebFetcher {
+	f := &WebFetcher{allowedDomains: allowedDomains}
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	transport := network.BaseTransport().Clone()
+	transport.DialContext = dialer.DialContext
+	if len(allowedDomains) == 0 {
+		network.RestrictToPublic(transport, dialer)
+	}
+	f.httpClient = &http.Client{
+		Timeout:   webFetchTimeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return fmt.Errorf("stopped after 10 redirects")
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("redirect to unsupported scheme %s", req.URL.Scheme)
+			}
+			if !f.hostAllowed(req.URL.Hostname()) {
+				return fmt.Errorf("redirect to disallowed host %s", req.URL.Hostname())
+			}
+			return nil
+		},
+	}
+	return f
+}
+
+// Crawl fetches the start page and, breadth first, the same-host pages it links to
+// up to the requested depth. Pages that fail to load are skipped; an error is
+// returned only if the start page cannot be fetched.
+func (f *WebFetcher) Crawl(ctx context.Context, startURL string, options CrawlOptions) ([]WebPage, error) {
+	start, err := url.Parse(startURL)
+	if err != nil || (start.Scheme != "http" && start.Scheme != "https") || start.Host == "" {
+		return nil, fmt.Errorf("invalid URL '%s': only http and https URLs are supported", startURL)
+	}
+	if !f.hostAllowed(start.Hostname()) {
+		return nil, fmt.Errorf("host %s is not in the allowed domains", start.Hostname())
+	}
+	start.Fragment = ""
+
+	type queued struct {
+		url   *url.URL
+		depth int
+	}
+	queue := []queued{{url: start}}
+	seen := map[string]bool{start.String(): true}
+	var pages []WebPage
+
+	for fetched := 0; len(queue) > 0 && fetched < options.MaxPages; fetched++ {
+		next := queue[0]
+		queue = queue[1:]
+
+		page, links, err := f.fetch(ctx, next.url)
+		if err != nil {
+			if next.url == start {
+				return nil, err
+			}
+			fmt.Printf("Warning: skipping %s: %v\n", next.url, err)
+			continue
+		}
+		if page.Text != "" {
+			pages = append(pages, page)
+		}
+
+		if next.depth >= options.Depth {
+			continue
+		}
+		for _, link := range links {
+			if link.Hostname() != start.Hostname() || seen[link.String()] {
+				continue
+			}
+			seen[link.String()] = true
+			queue = append(queue, queued{url: link, depth: next.depth + 1})
+		}
+	}
+	return pages, nil
+}
+
+// fetch downloads a page and returns its readable content and outgoing links
+func (f *WebFetcher) fetch(ctx context.Context, pageURL *url.URL) (WebPage, []*url.URL, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL.String(), nil)
+	if err != nil {
+		return WebPage{}, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", webUserAgent)
+	req.Header.Set("Accept", "text/html,text/plain;q=0.9")
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return WebPage{}, nil, fmt.Errorf("failed to fetch %s: %w", pageURL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return WebPage{}, nil, fmt.Errorf("failed to fetch %s: %s", pageURL, resp.Status)
+	}
+
+	body := io.LimitReader(resp.Body, webMaxBodyBytes)
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch mediaType {
+	case "text/html", "application/xhtml+xml", "":
+		return extractHTML(resp.Request.URL, body)
+	case "text/plain", "text/markdown":
+		text, err := io.ReadAll(body)
+		if err != nil {
+			return WebPage{}, nil, fmt.Errorf("failed to read %s: %w", pageURL, err)
+		}
+		return WebPage{URL: resp.Request.URL.String(), Title: path.Base(resp.Request.URL.Path), Text: strings.TrimSpace(string(text))}, nil, nil
+	default:
+		return WebPage{}, nil, fmt.Errorf("unsupported content type %s at %s", mediaType, pageURL)
+	}
+}
+
+// hostAllowed reports whether the host is one of the allowed domains or a subdomain
+func (f *WebFetcher) hostAllowed(host string) bool {
+	if len(f.allowedDomains) == 0 {
+		return true
+	}
+	host = strings.ToLower(host)
+	for _, domain := range f.allowedDomains {
+		domain = strings.ToLower(strings.TrimPrefix(domain, "."))
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// nonContentElements are removed before extracting a page's text
+const nonContentElements = "script, style, noscript, template, svg, iframe, nav, header, footer, aside, form"
+
+// extractHTML returns the readable text of an HTML page, preferring its <main> or
+// <article> element, and the http(s) links it contains
+func extractHTML(pageURL *url.URL, body io.Reader) (WebPage, []*url.URL, error) {
+	doc, err := goquery.NewDocumentFromReader(body)
+	if err != nil {
+		return WebPage{}, nil, fmt.Errorf("failed to parse %s: %w", pageURL, err)
+	}
+
+	var links []*url.URL
+	doc.Find("a[href]").Each(func(_ int, a *goquery.Selection) {
+		href, _ := a.Attr("href")
+		link, err := pageURL.Parse(strings.TrimSpace(href))
+		if err != nil || (link.Scheme != "http" && link.Scheme != "https") {
+			return
+		}
+		link.Fragment = ""
+		links = append(links, link)
+	})
+
+	title := strings.TrimSpace(doc.Find("title").First().Text())
+	if title == "" {
+		title = pageURL.String()
+	}
+
+	doc.Find(nonContentElements).Remove()
+	content := doc.Find("main, article, [role=main]").First()
+	if content.Length() == 0 {
+		content = doc.Find("body")
+	}
+
+	var text strings.Builder
+	for _, node := range content.Nodes {
+		writeText(&text, node, false)
+	}
+	return WebPage{URL: pageURL.String(), Title: title, Text: collapseBlankLines(text.String())}, links, nil
+}
+
+// HTMLToText returns the readable text of an HTML document or fragment, such as a
+// wiki page body
+func HTMLToText(content string) (string, error) {
+	page, _, err := extractHTML(&url.URL{}, strings.NewReader(content))
+	if err != nil {
+		return "", err
+	}
+	return page.Text, nil
+}
+
+// blockElements start a new line in the extracted text
+var blockElements = map[string]bool{
+	"p": true, "div": true, "section": true, "br": true, "li": true, "tr": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"pre": true, "blockquote": true, "table": true, "ul": true, "ol": true, "dt": true, "dd": true,
+}
+
+// writeText appends the text of a node, separating block elements with newlines.
+// Line breaks in the source are kept only inside <pre>.
+func writeText(text *strings.Builder, node *html.Node, pre bool) {
+	switch node.Type {
+	case html.TextNode:
+		if pre {
+			text.WriteString(node.Data)
+		} else {
+			text.WriteString(strings.ReplaceAll(node.Data, "\n", " "))
+		}
+		return
+	case html.ElementNode:
+		if blockElements[node.Data] {
+			text.WriteString("\n")
+		}
+		pre = pre || node.Data == "pre"
+	}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		writeText(text, child, pre)
+	}
+	if node.Type == html.ElementNode && blockElements[node.Data] {
+		text.WriteString("\n")
+	}
+}
+
+// collapseBlankLines collapses the whitespace within each line and drops empty lines
+func collapseBlankLines(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,140 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newDocsSite serves a small documentation site whose index links to two pages,
+// one of which links further, plus an external link
+func newDocsSite(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = fmt.Fprint(w, `<html><head><title>Platform Docs</title><script>var tracking = 1;</script></head>
+<body><nav><a href="/deploy#top">Deploy</a> <a href="/oncall">On-call</a> <a href="https://example.com/">External</a></nav>
+<main><h1>Welcome</h1><p>The platform runs on <b>Kubernetes</b>.</p><ul><li>Fast</li><li>Reliable</li></ul></main>
+<footer>Copyright</footer></body></html>`)
+	})
+	mux.HandleFunc("/deploy", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = fmt.Fprint(w, `<html><head><title>Deploying</title></head><body><p>Deploys use Argo CD.</p><a href="/deploy/rollback">Rollback</a></body></html>`)
+	})
+	mux.HandleFunc("/deploy/rollback", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = fmt.Fprint(w, "Roll back with argocd app rollback.")
+	})
+	mux.HandleFunc("/oncall", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		_, _ = fmt.Fprint(w, "%PDF-1.4")
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// localSite allows the test server's host, which is refused without allowed domains
+var localSite = []string{"127.0.0.1"}
+
+func TestWebFetcherExtractsReadableText(t *testing.T) {
+	server := newDocsSite(t)
+
+	pages, err := NewWebFetcher(localSite).Crawl(context.Background(), server.URL+"/", CrawlOptions{MaxPages: 10})
+	require.NoError(t, err)
+	require.Len(t, pages, 1)
+	assert.Equal(t, "Platform Docs", pages[0].Title)
+	assert.Equal(t, "Welcome\nThe platform runs on Kubernetes.\nFast\nReliable", pages[0].Text)
+	assert.NotContains(t, pages[0].Text, "tracking")
+	assert.NotContains(t, pages[0].Text, "Copyright")
+}
+
+func TestWebFetcherCrawlDepthAndLimits(t *testing.T) {
+	server := newDocsSite(t)
+	fetcher := NewWebFetcher(localSite)
+	ctx := context.Background()
+
+	// Depth 1 follows same-host links once; the PDF link is skipped
+	pages, err := fetcher.Crawl(ctx, server.URL+"/", CrawlOptions{Depth: 1, MaxPages: 10})
+	require.NoError(t, err)
+	require.Len(t, pages, 2)
+	assert.Equal(t, server.URL+"/deploy", pages[1].URL)
+
+	pages, err = fetcher.Crawl(ctx, server.URL+"/", CrawlOptions{Depth: 2, MaxPages: 10})
+	require.NoError(t, err)
+	require.Len(t, pages, 3)
+	assert.Equal(t, "Roll back with argocd app rollback.", pages[2].Text)
+
+	pages, err = fetcher.Crawl(ctx, server.URL+"/", CrawlOptions{Depth: 2, MaxPages: 1})
+	require.NoError(t, err)
+	assert.Len(t, pages, 1)
+}
+
+func TestWebFetcherRejectsDisallowedURLs(t *testing.T) {
+	server := newDocsSite(t)
+	ctx := context.Background()
+
+	_, err := NewWebFetcher([]string{"docs.example.com"}).Crawl(ctx, server.URL+"/", CrawlOptions{MaxPages: 1})
+	assert.ErrorContains(t, err, "not in the allowed domains")
+
+	_, err = NewWebFetcher(nil).Crawl(ctx, "file:///etc/passwd", CrawlOptions{MaxPages: 1})
+	assert.ErrorContains(t, err, "only http and https")
+
+	_, err = NewWebFetcher(localSite).Crawl(ctx, server.URL+"/missing", CrawlOptions{MaxPages: 1})
+	assert.ErrorContains(t, err, "404")
+
+	// Without allowed domains, private and loopback addresses are refused
+	_, err = NewWebFetcher(nil).Crawl(ctx, server.URL+"/", CrawlOptions{MaxPages: 1})
+	assert.ErrorContains(t, err, "not public")
+
+	fetcher := NewWebFetcher([]string{"example.com"})
+	assert.True(t, fetcher.hostAllowed("docs.example.com"))
+	assert.False(t, fetcher.hostAllowed("badexample.com"))
+}
+
+func TestIngestURLTool(t *testing.T) {
+	server := newDocsSite(t)
+	client, err := NewClientWithProvider("simple", map[string]interface{}{
+		"database_path": filepath.Join(t.TempDir(), "knowledge.db"),
+	})
+	require.NoError(t, err)
+	defer func() { _ = client.Close() }()
+	client.SetWebOptions(WebOptions{AllowedDomains: localSite})
+	ctx := context.Background()
+
+	output, err := client.CallTool(ctx, "rag_ingest_url", map[string]interface{}{
+		"url": server.URL + "/", "depth": float64(1), "namespace": "platform-docs",
+	})
+	require.NoError(t, err)
+	assert.Contains(t, output, "Successfully ingested 2 of 2 page(s)")
+	assert.Contains(t, output, "into namespace platform-docs")
+
+	// Re-ingesting an unchanged page is skipped
+	output, err = client.CallTool(ctx, "rag_ingest_url", map[string]interface{}{"url": server.URL + "/deploy", "namespace": "platform-docs"})
+	require.NoError(t, err)
+	assert.Contains(t, output, "(unchanged)")
+	stats, err := client.GetProvider().GetStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.TotalFiles)
+
+	results, err := client.GetProvider().Search(ctx, "Argo", SearchOptions{})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Deploying", results[0].FileName)
+	assert.Equal(t, server.URL+"/deploy", results[0].Metadata["url"])
+	assert.Equal(t, "platform-docs", results[0].Metadata[NamespaceMetadataKey])
+
+	_, err = client.CallTool(ctx, "rag_ingest_url", map[string]interface{}{"url": server.URL + "/", "depth": float64(5)})
+	assert.ErrorContains(t, err, "depth must be between 0 and 2")
+}
@@ -0,0 +1,86 @@
+// Package routing picks the model that answers each message, sending trivial
+// prompts to a cheap, fast model and the rest to a powerful one.
+package routing
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+// Reasons a route was chosen, reported in the routing metrics
+const (
+	ReasonChannel    = "channel"
+	ReasonLength     = "length"
+	ReasonToolIntent = "tool_intent"
+	ReasonDefault    = "default"
+)
+
+// Decision is the model chosen for a message
+type Decision struct {
+	Route    string // config.RouteCheap or config.RoutePowerful
+	Reason   string // Why the route was chosen
+	Provider string // Key of llm.providers
+	Model    string // Model override, or "" for the provider's configured model
+}
+
+// Router classifies prompts by length, tool intent and channel
+type Router struct {
+	cfg      config.LLMRoutingConfig
+	keywords *regexp.Regexp // nil when no keywords are configured
+}
+
+// NewRouter creates a Router from the routing configuration
+func NewRouter(cfg config.LLMRoutingConfig) *Router {
+	router := &Router{cfg: cfg}
+	var patterns []string
+	for _, keyword := range cfg.ToolIntentKeywords {
+		if keyword = strings.TrimSpace(keyword); keyword != "" {
+			patterns = append(patterns, regexp.QuoteMeta(strings.ToLower(keyword)))
+		}
+	}
+	if len(patterns) > 0 {
+		router.keywords = regexp.MustCompile(`\b(` + strings.Join(patterns, "|") + `)\b`)
+	}
+	return router
+}
+
+// Route chooses the model for a prompt. toolNames are the tools offered for the
+// request; naming one of them counts as tool intent.
+func (r *Router) Route(prompt, channelID string, toolNames []string) Decision {
+	if route := r.cfg.RouteForChannel(channelID); route != "" {
+		return r.decision(route, ReasonChannel)
+	}
+	if utf8.RuneCountInString(strings.TrimSpace(prompt)) > r.cfg.MaxCheapLength {
+		return r.decision(config.RoutePowerful, ReasonLength)
+	}
+	if r.hasToolIntent(prompt, toolNames) {
+		return r.decision(config.RoutePowerful, ReasonToolIntent)
+	}
+	return r.decision(config.RouteCheap, ReasonDefault)
+}
+
+// hasToolIntent reports whether the prompt names a tool or uses a tool intent keyword
+func (r *Router) hasToolIntent(prompt string, toolNames []string) bool {
+	lower := strings.ToLower(prompt)
+	if r.keywords != nil && r.keywords.MatchString(lower) {
+		return true
+	}
+	for _, name := range toolNames {
+		name = strings.ToLower(name)
+		if strings.Contains(lower, name) || strings.Contains(lower, strings.ReplaceAll(name, "_", " ")) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) decision(route, reason string) Decision {
+	target := r.cfg.Cheap
+	if route == config.RoutePowerful {
+		target = r.cfg.Powerful
+	}
+	return Decision{Route: route, Reason: reason, Provider: target.Provider, Model: target.Model}
+}
@@ -0,0 +1,63 @@
+package routing
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+func newTestRouter() *Router {
+	cfg := &config.Config{}
+	cfg.LLM.Routing = config.LLMRoutingConfig{
+		Enabled:  true,
+		Cheap:    config.LLMRouteConfig{Model: "gpt-4o-mini"},
+		Powerful: config.LLMRouteConfig{Model: "gpt-4o"},
+		Channels: map[string]string{"C-OPS": config.RoutePowerful, "C-RANDOM": config.RouteCheap},
+	}
+	cfg.ApplyDefaults()
+	return NewRouter(cfg.LLM.Routing)
+}
+
+func TestRoute(t *testing.T) {
+	router := newTestRouter()
+	tools := []string{"get_weather", "jira"}
+
+	tests := []struct {
+		name    string
+		prompt  string
+		channel string
+		route   string
+		reason  string
+	}{
+		{"short question", "What does MCP stand for?", "C1", config.RouteCheap, ReasonDefault},
+		{"long prompt", strings.Repeat("word ", 50), "C1", config.RoutePowerful, ReasonLength},
+		{"intent keyword", "Can you search the docs for retries?", "C1", config.RoutePowerful, ReasonToolIntent},
+		{"keyword inside a word", "Thanks, that was researched well", "C1", config.RouteCheap, ReasonDefault},
+		{"tool name", "Is jira down?", "C1", config.RoutePowerful, ReasonToolIntent},
+		{"tool name with spaces", "get weather in Paris", "C1", config.RoutePowerful, ReasonToolIntent},
+		{"powerful channel", "hi", "C-OPS", config.RoutePowerful, ReasonChannel},
+		{"cheap channel", "Please search everything", "C-RANDOM", config.RouteCheap, ReasonChannel},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := router.Route(tt.prompt, tt.channel, tools)
+			assert.Equal(t, tt.route, decision.Route)
+			assert.Equal(t, tt.reason, decision.Reason)
+		})
+	}
+}
+
+func TestRouteTargets(t *testing.T) {
+	router := newTestRouter()
+
+	cheap := router.Route("hello", "C1", nil)
+	assert.Equal(t, config.ProviderOpenAI, cheap.Provider)
+	assert.Equal(t, "gpt-4o-mini", cheap.Model)
+
+	powerful := router.Route("hi", "C-OPS", nil)
+	assert.Equal(t, config.ProviderOpenAI, powerful.Provider)
+	assert.Equal(t, "gpt-4o", powerful.Model)
+}
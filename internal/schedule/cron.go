@@ -0,0 +1,140 @@
+// Package schedule parses cron expressions and computes when they next fire.
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// descriptors are the shorthands accepted in place of the five cron fields
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// field is the allowed range of a cron field
+type field struct {
+	name     string
+	min, max int
+}
+
+var fields = []field{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7}, // 0 and 7 are Sunday
+}
+
+// Cron is a parsed cron expression: minute, hour, day of month, month and day
+// of week, each a *, a value, a range (1-5), a list (1,15) or a step (*/15)
+type Cron struct {
+	minute, hour, dom, month, dow uint64 // Bit n set when value n matches
+	domAny, dowAny                bool   // The field was *, which matters when combining days
+}
+
+// ParseCron parses a five-field cron expression or a descriptor such as @daily
+func ParseCron(expr string) (*Cron, error) {
+	expr = strings.TrimSpace(expr)
+	if descriptor, ok := descriptors[strings.ToLower(expr)]; ok {
+		expr = descriptor
+	}
+	parts := strings.Fields(expr)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("cron expression '%s' must have 5 fields (minute hour day month weekday)", expr)
+	}
+	sets := make([]uint64, len(fields))
+	for i, part := range parts {
+		set, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("cron expression '%s': %w", expr, err)
+		}
+		sets[i] = set
+	}
+	c := &Cron{minute: sets[0], hour: sets[1], dom: sets[2], month: sets[3], dow: sets[4]}
+	c.domAny = strings.HasPrefix(parts[2], "*")
+	c.dowAny = strings.HasPrefix(parts[4], "*")
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1 // 7 is another name for Sunday
+	}
+	return c, nil
+}
+
+// parseField parses one comma-separated cron field into a bit set
+func parseField(text string, f field) (uint64, error) {
+	var set uint64
+	for _, item := range strings.Split(text, ",") {
+		rangeText, stepText, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepText); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step '%s' in %s", stepText, f.name)
+			}
+		}
+
+		low, high := f.min, f.max
+		if rangeText != "*" {
+			lowText, highText, isRange := strings.Cut(rangeText, "-")
+			var err error
+			if low, err = strconv.Atoi(lowText); err != nil {
+				return 0, fmt.Errorf("invalid value '%s' in %s", item, f.name)
+			}
+			high = low
+			if isRange {
+				if high, err = strconv.Atoi(highText); err != nil {
+					return 0, fmt.Errorf("invalid value '%s' in %s", item, f.name)
+				}
+			} else if hasStep {
+				high = f.max // 5/15 means from 5 to the end in steps of 15
+			}
+		}
+		if low < f.min || high > f.max || low > high {
+			return 0, fmt.Errorf("%s '%s' is out of range %d-%d", f.name, item, f.min, f.max)
+		}
+		for v := low; v <= high; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
+
+// Next returns the first time after t, to the minute, that the expression
+// matches in t's location, or the zero time if it never does (e.g. 30 February)
+func (c *Cron) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case c.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case c.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case c.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches applies the cron rule for days: when both day of month and day of
+// week are restricted, either may match
+func (c *Cron) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domAny || c.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
@@ -0,0 +1,49 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCronNext(t *testing.T) {
+	start := time.Date(2025, 3, 14, 10, 30, 0, 0, time.UTC) // A Friday
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"0 9 * * *", time.Date(2025, 3, 15, 9, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2025, 3, 14, 10, 45, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2025, 3, 17, 9, 0, 0, 0, time.UTC)},
+		{"30 17 * * 5", time.Date(2025, 3, 14, 17, 30, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 8 1,15 * 1", time.Date(2025, 3, 15, 8, 0, 0, 0, time.UTC)}, // Day of month or Monday
+		{"@weekly", time.Date(2025, 3, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 12 * * 7", time.Date(2025, 3, 16, 12, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		cron, err := ParseCron(tt.expr)
+		require.NoError(t, err, tt.expr)
+		assert.Equal(t, tt.want, cron.Next(start), tt.expr)
+	}
+}
+
+func TestCronNextInTimezone(t *testing.T) {
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	require.NoError(t, err)
+	cron, err := ParseCron("0 9 * * *")
+	require.NoError(t, err)
+
+	next := cron.Next(time.Date(2025, 3, 14, 10, 0, 0, 0, kolkata))
+	assert.Equal(t, time.Date(2025, 3, 15, 9, 0, 0, 0, kolkata), next)
+}
+
+func TestParseCronErrors(t *testing.T) {
+	for _, expr := range []string{"", "0 9 * *", "60 * * * *", "0 9 * * 8", "*/0 * * * *", "a * * * *", "5-1 * * * *"} {
+		_, err := ParseCron(expr)
+		assert.Error(t, err, expr)
+	}
+}
@@ -0,0 +1,200 @@
+// Package scratchpad keeps the intermediate reasoning of agent runs: the thoughts,
+// tool calls and tool results that led to each answer. Runs are stored per Slack
+// thread so users can inspect the work behind an answer, and follow-up questions
+// can build on it instead of calling the same tools again.
+package scratchpad
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/filestore"
+)
+
+// maxRunsPerThread bounds the runs kept for a thread, oldest dropped first
+const maxRunsPerThread = 10
+
+// Step is one action of an agent run and what came back from it
+type Step struct {
+	Thought     string `json:"thought,omitempty"`     // The agent's reasoning before the action
+	Action      string `json:"action,omitempty"`      // Tool called
+	ActionInput string `json:"actionInput,omitempty"` // Input the tool was called with
+	Observation string `json:"observation,omitempty"` // Tool result, or the error it returned
+}
+
+// Run is the scratchpad of one agent run, answering the prompt posted at PromptTS
+type Run struct {
+	PromptTS string    `json:"promptTs"`
+	Prompt   string    `json:"prompt"`
+	UserID   string    `json:"userId,omitempty"` // User who posted the prompt
+	Steps    []Step    `json:"steps,omitempty"`
+	Answer   string    `json:"answer,omitempty"`
+	Finished time.Time `json:"finished"`
+}
+
+// thread holds the runs of a Slack thread, oldest first
+type thread struct {
+	Runs     []Run     `json:"runs"`
+	LastUsed time.Time `json:"lastUsed"`
+}
+
+// Store keeps the scratchpads of the most recently active threads. With a path,
+// it is persisted to a JSON file so scratchpads survive restarts.
+type Store struct {
+	path       string
+	maxThreads int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	threads map[string]*thread // By channel and thread timestamp
+}
+
+// NewStore opens (or creates) a store that keeps at most maxThreads threads. An
+// empty path keeps the scratchpads in memory only.
+func NewStore(path string, maxThreads int) (*Store, error) {
+	s := &Store{path: path, maxThreads: maxThreads, now: time.Now, threads: make(map[string]*thread)}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Save records a finished run in its thread. A run of the same prompt, such as
+// an earlier attempt, is replaced.
+func (s *Store) Save(channelID, threadTS string, run Run) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := threadKey(channelID, threadTS)
+	t := s.threads[key]
+	if t == nil {
+		t = &thread{}
+		s.threads[key] = t
+	}
+	t.Runs = append(withoutPrompt(t.Runs, run.PromptTS), run)
+	if len(t.Runs) > maxRunsPerThread {
+		t.Runs = t.Runs[len(t.Runs)-maxRunsPerThread:]
+	}
+	t.LastUsed = s.now()
+	s.evict()
+	return s.save()
+}
+
+// Get returns the run answering the prompt posted at promptTS in the thread
+func (s *Store) Get(channelID, threadTS, promptTS string) (Run, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.threads[threadKey(channelID, threadTS)]
+	if t == nil {
+		return Run{}, false
+	}
+	for _, run := range t.Runs {
+		if run.PromptTS == promptTS {
+			return run, true
+		}
+	}
+	return Run{}, false
+}
+
+// Latest returns the newest run of the thread whose prompt was posted before ts,
+// or the newest run of the thread when ts is empty
+func (s *Store) Latest(channelID, threadTS, ts string) (Run, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.threads[threadKey(channelID, threadTS)]
+	if t == nil {
+		return Run{}, false
+	}
+	for i := len(t.Runs) - 1; i >= 0; i-- {
+		if ts == "" || t.Runs[i].PromptTS < ts {
+			return t.Runs[i], true
+		}
+	}
+	return Run{}, false
+}
+
+// evict drops the least recently used threads beyond the limit; callers must hold mu
+func (s *Store) evict() {
+	if s.maxThreads <= 0 || len(s.threads) <= s.maxThreads {
+		return
+	}
+	keys := make([]string, 0, len(s.threads))
+	for key := range s.threads {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return s.threads[keys[i]].LastUsed.Before(s.threads[keys[j]].LastUsed) })
+	for _, key := range keys[:len(keys)-s.maxThreads] {
+		delete(s.threads, key)
+	}
+}
+
+// load reads the store file if it exists
+func (s *Store) load() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read scratchpad store: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.threads); err != nil {
+		return fmt.Errorf("failed to parse scratchpad store: %w", err)
+	}
+	return nil
+}
+
+// save atomically writes the store file; callers must hold mu
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+	if err := filestore.WriteJSON(s.path, s.threads); err != nil {
+		return fmt.Errorf("failed to save scratchpad store: %w", err)
+	}
+	return nil
+}
+
+// Context describes a run for a follow-up prompt, with each observation cut to
+// maxObservation bytes
+func (r Run) Context(maxObservation int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Earlier request: %s\n", r.Prompt)
+	for i, step := range r.Steps {
+		fmt.Fprintf(&b, "Step %d: called %s with %s\n", i+1, step.Action, step.ActionInput)
+		fmt.Fprintf(&b, "Result: %s\n", truncate(step.Observation, maxObservation))
+	}
+	if r.Answer != "" {
+		fmt.Fprintf(&b, "Answer given: %s\n", r.Answer)
+	}
+	return b.String()
+}
+
+// truncate cuts text to at most max bytes, marking the cut
+func truncate(text string, max int) string {
+	if max <= 0 || len(text) <= max {
+		return text
+	}
+	return strings.ToValidUTF8(text[:max], "") + "..."
+}
+
+// withoutPrompt returns the runs that do not answer the prompt posted at promptTS
+func withoutPrompt(runs []Run, promptTS string) []Run {
+	kept := runs[:0:0]
+	for _, run := range runs {
+		if run.PromptTS != promptTS {
+			kept = append(kept, run)
+		}
+	}
+	return kept
+}
+
+func threadKey(channelID, threadTS string) string {
+	return channelID + ":" + threadTS
+}
@@ -0,0 +1,86 @@
+package scratchpad
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreKeepsRunsPerThread(t *testing.T) {
+	store, err := NewStore("", 0)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Save("C1", "100.1", Run{PromptTS: "100.1", Prompt: "list pods", Steps: []Step{{Action: "kubectl", Observation: "api-1"}}}))
+	require.NoError(t, store.Save("C1", "100.1", Run{PromptTS: "100.5", Prompt: "restart api-1"}))
+
+	run, ok := store.Get("C1", "100.1", "100.1")
+	require.True(t, ok)
+	assert.Equal(t, "list pods", run.Prompt)
+	_, ok = store.Get("C2", "100.1", "100.1")
+	assert.False(t, ok)
+
+	// The latest run before a prompt, or the newest one
+	run, _ = store.Latest("C1", "100.1", "100.5")
+	assert.Equal(t, "100.1", run.PromptTS)
+	run, _ = store.Latest("C1", "100.1", "")
+	assert.Equal(t, "100.5", run.PromptTS)
+	_, ok = store.Latest("C1", "100.1", "100.1")
+	assert.False(t, ok)
+
+	// A new run of the same prompt replaces the earlier one
+	require.NoError(t, store.Save("C1", "100.1", Run{PromptTS: "100.1", Prompt: "list pods again"}))
+	run, _ = store.Get("C1", "100.1", "100.1")
+	assert.Equal(t, "list pods again", run.Prompt)
+	run, _ = store.Latest("C1", "100.1", "")
+	assert.Equal(t, "100.1", run.PromptTS)
+}
+
+func TestStoreEvictsLeastRecentlyUsedThreads(t *testing.T) {
+	store, err := NewStore("", 2)
+	require.NoError(t, err)
+	now := time.Unix(1000, 0)
+	store.now = func() time.Time { return now }
+
+	for i := 1; i <= 3; i++ {
+		now = now.Add(time.Minute)
+		require.NoError(t, store.Save("C1", fmt.Sprintf("10%d.1", i), Run{PromptTS: "1"}))
+	}
+	_, ok := store.Get("C1", "101.1", "1")
+	assert.False(t, ok)
+	_, ok = store.Get("C1", "103.1", "1")
+	assert.True(t, ok)
+
+	for i := 0; i < maxRunsPerThread+3; i++ {
+		require.NoError(t, store.Save("C1", "103.1", Run{PromptTS: fmt.Sprintf("%03d", i)}))
+	}
+	_, ok = store.Get("C1", "103.1", "000")
+	assert.False(t, ok)
+	assert.Len(t, store.threads["C1:103.1"].Runs, maxRunsPerThread)
+}
+
+func TestStorePersistsToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "scratchpads.json")
+	store, err := NewStore(path, 10)
+	require.NoError(t, err)
+	require.NoError(t, store.Save("C1", "100.1", Run{PromptTS: "100.1", Prompt: "list pods", Steps: []Step{{Action: "kubectl", ActionInput: "{}", Observation: "api-1"}}}))
+
+	reopened, err := NewStore(path, 10)
+	require.NoError(t, err)
+	run, ok := reopened.Get("C1", "100.1", "100.1")
+	require.True(t, ok)
+	assert.Equal(t, []Step{{Action: "kubectl", ActionInput: "{}", Observation: "api-1"}}, run.Steps)
+}
+
+func TestRunContext(t *testing.T) {
+	run := Run{
+		Prompt: "list pods",
+		Steps:  []Step{{Action: "kubectl", ActionInput: `{"cmd":"get pods"}`, Observation: strings.Repeat("x", 20)}},
+		Answer: "There are 3 pods.",
+	}
+	assert.Equal(t, "Earlier request: list pods\nStep 1: called kubectl with {\"cmd\":\"get pods\"}\nResult: xxxxxxxxxx...\nAnswer given: There are 3 pods.\n", run.Context(10))
+}
@@ -0,0 +1,93 @@
+package slackbot
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/slack-go/slack"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+)
+
+// maxHomeSectionLength keeps App Home sections under Slack's 3000 character limit
+const maxHomeSectionLength = 2900
+
+// homeViewPublisher is implemented by frontends that can publish an App Home tab
+type homeViewPublisher interface {
+	PublishView(userID string, view slack.HomeTabViewRequest, hash string) (*slack.ViewResponse, error)
+}
+
+// publishHomeView shows the status of MCP servers and any startup warnings in the App Home tab
+func (c *Client) publishHomeView(userID string) {
+	publisher, ok := c.userFrontend.(homeViewPublisher)
+	if !ok {
+		return
+	}
+
+	view := slack.HomeTabViewRequest{
+		Type:   slack.VTHomeTab,
+		Blocks: slack.Blocks{BlockSet: c.buildHomeBlocks()},
+	}
+	if _, err := publisher.PublishView(userID, view, ""); err != nil {
+		c.logger.ErrorKV("Failed to publish App Home view", "user", userID, "error", err)
+	}
+}
+
+// buildHomeBlocks renders the App Home status view
+func (c *Client) buildHomeBlocks() []slack.Block {
+	c.mcpMu.RLock()
+	toolCounts := make(map[string]int, len(c.mcpClients))
+	for name := range c.mcpClients {
+		toolCounts[name] = 0
+	}
+	for _, tool := range c.discoveredTools {
+		if _, ok := toolCounts[tool.ServerName]; ok {
+			toolCounts[tool.ServerName]++
+		}
+	}
+	warnings := append([]string(nil), c.statusWarnings...)
+	c.mcpMu.RUnlock()
+
+	servers := make([]string, 0, len(toolCounts))
+	for name := range toolCounts {
+		servers = append(servers, name)
+	}
+	sort.Strings(servers)
+
+	var serverLines strings.Builder
+	if len(servers) == 0 {
+		serverLines.WriteString("_No MCP servers are connected._")
+	}
+	for _, name := range servers {
+		fmt.Fprintf(&serverLines, "• *%s*: %d tools\n", name, toolCounts[name])
+	}
+
+	var warningLines strings.Builder
+	if len(warnings) == 0 {
+		warningLines.WriteString("_No warnings._")
+	}
+	for _, warning := range warnings {
+		fmt.Fprintf(&warningLines, ":warning: %s\n", warning)
+	}
+
+	blocks := []slack.Block{
+		slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, "Slack MCP Client status", false, false)),
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, logging.TruncateForLog("*MCP servers*\n"+serverLines.String(), maxHomeSectionLength), false, false), nil, nil),
+		slack.NewDividerBlock(),
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, logging.TruncateForLog("*Warnings*\n"+warningLines.String(), maxHomeSectionLength), false, false), nil, nil),
+	}
+	if c.experiments != nil {
+		blocks = append(blocks,
+			slack.NewDividerBlock(),
+			slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, logging.TruncateForLog("*Experiments*\n"+c.experimentLines(), maxHomeSectionLength), false, false), nil, nil),
+		)
+	}
+	if c.objectives != nil {
+		blocks = append(blocks,
+			slack.NewDividerBlock(),
+			slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, logging.TruncateForLog("*Service levels*\n"+c.sloLines(), maxHomeSectionLength), false, false), nil, nil),
+		)
+	}
+	return blocks
+}
@@ -0,0 +1,146 @@
+package slackbot
+
+import (
+	"context"
+
+	"github.com/slack-go/slack"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+// maxAssistantTitleLength bounds the thread title derived from the first prompt
+const maxAssistantTitleLength = 60
+
+// AssistantFrontend is implemented by frontends that support Slack's Assistant
+// threads API. Frontends without it keep the regular "thinking" message.
+type AssistantFrontend interface {
+	SetAssistantStatus(channelID, threadTS, status string) error
+	SetAssistantSuggestedPrompts(channelID, threadTS, title string, prompts []config.SlackAssistantPrompt) error
+	SetAssistantTitle(channelID, threadTS, title string) error
+}
+
+// SetAssistantStatus shows a status such as "is thinking..." under the assistant
+// thread. Slack clears it when the app replies; an empty status clears it now.
+func (slackClient *SlackClient) SetAssistantStatus(channelID, threadTS, status string) error {
+	return slackClient.SetAssistantThreadsStatusContext(context.Background(), slack.AssistantThreadsSetStatusParameters{
+		ChannelID: channelID,
+		ThreadTS:  threadTS,
+		Status:    status,
+	})
+}
+
+// SetAssistantSuggestedPrompts offers prompts the user can click in the assistant thread
+func (slackClient *SlackClient) SetAssistantSuggestedPrompts(channelID, threadTS, title string, prompts []config.SlackAssistantPrompt) error {
+	params := slack.AssistantThreadsSetSuggestedPromptsParameters{
+		Title:     title,
+		ChannelID: channelID,
+		ThreadTS:  threadTS,
+	}
+	for _, prompt := range prompts {
+		params.AddPrompt(prompt.Title, prompt.Message)
+	}
+	return slackClient.SetAssistantThreadsSuggestedPromptsContext(context.Background(), params)
+}
+
+// SetAssistantTitle names the assistant thread in the user's thread history
+func (slackClient *SlackClient) SetAssistantTitle(channelID, threadTS, title string) error {
+	return slackClient.SetAssistantThreadsTitleContext(context.Background(), slack.AssistantThreadsSetTitleParameters{
+		ChannelID: channelID,
+		ThreadTS:  threadTS,
+		Title:     title,
+	})
+}
+
+// assistantFrontend returns the frontend's Assistant API when the surface is enabled
+func (c *Client) assistantFrontend() (AssistantFrontend, bool) {
+	if !c.cfg.Slack.Assistant.Enabled {
+		return nil, false
+	}
+	frontend, ok := c.userFrontend.(AssistantFrontend)
+	return frontend, ok
+}
+
+// startAssistantThread records a new assistant thread and posts the suggested prompts
+func (c *Client) startAssistantThread(channelID, threadTS string) {
+	frontend, ok := c.assistantFrontend()
+	if !ok {
+		return
+	}
+	c.markAssistantThread(channelID, threadTS, true)
+
+	prompts := c.cfg.Slack.Assistant.SuggestedPrompts
+	if len(prompts) == 0 {
+		return
+	}
+	if err := frontend.SetAssistantSuggestedPrompts(channelID, threadTS, c.cfg.Slack.Assistant.PromptsTitle, prompts); err != nil {
+		c.logger.WarnKV("Failed to set assistant suggested prompts", "channel", channelID, "thread_ts", threadTS, "error", err)
+	}
+}
+
+// markAssistantThread records that a thread lives in the assistant container.
+// needsTitle is set for new threads, which are titled after their first prompt.
+func (c *Client) markAssistantThread(channelID, threadTS string, needsTitle bool) {
+	c.assistantMu.Lock()
+	defer c.assistantMu.Unlock()
+	if c.assistantThreads == nil {
+		c.assistantThreads = make(map[string]bool)
+	}
+	key := historyKey(channelID, threadTS)
+	if _, exists := c.assistantThreads[key]; !exists || needsTitle {
+		c.assistantThreads[key] = needsTitle
+	}
+}
+
+// isAssistantThread reports whether replies to the thread use the assistant container
+func (c *Client) isAssistantThread(channelID, threadTS string) bool {
+	if _, ok := c.assistantFrontend(); !ok {
+		return false
+	}
+	c.assistantMu.Lock()
+	defer c.assistantMu.Unlock()
+	_, exists := c.assistantThreads[historyKey(channelID, threadTS)]
+	return exists
+}
+
+// titleAssistantThread names a new assistant thread after its first prompt
+func (c *Client) titleAssistantThread(channelID, threadTS, userPrompt string) {
+	frontend, ok := c.assistantFrontend()
+	if !ok {
+		return
+	}
+	key := historyKey(channelID, threadTS)
+	c.assistantMu.Lock()
+	needsTitle := c.assistantThreads[key]
+	if needsTitle {
+		c.assistantThreads[key] = false
+	}
+	c.assistantMu.Unlock()
+	if !needsTitle {
+		return
+	}
+	if err := frontend.SetAssistantTitle(channelID, threadTS, assistantTitle(userPrompt)); err != nil {
+		c.logger.WarnKV("Failed to set assistant thread title", "channel", channelID, "thread_ts", threadTS, "error", err)
+	}
+}
+
+// setAssistantStatus updates the status of an assistant thread. It returns false
+// when the thread is not an assistant thread, so the caller can fall back to a message.
+func (c *Client) setAssistantStatus(channelID, threadTS, status string) bool {
+	if !c.isAssistantThread(channelID, threadTS) {
+		return false
+	}
+	frontend, _ := c.assistantFrontend()
+	if err := frontend.SetAssistantStatus(channelID, threadTS, status); err != nil {
+		c.logger.WarnKV("Failed to set assistant thread status", "channel", channelID, "thread_ts", threadTS, "error", err)
+	}
+	return true
+}
+
+// assistantTitle shortens a prompt to a thread title
+func assistantTitle(prompt string) string {
+	runes := []rune(prompt)
+	if len(runes) <= maxAssistantTitleLength {
+		return prompt
+	}
+	return string(runes[:maxAssistantTitleLength-1]) + "…"
+}
@@ -0,0 +1,92 @@
+package slackbot
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tmc/langchaingo/llms"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+// assistantRecorder is a frontend with the Assistant API that records its calls
+type assistantRecorder struct {
+	*StdioClient
+	statuses []string
+	titles   []string
+	prompts  []config.SlackAssistantPrompt
+}
+
+func (r *assistantRecorder) SetAssistantStatus(_, _, status string) error {
+	r.statuses = append(r.statuses, status)
+	return nil
+}
+
+func (r *assistantRecorder) SetAssistantSuggestedPrompts(_, _, _ string, prompts []config.SlackAssistantPrompt) error {
+	r.prompts = prompts
+	return nil
+}
+
+func (r *assistantRecorder) SetAssistantTitle(_, _, title string) error {
+	r.titles = append(r.titles, title)
+	return nil
+}
+
+func newAssistantTestClient() (*Client, *assistantRecorder, *bytes.Buffer) {
+	cfg := &config.Config{}
+	cfg.ApplyDefaults()
+	cfg.Slack.Assistant.Enabled = true
+	cfg.Slack.Assistant.SuggestedPrompts = []config.SlackAssistantPrompt{{Title: "Incidents", Message: "Which incidents are open?"}}
+
+	logger := logging.New("test", logging.LevelError)
+	output := &bytes.Buffer{}
+	stdio := NewStdioClient(logger)
+	stdio.Output = output
+	frontend := &assistantRecorder{StdioClient: stdio}
+	return &Client{cfg: cfg, userFrontend: frontend, logger: logger}, frontend, output
+}
+
+func TestAssistantThread(t *testing.T) {
+	client, frontend, output := newAssistantTestClient()
+
+	client.startAssistantThread("D1", "100.1")
+	assert.Equal(t, client.cfg.Slack.Assistant.SuggestedPrompts, frontend.prompts)
+
+	// The thread is titled once, after its first prompt
+	client.titleAssistantThread("D1", "100.1", "Which incidents are open?")
+	client.titleAssistantThread("D1", "100.1", "And yesterday?")
+	assert.Equal(t, []string{"Which incidents are open?"}, frontend.titles)
+
+	client.showThinking(context.Background(), "D1", "100.1")
+	client.showToolStatus(context.Background(), "D1", "100.1", &llms.ContentChoice{Content: `{"tool":"rag_search","args":{}}`})
+	client.showToolStatus(context.Background(), "D1", "100.1", &llms.ContentChoice{ToolCalls: []llms.ToolCall{{FunctionCall: &llms.FunctionCall{Name: "list_alerts"}}}})
+	client.showToolStatus(context.Background(), "D1", "100.1", &llms.ContentChoice{Content: "No tool needed."})
+	assert.Equal(t, []string{"is thinking...", "is searching the knowledge base...", "is running list_alerts..."}, frontend.statuses)
+	assert.Empty(t, output.String())
+
+	// Other threads keep the thinking message
+	client.showThinking(context.Background(), "C1", "200.1")
+	assert.Contains(t, output.String(), "Thinking...")
+	assert.Len(t, frontend.statuses, 3)
+}
+
+func TestAssistantDisabled(t *testing.T) {
+	client, frontend, output := newAssistantTestClient()
+	client.cfg.Slack.Assistant.Enabled = false
+
+	client.startAssistantThread("D1", "100.1")
+	client.showThinking(context.Background(), "D1", "100.1")
+	assert.Nil(t, frontend.prompts)
+	assert.Empty(t, frontend.statuses)
+	assert.Contains(t, output.String(), "Thinking...")
+}
+
+func TestAssistantTitle(t *testing.T) {
+	assert.Equal(t, "Short question", assistantTitle("Short question"))
+	long := assistantTitle(string(bytes.Repeat([]byte("a"), 100)))
+	assert.Len(t, []rune(long), maxAssistantTitleLength)
+	assert.True(t, len(long) > 0 && long[len(long)-3:] == "…")
+}
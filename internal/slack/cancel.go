@@ -0,0 +1,129 @@
+package slackbot
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	// cancelReaction is the 🛑 reaction that stops a request
+	cancelReaction = "octagonal_sign"
+	// stopCommand is the message that stops the requests in a thread
+	stopCommand = "stop"
+
+	cancelledMessage = "🛑 Stopped."
+	shutdownMessage  = "🛑 Stopped because the bot is restarting. Please ask again in a moment."
+	nothingToStop    = "There is no request in progress to stop."
+)
+
+// inflightRequest is a prompt being answered, which its user can cancel
+type inflightRequest struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	cancelled atomic.Bool
+	channelID string
+	threadTS  string
+	promptTS  string // The user's message
+	userID    string
+}
+
+type requestKey struct{}
+
+// trackRequest makes the interaction cancellable. Cancelling the returned context
+// stops the LLM call and any running tool. The returned function must be called
+// when the interaction ends.
+func (c *Client) trackRequest(ctx context.Context, channelID, threadTS, promptTS, userID string) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	req := &inflightRequest{cancel: cancel, channelID: channelID, threadTS: threadTS, promptTS: promptTS, userID: userID}
+	ctx = context.WithValue(ctx, requestKey{}, req)
+	req.ctx = ctx
+
+	c.inflightMu.Lock()
+	if c.inflight == nil {
+		c.inflight = make(map[*inflightRequest]bool)
+	}
+	c.inflight[req] = true
+	c.inflightMu.Unlock()
+
+	return ctx, func() {
+		c.inflightMu.Lock()
+		delete(c.inflight, req)
+		c.inflightMu.Unlock()
+		cancel()
+	}
+}
+
+// requestCancelled reports whether the user stopped the interaction
+func requestCancelled(ctx context.Context) bool {
+	req, _ := ctx.Value(requestKey{}).(*inflightRequest)
+	return req != nil && req.cancelled.Load()
+}
+
+// cancelRequests stops the requests matching the filter and returns how many were stopped
+func (c *Client) cancelRequests(match func(req *inflightRequest) bool) int {
+	return c.stopRequests(match, cancelledMessage)
+}
+
+// stopRequests stops the requests matching the filter, replying with the notice
+// in their place, and returns how many were stopped
+func (c *Client) stopRequests(match func(req *inflightRequest) bool, notice string) int {
+	c.inflightMu.Lock()
+	var matched []*inflightRequest
+	for req := range c.inflight {
+		if match(req) {
+			matched = append(matched, req)
+		}
+	}
+	c.inflightMu.Unlock()
+
+	stopped := 0
+	for _, req := range matched {
+		if !req.cancelled.CompareAndSwap(false, true) {
+			continue
+		}
+		req.cancel()
+		c.logger.InfoKV("Request cancelled", "channel", req.channelID, "thread_ts", req.threadTS, "user", req.userID)
+		c.sendReply(req.ctx, req.channelID, req.threadTS, notice)
+		stopped++
+	}
+	return stopped
+}
+
+// stopAllRequests cancels every request still being answered, so a shutdown does
+// not leave LLM calls and tools running, and returns how many were stopped
+func (c *Client) stopAllRequests() int {
+	return c.stopRequests(func(*inflightRequest) bool { return true }, shutdownMessage)
+}
+
+// handleStopCommand stops the user's requests in the thread, or in the whole
+// channel when "stop" is sent outside a thread
+func (c *Client) handleStopCommand(channelID, threadTS, userID string) {
+	stopped := c.cancelRequests(func(req *inflightRequest) bool {
+		return req.channelID == channelID && req.userID == userID && (threadTS == "" || req.threadTS == threadTS)
+	})
+	if stopped == 0 {
+		c.sendMessage(channelID, threadTS, nothingToStop)
+	}
+}
+
+// handleCancelReaction stops the request whose prompt or placeholder the user
+// reacted to with 🛑
+func (c *Client) handleCancelReaction(channelID, messageTS, userID string) {
+	c.cancelRequests(func(req *inflightRequest) bool {
+		if req.channelID != channelID || req.userID != userID {
+			return false
+		}
+		if req.promptTS == messageTS {
+			return true
+		}
+		progress := progressFrom(req.ctx)
+		return progress != nil && progress.ts == messageTS
+	})
+}
+
+// isStopCommand reports whether a message asks to stop the running request
+func isStopCommand(text string) bool {
+	text = strings.ToLower(strings.TrimSpace(text))
+	return strings.TrimRight(text, ".!") == stopCommand
+}
@@ -0,0 +1,71 @@
+package slackbot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStopCommandCancelsRequest(t *testing.T) {
+	client, frontend, output := newProgressTestClient()
+
+	ctx := client.showThinking(context.Background(), "C1", "100.1")
+	ctx, done := client.trackRequest(ctx, "C1", "100.1", "100.1", "U1")
+	defer done()
+
+	// Only the requesting user can stop the request
+	client.handleStopCommand("C1", "100.1", "U2")
+	assert.NoError(t, ctx.Err())
+	assert.Contains(t, output.String(), nothingToStop)
+
+	client.handleStopCommand("C1", "100.1", "U1")
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+	assert.Equal(t, []string{"111.1 " + cancelledMessage}, frontend.replaced)
+
+	// The interaction's own replies and statuses are dropped
+	client.reply(ctx, "C1", "100.1", "Sorry, I encountered an error: context canceled")
+	client.showStatus(ctx, "C1", "100.1", "", progressWritingStatus)
+	client.clearProgress(ctx)
+	assert.Len(t, frontend.replaced, 1)
+	assert.Empty(t, frontend.updates)
+	assert.Empty(t, frontend.deleted)
+}
+
+func TestCancelReaction(t *testing.T) {
+	client, _, _ := newProgressTestClient()
+
+	ctx := client.showThinking(context.Background(), "C1", "100.1")
+	ctx, done := client.trackRequest(ctx, "C1", "100.1", "100.2", "U1")
+	defer done()
+
+	client.handleCancelReaction("C1", "999.9", "U1")
+	assert.NoError(t, ctx.Err())
+
+	// Reacting to the placeholder stops the request
+	client.handleCancelReaction("C1", "111.1", "U1")
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+
+	// Finished requests can no longer be stopped
+	done()
+	assert.Zero(t, client.cancelRequests(func(*inflightRequest) bool { return true }))
+}
+
+func TestCloseStopsRequests(t *testing.T) {
+	client, frontend, _ := newProgressTestClient()
+
+	ctx := client.showThinking(context.Background(), "C1", "100.1")
+	ctx, done := client.trackRequest(ctx, "C1", "100.1", "100.1", "U1")
+	defer done()
+
+	assert.NoError(t, client.Close())
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+	assert.True(t, requestCancelled(ctx), "the interaction does not report the cancellation as an error")
+	assert.Equal(t, []string{"111.1 " + shutdownMessage}, frontend.replaced)
+}
+
+func TestIsStopCommand(t *testing.T) {
+	assert.True(t, isStopCommand("stop"))
+	assert.True(t, isStopCommand(" Stop! "))
+	assert.False(t, isStopCommand("stop the deployment"))
+}
@@ -0,0 +1,34 @@
+package slackbot
+
+import (
+	"context"
+	"strings"
+
+	"github.com/tuannvm/slack-mcp-client/internal/rag"
+)
+
+// citationFooter returns the "Sources" footer for the knowledge base chunks used to
+// answer the request, followed by a freshness warning when some were stale. It is
+// empty when neither is tracked or nothing was found.
+func citationFooter(ctx context.Context, response string) string {
+	var parts []string
+	if citations := rag.CitationsFromContext(ctx); citations != nil {
+		if sources := citations.Format(response); sources != "" {
+			parts = append(parts, sources)
+		}
+	}
+	if stale := rag.StaleSourcesFromContext(ctx); stale != nil {
+		if warning := stale.Warning(); warning != "" {
+			parts = append(parts, warning)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// withCitations appends the citation footer, if any, to a reply
+func withCitations(ctx context.Context, response string) string {
+	if footer := citationFooter(ctx, response); footer != "" {
+		return response + "\n\n" + footer
+	}
+	return response
+}
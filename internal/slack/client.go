@@ -7,36 +7,94 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
 	"github.com/slack-go/slack/socketmode"
 
 	"github.com/tmc/langchaingo/callbacks"
 	"github.com/tmc/langchaingo/llms"
+	"github.com/tuannvm/slack-mcp-client/internal/affinity"
+	"github.com/tuannvm/slack-mcp-client/internal/availability"
+	"github.com/tuannvm/slack-mcp-client/internal/circuit"
 	customErrors "github.com/tuannvm/slack-mcp-client/internal/common/errors"
 	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/common/tokens"
 	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/credentials"
+	"github.com/tuannvm/slack-mcp-client/internal/dedupe"
+	"github.com/tuannvm/slack-mcp-client/internal/experiments"
 	"github.com/tuannvm/slack-mcp-client/internal/handlers"
+	"github.com/tuannvm/slack-mcp-client/internal/hooks"
+	"github.com/tuannvm/slack-mcp-client/internal/httptools"
+	"github.com/tuannvm/slack-mcp-client/internal/intent"
+	"github.com/tuannvm/slack-mcp-client/internal/jobs"
 	"github.com/tuannvm/slack-mcp-client/internal/llm"
 	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+	"github.com/tuannvm/slack-mcp-client/internal/memory"
+	"github.com/tuannvm/slack-mcp-client/internal/moderation"
+	"github.com/tuannvm/slack-mcp-client/internal/monitoring"
 	"github.com/tuannvm/slack-mcp-client/internal/observability"
+	"github.com/tuannvm/slack-mcp-client/internal/oncall"
 	"github.com/tuannvm/slack-mcp-client/internal/rag"
+	"github.com/tuannvm/slack-mcp-client/internal/rag/connectors"
+	"github.com/tuannvm/slack-mcp-client/internal/routing"
+	"github.com/tuannvm/slack-mcp-client/internal/scratchpad"
+	"github.com/tuannvm/slack-mcp-client/internal/slo"
+	"github.com/tuannvm/slack-mcp-client/internal/toolselect"
+	"github.com/tuannvm/slack-mcp-client/internal/wasmtools"
+	"github.com/tuannvm/slack-mcp-client/pkg/middleware"
 )
 
 // Client represents the Slack client application.
 type Client struct {
-	logger          *logging.Logger // Structured logger
-	userFrontend    UserFrontend
-	mcpClients      map[string]*mcp.Client
-	llmMCPBridge    *handlers.LLMMCPBridge
-	llmRegistry     *llm.ProviderRegistry // LLM provider registry
-	cfg             *config.Config        // Holds the application configuration
-	messageHistory  map[string][]Message
-	historyLimit    int
-	discoveredTools map[string]mcp.ToolInfo
-	tracingHandler  observability.TracingHandler
+	logger           *logging.Logger // Structured logger
+	userFrontend     UserFrontend
+	mcpMu            sync.RWMutex // Guards mcpClients, discoveredTools and statusWarnings, which grow as servers finish initializing
+	mcpClients       map[string]*mcp.Client
+	llmMCPBridge     *handlers.LLMMCPBridge
+	llmRegistry      *llm.ProviderRegistry // LLM provider registry
+	cfg              *config.Config        // Holds the application configuration
+	historyMu        sync.Mutex            // Guards messageHistory, which message handlers, retries and edits share
+	messageHistory   map[string][]Message
+	historyLimit     int
+	toolResults      *toolResults // Full tool results whose history entries were truncated (nil when results are kept whole)
+	discoveredTools  map[string]mcp.ToolInfo
+	statusWarnings   []string // Startup problems shown in the App Home status view
+	tracingHandler   observability.TracingHandler
+	eventDeduper     dedupe.Store             // Shared event de-duplication store (nil when disabled)
+	credentials      *credentials.Manager     // Per-user MCP credentials (nil when no server uses them)
+	moderator        moderation.Classifier    // Content moderation classifier (nil when disabled)
+	hooks            *hooks.Runner            // External hooks (nil when none are configured)
+	wasmTools        *wasmtools.Client        // Sandboxed WASM tools (nil when none are configured)
+	ragClient        *rag.Client              // Knowledge base client (nil when RAG is disabled)
+	sourceSyncer     *connectors.Syncer       // Syncs rag.sources into the knowledge base (nil when none)
+	security         config.SecurityDirectory // Resolves names in the security lists (nil when they only hold IDs)
+	conversations    *conversationTypes       // Resolves conversation types (nil when the frontend cannot look them up)
+	listeners        *channelListeners        // Channels answered without a mention (nil when none)
+	postProcessors   []postProcessor          // Edits applied to answers before they are posted
+	incidents        *incidentTracker         // Incidents running in incident channels (nil when incident mode is disabled)
+	experiments      *experiments.Manager     // A/B experiments on prompts and models (nil when none are enabled)
+	objectives       *slo.Tracker             // Service level objectives (nil when SLO tracking is disabled)
+	circuits         *circuit.Breakers        // Circuit breakers of unreachable MCP servers (nil when disabled)
+	workers          *promptWorkers           // Limit on prompts answered at once (nil when unlimited)
+	scratchpads      *scratchpad.Store        // Agent reasoning per thread (nil when scratchpads are disabled)
+	memory           *memory.Store            // Long-term facts about users and teams (nil when memory is disabled)
+	jobs             *jobs.Store              // Tool calls scheduled to run later (nil when jobs are disabled)
+	jobScheduler     *jobs.Scheduler          // The tool that schedules jobs (nil when jobs are disabled)
+	jobsCancel       context.CancelFunc       // Stops running scheduled jobs (nil when none run)
+	digestCancel     context.CancelFunc       // Stops the scheduled channel digests (nil when none run)
+	availability     *availability.Controller // Maintenance mode and quiet hours
+	inflightMu       sync.Mutex
+	inflight         map[*inflightRequest]bool // Requests being answered, which users can cancel
+	assistantMu      sync.Mutex
+	assistantThreads map[string]bool // Assistant threads by history key; true until the thread is titled
+	workflowMu       sync.Mutex
+	workflowReplies  map[string][]string // Replies to prompts asked by workflow steps, by history key
 }
 
 // Message represents a message in the conversation history
@@ -48,11 +106,35 @@ type Message struct {
 	UserID         string
 	RealName       string
 	Email          string
+	Tokens         int        // Tokens of the content, counted when the message is added
+	Tool           *ToolEntry // The call and result of a "tool" message (nil for messages added as plain text)
+}
+
+// ClientOption customizes a client created by NewClient
+type ClientOption func(*clientOptions)
+
+// clientOptions are the settings of NewClient that do not come from the config
+type clientOptions struct {
+	nativeTools []func(*handlers.NativeToolRegistry)
+}
+
+// WithNativeTools adds a tool server that runs in process, such as the tools of
+// an application embedding the client, next to the built-in tools
+func WithNativeTools(serverName string, server mcp.MCPClientInterface, tools map[string]mcp.ToolInfo) ClientOption {
+	return func(o *clientOptions) {
+		o.nativeTools = append(o.nativeTools, func(registry *handlers.NativeToolRegistry) {
+			registry.Register(serverName, server, tools)
+		})
+	}
 }
 
 // NewClient creates a new Slack client instance.
 func NewClient(userFrontend UserFrontend, stdLogger *logging.Logger, mcpClients map[string]*mcp.Client,
-	discoveredTools map[string]mcp.ToolInfo, cfg *config.Config) (*Client, error) {
+	discoveredTools map[string]mcp.ToolInfo, cfg *config.Config, opts ...ClientOption) (*Client, error) {
+	var options clientOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
 
 	// MCP clients are now optional - if none are provided, we'll just use LLM capabilities
 	if mcpClients == nil {
@@ -84,71 +166,81 @@ func NewClient(userFrontend UserFrontend, stdLogger *logging.Logger, mcpClients
 		clientLogger.DebugKV("Adding MCP client to raw map for bridge", "name", name)
 	}
 
+	// Built-in tools register their client and tools here, and are added to the bridge below
+	nativeTools := handlers.NewNativeToolRegistry(clientLogger)
+
 	// Check if RAG client is available in config and add it
+	var ragClient *rag.Client
 	if cfg.RAG.Enabled {
 		clientLogger.InfoKV("RAG enabled, creating client for bridge integration", "provider", cfg.RAG.Provider)
 
-		// Use the legacy API for now until we properly update the RAG package
-		// Convert structured config to legacy format
-		ragConfig := map[string]interface{}{
-			"provider": cfg.RAG.Provider,
+		var err error
+		ragClient, err = NewRAGClient(cfg)
+		if err != nil {
+			clientLogger.ErrorKV("Failed to create RAG client", "error", err)
+		} else {
+			nativeTools.Register(handlers.RAGServerName, ragClient, handlers.RAGToolInfos(cfg.RAG.Web))
 		}
+	}
 
-		// Add provider-specific settings
-		if providerSettings, exists := cfg.RAG.Providers[cfg.RAG.Provider]; exists {
-			switch cfg.RAG.Provider {
-			case "simple":
-				ragConfig["database_path"] = providerSettings.DatabasePath
-			case "openai":
-				if providerSettings.IndexName != "" {
-					ragConfig["vector_store_name"] = providerSettings.IndexName
-				}
-				if providerSettings.VectorStoreID != "" {
-					ragConfig["vector_store_id"] = providerSettings.VectorStoreID
-				}
-				if providerSettings.Dimensions > 0 {
-					ragConfig["dimensions"] = providerSettings.Dimensions
-				}
-				if providerSettings.SimilarityMetric != "" {
-					ragConfig["similarity_metric"] = providerSettings.SimilarityMetric
-				}
-				if providerSettings.MaxResults > 0 {
-					ragConfig["max_results"] = providerSettings.MaxResults
-				}
-				if providerSettings.ScoreThreshold > 0 {
-					ragConfig["score_threshold"] = providerSettings.ScoreThreshold
-				}
-				if providerSettings.RewriteQuery {
-					ragConfig["rewrite_query"] = providerSettings.RewriteQuery
-				}
-				if providerSettings.VectorStoreNameRegex != "" {
-					ragConfig["vector_store_name_regex"] = providerSettings.VectorStoreNameRegex
-				}
-				if providerSettings.VectorStoreMetadataKey != "" {
-					ragConfig["vs_metadata_key"] = providerSettings.VectorStoreMetadataKey
-				}
-				if providerSettings.VectorStoreMetadataValue != "" {
-					ragConfig["vs_metadata_value"] = providerSettings.VectorStoreMetadataValue
-				}
-				// Add OpenAI API key from LLM config or environment
-				if openaiConfig, exists := cfg.LLM.Providers["openai"]; exists && openaiConfig.APIKey != "" {
-					ragConfig["api_key"] = openaiConfig.APIKey
-				}
-			}
+	// Answer who-is-on-call questions through the paging provider's API
+	if cfg.OnCall.Enabled {
+		onCallClient, err := oncall.NewClient(cfg.OnCall)
+		if err != nil {
+			clientLogger.ErrorKV("Failed to create on-call client", "provider", cfg.OnCall.Provider, "error", err)
+			return nil, customErrors.WrapConfigError(err, "oncall_init_failed", "Failed to initialize on-call lookups")
 		}
+		nativeTools.Register(oncall.ServerName, onCallClient, map[string]mcp.ToolInfo{oncall.ToolName: oncall.ToolInfo(cfg.OnCall)})
+	}
+
+	// Split conversations between the arms of A/B experiments
+	experimentManager, err := experiments.NewManager(cfg.Experiments)
+	if err != nil {
+		clientLogger.ErrorKV("Failed to restore experiment rollbacks", "error", err)
+		return nil, customErrors.WrapConfigError(err, "experiments_init_failed", "Failed to initialize experiments")
+	}
 
-		// Set chunk size
-		if cfg.RAG.ChunkSize > 0 {
-			ragConfig["chunk_size"] = cfg.RAG.ChunkSize
+	// Let the LLM schedule tool calls to run later
+	var jobStore *jobs.Store
+	var jobScheduler *jobs.Scheduler
+	if cfg.Jobs.Enabled {
+		var err error
+		jobStore, err = jobs.NewStore(cfg.Jobs.StorePath, cfg.Jobs.MaxJobs)
+		if err != nil {
+			clientLogger.ErrorKV("Failed to open job store", "path", cfg.Jobs.StorePath, "error", err)
+			return nil, customErrors.WrapConfigError(err, "jobs_init_failed", "Failed to initialize scheduled tool calls")
 		}
+		jobScheduler = jobs.NewScheduler(jobStore, cfg.Jobs, cfg.Slack.SlashCommand)
+		nativeTools.Register(jobs.ServerName, jobScheduler, map[string]mcp.ToolInfo{jobs.ToolName: jobs.ToolInfo()})
+	}
 
-		ragClient, err := rag.NewClientWithProvider(cfg.RAG.Provider, ragConfig)
+	// Run the configured WASM tools in a sandbox
+	var wasmClient *wasmtools.Client
+	if len(cfg.WASMTools) > 0 {
+		var err error
+		wasmClient, err = wasmtools.NewClient(context.Background(), cfg.WASMTools, clientLogger.WithName("wasm-tools"))
 		if err != nil {
-			clientLogger.ErrorKV("Failed to create RAG client", "error", err)
-		} else {
-			rawClientMap["rag"] = ragClient
-			clientLogger.DebugKV("Added RAG client to raw map for bridge", "name", "rag")
+			clientLogger.ErrorKV("Failed to load WASM tools", "error", err)
+			return nil, customErrors.WrapConfigError(err, "wasm_tools_init_failed", "Failed to load WASM tools")
 		}
+		nativeTools.Register(wasmtools.ServerName, wasmClient, wasmtools.ToolInfos(cfg.WASMTools))
+	}
+
+	// Call the REST endpoints declared as tools
+	if len(cfg.HTTPTools) > 0 {
+		httpToolsClient, err := httptools.NewClient(cfg.HTTPTools)
+		if err != nil {
+			clientLogger.ErrorKV("Failed to load HTTP tools", "error", err)
+			return nil, customErrors.WrapConfigError(err, "http_tools_init_failed", "Failed to load HTTP tools")
+		}
+		nativeTools.Register(httptools.ServerName, httpToolsClient, httptools.ToolInfos(cfg.HTTPTools))
+	}
+
+	// Let the model fetch tool results that were truncated in the history
+	var fullToolResults *toolResults
+	if cfg.Slack.ToolHistory.MaxResultChars > 0 {
+		fullToolResults = newToolResults()
+		nativeTools.Register(toolHistoryServerName, fullToolResults, map[string]mcp.ToolInfo{toolResultToolName: toolResultInfo()})
 	}
 
 	logLevel := getLogLevel(stdLogger)
@@ -164,6 +256,14 @@ func NewClient(userFrontend UserFrontend, stdLogger *logging.Logger, mcpClients
 	}
 	clientLogger.Info("LLM provider registry initialized successfully")
 
+	// Configure chunking, hybrid search and reranking for the knowledge base
+	if ragClient != nil {
+		if err := ConfigureRAGRetrieval(ragClient, cfg, registry, clientLogger); err != nil {
+			clientLogger.ErrorKV("Failed to configure RAG retrieval", "mode", cfg.RAG.Search.Mode, "error", err)
+			return nil, customErrors.WrapConfigError(err, "rag_retrieval_init_failed", "Failed to configure RAG search")
+		}
+	}
+
 	// Load custom prompt from file if specified and customPrompt is empty
 	if cfg.LLM.CustomPromptFile != "" && cfg.LLM.CustomPrompt == "" {
 		content, err := os.ReadFile(cfg.LLM.CustomPromptFile)
@@ -175,6 +275,17 @@ func NewClient(userFrontend UserFrontend, stdLogger *logging.Logger, mcpClients
 		clientLogger.InfoKV("Loaded custom prompt from file", "file", cfg.LLM.CustomPromptFile)
 	}
 
+	// Add the built-in tools, which give way to MCP tools of the same name
+	for _, register := range options.nativeTools {
+		register(nativeTools)
+	}
+	if discoveredTools == nil {
+		discoveredTools = make(map[string]mcp.ToolInfo)
+	}
+	if added := nativeTools.AddTo(rawClientMap, discoveredTools); added > 0 {
+		clientLogger.InfoKV("Added native tools to available tools", "servers", nativeTools.Len(), "tool_count", added)
+	}
+
 	// Pass the raw map to the bridge with the configured log level
 	llmMCPBridge := handlers.NewLLMMCPBridgeFromClientsWithLogLevel(
 		rawClientMap,
@@ -185,12 +296,200 @@ func NewClient(userFrontend UserFrontend, stdLogger *logging.Logger, mcpClients
 		cfg,
 	)
 	clientLogger.InfoKV("LLM-MCP bridge initialized", "clients", len(mcpClients), "tools", len(discoveredTools))
+	if jobScheduler != nil {
+		jobScheduler.SetToolLookup(llmMCPBridge.HasTool)
+	}
+
+	// Initialize embedding-based tool selection for large tool sets
+	if cfg.LLM.ToolSelection.Enabled {
+		selector, err := toolselect.NewSelectorFromConfig(cfg, clientLogger)
+		if err != nil {
+			clientLogger.ErrorKV("Failed to initialize tool selection", "provider", cfg.LLM.ToolSelection.Provider, "error", err)
+			return nil, customErrors.WrapConfigError(err, "tool_selection_init_failed", "Failed to initialize tool selection")
+		}
+		llmMCPBridge.SetToolSelector(selector)
+	}
+
+	// Route trivial prompts to a cheaper model
+	if cfg.LLM.Routing.Enabled {
+		clientLogger.InfoKV("Using model routing", "cheap_provider", cfg.LLM.Routing.Cheap.Provider, "cheap_model", cfg.LLM.Routing.Cheap.Model,
+			"powerful_provider", cfg.LLM.Routing.Powerful.Provider, "powerful_model", cfg.LLM.Routing.Powerful.Model)
+		llmMCPBridge.SetRouter(routing.NewRouter(cfg.LLM.Routing))
+	}
+
+	// Keep the tool schemas out of prompts that do not need them
+	if cfg.LLM.Intent.Enabled {
+		clientLogger.InfoKV("Using the intent gate", "classifier", cfg.LLM.Intent.Classifier, "provider", cfg.LLM.Intent.Provider, "model", cfg.LLM.Intent.Model)
+		llmMCPBridge.SetIntentClassifier(intent.NewClassifier(cfg.LLM.Intent, llmMCPBridge.IntentCompletion))
+	}
+
+	// Keep each thread on the model that first answered it
+	if cfg.LLM.ThreadAffinity.Enabled {
+		threadModels, err := affinity.NewStore(cfg.LLM.ThreadAffinity.StorePath, cfg.LLM.ThreadAffinity.MaxThreads)
+		if err != nil {
+			clientLogger.ErrorKV("Failed to open thread model store", "path", cfg.LLM.ThreadAffinity.StorePath, "error", err)
+			return nil, customErrors.WrapConfigError(err, "thread_affinity_init_failed", "Failed to initialize thread affinity")
+		}
+		llmMCPBridge.SetThreadAffinity(threadModels)
+	}
+
+	// Remember facts about users and teams across conversations
+	var memoryStore *memory.Store
+	if cfg.Memory.Enabled {
+		memoryStore, err = memory.NewStore(cfg.Memory.StorePath, cfg.Memory.MaxFacts)
+		if err != nil {
+			clientLogger.ErrorKV("Failed to open memory store", "path", cfg.Memory.StorePath, "error", err)
+			return nil, customErrors.WrapConfigError(err, "memory_init_failed", "Failed to initialize long-term memory")
+		}
+		llmMCPBridge.SetMemory(memoryStore)
+	}
+
+	// Run policy hooks before messages, tool calls and responses
+	var hookRunner *hooks.Runner
+	if len(cfg.Hooks) > 0 {
+		clientLogger.InfoKV("Using external hooks", "count", len(cfg.Hooks))
+		hookRunner = hooks.NewRunner(cfg.Hooks, clientLogger.WithName("hooks"))
+	}
+
+	// Track the service level objectives
+	objectives := slo.NewTracker(cfg.SLO)
+
+	// Stop calling MCP servers that keep being unreachable
+	circuits := circuit.NewBreakers(cfg.MCPCircuit)
+
+	// Run tool calls and LLM calls through the configured middlewares, then the
+	// tool call hooks, then the SLO tracking of tool calls, then the circuit breakers
+	var inner []middleware.Middleware
+	for _, m := range []middleware.Middleware{hookRunner.Middleware(), objectives.Middleware(), circuits.Middleware()} {
+		if m != nil {
+			inner = append(inner, m)
+		}
+	}
+	if len(cfg.Middlewares) > 0 || len(inner) > 0 {
+		chain, err := handlers.NewMiddlewareChain(cfg.Middlewares, clientLogger, inner...)
+		if err != nil {
+			clientLogger.ErrorKV("Failed to initialize middlewares", "error", err)
+			return nil, customErrors.WrapConfigError(err, "middleware_init_failed", "Failed to initialize middlewares")
+		}
+		clientLogger.InfoKV("Using middlewares", "count", chain.Len())
+		llmMCPBridge.SetMiddlewares(chain)
+	}
+
+	// Keep external knowledge base sources in sync
+	var sourceSyncer *connectors.Syncer
+	if ragClient != nil && len(cfg.RAG.Sources) > 0 {
+		sourceSyncer, err = connectors.NewSyncer(cfg.RAG.Sources, ragClient.GetProvider(), clientLogger)
+		if err != nil {
+			clientLogger.ErrorKV("Failed to initialize knowledge base sources", "error", err)
+			return nil, customErrors.WrapConfigError(err, "rag_sources_init_failed", "Failed to initialize RAG sources")
+		}
+	}
 
 	// Initialize observability
 	tracingHandler := observability.NewTracingHandler(cfg, clientLogger)
 
+	// Initialize event de-duplication. Redelivered events are ignored in memory
+	// unless another provider is enabled, e.g. Redis for multi-replica deployments.
+	dedupeCfg := cfg.Dedupe
+	if !dedupeCfg.Enabled {
+		dedupeCfg.Provider = config.DedupeProviderMemory
+	}
+	eventDeduper, err := dedupe.NewStore(dedupeCfg, clientLogger)
+	if err != nil {
+		clientLogger.ErrorKV("Failed to initialize event de-duplication", "provider", dedupeCfg.Provider, "error", err)
+		return nil, customErrors.WrapConfigError(err, "dedupe_init_failed", "Failed to initialize event de-duplication")
+	}
+
+	// Initialize per-user credentials for servers that act on behalf of the requesting user
+	credentialManager, err := credentials.NewManager(cfg, clientLogger)
+	if err != nil {
+		clientLogger.ErrorKV("Failed to initialize credential vault", "error", err)
+		return nil, customErrors.WrapConfigError(err, "credentials_init_failed", "Failed to initialize per-user credentials")
+	}
+	if credentialManager != nil {
+		for _, serverName := range credentialManager.Servers() {
+			if mcpClient, ok := mcpClients[serverName]; ok {
+				mcpClient.SetCredentialSource(credentialManager)
+			}
+		}
+		credentialManager.SetNotifier(func(userID, text string) {
+			userFrontend.SendMessage(userID, "", text)
+		})
+	}
+
+	// Initialize content moderation
+	var moderator moderation.Classifier
+	if cfg.Moderation.Enabled {
+		moderator, err = moderation.NewClassifier(cfg, clientLogger)
+		if err != nil {
+			clientLogger.ErrorKV("Failed to initialize content moderation", "provider", cfg.Moderation.Provider, "error", err)
+			return nil, customErrors.WrapConfigError(err, "moderation_init_failed", "Failed to initialize content moderation")
+		}
+	}
+
+	// Resolve the user group handles and channel names in the security lists
+	var securityDirectory config.SecurityDirectory
+	if cfg.Security.Enabled && cfg.Security.UsesSlackNames() {
+		if directory, ok := userFrontend.(DirectoryFrontend); ok {
+			ttl, _ := time.ParseDuration(cfg.Security.LookupCacheTTL) // Validated when the config is loaded
+			securityDirectory = newSecurityDirectory(directory, ttl, clientLogger.WithName("security"))
+		} else {
+			clientLogger.Warn("Security lists name user groups or channels, but this frontend cannot resolve them; only IDs will match")
+		}
+	}
+
+	// Maintenance mode and quiet hours, which the readiness endpoint reports
+	availabilityController, err := availability.NewController(cfg.Maintenance)
+	if err != nil {
+		clientLogger.ErrorKV("Failed to initialize maintenance settings", "error", err)
+		return nil, customErrors.WrapConfigError(err, "maintenance_init_failed", "Failed to initialize maintenance settings")
+	}
+	availability.Register(availabilityController)
+
+	// Resolve whether messages come from DMs, group DMs or channels
+	var conversations *conversationTypes
+	if api, ok := userFrontend.(ConversationFrontend); ok {
+		ttl, _ := time.ParseDuration(cfg.Slack.Conversations.CacheTTL) // Validated when the config is loaded
+		conversations = newConversationTypes(api, ttl, clientLogger.WithName("conversations"))
+	}
+
+	// Answer matching messages without a mention in listened channels
+	var listeners *channelListeners
+	if len(cfg.Slack.Listeners) > 0 {
+		listeners, err = newChannelListeners(cfg.Slack.Listeners)
+		if err != nil {
+			return nil, customErrors.WrapConfigError(err, "listeners_init_failed", "Failed to initialize channel listeners")
+		}
+	}
+
+	// Edit answers before they are posted
+	postProcessors, err := newPostProcessors(cfg.Slack.PostProcessors)
+	if err != nil {
+		return nil, customErrors.WrapConfigError(err, "post_processors_init_failed", "Failed to initialize response post-processors")
+	}
+
+	// Track incidents in incident channels
+	var incidents *incidentTracker
+	if cfg.Slack.Incidents.Enabled {
+		api, _ := userFrontend.(ConversationFrontend)
+		incidents, err = newIncidentTracker(cfg.Slack.Incidents, api)
+		if err != nil {
+			return nil, customErrors.WrapConfigError(err, "incidents_init_failed", "Failed to initialize incident mode")
+		}
+	}
+
+	// Keep the reasoning of agent runs per thread
+	var scratchpads *scratchpad.Store
+	if cfg.Slack.Scratchpad.Enabled {
+		scratchpads, err = scratchpad.NewStore(cfg.Slack.Scratchpad.StorePath, cfg.Slack.Scratchpad.MaxThreads)
+		if err != nil {
+			clientLogger.ErrorKV("Failed to open scratchpad store", "path", cfg.Slack.Scratchpad.StorePath, "error", err)
+			return nil, customErrors.WrapConfigError(err, "scratchpad_init_failed", "Failed to initialize agent scratchpads")
+		}
+	}
+
 	// --- Create and return Client instance ---
-	return &Client{
+	client := &Client{
 		logger:          clientLogger,
 		userFrontend:    userFrontend,
 		mcpClients:      mcpClients,
@@ -199,26 +498,124 @@ func NewClient(userFrontend UserFrontend, stdLogger *logging.Logger, mcpClients
 		cfg:             cfg,
 		messageHistory:  make(map[string][]Message),
 		historyLimit:    cfg.Slack.MessageHistory, // Store configured number of messages per channel
+		toolResults:     fullToolResults,
 		discoveredTools: discoveredTools,
 		tracingHandler:  tracingHandler,
-	}, nil
+		eventDeduper:    eventDeduper,
+		credentials:     credentialManager,
+		moderator:       moderator,
+		hooks:           hookRunner,
+		wasmTools:       wasmClient,
+		ragClient:       ragClient,
+		sourceSyncer:    sourceSyncer,
+		security:        securityDirectory,
+		conversations:   conversations,
+		listeners:       listeners,
+		postProcessors:  postProcessors,
+		incidents:       incidents,
+		experiments:     experimentManager,
+		scratchpads:     scratchpads,
+		memory:          memoryStore,
+		jobs:            jobStore,
+		jobScheduler:    jobScheduler,
+		availability:    availabilityController,
+		objectives:      objectives,
+		circuits:        circuits,
+		workers:         newPromptWorkers(cfg.Slack.Workers),
+		workflowReplies: make(map[string][]string),
+	}
+	client.experiments.SetRollbackHandler(client.handleExperimentRollback)
+	client.objectives.SetAlertHandler(client.handleSLOAlert)
+	client.circuits.SetChangeHandler(client.handleCircuitChange)
+	return client, nil
 }
 
 // Run starts the Socket Mode event loop and event handling.
 func (c *Client) Run() error {
 	go c.handleEvents()
-	c.logger.Info("Starting Slack Socket Mode listener...")
+	go c.indexTools()
+	go c.embedKnowledgeBase()
+	c.startDigests()
+	c.startJobs()
+	if c.credentials != nil {
+		c.credentials.Start()
+	}
+	if c.sourceSyncer != nil {
+		c.sourceSyncer.Start()
+	}
+	if c.ragClient != nil {
+		c.ragClient.StartStoreMetrics(ragStoreMetricsInterval, func(err error) {
+			c.logger.WarnKV("Failed to read knowledge base statistics", "error", err)
+		})
+		if gc := c.cfg.RAG.GC; gc.Enabled {
+			c.ragClient.StartGarbageCollection(gc.GetInterval(), rag.GCOptions{MinAge: gc.GetMinAge()}, func(result rag.GCResult, err error) {
+				if err != nil {
+					c.logger.WarnKV("Knowledge base garbage collection failed", "error", err)
+					return
+				}
+				c.logger.InfoKV("Collected knowledge base garbage", "failed_entries", result.FailedEntries,
+					"orphaned_files", result.OrphanedFiles, "freed_bytes", result.FreedBytes)
+			})
+		}
+	}
+	c.logger.InfoKV("Starting Slack event listener...", "mode", c.cfg.Slack.Mode)
 	return c.userFrontend.Run()
 }
 
 // Close gracefully closes the Slack client
 func (c *Client) Close() error {
 	c.logger.Info("Closing Slack client...")
+	if stopped := c.stopAllRequests(); stopped > 0 {
+		c.logger.InfoKV("Stopped requests in progress", "count", stopped)
+	}
+	c.stopDigests()
+	c.stopJobs()
+	// Flush any replies still waiting in the outbound queue
+	if closer, ok := c.userFrontend.(interface{ Close() error }); ok {
+		if err := closer.Close(); err != nil {
+			c.logger.ErrorKV("Failed to close user frontend", "error", err)
+		}
+	}
+	if c.eventDeduper != nil {
+		if err := c.eventDeduper.Close(); err != nil {
+			c.logger.ErrorKV("Failed to close event de-duplication store", "error", err)
+		}
+	}
+	if c.credentials != nil {
+		if err := c.credentials.Close(); err != nil {
+			c.logger.ErrorKV("Failed to stop OAuth callback listener", "error", err)
+		}
+	}
+	if c.sourceSyncer != nil {
+		c.sourceSyncer.Close()
+	}
+	if c.ragClient != nil {
+		c.ragClient.StopStoreMetrics()
+		c.ragClient.StopGarbageCollection()
+	}
+	if c.wasmTools != nil {
+		if err := c.wasmTools.Close(context.Background()); err != nil {
+			c.logger.ErrorKV("Failed to close WASM tools", "error", err)
+		}
+	}
 	// Note: socketmode.Client doesn't have a public Close method
 	// The client will stop when the context is cancelled or when there's a connection error
 	return nil
 }
 
+// SetModel answers every following request with a provider of llm.providers and
+// a model of that provider, or the provider's configured model when model is
+// empty. An empty provider restores the configured provider and model routing.
+func (c *Client) SetModel(provider, model string) error {
+	if provider != "" {
+		if _, err := c.llmRegistry.GetProvider(provider); err != nil {
+			return err
+		}
+	}
+	c.llmMCPBridge.PinModel(provider, model)
+	return nil
+}
+
 // handleEvents listens for incoming events and dispatches them.
 func (c *Client) handleEvents() {
 	for evt := range c.userFrontend.GetEventChannel() {
@@ -237,7 +634,26 @@ func (c *Client) handleEvents() {
 			}
 			c.userFrontend.Ack(*evt.Request)
 			c.logger.InfoKV("Received EventsAPI event", "type", eventsAPIEvent.Type)
+			if !c.claimEvent(eventsAPIEvent) {
+				continue
+			}
 			c.handleEventMessage(eventsAPIEvent)
+		case socketmode.EventTypeInteractive:
+			callback, ok := evt.Data.(slack.InteractionCallback)
+			if !ok {
+				c.logger.WarnKV("Ignored unexpected interactive event type", "type", fmt.Sprintf("%T", evt.Data))
+				continue
+			}
+			c.userFrontend.Ack(*evt.Request)
+			go c.handleInteraction(callback)
+		case socketmode.EventTypeSlashCommand:
+			command, ok := evt.Data.(slack.SlashCommand)
+			if !ok {
+				c.logger.WarnKV("Ignored unexpected slash command event type", "type", fmt.Sprintf("%T", evt.Data))
+				continue
+			}
+			c.userFrontend.Ack(*evt.Request)
+			go c.handleSlashCommand(command)
 		default:
 			c.logger.DebugKV("Ignored event type", "type", evt.Type)
 		}
@@ -245,6 +661,39 @@ func (c *Client) handleEvents() {
 	c.logger.Info("Slack event channel closed.")
 }
 
+// claimEvent reports whether this replica should process the event, which it
+// should not when Slack redelivered it or another replica claimed it first.
+// Events without an ID, or without a dedupe store, are always processed.
+// If the dedupe store is unreachable the event is processed rather than dropped.
+func (c *Client) claimEvent(event slackevents.EventsAPIEvent) bool {
+	if c.eventDeduper == nil {
+		return true
+	}
+	callback, ok := event.Data.(*slackevents.EventsAPICallbackEvent)
+	if !ok || callback.EventID == "" {
+		return true
+	}
+
+	replica := c.cfg.Dedupe.ReplicaID
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	claimed, err := c.eventDeduper.Claim(ctx, callback.EventID)
+	switch {
+	case err != nil:
+		c.logger.WarnKV("Event de-duplication failed, processing event anyway", "event_id", callback.EventID, "error", err)
+		monitoring.SlackEventsDeduped.WithLabelValues(replica, "error").Inc()
+		return true
+	case !claimed:
+		c.logger.DebugKV("Event already claimed, skipping redelivery", "event_id", callback.EventID, "replica", replica)
+		monitoring.SlackEventsDeduped.WithLabelValues(replica, "duplicate").Inc()
+		return false
+	default:
+		monitoring.SlackEventsDeduped.WithLabelValues(replica, "claimed").Inc()
+		return true
+	}
+}
+
 // handleEventMessage processes specific EventsAPI messages.
 func (c *Client) handleEventMessage(event slackevents.EventsAPIEvent) {
 	switch event.Type {
@@ -253,39 +702,119 @@ func (c *Client) handleEventMessage(event slackevents.EventsAPIEvent) {
 		switch ev := innerEvent.Data.(type) {
 		case *slackevents.AppMentionEvent:
 			c.logger.InfoKV("Received app mention in channel", "channel", ev.Channel, "user", ev.User, "text", ev.Text, "ThreadTS", ev.ThreadTimeStamp)
+			if conversationType := c.conversationType(ev.Channel, ""); !c.answersMention(conversationType) {
+				c.logger.DebugKV("Ignoring mention, replies are off in this conversation type", "channel", ev.Channel, "type", conversationType)
+				return
+			}
 			messageText := c.userFrontend.RemoveBotMention(ev.Text)
+			if isStopCommand(messageText) {
+				go c.handleStopCommand(ev.Channel, ev.ThreadTimeStamp, ev.User)
+				return
+			}
+			parentTS := ev.ThreadTimeStamp
+			if parentTS == "" {
+				parentTS = ev.TimeStamp // Use the original message timestamp if no thread
+			}
+			if c.handleIncidentCommand(messageText, ev.Channel, parentTS, ev.User) {
+				return
+			}
+			if c.handleModelCommand(messageText, ev.Channel, ev.ThreadTimeStamp, parentTS, ev.User) {
+				return
+			}
 			profile, err := c.userFrontend.GetUserInfo(ev.User)
 			if err != nil {
 				c.logger.WarnKV("Failed to get user info", "user", ev.User, "error", err)
 				profile = &UserProfile{userId: ev.User, realName: "Unknown", email: ""}
 			}
 
-			parentTS := ev.ThreadTimeStamp
-			if parentTS == "" {
-				parentTS = ev.TimeStamp // Use the original message timestamp if no thread
-			}
 			// Use handleUserPrompt for app mentions too, for consistency
 			go c.handleUserPrompt(strings.TrimSpace(messageText), ev.Channel, parentTS, ev.TimeStamp, profile)
 
 		case *slackevents.MessageEvent:
-			isDirectMessage := strings.HasPrefix(ev.Channel, "D")
+			if ev.SubType == "message_changed" {
+				go c.handleEditedMessage(ev)
+				return
+			}
 			isValidUser := c.userFrontend.IsValidUser(ev.User)
-			isNotEdited := ev.SubType != "message_changed"
 			isBot := ev.BotID != "" || ev.SubType == "bot_message"
+			if !isValidUser || isBot {
+				return
+			}
+			conversationType := c.conversationType(ev.Channel, ev.ChannelType)
+			if conversationType == config.ConversationPublic || conversationType == config.ConversationPrivate {
+				go c.trackIncidentMessage(ev.Channel, ev.TimeStamp, ev.User, ev.Text)
+			}
+			messageText := c.userFrontend.RemoveBotMention(ev.Text)
+			mentioned := messageText != ev.Text
+			if !c.answersMessage(conversationType, mentioned) && (mentioned || !c.listenerAnswers(ev.Channel, conversationType, messageText)) {
+				return
+			}
 
-			if isDirectMessage && isValidUser && isNotEdited && !isBot {
-				c.logger.InfoKV("Received direct message in channel", "channel", ev.Channel, "user", ev.User, "text", ev.Text, "ThreadTS", ev.ThreadTimeStamp)
-				profile, err := c.userFrontend.GetUserInfo(ev.User)
-				if err != nil {
-					c.logger.WarnKV("Failed to get user info", "user", ev.User, "error", err)
-					profile = &UserProfile{userId: ev.User, realName: "Unknown", email: ""}
-				}
+			c.logger.InfoKV("Received message", "channel", ev.Channel, "type", conversationType, "user", ev.User, "text", ev.Text, "ThreadTS", ev.ThreadTimeStamp)
+			// Threads in the app's DM are assistant threads, including those started before a restart
+			isDirectMessage := conversationType == config.ConversationIM
+			if isDirectMessage && c.cfg.Slack.Assistant.Enabled && ev.ThreadTimeStamp != "" {
+				c.markAssistantThread(ev.Channel, ev.ThreadTimeStamp, false)
+			}
+			profile, err := c.userFrontend.GetUserInfo(ev.User)
+			if err != nil {
+				c.logger.WarnKV("Failed to get user info", "user", ev.User, "error", err)
+				profile = &UserProfile{userId: ev.User, realName: "Unknown", email: ""}
+			}
+
+			parentTS := ev.ThreadTimeStamp
+			if parentTS == "" {
+				parentTS = ev.TimeStamp // Use the original message timestamp if no thread
+			}
+			// Admin and credential commands are only accepted in DMs
+			if isDirectMessage && c.handleCredentialCommand(messageText, ev.Channel, parentTS, ev.User) {
+				return
+			}
+			if isDirectMessage && c.handleMaintenanceCommand(messageText, ev.Channel, parentTS, ev.User) {
+				return
+			}
+			if isStopCommand(messageText) {
+				go c.handleStopCommand(ev.Channel, ev.ThreadTimeStamp, ev.User)
+				return
+			}
+			if c.handleModelCommand(messageText, ev.Channel, ev.ThreadTimeStamp, parentTS, ev.User) {
+				return
+			}
+			go c.handleUserPrompt(strings.TrimSpace(messageText), ev.Channel, parentTS, ev.TimeStamp, profile) // Use goroutine to avoid blocking event loop
 
-				parentTS := ev.ThreadTimeStamp
-				if parentTS == "" {
-					parentTS = ev.TimeStamp // Use the original message timestamp if no thread
+		case *slackevents.ReactionAddedEvent:
+			if ev.Reaction == cancelReaction {
+				go c.handleCancelReaction(ev.Item.Channel, ev.Item.Timestamp, ev.User)
+			}
+			if c.incidents != nil && ev.Reaction == c.cfg.Slack.Incidents.TimelineReaction {
+				go c.handleTimelineReaction(ev.Item.Channel, ev.Item.Timestamp)
+			}
+			if c.experiments != nil {
+				if positive, ok := c.feedbackSentiment(ev.Reaction); ok {
+					go c.handleFeedbackReaction(ev.Item.Channel, ev.Item.Timestamp, ev.User, positive)
 				}
-				go c.handleUserPrompt(ev.Text, ev.Channel, parentTS, ev.TimeStamp, profile) // Use goroutine to avoid blocking event loop
+			}
+			if action, ok := c.reactionAction(ev.Reaction); ok && ev.Item.Type == "message" {
+				go c.handleReactionAction(ev.Item.Channel, ev.Item.Timestamp, ev.User, action)
+			}
+
+		case *slackevents.AssistantThreadStartedEvent:
+			c.logger.InfoKV("Assistant thread started", "channel", ev.AssistantThread.ChannelID, "user", ev.AssistantThread.UserID)
+			go c.startAssistantThread(ev.AssistantThread.ChannelID, ev.AssistantThread.ThreadTimeStamp)
+
+		case *slackevents.AssistantThreadContextChangedEvent:
+			if c.cfg.Slack.Assistant.Enabled {
+				c.markAssistantThread(ev.AssistantThread.ChannelID, ev.AssistantThread.ThreadTimeStamp, false)
+			}
+
+		case *slackevents.AppHomeOpenedEvent:
+			if ev.Tab == "home" {
+				go c.publishHomeView(ev.User)
+			}
+
+		case *slackevents.FunctionExecutedEvent:
+			if c.cfg.Slack.WorkflowStep.Enabled && ev.Function.CallbackID == c.cfg.Slack.WorkflowStep.CallbackID {
+				go c.handleFunctionExecuted(ev)
 			}
 
 		default:
@@ -296,44 +825,127 @@ func (c *Client) handleEventMessage(event slackevents.EventsAPIEvent) {
 	}
 }
 
+// customPrompt returns the system prompt of the request, which an experiment
+// variant may replace
+func (c *Client) customPrompt(ctx context.Context) string {
+	if assignment, ok := experiments.FromContext(ctx); ok && assignment.CustomPrompt != "" {
+		return assignment.CustomPrompt
+	}
+	return c.cfg.LLM.CustomPrompt
+}
+
 func historyKey(channelID, threadTS string) string {
 	return fmt.Sprintf("%s:%s", channelID, threadTS)
 }
 
 // addToHistory adds a message to the channel history
 func (c *Client) addToHistory(channelID, threadTS, timestamp, role, content, userID, realName, email string) {
-	key := historyKey(channelID, threadTS)
-	history, exists := c.messageHistory[key]
-	if !exists {
-		history = []Message{}
-	}
-
-	// Add the new message
-	message := Message{
+	c.appendHistory(channelID, threadTS, Message{
 		Role:           role,
 		Content:        content,
-		Timestamp:      time.Now(),
 		SlackTimestamp: timestamp,
 		UserID:         userID,
 		RealName:       realName,
 		Email:          email,
-	}
-	history = append(history, message)
+	})
+}
+
+// appendHistory adds a message to the thread history and trims the history to its
+// limits
+func (c *Client) appendHistory(channelID, threadTS string, message Message) {
+	key := historyKey(channelID, threadTS)
+	message.Timestamp = time.Now()
+	message.Tokens = tokens.Count(message.Content)
+	c.historyMu.Lock()
+	defer c.historyMu.Unlock()
+	history := append(c.messageHistory[key], message)
 
 	// Limit history size
-	if len(history) > c.historyLimit {
-		history = history[len(history)-c.historyLimit:]
+	kept := history
+	if len(kept) > c.historyLimit {
+		kept = kept[len(kept)-c.historyLimit:]
+	}
+	kept = c.trimHistoryTokens(kept)
+
+	// Full tool results go with their entries
+	if c.toolResults != nil && len(kept) < len(history) {
+		remaining := make(map[*ToolEntry]bool, len(kept))
+		for _, msg := range kept {
+			remaining[msg.Tool] = true
+		}
+		for _, msg := range history {
+			if msg.Tool != nil && msg.Tool.Truncated() && !remaining[msg.Tool] {
+				c.toolResults.remove(key, msg.Tool.ResultID)
+			}
+		}
+	}
+
+	c.messageHistory[key] = kept
+}
+
+// threadHistory returns a copy of the thread history, safe to use while other
+// messages of the thread are handled
+func (c *Client) threadHistory(channelID, threadTS string) []Message {
+	c.historyMu.Lock()
+	defer c.historyMu.Unlock()
+	return slices.Clone(c.messageHistory[historyKey(channelID, threadTS)])
+}
+
+// trimHistoryTokens evicts messages until the weighted tokens of the history fit
+// the budget: the oldest tool results first, since they tend to be the largest and
+// the least needed later, then the oldest messages that count against the budget.
+// The newest message is kept.
+func (c *Client) trimHistoryTokens(history []Message) []Message {
+	budget := c.cfg.Slack.HistoryTokens
+	if budget.MaxTokens <= 0 {
+		return history
 	}
+	weighted := func(msg Message) float64 {
+		return float64(msg.Tokens) * budget.Weight(msg.Role)
+	}
+	var total float64
+	for _, msg := range history {
+		total += weighted(msg)
+	}
+	if total <= float64(budget.MaxTokens) {
+		return history
+	}
+
+	evicted := make([]bool, len(history))
+	last := len(history) - 1
+	for i := 0; i < last && total > float64(budget.MaxTokens); i++ {
+		if history[i].Role == "tool" {
+			evicted[i] = true
+			total -= weighted(history[i])
+		}
+	}
+	for i := 0; i < last && total > float64(budget.MaxTokens); i++ {
+		if !evicted[i] && weighted(history[i]) > 0 {
+			evicted[i] = true
+			total -= weighted(history[i])
+		}
+	}
+
+	kept := make([]Message, 0, len(history))
+	for i, msg := range history {
+		if !evicted[i] {
+			kept = append(kept, msg)
+		}
+	}
+	c.logger.DebugKV("Trimmed history to its token budget", "evicted", len(history)-len(kept), "tokens", int(total))
+	return kept
+}
 
-	c.messageHistory[key] = history
+// historyContext returns the conversation context of a part of the thread history,
+// and ctx with its tool results, which reach the LLM as tool messages
+func (c *Client) historyContext(ctx context.Context, channelID string, history []Message) (context.Context, string) {
+	return handlers.ContextWithToolResults(ctx, toolResultMessages(history)), c.contextFromMessages(channelID, history)
 }
 
-// getContextFromHistory builds a context string from message history
-//
-//nolint:unused // Reserved for future use
-func (c *Client) getContextFromHistory(channelID string, threadTS string) string {
-	history, exists := c.messageHistory[historyKey(channelID, threadTS)]
-	if !exists || len(history) == 0 {
+// contextFromMessages builds a context string from a part of the message history.
+// Tool results with a structured entry are left out; see toolResultMessages.
+func (c *Client) contextFromMessages(channelID string, history []Message) string {
+	if len(history) == 0 {
 		return ""
 	}
 
@@ -347,6 +959,9 @@ func (c *Client) getContextFromHistory(channelID string, threadTS string) string
 			sanitizedContent := strings.ReplaceAll(msg.Content, "\n", " \\n ")
 			contextBuilder.WriteString(fmt.Sprintf("%s: %s\n", prefix, sanitizedContent))
 		case "tool":
+			if msg.Tool != nil {
+				continue
+			}
 			prefix := "Tool Result"
 			sanitizedContent := strings.ReplaceAll(msg.Content, "\n", " \\n ")
 			contextBuilder.WriteString(fmt.Sprintf("%s: %s\n", prefix, sanitizedContent))
@@ -369,11 +984,19 @@ func (c *Client) getContextFromHistory(channelID string, threadTS string) string
 
 // handleUserPrompt sends the user's text to the configured LLM provider.
 func (c *Client) handleUserPrompt(userPrompt, channelID, threadTS string, timestamp string, profile *UserProfile) {
+	c.answerPrompt(userPrompt, channelID, threadTS, timestamp, profile, "")
+}
+
+// answerPrompt answers a prompt. A prompt that branches off an earlier one, set by
+// branchOf, sees only the conversation that came before that prompt.
+func (c *Client) answerPrompt(userPrompt, channelID, threadTS string, timestamp string, profile *UserProfile, branchOf string) {
+	received := time.Now()
+	defer c.promptAnswered(channelID, threadTS, timestamp)
 	c.logger.DebugKV("Routing prompt via configured provider", "provider", c.cfg.LLM.Provider)
 	c.logger.DebugKV("User prompt", "text", userPrompt)
 
 	// Security validation check
-	securityResult := c.cfg.ValidateAccess(profile.userId, channelID)
+	securityResult := c.cfg.ValidateAccessWithDirectory(profile.userId, channelID, c.security)
 	if !securityResult.Allowed {
 		// Log unauthorized access attempt if enabled
 		if c.cfg.Security.LogUnauthorized != nil && *c.cfg.Security.LogUnauthorized {
@@ -388,7 +1011,7 @@ func (c *Client) handleUserPrompt(userPrompt, channelID, threadTS string, timest
 
 		// Send rejection message if configured
 		if c.cfg.Security.RejectionMessage != "" {
-			c.userFrontend.SendMessage(channelID, threadTS, c.cfg.Security.RejectionMessage)
+			c.sendMessage(channelID, threadTS, c.cfg.Security.RejectionMessage)
 		}
 
 		// Early return - do not process the request further
@@ -406,13 +1029,89 @@ func (c *Client) handleUserPrompt(userPrompt, channelID, threadTS string, timest
 		)
 	}
 
-	ctx, span := c.tracingHandler.StartTrace(context.Background(), "slack-user-interaction", userPrompt, map[string]string{
+	// Reply with a notice during maintenance and quiet hours
+	if notice := c.unavailableNotice(profile.userId); notice != "" {
+		c.logger.DebugKV("Bot unavailable, sending notice", "user_id", profile.userId, "channel_id", channelID)
+		c.sendMessage(channelID, threadTS, notice)
+		return
+	}
+
+	// Wait for a worker, telling the user when all of them are busy
+	release, ok := c.acquireWorker(channelID, threadTS)
+	if !ok {
+		return
+	}
+	defer release()
+
+	traceMetadata := map[string]string{
 		"session_id":   fmt.Sprintf("%s-%s", channelID, threadTS),
+		"channel_id":   channelID,
 		"user_email":   profile.email,
 		"llm_provider": c.cfg.LLM.Provider,
 		"use_agent":    fmt.Sprintf("%t", c.cfg.LLM.UseAgent),
-	})
+		"prompt_ts":    timestamp,
+	}
+	if branchOf != "" {
+		// Retries share the thread's session, so both runs can be compared there
+		traceMetadata["branch_of"] = branchOf
+	}
+	assignment, inExperiment := c.experiments.Assign(channelID, threadTS)
+	if inExperiment {
+		traceMetadata["experiment"] = assignment.Experiment
+		traceMetadata["experiment_arm"] = assignment.Arm
+	}
+	ctx, span := c.tracingHandler.StartTrace(context.Background(), "slack-user-interaction", userPrompt, traceMetadata)
 	defer span.End()
+	if inExperiment {
+		ctx = experiments.WithAssignment(ctx, assignment)
+	}
+
+	// Make the requesting user available to MCP servers that opt in to identity propagation
+	ctx = mcp.ContextWithIdentity(ctx, mcp.Identity{UserID: profile.userId, Email: profile.email})
+	ctx = mcp.ContextWithVars(ctx, requestVars(channelID, threadTS, timestamp, profile))
+	ctx = hooks.ContextWithConversation(ctx, channelID, threadTS)
+
+	// Check the prompt against the content policy before it reaches the LLM
+	if _, allowed := c.moderate(ctx, moderationInput, userPrompt, channelID, threadTS, profile.userId); !allowed {
+		c.sendMessage(channelID, threadTS, c.cfg.Moderation.BlockMessage)
+		return
+	}
+
+	// Let the message hooks deny or rewrite the prompt
+	userPrompt, allowed := c.runMessageHooks(ctx, userPrompt, channelID, threadTS)
+	if !allowed {
+		return
+	}
+
+	// Offer the LLM only the tools relevant to this message when tool selection is enabled
+	ctx = c.llmMCPBridge.SelectTools(ctx, userPrompt, channelID)
+
+	// Offer no tools for plain chat, and only the knowledge base for its questions, when the intent gate is enabled
+	ctx = c.llmMCPBridge.ClassifyIntent(ctx, userPrompt, channelID)
+
+	// Answer trivial prompts with the cheap model when model routing is enabled
+	ctx = c.llmMCPBridge.RouteRequest(ctx, userPrompt, channelID)
+
+	// Give the LLM what is remembered about the user and the team, and learn from the prompt
+	ctx = c.llmMCPBridge.RecallMemory(ctx, userPrompt, profile.userId, channelID)
+	if branchOf == "" {
+		go c.rememberFacts(userPrompt, channelID, threadTS, timestamp, profile)
+	}
+
+	// Confine knowledge base searches to the channel's namespace; only admins may name another one
+	if c.cfg.RAG.Enabled && len(c.cfg.RAG.Namespaces) > 0 {
+		ctx = rag.WithNamespaceScope(ctx, c.cfg.RAG.NamespaceForChannel(channelID), c.cfg.IsAdminUser(profile.userId, c.security))
+	}
+
+	// Track knowledge base sources so the reply can cite them
+	if c.cfg.RAG.Enabled && c.cfg.RAG.Citations {
+		ctx, _ = rag.WithCitationCollector(ctx)
+	}
+
+	// Check the knowledge base sources used for the reply for staleness
+	if staleAfter := c.cfg.RAG.Freshness.GetStaleAfter(); c.cfg.RAG.Enabled && staleAfter > 0 {
+		ctx, _ = rag.WithStaleSources(ctx, staleAfter)
+	}
 
 	// Fetch thread replies from slack
 	replies, err := c.userFrontend.GetThreadReplies(channelID, threadTS)
@@ -421,7 +1120,7 @@ func (c *Client) handleUserPrompt(userPrompt, channelID, threadTS string, timest
 	} else {
 		c.logger.DebugKV("Fetched thread replies", "channel", channelID, "thread_ts", threadTS, "count", len(replies))
 		existingMessages := make(map[string]bool)
-		history := c.messageHistory[historyKey(channelID, threadTS)]
+		history := c.threadHistory(channelID, threadTS)
 		for _, msg := range history {
 			// key := fmt.Sprintf("%s:%s", msg.UserID, msg.Content)
 			existingMessages[msg.SlackTimestamp] = true
@@ -445,17 +1144,50 @@ func (c *Client) handleUserPrompt(userPrompt, channelID, threadTS string, timest
 	}
 
 	// Get context from history
-	contextHistory := c.getContextFromHistory(channelID, threadTS)
+	history := c.threadHistory(channelID, threadTS)
+	if branchOf != "" {
+		history = c.historyBefore(channelID, threadTS, branchOf)
+	}
+	ctx, contextHistory := c.historyContext(ctx, channelID, history)
+
+	// Build on the agent's earlier work in the thread; a retry sees the work before the prompt it retries
+	if branchOf != "" {
+		contextHistory += c.scratchpadContext(channelID, threadTS, branchOf)
+	} else {
+		contextHistory += c.scratchpadContext(channelID, threadTS, timestamp)
+	}
+
+	// In incident channels, answer from the incident timeline and the channel's messages
+	ctx, incidentBackground := c.incidentContext(ctx, channelID, userPrompt)
+	contextHistory = incidentBackground + contextHistory
 
 	c.addToHistory(channelID, threadTS, timestamp, "user", userPrompt, profile.userId, profile.realName, profile.email) // Add user message to history
 
-	// Show a temporary "typing" indicator
-	c.userFrontend.SendMessage(channelID, threadTS, c.cfg.Slack.ThinkingMessage)
+	// Show a temporary "typing" indicator, or the thread status in the assistant container
+	c.titleAssistantThread(channelID, threadTS, userPrompt)
+	ctx = c.showThinking(ctx, channelID, threadTS)
+	defer c.clearProgress(ctx)
+
+	// Let the user stop the request with "stop" or a 🛑 reaction
+	ctx, done := c.trackRequest(ctx, channelID, threadTS, timestamp, profile.userId)
+	defer done()
+	if c.cfg.RAG.Enabled {
+		ctx = c.withIngestProgress(ctx, channelID, threadTS)
+	}
+	ctx, answered := c.objectives.StartAnswer(ctx, received)
+	defer answered()
+	ctx = c.withToolNotice(ctx, channelID, threadTS, timestamp)
+	defer c.offerAnswerActions(ctx, channelID, threadTS, timestamp)
+
+	// Answer knowledge base questions directly when RAG-first mode is enabled
+	if c.answerFromKnowledgeBase(ctx, userPrompt, contextHistory, channelID, threadTS, profile.userId) {
+		return
+	}
 
 	if !c.cfg.LLM.UseAgent {
 		// Prepare the final prompt with custom prompt as system instruction
 		var finalPrompt string
-		customPrompt := c.cfg.LLM.CustomPrompt
+		customPrompt := c.customPrompt(ctx)
 		if customPrompt != "" {
 			// Use custom prompt as system instruction, then add user prompt
 			finalPrompt = fmt.Sprintf("System instructions: %s\n\nUser: %s", customPrompt, userPrompt)
@@ -472,7 +1204,7 @@ func (c *Client) handleUserPrompt(userPrompt, channelID, threadTS string, timest
 		startTime := time.Now()
 
 		// Call LLM using the integrated logic with system instruction
-		llmResponse, err := c.llmMCPBridge.CallLLM(finalPrompt, contextHistory)
+		llmResponse, err := c.llmMCPBridge.CallLLM(llmCtx, finalPrompt, contextHistory)
 
 		duration := time.Since(startTime)
 
@@ -481,9 +1213,10 @@ func (c *Client) handleUserPrompt(userPrompt, channelID, threadTS string, timest
 
 		if err != nil {
 			c.logger.ErrorKV("Error from LLM provider", "provider", c.cfg.LLM.Provider, "error", err)
-			c.userFrontend.SendMessage(channelID, threadTS, fmt.Sprintf("Sorry, I encountered an error with the LLM provider ('%s'): %v", c.cfg.LLM.Provider, err))
+			c.reply(ctx, channelID, threadTS, c.presentError(llmCtx, err))
 			c.tracingHandler.RecordError(llmSpan, err, "ERROR")
 			llmSpan.End()
+			c.recordPromptError(ctx)
 			return
 		}
 
@@ -507,13 +1240,14 @@ func (c *Client) handleUserPrompt(userPrompt, channelID, threadTS string, timest
 		llmSpan.End()
 
 		// Process the LLM response through the MCP pipeline
-		c.processLLMResponseAndReply(llmCtx, llmResponse, userPrompt, channelID, threadTS)
+		c.processLLMResponseAndReply(llmCtx, llmResponse, userPrompt, channelID, threadTS, profile.userId)
 	} else {
 		// Agent path with enhanced tracing
 		agentCtx, agentSpan := c.tracingHandler.StartSpan(ctx, "llm-agent-call", "generation", userPrompt, map[string]string{
 			"provider": c.cfg.LLM.Provider,
 			"is_agent": "true",
 		})
+		steps := c.newAgentSteps(channelID)
 		sendMsg := func(msg string) {
 			// Trace each messages sent by the agent
 			_, msgSpan := c.tracingHandler.StartSpan(agentCtx, "agent-message-send", "event", msg, map[string]string{
@@ -523,32 +1257,47 @@ func (c *Client) handleUserPrompt(userPrompt, channelID, threadTS string, timest
 				"message_length": fmt.Sprintf("%d", len(msg)),
 			})
 
+			msg = c.filterAgentStep(agentCtx, steps, channelID, threadTS, profile.userId, msg)
+
 			c.addToHistory(channelID, threadTS, "", "assistant", msg, "", "", "") // Original LLM response (tool call JSON)
-			c.userFrontend.SendMessage(channelID, threadTS, msg)
+			c.sendAgentStep(agentCtx, steps, channelID, threadTS, msg)
 			c.tracingHandler.RecordSuccess(msgSpan, "Agent message sent successfully")
 			msgSpan.End()
 		}
 
+		var handler callbacks.Handler = &agentCallbackHandler{
+			callbacks.SimpleHandler{},
+			sendMsg,
+		}
+		recorder := c.newScratchpadRecorder()
+		if recorder != nil {
+			handler = callbacks.CombiningHandler{Callbacks: []callbacks.Handler{handler, recorder}}
+		}
+		toolOutputs := c.newToolOutputRecorder()
+		if toolOutputs != nil {
+			handler = callbacks.CombiningHandler{Callbacks: []callbacks.Handler{handler, toolOutputs}}
+		}
+
 		startTime := time.Now()
 		llmResponse, err := c.llmMCPBridge.CallLLMAgent(
+			agentCtx,
 			profile.realName,
-			c.cfg.LLM.CustomPrompt,
+			c.customPrompt(agentCtx),
 			userPrompt,
 			contextHistory,
-			&agentCallbackHandler{
-				callbacks.SimpleHandler{},
-				sendMsg,
-			})
+			handler)
 		duration := time.Since(startTime)
+		c.saveScratchpad(recorder, channelID, threadTS, timestamp, profile.userId, userPrompt, llmResponse)
 
 		// Set duration
 		c.tracingHandler.SetDuration(agentSpan, duration)
 
 		if err != nil {
 			c.logger.ErrorKV("Error from LLM provider", "provider", c.cfg.LLM.Provider, "error", err)
-			c.userFrontend.SendMessage(channelID, threadTS, fmt.Sprintf("Sorry, I encountered an error with the LLM provider ('%s'): %v", c.cfg.LLM.Provider, err))
+			c.reply(ctx, channelID, threadTS, c.presentError(agentCtx, err))
 			c.tracingHandler.RecordError(agentSpan, err, "ERROR")
 			agentSpan.End()
+			c.recordPromptError(ctx)
 			return
 		}
 		c.logger.InfoKV("Received response from LLM", "provider", c.cfg.LLM.Provider, "length", len(llmResponse))
@@ -558,10 +1307,24 @@ func (c *Client) handleUserPrompt(userPrompt, channelID, threadTS string, timest
 
 		// Send the final response back to Slack
 		if llmResponse == "" {
-			c.userFrontend.SendMessage(channelID, threadTS, "(LLM returned an empty response)")
+			c.reply(agentCtx, channelID, threadTS, "(LLM returned an empty response)")
 			c.tracingHandler.RecordError(agentSpan, fmt.Errorf("LLM returned an empty response"), "ERROR")
 
 		} else {
+			// Post the answer when the steps were not kept. Otherwise the agent's messages
+			// were already sent as they were produced, so sources follow as a separate message,
+			// together with any text the post-processors append.
+			// The answer is checked against the tool results of the run. It cannot be
+			// regenerated without running the agent again, so it only gets the warning.
+			answer := c.groundAnswer(agentCtx, groundingSourceTool, userPrompt, toolOutputs.sources(), llmResponse, nil)
+			c.finishAgentSteps(agentCtx, steps, channelID, threadTS, profile.userId, answer)
+			if steps.retention == config.IntermediateKeep && answer != llmResponse {
+				// A kept answer was already sent as the last step, so the warning follows it
+				c.reply(agentCtx, channelID, threadTS, strings.TrimSpace(strings.TrimPrefix(answer, llmResponse)))
+			}
+			if footer := c.postProcess(channelID, citationFooter(agentCtx, llmResponse), true); footer != "" {
+				c.reply(agentCtx, channelID, threadTS, footer)
+			}
 			c.tracingHandler.RecordSuccess(agentSpan, "LLM agent call succeeded")
 		}
 		agentSpan.End()
@@ -606,6 +1369,8 @@ func (c *Client) extractToolNameFromResponse(response string) string {
 	}
 
 	// Fallback: look for tool names in the response text
+	c.mcpMu.RLock()
+	defer c.mcpMu.RUnlock()
 	for toolName := range c.discoveredTools {
 		if strings.Contains(response, toolName) {
 			return toolName
@@ -648,9 +1413,27 @@ func (c *Client) estimateToolTokenUsage(toolName, prompt, response string) int {
 	}
 }
 
+// requestVars returns the context variables of a request, which tool argument
+// defaults reference
+func requestVars(channelID, threadTS, messageTS string, profile *UserProfile) map[string]string {
+	vars := map[string]string{
+		config.ContextVarChannelID: channelID,
+		config.ContextVarThreadTS:  threadTS,
+		config.ContextVarMessageTS: messageTS,
+	}
+	if profile != nil {
+		vars[config.ContextVarUserID] = profile.userId
+		vars[config.ContextVarUserEmail] = profile.email
+		if profile.realName != "Unknown" {
+			vars[config.ContextVarUserName] = profile.realName
+		}
+	}
+	return vars
+}
+
 // processLLMResponseAndReply processes the LLM response, handles tool results with re-prompting, and sends the final reply.
 // Incorporates logic previously in LLMClient.ProcessToolResponse.
-func (c *Client) processLLMResponseAndReply(traceCtx context.Context, llmResponse *llms.ContentChoice, userPrompt, channelID, threadTS string) {
+func (c *Client) processLLMResponseAndReply(traceCtx context.Context, llmResponse *llms.ContentChoice, userPrompt, channelID, threadTS, userID string) {
 	// Start tool processing span
 	ctx, span := c.tracingHandler.StartSpan(traceCtx, "tool-processing", "span", userPrompt, map[string]string{
 		"channel_id":      channelID,
@@ -667,10 +1450,6 @@ func (c *Client) processLLMResponseAndReply(traceCtx context.Context, llmRespons
 	}
 	c.logger.DebugKV("Added extra arguments", "channel_id", channelID, "thread_ts", threadTS)
 
-	// Create a context with timeout for tool processing
-	toolCtx, cancel := context.WithTimeout(ctx, 1*time.Minute)
-	defer cancel()
-
 	// --- Process Tool Response (Logic from LLMClient.ProcessToolResponse) ---
 	var finalResponse string
 	var isToolResult bool
@@ -692,13 +1471,14 @@ func (c *Client) processLLMResponseAndReply(traceCtx context.Context, llmRespons
 			"response_type":    "processing",
 			"tool_name":        executedToolName,
 		})
+		c.showToolStatus(ctx, channelID, threadTS, llmResponse)
 		startTime := time.Now()
 		// Process the response through the bridge
-		processedResponse, err := c.llmMCPBridge.ProcessLLMResponse(toolCtx, llmResponse, userPrompt, extraArgs)
+		processedResponse, err := c.llmMCPBridge.ProcessLLMResponse(ctx, llmResponse, userPrompt, extraArgs)
 		toolDuration := time.Since(startTime)
 		c.tracingHandler.SetDuration(toolExecSpan, toolDuration)
 		if err != nil {
-			finalResponse = fmt.Sprintf("Sorry, I encountered an error while trying to use a tool: %v", err)
+			finalResponse = c.presentError(ctx, err)
 			isToolResult = false
 			toolProcessingErr = err // Store the error
 			c.tracingHandler.RecordError(toolExecSpan, err, "ERROR")
@@ -724,13 +1504,15 @@ func (c *Client) processLLMResponseAndReply(traceCtx context.Context, llmRespons
 	if toolProcessingErr != nil {
 		c.tracingHandler.RecordError(span, toolProcessingErr, "ERROR")
 		c.logger.ErrorKV("Tool processing error", "error", toolProcessingErr)
-		c.userFrontend.SendMessage(channelID, threadTS, finalResponse) // Post the error message
+		c.reply(ctx, channelID, threadTS, finalResponse) // Post the error message
+		c.recordPromptError(ctx)
 		return
 	}
 
 	if isToolResult {
 		c.logger.Debug("Tool executed. Re-prompting LLM with tool result.")
 		c.logger.DebugKV("Tool result", "result", logging.TruncateForLog(finalResponse, 500))
+		toolResult := finalResponse
 
 		// Always re-prompt LLM with tool results for synthesis
 		// Construct a new prompt incorporating the original prompt and the tool result
@@ -747,17 +1529,30 @@ func (c *Client) processLLMResponseAndReply(traceCtx context.Context, llmRespons
 				"tool_estimated_tokens": c.estimateToolTokenUsage(executedToolName, userPrompt, finalResponse), // Add this
 			})
 
-		// Add history
-		c.addToHistory(channelID, threadTS, "", "assistant", llmResponse.Content, "", "", "") // Original LLM response (tool call JSON)
-		c.addToHistory(channelID, threadTS, "", "tool", finalResponse, "", "", "")            // Tool execution result
+		// Add history: the tool call and its result as a structured entry. A native
+		// tool call has no text, which the entry describes instead.
+		if strings.TrimSpace(llmResponse.Content) != "" {
+			c.addToHistory(channelID, threadTS, "", "assistant", llmResponse.Content, "", "", "") // Original LLM response (tool call JSON)
+		}
+		toolName, toolArgs := executedToolName, map[string]interface{}(nil)
+		if toolCall := c.llmMCPBridge.DetectToolCall(llmResponse); toolCall != nil {
+			toolName, toolArgs = toolCall.Tool, toolCall.Args
+		}
+		// A repeated call is diffed against the previous result, before it joins the history
+		diff := c.diffToolResult(channelID, threadTS, toolName, toolArgs, finalResponse)
+		if diff != nil {
+			rePrompt += diff.prompt()
+		}
+		c.addToolResult(channelID, threadTS, toolName, toolArgs, finalResponse)
 
 		c.logger.DebugKV("Re-prompting LLM", "prompt", rePrompt)
+		c.showStatus(ctx, channelID, threadTS, c.cfg.Slack.Assistant.ThinkingStatus, progressWritingStatus)
 
 		// Re-prompt using the LLM client with custom prompt as system instruction
 		var repromptErr error
 		// Prepare the re-prompt with custom prompt as system instruction
 		var finalRePrompt string
-		customPrompt := c.cfg.LLM.CustomPrompt
+		customPrompt := c.customPrompt(ctx)
 
 		if customPrompt != "" {
 			// Use custom prompt as system instruction for re-prompt too
@@ -767,7 +1562,8 @@ func (c *Client) processLLMResponseAndReply(traceCtx context.Context, llmRespons
 		}
 		startTime := time.Now()
 
-		finalResStruct, repromptErr := c.llmMCPBridge.CallLLM(finalRePrompt, c.getContextFromHistory(channelID, threadTS))
+		repromptCtx, repromptHistory := c.historyContext(ctx, channelID, c.threadHistory(channelID, threadTS))
+		finalResStruct, repromptErr := c.llmMCPBridge.CallLLM(repromptCtx, finalRePrompt, repromptHistory)
 
 		duration := time.Since(startTime)
 		// Set duration
@@ -777,11 +1573,21 @@ func (c *Client) processLLMResponseAndReply(traceCtx context.Context, llmRespons
 			c.tracingHandler.RecordError(repromptSpan, repromptErr, "ERROR")
 			c.logger.ErrorKV("Error during LLM re-prompt", "error", repromptErr)
 			// Fallback: Show the tool result and the error
-			finalResponse = fmt.Sprintf("Tool Result:\n```%s```\n\n(Error generating final response: %v)", finalResponse, repromptErr)
+			finalResponse = fmt.Sprintf("Tool Result:\n```%s```\n\n%s", finalResponse, c.presentError(ctx, repromptErr))
 			c.tracingHandler.RecordError(span, repromptErr, "ERROR")
+			c.recordPromptError(ctx)
 		} else {
 			c.logger.DebugKV("LLM re-prompt successful", "response", logging.TruncateForLog(fmt.Sprintf("%v", finalResStruct), 500))
-			finalResponse = finalResStruct.Content
+			finalResponse = c.groundAnswer(ctx, groundingSourceTool, userPrompt, toolResult, finalResStruct.Content, func(feedback string) (string, error) {
+				regenerated, err := c.llmMCPBridge.CallLLM(repromptCtx, finalRePrompt+"\n\n"+feedback, repromptHistory)
+				if err != nil {
+					return "", err
+				}
+				return regenerated.Content, nil
+			})
+			if diff != nil && strings.TrimSpace(finalResponse) != "" {
+				finalResponse += diff.attachment(c.cfg.Slack.ToolHistory.MaxDiffLines)
+			}
 			repromptUsageDetails := map[string]int{
 				"prompt_tokens":     getIntFromMap(finalResStruct.GenerationInfo, "PromptTokens"),
 				"completion_tokens": getIntFromMap(finalResStruct.GenerationInfo, "CompletionTokens"),
@@ -814,11 +1620,14 @@ func (c *Client) processLLMResponseAndReply(traceCtx context.Context, llmRespons
 	})
 	// Send the final response back to Slack
 	if finalResponse == "" {
-		c.userFrontend.SendMessage(channelID, threadTS, "(LLM returned an empty response)")
+		c.reply(ctx, channelID, threadTS, "(LLM returned an empty response)")
 		c.tracingHandler.RecordError(msgSpan, fmt.Errorf("LLM returned an empty response"), "ERROR")
 
 	} else {
-		c.userFrontend.SendMessage(channelID, threadTS, finalResponse)
+		finalResponse, _ = c.moderate(ctx, moderationOutput, finalResponse, channelID, threadTS, userID)
+		finalResponse = c.runResponseHooks(ctx, finalResponse)
+		finalResponse = c.postProcess(channelID, withCitations(ctx, finalResponse), true)
+		c.reply(ctx, channelID, threadTS, finalResponse)
 		c.tracingHandler.RecordSuccess(msgSpan, "Slack message sent successfully")
 	}
 	msgSpan.End()
@@ -0,0 +1,44 @@
+package slackbot
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/wasmtools"
+)
+
+func TestWASMToolsWorkAfterRun(t *testing.T) {
+	if testing.Short() {
+		t.Skip("compiles a WASM module")
+	}
+	path := filepath.Join(t.TempDir(), "upper.wasm")
+	cmd := exec.Command("go", "build", "-o", path, "../wasmtools/testdata/upper")
+	cmd.Env = append(os.Environ(), "GOOS=wasip1", "GOARCH=wasm")
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	client, _, _ := newJobsTestClient(t, backupTool{})
+	client.userFrontend.(*progressRecorder).Input = strings.NewReader("")
+	client.wasmTools, err = wasmtools.NewClient(context.Background(), map[string]config.WASMToolConfig{
+		"upper": {Path: path, Timeout: "2s", MaxMemoryMB: 64},
+	}, client.logger)
+	require.NoError(t, err)
+
+	// Run returns once the frontend stops; the tools stay usable until Close
+	require.NoError(t, client.Run())
+	result, err := client.wasmTools.CallTool(context.Background(), "upper", map[string]interface{}{"text": "hello"})
+	require.NoError(t, err)
+	assert.Equal(t, "HELLO", result)
+
+	require.NoError(t, client.Close())
+	_, err = client.wasmTools.CallTool(context.Background(), "upper", map[string]interface{}{"text": "hello"})
+	assert.Error(t, err)
+}
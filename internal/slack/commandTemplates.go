@@ -0,0 +1,135 @@
+package slackbot
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/slack-go/slack"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/hooks"
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+	"github.com/tuannvm/slack-mcp-client/internal/monitoring"
+)
+
+// maxCommandResultChars limits the tool result shown in a command template's reply
+const maxCommandResultChars = 3000
+
+// runCommandTemplate calls the tool of a command template, such as
+// "/mcp deploy-status api", as the user who ran it, and returns the reply. The
+// LLM is not involved: the tool's arguments come from the template.
+func (c *Client) runCommandTemplate(command slack.SlashCommand, name string, template config.SlackCommandTemplate, args []string) string {
+	usage := commandTemplateUsage(c.cfg.Slack.SlashCommand, name, template)
+	if len(args) < len(template.Params) {
+		return "Usage: " + usage
+	}
+	if result := c.cfg.ValidateAccessWithDirectory(command.UserID, command.ChannelID, c.security); !result.Allowed {
+		c.logger.WarnKV("Denied command template", "command", name, "user", command.UserID, "channel", command.ChannelID, "reason", result.Reason)
+		monitoring.CommandTemplateCalls.WithLabelValues(name, "denied").Inc()
+		if c.cfg.Security.RejectionMessage != "" {
+			return c.cfg.Security.RejectionMessage
+		}
+		return "You are not allowed to use this command here."
+	}
+	if c.llmMCPBridge == nil {
+		return "Tools are not available."
+	}
+
+	ctx := context.Background()
+	profile, err := c.userFrontend.GetUserInfo(command.UserID)
+	if err != nil {
+		c.logger.WarnKV("Failed to get user info", "user", command.UserID, "error", err)
+		profile = &UserProfile{userId: command.UserID}
+	}
+	ctx = mcp.ContextWithIdentity(ctx, mcp.Identity{UserID: command.UserID, Email: profile.email})
+	ctx = mcp.ContextWithVars(ctx, requestVars(command.ChannelID, "", "", profile))
+	ctx = hooks.ContextWithConversation(ctx, command.ChannelID, "")
+
+	// The params are variables of the template, next to the context variables
+	params := make(map[string]string, len(template.Params))
+	for i, param := range template.Params {
+		if i == len(template.Params)-1 {
+			params[param] = strings.Join(args[i:], " ")
+		} else {
+			params[param] = args[i]
+		}
+	}
+	toolArgs := mcp.ApplyArgDefaults(mcp.ContextWithVars(ctx, params), template.Args, nil, map[string]interface{}{})
+	if tool, ok := c.Tools()[template.Tool]; ok {
+		coerceCommandArgs(tool.InputSchema, toolArgs)
+	}
+
+	c.logger.InfoKV("Running command template", "command", name, "tool", template.Tool, "user", command.UserID, "channel", command.ChannelID)
+	result, err := c.llmMCPBridge.CallTool(ctx, template.Tool, toolArgs)
+	if err != nil {
+		c.logger.WarnKV("Command template failed", "command", name, "tool", template.Tool, "error", err)
+		monitoring.CommandTemplateCalls.WithLabelValues(name, "failed").Inc()
+		return c.presentError(ctx, err)
+	}
+	monitoring.CommandTemplateCalls.WithLabelValues(name, "completed").Inc()
+
+	if runes := []rune(result); len(runes) > maxCommandResultChars {
+		result = string(runes[:maxCommandResultChars]) + "\n… (truncated)"
+	}
+	invocation := strings.TrimSpace(c.cfg.Slack.SlashCommand + " " + name + " " + strings.Join(args, " "))
+	return fmt.Sprintf("*`%s`* (`%s`)\n```\n%s\n```", invocation, template.Tool, strings.TrimSpace(result))
+}
+
+// commandTemplateUsage shows how to run a command template
+func commandTemplateUsage(slashCommand, name string, template config.SlackCommandTemplate) string {
+	usage := slashCommand + " " + name
+	for _, param := range template.Params {
+		usage += " <" + param + ">"
+	}
+	return "`" + usage + "`"
+}
+
+// commandTemplatesHelp lists the command templates for the slash command's help
+func (c *Client) commandTemplatesHelp() string {
+	names := make([]string, 0, len(c.cfg.Slack.Commands))
+	for name := range c.cfg.Slack.Commands {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	var b strings.Builder
+	for _, name := range names {
+		template := c.cfg.Slack.Commands[name]
+		description := template.Description
+		if description == "" {
+			description = fmt.Sprintf("calls `%s`", template.Tool)
+		}
+		fmt.Fprintf(&b, "\n• %s: %s", commandTemplateUsage(c.cfg.Slack.SlashCommand, name, template), description)
+	}
+	return b.String()
+}
+
+// coerceCommandArgs converts the arguments rendered as text to the number or
+// boolean the tool's schema asks for. Values that do not parse are left as they
+// are, for the tool to reject.
+func coerceCommandArgs(schema map[string]interface{}, args map[string]interface{}) {
+	properties, _ := schema["properties"].(map[string]interface{})
+	for name, value := range args {
+		text, ok := value.(string)
+		if !ok {
+			continue
+		}
+		property, _ := properties[name].(map[string]interface{})
+		switch property["type"] {
+		case "integer":
+			if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+				args[name] = n
+			}
+		case "number":
+			if n, err := strconv.ParseFloat(text, 64); err == nil {
+				args[name] = n
+			}
+		case "boolean":
+			if b, err := strconv.ParseBool(text); err == nil {
+				args[name] = b
+			}
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package slackbot
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+func TestCommandTemplateCallsTool(t *testing.T) {
+	client, _, output := newJobsTestClient(t, backupTool{})
+	client.cfg.Slack.Commands = map[string]config.SlackCommandTemplate{
+		"backup-status": {Tool: "backup_check", Params: []string{"cluster"}, Args: map[string]interface{}{"cluster": "${cluster}"}, Description: "backup status of a cluster"},
+	}
+	run := func(text string) string {
+		output.Reset()
+		client.handleSlashCommand(slack.SlashCommand{Command: "/mcp", Text: text, ChannelID: "C1", UserID: "U1"})
+		return output.String()
+	}
+
+	assert.Contains(t, run("Backup-Status prod eu"), "*`/mcp backup-status prod eu`* (`backup_check`)\n```\nbackups of prod eu ok, checked for U1 in C1:\n```")
+	assert.Contains(t, run("backup-status"), "Usage: `/mcp backup-status <cluster>`")
+	assert.Contains(t, run("help"), "• `/mcp backup-status <cluster>`: backup status of a cluster")
+}
+
+func TestCommandTemplateFailure(t *testing.T) {
+	client, _, output := newJobsTestClient(t, backupTool{err: errors.New("cluster unreachable")})
+	client.cfg.Slack.Commands = map[string]config.SlackCommandTemplate{
+		"backup-status": {Tool: "backup_check", Args: map[string]interface{}{"cluster": "prod"}},
+	}
+	client.handleSlashCommand(slack.SlashCommand{Command: "/mcp", Text: "backup-status", ChannelID: "C1", UserID: "U1"})
+	assert.Contains(t, output.String(), "Sorry, the `backup_check` tool failed.")
+	assert.NotContains(t, output.String(), "cluster unreachable")
+}
+
+func TestCoerceCommandArgs(t *testing.T) {
+	schema := map[string]interface{}{"properties": map[string]interface{}{
+		"limit":   map[string]interface{}{"type": "integer"},
+		"ratio":   map[string]interface{}{"type": "number"},
+		"verbose": map[string]interface{}{"type": "boolean"},
+		"name":    map[string]interface{}{"type": "string"},
+	}}
+	args := map[string]interface{}{"limit": "20", "ratio": "0.5", "verbose": "true", "name": "42", "since": "1h"}
+	coerceCommandArgs(schema, args)
+	assert.Equal(t, map[string]interface{}{"limit": int64(20), "ratio": 0.5, "verbose": true, "name": "42", "since": "1h"}, args)
+
+	args = map[string]interface{}{"limit": "many"}
+	coerceCommandArgs(schema, args)
+	assert.Equal(t, "many", args["limit"], "Values that do not parse are left for the tool to reject")
+}
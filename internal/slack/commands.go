@@ -0,0 +1,68 @@
+package slackbot
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+// CommandFrontend is implemented by frontends that can answer a slash command
+// with a message only the user who ran it sees
+type CommandFrontend interface {
+	RespondToCommand(channelID, responseURL, text string) error
+}
+
+// RespondToCommand posts an ephemeral reply to the command's response URL, which
+// works in conversations the bot is not a member of
+func (slackClient *SlackClient) RespondToCommand(channelID, responseURL, text string) error {
+	_, _, err := slackClient.PostMessage(channelID,
+		slack.MsgOptionText(text, false),
+		slack.MsgOptionResponseURL(responseURL, slack.ResponseTypeEphemeral))
+	return err
+}
+
+// handleSlashCommand runs a subcommand of the app's slash command, such as
+// "/mcp memory list", and replies to the user who ran it
+func (c *Client) handleSlashCommand(command slack.SlashCommand) {
+	if command.Command != c.cfg.Slack.SlashCommand {
+		c.logger.DebugKV("Ignored unknown slash command", "command", command.Command)
+		return
+	}
+	c.logger.InfoKV("Received slash command", "command", command.Command, "text", command.Text, "channel", command.ChannelID, "user", command.UserID)
+
+	args := strings.Fields(command.Text)
+	var reply string
+	switch {
+	case len(args) > 0 && strings.EqualFold(args[0], "memory"):
+		reply = c.memoryCommand(args[1:], command.UserID, command.ChannelID)
+	case len(args) > 0 && strings.EqualFold(args[0], "jobs"):
+		reply = c.jobsCommand(args[1:], command.UserID)
+	case len(args) > 0 && c.cfg.Slack.Commands[strings.ToLower(args[0])].Tool != "":
+		name := strings.ToLower(args[0])
+		reply = c.runCommandTemplate(command, name, c.cfg.Slack.Commands[name], args[1:])
+	default:
+		reply = c.slashCommandHelp()
+	}
+	c.respondToCommand(command, reply)
+}
+
+// respondToCommand replies to the user who ran a slash command
+func (c *Client) respondToCommand(command slack.SlashCommand, text string) {
+	frontend, ok := c.userFrontend.(CommandFrontend)
+	if !ok || command.ResponseURL == "" {
+		c.sendMessage(command.ChannelID, "", text)
+		return
+	}
+	text = c.postProcess(command.ChannelID, text, true)
+	if err := frontend.RespondToCommand(command.ChannelID, command.ResponseURL, text); err != nil {
+		c.logger.WarnKV("Failed to respond to slash command", "command", command.Command, "user", command.UserID, "error", err)
+	}
+}
+
+// slashCommandHelp lists the subcommands of the slash command
+func (c *Client) slashCommandHelp() string {
+	name := c.cfg.Slack.SlashCommand
+	return fmt.Sprintf("Usage:\n• `%[1]s memory list`: facts I remember about you and this channel's team\n• `%[1]s memory delete <id>`: forget a fact"+
+		"\n• `%[1]s jobs`: tool calls scheduled to run later\n• `%[1]s jobs cancel <id>`: cancel a scheduled tool call", name) + c.commandTemplatesHelp()
+}
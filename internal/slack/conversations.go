@@ -0,0 +1,153 @@
+package slackbot
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+// conversationRetryAfter is how long a conversation type guessed after a failed
+// lookup is used before looking it up again
+const conversationRetryAfter = time.Minute
+
+// ConversationFrontend is implemented by frontends that can look up conversations
+type ConversationFrontend interface {
+	GetConversationInfo(input *slack.GetConversationInfoInput) (*slack.Channel, error)
+}
+
+// conversationTypes resolves whether a conversation is a DM, a group DM, or a
+// private or public channel through conversations.info, caching the result.
+// Channel ID prefixes alone are ambiguous: G is used for both private channels
+// and group DMs, and channels converted to private keep their C prefix.
+type conversationTypes struct {
+	api    ConversationFrontend
+	ttl    time.Duration
+	logger *logging.Logger
+	now    func() time.Time
+
+	mu    sync.Mutex
+	types map[string]cachedLookup[string] // Channel ID -> conversation type
+}
+
+// newConversationTypes creates a resolver that caches lookups for ttl
+func newConversationTypes(api ConversationFrontend, ttl time.Duration, logger *logging.Logger) *conversationTypes {
+	return &conversationTypes{
+		api:    api,
+		ttl:    ttl,
+		logger: logger,
+		now:    time.Now,
+		types:  make(map[string]cachedLookup[string]),
+	}
+}
+
+// Type returns the conversation type of a channel. When the lookup fails, for
+// example for lack of the *:read scopes, the type is guessed from the channel ID.
+func (r *conversationTypes) Type(channelID string) string {
+	r.mu.Lock()
+	cached, exists := r.types[channelID]
+	r.mu.Unlock()
+	if exists && !r.now().After(cached.expires) {
+		return cached.value
+	}
+
+	conversationType, ttl := "", r.ttl
+	channel, err := r.api.GetConversationInfo(&slack.GetConversationInfoInput{ChannelID: channelID})
+	if err != nil {
+		r.logger.WarnKV("Failed to look up Slack conversation type, guessing from its ID", "channel", channelID, "error", err)
+		conversationType, ttl = conversationTypeFromID(channelID), conversationRetryAfter
+	} else {
+		conversationType = conversationTypeOf(channel)
+	}
+	r.mu.Lock()
+	r.types[channelID] = cachedLookup[string]{value: conversationType, expires: r.now().Add(ttl)}
+	r.mu.Unlock()
+	return conversationType
+}
+
+// conversationTypeOf returns the type of a conversation returned by the Slack API
+func conversationTypeOf(channel *slack.Channel) string {
+	switch {
+	case channel.IsIM:
+		return config.ConversationIM
+	case channel.IsMpIM:
+		return config.ConversationMPIM
+	case channel.IsPrivate || channel.IsGroup:
+		return config.ConversationPrivate
+	default:
+		return config.ConversationPublic
+	}
+}
+
+// conversationTypeFromEvent maps the channel_type of a message event, which is
+// empty for other events
+func conversationTypeFromEvent(channelType string) string {
+	switch channelType {
+	case "im":
+		return config.ConversationIM
+	case "mpim":
+		return config.ConversationMPIM
+	case "group":
+		return config.ConversationPrivate
+	case "channel":
+		return config.ConversationPublic
+	default:
+		return ""
+	}
+}
+
+// conversationTypeFromID guesses a conversation type from the channel ID prefix
+func conversationTypeFromID(channelID string) string {
+	switch {
+	case strings.HasPrefix(channelID, "D"):
+		return config.ConversationIM
+	case strings.HasPrefix(channelID, "G"):
+		return config.ConversationPrivate
+	default:
+		return config.ConversationPublic
+	}
+}
+
+// conversationType returns the type of the conversation a message was sent in.
+// channelType is the channel_type of message events, when known.
+func (c *Client) conversationType(channelID, channelType string) string {
+	if conversationType := conversationTypeFromEvent(channelType); conversationType != "" {
+		return conversationType
+	}
+	if c.conversations != nil {
+		return c.conversations.Type(channelID)
+	}
+	return conversationTypeFromID(channelID)
+}
+
+// repliesTo reports whether the reply mode of a conversation type covers a
+// message, depending on whether it mentions the bot
+func (c *Client) repliesTo(conversationType string, mentioned bool) bool {
+	switch c.cfg.Slack.Conversations.ReplyMode(conversationType) {
+	case config.ConversationReplyAll:
+		return true
+	case config.ConversationReplyMentions:
+		return mentioned
+	default:
+		return false
+	}
+}
+
+// answersMessage reports whether a message event is answered. Mentions outside
+// DMs are not, because Slack also delivers them as app_mention events, which are
+// answered instead.
+func (c *Client) answersMessage(conversationType string, mentioned bool) bool {
+	if conversationType != config.ConversationIM && mentioned {
+		return false
+	}
+	return c.repliesTo(conversationType, mentioned)
+}
+
+// answersMention reports whether an app mention is answered in its conversation
+func (c *Client) answersMention(conversationType string) bool {
+	return c.repliesTo(conversationType, true)
+}
@@ -0,0 +1,76 @@
+package slackbot
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+// fakeConversations serves conversations.info from a map and counts lookups
+type fakeConversations struct {
+	channels map[string]*slack.Channel
+	lookups  int
+}
+
+func (f *fakeConversations) GetConversationInfo(input *slack.GetConversationInfoInput) (*slack.Channel, error) {
+	f.lookups++
+	if channel, ok := f.channels[input.ChannelID]; ok {
+		return channel, nil
+	}
+	return nil, errors.New("missing_scope")
+}
+
+func TestConversationTypesResolveAndCache(t *testing.T) {
+	groupDM := &slack.Channel{}
+	groupDM.IsMpIM = true
+	private := &slack.Channel{}
+	private.IsPrivate = true
+	api := &fakeConversations{channels: map[string]*slack.Channel{"G1": groupDM, "C2": private}}
+	resolver := newConversationTypes(api, time.Hour, logging.New("test", logging.LevelError))
+	now := time.Now()
+	resolver.now = func() time.Time { return now }
+
+	// A G prefix may be a group DM, and a converted private channel keeps its C prefix
+	assert.Equal(t, config.ConversationMPIM, resolver.Type("G1"))
+	assert.Equal(t, config.ConversationPrivate, resolver.Type("C2"))
+	assert.Equal(t, config.ConversationMPIM, resolver.Type("G1"))
+	assert.Equal(t, 2, api.lookups)
+
+	// Failed lookups fall back to the ID prefix and are retried after a minute
+	assert.Equal(t, config.ConversationPrivate, resolver.Type("G3"))
+	assert.Equal(t, config.ConversationPrivate, resolver.Type("G3"))
+	assert.Equal(t, 3, api.lookups)
+	now = now.Add(conversationRetryAfter + time.Second)
+	resolver.Type("G3")
+	assert.Equal(t, 4, api.lookups)
+}
+
+func TestAnswersMessagePerConversationType(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Slack.Conversations.GroupMessages = config.ConversationReplyAll
+	cfg.Slack.Conversations.PrivateChannels = config.ConversationReplyOff
+	cfg.ApplyDefaults()
+	client := &Client{cfg: cfg}
+
+	// DMs answer every message by default
+	assert.True(t, client.answersMessage(config.ConversationIM, false))
+	assert.True(t, client.answersMessage(config.ConversationIM, true))
+	// Group DMs configured to answer everything, except mentions, which arrive as app_mention
+	assert.True(t, client.answersMessage(config.ConversationMPIM, false))
+	assert.False(t, client.answersMessage(config.ConversationMPIM, true))
+	assert.True(t, client.answersMention(config.ConversationMPIM))
+	// Public channels only answer mentions by default
+	assert.False(t, client.answersMessage(config.ConversationPublic, false))
+	assert.True(t, client.answersMention(config.ConversationPublic))
+	// Replies are off in private channels
+	assert.False(t, client.answersMention(config.ConversationPrivate))
+
+	assert.Equal(t, config.ConversationPrivate, client.conversationType("C1", "group"))
+	assert.Equal(t, config.ConversationIM, client.conversationType("D1", ""))
+}
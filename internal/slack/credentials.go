@@ -0,0 +1,70 @@
+package slackbot
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/tuannvm/slack-mcp-client/internal/credentials"
+)
+
+// handleCredentialCommand handles the DM commands users run to manage their linked
+// accounts: "link <server>", "unlink <server>" and "links". It reports whether the
+// message was a credential command.
+func (c *Client) handleCredentialCommand(text, channelID, threadTS, userID string) bool {
+	if c.credentials == nil {
+		return false
+	}
+
+	fields := strings.Fields(strings.TrimSpace(text))
+	if len(fields) == 0 {
+		return false
+	}
+	command := strings.ToLower(fields[0])
+
+	switch {
+	case command == "links" && len(fields) == 1:
+		linked, err := c.credentials.Linked(userID)
+		if err != nil {
+			c.logger.ErrorKV("Failed to list linked accounts", "user", userID, "error", err)
+			c.sendMessage(channelID, threadTS, "Sorry, I couldn't look up your linked accounts.")
+			return true
+		}
+		reply := fmt.Sprintf("Servers that use your own account: %s\n", strings.Join(c.credentials.Servers(), ", "))
+		if len(linked) == 0 {
+			reply += "You haven't linked any accounts yet. Send `link <server>` to connect one."
+		} else {
+			reply += fmt.Sprintf("Linked: %s", strings.Join(linked, ", "))
+		}
+		c.sendMessage(channelID, threadTS, reply)
+		return true
+
+	case command == "link" && len(fields) == 2:
+		authURL, err := c.credentials.AuthURL(userID, fields[1])
+		if err != nil {
+			c.sendMessage(channelID, threadTS,
+				fmt.Sprintf("`%s` doesn't use per-user accounts. Available: %s", fields[1], strings.Join(c.credentials.Servers(), ", ")))
+			return true
+		}
+		c.logger.InfoKV("Started account linking", "user", userID, "server", fields[1])
+		c.sendMessage(channelID, threadTS,
+			fmt.Sprintf("<%s|Click here to link your *%s* account>. The link expires in 10 minutes.", authURL, fields[1]))
+		return true
+
+	case command == "unlink" && len(fields) == 2:
+		err := c.credentials.Unlink(userID, fields[1])
+		switch {
+		case errors.Is(err, credentials.ErrNotLinked):
+			c.sendMessage(channelID, threadTS, fmt.Sprintf("You don't have a linked *%s* account.", fields[1]))
+		case err != nil:
+			c.logger.ErrorKV("Failed to unlink account", "user", userID, "server", fields[1], "error", err)
+			c.sendMessage(channelID, threadTS, "Sorry, I couldn't remove that account.")
+		default:
+			c.logger.InfoKV("Unlinked account", "user", userID, "server", fields[1])
+			c.sendMessage(channelID, threadTS, fmt.Sprintf("Your *%s* account has been unlinked.", fields[1]))
+		}
+		return true
+	}
+
+	return false
+}
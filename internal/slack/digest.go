@@ -0,0 +1,249 @@
+package slackbot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/llm"
+	"github.com/tuannvm/slack-mcp-client/internal/monitoring"
+	"github.com/tuannvm/slack-mcp-client/internal/schedule"
+)
+
+// defaultDigestPrompt instructs the LLM when a digest has no prompt of its own
+const defaultDigestPrompt = `You write a digest of a Slack channel for people who were away.
+Summarize the conversation below in a few short sections: key decisions, open questions and action items with their owners.
+Mention incidents and announcements first. Leave out small talk. Use Slack markdown and keep it under 300 words.`
+
+// digestPageSize is how many messages are requested per conversations.history call
+const digestPageSize = 200
+
+// DigestFrontend is implemented by frontends that can read channel history
+type DigestFrontend interface {
+	GetConversationHistory(params *slack.GetConversationHistoryParameters) (*slack.GetConversationHistoryResponse, error)
+}
+
+// CanvasFrontend is implemented by frontends that can publish canvases
+type CanvasFrontend interface {
+	CreateCanvas(title string, documentContent slack.DocumentContent) (string, error)
+	SetCanvasAccess(params slack.SetCanvasAccessParams) error
+	GetFileInfo(fileID string, count, page int) (*slack.File, []slack.Comment, *slack.Paging, error)
+}
+
+// digestJob is a configured digest and its parsed schedule
+type digestJob struct {
+	cfg      config.SlackDigestConfig
+	cron     *schedule.Cron
+	location *time.Location
+}
+
+// startDigests schedules the configured digests until stopDigests is called
+func (c *Client) startDigests() {
+	if len(c.cfg.Slack.Digests) == 0 {
+		return
+	}
+	if _, ok := c.userFrontend.(DigestFrontend); !ok {
+		c.logger.Warn("Channel digests are configured, but this frontend cannot read channel history")
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.digestCancel = cancel
+	for _, digestCfg := range c.cfg.Slack.Digests {
+		cron, err := schedule.ParseCron(digestCfg.Schedule)
+		if err != nil {
+			c.logger.ErrorKV("Invalid digest schedule", "channel", digestCfg.Channel, "error", err) // Validated when the config is loaded
+			continue
+		}
+		location, err := time.LoadLocation(digestCfg.Timezone)
+		if err != nil {
+			location = time.UTC
+		}
+		go c.runDigestSchedule(ctx, digestJob{cfg: digestCfg, cron: cron, location: location})
+	}
+}
+
+// stopDigests cancels the digest schedules
+func (c *Client) stopDigests() {
+	if c.digestCancel != nil {
+		c.digestCancel()
+	}
+}
+
+// runDigestSchedule posts a digest each time the schedule fires
+func (c *Client) runDigestSchedule(ctx context.Context, job digestJob) {
+	for {
+		next := job.cron.Next(time.Now().In(job.location))
+		if next.IsZero() {
+			c.logger.WarnKV("Digest schedule never fires", "channel", job.cfg.Channel, "schedule", job.cfg.Schedule)
+			return
+		}
+		c.logger.DebugKV("Next channel digest scheduled", "channel", job.cfg.Channel, "at", next)
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if !c.claimDigest(ctx, job.cfg, next) {
+			continue
+		}
+		if err := c.postDigest(ctx, job.cfg, next); err != nil {
+			c.logger.ErrorKV("Failed to post channel digest", "channel", job.cfg.Channel, "error", err)
+			monitoring.SlackDigests.WithLabelValues("error").Inc()
+		}
+	}
+}
+
+// claimDigest claims a scheduled run in the dedupe store, so replicas sharing
+// Redis post each digest once. The run goes ahead if the store is unreachable.
+func (c *Client) claimDigest(ctx context.Context, cfg config.SlackDigestConfig, at time.Time) bool {
+	if c.eventDeduper == nil {
+		return true
+	}
+	claimed, err := c.eventDeduper.Claim(ctx, fmt.Sprintf("digest:%s:%s:%d", cfg.Channel, cfg.PostTo, at.Unix()))
+	if err != nil {
+		c.logger.WarnKV("Failed to claim channel digest, posting anyway", "channel", cfg.Channel, "error", err)
+		return true
+	}
+	if !claimed {
+		c.logger.DebugKV("Channel digest already posted by another replica", "channel", cfg.Channel)
+	}
+	return claimed
+}
+
+// postDigest summarizes the channel's messages of the last hours before now and
+// posts the summary. Nothing is posted when the channel was quiet.
+func (c *Client) postDigest(ctx context.Context, cfg config.SlackDigestConfig, now time.Time) error {
+	since := now.Add(-time.Duration(cfg.Hours) * time.Hour)
+	messages, err := c.channelHistory(cfg.Channel, since, cfg.MaxMessages)
+	if err != nil {
+		return fmt.Errorf("failed to read channel history: %w", err)
+	}
+	if len(messages) == 0 {
+		c.logger.InfoKV("No messages to digest", "channel", cfg.Channel, "hours", cfg.Hours)
+		monitoring.SlackDigests.WithLabelValues("empty").Inc()
+		return nil
+	}
+
+	summary, err := c.summarizeChannel(ctx, cfg, c.digestTranscript(messages, now.Location()))
+	if err != nil {
+		return fmt.Errorf("failed to summarize channel: %w", err)
+	}
+	title := fmt.Sprintf("Digest of <#%s>, last %d hours", cfg.Channel, cfg.Hours)
+	if cfg.Canvas {
+		link, err := c.publishCanvas(fmt.Sprintf("Channel digest %s", now.Format("2006-01-02")), summary, cfg.PostTo)
+		if err != nil {
+			return fmt.Errorf("failed to create digest canvas: %w", err)
+		}
+		c.sendMessage(cfg.PostTo, "", fmt.Sprintf("📋 *%s:* <%s|open the canvas>", title, link))
+	} else {
+		c.sendMessage(cfg.PostTo, "", fmt.Sprintf("📋 *%s*\n\n%s", title, summary))
+	}
+	c.logger.InfoKV("Posted channel digest", "channel", cfg.Channel, "postTo", cfg.PostTo, "messages", len(messages), "canvas", cfg.Canvas)
+	monitoring.SlackDigests.WithLabelValues("posted").Inc()
+	return nil
+}
+
+// channelHistory returns up to limit of the most recent messages posted since,
+// oldest first, leaving out the bot's own messages and channel events
+func (c *Client) channelHistory(channelID string, since time.Time, limit int) ([]slack.Message, error) {
+	frontend := c.userFrontend.(DigestFrontend)
+	params := &slack.GetConversationHistoryParameters{
+		ChannelID: channelID,
+		Oldest:    strconv.FormatInt(since.Unix(), 10) + ".000000",
+		Limit:     digestPageSize,
+	}
+	var messages []slack.Message
+	for len(messages) < limit {
+		resp, err := frontend.GetConversationHistory(params)
+		if err != nil {
+			return nil, err
+		}
+		for _, msg := range resp.Messages {
+			if msg.SubType != "" || msg.BotID != "" || strings.TrimSpace(msg.Text) == "" {
+				continue
+			}
+			messages = append(messages, msg)
+		}
+		if !resp.HasMore || resp.ResponseMetaData.NextCursor == "" {
+			break
+		}
+		params.Cursor = resp.ResponseMetaData.NextCursor
+	}
+	// Slack returns the newest messages first
+	sort.Slice(messages, func(i, j int) bool { return tsAfter(messages[j].Timestamp, messages[i].Timestamp) })
+	if len(messages) > limit {
+		messages = messages[len(messages)-limit:]
+	}
+	return messages, nil
+}
+
+// digestTranscript renders messages as "[15:04] Name: text" lines for the LLM
+func (c *Client) digestTranscript(messages []slack.Message, location *time.Location) string {
+	var transcript strings.Builder
+	for _, msg := range messages {
+		name := msg.User
+		if profile, err := c.userFrontend.GetUserInfo(msg.User); err == nil && profile.realName != "" {
+			name = profile.realName
+		}
+		posted := slackTime(msg.Timestamp).In(location)
+		line := fmt.Sprintf("[%s] %s: %s", posted.Format("Mon 15:04"), name, strings.ReplaceAll(msg.Text, "\n", " "))
+		if msg.ReplyCount > 0 {
+			line += fmt.Sprintf(" (%d replies)", msg.ReplyCount)
+		}
+		transcript.WriteString(line + "\n")
+	}
+	return transcript.String()
+}
+
+// summarizeChannel asks the LLM for the digest of a transcript
+func (c *Client) summarizeChannel(ctx context.Context, cfg config.SlackDigestConfig, transcript string) (string, error) {
+	prompt := cfg.Prompt
+	if prompt == "" {
+		prompt = defaultDigestPrompt
+	}
+	providerCfg := c.cfg.LLM.Providers[c.cfg.LLM.Provider]
+	response, err := c.llmRegistry.GenerateChatCompletion(ctx, c.cfg.LLM.Provider, []llm.RequestMessage{
+		{Role: "system", Content: prompt},
+		{Role: "user", Content: transcript},
+	}, llm.ProviderOptions{
+		Temperature: providerCfg.Temperature,
+		MaxTokens:   providerCfg.MaxTokens,
+	})
+	if err != nil {
+		return "", err
+	}
+	summary := strings.TrimSpace(response.Content)
+	if summary == "" {
+		return "", fmt.Errorf("the LLM returned an empty summary")
+	}
+	return summary, nil
+}
+
+// publishCanvas writes markdown to a new canvas the channel can read and returns
+// its link
+func (c *Client) publishCanvas(title, markdown, channelID string) (string, error) {
+	frontend, ok := c.userFrontend.(CanvasFrontend)
+	if !ok {
+		return "", fmt.Errorf("this frontend cannot create canvases")
+	}
+	canvasID, err := frontend.CreateCanvas(title, slack.DocumentContent{Type: "markdown", Markdown: markdown})
+	if err != nil {
+		return "", err
+	}
+	if err := frontend.SetCanvasAccess(slack.SetCanvasAccessParams{CanvasID: canvasID, AccessLevel: "read", ChannelIDs: []string{channelID}}); err != nil {
+		return "", err
+	}
+	file, _, _, err := frontend.GetFileInfo(canvasID, 0, 0)
+	if err != nil {
+		return "", err
+	}
+	return file.Permalink, nil
+}
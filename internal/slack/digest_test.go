@@ -0,0 +1,83 @@
+package slackbot
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+)
+
+// fakeHistory serves conversations.history pages, newest message first
+type fakeHistory struct {
+	UserFrontend
+	pages []slack.GetConversationHistoryResponse
+	calls []slack.GetConversationHistoryParameters
+}
+
+func (f *fakeHistory) GetConversationHistory(params *slack.GetConversationHistoryParameters) (*slack.GetConversationHistoryResponse, error) {
+	f.calls = append(f.calls, *params)
+	return &f.pages[len(f.calls)-1], nil
+}
+
+func (f *fakeHistory) GetUserInfo(userID string) (*UserProfile, error) {
+	if userID == "U1" {
+		return &UserProfile{realName: "Ada Lovelace"}, nil
+	}
+	return nil, errors.New("user_not_found")
+}
+
+func historyPage(nextCursor string, messages ...slack.Message) slack.GetConversationHistoryResponse {
+	page := slack.GetConversationHistoryResponse{Messages: messages, HasMore: nextCursor != ""}
+	page.ResponseMetaData.NextCursor = nextCursor
+	return page
+}
+
+func historyMessage(ts, user, text string) slack.Message {
+	msg := slack.Message{}
+	msg.Timestamp, msg.User, msg.Text = ts, user, text
+	return msg
+}
+
+func TestChannelHistoryPagesAndOrders(t *testing.T) {
+	joined := historyMessage("1700000050.000000", "U2", "")
+	joined.SubType = "channel_join"
+	bot := historyMessage("1700000040.000000", "", "digest")
+	bot.BotID = "B1"
+	frontend := &fakeHistory{pages: []slack.GetConversationHistoryResponse{
+		historyPage("next", historyMessage("1700000300.000000", "U1", "third"), joined, historyMessage("1700000200.000000", "U2", "second")),
+		historyPage("", bot, historyMessage("1700000100.000000", "U1", "first")),
+	}}
+	client := &Client{userFrontend: frontend, logger: logging.New("test", logging.LevelError)}
+
+	messages, err := client.channelHistory("C1", time.Unix(1700000000, 0), 10)
+	require.NoError(t, err)
+	require.Len(t, frontend.calls, 2)
+	assert.Equal(t, "1700000000.000000", frontend.calls[0].Oldest)
+	assert.Equal(t, "next", frontend.calls[1].Cursor)
+	var texts []string
+	for _, msg := range messages {
+		texts = append(texts, msg.Text)
+	}
+	assert.Equal(t, []string{"first", "second", "third"}, texts, "oldest first, without bots and channel events")
+
+	// Only the most recent messages are kept past the limit
+	frontend.calls = nil
+	messages, err = client.channelHistory("C1", time.Unix(1700000000, 0), 2)
+	require.NoError(t, err)
+	assert.Len(t, frontend.calls, 1)
+	assert.Equal(t, "1700000200.000000", messages[0].Timestamp)
+	assert.Equal(t, "1700000300.000000", messages[1].Timestamp)
+}
+
+func TestDigestTranscript(t *testing.T) {
+	client := &Client{userFrontend: &fakeHistory{}}
+	threaded := historyMessage("1700000100.000000", "U1", "deploy is\nblocked")
+	threaded.ReplyCount = 3
+	transcript := client.digestTranscript([]slack.Message{threaded, historyMessage("1700000160.000000", "U9", "on it")}, time.UTC)
+	assert.Equal(t, "[Tue 22:15] Ada Lovelace: deploy is blocked (3 replies)\n[Tue 22:16] U9: on it\n", transcript)
+}
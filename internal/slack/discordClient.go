@@ -0,0 +1,849 @@
+package slackbot
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/network"
+	"github.com/tuannvm/slack-mcp-client/internal/slack/formatter"
+)
+
+// Gateway opcodes
+const (
+	discordOpDispatch       = 0
+	discordOpHeartbeat      = 1
+	discordOpIdentify       = 2
+	discordOpResume         = 6
+	discordOpReconnect      = 7
+	discordOpInvalidSession = 9
+	discordOpHello          = 10
+	discordOpHeartbeatAck   = 11
+)
+
+// discordIntents subscribes to server and direct messages and their content
+const discordIntents = 1<<9 | 1<<12 | 1<<15
+
+// discordMessageLimit is the maximum length of a Discord message
+const discordMessageLimit = 2000
+
+// Channel types that are threads
+const (
+	discordChannelDM             = 1
+	discordChannelAnnounceThread = 10
+	discordChannelPublicThread   = 11
+	discordChannelPrivateThread  = 12
+)
+
+// discordUnknownChannel is the API error code of a channel that does not exist
+const discordUnknownChannel = 10003
+
+// errDiscordReconnect asks Run to open a new Gateway session
+var errDiscordReconnect = errors.New("discord gateway asked to reconnect")
+
+// DiscordClient is a UserFrontend that serves Discord servers. Messages arrive
+// over the Gateway and are fed into the event channel as Slack events, so the
+// dispatch pipeline is shared: a mention in a server channel becomes an
+// app_mention, other messages become message events, with channel type "im" in
+// DMs. A message in a thread carries the thread's parent channel and the thread
+// ID as its thread timestamp; replies to a channel message go to a thread started
+// on it, whose ID is the message ID.
+type DiscordClient struct {
+	token           string
+	apiURL          string
+	gatewayURL      string
+	replyInChannel  bool
+	thinkingMessage string
+	httpClient      *http.Client
+	dialer          *websocket.Dialer
+	logger          *logging.Logger
+	events          chan socketmode.Event
+	outbox          *outboundQueue
+
+	mu         sync.Mutex
+	botUserID  string
+	mentionRgx *regexp.Regexp
+	sessionID  string
+	resumeURL  string
+	sequence   int64
+	channels   map[string]discordChannel // Channels and threads seen, by ID
+	users      map[string]*UserProfile
+	conn       *websocket.Conn
+	closed     bool
+
+	writeMu sync.Mutex // Serializes Gateway writes
+}
+
+// discordPayload is a Gateway message
+type discordPayload struct {
+	Op int             `json:"op"`
+	D  json.RawMessage `json:"d,omitempty"`
+	S  *int64          `json:"s,omitempty"`
+	T  string          `json:"t,omitempty"`
+}
+
+// discordUser is a Discord user object
+type discordUser struct {
+	ID         string `json:"id"`
+	Username   string `json:"username"`
+	GlobalName string `json:"global_name"`
+	Bot        bool   `json:"bot"`
+}
+
+// discordChannel is a Discord channel or thread
+type discordChannel struct {
+	ID       string `json:"id"`
+	Type     int    `json:"type"`
+	GuildID  string `json:"guild_id"`
+	ParentID string `json:"parent_id"`
+}
+
+// isThread reports whether the channel is a thread
+func (ch discordChannel) isThread() bool {
+	switch ch.Type {
+	case discordChannelAnnounceThread, discordChannelPublicThread, discordChannelPrivateThread:
+		return true
+	}
+	return false
+}
+
+// discordMessage is a Discord message
+type discordMessage struct {
+	ID        string        `json:"id"`
+	ChannelID string        `json:"channel_id"`
+	GuildID   string        `json:"guild_id"`
+	Author    discordUser   `json:"author"`
+	Content   string        `json:"content"`
+	Mentions  []discordUser `json:"mentions"`
+}
+
+// discordAPIError is an error response of the REST API
+type discordAPIError struct {
+	Status  int    `json:"-"`
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *discordAPIError) Error() string {
+	return fmt.Sprintf("discord API error %d (code %d): %s", e.Status, e.Code, e.Message)
+}
+
+// Retryable reports whether the outbound queue should retry the request
+func (e *discordAPIError) Retryable() bool {
+	return e.Status >= 500
+}
+
+// NewDiscordClient creates a Discord frontend. Replies are delivered through an
+// outbound queue configured like Slack's.
+func NewDiscordClient(discordCfg config.DiscordConfig, stdLogger *logging.Logger, thinkingMessage string, outbound config.SlackOutboundConfig) (*DiscordClient, error) {
+	if discordCfg.BotToken == "" {
+		return nil, fmt.Errorf("DISCORD_BOT_TOKEN must be set")
+	}
+	apiURL := strings.TrimSuffix(discordCfg.APIURL, "/")
+	if apiURL == "" {
+		apiURL = "https://discord.com/api/v10"
+	}
+
+	discordLogger := logging.New("discord-client", getLogLevel(stdLogger))
+	client := &DiscordClient{
+		token:           discordCfg.BotToken,
+		apiURL:          apiURL,
+		gatewayURL:      discordCfg.GatewayURL,
+		replyInChannel:  discordCfg.ReplyInChannel,
+		thinkingMessage: thinkingMessage,
+		httpClient:      &http.Client{Timeout: 30 * time.Second},
+		dialer:          network.WebsocketDialer(),
+		logger:          discordLogger,
+		events:          make(chan socketmode.Event, 50),
+		channels:        make(map[string]discordChannel),
+		users:           make(map[string]*UserProfile),
+	}
+	client.outbox = newOutboundQueue(outbound, client.deliverMessage, discordLogger)
+	return client, nil
+}
+
+// Run connects to the Gateway and feeds messages into the event channel until
+// Close is called. Dropped connections are resumed, or identified again.
+func (d *DiscordClient) Run() error {
+	gatewayURL := d.gatewayURL
+	if gatewayURL == "" {
+		var gateway struct {
+			URL string `json:"url"`
+		}
+		if err := d.api(http.MethodGet, "/gateway/bot", nil, &gateway); err != nil {
+			return fmt.Errorf("failed to get the discord gateway URL: %w", err)
+		}
+		gatewayURL = gateway.URL
+	}
+
+	backoff := time.Second
+	for {
+		err := d.session(gatewayURL)
+		if d.isClosed() {
+			return nil
+		}
+		var closeErr *websocket.CloseError
+		if errors.As(err, &closeErr) && discordFatalCloseCode(closeErr.Code) {
+			return fmt.Errorf("discord gateway closed the connection: %w", err)
+		}
+		if errors.Is(err, errDiscordReconnect) {
+			backoff = time.Second
+		} else {
+			d.logger.WarnKV("Discord gateway connection lost, reconnecting", "error", err, "retry_in", backoff)
+			time.Sleep(backoff)
+			backoff = min(backoff*2, 30*time.Second)
+		}
+	}
+}
+
+// discordFatalCloseCode reports whether a Gateway close code means reconnecting
+// cannot help, such as an invalid token or intents the bot may not use
+func discordFatalCloseCode(code int) bool {
+	return code == 4004 || (code >= 4010 && code <= 4014)
+}
+
+// session runs one Gateway connection until it drops or the Gateway asks for a
+// new one
+func (d *DiscordClient) session(gatewayURL string) error {
+	d.mu.Lock()
+	resume := d.sessionID != ""
+	if resume && d.resumeURL != "" {
+		gatewayURL = d.resumeURL
+	}
+	d.mu.Unlock()
+
+	conn, _, err := d.dialer.Dial(discordGatewayURL(gatewayURL), nil)
+	if err != nil {
+		return fmt.Errorf("failed to connect to the discord gateway: %w", err)
+	}
+	defer conn.Close()
+	if !d.setConn(conn) {
+		return nil
+	}
+	defer d.setConn(nil)
+
+	var hello discordPayload
+	if err := conn.ReadJSON(&hello); err != nil {
+		return fmt.Errorf("failed to read the discord gateway hello: %w", err)
+	}
+	if hello.Op != discordOpHello {
+		return fmt.Errorf("unexpected discord gateway opcode %d, expected hello", hello.Op)
+	}
+	var helloData struct {
+		HeartbeatInterval int64 `json:"heartbeat_interval"`
+	}
+	if err := json.Unmarshal(hello.D, &helloData); err != nil || helloData.HeartbeatInterval <= 0 {
+		return fmt.Errorf("invalid discord gateway hello: %s", hello.D)
+	}
+
+	if resume {
+		err = d.resume(conn)
+	} else {
+		err = d.identify(conn)
+	}
+	if err != nil {
+		return err
+	}
+
+	acked := make(chan struct{}, 1)
+	stop := make(chan struct{})
+	defer close(stop)
+	go d.heartbeat(conn, time.Duration(helloData.HeartbeatInterval)*time.Millisecond, acked, stop)
+
+	for {
+		var payload discordPayload
+		if err := conn.ReadJSON(&payload); err != nil {
+			return err
+		}
+		if payload.S != nil {
+			d.mu.Lock()
+			d.sequence = *payload.S
+			d.mu.Unlock()
+		}
+		switch payload.Op {
+		case discordOpDispatch:
+			d.handleDispatch(payload.T, payload.D)
+		case discordOpHeartbeat:
+			if err := d.sendHeartbeat(conn); err != nil {
+				return err
+			}
+		case discordOpHeartbeatAck:
+			select {
+			case acked <- struct{}{}:
+			default:
+			}
+		case discordOpReconnect:
+			return errDiscordReconnect
+		case discordOpInvalidSession:
+			var resumable bool
+			_ = json.Unmarshal(payload.D, &resumable)
+			if !resumable {
+				d.mu.Lock()
+				d.sessionID, d.resumeURL, d.sequence = "", "", 0
+				d.mu.Unlock()
+			}
+			return errDiscordReconnect
+		}
+	}
+}
+
+// discordGatewayURL adds the API version and encoding to a Gateway URL
+func discordGatewayURL(gatewayURL string) string {
+	u, err := url.Parse(gatewayURL)
+	if err != nil {
+		return gatewayURL
+	}
+	query := u.Query()
+	query.Set("v", "10")
+	query.Set("encoding", "json")
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// heartbeat sends heartbeats at the interval set by the Gateway, and closes the
+// connection when one is not acknowledged
+func (d *DiscordClient) heartbeat(conn *websocket.Conn, interval time.Duration, acked <-chan struct{}, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	waiting := false
+	for {
+		select {
+		case <-stop:
+			return
+		case <-acked:
+			waiting = false
+		case <-ticker.C:
+			if waiting {
+				d.logger.WarnKV("Discord gateway heartbeat not acknowledged, reconnecting")
+				_ = conn.Close()
+				return
+			}
+			if err := d.sendHeartbeat(conn); err != nil {
+				return
+			}
+			waiting = true
+		}
+	}
+}
+
+// sendHeartbeat sends a heartbeat with the last sequence number received
+func (d *DiscordClient) sendHeartbeat(conn *websocket.Conn) error {
+	d.mu.Lock()
+	var sequence interface{}
+	if d.sequence > 0 {
+		sequence = d.sequence
+	}
+	d.mu.Unlock()
+	return d.send(conn, discordOpHeartbeat, sequence)
+}
+
+// identify starts a new Gateway session
+func (d *DiscordClient) identify(conn *websocket.Conn) error {
+	return d.send(conn, discordOpIdentify, map[string]interface{}{
+		"token":   d.token,
+		"intents": discordIntents,
+		"properties": map[string]string{
+			"os":      "linux",
+			"browser": "slack-mcp-client",
+			"device":  "slack-mcp-client",
+		},
+	})
+}
+
+// resume continues the previous Gateway session, replaying missed events
+func (d *DiscordClient) resume(conn *websocket.Conn) error {
+	d.mu.Lock()
+	data := map[string]interface{}{
+		"token":      d.token,
+		"session_id": d.sessionID,
+		"seq":        d.sequence,
+	}
+	d.mu.Unlock()
+	return d.send(conn, discordOpResume, data)
+}
+
+// send writes a Gateway command
+func (d *DiscordClient) send(conn *websocket.Conn, op int, data interface{}) error {
+	d.writeMu.Lock()
+	defer d.writeMu.Unlock()
+	return conn.WriteJSON(map[string]interface{}{"op": op, "d": data})
+}
+
+// handleDispatch handles a Gateway event
+func (d *DiscordClient) handleDispatch(eventType string, data json.RawMessage) {
+	switch eventType {
+	case "READY":
+		var ready struct {
+			SessionID        string      `json:"session_id"`
+			ResumeGatewayURL string      `json:"resume_gateway_url"`
+			User             discordUser `json:"user"`
+		}
+		if err := json.Unmarshal(data, &ready); err != nil {
+			d.logger.WarnKV("Invalid discord READY event", "error", err)
+			return
+		}
+		d.mu.Lock()
+		d.sessionID = ready.SessionID
+		d.resumeURL = ready.ResumeGatewayURL
+		d.botUserID = ready.User.ID
+		d.mentionRgx = discordMentionPattern(ready.User.ID)
+		d.mu.Unlock()
+		d.logger.InfoKV("Connected to the Discord gateway", "bot_user", ready.User.Username)
+
+	case "GUILD_CREATE":
+		var guild struct {
+			ID       string           `json:"id"`
+			Channels []discordChannel `json:"channels"`
+			Threads  []discordChannel `json:"threads"`
+		}
+		if err := json.Unmarshal(data, &guild); err != nil {
+			return
+		}
+		for _, ch := range append(guild.Channels, guild.Threads...) {
+			ch.GuildID = guild.ID
+			d.rememberChannel(ch)
+		}
+
+	case "CHANNEL_CREATE", "CHANNEL_UPDATE", "THREAD_CREATE", "THREAD_UPDATE":
+		var ch discordChannel
+		if err := json.Unmarshal(data, &ch); err == nil {
+			d.rememberChannel(ch)
+		}
+
+	case "MESSAGE_CREATE":
+		var msg discordMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			d.logger.WarnKV("Invalid discord MESSAGE_CREATE event", "error", err)
+			return
+		}
+		if event, ok := d.messageEvent(msg); ok {
+			d.events <- event
+		}
+	}
+}
+
+// discordMentionPattern matches mentions of a user, with or without the
+// nickname marker
+func discordMentionPattern(userID string) *regexp.Regexp {
+	return regexp.MustCompile(fmt.Sprintf("<@!?%s>", regexp.QuoteMeta(userID)))
+}
+
+// rememberChannel caches a channel, so that messages in threads are mapped to
+// their parent channel without a lookup
+func (d *DiscordClient) rememberChannel(ch discordChannel) {
+	if ch.ID == "" {
+		return
+	}
+	d.mu.Lock()
+	d.channels[ch.ID] = ch
+	d.mu.Unlock()
+}
+
+// channel returns a channel from the cache, or looks it up
+func (d *DiscordClient) channel(channelID string) (discordChannel, error) {
+	d.mu.Lock()
+	ch, ok := d.channels[channelID]
+	d.mu.Unlock()
+	if ok {
+		return ch, nil
+	}
+	if err := d.api(http.MethodGet, "/channels/"+channelID, nil, &ch); err != nil {
+		return discordChannel{}, err
+	}
+	d.rememberChannel(ch)
+	return ch, nil
+}
+
+// messageEvent translates a Discord message into the Slack event the dispatch
+// pipeline handles. Messages of bots are skipped.
+func (d *DiscordClient) messageEvent(msg discordMessage) (socketmode.Event, bool) {
+	d.mu.Lock()
+	botUserID := d.botUserID
+	d.mu.Unlock()
+	if msg.Author.Bot || msg.Author.ID == "" || msg.Author.ID == botUserID {
+		return socketmode.Event{}, false
+	}
+
+	var inner interface{}
+	if msg.GuildID == "" {
+		d.rememberChannel(discordChannel{ID: msg.ChannelID, Type: discordChannelDM})
+		inner = &slackevents.MessageEvent{
+			Type:        "message",
+			User:        msg.Author.ID,
+			Text:        msg.Content,
+			TimeStamp:   msg.ID,
+			Channel:     msg.ChannelID,
+			ChannelType: "im",
+		}
+	} else {
+		channelID, threadTS := msg.ChannelID, ""
+		ch, err := d.channel(msg.ChannelID)
+		if err != nil {
+			d.logger.WarnKV("Failed to look up discord channel", "channel", msg.ChannelID, "error", err)
+		} else if ch.isThread() && ch.ParentID != "" {
+			channelID, threadTS = ch.ParentID, ch.ID
+		}
+
+		mentioned := false
+		for _, user := range msg.Mentions {
+			mentioned = mentioned || (botUserID != "" && user.ID == botUserID)
+		}
+		if mentioned {
+			inner = &slackevents.AppMentionEvent{
+				Type:            "app_mention",
+				User:            msg.Author.ID,
+				Text:            msg.Content,
+				TimeStamp:       msg.ID,
+				ThreadTimeStamp: threadTS,
+				Channel:         channelID,
+			}
+		} else {
+			inner = &slackevents.MessageEvent{
+				Type:            "message",
+				User:            msg.Author.ID,
+				Text:            msg.Content,
+				TimeStamp:       msg.ID,
+				ThreadTimeStamp: threadTS,
+				Channel:         channelID,
+				ChannelType:     "channel",
+			}
+		}
+	}
+
+	return socketmode.Event{
+		Type: socketmode.EventTypeEventsAPI,
+		Data: slackevents.EventsAPIEvent{
+			Type:       slackevents.CallbackEvent,
+			Data:       &slackevents.EventsAPICallbackEvent{EventID: "discord-" + msg.ID},
+			InnerEvent: slackevents.EventsAPIInnerEvent{Data: inner},
+		},
+		Request: &socketmode.Request{},
+	}, true
+}
+
+// setConn records the open Gateway connection; it returns false once the
+// client is closed
+func (d *DiscordClient) setConn(conn *websocket.Conn) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed && conn != nil {
+		return false
+	}
+	d.conn = conn
+	return true
+}
+
+// isClosed reports whether Close was called
+func (d *DiscordClient) isClosed() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.closed
+}
+
+// Close disconnects from the Gateway and drains the outbound queue
+func (d *DiscordClient) Close() error {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return nil
+	}
+	d.closed = true
+	conn := d.conn
+	d.mu.Unlock()
+	if conn != nil {
+		d.writeMu.Lock()
+		_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+		d.writeMu.Unlock()
+		_ = conn.Close()
+	}
+	d.outbox.close()
+	return nil
+}
+
+// Ack is a no-op: Gateway events need no acknowledgement
+func (d *DiscordClient) Ack(req socketmode.Request, payload ...interface{}) {}
+
+func (d *DiscordClient) GetEventChannel() chan socketmode.Event {
+	return d.events
+}
+
+func (d *DiscordClient) RemoveBotMention(msg string) string {
+	d.mu.Lock()
+	mentionRgx := d.mentionRgx
+	d.mu.Unlock()
+	if mentionRgx == nil {
+		return msg
+	}
+	return mentionRgx.ReplaceAllString(msg, "")
+}
+
+func (d *DiscordClient) GetLogger() *logging.Logger {
+	return d.logger
+}
+
+func (d *DiscordClient) IsValidUser(userID string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return userID != "" && userID != d.botUserID
+}
+
+// GetThreadReplies returns the message a thread was started on and the thread's
+// latest 100 messages, oldest first. A message without a thread is returned alone.
+func (d *DiscordClient) GetThreadReplies(channelID, threadTS string) ([]slack.Message, error) {
+	if channelID == "" || threadTS == "" {
+		return nil, fmt.Errorf("channelID and threadTS must be provided")
+	}
+
+	var messages []slack.Message
+	var starter discordMessage
+	err := d.api(http.MethodGet, fmt.Sprintf("/channels/%s/messages/%s", channelID, threadTS), nil, &starter)
+	if err == nil {
+		messages = append(messages, d.slackMessage(starter, threadTS))
+	} else if !isDiscordNotFound(err) {
+		return nil, fmt.Errorf("failed to fetch discord message: %w", err)
+	}
+
+	d.mu.Lock()
+	ch, cached := d.channels[channelID]
+	d.mu.Unlock()
+	if cached && ch.Type == discordChannelDM {
+		return messages, nil // DMs have no threads
+	}
+
+	var replies []discordMessage
+	err = d.api(http.MethodGet, fmt.Sprintf("/channels/%s/messages?limit=100", threadTS), nil, &replies)
+	if err != nil {
+		if isDiscordNotFound(err) {
+			return messages, nil
+		}
+		return nil, fmt.Errorf("failed to fetch discord thread messages: %w", err)
+	}
+	// Messages are returned newest first; the thread's starter message reference has no content
+	for i := len(replies) - 1; i >= 0; i-- {
+		if replies[i].Content == "" {
+			continue
+		}
+		messages = append(messages, d.slackMessage(replies[i], threadTS))
+	}
+	return messages, nil
+}
+
+// slackMessage converts a Discord message for the conversation history; messages
+// of bots carry a bot ID
+func (d *DiscordClient) slackMessage(msg discordMessage, threadTS string) slack.Message {
+	message := slack.Message{}
+	message.User = msg.Author.ID
+	message.Text = msg.Content
+	message.Timestamp = msg.ID
+	message.ThreadTimestamp = threadTS
+	if msg.Author.Bot {
+		message.BotID = msg.Author.ID
+	}
+	return message
+}
+
+func (d *DiscordClient) GetUserInfo(userID string) (*UserProfile, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID must be provided")
+	}
+	d.mu.Lock()
+	profile, ok := d.users[userID]
+	d.mu.Unlock()
+	if ok {
+		return profile, nil
+	}
+
+	var user discordUser
+	if err := d.api(http.MethodGet, "/users/"+userID, nil, &user); err != nil {
+		return nil, fmt.Errorf("failed to fetch discord user: %w", err)
+	}
+	realName := user.GlobalName
+	if realName == "" {
+		realName = user.Username
+	}
+	profile = &UserProfile{userId: userID, realName: realName}
+	d.mu.Lock()
+	d.users[userID] = profile
+	d.mu.Unlock()
+	return profile, nil
+}
+
+// SendMessage queues a message for delivery. The thinking message is shown as
+// the typing indicator instead.
+func (d *DiscordClient) SendMessage(channelID, threadTS, text string) {
+	if text == "" {
+		d.logger.WarnKV("Attempted to send empty message, skipping", "channel", channelID)
+		return
+	}
+	if text == d.thinkingMessage {
+		target := channelID
+		if thread, ok := d.knownThread(threadTS); ok {
+			target = thread
+		}
+		if err := d.api(http.MethodPost, "/channels/"+target+"/typing", nil, nil); err != nil {
+			d.logger.DebugKV("Failed to show the typing indicator", "channel", target, "error", err)
+		}
+		return
+	}
+	// Each part is queued on its own so that a retry does not post earlier parts again
+	for _, chunk := range formatter.SplitMessage(formatter.MarkdownLinks(text), discordMessageLimit) {
+		d.outbox.enqueue(channelID, threadTS, chunk)
+	}
+}
+
+// knownThread returns the thread of a thread timestamp when it is cached
+func (d *DiscordClient) knownThread(threadTS string) (string, bool) {
+	if threadTS == "" {
+		return "", false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	ch, ok := d.channels[threadTS]
+	return threadTS, ok && ch.isThread()
+}
+
+// deliverMessage posts a queued message. Replies to a channel message start a
+// thread on it unless replyInChannel is set.
+func (d *DiscordClient) deliverMessage(msg outboundMessage) error {
+	target, err := d.replyTarget(msg.ChannelID, msg.ThreadTS)
+	if err != nil {
+		return err
+	}
+	return d.api(http.MethodPost, "/channels/"+target+"/messages", map[string]interface{}{
+		"content":          msg.Text,
+		"allowed_mentions": map[string]interface{}{"parse": []string{"users"}},
+	}, nil)
+}
+
+// replyTarget returns the channel a reply is posted to, starting a thread when
+// needed
+func (d *DiscordClient) replyTarget(channelID, threadTS string) (string, error) {
+	if threadTS == "" {
+		return channelID, nil
+	}
+	if thread, ok := d.knownThread(threadTS); ok {
+		return thread, nil
+	}
+	d.mu.Lock()
+	ch, cached := d.channels[channelID]
+	d.mu.Unlock()
+	if d.replyInChannel || (cached && ch.Type == discordChannelDM) {
+		return channelID, nil
+	}
+
+	var starter discordMessage
+	name := "Conversation"
+	if err := d.api(http.MethodGet, fmt.Sprintf("/channels/%s/messages/%s", channelID, threadTS), nil, &starter); err == nil {
+		name = discordThreadName(d.RemoveBotMention(starter.Content))
+	}
+	var thread discordChannel
+	err := d.api(http.MethodPost, fmt.Sprintf("/channels/%s/messages/%s/threads", channelID, threadTS), map[string]interface{}{
+		"name": name,
+	}, &thread)
+	if err != nil {
+		var apiErr *discordAPIError
+		if errors.As(err, &apiErr) && apiErr.Status == http.StatusBadRequest {
+			// The message cannot have a thread, for example because it is in a DM
+			d.logger.DebugKV("Replying in the channel, the message cannot start a thread", "channel", channelID, "error", err)
+			return channelID, nil
+		}
+		return "", fmt.Errorf("failed to start discord thread: %w", err)
+	}
+	if thread.ID == "" {
+		thread = discordChannel{ID: threadTS, Type: discordChannelPublicThread, ParentID: channelID}
+	}
+	d.rememberChannel(thread)
+	return thread.ID, nil
+}
+
+// discordThreadName names a thread after the message it starts on
+func discordThreadName(text string) string {
+	name := strings.Join(strings.Fields(text), " ")
+	if name == "" {
+		return "Conversation"
+	}
+	if runes := []rune(name); len(runes) > 80 {
+		name = string(runes[:79]) + "…"
+	}
+	return name
+}
+
+// isDiscordNotFound reports whether the API answered that the resource does not exist
+func isDiscordNotFound(err error) bool {
+	var apiErr *discordAPIError
+	return errors.As(err, &apiErr) && (apiErr.Status == http.StatusNotFound || apiErr.Code == discordUnknownChannel)
+}
+
+// api calls the REST API, decoding the response into out when it is not nil.
+// Rate limits are returned as errors the outbound queue retries after the
+// requested delay.
+func (d *DiscordClient) api(method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequest(method, d.apiURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bot "+d.token)
+	req.Header.Set("User-Agent", "DiscordBot (https://github.com/tuannvm/slack-mcp-client, 1.0)")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		var limited struct {
+			RetryAfter float64 `json:"retry_after"`
+		}
+		_ = json.Unmarshal(data, &limited)
+		if limited.RetryAfter <= 0 {
+			limited.RetryAfter, _ = strconv.ParseFloat(resp.Header.Get("Retry-After"), 64)
+		}
+		return &slack.RateLimitedError{RetryAfter: time.Duration(limited.RetryAfter * float64(time.Second))}
+	}
+	if resp.StatusCode >= 300 {
+		apiErr := &discordAPIError{Status: resp.StatusCode}
+		_ = json.Unmarshal(data, apiErr)
+		if apiErr.Message == "" {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+		return apiErr
+	}
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("invalid discord API response: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package slackbot
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+func newTestDiscordClient(t *testing.T, apiURL, gatewayURL string) *DiscordClient {
+	client, err := NewDiscordClient(config.DiscordConfig{BotToken: "discord-token", APIURL: apiURL, GatewayURL: gatewayURL},
+		logging.New("test", logging.LevelError), "Thinking...", config.SlackOutboundConfig{MaxAttempts: 1})
+	require.NoError(t, err)
+	return client
+}
+
+func nextDiscordEvent(t *testing.T, client *DiscordClient) interface{} {
+	select {
+	case event := <-client.GetEventChannel():
+		return event.Data.(slackevents.EventsAPIEvent).InnerEvent.Data
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event received")
+		return nil
+	}
+}
+
+func TestDiscordGatewayTranslatesMessages(t *testing.T) {
+	identified := make(chan map[string]interface{}, 1)
+	upgrader := websocket.Upgrader{}
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		send := func(op int, eventType string, data string) {
+			_ = conn.WriteJSON(map[string]interface{}{"op": op, "t": eventType, "s": 1, "d": json.RawMessage(data)})
+		}
+		send(discordOpHello, "", `{"heartbeat_interval": 45000}`)
+		var identify struct {
+			Op int                    `json:"op"`
+			D  map[string]interface{} `json:"d"`
+		}
+		if err := conn.ReadJSON(&identify); err != nil || identify.Op != discordOpIdentify {
+			return
+		}
+		identified <- identify.D
+		send(discordOpDispatch, "READY", `{"session_id": "s1", "user": {"id": "B1", "username": "bot"}}`)
+		send(discordOpDispatch, "THREAD_CREATE", `{"id": "T1", "type": 11, "parent_id": "C1", "guild_id": "G1"}`)
+		send(discordOpDispatch, "MESSAGE_CREATE", `{"id": "M1", "channel_id": "C1", "guild_id": "G1", "author": {"id": "U1"}, "content": "<@B1> hello", "mentions": [{"id": "B1"}]}`)
+		send(discordOpDispatch, "MESSAGE_CREATE", `{"id": "M2", "channel_id": "T1", "guild_id": "G1", "author": {"id": "U1"}, "content": "and then?"}`)
+		send(discordOpDispatch, "MESSAGE_CREATE", `{"id": "M3", "channel_id": "C1", "guild_id": "G1", "author": {"id": "X1", "bot": true}, "content": "beep"}`)
+		send(discordOpDispatch, "MESSAGE_CREATE", `{"id": "M4", "channel_id": "D1", "author": {"id": "U1"}, "content": "private"}`)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer gateway.Close()
+
+	client := newTestDiscordClient(t, "http://127.0.0.1:1", "ws"+strings.TrimPrefix(gateway.URL, "http"))
+	done := make(chan error, 1)
+	go func() { done <- client.Run() }()
+
+	select {
+	case data := <-identified:
+		assert.Equal(t, "discord-token", data["token"])
+		assert.Equal(t, float64(discordIntents), data["intents"])
+	case <-time.After(5 * time.Second):
+		t.Fatal("client did not identify")
+	}
+
+	mention, ok := nextDiscordEvent(t, client).(*slackevents.AppMentionEvent)
+	require.True(t, ok)
+	assert.Equal(t, "C1", mention.Channel)
+	assert.Equal(t, "M1", mention.TimeStamp)
+	assert.Equal(t, "", mention.ThreadTimeStamp)
+	assert.Equal(t, " hello", client.RemoveBotMention(mention.Text))
+
+	threadMessage, ok := nextDiscordEvent(t, client).(*slackevents.MessageEvent)
+	require.True(t, ok)
+	assert.Equal(t, "C1", threadMessage.Channel)
+	assert.Equal(t, "T1", threadMessage.ThreadTimeStamp)
+	assert.Equal(t, "channel", threadMessage.ChannelType)
+
+	// The bot's message is skipped
+	direct, ok := nextDiscordEvent(t, client).(*slackevents.MessageEvent)
+	require.True(t, ok)
+	assert.Equal(t, "D1", direct.Channel)
+	assert.Equal(t, "im", direct.ChannelType)
+	assert.False(t, client.IsValidUser("B1"))
+
+	require.NoError(t, client.Close())
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after Close")
+	}
+}
+
+func TestDiscordReplyStartsThread(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	var posted []string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bot discord-token", r.Header.Get("Authorization"))
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/channels/C1/messages/M1":
+			_, _ = w.Write([]byte(`{"id": "M1", "channel_id": "C1", "author": {"id": "U1"}, "content": "<@B1> what is up?"}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/channels/C1/messages/M1/threads":
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			assert.Equal(t, "what is up?", body["name"])
+			_, _ = w.Write([]byte(`{"id": "M1", "type": 11, "parent_id": "C1"}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/channels/M1/messages":
+			var body map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			posted = append(posted, body["content"].(string))
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"code": 10003, "message": "Unknown Channel"}`))
+		}
+	}))
+	defer api.Close()
+
+	client := newTestDiscordClient(t, api.URL, "")
+	client.mentionRgx = discordMentionPattern("B1")
+	client.SendMessage("C1", "M1", "See <https://example.com/doc|the doc>")
+	client.SendMessage("C1", "M1", strings.Repeat("a", 1500)+"\n"+strings.Repeat("b", 1000))
+	require.NoError(t, client.Close())
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{
+		"GET /channels/C1/messages/M1",
+		"POST /channels/C1/messages/M1/threads",
+		"POST /channels/M1/messages",
+		"POST /channels/M1/messages",
+		"POST /channels/M1/messages",
+	}, requests)
+	assert.Equal(t, []string{"See [the doc](https://example.com/doc)", strings.Repeat("a", 1500), strings.Repeat("b", 1000)}, posted)
+}
+
+func TestDiscordThinkingMessageShowsTyping(t *testing.T) {
+	paths := make(chan string, 1)
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths <- r.Method + " " + r.URL.Path
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer api.Close()
+
+	client := newTestDiscordClient(t, api.URL, "")
+	defer client.Close()
+	client.SendMessage("C1", "", "Thinking...")
+	assert.Equal(t, "POST /channels/C1/typing", <-paths)
+}
+
+var _ UserFrontend = (*DiscordClient)(nil)
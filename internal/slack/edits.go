@@ -0,0 +1,113 @@
+package slackbot
+
+import (
+	"strings"
+
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/tokens"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+const (
+	// supersededMessage replaces the answer to a prompt the user edited meanwhile
+	supersededMessage = "✏️ Your message was edited, answering the new version."
+	// editedAnswerNotice precedes the new answer to a prompt edited after it was answered
+	editedAnswerNotice = "✏️ Your message was edited, here is an updated answer."
+)
+
+// editedPrompt is a correction of the user's latest prompt
+type editedPrompt struct {
+	channelID string
+	threadTS  string
+	promptTS  string
+	userID    string
+	text      string
+}
+
+// handleEditedMessage answers again when the user edits their latest prompt and
+// slack.editedPrompts is "reanswer". An answer still being written is stopped;
+// otherwise the new answer is posted below a note that the prompt was edited.
+func (c *Client) handleEditedMessage(ev *slackevents.MessageEvent) {
+	if c.cfg.Slack.EditedPrompts != config.EditedPromptsReanswer {
+		return
+	}
+	edit, ok := c.editedPrompt(ev)
+	if !ok {
+		return
+	}
+	c.logger.InfoKV("User edited their latest prompt, answering again", "channel", edit.channelID, "user", edit.userID, "ts", edit.promptTS)
+
+	superseded := c.stopRequests(func(req *inflightRequest) bool {
+		return req.channelID == edit.channelID && req.promptTS == edit.promptTS
+	}, supersededMessage)
+	c.replaceHistoryContent(edit.channelID, edit.threadTS, edit.promptTS, edit.text)
+
+	profile, err := c.userFrontend.GetUserInfo(edit.userID)
+	if err != nil {
+		c.logger.WarnKV("Failed to get user info", "user", edit.userID, "error", err)
+		profile = &UserProfile{userId: edit.userID, realName: "Unknown", email: ""}
+	}
+	if superseded == 0 {
+		c.sendMessage(edit.channelID, edit.threadTS, editedAnswerNotice)
+	}
+	c.handleUserPrompt(edit.text, edit.channelID, edit.threadTS, edit.promptTS, profile)
+}
+
+// editedPrompt returns the correction a message_changed event carries. Only
+// text edits of the user's most recent prompt in the thread count, and where
+// the bot only answers mentions, the edited message must still mention it.
+func (c *Client) editedPrompt(ev *slackevents.MessageEvent) (editedPrompt, bool) {
+	message := ev.Message
+	if message == nil || message.BotID != "" || !c.userFrontend.IsValidUser(message.User) {
+		return editedPrompt{}, false
+	}
+	// Unfurls and other attachments also arrive as message_changed
+	if ev.PreviousMessage != nil && ev.PreviousMessage.Text == message.Text {
+		return editedPrompt{}, false
+	}
+
+	// The edited message must still be one the bot answers in this conversation
+	text := c.userFrontend.RemoveBotMention(message.Text)
+	if !c.repliesTo(c.conversationType(ev.Channel, ev.ChannelType), text != message.Text) {
+		return editedPrompt{}, false
+	}
+	edit := editedPrompt{
+		channelID: ev.Channel,
+		threadTS:  message.ThreadTimeStamp,
+		promptTS:  message.TimeStamp,
+		userID:    message.User,
+		text:      strings.TrimSpace(text),
+	}
+	if edit.threadTS == "" {
+		edit.threadTS = edit.promptTS
+	}
+	if edit.text == "" || !c.isLatestPrompt(edit.channelID, edit.threadTS, edit.promptTS, edit.userID) {
+		return editedPrompt{}, false
+	}
+	return edit, true
+}
+
+// isLatestPrompt reports whether ts is the user's most recent message in the thread
+func (c *Client) isLatestPrompt(channelID, threadTS, ts, userID string) bool {
+	history := c.threadHistory(channelID, threadTS)
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Role == "user" && history[i].UserID == userID {
+			return history[i].SlackTimestamp == ts
+		}
+	}
+	return false
+}
+
+// replaceHistoryContent updates the text of a message in the thread history
+func (c *Client) replaceHistoryContent(channelID, threadTS, ts, content string) {
+	c.historyMu.Lock()
+	defer c.historyMu.Unlock()
+	history := c.messageHistory[historyKey(channelID, threadTS)]
+	for i := range history {
+		if history[i].SlackTimestamp == ts {
+			history[i].Content = content
+			history[i].Tokens = tokens.Count(content)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package slackbot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/slack-go/slack/slackevents"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func messageChanged(channelID, threadTS, ts, previous, text string) *slackevents.MessageEvent {
+	return &slackevents.MessageEvent{
+		Channel:         channelID,
+		SubType:         "message_changed",
+		Message:         &slackevents.MessageEvent{User: "U1", ThreadTimeStamp: threadTS, TimeStamp: ts, Text: text},
+		PreviousMessage: &slackevents.MessageEvent{User: "U1", TimeStamp: ts, Text: previous},
+	}
+}
+
+func TestEditedPromptOnlyForLatestPrompt(t *testing.T) {
+	client, _, _ := newProgressTestClient()
+	client.historyLimit = 50
+	client.messageHistory = map[string][]Message{}
+	client.addToHistory("D1", "100.1", "100.1", "user", "deploy api", "U1", "", "")
+	client.addToHistory("D1", "100.1", "100.2", "assistant", "Deployed.", "B1", "", "")
+	client.addToHistory("D1", "100.1", "100.3", "user", "rollbak api", "U1", "", "")
+
+	edit, ok := client.editedPrompt(messageChanged("D1", "100.1", "100.3", "rollbak api", "rollback api"))
+	require.True(t, ok)
+	assert.Equal(t, editedPrompt{channelID: "D1", threadTS: "100.1", promptTS: "100.3", userID: "U1", text: "rollback api"}, edit)
+
+	// Older prompts and edits that keep the text, such as unfurls, are ignored
+	_, ok = client.editedPrompt(messageChanged("D1", "100.1", "100.1", "deploy api", "deploy web"))
+	assert.False(t, ok)
+	_, ok = client.editedPrompt(messageChanged("D1", "100.1", "100.3", "rollbak api", "rollbak api"))
+	assert.False(t, ok)
+
+	client.replaceHistoryContent("D1", "100.1", "100.3", edit.text)
+	assert.Equal(t, "rollback api", client.messageHistory[historyKey("D1", "100.1")][2].Content)
+}
+
+func TestEditSupersedesAnswerInProgress(t *testing.T) {
+	client, frontend, _ := newProgressTestClient()
+
+	ctx := client.showThinking(context.Background(), "D1", "100.1")
+	ctx, done := client.trackRequest(ctx, "D1", "100.1", "100.3", "U1")
+	defer done()
+
+	stopped := client.stopRequests(func(req *inflightRequest) bool { return req.promptTS == "100.3" }, supersededMessage)
+	assert.Equal(t, 1, stopped)
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+	assert.Equal(t, []string{"111.1 " + supersededMessage}, frontend.replaced)
+}
@@ -0,0 +1,120 @@
+package slackbot
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+
+	customErrors "github.com/tuannvm/slack-mcp-client/internal/common/errors"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+	"github.com/tuannvm/slack-mcp-client/internal/monitoring"
+)
+
+// defaultErrorMessages are what users are told about each kind of error, unless
+// slack.errorMessages overrides it
+var defaultErrorMessages = map[string]string{
+	config.ErrorKindToolTimeout:       "Sorry, `{tool}` did not finish within {timeout}, so I stopped it. Please try again, or narrow down the request.",
+	config.ErrorKindServerUnavailable: "Sorry, the {server} tool server is not responding right now, so I could not run `{tool}`. Please try again in a few minutes.",
+	config.ErrorKindToolFailed:        "Sorry, the `{tool}` tool failed. Please try again, or rephrase the request.",
+	config.ErrorKindLLMRateLimited:    "Sorry, the AI provider is receiving too many requests right now. Please try again in a minute.",
+	config.ErrorKindLLMTimeout:        "Sorry, the AI provider took too long to answer. Please try again.",
+	config.ErrorKindLLMAuth:           "Sorry, the AI provider rejected my credentials, so I cannot answer right now. Please let the bot's admins know.",
+	config.ErrorKindLLMContextLength:  "Sorry, this conversation has grown too long for the AI model. Please start a new thread.",
+	config.ErrorKindLLMUnavailable:    "Sorry, the AI provider is unavailable right now. Please try again in a few minutes.",
+	config.ErrorKindInternal:          "Sorry, something went wrong while answering. Please try again.",
+}
+
+// llmErrorPatterns classify LLM provider errors, which arrive as wrapped strings, by
+// their message. The first matching kind wins.
+var llmErrorPatterns = []struct {
+	kind    string
+	pattern *regexp.Regexp
+}{
+	{config.ErrorKindLLMContextLength, regexp.MustCompile(`(?i)context_length_exceeded|context length|maximum context|prompt is too long|too many tokens`)},
+	{config.ErrorKindLLMRateLimited, regexp.MustCompile(`(?i)\b429\b|rate.?limit|too many requests|quota`)},
+	{config.ErrorKindLLMAuth, regexp.MustCompile(`(?i)\b40[13]\b|invalid.?api.?key|incorrect api key|unauthorized|authentication|permission denied`)},
+	{config.ErrorKindLLMTimeout, regexp.MustCompile(`(?i)deadline exceeded|timeout|timed out`)},
+	{config.ErrorKindLLMUnavailable, regexp.MustCompile(`(?i)\b(500|502|503|529)\b|overloaded|unavailable|connection refused|no such host|connection reset|\bEOF\b`)},
+}
+
+// userError is an error of a request, classified for the user who sent it
+type userError struct {
+	kind     string
+	tool     string
+	server   string
+	provider string
+	timeout  string
+}
+
+// classifyError tells what kind of error a request failed with. Errors of the MCP
+// domain come from tools; the others from the LLM provider, unless nothing about
+// them is recognized.
+func classifyError(err error) userError {
+	var timeoutErr *mcp.ToolTimeoutError
+	if errors.As(err, &timeoutErr) {
+		return userError{kind: config.ErrorKindToolTimeout, tool: timeoutErr.Tool, timeout: timeoutErr.Timeout.String()}
+	}
+	if domain, ok := customErrors.GetDomain(err); ok && domain == customErrors.ErrorDomainMCP {
+		classified := userError{kind: config.ErrorKindToolFailed, tool: "unknown", server: "MCP"}
+		if tool, ok := customErrors.GetErrorData(err, "tool_name"); ok {
+			classified.tool = fmt.Sprint(tool)
+		}
+		if server, ok := customErrors.GetErrorData(err, "server_name"); ok && fmt.Sprint(server) != "" {
+			classified.server = fmt.Sprint(server)
+		}
+		if mcp.IsServerUnavailable(err) {
+			classified.kind = config.ErrorKindServerUnavailable
+		}
+		return classified
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return userError{kind: config.ErrorKindLLMTimeout}
+	}
+	for _, pattern := range llmErrorPatterns {
+		if pattern.pattern.MatchString(err.Error()) {
+			return userError{kind: pattern.kind}
+		}
+	}
+	return userError{kind: config.ErrorKindInternal}
+}
+
+// presentError turns the error a request failed with into a message for the user.
+// The details stay in the logs and the trace: the message only carries a reference,
+// the trace ID when the request is traced, to find them.
+func (c *Client) presentError(ctx context.Context, err error) string {
+	classified := classifyError(err)
+	classified.provider = c.cfg.LLM.Provider
+	reference := errorReference(ctx)
+	monitoring.UserErrors.WithLabelValues(classified.kind).Inc()
+	c.logger.ErrorKV("Request failed", "kind", classified.kind, "reference", reference, "error", err)
+
+	message, ok := c.cfg.Slack.ErrorMessages[classified.kind]
+	if !ok {
+		message = defaultErrorMessages[classified.kind]
+	}
+	message = strings.NewReplacer(
+		"{tool}", classified.tool,
+		"{server}", classified.server,
+		"{provider}", classified.provider,
+		"{timeout}", classified.timeout,
+	).Replace(message)
+	return fmt.Sprintf("%s\n_Reference: `%s`_", message, reference)
+}
+
+// errorReference identifies a failed request in the logs: the ID of its trace, or
+// a random one when it is not traced
+func errorReference(ctx context.Context) string {
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+		return spanContext.TraceID().String()
+	}
+	random := make([]byte, 4)
+	_, _ = rand.Read(random)
+	return hex.EncodeToString(random)
+}
@@ -0,0 +1,65 @@
+package slackbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	customErrors "github.com/tuannvm/slack-mcp-client/internal/common/errors"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+)
+
+func TestClassifyError(t *testing.T) {
+	toolErr := func(cause error) error {
+		return customErrors.WrapMCPError(cause, "tool_execution_failed", "Failed to execute MCP tool 'get_pods'").
+			WithData("tool_name", "get_pods").
+			WithData("server_name", "kubernetes")
+	}
+	timeout := &mcp.ToolTimeoutError{Tool: "get_pods", Timeout: 30 * time.Second}
+
+	tests := []struct {
+		name string
+		err  error
+		want userError
+	}{
+		{"tool timeout", customErrors.WrapMCPError(timeout, "tool_timeout", "timed out"), userError{kind: config.ErrorKindToolTimeout, tool: "get_pods", timeout: "30s"}},
+		{"server down", toolErr(fmt.Errorf("failed to write request: %w", syscall.EPIPE)), userError{kind: config.ErrorKindServerUnavailable, tool: "get_pods", server: "kubernetes"}},
+		{"tool failed", toolErr(errors.New("namespace not found")), userError{kind: config.ErrorKindToolFailed, tool: "get_pods", server: "kubernetes"}},
+		{"rate limited", customErrors.WrapSlackError(errors.New("API returned unexpected status code: 429: Rate limit reached"), "llm_request_failed", "LLM request failed"), userError{kind: config.ErrorKindLLMRateLimited}},
+		{"bad key", errors.New("API returned unexpected status code: 401: Incorrect API key provided"), userError{kind: config.ErrorKindLLMAuth}},
+		{"context length", errors.New("This model's maximum context length is 128000 tokens"), userError{kind: config.ErrorKindLLMContextLength}},
+		{"deadline", fmt.Errorf("request: %w", context.DeadlineExceeded), userError{kind: config.ErrorKindLLMTimeout}},
+		{"overloaded", errors.New("status code: 529: Overloaded"), userError{kind: config.ErrorKindLLMUnavailable}},
+		{"max tokens are not a status", errors.New("invalid max_tokens 1500"), userError{kind: config.ErrorKindInternal}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, classifyError(tt.err))
+		})
+	}
+}
+
+func TestPresentError(t *testing.T) {
+	client, _, _ := newProgressTestClient()
+	err := customErrors.WrapMCPError(errors.New("connection refused"), "tool_execution_failed", "Failed to execute MCP tool 'get_pods'").
+		WithData("tool_name", "get_pods").
+		WithData("server_name", "kubernetes")
+
+	message := client.presentError(context.Background(), err)
+	assert.Contains(t, message, "Sorry, the kubernetes tool server is not responding right now, so I could not run `get_pods`.")
+	assert.Regexp(t, "\n_Reference: `[0-9a-f]{8}`_$", message)
+	assert.NotContains(t, message, "connection refused")
+
+	client.cfg.Slack.ErrorMessages = map[string]string{config.ErrorKindServerUnavailable: "{server} is down, ask #platform-oncall."}
+	assert.Contains(t, client.presentError(context.Background(), err), "kubernetes is down, ask #platform-oncall.")
+
+	for _, kind := range config.ErrorKinds {
+		assert.NotEmpty(t, defaultErrorMessages[kind], kind)
+	}
+}
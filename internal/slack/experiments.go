@@ -0,0 +1,109 @@
+package slackbot
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/slack-go/slack"
+
+	"github.com/tuannvm/slack-mcp-client/internal/experiments"
+)
+
+// MessageLookupFrontend is implemented by frontends that can fetch a message by its
+// timestamp, which feedback reactions need to find the conversation they belong to,
+// and tell the bot's own messages from those of other apps
+type MessageLookupFrontend interface {
+	GetConversationReplies(params *slack.GetConversationRepliesParameters) ([]slack.Message, bool, string, error)
+	IsOwnMessage(msg slack.Message) bool
+}
+
+// feedbackSentiment reports whether a reaction is positive or negative feedback
+func (c *Client) feedbackSentiment(reaction string) (positive bool, ok bool) {
+	// Skin tones arrive as "+1::skin-tone-2"
+	reaction, _, _ = strings.Cut(reaction, "::")
+	switch {
+	case slices.Contains(c.cfg.Slack.FeedbackReactions.Positive, reaction):
+		return true, true
+	case slices.Contains(c.cfg.Slack.FeedbackReactions.Negative, reaction):
+		return false, true
+	}
+	return false, false
+}
+
+// handleFeedbackReaction counts a feedback reaction on one of the bot's answers
+// towards the experiment arm of its conversation
+func (c *Client) handleFeedbackReaction(channelID, messageTS, userID string, positive bool) {
+	frontend, ok := c.userFrontend.(MessageLookupFrontend)
+	if !ok {
+		return
+	}
+	messages, _, _, err := frontend.GetConversationReplies(&slack.GetConversationRepliesParameters{
+		ChannelID: channelID,
+		Timestamp: messageTS,
+		Latest:    messageTS,
+		Inclusive: true,
+		Limit:     1,
+	})
+	if err != nil || len(messages) == 0 {
+		c.logger.WarnKV("Failed to fetch the message reacted to for experiment feedback", "channel", channelID, "ts", messageTS, "error", err)
+		return
+	}
+	msg := messages[0]
+	if !frontend.IsOwnMessage(msg) {
+		return // Only feedback on the bot's answers counts
+	}
+	threadTS := msg.ThreadTimestamp
+	if threadTS == "" {
+		threadTS = msg.Timestamp
+	}
+	if assignment, counted := c.experiments.Feedback(channelID, threadTS, messageTS, userID, positive); counted {
+		c.logger.DebugKV("Recorded experiment feedback", "experiment", assignment.Experiment, "arm", assignment.Arm,
+			"positive", positive, "channel", channelID, "ts", messageTS)
+	}
+}
+
+// handleExperimentRollback logs a variant rolled back to the control arm, or
+// resumed after it was changed, and alerts the experiment's admin channel
+func (c *Client) handleExperimentRollback(rollback experiments.Rollback) {
+	if rollback.Err != nil {
+		c.logger.ErrorKV("Failed to persist experiment rollback", "experiment", rollback.Experiment, "error", rollback.Err)
+	}
+	var alert string
+	switch {
+	case rollback.Resumed:
+		c.logger.InfoKV("Resumed experiment variant changed since its rollback", "experiment", rollback.Experiment, "rolled_back", rollback.Time)
+		alert = fmt.Sprintf(":arrow_forward: The variant of experiment *%s*, rolled back at %s (%s), was changed and answers its share of conversations again.",
+			rollback.Experiment, rollback.Time.UTC().Format("2006-01-02 15:04 MST"), rollback.Reason)
+	case rollback.Persisted:
+		c.logger.WarnKV("Rolled back experiment variant", "experiment", rollback.Experiment, "reason", rollback.Reason)
+		alert = fmt.Sprintf(":rotating_light: Experiment *%s* was rolled back to the control arm: %s. Its conversations are answered by the control arm until its variant is changed in the configuration.",
+			rollback.Experiment, rollback.Reason)
+	default:
+		c.logger.WarnKV("Rolled back experiment variant", "experiment", rollback.Experiment, "reason", rollback.Reason)
+		alert = fmt.Sprintf(":rotating_light: Experiment *%s* was rolled back to the control arm: %s. Its conversations are answered by the control arm until the bot is restarted or its configuration is reloaded, when the variant resumes.",
+			rollback.Experiment, rollback.Reason)
+	}
+	if rollback.AdminChannel != "" {
+		c.sendMessage(rollback.AdminChannel, "", alert)
+	}
+}
+
+// experimentLines summarizes the feedback per experiment arm for the App Home view
+func (c *Client) experimentLines() string {
+	var lines strings.Builder
+	for _, result := range c.experiments.Results() {
+		fmt.Fprintf(&lines, "• *%s* %s: %d answers, %d 👍 %d 👎", result.Experiment, result.Arm, result.Interactions, result.Positive, result.Negative)
+		if result.Positive+result.Negative > 0 {
+			fmt.Fprintf(&lines, " (%.0f%% positive)", 100*result.Score())
+		}
+		if result.Errors > 0 {
+			fmt.Fprintf(&lines, ", %d failed", result.Errors)
+		}
+		lines.WriteString("\n")
+	}
+	for _, rollback := range c.experiments.Rollbacks() {
+		fmt.Fprintf(&lines, "• *%s* variant rolled back at %s: %s\n", rollback.Experiment, rollback.Time.UTC().Format("2006-01-02 15:04 MST"), rollback.Reason)
+	}
+	return lines.String()
+}
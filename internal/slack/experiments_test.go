@@ -0,0 +1,113 @@
+package slackbot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/experiments"
+)
+
+// threadLookup is a frontend that finds the messages of a thread
+type threadLookup struct {
+	*progressRecorder
+	messages map[string]slack.Message
+}
+
+func (l *threadLookup) GetConversationReplies(params *slack.GetConversationRepliesParameters) ([]slack.Message, bool, string, error) {
+	if msg, ok := l.messages[params.Timestamp]; ok {
+		return []slack.Message{msg}, false, "", nil
+	}
+	return nil, false, "", nil
+}
+
+// IsOwnMessage treats B1 as the bot; other bot IDs are other apps
+func (l *threadLookup) IsOwnMessage(msg slack.Message) bool {
+	return msg.BotID == "B1"
+}
+
+func TestFeedbackReactionsScoreExperimentArm(t *testing.T) {
+	client, progress, _ := newProgressTestClient()
+	manager, err := experiments.NewManager([]config.ExperimentConfig{
+		{Name: "concise", Percent: 100, Variant: config.ExperimentVariantConfig{CustomPrompt: "Be brief."}},
+	})
+	require.NoError(t, err)
+	client.experiments = manager
+	client.userFrontend = &threadLookup{progressRecorder: progress, messages: map[string]slack.Message{
+		"100.2": {Msg: slack.Msg{BotID: "B1", Timestamp: "100.2", ThreadTimestamp: "100.1"}},
+		"100.3": {Msg: slack.Msg{User: "U2", Timestamp: "100.3", ThreadTimestamp: "100.1"}},
+		"100.4": {Msg: slack.Msg{BotID: "B2", Timestamp: "100.4", ThreadTimestamp: "100.1"}},
+	}}
+
+	for _, reaction := range []string{"+1::skin-tone-3", "-1", "eyes"} {
+		if positive, ok := client.feedbackSentiment(reaction); ok {
+			client.handleFeedbackReaction("C1", "100.2", "U1", positive)
+		}
+	}
+	// Reactions on users' messages are not feedback on answers
+	client.handleFeedbackReaction("C1", "100.3", "U1", true)
+	// Nor are reactions on the messages of other apps
+	client.handleFeedbackReaction("C1", "100.4", "U1", true)
+
+	results := client.experiments.Results()
+	require.Len(t, results, 2)
+	assert.Equal(t, experiments.Result{Experiment: "concise", Arm: experiments.ArmVariant, Positive: 1, Negative: 1}, results[1])
+	assert.Contains(t, client.experimentLines(), "*concise* variant: 0 answers, 1 👍 1 👎 (50% positive)")
+}
+
+func TestCustomPromptFromExperimentVariant(t *testing.T) {
+	client, _, _ := newProgressTestClient()
+	client.cfg.LLM.CustomPrompt = "Be helpful."
+	assert.Equal(t, "Be helpful.", client.customPrompt(context.Background()))
+
+	control := experiments.WithAssignment(context.Background(), experiments.Assignment{Experiment: "concise", Arm: experiments.ArmControl})
+	assert.Equal(t, "Be helpful.", client.customPrompt(control))
+	variant := experiments.WithAssignment(context.Background(), experiments.Assignment{Experiment: "concise", Arm: experiments.ArmVariant, CustomPrompt: "Be brief."})
+	assert.Equal(t, "Be brief.", client.customPrompt(variant))
+}
+
+// alertRecorder is a frontend that records the messages sent to channels
+type alertRecorder struct {
+	*progressRecorder
+	sent map[string][]string
+}
+
+func (r *alertRecorder) SendMessage(channelID, _, text string) {
+	r.sent[channelID] = append(r.sent[channelID], text)
+}
+
+func TestExperimentRollbackAlertsAdminChannel(t *testing.T) {
+	client, progress, _ := newProgressTestClient()
+	frontend := &alertRecorder{progressRecorder: progress, sent: map[string][]string{}}
+	client.userFrontend = frontend
+	cfg := &config.Config{Experiments: []config.ExperimentConfig{{
+		Name:     "prompt-v2",
+		Percent:  100,
+		Variant:  config.ExperimentVariantConfig{CustomPrompt: "Be brief."},
+		Rollback: config.ExperimentRollbackConfig{Enabled: true, MinSamples: 2, AdminChannel: "C-ADMIN"},
+	}}}
+	cfg.ApplyDefaults()
+	manager, err := experiments.NewManager(cfg.Experiments)
+	require.NoError(t, err)
+	client.experiments = manager
+	client.experiments.SetRollbackHandler(client.handleExperimentRollback)
+
+	assignment, _ := client.experiments.Assign("C1", "100.1")
+	ctx := experiments.WithAssignment(context.Background(), assignment)
+	client.experiments.Assign("C1", "100.1")
+
+	// A prompt stopped by the user is not a failure
+	stopped, cancel := context.WithCancel(ctx)
+	cancel()
+	client.recordPromptError(stopped)
+	assert.Empty(t, frontend.sent)
+
+	client.recordPromptError(ctx)
+	require.Len(t, frontend.sent["C-ADMIN"], 1)
+	assert.Contains(t, frontend.sent["C-ADMIN"][0], "Experiment *prompt-v2* was rolled back to the control arm: 1 of 2 prompts failed")
+	assert.Contains(t, client.experimentLines(), "*prompt-v2* variant rolled back at ")
+}
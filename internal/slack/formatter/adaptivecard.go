@@ -0,0 +1,145 @@
+package formatter
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// AdaptiveCardContentType is the attachment content type of Adaptive Cards
+const AdaptiveCardContentType = "application/vnd.microsoft.card.adaptive"
+
+// fieldPattern matches the "*Title*\nValue" fields of CreateBlockMessage
+var fieldPattern = regexp.MustCompile(`(?s)^\*([^*\n]+)\*\n(.*)$`)
+
+// AdaptiveCard converts a Block Kit message into an Adaptive Card, the rich
+// message format of Microsoft Teams. Headers, sections, fields, context,
+// dividers and images are rendered; link buttons become card actions, and other
+// interactive elements are dropped.
+func AdaptiveCard(blockKit string) (map[string]interface{}, error) {
+	var message struct {
+		Blocks []map[string]interface{} `json:"blocks"`
+	}
+	if err := json.Unmarshal([]byte(blockKit), &message); err != nil {
+		return nil, fmt.Errorf("invalid Block Kit message: %w", err)
+	}
+
+	body := []map[string]interface{}{}
+	actions := []map[string]interface{}{}
+	separator := false
+	add := func(element map[string]interface{}) {
+		if separator {
+			element["separator"] = true
+			separator = false
+		}
+		body = append(body, element)
+	}
+
+	for _, block := range message.Blocks {
+		switch block["type"] {
+		case "header":
+			add(textBlock(blockText(block["text"]), map[string]interface{}{"size": "Large", "weight": "Bolder"}))
+		case "section":
+			if text := blockText(block["text"]); text != "" {
+				add(textBlock(MrkdwnToMarkdown(text), nil))
+			}
+			if fields, ok := block["fields"].([]interface{}); ok {
+				add(fieldsElement(fields))
+			}
+			if accessory, ok := block["accessory"].(map[string]interface{}); ok {
+				if action := buttonAction(accessory); action != nil {
+					actions = append(actions, action)
+				}
+			}
+		case "context":
+			var parts []string
+			elements, _ := block["elements"].([]interface{})
+			for _, element := range elements {
+				if text := blockText(element); text != "" {
+					parts = append(parts, MrkdwnToMarkdown(text))
+				}
+			}
+			if len(parts) > 0 {
+				add(textBlock(strings.Join(parts, " · "), map[string]interface{}{"size": "Small", "isSubtle": true}))
+			}
+		case "divider":
+			separator = true
+		case "image":
+			url, _ := block["image_url"].(string)
+			if url != "" {
+				altText, _ := block["alt_text"].(string)
+				add(map[string]interface{}{"type": "Image", "url": url, "altText": altText})
+			}
+		case "actions":
+			elements, _ := block["elements"].([]interface{})
+			for _, element := range elements {
+				if element, ok := element.(map[string]interface{}); ok {
+					if action := buttonAction(element); action != nil {
+						actions = append(actions, action)
+					}
+				}
+			}
+		}
+	}
+	if len(body) == 0 {
+		return nil, fmt.Errorf("no renderable blocks in Block Kit message")
+	}
+
+	card := map[string]interface{}{
+		"type":    "AdaptiveCard",
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"version": "1.4",
+		"body":    body,
+	}
+	if len(actions) > 0 {
+		card["actions"] = actions
+	}
+	return card, nil
+}
+
+// blockText returns the text of a Block Kit text object
+func blockText(object interface{}) string {
+	textObject, ok := object.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	text, _ := textObject["text"].(string)
+	return text
+}
+
+// textBlock creates an Adaptive Card TextBlock with extra properties
+func textBlock(text string, properties map[string]interface{}) map[string]interface{} {
+	element := map[string]interface{}{"type": "TextBlock", "text": text, "wrap": true}
+	for key, value := range properties {
+		element[key] = value
+	}
+	return element
+}
+
+// fieldsElement renders section fields as a FactSet when they are titled
+// fields, and as a Container of text otherwise
+func fieldsElement(fields []interface{}) map[string]interface{} {
+	facts := []map[string]interface{}{}
+	texts := []map[string]interface{}{}
+	for _, field := range fields {
+		text := blockText(field)
+		if parts := fieldPattern.FindStringSubmatch(text); parts != nil {
+			facts = append(facts, map[string]interface{}{"title": parts[1], "value": MrkdwnToMarkdown(parts[2])})
+		}
+		texts = append(texts, textBlock(MrkdwnToMarkdown(text), nil))
+	}
+	if len(facts) == len(fields) {
+		return map[string]interface{}{"type": "FactSet", "facts": facts}
+	}
+	return map[string]interface{}{"type": "Container", "items": texts}
+}
+
+// buttonAction converts a link button into an Action.OpenUrl
+func buttonAction(element map[string]interface{}) map[string]interface{} {
+	url, _ := element["url"].(string)
+	if element["type"] != "button" || url == "" {
+		return nil
+	}
+	return map[string]interface{}{"type": "Action.OpenUrl", "title": blockText(element["text"]), "url": url}
+}
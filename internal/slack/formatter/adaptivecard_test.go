@@ -0,0 +1,40 @@
+package formatter
+
+import (
+	"testing"
+)
+
+func TestAdaptiveCard(t *testing.T) {
+	message := CreateBlockMessage("Rolled out to *all* regions", BlockOptions{
+		HeaderText: "Deploy",
+		Fields:     []Field{{Title: "Status", Value: "green"}},
+		Actions:    []Action{{Text: "Open", URL: "https://example.com/deploy"}},
+	})
+
+	card, err := AdaptiveCard(message)
+	if err != nil {
+		t.Fatalf("AdaptiveCard() error = %v", err)
+	}
+	body := card["body"].([]map[string]interface{})
+	if len(body) != 3 {
+		t.Fatalf("Expected 3 body elements, got %d: %v", len(body), body)
+	}
+	if body[0]["text"] != "Deploy" || body[0]["weight"] != "Bolder" {
+		t.Errorf("Expected a bold header, got %v", body[0])
+	}
+	facts := body[1]["facts"].([]map[string]interface{})
+	if body[1]["type"] != "FactSet" || facts[0]["title"] != "Status" || facts[0]["value"] != "green" {
+		t.Errorf("Expected the fields as facts, got %v", body[1])
+	}
+	if body[2]["text"] != "Rolled out to **all** regions" {
+		t.Errorf("Expected the section as Markdown, got %v", body[2])
+	}
+	actions := card["actions"].([]map[string]interface{})
+	if len(actions) != 1 || actions[0]["type"] != "Action.OpenUrl" || actions[0]["url"] != "https://example.com/deploy" {
+		t.Errorf("Expected the button as an OpenUrl action, got %v", actions)
+	}
+
+	if _, err := AdaptiveCard(`{"blocks": [{"type": "actions", "elements": []}]}`); err == nil {
+		t.Error("Expected an error for a message without renderable blocks")
+	}
+}
@@ -0,0 +1,147 @@
+package formatter
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// slackLinkPattern matches Slack links, <url|label> or <url>
+	slackLinkPattern = regexp.MustCompile(`<((?:https?|mailto):[^|>\s]+)(?:\|([^>]+))?>`)
+	// slackBoldPattern matches Slack bold text, *text*
+	slackBoldPattern = regexp.MustCompile(`(^|[^*\w])\*([^*\s]|[^*\s][^*\n]*[^*\s])\*([^*\w]|$)`)
+)
+
+// MarkdownLinks rewrites Slack links as Markdown links, for platforms that
+// render Markdown
+func MarkdownLinks(text string) string {
+	return slackLinkPattern.ReplaceAllStringFunc(text, func(link string) string {
+		parts := slackLinkPattern.FindStringSubmatch(link)
+		if parts[2] == "" {
+			return parts[1]
+		}
+		return fmt.Sprintf("[%s](%s)", parts[2], parts[1])
+	})
+}
+
+// MrkdwnToMarkdown converts Slack mrkdwn to Markdown: links and bold text
+func MrkdwnToMarkdown(text string) string {
+	text = MarkdownLinks(text)
+	return slackBoldPattern.ReplaceAllString(text, "$1**$2**$3")
+}
+
+// BlockKitMarkdown renders a Block Kit message as Markdown, for platforms that
+// render Markdown but not Block Kit. Link buttons become links, and other
+// interactive elements are dropped.
+func BlockKitMarkdown(blockKit string) (string, error) {
+	var message struct {
+		Blocks []map[string]interface{} `json:"blocks"`
+	}
+	if err := json.Unmarshal([]byte(blockKit), &message); err != nil {
+		return "", fmt.Errorf("invalid Block Kit message: %w", err)
+	}
+
+	var parts []string
+	for _, block := range message.Blocks {
+		switch block["type"] {
+		case "header":
+			if text := blockText(block["text"]); text != "" {
+				parts = append(parts, "#### "+text)
+			}
+		case "section":
+			if text := blockText(block["text"]); text != "" {
+				parts = append(parts, MrkdwnToMarkdown(text))
+			}
+			if fields, ok := block["fields"].([]interface{}); ok {
+				var lines []string
+				for _, field := range fields {
+					text := blockText(field)
+					if match := fieldPattern.FindStringSubmatch(text); match != nil {
+						lines = append(lines, fmt.Sprintf("**%s**: %s", match[1], MrkdwnToMarkdown(match[2])))
+					} else if text != "" {
+						lines = append(lines, MrkdwnToMarkdown(text))
+					}
+				}
+				if len(lines) > 0 {
+					parts = append(parts, strings.Join(lines, "\n"))
+				}
+			}
+			if accessory, ok := block["accessory"].(map[string]interface{}); ok {
+				if link := buttonLink(accessory); link != "" {
+					parts = append(parts, link)
+				}
+			}
+		case "context":
+			var texts []string
+			elements, _ := block["elements"].([]interface{})
+			for _, element := range elements {
+				if text := blockText(element); text != "" {
+					texts = append(texts, MrkdwnToMarkdown(text))
+				}
+			}
+			if len(texts) > 0 {
+				parts = append(parts, "_"+strings.Join(texts, " · ")+"_")
+			}
+		case "divider":
+			parts = append(parts, "---")
+		case "image":
+			if url, _ := block["image_url"].(string); url != "" {
+				altText, _ := block["alt_text"].(string)
+				parts = append(parts, fmt.Sprintf("![%s](%s)", altText, url))
+			}
+		case "actions":
+			var links []string
+			elements, _ := block["elements"].([]interface{})
+			for _, element := range elements {
+				if element, ok := element.(map[string]interface{}); ok {
+					if link := buttonLink(element); link != "" {
+						links = append(links, link)
+					}
+				}
+			}
+			if len(links) > 0 {
+				parts = append(parts, strings.Join(links, " · "))
+			}
+		}
+	}
+	if len(parts) == 0 {
+		return "", fmt.Errorf("no renderable blocks in Block Kit message")
+	}
+	return strings.Join(parts, "\n\n"), nil
+}
+
+// buttonLink renders a link button as a Markdown link
+func buttonLink(element map[string]interface{}) string {
+	action := buttonAction(element)
+	if action == nil {
+		return ""
+	}
+	return fmt.Sprintf("[%s](%s)", action["title"], action["url"])
+}
+
+// SplitMessage splits text into messages of at most limit characters,
+// preferring to split at line breaks
+func SplitMessage(text string, limit int) []string {
+	var chunks []string
+	runes := []rune(text)
+	for len(runes) > limit {
+		cut := limit
+		for i := cut - 1; i > 0; i-- {
+			if runes[i] == '\n' {
+				cut = i
+				break
+			}
+		}
+		chunks = append(chunks, string(runes[:cut]))
+		for cut < len(runes) && runes[cut] == '\n' {
+			cut++
+		}
+		runes = runes[cut:]
+	}
+	if len(runes) > 0 {
+		chunks = append(chunks, string(runes))
+	}
+	return chunks
+}
@@ -0,0 +1,57 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMrkdwnToMarkdown(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"plain", "plain"},
+		{"*bold* text", "**bold** text"},
+		{"see <https://example.com|docs> or <https://example.org>", "see [docs](https://example.com) or https://example.org"},
+		{"2 * 3 * 4", "2 * 3 * 4"},
+	}
+	for _, tt := range tests {
+		if result := MrkdwnToMarkdown(tt.input); result != tt.expected {
+			t.Errorf("MrkdwnToMarkdown(%q) = %q, want %q", tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestBlockKitMarkdown(t *testing.T) {
+	message := CreateBlockMessage("Rolled out to *all* regions", BlockOptions{
+		HeaderText: "Deploy",
+		Fields:     []Field{{Title: "Status", Value: "green"}, {Title: "Version", Value: "1.2.3"}},
+		Actions:    []Action{{Text: "Open", URL: "https://example.com/deploy"}},
+	})
+
+	markdown, err := BlockKitMarkdown(message)
+	if err != nil {
+		t.Fatalf("BlockKitMarkdown() error = %v", err)
+	}
+	for _, want := range []string{"#### Deploy", "**Status**: green", "Rolled out to **all** regions", "[Open](https://example.com/deploy)"} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("Expected %q in:\n%s", want, markdown)
+		}
+	}
+}
+
+func TestSplitMessage(t *testing.T) {
+	if chunks := SplitMessage("short", 10); len(chunks) != 1 || chunks[0] != "short" {
+		t.Errorf("SplitMessage() = %q, want one chunk", chunks)
+	}
+
+	chunks := SplitMessage(strings.Repeat("x", 15), 10)
+	if len(chunks) != 2 || len(chunks[0]) != 10 || len(chunks[1]) != 5 {
+		t.Errorf("SplitMessage() = %q, want chunks of 10 and 5", chunks)
+	}
+
+	chunks = SplitMessage("first line\nsecond", 15)
+	if len(chunks) != 2 || chunks[0] != "first line" || chunks[1] != "second" {
+		t.Errorf("SplitMessage() = %q, want a split at the line break", chunks)
+	}
+}
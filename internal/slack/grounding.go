@@ -0,0 +1,216 @@
+package slackbot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tmc/langchaingo/callbacks"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/llm"
+	"github.com/tuannvm/slack-mcp-client/internal/monitoring"
+)
+
+// Sources of checked answers, used in traces and metrics
+const (
+	groundingSourceTool = "tool"
+	groundingSourceRAG  = "rag"
+)
+
+// groundingCheckPrompt asks whether an answer is supported by its sources
+const groundingCheckPrompt = "You check answers of a Slack assistant for hallucinations. Decide whether every factual claim " +
+	"in the answer below is supported by the sources. General knowledge, greetings and suggestions to double-check " +
+	"are fine; facts such as names, numbers, dates, commands or statuses that the sources do not contain are not. " +
+	"Reply with only a JSON object: {\"grounded\": true or false, \"unsupported\": [the unsupported claims, quoted briefly]}.\n\n" +
+	"Question: %s\n\nSources:\n```\n%s\n```\n\nAnswer:\n```\n%s\n```"
+
+// groundingVerdictSchema constrains the check's reply in structured output mode
+var groundingVerdictSchema = &llm.ResponseSchema{
+	Name:        "grounding_verdict",
+	Description: "whether the answer is supported by the sources",
+	Strict:      true,
+	Schema: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"grounded":    map[string]interface{}{"type": "boolean"},
+			"unsupported": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+		},
+		"required":             []string{"grounded", "unsupported"},
+		"additionalProperties": false,
+	},
+}
+
+// groundingVerdict is the result of a grounding check
+type groundingVerdict struct {
+	Grounded    bool     `json:"grounded"`
+	Unsupported []string `json:"unsupported"`
+}
+
+// String describes the verdict in traces
+func (v groundingVerdict) String() string {
+	if v.Grounded {
+		return "grounded"
+	}
+	if len(v.Unsupported) == 0 {
+		return "ungrounded"
+	}
+	return "ungrounded: " + strings.Join(v.Unsupported, "; ")
+}
+
+// toolOutputRecorder collects the tool results of an agent run, which are the
+// sources its answer is checked against
+type toolOutputRecorder struct {
+	callbacks.SimpleHandler
+
+	mu      sync.Mutex
+	outputs []string
+}
+
+func (r *toolOutputRecorder) HandleToolEnd(_ context.Context, output string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.outputs = append(r.outputs, output)
+}
+
+// sources returns the collected tool results
+func (r *toolOutputRecorder) sources() string {
+	if r == nil {
+		return ""
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return strings.Join(r.outputs, "\n\n")
+}
+
+// newToolOutputRecorder returns a recorder when agent answers are checked
+func (c *Client) newToolOutputRecorder() *toolOutputRecorder {
+	if !c.cfg.LLM.Grounding.Enabled {
+		return nil
+	}
+	return &toolOutputRecorder{}
+}
+
+// groundAnswer checks an answer against the sources it was synthesized from and
+// applies llm.grounding.action when it is not supported by them: the answer is
+// regenerated once with the unsupported claims pointed out, and posted with a
+// warning if it is still ungrounded. regenerate may be nil when the answer cannot
+// be regenerated. When the check fails the answer is posted as is.
+func (c *Client) groundAnswer(ctx context.Context, source, question, sources, answer string, regenerate func(feedback string) (string, error)) string {
+	groundingCfg := c.cfg.LLM.Grounding
+	if !groundingCfg.Enabled || c.llmRegistry == nil || strings.TrimSpace(answer) == "" || strings.TrimSpace(sources) == "" {
+		return answer
+	}
+
+	verdict, err := c.checkGrounding(ctx, source, question, sources, answer)
+	if err != nil || verdict.Grounded {
+		return answer
+	}
+
+	if groundingCfg.Action == config.GroundingActionRegenerate && regenerate != nil {
+		c.logger.InfoKV("Answer is not supported by its sources, regenerating", "source", source, "unsupported", verdict.Unsupported)
+		regenerated, err := regenerate(groundingFeedback(verdict))
+		if err != nil {
+			c.logger.WarnKV("Failed to regenerate ungrounded answer", "source", source, "error", err)
+		} else if strings.TrimSpace(regenerated) != "" {
+			answer = regenerated
+			verdict, err = c.checkGrounding(ctx, source, question, sources, answer)
+			if err != nil || verdict.Grounded {
+				return answer
+			}
+		}
+	}
+
+	c.logger.InfoKV("Posting answer with a grounding warning", "source", source, "unsupported", verdict.Unsupported)
+	return answer + "\n\n_" + groundingCfg.Warning + "_"
+}
+
+// checkGrounding asks the grounding model whether the answer is supported by the
+// sources, recording the verdict in a trace span and in metrics
+func (c *Client) checkGrounding(ctx context.Context, source, question, sources, answer string) (groundingVerdict, error) {
+	groundingCfg := c.cfg.LLM.Grounding
+	model := groundingCfg.Model
+	if model == "" {
+		model = c.cfg.LLM.Providers[groundingCfg.Provider].Model
+	}
+	prompt := fmt.Sprintf(groundingCheckPrompt, question, truncateSources(sources, groundingCfg.MaxSourceLength), answer)
+
+	checkCtx, span := c.tracingHandler.StartLLMSpan(ctx, "llm-grounding-check", model, prompt, map[string]interface{}{
+		"source": source,
+	})
+	defer span.End()
+	options := llm.ProviderOptions{Model: groundingCfg.Model, Temperature: 0, MaxTokens: 300}
+	if c.cfg.LLM.StructuredOutput {
+		options.ResponseSchema = groundingVerdictSchema
+	}
+	startTime := time.Now()
+	response, err := c.llmRegistry.GenerateCompletion(checkCtx, groundingCfg.Provider, prompt, options)
+	c.tracingHandler.SetDuration(span, time.Since(startTime))
+	var verdict groundingVerdict
+	if err == nil {
+		verdict, err = parseGroundingVerdict(response.Content)
+	}
+	if err != nil {
+		c.logger.WarnKV("Grounding check failed, posting the answer unchecked", "source", source, "error", err)
+		monitoring.GroundingChecks.WithLabelValues(source, "error").Inc()
+		c.tracingHandler.RecordError(span, err, "WARNING")
+		return verdict, err
+	}
+
+	outcome := "grounded"
+	if !verdict.Grounded {
+		outcome = "ungrounded"
+	}
+	monitoring.GroundingChecks.WithLabelValues(source, outcome).Inc()
+	span.SetAttributes(
+		attribute.String("grounding.verdict", outcome),
+		attribute.Int("grounding.unsupported_claims", len(verdict.Unsupported)),
+	)
+	c.tracingHandler.SetOutput(span, verdict.String())
+	c.tracingHandler.RecordSuccess(span, "Grounding check completed")
+	return verdict, nil
+}
+
+// parseGroundingVerdict decodes the check's reply. A reply without a JSON verdict
+// is read as a plain "yes" or "no".
+func parseGroundingVerdict(content string) (groundingVerdict, error) {
+	var reply struct {
+		Grounded    *bool    `json:"grounded"`
+		Unsupported []string `json:"unsupported"`
+	}
+	if err := llm.DecodeJSON(content, &reply); err == nil && reply.Grounded != nil {
+		return groundingVerdict{Grounded: *reply.Grounded, Unsupported: reply.Unsupported}, nil
+	}
+	word := strings.ToLower(strings.TrimSpace(content))
+	if fields := strings.Fields(word); len(fields) > 0 {
+		word = strings.Trim(fields[0], "\"'*`.,!:")
+	}
+	switch word {
+	case "yes", "grounded":
+		return groundingVerdict{Grounded: true}, nil
+	case "no", "ungrounded":
+		return groundingVerdict{}, nil
+	}
+	return groundingVerdict{}, fmt.Errorf("unexpected grounding check reply: %q", truncateSources(content, 100))
+}
+
+// groundingFeedback asks for an answer without the unsupported claims
+func groundingFeedback(verdict groundingVerdict) string {
+	feedback := "A review found that your previous answer made claims that the sources do not support"
+	if len(verdict.Unsupported) > 0 {
+		feedback += ":\n- " + strings.Join(verdict.Unsupported, "\n- ")
+	}
+	return feedback + "\n\nAnswer again using only information from the sources, and say so when they do not contain something."
+}
+
+// truncateSources cuts sources to at most max characters
+func truncateSources(sources string, max int) string {
+	runes := []rune(sources)
+	if max <= 0 || len(runes) <= max {
+		return sources
+	}
+	return string(runes[:max]) + "\n[...]"
+}
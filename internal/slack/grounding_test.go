@@ -0,0 +1,75 @@
+package slackbot
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+func TestParseGroundingVerdict(t *testing.T) {
+	verdict, err := parseGroundingVerdict(`{"grounded": true, "unsupported": []}`)
+	require.NoError(t, err)
+	assert.True(t, verdict.Grounded)
+
+	verdict, err = parseGroundingVerdict("```json\n{\"grounded\": false, \"unsupported\": [\"the deploy runs at 3pm\"]}\n```")
+	require.NoError(t, err)
+	assert.False(t, verdict.Grounded)
+	assert.Equal(t, []string{"the deploy runs at 3pm"}, verdict.Unsupported)
+	assert.Equal(t, "ungrounded: the deploy runs at 3pm", verdict.String())
+
+	verdict, err = parseGroundingVerdict("Yes.")
+	require.NoError(t, err)
+	assert.True(t, verdict.Grounded)
+
+	verdict, err = parseGroundingVerdict("no")
+	require.NoError(t, err)
+	assert.False(t, verdict.Grounded)
+
+	_, err = parseGroundingVerdict("not sure")
+	assert.Error(t, err)
+	_, err = parseGroundingVerdict(`{"unsupported": []}`)
+	assert.Error(t, err)
+}
+
+func TestGroundingFeedback(t *testing.T) {
+	feedback := groundingFeedback(groundingVerdict{Unsupported: []string{"owner is Ana", "SLA is 99.99%"}})
+	assert.Contains(t, feedback, "- owner is Ana\n- SLA is 99.99%")
+	assert.Contains(t, feedback, "using only information from the sources")
+	assert.NotContains(t, groundingFeedback(groundingVerdict{}), ":\n-")
+}
+
+func TestTruncateSources(t *testing.T) {
+	assert.Equal(t, "short", truncateSources("short", 10))
+	assert.Equal(t, "ééé\n[...]", truncateSources("éééééé", 3))
+	assert.Equal(t, "anything", truncateSources("anything", 0))
+}
+
+func TestGroundAnswerSkipsUncheckableAnswers(t *testing.T) {
+	c := &Client{cfg: &config.Config{}}
+	assert.Equal(t, "answer", c.groundAnswer(context.Background(), groundingSourceTool, "q", "sources", "answer", nil))
+
+	c.cfg.LLM.Grounding.Enabled = true
+	regenerate := func(string) (string, error) {
+		t.Fatal("An unchecked answer must not be regenerated")
+		return "", nil
+	}
+	assert.Equal(t, "answer", c.groundAnswer(context.Background(), groundingSourceRAG, "q", strings.Repeat(" ", 3), "answer", regenerate))
+}
+
+func TestToolOutputRecorderCollectsAgentSources(t *testing.T) {
+	c := &Client{cfg: &config.Config{}}
+	assert.Nil(t, c.newToolOutputRecorder())
+	assert.Empty(t, c.newToolOutputRecorder().sources())
+
+	c.cfg.LLM.Grounding.Enabled = true
+	recorder := c.newToolOutputRecorder()
+	require.NotNil(t, recorder)
+	recorder.HandleToolEnd(context.Background(), "owner: team-a")
+	recorder.HandleToolEnd(context.Background(), "status: healthy")
+	assert.Equal(t, "owner: team-a\n\nstatus: healthy", recorder.sources())
+}
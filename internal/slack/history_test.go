@@ -0,0 +1,161 @@
+package slackbot
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/tokens"
+	"github.com/tuannvm/slack-mcp-client/internal/hooks"
+	"github.com/tuannvm/slack-mcp-client/pkg/middleware"
+)
+
+// roles returns the roles of a thread's history, in order
+func roles(history []Message) []string {
+	result := make([]string, len(history))
+	for i, msg := range history {
+		result[i] = msg.Role
+	}
+	return result
+}
+
+func TestHistoryEvictsToolResultsFirst(t *testing.T) {
+	client, _, _ := newProgressTestClient()
+	client.historyLimit = 50
+	client.messageHistory = map[string][]Message{}
+	client.cfg.Slack.HistoryTokens.MaxTokens = 1000
+
+	large := strings.Repeat("alert firing on host ", 100) // About 400 tokens
+	client.addToHistory("C1", "100.1", "100.1", "user", "list the alerts", "U1", "", "")
+	client.addToHistory("C1", "100.1", "", "tool", large, "", "", "")
+	client.addToHistory("C1", "100.1", "100.2", "assistant", "Two alerts are firing.", "", "", "")
+	client.addToHistory("C1", "100.1", "100.3", "user", "and the logs?", "U1", "", "")
+	client.addToHistory("C1", "100.1", "", "tool", large, "", "", "")
+	assert.Equal(t, []string{"user", "tool", "assistant", "user", "tool"}, roles(client.messageHistory[historyKey("C1", "100.1")]))
+
+	// The third result goes over budget, which evicts the oldest tool result
+	// while the short messages around it stay
+	client.addToHistory("C1", "100.1", "", "tool", large, "", "", "")
+	history := client.messageHistory[historyKey("C1", "100.1")]
+	assert.Equal(t, []string{"user", "assistant", "user", "tool", "tool"}, roles(history))
+	assert.Equal(t, "list the alerts", history[0].Content)
+	assert.Equal(t, tokens.Count(large), history[4].Tokens)
+}
+
+func TestHistoryRoleWeights(t *testing.T) {
+	client, _, _ := newProgressTestClient()
+	client.historyLimit = 50
+	client.messageHistory = map[string][]Message{}
+	client.cfg.Slack.HistoryTokens.MaxTokens = 100
+	client.cfg.Slack.HistoryTokens.RoleWeights = map[string]float64{"assistant": 0}
+
+	// Weightless answers never count against the budget, so the oldest prompts go
+	answer := strings.Repeat("word ", 200)
+	prompt := strings.Repeat("word ", 40)
+	client.addToHistory("C1", "100.1", "100.1", "user", prompt, "U1", "", "")
+	client.addToHistory("C1", "100.1", "100.2", "assistant", answer, "", "", "")
+	client.addToHistory("C1", "100.1", "100.3", "user", prompt, "U1", "", "")
+	client.addToHistory("C1", "100.1", "100.4", "assistant", answer, "", "", "")
+	client.addToHistory("C1", "100.1", "100.5", "user", prompt, "U1", "", "")
+	history := client.messageHistory[historyKey("C1", "100.1")]
+	require.Len(t, history, 4)
+	assert.Equal(t, []string{"assistant", "user", "assistant", "user"}, roles(history))
+	assert.Equal(t, "100.5", history[3].SlackTimestamp)
+
+	// The newest message is kept even when it alone is over budget
+	client.addToHistory("C1", "100.1", "", "tool", answer, "", "", "")
+	history = client.messageHistory[historyKey("C1", "100.1")]
+	assert.Equal(t, []string{"assistant", "assistant", "tool"}, roles(history))
+
+	// A disabled budget only limits the number of messages
+	client.cfg.Slack.HistoryTokens.MaxTokens = -1
+	client.addToHistory("C1", "100.1", "", "tool", answer, "", "", "")
+	assert.Len(t, client.messageHistory[historyKey("C1", "100.1")], 4)
+}
+
+func TestToolResultsAreStructuredEntries(t *testing.T) {
+	client, _, _ := newProgressTestClient()
+	client.historyLimit = 3
+	client.messageHistory = map[string][]Message{}
+	client.toolResults = newToolResults()
+	client.cfg.Slack.ToolHistory.MaxResultChars = 20
+
+	args := map[string]interface{}{"service": "api", "since": "1h"}
+	client.addToolResult("C1", "100.1", "list_alerts", args, "HighLatency on api\nErrorRate on api")
+	client.addToolResult("C1", "100.1", "get_status", nil, "ok")
+	history := client.messageHistory[historyKey("C1", "100.1")]
+	require.Len(t, history, 2)
+
+	// The long result is truncated and its full text can be fetched
+	entry := history[0].Tool
+	require.NotNil(t, entry)
+	assert.Equal(t, "list_alerts", entry.Name)
+	assert.Equal(t, hashToolArgs(map[string]interface{}{"since": "1h", "service": "api"}), entry.ArgsHash)
+	assert.Equal(t, "HighLatency on api\nE", entry.Result)
+	assert.Equal(t, entry.Result, history[0].Content)
+	assert.Equal(t, "r1", entry.ResultID)
+	assert.False(t, history[1].Tool.Truncated())
+
+	// Results reach the LLM as tool messages, not as conversation text
+	assert.NotContains(t, client.contextFromMessages("C1", history), "HighLatency")
+	messages := toolResultMessages(history)
+	require.Len(t, messages, 2)
+	assert.Equal(t, middleware.Message{
+		Role:       "tool",
+		Content:    "HighLatency on api\nE\n[truncated from 35 characters; call get_tool_result with {\"id\": \"r1\"} for the full result]",
+		ToolName:   "list_alerts",
+		ToolArgs:   `{"service":"api","since":"1h"}`,
+		ToolCallID: "history_0",
+	}, messages[0])
+	assert.Equal(t, "ok", messages[1].Content)
+	assert.Equal(t, "{}", messages[1].ToolArgs)
+
+	// Full results are looked up in the request's conversation; arguments naming
+	// another one are ignored
+	inThread := hooks.ContextWithConversation(t.Context(), "C1", "100.1")
+	full, err := client.toolResults.CallTool(inThread, toolResultToolName, map[string]interface{}{"id": "r1", "channel_id": "C2"})
+	require.NoError(t, err)
+	assert.Equal(t, "HighLatency on api\nErrorRate on api", full)
+	otherThread := hooks.ContextWithConversation(t.Context(), "C2", "100.1")
+	missing, err := client.toolResults.CallTool(otherThread, toolResultToolName, map[string]interface{}{"id": "r1", "channel_id": "C1", "thread_ts": "100.1"})
+	require.NoError(t, err)
+	assert.Contains(t, missing, "No result with id")
+	_, err = client.toolResults.CallTool(t.Context(), toolResultToolName, map[string]interface{}{"id": "r1", "channel_id": "C1", "thread_ts": "100.1"})
+	assert.Error(t, err)
+
+	// A full result is dropped once its entry leaves the history
+	client.addToHistory("C1", "100.1", "100.2", "user", "thanks", "U1", "", "")
+	client.addToHistory("C1", "100.1", "100.3", "user", "one more", "U1", "", "")
+	_, ok := client.toolResults.get(historyKey("C1", "100.1"), "r1")
+	assert.False(t, ok)
+}
+
+func TestHistoryIsSafeForConcurrentThreads(t *testing.T) {
+	client, _, _ := newProgressTestClient()
+	client.historyLimit = 20
+	client.messageHistory = map[string][]Message{}
+	client.cfg.Slack.ToolHistory.DiffRepeated = true
+
+	// A retry or edit in a thread runs while other messages are added to the history
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ts := fmt.Sprintf("100.%d", i)
+			for j := 0; j < 50; j++ {
+				client.addToHistory("C1", "100.1", ts, "user", "how many pods are crashlooping?", "U1", "", "")
+				client.replaceHistoryContent("C1", "100.1", ts, "how many pods are pending?")
+				client.isLatestPrompt("C1", "100.1", ts, "U1")
+				client.historyBefore("C1", "100.1", ts)
+				client.diffToolResult("C1", "100.1", "list_pods", nil, "none")
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.Len(t, client.threadHistory("C1", "100.1"), 20)
+}
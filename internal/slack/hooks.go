@@ -0,0 +1,48 @@
+package slackbot
+
+import (
+	"context"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/hooks"
+)
+
+// Replies used when a hook denies a message or response without giving a reason
+const (
+	hookDeniedMessage  = "Sorry, I can't help with that request."
+	hookDeniedResponse = "Sorry, I can't share that response."
+)
+
+// runMessageHooks runs the message_received hooks on the user's prompt. It returns
+// the prompt to answer, or false after telling the user the message was denied.
+func (c *Client) runMessageHooks(ctx context.Context, userPrompt, channelID, threadTS string) (string, bool) {
+	if !c.hooks.Handles(config.HookEventMessageReceived) {
+		return userPrompt, true
+	}
+	result := c.hooks.Run(ctx, hooks.Payload{Event: config.HookEventMessageReceived, Text: userPrompt})
+	if result.Denied {
+		message := result.Message
+		if message == "" {
+			message = hookDeniedMessage
+		}
+		c.sendMessage(channelID, threadTS, message)
+		return "", false
+	}
+	return result.Text, true
+}
+
+// runResponseHooks runs the after_response hooks on a response and returns the
+// text to post in its place
+func (c *Client) runResponseHooks(ctx context.Context, response string) string {
+	if !c.hooks.Handles(config.HookEventAfterResponse) {
+		return response
+	}
+	result := c.hooks.Run(ctx, hooks.Payload{Event: config.HookEventAfterResponse, Text: response})
+	if result.Denied {
+		if result.Message != "" {
+			return result.Message
+		}
+		return hookDeniedResponse
+	}
+	return result.Text
+}
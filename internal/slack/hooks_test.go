@@ -0,0 +1,81 @@
+package slackbot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/hooks"
+)
+
+func TestMessageAndResponseHooks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload hooks.Payload
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		switch {
+		case payload.Text == "drop tables":
+			_, _ = fmt.Fprint(w, `{"action": "deny"}`)
+		case payload.Event == config.HookEventAfterResponse:
+			_, _ = fmt.Fprint(w, `{"action": "modify", "text": "[reviewed] `+payload.Text+`"}`)
+		}
+	}))
+	defer server.Close()
+
+	client, _, output := newProgressTestClient()
+	client.hooks = hooks.NewRunner([]config.HookConfig{{
+		Name:    "policy",
+		Events:  []string{config.HookEventMessageReceived, config.HookEventAfterResponse},
+		URL:     server.URL,
+		Timeout: "5s",
+		OnError: config.HookOnErrorAllow,
+	}}, client.logger)
+
+	prompt, allowed := client.runMessageHooks(context.Background(), "show alerts", "C1", "100.1")
+	assert.True(t, allowed)
+	assert.Equal(t, "show alerts", prompt)
+
+	_, allowed = client.runMessageHooks(context.Background(), "drop tables", "C1", "100.1")
+	assert.False(t, allowed)
+	assert.Contains(t, output.String(), hookDeniedMessage)
+
+	assert.Equal(t, "[reviewed] Two alerts are firing.", client.runResponseHooks(context.Background(), "Two alerts are firing."))
+}
+
+func TestAgentAnswerRunsResponseHooksOnce(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload hooks.Payload
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		calls.Add(1)
+		_, _ = fmt.Fprint(w, `{"action": "modify", "text": "[reviewed] `+payload.Text+`"}`)
+	}))
+	defer server.Close()
+
+	client, frontend, _ := newProgressTestClient()
+	client.cfg.Slack.IntermediateMessages.Retention = config.IntermediateDelete
+	client.hooks = hooks.NewRunner([]config.HookConfig{{
+		Name:    "policy",
+		Events:  []string{config.HookEventAfterResponse},
+		URL:     server.URL,
+		Timeout: "5s",
+		OnError: config.HookOnErrorAllow,
+	}}, client.logger)
+
+	// The agent sends its answer as its last message, then the run posts it
+	ctx := client.showThinking(context.Background(), "C1", "100.1")
+	steps := client.newAgentSteps("C1")
+	for _, msg := range []string{"Thought: check alerts", "Two alerts are firing."} {
+		client.sendAgentStep(ctx, steps, "C1", "100.1", client.filterAgentStep(ctx, steps, "C1", "100.1", "U1", msg))
+	}
+	client.finishAgentSteps(ctx, steps, "C1", "100.1", "U1", "Two alerts are firing.")
+
+	assert.Equal(t, int32(2), calls.Load())
+	assert.Equal(t, []string{"111.1 [reviewed] Two alerts are firing."}, frontend.replaced)
+}
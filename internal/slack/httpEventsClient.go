@@ -0,0 +1,264 @@
+package slackbot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/monitoring"
+)
+
+// maxEventBodyBytes bounds the size of an Events API request body
+const maxEventBodyBytes = 1 << 20
+
+// HTTPEventsClient is a UserFrontend that receives events through the Slack
+// Events API over HTTP instead of Socket Mode. Events are verified with the
+// app's signing secret and fed into the same event channel used by Socket Mode,
+// so the dispatch pipeline is shared. Outbound calls reuse SlackClient.
+type HTTPEventsClient struct {
+	*SlackClient
+	signingSecret string
+	server        *http.Server
+	events        chan socketmode.Event
+}
+
+// GetSlackHTTPClient creates an HTTP Events API frontend
+func GetSlackHTTPClient(slackCfg config.SlackConfig, stdLogger *logging.Logger) (*HTTPEventsClient, error) {
+	if slackCfg.SigningSecret == "" {
+		return nil, fmt.Errorf("SLACK_SIGNING_SECRET must be set in http mode")
+	}
+
+	slackClient, err := newSlackClient(slackCfg.BotToken, "", stdLogger, slackCfg.ThinkingMessage, slackCfg.Outbound)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient := &HTTPEventsClient{
+		SlackClient:   slackClient,
+		signingSecret: slackCfg.SigningSecret,
+		events:        make(chan socketmode.Event, 50),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(slackCfg.HTTP.EventsPath, httpClient.handleEventsRequest)
+	mux.HandleFunc(slackCfg.HTTP.InteractivityPath, httpClient.handleInteractivityRequest)
+	mux.HandleFunc(slackCfg.HTTP.CommandsPath, httpClient.handleCommandRequest)
+	httpClient.server = &http.Server{
+		Addr:              slackCfg.HTTP.ListenAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	return httpClient, nil
+}
+
+// Run starts the HTTP listener and blocks until it stops
+func (h *HTTPEventsClient) Run() error {
+	h.logger.InfoKV("Starting Slack Events API HTTP listener", "addr", h.server.Addr)
+	if err := h.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("slack events http server failed: %w", err)
+	}
+	return nil
+}
+
+// Close stops the HTTP listener and drains the outbound queue
+func (h *HTTPEventsClient) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := h.server.Shutdown(ctx)
+	if closeErr := h.SlackClient.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Ack is a no-op: HTTP events are acknowledged when the request is answered
+func (h *HTTPEventsClient) Ack(_ socketmode.Request, _ ...interface{}) {}
+
+// GetEventChannel returns the channel verified events are delivered on
+func (h *HTTPEventsClient) GetEventChannel() chan socketmode.Event {
+	return h.events
+}
+
+// handleEventsRequest verifies and dispatches a single Events API request
+func (h *HTTPEventsClient) handleEventsRequest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxEventBodyBytes))
+	if err != nil {
+		h.logger.WarnKV("Failed to read Events API request body", "error", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err := verifySlackSignature(r.Header, body, h.signingSecret); err != nil {
+		h.logger.WarnKV("Rejected Events API request with invalid signature", "remote", r.RemoteAddr, "error", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	// The signature has been verified, so the deprecated verification token is not checked
+	eventsAPIEvent, err := slackevents.ParseEvent(json.RawMessage(body), slackevents.OptionNoVerifyToken())
+	if err != nil {
+		h.logger.WarnKV("Failed to parse Events API request", "error", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	switch eventsAPIEvent.Type {
+	case slackevents.URLVerification:
+		var challenge slackevents.EventsAPIURLVerificationEvent
+		if err := json.Unmarshal(body, &challenge); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(challenge.Challenge))
+		h.logger.Info("Answered Events API URL verification challenge")
+	case slackevents.CallbackEvent:
+		if retryNum := r.Header.Get("X-Slack-Retry-Num"); retryNum != "" {
+			h.logger.DebugKV("Received Events API retry", "retry_num", retryNum, "reason", r.Header.Get("X-Slack-Retry-Reason"))
+		}
+		// Slack retries events that are not acknowledged within 3 seconds, so the
+		// ack is written before the event is queued and a full queue drops it
+		w.WriteHeader(http.StatusOK)
+		queueEvent(h.events, socketmode.Event{
+			Type:    socketmode.EventTypeEventsAPI,
+			Data:    eventsAPIEvent,
+			Request: &socketmode.Request{Type: socketmode.RequestTypeEventsAPI},
+		}, h.logger)
+	default:
+		h.logger.DebugKV("Ignored Events API request type", "type", eventsAPIEvent.Type)
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// handleInteractivityRequest verifies and dispatches a single interactivity
+// request, sent when a user clicks a button in one of the bot's messages
+func (h *HTTPEventsClient) handleInteractivityRequest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxEventBodyBytes))
+	if err != nil {
+		h.logger.WarnKV("Failed to read interactivity request body", "error", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err := verifySlackSignature(r.Header, body, h.signingSecret); err != nil {
+		h.logger.WarnKV("Rejected interactivity request with invalid signature", "remote", r.RemoteAddr, "error", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	// The payload is a JSON document in the "payload" form field
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	var callback slack.InteractionCallback
+	if err := json.Unmarshal([]byte(form.Get("payload")), &callback); err != nil {
+		h.logger.WarnKV("Failed to parse interactivity request", "error", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// Slack shows the user an error unless the interaction is acknowledged within 3 seconds
+	w.WriteHeader(http.StatusOK)
+	queueEvent(h.events, socketmode.Event{
+		Type:    socketmode.EventTypeInteractive,
+		Data:    callback,
+		Request: &socketmode.Request{Type: socketmode.RequestTypeInteractive},
+	}, h.logger)
+}
+
+// handleCommandRequest verifies and dispatches a single slash command request
+func (h *HTTPEventsClient) handleCommandRequest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxEventBodyBytes))
+	if err != nil {
+		h.logger.WarnKV("Failed to read slash command request body", "error", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err := verifySlackSignature(r.Header, body, h.signingSecret); err != nil {
+		h.logger.WarnKV("Rejected slash command request with invalid signature", "remote", r.RemoteAddr, "error", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	// The command is sent as form fields
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		h.logger.WarnKV("Failed to parse slash command request", "error", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	command := slack.SlashCommand{
+		TeamID:      form.Get("team_id"),
+		ChannelID:   form.Get("channel_id"),
+		ChannelName: form.Get("channel_name"),
+		UserID:      form.Get("user_id"),
+		UserName:    form.Get("user_name"),
+		Command:     form.Get("command"),
+		Text:        form.Get("text"),
+		ResponseURL: form.Get("response_url"),
+		TriggerID:   form.Get("trigger_id"),
+		APIAppID:    form.Get("api_app_id"),
+	}
+
+	// Slack shows the user an error unless the command is acknowledged within 3 seconds
+	w.WriteHeader(http.StatusOK)
+	queueEvent(h.events, socketmode.Event{
+		Type:    socketmode.EventTypeSlashCommand,
+		Data:    command,
+		Request: &socketmode.Request{Type: socketmode.RequestTypeSlashCommands},
+	}, h.logger)
+}
+
+// queueEvent hands an acknowledged event to the handlers without blocking, so a
+// backlog of events cannot hold up the acknowledgement of the next request.
+// Events that arrive while the queue is full are dropped and counted.
+func queueEvent(events chan socketmode.Event, evt socketmode.Event, logger *logging.Logger) {
+	select {
+	case events <- evt:
+	default:
+		logger.WarnKV("Dropped event, the event queue is full", "type", evt.Type)
+		monitoring.SlackEventsDropped.WithLabelValues(string(evt.Type)).Inc()
+	}
+}
+
+// verifySlackSignature checks the X-Slack-Signature header against the request body
+func verifySlackSignature(header http.Header, body []byte, signingSecret string) error {
+	verifier, err := slack.NewSecretsVerifier(header, signingSecret)
+	if err != nil {
+		return err
+	}
+	if _, err := verifier.Write(body); err != nil {
+		return err
+	}
+	return verifier.Ensure()
+}
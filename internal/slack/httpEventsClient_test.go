@@ -0,0 +1,177 @@
+package slackbot
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/socketmode"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+)
+
+const testSigningSecret = "test-secret"
+
+func newTestHTTPEventsClient() *HTTPEventsClient {
+	return &HTTPEventsClient{
+		SlackClient:   &SlackClient{logger: logging.New("test", logging.LevelError)},
+		signingSecret: testSigningSecret,
+		events:        make(chan socketmode.Event, 1),
+	}
+}
+
+func signedRequest(body, secret string) *http.Request {
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(fmt.Sprintf("v0:%s:%s", ts, body)))
+
+	req := httptest.NewRequest(http.MethodPost, "/slack/events", strings.NewReader(body))
+	req.Header.Set("X-Slack-Request-Timestamp", ts)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+func TestHTTPEventsURLVerification(t *testing.T) {
+	client := newTestHTTPEventsClient()
+	rec := httptest.NewRecorder()
+
+	client.handleEventsRequest(rec, signedRequest(`{"type":"url_verification","challenge":"abc123","token":"x"}`, testSigningSecret))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "abc123", rec.Body.String())
+}
+
+func TestHTTPEventsRejectsBadSignature(t *testing.T) {
+	client := newTestHTTPEventsClient()
+	rec := httptest.NewRecorder()
+
+	client.handleEventsRequest(rec, signedRequest(`{"type":"url_verification","challenge":"abc123"}`, "wrong-secret"))
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Len(t, client.events, 0)
+}
+
+func TestHTTPEventsDispatchesCallback(t *testing.T) {
+	client := newTestHTTPEventsClient()
+	rec := httptest.NewRecorder()
+	body := `{"type":"event_callback","event_id":"Ev1","event":{"type":"app_mention","user":"U1","text":"<@UBOT> hi","channel":"C1","ts":"1.2"}}`
+
+	client.handleEventsRequest(rec, signedRequest(body, testSigningSecret))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	if assert.Len(t, client.events, 1) {
+		evt := <-client.events
+		assert.Equal(t, socketmode.EventTypeEventsAPI, evt.Type)
+		assert.NotNil(t, evt.Request)
+	}
+}
+
+func TestHTTPInteractivityDispatchesBlockActions(t *testing.T) {
+	client := newTestHTTPEventsClient()
+	rec := httptest.NewRecorder()
+	payload := `{"type":"block_actions","user":{"id":"U1"},"channel":{"id":"C1"},"actions":[{"block_id":"b1","action_id":"cancel_request","value":"100.2"}]}`
+
+	client.handleInteractivityRequest(rec, signedRequest("payload="+url.QueryEscape(payload), testSigningSecret))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	if assert.Len(t, client.events, 1) {
+		evt := <-client.events
+		assert.Equal(t, socketmode.EventTypeInteractive, evt.Type)
+		callback, ok := evt.Data.(slack.InteractionCallback)
+		if assert.True(t, ok) {
+			assert.Equal(t, slack.InteractionTypeBlockActions, callback.Type)
+			assert.Equal(t, "U1", callback.User.ID)
+			assert.Equal(t, "cancel_request", callback.ActionCallback.BlockActions[0].ActionID)
+		}
+	}
+}
+
+func TestHTTPCommandDispatchesSlashCommand(t *testing.T) {
+	client := newTestHTTPEventsClient()
+	rec := httptest.NewRecorder()
+	form := url.Values{"command": {"/mcp"}, "text": {"memory list"}, "user_id": {"U1"}, "channel_id": {"C1"}, "response_url": {"https://hooks.slack.com/commands/1"}}
+
+	client.handleCommandRequest(rec, signedRequest(form.Encode(), testSigningSecret))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	if assert.Len(t, client.events, 1) {
+		evt := <-client.events
+		assert.Equal(t, socketmode.EventTypeSlashCommand, evt.Type)
+		command, ok := evt.Data.(slack.SlashCommand)
+		if assert.True(t, ok) {
+			assert.Equal(t, "/mcp", command.Command)
+			assert.Equal(t, "memory list", command.Text)
+			assert.Equal(t, "U1", command.UserID)
+		}
+	}
+}
+
+func TestHTTPEventIsAcknowledgedWhenTheQueueIsFull(t *testing.T) {
+	client := newTestHTTPEventsClient()
+	client.events <- socketmode.Event{Type: socketmode.EventTypeEventsAPI}
+	rec := httptest.NewRecorder()
+	body := `{"type":"event_callback","event_id":"Ev1","event":{"type":"app_mention","user":"U1","text":"<@UBOT> hi","channel":"C1","ts":"1.2"}}`
+
+	done := make(chan struct{})
+	go func() {
+		client.handleEventsRequest(rec, signedRequest(body, testSigningSecret))
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the event was not acknowledged while the queue was full")
+	}
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Len(t, client.events, 1, "the event is dropped")
+}
+
+func TestHTTPCommandIsAcknowledgedWhenTheQueueIsFull(t *testing.T) {
+	client := newTestHTTPEventsClient()
+	client.events <- socketmode.Event{Type: socketmode.EventTypeEventsAPI}
+	rec := httptest.NewRecorder()
+	form := url.Values{"command": {"/mcp"}, "text": {"help"}, "user_id": {"U1"}, "channel_id": {"C1"}}
+
+	done := make(chan struct{})
+	go func() {
+		client.handleCommandRequest(rec, signedRequest(form.Encode(), testSigningSecret))
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the command was not acknowledged while the queue was full")
+	}
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Len(t, client.events, 1, "the command is dropped")
+}
+
+func TestHTTPInteractivityIsAcknowledgedWhenTheQueueIsFull(t *testing.T) {
+	client := newTestHTTPEventsClient()
+	client.events <- socketmode.Event{Type: socketmode.EventTypeEventsAPI}
+	rec := httptest.NewRecorder()
+	payload := `{"type":"block_actions","user":{"id":"U1"},"channel":{"id":"C1"},"actions":[{"action_id":"cancel_request","value":"100.2"}]}`
+
+	done := make(chan struct{})
+	go func() {
+		client.handleInteractivityRequest(rec, signedRequest("payload="+url.QueryEscape(payload), testSigningSecret))
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the interaction was not acknowledged while the queue was full")
+	}
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Len(t, client.events, 1, "the interaction is dropped")
+}
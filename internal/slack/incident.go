@@ -0,0 +1,442 @@
+package slackbot
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/llm"
+	"github.com/tuannvm/slack-mcp-client/internal/rag"
+)
+
+const (
+	// incidentCommand starts the commands that control incident mode in a channel
+	incidentCommand = "incident"
+	// incidentIngestTimeout bounds storing one incident message in the knowledge base
+	incidentIngestTimeout = 30 * time.Second
+	// incidentContextResults is how many incident messages are retrieved for a question
+	incidentContextResults = 8
+	// timelineTextLimit is how much of a message is kept in a timeline entry
+	timelineTextLimit = 300
+)
+
+// defaultPostmortemPrompt instructs the LLM when incidents.postmortemPrompt is not set
+const defaultPostmortemPrompt = `You draft blameless postmortems from incident channels.
+Using the timeline and the channel messages below, write a postmortem draft in markdown with these sections:
+Summary, Impact, Timeline (UTC), Root cause, Resolution, What went well, What went wrong, Action items (with owners when known).
+Only state what the messages support. Mark anything that is unknown or needs confirmation with "TODO".`
+
+const incidentHelp = "Incident commands: `incident start [title]`, `incident timeline`, `incident postmortem` and `incident end`."
+
+// timelineEntry is a key message of an incident
+type timelineEntry struct {
+	ts     string
+	at     time.Time
+	user   string // Display name of the author
+	text   string
+	reason string // Why the message is on the timeline: start, keyword, reaction or end
+}
+
+// incident is the state of an incident channel
+type incident struct {
+	channelID string
+	title     string
+	started   time.Time
+	timeline  []timelineEntry
+}
+
+// incidentTracker keeps the incidents running in incident channels. Incidents
+// are started with "incident start" or, in channels whose name matches the
+// channel pattern, by the first message.
+type incidentTracker struct {
+	now      func() time.Time
+	keywords []*regexp.Regexp
+	pattern  *regexp.Regexp       // nil when incidents are only started by command
+	api      ConversationFrontend // Resolves channel names for the pattern (nil when it cannot)
+
+	mu     sync.Mutex
+	active map[string]*incident            // By channel ID
+	ended  map[string]bool                 // Channels whose incident was ended, which the pattern does not restart
+	names  map[string]cachedLookup[string] // Channel names by ID; failed lookups are retried after conversationRetryAfter
+
+	ingestMu sync.Mutex // Stores incident messages in the knowledge base one at a time
+}
+
+// newIncidentTracker compiles the incident configuration
+func newIncidentTracker(cfg config.SlackIncidentsConfig, api ConversationFrontend) (*incidentTracker, error) {
+	t := &incidentTracker{
+		now:    time.Now,
+		api:    api,
+		active: make(map[string]*incident),
+		ended:  make(map[string]bool),
+		names:  make(map[string]cachedLookup[string]),
+	}
+	for _, keyword := range cfg.TimelineKeywords {
+		t.keywords = append(t.keywords, regexp.MustCompile(`(?i)\b`+regexp.QuoteMeta(keyword)+`\b`))
+	}
+	if cfg.ChannelPattern != "" {
+		pattern, err := regexp.Compile(cfg.ChannelPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid incident channel pattern: %w", err)
+		}
+		t.pattern = pattern
+	}
+	return t, nil
+}
+
+// start starts an incident in the channel. It reports false, with the running
+// incident, when one is already running.
+func (t *incidentTracker) start(channelID, title string) (incident, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if running, ok := t.active[channelID]; ok {
+		return *running, false
+	}
+	started := &incident{channelID: channelID, title: title, started: t.now()}
+	t.active[channelID] = started
+	delete(t.ended, channelID)
+	return *started, true
+}
+
+// end ends the channel's incident and returns it
+func (t *incidentTracker) end(channelID string) (incident, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	running, ok := t.active[channelID]
+	if !ok {
+		return incident{}, false
+	}
+	delete(t.active, channelID)
+	t.ended[channelID] = true
+	return *running, true
+}
+
+// get returns a copy of the channel's running incident
+func (t *incidentTracker) get(channelID string) (incident, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	running, ok := t.active[channelID]
+	if !ok {
+		return incident{}, false
+	}
+	snapshot := *running
+	snapshot.timeline = append([]timelineEntry(nil), running.timeline...)
+	return snapshot, true
+}
+
+// record adds a key message to the channel's timeline, in timestamp order. It
+// reports false when there is no incident or the message is already on it.
+func (t *incidentTracker) record(channelID string, entry timelineEntry) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	running, ok := t.active[channelID]
+	if !ok {
+		return false
+	}
+	for _, existing := range running.timeline {
+		if existing.ts == entry.ts {
+			return false
+		}
+	}
+	if text := []rune(entry.text); len(text) > timelineTextLimit {
+		entry.text = string(text[:timelineTextLimit]) + "…"
+	}
+	running.timeline = append(running.timeline, entry)
+	sort.SliceStable(running.timeline, func(i, j int) bool {
+		return tsAfter(running.timeline[j].ts, running.timeline[i].ts)
+	})
+	return true
+}
+
+// isKeyMessage reports whether a message belongs on the timeline by its words
+func (t *incidentTracker) isKeyMessage(text string) bool {
+	for _, keyword := range t.keywords {
+		if keyword.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+// running returns the channel's incident, starting one when the channel's name
+// matches the pattern and its incident was not ended by command
+func (t *incidentTracker) running(channelID string) (incident, bool) {
+	if running, ok := t.get(channelID); ok || t.pattern == nil {
+		return running, ok
+	}
+	t.mu.Lock()
+	ended := t.ended[channelID]
+	t.mu.Unlock()
+	if ended {
+		return incident{}, false
+	}
+	name := t.channelName(channelID)
+	if name == "" || !t.pattern.MatchString(name) {
+		return incident{}, false
+	}
+	running, _ := t.start(channelID, "#"+name)
+	return running, true
+}
+
+// channelName returns the channel's name, looked up once, or "" when unknown.
+// A failed lookup is not retried for conversationRetryAfter, so messages in a
+// channel the bot cannot look up do not each cost an API call.
+func (t *incidentTracker) channelName(channelID string) string {
+	t.mu.Lock()
+	cached, ok := t.names[channelID]
+	t.mu.Unlock()
+	if (ok && (cached.expires.IsZero() || !t.now().After(cached.expires))) || t.api == nil {
+		return cached.value
+	}
+	lookup := cachedLookup[string]{}
+	channel, err := t.api.GetConversationInfo(&slack.GetConversationInfoInput{ChannelID: channelID})
+	if err != nil {
+		lookup.expires = t.now().Add(conversationRetryAfter)
+	} else {
+		lookup.value = channel.Name
+	}
+	t.mu.Lock()
+	t.names[channelID] = lookup
+	t.mu.Unlock()
+	return lookup.value
+}
+
+// incidentNamespace is the knowledge base namespace holding an incident channel's messages
+func incidentNamespace(channelID string) string {
+	return "incident-" + channelID
+}
+
+// formatTimeline renders a timeline as one line per entry
+func formatTimeline(entries []timelineEntry) string {
+	var timeline strings.Builder
+	for _, entry := range entries {
+		timeline.WriteString(fmt.Sprintf("• %s UTC, %s: %s\n", entry.at.UTC().Format("Jan 2 15:04"), entry.user, entry.text))
+	}
+	return timeline.String()
+}
+
+// handleIncidentCommand handles "incident start [title]", "incident end",
+// "incident timeline" and "incident postmortem". It reports whether the message
+// was an incident command.
+func (c *Client) handleIncidentCommand(text, channelID, threadTS, userID string) bool {
+	if c.incidents == nil {
+		return false
+	}
+	fields := strings.Fields(strings.TrimSpace(text))
+	if len(fields) < 2 || strings.ToLower(fields[0]) != incidentCommand {
+		return false
+	}
+	action := strings.ToLower(fields[1])
+	if action != "start" && action != "end" && action != "timeline" && action != "postmortem" {
+		return false // A question about incidents rather than a command
+	}
+	if result := c.cfg.ValidateAccessWithDirectory(userID, channelID, c.security); !result.Allowed {
+		c.logger.WarnKV("Denied incident command", "user", userID, "channel", channelID, "action", action, "reason", result.Reason)
+		if c.cfg.Security.RejectionMessage != "" {
+			c.sendMessage(channelID, threadTS, c.cfg.Security.RejectionMessage)
+		}
+		return true
+	}
+	profile, err := c.userFrontend.GetUserInfo(userID)
+	if err != nil {
+		profile = &UserProfile{userId: userID, realName: userID}
+	}
+
+	switch action {
+	case "start":
+		title := strings.Join(fields[2:], " ")
+		if title == "" {
+			title = "Incident in <#" + channelID + ">"
+		}
+		started, ok := c.incidents.start(channelID, title)
+		if !ok {
+			c.sendMessage(channelID, threadTS, fmt.Sprintf("🚨 Incident mode is already on here: *%s*.", started.title))
+			return true
+		}
+		c.incidents.record(channelID, timelineEntry{ts: threadTS, at: started.started, user: profile.realName, text: "Incident started: " + title, reason: "start"})
+		c.logger.InfoKV("Incident mode started", "channel", channelID, "user", userID, "title", title)
+		c.sendMessage(channelID, threadTS, fmt.Sprintf("🚨 Incident mode is on: *%s*. I'll keep a timeline of key messages and messages reacted to with :%s:. Ask me about the incident anytime. %s",
+			title, c.cfg.Slack.Incidents.TimelineReaction, incidentHelp))
+	case "end":
+		c.incidents.record(channelID, timelineEntry{ts: threadTS, at: time.Now(), user: profile.realName, text: "Incident ended", reason: "end"})
+		ended, ok := c.incidents.end(channelID)
+		if !ok {
+			c.sendMessage(channelID, threadTS, "There is no incident running here.")
+			return true
+		}
+		c.logger.InfoKV("Incident mode ended", "channel", channelID, "user", userID, "title", ended.title)
+		c.sendMessage(channelID, threadTS, fmt.Sprintf("✅ Incident mode is off: *%s*, after %s.\n\n*Timeline*\n%s",
+			ended.title, time.Since(ended.started).Round(time.Minute), formatTimeline(ended.timeline)))
+	case "timeline":
+		running, ok := c.incidents.get(channelID)
+		if !ok {
+			c.sendMessage(channelID, threadTS, "There is no incident running here.")
+			return true
+		}
+		c.sendMessage(channelID, threadTS, fmt.Sprintf("*Timeline of %s*\n%s", running.title, formatTimeline(running.timeline)))
+	case "postmortem":
+		running, ok := c.incidents.get(channelID)
+		if !ok {
+			c.sendMessage(channelID, threadTS, "There is no incident running here. Start one with `incident start`.")
+			return true
+		}
+		go c.draftPostmortem(running, threadTS)
+	}
+	return true
+}
+
+// trackIncidentMessage adds a message of an incident channel to the timeline when
+// it is a key message, and stores it in the incident's knowledge base namespace
+func (c *Client) trackIncidentMessage(channelID, ts, userID, text string) {
+	if c.incidents == nil || strings.TrimSpace(text) == "" {
+		return
+	}
+	running, ok := c.incidents.running(channelID)
+	if !ok {
+		return
+	}
+	name := userID
+	if profile, err := c.userFrontend.GetUserInfo(userID); err == nil && profile.realName != "" {
+		name = profile.realName
+	}
+	at := slackTime(ts)
+	if c.incidents.isKeyMessage(text) && c.incidents.record(channelID, timelineEntry{ts: ts, at: at, user: name, text: text, reason: "keyword"}) {
+		c.logger.DebugKV("Added message to incident timeline", "channel", channelID, "ts", ts, "reason", "keyword")
+	}
+	c.ingestIncidentMessage(running, ts, at, name, text)
+}
+
+// handleTimelineReaction adds the message a user reacted to with the timeline
+// reaction to the channel's incident timeline
+func (c *Client) handleTimelineReaction(channelID, ts string) {
+	if c.incidents == nil {
+		return
+	}
+	if _, ok := c.incidents.get(channelID); !ok {
+		return
+	}
+	frontend, ok := c.userFrontend.(DigestFrontend)
+	if !ok {
+		return
+	}
+	resp, err := frontend.GetConversationHistory(&slack.GetConversationHistoryParameters{ChannelID: channelID, Latest: ts, Oldest: ts, Inclusive: true, Limit: 1})
+	if err != nil || len(resp.Messages) == 0 {
+		c.logger.WarnKV("Failed to fetch the message reacted to for the incident timeline", "channel", channelID, "ts", ts, "error", err)
+		return
+	}
+	msg := resp.Messages[0]
+	name := msg.User
+	if profile, err := c.userFrontend.GetUserInfo(msg.User); err == nil && profile.realName != "" {
+		name = profile.realName
+	}
+	if c.incidents.record(channelID, timelineEntry{ts: ts, at: slackTime(ts), user: name, text: msg.Text, reason: "reaction"}) {
+		c.logger.DebugKV("Added message to incident timeline", "channel", channelID, "ts", ts, "reason", "reaction")
+	}
+}
+
+// ingestIncidentMessage stores a message in the incident's knowledge base
+// namespace, so questions can be answered from the whole channel
+func (c *Client) ingestIncidentMessage(running incident, ts string, at time.Time, name, text string) {
+	if c.ragClient == nil {
+		return
+	}
+	ingester, ok := c.ragClient.GetProvider().(rag.DocumentIngester)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), incidentIngestTimeout)
+	defer cancel()
+	c.incidents.ingestMu.Lock()
+	defer c.incidents.ingestMu.Unlock()
+	source := fmt.Sprintf("slack://%s/%s", running.channelID, ts)
+	content := fmt.Sprintf("[%s UTC] %s: %s", at.UTC().Format("Jan 2 15:04"), name, text)
+	metadata := map[string]string{rag.NamespaceMetadataKey: incidentNamespace(running.channelID), "channel": running.channelID, "ts": ts}
+	if _, err := ingester.IngestDocument(ctx, source, running.title, content, metadata); err != nil {
+		c.logger.WarnKV("Failed to store incident message in the knowledge base", "channel", running.channelID, "ts", ts, "error", err)
+	}
+}
+
+// incidentContext returns what the LLM knows about the channel's incident when
+// answering a question in it: the timeline, and the channel messages most
+// relevant to the question. It returns ctx scoped to the incident's namespace.
+func (c *Client) incidentContext(ctx context.Context, channelID, question string) (context.Context, string) {
+	if c.incidents == nil {
+		return ctx, ""
+	}
+	running, ok := c.incidents.get(channelID)
+	if !ok {
+		return ctx, ""
+	}
+	var background strings.Builder
+	background.WriteString(fmt.Sprintf("This channel is handling an incident: %s, started %s UTC.\nTimeline of key messages:\n%s",
+		running.title, running.started.UTC().Format("Jan 2 15:04"), formatTimeline(running.timeline)))
+	if c.ragClient != nil {
+		ctx = rag.WithNamespace(ctx, incidentNamespace(channelID))
+		results, err := c.ragClient.Retrieve(ctx, question)
+		if err != nil {
+			c.logger.WarnKV("Failed to search incident messages", "channel", channelID, "error", err)
+		}
+		if len(results) > incidentContextResults {
+			results = results[:incidentContextResults]
+		}
+		if len(results) > 0 {
+			background.WriteString("Channel messages related to the question:\n")
+			for _, result := range results {
+				background.WriteString(result.Content + "\n")
+			}
+		}
+	}
+	return ctx, background.String() + "\n"
+}
+
+// draftPostmortem asks the LLM for a postmortem of the incident and publishes it
+// as a canvas shared with the channel, or as a message when canvases are not
+// available
+func (c *Client) draftPostmortem(running incident, threadTS string) {
+	ctx := context.Background()
+	c.sendMessage(running.channelID, threadTS, "📝 Drafting the postmortem...")
+	var transcript string
+	if _, ok := c.userFrontend.(DigestFrontend); ok {
+		messages, err := c.channelHistory(running.channelID, running.started, c.cfg.Slack.Incidents.MaxMessages)
+		if err != nil {
+			c.logger.WarnKV("Failed to read incident channel history", "channel", running.channelID, "error", err)
+		}
+		transcript = c.digestTranscript(messages, time.UTC)
+	}
+
+	prompt := c.cfg.Slack.Incidents.PostmortemPrompt
+	if prompt == "" {
+		prompt = defaultPostmortemPrompt
+	}
+	providerCfg := c.cfg.LLM.Providers[c.cfg.LLM.Provider]
+	response, err := c.llmRegistry.GenerateChatCompletion(ctx, c.cfg.LLM.Provider, []llm.RequestMessage{
+		{Role: "system", Content: prompt},
+		{Role: "user", Content: fmt.Sprintf("Incident: %s\nStarted: %s UTC\n\nTimeline:\n%s\nChannel messages:\n%s",
+			running.title, running.started.UTC().Format("2006-01-02 15:04"), formatTimeline(running.timeline), transcript)},
+	}, llm.ProviderOptions{
+		Temperature: providerCfg.Temperature,
+		MaxTokens:   providerCfg.MaxTokens,
+	})
+	if err != nil || strings.TrimSpace(response.Content) == "" {
+		c.logger.ErrorKV("Failed to draft postmortem", "channel", running.channelID, "error", err)
+		c.sendMessage(running.channelID, threadTS, "Sorry, I could not draft the postmortem.")
+		return
+	}
+	draft := strings.TrimSpace(response.Content)
+
+	link, err := c.publishCanvas("Postmortem draft: "+running.title, draft, running.channelID)
+	if err != nil {
+		c.logger.WarnKV("Failed to create postmortem canvas, posting the draft instead", "channel", running.channelID, "error", err)
+		c.sendMessage(running.channelID, threadTS, "*Postmortem draft*\n\n"+draft)
+		return
+	}
+	c.logger.InfoKV("Posted postmortem draft", "channel", running.channelID, "title", running.title)
+	c.sendMessage(running.channelID, threadTS, fmt.Sprintf("📝 The postmortem draft is ready: <%s|open the canvas>", link))
+}
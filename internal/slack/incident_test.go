@@ -0,0 +1,135 @@
+package slackbot
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+func newIncidentTestClient(t *testing.T, pattern string, api ConversationFrontend) (*Client, *bytes.Buffer) {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Slack.Incidents.Enabled = true
+	cfg.Slack.Incidents.ChannelPattern = pattern
+	cfg.ApplyDefaults()
+	incidents, err := newIncidentTracker(cfg.Slack.Incidents, api)
+	require.NoError(t, err)
+
+	logger := logging.New("test", logging.LevelError)
+	output := &bytes.Buffer{}
+	stdio := NewStdioClient(logger)
+	stdio.Output = output
+	return &Client{cfg: cfg, userFrontend: stdio, logger: logger, incidents: incidents}, output
+}
+
+func TestIncidentCommands(t *testing.T) {
+	client, output := newIncidentTestClient(t, "", nil)
+
+	assert.False(t, client.handleIncidentCommand("what happened?", "C1", "1.0", "U1"))
+	assert.False(t, client.handleIncidentCommand("incident reports from last week?", "C1", "1.0", "U1"))
+
+	assert.True(t, client.handleIncidentCommand("incident start API errors", "C1", "100.0", "U1"))
+	assert.Contains(t, output.String(), "Incident mode is on: *API errors*")
+	assert.True(t, client.handleIncidentCommand("incident start again", "C1", "101.0", "U1"))
+	assert.Contains(t, output.String(), "already on here: *API errors*")
+
+	// Key messages are added to the timeline, other messages are not
+	client.trackIncidentMessage("C1", "120.0", "U2", "Rolled back the deploy, errors are dropping")
+	client.trackIncidentMessage("C1", "110.0", "U2", "looking at the dashboards")
+	client.trackIncidentMessage("C1", "130.0", "U2", "Errors mitigated")
+	client.trackIncidentMessage("C2", "140.0", "U2", "resolved")
+	running, ok := client.incidents.get("C1")
+	require.True(t, ok)
+	var texts []string
+	for _, entry := range running.timeline {
+		texts = append(texts, entry.text)
+	}
+	assert.Equal(t, []string{"Incident started: API errors", "Rolled back the deploy, errors are dropping", "Errors mitigated"}, texts)
+
+	_, background := client.incidentContext(context.Background(), "C1", "what changed?")
+	assert.Contains(t, background, "handling an incident: API errors")
+	assert.Contains(t, background, "Errors mitigated")
+	_, background = client.incidentContext(context.Background(), "C2", "what changed?")
+	assert.Empty(t, background)
+
+	output.Reset()
+	assert.True(t, client.handleIncidentCommand("incident end", "C1", "200.0", "U1"))
+	assert.Contains(t, output.String(), "Incident mode is off: *API errors*")
+	assert.Contains(t, output.String(), "Incident ended")
+	_, ok = client.incidents.get("C1")
+	assert.False(t, ok)
+	assert.True(t, client.handleIncidentCommand("incident timeline", "C1", "201.0", "U1"))
+	assert.Contains(t, output.String(), "There is no incident running here.")
+}
+
+func TestIncidentTimelineIgnoresDuplicates(t *testing.T) {
+	tracker, err := newIncidentTracker(config.SlackIncidentsConfig{}, nil)
+	require.NoError(t, err)
+	tracker.start("C1", "outage")
+	assert.True(t, tracker.record("C1", timelineEntry{ts: "1.0", text: "declared"}))
+	assert.False(t, tracker.record("C1", timelineEntry{ts: "1.0", text: "declared"}))
+	assert.False(t, tracker.record("C2", timelineEntry{ts: "1.0", text: "declared"}), "no incident in the channel")
+
+	// Long messages are cut on a character boundary
+	assert.True(t, tracker.record("C1", timelineEntry{ts: "2.0", text: strings.Repeat("é", timelineTextLimit+1)}))
+	running, _ := tracker.get("C1")
+	assert.Equal(t, strings.Repeat("é", timelineTextLimit)+"…", running.timeline[1].text)
+}
+
+func TestIncidentCommandsNeedAccess(t *testing.T) {
+	client, output := newIncidentTestClient(t, "", nil)
+	client.cfg.Security.Enabled = true
+	client.cfg.Security.AllowedUsers = []string{"U1"}
+	client.cfg.ApplyDefaults()
+
+	assert.True(t, client.handleIncidentCommand("incident start API errors", "C1", "100.0", "U2"))
+	assert.Contains(t, output.String(), client.cfg.Security.RejectionMessage)
+	_, ok := client.incidents.get("C1")
+	assert.False(t, ok, "denied users cannot start incidents")
+
+	assert.True(t, client.handleIncidentCommand("incident start API errors", "C1", "100.0", "U1"))
+	_, ok = client.incidents.get("C1")
+	assert.True(t, ok)
+}
+
+func TestIncidentChannelPattern(t *testing.T) {
+	api := &fakeConversations{channels: map[string]*slack.Channel{
+		"C1": {GroupConversation: slack.GroupConversation{Name: "inc-2024-db"}},
+		"C2": {GroupConversation: slack.GroupConversation{Name: "general"}},
+	}}
+	client, _ := newIncidentTestClient(t, "^inc-", api)
+	now := time.Now()
+	client.incidents.now = func() time.Time { return now }
+
+	running, ok := client.incidents.running("C1")
+	require.True(t, ok)
+	assert.Equal(t, "#inc-2024-db", running.title)
+	_, ok = client.incidents.running("C2")
+	assert.False(t, ok)
+	client.incidents.running("C1")
+	client.incidents.running("C2")
+	assert.Equal(t, 2, api.lookups, "channel names are looked up once")
+
+	// Failed lookups are cached too, until they are retried
+	_, ok = client.incidents.running("C3")
+	assert.False(t, ok)
+	client.incidents.running("C3")
+	assert.Equal(t, 3, api.lookups)
+	now = now.Add(conversationRetryAfter + time.Second)
+	client.incidents.running("C3")
+	assert.Equal(t, 4, api.lookups)
+
+	// Ending the incident by command stops the pattern from restarting it
+	assert.True(t, client.handleIncidentCommand("incident end", "C1", "1.0", "U1"))
+	_, ok = client.incidents.running("C1")
+	assert.False(t, ok)
+}
@@ -0,0 +1,85 @@
+package slackbot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+// agentSteps holds the intermediate messages of an agent run in a channel that
+// does not keep them
+type agentSteps struct {
+	retention string
+
+	mu    sync.Mutex
+	steps []string
+	// The last message of the agent, before and after moderation and the
+	// after_response hooks. The agent sends its answer as its last message, which
+	// finishAgentSteps then posts without filtering it again.
+	lastRaw, lastFiltered string
+}
+
+// newAgentSteps starts tracking the intermediate messages of an agent run
+func (c *Client) newAgentSteps(channelID string) *agentSteps {
+	return &agentSteps{retention: c.cfg.Slack.IntermediateMessages.RetentionForChannel(channelID)}
+}
+
+// filterAgentStep moderates an agent message and runs the after_response hooks on it
+func (c *Client) filterAgentStep(ctx context.Context, steps *agentSteps, channelID, threadTS, userID, msg string) string {
+	filtered, _ := c.moderate(ctx, moderationOutput, msg, channelID, threadTS, userID)
+	filtered = c.runResponseHooks(ctx, filtered)
+	steps.mu.Lock()
+	steps.lastRaw, steps.lastFiltered = msg, filtered
+	steps.mu.Unlock()
+	return filtered
+}
+
+// sendAgentStep posts an intermediate agent message. Unless the channel keeps
+// them, the step is only shown in the placeholder until the answer replaces it.
+func (c *Client) sendAgentStep(ctx context.Context, steps *agentSteps, channelID, threadTS, msg string) {
+	if steps.retention == config.IntermediateKeep {
+		c.reply(ctx, channelID, threadTS, c.postProcess(channelID, msg, false))
+		return
+	}
+	steps.mu.Lock()
+	steps.steps = append(steps.steps, msg)
+	steps.mu.Unlock()
+	c.showStatus(ctx, channelID, threadTS, c.cfg.Slack.Assistant.ThinkingStatus, msg)
+}
+
+// finishAgentSteps posts the answer of an agent run whose steps were not kept.
+// With the collapse retention, the steps follow the answer as a single message.
+// When the steps were kept, the answer was already sent as the last of them.
+func (c *Client) finishAgentSteps(ctx context.Context, steps *agentSteps, channelID, threadTS, userID, answer string) {
+	if steps.retention == config.IntermediateKeep {
+		return
+	}
+	steps.mu.Lock()
+	filtered, sent := steps.lastFiltered, steps.lastRaw == answer
+	collapsed := collapseSteps(steps.steps)
+	steps.mu.Unlock()
+	if !sent {
+		filtered = c.filterAgentStep(ctx, steps, channelID, threadTS, userID, answer)
+	}
+	c.reply(ctx, channelID, threadTS, c.postProcess(channelID, filtered, false))
+
+	if steps.retention == config.IntermediateCollapse && collapsed != "" {
+		c.reply(ctx, channelID, threadTS, c.postProcess(channelID, collapsed, false))
+	}
+}
+
+// collapseSteps joins intermediate messages into one quoted message
+func collapseSteps(steps []string) string {
+	if len(steps) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "_Intermediate steps (%d):_", len(steps))
+	for i, step := range steps {
+		fmt.Fprintf(&b, "\n>*%d.* %s", i+1, strings.ReplaceAll(strings.TrimSpace(step), "\n", "\n>"))
+	}
+	return b.String()
+}
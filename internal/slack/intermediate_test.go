@@ -0,0 +1,50 @@
+package slackbot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+func TestAgentStepsRetention(t *testing.T) {
+	t.Run("keep", func(t *testing.T) {
+		client, frontend, output := newProgressTestClient()
+		ctx := client.showThinking(context.Background(), "C1", "100.1")
+		steps := client.newAgentSteps("C1")
+		client.sendAgentStep(ctx, steps, "C1", "100.1", "Thought: check alerts")
+		client.sendAgentStep(ctx, steps, "C1", "100.1", "AI: Two alerts are firing.")
+		client.finishAgentSteps(ctx, steps, "C1", "100.1", "U1", "Two alerts are firing.")
+
+		assert.Equal(t, []string{"111.1 Thought: check alerts"}, frontend.replaced)
+		assert.Contains(t, output.String(), "AI: Two alerts are firing.")
+		assert.Empty(t, frontend.updates)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		client, frontend, output := newProgressTestClient()
+		client.cfg.Slack.IntermediateMessages.Retention = config.IntermediateDelete
+		ctx := client.showThinking(context.Background(), "C1", "100.1")
+		steps := client.newAgentSteps("C1")
+		client.sendAgentStep(ctx, steps, "C1", "100.1", "Thought: check alerts")
+		client.finishAgentSteps(ctx, steps, "C1", "100.1", "U1", "Two alerts are firing.")
+
+		assert.Equal(t, []string{"Thought: check alerts"}, frontend.updates)
+		assert.Equal(t, []string{"111.1 Two alerts are firing."}, frontend.replaced)
+		assert.Empty(t, output.String())
+	})
+
+	t.Run("collapse per channel", func(t *testing.T) {
+		client, frontend, output := newProgressTestClient()
+		client.cfg.Slack.IntermediateMessages.Channels = map[string]string{"C2": config.IntermediateCollapse}
+		ctx := client.showThinking(context.Background(), "C2", "100.1")
+		steps := client.newAgentSteps("C2")
+		client.sendAgentStep(ctx, steps, "C2", "100.1", "Thought: check alerts\nAction: list_alerts")
+		client.finishAgentSteps(ctx, steps, "C2", "100.1", "U1", "Two alerts are firing.")
+
+		assert.Equal(t, []string{"111.1 Two alerts are firing."}, frontend.replaced)
+		assert.Contains(t, output.String(), "_Intermediate steps (1):_\n>*1.* Thought: check alerts\n>Action: list_alerts")
+	})
+}
@@ -0,0 +1,172 @@
+package slackbot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tuannvm/slack-mcp-client/internal/hooks"
+	"github.com/tuannvm/slack-mcp-client/internal/jobs"
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+	"github.com/tuannvm/slack-mcp-client/internal/monitoring"
+)
+
+const (
+	// jobPollInterval is how often due jobs are looked for
+	jobPollInterval = 30 * time.Second
+	// maxJobResultChars bounds the part of a job result posted in the thread
+	maxJobResultChars = 3000
+)
+
+// startJobs runs the scheduled tool calls when they are due until stopJobs is called
+func (c *Client) startJobs() {
+	if c.jobs == nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.jobsCancel = cancel
+	go func() {
+		ticker := time.NewTicker(jobPollInterval)
+		defer ticker.Stop()
+		for {
+			c.runDueJobs(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// stopJobs stops running scheduled tool calls
+func (c *Client) stopJobs() {
+	if c.jobsCancel != nil {
+		c.jobsCancel()
+	}
+}
+
+// runDueJobs runs the jobs that are due, each in its own goroutine
+func (c *Client) runDueJobs(ctx context.Context) {
+	due, err := c.jobs.TakeDue(time.Now())
+	if err != nil {
+		c.logger.ErrorKV("Failed to save the jobs after taking the due ones", "error", err)
+	}
+	monitoring.ScheduledJobsPending.Set(float64(c.jobs.Len()))
+	for _, job := range due {
+		go c.runJob(ctx, job)
+	}
+}
+
+// runJob calls the tool of a job as the user who scheduled it and posts the result
+// in the thread it was scheduled from
+func (c *Client) runJob(ctx context.Context, job jobs.Job) {
+	if result := c.cfg.ValidateAccessWithDirectory(job.UserID, job.ChannelID, c.security); !result.Allowed {
+		c.logger.WarnKV("Skipping scheduled job, the user is no longer allowed", "job", job.ID, "user", job.UserID, "channel", job.ChannelID, "reason", result.Reason)
+		monitoring.ScheduledJobs.WithLabelValues("denied").Inc()
+		c.sendMessage(job.ChannelID, job.ThreadTS, fmt.Sprintf("⏰ Scheduled job `%s` (`%s`) was skipped: <@%s> is no longer allowed to use the bot here.", job.ID, job.Tool, job.UserID))
+		return
+	}
+
+	identity := mcp.Identity{UserID: job.UserID}
+	profile, err := c.userFrontend.GetUserInfo(job.UserID)
+	if err != nil {
+		c.logger.WarnKV("Failed to get user info", "user", job.UserID, "error", err)
+		profile = &UserProfile{userId: job.UserID}
+	} else {
+		identity.Email = profile.email
+	}
+	ctx = mcp.ContextWithIdentity(ctx, identity)
+	ctx = mcp.ContextWithVars(ctx, requestVars(job.ChannelID, job.ThreadTS, "", profile))
+	ctx = hooks.ContextWithConversation(ctx, job.ChannelID, job.ThreadTS)
+
+	c.logger.InfoKV("Running scheduled job", "job", job.ID, "tool", job.Tool, "user", job.UserID, "channel", job.ChannelID)
+	result, err := c.llmMCPBridge.CallTool(ctx, job.Tool, job.Args)
+	if err != nil {
+		c.logger.WarnKV("Scheduled job failed", "job", job.ID, "tool", job.Tool, "error", err)
+		monitoring.ScheduledJobs.WithLabelValues("failed").Inc()
+		c.sendMessage(job.ChannelID, job.ThreadTS, fmt.Sprintf("⏰ Scheduled job `%s` (`%s`) failed: %v", job.ID, job.Tool, err))
+		return
+	}
+	monitoring.ScheduledJobs.WithLabelValues("completed").Inc()
+
+	// Keep the result in the thread history, so follow-up questions can use it. A
+	// job repeating an earlier call of the thread shows what changed since.
+	diff := c.diffToolResult(job.ChannelID, job.ThreadTS, job.Tool, job.Args, result)
+	c.addToolResult(job.ChannelID, job.ThreadTS, job.Tool, job.Args, result)
+	message := describeJobResult(job, result)
+	if diff != nil {
+		message += diff.attachment(c.cfg.Slack.ToolHistory.MaxDiffLines)
+	}
+	c.sendMessage(job.ChannelID, job.ThreadTS, message)
+}
+
+// describeJobResult is the message posting the result of a job
+func describeJobResult(job jobs.Job, result string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "⏰ *Scheduled job `%s`* (`%s`, asked by <@%s>)", job.ID, job.Tool, job.UserID)
+	if job.Note != "" {
+		fmt.Fprintf(&b, ": %s", job.Note)
+	}
+	if runes := []rune(result); len(runes) > maxJobResultChars {
+		result = string(runes[:maxJobResultChars]) + "\n… (truncated)"
+	}
+	fmt.Fprintf(&b, "\n```\n%s\n```", strings.TrimSpace(result))
+	return b.String()
+}
+
+// jobsCommand runs "jobs", "jobs list" and "jobs cancel <id>" for the user and
+// returns the reply. Users see their own jobs; admins see every job.
+func (c *Client) jobsCommand(args []string, userID string) string {
+	if c.jobs == nil {
+		return "Scheduled tool calls are not enabled."
+	}
+	switch {
+	case len(args) == 0 || (len(args) == 1 && strings.EqualFold(args[0], "list")):
+		if c.cfg.IsAdminUser(userID, c.security) {
+			return c.describeJobs(c.jobs.List(""))
+		}
+		return c.describeJobs(c.jobs.List(userID))
+	case len(args) == 2 && strings.EqualFold(args[0], "cancel"):
+		return c.cancelJob(strings.TrimPrefix(args[1], "#"), userID)
+	default:
+		return fmt.Sprintf("Usage: `%[1]s jobs` or `%[1]s jobs cancel <id>`", c.cfg.Slack.SlashCommand)
+	}
+}
+
+// cancelJob removes a job before it runs. Users can cancel their own jobs; admins
+// can cancel any job.
+func (c *Client) cancelJob(id, userID string) string {
+	job, ok := c.jobs.Get(id)
+	if !ok {
+		return fmt.Sprintf("There is no scheduled job `%s`.", id)
+	}
+	if job.UserID != userID && !c.cfg.IsAdminUser(userID, c.security) {
+		return fmt.Sprintf("Only <@%s>, who scheduled it, or an admin can cancel job `%s`.", job.UserID, id)
+	}
+	if _, err := c.jobs.Delete(id); err != nil {
+		c.logger.ErrorKV("Failed to cancel job", "job", id, "user", userID, "error", err)
+		return fmt.Sprintf("Failed to cancel job `%s`: %v", id, err)
+	}
+	monitoring.ScheduledJobsPending.Set(float64(c.jobs.Len()))
+	c.logger.InfoKV("Cancelled scheduled job", "job", id, "tool", job.Tool, "user", userID)
+	return fmt.Sprintf("Cancelled job `%s` (`%s` at %s).", id, job.Tool, c.jobScheduler.FormatTime(job.RunAt))
+}
+
+// describeJobs lists jobs with when and where they run
+func (c *Client) describeJobs(list []jobs.Job) string {
+	if len(list) == 0 {
+		return "No tool calls are scheduled."
+	}
+	var b strings.Builder
+	b.WriteString("*Scheduled tool calls*\n")
+	for _, job := range list {
+		fmt.Fprintf(&b, "• `%s` `%s` at %s in <#%s>, asked by <@%s>", job.ID, job.Tool, c.jobScheduler.FormatTime(job.RunAt), job.ChannelID, job.UserID)
+		if job.Note != "" {
+			fmt.Fprintf(&b, ": %s", job.Note)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,114 @@
+package slackbot
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/handlers"
+	"github.com/tuannvm/slack-mcp-client/internal/hooks"
+	"github.com/tuannvm/slack-mcp-client/internal/jobs"
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+)
+
+// backupTool is an MCP client whose backup_check tool reports who called it and where
+type backupTool struct {
+	err error
+}
+
+func (b backupTool) CallTool(ctx context.Context, _ string, args map[string]interface{}) (string, error) {
+	identity, _ := mcp.IdentityFromContext(ctx)
+	channelID, threadTS, _ := hooks.ConversationFromContext(ctx)
+	return "backups of " + args["cluster"].(string) + " ok, checked for " + identity.UserID + " in " + channelID + ":" + threadTS, b.err
+}
+
+// newJobsTestClient returns a client with scheduled jobs and a backup_check tool,
+// its job store and what it posted
+func newJobsTestClient(t *testing.T, tool backupTool) (*Client, *jobs.Store, *bytes.Buffer) {
+	t.Helper()
+	client, _, output := newProgressTestClient()
+	store, err := jobs.NewStore("", 0)
+	require.NoError(t, err)
+	client.jobs = store
+	client.jobScheduler = jobs.NewScheduler(store, client.cfg.Jobs, "/mcp")
+	client.historyLimit = 10
+	client.messageHistory = make(map[string][]Message)
+	tools := map[string]mcp.ToolInfo{"backup_check": {ToolName: "backup_check", ServerName: "backups"}}
+	client.llmMCPBridge = handlers.NewLLMMCPBridge(map[string]mcp.MCPClientInterface{"backups": tool}, log.New(os.Stderr, "", 0), tools, nil, client.cfg)
+	return client, store, output
+}
+
+func TestScheduledJobRunsAsTheUser(t *testing.T) {
+	client, store, output := newJobsTestClient(t, backupTool{})
+	_, err := store.Add(jobs.Job{Tool: "backup_check", Args: map[string]interface{}{"cluster": "prod"}, Note: "nightly check",
+		RunAt: time.Now().Add(-time.Minute), ChannelID: "C1", ThreadTS: "100.1", UserID: "U1"})
+	require.NoError(t, err)
+	_, err = store.Add(jobs.Job{Tool: "backup_check", RunAt: time.Now().Add(time.Hour), ChannelID: "C1", UserID: "U1"})
+	require.NoError(t, err)
+
+	due, err := store.TakeDue(time.Now())
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	client.runJob(context.Background(), due[0])
+	assert.Contains(t, output.String(), "⏰ *Scheduled job `1`* (`backup_check`, asked by <@U1>): nightly check\n```\nbackups of prod ok, checked for U1 in C1:100.1\n```")
+	assert.Equal(t, 1, store.Len())
+
+	// The result is kept in the thread history for follow-up questions
+	history := client.messageHistory[historyKey("C1", "100.1")]
+	require.Len(t, history, 1)
+	assert.Equal(t, "backup_check", history[0].Tool.Name)
+}
+
+func TestScheduledJobFailureIsPosted(t *testing.T) {
+	client, _, output := newJobsTestClient(t, backupTool{err: errors.New("cluster unreachable")})
+
+	client.runJob(context.Background(), jobs.Job{ID: "7", Tool: "backup_check", Args: map[string]interface{}{"cluster": "prod"}, ChannelID: "C1", UserID: "U1"})
+	assert.Contains(t, output.String(), "⏰ Scheduled job `7` (`backup_check`) failed:")
+	assert.Contains(t, output.String(), "cluster unreachable")
+}
+
+func TestJobsSlashCommands(t *testing.T) {
+	client, _, output := newProgressTestClient()
+	run := func(userID, text string) string {
+		output.Reset()
+		client.handleSlashCommand(slack.SlashCommand{Command: "/mcp", Text: text, UserID: userID, ChannelID: "D1"})
+		return output.String()
+	}
+	assert.Contains(t, run("U1", "jobs"), "Scheduled tool calls are not enabled.")
+
+	store, err := jobs.NewStore("", 0)
+	require.NoError(t, err)
+	client.jobs = store
+	client.jobScheduler = jobs.NewScheduler(store, client.cfg.Jobs, "/mcp")
+	assert.Contains(t, run("U1", "jobs"), "No tool calls are scheduled.")
+
+	runAt := time.Date(2026, 10, 2, 2, 0, 0, 0, time.UTC)
+	_, err = store.Add(jobs.Job{Tool: "backup_check", Note: "nightly check", RunAt: runAt, ChannelID: "C1", UserID: "U2"})
+	require.NoError(t, err)
+	_, err = store.Add(jobs.Job{Tool: "list_alerts", RunAt: runAt, ChannelID: "C1", UserID: "U3"})
+	require.NoError(t, err)
+
+	// Users see their own jobs and admins see every job
+	listed := run("U2", "jobs list")
+	assert.Contains(t, listed, "• `1` `backup_check` at 2026-10-02 02:00 UTC in <#C1>, asked by <@U2>: nightly check")
+	assert.NotContains(t, listed, "list_alerts")
+
+	// Only the user who scheduled a job, or an admin, can cancel it
+	assert.Contains(t, run("U1", "jobs cancel 1"), "Only <@U2>, who scheduled it, or an admin can cancel job `1`.")
+	assert.Contains(t, run("U2", "jobs cancel #1"), "Cancelled job `1` (`backup_check` at 2026-10-02 02:00 UTC).")
+	client.cfg.Security.AdminUsers = []string{"U1"}
+	assert.Contains(t, run("U1", "jobs"), "list_alerts")
+	assert.Contains(t, run("U1", "jobs cancel 2"), "Cancelled job `2`")
+	assert.Contains(t, run("U1", "jobs cancel 2"), "There is no scheduled job `2`.")
+
+	assert.Contains(t, run("U1", "jobs run 2"), "Usage: `/mcp jobs` or `/mcp jobs cancel <id>`")
+	assert.Contains(t, run("U1", "help"), "`/mcp jobs cancel <id>`")
+}
@@ -0,0 +1,118 @@
+package slackbot
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+// channelListener holds the compiled triggers of one channel
+type channelListener struct {
+	keywords  []*regexp.Regexp
+	questions bool
+	patterns  []*regexp.Regexp
+	cooldown  time.Duration
+}
+
+// channelListeners decides which messages in listened channels are answered
+// without a mention. A channel answers at most once per cooldown, so a busy
+// channel full of questions does not get a reply to each of them.
+type channelListeners struct {
+	now func() time.Time
+
+	mu         sync.Mutex
+	channels   map[string]*channelListener
+	lastAnswer map[string]time.Time // By channel ID
+}
+
+// newChannelListeners compiles the listeners of slack.listeners
+func newChannelListeners(listeners map[string]config.SlackListenerConfig) (*channelListeners, error) {
+	l := &channelListeners{
+		now:        time.Now,
+		channels:   make(map[string]*channelListener, len(listeners)),
+		lastAnswer: make(map[string]time.Time),
+	}
+	for channelID, cfg := range listeners {
+		listener := &channelListener{questions: cfg.Questions}
+		for _, keyword := range cfg.Keywords {
+			listener.keywords = append(listener.keywords, regexp.MustCompile(`(?i)\b`+regexp.QuoteMeta(keyword)+`\b`))
+		}
+		for _, pattern := range cfg.Patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid listener pattern for channel %s: %w", channelID, err)
+			}
+			listener.patterns = append(listener.patterns, re)
+		}
+		if cfg.Cooldown != "" {
+			cooldown, err := time.ParseDuration(cfg.Cooldown)
+			if err != nil {
+				return nil, fmt.Errorf("invalid listener cooldown for channel %s: %w", channelID, err)
+			}
+			listener.cooldown = cooldown
+		}
+		l.channels[channelID] = listener
+	}
+	return l, nil
+}
+
+// trigger returns what in the message triggers an answer in the channel, or ""
+func (l *channelListeners) trigger(channelID, text string) string {
+	listener := l.channels[channelID]
+	if listener == nil {
+		return ""
+	}
+	for _, keyword := range listener.keywords {
+		if keyword.MatchString(text) {
+			return "keyword"
+		}
+	}
+	if listener.questions && strings.HasSuffix(strings.TrimSpace(text), "?") {
+		return "question"
+	}
+	for _, pattern := range listener.patterns {
+		if pattern.MatchString(text) {
+			return "pattern"
+		}
+	}
+	return ""
+}
+
+// claim reports whether the channel is out of its cooldown, and starts a new one
+func (l *channelListeners) claim(channelID string) bool {
+	listener := l.channels[channelID]
+	if listener == nil {
+		return false
+	}
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if last, ok := l.lastAnswer[channelID]; ok && now.Sub(last) < listener.cooldown {
+		return false
+	}
+	l.lastAnswer[channelID] = now
+	return true
+}
+
+// listenerAnswers reports whether a message that does not mention the bot is
+// answered by the listener of its channel. Replies are never sent in
+// conversations whose reply mode is off.
+func (c *Client) listenerAnswers(channelID, conversationType, text string) bool {
+	if c.listeners == nil || c.cfg.Slack.Conversations.ReplyMode(conversationType) == config.ConversationReplyOff {
+		return false
+	}
+	trigger := c.listeners.trigger(channelID, text)
+	if trigger == "" {
+		return false
+	}
+	if !c.listeners.claim(channelID) {
+		c.logger.DebugKV("Listener trigger in cooldown, not answering", "channel", channelID, "trigger", trigger)
+		return false
+	}
+	c.logger.InfoKV("Answering message matched by channel listener", "channel", channelID, "trigger", trigger)
+	return true
+}
@@ -0,0 +1,49 @@
+package slackbot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+func TestChannelListenerTriggers(t *testing.T) {
+	listeners, err := newChannelListeners(map[string]config.SlackListenerConfig{
+		"C1": {Keywords: []string{"deploy", "on-call"}, Questions: true, Patterns: []string{`^INC-\d+`}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "keyword", listeners.trigger("C1", "Who is On-Call today"))
+	assert.Equal(t, "keyword", listeners.trigger("C1", "deploy failed"))
+	assert.Equal(t, "", listeners.trigger("C1", "redeployed the api"), "keywords match whole words")
+	assert.Equal(t, "question", listeners.trigger("C1", "is the api down? "))
+	assert.Equal(t, "pattern", listeners.trigger("C1", "INC-42 resolved"))
+	assert.Equal(t, "", listeners.trigger("C1", "thanks all"))
+	assert.Equal(t, "", listeners.trigger("C2", "deploy failed"), "other channels are not listened to")
+}
+
+func TestChannelListenerCooldown(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Slack.Listeners = map[string]config.SlackListenerConfig{"C1": {Questions: true}}
+	cfg.ApplyDefaults()
+	listeners, err := newChannelListeners(cfg.Slack.Listeners)
+	require.NoError(t, err)
+	now := time.Now()
+	listeners.now = func() time.Time { return now }
+	client := &Client{cfg: cfg, listeners: listeners, logger: logging.New("test", logging.LevelError)}
+
+	assert.True(t, client.listenerAnswers("C1", config.ConversationPublic, "anyone around?"))
+	assert.False(t, client.listenerAnswers("C1", config.ConversationPublic, "still nobody?"), "within the cooldown")
+	now = now.Add(2 * time.Minute)
+	assert.False(t, client.listenerAnswers("C1", config.ConversationPublic, "no question here"))
+	assert.True(t, client.listenerAnswers("C1", config.ConversationPublic, "and now?"))
+
+	// Listeners do not override conversation types whose replies are off
+	cfg.Slack.Conversations.PublicChannels = config.ConversationReplyOff
+	now = now.Add(time.Hour)
+	assert.False(t, client.listenerAnswers("C1", config.ConversationPublic, "anyone?"))
+}
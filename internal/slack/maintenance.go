@@ -0,0 +1,65 @@
+package slackbot
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tuannvm/slack-mcp-client/internal/availability"
+)
+
+// handleMaintenanceCommand handles the command admins use to toggle maintenance
+// mode at runtime: "maintenance on", "maintenance off" and "maintenance status".
+// It reports whether the message was a maintenance command.
+func (c *Client) handleMaintenanceCommand(text, channelID, threadTS, userID string) bool {
+	if c.availability == nil {
+		return false
+	}
+	fields := strings.Fields(strings.ToLower(strings.TrimSpace(text)))
+	if len(fields) != 2 || fields[0] != "maintenance" {
+		return false
+	}
+	action := fields[1]
+	if action != "on" && action != "off" && action != "status" {
+		return false
+	}
+	if !c.cfg.IsAdminUser(userID, c.security) {
+		c.sendMessage(channelID, threadTS, "Only admins can change maintenance mode.")
+		return true
+	}
+
+	switch action {
+	case "on", "off":
+		c.availability.SetMaintenance(action == "on")
+		c.logger.InfoKV("Maintenance mode changed", "enabled", action == "on", "user", userID)
+	}
+	c.sendMessage(channelID, threadTS, describeAvailability(c.availability.Status()))
+	return true
+}
+
+// describeAvailability summarizes a status for an admin
+func describeAvailability(status availability.Status) string {
+	if status.Available {
+		return "Maintenance mode is *off*. I'm answering everyone."
+	}
+	audience := "Everyone gets the notice."
+	if status.AdminsOnly {
+		audience = "Only admins are answered."
+	}
+	if status.Reason == availability.ReasonQuietHours {
+		return fmt.Sprintf("Maintenance mode is *off*, but it's quiet hours. %s", audience)
+	}
+	return fmt.Sprintf("Maintenance mode is *on*. %s", audience)
+}
+
+// unavailableNotice returns the notice to send instead of answering the user, or
+// "" when the bot answers them
+func (c *Client) unavailableNotice(userID string) string {
+	if c.availability == nil {
+		return ""
+	}
+	status := c.availability.Status()
+	if status.Available || (status.AdminsOnly && c.cfg.IsAdminUser(userID, c.security)) {
+		return ""
+	}
+	return status.Message
+}
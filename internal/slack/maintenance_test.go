@@ -0,0 +1,54 @@
+package slackbot
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/availability"
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+func newMaintenanceTestClient(t *testing.T, adminsOnly bool) (*Client, *bytes.Buffer) {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Security.AdminUsers = []string{"UADMIN"}
+	cfg.Maintenance.AdminsOnly = adminsOnly
+	cfg.ApplyDefaults()
+	controller, err := availability.NewController(cfg.Maintenance)
+	require.NoError(t, err)
+
+	logger := logging.New("test", logging.LevelError)
+	output := &bytes.Buffer{}
+	stdio := NewStdioClient(logger)
+	stdio.Output = output
+	return &Client{cfg: cfg, userFrontend: stdio, logger: logger, availability: controller}, output
+}
+
+func TestMaintenanceCommand(t *testing.T) {
+	client, output := newMaintenanceTestClient(t, true)
+
+	assert.False(t, client.handleMaintenanceCommand("maintenance window tonight?", "D1", "1.1", "UADMIN"))
+
+	// Only admins can toggle maintenance mode
+	assert.True(t, client.handleMaintenanceCommand("maintenance on", "D1", "1.1", "U1"))
+	assert.Contains(t, output.String(), "Only admins")
+	assert.Empty(t, client.unavailableNotice("U1"))
+
+	assert.True(t, client.handleMaintenanceCommand("Maintenance ON", "D1", "1.1", "UADMIN"))
+	assert.Contains(t, output.String(), "Maintenance mode is *on*. Only admins are answered.")
+	assert.Equal(t, client.cfg.Maintenance.Message, client.unavailableNotice("U1"))
+	assert.Empty(t, client.unavailableNotice("UADMIN"))
+
+	assert.True(t, client.handleMaintenanceCommand("maintenance off", "D1", "1.1", "UADMIN"))
+	assert.Empty(t, client.unavailableNotice("U1"))
+}
+
+func TestMaintenanceNoticeForEveryone(t *testing.T) {
+	client, _ := newMaintenanceTestClient(t, false)
+	client.availability.SetMaintenance(true)
+	assert.NotEmpty(t, client.unavailableNotice("UADMIN"))
+}
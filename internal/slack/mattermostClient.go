@@ -0,0 +1,496 @@
+package slackbot
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/network"
+	"github.com/tuannvm/slack-mcp-client/internal/slack/formatter"
+)
+
+// mattermostMessageLimit is the maximum length of a Mattermost post
+const mattermostMessageLimit = 16383
+
+// mattermostChannelTypes maps Mattermost channel types to Slack channel types:
+// direct, group, open and private channels
+var mattermostChannelTypes = map[string]string{
+	"D": "im",
+	"G": "mpim",
+	"O": "channel",
+	"P": "group",
+}
+
+// MattermostClient is a UserFrontend that serves a Mattermost server. Posts
+// arrive over the WebSocket API and are fed into the event channel as Slack
+// events, so the dispatch pipeline is shared: a post that mentions the bot
+// outside a direct channel becomes an app_mention, other posts become message
+// events. Mattermost threads map directly: the root post ID is the thread
+// timestamp.
+type MattermostClient struct {
+	serverURL       string
+	token           string
+	thinkingMessage string
+	httpClient      *http.Client
+	dialer          *websocket.Dialer
+	logger          *logging.Logger
+	events          chan socketmode.Event
+	outbox          *outboundQueue
+	botUserID       string
+	mentionRgx      *regexp.Regexp
+
+	mu           sync.Mutex
+	channelTypes map[string]string // Mattermost channel type, by channel ID
+	users        map[string]*UserProfile
+	conn         *websocket.Conn
+	closed       bool
+}
+
+// mattermostPost is a Mattermost post
+type mattermostPost struct {
+	ID        string                 `json:"id"`
+	CreateAt  int64                  `json:"create_at"`
+	UserID    string                 `json:"user_id"`
+	ChannelID string                 `json:"channel_id"`
+	RootID    string                 `json:"root_id"`
+	Message   string                 `json:"message"`
+	Type      string                 `json:"type"`
+	Props     map[string]interface{} `json:"props"`
+}
+
+// fromBot reports whether a bot or an integration wrote the post
+func (p mattermostPost) fromBot() bool {
+	fromBot, _ := p.Props["from_bot"].(string)
+	fromWebhook, _ := p.Props["from_webhook"].(string)
+	return fromBot == "true" || fromWebhook == "true"
+}
+
+// mattermostAPIError is an error response of the REST API
+type mattermostAPIError struct {
+	Status  int    `json:"status_code"`
+	ID      string `json:"id"`
+	Message string `json:"message"`
+}
+
+func (e *mattermostAPIError) Error() string {
+	return fmt.Sprintf("mattermost API error %d (%s): %s", e.Status, e.ID, e.Message)
+}
+
+// Retryable reports whether the outbound queue should retry the request
+func (e *mattermostAPIError) Retryable() bool {
+	return e.Status >= 500
+}
+
+// NewMattermostClient creates a Mattermost frontend and looks up the bot's
+// account. Replies are delivered through an outbound queue configured like
+// Slack's.
+func NewMattermostClient(mattermostCfg config.MattermostConfig, stdLogger *logging.Logger, thinkingMessage string, outbound config.SlackOutboundConfig) (*MattermostClient, error) {
+	if mattermostCfg.URL == "" || mattermostCfg.BotToken == "" {
+		return nil, fmt.Errorf("MATTERMOST_URL and MATTERMOST_BOT_TOKEN must be set")
+	}
+
+	mattermostLogger := logging.New("mattermost-client", getLogLevel(stdLogger))
+	client := &MattermostClient{
+		serverURL:       strings.TrimSuffix(mattermostCfg.URL, "/"),
+		token:           mattermostCfg.BotToken,
+		thinkingMessage: thinkingMessage,
+		httpClient:      &http.Client{Timeout: 30 * time.Second},
+		dialer:          network.WebsocketDialer(),
+		logger:          mattermostLogger,
+		events:          make(chan socketmode.Event, 50),
+		channelTypes:    make(map[string]string),
+		users:           make(map[string]*UserProfile),
+	}
+
+	var me struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+	}
+	if err := client.api(http.MethodGet, "/api/v4/users/me", nil, &me); err != nil {
+		return nil, fmt.Errorf("failed to authenticate with Mattermost: %w", err)
+	}
+	client.botUserID = me.ID
+	client.mentionRgx = regexp.MustCompile(fmt.Sprintf(`@%s\b`, regexp.QuoteMeta(me.Username)))
+
+	client.outbox = newOutboundQueue(outbound, client.deliverMessage, mattermostLogger)
+	return client, nil
+}
+
+// Run connects to the WebSocket API and feeds posts into the event channel
+// until Close is called, reconnecting when the connection drops
+func (m *MattermostClient) Run() error {
+	backoff := time.Second
+	for {
+		connected, err := m.listen()
+		if m.isClosed() {
+			return nil
+		}
+		var closeErr *websocket.CloseError
+		if errors.As(err, &closeErr) && closeErr.Code == websocket.ClosePolicyViolation {
+			return fmt.Errorf("mattermost closed the connection: %w", err)
+		}
+		if connected {
+			backoff = time.Second
+		}
+		m.logger.WarnKV("Mattermost connection lost, reconnecting", "error", err, "retry_in", backoff)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, 30*time.Second)
+	}
+}
+
+// listen runs one WebSocket connection until it drops. connected reports
+// whether the connection was established.
+func (m *MattermostClient) listen() (connected bool, err error) {
+	wsURL := "ws" + strings.TrimPrefix(m.serverURL, "http") + "/api/v4/websocket"
+	header := http.Header{"Authorization": []string{"Bearer " + m.token}}
+	conn, _, err := m.dialer.Dial(wsURL, header)
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to the mattermost websocket: %w", err)
+	}
+	defer conn.Close()
+	if !m.setConn(conn) {
+		return true, nil
+	}
+	defer m.setConn(nil)
+	m.logger.InfoKV("Connected to the Mattermost websocket", "server", m.serverURL)
+
+	for {
+		var event struct {
+			Event string                 `json:"event"`
+			Data  map[string]interface{} `json:"data"`
+		}
+		if err := conn.ReadJSON(&event); err != nil {
+			return true, err
+		}
+		if event.Event != "posted" {
+			continue
+		}
+		if socketEvent, ok := m.postedEvent(event.Data); ok {
+			m.events <- socketEvent
+		}
+	}
+}
+
+// postedEvent translates a posted event into the Slack event the dispatch
+// pipeline handles. Posts of the bot, other bots and system messages are skipped.
+func (m *MattermostClient) postedEvent(data map[string]interface{}) (socketmode.Event, bool) {
+	// The post and the mentions are JSON documents in strings
+	postJSON, _ := data["post"].(string)
+	mentionsJSON, _ := data["mentions"].(string)
+	channelType, _ := data["channel_type"].(string)
+
+	var post mattermostPost
+	if err := json.Unmarshal([]byte(postJSON), &post); err != nil {
+		m.logger.WarnKV("Invalid Mattermost post", "error", err)
+		return socketmode.Event{}, false
+	}
+	if post.UserID == "" || post.UserID == m.botUserID || post.Type != "" || post.fromBot() {
+		return socketmode.Event{}, false
+	}
+
+	m.mu.Lock()
+	m.channelTypes[post.ChannelID] = channelType
+	m.mu.Unlock()
+
+	var mentions []string
+	_ = json.Unmarshal([]byte(mentionsJSON), &mentions)
+	mentioned := false
+	for _, userID := range mentions {
+		mentioned = mentioned || userID == m.botUserID
+	}
+
+	var inner interface{}
+	if mentioned && channelType != "D" {
+		inner = &slackevents.AppMentionEvent{
+			Type:            "app_mention",
+			User:            post.UserID,
+			Text:            post.Message,
+			TimeStamp:       post.ID,
+			ThreadTimeStamp: post.RootID,
+			Channel:         post.ChannelID,
+		}
+	} else {
+		inner = &slackevents.MessageEvent{
+			Type:            "message",
+			User:            post.UserID,
+			Text:            post.Message,
+			TimeStamp:       post.ID,
+			ThreadTimeStamp: post.RootID,
+			Channel:         post.ChannelID,
+			ChannelType:     mattermostChannelTypes[channelType],
+		}
+	}
+
+	return socketmode.Event{
+		Type: socketmode.EventTypeEventsAPI,
+		Data: slackevents.EventsAPIEvent{
+			Type:       slackevents.CallbackEvent,
+			Data:       &slackevents.EventsAPICallbackEvent{EventID: "mattermost-" + post.ID},
+			InnerEvent: slackevents.EventsAPIInnerEvent{Data: inner},
+		},
+		Request: &socketmode.Request{},
+	}, true
+}
+
+// setConn records the open connection; it returns false once the client is closed
+func (m *MattermostClient) setConn(conn *websocket.Conn) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed && conn != nil {
+		return false
+	}
+	m.conn = conn
+	return true
+}
+
+// isClosed reports whether Close was called
+func (m *MattermostClient) isClosed() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.closed
+}
+
+// Close disconnects from the WebSocket API and drains the outbound queue
+func (m *MattermostClient) Close() error {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return nil
+	}
+	m.closed = true
+	conn := m.conn
+	m.mu.Unlock()
+	if conn != nil {
+		_ = conn.Close()
+	}
+	m.outbox.close()
+	return nil
+}
+
+// Ack is a no-op: WebSocket events need no acknowledgement
+func (m *MattermostClient) Ack(req socketmode.Request, payload ...interface{}) {}
+
+func (m *MattermostClient) GetEventChannel() chan socketmode.Event {
+	return m.events
+}
+
+func (m *MattermostClient) RemoveBotMention(msg string) string {
+	return m.mentionRgx.ReplaceAllString(msg, "")
+}
+
+func (m *MattermostClient) GetLogger() *logging.Logger {
+	return m.logger
+}
+
+func (m *MattermostClient) IsValidUser(userID string) bool {
+	return userID != "" && userID != m.botUserID
+}
+
+// GetConversationInfo reports the type of a channel the bot received a post
+// from, or looks it up
+func (m *MattermostClient) GetConversationInfo(input *slack.GetConversationInfoInput) (*slack.Channel, error) {
+	m.mu.Lock()
+	channelType, ok := m.channelTypes[input.ChannelID]
+	m.mu.Unlock()
+	if !ok {
+		var channel struct {
+			Type string `json:"type"`
+		}
+		if err := m.api(http.MethodGet, "/api/v4/channels/"+url.PathEscape(input.ChannelID), nil, &channel); err != nil {
+			return nil, err
+		}
+		channelType = channel.Type
+		m.mu.Lock()
+		m.channelTypes[input.ChannelID] = channelType
+		m.mu.Unlock()
+	}
+	channel := &slack.Channel{}
+	channel.ID = input.ChannelID
+	channel.IsIM = channelType == "D"
+	channel.IsMpIM = channelType == "G"
+	channel.IsPrivate = channelType == "P"
+	return channel, nil
+}
+
+// GetThreadReplies returns the posts of a thread, oldest first; posts of bots
+// carry a bot ID
+func (m *MattermostClient) GetThreadReplies(channelID, threadTS string) ([]slack.Message, error) {
+	if channelID == "" || threadTS == "" {
+		return nil, fmt.Errorf("channelID and threadTS must be provided")
+	}
+	var thread struct {
+		Order []string                  `json:"order"`
+		Posts map[string]mattermostPost `json:"posts"`
+	}
+	if err := m.api(http.MethodGet, "/api/v4/posts/"+url.PathEscape(threadTS)+"/thread", nil, &thread); err != nil {
+		return nil, fmt.Errorf("failed to fetch mattermost thread: %w", err)
+	}
+
+	posts := make([]mattermostPost, 0, len(thread.Posts))
+	for _, post := range thread.Posts {
+		if post.Type == "" {
+			posts = append(posts, post)
+		}
+	}
+	sort.Slice(posts, func(i, j int) bool { return posts[i].CreateAt < posts[j].CreateAt })
+
+	messages := make([]slack.Message, 0, len(posts))
+	for _, post := range posts {
+		message := slack.Message{}
+		message.User = post.UserID
+		message.Text = post.Message
+		message.Timestamp = post.ID
+		message.ThreadTimestamp = threadTS
+		if post.UserID == m.botUserID || post.fromBot() {
+			message.BotID = post.UserID
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+func (m *MattermostClient) GetUserInfo(userID string) (*UserProfile, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID must be provided")
+	}
+	m.mu.Lock()
+	profile, ok := m.users[userID]
+	m.mu.Unlock()
+	if ok {
+		return profile, nil
+	}
+
+	var user struct {
+		Username  string `json:"username"`
+		FirstName string `json:"first_name"`
+		LastName  string `json:"last_name"`
+		Email     string `json:"email"`
+	}
+	if err := m.api(http.MethodGet, "/api/v4/users/"+url.PathEscape(userID), nil, &user); err != nil {
+		return nil, fmt.Errorf("failed to fetch mattermost user: %w", err)
+	}
+	realName := strings.TrimSpace(user.FirstName + " " + user.LastName)
+	if realName == "" {
+		realName = user.Username
+	}
+	profile = &UserProfile{userId: userID, realName: realName, email: user.Email}
+	m.mu.Lock()
+	m.users[userID] = profile
+	m.mu.Unlock()
+	return profile, nil
+}
+
+// SendMessage queues a message for delivery, in the thread of threadTS when it
+// is set. The thinking message is shown as the typing indicator instead.
+func (m *MattermostClient) SendMessage(channelID, threadTS, text string) {
+	if text == "" {
+		m.logger.WarnKV("Attempted to send empty message, skipping", "channel", channelID)
+		return
+	}
+	if text == m.thinkingMessage {
+		if err := m.api(http.MethodPost, "/api/v4/users/me/typing", map[string]string{"channel_id": channelID, "parent_id": threadTS}, nil); err != nil {
+			m.logger.DebugKV("Failed to show the typing indicator", "channel", channelID, "error", err)
+		}
+		return
+	}
+	// Each part is queued on its own so that a retry does not post earlier parts again
+	for _, chunk := range formatter.SplitMessage(markdownMessage(text), mattermostMessageLimit) {
+		m.outbox.enqueue(channelID, threadTS, chunk)
+	}
+}
+
+// markdownMessage renders a reply as Markdown: Block Kit and structured data
+// are converted, and Slack links are rewritten
+func markdownMessage(text string) string {
+	var blockKit string
+	switch formatter.DetectMessageType(text) {
+	case formatter.JSONBlock:
+		blockKit = text
+	case formatter.StructuredData:
+		blockKit = formatter.FormatStructuredData(text)
+	}
+	if blockKit != "" {
+		if markdown, err := formatter.BlockKitMarkdown(blockKit); err == nil {
+			return markdown
+		}
+	}
+	return formatter.MarkdownLinks(text)
+}
+
+// deliverMessage posts a queued message
+func (m *MattermostClient) deliverMessage(msg outboundMessage) error {
+	return m.api(http.MethodPost, "/api/v4/posts", map[string]string{
+		"channel_id": msg.ChannelID,
+		"root_id":    msg.ThreadTS,
+		"message":    msg.Text,
+	}, nil)
+}
+
+// api calls the REST API, decoding the response into out when it is not nil.
+// Rate limits are returned as errors the outbound queue retries after the
+// requested delay.
+func (m *MattermostClient) api(method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequest(method, m.serverURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+m.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		retryAfter, _ := strconv.Atoi(resp.Header.Get("X-Ratelimit-Reset"))
+		return &slack.RateLimitedError{RetryAfter: time.Duration(retryAfter) * time.Second}
+	}
+	if resp.StatusCode >= 300 {
+		apiErr := &mattermostAPIError{}
+		_ = json.Unmarshal(data, apiErr)
+		apiErr.Status = resp.StatusCode
+		if apiErr.Message == "" {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+		return apiErr
+	}
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("invalid mattermost API response: %w", err)
+		}
+	}
+	return nil
+}
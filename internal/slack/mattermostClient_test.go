@@ -0,0 +1,160 @@
+package slackbot
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+// testMattermostServer fakes the REST and WebSocket APIs of a Mattermost server
+type testMattermostServer struct {
+	*httptest.Server
+	events chan map[string]interface{} // Sent over the WebSocket
+
+	mu    sync.Mutex
+	posts []map[string]string
+}
+
+func newTestMattermostServer(t *testing.T) *testMattermostServer {
+	server := &testMattermostServer{events: make(chan map[string]interface{}, 10)}
+	upgrader := websocket.Upgrader{}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer mm-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v4/users/me":
+			_, _ = w.Write([]byte(`{"id": "bot1", "username": "helper"}`))
+		case "/api/v4/users/user1":
+			_, _ = w.Write([]byte(`{"username": "ada", "first_name": "Ada", "last_name": "Lovelace", "email": "ada@example.com"}`))
+		case "/api/v4/posts/root1/thread":
+			_, _ = w.Write([]byte(`{"order": ["reply1", "root1"], "posts": {
+				"root1": {"id": "root1", "create_at": 1, "user_id": "user1", "message": "@helper status?"},
+				"reply1": {"id": "reply1", "create_at": 2, "user_id": "bot1", "message": "All green"}
+			}}`))
+		case "/api/v4/posts":
+			var post map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&post)
+			server.mu.Lock()
+			server.posts = append(server.posts, post)
+			server.mu.Unlock()
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{}`))
+		case "/api/v4/websocket":
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			for event := range server.events {
+				if err := conn.WriteJSON(event); err != nil {
+					return
+				}
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(func() {
+		close(server.events)
+		server.Close()
+	})
+	return server
+}
+
+func postedEvent(post, channelType string, mentions string) map[string]interface{} {
+	return map[string]interface{}{
+		"event": "posted",
+		"data":  map[string]interface{}{"post": post, "channel_type": channelType, "mentions": mentions, "set_online": true},
+	}
+}
+
+func TestMattermostTranslatesPosts(t *testing.T) {
+	server := newTestMattermostServer(t)
+	client, err := NewMattermostClient(config.MattermostConfig{URL: server.URL, BotToken: "mm-token"},
+		logging.New("test", logging.LevelError), "Thinking...", config.SlackOutboundConfig{MaxAttempts: 1})
+	require.NoError(t, err)
+	done := make(chan error, 1)
+	go func() { done <- client.Run() }()
+
+	server.events <- map[string]interface{}{"event": "hello", "data": map[string]interface{}{"server_version": "9.0"}}
+	server.events <- postedEvent(`{"id": "p1", "user_id": "user1", "channel_id": "town", "message": "@helper status?"}`, "O", `["bot1"]`)
+	server.events <- postedEvent(`{"id": "p2", "user_id": "bot1", "channel_id": "town", "message": "All green"}`, "O", "")
+	server.events <- postedEvent(`{"id": "p3", "user_id": "user1", "channel_id": "town", "message": "joined", "type": "system_join_channel"}`, "O", "")
+	server.events <- postedEvent(`{"id": "p4", "user_id": "user1", "channel_id": "dm1", "root_id": "p0", "message": "thanks"}`, "D", "")
+
+	next := func() interface{} {
+		select {
+		case event := <-client.GetEventChannel():
+			return event.Data.(slackevents.EventsAPIEvent).InnerEvent.Data
+		case <-time.After(5 * time.Second):
+			t.Fatal("no event received")
+			return nil
+		}
+	}
+	mention, ok := next().(*slackevents.AppMentionEvent)
+	require.True(t, ok)
+	assert.Equal(t, "town", mention.Channel)
+	assert.Equal(t, "p1", mention.TimeStamp)
+	assert.Equal(t, " status?", client.RemoveBotMention(mention.Text))
+
+	// The bot's post and the system message are skipped
+	direct, ok := next().(*slackevents.MessageEvent)
+	require.True(t, ok)
+	assert.Equal(t, "im", direct.ChannelType)
+	assert.Equal(t, "p0", direct.ThreadTimeStamp)
+
+	channel, err := client.GetConversationInfo(&slack.GetConversationInfoInput{ChannelID: "dm1"})
+	require.NoError(t, err)
+	assert.True(t, channel.IsIM)
+
+	require.NoError(t, client.Close())
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after Close")
+	}
+}
+
+func TestMattermostRepliesAndHistory(t *testing.T) {
+	server := newTestMattermostServer(t)
+	client, err := NewMattermostClient(config.MattermostConfig{URL: server.URL + "/", BotToken: "mm-token"},
+		logging.New("test", logging.LevelError), "Thinking...", config.SlackOutboundConfig{MaxAttempts: 1})
+	require.NoError(t, err)
+
+	replies, err := client.GetThreadReplies("town", "root1")
+	require.NoError(t, err)
+	require.Len(t, replies, 2)
+	assert.Equal(t, "root1", replies[0].Timestamp)
+	assert.Empty(t, replies[0].BotID)
+	assert.Equal(t, "bot1", replies[1].BotID)
+
+	profile, err := client.GetUserInfo("user1")
+	require.NoError(t, err)
+	assert.Equal(t, "Ada Lovelace", profile.realName)
+	assert.Equal(t, "ada@example.com", profile.email)
+
+	client.SendMessage("town", "root1", `{"blocks": [{"type": "header", "text": {"type": "plain_text", "text": "Deploy"}}, {"type": "section", "text": {"type": "mrkdwn", "text": "See <https://example.com|the runbook>"}}]}`)
+	require.NoError(t, client.Close())
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	require.Len(t, server.posts, 1)
+	assert.Equal(t, "town", server.posts[0]["channel_id"])
+	assert.Equal(t, "root1", server.posts[0]["root_id"])
+	assert.Equal(t, "#### Deploy\n\nSee [the runbook](https://example.com)", server.posts[0]["message"])
+}
@@ -0,0 +1,150 @@
+package slackbot
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/slack-go/slack"
+
+	"github.com/tuannvm/slack-mcp-client/internal/circuit"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/monitoring"
+)
+
+// Reasons of alerts about MCP servers that are down, used in metrics
+const (
+	mcpAlertInitFailed  = "init_failed"
+	mcpAlertStopped     = "stopped"
+	mcpAlertCircuitOpen = "circuit_open"
+)
+
+// maxAlertErrorLength caps the error quoted in an alert
+const maxAlertErrorLength = 1000
+
+// mcpFixHints suggest fixes for the errors of servers that are down, by parts of
+// the error message. The first matching hint wins; {command} is replaced with the
+// name of the server's command and {url} with the host of its URL.
+var mcpFixHints = []struct {
+	parts []string
+	fix   string
+}{
+	{[]string{"executable file not found", "command not found"}, "Check that `{command}` is installed in the bot's image and on its PATH, or give its full path in `command`."},
+	{[]string{"tool name collision"}, "Another server offers tools with the same names. Set `toolCollision.strategy` to `\"prefix\"`, or rename or disable the tools of one server."},
+	{[]string{"x509", "certificate", "tls:"}, "Check the server's TLS settings: its certificate, `tls.caFile` or `network.caBundle`, and the client certificate if it requires mutual TLS."},
+	{[]string{"no such host"}, "Check the host name of `{url}`: it does not resolve from the bot."},
+	{[]string{"connection refused", "connection reset", "dial tcp", "transport has been closed"}, "Check that the server at `{url}` is running and reachable from the bot (port, firewall, network policy)."},
+	{[]string{"401", "403", "unauthorized", "forbidden", "invalid token", "authentication"}, "Check the server's credentials: the tokens in its `httpHeaders` or `env`, or its OAuth settings."},
+	{[]string{"deadline exceeded", "timeout", "timed out"}, "The server did not answer in time. Check its logs, or raise `initializeTimeoutSeconds` if it is slow to start."},
+	{[]string{"permission denied"}, "Check that the bot's user may run `{command}` and read the files it needs."},
+	{[]string{"exit status", "exited", "eof", "broken pipe", "file already closed"}, "The server process exited. Run `{command}` by hand with the same `env` to see its error."},
+}
+
+// mcpSuggestedFix suggests a fix for the error of a server that is down
+func mcpSuggestedFix(serverConf config.MCPServerConfig, reason string) string {
+	message := strings.ToLower(reason)
+	command := mcpCommandName(serverConf)
+	if command == "" {
+		command = "the server's command"
+	}
+	target := mcpURLHost(serverConf)
+	if target == "" {
+		target = "its URL"
+	}
+	for _, hint := range mcpFixHints {
+		for _, part := range hint.parts {
+			if strings.Contains(message, part) {
+				return strings.NewReplacer("{command}", command, "{url}", target).Replace(hint.fix)
+			}
+		}
+	}
+	return "Check the server's logs and its entry in `mcpServers`."
+}
+
+// mcpServerTarget describes how a server is reached in alerts. Arguments, URL paths
+// and query strings can carry tokens, so only the command name or URL host is shown.
+func mcpServerTarget(serverConf config.MCPServerConfig) string {
+	switch {
+	case serverConf.Builtin != "":
+		return fmt.Sprintf("built-in `%s` server", serverConf.Builtin)
+	case serverConf.URL != "":
+		return mcpURLHost(serverConf)
+	default:
+		return mcpCommandName(serverConf)
+	}
+}
+
+// mcpCommandName returns the name of the command a server runs, without its path
+// or arguments
+func mcpCommandName(serverConf config.MCPServerConfig) string {
+	if serverConf.Command == "" {
+		return ""
+	}
+	return filepath.Base(serverConf.Command)
+}
+
+// mcpURLHost returns the host, and port, of a server's URL
+func mcpURLHost(serverConf config.MCPServerConfig) string {
+	u, err := url.Parse(serverConf.URL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+// alertMCPServerDown posts an alert with the server name, its error and a suggested
+// fix to the admin channel, when one is configured
+func (c *Client) alertMCPServerDown(serverName, alertReason, summary, reason string) {
+	channel := c.cfg.MCPAlerts.Channel
+	if channel == "" {
+		return
+	}
+	monitoring.MCPServerAlerts.WithLabelValues(serverName, alertReason).Inc()
+	serverConf := c.cfg.MCPServers[serverName]
+	fix := mcpSuggestedFix(serverConf, reason)
+	if len(reason) > maxAlertErrorLength {
+		reason = reason[:maxAlertErrorLength] + "..."
+	}
+
+	headline := fmt.Sprintf(":rotating_light: MCP server *%s* %s.", serverName, summary)
+	if c.cfg.MCPAlerts.Mention != "" {
+		headline = c.cfg.MCPAlerts.Mention + " " + headline
+	}
+	text := fmt.Sprintf("%s\nError: %s\nSuggested fix: %s", headline, reason, fix)
+
+	frontend, ok := c.userFrontend.(ToolNoticeFrontend)
+	if !ok {
+		c.sendMessage(channel, "", text)
+		return
+	}
+	fields := []*slack.TextBlockObject{
+		slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("*Server*\n%s", serverName), false, false),
+	}
+	if target := mcpServerTarget(serverConf); target != "" {
+		fields = append(fields, slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("*Target*\n%s", target), false, false))
+	}
+	blocks := []slack.Block{
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, headline, false, false), fields, nil),
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("*Error*\n```%s```", reason), false, false), nil, nil),
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("*Suggested fix*\n%s", fix), false, false), nil, nil),
+	}
+	if _, err := frontend.PostBlocks(channel, "", text, blocks...); err != nil {
+		c.logger.WarnKV("Failed to post MCP server alert", "server", serverName, "channel", channel, "error", err)
+	}
+}
+
+// handleCircuitChange alerts about a server whose circuit breaker opened, and
+// announces that it is reachable again once the circuit closes
+func (c *Client) handleCircuitChange(event circuit.Event) {
+	if !event.Open {
+		c.logger.InfoKV("MCP server reachable again, circuit breaker closed", "server", event.Server)
+		if c.cfg.MCPAlerts.Channel != "" {
+			c.sendMessage(c.cfg.MCPAlerts.Channel, "", fmt.Sprintf(":white_check_mark: MCP server *%s* is reachable again.", event.Server))
+		}
+		return
+	}
+	c.logger.WarnKV("MCP server unreachable, circuit breaker opened", "server", event.Server, "failures", event.Failures, "error", event.Err)
+	c.alertMCPServerDown(event.Server, mcpAlertCircuitOpen,
+		fmt.Sprintf("is unreachable: its circuit breaker opened after %d failed tool calls", event.Failures), fmt.Sprint(event.Err))
+}
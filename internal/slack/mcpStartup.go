@@ -0,0 +1,145 @@
+package slackbot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+)
+
+// RegisterMCPServer makes a server that finished initializing after startup, and its
+// tools, available to the LLM and announces it in the startup notification channel.
+// Registering a server again, after its process was restarted, replaces its tools.
+// Tool name collisions are resolved with the configured strategy; with the "error"
+// strategy a colliding server is rejected.
+func (c *Client) RegisterMCPServer(serverName string, mcpClient *mcp.Client, tools map[string]mcp.ToolInfo) error {
+	collisions, err := c.registerMCPServer(serverName, mcpClient, tools)
+	if err != nil {
+		return err
+	}
+
+	for _, collision := range collisions {
+		c.logger.WarnKV("Tool name collision", "tool", collision.Tool, "kept", collision.Kept, "dropped", collision.Dropped)
+		c.AddStatusWarnings(collision.String())
+	}
+
+	c.circuits.Reset(serverName)
+	go c.indexTools()
+	c.logger.InfoKV("MCP server ready", "server", serverName, "tools", len(tools))
+	c.notifyMCPStartup(fmt.Sprintf(":white_check_mark: MCP server *%s* is ready with %d tools.", serverName, len(tools)))
+	return nil
+}
+
+// registerMCPServer merges a server's tools into the discovered tools and hands
+// them to the bridge. It holds mcpMu throughout, so servers registering at the
+// same time cannot leave the bridge with another's stale copy of the tools.
+func (c *Client) registerMCPServer(serverName string, mcpClient *mcp.Client, tools map[string]mcp.ToolInfo) ([]mcp.ToolCollision, error) {
+	c.mcpMu.Lock()
+	defer c.mcpMu.Unlock()
+
+	discoveredTools := make(map[string]mcp.ToolInfo, len(c.discoveredTools)+len(tools))
+	for name, tool := range c.discoveredTools {
+		if tool.ServerName != serverName {
+			discoveredTools[name] = tool
+		}
+	}
+	collisions, err := mcp.MergeTools(c.cfg.ToolCollision, discoveredTools, tools)
+	if err != nil {
+		return nil, fmt.Errorf("server '%s' rejected: %w", serverName, err)
+	}
+
+	if c.credentials != nil {
+		for _, name := range c.credentials.Servers() {
+			if name == serverName {
+				mcpClient.SetCredentialSource(c.credentials)
+			}
+		}
+	}
+
+	mcpClients := make(map[string]*mcp.Client, len(c.mcpClients)+1)
+	for name, existing := range c.mcpClients {
+		mcpClients[name] = existing
+	}
+	mcpClients[serverName] = mcpClient
+	c.mcpClients = mcpClients
+	c.discoveredTools = discoveredTools
+	c.llmMCPBridge.RegisterServer(serverName, mcpClient, discoveredTools)
+	return collisions, nil
+}
+
+// NotifyMCPServerFailed announces a server that could not be initialized, and
+// alerts the admin channel about it
+func (c *Client) NotifyMCPServerFailed(serverName, reason string) {
+	c.logger.WarnKV("MCP server failed to initialize", "server", serverName, "reason", reason)
+	c.AddStatusWarnings(fmt.Sprintf("MCP server '%s' failed to initialize: %s", serverName, reason))
+	c.notifyMCPFailure(fmt.Sprintf(":x: MCP server *%s* failed to initialize: %s", serverName, reason))
+	c.alertMCPServerDown(serverName, mcpAlertInitFailed, "failed to initialize", reason)
+}
+
+// NotifyMCPServerCrashed announces a stdio server whose process exited, and
+// whether it is being restarted or was given up on
+func (c *Client) NotifyMCPServerCrashed(serverName string, err error, restarting bool) {
+	if restarting {
+		c.notifyMCPStartup(fmt.Sprintf(":warning: MCP server *%s* crashed (%v) and is restarting.", serverName, err))
+		return
+	}
+	c.AddStatusWarnings(fmt.Sprintf("MCP server '%s' keeps crashing and was stopped: %v", serverName, err))
+	c.notifyMCPFailure(fmt.Sprintf(":x: MCP server *%s* keeps crashing and was stopped: %v", serverName, err))
+	c.alertMCPServerDown(serverName, mcpAlertStopped, "keeps crashing and was stopped", fmt.Sprint(err))
+}
+
+// AddStatusWarnings records warnings shown in the App Home status view
+func (c *Client) AddStatusWarnings(warnings ...string) {
+	c.mcpMu.Lock()
+	defer c.mcpMu.Unlock()
+	c.statusWarnings = append(c.statusWarnings, warnings...)
+}
+
+// MCPClients returns the MCP clients registered so far
+func (c *Client) MCPClients() map[string]*mcp.Client {
+	c.mcpMu.RLock()
+	defer c.mcpMu.RUnlock()
+	return c.mcpClients
+}
+
+// Tools returns the tools the LLM can call, keyed by name: the tools the bridge
+// was given, including native tools and servers registered after startup
+func (c *Client) Tools() map[string]mcp.ToolInfo {
+	available := map[string]mcp.ToolInfo{}
+	if c.llmMCPBridge != nil {
+		available = c.llmMCPBridge.Tools()
+	}
+	tools := make(map[string]mcp.ToolInfo, len(available))
+	for name, tool := range available {
+		tools[name] = tool
+	}
+	return tools
+}
+
+// notifyMCPStartup posts a message to the configured startup notification channel
+func (c *Client) notifyMCPStartup(text string) {
+	if c.cfg.MCPStartup.NotifyChannel == "" {
+		return
+	}
+	c.sendMessage(c.cfg.MCPStartup.NotifyChannel, "", text)
+}
+
+// notifyMCPFailure posts a failure to the startup notification channel, unless it
+// is also the admin channel, which gets a detailed alert instead
+func (c *Client) notifyMCPFailure(text string) {
+	if c.cfg.MCPStartup.NotifyChannel == c.cfg.MCPAlerts.Channel {
+		return
+	}
+	c.notifyMCPStartup(text)
+}
+
+// indexTools embeds the current tool descriptions ahead of the first request when
+// tool selection is enabled
+func (c *Client) indexTools() {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	if err := c.llmMCPBridge.IndexTools(ctx); err != nil {
+		c.logger.WarnKV("Failed to index tools for tool selection", "error", err)
+	}
+}
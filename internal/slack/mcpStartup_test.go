@@ -0,0 +1,39 @@
+package slackbot
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/handlers"
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+)
+
+func TestRegisterMCPServersConcurrently(t *testing.T) {
+	client, _, _ := newProgressTestClient()
+	client.llmMCPBridge = handlers.NewLLMMCPBridge(map[string]mcp.MCPClientInterface{}, log.New(os.Stderr, "", 0), nil, nil, client.cfg)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			server := fmt.Sprintf("server%d", i)
+			tool := fmt.Sprintf("tool%d", i)
+			err := client.RegisterMCPServer(server, &mcp.Client{}, map[string]mcp.ToolInfo{tool: {ToolName: tool, RemoteName: tool, ServerName: server}})
+			require.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	// Every server's tools reach the bridge, whatever order they registered in
+	assert.Len(t, client.discoveredTools, 20)
+	for i := 0; i < 20; i++ {
+		assert.True(t, client.llmMCPBridge.HasTool(fmt.Sprintf("tool%d", i)))
+	}
+}
@@ -0,0 +1,98 @@
+package slackbot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/memory"
+)
+
+const (
+	// memoryExtractTimeout bounds the LLM call that extracts facts from a prompt
+	memoryExtractTimeout = time.Minute
+	// maxListedFacts bounds the facts "memory list" shows
+	maxListedFacts = 50
+)
+
+// rememberFacts learns the durable facts stated in a prompt. Nothing is learned
+// from DMs, group DMs or private channels, whose facts would otherwise be recalled
+// for people who cannot read them. It runs alongside the answer, so failures are
+// only logged.
+func (c *Client) rememberFacts(userPrompt, channelID, threadTS, timestamp string, profile *UserProfile) {
+	if c.memory == nil || c.conversationType(channelID, "") != config.ConversationPublic {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), memoryExtractTimeout)
+	defer cancel()
+	source := memory.Source{ChannelID: channelID, ThreadTS: threadTS, MessageTS: timestamp, UserID: profile.userId}
+	if _, err := c.llmMCPBridge.ExtractMemory(ctx, userPrompt, profile.realName, source); err != nil {
+		c.logger.WarnKV("Failed to extract facts to remember", "channel", channelID, "user", profile.userId, "error", err)
+	}
+}
+
+// memoryCommand runs "memory list" and "memory delete <id>" for the user in the
+// channel and returns the reply
+func (c *Client) memoryCommand(args []string, userID, channelID string) string {
+	if c.memory == nil {
+		return "Long-term memory is not enabled."
+	}
+	if result := c.cfg.ValidateAccessWithDirectory(userID, channelID, c.security); !result.Allowed {
+		c.logger.WarnKV("Denied memory command", "user", userID, "channel", channelID, "reason", result.Reason)
+		if c.cfg.Security.RejectionMessage != "" {
+			return c.cfg.Security.RejectionMessage
+		}
+		return "You are not allowed to use this command here."
+	}
+	name := c.cfg.Slack.SlashCommand
+	switch {
+	case len(args) == 1 && strings.EqualFold(args[0], "list"):
+		return describeFacts(c.memory.List(userID, channelID))
+	case len(args) == 2 && strings.EqualFold(args[0], "delete"):
+		return c.deleteFact(strings.TrimPrefix(args[1], "#"), userID)
+	default:
+		return fmt.Sprintf("Usage: `%[1]s memory list` or `%[1]s memory delete <id>`", name)
+	}
+}
+
+// deleteFact forgets a fact. Users can delete facts about themselves and facts
+// they stated; admins can delete any fact.
+func (c *Client) deleteFact(id, userID string) string {
+	fact, ok := c.memory.Get(id)
+	if !ok {
+		return fmt.Sprintf("There is no fact `%s`.", id)
+	}
+	if fact.Scope != userID && fact.Source.UserID != userID && !c.cfg.IsAdminUser(userID, c.security) {
+		return fmt.Sprintf("Only <@%s>, the user it is about, or an admin can delete fact `%s`.", fact.Source.UserID, id)
+	}
+	if _, err := c.memory.Delete(id); err != nil {
+		c.logger.ErrorKV("Failed to delete fact", "id", id, "user", userID, "error", err)
+		return fmt.Sprintf("Failed to delete fact `%s`: %v", id, err)
+	}
+	c.logger.InfoKV("Deleted fact", "id", id, "user", userID)
+	return fmt.Sprintf("Forgot fact `%s`: %s", id, fact.Text)
+}
+
+// describeFacts lists facts with where each was learned
+func describeFacts(facts []memory.Fact) string {
+	if len(facts) == 0 {
+		return "I don't remember anything about you or this channel's team yet."
+	}
+	var b strings.Builder
+	b.WriteString("*What I remember*\n")
+	for i, fact := range facts {
+		if i == maxListedFacts {
+			fmt.Fprintf(&b, "_%d older facts not shown._\n", len(facts)-maxListedFacts)
+			break
+		}
+		about := "team"
+		if fact.Scope != memory.ScopeTeam {
+			about = fmt.Sprintf("<@%s>", fact.Scope)
+		}
+		fmt.Fprintf(&b, "• `%s` %s _(about %s, from <@%s> in <#%s> on %s)_\n",
+			fact.ID, fact.Text, about, fact.Source.UserID, fact.Source.ChannelID, fact.Created.Format("2006-01-02"))
+	}
+	return b.String()
+}
@@ -0,0 +1,73 @@
+package slackbot
+
+import (
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/memory"
+)
+
+func TestMemorySlashCommands(t *testing.T) {
+	client, _, output := newProgressTestClient()
+	runIn := func(channelID, userID, text string) string {
+		output.Reset()
+		client.handleSlashCommand(slack.SlashCommand{Command: "/mcp", Text: text, UserID: userID, ChannelID: channelID})
+		return output.String()
+	}
+	run := func(userID, text string) string { return runIn("C1", userID, text) }
+	assert.Contains(t, run("U1", "memory list"), "Long-term memory is not enabled.")
+
+	store, err := memory.NewStore("", 0)
+	require.NoError(t, err)
+	client.memory = store
+	assert.Contains(t, run("U1", "memory list"), "I don't remember anything")
+
+	_, _, err = store.Add(memory.Fact{Scope: memory.ScopeTeam, Text: "Deploys happen on Tuesdays", Source: memory.Source{ChannelID: "C1", UserID: "U2"}})
+	require.NoError(t, err)
+	_, _, err = store.Add(memory.Fact{Scope: "U3", Text: "Carol owns billing", Source: memory.Source{ChannelID: "C1", UserID: "U3"}})
+	require.NoError(t, err)
+
+	listed := run("U1", "memory list")
+	assert.Contains(t, listed, "• `1` Deploys happen on Tuesdays _(about team, from <@U2> in <#C1> on ")
+	assert.NotContains(t, listed, "Carol")
+	assert.NotContains(t, runIn("C2", "U1", "memory list"), "Deploys", "team facts stay in their channel")
+
+	// Only the user a fact is about, the user who stated it, or an admin can delete it
+	assert.Contains(t, run("U1", "memory delete 1"), "Only <@U2>, the user it is about, or an admin can delete fact `1`.")
+	assert.Contains(t, run("U3", "memory delete 2"), "Forgot fact `2`: Carol owns billing")
+	client.cfg.Security.AdminUsers = []string{"U1"}
+	assert.Contains(t, run("U1", "memory delete #1"), "Forgot fact `1`")
+	assert.Contains(t, run("U1", "memory delete 1"), "There is no fact `1`.")
+
+	assert.Contains(t, run("U1", "memory forget"), "Usage: `/mcp memory list` or `/mcp memory delete <id>`")
+	assert.Contains(t, run("U1", "help"), "`/mcp memory list`")
+
+	// Users without access to the bot in the channel cannot read or change memory
+	client.cfg.Security.Enabled = true
+	client.cfg.Security.AllowedUsers = []string{"U1"}
+	client.cfg.ApplyDefaults()
+	assert.Contains(t, run("U3", "memory list"), client.cfg.Security.RejectionMessage)
+
+	// Other slash commands are not the bot's
+	assert.Empty(t, func() string {
+		output.Reset()
+		client.handleSlashCommand(slack.SlashCommand{Command: "/other", Text: "memory list", UserID: "U1", ChannelID: "D1"})
+		return output.String()
+	}())
+}
+
+func TestNothingIsLearnedFromPrivateConversations(t *testing.T) {
+	client, _, _ := newProgressTestClient()
+	store, err := memory.NewStore("", 0)
+	require.NoError(t, err)
+	client.memory = store
+
+	// The bridge is never reached, so facts are neither extracted nor stored
+	for _, channelID := range []string{"D1", "G1"} {
+		client.rememberFacts("Deploys happen on Tuesdays", channelID, "100.1", "100.1", &UserProfile{userId: "U1"})
+	}
+	assert.Empty(t, store.List("U1", "D1"))
+}
@@ -0,0 +1,88 @@
+package slackbot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/monitoring"
+)
+
+// Directions of moderated content, used in logs and metrics
+const (
+	moderationInput  = "input"
+	moderationOutput = "output"
+)
+
+// moderate checks text against the content policy and applies the configured action.
+// It returns the text to send (replaced when blocked, annotated when configured) and
+// false when the text was blocked. If the classifier fails the text is allowed.
+func (c *Client) moderate(ctx context.Context, direction, text, channelID, threadTS, userID string) (string, bool) {
+	if c.moderator == nil {
+		return text, true
+	}
+	if direction == moderationInput && c.cfg.Moderation.CheckInput != nil && !*c.cfg.Moderation.CheckInput {
+		return text, true
+	}
+	if direction == moderationOutput && c.cfg.Moderation.CheckOutput != nil && !*c.cfg.Moderation.CheckOutput {
+		return text, true
+	}
+
+	result, err := c.moderator.Classify(ctx, text)
+	if err != nil {
+		c.logger.WarnKV("Content moderation check failed, allowing content", "direction", direction, "error", err)
+		monitoring.ModerationChecks.WithLabelValues(direction, "error").Inc()
+		return text, true
+	}
+	if !result.Flagged {
+		monitoring.ModerationChecks.WithLabelValues(direction, "passed").Inc()
+		return text, true
+	}
+
+	monitoring.ModerationChecks.WithLabelValues(direction, "flagged").Inc()
+	for _, category := range result.Categories {
+		monitoring.ModerationFlagged.WithLabelValues(direction, category).Inc()
+	}
+	action := c.cfg.Moderation.Action
+	c.logger.WarnKV("Content flagged by moderation",
+		"direction", direction, "action", action, "categories", result.Categories,
+		"channel", channelID, "thread_ts", threadTS, "user", userID)
+
+	categories := strings.Join(result.Categories, ", ")
+	if categories == "" {
+		categories = "unspecified"
+	}
+
+	if adminChannel := c.cfg.Moderation.AdminChannel; adminChannel != "" {
+		report := fmt.Sprintf(":warning: Content flagged by moderation (%s, action: %s)\n*Channel:* <#%s> (thread %s)\n*Categories:* %s\n>%s",
+			direction, action, channelID, threadTS, categories, logging.TruncateForLog(text, 300))
+		if userID != "" {
+			report += fmt.Sprintf("\n*User:* <@%s>", userID)
+		}
+		c.sendMessage(adminChannel, "", report)
+	}
+
+	switch action {
+	case config.ModerationActionBlock:
+		return c.cfg.Moderation.BlockMessage, false
+	case config.ModerationActionAnnotate:
+		note := fmt.Sprintf("_:warning: This %s was flagged by content moderation (%s)._", moderationSubject(direction), categories)
+		if direction == moderationInput {
+			c.sendMessage(channelID, threadTS, note)
+			return text, true
+		}
+		return text + "\n\n" + note, true
+	default:
+		return text, true
+	}
+}
+
+// moderationSubject names the moderated content in user-facing notes
+func moderationSubject(direction string) string {
+	if direction == moderationInput {
+		return "message"
+	}
+	return "response"
+}
@@ -0,0 +1,219 @@
+package slackbot
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/monitoring"
+)
+
+// outboundMessage is a reply waiting to be delivered to Slack
+type outboundMessage struct {
+	ChannelID string    `json:"channel_id"`
+	ThreadTS  string    `json:"thread_ts,omitempty"`
+	UpdateTS  string    `json:"update_ts,omitempty"` // Existing message to edit instead of posting a new one
+	Text      string    `json:"text"`
+	QueuedAt  time.Time `json:"queued_at"`
+}
+
+// deadLetter is the record written when a message could not be delivered
+type deadLetter struct {
+	outboundMessage
+	Attempts int       `json:"attempts"`
+	Error    string    `json:"error"`
+	FailedAt time.Time `json:"failed_at"`
+}
+
+// outboundQueue delivers messages to Slack in order from a single worker,
+// retrying rate-limited and transient failures before dead-lettering them.
+type outboundQueue struct {
+	deliver        func(msg outboundMessage) error
+	messages       chan outboundMessage
+	maxAttempts    int
+	baseBackoff    time.Duration
+	maxBackoff     time.Duration
+	deadLetterFile string
+	logger         *logging.Logger
+	sleep          func(time.Duration)
+
+	mu      sync.Mutex
+	closed  bool
+	senders sync.WaitGroup // Enqueues waiting for room in the queue, which close waits for
+	done    chan struct{}
+}
+
+// newOutboundQueue creates the queue and starts its worker
+func newOutboundQueue(cfg config.SlackOutboundConfig, deliver func(msg outboundMessage) error, logger *logging.Logger) *outboundQueue {
+	baseBackoff, err := time.ParseDuration(cfg.BaseBackoff)
+	if err != nil || baseBackoff <= 0 {
+		baseBackoff = time.Second
+	}
+	maxBackoff, err := time.ParseDuration(cfg.MaxBackoff)
+	if err != nil || maxBackoff < baseBackoff {
+		maxBackoff = 30 * time.Second
+	}
+	maxAttempts := cfg.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 5
+	}
+	queueSize := cfg.QueueSize
+	if queueSize <= 0 {
+		queueSize = 100
+	}
+
+	q := &outboundQueue{
+		deliver:        deliver,
+		messages:       make(chan outboundMessage, queueSize),
+		maxAttempts:    maxAttempts,
+		baseBackoff:    baseBackoff,
+		maxBackoff:     maxBackoff,
+		deadLetterFile: cfg.DeadLetterFile,
+		logger:         logger,
+		sleep:          time.Sleep,
+		done:           make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+// enqueue adds a message to the queue. It blocks while the queue is full so
+// that replies are delayed rather than dropped under sustained rate limiting.
+func (q *outboundQueue) enqueue(channelID, threadTS, text string) {
+	q.enqueueMessage(outboundMessage{ChannelID: channelID, ThreadTS: threadTS, Text: text})
+}
+
+// enqueueMessage queues a prepared message, such as an edit of an existing message
+func (q *outboundQueue) enqueueMessage(msg outboundMessage) {
+	msg.QueuedAt = time.Now()
+
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		// Queue is shutting down; deliver inline so the reply is not lost
+		q.process(msg)
+		return
+	}
+	q.senders.Add(1)
+	q.mu.Unlock()
+
+	// The lock is not held while the queue is full, so other replies and close are
+	// not held up behind this one
+	defer q.senders.Done()
+	q.messages <- msg
+}
+
+// close stops accepting messages and waits for queued messages to be delivered
+func (q *outboundQueue) close() {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return
+	}
+	q.closed = true
+	q.mu.Unlock()
+	// The worker keeps draining the queue, so waiting enqueues finish
+	q.senders.Wait()
+	close(q.messages)
+	<-q.done
+}
+
+func (q *outboundQueue) run() {
+	defer close(q.done)
+	for msg := range q.messages {
+		q.process(msg)
+	}
+}
+
+// process attempts delivery with retries and dead-letters the message on failure
+func (q *outboundQueue) process(msg outboundMessage) {
+	backoff := q.baseBackoff
+	var err error
+	attempt := 1
+	for ; attempt <= q.maxAttempts; attempt++ {
+		err = q.deliver(msg)
+		if err == nil {
+			monitoring.SlackOutboundMessages.WithLabelValues("sent").Inc()
+			return
+		}
+		if !isRetryableSlackError(err) || attempt == q.maxAttempts {
+			break
+		}
+
+		delay := backoff
+		var rateLimited *slack.RateLimitedError
+		if errors.As(err, &rateLimited) && rateLimited.RetryAfter > 0 {
+			delay = rateLimited.RetryAfter
+		}
+		q.logger.WarnKV("Slack message delivery failed, retrying",
+			"channel", msg.ChannelID, "attempt", attempt, "retry_in", delay, "error", err)
+		monitoring.SlackOutboundMessages.WithLabelValues("retried").Inc()
+		q.sleep(delay)
+
+		backoff *= 2
+		if backoff > q.maxBackoff {
+			backoff = q.maxBackoff
+		}
+	}
+
+	if attempt > q.maxAttempts {
+		attempt = q.maxAttempts
+	}
+	q.writeDeadLetter(msg, attempt, err)
+}
+
+// writeDeadLetter records an undeliverable message in the log and, if configured, the dead-letter file
+func (q *outboundQueue) writeDeadLetter(msg outboundMessage, attempts int, err error) {
+	monitoring.SlackOutboundMessages.WithLabelValues("dead_lettered").Inc()
+	q.logger.ErrorKV("Slack message could not be delivered, moved to dead-letter log",
+		"channel", msg.ChannelID, "thread_ts", msg.ThreadTS, "attempts", attempts,
+		"error", err, "text", logging.TruncateForLog(msg.Text, 200))
+
+	if q.deadLetterFile == "" {
+		return
+	}
+
+	record := deadLetter{
+		outboundMessage: msg,
+		Attempts:        attempts,
+		Error:           err.Error(),
+		FailedAt:        time.Now(),
+	}
+	line, marshalErr := json.Marshal(record)
+	if marshalErr != nil {
+		q.logger.ErrorKV("Failed to marshal dead-letter record", "error", marshalErr)
+		return
+	}
+
+	f, openErr := os.OpenFile(q.deadLetterFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if openErr != nil {
+		q.logger.ErrorKV("Failed to open dead-letter file", "file", q.deadLetterFile, "error", openErr)
+		return
+	}
+	defer func() {
+		if closeErr := f.Close(); closeErr != nil {
+			q.logger.ErrorKV("Failed to close dead-letter file", "file", q.deadLetterFile, "error", closeErr)
+		}
+	}()
+	if _, writeErr := f.Write(append(line, '\n')); writeErr != nil {
+		q.logger.ErrorKV("Failed to write dead-letter record", "file", q.deadLetterFile, "error", writeErr)
+	}
+}
+
+// isRetryableSlackError reports whether a Slack API error is worth retrying:
+// rate limits, 5xx responses and network errors.
+func isRetryableSlackError(err error) bool {
+	var retryable interface{ Retryable() bool }
+	if errors.As(err, &retryable) {
+		return retryable.Retryable()
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
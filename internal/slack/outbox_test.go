@@ -0,0 +1,112 @@
+package slackbot
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+func TestOutboundQueueRetriesWithRetryAfter(t *testing.T) {
+	var delays []time.Duration
+	attempts := 0
+	deliver := func(msg outboundMessage) error {
+		attempts++
+		if attempts == 1 {
+			return &slack.RateLimitedError{RetryAfter: 7 * time.Second}
+		}
+		if attempts == 2 {
+			return slack.StatusCodeError{Code: 503, Status: "503 Service Unavailable"}
+		}
+		return nil
+	}
+
+	q := newOutboundQueue(config.SlackOutboundConfig{MaxAttempts: 5, BaseBackoff: "1s", MaxBackoff: "10s"},
+		deliver, logging.New("test", logging.LevelError))
+	q.sleep = func(d time.Duration) { delays = append(delays, d) }
+
+	q.process(outboundMessage{ChannelID: "C1", Text: "hello"})
+	q.close()
+
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, []time.Duration{7 * time.Second, 2 * time.Second}, delays)
+}
+
+func TestOutboundQueueDeadLetter(t *testing.T) {
+	deadLetterFile := filepath.Join(t.TempDir(), "dead-letter.jsonl")
+	attempts := 0
+	deliver := func(msg outboundMessage) error {
+		attempts++
+		return errors.New("channel_not_found")
+	}
+
+	q := newOutboundQueue(config.SlackOutboundConfig{MaxAttempts: 3, DeadLetterFile: deadLetterFile},
+		deliver, logging.New("test", logging.LevelError))
+	q.sleep = func(time.Duration) {}
+
+	q.enqueue("C1", "123.456", "undeliverable")
+	q.close()
+
+	// Non-retryable errors are not retried
+	assert.Equal(t, 1, attempts)
+
+	data, err := os.ReadFile(deadLetterFile)
+	assert.NoError(t, err)
+	assert.True(t, strings.Contains(string(data), `"text":"undeliverable"`))
+	assert.True(t, strings.Contains(string(data), `"error":"channel_not_found"`))
+}
+
+func TestOutboundQueueFullDoesNotHoldLock(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var delivered []string
+	deliver := func(msg outboundMessage) error {
+		<-release
+		mu.Lock()
+		defer mu.Unlock()
+		delivered = append(delivered, msg.Text)
+		return nil
+	}
+	q := newOutboundQueue(config.SlackOutboundConfig{QueueSize: 1}, deliver, logging.New("test", logging.LevelError))
+
+	// The worker holds the first message and the second fills the queue, so the third waits
+	q.enqueue("C1", "", "first")
+	q.enqueue("C1", "", "second")
+	waiting := make(chan struct{})
+	go func() {
+		defer close(waiting)
+		q.enqueue("C1", "", "third")
+	}()
+	assert.Eventually(t, func() bool {
+		if !q.mu.TryLock() {
+			return false
+		}
+		defer q.mu.Unlock()
+		return len(q.messages) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		q.close()
+	}()
+	assert.Eventually(t, func() bool {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		return q.closed
+	}, time.Second, 5*time.Millisecond, "close is not held up by the waiting enqueue")
+
+	close(release)
+	<-waiting
+	<-closed
+	assert.ElementsMatch(t, []string{"first", "second", "third"}, delivered)
+}
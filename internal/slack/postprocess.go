@@ -0,0 +1,80 @@
+package slackbot
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+// linkPattern matches http and https links, bare or inside Slack's <url|label>
+// and Markdown's [label](url) forms
+var linkPattern = regexp.MustCompile(`https?://[^\s<>|]+`)
+
+// linkTrailers are characters that end a sentence or a Markdown link rather than
+// the URL before them
+const linkTrailers = ".,;:!?)]*_'\""
+
+// postProcessor is a compiled slack.postProcessors entry
+type postProcessor struct {
+	config.SlackPostProcessorConfig
+	pattern *regexp.Regexp // Nil when no pattern is set
+}
+
+// newPostProcessors compiles the post-processors of slack.postProcessors
+func newPostProcessors(configs []config.SlackPostProcessorConfig) ([]postProcessor, error) {
+	processors := make([]postProcessor, 0, len(configs))
+	for i, cfg := range configs {
+		processor := postProcessor{SlackPostProcessorConfig: cfg}
+		if cfg.Pattern != "" {
+			re, err := regexp.Compile(cfg.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern for post-processor %d: %w", i, err)
+			}
+			processor.pattern = re
+		}
+		processors = append(processors, processor)
+	}
+	return processors, nil
+}
+
+// postProcess applies the channel's post-processors to a message about to be
+// posted. Text is appended only when last is true, so an answer sent as several
+// messages carries it once, under the final one.
+func (c *Client) postProcess(channelID, text string, last bool) string {
+	for _, processor := range c.postProcessors {
+		if !processor.AppliesTo(channelID) {
+			continue
+		}
+		switch processor.Type {
+		case config.PostProcessorAppend:
+			if last {
+				text = strings.TrimSpace(strings.TrimSpace(text) + "\n\n" + processor.Text)
+			}
+		case config.PostProcessorStrip:
+			text = processor.pattern.ReplaceAllString(text, processor.Replacement)
+		case config.PostProcessorRewriteLinks:
+			text = linkPattern.ReplaceAllStringFunc(text, processor.rewriteLink)
+		}
+	}
+	return text
+}
+
+// sendMessage posts a message of the bot after the channel's post-processors. Every
+// message but answers goes through it; answers are processed where they are split
+// into messages, so appended text follows only the last of them.
+func (c *Client) sendMessage(channelID, threadTS, text string) {
+	c.userFrontend.SendMessage(channelID, threadTS, c.postProcess(channelID, text, true))
+}
+
+// rewriteLink returns the link rewritten through the template, keeping any
+// trailing punctuation matched with it
+func (p postProcessor) rewriteLink(match string) string {
+	link := strings.TrimRight(match, linkTrailers)
+	if p.pattern != nil && !p.pattern.MatchString(link) {
+		return match
+	}
+	return strings.ReplaceAll(p.Template, "{url}", url.QueryEscape(link)) + match[len(link):]
+}
@@ -0,0 +1,55 @@
+package slackbot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/jobs"
+)
+
+func TestPostProcess(t *testing.T) {
+	processors, err := newPostProcessors([]config.SlackPostProcessorConfig{
+		{Type: config.PostProcessorStrip, Pattern: `(?i)ticket-\d+`, Replacement: "[redacted]"},
+		{Type: config.PostProcessorRewriteLinks, Pattern: `^https://(www\.)?example\.com`, Template: "https://proxy.corp/go?u={url}"},
+		{Type: config.PostProcessorAppend, Text: "_AI-generated, verify before acting._", Channels: []string{"C1"}},
+	})
+	require.NoError(t, err)
+	client := &Client{postProcessors: processors}
+
+	answer := "See TICKET-12 and https://example.com/runbook?step=2. Also <https://internal.corp/x|docs> or [guide](https://www.example.com/guide)."
+	assert.Equal(t,
+		"See [redacted] and https://proxy.corp/go?u=https%3A%2F%2Fexample.com%2Frunbook%3Fstep%3D2. "+
+			"Also <https://internal.corp/x|docs> or [guide](https://proxy.corp/go?u=https%3A%2F%2Fwww.example.com%2Fguide).\n\n"+
+			"_AI-generated, verify before acting._",
+		client.postProcess("C1", answer, true))
+
+	assert.Equal(t, "Step one", client.postProcess("C1", "Step one", false), "text is appended to the last message only")
+	assert.Equal(t, "_AI-generated, verify before acting._", client.postProcess("C1", "", true))
+	assert.Equal(t, "Done", client.postProcess("C2", "Done", true), "the disclaimer only applies to its channels")
+
+	_, err = newPostProcessors([]config.SlackPostProcessorConfig{{Type: config.PostProcessorStrip, Pattern: "("}})
+	assert.ErrorContains(t, err, "invalid pattern")
+}
+
+func TestPostProcessorsApplyToJobOutput(t *testing.T) {
+	client, store, output := newJobsTestClient(t, backupTool{})
+	processors, err := newPostProcessors([]config.SlackPostProcessorConfig{
+		{Type: config.PostProcessorStrip, Pattern: `prod`, Replacement: "[cluster]"},
+		{Type: config.PostProcessorAppend, Text: "_AI-generated, verify before acting._"},
+	})
+	require.NoError(t, err)
+	client.postProcessors = processors
+
+	_, err = store.Add(jobs.Job{Tool: "backup_check", Args: map[string]interface{}{"cluster": "prod"}, RunAt: time.Now().Add(-time.Minute), ChannelID: "C1", UserID: "U1"})
+	require.NoError(t, err)
+	due, err := store.TakeDue(time.Now())
+	require.NoError(t, err)
+	client.runJob(context.Background(), due[0])
+	assert.Contains(t, output.String(), "backups of [cluster] ok")
+	assert.Contains(t, output.String(), "```\n\n_AI-generated, verify before acting._")
+}
@@ -0,0 +1,262 @@
+package slackbot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/tmc/langchaingo/llms"
+
+	"github.com/tuannvm/slack-mcp-client/internal/rag"
+)
+
+// Progress shown in the placeholder message while a prompt is answered
+const (
+	progressToolStatus      = "Calling tool `%s`..."
+	progressSearchingStatus = "Searching the knowledge base..."
+	progressWritingStatus   = "Writing the answer..."
+	progressIngestStatus    = "Ingesting documents: %d of %d files done..."
+)
+
+// ingestProgressInterval bounds how often ingestion progress edits the placeholder,
+// which Slack rate limits
+const ingestProgressInterval = 3 * time.Second
+
+// ragSearchToolName is the knowledge base search tool, which gets its own status
+const ragSearchToolName = "rag_search"
+
+// ProgressFrontend is implemented by frontends that can edit messages. The thinking
+// message becomes a placeholder that shows progress and is then edited into the
+// answer. Frontends without it post the thinking message and a separate answer.
+type ProgressFrontend interface {
+	// PostPlaceholder posts a placeholder message and returns its timestamp
+	PostPlaceholder(channelID, threadTS, text string) (string, error)
+	// UpdatePlaceholder replaces the placeholder text with a progress status
+	UpdatePlaceholder(channelID, ts, text string) error
+	// ReplacePlaceholder queues the edit of the placeholder into the answer
+	ReplacePlaceholder(channelID, threadTS, ts, text string)
+	// DeletePlaceholder removes a placeholder that no answer replaced
+	DeletePlaceholder(channelID, ts string)
+}
+
+// PostPlaceholder posts the placeholder directly rather than through the outbound
+// queue, since its timestamp is needed for the edits that follow
+func (slackClient *SlackClient) PostPlaceholder(channelID, threadTS, text string) (string, error) {
+	options := []slack.MsgOption{slack.MsgOptionText(text, false)}
+	if threadTS != "" {
+		options = append(options, slack.MsgOptionTS(threadTS))
+	}
+	_, ts, err := slackClient.PostMessage(channelID, options...)
+	if err != nil {
+		return "", err
+	}
+	slackClient.placeholderMu.Lock()
+	defer slackClient.placeholderMu.Unlock()
+	if slackClient.placeholders == nil {
+		slackClient.placeholders = make(map[string]bool)
+	}
+	slackClient.placeholders[ts] = true
+	return ts, nil
+}
+
+// UpdatePlaceholder edits the placeholder text. Statuses are best effort and are
+// not retried.
+func (slackClient *SlackClient) UpdatePlaceholder(channelID, ts, text string) error {
+	_, _, _, err := slackClient.UpdateMessage(channelID, ts, slack.MsgOptionText(text, false))
+	if err == nil {
+		slackClient.threadReplies().invalidateMessage(channelID, ts)
+	}
+	return err
+}
+
+// ReplacePlaceholder queues the answer as an edit of the placeholder, so it stays
+// in order with the other replies and is retried like them
+func (slackClient *SlackClient) ReplacePlaceholder(channelID, threadTS, ts, text string) {
+	if text == "" {
+		slackClient.logger.WarnKV("Attempted to send empty message, skipping", "channel", channelID)
+		slackClient.DeletePlaceholder(channelID, ts)
+		return
+	}
+	slackClient.forgetPlaceholder(ts)
+	slackClient.outbox.enqueueMessage(outboundMessage{ChannelID: channelID, ThreadTS: threadTS, UpdateTS: ts, Text: text})
+}
+
+// DeletePlaceholder removes the placeholder message
+func (slackClient *SlackClient) DeletePlaceholder(channelID, ts string) {
+	slackClient.forgetPlaceholder(ts)
+	if _, _, err := slackClient.DeleteMessage(channelID, ts); err != nil {
+		slackClient.logger.WarnKV("Failed to delete placeholder message", "channel", channelID, "ts", ts, "error", err)
+		return
+	}
+	slackClient.threadReplies().removeMessage(channelID, ts)
+}
+
+func (slackClient *SlackClient) forgetPlaceholder(ts string) {
+	slackClient.placeholderMu.Lock()
+	defer slackClient.placeholderMu.Unlock()
+	delete(slackClient.placeholders, ts)
+}
+
+// isPlaceholder reports whether a message is a placeholder awaiting its answer
+func (slackClient *SlackClient) isPlaceholder(ts string) bool {
+	slackClient.placeholderMu.Lock()
+	defer slackClient.placeholderMu.Unlock()
+	return slackClient.placeholders[ts]
+}
+
+// progressMessage is the placeholder of one interaction
+type progressMessage struct {
+	mu        sync.Mutex
+	channelID string
+	threadTS  string
+	ts        string
+	answered  bool
+}
+
+type progressKey struct{}
+
+// progressFrom returns the interaction's placeholder, if one was posted
+func progressFrom(ctx context.Context) *progressMessage {
+	progress, _ := ctx.Value(progressKey{}).(*progressMessage)
+	return progress
+}
+
+// showThinking shows that a prompt is being worked on: a thread status in the
+// assistant container, otherwise a placeholder message that later becomes the
+// answer, or the thinking message on frontends that cannot edit messages
+func (c *Client) showThinking(ctx context.Context, channelID, threadTS string) context.Context {
+	if c.setAssistantStatus(channelID, threadTS, c.cfg.Slack.Assistant.ThinkingStatus) {
+		return ctx
+	}
+	frontend, ok := c.userFrontend.(ProgressFrontend)
+	if !ok || !c.cfg.Slack.ProgressUpdatesEnabled() {
+		c.userFrontend.SendMessage(channelID, threadTS, c.cfg.Slack.ThinkingMessage)
+		return ctx
+	}
+	ts, err := frontend.PostPlaceholder(channelID, threadTS, c.cfg.Slack.ThinkingMessage)
+	if err != nil {
+		c.logger.WarnKV("Failed to post placeholder message", "channel", channelID, "error", err)
+		return ctx
+	}
+	return context.WithValue(ctx, progressKey{}, &progressMessage{channelID: channelID, threadTS: threadTS, ts: ts})
+}
+
+// showStatus reports a step of the interaction in the assistant thread status or
+// the placeholder message
+func (c *Client) showStatus(ctx context.Context, channelID, threadTS, assistantStatus, placeholderStatus string) {
+	if requestCancelled(ctx) {
+		return
+	}
+	if c.setAssistantStatus(channelID, threadTS, assistantStatus) {
+		return
+	}
+	progress := progressFrom(ctx)
+	if progress == nil {
+		return
+	}
+	progress.mu.Lock()
+	defer progress.mu.Unlock()
+	if progress.answered {
+		return
+	}
+	frontend := c.userFrontend.(ProgressFrontend)
+	if err := frontend.UpdatePlaceholder(progress.channelID, progress.ts, placeholderStatus); err != nil {
+		c.logger.DebugKV("Failed to update placeholder message", "channel", channelID, "error", err)
+	}
+}
+
+// showToolStatus shows which tool the response is waiting on
+func (c *Client) showToolStatus(ctx context.Context, channelID, threadTS string, llmResponse *llms.ContentChoice) {
+	toolName := pendingToolName(llmResponse)
+	if toolName == "" {
+		return
+	}
+	if toolName == ragSearchToolName {
+		c.showStatus(ctx, channelID, threadTS, c.cfg.Slack.Assistant.SearchingStatus, progressSearchingStatus)
+		return
+	}
+	c.showStatus(ctx, channelID, threadTS, fmt.Sprintf(c.cfg.Slack.Assistant.ToolStatus, toolName),
+		fmt.Sprintf(progressToolStatus, toolName))
+}
+
+// withIngestProgress shows how many files a rag_ingest call of the interaction has
+// ingested, in the assistant thread status or the placeholder message
+func (c *Client) withIngestProgress(ctx context.Context, channelID, threadTS string) context.Context {
+	var last time.Time
+	statusCtx := ctx
+	return rag.WithIngestProgress(ctx, func(progress rag.IngestProgress) {
+		if progress.Done < progress.Total && time.Since(last) < ingestProgressInterval {
+			return
+		}
+		last = time.Now()
+		status := fmt.Sprintf(progressIngestStatus, progress.Done, progress.Total)
+		c.showStatus(statusCtx, channelID, threadTS, status, status)
+	})
+}
+
+// reply sends a message of the interaction. The first reply replaces the
+// placeholder; later replies are posted as new messages. Nothing is sent once
+// the user stopped the interaction.
+func (c *Client) reply(ctx context.Context, channelID, threadTS, text string) {
+	if requestCancelled(ctx) {
+		c.logger.DebugKV("Dropping reply of a cancelled request", "channel", channelID, "thread_ts", threadTS)
+		return
+	}
+	c.sendReply(ctx, channelID, threadTS, text)
+}
+
+// sendReply is reply without the cancellation check
+func (c *Client) sendReply(ctx context.Context, channelID, threadTS, text string) {
+	c.recordWorkflowReply(channelID, threadTS, text)
+	if progress := progressFrom(ctx); progress != nil {
+		progress.mu.Lock()
+		first := !progress.answered
+		progress.answered = true
+		progress.mu.Unlock()
+		if first {
+			c.userFrontend.(ProgressFrontend).ReplacePlaceholder(channelID, threadTS, progress.ts, text)
+			return
+		}
+	}
+	c.userFrontend.SendMessage(channelID, threadTS, text)
+}
+
+// clearProgress deletes the placeholder if the interaction ended without a reply
+func (c *Client) clearProgress(ctx context.Context) {
+	progress := progressFrom(ctx)
+	if progress == nil {
+		return
+	}
+	progress.mu.Lock()
+	answered := progress.answered
+	progress.answered = true
+	progress.mu.Unlock()
+	if !answered {
+		c.userFrontend.(ProgressFrontend).DeletePlaceholder(progress.channelID, progress.ts)
+	}
+}
+
+// pendingToolName returns the tool an LLM response asks to call, if any
+func pendingToolName(llmResponse *llms.ContentChoice) string {
+	if llmResponse == nil {
+		return ""
+	}
+	for _, toolCall := range llmResponse.ToolCalls {
+		if toolCall.FunctionCall != nil {
+			return toolCall.FunctionCall.Name
+		}
+	}
+	if llmResponse.FuncCall != nil {
+		return llmResponse.FuncCall.Name
+	}
+	var toolCall struct {
+		Tool string `json:"tool"`
+	}
+	if err := json.Unmarshal([]byte(llmResponse.Content), &toolCall); err == nil {
+		return toolCall.Tool
+	}
+	return ""
+}
@@ -0,0 +1,95 @@
+package slackbot
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tmc/langchaingo/llms"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+// progressRecorder is a frontend that can edit messages and records the edits
+type progressRecorder struct {
+	*StdioClient
+	posted   []string
+	updates  []string
+	replaced []string
+	deleted  []string
+}
+
+func (r *progressRecorder) PostPlaceholder(_, _, text string) (string, error) {
+	r.posted = append(r.posted, text)
+	return "111.1", nil
+}
+
+func (r *progressRecorder) UpdatePlaceholder(_, _, text string) error {
+	r.updates = append(r.updates, text)
+	return nil
+}
+
+func (r *progressRecorder) ReplacePlaceholder(_, _, ts, text string) {
+	r.replaced = append(r.replaced, ts+" "+text)
+}
+
+func (r *progressRecorder) DeletePlaceholder(_, ts string) {
+	r.deleted = append(r.deleted, ts)
+}
+
+func newProgressTestClient() (*Client, *progressRecorder, *bytes.Buffer) {
+	cfg := &config.Config{}
+	cfg.ApplyDefaults()
+	logger := logging.New("test", logging.LevelError)
+	output := &bytes.Buffer{}
+	stdio := NewStdioClient(logger)
+	stdio.Output = output
+	frontend := &progressRecorder{StdioClient: stdio}
+	return &Client{cfg: cfg, userFrontend: frontend, logger: logger}, frontend, output
+}
+
+func TestProgressPlaceholderBecomesAnswer(t *testing.T) {
+	client, frontend, output := newProgressTestClient()
+
+	ctx := client.showThinking(context.Background(), "C1", "100.1")
+	assert.Equal(t, []string{"Thinking..."}, frontend.posted)
+
+	client.showToolStatus(ctx, "C1", "100.1", &llms.ContentChoice{Content: `{"tool":"list_alerts","args":{}}`})
+	client.showToolStatus(ctx, "C1", "100.1", &llms.ContentChoice{Content: `{"tool":"rag_search","args":{}}`})
+	client.showStatus(ctx, "C1", "100.1", "", progressWritingStatus)
+	assert.Equal(t, []string{"Calling tool `list_alerts`...", progressSearchingStatus, progressWritingStatus}, frontend.updates)
+
+	client.reply(ctx, "C1", "100.1", "Two alerts are firing.")
+	client.reply(ctx, "C1", "100.1", "Sources: runbook")
+	client.clearProgress(ctx)
+	assert.Equal(t, []string{"111.1 Two alerts are firing."}, frontend.replaced)
+	assert.Contains(t, output.String(), "Sources: runbook")
+	assert.Empty(t, frontend.deleted)
+
+	// Statuses after the answer are ignored
+	client.showStatus(ctx, "C1", "100.1", "", progressWritingStatus)
+	assert.Len(t, frontend.updates, 3)
+}
+
+func TestProgressPlaceholderDeletedWithoutAnswer(t *testing.T) {
+	client, frontend, _ := newProgressTestClient()
+
+	ctx := client.showThinking(context.Background(), "C1", "100.1")
+	client.clearProgress(ctx)
+	assert.Equal(t, []string{"111.1"}, frontend.deleted)
+}
+
+func TestProgressUpdatesDisabled(t *testing.T) {
+	client, frontend, output := newProgressTestClient()
+	disabled := false
+	client.cfg.Slack.ProgressUpdates = &disabled
+
+	ctx := client.showThinking(context.Background(), "C1", "100.1")
+	client.reply(ctx, "C1", "100.1", "Done.")
+	assert.Empty(t, frontend.posted)
+	assert.Empty(t, frontend.replaced)
+	assert.Contains(t, output.String(), "Thinking...")
+	assert.Contains(t, output.String(), "Done.")
+}
@@ -0,0 +1,224 @@
+package slackbot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/handlers"
+	"github.com/tuannvm/slack-mcp-client/internal/llm"
+	"github.com/tuannvm/slack-mcp-client/internal/rag"
+	"github.com/tuannvm/slack-mcp-client/pkg/middleware"
+)
+
+// ragAnswerDecline is the reply the LLM gives when the retrieved contexts do not
+// answer the question
+const ragAnswerDecline = "NOT_IN_KNOWLEDGE_BASE"
+
+// ragAnswerInstructions asks for an answer grounded in the retrieved contexts
+const ragAnswerInstructions = "Answer the user's question using only the knowledge base contexts provided with it. " +
+	"If the contexts do not contain the answer, reply with exactly " + ragAnswerDecline + " and nothing else."
+
+// ragClassifierPrompt asks whether a message can be answered from the knowledge base
+const ragClassifierPrompt = "Decide whether the following Slack message is a question that internal documentation " +
+	"(such as runbooks, guides or policies) could answer, rather than a request to perform an action, " +
+	"fetch live data or chat. Reply with only \"yes\" or \"no\".\n\nMessage: %s"
+
+// answerFromKnowledgeBase handles the prompt in RAG-first mode: when the question is
+// about the knowledge base, it is answered from the retrieved contexts in a single
+// LLM call, without the tool-call round trip. It returns false when the prompt should
+// go through the regular pipeline instead.
+func (c *Client) answerFromKnowledgeBase(ctx context.Context, userPrompt, contextHistory, channelID, threadTS, userID string) bool {
+	answerCfg := c.cfg.RAG.Answer
+	if c.ragClient == nil || !answerCfg.AppliesToChannel(channelID) {
+		return false
+	}
+
+	ctx, span := c.tracingHandler.StartSpan(ctx, "rag-first-answer", "span", userPrompt, map[string]string{
+		"channel_id": channelID,
+		"thread_ts":  threadTS,
+		"classifier": answerCfg.Classifier,
+	})
+	defer span.End()
+
+	if answerCfg.Classifier == config.RAGAnswerClassifierLLM {
+		related, err := c.isKnowledgeBaseQuestion(ctx, userPrompt)
+		if err != nil {
+			c.logger.WarnKV("Failed to classify prompt for RAG-first answer", "error", err)
+			c.tracingHandler.RecordError(span, err, "WARNING")
+			return false
+		}
+		if !related {
+			c.tracingHandler.SetOutput(span, "Not a knowledge base question")
+			return false
+		}
+	}
+
+	c.showStatus(ctx, channelID, threadTS, c.cfg.Slack.Assistant.SearchingStatus, progressSearchingStatus)
+	results, err := c.ragClient.Retrieve(ctx, userPrompt)
+	if err != nil {
+		c.logger.WarnKV("Knowledge base search failed, using the regular pipeline", "error", err)
+		c.tracingHandler.RecordError(span, err, "WARNING")
+		return false
+	}
+	results = relevantResults(results, answerCfg.MinScore, answerCfg.MaxResults)
+	if len(results) == 0 {
+		c.tracingHandler.SetOutput(span, "No relevant knowledge base results")
+		return false
+	}
+
+	// Record citations and stale sources separately, so a declined answer leaves the
+	// request's sources untouched
+	answerCtx := ctx
+	if rag.CitationsFromContext(ctx) != nil {
+		answerCtx, _ = rag.WithCitationCollector(answerCtx)
+	}
+	if stale := rag.StaleSourcesFromContext(ctx); stale != nil {
+		answerCtx, _ = rag.WithStaleSources(answerCtx, c.cfg.RAG.Freshness.GetStaleAfter())
+	}
+	contexts := rag.FormatResults(answerCtx, userPrompt, results)
+	messages := ragAnswerMessages(c.customPrompt(ctx), contextHistory, handlers.ToolResultsFromContext(ctx), userPrompt, contexts)
+
+	providerCfg := c.cfg.LLM.Providers[c.cfg.LLM.Provider]
+	llmCtx, llmSpan := c.tracingHandler.StartLLMSpan(ctx, "llm-rag-answer", providerCfg.Model, userPrompt, map[string]interface{}{
+		"temperature": providerCfg.Temperature,
+		"max_tokens":  providerCfg.MaxTokens,
+		"contexts":    len(results),
+	})
+	startTime := time.Now()
+	response, err := c.llmRegistry.GenerateChatCompletion(llmCtx, c.cfg.LLM.Provider, messages, llm.ProviderOptions{
+		Temperature: providerCfg.Temperature,
+		MaxTokens:   providerCfg.MaxTokens,
+	})
+	c.tracingHandler.SetDuration(llmSpan, time.Since(startTime))
+	if err != nil {
+		c.logger.WarnKV("RAG-first answer failed, using the regular pipeline", "error", err)
+		c.tracingHandler.RecordError(llmSpan, err, "WARNING")
+		llmSpan.End()
+		return false
+	}
+	c.tracingHandler.SetOutput(llmSpan, response.Content)
+	c.tracingHandler.RecordSuccess(llmSpan, "RAG-first answer generated")
+	llmSpan.End()
+
+	answer := strings.TrimSpace(response.Content)
+	if answer == "" || strings.Contains(answer, ragAnswerDecline) {
+		c.logger.DebugKV("Knowledge base does not answer the prompt, using the regular pipeline", "channel", channelID)
+		c.tracingHandler.SetOutput(span, "Declined: contexts do not answer the question")
+		return false
+	}
+
+	answer = c.groundAnswer(ctx, groundingSourceRAG, userPrompt, contexts, answer, func(feedback string) (string, error) {
+		retry := append(messages,
+			llm.RequestMessage{Role: "assistant", Content: answer},
+			llm.RequestMessage{Role: "user", Content: feedback})
+		regenerated, err := c.llmRegistry.GenerateChatCompletion(ctx, c.cfg.LLM.Provider, retry, llm.ProviderOptions{
+			Temperature: providerCfg.Temperature,
+			MaxTokens:   providerCfg.MaxTokens,
+		})
+		if err != nil {
+			return "", err
+		}
+		if strings.Contains(regenerated.Content, ragAnswerDecline) {
+			return "", fmt.Errorf("regenerated answer declined the question")
+		}
+		return strings.TrimSpace(regenerated.Content), nil
+	})
+
+	c.logger.InfoKV("Answered from the knowledge base", "channel", channelID, "contexts", len(results), "length", len(answer))
+	c.addToHistory(channelID, threadTS, "", "assistant", answer, "", "", "")
+	answer, _ = c.moderate(ctx, moderationOutput, answer, channelID, threadTS, userID)
+	answer = c.runResponseHooks(ctx, answer)
+	answer = c.postProcess(channelID, withCitations(answerCtx, answer), true)
+	c.reply(ctx, channelID, threadTS, answer)
+	c.tracingHandler.SetOutput(span, answer)
+	c.tracingHandler.RecordSuccess(span, "Answered from the knowledge base")
+	return true
+}
+
+// ragClassifierSchema constrains the classifier reply in structured output mode
+var ragClassifierSchema = &llm.ResponseSchema{
+	Name:        "knowledge_base_question",
+	Description: "whether the message is a knowledge base question",
+	Strict:      true,
+	Schema: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"knowledgeBaseQuestion": map[string]interface{}{"type": "boolean"},
+		},
+		"required":             []string{"knowledgeBaseQuestion"},
+		"additionalProperties": false,
+	},
+}
+
+// isKnowledgeBaseQuestion asks the LLM whether the prompt is a knowledge base question
+func (c *Client) isKnowledgeBaseQuestion(ctx context.Context, userPrompt string) (bool, error) {
+	prompt := fmt.Sprintf(ragClassifierPrompt, userPrompt)
+	if c.cfg.LLM.StructuredOutput {
+		response, err := c.llmRegistry.GenerateCompletion(ctx, c.cfg.LLM.Provider, prompt,
+			llm.ProviderOptions{Temperature: 0, MaxTokens: 20, ResponseSchema: ragClassifierSchema})
+		if err != nil {
+			return false, err
+		}
+		var reply struct {
+			KnowledgeBaseQuestion bool `json:"knowledgeBaseQuestion"`
+		}
+		if err := llm.DecodeJSON(response.Content, &reply); err == nil {
+			return reply.KnowledgeBaseQuestion, nil
+		}
+		return isAffirmative(response.Content), nil
+	}
+
+	response, err := c.llmRegistry.GenerateCompletion(ctx, c.cfg.LLM.Provider, prompt,
+		llm.ProviderOptions{Temperature: 0, MaxTokens: 5})
+	if err != nil {
+		return false, err
+	}
+	return isAffirmative(response.Content), nil
+}
+
+// isAffirmative reports whether a classifier reply starts with "yes"
+func isAffirmative(reply string) bool {
+	reply = strings.ToLower(strings.TrimSpace(reply))
+	reply = strings.TrimLeft(reply, "\"'*`")
+	return strings.HasPrefix(reply, "yes")
+}
+
+// relevantResults keeps at most maxResults results when the best one scores at
+// least minScore, and none otherwise
+func relevantResults(results []rag.SearchResult, minScore float64, maxResults int) []rag.SearchResult {
+	var best float32
+	for _, result := range results {
+		if result.Score > best {
+			best = result.Score
+		}
+	}
+	if len(results) == 0 || float64(best) < minScore {
+		return nil
+	}
+	if maxResults > 0 && len(results) > maxResults {
+		results = results[:maxResults]
+	}
+	return results
+}
+
+// ragAnswerMessages builds the single LLM request that answers from the contexts
+func ragAnswerMessages(customPrompt, contextHistory string, toolResults []middleware.Message, userPrompt, contexts string) []llm.RequestMessage {
+	instructions := ragAnswerInstructions
+	if customPrompt != "" {
+		instructions = customPrompt + "\n\n" + instructions
+	}
+	messages := []llm.RequestMessage{{Role: "system", Content: instructions}}
+	if contextHistory != "" {
+		messages = append(messages, llm.RequestMessage{Role: "system", Content: "Previous conversation: " + contextHistory})
+	}
+	for _, result := range toolResults {
+		messages = append(messages, llm.RequestMessage{Role: result.Role, Content: result.Content, ToolName: result.ToolName, ToolArgs: result.ToolArgs, ToolCallID: result.ToolCallID})
+	}
+	return append(messages, llm.RequestMessage{
+		Role:    "user",
+		Content: fmt.Sprintf("Knowledge base contexts:\n%s\nQuestion: %s", contexts, userPrompt),
+	})
+}
@@ -0,0 +1,39 @@
+package slackbot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/rag"
+)
+
+func TestRelevantResults(t *testing.T) {
+	results := []rag.SearchResult{{Content: "a", Score: 0.4}, {Content: "b", Score: 0.9}, {Content: "c", Score: 0.2}}
+
+	assert.Len(t, relevantResults(results, 0, 2), 2)
+	assert.Len(t, relevantResults(results, 0.8, 5), 3)
+	assert.Empty(t, relevantResults(results, 0.95, 5))
+	assert.Empty(t, relevantResults(nil, 0, 5))
+}
+
+func TestIsAffirmative(t *testing.T) {
+	assert.True(t, isAffirmative("Yes"))
+	assert.True(t, isAffirmative(" \"yes.\""))
+	assert.False(t, isAffirmative("No"))
+	assert.False(t, isAffirmative(""))
+}
+
+func TestRAGAnswerMessages(t *testing.T) {
+	messages := ragAnswerMessages("You are the platform bot.", "User: hi", nil, "How do I roll back?", "--- Context [1] ---\n")
+	require.Len(t, messages, 3)
+	assert.Equal(t, "system", messages[0].Role)
+	assert.Contains(t, messages[0].Content, "You are the platform bot.")
+	assert.Contains(t, messages[0].Content, ragAnswerDecline)
+	assert.Equal(t, "Previous conversation: User: hi", messages[1].Content)
+	assert.Equal(t, "user", messages[2].Role)
+	assert.Contains(t, messages[2].Content, "Question: How do I roll back?")
+
+	assert.Len(t, ragAnswerMessages("", "", nil, "q", "contexts"), 2)
+}
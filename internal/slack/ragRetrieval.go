@@ -0,0 +1,238 @@
+package slackbot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/tmc/langchaingo/embeddings"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/llm"
+	"github.com/tuannvm/slack-mcp-client/internal/rag"
+)
+
+// rerankTimeout bounds a single cross-encoder rerank request
+const rerankTimeout = 30 * time.Second
+
+// ragStoreMetricsInterval is how often the knowledge base size gauges are refreshed
+const ragStoreMetricsInterval = 5 * time.Minute
+
+// NewRAGClient creates the client of the knowledge base configured in cfg.RAG
+func NewRAGClient(cfg *config.Config) (*rag.Client, error) {
+	// Use the legacy API for now until we properly update the RAG package
+	// Convert structured config to legacy format
+	ragConfig := map[string]interface{}{
+		"provider": cfg.RAG.Provider,
+	}
+
+	// Add provider-specific settings
+	if providerSettings, exists := cfg.RAG.Providers[cfg.RAG.Provider]; exists {
+		switch cfg.RAG.Provider {
+		case "simple", "sqlite", "json":
+			ragConfig["database_path"] = providerSettings.DatabasePath
+		case "openai":
+			if providerSettings.IndexName != "" {
+				ragConfig["vector_store_name"] = providerSettings.IndexName
+			}
+			if providerSettings.VectorStoreID != "" {
+				ragConfig["vector_store_id"] = providerSettings.VectorStoreID
+			}
+			if providerSettings.Dimensions > 0 {
+				ragConfig["dimensions"] = providerSettings.Dimensions
+			}
+			if providerSettings.SimilarityMetric != "" {
+				ragConfig["similarity_metric"] = providerSettings.SimilarityMetric
+			}
+			if providerSettings.MaxResults > 0 {
+				ragConfig["max_results"] = providerSettings.MaxResults
+			}
+			if providerSettings.ScoreThreshold > 0 {
+				ragConfig["score_threshold"] = providerSettings.ScoreThreshold
+			}
+			if providerSettings.RewriteQuery {
+				ragConfig["rewrite_query"] = providerSettings.RewriteQuery
+			}
+			if providerSettings.VectorStoreNameRegex != "" {
+				ragConfig["vector_store_name_regex"] = providerSettings.VectorStoreNameRegex
+			}
+			if providerSettings.VectorStoreMetadataKey != "" {
+				ragConfig["vs_metadata_key"] = providerSettings.VectorStoreMetadataKey
+			}
+			if providerSettings.VectorStoreMetadataValue != "" {
+				ragConfig["vs_metadata_value"] = providerSettings.VectorStoreMetadataValue
+			}
+			// Add OpenAI API key from LLM config or environment
+			if openaiConfig, exists := cfg.LLM.Providers["openai"]; exists && openaiConfig.APIKey != "" {
+				ragConfig["api_key"] = openaiConfig.APIKey
+			}
+		}
+	}
+
+	ragClient, err := rag.NewClientWithProvider(cfg.RAG.Provider, ragConfig)
+	if err != nil {
+		return nil, err
+	}
+	ragClient.SetWebOptions(rag.WebOptions{
+		MaxDepth:       cfg.RAG.Web.MaxDepth,
+		MaxPages:       cfg.RAG.Web.MaxPages,
+		AllowedDomains: cfg.RAG.Web.AllowedDomains,
+	})
+	ragClient.SetIngestWorkers(cfg.RAG.Ingest.Workers)
+	return ragClient, nil
+}
+
+// ConfigureRAGRetrieval sets up chunking, and enables vector/hybrid search, recency
+// decay and reranking on the RAG client as configured. The bot and the rag eval
+// command both use it, so evaluations score the retrieval the bot answers with.
+func ConfigureRAGRetrieval(ragClient *rag.Client, cfg *config.Config, registry *llm.ProviderRegistry, logger *logging.Logger) error {
+	search := cfg.RAG.Search
+	var embedder embeddings.Embedder
+	if search.Mode != config.RAGSearchKeyword || cfg.RAG.Chunking.Strategy == config.RAGChunkSemantic {
+		var err error
+		embedder, err = ragEmbedder(cfg)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := ragClient.SetChunking(ragChunkingOptions(cfg, embedder)); err != nil {
+		return err
+	}
+
+	if search.Mode != config.RAGSearchKeyword {
+		err := ragClient.EnableVectorSearch(embedder, rag.HybridOptions{
+			Mode:           search.Mode,
+			VectorWeight:   search.VectorWeight,
+			Candidates:     search.Candidates,
+			EmbeddingModel: search.EmbeddingModel,
+		})
+		if err != nil {
+			return err
+		}
+		logger.InfoKV("Using embedding-based RAG search", "mode", search.Mode,
+			"provider", search.EmbeddingProvider, "model", search.EmbeddingModel)
+	}
+
+	if halfLife := cfg.RAG.Freshness.GetHalfLife(); halfLife > 0 {
+		ragClient.SetRecencyHalfLife(halfLife)
+		logger.InfoKV("Ranking RAG results by recency", "half_life", halfLife)
+	}
+
+	rerank := cfg.RAG.Rerank
+	switch rerank.Provider {
+	case config.RAGRerankLLM:
+		ragClient.SetReranker(rag.NewLLMReranker(func(ctx context.Context, prompt string) (string, error) {
+			options := llm.ProviderOptions{Temperature: 0}
+			if cfg.LLM.StructuredOutput {
+				options.ResponseSchema = rerankSchema
+			}
+			choice, err := registry.GenerateCompletion(ctx, cfg.LLM.Provider, prompt, options)
+			if err != nil {
+				return "", err
+			}
+			return structuredRanking(choice.Content), nil
+		}), rerank.Candidates, rerank.TopN)
+	case config.RAGRerankCrossEncoder:
+		ragClient.SetReranker(rag.NewCrossEncoderReranker(rerank.URL, rerank.Model, rerank.APIKey,
+			&http.Client{Timeout: rerankTimeout}), rerank.Candidates, rerank.TopN)
+	default:
+		return nil
+	}
+	logger.InfoKV("Reranking RAG results", "provider", rerank.Provider, "candidates", rerank.Candidates, "top_n", rerank.TopN)
+	return nil
+}
+
+// ConfigureRAGChunking sets the chunking of rag.chunking on the RAG client, as the
+// bot does, so documents ingested from the command line are split the same way.
+// The semantic strategy embeds sentences with the embedder of rag.search.
+func ConfigureRAGChunking(ragClient *rag.Client, cfg *config.Config) error {
+	var embedder embeddings.Embedder
+	if cfg.RAG.Chunking.Strategy == config.RAGChunkSemantic {
+		var err error
+		embedder, err = ragEmbedder(cfg)
+		if err != nil {
+			return err
+		}
+	}
+	return ragClient.SetChunking(ragChunkingOptions(cfg, embedder))
+}
+
+// ragEmbedder returns the embedder configured in rag.search
+func ragEmbedder(cfg *config.Config) (embeddings.Embedder, error) {
+	search := cfg.RAG.Search
+	return llm.NewEmbedder(search.EmbeddingProvider, search.EmbeddingModel, cfg.LLM.Providers[search.EmbeddingProvider])
+}
+
+// ragChunkingOptions converts the chunking configuration. The legacy chunkSize sets
+// the size in characters, except for openai, which keeps its automatic chunking
+// unless chunking.size is set.
+func ragChunkingOptions(cfg *config.Config, embedder embeddings.Embedder) rag.ChunkingOptions {
+	chunking := cfg.RAG.Chunking
+	options := rag.ChunkingOptions{
+		Strategy:             chunking.Strategy,
+		Size:                 chunking.Size,
+		Overlap:              chunking.Overlap,
+		Unit:                 chunking.Unit,
+		BreakpointPercentile: chunking.BreakpointPercentile,
+	}
+	if chunking.Strategy == config.RAGChunkSemantic {
+		options.Embedder = embedder
+	}
+	if options.Size == 0 && options.Unit == config.RAGChunkUnitCharacters && cfg.RAG.Provider != "openai" {
+		options.Size = cfg.RAG.ChunkSize
+	}
+	return options
+}
+
+// embedKnowledgeBase embeds chunks stored without a vector, such as documents
+// ingested from the command line, so vector search covers the whole knowledge base
+func (c *Client) embedKnowledgeBase() {
+	if c.ragClient == nil || c.cfg.RAG.Search.Mode == config.RAGSearchKeyword {
+		return
+	}
+	embedded, err := c.ragClient.EmbedMissing(context.Background())
+	if err != nil {
+		c.logger.WarnKV("Failed to embed knowledge base chunks", "embedded", embedded, "error", err)
+		return
+	}
+	if embedded > 0 {
+		c.logger.InfoKV("Embedded knowledge base chunks", "count", embedded)
+	}
+}
+
+// rerankSchema constrains the LLM reranker reply in structured output mode
+var rerankSchema = &llm.ResponseSchema{
+	Name:        "passage_ranking",
+	Description: "passage numbers, most relevant first",
+	Strict:      true,
+	Schema: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"ranking": map[string]interface{}{
+				"type":  "array",
+				"items": map[string]interface{}{"type": "integer"},
+			},
+		},
+		"required":             []string{"ranking"},
+		"additionalProperties": false,
+	},
+}
+
+// structuredRanking returns the ranking array of a rerankSchema reply, which is the
+// format the reranker parses. Other replies are returned unchanged.
+func structuredRanking(content string) string {
+	var reply struct {
+		Ranking []int `json:"ranking"`
+	}
+	if err := llm.DecodeJSON(content, &reply); err != nil || reply.Ranking == nil {
+		return content
+	}
+	ranking, err := json.Marshal(reply.Ranking)
+	if err != nil {
+		return content
+	}
+	return string(ranking)
+}
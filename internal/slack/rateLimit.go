@@ -0,0 +1,168 @@
+package slackbot
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/monitoring"
+)
+
+// Slack rate limit families. Tiers allow roughly 1, 20, 50 and 100+ calls per
+// minute per method and workspace; chat.postMessage has its own limit of about
+// one message per second per channel.
+const (
+	rateLimitTier1   = "tier1"
+	rateLimitTier2   = "tier2"
+	rateLimitTier3   = "tier3"
+	rateLimitTier4   = "tier4"
+	rateLimitSpecial = "special"
+)
+
+// rateLimitPerMinute is the number of calls each family allows per minute
+var rateLimitPerMinute = map[string]int{
+	rateLimitTier1:   1,
+	rateLimitTier2:   20,
+	rateLimitTier3:   50,
+	rateLimitTier4:   100,
+	rateLimitSpecial: 60,
+}
+
+// slackMethodFamilies maps the Web API methods this client calls to their rate
+// limit family; other methods are assumed to be tier 3
+var slackMethodFamilies = map[string]string{
+	"apps.connections.open":                 rateLimitTier1,
+	"usergroups.list":                       rateLimitTier2,
+	"usergroups.users.list":                 rateLimitTier2,
+	"users.list":                            rateLimitTier2,
+	"reactions.remove":                      rateLimitTier2,
+	"chat.update":                           rateLimitTier3,
+	"chat.delete":                           rateLimitTier3,
+	"conversations.history":                 rateLimitTier3,
+	"conversations.replies":                 rateLimitTier3,
+	"conversations.info":                    rateLimitTier3,
+	"reactions.add":                         rateLimitTier3,
+	"auth.test":                             rateLimitTier4,
+	"users.info":                            rateLimitTier4,
+	"conversations.members":                 rateLimitTier4,
+	"chat.postEphemeral":                    rateLimitTier4,
+	"views.publish":                         rateLimitTier4,
+	"assistant.threads.setStatus":           rateLimitTier4,
+	"assistant.threads.setSuggestedPrompts": rateLimitTier4,
+	"assistant.threads.setTitle":            rateLimitTier4,
+	"chat.postMessage":                      rateLimitSpecial,
+}
+
+const (
+	rateLimitWindow       = time.Minute
+	rateLimitWarnFraction = 0.2 // Warn when less than this share of a method's quota is left
+)
+
+// rateLimitFamily returns the rate limit family of a Web API method
+func rateLimitFamily(method string) string {
+	if family, ok := slackMethodFamilies[method]; ok {
+		return family
+	}
+	return rateLimitTier3
+}
+
+// rateLimitTransport observes Slack Web API calls. It counts each method's calls
+// over the last minute to estimate the quota left, which Slack does not report,
+// records Retry-After and X-RateLimit-Remaining when Slack sends them, and warns
+// before a method runs out.
+type rateLimitTransport struct {
+	base   http.RoundTripper
+	logger *logging.Logger
+	now    func() time.Time
+
+	mu       sync.Mutex
+	calls    map[string][]time.Time // Call times within the window, per method
+	warnedAt map[string]time.Time   // Last low-quota warning, per method
+}
+
+func newRateLimitTransport(base http.RoundTripper, logger *logging.Logger) *rateLimitTransport {
+	return &rateLimitTransport{
+		base:     base,
+		logger:   logger,
+		now:      time.Now,
+		calls:    make(map[string][]time.Time),
+		warnedAt: make(map[string]time.Time),
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	method, ok := strings.CutPrefix(req.URL.Path, "/api/")
+	if !ok {
+		return t.base.RoundTrip(req)
+	}
+	family := rateLimitFamily(method)
+	remaining := t.record(method, family)
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		monitoring.SlackAPIRequests.WithLabelValues(method, "error").Inc()
+		return resp, err
+	}
+
+	// Slack's own count is more accurate than the estimate, when it sends one
+	if header, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining")); err == nil {
+		remaining = header
+	}
+	monitoring.SlackRateLimitRemaining.WithLabelValues(family, method).Set(float64(remaining))
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		monitoring.SlackAPIRequests.WithLabelValues(method, "rate_limited").Inc()
+		t.logger.WarnKV("Slack API rate limit hit", "method", method, "family", family,
+			"retryAfter", resp.Header.Get("Retry-After"))
+		return resp, nil
+	}
+	monitoring.SlackAPIRequests.WithLabelValues(method, "ok").Inc()
+	t.warnIfLow(method, family, remaining)
+	return resp, nil
+}
+
+// record counts a call and returns the estimated calls left in the window
+func (t *rateLimitTransport) record(method, family string) int {
+	now := t.now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	calls := t.calls[method]
+	kept := calls[:0]
+	for _, at := range calls {
+		if now.Sub(at) < rateLimitWindow {
+			kept = append(kept, at)
+		}
+	}
+	kept = append(kept, now)
+	t.calls[method] = kept
+
+	remaining := rateLimitPerMinute[family] - len(kept)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return remaining
+}
+
+// warnIfLow logs at most one warning per method and window when its quota runs low
+func (t *rateLimitTransport) warnIfLow(method, family string, remaining int) {
+	limit := rateLimitPerMinute[family]
+	// Tier 1 methods allow a single call, so every call would warn
+	if limit <= 1 || float64(remaining) >= float64(limit)*rateLimitWarnFraction {
+		return
+	}
+	now := t.now()
+	t.mu.Lock()
+	if now.Sub(t.warnedAt[method]) < rateLimitWindow {
+		t.mu.Unlock()
+		return
+	}
+	t.warnedAt[method] = now
+	t.mu.Unlock()
+	t.logger.WarnKV("Slack API rate limit nearly reached", "method", method, "family", family,
+		"remaining", remaining, "limitPerMinute", limit)
+}
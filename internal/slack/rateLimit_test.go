@@ -0,0 +1,68 @@
+package slackbot
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/monitoring"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRateLimitTransportEstimatesRemainingQuota(t *testing.T) {
+	status := http.StatusOK
+	header := http.Header{}
+	base := roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: status, Header: header, Body: http.NoBody}, nil
+	})
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	transport := newRateLimitTransport(base, logging.New("test", logging.LevelError))
+	transport.now = func() time.Time { return now }
+	call := func(method string) {
+		req, err := http.NewRequest(http.MethodPost, "https://slack.com/api/"+method, nil)
+		require.NoError(t, err)
+		resp, err := transport.RoundTrip(req)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+	}
+	remaining := func(method string) float64 {
+		return testutil.ToFloat64(monitoring.SlackRateLimitRemaining.WithLabelValues(rateLimitFamily(method), method))
+	}
+
+	// Tier 3: 50 calls per minute
+	for i := 0; i < 45; i++ {
+		call("chat.update")
+	}
+	assert.Equal(t, 5.0, remaining("chat.update"))
+	assert.Equal(t, time.Time{}, transport.warnedAt["conversations.replies"])
+	assert.Equal(t, now, transport.warnedAt["chat.update"], "expected a warning below 20% of the quota")
+
+	// Calls older than a minute no longer count
+	now = now.Add(61 * time.Second)
+	call("chat.update")
+	assert.Equal(t, 49.0, remaining("chat.update"))
+
+	// Slack's own count wins over the estimate
+	header.Set("X-RateLimit-Remaining", "3")
+	call("users.info")
+	assert.Equal(t, 3.0, remaining("users.info"))
+
+	rateLimited := testutil.ToFloat64(monitoring.SlackAPIRequests.WithLabelValues("chat.postMessage", "rate_limited"))
+	status = http.StatusTooManyRequests
+	header.Set("Retry-After", "30")
+	call("chat.postMessage")
+	assert.Equal(t, rateLimited+1, testutil.ToFloat64(monitoring.SlackAPIRequests.WithLabelValues("chat.postMessage", "rate_limited")))
+
+	assert.Equal(t, rateLimitTier3, rateLimitFamily("unknown.method"))
+	assert.Equal(t, rateLimitSpecial, rateLimitFamily("chat.postMessage"))
+}
@@ -0,0 +1,198 @@
+package slackbot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/monitoring"
+)
+
+const (
+	regenerateNoticeText = "🔁 <@%s> asked for a new answer to:\n%s"
+	followUpNoticeText   = "🧵 <@%s> asked: %s"
+	canvasSavedText      = "📌 <@%s> saved this answer to a canvas: <%s|open the canvas>"
+)
+
+// reactedAnswer is one of the bot's answers a user reacted to, with the prompt it
+// answered
+type reactedAnswer struct {
+	channelID string
+	threadTS  string
+	ts        string
+	text      string
+	promptTS  string
+	prompt    string
+}
+
+// reactionAction returns the quick action configured for a reaction
+func (c *Client) reactionAction(reaction string) (config.SlackReactionAction, bool) {
+	// Skin tones arrive as "+1::skin-tone-2"
+	reaction, _, _ = strings.Cut(reaction, "::")
+	action, ok := c.cfg.Slack.ReactionActions[reaction]
+	return action, ok
+}
+
+// handleReactionAction runs the quick action of a reaction to one of the bot's
+// answers. The user who reacted must be allowed to use the bot in the channel, and
+// the action runs on their behalf.
+func (c *Client) handleReactionAction(channelID, messageTS, userID string, action config.SlackReactionAction) {
+	answer, ok := c.reactedAnswer(channelID, messageTS)
+	if !ok {
+		return
+	}
+	if result := c.cfg.ValidateAccessWithDirectory(userID, channelID, c.security); !result.Allowed {
+		c.logger.WarnKV("Ignored quick action of a user who is not allowed", "action", action.Action, "user", userID, "channel", channelID, "reason", result.Reason)
+		monitoring.SlackReactionActions.WithLabelValues(action.Action, "denied").Inc()
+		return
+	}
+	profile, err := c.userFrontend.GetUserInfo(userID)
+	if err != nil {
+		c.logger.WarnKV("Failed to get user info", "user", userID, "error", err)
+		profile = &UserProfile{userId: userID, realName: "Unknown", email: ""}
+	}
+
+	c.logger.InfoKV("Running quick action", "action", action.Action, "user", userID, "channel", channelID, "ts", messageTS)
+	switch action.Action {
+	case config.ReactionActionCanvas:
+		err = c.saveAnswerToCanvas(answer, userID)
+	case config.ReactionActionRegenerate:
+		err = c.regenerateAnswer(answer, profile)
+	default:
+		err = c.followUpAnswer(answer, action.Prompt, profile)
+	}
+	if err != nil {
+		c.logger.WarnKV("Quick action failed", "action", action.Action, "channel", channelID, "ts", messageTS, "error", err)
+		monitoring.SlackReactionActions.WithLabelValues(action.Action, "error").Inc()
+		c.sendMessage(channelID, answer.threadTS, fmt.Sprintf("Sorry, I could not %s: %v", reactionActionDescription(action.Action), err))
+		return
+	}
+	monitoring.SlackReactionActions.WithLabelValues(action.Action, "completed").Inc()
+}
+
+// reactedAnswer fetches the message reacted to and the user prompt that precedes
+// it in the thread. It returns false when the message is not an answer of the bot.
+func (c *Client) reactedAnswer(channelID, messageTS string) (reactedAnswer, bool) {
+	frontend, ok := c.userFrontend.(MessageLookupFrontend)
+	if !ok {
+		return reactedAnswer{}, false
+	}
+	messages, _, _, err := frontend.GetConversationReplies(&slack.GetConversationRepliesParameters{
+		ChannelID: channelID,
+		Timestamp: messageTS,
+		Latest:    messageTS,
+		Inclusive: true,
+		Limit:     1,
+	})
+	if err != nil || len(messages) == 0 {
+		c.logger.WarnKV("Failed to fetch the message reacted to for a quick action", "channel", channelID, "ts", messageTS, "error", err)
+		return reactedAnswer{}, false
+	}
+	msg := messages[0]
+	if !frontend.IsOwnMessage(msg) || strings.TrimSpace(msg.Text) == "" {
+		return reactedAnswer{}, false // Quick actions only apply to the bot's answers
+	}
+	answer := reactedAnswer{channelID: channelID, threadTS: msg.ThreadTimestamp, ts: msg.Timestamp, text: msg.Text}
+	if answer.threadTS == "" {
+		answer.threadTS = msg.Timestamp
+	}
+
+	replies, err := c.userFrontend.GetThreadReplies(channelID, answer.threadTS)
+	if err != nil {
+		c.logger.WarnKV("Failed to fetch thread replies", "channel", channelID, "thread_ts", answer.threadTS, "error", err)
+		return answer, true
+	}
+	answer.promptTS, answer.prompt = c.precedingPrompt(replies, answer.ts)
+	return answer, true
+}
+
+// precedingPrompt returns the timestamp and text of the last user message posted
+// before ts
+func (c *Client) precedingPrompt(replies []slack.Message, ts string) (string, string) {
+	var promptTS, prompt string
+	for _, reply := range replies {
+		if reply.BotID != "" || reply.SubType != "" || !tsAfter(ts, reply.Timestamp) {
+			continue
+		}
+		if promptTS == "" || tsAfter(reply.Timestamp, promptTS) {
+			promptTS, prompt = reply.Timestamp, strings.TrimSpace(c.userFrontend.RemoveBotMention(reply.Text))
+		}
+	}
+	return promptTS, prompt
+}
+
+// regenerateAnswer answers the prompt of the answer again, as a sibling of the
+// original: the new run sees the conversation up to the prompt, not its answer
+func (c *Client) regenerateAnswer(answer reactedAnswer, profile *UserProfile) error {
+	if answer.prompt == "" {
+		return fmt.Errorf("the question of this answer was not found")
+	}
+	ts, err := c.postActionNotice(answer, fmt.Sprintf(regenerateNoticeText, profile.userId, quote(answer.prompt)))
+	if err != nil {
+		return err
+	}
+	c.answerPrompt(answer.prompt, answer.channelID, answer.threadTS, ts, profile, answer.promptTS)
+	return nil
+}
+
+// followUpAnswer asks a follow-up prompt about the answer in its thread
+func (c *Client) followUpAnswer(answer reactedAnswer, prompt string, profile *UserProfile) error {
+	ts, err := c.postActionNotice(answer, fmt.Sprintf(followUpNoticeText, profile.userId, prompt))
+	if err != nil {
+		return err
+	}
+	c.handleUserPrompt(followUpPrompt(prompt, answer.text), answer.channelID, answer.threadTS, ts, profile)
+	return nil
+}
+
+// followUpPrompt quotes the answer a follow-up prompt refers to, which need not
+// be the latest answer of the thread
+func followUpPrompt(prompt, answer string) string {
+	return fmt.Sprintf("%s\n\nThe answer this refers to:\n```\n%s\n```", prompt, answer)
+}
+
+// saveAnswerToCanvas writes the answer, under its question, to a new canvas the
+// channel can read and posts its link in the thread
+func (c *Client) saveAnswerToCanvas(answer reactedAnswer, userID string) error {
+	title := fmt.Sprintf("Saved answer %s", time.Now().Format("2006-01-02 15:04"))
+	link, err := c.publishCanvas(title, answerCanvasMarkdown(answer), answer.channelID)
+	if err != nil {
+		return err
+	}
+	c.sendMessage(answer.channelID, answer.threadTS, fmt.Sprintf(canvasSavedText, userID, link))
+	return nil
+}
+
+// answerCanvasMarkdown is the content of the canvas an answer is saved to
+func answerCanvasMarkdown(answer reactedAnswer) string {
+	if answer.prompt == "" {
+		return answer.text
+	}
+	return fmt.Sprintf("**Question:** %s\n\n%s", answer.prompt, answer.text)
+}
+
+// postActionNotice posts the notice that a quick action is running. Its timestamp
+// identifies the new run, so it can be cancelled and retried like a prompt.
+func (c *Client) postActionNotice(answer reactedAnswer, text string) (string, error) {
+	frontend, ok := c.userFrontend.(ToolNoticeFrontend)
+	if !ok {
+		return "", fmt.Errorf("this frontend cannot post notices")
+	}
+	return frontend.PostBlocks(answer.channelID, answer.threadTS, text,
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, text, false, false), nil, nil))
+}
+
+// reactionActionDescription names a quick action in error replies
+func reactionActionDescription(action string) string {
+	switch action {
+	case config.ReactionActionCanvas:
+		return "save the answer to a canvas"
+	case config.ReactionActionRegenerate:
+		return "answer again"
+	default:
+		return "follow up on the answer"
+	}
+}
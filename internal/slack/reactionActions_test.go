@@ -0,0 +1,89 @@
+package slackbot
+
+import (
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+func TestReactionActionMapping(t *testing.T) {
+	client, _, _ := newProgressTestClient()
+	client.cfg.Slack.ReactionActions = map[string]config.SlackReactionAction{
+		"repeat": {Action: config.ReactionActionRegenerate},
+		"thread": {Action: config.ReactionActionExpand},
+	}
+	client.cfg.ApplyDefaults()
+
+	action, ok := client.reactionAction("repeat")
+	require.True(t, ok)
+	assert.Equal(t, config.ReactionActionRegenerate, action.Action)
+
+	action, ok = client.reactionAction("thread::skin-tone-2")
+	require.True(t, ok)
+	assert.Contains(t, action.Prompt, "more detail")
+
+	_, ok = client.reactionAction("eyes")
+	assert.False(t, ok)
+}
+
+func TestPrecedingPrompt(t *testing.T) {
+	client, _, _ := newProgressTestClient()
+	replies := []slack.Message{
+		{Msg: slack.Msg{User: "U1", Timestamp: "100.1", Text: "How do I deploy?"}},
+		{Msg: slack.Msg{BotID: "B1", Timestamp: "100.2", Text: "Run make deploy."}},
+		{Msg: slack.Msg{User: "U1", Timestamp: "100.3", Text: "And roll back?"}},
+		{Msg: slack.Msg{User: "U1", Timestamp: "100.35", SubType: "channel_join"}},
+		{Msg: slack.Msg{BotID: "B1", Timestamp: "100.4", Text: "Run make rollback."}},
+		{Msg: slack.Msg{User: "U2", Timestamp: "100.5", Text: "thanks"}},
+	}
+
+	ts, prompt := client.precedingPrompt(replies, "100.4")
+	assert.Equal(t, "100.3", ts)
+	assert.Equal(t, "And roll back?", prompt)
+
+	ts, prompt = client.precedingPrompt(replies, "100.2")
+	assert.Equal(t, "100.1", ts)
+	assert.Equal(t, "How do I deploy?", prompt)
+
+	ts, _ = client.precedingPrompt(replies, "100.05")
+	assert.Empty(t, ts)
+}
+
+func TestReactionActionIgnoresUserMessages(t *testing.T) {
+	client, progress, output := newProgressTestClient()
+	frontend := &threadLookup{progressRecorder: progress, messages: map[string]slack.Message{
+		"100.3": {Msg: slack.Msg{User: "U2", Timestamp: "100.3", ThreadTimestamp: "100.1", Text: "hello"}},
+		"100.4": {Msg: slack.Msg{BotID: "B2", Timestamp: "100.4", ThreadTimestamp: "100.1", Text: "Build passed."}},
+	}}
+	client.userFrontend = frontend
+
+	client.handleReactionAction("C1", "100.3", "U1", config.SlackReactionAction{Action: config.ReactionActionRegenerate})
+	assert.Empty(t, output.String())
+
+	// Messages of other apps are not the bot's answers either
+	client.handleReactionAction("C1", "100.4", "U1", config.SlackReactionAction{Action: config.ReactionActionRegenerate})
+	assert.Empty(t, output.String())
+}
+
+func TestRegenerateWithoutPromptReportsError(t *testing.T) {
+	client, progress, output := newProgressTestClient()
+	client.userFrontend = &threadLookup{progressRecorder: progress, messages: map[string]slack.Message{
+		"100.2": {Msg: slack.Msg{BotID: "B1", Timestamp: "100.2", ThreadTimestamp: "100.1", Text: "Run make deploy."}},
+	}}
+
+	client.handleReactionAction("C1", "100.2", "U1", config.SlackReactionAction{Action: config.ReactionActionRegenerate})
+	assert.Contains(t, output.String(), "Sorry, I could not answer again: the question of this answer was not found")
+}
+
+func TestQuickActionContent(t *testing.T) {
+	answer := reactedAnswer{prompt: "How do I deploy?", text: "Run make deploy."}
+	assert.Equal(t, "**Question:** How do I deploy?\n\nRun make deploy.", answerCanvasMarkdown(answer))
+	assert.Equal(t, "Run make deploy.", answerCanvasMarkdown(reactedAnswer{text: "Run make deploy."}))
+
+	prompt := followUpPrompt("Expand on it.", "Run make deploy.")
+	assert.Equal(t, "Expand on it.\n\nThe answer this refers to:\n```\nRun make deploy.\n```", prompt)
+}
@@ -0,0 +1,337 @@
+package slackbot
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os/user"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+)
+
+const (
+	replChannelID = "D-REPL"      // Conversation of the chat REPL, a direct message
+	replUserID    = "U-REPL"      // The person at the terminal
+	replPrompt    = "you> "       // Shown when the REPL waits for a line
+	replBotPrefix = "assistant> " // Precedes the replies
+)
+
+// PromptFrontend is implemented by frontends that wait until a prompt has been
+// answered before accepting the next one
+type PromptFrontend interface {
+	PromptAnswered(channelID, threadTS, promptTS string)
+}
+
+// promptAnswered tells the frontend that the prompt sent at promptTS was answered
+func (c *Client) promptAnswered(channelID, threadTS, promptTS string) {
+	if frontend, ok := c.userFrontend.(PromptFrontend); ok {
+		frontend.PromptAnswered(channelID, threadTS, promptTS)
+	}
+}
+
+// ReplCommand is a command of the chat REPL, run by typing "/name args"
+type ReplCommand struct {
+	Usage string                     // Arguments shown by /help, e.g. "[provider] [model]"
+	Help  string                     // What the command does
+	Run   func(args []string) string // Returns the text printed for the user
+}
+
+// ReplClient is the frontend of the chat subcommand: an interactive conversation
+// in the terminal. Lines are sent as direct messages in one thread, so answers
+// see the earlier turns, and lines starting with "/" run REPL commands.
+type ReplClient struct {
+	events          chan socketmode.Event
+	input           io.Reader
+	output          io.Writer
+	logger          *logging.Logger
+	thinkingMessage string
+
+	mu          sync.Mutex
+	commands    map[string]ReplCommand
+	threadTS    string        // Thread of the current conversation
+	sequence    int           // Makes message timestamps unique
+	pendingTS   string        // Timestamp of the prompt being answered, "" when idle
+	answered    chan struct{} // Closed when the pending prompt is answered or interrupted
+	closed      chan struct{} // Closed by Close to stop Run
+	closeOnce   sync.Once
+	outputMutex sync.Mutex // Keeps replies and prompts from interleaving
+}
+
+// NewReplClient creates a REPL reading lines from input and printing to output
+func NewReplClient(stdLogger *logging.Logger, input io.Reader, output io.Writer, thinkingMessage string) *ReplClient {
+	r := &ReplClient{
+		events:          make(chan socketmode.Event, 50),
+		input:           input,
+		output:          output,
+		logger:          logging.New("repl-client", getLogLevel(stdLogger)),
+		thinkingMessage: thinkingMessage,
+		commands:        make(map[string]ReplCommand),
+		closed:          make(chan struct{}),
+	}
+	r.threadTS = r.nextTimestamp()
+	return r
+}
+
+// AddCommand registers a command run by typing "/name"
+func (r *ReplClient) AddCommand(name string, command ReplCommand) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.commands[name] = command
+}
+
+// Run reads lines until the input ends, /quit is typed or the REPL is closed.
+// Each prompt is answered before the next line is read.
+func (r *ReplClient) Run() error {
+	lines := make(chan string)
+	scanErr := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(r.input)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			select {
+			case lines <- scanner.Text():
+			case <-r.closed:
+				return
+			}
+		}
+		scanErr <- scanner.Err()
+		close(lines)
+	}()
+
+	r.print("Type a message, or /help for commands.\n")
+	for {
+		r.print(replPrompt)
+		var line string
+		select {
+		case next, ok := <-lines:
+			if !ok {
+				r.print("\n")
+				if err := <-scanErr; err != nil {
+					return fmt.Errorf("error reading input: %w", err)
+				}
+				return nil
+			}
+			line = strings.TrimSpace(next)
+		case <-r.closed:
+			return nil
+		}
+
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "/"):
+			if !r.runCommand(line) {
+				return nil
+			}
+		case isStopCommand(line):
+			r.mu.Lock()
+			timestamp, threadTS := r.nextTimestamp(), r.threadTS
+			r.mu.Unlock()
+			r.send(line, timestamp, threadTS) // Nothing is running, so nothing answers it
+		default:
+			r.ask(line)
+		}
+	}
+}
+
+// runCommand runs a REPL command and reports whether the REPL keeps going
+func (r *ReplClient) runCommand(line string) bool {
+	args := strings.Fields(strings.TrimPrefix(line, "/"))
+	if len(args) == 0 {
+		return true
+	}
+	name := strings.ToLower(args[0])
+	switch name {
+	case "quit", "exit":
+		return false
+	case "help":
+		r.print(r.help())
+	case "reset":
+		r.mu.Lock()
+		r.threadTS = r.nextTimestamp()
+		r.mu.Unlock()
+		r.print("Started a new conversation.\n")
+	default:
+		r.mu.Lock()
+		command, ok := r.commands[name]
+		r.mu.Unlock()
+		if !ok {
+			r.print(fmt.Sprintf("Unknown command /%s, type /help for commands.\n", name))
+			return true
+		}
+		r.print(strings.TrimRight(command.Run(args[1:]), "\n") + "\n")
+	}
+	return true
+}
+
+// help lists the built-in and registered commands
+func (r *ReplClient) help() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names := make([]string, 0, len(r.commands))
+	for name := range r.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("Commands:\n")
+	for _, name := range names {
+		command := r.commands[name]
+		fmt.Fprintf(&b, "  %s: %s\n", strings.TrimSpace("/"+name+" "+command.Usage), command.Help)
+	}
+	b.WriteString("  /reset: start a new conversation\n")
+	b.WriteString("  /help: show this help\n")
+	b.WriteString("  /quit: leave the chat\n")
+	b.WriteString("Ctrl-C stops the answer in progress.\n")
+	return b.String()
+}
+
+// ask sends a prompt and waits until it is answered, interrupted or the REPL closes
+func (r *ReplClient) ask(text string) {
+	r.mu.Lock()
+	timestamp := r.nextTimestamp()
+	threadTS := r.threadTS
+	answered := make(chan struct{})
+	r.pendingTS, r.answered = timestamp, answered
+	r.mu.Unlock()
+
+	r.send(text, timestamp, threadTS)
+	select {
+	case <-answered:
+	case <-r.closed:
+	}
+
+	r.mu.Lock()
+	r.pendingTS, r.answered = "", nil
+	r.mu.Unlock()
+}
+
+// Interrupt stops the answer in progress and reports whether there was one
+func (r *ReplClient) Interrupt() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.pendingTS == "" {
+		return false
+	}
+	r.send(stopCommand, r.nextTimestamp(), r.threadTS)
+	close(r.answered)
+	r.pendingTS = ""
+	return true
+}
+
+// PromptAnswered lets Run read the next line once the pending prompt is answered
+func (r *ReplClient) PromptAnswered(channelID, threadTS, promptTS string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if promptTS != "" && promptTS == r.pendingTS {
+		close(r.answered)
+		r.pendingTS = ""
+	}
+}
+
+// send delivers a line as a direct message in the conversation's thread
+func (r *ReplClient) send(text, timestamp, threadTS string) {
+	r.events <- socketmode.Event{
+		Type: socketmode.EventTypeEventsAPI,
+		Data: slackevents.EventsAPIEvent{
+			Type: slackevents.CallbackEvent,
+			Data: &slackevents.EventsAPICallbackEvent{EventID: "repl-" + timestamp},
+			InnerEvent: slackevents.EventsAPIInnerEvent{
+				Type: string(slackevents.Message),
+				Data: &slackevents.MessageEvent{
+					Type:            string(slackevents.Message),
+					User:            replUserID,
+					Text:            text,
+					TimeStamp:       timestamp,
+					ThreadTimeStamp: threadTS,
+					Channel:         replChannelID,
+					ChannelType:     slack.TYPE_IM,
+				},
+			},
+		},
+		Request: &socketmode.Request{},
+	}
+}
+
+// nextTimestamp returns a unique Slack-style message timestamp. Call it with mu held.
+func (r *ReplClient) nextTimestamp() string {
+	r.sequence++
+	return fmt.Sprintf("%d.%06d", time.Now().Unix(), r.sequence)
+}
+
+// print writes to the output, logging failures
+func (r *ReplClient) print(text string) {
+	r.outputMutex.Lock()
+	defer r.outputMutex.Unlock()
+	if _, err := io.WriteString(r.output, text); err != nil {
+		r.logger.ErrorKV("Failed to write to the terminal", "error", err)
+	}
+}
+
+// Close stops Run and releases a prompt waiting for its answer
+func (r *ReplClient) Close() error {
+	r.closeOnce.Do(func() { close(r.closed) })
+	return nil
+}
+
+func (r *ReplClient) Ack(req socketmode.Request, payload ...interface{}) {}
+
+func (r *ReplClient) GetEventChannel() chan socketmode.Event {
+	return r.events
+}
+
+func (r *ReplClient) RemoveBotMention(msg string) string {
+	return msg
+}
+
+func (r *ReplClient) GetLogger() *logging.Logger {
+	return r.logger
+}
+
+func (r *ReplClient) IsValidUser(userID string) bool {
+	return userID == replUserID
+}
+
+// SendMessage prints a reply as Markdown. The thinking message is shown as a
+// status line.
+func (r *ReplClient) SendMessage(channelID, threadTS, text string) {
+	if text == r.thinkingMessage {
+		r.print("(" + text + ")\n")
+		return
+	}
+	r.print(replBotPrefix + markdownMessage(text) + "\n")
+}
+
+// GetThreadReplies returns nothing, the conversation's history is kept in memory
+func (r *ReplClient) GetThreadReplies(channelID, threadTS string) ([]slack.Message, error) {
+	return []slack.Message{}, nil
+}
+
+// GetUserInfo describes the user logged in to the terminal
+func (r *ReplClient) GetUserInfo(userID string) (*UserProfile, error) {
+	currentUser, err := user.Current()
+	if err != nil {
+		return nil, fmt.Errorf("while getting current user: %w", err)
+	}
+	name := currentUser.Name
+	if name == "" {
+		name = currentUser.Username
+	}
+	return &UserProfile{userId: userID, realName: name}, nil
+}
+
+// GetConversationInfo describes the REPL's conversation as a direct message
+func (r *ReplClient) GetConversationInfo(input *slack.GetConversationInfoInput) (*slack.Channel, error) {
+	channel := &slack.Channel{}
+	channel.ID = input.ChannelID
+	channel.IsIM = input.ChannelID == replChannelID
+	return channel, nil
+}
@@ -0,0 +1,97 @@
+package slackbot
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack/slackevents"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+)
+
+// syncBuffer is a bytes.Buffer that is safe for concurrent use
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestReplClientConversation(t *testing.T) {
+	input, inputWriter := io.Pipe()
+	output := &syncBuffer{}
+	repl := NewReplClient(logging.New("test", logging.LevelError), input, output, "Thinking...")
+	repl.AddCommand("tools", ReplCommand{Usage: "[filter]", Help: "list tools", Run: func(args []string) string {
+		return "tools: " + strings.Join(args, ",")
+	}})
+	done := make(chan error, 1)
+	go func() { done <- repl.Run() }()
+
+	nextMessage := func() *slackevents.MessageEvent {
+		select {
+		case event := <-repl.GetEventChannel():
+			return event.Data.(slackevents.EventsAPIEvent).InnerEvent.Data.(*slackevents.MessageEvent)
+		case <-time.After(5 * time.Second):
+			t.Fatal("no event received")
+			return nil
+		}
+	}
+
+	_, err := io.WriteString(inputWriter, "/tools time\nwhat time is it?\nand in Paris?\n")
+	require.NoError(t, err)
+	first := nextMessage()
+	assert.Equal(t, "what time is it?", first.Text)
+	assert.Equal(t, "im", first.ChannelType)
+	assert.True(t, repl.IsValidUser(first.User))
+	assert.Contains(t, output.String(), "tools: time\n")
+
+	// The next line is only read once the prompt is answered
+	select {
+	case <-repl.GetEventChannel():
+		t.Fatal("the next prompt was sent before the answer")
+	case <-time.After(50 * time.Millisecond):
+	}
+	repl.SendMessage(first.Channel, first.ThreadTimeStamp, "Thinking...")
+	repl.SendMessage(first.Channel, first.ThreadTimeStamp, "See <https://time.is|time.is>")
+	repl.PromptAnswered(first.Channel, first.ThreadTimeStamp, first.TimeStamp)
+	second := nextMessage()
+	assert.Equal(t, "and in Paris?", second.Text)
+	assert.Equal(t, first.ThreadTimeStamp, second.ThreadTimeStamp, "turns share the conversation's thread")
+	assert.Contains(t, output.String(), "(Thinking...)\nassistant> See [time.is](https://time.is)\n")
+
+	// Interrupting stops the request and reads the next line
+	assert.True(t, repl.Interrupt())
+	assert.Equal(t, stopCommand, nextMessage().Text)
+	assert.False(t, repl.Interrupt())
+
+	_, err = io.WriteString(inputWriter, "/reset\nhello\n")
+	require.NoError(t, err)
+	third := nextMessage()
+	assert.NotEqual(t, first.ThreadTimeStamp, third.ThreadTimeStamp, "/reset starts a new thread")
+	repl.PromptAnswered(third.Channel, third.ThreadTimeStamp, third.TimeStamp)
+
+	_, err = io.WriteString(inputWriter, "/quit\n")
+	require.NoError(t, err)
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after /quit")
+	}
+}
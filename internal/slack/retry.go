@@ -0,0 +1,202 @@
+package slackbot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	// retryWithEditAction is the action ID of the button under answers
+	retryWithEditAction = "retry_with_edit"
+	// retryModalCallback identifies the submissions of the retry modal
+	retryModalCallback = "retry_with_edit"
+	// retryPromptBlock and retryPromptAction identify the prompt input of the modal
+	retryPromptBlock  = "prompt"
+	retryPromptAction = "prompt"
+
+	retryButtonText = "✏️ Retry with edit"
+	retryNoticeText = "✏️ <@%s> retried with an edit:\n%s"
+	// maxModalInput is the longest initial value Slack accepts in a text input
+	maxModalInput = 3000
+)
+
+// RetryFrontend is implemented by frontends that can open modals, which the
+// "Retry with edit" and "Show work" buttons need
+type RetryFrontend interface {
+	ToolNoticeFrontend
+	OpenView(triggerID string, view slack.ModalViewRequest) (*slack.ViewResponse, error)
+}
+
+// retryMetadata is carried by the modal from the button click to its submission
+type retryMetadata struct {
+	ChannelID string `json:"channel"`
+	ThreadTS  string `json:"thread"`
+	PromptTS  string `json:"prompt"`
+}
+
+// offerAnswerActions posts the buttons that follow the answer to the prompt posted
+// at promptTS: "Retry with edit", and "Show work" when the agent's steps were
+// recorded. They are queued like the answer so they appear below it.
+func (c *Client) offerAnswerActions(ctx context.Context, channelID, threadTS, promptTS string) {
+	if requestCancelled(ctx) {
+		return
+	}
+	if _, ok := c.userFrontend.(RetryFrontend); !ok {
+		return
+	}
+	var buttons []slack.BlockElement
+	if c.cfg.Slack.RetryWithEdit {
+		buttons = append(buttons, slack.NewButtonBlockElement(retryWithEditAction, promptTS, slack.NewTextBlockObject(slack.PlainTextType, retryButtonText, true, false)))
+	}
+	if button := c.showWorkButton(channelID, threadTS, promptTS); button != nil {
+		buttons = append(buttons, button)
+	}
+	if len(buttons) == 0 {
+		return
+	}
+	message, err := json.Marshal(map[string]interface{}{
+		"text":   answerActionsText(buttons),
+		"blocks": []slack.Block{slack.NewActionBlock("", buttons...)},
+	})
+	if err != nil {
+		c.logger.WarnKV("Failed to build answer buttons", "error", err)
+		return
+	}
+	c.userFrontend.SendMessage(channelID, threadTS, string(message))
+}
+
+// answerActionsText is the notification text of the buttons message
+func answerActionsText(buttons []slack.BlockElement) string {
+	labels := make([]string, 0, len(buttons))
+	for _, element := range buttons {
+		if button, ok := element.(*slack.ButtonBlockElement); ok {
+			labels = append(labels, button.Text.Text)
+		}
+	}
+	return strings.Join(labels, " · ")
+}
+
+// openRetryModal opens a modal pre-filled with the prompt the clicked button follows
+func (c *Client) openRetryModal(callback slack.InteractionCallback, promptTS string) {
+	frontend, ok := c.userFrontend.(RetryFrontend)
+	if !ok {
+		return
+	}
+	channelID := callback.Channel.ID
+	threadTS := callback.Message.ThreadTimestamp
+	if threadTS == "" {
+		threadTS = callback.Message.Timestamp
+	}
+	prompt, ok := c.promptText(channelID, threadTS, promptTS)
+	if !ok {
+		c.logger.WarnKV("Prompt to retry not found", "channel", channelID, "thread_ts", threadTS, "prompt_ts", promptTS)
+		c.sendMessage(channelID, threadTS, "Sorry, I could not find the message to retry.")
+		return
+	}
+	if len(prompt) > maxModalInput {
+		prompt = strings.ToValidUTF8(prompt[:maxModalInput], "")
+	}
+	metadata, err := json.Marshal(retryMetadata{ChannelID: channelID, ThreadTS: threadTS, PromptTS: promptTS})
+	if err != nil {
+		c.logger.WarnKV("Failed to encode retry metadata", "error", err)
+		return
+	}
+
+	input := slack.NewPlainTextInputBlockElement(nil, retryPromptAction)
+	input.Multiline = true
+	input.InitialValue = prompt
+	view := slack.ModalViewRequest{
+		Type:            slack.VTModal,
+		CallbackID:      retryModalCallback,
+		Title:           slack.NewTextBlockObject(slack.PlainTextType, "Retry with edit", false, false),
+		Submit:          slack.NewTextBlockObject(slack.PlainTextType, "Run", false, false),
+		Close:           slack.NewTextBlockObject(slack.PlainTextType, "Cancel", false, false),
+		PrivateMetadata: string(metadata),
+		Blocks: slack.Blocks{BlockSet: []slack.Block{
+			slack.NewInputBlock(retryPromptBlock, slack.NewTextBlockObject(slack.PlainTextType, "Prompt", false, false), nil, input),
+		}},
+	}
+	if _, err := frontend.OpenView(callback.TriggerID, view); err != nil {
+		c.logger.WarnKV("Failed to open retry modal", "channel", channelID, "user", callback.User.ID, "error", err)
+	}
+}
+
+// handleRetrySubmission runs the edited prompt as a sibling of the original: the
+// new run sees the conversation up to the original prompt, not its answer
+func (c *Client) handleRetrySubmission(callback slack.InteractionCallback) {
+	var metadata retryMetadata
+	if err := json.Unmarshal([]byte(callback.View.PrivateMetadata), &metadata); err != nil {
+		c.logger.WarnKV("Ignored retry submission with invalid metadata", "error", err)
+		return
+	}
+	prompt := strings.TrimSpace(callback.View.State.Values[retryPromptBlock][retryPromptAction].Value)
+	if prompt == "" {
+		return
+	}
+	frontend, ok := c.userFrontend.(RetryFrontend)
+	if !ok {
+		return
+	}
+
+	userID := callback.User.ID
+	c.logger.InfoKV("Retrying prompt with an edit", "channel", metadata.ChannelID, "thread_ts", metadata.ThreadTS, "prompt_ts", metadata.PromptTS, "user", userID)
+
+	// The notice shows the edited prompt in the thread, and its timestamp
+	// identifies the new run, so it can be cancelled and retried in turn
+	text := fmt.Sprintf(retryNoticeText, userID, quote(prompt))
+	ts, err := frontend.PostBlocks(metadata.ChannelID, metadata.ThreadTS, text,
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, text, false, false), nil, nil))
+	if err != nil {
+		c.logger.ErrorKV("Failed to post retry notice", "channel", metadata.ChannelID, "error", err)
+		return
+	}
+
+	profile, err := c.userFrontend.GetUserInfo(userID)
+	if err != nil {
+		c.logger.WarnKV("Failed to get user info", "user", userID, "error", err)
+		profile = &UserProfile{userId: userID, realName: "Unknown", email: ""}
+	}
+	c.answerPrompt(prompt, metadata.ChannelID, metadata.ThreadTS, ts, profile, metadata.PromptTS)
+}
+
+// promptText returns the text of the user prompt posted at ts, from the history or
+// else from the thread
+func (c *Client) promptText(channelID, threadTS, ts string) (string, bool) {
+	for _, msg := range c.threadHistory(channelID, threadTS) {
+		if msg.Role == "user" && msg.SlackTimestamp == ts {
+			return msg.Content, true
+		}
+	}
+	replies, err := c.userFrontend.GetThreadReplies(channelID, threadTS)
+	if err != nil {
+		c.logger.WarnKV("Failed to fetch thread replies", "channel", channelID, "thread_ts", threadTS, "error", err)
+		return "", false
+	}
+	for _, reply := range replies {
+		if reply.Timestamp == ts && reply.BotID == "" {
+			return strings.TrimSpace(c.userFrontend.RemoveBotMention(reply.Text)), true
+		}
+	}
+	return "", false
+}
+
+// historyBefore returns the thread history that precedes the message posted at ts,
+// or all of it when the message is no longer in the history
+func (c *Client) historyBefore(channelID, threadTS, ts string) []Message {
+	history := c.threadHistory(channelID, threadTS)
+	for i, msg := range history {
+		if msg.SlackTimestamp == ts {
+			return history[:i]
+		}
+	}
+	return history
+}
+
+// quote formats text as a Slack block quote
+func quote(text string) string {
+	return "> " + strings.ReplaceAll(text, "\n", "\n> ")
+}
@@ -0,0 +1,128 @@
+package slackbot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// modalRecorder is a frontend that can open modals and records them
+type modalRecorder struct {
+	*noticeRecorder
+	views []slack.ModalViewRequest
+}
+
+func (r *modalRecorder) OpenView(_ string, view slack.ModalViewRequest) (*slack.ViewResponse, error) {
+	r.views = append(r.views, view)
+	return &slack.ViewResponse{}, nil
+}
+
+func newRetryTestClient() (*Client, *modalRecorder, *bytes.Buffer) {
+	client, progress, output := newProgressTestClient()
+	client.cfg.Slack.RetryWithEdit = true
+	client.historyLimit = 50
+	client.messageHistory = map[string][]Message{}
+	frontend := &modalRecorder{noticeRecorder: &noticeRecorder{progressRecorder: progress}}
+	client.userFrontend = frontend
+	return client, frontend, output
+}
+
+func TestRetryButtonFollowsAnswer(t *testing.T) {
+	client, _, output := newRetryTestClient()
+
+	client.offerAnswerActions(context.Background(), "C1", "100.1", "100.3")
+	sent := strings.Split(output.String(), "\n")
+	require.Len(t, sent, 4)
+	var message struct {
+		Blocks []map[string]interface{} `json:"blocks"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(sent[1]), &message))
+	require.Len(t, message.Blocks, 1)
+	button := message.Blocks[0]["elements"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, retryWithEditAction, button["action_id"])
+	assert.Equal(t, "100.3", button["value"])
+
+	// Nothing is offered when the feature is off
+	output.Reset()
+	client.cfg.Slack.RetryWithEdit = false
+	client.offerAnswerActions(context.Background(), "C1", "100.1", "100.3")
+	assert.Empty(t, output.String())
+}
+
+func TestRetryModalIsPrefilled(t *testing.T) {
+	client, frontend, _ := newRetryTestClient()
+	client.addToHistory("C1", "100.1", "100.1", "user", "deploy api", "U1", "", "")
+	client.addToHistory("C1", "100.1", "100.2", "assistant", "Deployed.", "B1", "", "")
+
+	client.handleInteraction(slack.InteractionCallback{
+		Type:           slack.InteractionTypeBlockActions,
+		TriggerID:      "trigger",
+		Channel:        slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C1"}}},
+		User:           slack.User{ID: "U1"},
+		Message:        slack.Message{Msg: slack.Msg{Timestamp: "100.4", ThreadTimestamp: "100.1"}},
+		ActionCallback: slack.ActionCallbacks{BlockActions: []*slack.BlockAction{{ActionID: retryWithEditAction, Value: "100.1"}}},
+	})
+	require.Len(t, frontend.views, 1)
+	view := frontend.views[0]
+	assert.Equal(t, retryModalCallback, view.CallbackID)
+	input := view.Blocks.BlockSet[0].(*slack.InputBlock).Element.(*slack.PlainTextInputBlockElement)
+	assert.Equal(t, "deploy api", input.InitialValue)
+
+	var metadata retryMetadata
+	require.NoError(t, json.Unmarshal([]byte(view.PrivateMetadata), &metadata))
+	assert.Equal(t, retryMetadata{ChannelID: "C1", ThreadTS: "100.1", PromptTS: "100.1"}, metadata)
+}
+
+func TestRetryBranchesOffOriginalPrompt(t *testing.T) {
+	client, _, _ := newRetryTestClient()
+	client.addToHistory("C1", "100.1", "100.1", "user", "list services", "U1", "", "")
+	client.addToHistory("C1", "100.1", "100.2", "assistant", "api, web", "B1", "", "")
+	client.addToHistory("C1", "100.1", "100.3", "user", "deploy api", "U1", "", "")
+	client.addToHistory("C1", "100.1", "100.4", "assistant", "Deployed.", "B1", "", "")
+
+	branch := client.historyBefore("C1", "100.1", "100.3")
+	require.Len(t, branch, 2)
+	assert.Equal(t, "api, web", branch[1].Content)
+
+	background := client.contextFromMessages("C1", branch)
+	assert.Contains(t, background, "list services")
+	assert.NotContains(t, background, "Deployed.")
+
+	// The branch does not share its backing array with the history
+	branch = append(branch, Message{Content: "deploy web"})
+	assert.Equal(t, "deploy api", client.messageHistory[historyKey("C1", "100.1")][2].Content)
+	assert.Equal(t, "deploy web", branch[2].Content)
+}
+
+func TestRetryReadsHistoryWhileThreadIsAnswered(t *testing.T) {
+	client, _, _ := newRetryTestClient()
+	client.addToHistory("C1", "100.1", "100.1", "user", "deploy api", "U1", "", "")
+
+	// The thread's next messages land in the history while a retry looks up its prompt
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 40; i++ {
+			client.addToHistory("C1", "100.1", fmt.Sprintf("101.%d", i), "assistant", "Deployed.", "B1", "", "")
+		}
+	}()
+	for i := 0; i < 40; i++ {
+		prompt, ok := client.promptText("C1", "100.1", "100.1")
+		require.True(t, ok)
+		assert.Equal(t, "deploy api", prompt)
+	}
+	wg.Wait()
+}
+
+func TestQuote(t *testing.T) {
+	assert.Equal(t, "> deploy\n> api", quote("deploy\napi"))
+}
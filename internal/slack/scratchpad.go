@@ -0,0 +1,199 @@
+package slackbot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/tmc/langchaingo/callbacks"
+	"github.com/tmc/langchaingo/schema"
+
+	"github.com/tuannvm/slack-mcp-client/internal/scratchpad"
+)
+
+const (
+	// showWorkAction is the action ID of the "Show work" button under answers
+	showWorkAction = "show_work"
+
+	showWorkButtonText = "🧠 Show work"
+	// maxResumeObservation bounds each tool result a follow-up prompt is given
+	maxResumeObservation = 1000
+	// maxWorkSteps bounds the steps shown in the modal, which holds up to 100 blocks
+	maxWorkSteps = 45
+	// maxSectionText is the longest text Slack accepts in a section block
+	maxSectionText = 3000
+)
+
+// scratchpadRecorder collects the steps of an agent run from its callbacks
+type scratchpadRecorder struct {
+	callbacks.SimpleHandler
+
+	mu    sync.Mutex
+	steps []scratchpad.Step
+}
+
+func (r *scratchpadRecorder) HandleAgentAction(_ context.Context, action schema.AgentAction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.steps = append(r.steps, scratchpad.Step{
+		Thought:     thoughtOf(action.Log),
+		Action:      action.Tool,
+		ActionInput: strings.TrimSpace(strings.TrimSuffix(action.ToolInput, "\nObservation:")),
+	})
+}
+
+func (r *scratchpadRecorder) HandleToolEnd(_ context.Context, output string) {
+	r.observe(output)
+}
+
+func (r *scratchpadRecorder) HandleToolError(_ context.Context, err error) {
+	r.observe("Error: " + err.Error())
+}
+
+// observe sets the result of the latest action
+func (r *scratchpadRecorder) observe(observation string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.steps) > 0 {
+		r.steps[len(r.steps)-1].Observation = observation
+	}
+}
+
+// thoughtOf returns the reasoning the agent wrote before choosing an action
+func thoughtOf(log string) string {
+	if i := strings.Index(log, "Action:"); i >= 0 {
+		log = log[:i]
+	}
+	var lines []string
+	for _, line := range strings.Split(log, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "Thought: Do I need to use a tool?") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// newScratchpadRecorder returns a recorder for an agent run, or nil when
+// scratchpads are disabled
+func (c *Client) newScratchpadRecorder() *scratchpadRecorder {
+	if c.scratchpads == nil {
+		return nil
+	}
+	return &scratchpadRecorder{}
+}
+
+// saveScratchpad stores the steps of the agent run answering the prompt posted at promptTS
+func (c *Client) saveScratchpad(recorder *scratchpadRecorder, channelID, threadTS, promptTS, userID, prompt, answer string) {
+	if recorder == nil {
+		return
+	}
+	recorder.mu.Lock()
+	steps := append([]scratchpad.Step(nil), recorder.steps...)
+	recorder.mu.Unlock()
+	run := scratchpad.Run{PromptTS: promptTS, Prompt: prompt, UserID: userID, Steps: steps, Answer: answer, Finished: time.Now()}
+	if err := c.scratchpads.Save(channelID, threadTS, run); err != nil {
+		c.logger.WarnKV("Failed to save agent scratchpad", "channel", channelID, "thread_ts", threadTS, "error", err)
+	}
+}
+
+// scratchpadContext returns the thread's latest agent work before the prompt
+// posted at ts, so a follow-up can build on its tool results
+func (c *Client) scratchpadContext(channelID, threadTS, ts string) string {
+	if c.scratchpads == nil || !c.cfg.Slack.Scratchpad.Resumes() {
+		return ""
+	}
+	run, ok := c.scratchpads.Latest(channelID, threadTS, ts)
+	if !ok || len(run.Steps) == 0 {
+		return ""
+	}
+	return "Your work on an earlier request in this thread. Reuse these tool results instead of calling the tools again when they still answer the question:\n---\n" +
+		run.Context(maxResumeObservation) + "---\n"
+}
+
+// showWorkButton returns the "Show work" button for the answer to the prompt
+// posted at promptTS, or nil when its run made no tool calls
+func (c *Client) showWorkButton(channelID, threadTS, promptTS string) *slack.ButtonBlockElement {
+	if c.scratchpads == nil {
+		return nil
+	}
+	run, ok := c.scratchpads.Get(channelID, threadTS, promptTS)
+	if !ok || len(run.Steps) == 0 {
+		return nil
+	}
+	return slack.NewButtonBlockElement(showWorkAction, promptTS, slack.NewTextBlockObject(slack.PlainTextType, showWorkButtonText, true, false))
+}
+
+// openScratchpadModal shows the user who clicked "Show work" the steps behind the
+// answer. Tool results may have been fetched with the asking user's credentials, so
+// only that user or an admin, allowed to use the bot in the channel, can see them.
+func (c *Client) openScratchpadModal(callback slack.InteractionCallback, promptTS string) {
+	frontend, ok := c.userFrontend.(RetryFrontend)
+	if !ok || c.scratchpads == nil {
+		return
+	}
+	channelID := callback.Channel.ID
+	threadTS := callback.Message.ThreadTimestamp
+	if threadTS == "" {
+		threadTS = callback.Message.Timestamp
+	}
+	run, ok := c.scratchpads.Get(channelID, threadTS, promptTS)
+	if !ok {
+		c.logger.DebugKV("Scratchpad not found", "channel", channelID, "thread_ts", threadTS, "prompt_ts", promptTS)
+		return
+	}
+	blocks := scratchpadBlocks(run)
+	userID := callback.User.ID
+	if result := c.cfg.ValidateAccessWithDirectory(userID, channelID, c.security); !result.Allowed ||
+		(run.UserID != userID && !c.cfg.IsAdminUser(userID, c.security)) {
+		c.logger.WarnKV("Denied scratchpad to a user who may not see it", "channel", channelID, "user", userID, "asked_by", run.UserID)
+		text := "Only the user who asked or an admin can see this work."
+		if run.UserID != "" {
+			text = fmt.Sprintf("Only <@%s>, who asked, or an admin can see this work.", run.UserID)
+		}
+		blocks = []slack.Block{slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, text, false, false), nil, nil)}
+	}
+	view := slack.ModalViewRequest{
+		Type:   slack.VTModal,
+		Title:  slack.NewTextBlockObject(slack.PlainTextType, "Agent work", false, false),
+		Close:  slack.NewTextBlockObject(slack.PlainTextType, "Close", false, false),
+		Blocks: slack.Blocks{BlockSet: blocks},
+	}
+	if _, err := frontend.OpenView(callback.TriggerID, view); err != nil {
+		c.logger.WarnKV("Failed to open scratchpad modal", "channel", channelID, "user", callback.User.ID, "error", err)
+	}
+}
+
+// scratchpadBlocks lays out a run as one section per step
+func scratchpadBlocks(run scratchpad.Run) []slack.Block {
+	section := func(text string) slack.Block {
+		if len(text) > maxSectionText {
+			text = strings.ToValidUTF8(text[:maxSectionText-3], "") + "..."
+		}
+		return slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, text, false, false), nil, nil)
+	}
+	blocks := []slack.Block{section("*Request*\n" + quote(run.Prompt)), slack.NewDividerBlock()}
+	for i, step := range run.Steps {
+		if i == maxWorkSteps {
+			blocks = append(blocks, section(fmt.Sprintf("_%d more steps not shown._", len(run.Steps)-maxWorkSteps)))
+			break
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, "*%d. %s*\n", i+1, step.Action)
+		if step.Thought != "" {
+			b.WriteString(quote(step.Thought) + "\n")
+		}
+		fmt.Fprintf(&b, "Input: `%s`\n", step.ActionInput)
+		observation := step.Observation
+		if len(observation) > maxSectionText/2 {
+			observation = strings.ToValidUTF8(observation[:maxSectionText/2], "") + "..."
+		}
+		fmt.Fprintf(&b, "Result:\n```%s```", observation)
+		blocks = append(blocks, section(b.String()))
+	}
+	return blocks
+}
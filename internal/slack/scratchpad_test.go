@@ -0,0 +1,129 @@
+package slackbot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/schema"
+
+	"github.com/tuannvm/slack-mcp-client/internal/scratchpad"
+)
+
+func TestScratchpadRecorder(t *testing.T) {
+	recorder := &scratchpadRecorder{}
+	ctx := context.Background()
+	recorder.HandleAgentAction(ctx, schema.AgentAction{
+		Tool:      "kubectl",
+		ToolInput: "{\"cmd\": \"get pods\"}\nObservation:",
+		Log:       "Thought: Do I need to use a tool? Yes\nJustification: The user wants the pods.\nAction: kubectl\nAction Input: {\"cmd\": \"get pods\"}",
+	})
+	recorder.HandleToolEnd(ctx, "api-1")
+	recorder.HandleAgentAction(ctx, schema.AgentAction{Tool: "kubectl", ToolInput: "{}"})
+	recorder.HandleToolError(ctx, errors.New("forbidden"))
+
+	assert.Equal(t, []scratchpad.Step{
+		{Thought: "Justification: The user wants the pods.", Action: "kubectl", ActionInput: `{"cmd": "get pods"}`, Observation: "api-1"},
+		{Action: "kubectl", ActionInput: "{}", Observation: "Error: forbidden"},
+	}, recorder.steps)
+}
+
+func newScratchpadTestClient(t *testing.T) (*Client, *modalRecorder, func() []string) {
+	client, frontend, output := newRetryTestClient()
+	client.cfg.Slack.RetryWithEdit = false
+	store, err := scratchpad.NewStore("", 10)
+	require.NoError(t, err)
+	client.scratchpads = store
+	return client, frontend, func() []string { return strings.Split(output.String(), "\n") }
+}
+
+func TestShowWorkButtonAndModal(t *testing.T) {
+	client, frontend, sent := newScratchpadTestClient(t)
+
+	// Runs without tool calls offer nothing to show
+	client.saveScratchpad(&scratchpadRecorder{}, "C1", "100.1", "100.1", "U1", "hello", "Hi!")
+	client.offerAnswerActions(context.Background(), "C1", "100.1", "100.1")
+	assert.Equal(t, []string{""}, sent())
+
+	recorder := &scratchpadRecorder{steps: []scratchpad.Step{{Thought: "Need the pods.", Action: "kubectl", ActionInput: "{}", Observation: "api-1"}}}
+	client.saveScratchpad(recorder, "C1", "100.1", "100.3", "U1", "list pods", "One pod: api-1")
+	client.cfg.Slack.RetryWithEdit = true
+	client.offerAnswerActions(context.Background(), "C1", "100.1", "100.3")
+	var message struct {
+		Text   string                   `json:"text"`
+		Blocks []map[string]interface{} `json:"blocks"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(sent()[1]), &message))
+	buttons := message.Blocks[0]["elements"].([]interface{})
+	require.Len(t, buttons, 2)
+	assert.Equal(t, showWorkAction, buttons[1].(map[string]interface{})["action_id"])
+	assert.Equal(t, retryButtonText+" · "+showWorkButtonText, message.Text)
+
+	client.handleInteraction(slack.InteractionCallback{
+		Type:           slack.InteractionTypeBlockActions,
+		TriggerID:      "trigger",
+		Channel:        slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C1"}}},
+		User:           slack.User{ID: "U1"},
+		Message:        slack.Message{Msg: slack.Msg{Timestamp: "100.5", ThreadTimestamp: "100.1"}},
+		ActionCallback: slack.ActionCallbacks{BlockActions: []*slack.BlockAction{{ActionID: showWorkAction, Value: "100.3"}}},
+	})
+	require.Len(t, frontend.views, 1)
+	blocks := frontend.views[0].Blocks.BlockSet
+	require.Len(t, blocks, 3)
+	step := blocks[2].(*slack.SectionBlock).Text.Text
+	assert.Equal(t, "*1. kubectl*\n> Need the pods.\nInput: `{}`\nResult:\n```api-1```", step)
+}
+
+func TestShowWorkIsOnlyForTheAskerAndAdmins(t *testing.T) {
+	client, frontend, _ := newScratchpadTestClient(t)
+	client.cfg.Security.AdminUsers = []string{"UADMIN"}
+	recorder := &scratchpadRecorder{steps: []scratchpad.Step{{Action: "kubectl", ActionInput: "{}", Observation: "api-1"}}}
+	client.saveScratchpad(recorder, "C1", "100.1", "100.1", "U1", "list pods", "One pod: api-1")
+
+	showWork := func(userID string) []slack.Block {
+		client.handleInteraction(slack.InteractionCallback{
+			Type:           slack.InteractionTypeBlockActions,
+			TriggerID:      "trigger",
+			Channel:        slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C1"}}},
+			User:           slack.User{ID: userID},
+			Message:        slack.Message{Msg: slack.Msg{Timestamp: "100.2", ThreadTimestamp: "100.1"}},
+			ActionCallback: slack.ActionCallbacks{BlockActions: []*slack.BlockAction{{ActionID: showWorkAction, Value: "100.1"}}},
+		})
+		return frontend.views[len(frontend.views)-1].Blocks.BlockSet
+	}
+
+	// Another user of the thread only learns who can see the work
+	blocks := showWork("U2")
+	require.Len(t, blocks, 1)
+	assert.Equal(t, "Only <@U1>, who asked, or an admin can see this work.", blocks[0].(*slack.SectionBlock).Text.Text)
+
+	assert.Len(t, showWork("U1"), 3)
+	assert.Len(t, showWork("UADMIN"), 3)
+
+	// Nor can the asker once they are no longer allowed in the channel
+	client.cfg.Security.Enabled = true
+	client.cfg.Security.AllowedUsers = []string{"U2"}
+	assert.Len(t, showWork("U1"), 1)
+}
+
+func TestScratchpadContextResumesLatestRun(t *testing.T) {
+	client, _, _ := newScratchpadTestClient(t)
+	assert.Empty(t, client.scratchpadContext("C1", "100.1", "100.5"))
+
+	recorder := &scratchpadRecorder{steps: []scratchpad.Step{{Action: "kubectl", ActionInput: "{}", Observation: "api-1"}}}
+	client.saveScratchpad(recorder, "C1", "100.1", "100.1", "U1", "list pods", "One pod: api-1")
+	resumed := client.scratchpadContext("C1", "100.1", "100.5")
+	assert.Contains(t, resumed, "Step 1: called kubectl with {}\nResult: api-1\n")
+
+	// A retry of the first prompt does not see its own work
+	assert.Empty(t, client.scratchpadContext("C1", "100.1", "100.1"))
+
+	resume := false
+	client.cfg.Slack.Scratchpad.Resume = &resume
+	assert.Empty(t, client.scratchpadContext("C1", "100.1", "100.5"))
+}
@@ -0,0 +1,192 @@
+package slackbot
+
+import (
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+)
+
+// DirectoryFrontend is implemented by frontends that can look up Slack user
+// groups and channels. It lets the security lists name user groups (@sre-team)
+// and channels (#prod-*) instead of listing IDs.
+type DirectoryFrontend interface {
+	GetUserGroups(options ...slack.GetUserGroupsOption) ([]slack.UserGroup, error)
+	GetConversationInfo(input *slack.GetConversationInfoInput) (*slack.Channel, error)
+	GetConversations(params *slack.GetConversationsParameters) ([]slack.Channel, string, error)
+	GetUsersInConversation(params *slack.GetUsersInConversationParameters) ([]string, string, error)
+}
+
+// cachedLookup is a resolved value and when it must be looked up again
+type cachedLookup[T any] struct {
+	value   T
+	expires time.Time
+}
+
+// securityDirectory resolves the names in the security lists through the Slack
+// API. Results are cached for the configured TTL, so people joining or leaving a
+// user group or channel are picked up without a restart. Failed lookups are not
+// cached and report no match.
+type securityDirectory struct {
+	api    DirectoryFrontend
+	ttl    time.Duration
+	logger *logging.Logger
+	now    func() time.Time
+
+	mu         sync.Mutex
+	groups     *cachedLookup[map[string]map[string]bool] // User group handle -> member IDs
+	channelIDs *cachedLookup[map[string]string]          // Channel name -> ID
+	names      map[string]cachedLookup[string]           // Channel ID -> name
+	members    map[string]cachedLookup[map[string]bool]  // Channel ID -> member IDs
+}
+
+// newSecurityDirectory creates a directory that caches lookups for ttl
+func newSecurityDirectory(api DirectoryFrontend, ttl time.Duration, logger *logging.Logger) *securityDirectory {
+	return &securityDirectory{
+		api:     api,
+		ttl:     ttl,
+		logger:  logger,
+		now:     time.Now,
+		names:   make(map[string]cachedLookup[string]),
+		members: make(map[string]cachedLookup[map[string]bool]),
+	}
+}
+
+// IsUserGroupMember reports whether the user belongs to the user group with the handle
+func (d *securityDirectory) IsUserGroupMember(handle, userID string) bool {
+	d.mu.Lock()
+	groups := d.groups
+	d.mu.Unlock()
+	if groups == nil || d.now().After(groups.expires) {
+		userGroups, err := d.api.GetUserGroups(slack.GetUserGroupsOptionIncludeUsers(true))
+		if err != nil {
+			d.logger.WarnKV("Failed to look up Slack user groups", "handle", handle, "error", err)
+			return false
+		}
+		resolved := make(map[string]map[string]bool, len(userGroups))
+		for _, group := range userGroups {
+			resolved[group.Handle] = toSet(group.Users)
+		}
+		groups = &cachedLookup[map[string]map[string]bool]{value: resolved, expires: d.now().Add(d.ttl)}
+		d.mu.Lock()
+		d.groups = groups
+		d.mu.Unlock()
+	}
+	members, exists := groups.value[handle]
+	if !exists {
+		d.logger.DebugKV("Unknown Slack user group in security list", "handle", handle)
+	}
+	return members[userID]
+}
+
+// IsChannelMember reports whether the user is a member of the named channel
+func (d *securityDirectory) IsChannelMember(channelName, userID string) bool {
+	channelID := d.channelID(channelName)
+	if channelID == "" {
+		return false
+	}
+
+	d.mu.Lock()
+	cached, exists := d.members[channelID]
+	d.mu.Unlock()
+	if !exists || d.now().After(cached.expires) {
+		members, err := d.channelMembers(channelID)
+		if err != nil {
+			d.logger.WarnKV("Failed to look up Slack channel members", "channel", channelName, "error", err)
+			return false
+		}
+		cached = cachedLookup[map[string]bool]{value: members, expires: d.now().Add(d.ttl)}
+		d.mu.Lock()
+		d.members[channelID] = cached
+		d.mu.Unlock()
+	}
+	return cached.value[userID]
+}
+
+// ChannelName returns the name of a channel, or "" when it cannot be resolved
+func (d *securityDirectory) ChannelName(channelID string) string {
+	d.mu.Lock()
+	cached, exists := d.names[channelID]
+	d.mu.Unlock()
+	if exists && !d.now().After(cached.expires) {
+		return cached.value
+	}
+	channel, err := d.api.GetConversationInfo(&slack.GetConversationInfoInput{ChannelID: channelID})
+	if err != nil {
+		d.logger.WarnKV("Failed to look up Slack channel name", "channel", channelID, "error", err)
+		return ""
+	}
+	d.mu.Lock()
+	d.names[channelID] = cachedLookup[string]{value: channel.Name, expires: d.now().Add(d.ttl)}
+	d.mu.Unlock()
+	return channel.Name
+}
+
+// channelID resolves a channel name, listing the public and private channels the
+// app can see
+func (d *securityDirectory) channelID(channelName string) string {
+	d.mu.Lock()
+	channelIDs := d.channelIDs
+	d.mu.Unlock()
+	if channelIDs == nil || d.now().After(channelIDs.expires) {
+		resolved := make(map[string]string)
+		params := &slack.GetConversationsParameters{
+			ExcludeArchived: true,
+			Limit:           1000,
+			Types:           []string{"public_channel", "private_channel"},
+		}
+		for {
+			channels, cursor, err := d.api.GetConversations(params)
+			if err != nil {
+				d.logger.WarnKV("Failed to list Slack channels", "channel", channelName, "error", err)
+				return ""
+			}
+			for _, channel := range channels {
+				resolved[channel.Name] = channel.ID
+			}
+			if cursor == "" {
+				break
+			}
+			params.Cursor = cursor
+		}
+		channelIDs = &cachedLookup[map[string]string]{value: resolved, expires: d.now().Add(d.ttl)}
+		d.mu.Lock()
+		d.channelIDs = channelIDs
+		d.mu.Unlock()
+	}
+	channelID, exists := channelIDs.value[channelName]
+	if !exists {
+		d.logger.DebugKV("Unknown Slack channel in security list", "channel", channelName)
+	}
+	return channelID
+}
+
+// channelMembers lists every member of a channel
+func (d *securityDirectory) channelMembers(channelID string) (map[string]bool, error) {
+	members := make(map[string]bool)
+	params := &slack.GetUsersInConversationParameters{ChannelID: channelID, Limit: 1000}
+	for {
+		users, cursor, err := d.api.GetUsersInConversation(params)
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range users {
+			members[user] = true
+		}
+		if cursor == "" {
+			return members, nil
+		}
+		params.Cursor = cursor
+	}
+}
+
+// toSet converts a list of IDs to a set
+func toSet(ids []string) map[string]bool {
+	set := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
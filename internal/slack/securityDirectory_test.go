@@ -0,0 +1,125 @@
+package slackbot
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+)
+
+// fakeDirectoryAPI serves user groups and channels from memory and counts calls
+type fakeDirectoryAPI struct {
+	groups   []slack.UserGroup
+	channels []slack.Channel
+	members  map[string][]string
+	fail     bool
+	calls    map[string]int
+}
+
+func (f *fakeDirectoryAPI) count(method string) error {
+	if f.calls == nil {
+		f.calls = make(map[string]int)
+	}
+	f.calls[method]++
+	if f.fail {
+		return errors.New("slack unavailable")
+	}
+	return nil
+}
+
+func (f *fakeDirectoryAPI) GetUserGroups(...slack.GetUserGroupsOption) ([]slack.UserGroup, error) {
+	if err := f.count("groups"); err != nil {
+		return nil, err
+	}
+	return f.groups, nil
+}
+
+func (f *fakeDirectoryAPI) GetConversationInfo(input *slack.GetConversationInfoInput) (*slack.Channel, error) {
+	if err := f.count("info"); err != nil {
+		return nil, err
+	}
+	for _, channel := range f.channels {
+		if channel.ID == input.ChannelID {
+			return &channel, nil
+		}
+	}
+	return nil, errors.New("channel_not_found")
+}
+
+func (f *fakeDirectoryAPI) GetConversations(params *slack.GetConversationsParameters) ([]slack.Channel, string, error) {
+	if err := f.count("list"); err != nil {
+		return nil, "", err
+	}
+	// Serve one channel per page to exercise pagination
+	page := 0
+	if params.Cursor != "" {
+		page = int(params.Cursor[0] - '0')
+	}
+	if page >= len(f.channels) {
+		return nil, "", nil
+	}
+	cursor := ""
+	if page+1 < len(f.channels) {
+		cursor = string(rune('0' + page + 1))
+	}
+	return f.channels[page : page+1], cursor, nil
+}
+
+func (f *fakeDirectoryAPI) GetUsersInConversation(params *slack.GetUsersInConversationParameters) ([]string, string, error) {
+	if err := f.count("members"); err != nil {
+		return nil, "", err
+	}
+	return f.members[params.ChannelID], "", nil
+}
+
+func newFakeChannel(id, name string) slack.Channel {
+	channel := slack.Channel{}
+	channel.ID = id
+	channel.Name = name
+	return channel
+}
+
+func TestSecurityDirectory(t *testing.T) {
+	api := &fakeDirectoryAPI{
+		groups:   []slack.UserGroup{{Handle: "sre-team", Users: []string{"U1"}}},
+		channels: []slack.Channel{newFakeChannel("C1", "general"), newFakeChannel("C2", "sre-private")},
+		members:  map[string][]string{"C2": {"U2"}},
+	}
+	now := time.Now()
+	directory := newSecurityDirectory(api, 10*time.Minute, logging.New("test", logging.LevelError))
+	directory.now = func() time.Time { return now }
+
+	assert.True(t, directory.IsUserGroupMember("sre-team", "U1"))
+	assert.False(t, directory.IsUserGroupMember("sre-team", "U2"))
+	assert.False(t, directory.IsUserGroupMember("unknown", "U1"))
+	assert.Equal(t, 1, api.calls["groups"])
+
+	assert.True(t, directory.IsChannelMember("sre-private", "U2"))
+	assert.False(t, directory.IsChannelMember("sre-private", "U1"))
+	assert.False(t, directory.IsChannelMember("missing", "U2"))
+	assert.Equal(t, 2, api.calls["list"], "channels are listed once, across pages")
+	assert.Equal(t, 1, api.calls["members"])
+
+	assert.Equal(t, "general", directory.ChannelName("C1"))
+	assert.Equal(t, "general", directory.ChannelName("C1"))
+	assert.Equal(t, 1, api.calls["info"])
+
+	// Membership changes are picked up once the cache expires
+	api.groups[0].Users = []string{"U2"}
+	assert.False(t, directory.IsUserGroupMember("sre-team", "U2"))
+	now = now.Add(11 * time.Minute)
+	assert.True(t, directory.IsUserGroupMember("sre-team", "U2"))
+	assert.Equal(t, 2, api.calls["groups"])
+
+	// Failed lookups deny access and are retried on the next check
+	now = now.Add(11 * time.Minute)
+	api.fail = true
+	assert.False(t, directory.IsUserGroupMember("sre-team", "U2"))
+	assert.Equal(t, "", directory.ChannelName("C2"))
+	api.fail = false
+	assert.True(t, directory.IsUserGroupMember("sre-team", "U2"))
+}
@@ -0,0 +1,199 @@
+package slackbot
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/llm"
+)
+
+// selfTestPrompt is sent to each LLM provider the bot uses to validate it
+const selfTestPrompt = "Reply with OK."
+
+// WorkspaceFrontend is implemented by frontends that know the workspace they are
+// connected to
+type WorkspaceFrontend interface {
+	// Workspace returns the name and URL of the connected workspace
+	Workspace() (string, string)
+}
+
+// selfTestProvider is an LLM provider validated by the self-test
+type selfTestProvider struct {
+	name  string
+	model string
+	err   error
+}
+
+// selfTestReport is what the self-test found once the bot started
+type selfTestReport struct {
+	workspace      string // Name and URL of the workspace, empty when the frontend does not tell
+	frontend       string
+	providers      []selfTestProvider
+	serversEnabled int
+	serversReady   int
+	failedServers  []string // Enabled servers that are not ready
+	tools          int
+	ragEnabled     bool
+	ragDocuments   int
+	ragChunks      int
+	ragErr         error
+}
+
+// RunSelfTest checks what the bot started with and posts a summary to the
+// self-test channel, so operators know a deployment works without reading logs.
+// It is called once every MCP server finished initializing.
+func (c *Client) RunSelfTest(ctx context.Context) {
+	channel := c.cfg.SelfTest.Channel
+	if channel == "" {
+		return
+	}
+	report := c.selfTest(ctx)
+	if ctx.Err() != nil {
+		return
+	}
+	summary, passed := report.summary()
+	c.logger.InfoKV("Startup self-test completed", "passed", passed, "providers", len(report.providers),
+		"servers_ready", report.serversReady, "servers_enabled", report.serversEnabled, "tools", report.tools)
+	c.sendMessage(channel, "", summary)
+}
+
+// selfTest validates the LLM providers and gathers the state of the MCP servers and
+// the knowledge base
+func (c *Client) selfTest(ctx context.Context) selfTestReport {
+	report := selfTestReport{frontend: c.cfg.Frontend, ragEnabled: c.ragClient != nil}
+	if report.frontend == "" {
+		report.frontend = config.FrontendSlack
+	}
+	if frontend, ok := c.userFrontend.(WorkspaceFrontend); ok {
+		if name, url := frontend.Workspace(); name != "" {
+			report.workspace = name
+			if url != "" {
+				report.workspace = fmt.Sprintf("%s (%s)", name, strings.TrimSuffix(url, "/"))
+			}
+		}
+	}
+
+	timeout, _ := time.ParseDuration(c.cfg.SelfTest.ProviderTimeout) // Validated with the config
+	for _, name := range c.selfTestProviders() {
+		provider := selfTestProvider{name: name, model: c.cfg.LLM.Providers[name].Model}
+		provider.err = c.validateProvider(ctx, name, timeout)
+		report.providers = append(report.providers, provider)
+	}
+
+	clients := c.MCPClients()
+	for name, server := range c.cfg.MCPServers {
+		if server.Disabled {
+			continue
+		}
+		report.serversEnabled++
+		if clients[name] != nil {
+			report.serversReady++
+		} else {
+			report.failedServers = append(report.failedServers, name)
+		}
+	}
+	slices.Sort(report.failedServers)
+	report.tools = len(c.Tools())
+
+	if c.ragClient != nil {
+		stats, err := c.ragClient.GetProvider().GetStats(ctx)
+		if err != nil {
+			report.ragErr = err
+		} else {
+			report.ragDocuments, report.ragChunks = stats.TotalFiles, stats.TotalChunks
+		}
+	}
+	return report
+}
+
+// selfTestProviders returns the LLM providers the bot answers with: the primary
+// provider and those of model routing, the grounding check and the intent classifier
+func (c *Client) selfTestProviders() []string {
+	names := []string{c.cfg.LLM.Provider}
+	if c.cfg.LLM.Routing.Enabled {
+		names = append(names, c.cfg.LLM.Routing.Cheap.Provider, c.cfg.LLM.Routing.Powerful.Provider)
+	}
+	if c.cfg.LLM.Grounding.Enabled {
+		names = append(names, c.cfg.LLM.Grounding.Provider)
+	}
+	if c.cfg.LLM.Intent.Enabled && c.cfg.LLM.Intent.Classifier == config.IntentClassifierLLM {
+		names = append(names, c.cfg.LLM.Intent.Provider)
+	}
+	names = slices.DeleteFunc(names, func(name string) bool { return name == "" })
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// validateProvider sends the test prompt to a provider
+func (c *Client) validateProvider(ctx context.Context, name string, timeout time.Duration) error {
+	if c.llmRegistry == nil {
+		return fmt.Errorf("no LLM provider registry")
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	response, err := c.llmRegistry.GenerateCompletion(ctx, name, selfTestPrompt, llm.ProviderOptions{MaxTokens: 16})
+	if err != nil {
+		c.logger.WarnKV("LLM provider failed the startup self-test", "provider", name, "error", err)
+		return err
+	}
+	if strings.TrimSpace(response.Content) == "" {
+		return fmt.Errorf("empty answer")
+	}
+	return nil
+}
+
+// summary formats the report for the self-test channel and reports whether every
+// check passed
+func (r selfTestReport) summary() (string, bool) {
+	passed := len(r.failedServers) == 0 && r.ragErr == nil
+	var lines []string
+	if r.workspace != "" {
+		lines = append(lines, fmt.Sprintf("• Workspace: %s", r.workspace))
+	} else {
+		lines = append(lines, fmt.Sprintf("• Frontend: %s", r.frontend))
+	}
+
+	providers := make([]string, 0, len(r.providers))
+	for _, provider := range r.providers {
+		label := provider.name
+		if provider.model != "" {
+			label = fmt.Sprintf("%s (%s)", provider.name, provider.model)
+		}
+		if provider.err != nil {
+			passed = false
+			providers = append(providers, fmt.Sprintf(":x: %s: %s", label, truncateSources(provider.err.Error(), 200)))
+		} else {
+			providers = append(providers, ":white_check_mark: "+label)
+		}
+	}
+	if len(providers) == 0 {
+		passed = false
+		providers = append(providers, ":x: none configured")
+	}
+	lines = append(lines, "• LLM providers: "+strings.Join(providers, ", "))
+
+	servers := fmt.Sprintf("• MCP servers: %d of %d ready, %d tools discovered", r.serversReady, r.serversEnabled, r.tools)
+	if len(r.failedServers) > 0 {
+		servers += fmt.Sprintf(" (:x: not ready: %s)", strings.Join(r.failedServers, ", "))
+	}
+	lines = append(lines, servers)
+
+	switch {
+	case !r.ragEnabled:
+		lines = append(lines, "• Knowledge base: disabled")
+	case r.ragErr != nil:
+		lines = append(lines, fmt.Sprintf("• Knowledge base: :x: %s", truncateSources(r.ragErr.Error(), 200)))
+	default:
+		lines = append(lines, fmt.Sprintf("• Knowledge base: %d documents, %d chunks", r.ragDocuments, r.ragChunks))
+	}
+
+	title := ":white_check_mark: *Startup self-test passed*"
+	if !passed {
+		title = ":warning: *Startup self-test found problems*"
+	}
+	return title + "\n" + strings.Join(lines, "\n"), passed
+}
@@ -0,0 +1,92 @@
+package slackbot
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/handlers"
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+)
+
+// workspaceRecorder is a frontend connected to a workspace
+type workspaceRecorder struct {
+	*progressRecorder
+}
+
+func (workspaceRecorder) Workspace() (string, string) {
+	return "Acme", "https://acme.slack.com/"
+}
+
+func TestSelfTestSummary(t *testing.T) {
+	report := selfTestReport{
+		workspace:      "Acme (https://acme.slack.com)",
+		providers:      []selfTestProvider{{name: "openai", model: "gpt-4o"}},
+		serversEnabled: 2,
+		serversReady:   2,
+		tools:          42,
+		ragEnabled:     true,
+		ragDocuments:   120,
+		ragChunks:      3400,
+	}
+	summary, passed := report.summary()
+	assert.True(t, passed)
+	assert.Equal(t, ":white_check_mark: *Startup self-test passed*\n"+
+		"• Workspace: Acme (https://acme.slack.com)\n"+
+		"• LLM providers: :white_check_mark: openai (gpt-4o)\n"+
+		"• MCP servers: 2 of 2 ready, 42 tools discovered\n"+
+		"• Knowledge base: 120 documents, 3400 chunks", summary)
+
+	report.providers = append(report.providers, selfTestProvider{name: "anthropic", err: errors.New("401 invalid x-api-key")})
+	report.serversReady = 1
+	report.failedServers = []string{"github"}
+	report.ragEnabled = false
+	summary, passed = report.summary()
+	assert.False(t, passed)
+	assert.Contains(t, summary, ":warning: *Startup self-test found problems*")
+	assert.Contains(t, summary, ":x: anthropic: 401 invalid x-api-key")
+	assert.Contains(t, summary, "1 of 2 ready, 42 tools discovered (:x: not ready: github)")
+	assert.Contains(t, summary, "• Knowledge base: disabled")
+}
+
+func TestSelfTestProviders(t *testing.T) {
+	client, _, _ := newProgressTestClient()
+	client.cfg.LLM.Provider = config.ProviderOpenAI
+	assert.Equal(t, []string{config.ProviderOpenAI}, client.selfTestProviders())
+
+	client.cfg.LLM.Routing.Enabled = true
+	client.cfg.LLM.Routing.Cheap.Provider = config.ProviderOllama
+	client.cfg.LLM.Routing.Powerful.Provider = config.ProviderOpenAI
+	assert.Equal(t, []string{config.ProviderOllama, config.ProviderOpenAI}, client.selfTestProviders())
+
+	client.cfg.LLM.Intent = config.LLMIntentConfig{Enabled: true, Classifier: config.IntentClassifierLLM, Provider: config.ProviderAnthropic}
+	assert.Equal(t, []string{config.ProviderAnthropic, config.ProviderOllama, config.ProviderOpenAI}, client.selfTestProviders())
+}
+
+func TestRunSelfTestPostsToChannel(t *testing.T) {
+	client, progress, output := newProgressTestClient()
+	client.userFrontend = workspaceRecorder{progressRecorder: progress}
+	client.cfg.MCPServers = map[string]config.MCPServerConfig{
+		"kubernetes": {Command: "kubectl-mcp"},
+		"github":     {URL: "http://github-mcp:8080/mcp"},
+		"legacy":     {Command: "legacy-mcp", Disabled: true},
+	}
+	client.mcpClients = map[string]*mcp.Client{"kubernetes": {}}
+	client.discoveredTools = map[string]mcp.ToolInfo{"get_pods": {ServerName: "kubernetes"}}
+	client.llmMCPBridge = handlers.NewLLMMCPBridge(map[string]mcp.MCPClientInterface{}, log.New(os.Stderr, "", 0), client.discoveredTools, nil, client.cfg)
+
+	client.RunSelfTest(context.Background())
+	assert.Empty(t, output.String(), "Nothing is posted without a channel")
+
+	client.cfg.SelfTest.Channel = "C-ADMIN"
+	client.RunSelfTest(context.Background())
+	assert.Contains(t, output.String(), "• Workspace: Acme (https://acme.slack.com)")
+	assert.Contains(t, output.String(), "1 of 2 ready, 1 tools discovered (:x: not ready: github)")
+	// The test client has no LLM registry to validate providers with
+	assert.Contains(t, output.String(), ":warning: *Startup self-test found problems*")
+}
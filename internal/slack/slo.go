@@ -0,0 +1,51 @@
+package slackbot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/tuannvm/slack-mcp-client/internal/slo"
+)
+
+// recordPromptError counts a failed prompt towards its experiment arm and the
+// error rate objective. A prompt stopped by the user is not a failure.
+func (c *Client) recordPromptError(ctx context.Context) {
+	if ctx.Err() != nil {
+		return
+	}
+	c.experiments.Error(ctx)
+	slo.Fail(ctx)
+}
+
+// handleSLOAlert logs an objective that started or stopped burning its error
+// budget too fast, and posts it to the alert channel
+func (c *Client) handleSLOAlert(alert slo.Alert) {
+	text := fmt.Sprintf(":rotating_light: SLO *%s* is burning its error budget %.1fx faster than allowed: %s", alert.Objective, alert.BurnRate, alert.Summary)
+	if alert.Firing {
+		c.logger.WarnKV("SLO error budget burning too fast", "objective", alert.Objective, "burn_rate", alert.BurnRate, "summary", alert.Summary)
+	} else {
+		text = fmt.Sprintf(":white_check_mark: SLO *%s* recovered: %s", alert.Objective, alert.Summary)
+		c.logger.InfoKV("SLO recovered", "objective", alert.Objective, "burn_rate", alert.BurnRate, "summary", alert.Summary)
+	}
+	if alert.Channel != "" {
+		c.sendMessage(alert.Channel, "", text)
+	}
+}
+
+// sloLines summarizes the objectives over the SLO window for the App Home view
+func (c *Client) sloLines() string {
+	var lines strings.Builder
+	for _, status := range c.objectives.Statuses() {
+		if status.Samples == 0 {
+			fmt.Fprintf(&lines, "• *%s*: no data\n", status.Objective)
+			continue
+		}
+		marker := ""
+		if status.Firing {
+			marker = " :rotating_light:"
+		}
+		fmt.Fprintf(&lines, "• *%s*: %s, burn rate %.1f%s\n", status.Objective, status.Summary, status.BurnRate, marker)
+	}
+	return lines.String()
+}
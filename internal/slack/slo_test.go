@@ -0,0 +1,30 @@
+package slackbot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/slo"
+)
+
+func TestSLOAlertsPostToAlertChannel(t *testing.T) {
+	client, progress, _ := newProgressTestClient()
+	frontend := &alertRecorder{progressRecorder: progress, sent: map[string][]string{}}
+	client.userFrontend = frontend
+	cfg := &config.Config{SLO: config.SLOConfig{Enabled: true, MinSamples: 1, AlertChannel: "C-OPS"}}
+	cfg.ApplyDefaults()
+	client.objectives = slo.NewTracker(cfg.SLO)
+	client.objectives.SetAlertHandler(client.handleSLOAlert)
+
+	ctx, answered := client.objectives.StartAnswer(t.Context(), time.Now())
+	client.recordPromptError(ctx)
+	answered()
+	require.Len(t, frontend.sent["C-OPS"], 1)
+	assert.Equal(t, ":rotating_light: SLO *errors* is burning its error budget 20.0x faster than allowed: 1 of 1 prompts failed (objective 5%)", frontend.sent["C-OPS"][0])
+	assert.Contains(t, client.sloLines(), "• *errors*: 1 of 1 prompts failed (objective 5%), burn rate 20.0 :rotating_light:")
+	assert.Contains(t, client.sloLines(), "• *tool_failures*: no data")
+}
@@ -0,0 +1,688 @@
+package slackbot
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/slack/formatter"
+)
+
+// teamsTokenIssuer is the issuer of the tokens the Bot Framework sends with activities
+const teamsTokenIssuer = "https://api.botframework.com"
+
+// teamsConnectorScope is the OAuth scope of the Bot Connector service
+const teamsConnectorScope = "https://api.botframework.com/.default"
+
+// teamsClockSkew is the clock difference tolerated when checking token lifetimes
+const teamsClockSkew = 5 * time.Minute
+
+// teamsKeysTTL is how long the token signing keys are cached
+const teamsKeysTTL = 24 * time.Hour
+
+// teamsKeysRetryInterval is the least time between two fetches of the signing
+// keys, so tokens with made-up key IDs cannot make every request fetch them
+const teamsKeysRetryInterval = time.Minute
+
+// teamsMentionPattern matches the mention markup of Teams messages
+var teamsMentionPattern = regexp.MustCompile(`<at>([^<]*)</at>`)
+
+// TeamsClient is a UserFrontend that serves Microsoft Teams through the Bot
+// Framework. Teams posts activities to the messaging endpoint, where they are
+// authenticated and fed into the event channel as Slack events, so the dispatch
+// pipeline is shared. A channel post is a thread: its replies carry the channel
+// ID and the post's message ID as thread timestamp. Personal chats map to DMs
+// and group chats to group DMs. Answers in Block Kit are rendered as Adaptive
+// Cards.
+type TeamsClient struct {
+	appID           string
+	thinkingMessage string
+	server          *http.Server
+	connector       *http.Client // Authenticated with the app's credentials
+	verifier        *teamsTokenVerifier
+	logger          *logging.Logger
+	events          chan socketmode.Event
+	outbox          *outboundQueue
+
+	mu            sync.Mutex
+	botID         string
+	botName       string
+	conversations map[string]teamsConversationRef // By channel ID
+	users         map[string]*UserProfile
+	userChannels  map[string]string // Channel a user was last seen in, by user ID
+}
+
+// teamsConversationRef is what replies to a conversation need
+type teamsConversationRef struct {
+	ServiceURL       string
+	ConversationType string // personal, groupChat or channel
+}
+
+// teamsAccount is a Bot Framework channel account
+type teamsAccount struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	AADObjectID string `json:"aadObjectId,omitempty"`
+}
+
+// teamsActivity is the part of a Bot Framework activity the client uses
+type teamsActivity struct {
+	Type         string       `json:"type"`
+	ID           string       `json:"id"`
+	ServiceURL   string       `json:"serviceUrl"`
+	From         teamsAccount `json:"from"`
+	Recipient    teamsAccount `json:"recipient"`
+	Text         string       `json:"text"`
+	Conversation struct {
+		ID               string `json:"id"`
+		ConversationType string `json:"conversationType"`
+	} `json:"conversation"`
+	Entities []struct {
+		Type      string       `json:"type"`
+		Mentioned teamsAccount `json:"mentioned"`
+	} `json:"entities"`
+	ChannelData struct {
+		Channel *struct {
+			ID string `json:"id"`
+		} `json:"channel"`
+	} `json:"channelData"`
+}
+
+// teamsAPIError is an error response of the Bot Connector service
+type teamsAPIError struct {
+	Status int
+	Body   string
+}
+
+func (e *teamsAPIError) Error() string {
+	return fmt.Sprintf("teams connector error %d: %s", e.Status, e.Body)
+}
+
+// Retryable reports whether the outbound queue should retry the request
+func (e *teamsAPIError) Retryable() bool {
+	return e.Status >= 500
+}
+
+// NewTeamsClient creates a Microsoft Teams frontend. Replies are delivered
+// through an outbound queue configured like Slack's.
+func NewTeamsClient(teamsCfg config.TeamsConfig, stdLogger *logging.Logger, thinkingMessage string, outbound config.SlackOutboundConfig) (*TeamsClient, error) {
+	if teamsCfg.AppID == "" || teamsCfg.AppPassword == "" {
+		return nil, fmt.Errorf("TEAMS_APP_ID and TEAMS_APP_PASSWORD must be set")
+	}
+
+	teamsLogger := logging.New("teams-client", getLogLevel(stdLogger))
+	httpClient := &http.Client{Timeout: 30 * time.Second}
+	credentials := clientcredentials.Config{
+		ClientID:     teamsCfg.AppID,
+		ClientSecret: teamsCfg.AppPassword,
+		TokenURL:     teamsCfg.TokenURL,
+		Scopes:       []string{teamsConnectorScope},
+	}
+	connector := credentials.Client(context.WithValue(context.Background(), oauth2.HTTPClient, httpClient))
+	connector.Timeout = 30 * time.Second
+
+	client := &TeamsClient{
+		appID:           teamsCfg.AppID,
+		thinkingMessage: thinkingMessage,
+		connector:       connector,
+		verifier:        newTeamsTokenVerifier(teamsCfg.OpenIDMetadataURL, teamsCfg.AppID, httpClient),
+		logger:          teamsLogger,
+		events:          make(chan socketmode.Event, 50),
+		conversations:   make(map[string]teamsConversationRef),
+		users:           make(map[string]*UserProfile),
+		userChannels:    make(map[string]string),
+	}
+	client.outbox = newOutboundQueue(outbound, client.deliverMessage, teamsLogger)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(teamsCfg.MessagesPath, client.handleActivityRequest)
+	client.server = &http.Server{
+		Addr:              teamsCfg.ListenAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return client, nil
+}
+
+// Run starts the messaging endpoint and blocks until it stops
+func (t *TeamsClient) Run() error {
+	t.logger.InfoKV("Starting Microsoft Teams messaging endpoint", "addr", t.server.Addr)
+	if err := t.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("teams messaging endpoint failed: %w", err)
+	}
+	return nil
+}
+
+// Close stops the messaging endpoint and drains the outbound queue
+func (t *TeamsClient) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := t.server.Shutdown(ctx)
+	t.outbox.close()
+	return err
+}
+
+// handleActivityRequest authenticates an activity posted by the Bot Framework
+// and queues it as an event
+func (t *TeamsClient) handleActivityRequest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxEventBodyBytes))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	claims, err := t.verifier.verify(r.Header.Get("Authorization"))
+	if err != nil {
+		t.logger.WarnKV("Rejected Teams activity", "error", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	var activity teamsActivity
+	if err := json.Unmarshal(body, &activity); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	// The service URL receives the bot's credentials, so it must be the one the token was issued for
+	if claims.ServiceURL != "" && claims.ServiceURL != activity.ServiceURL {
+		t.logger.WarnKV("Rejected Teams activity with a foreign service URL", "service_url", activity.ServiceURL)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+
+	if event, ok := t.messageEvent(activity); ok {
+		queueEvent(t.events, event, t.logger)
+	}
+}
+
+// messageEvent translates a message activity into the Slack event the dispatch
+// pipeline handles, and remembers where replies to it go
+func (t *TeamsClient) messageEvent(activity teamsActivity) (socketmode.Event, bool) {
+	if activity.Type != "message" || activity.From.ID == "" || activity.Conversation.ID == "" {
+		return socketmode.Event{}, false
+	}
+
+	channelID, threadTS := activity.Conversation.ID, ""
+	conversationType := activity.Conversation.ConversationType
+	if conversationType == "channel" && activity.ChannelData.Channel != nil {
+		// Channel posts are conversations of their own: <channel>;messageid=<post>
+		channelID = activity.ChannelData.Channel.ID
+		if _, post, ok := strings.Cut(activity.Conversation.ID, ";messageid="); ok && post != activity.ID {
+			threadTS = post
+		}
+	}
+
+	t.mu.Lock()
+	t.botID, t.botName = activity.Recipient.ID, activity.Recipient.Name
+	t.conversations[channelID] = teamsConversationRef{ServiceURL: activity.ServiceURL, ConversationType: conversationType}
+	if _, known := t.users[activity.From.ID]; !known {
+		t.users[activity.From.ID] = &UserProfile{userId: activity.From.ID, realName: activity.From.Name}
+	}
+	t.userChannels[activity.From.ID] = channelID
+	t.mu.Unlock()
+
+	mentioned := false
+	for _, entity := range activity.Entities {
+		mentioned = mentioned || (entity.Type == "mention" && entity.Mentioned.ID == activity.Recipient.ID)
+	}
+
+	var inner interface{}
+	switch {
+	case conversationType == "personal":
+		inner = &slackevents.MessageEvent{Type: "message", User: activity.From.ID, Text: activity.Text, TimeStamp: activity.ID, Channel: channelID, ChannelType: "im"}
+	case mentioned:
+		inner = &slackevents.AppMentionEvent{Type: "app_mention", User: activity.From.ID, Text: activity.Text, TimeStamp: activity.ID, ThreadTimeStamp: threadTS, Channel: channelID}
+	case conversationType == "groupChat":
+		inner = &slackevents.MessageEvent{Type: "message", User: activity.From.ID, Text: activity.Text, TimeStamp: activity.ID, Channel: channelID, ChannelType: "mpim"}
+	default:
+		inner = &slackevents.MessageEvent{Type: "message", User: activity.From.ID, Text: activity.Text, TimeStamp: activity.ID, ThreadTimeStamp: threadTS, Channel: channelID, ChannelType: "channel"}
+	}
+
+	return socketmode.Event{
+		Type: socketmode.EventTypeEventsAPI,
+		Data: slackevents.EventsAPIEvent{
+			Type:       slackevents.CallbackEvent,
+			Data:       &slackevents.EventsAPICallbackEvent{EventID: "teams-" + activity.ID},
+			InnerEvent: slackevents.EventsAPIInnerEvent{Data: inner},
+		},
+		Request: &socketmode.Request{},
+	}, true
+}
+
+// Ack is a no-op: activities are acknowledged when they are received
+func (t *TeamsClient) Ack(req socketmode.Request, payload ...interface{}) {}
+
+func (t *TeamsClient) GetEventChannel() chan socketmode.Event {
+	return t.events
+}
+
+// RemoveBotMention removes the <at>name</at> mentions of the bot
+func (t *TeamsClient) RemoveBotMention(msg string) string {
+	t.mu.Lock()
+	botName := t.botName
+	t.mu.Unlock()
+	if botName == "" {
+		return msg
+	}
+	return teamsMentionPattern.ReplaceAllStringFunc(msg, func(mention string) string {
+		if teamsMentionPattern.FindStringSubmatch(mention)[1] == botName {
+			return ""
+		}
+		return mention
+	})
+}
+
+func (t *TeamsClient) GetLogger() *logging.Logger {
+	return t.logger
+}
+
+func (t *TeamsClient) IsValidUser(userID string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return userID != "" && userID != t.botID
+}
+
+// GetConversationInfo reports the type of a conversation the bot received a
+// message from, so that mentions in group chats follow the group DM settings
+func (t *TeamsClient) GetConversationInfo(input *slack.GetConversationInfoInput) (*slack.Channel, error) {
+	t.mu.Lock()
+	ref, ok := t.conversations[input.ChannelID]
+	t.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown teams conversation %s", input.ChannelID)
+	}
+	channel := &slack.Channel{}
+	channel.ID = input.ChannelID
+	channel.IsIM = ref.ConversationType == "personal"
+	channel.IsMpIM = ref.ConversationType == "groupChat"
+	return channel, nil
+}
+
+// GetThreadReplies returns no messages: the Bot Framework cannot read
+// conversations, so the history is made of the messages the bot received
+func (t *TeamsClient) GetThreadReplies(channelID, threadTS string) ([]slack.Message, error) {
+	return []slack.Message{}, nil
+}
+
+// GetUserInfo returns the user's name and, when the connector shares it, email
+// address. Only users who wrote to the bot can be looked up.
+func (t *TeamsClient) GetUserInfo(userID string) (*UserProfile, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID must be provided")
+	}
+	t.mu.Lock()
+	profile, known := t.users[userID]
+	channelID := t.userChannels[userID]
+	ref := t.conversations[channelID]
+	t.mu.Unlock()
+	if !known {
+		return nil, fmt.Errorf("unknown teams user %s", userID)
+	}
+	if profile.email != "" || ref.ServiceURL == "" {
+		return profile, nil
+	}
+
+	var member struct {
+		Name              string `json:"name"`
+		Email             string `json:"email"`
+		UserPrincipalName string `json:"userPrincipalName"`
+	}
+	path := fmt.Sprintf("/v3/conversations/%s/members/%s", url.PathEscape(channelID), url.PathEscape(userID))
+	if err := t.api(http.MethodGet, ref.ServiceURL, path, nil, &member); err != nil {
+		t.logger.DebugKV("Failed to look up Teams member", "user", userID, "error", err)
+		return profile, nil
+	}
+	email := member.Email
+	if email == "" {
+		email = member.UserPrincipalName
+	}
+	realName := member.Name
+	if realName == "" {
+		realName = profile.realName
+	}
+	profile = &UserProfile{userId: userID, realName: realName, email: email}
+	t.mu.Lock()
+	t.users[userID] = profile
+	t.mu.Unlock()
+	return profile, nil
+}
+
+// SendMessage queues a message for delivery. The thinking message is shown as
+// the typing indicator instead.
+func (t *TeamsClient) SendMessage(channelID, threadTS, text string) {
+	if text == "" {
+		t.logger.WarnKV("Attempted to send empty message, skipping", "channel", channelID)
+		return
+	}
+	if text == t.thinkingMessage {
+		if err := t.post(channelID, threadTS, map[string]interface{}{"type": "typing"}); err != nil {
+			t.logger.DebugKV("Failed to show the typing indicator", "channel", channelID, "error", err)
+		}
+		return
+	}
+	t.outbox.enqueue(channelID, threadTS, text)
+}
+
+// deliverMessage posts a queued message, as an Adaptive Card when it is Block
+// Kit or structured data and as Markdown otherwise
+func (t *TeamsClient) deliverMessage(msg outboundMessage) error {
+	return t.post(msg.ChannelID, msg.ThreadTS, teamsMessage(msg.Text))
+}
+
+// teamsMessage builds the message activity of a reply
+func teamsMessage(text string) map[string]interface{} {
+	var card map[string]interface{}
+	var err error
+	switch formatter.DetectMessageType(text) {
+	case formatter.JSONBlock:
+		card, err = formatter.AdaptiveCard(text)
+	case formatter.StructuredData:
+		card, err = formatter.AdaptiveCard(formatter.FormatStructuredData(text))
+	}
+	if card != nil && err == nil {
+		return map[string]interface{}{
+			"type":        "message",
+			"attachments": []map[string]interface{}{{"contentType": formatter.AdaptiveCardContentType, "content": card}},
+		}
+	}
+	return map[string]interface{}{
+		"type":       "message",
+		"text":       formatter.MarkdownLinks(text),
+		"textFormat": "markdown",
+	}
+}
+
+// post sends an activity to a conversation: to the thread of a channel post, or
+// to the chat
+func (t *TeamsClient) post(channelID, threadTS string, activity map[string]interface{}) error {
+	t.mu.Lock()
+	ref, ok := t.conversations[channelID]
+	t.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("no teams conversation known for %s", channelID)
+	}
+	conversationID := channelID
+	if ref.ConversationType == "channel" && threadTS != "" {
+		conversationID = channelID + ";messageid=" + threadTS
+	}
+	return t.api(http.MethodPost, ref.ServiceURL, "/v3/conversations/"+url.PathEscape(conversationID)+"/activities", activity, nil)
+}
+
+// api calls the Bot Connector service, decoding the response into out when it
+// is not nil. Rate limits are returned as errors the outbound queue retries
+// after the requested delay.
+func (t *TeamsClient) api(method, serviceURL, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequest(method, strings.TrimSuffix(serviceURL, "/")+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := t.connector.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		retryAfter, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+		return &slack.RateLimitedError{RetryAfter: time.Duration(retryAfter) * time.Second}
+	}
+	if resp.StatusCode >= 300 {
+		return &teamsAPIError{Status: resp.StatusCode, Body: strings.TrimSpace(string(data))}
+	}
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("invalid teams connector response: %w", err)
+		}
+	}
+	return nil
+}
+
+// teamsClaims are the claims of a Bot Framework token the client checks
+type teamsClaims struct {
+	Issuer     string          `json:"iss"`
+	Audience   json.RawMessage `json:"aud"`
+	Expires    int64           `json:"exp"`
+	NotBefore  int64           `json:"nbf"`
+	ServiceURL string          `json:"serviceurl"`
+}
+
+// teamsTokenVerifier checks the tokens the Bot Framework sends with activities:
+// RS256 signatures by the keys published in its OpenID metadata, the issuer, the
+// app ID as audience and the token lifetime
+type teamsTokenVerifier struct {
+	metadataURL string
+	appID       string
+	httpClient  *http.Client
+	now         func() time.Time
+
+	mu         sync.Mutex
+	keys       map[string]*rsa.PublicKey // By key ID
+	fetched    time.Time                 // When the keys were last fetched
+	attempted  time.Time                 // When the keys were last requested, successfully or not
+	refreshing chan struct{}             // Closed when the fetch in progress ends; nil when none is
+}
+
+// newTeamsTokenVerifier creates a verifier that fetches keys from metadataURL
+func newTeamsTokenVerifier(metadataURL, appID string, httpClient *http.Client) *teamsTokenVerifier {
+	return &teamsTokenVerifier{metadataURL: metadataURL, appID: appID, httpClient: httpClient, now: time.Now}
+}
+
+// verify checks the bearer token of an Authorization header and returns its claims
+func (v *teamsTokenVerifier) verify(authorization string) (*teamsClaims, error) {
+	token, ok := strings.CutPrefix(authorization, "Bearer ")
+	if !ok {
+		return nil, errors.New("missing bearer token")
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeTokenPart(parts[0], &header); err != nil {
+		return nil, err
+	}
+	if header.Alg != "RS256" {
+		return nil, fmt.Errorf("unsupported token algorithm %q", header.Alg)
+	}
+	key, err := v.key(header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token signature: %w", err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return nil, errors.New("invalid token signature")
+	}
+
+	var claims teamsClaims
+	if err := decodeTokenPart(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	if claims.Issuer != teamsTokenIssuer {
+		return nil, fmt.Errorf("unexpected token issuer %q", claims.Issuer)
+	}
+	if !audienceIncludes(claims.Audience, v.appID) {
+		return nil, errors.New("token is not for this app")
+	}
+	now := v.now()
+	if claims.Expires == 0 || now.After(time.Unix(claims.Expires, 0).Add(teamsClockSkew)) {
+		return nil, errors.New("token expired")
+	}
+	if claims.NotBefore != 0 && now.Add(teamsClockSkew).Before(time.Unix(claims.NotBefore, 0)) {
+		return nil, errors.New("token not valid yet")
+	}
+	return &claims, nil
+}
+
+// decodeTokenPart decodes a base64url JSON part of a token
+func decodeTokenPart(part string, out interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return fmt.Errorf("malformed token: %w", err)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("malformed token: %w", err)
+	}
+	return nil
+}
+
+// audienceIncludes reports whether an aud claim, a string or a list, names appID
+func audienceIncludes(audience json.RawMessage, appID string) bool {
+	var single string
+	if json.Unmarshal(audience, &single) == nil {
+		return single == appID
+	}
+	var list []string
+	if json.Unmarshal(audience, &list) == nil {
+		for _, aud := range list {
+			if aud == appID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// key returns a signing key, fetching the keys again when they are stale or
+// the key is unknown, as happens after a rotation. The keys are fetched at most
+// once per teamsKeysRetryInterval, outside the lock, and requests that need them
+// meanwhile wait for that fetch instead of starting their own.
+func (v *teamsTokenVerifier) key(kid string) (*rsa.PublicKey, error) {
+	v.mu.Lock()
+	for {
+		key, known := v.keys[kid]
+		if known && v.now().Sub(v.fetched) < teamsKeysTTL {
+			v.mu.Unlock()
+			return key, nil
+		}
+		if v.refreshing == nil {
+			break
+		}
+		refreshing := v.refreshing
+		v.mu.Unlock()
+		<-refreshing
+		v.mu.Lock()
+	}
+	if v.now().Sub(v.attempted) < teamsKeysRetryInterval {
+		key, known := v.keys[kid]
+		v.mu.Unlock()
+		if !known {
+			return nil, fmt.Errorf("unknown token signing key %q", kid)
+		}
+		return key, nil // Stale, fetched again after the interval
+	}
+	refreshing := make(chan struct{})
+	v.refreshing, v.attempted = refreshing, v.now()
+	v.mu.Unlock()
+
+	keys, err := v.fetchKeys()
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.refreshing = nil
+	close(refreshing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Bot Framework signing keys: %w", err)
+	}
+	v.keys, v.fetched = keys, v.now()
+	key, ok := keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown token signing key %q", kid)
+	}
+	return key, nil
+}
+
+// fetchKeys reads the JSON Web Key Set named by the OpenID metadata
+func (v *teamsTokenVerifier) fetchKeys() (map[string]*rsa.PublicKey, error) {
+	var metadata struct {
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := v.getJSON(v.metadataURL, &metadata); err != nil {
+		return nil, err
+	}
+	var keySet struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := v.getJSON(metadata.JWKSURI, &keySet); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(keySet.Keys))
+	for _, jwk := range keySet.Keys {
+		if jwk.Kty != "RSA" {
+			continue
+		}
+		n, errN := base64.RawURLEncoding.DecodeString(jwk.N)
+		e, errE := base64.RawURLEncoding.DecodeString(jwk.E)
+		if errN != nil || errE != nil {
+			continue
+		}
+		keys[jwk.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	return keys, nil
+}
+
+// getJSON fetches a JSON document
+func (v *teamsTokenVerifier) getJSON(url string, out interface{}) error {
+	resp, err := v.httpClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: status %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(out)
+}
@@ -0,0 +1,265 @@
+package slackbot
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/slack/formatter"
+)
+
+// newTestBotFramework serves OpenID metadata, signing keys and OAuth tokens,
+// and returns a function that signs Bot Framework tokens
+func newTestBotFramework(t *testing.T) (*httptest.Server, func(claims map[string]interface{}) string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/openid":
+			_ = json.NewEncoder(w).Encode(map[string]string{"jwks_uri": server.URL + "/keys"})
+		case "/keys":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+				"kid": "k1",
+				"kty": "RSA",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}}})
+		case "/token":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"access_token": "connector-token", "token_type": "Bearer", "expires_in": 3600}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	sign := func(claims map[string]interface{}) string {
+		header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "k1", "typ": "JWT"})
+		payload, _ := json.Marshal(claims)
+		signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+		digest := sha256.Sum256([]byte(signed))
+		signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+		return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+	}
+	return server, sign
+}
+
+func newTestTeamsClient(t *testing.T, botFramework *httptest.Server) *TeamsClient {
+	client, err := NewTeamsClient(config.TeamsConfig{
+		AppID:             "app-id",
+		AppPassword:       "secret",
+		MessagesPath:      "/api/messages",
+		OpenIDMetadataURL: botFramework.URL + "/openid",
+		TokenURL:          botFramework.URL + "/token",
+	}, logging.New("test", logging.LevelError), "Thinking...", config.SlackOutboundConfig{MaxAttempts: 1})
+	require.NoError(t, err)
+	return client
+}
+
+func teamsActivityRequest(token, serviceURL string) *http.Request {
+	activity := `{
+		"type": "message", "id": "1700000000002", "serviceUrl": "` + serviceURL + `",
+		"from": {"id": "29:user", "name": "Ada Lovelace"},
+		"recipient": {"id": "28:bot", "name": "Helper"},
+		"text": "<at>Helper</at> deploy status?",
+		"conversation": {"id": "19:abc@thread.tacv2;messageid=1700000000001", "conversationType": "channel"},
+		"entities": [{"type": "mention", "mentioned": {"id": "28:bot", "name": "Helper"}}],
+		"channelData": {"channel": {"id": "19:abc@thread.tacv2"}}
+	}`
+	req := httptest.NewRequest(http.MethodPost, "/api/messages", strings.NewReader(activity))
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
+
+func TestTeamsActivityAuthentication(t *testing.T) {
+	botFramework, sign := newTestBotFramework(t)
+	client := newTestTeamsClient(t, botFramework)
+	serviceURL := "https://smba.example.com/amer/"
+	claims := func(audience string) map[string]interface{} {
+		return map[string]interface{}{
+			"iss":        teamsTokenIssuer,
+			"aud":        audience,
+			"exp":        time.Now().Add(time.Hour).Unix(),
+			"serviceurl": serviceURL,
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	client.handleActivityRequest(rec, teamsActivityRequest(sign(claims("another-app")), serviceURL))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = httptest.NewRecorder()
+	client.handleActivityRequest(rec, teamsActivityRequest(sign(claims("app-id")), "https://attacker.example.com/"))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	expired := claims("app-id")
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+	rec = httptest.NewRecorder()
+	client.handleActivityRequest(rec, teamsActivityRequest(sign(expired), serviceURL))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Empty(t, client.events)
+
+	rec = httptest.NewRecorder()
+	client.handleActivityRequest(rec, teamsActivityRequest(sign(claims("app-id")), serviceURL))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	event := <-client.GetEventChannel()
+	mention, ok := event.Data.(slackevents.EventsAPIEvent).InnerEvent.Data.(*slackevents.AppMentionEvent)
+	require.True(t, ok)
+	assert.Equal(t, "19:abc@thread.tacv2", mention.Channel)
+	assert.Equal(t, "1700000000001", mention.ThreadTimeStamp)
+	assert.Equal(t, " deploy status?", client.RemoveBotMention(mention.Text))
+	assert.False(t, client.IsValidUser("28:bot"))
+
+	profile, err := client.GetUserInfo("29:user")
+	require.NoError(t, err)
+	assert.Equal(t, "Ada Lovelace", profile.realName)
+	channel, err := client.GetConversationInfo(&slack.GetConversationInfoInput{ChannelID: "19:abc@thread.tacv2"})
+	require.NoError(t, err)
+	assert.False(t, channel.IsIM || channel.IsMpIM)
+}
+
+// countingTransport counts the requests sent through it
+type countingTransport struct {
+	mu       sync.Mutex
+	requests int
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.mu.Lock()
+	c.requests++
+	c.mu.Unlock()
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestTeamsUnknownKeyIDsFetchKeysOncePerInterval(t *testing.T) {
+	botFramework, sign := newTestBotFramework(t)
+	transport := &countingTransport{}
+	verifier := newTeamsTokenVerifier(botFramework.URL+"/openid", "app-id", &http.Client{Transport: transport})
+	now := time.Now()
+	verifier.now = func() time.Time { return now }
+	valid := "Bearer " + sign(map[string]interface{}{"iss": teamsTokenIssuer, "aud": "app-id", "exp": now.Add(time.Hour).Unix()})
+	forged := func(kid string) string {
+		header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": kid})
+		return "Bearer " + base64.RawURLEncoding.EncodeToString(header) + ".e30.c2ln"
+	}
+
+	_, err := verifier.verify(valid)
+	require.NoError(t, err)
+	assert.Equal(t, 2, transport.requests, "the metadata and the key set")
+
+	// Unauthenticated requests with random key IDs do not fetch the keys again
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := verifier.verify(forged(fmt.Sprintf("random-%d", i)))
+			assert.ErrorContains(t, err, "unknown token signing key")
+		}(i)
+	}
+	wg.Wait()
+	_, err = verifier.verify(valid)
+	require.NoError(t, err)
+	assert.Equal(t, 2, transport.requests)
+
+	// After the interval, one miss fetches them again, as after a key rotation
+	now = now.Add(teamsKeysRetryInterval)
+	for i := 0; i < 5; i++ {
+		_, err = verifier.verify(forged("rotated"))
+		assert.ErrorContains(t, err, "unknown token signing key")
+	}
+	assert.Equal(t, 4, transport.requests)
+}
+
+func TestTeamsActivityIsAcknowledgedWhenTheQueueIsFull(t *testing.T) {
+	botFramework, sign := newTestBotFramework(t)
+	client := newTestTeamsClient(t, botFramework)
+	serviceURL := "https://smba.example.com/amer/"
+	for len(client.events) < cap(client.events) {
+		client.events <- socketmode.Event{Type: socketmode.EventTypeEventsAPI}
+	}
+	token := sign(map[string]interface{}{
+		"iss":        teamsTokenIssuer,
+		"aud":        "app-id",
+		"exp":        time.Now().Add(time.Hour).Unix(),
+		"serviceurl": serviceURL,
+	})
+
+	rec := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		client.handleActivityRequest(rec, teamsActivityRequest(token, serviceURL))
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the activity was not acknowledged while the queue was full")
+	}
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Len(t, client.events, cap(client.events), "the activity is dropped")
+}
+
+func TestTeamsReplyPostsToThread(t *testing.T) {
+	botFramework, _ := newTestBotFramework(t)
+	var mu sync.Mutex
+	var paths []string
+	var activities []map[string]interface{}
+	connector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer connector-token", r.Header.Get("Authorization"))
+		var activity map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&activity)
+		mu.Lock()
+		paths = append(paths, r.URL.EscapedPath())
+		activities = append(activities, activity)
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"id": "1"}`))
+	}))
+	defer connector.Close()
+
+	client := newTestTeamsClient(t, botFramework)
+	client.conversations["19:abc@thread.tacv2"] = teamsConversationRef{ServiceURL: connector.URL, ConversationType: "channel"}
+	client.SendMessage("19:abc@thread.tacv2", "1700000000001", "Thinking...")
+	client.SendMessage("19:abc@thread.tacv2", "1700000000001", "See <https://example.com|the runbook>")
+	client.SendMessage("19:abc@thread.tacv2", "1700000000001", formatter.CreateBlockMessage("", formatter.BlockOptions{
+		HeaderText: "Deploy",
+		Fields:     []formatter.Field{{Title: "Status", Value: "green"}},
+	}))
+	require.NoError(t, client.Close())
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, paths, 3)
+	for _, path := range paths {
+		assert.Equal(t, "/v3/conversations/"+url.PathEscape("19:abc@thread.tacv2;messageid=1700000000001")+"/activities", path)
+	}
+	assert.Equal(t, "typing", activities[0]["type"])
+	assert.Equal(t, "See [the runbook](https://example.com)", activities[1]["text"])
+	attachments := activities[2]["attachments"].([]interface{})
+	require.Len(t, attachments, 1)
+	assert.Equal(t, formatter.AdaptiveCardContentType, attachments[0].(map[string]interface{})["contentType"])
+}
@@ -0,0 +1,178 @@
+package slackbot
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/tuannvm/slack-mcp-client/internal/monitoring"
+)
+
+const (
+	threadCacheMaxThreads  = 500              // Least recently used threads are dropped beyond this
+	threadCacheFullRefresh = 10 * time.Minute // Refetch whole threads this often to pick up edits and deletions
+)
+
+// repliesFetcher fetches one page of a thread, like slack.Client.GetConversationReplies
+type repliesFetcher func(params *slack.GetConversationRepliesParameters) ([]slack.Message, bool, string, error)
+
+// threadEntry is the cached state of one thread
+type threadEntry struct {
+	replies     []slack.Message // Oldest first, parent included
+	newestTS    string          // Timestamp of the newest cached reply
+	fetchedAt   time.Time       // Last full fetch
+	lastUsed    time.Time
+	channelID   string
+	threadTS    string
+	needsRefill bool // Set when a cached message changed; the next fetch is a full one
+}
+
+// threadCache keeps the replies fetched per thread and only asks Slack for the
+// messages posted after the newest one it has. Edits and deletions made through
+// this client are applied to the cache; changes made by others are picked up by
+// the periodic full refresh.
+type threadCache struct {
+	now func() time.Time
+
+	mu      sync.Mutex
+	threads map[string]*threadEntry // By channel and thread timestamp
+}
+
+func newThreadCache() *threadCache {
+	return &threadCache{now: time.Now, threads: make(map[string]*threadEntry)}
+}
+
+func threadKey(channelID, threadTS string) string {
+	return channelID + "/" + threadTS
+}
+
+// replies returns every message of a thread, fetching only what is new since
+// the last call when the thread is cached
+func (c *threadCache) replies(channelID, threadTS string, fetch repliesFetcher) ([]slack.Message, error) {
+	key := threadKey(channelID, threadTS)
+	now := c.now()
+
+	c.mu.Lock()
+	entry := c.threads[key]
+	incremental := entry != nil && !entry.needsRefill && now.Sub(entry.fetchedAt) < threadCacheFullRefresh
+	oldest := ""
+	if incremental {
+		oldest = entry.newestTS
+	}
+	c.mu.Unlock()
+
+	params := &slack.GetConversationRepliesParameters{ChannelID: channelID, Timestamp: threadTS}
+	mode := "full"
+	if incremental {
+		mode = "incremental"
+		params.Oldest = oldest
+		params.Inclusive = false
+	}
+	var fetched []slack.Message
+	for {
+		page, hasMore, cursor, err := fetch(params)
+		if err != nil {
+			return nil, err
+		}
+		fetched = append(fetched, page...)
+		if !hasMore || cursor == "" {
+			break
+		}
+		params.Cursor = cursor
+	}
+	monitoring.SlackThreadFetches.WithLabelValues(mode).Inc()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	current := c.threads[key]
+	switch {
+	case !incremental:
+		current = &threadEntry{channelID: channelID, threadTS: threadTS, fetchedAt: now}
+		c.threads[key] = current
+	case current == nil:
+		// Evicted while fetching; the new messages still extend what was cached
+		current = entry
+		c.threads[key] = current
+	}
+	current.lastUsed = now
+	for _, msg := range fetched {
+		// Slack always returns the parent message, which is already cached
+		if current.newestTS != "" && !tsAfter(msg.Timestamp, current.newestTS) {
+			continue
+		}
+		current.replies = append(current.replies, msg)
+		current.newestTS = msg.Timestamp
+	}
+	c.evict()
+
+	replies := make([]slack.Message, len(current.replies))
+	copy(replies, current.replies)
+	return replies, nil
+}
+
+// removeMessage drops a deleted message from the cached thread holding it
+func (c *threadCache) removeMessage(channelID, ts string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, entry := range c.threads {
+		if entry.channelID != channelID {
+			continue
+		}
+		for i, msg := range entry.replies {
+			if msg.Timestamp == ts {
+				entry.replies = append(entry.replies[:i:i], entry.replies[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// invalidateMessage marks the thread holding an edited message for a full
+// refetch, since the cached copy is stale
+func (c *threadCache) invalidateMessage(channelID, ts string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, entry := range c.threads {
+		if entry.channelID != channelID {
+			continue
+		}
+		for _, msg := range entry.replies {
+			if msg.Timestamp == ts {
+				entry.needsRefill = true
+				return
+			}
+		}
+	}
+}
+
+// evict drops the least recently used threads beyond the cache size
+func (c *threadCache) evict() {
+	for len(c.threads) > threadCacheMaxThreads {
+		var oldestKey string
+		var oldest time.Time
+		for key, entry := range c.threads {
+			if oldestKey == "" || entry.lastUsed.Before(oldest) {
+				oldestKey, oldest = key, entry.lastUsed
+			}
+		}
+		delete(c.threads, oldestKey)
+	}
+}
+
+// tsAfter reports whether Slack timestamp a is later than b
+func tsAfter(a, b string) bool {
+	af, errA := strconv.ParseFloat(a, 64)
+	bf, errB := strconv.ParseFloat(b, 64)
+	if errA != nil || errB != nil {
+		return a > b
+	}
+	return af > bf
+}
+
+// slackTime converts a Slack timestamp to a time, to the second
+func slackTime(ts string) time.Time {
+	seconds, _ := strconv.ParseFloat(ts, 64)
+	return time.Unix(int64(seconds), 0)
+}
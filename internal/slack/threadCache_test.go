@@ -0,0 +1,105 @@
+package slackbot
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeThread serves a thread like conversations.replies and records the oldest
+// timestamp of each request
+type fakeThread struct {
+	messages []slack.Message
+	requests []string
+}
+
+func (f *fakeThread) fetch(params *slack.GetConversationRepliesParameters) ([]slack.Message, bool, string, error) {
+	f.requests = append(f.requests, params.Oldest)
+	page := []slack.Message{f.messages[0]} // The parent is always returned
+	for _, msg := range f.messages[1:] {
+		if params.Oldest == "" || tsAfter(msg.Timestamp, params.Oldest) {
+			page = append(page, msg)
+		}
+	}
+	return page, false, "", nil
+}
+
+func (f *fakeThread) post(ts, text string) {
+	msg := slack.Message{}
+	msg.Timestamp = ts
+	msg.Text = text
+	f.messages = append(f.messages, msg)
+}
+
+func messageTexts(messages []slack.Message) []string {
+	texts := make([]string, len(messages))
+	for i, msg := range messages {
+		texts[i] = msg.Text
+	}
+	return texts
+}
+
+func TestThreadCacheFetchesOnlyNewReplies(t *testing.T) {
+	cache := newThreadCache()
+	now := time.Unix(1700000000, 0)
+	cache.now = func() time.Time { return now }
+	thread := &fakeThread{}
+	thread.post("100.000001", "question")
+	thread.post("100.000002", "answer")
+
+	replies, err := cache.replies("C1", "100.000001", thread.fetch)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"question", "answer"}, messageTexts(replies))
+
+	thread.post("100.000010", "follow-up")
+	replies, err = cache.replies("C1", "100.000001", thread.fetch)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"question", "answer", "follow-up"}, messageTexts(replies))
+	assert.Equal(t, []string{"", "100.000002"}, thread.requests)
+
+	// Deleted messages leave the cache without a refetch
+	cache.removeMessage("C1", "100.000002")
+	replies, err = cache.replies("C1", "100.000001", thread.fetch)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"question", "follow-up"}, messageTexts(replies))
+	assert.Equal(t, "100.000010", thread.requests[2])
+
+	// Edited messages refetch the whole thread once
+	thread.messages[2].Text = "edited follow-up"
+	cache.invalidateMessage("C1", "100.000010")
+	replies, err = cache.replies("C1", "100.000001", thread.fetch)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"question", "answer", "edited follow-up"}, messageTexts(replies))
+	assert.Equal(t, "", thread.requests[3])
+
+	// Threads are refetched in full periodically
+	now = now.Add(threadCacheFullRefresh)
+	_, err = cache.replies("C1", "100.000001", thread.fetch)
+	require.NoError(t, err)
+	assert.Equal(t, "", thread.requests[4])
+}
+
+func TestThreadCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newThreadCache()
+	now := time.Unix(1700000000, 0)
+	cache.now = func() time.Time { now = now.Add(time.Second); return now }
+	thread := &fakeThread{}
+	thread.post("100.000001", "question")
+
+	for i := 0; i <= threadCacheMaxThreads; i++ {
+		_, err := cache.replies("C1", strconv.Itoa(i), thread.fetch)
+		require.NoError(t, err)
+	}
+	assert.Len(t, cache.threads, threadCacheMaxThreads)
+	assert.NotContains(t, cache.threads, threadKey("C1", "0"))
+}
+
+func TestTSAfter(t *testing.T) {
+	assert.True(t, tsAfter("1700000000.000010", "1700000000.000002"))
+	assert.True(t, tsAfter("1700000001.000000", "999999999.999999"))
+	assert.False(t, tsAfter("1700000000.000002", "1700000000.000002"))
+}
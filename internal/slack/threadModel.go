@@ -0,0 +1,85 @@
+package slackbot
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// modelCommand shows or switches the model of a thread
+	modelCommand = "model"
+	// modelAuto forgets the model of a thread
+	modelAuto = "auto"
+)
+
+// handleModelCommand runs "model", "model <provider> [model]" and "model auto" in a
+// thread, which show, switch and forget the model answering it, and reports
+// whether the text was such a command. threadTS is the thread the command was
+// sent in, empty outside a thread, and replyTS the thread to reply in.
+func (c *Client) handleModelCommand(text, channelID, threadTS, replyTS, userID string) bool {
+	if !c.cfg.LLM.ThreadAffinity.Enabled || c.llmMCPBridge == nil {
+		return false
+	}
+	fields := strings.Fields(strings.TrimSpace(text))
+	if len(fields) == 0 || len(fields) > 3 || !strings.EqualFold(fields[0], modelCommand) {
+		return false
+	}
+	var provider, model string
+	if len(fields) > 1 && !strings.EqualFold(fields[1], modelAuto) {
+		provider = fields[1]
+		if _, ok := c.cfg.LLM.Providers[provider]; !ok {
+			return false // A question about a model rather than a command
+		}
+		if len(fields) == 3 {
+			model = fields[2]
+		}
+	} else if len(fields) == 3 {
+		return false
+	}
+	if threadTS == "" {
+		c.sendMessage(channelID, replyTS, "Send `model` as a reply in the thread whose model you want to see or change.")
+		return true
+	}
+
+	switch {
+	case len(fields) == 1:
+		c.sendMessage(channelID, threadTS, c.describeThreadModel(channelID, threadTS))
+		return true
+	case provider == "":
+		if err := c.llmMCPBridge.SetThreadModel(channelID, threadTS, "", ""); err != nil {
+			c.logger.WarnKV("Failed to forget the model of the thread", "channel", channelID, "thread", threadTS, "error", err)
+		}
+		c.logger.InfoKV("Forgot the model of the thread", "channel", channelID, "thread", threadTS, "user", userID)
+		c.sendMessage(channelID, threadTS, "This thread is back on the configured model from its next turn.")
+		return true
+	}
+
+	if c.llmRegistry != nil {
+		if _, err := c.llmRegistry.GetProvider(provider); err != nil {
+			c.sendMessage(channelID, threadTS, fmt.Sprintf("`%s` is not available: %v", provider, err))
+			return true
+		}
+	}
+	if err := c.llmMCPBridge.SetThreadModel(channelID, threadTS, provider, model); err != nil {
+		c.logger.WarnKV("Failed to save the model of the thread", "channel", channelID, "thread", threadTS, "error", err)
+	}
+	c.logger.InfoKV("Switched the model of the thread", "channel", channelID, "thread", threadTS, "user", userID, "provider", provider, "model", model)
+	c.sendMessage(channelID, threadTS, "Switched the model of this thread. "+c.describeThreadModel(channelID, threadTS))
+	return true
+}
+
+// describeThreadModel says which model answers a thread
+func (c *Client) describeThreadModel(channelID, threadTS string) string {
+	current, ok := c.llmMCPBridge.ThreadModel(channelID, threadTS)
+	if !ok {
+		return "This thread has no model yet: its next turn is answered by the configured model. Send `model <provider> [model]` to choose one."
+	}
+	text := fmt.Sprintf("This thread is answered by `%s`", current.Provider)
+	if current.Model != "" {
+		text += fmt.Sprintf(" (`%s`)", current.Model)
+	}
+	if current.Override {
+		text += ", chosen with `model`"
+	}
+	return text + ". Send `model <provider> [model]` to switch, or `model auto` to go back to the configured model."
+}
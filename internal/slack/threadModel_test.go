@@ -0,0 +1,54 @@
+package slackbot
+
+import (
+	"log"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/affinity"
+	"github.com/tuannvm/slack-mcp-client/internal/handlers"
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+)
+
+func TestModelCommand(t *testing.T) {
+	client, _, output := newProgressTestClient()
+	client.llmMCPBridge = handlers.NewLLMMCPBridge(map[string]mcp.MCPClientInterface{}, log.New(os.Stderr, "", 0), nil, nil, client.cfg)
+	run := func(text, threadTS string) (string, bool) {
+		output.Reset()
+		handled := client.handleModelCommand(text, "C1", threadTS, "100.1", "U1")
+		return output.String(), handled
+	}
+	_, handled := run("model", "100.1")
+	assert.False(t, handled, "the command needs thread affinity")
+
+	client.cfg.LLM.ThreadAffinity.Enabled = true
+	store, err := affinity.NewStore("", 0)
+	require.NoError(t, err)
+	client.llmMCPBridge.SetThreadAffinity(store)
+
+	reply, handled := run("model", "100.1")
+	assert.True(t, handled)
+	assert.Contains(t, reply, "This thread has no model yet")
+
+	reply, _ = run("model anthropic claude-sonnet-4-5", "100.1")
+	assert.Contains(t, reply, "Switched the model of this thread. This thread is answered by `anthropic` (`claude-sonnet-4-5`), chosen with `model`.")
+	reply, _ = run("Model", "100.1")
+	assert.Contains(t, reply, "This thread is answered by `anthropic`")
+
+	reply, _ = run("model auto", "100.1")
+	assert.Contains(t, reply, "This thread is back on the configured model")
+	_, ok := client.llmMCPBridge.ThreadModel("C1", "100.1")
+	assert.False(t, ok)
+
+	// Questions about models are prompts, and the command only works in threads
+	_, handled = run("model the traffic growth", "100.1")
+	assert.False(t, handled)
+	_, handled = run("model auto please", "100.1")
+	assert.False(t, handled)
+	reply, handled = run("model openai", "")
+	assert.True(t, handled)
+	assert.Contains(t, reply, "Send `model` as a reply in the thread")
+}
@@ -0,0 +1,185 @@
+package slackbot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tuannvm/slack-mcp-client/internal/monitoring"
+)
+
+const (
+	// maxDiffCells bounds the work of a line diff; longer results are compared as
+	// sets of lines instead
+	maxDiffCells = 1 << 20
+	// maxPromptDiffLines bounds the changed lines given to the LLM
+	maxPromptDiffLines = 200
+)
+
+// toolDiff is how the result of a repeated tool call changed since the previous
+// call with the same arguments in the thread
+type toolDiff struct {
+	Tool  string
+	Since time.Duration // Time since the previous result
+	Lines []string      // Removed lines prefixed with "- ", added lines with "+ "
+}
+
+// diffToolResult compares a tool result with the previous result of the tool with
+// the same arguments in the thread. It returns nil when diffing is off, the call is
+// not repeated or the previous full result is no longer kept. It must be called
+// before the new result is added to the history.
+func (c *Client) diffToolResult(channelID, threadTS, toolName string, args map[string]interface{}, result string) *toolDiff {
+	if !c.cfg.Slack.ToolHistory.DiffRepeated {
+		return nil
+	}
+	key := historyKey(channelID, threadTS)
+	argsHash := hashToolArgs(args)
+	history := c.threadHistory(channelID, threadTS)
+	for i := len(history) - 1; i >= 0; i-- {
+		entry := history[i].Tool
+		if entry == nil || entry.Name != toolName || entry.ArgsHash != argsHash {
+			continue
+		}
+		previous := entry.Result
+		if entry.Truncated() {
+			full, ok := c.toolResults.get(key, entry.ResultID)
+			if !ok {
+				return nil
+			}
+			previous = full
+		}
+		diff := &toolDiff{Tool: toolName, Since: time.Since(history[i].Timestamp), Lines: diffLines(previous, result)}
+		if len(diff.Lines) == 0 {
+			monitoring.ToolResultDiffs.WithLabelValues("unchanged").Inc()
+		} else {
+			monitoring.ToolResultDiffs.WithLabelValues("changed").Inc()
+		}
+		c.logger.DebugKV("Diffed repeated tool call", "tool", toolName, "args", argsHash, "changed_lines", len(diff.Lines))
+		return diff
+	}
+	return nil
+}
+
+// prompt tells the LLM how the result changed, so the answer highlights it
+func (d *toolDiff) prompt() string {
+	if len(d.Lines) == 0 {
+		return fmt.Sprintf("\n\n`%s` was called with the same arguments %s ago in this conversation, and its result has not changed since then. Say so at the start of your answer.",
+			d.Tool, formatSince(d.Since))
+	}
+	lines, more := d.Lines, ""
+	if len(lines) > maxPromptDiffLines {
+		lines, more = lines[:maxPromptDiffLines], fmt.Sprintf("\n… %d more changed lines", len(d.Lines)-maxPromptDiffLines)
+	}
+	return fmt.Sprintf("\n\n`%s` was called with the same arguments %s ago in this conversation. This is how its result changed since then, with removed lines starting with - and added lines with +:\n```\n%s%s\n```\n\nStart your answer with a short summary of what changed since the previous run.",
+		d.Tool, formatSince(d.Since), strings.Join(lines, "\n"), more)
+}
+
+// attachment is the raw diff posted under the answer, with at most maxLines
+// changed lines
+func (d *toolDiff) attachment(maxLines int) string {
+	if len(d.Lines) == 0 {
+		return fmt.Sprintf("\n\n_No changes in `%s` since the previous run %s ago._", d.Tool, formatSince(d.Since))
+	}
+	lines, more := d.Lines, ""
+	if maxLines > 0 && len(lines) > maxLines {
+		lines, more = lines[:maxLines], fmt.Sprintf("\n… %d more changed lines", len(d.Lines)-maxLines)
+	}
+	return fmt.Sprintf("\n\n*Changes in `%s` since the previous run %s ago:*\n```\n%s%s\n```",
+		d.Tool, formatSince(d.Since), strings.Join(lines, "\n"), more)
+}
+
+// formatSince shows a time since in seconds under a minute and in minutes above,
+// such as "45s" or "1h5m"
+func formatSince(d time.Duration) string {
+	if d < time.Minute {
+		return d.Round(time.Second).String()
+	}
+	text := strings.TrimSuffix(d.Round(time.Minute).String(), "0s")
+	if strings.HasSuffix(text, "h0m") {
+		text = strings.TrimSuffix(text, "0m")
+	}
+	return text
+}
+
+// diffLines returns the lines removed from old, prefixed with "- ", and the lines
+// added in new, prefixed with "+ ", in order. It returns nothing when the texts
+// have the same lines.
+func diffLines(old, new string) []string {
+	a, b := splitLines(old), splitLines(new)
+
+	// Lines both texts start and end with are unchanged
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		a, b = a[1:], b[1:]
+	}
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1] == b[len(b)-1] {
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+	if len(a)*len(b) > maxDiffCells {
+		return diffLineSets(a, b)
+	}
+
+	// Longest common subsequence of the remaining lines, from the end
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var lines []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i, j = i+1, j+1
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, "- "+a[i])
+			i++
+		default:
+			lines = append(lines, "+ "+b[j])
+			j++
+		}
+	}
+	return lines
+}
+
+// diffLineSets compares texts too long for a line diff as multisets of lines:
+// removed lines first, then added lines
+func diffLineSets(a, b []string) []string {
+	missing := func(lines, from []string, prefix string) []string {
+		counts := make(map[string]int, len(from))
+		for _, line := range from {
+			counts[line]++
+		}
+		var changed []string
+		for _, line := range lines {
+			if counts[line] > 0 {
+				counts[line]--
+			} else {
+				changed = append(changed, prefix+line)
+			}
+		}
+		return changed
+	}
+	return append(missing(a, b, "- "), missing(b, a, "+ ")...)
+}
+
+// splitLines splits a text into lines, without trailing whitespace
+func splitLines(text string) []string {
+	text = strings.TrimRight(text, "\n")
+	if text == "" {
+		return nil
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+	return lines
+}
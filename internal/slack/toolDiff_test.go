@@ -0,0 +1,84 @@
+package slackbot
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/jobs"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     []string
+	}{
+		{"unchanged", "pod-a CrashLoopBackOff\npod-b Running\n", "pod-a CrashLoopBackOff\npod-b Running", nil},
+		{"changed line", "pod-a CrashLoopBackOff\npod-b Running\npod-c Running", "pod-a Running\npod-b Running\npod-c Running",
+			[]string{"- pod-a CrashLoopBackOff", "+ pod-a Running"}},
+		{"added and removed lines", "pod-a\npod-b\npod-c", "pod-b\npod-c\npod-d",
+			[]string{"- pod-a", "+ pod-d"}},
+		{"from nothing", "", "pod-a", []string{"+ pod-a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, diffLines(tt.old, tt.new))
+		})
+	}
+
+	// Results too long for a line diff are compared as sets of lines
+	assert.Equal(t, []string{"- b", "+ d", "+ a"}, diffLineSets([]string{"a", "b", "c"}, []string{"c", "d", "a", "a"}))
+}
+
+func TestRepeatedToolCallsAreDiffed(t *testing.T) {
+	client, _, _ := newProgressTestClient()
+	client.historyLimit = 10
+	client.messageHistory = map[string][]Message{}
+	client.toolResults = newToolResults()
+	client.cfg.Slack.ToolHistory.MaxResultChars = 20
+	args := map[string]interface{}{"namespace": "prod"}
+	first := "pod-a CrashLoopBackOff\npod-b CrashLoopBackOff\npod-c Running"
+	second := "pod-a Running\npod-b CrashLoopBackOff\npod-c Running"
+
+	client.addToolResult("C1", "100.1", "list_pods", args, first)
+	assert.Nil(t, client.diffToolResult("C1", "100.1", "list_pods", args, second), "diffing is off by default")
+
+	client.cfg.Slack.ToolHistory.DiffRepeated = true
+	assert.Nil(t, client.diffToolResult("C1", "100.1", "list_pods", map[string]interface{}{"namespace": "dev"}, second), "other arguments are another question")
+	assert.Nil(t, client.diffToolResult("C1", "200.1", "list_pods", args, second), "other threads are not compared")
+
+	// The full previous result is compared, even though its entry was truncated
+	diff := client.diffToolResult("C1", "100.1", "list_pods", args, second)
+	require.NotNil(t, diff)
+	assert.Equal(t, []string{"- pod-a CrashLoopBackOff", "+ pod-a Running"}, diff.Lines)
+	assert.Contains(t, diff.prompt(), "`list_pods` was called with the same arguments")
+	assert.Contains(t, diff.prompt(), "Start your answer with a short summary of what changed")
+	diff.Since = 5 * time.Minute
+	assert.Equal(t, "\n\n*Changes in `list_pods` since the previous run 5m ago:*\n```\n- pod-a CrashLoopBackOff\n+ pod-a Running\n```", diff.attachment(40))
+	assert.Contains(t, diff.attachment(1), "- pod-a CrashLoopBackOff\n… 1 more changed lines\n```")
+
+	// The newest result is the one compared next
+	client.addToolResult("C1", "100.1", "list_pods", args, second)
+	diff = client.diffToolResult("C1", "100.1", "list_pods", args, second)
+	require.NotNil(t, diff)
+	assert.Empty(t, diff.Lines)
+	assert.Contains(t, diff.prompt(), "its result has not changed")
+	assert.Contains(t, diff.attachment(40), "_No changes in `list_pods` since the previous run")
+}
+
+func TestRepeatedScheduledJobShowsChanges(t *testing.T) {
+	client, _, output := newJobsTestClient(t, backupTool{})
+	client.cfg.Slack.ToolHistory.DiffRepeated = true
+	args := map[string]interface{}{"cluster": "prod"}
+	client.addToolResult("C1", "100.1", "backup_check", args, "backups of prod failing")
+
+	client.runJob(context.Background(), jobs.Job{ID: "3", Tool: "backup_check", Args: args, ChannelID: "C1", ThreadTS: "100.1", UserID: "U1"})
+	posted := output.String()
+	assert.Contains(t, posted, "*Changes in `backup_check` since the previous run")
+	assert.True(t, strings.Contains(posted, "- backups of prod failing\n+ backups of prod ok, checked for U1 in C1:100.1"), posted)
+}
@@ -0,0 +1,189 @@
+package slackbot
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/tuannvm/slack-mcp-client/internal/hooks"
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+	"github.com/tuannvm/slack-mcp-client/pkg/middleware"
+)
+
+const (
+	// toolHistoryServerName identifies the full tool results among the bridge's clients
+	toolHistoryServerName = "history"
+	// toolResultToolName is the tool the model fetches a full tool result with
+	toolResultToolName = "get_tool_result"
+)
+
+// ToolEntry is a tool result in the conversation history
+type ToolEntry struct {
+	Name     string // Tool that was called
+	Args     string // Arguments the model called it with, as JSON
+	ArgsHash string // Short hash of the arguments
+	Result   string // The result, truncated to slack.toolHistory.maxResultChars
+	ResultID string // ID of the full result when the result was truncated
+	Size     int    // Characters in the full result
+}
+
+// Truncated reports whether the history holds only the start of the result
+func (e *ToolEntry) Truncated() bool {
+	return e.ResultID != ""
+}
+
+// content is the result as the model is given it, telling it how to fetch the
+// rest of a truncated result
+func (e *ToolEntry) content() string {
+	if !e.Truncated() {
+		return e.Result
+	}
+	return e.Result + fmt.Sprintf("\n[truncated from %d characters; call %s with {\"id\": %q} for the full result]", e.Size, toolResultToolName, e.ResultID)
+}
+
+// toolResultMessages returns the tool results of a part of the thread history as
+// "tool" messages, which providers send as the call and its result instead of
+// text in the conversation context
+func toolResultMessages(history []Message) []middleware.Message {
+	var messages []middleware.Message
+	for i, msg := range history {
+		if msg.Role != "tool" || msg.Tool == nil {
+			continue
+		}
+		messages = append(messages, middleware.Message{
+			Role:       "tool",
+			Content:    msg.Tool.content(),
+			ToolName:   msg.Tool.Name,
+			ToolArgs:   msg.Tool.Args,
+			ToolCallID: fmt.Sprintf("history_%d", i),
+		})
+	}
+	return messages
+}
+
+// encodeToolArgs returns tool arguments as JSON, with sorted keys
+func encodeToolArgs(args map[string]interface{}) string {
+	if len(args) == 0 {
+		args = map[string]interface{}{}
+	}
+	encoded, err := json.Marshal(args) // Map keys are sorted
+	if err != nil {
+		return fmt.Sprintf("%v", args)
+	}
+	return string(encoded)
+}
+
+// hashToolArgs returns a short, stable hash of tool arguments, so repeated calls
+// with the same arguments can be recognized
+func hashToolArgs(args map[string]interface{}) string {
+	sum := sha256.Sum256([]byte(encodeToolArgs(args)))
+	return hex.EncodeToString(sum[:4])
+}
+
+// toolResults keeps the full tool results whose history entries were truncated,
+// by thread, until the entries leave the history
+type toolResults struct {
+	mu      sync.Mutex
+	results map[string]map[string]string // By history key, then result ID
+	next    map[string]int               // Next result ID per history key
+}
+
+func newToolResults() *toolResults {
+	return &toolResults{
+		results: make(map[string]map[string]string),
+		next:    make(map[string]int),
+	}
+}
+
+// add stores a full result of a thread and returns its ID
+func (r *toolResults) add(key, result string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.next[key]++
+	id := fmt.Sprintf("r%d", r.next[key])
+	if r.results[key] == nil {
+		r.results[key] = make(map[string]string)
+	}
+	r.results[key][id] = result
+	return id
+}
+
+// get returns a full result of a thread
+func (r *toolResults) get(key, id string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result, ok := r.results[key][id]
+	return result, ok
+}
+
+// remove drops a full result of a thread
+func (r *toolResults) remove(key, id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.results[key], id)
+	if len(r.results[key]) == 0 {
+		delete(r.results, key)
+	}
+}
+
+// toolResultInfo describes the tool that fetches full tool results
+func toolResultInfo() mcp.ToolInfo {
+	return mcp.ToolInfo{
+		ToolName:        toolResultToolName,
+		ToolDescription: "Fetch the full result of an earlier tool call in this conversation, when the conversation context marks it as truncated. Pass the id given there.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"id": map[string]interface{}{
+					"type":        "string",
+					"description": "The id of the truncated result, such as r1",
+				},
+			},
+			"required": []string{"id"},
+		},
+		ServerName: toolHistoryServerName,
+	}
+}
+
+// CallTool implements the bridge's client interface. Results are looked up in the
+// conversation of the request, never in one named by the arguments, so a thread
+// cannot read another thread's results.
+func (r *toolResults) CallTool(ctx context.Context, toolName string, args map[string]interface{}) (string, error) {
+	if toolName != toolResultToolName {
+		return "", fmt.Errorf("unknown history tool: %s. Available tools: %s", toolName, toolResultToolName)
+	}
+	channelID, threadTS, ok := hooks.ConversationFromContext(ctx)
+	if !ok || channelID == "" {
+		return "", fmt.Errorf("tool results can only be fetched from a conversation")
+	}
+	id, _ := args["id"].(string)
+	if id == "" {
+		return "", fmt.Errorf("the id of the result is required")
+	}
+	result, ok := r.get(historyKey(channelID, threadTS), id)
+	if !ok {
+		return fmt.Sprintf("No result with id %q in this conversation. It may have left the history; call the original tool again.", id), nil
+	}
+	return result, nil
+}
+
+// addToolResult adds a tool result to the thread history, truncating a long one
+// and keeping its full text for get_tool_result
+func (c *Client) addToolResult(channelID, threadTS, toolName string, args map[string]interface{}, result string) {
+	entry := &ToolEntry{
+		Name:     toolName,
+		Args:     encodeToolArgs(args),
+		ArgsHash: hashToolArgs(args),
+		Result:   result,
+		Size:     utf8.RuneCountInString(result),
+	}
+	if limit := c.cfg.Slack.ToolHistory.MaxResultChars; limit > 0 && entry.Size > limit && c.toolResults != nil {
+		entry.Result = string([]rune(result)[:limit])
+		entry.ResultID = c.toolResults.add(historyKey(channelID, threadTS), result)
+	}
+	c.appendHistory(channelID, threadTS, Message{Role: "tool", Content: entry.Result, Tool: entry})
+}
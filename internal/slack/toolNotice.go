@@ -0,0 +1,117 @@
+package slackbot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+)
+
+const (
+	// cancelRequestAction is the action ID of the button that stops a request
+	cancelRequestAction = "cancel_request"
+
+	toolNoticeText     = "⏳ Still working on `%s`..."
+	toolNoticeDeadline = " I will stop it if it takes longer than %s."
+	toolNoticeReaction = " React with :octagonal_sign: to your message to cancel."
+)
+
+// ToolNoticeFrontend is implemented by frontends that can post Block Kit messages
+// and delete them. A slow tool call is announced with a message whose button
+// cancels the request; other frontends point at the 🛑 reaction instead.
+type ToolNoticeFrontend interface {
+	// PostBlocks posts a Block Kit message with a plain-text fallback and returns its timestamp
+	PostBlocks(channelID, threadTS, text string, blocks ...slack.Block) (string, error)
+	DeleteMessage(channelID, messageTimestamp string) (string, string, error)
+}
+
+// PostBlocks posts the message directly rather than through the outbound queue,
+// since its timestamp is needed to delete it
+func (slackClient *SlackClient) PostBlocks(channelID, threadTS, text string, blocks ...slack.Block) (string, error) {
+	options := []slack.MsgOption{slack.MsgOptionText(text, false), slack.MsgOptionBlocks(blocks...)}
+	if threadTS != "" {
+		options = append(options, slack.MsgOptionTS(threadTS))
+	}
+	_, ts, err := slackClient.PostMessage(channelID, options...)
+	return ts, err
+}
+
+// withToolNotice makes the request's tool calls that run past the notice
+// threshold post a "still working" message with a button to cancel the request.
+// The message is deleted when the call ends.
+func (c *Client) withToolNotice(ctx context.Context, channelID, threadTS, promptTS string) context.Context {
+	after, err := time.ParseDuration(c.cfg.Timeouts.ToolNoticeAfter)
+	if err != nil {
+		return ctx // Validated when the config is loaded
+	}
+	return mcp.WithSlowToolNotice(ctx, after, func(toolName string, timeout time.Duration) func() {
+		if requestCancelled(ctx) {
+			return nil
+		}
+		text := fmt.Sprintf(toolNoticeText, toolName)
+		if timeout > 0 {
+			text += fmt.Sprintf(toolNoticeDeadline, timeout)
+		}
+		frontend, ok := c.userFrontend.(ToolNoticeFrontend)
+		if !ok {
+			c.sendMessage(channelID, threadTS, text+toolNoticeReaction)
+			return nil
+		}
+		ts, err := frontend.PostBlocks(channelID, threadTS, text, toolNoticeBlocks(text, promptTS)...)
+		if err != nil {
+			c.logger.WarnKV("Failed to post slow tool notice", "channel", channelID, "tool", toolName, "error", err)
+			return nil
+		}
+		return func() {
+			if _, _, err := frontend.DeleteMessage(channelID, ts); err != nil {
+				c.logger.DebugKV("Failed to delete slow tool notice", "channel", channelID, "ts", ts, "error", err)
+			}
+		}
+	})
+}
+
+// toolNoticeBlocks lays out the notice with a button that cancels the request of
+// the prompt posted at promptTS
+func toolNoticeBlocks(text, promptTS string) []slack.Block {
+	button := slack.NewButtonBlockElement(cancelRequestAction, promptTS, slack.NewTextBlockObject(slack.PlainTextType, "Cancel", false, false))
+	button.Style = slack.StyleDanger
+	return []slack.Block{
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, text, false, false), nil, nil),
+		slack.NewActionBlock("", button),
+	}
+}
+
+// handleInteraction handles clicks on the buttons of the bot's messages and
+// submissions of its modals
+func (c *Client) handleInteraction(callback slack.InteractionCallback) {
+	if callback.Type == slack.InteractionTypeViewSubmission && callback.View.CallbackID == retryModalCallback {
+		c.handleRetrySubmission(callback)
+		return
+	}
+	if callback.Type != slack.InteractionTypeBlockActions {
+		c.logger.DebugKV("Ignored interaction type", "type", callback.Type)
+		return
+	}
+	for _, action := range callback.ActionCallback.BlockActions {
+		if action.ActionID == retryWithEditAction {
+			c.openRetryModal(callback, action.Value)
+			continue
+		}
+		if action.ActionID == showWorkAction {
+			c.openScratchpadModal(callback, action.Value)
+			continue
+		}
+		if action.ActionID != cancelRequestAction {
+			continue
+		}
+		stopped := c.cancelRequests(func(req *inflightRequest) bool {
+			return req.channelID == callback.Channel.ID && req.promptTS == action.Value && req.userID == callback.User.ID
+		})
+		if stopped == 0 {
+			c.logger.DebugKV("Cancel button matched no request of the user", "channel", callback.Channel.ID, "user", callback.User.ID)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package slackbot
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+)
+
+// noticeRecorder is a frontend that can post Block Kit messages and records them
+type noticeRecorder struct {
+	*progressRecorder
+	mu             sync.Mutex
+	notices        []string
+	blocks         []slack.Block
+	deletedNotices []string
+}
+
+func (r *noticeRecorder) PostBlocks(_, _, text string, blocks ...slack.Block) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.notices = append(r.notices, text)
+	r.blocks = blocks
+	return "222.2", nil
+}
+
+func (r *noticeRecorder) DeleteMessage(_, ts string) (string, string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deletedNotices = append(r.deletedNotices, ts)
+	return "", ts, nil
+}
+
+// blockingTool answers when its call is cancelled
+type blockingTool struct{}
+
+func (blockingTool) CallTool(ctx context.Context, _ string, _ map[string]interface{}) (string, error) {
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func TestSlowToolNoticeCancelsRequest(t *testing.T) {
+	client, progress, _ := newProgressTestClient()
+	client.cfg.Timeouts.ToolNoticeAfter = "10ms"
+	frontend := &noticeRecorder{progressRecorder: progress}
+	client.userFrontend = frontend
+
+	ctx, done := client.trackRequest(context.Background(), "C1", "100.1", "100.2", "U1")
+	defer done()
+	ctx = client.withToolNotice(ctx, "C1", "100.1", "100.2")
+
+	called := make(chan error, 1)
+	go func() {
+		_, err := mcp.CallToolWithTimeout(ctx, blockingTool{}, "run_report", time.Minute, nil)
+		called <- err
+	}()
+	require.Eventually(t, func() bool {
+		frontend.mu.Lock()
+		defer frontend.mu.Unlock()
+		return len(frontend.notices) == 1
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, "⏳ Still working on `run_report`... I will stop it if it takes longer than 1m0s.", frontend.notices[0])
+
+	actions, ok := frontend.blocks[1].(*slack.ActionBlock)
+	require.True(t, ok)
+	button := actions.Elements.ElementSet[0].(*slack.ButtonBlockElement)
+	assert.Equal(t, cancelRequestAction, button.ActionID)
+	assert.Equal(t, "100.2", button.Value)
+
+	click := func(userID string) {
+		client.handleInteraction(slack.InteractionCallback{
+			Type:           slack.InteractionTypeBlockActions,
+			Channel:        slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C1"}}},
+			User:           slack.User{ID: userID},
+			ActionCallback: slack.ActionCallbacks{BlockActions: []*slack.BlockAction{{ActionID: button.ActionID, Value: button.Value}}},
+		})
+	}
+
+	// Only the requesting user can cancel the request
+	click("U2")
+	assert.NoError(t, ctx.Err())
+
+	click("U1")
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+	select {
+	case err := <-called:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("the tool call was not cancelled")
+	}
+	assert.Equal(t, []string{"222.2"}, frontend.deletedNotices)
+}
@@ -3,15 +3,19 @@ package slackbot
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/socketmode"
 
 	customErrors "github.com/tuannvm/slack-mcp-client/internal/common/errors"
 	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/network"
 	"github.com/tuannvm/slack-mcp-client/internal/slack/formatter"
 )
 
@@ -37,16 +41,22 @@ func getLogLevel(stdLogger *logging.Logger) logging.LogLevel {
 	return logLevel
 }
 
-func GetSlackClient(botToken, appToken string, stdLogger *logging.Logger, thinkingMessage string) (*SlackClient, error) {
-	if botToken == "" {
-		return nil, fmt.Errorf("SLACK_BOT_TOKEN must be set")
-	}
+func GetSlackClient(botToken, appToken string, stdLogger *logging.Logger, thinkingMessage string, outbound config.SlackOutboundConfig) (*SlackClient, error) {
 	if appToken == "" {
 		return nil, fmt.Errorf("SLACK_APP_TOKEN must be set")
 	}
 	if !strings.HasPrefix(appToken, "xapp-") {
 		return nil, fmt.Errorf("SLACK_APP_TOKEN must have the prefix \"xapp-\"")
 	}
+	return newSlackClient(botToken, appToken, stdLogger, thinkingMessage, outbound)
+}
+
+// newSlackClient authenticates with Slack and builds the client shared by the
+// Socket Mode and HTTP Events API frontends. appToken may be empty in HTTP mode.
+func newSlackClient(botToken, appToken string, stdLogger *logging.Logger, thinkingMessage string, outbound config.SlackOutboundConfig) (*SlackClient, error) {
+	if botToken == "" {
+		return nil, fmt.Errorf("SLACK_BOT_TOKEN must be set")
+	}
 
 	logLevel := getLogLevel(stdLogger)
 
@@ -54,12 +64,16 @@ func GetSlackClient(botToken, appToken string, stdLogger *logging.Logger, thinki
 	slackLogger := logging.New("slack-client", logLevel)
 
 	// Initialize the API client
-	api := slack.New(
-		botToken,
-		slack.OptionAppLevelToken(appToken),
+	apiOptions := []slack.Option{
 		// Still using standard logger for Slack API as it expects a standard logger
 		slack.OptionLog(slackLogger.StdLogger()),
-	)
+		// Observe rate limits of every Web API call
+		slack.OptionHTTPClient(&http.Client{Transport: newRateLimitTransport(http.DefaultTransport, slackLogger)}),
+	}
+	if appToken != "" {
+		apiOptions = append(apiOptions, slack.OptionAppLevelToken(appToken))
+	}
+	api := slack.New(botToken, apiOptions...)
 
 	// Authenticate with Slack
 	authTest, err := api.AuthTestContext(context.Background())
@@ -75,16 +89,23 @@ func GetSlackClient(botToken, appToken string, stdLogger *logging.Logger, thinki
 		// Still using standard logger for socket mode as it expects a standard logger
 		socketmode.OptionLog(slackLogger.StdLogger()),
 		socketmode.OptionDebug(false),
+		socketmode.OptionDialer(network.WebsocketDialer()),
 	)
 
-	return &SlackClient{
+	slackClient := &SlackClient{
 		Client:          client,
 		botMentionRgx:   mentionRegex,
 		botUserID:       authTest.UserID,
+		botID:           authTest.BotID,
+		team:            authTest.Team,
+		teamURL:         authTest.URL,
 		logger:          slackLogger,
 		thinkingMessage: thinkingMessage,
 		userCache:       make(map[string]*UserProfile),
-	}, nil
+	}
+	slackClient.outbox = newOutboundQueue(outbound, slackClient.deliverMessage, slackLogger)
+
+	return slackClient, nil
 }
 
 type UserProfile struct {
@@ -97,9 +118,30 @@ type SlackClient struct {
 	*socketmode.Client
 	botMentionRgx   *regexp.Regexp
 	botUserID       string
+	botID           string // ID of the bot, which the messages it posts carry as their bot_id
+	team            string // Name of the workspace the bot token belongs to
+	teamURL         string // URL of the workspace, e.g. "https://acme.slack.com/"
 	logger          *logging.Logger
 	thinkingMessage string
 	userCache       map[string]*UserProfile
+	outbox          *outboundQueue
+
+	placeholderMu sync.Mutex
+	placeholders  map[string]bool // Progress placeholders awaiting their answer, by message timestamp
+
+	threadsOnce sync.Once
+	threads     *threadCache // Replies fetched per thread; see threadReplies
+}
+
+// Close drains the outbound message queue
+func (slackClient *SlackClient) Close() error {
+	slackClient.outbox.close()
+	return nil
+}
+
+// Workspace returns the name and URL of the connected workspace
+func (slackClient *SlackClient) Workspace() (string, string) {
+	return slackClient.team, slackClient.teamURL
 }
 
 func (slackClient *SlackClient) GetEventChannel() chan socketmode.Event {
@@ -122,20 +164,32 @@ func (slackClient *SlackClient) IsBotUser(userID string) bool {
 	return userID == slackClient.botUserID
 }
 
+// IsOwnMessage reports whether msg was posted by this bot, rather than a user or
+// another app
+func (slackClient *SlackClient) IsOwnMessage(msg slack.Message) bool {
+	return msg.BotID != "" && msg.BotID == slackClient.botID
+}
+
 func (slackClient *SlackClient) GetThreadReplies(channelID, threadTS string) ([]slack.Message, error) {
 	if channelID == "" || threadTS == "" {
 		return nil, fmt.Errorf("channelID and threadTS must be provided")
 	}
-	replies, _, _, err := slackClient.GetConversationReplies(&slack.GetConversationRepliesParameters{
-		ChannelID: channelID,
-		Timestamp: threadTS,
-	})
+	// Long threads are fetched once, then only their new messages
+	replies, err := slackClient.threadReplies().replies(channelID, threadTS, slackClient.GetConversationReplies)
 	if err != nil {
 		return nil, customErrors.WrapSlackError(err, "fetch_thread_replies_failed", "Failed to fetch thread replies")
 	}
 	return replies, nil
 }
 
+// threadReplies returns the thread reply cache, creating it on first use
+func (slackClient *SlackClient) threadReplies() *threadCache {
+	slackClient.threadsOnce.Do(func() {
+		slackClient.threads = newThreadCache()
+	})
+	return slackClient.threads
+}
+
 func (slackClient *SlackClient) GetUserInfo(userID string) (*UserProfile, error) {
 	if userID == "" {
 		return nil, fmt.Errorf("userID must be provided")
@@ -158,24 +212,38 @@ func (slackClient *SlackClient) GetUserInfo(userID string) (*UserProfile, error)
 	return profile, nil
 }
 
-// SendMessage sends a message back to Slack, replying in a thread if threadTS is provided.
+// SendMessage queues a message for delivery to Slack, replying in a thread if threadTS is provided.
+// Messages are delivered in order and retried on rate limits and transient failures.
 func (slackClient *SlackClient) SendMessage(channelID, threadTS, text string) {
 	if text == "" {
 		slackClient.logger.WarnKV("Attempted to send empty message, skipping", "channel", channelID)
 		return
 	}
+	slackClient.outbox.enqueue(channelID, threadTS, text)
+}
 
-	// Delete "typing" indicator messages if any
+// deliverMessage posts a queued message to Slack, or edits the message it replaces.
+// Retryable errors are returned to the outbound queue; other Block Kit errors fall
+// back to plain text.
+func (slackClient *SlackClient) deliverMessage(msg outboundMessage) error {
+	channelID, threadTS, text := msg.ChannelID, msg.ThreadTS, msg.Text
+
+	// Delete "typing" indicator messages if any, except progress placeholders,
+	// which are edited into the answer instead
 	// This is a simplistic approach - more sophisticated approaches might track message IDs
-	history, err := slackClient.GetThreadReplies(channelID, threadTS)
-	if err == nil && history != nil {
-		for _, msg := range history {
-			if slackClient.IsBotUser(msg.User) && msg.Text == slackClient.thinkingMessage {
-				_, _, err := slackClient.DeleteMessage(channelID, msg.Timestamp)
-				if err != nil {
-					slackClient.logger.ErrorKV("Error deleting typing indicator message", "error", err)
+	if msg.UpdateTS == "" {
+		history, err := slackClient.GetThreadReplies(channelID, threadTS)
+		if err == nil && history != nil {
+			for _, msg := range history {
+				if slackClient.IsBotUser(msg.User) && msg.Text == slackClient.thinkingMessage && !slackClient.isPlaceholder(msg.Timestamp) {
+					_, _, err := slackClient.DeleteMessage(channelID, msg.Timestamp)
+					if err != nil {
+						slackClient.logger.ErrorKV("Error deleting typing indicator message", "error", err)
+					} else {
+						slackClient.threadReplies().removeMessage(channelID, msg.Timestamp)
+					}
+					break // Just delete the most recent one
 				}
-				break // Just delete the most recent one
 			}
 		}
 	}
@@ -184,6 +252,12 @@ func (slackClient *SlackClient) SendMessage(channelID, threadTS, text string) {
 	messageType := formatter.DetectMessageType(text)
 	slackClient.logger.DebugKV("Detected message type", "type", messageType, "length", len(text))
 
+	// Edits keep the message in its thread, so they carry no thread timestamp
+	formatThreadTS := threadTS
+	if msg.UpdateTS != "" {
+		formatThreadTS = ""
+	}
+
 	var msgOptions []slack.MsgOption
 
 	switch messageType {
@@ -191,7 +265,7 @@ func (slackClient *SlackClient) SendMessage(channelID, threadTS, text string) {
 		// Message is already in Block Kit JSON format
 		options := formatter.DefaultOptions()
 		options.Format = formatter.BlockFormat
-		options.ThreadTS = threadTS
+		options.ThreadTS = formatThreadTS
 		msgOptions = formatter.FormatMessage(text, options)
 
 	case formatter.StructuredData:
@@ -199,40 +273,71 @@ func (slackClient *SlackClient) SendMessage(channelID, threadTS, text string) {
 		formattedText := formatter.FormatStructuredData(text)
 		options := formatter.DefaultOptions()
 		options.Format = formatter.BlockFormat
-		options.ThreadTS = threadTS
+		options.ThreadTS = formatThreadTS
 		msgOptions = formatter.FormatMessage(formattedText, options)
 
 	case formatter.MarkdownText, formatter.PlainText:
 		// Apply Markdown formatting and use default text formatting
 		formattedText := formatter.FormatMarkdown(text)
 		options := formatter.DefaultOptions()
-		options.ThreadTS = threadTS
+		options.ThreadTS = formatThreadTS
 		msgOptions = formatter.FormatMessage(formattedText, options)
 	}
 
 	// Send the message
-	_, _, err = slackClient.PostMessage(channelID, msgOptions...)
-	if err != nil {
-		slackClient.logger.ErrorKV("Error posting message to channel", "channel", channelID, "error", err, "messageType", messageType)
+	err := slackClient.sendOrUpdate(msg, msgOptions)
+	if err == nil {
+		return nil
+	}
+	slackClient.logger.ErrorKV("Error posting message to channel", "channel", channelID, "error", err, "messageType", messageType)
 
-		// If we get an error with Block Kit format, try falling back to plain text
-		if messageType == formatter.JSONBlock || messageType == formatter.StructuredData {
-			slackClient.logger.InfoKV("Falling back to plain text format due to Block Kit error", "channel", channelID)
+	// Rate limits and transient failures are retried by the outbound queue
+	if isRetryableSlackError(err) {
+		return err
+	}
 
-			// Apply markdown formatting to the original text and send as plain text
-			formattedText := formatter.FormatMarkdown(text)
-			fallbackOptions := []slack.MsgOption{
-				slack.MsgOptionText(formattedText, false),
-			}
-			if threadTS != "" {
-				fallbackOptions = append(fallbackOptions, slack.MsgOptionTS(threadTS))
-			}
+	// If we get an error with Block Kit format, try falling back to plain text
+	if messageType == formatter.JSONBlock || messageType == formatter.StructuredData {
+		slackClient.logger.InfoKV("Falling back to plain text format due to Block Kit error", "channel", channelID)
 
-			// Try sending with plain text format
-			_, _, fallbackErr := slackClient.PostMessage(channelID, fallbackOptions...)
-			if fallbackErr != nil {
-				slackClient.logger.ErrorKV("Error posting fallback message to channel", "channel", channelID, "error", fallbackErr)
-			}
+		// Apply markdown formatting to the original text and send as plain text
+		formattedText := formatter.FormatMarkdown(text)
+		fallbackOptions := []slack.MsgOption{
+			slack.MsgOptionText(formattedText, false),
+		}
+		if formatThreadTS != "" {
+			fallbackOptions = append(fallbackOptions, slack.MsgOptionTS(formatThreadTS))
+		}
+
+		// Try sending with plain text format
+		fallbackErr := slackClient.sendOrUpdate(msg, fallbackOptions)
+		if fallbackErr != nil {
+			slackClient.logger.ErrorKV("Error posting fallback message to channel", "channel", channelID, "error", fallbackErr)
+		}
+		return fallbackErr
+	}
+
+	// An edit that Slack rejects, for example because the placeholder was deleted,
+	// is posted as a new message
+	if msg.UpdateTS != "" {
+		slackClient.logger.InfoKV("Posting a new message because the edit failed", "channel", channelID, "error", err)
+		slackClient.DeletePlaceholder(channelID, msg.UpdateTS)
+		msg.UpdateTS = ""
+		return slackClient.deliverMessage(msg)
+	}
+
+	return err
+}
+
+// sendOrUpdate posts a message, or edits the existing message when UpdateTS is set
+func (slackClient *SlackClient) sendOrUpdate(msg outboundMessage, options []slack.MsgOption) error {
+	if msg.UpdateTS != "" {
+		_, _, _, err := slackClient.UpdateMessage(msg.ChannelID, msg.UpdateTS, options...)
+		if err == nil {
+			slackClient.threadReplies().invalidateMessage(msg.ChannelID, msg.UpdateTS)
 		}
+		return err
 	}
+	_, _, err := slackClient.PostMessage(msg.ChannelID, options...)
+	return err
 }
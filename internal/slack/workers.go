@@ -0,0 +1,100 @@
+package slackbot
+
+import (
+	"sync"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/monitoring"
+)
+
+// promptWorkers limits how many prompts are answered at once. Prompts beyond the
+// limit wait in line for a worker, up to maxQueued of them.
+type promptWorkers struct {
+	slots     chan struct{}
+	maxQueued int // -1 for no limit
+
+	mu      sync.Mutex
+	waiting int
+}
+
+// newPromptWorkers returns the workers of the configuration, or nil when the
+// number of prompts answered at once is not limited
+func newPromptWorkers(cfg config.SlackWorkersConfig) *promptWorkers {
+	if cfg.MaxConcurrent <= 0 {
+		return nil
+	}
+	return &promptWorkers{slots: make(chan struct{}, cfg.MaxConcurrent), maxQueued: cfg.MaxQueued}
+}
+
+// tryAcquire takes a free worker without waiting
+func (w *promptWorkers) tryAcquire() bool {
+	select {
+	case w.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// enqueue gets a place in line, or reports that the line is full
+func (w *promptWorkers) enqueue() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.maxQueued >= 0 && w.waiting >= w.maxQueued {
+		return false
+	}
+	w.waiting++
+	monitoring.PromptsWaiting.Set(float64(w.waiting))
+	return true
+}
+
+// acquire waits in line for a worker
+func (w *promptWorkers) acquire() {
+	w.slots <- struct{}{}
+	w.mu.Lock()
+	w.waiting--
+	monitoring.PromptsWaiting.Set(float64(w.waiting))
+	w.mu.Unlock()
+}
+
+// release frees a worker for the next prompt in line
+func (w *promptWorkers) release() {
+	<-w.slots
+}
+
+// acquireWorker takes a worker to answer a prompt in the thread. When every worker
+// is busy, the user is told right away that the prompt waits in line, or that it
+// was turned away because the line is full, in which case ok is false. release
+// must be called once the prompt is answered.
+func (c *Client) acquireWorker(channelID, threadTS string) (release func(), ok bool) {
+	if c.workers == nil {
+		return func() {}, true
+	}
+	if c.workers.tryAcquire() {
+		return c.workers.release, true
+	}
+
+	busy := c.cfg.Slack.Workers
+	if !c.workers.enqueue() {
+		c.logger.WarnKV("Turned a prompt away, all workers are busy and the line is full", "channel", channelID, "thread_ts", threadTS)
+		monitoring.PromptsDelayed.WithLabelValues("rejected").Inc()
+		c.sendMessage(channelID, threadTS, busy.FullMessage)
+		return nil, false
+	}
+	c.logger.InfoKV("All workers are busy, prompt waits in line", "channel", channelID, "thread_ts", threadTS)
+	monitoring.PromptsDelayed.WithLabelValues("queued").Inc()
+
+	// A placeholder notice is removed once a worker picks the prompt up
+	noticeTS := ""
+	if progress, ok := c.userFrontend.(ProgressFrontend); ok {
+		noticeTS, _ = progress.PostPlaceholder(channelID, threadTS, busy.BusyMessage)
+	}
+	if noticeTS == "" {
+		c.sendMessage(channelID, threadTS, busy.BusyMessage)
+	}
+	c.workers.acquire()
+	if noticeTS != "" {
+		c.userFrontend.(ProgressFrontend).DeletePlaceholder(channelID, noticeTS)
+	}
+	return c.workers.release, true
+}
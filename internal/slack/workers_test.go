@@ -0,0 +1,64 @@
+package slackbot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+// busyRecorder is a frontend that hands the placeholders it posts to the test
+type busyRecorder struct {
+	*alertRecorder
+	notices chan string
+}
+
+func (r *busyRecorder) PostPlaceholder(_, _, text string) (string, error) {
+	r.notices <- text
+	return "111.1", nil
+}
+
+func TestBusyWorkersQueueAndTurnAwayPrompts(t *testing.T) {
+	client, progress, _ := newProgressTestClient()
+	frontend := &busyRecorder{alertRecorder: &alertRecorder{progressRecorder: progress, sent: map[string][]string{}}, notices: make(chan string, 1)}
+	client.userFrontend = frontend
+	client.workers = newPromptWorkers(config.SlackWorkersConfig{MaxConcurrent: 1, MaxQueued: 1})
+
+	release, ok := client.acquireWorker("C1", "100.1")
+	require.True(t, ok)
+	assert.Empty(t, frontend.notices, "a free worker answers without a notice")
+
+	// The next prompt waits in line behind a busy notice
+	acquired := make(chan func())
+	go func() {
+		next, _ := client.acquireWorker("C1", "200.1")
+		acquired <- next
+	}()
+	assert.Equal(t, client.cfg.Slack.Workers.BusyMessage, <-frontend.notices)
+
+	// A full line turns the prompt away
+	_, ok = client.acquireWorker("C1", "300.1")
+	assert.False(t, ok)
+	assert.Equal(t, []string{client.cfg.Slack.Workers.FullMessage}, frontend.sent["C1"])
+
+	// Once a worker frees up, the waiting prompt is picked up and its notice removed
+	release()
+	next := <-acquired
+	assert.Equal(t, []string{"111.1"}, progress.deleted)
+	next()
+	assert.True(t, client.workers.tryAcquire())
+}
+
+func TestUnlimitedWorkers(t *testing.T) {
+	assert.Nil(t, newPromptWorkers(config.SlackWorkersConfig{MaxConcurrent: -1}))
+
+	client, progress, _ := newProgressTestClient()
+	client.workers = nil
+	for i := 0; i < 3; i++ {
+		_, ok := client.acquireWorker("C1", "100.1")
+		assert.True(t, ok)
+	}
+	assert.Empty(t, progress.posted)
+}
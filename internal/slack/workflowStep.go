@@ -0,0 +1,99 @@
+package slackbot
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/tuannvm/slack-mcp-client/internal/monitoring"
+)
+
+// Inputs and output of the Workflow Builder step, as declared in the app manifest
+const (
+	workflowInputPrompt  = "prompt"
+	workflowInputChannel = "channel_id"
+	workflowOutputAnswer = "answer"
+)
+
+// WorkflowFrontend is implemented by frontends that can run custom steps of
+// Workflow Builder
+type WorkflowFrontend interface {
+	PostMessage(channelID string, options ...slack.MsgOption) (string, string, error)
+	FunctionCompleteSuccess(functionExecutionID string, options ...slack.FunctionCompleteSuccessRequestOption) error
+	FunctionCompleteError(functionExecutionID, errorMessage string) error
+}
+
+// handleFunctionExecuted runs the workflow step and completes the function
+// execution with the answer, or with an error the workflow shows its builder
+func (c *Client) handleFunctionExecuted(ev *slackevents.FunctionExecutedEvent) {
+	frontend, ok := c.userFrontend.(WorkflowFrontend)
+	if !ok {
+		c.logger.WarnKV("Ignoring workflow step, the frontend cannot run custom steps", "callback_id", ev.Function.CallbackID)
+		return
+	}
+	c.logger.InfoKV("Running workflow step", "callback_id", ev.Function.CallbackID, "workflow_execution_id", ev.WorkflowExecutionID)
+
+	answer, err := c.runWorkflowStep(frontend, ev.Inputs)
+	if err != nil {
+		c.logger.WarnKV("Workflow step failed", "workflow_execution_id", ev.WorkflowExecutionID, "error", err)
+		monitoring.SlackWorkflowSteps.WithLabelValues("error").Inc()
+		if err := frontend.FunctionCompleteError(ev.FunctionExecutionID, err.Error()); err != nil {
+			c.logger.ErrorKV("Failed to report the workflow step error", "function_execution_id", ev.FunctionExecutionID, "error", err)
+		}
+		return
+	}
+	outputs := map[string]string{workflowOutputAnswer: answer}
+	if err := frontend.FunctionCompleteSuccess(ev.FunctionExecutionID, slack.FunctionCompleteSuccessRequestOptionOutput(outputs)); err != nil {
+		c.logger.ErrorKV("Failed to complete the workflow step", "function_execution_id", ev.FunctionExecutionID, "error", err)
+		monitoring.SlackWorkflowSteps.WithLabelValues("error").Inc()
+		return
+	}
+	monitoring.SlackWorkflowSteps.WithLabelValues("completed").Inc()
+}
+
+// runWorkflowStep posts the prompt of the step in its channel and answers it in
+// the thread like any other prompt, returning the replies posted there
+func (c *Client) runWorkflowStep(frontend WorkflowFrontend, inputs map[string]string) (string, error) {
+	prompt := strings.TrimSpace(inputs[workflowInputPrompt])
+	channelID := inputs[workflowInputChannel]
+	if prompt == "" || channelID == "" {
+		return "", fmt.Errorf("the step needs a prompt and a channel")
+	}
+
+	// The inputs are set by whoever builds the workflow, so a user_id input is not
+	// proof of who is asking. The step runs without a user identity and only the
+	// channel is checked against the security settings.
+	profile := &UserProfile{realName: "Workflow"}
+	question := fmt.Sprintf("🔄 *Workflow question:*\n%s", prompt)
+	_, threadTS, err := frontend.PostMessage(channelID, slack.MsgOptionText(question, false))
+	if err != nil {
+		return "", fmt.Errorf("failed to post the prompt in <#%s>: %w", channelID, err)
+	}
+
+	key := historyKey(channelID, threadTS)
+	c.workflowMu.Lock()
+	c.workflowReplies[key] = nil
+	c.workflowMu.Unlock()
+	c.answerPrompt(prompt, channelID, threadTS, threadTS, profile, "")
+	c.workflowMu.Lock()
+	replies := c.workflowReplies[key]
+	delete(c.workflowReplies, key)
+	c.workflowMu.Unlock()
+
+	if len(replies) == 0 {
+		return "", fmt.Errorf("the prompt was not answered, see the thread in <#%s>", channelID)
+	}
+	return strings.Join(replies, "\n\n"), nil
+}
+
+// recordWorkflowReply keeps a reply to a prompt asked by a workflow step
+func (c *Client) recordWorkflowReply(channelID, threadTS, text string) {
+	c.workflowMu.Lock()
+	defer c.workflowMu.Unlock()
+	key := historyKey(channelID, threadTS)
+	if replies, ok := c.workflowReplies[key]; ok {
+		c.workflowReplies[key] = append(replies, text)
+	}
+}
@@ -0,0 +1,92 @@
+package slackbot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// workflowRecorder records the prompts posted by workflow steps and how their
+// executions completed
+type workflowRecorder struct {
+	*progressRecorder
+	prompts []string
+	outputs map[string]map[string]string
+	errors  map[string]string
+}
+
+func (r *workflowRecorder) PostMessage(channelID string, options ...slack.MsgOption) (string, string, error) {
+	_, values, err := slack.UnsafeApplyMsgOptions("", channelID, "", options...)
+	if err != nil {
+		return "", "", err
+	}
+	r.prompts = append(r.prompts, values.Get("text"))
+	return channelID, "200.1", nil
+}
+
+func (r *workflowRecorder) FunctionCompleteSuccess(functionExecutionID string, options ...slack.FunctionCompleteSuccessRequestOption) error {
+	request := &slack.FunctionCompleteSuccessRequest{FunctionExecutionID: functionExecutionID}
+	for _, option := range options {
+		_ = option(request)
+	}
+	r.outputs[functionExecutionID] = request.Outputs
+	return nil
+}
+
+func (r *workflowRecorder) FunctionCompleteError(functionExecutionID, errorMessage string) error {
+	r.errors[functionExecutionID] = errorMessage
+	return nil
+}
+
+func newWorkflowTestClient() (*Client, *workflowRecorder) {
+	client, progress, _ := newProgressTestClient()
+	frontend := &workflowRecorder{progressRecorder: progress, outputs: map[string]map[string]string{}, errors: map[string]string{}}
+	client.userFrontend = frontend
+	client.workflowReplies = make(map[string][]string)
+	return client, frontend
+}
+
+func functionExecuted(executionID string, inputs map[string]string) *slackevents.FunctionExecutedEvent {
+	ev := &slackevents.FunctionExecutedEvent{FunctionExecutionID: executionID, Inputs: inputs}
+	ev.Function.CallbackID = "ask_mcp"
+	return ev
+}
+
+func TestWorkflowStepNeedsPromptAndChannel(t *testing.T) {
+	client, frontend := newWorkflowTestClient()
+
+	client.handleFunctionExecuted(functionExecuted("Fx1", map[string]string{"prompt": "  "}))
+	assert.Equal(t, "the step needs a prompt and a channel", frontend.errors["Fx1"])
+	assert.Empty(t, frontend.prompts)
+}
+
+func TestWorkflowStepFailsWithoutAnswer(t *testing.T) {
+	client, frontend := newWorkflowTestClient()
+	client.cfg.Security.Enabled = true
+	client.cfg.Security.AllowedUsers = []string{"U-ADMIN"}
+	client.cfg.Security.AdminUsers = []string{"U-ADMIN"}
+	client.cfg.ApplyDefaults()
+
+	// A user_id input does not lend the step that user's access
+	client.handleFunctionExecuted(functionExecuted("Fx1", map[string]string{"prompt": "Any open incidents?", "channel_id": "C1", "user_id": "U-ADMIN"}))
+	require.Len(t, frontend.prompts, 1)
+	assert.Equal(t, "🔄 *Workflow question:*\nAny open incidents?", frontend.prompts[0])
+	assert.Equal(t, "the prompt was not answered, see the thread in <#C1>", frontend.errors["Fx1"])
+	assert.Empty(t, frontend.outputs)
+	assert.Empty(t, client.workflowReplies)
+}
+
+func TestWorkflowRepliesAreCollected(t *testing.T) {
+	client, _ := newWorkflowTestClient()
+	client.workflowReplies[historyKey("C1", "200.1")] = nil
+
+	client.reply(context.Background(), "C1", "200.1", "Two alerts are firing.")
+	client.reply(context.Background(), "C1", "200.1", "Sources: runbook")
+	client.reply(context.Background(), "C1", "300.1", "Another thread")
+	assert.Equal(t, []string{"Two alerts are firing.", "Sources: runbook"}, client.workflowReplies[historyKey("C1", "200.1")])
+	assert.Len(t, client.workflowReplies, 1)
+}
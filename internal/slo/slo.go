@@ -0,0 +1,288 @@
+// Package slo tracks the bot's service level objectives: the p95 latency of its
+// answers, the share of prompts that fail and the share of tool calls that fail.
+// Each objective is measured over a sliding window against its error budget, the
+// share of answers or calls allowed to miss it. An alert fires when a budget
+// burns faster than allowed, and another when the objective recovers.
+package slo
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/monitoring"
+	"github.com/tuannvm/slack-mcp-client/pkg/middleware"
+)
+
+// Objectives
+const (
+	ObjectiveLatency      = "latency"
+	ObjectiveErrors       = "errors"
+	ObjectiveToolFailures = "tool_failures"
+)
+
+// latencyBudget is the share of answers allowed to take longer than the p95 objective
+const latencyBudget = 0.05
+
+// Alert is an objective whose error budget started or stopped burning too fast
+type Alert struct {
+	Objective string
+	Firing    bool // False when the objective recovered
+	BurnRate  float64
+	Summary   string // What was measured, such as "3 of 40 prompts failed (objective 5%)"
+	Channel   string // Channel ID to post to, empty when none is configured
+	Time      time.Time
+}
+
+// Status is an objective as measured over the window
+type Status struct {
+	Objective string
+	Samples   int // Answers or tool calls in the window
+	BurnRate  float64
+	Summary   string
+	Firing    bool
+}
+
+// sample is an answer or tool call in the window
+type sample struct {
+	time     time.Time
+	duration time.Duration // Answers only
+	failed   bool
+}
+
+// Tracker measures answers and tool calls against the objectives
+type Tracker struct {
+	cfg           config.SLOConfig
+	window        time.Duration
+	latencyTarget time.Duration
+	now           func() time.Time
+
+	mu      sync.Mutex
+	answers []sample
+	tools   []sample
+	firing  map[string]bool
+	onAlert func(Alert)
+}
+
+// NewTracker returns a Tracker for the objectives, or nil when SLO tracking is disabled
+func NewTracker(cfg config.SLOConfig) *Tracker {
+	if !cfg.Enabled {
+		return nil
+	}
+	t := &Tracker{cfg: cfg, now: time.Now, firing: make(map[string]bool)}
+	t.window, _ = time.ParseDuration(cfg.Window) // Validated with the config
+	t.latencyTarget, _ = time.ParseDuration(cfg.LatencyP95)
+	return t
+}
+
+// SetAlertHandler sets the function told about alerts
+func (t *Tracker) SetAlertHandler(handler func(Alert)) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onAlert = handler
+}
+
+type answerKey struct{}
+
+// answer is a prompt being answered
+type answer struct {
+	failed atomic.Bool
+}
+
+// StartAnswer measures the answer to a prompt received at start. The returned
+// function records it once the answer is posted; an answer stopped by cancelling
+// ctx is not recorded.
+func (t *Tracker) StartAnswer(ctx context.Context, start time.Time) (context.Context, func()) {
+	if t == nil {
+		return ctx, func() {}
+	}
+	a := &answer{}
+	return context.WithValue(ctx, answerKey{}, a), func() {
+		if ctx.Err() != nil {
+			return
+		}
+		t.ObserveAnswer(t.now().Sub(start), a.failed.Load())
+	}
+}
+
+// Fail marks the answer measured in ctx as failed
+func Fail(ctx context.Context) {
+	if a, ok := ctx.Value(answerKey{}).(*answer); ok {
+		a.failed.Store(true)
+	}
+}
+
+// ObserveAnswer records an answer that took duration, and whether it failed
+func (t *Tracker) ObserveAnswer(duration time.Duration, failed bool) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.answers = t.prune(append(t.answers, sample{time: t.now(), duration: duration, failed: failed}))
+	alerts := t.evaluate(t.latencyStatus(), t.errorStatus())
+	t.mu.Unlock()
+	t.notify(alerts)
+}
+
+// ObserveToolCall records a tool call, and whether it failed
+func (t *Tracker) ObserveToolCall(failed bool) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.tools = t.prune(append(t.tools, sample{time: t.now(), failed: failed}))
+	alerts := t.evaluate(t.toolStatus())
+	t.mu.Unlock()
+	t.notify(alerts)
+}
+
+// Statuses returns the objectives as measured over the window
+func (t *Tracker) Statuses() []Status {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.answers = t.prune(t.answers)
+	t.tools = t.prune(t.tools)
+	statuses := []Status{t.latencyStatus(), t.errorStatus(), t.toolStatus()}
+	for i := range statuses {
+		statuses[i].Firing = t.firing[statuses[i].Objective]
+	}
+	return statuses
+}
+
+// prune drops the samples older than the window. It is called with t.mu held.
+func (t *Tracker) prune(samples []sample) []sample {
+	now := t.now()
+	start := 0
+	for start < len(samples) && now.Sub(samples[start].time) > t.window {
+		start++
+	}
+	return samples[start:]
+}
+
+// latencyStatus measures the p95 latency of the answers. It is called with t.mu held.
+func (t *Tracker) latencyStatus() Status {
+	status := Status{Objective: ObjectiveLatency, Samples: len(t.answers)}
+	if status.Samples == 0 {
+		return status
+	}
+	durations := make([]time.Duration, 0, len(t.answers))
+	slow := 0
+	for _, answer := range t.answers {
+		durations = append(durations, answer.duration)
+		if answer.duration > t.latencyTarget {
+			slow++
+		}
+	}
+	slices.Sort(durations)
+	p95 := durations[int(math.Ceil(0.95*float64(len(durations))))-1]
+	status.BurnRate = float64(slow) / float64(status.Samples) / latencyBudget
+	status.Summary = fmt.Sprintf("p95 latency %s over %d answers (objective %s)", p95.Round(time.Second), status.Samples, t.latencyTarget)
+	return status
+}
+
+// errorStatus measures the share of failed prompts. It is called with t.mu held.
+func (t *Tracker) errorStatus() Status {
+	return failureStatus(ObjectiveErrors, t.answers, "prompts", t.cfg.ErrorRate)
+}
+
+// toolStatus measures the share of failed tool calls. It is called with t.mu held.
+func (t *Tracker) toolStatus() Status {
+	return failureStatus(ObjectiveToolFailures, t.tools, "tool calls", t.cfg.ToolFailureRate)
+}
+
+// failureStatus measures the share of failed samples against a budget
+func failureStatus(objective string, samples []sample, noun string, budget float64) Status {
+	status := Status{Objective: objective, Samples: len(samples)}
+	if status.Samples == 0 {
+		return status
+	}
+	failed := 0
+	for _, s := range samples {
+		if s.failed {
+			failed++
+		}
+	}
+	status.BurnRate = float64(failed) / float64(status.Samples) / budget
+	status.Summary = fmt.Sprintf("%d of %d %s failed (objective %g%%)", failed, status.Samples, noun, 100*budget)
+	return status
+}
+
+// evaluate exports the burn rates and returns the alerts of objectives that
+// started or stopped burning their budget too fast. It is called with t.mu held.
+func (t *Tracker) evaluate(statuses ...Status) []Alert {
+	var alerts []Alert
+	for _, status := range statuses {
+		monitoring.SLOBurnRate.WithLabelValues(status.Objective).Set(status.BurnRate)
+		burning := status.BurnRate >= t.cfg.BurnRate
+		switch {
+		case burning && !t.firing[status.Objective] && status.Samples >= t.cfg.MinSamples:
+			t.firing[status.Objective] = true
+			monitoring.SLOAlerts.WithLabelValues(status.Objective).Inc()
+		case !burning && t.firing[status.Objective]:
+			t.firing[status.Objective] = false
+		default:
+			continue
+		}
+		alerts = append(alerts, Alert{
+			Objective: status.Objective,
+			Firing:    t.firing[status.Objective],
+			BurnRate:  status.BurnRate,
+			Summary:   status.Summary,
+			Channel:   t.cfg.AlertChannel,
+			Time:      t.now(),
+		})
+	}
+	return alerts
+}
+
+// notify tells the alert handler about alerts
+func (t *Tracker) notify(alerts []Alert) {
+	if len(alerts) == 0 {
+		return
+	}
+	t.mu.Lock()
+	handler := t.onAlert
+	t.mu.Unlock()
+	if handler == nil {
+		return
+	}
+	for _, alert := range alerts {
+		handler(alert)
+	}
+}
+
+// toolObserver records the outcome of tool calls as a bridge middleware
+type toolObserver struct {
+	middleware.Base
+	tracker *Tracker
+}
+
+// Middleware returns a middleware that records tool calls, or nil when SLO
+// tracking is disabled
+func (t *Tracker) Middleware() middleware.Middleware {
+	if t == nil {
+		return nil
+	}
+	return toolObserver{tracker: t}
+}
+
+func (o toolObserver) WrapTool(next middleware.ToolHandler) middleware.ToolHandler {
+	return func(ctx context.Context, call *middleware.ToolCall) (string, error) {
+		result, err := next(ctx, call)
+		if ctx.Err() == nil {
+			o.tracker.ObserveToolCall(err != nil)
+		}
+		return result, err
+	}
+}
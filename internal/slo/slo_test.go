@@ -0,0 +1,115 @@
+package slo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/pkg/middleware"
+)
+
+// newTestTracker returns a tracker with the default objectives, its clock and
+// the alerts it fired
+func newTestTracker(t *testing.T, cfg config.SLOConfig) (*Tracker, *time.Time, *[]Alert) {
+	t.Helper()
+	c := &config.Config{SLO: cfg}
+	c.SLO.Enabled = true
+	c.ApplyDefaults()
+	tracker := NewTracker(c.SLO)
+	require.NotNil(t, tracker)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker.now = func() time.Time { return now }
+	var alerts []Alert
+	tracker.SetAlertHandler(func(alert Alert) { alerts = append(alerts, alert) })
+	return tracker, &now, &alerts
+}
+
+func TestNewTrackerDisabled(t *testing.T) {
+	var tracker *Tracker = NewTracker(config.SLOConfig{})
+	assert.Nil(t, tracker)
+	assert.Nil(t, tracker.Middleware())
+	assert.Nil(t, tracker.Statuses())
+
+	// A nil tracker measures nothing
+	ctx, answered := tracker.StartAnswer(context.Background(), time.Now())
+	Fail(ctx)
+	answered()
+	tracker.ObserveToolCall(true)
+}
+
+func TestErrorRateAlertsAndRecovers(t *testing.T) {
+	tracker, now, alerts := newTestTracker(t, config.SLOConfig{MinSamples: 10, AlertChannel: "C-OPS"})
+
+	// One failure in 10 prompts burns the 5% budget twice as fast as allowed
+	for i := 0; i < 9; i++ {
+		tracker.ObserveAnswer(time.Second, false)
+	}
+	assert.Empty(t, *alerts)
+	tracker.ObserveAnswer(time.Second, true)
+	require.Len(t, *alerts, 1)
+	alert := (*alerts)[0]
+	assert.Equal(t, ObjectiveErrors, alert.Objective)
+	assert.True(t, alert.Firing)
+	assert.InDelta(t, 2, alert.BurnRate, 0.001)
+	assert.Equal(t, "1 of 10 prompts failed (objective 5%)", alert.Summary)
+	assert.Equal(t, "C-OPS", alert.Channel)
+
+	// A firing objective alerts again only once it recovers
+	tracker.ObserveAnswer(time.Second, true)
+	assert.Len(t, *alerts, 1)
+	*now = now.Add(2 * time.Hour)
+	tracker.ObserveAnswer(time.Second, false)
+	require.Len(t, *alerts, 2)
+	assert.False(t, (*alerts)[1].Firing)
+	assert.Equal(t, "0 of 1 prompts failed (objective 5%)", (*alerts)[1].Summary)
+}
+
+func TestLatencyObjective(t *testing.T) {
+	tracker, _, alerts := newTestTracker(t, config.SLOConfig{MinSamples: 20, LatencyP95: "30s", BurnRate: 1.5})
+
+	for i := 0; i < 19; i++ {
+		tracker.ObserveAnswer(time.Duration(i+1)*time.Second, false)
+	}
+	tracker.ObserveAnswer(45*time.Second, false)
+	assert.Empty(t, *alerts, "one slow answer in 20 spends the budget exactly")
+	tracker.ObserveAnswer(50*time.Second, false)
+	require.Len(t, *alerts, 1)
+	assert.Equal(t, ObjectiveLatency, (*alerts)[0].Objective)
+	assert.Equal(t, "p95 latency 45s over 21 answers (objective 30s)", (*alerts)[0].Summary)
+
+	statuses := tracker.Statuses()
+	require.Len(t, statuses, 3)
+	assert.True(t, statuses[0].Firing)
+	assert.False(t, statuses[1].Firing)
+	assert.Zero(t, statuses[2].Samples)
+}
+
+func TestAnswersAndToolCallsAreMeasured(t *testing.T) {
+	tracker, now, alerts := newTestTracker(t, config.SLOConfig{MinSamples: 2})
+
+	ctx, answered := tracker.StartAnswer(context.Background(), now.Add(-5*time.Second))
+	Fail(ctx)
+	answered()
+	stopped, cancel := context.WithCancel(context.Background())
+	_, answered = tracker.StartAnswer(stopped, *now)
+	cancel()
+	answered()
+	statuses := tracker.Statuses()
+	assert.Equal(t, 1, statuses[1].Samples, "a stopped answer is not measured")
+	assert.Equal(t, "1 of 1 prompts failed (objective 5%)", statuses[1].Summary)
+
+	chain := middleware.NewChain(tracker.Middleware())
+	for _, err := range []error{nil, errors.New("boom")} {
+		_, _ = chain.RunTool(context.Background(), &middleware.ToolCall{Tool: "search"}, func(context.Context, *middleware.ToolCall) (string, error) {
+			return "", err
+		})
+	}
+	require.Len(t, *alerts, 1)
+	assert.Equal(t, ObjectiveToolFailures, (*alerts)[0].Objective)
+	assert.Equal(t, "1 of 2 tool calls failed (objective 10%)", (*alerts)[0].Summary)
+}
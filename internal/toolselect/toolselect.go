@@ -0,0 +1,154 @@
+// Package toolselect narrows the discovered MCP tools down to the ones most relevant
+// to a user's message, by comparing embeddings of the message and tool descriptions.
+package toolselect
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/llm"
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+)
+
+// Embedder turns text into vectors
+type Embedder interface {
+	EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error)
+	EmbedQuery(ctx context.Context, text string) ([]float32, error)
+}
+
+// indexedTool is a tool description and its embedding
+type indexedTool struct {
+	text   string
+	vector []float32
+}
+
+// Selector ranks tools by similarity to a query. Tool embeddings are computed once
+// and cached, so servers registered later are indexed on first use.
+type Selector struct {
+	embedder Embedder
+	logger   *logging.Logger
+
+	mu    sync.Mutex
+	index map[string]indexedTool // tool name -> embedding
+}
+
+// NewSelector creates a Selector using the given embedder
+func NewSelector(embedder Embedder, logger *logging.Logger) *Selector {
+	return &Selector{
+		embedder: embedder,
+		logger:   logger,
+		index:    make(map[string]indexedTool),
+	}
+}
+
+// NewSelectorFromConfig creates a Selector using the configured embedding provider
+func NewSelectorFromConfig(cfg *config.Config, logger *logging.Logger) (*Selector, error) {
+	selection := cfg.LLM.ToolSelection
+	embedder, err := llm.NewEmbedder(selection.Provider, selection.Model, cfg.LLM.Providers[selection.Provider])
+	if err != nil {
+		return nil, err
+	}
+	logger.InfoKV("Using embedding-based tool selection", "provider", selection.Provider, "model", selection.Model, "top_k", selection.TopK)
+	return NewSelector(embedder, logger.WithName("toolselect")), nil
+}
+
+// Index embeds any tools that are new or whose description changed
+func (s *Selector) Index(ctx context.Context, tools map[string]mcp.ToolInfo) error {
+	s.mu.Lock()
+	var names, texts []string
+	for name, tool := range tools {
+		text := toolText(name, tool)
+		if indexed, ok := s.index[name]; ok && indexed.text == text {
+			continue
+		}
+		names = append(names, name)
+		texts = append(texts, text)
+	}
+	s.mu.Unlock()
+
+	if len(texts) == 0 {
+		return nil
+	}
+	vectors, err := s.embedder.EmbedDocuments(ctx, texts)
+	if err != nil {
+		return fmt.Errorf("failed to embed tool descriptions: %w", err)
+	}
+	if len(vectors) != len(texts) {
+		return fmt.Errorf("embedder returned %d vectors for %d tools", len(vectors), len(texts))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, name := range names {
+		s.index[name] = indexedTool{text: texts[i], vector: vectors[i]}
+	}
+	s.logger.DebugKV("Indexed tool descriptions", "new_tools", len(names), "total_tools", len(s.index))
+	return nil
+}
+
+// Select returns the names of the topK tools most similar to the query, plus any
+// tools in alwaysInclude. All tools are returned when there are no more than topK.
+func (s *Selector) Select(ctx context.Context, query string, tools map[string]mcp.ToolInfo, topK int, alwaysInclude []string) ([]string, error) {
+	if topK <= 0 || len(tools) <= topK {
+		return sortedNames(tools), nil
+	}
+	if err := s.Index(ctx, tools); err != nil {
+		return nil, err
+	}
+	queryVector, err := s.embedder.EmbedQuery(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+
+	type scoredTool struct {
+		name  string
+		score float64
+	}
+	s.mu.Lock()
+	scored := make([]scoredTool, 0, len(tools))
+	for name := range tools {
+		scored = append(scored, scoredTool{name: name, score: llm.CosineSimilarity(queryVector, s.index[name].vector)})
+	}
+	s.mu.Unlock()
+	sort.Slice(scored, func(i, j int) bool {
+		if scored[i].score != scored[j].score {
+			return scored[i].score > scored[j].score
+		}
+		return scored[i].name < scored[j].name
+	})
+
+	selected := make([]string, 0, topK+len(alwaysInclude))
+	seen := make(map[string]bool, topK+len(alwaysInclude))
+	for _, name := range alwaysInclude {
+		if _, exists := tools[name]; exists && !seen[name] {
+			selected = append(selected, name)
+			seen[name] = true
+		}
+	}
+	for _, tool := range scored[:topK] {
+		if !seen[tool.name] {
+			selected = append(selected, tool.name)
+			seen[tool.name] = true
+		}
+	}
+	return selected, nil
+}
+
+// toolText is the text embedded for a tool
+func toolText(name string, tool mcp.ToolInfo) string {
+	return name + ": " + tool.ToolDescription
+}
+
+// sortedNames returns the tool names in a stable order
+func sortedNames(tools map[string]mcp.ToolInfo) []string {
+	names := make([]string, 0, len(tools))
+	for name := range tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,79 @@
+package toolselect
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+)
+
+// keywordEmbedder embeds text as counts of a fixed set of keywords
+type keywordEmbedder struct {
+	keywords  []string
+	documents int
+}
+
+func (e *keywordEmbedder) embed(text string) []float32 {
+	vector := make([]float32, len(e.keywords))
+	for i, keyword := range e.keywords {
+		vector[i] = float32(strings.Count(strings.ToLower(text), keyword))
+	}
+	return vector
+}
+
+func (e *keywordEmbedder) EmbedDocuments(_ context.Context, texts []string) ([][]float32, error) {
+	e.documents += len(texts)
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = e.embed(text)
+	}
+	return vectors, nil
+}
+
+func (e *keywordEmbedder) EmbedQuery(_ context.Context, text string) ([]float32, error) {
+	return e.embed(text), nil
+}
+
+func testTools() map[string]mcp.ToolInfo {
+	return map[string]mcp.ToolInfo{
+		"get_weather":   {ToolDescription: "Get the weather forecast for a city"},
+		"list_issues":   {ToolDescription: "List open GitHub issues"},
+		"create_issue":  {ToolDescription: "Create a GitHub issue"},
+		"query_metrics": {ToolDescription: "Query Prometheus metrics"},
+	}
+}
+
+func TestSelectTopK(t *testing.T) {
+	embedder := &keywordEmbedder{keywords: []string{"weather", "issue", "metrics"}}
+	selector := NewSelector(embedder, logging.New("test", logging.LevelError))
+
+	selected, err := selector.Select(context.Background(), "what's the weather in Hanoi?", testTools(), 1, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"get_weather"}, selected)
+
+	selected, err = selector.Select(context.Background(), "any open issues?", testTools(), 2, []string{"query_metrics", "unknown"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"query_metrics", "create_issue", "list_issues"}, selected)
+
+	// Tool descriptions are embedded only once
+	assert.Equal(t, 4, embedder.documents)
+}
+
+func TestSelectReturnsAllWhenUnderLimit(t *testing.T) {
+	embedder := &keywordEmbedder{keywords: []string{"weather"}}
+	selector := NewSelector(embedder, logging.New("test", logging.LevelError))
+
+	selected, err := selector.Select(context.Background(), "weather", testTools(), 10, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"create_issue", "get_weather", "list_issues", "query_metrics"}, selected)
+
+	selected, err = selector.Select(context.Background(), "weather", testTools(), 0, nil)
+	require.NoError(t, err)
+	assert.Len(t, selected, 4)
+	assert.Zero(t, embedder.documents)
+}
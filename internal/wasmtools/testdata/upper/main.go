@@ -0,0 +1,29 @@
+// Command upper is a WASM tool used by the tests: it upper-cases the "text"
+// argument, fails on "fail" and never finishes on "spin"
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func main() {
+	var args struct {
+		Text string `json:"text"`
+	}
+	if err := json.NewDecoder(os.Stdin).Decode(&args); err != nil {
+		fmt.Fprintln(os.Stderr, "invalid arguments:", err)
+		os.Exit(2)
+	}
+	switch args.Text {
+	case "fail":
+		fmt.Fprintln(os.Stderr, "cannot convert")
+		os.Exit(1)
+	case "spin":
+		for {
+		}
+	}
+	fmt.Print(strings.ToUpper(args.Text))
+}
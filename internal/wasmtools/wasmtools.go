@@ -0,0 +1,193 @@
+// Package wasmtools runs lightweight custom tools as WebAssembly modules in a
+// sandbox, so pure-compute tools such as formatters, calculators and converters
+// need no MCP server. A tool is a WASI command module: it reads the tool arguments
+// as JSON on stdin, writes the result to stdout and exits with a non-zero status
+// on failure, with the reason on stderr. Modules get no filesystem, network,
+// environment or clock beyond what WASI requires, and run with a memory limit
+// and a deadline.
+package wasmtools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+)
+
+const (
+	// ServerName identifies the WASM tools client among the bridge's clients
+	ServerName = "wasm"
+
+	// maxOutput bounds the result a module may write
+	maxOutput = 1 << 20
+
+	// wasmPageSize is the size of a WebAssembly memory page
+	wasmPageSize = 64 * 1024
+)
+
+// module is a compiled tool module with its own runtime, which holds its memory limit
+type module struct {
+	runtime  wazero.Runtime
+	compiled wazero.CompiledModule
+	timeout  time.Duration
+}
+
+// Client runs the configured WASM tools
+type Client struct {
+	modules map[string]*module
+	logger  *logging.Logger
+}
+
+// NewClient compiles the modules of the configured tools
+func NewClient(ctx context.Context, tools map[string]config.WASMToolConfig, logger *logging.Logger) (*Client, error) {
+	c := &Client{modules: make(map[string]*module, len(tools)), logger: logger}
+	for name, tool := range tools {
+		m, err := compile(ctx, tool)
+		if err != nil {
+			_ = c.Close(ctx)
+			return nil, fmt.Errorf("wasm tool '%s': %w", name, err)
+		}
+		c.modules[name] = m
+		logger.InfoKV("Compiled WASM tool", "tool", name, "path", tool.Path, "max_memory_mb", tool.MaxMemoryMB)
+	}
+	return c, nil
+}
+
+func compile(ctx context.Context, tool config.WASMToolConfig) (*module, error) {
+	timeout, err := time.ParseDuration(tool.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timeout '%s': %w", tool.Timeout, err)
+	}
+	code, err := os.ReadFile(tool.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read module: %w", err)
+	}
+
+	runtimeConfig := wazero.NewRuntimeConfig().
+		WithMemoryLimitPages(uint32(tool.MaxMemoryMB * 1024 * 1024 / wasmPageSize)).
+		WithCloseOnContextDone(true)
+	runtime := wazero.NewRuntimeWithConfig(ctx, runtimeConfig)
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, runtime); err != nil {
+		_ = runtime.Close(ctx)
+		return nil, fmt.Errorf("failed to provide WASI: %w", err)
+	}
+	compiled, err := runtime.CompileModule(ctx, code)
+	if err != nil {
+		_ = runtime.Close(ctx)
+		return nil, fmt.Errorf("failed to compile module: %w", err)
+	}
+	return &module{runtime: runtime, compiled: compiled, timeout: timeout}, nil
+}
+
+// ToolInfos describes the configured tools to the bridge
+func ToolInfos(tools map[string]config.WASMToolConfig) map[string]mcp.ToolInfo {
+	infos := make(map[string]mcp.ToolInfo, len(tools))
+	for name, tool := range tools {
+		infos[name] = mcp.ToolInfo{
+			ToolName:        name,
+			ToolDescription: tool.Description,
+			InputSchema:     tool.InputSchema,
+			ServerName:      ServerName,
+		}
+	}
+	return infos
+}
+
+// CallTool implements the bridge's client interface. Each call runs in a fresh
+// instance of the module, so calls share no state.
+func (c *Client) CallTool(ctx context.Context, toolName string, args map[string]interface{}) (string, error) {
+	m, ok := c.modules[toolName]
+	if !ok {
+		return "", fmt.Errorf("unknown WASM tool: %s. Available tools: %s", toolName, strings.Join(c.toolNames(), ", "))
+	}
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+	input, err := json.Marshal(args)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode arguments: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+	stdout := &limitedBuffer{limit: maxOutput}
+	stderr := &limitedBuffer{limit: 4096}
+	moduleConfig := wazero.NewModuleConfig().
+		WithName(""). // Unnamed, so concurrent calls can instantiate the module
+		WithArgs(toolName).
+		WithStdin(bytes.NewReader(input)).
+		WithStdout(stdout).
+		WithStderr(stderr)
+
+	instance, err := m.runtime.InstantiateModule(ctx, m.compiled, moduleConfig)
+	if instance != nil {
+		_ = instance.Close(context.Background())
+	}
+	var exitErr *sys.ExitError
+	switch {
+	case err == nil, errors.As(err, &exitErr) && exitErr.ExitCode() == 0:
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return "", fmt.Errorf("WASM tool '%s' did not finish within %s", toolName, m.timeout)
+	case ctx.Err() != nil:
+		return "", ctx.Err()
+	case errors.As(err, &exitErr):
+		return "", fmt.Errorf("WASM tool '%s' failed with exit code %d: %s", toolName, exitErr.ExitCode(), strings.TrimSpace(stderr.String()))
+	default:
+		return "", fmt.Errorf("WASM tool '%s' failed: %w", toolName, err)
+	}
+	if stdout.truncated {
+		return "", fmt.Errorf("WASM tool '%s' wrote more than %d bytes", toolName, maxOutput)
+	}
+	return stdout.String(), nil
+}
+
+// Close releases the compiled modules
+func (c *Client) Close(ctx context.Context) error {
+	var errs []error
+	for _, m := range c.modules {
+		if err := m.runtime.Close(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Client) toolNames() []string {
+	names := make([]string, 0, len(c.modules))
+	for name := range c.modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// limitedBuffer keeps at most limit bytes and notes when more were written
+type limitedBuffer struct {
+	bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.Len(); len(p) > room {
+		b.truncated = true
+		if room > 0 {
+			b.Buffer.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
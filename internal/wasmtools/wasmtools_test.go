@@ -0,0 +1,63 @@
+package wasmtools
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+// buildTestModule compiles testdata/upper for WASI
+func buildTestModule(t *testing.T) string {
+	if testing.Short() {
+		t.Skip("compiles a WASM module")
+	}
+	path := filepath.Join(t.TempDir(), "upper.wasm")
+	cmd := exec.Command("go", "build", "-o", path, "./testdata/upper")
+	cmd.Env = append(os.Environ(), "GOOS=wasip1", "GOARCH=wasm")
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+	return path
+}
+
+func TestWASMTool(t *testing.T) {
+	tools := map[string]config.WASMToolConfig{
+		"upper": {Path: buildTestModule(t), Description: "Upper-cases text", Timeout: "2s", MaxMemoryMB: 64},
+	}
+	client, err := NewClient(context.Background(), tools, logging.New("test", logging.LevelError))
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	result, err := client.CallTool(context.Background(), "upper", map[string]interface{}{"text": "hello"})
+	require.NoError(t, err)
+	assert.Equal(t, "HELLO", result)
+
+	_, err = client.CallTool(context.Background(), "upper", map[string]interface{}{"text": "fail"})
+	assert.EqualError(t, err, "WASM tool 'upper' failed with exit code 1: cannot convert")
+
+	_, err = client.CallTool(context.Background(), "upper", map[string]interface{}{"text": "spin"})
+	assert.EqualError(t, err, "WASM tool 'upper' did not finish within 2s")
+
+	_, err = client.CallTool(context.Background(), "lower", nil)
+	assert.EqualError(t, err, "unknown WASM tool: lower. Available tools: upper")
+
+	info := ToolInfos(tools)["upper"]
+	assert.Equal(t, ServerName, info.ServerName)
+	assert.Equal(t, "Upper-cases text", info.ToolDescription)
+}
+
+func TestNewClientReportsInvalidModules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.wasm")
+	require.NoError(t, os.WriteFile(path, []byte("not wasm"), 0o600))
+	_, err := NewClient(context.Background(), map[string]config.WASMToolConfig{
+		"broken": {Path: path, Timeout: "1s", MaxMemoryMB: 16},
+	}, logging.New("test", logging.LevelError))
+	assert.ErrorContains(t, err, "wasm tool 'broken': failed to compile module")
+}
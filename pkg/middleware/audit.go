@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// auditConfig configures the audit middleware
+type auditConfig struct {
+	Path           string `json:"path"`           // File the records are appended to as JSON lines (default: the log)
+	IncludeArgs    bool   `json:"includeArgs"`    // Record tool arguments
+	IncludeResults bool   `json:"includeResults"` // Record tool results and LLM answers
+	SkipLLM        bool   `json:"skipLLM"`        // Record tool calls only
+}
+
+// AuditRecord is one audited tool call or LLM call
+type AuditRecord struct {
+	Time       time.Time              `json:"time"`
+	Kind       string                 `json:"kind"` // "tool" or "llm"
+	UserID     string                 `json:"userId,omitempty"`
+	UserEmail  string                 `json:"userEmail,omitempty"`
+	Tool       string                 `json:"tool,omitempty"`
+	Server     string                 `json:"server,omitempty"`
+	Provider   string                 `json:"provider,omitempty"`
+	Model      string                 `json:"model,omitempty"`
+	Agent      bool                   `json:"agent,omitempty"`
+	DurationMS int64                  `json:"durationMs"`
+	Error      string                 `json:"error,omitempty"`
+	Args       map[string]interface{} `json:"args,omitempty"`
+	Result     string                 `json:"result,omitempty"`
+}
+
+// audit records who called which tool or model, when, for how long and with what outcome
+type audit struct {
+	cfg    auditConfig
+	logger Logger
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+func init() {
+	if err := Register("audit", newAudit); err != nil {
+		panic(err)
+	}
+}
+
+func newAudit(config map[string]interface{}, logger Logger) (Middleware, error) {
+	var cfg auditConfig
+	if err := DecodeConfig(config, &cfg); err != nil {
+		return nil, err
+	}
+	a := &audit{cfg: cfg, logger: logger}
+	if cfg.Path != "" {
+		file, err := os.OpenFile(cfg.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open audit log: %w", err)
+		}
+		a.file = file
+	}
+	return a, nil
+}
+
+func (a *audit) record(ctx context.Context, record AuditRecord, start time.Time, err error) {
+	record.Time = start.UTC()
+	record.DurationMS = time.Since(start).Milliseconds()
+	record.UserID, record.UserEmail, _ = UserFromContext(ctx)
+	if err != nil {
+		record.Error = err.Error()
+	}
+
+	if a.file == nil {
+		a.logger.InfoKV("Audit", "kind", record.Kind, "user", record.UserID, "tool", record.Tool, "provider", record.Provider,
+			"duration_ms", record.DurationMS, "error", record.Error)
+		return
+	}
+	line, marshalErr := json.Marshal(record)
+	if marshalErr != nil {
+		a.logger.WarnKV("Failed to encode audit record", "kind", record.Kind, "error", marshalErr)
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, writeErr := a.file.Write(append(line, '\n')); writeErr != nil {
+		a.logger.ErrorKV("Failed to write audit record", "path", a.cfg.Path, "error", writeErr)
+	}
+}
+
+func (a *audit) WrapTool(next ToolHandler) ToolHandler {
+	return func(ctx context.Context, call *ToolCall) (string, error) {
+		start := time.Now()
+		result, err := next(ctx, call)
+		record := AuditRecord{Kind: "tool", Tool: call.Tool, Server: call.Server}
+		if a.cfg.IncludeArgs {
+			record.Args = call.Args
+		}
+		if a.cfg.IncludeResults {
+			record.Result = result
+		}
+		a.record(ctx, record, start, err)
+		return result, err
+	}
+}
+
+func (a *audit) WrapLLM(next LLMHandler) LLMHandler {
+	if a.cfg.SkipLLM {
+		return next
+	}
+	return func(ctx context.Context, call *LLMCall) (*llms.ContentChoice, error) {
+		start := time.Now()
+		choice, err := next(ctx, call)
+		record := AuditRecord{Kind: "llm", Provider: call.Provider, Model: call.Model, Agent: call.Agent}
+		if a.cfg.IncludeResults && choice != nil {
+			record.Result = choice.Content
+		}
+		a.record(ctx, record, start, err)
+		return choice, err
+	}
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+)
+
+func TestAuditWritesJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	m, err := New("audit", map[string]interface{}{"path": path, "includeArgs": true}, testLogger())
+	require.NoError(t, err)
+	chain := NewChain(m)
+	ctx := mcp.ContextWithIdentity(context.Background(), mcp.Identity{UserID: "U1", Email: "a@example.com"})
+
+	_, err = chain.RunTool(ctx, &ToolCall{Tool: "search", Server: "github", Args: map[string]interface{}{"q": "bug"}}, func(context.Context, *ToolCall) (string, error) {
+		return "", errors.New("rate limited")
+	})
+	require.Error(t, err)
+	_, err = chain.RunLLM(ctx, &LLMCall{Provider: "openai", Model: "gpt-4o-mini"}, func(context.Context, *LLMCall) (*llms.ContentChoice, error) {
+		return &llms.ContentChoice{Content: "answer"}, nil
+	})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+
+	var tool, llm AuditRecord
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &tool))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &llm))
+	assert.Equal(t, "tool", tool.Kind)
+	assert.Equal(t, "U1", tool.UserID)
+	assert.Equal(t, "a@example.com", tool.UserEmail)
+	assert.Equal(t, "github", tool.Server)
+	assert.Equal(t, "rate limited", tool.Error)
+	assert.Equal(t, map[string]interface{}{"q": "bug"}, tool.Args)
+	assert.Equal(t, "llm", llm.Kind)
+	assert.Equal(t, "gpt-4o-mini", llm.Model)
+	assert.Empty(t, llm.Result, "results are only recorded when includeResults is set")
+}
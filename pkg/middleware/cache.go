@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+)
+
+// cacheConfig configures the cache middleware
+type cacheConfig struct {
+	Tools      []string `json:"tools"`      // Tools whose results are cached, as path.Match patterns, e.g. "jira_get_*"
+	TTL        string   `json:"ttl"`        // How long a result is reused (default: "5m")
+	MaxEntries int      `json:"maxEntries"` // Most results kept; the oldest is dropped first (default: 1000)
+}
+
+type cacheEntry struct {
+	result  string
+	expires time.Time
+}
+
+// cache reuses the results of read-only tools called again with the same
+// arguments by the same user. Only the listed tools are cached, since calling a
+// tool that changes something must not be skipped. Failed calls are not cached.
+type cache struct {
+	Base
+	tools      []string
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	order   []string // Keys in insertion order, for dropping the oldest
+	now     func() time.Time
+}
+
+func init() {
+	if err := Register("cache", newCache); err != nil {
+		panic(err)
+	}
+}
+
+func newCache(config map[string]interface{}, _ Logger) (Middleware, error) {
+	cfg := cacheConfig{TTL: "5m", MaxEntries: 1000}
+	if err := DecodeConfig(config, &cfg); err != nil {
+		return nil, err
+	}
+	if len(cfg.Tools) == 0 {
+		return nil, fmt.Errorf("tools must list the tools whose results are cached")
+	}
+	for _, pattern := range cfg.Tools {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid tool pattern %q: %w", pattern, err)
+		}
+	}
+	ttl, err := time.ParseDuration(cfg.TTL)
+	if err != nil || ttl <= 0 {
+		return nil, fmt.Errorf("ttl must be a positive duration, got %q", cfg.TTL)
+	}
+	if cfg.MaxEntries <= 0 {
+		return nil, fmt.Errorf("maxEntries must be positive, got %d", cfg.MaxEntries)
+	}
+	return &cache{
+		tools:      cfg.Tools,
+		ttl:        ttl,
+		maxEntries: cfg.MaxEntries,
+		entries:    make(map[string]cacheEntry),
+		now:        time.Now,
+	}, nil
+}
+
+func (c *cache) cached(tool string) bool {
+	return matchesAny(c.tools, tool)
+}
+
+func (c *cache) WrapTool(next ToolHandler) ToolHandler {
+	return func(ctx context.Context, call *ToolCall) (string, error) {
+		if !c.cached(call.Tool) {
+			return next(ctx, call)
+		}
+		// Map keys are marshaled in sorted order, so equal arguments give equal keys
+		args, err := json.Marshal(call.Args)
+		if err != nil {
+			return next(ctx, call)
+		}
+		userID, _, _ := UserFromContext(ctx)
+		key := userID + "\x00" + call.Server + "\x00" + call.Tool + "\x00" + string(args)
+
+		c.mu.Lock()
+		entry, hit := c.entries[key]
+		c.mu.Unlock()
+		if hit && c.now().Before(entry.expires) {
+			return entry.result, nil
+		}
+
+		result, err := next(ctx, call)
+		if err == nil {
+			c.store(key, result)
+		}
+		return result, err
+	}
+}
+
+func (c *cache) store(key, result string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.entries[key]; !exists {
+		c.order = append(c.order, key)
+	}
+	c.entries[key] = cacheEntry{result: result, expires: c.now().Add(c.ttl)}
+	for len(c.order) > c.maxEntries {
+		delete(c.entries, c.order[0])
+		c.order = c.order[1:]
+	}
+}
+
+// matchesAny reports whether name matches one of the path.Match patterns
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+)
+
+func TestCache(t *testing.T) {
+	m, err := New("cache", map[string]interface{}{"tools": []string{"jira_get_*"}, "ttl": "1m", "maxEntries": 2}, testLogger())
+	require.NoError(t, err)
+	c := m.(*cache)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+	chain := NewChain(c)
+
+	calls := 0
+	tool := func(_ context.Context, call *ToolCall) (string, error) {
+		calls++
+		if call.Args["key"] == "BAD" {
+			return "", errors.New("not found")
+		}
+		return call.Args["key"].(string), nil
+	}
+	run := func(ctx context.Context, name, key string) (string, error) {
+		return chain.RunTool(ctx, &ToolCall{Tool: name, Server: "jira", Args: map[string]interface{}{"key": key}}, tool)
+	}
+	alice := mcp.ContextWithIdentity(context.Background(), mcp.Identity{UserID: "U1"})
+	bob := mcp.ContextWithIdentity(context.Background(), mcp.Identity{UserID: "U2"})
+
+	result, err := run(alice, "jira_get_issue", "A-1")
+	require.NoError(t, err)
+	assert.Equal(t, "A-1", result)
+	_, _ = run(alice, "jira_get_issue", "A-1")
+	assert.Equal(t, 1, calls, "the second call is answered from the cache")
+
+	_, _ = run(bob, "jira_get_issue", "A-1")
+	assert.Equal(t, 2, calls, "results are cached per user")
+
+	_, _ = run(alice, "jira_create_issue", "A-1")
+	_, _ = run(alice, "jira_create_issue", "A-1")
+	assert.Equal(t, 4, calls, "unlisted tools are not cached")
+
+	_, _ = run(alice, "jira_get_issue", "BAD")
+	_, err = run(alice, "jira_get_issue", "BAD")
+	assert.Error(t, err)
+	assert.Equal(t, 6, calls, "failures are not cached")
+
+	now = now.Add(2 * time.Minute)
+	_, _ = run(alice, "jira_get_issue", "A-1")
+	assert.Equal(t, 7, calls, "expired results are fetched again")
+	assert.LessOrEqual(t, len(c.entries), 2)
+}
+
+func TestCacheConfig(t *testing.T) {
+	_, err := New("cache", nil, testLogger())
+	assert.ErrorContains(t, err, "tools must list")
+
+	_, err = New("cache", map[string]interface{}{"tools": []string{"*"}, "ttl": "soon"}, testLogger())
+	assert.ErrorContains(t, err, "ttl must be a positive duration")
+}
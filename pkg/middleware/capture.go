@@ -0,0 +1,336 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// Defaults of the capture middleware
+const (
+	defaultCaptureSize     = 200
+	defaultCaptureMaxChars = 20000
+)
+
+// captureConfig configures the capture middleware
+type captureConfig struct {
+	Size         int                `json:"size"`         // Captures kept, oldest dropped first (default: 200)
+	SampleRate   *float64           `json:"sampleRate"`   // Share of calls captured, from 0 to 1 (default: 1)
+	Providers    map[string]float64 `json:"providers"`    // Sample rate of the LLM calls of each provider, overriding sampleRate
+	SkipTools    bool               `json:"skipTools"`    // Capture LLM calls only
+	Patterns     []string           `json:"patterns"`     // Extra regular expressions to redact
+	SkipDefaults bool               `json:"skipDefaults"` // Redact only the extra patterns
+	MaxChars     int                `json:"maxChars"`     // Characters kept of each prompt, response, argument list and result (default: 20000)
+	Token        string             `json:"token"`        // Bearer token required to read the captures (default: none, captures are served to localhost only)
+}
+
+// CapturedMessage is a message of the history sent to the LLM
+type CapturedMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// CapturedToolCall is a tool call requested in an LLM response
+type CapturedToolCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// Capture is a redacted LLM call or tool call, kept for debugging
+type Capture struct {
+	ID           int64              `json:"id"`
+	Time         time.Time          `json:"time"`
+	Kind         string             `json:"kind"` // "llm" or "tool"
+	UserID       string             `json:"userId,omitempty"`
+	Provider     string             `json:"provider,omitempty"`
+	Model        string             `json:"model,omitempty"`
+	Agent        bool               `json:"agent,omitempty"`
+	SystemPrompt string             `json:"systemPrompt,omitempty"`
+	History      []CapturedMessage  `json:"history,omitempty"`
+	Prompt       string             `json:"prompt,omitempty"`
+	Response     string             `json:"response,omitempty"`
+	ToolCalls    []CapturedToolCall `json:"toolCalls,omitempty"`
+	Tool         string             `json:"tool,omitempty"`
+	Server       string             `json:"server,omitempty"`
+	Args         string             `json:"args,omitempty"`
+	Result       string             `json:"result,omitempty"`
+	Error        string             `json:"error,omitempty"`
+	DurationMS   int64              `json:"durationMs"`
+}
+
+// captureBuffer keeps the latest captures in a ring
+type captureBuffer struct {
+	mu       sync.Mutex
+	captures []Capture
+	next     int   // Position of the next capture once the ring is full
+	lastID   int64 // ID of the latest capture
+	token    string
+}
+
+// captures is the buffer of the capture middleware, served by CapturesHandler.
+// A middleware created on a config reload takes over the earlier captures.
+var captures atomic.Pointer[captureBuffer]
+
+// useCaptureBuffer returns a buffer of size captures holding the latest earlier ones
+func useCaptureBuffer(size int, token string) *captureBuffer {
+	buffer := &captureBuffer{token: token}
+	if previous := captures.Load(); previous != nil {
+		kept := previous.list(size)
+		for i := len(kept) - 1; i >= 0; i-- {
+			buffer.captures = append(buffer.captures, kept[i])
+		}
+		buffer.lastID = previous.latestID()
+	}
+	buffer.captures = append(make([]Capture, 0, size), buffer.captures...)
+	captures.Store(buffer)
+	return buffer
+}
+
+// add appends a capture, replacing the oldest one when the buffer is full
+func (b *captureBuffer) add(capture Capture) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lastID++
+	capture.ID = b.lastID
+	if len(b.captures) < cap(b.captures) {
+		b.captures = append(b.captures, capture)
+		return
+	}
+	b.captures[b.next] = capture
+	b.next = (b.next + 1) % len(b.captures)
+}
+
+// list returns up to limit captures, newest first, or all of them when limit is 0
+func (b *captureBuffer) list(limit int) []Capture {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if limit <= 0 || limit > len(b.captures) {
+		limit = len(b.captures)
+	}
+	result := make([]Capture, 0, limit)
+	for i := 0; i < limit; i++ {
+		index := (b.next - 1 - i + 2*len(b.captures)) % len(b.captures)
+		result = append(result, b.captures[index])
+	}
+	return result
+}
+
+func (b *captureBuffer) latestID() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.lastID
+}
+
+// capture records sampled, redacted LLM calls and tool calls in a ring buffer,
+// so answers can be investigated without enabling debug logging
+type capture struct {
+	cfg      captureConfig
+	redactor *redactor
+	buffer   *captureBuffer
+	sample   func() float64
+}
+
+func init() {
+	if err := Register("capture", newCapture); err != nil {
+		panic(err)
+	}
+}
+
+func newCapture(config map[string]interface{}, _ Logger) (Middleware, error) {
+	var cfg captureConfig
+	if err := DecodeConfig(config, &cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Size < 0 || cfg.MaxChars < 0 {
+		return nil, fmt.Errorf("size and maxChars must not be negative")
+	}
+	if cfg.Size == 0 {
+		cfg.Size = defaultCaptureSize
+	}
+	if cfg.MaxChars == 0 {
+		cfg.MaxChars = defaultCaptureMaxChars
+	}
+	if cfg.SampleRate == nil {
+		rate := 1.0
+		cfg.SampleRate = &rate
+	}
+	rates := map[string]float64{"sampleRate": *cfg.SampleRate}
+	for provider, rate := range cfg.Providers {
+		rates["providers."+provider] = rate
+	}
+	for name, rate := range rates {
+		if rate < 0 || rate > 1 {
+			return nil, fmt.Errorf("%s must be between 0 and 1, got %v", name, rate)
+		}
+	}
+	r, err := newRedactor(cfg.Patterns, cfg.SkipDefaults, "")
+	if err != nil {
+		return nil, err
+	}
+	return &capture{cfg: cfg, redactor: r, buffer: useCaptureBuffer(cfg.Size, cfg.Token), sample: rand.Float64}, nil
+}
+
+// sampled decides whether to capture a call made at rate
+func (c *capture) sampled(rate float64) bool {
+	return rate > 0 && (rate >= 1 || c.sample() < rate)
+}
+
+// text redacts and truncates a captured text
+func (c *capture) text(text string) string {
+	text = c.redactor.redact(text)
+	if runes := []rune(text); len(runes) > c.cfg.MaxChars {
+		return string(runes[:c.cfg.MaxChars]) + "… (truncated)"
+	}
+	return text
+}
+
+// record completes a capture and adds it to the buffer
+func (c *capture) record(ctx context.Context, capture Capture, start time.Time, err error) {
+	capture.Time = start.UTC()
+	capture.DurationMS = time.Since(start).Milliseconds()
+	capture.UserID, _, _ = UserFromContext(ctx)
+	if err != nil {
+		capture.Error = c.text(err.Error())
+	}
+	c.buffer.add(capture)
+}
+
+func (c *capture) WrapTool(next ToolHandler) ToolHandler {
+	if c.cfg.SkipTools {
+		return next
+	}
+	return func(ctx context.Context, call *ToolCall) (string, error) {
+		if !c.sampled(*c.cfg.SampleRate) {
+			return next(ctx, call)
+		}
+		capture := Capture{Kind: "tool", Tool: call.Tool, Server: call.Server}
+		if args, err := json.Marshal(call.Args); err == nil {
+			capture.Args = c.text(string(args))
+		}
+		start := time.Now()
+		result, err := next(ctx, call)
+		capture.Result = c.text(result)
+		c.record(ctx, capture, start, err)
+		return result, err
+	}
+}
+
+func (c *capture) WrapLLM(next LLMHandler) LLMHandler {
+	return func(ctx context.Context, call *LLMCall) (*llms.ContentChoice, error) {
+		rate, ok := c.cfg.Providers[call.Provider]
+		if !ok {
+			rate = *c.cfg.SampleRate
+		}
+		if !c.sampled(rate) {
+			return next(ctx, call)
+		}
+		// The request as this middleware passes it on
+		capture := Capture{
+			Kind:         "llm",
+			Provider:     call.Provider,
+			Model:        call.Model,
+			Agent:        call.Agent,
+			SystemPrompt: c.text(call.SystemPrompt),
+			Prompt:       c.text(call.Prompt),
+		}
+		for _, message := range call.History {
+			capture.History = append(capture.History, CapturedMessage{Role: message.Role, Content: c.text(message.Content)})
+		}
+		start := time.Now()
+		choice, err := next(ctx, call)
+		if choice != nil {
+			capture.Response = c.text(choice.Content)
+			for _, toolCall := range choice.ToolCalls {
+				if toolCall.FunctionCall != nil {
+					capture.ToolCalls = append(capture.ToolCalls, CapturedToolCall{
+						Name:      toolCall.FunctionCall.Name,
+						Arguments: c.text(toolCall.FunctionCall.Arguments),
+					})
+				}
+			}
+		}
+		c.record(ctx, capture, start, err)
+		return choice, err
+	}
+}
+
+// CapturesHandler serves the captures of the capture middleware as JSON, newest
+// first. The kind, provider, tool and user query parameters filter them, and
+// limit caps their number (default: 50). It answers 404 until the middleware is
+// enabled, and 401 without the configured token. Without a token, captures are
+// only served to requests from the same host, since they hold conversations.
+func CapturesHandler(w http.ResponseWriter, r *http.Request) {
+	buffer := captures.Load()
+	if buffer == nil {
+		http.Error(w, "debug captures are disabled; enable the capture middleware", http.StatusNotFound)
+		return
+	}
+	if buffer.token == "" && !fromLoopback(r) {
+		http.Error(w, "debug captures are only served to localhost; set the capture middleware's token to read them remotely", http.StatusForbidden)
+		return
+	}
+	if buffer.token != "" {
+		expected := "Bearer " + buffer.token
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(expected)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+	}
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	limit := 50
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "limit must be a positive number", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+	filters := map[string]func(Capture) string{
+		"kind":     func(c Capture) string { return c.Kind },
+		"provider": func(c Capture) string { return c.Provider },
+		"tool":     func(c Capture) string { return c.Tool },
+		"user":     func(c Capture) string { return c.UserID },
+	}
+	result := []Capture{}
+	for _, capture := range buffer.list(0) {
+		matches := true
+		for name, field := range filters {
+			if value := query.Get(name); value != "" && field(capture) != value {
+				matches = false
+			}
+		}
+		if matches && len(result) < limit {
+			result = append(result, capture)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"captures": result})
+}
+
+// fromLoopback reports whether a request comes from the same host
+func fromLoopback(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,191 @@
+// Package middleware defines the hooks that run around the bridge's tool calls and
+// LLM calls. Middlewares are registered by name, usually from the init() function of
+// the package that implements them, and enabled in the "middlewares" list of the
+// configuration. The built-in redaction, cache, audit and rbac middlewares are
+// registered the same way, so an organization can add its own in a Go package that
+// is compiled into the binary instead of forking the bridge.
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/tmc/langchaingo/llms"
+
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+)
+
+// ToolCall is a tool call on its way to an MCP server. Middlewares may modify Args.
+type ToolCall struct {
+	Tool   string                 // Tool name as offered to the LLM
+	Server string                 // MCP server that provides the tool
+	Args   map[string]interface{} // Arguments of the call
+}
+
+// ToolHandler executes a tool call and returns its result
+type ToolHandler func(ctx context.Context, call *ToolCall) (string, error)
+
+// Message is a message of the conversation sent to the LLM
+type Message struct {
+	Role    string
+	Content string
+
+	// Set on "tool" messages, which hold the result of an earlier tool call
+	ToolName   string // Tool that was called
+	ToolArgs   string // Arguments of the call as JSON
+	ToolCallID string // Pairs the result with its call
+}
+
+// LLMCall is a request on its way to the LLM provider. Middlewares may modify the
+// prompts and history.
+type LLMCall struct {
+	Provider     string    // Provider that answers the call
+	Model        string    // Model override chosen for the request, empty for the provider's default
+	Agent        bool      // The call runs the agent, which calls tools on its own
+	SystemPrompt string    // System prompt, including the tool instructions when tools are offered
+	History      []Message // Earlier conversation
+	Prompt       string    // The user's prompt
+}
+
+// LLMHandler sends an LLM call and returns the completion. Agent calls return only
+// the final answer in Content.
+type LLMHandler func(ctx context.Context, call *LLMCall) (*llms.ContentChoice, error)
+
+// Middleware wraps the handlers of tool calls and LLM calls. Code before calling next
+// runs before the call, code after it sees the result; not calling next stops the call.
+type Middleware interface {
+	WrapTool(next ToolHandler) ToolHandler
+	WrapLLM(next LLMHandler) LLMHandler
+}
+
+// Base passes every call through; embed it to implement only one of the hooks
+type Base struct{}
+
+// WrapTool returns next unchanged
+func (Base) WrapTool(next ToolHandler) ToolHandler { return next }
+
+// WrapLLM returns next unchanged
+func (Base) WrapLLM(next LLMHandler) LLMHandler { return next }
+
+// Logger is the logging interface handed to middleware factories
+type Logger interface {
+	DebugKV(msg string, keyValues ...interface{})
+	InfoKV(msg string, keyValues ...interface{})
+	WarnKV(msg string, keyValues ...interface{})
+	ErrorKV(msg string, keyValues ...interface{})
+}
+
+// Factory creates a middleware from the "config" object of its configuration entry
+type Factory func(config map[string]interface{}, logger Logger) (Middleware, error)
+
+// Global map to store middleware factories
+var (
+	factories = make(map[string]Factory)
+	mu        sync.RWMutex
+)
+
+// Register registers a factory under the name used in the configuration.
+// This should be called from the init() function of the middleware implementation.
+func Register(name string, factory Factory) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, exists := factories[name]; exists {
+		return fmt.Errorf("middleware factory for '%s' already registered", name)
+	}
+	factories[name] = factory
+	return nil
+}
+
+// New creates the middleware registered under name
+func New(name string, config map[string]interface{}, logger Logger) (Middleware, error) {
+	mu.RLock()
+	factory, exists := factories[name]
+	mu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("unknown middleware '%s' (registered: %v)", name, List())
+	}
+	m, err := factory(config, logger)
+	if err != nil {
+		return nil, fmt.Errorf("middleware '%s': %w", name, err)
+	}
+	return m, nil
+}
+
+// List returns the names of all registered middlewares, sorted
+func List() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Chain runs calls through a list of middlewares. The first middleware is the
+// outermost: it sees the call first and the result last. A nil Chain runs calls
+// unchanged.
+type Chain struct {
+	middlewares []Middleware
+}
+
+// NewChain returns a chain of the given middlewares
+func NewChain(middlewares ...Middleware) *Chain {
+	return &Chain{middlewares: middlewares}
+}
+
+// Len returns the number of middlewares in the chain
+func (c *Chain) Len() int {
+	if c == nil {
+		return 0
+	}
+	return len(c.middlewares)
+}
+
+// RunTool runs a tool call through the chain, ending with final
+func (c *Chain) RunTool(ctx context.Context, call *ToolCall, final ToolHandler) (string, error) {
+	handler := final
+	for i := c.Len() - 1; i >= 0; i-- {
+		handler = c.middlewares[i].WrapTool(handler)
+	}
+	return handler(ctx, call)
+}
+
+// RunLLM runs an LLM call through the chain, ending with final
+func (c *Chain) RunLLM(ctx context.Context, call *LLMCall, final LLMHandler) (*llms.ContentChoice, error) {
+	handler := final
+	for i := c.Len() - 1; i >= 0; i-- {
+		handler = c.middlewares[i].WrapLLM(handler)
+	}
+	return handler(ctx, call)
+}
+
+// UserFromContext returns the Slack user ID and email of the user whose request
+// made the call, when known
+func UserFromContext(ctx context.Context) (userID, email string, ok bool) {
+	identity, ok := mcp.IdentityFromContext(ctx)
+	return identity.UserID, identity.Email, ok
+}
+
+// DecodeConfig decodes a middleware's "config" object into target, a pointer to a
+// struct with json tags. Unknown fields are rejected so typos do not go unnoticed.
+func DecodeConfig(config map[string]interface{}, target interface{}) error {
+	if len(config) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(target); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+)
+
+// tracer records the order in which middlewares see calls and results
+type tracer struct {
+	name  string
+	trace *[]string
+}
+
+func (t tracer) WrapTool(next ToolHandler) ToolHandler {
+	return func(ctx context.Context, call *ToolCall) (string, error) {
+		*t.trace = append(*t.trace, t.name+" before")
+		result, err := next(ctx, call)
+		*t.trace = append(*t.trace, t.name+" after")
+		return result + " " + t.name, err
+	}
+}
+
+func (t tracer) WrapLLM(next LLMHandler) LLMHandler {
+	return func(ctx context.Context, call *LLMCall) (*llms.ContentChoice, error) {
+		call.Prompt += " " + t.name
+		return next(ctx, call)
+	}
+}
+
+func testLogger() *logging.Logger {
+	return logging.New("middleware-test", logging.LevelError)
+}
+
+func TestChainOrder(t *testing.T) {
+	var trace []string
+	chain := NewChain(tracer{name: "outer", trace: &trace}, tracer{name: "inner", trace: &trace})
+
+	result, err := chain.RunTool(context.Background(), &ToolCall{Tool: "search"}, func(context.Context, *ToolCall) (string, error) {
+		trace = append(trace, "tool")
+		return "result", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "result inner outer", result)
+	assert.Equal(t, []string{"outer before", "inner before", "tool", "inner after", "outer after"}, trace)
+
+	choice, err := chain.RunLLM(context.Background(), &LLMCall{Prompt: "hi"}, func(_ context.Context, call *LLMCall) (*llms.ContentChoice, error) {
+		return &llms.ContentChoice{Content: call.Prompt}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "hi outer inner", choice.Content)
+}
+
+func TestNilChainRunsCallsUnchanged(t *testing.T) {
+	var chain *Chain
+	assert.Equal(t, 0, chain.Len())
+	result, err := chain.RunTool(context.Background(), &ToolCall{}, func(context.Context, *ToolCall) (string, error) {
+		return "result", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "result", result)
+}
+
+func TestRegistry(t *testing.T) {
+	assert.Subset(t, List(), []string{"audit", "cache", "rbac", "redaction"})
+	assert.Error(t, Register("audit", newAudit), "names are registered once")
+
+	_, err := New("missing", nil, testLogger())
+	assert.ErrorContains(t, err, "unknown middleware 'missing'")
+
+	_, err = New("cache", map[string]interface{}{"tool": []string{"search"}}, testLogger())
+	assert.ErrorContains(t, err, "middleware 'cache': invalid config")
+}
+
+func TestUserFromContext(t *testing.T) {
+	_, _, ok := UserFromContext(context.Background())
+	assert.False(t, ok)
+
+	userID, email, ok := UserFromContext(mcp.ContextWithIdentity(context.Background(), mcp.Identity{UserID: "U1", Email: "a@example.com"}))
+	assert.True(t, ok)
+	assert.Equal(t, "U1", userID)
+	assert.Equal(t, "a@example.com", email)
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ErrForbidden is returned for a tool call the requesting user may not make
+var ErrForbidden = errors.New("not permitted")
+
+// rbacRule lets the listed users call the matching tools
+type rbacRule struct {
+	Tools []string `json:"tools"` // path.Match patterns of tool names, e.g. "jira_*"
+	Users []string `json:"users"` // Slack user IDs or emails
+}
+
+// rbacConfig configures the rbac middleware
+type rbacConfig struct {
+	Rules       []rbacRule `json:"rules"`
+	DefaultDeny bool       `json:"defaultDeny"` // Deny tools that no rule matches (default: allow them)
+}
+
+// rbac restricts tools to users. A tool that matches rules may only be called by
+// the users of those rules; other tools are allowed unless defaultDeny is set.
+type rbac struct {
+	Base
+	cfg rbacConfig
+}
+
+func init() {
+	if err := Register("rbac", newRBAC); err != nil {
+		panic(err)
+	}
+}
+
+func newRBAC(config map[string]interface{}, _ Logger) (Middleware, error) {
+	var cfg rbacConfig
+	if err := DecodeConfig(config, &cfg); err != nil {
+		return nil, err
+	}
+	for i, rule := range cfg.Rules {
+		if len(rule.Tools) == 0 {
+			return nil, fmt.Errorf("rules[%d] lists no tools", i)
+		}
+		for _, pattern := range rule.Tools {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("rules[%d]: invalid tool pattern %q: %w", i, pattern, err)
+			}
+		}
+	}
+	return &rbac{cfg: cfg}, nil
+}
+
+// allowed reports whether the user may call the tool
+func (r *rbac) allowed(tool, userID, email string) bool {
+	matched := false
+	for _, rule := range r.cfg.Rules {
+		if !matchesAny(rule.Tools, tool) {
+			continue
+		}
+		matched = true
+		for _, user := range rule.Users {
+			if user == userID || (email != "" && strings.EqualFold(user, email)) {
+				return true
+			}
+		}
+	}
+	return !matched && !r.cfg.DefaultDeny
+}
+
+func (r *rbac) WrapTool(next ToolHandler) ToolHandler {
+	return func(ctx context.Context, call *ToolCall) (string, error) {
+		userID, email, _ := UserFromContext(ctx)
+		if !r.allowed(call.Tool, userID, email) {
+			return "", fmt.Errorf("tool '%s' is %w for this user", call.Tool, ErrForbidden)
+		}
+		return next(ctx, call)
+	}
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+)
+
+func TestRBAC(t *testing.T) {
+	config := map[string]interface{}{
+		"rules": []map[string]interface{}{
+			{"tools": []string{"k8s_*"}, "users": []string{"U1", "sre@example.com"}},
+		},
+	}
+	m, err := New("rbac", config, testLogger())
+	require.NoError(t, err)
+	chain := NewChain(m)
+	run := func(identity mcp.Identity, tool string) error {
+		_, err := chain.RunTool(mcp.ContextWithIdentity(context.Background(), identity), &ToolCall{Tool: tool}, func(context.Context, *ToolCall) (string, error) {
+			return "ok", nil
+		})
+		return err
+	}
+
+	assert.NoError(t, run(mcp.Identity{UserID: "U1"}, "k8s_delete_pod"))
+	assert.NoError(t, run(mcp.Identity{UserID: "U3", Email: "SRE@example.com"}, "k8s_delete_pod"), "users match by email too")
+	err = run(mcp.Identity{UserID: "U2"}, "k8s_delete_pod")
+	assert.True(t, errors.Is(err, ErrForbidden))
+	assert.EqualError(t, err, "tool 'k8s_delete_pod' is not permitted for this user")
+	assert.NoError(t, run(mcp.Identity{UserID: "U2"}, "search"), "tools no rule matches are allowed")
+
+	config["defaultDeny"] = true
+	m, err = New("rbac", config, testLogger())
+	require.NoError(t, err)
+	chain = NewChain(m)
+	assert.ErrorIs(t, run(mcp.Identity{UserID: "U2"}, "search"), ErrForbidden)
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// defaultRedactionPatterns match secrets and personal data that should not reach
+// the LLM provider
+var defaultRedactionPatterns = []string{
+	`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`, // Email addresses
+	`\bxox[abposr]-[A-Za-z0-9\-]{10,}`,                 // Slack tokens
+	`\b(?:sk|pk|rk)-[A-Za-z0-9_\-]{20,}`,               // API keys such as OpenAI's
+	`\bgh[pousr]_[A-Za-z0-9]{36,}`,                     // GitHub tokens
+	`\bAKIA[0-9A-Z]{16}\b`,                             // AWS access key IDs
+	`(?i)\bbearer\s+[A-Za-z0-9._~+/\-]+=*`,             // Bearer tokens
+}
+
+// redactionConfig configures the redaction middleware
+type redactionConfig struct {
+	Patterns        []string `json:"patterns"`        // Extra regular expressions to redact
+	SkipDefaults    bool     `json:"skipDefaults"`    // Redact only the extra patterns
+	Replacement     string   `json:"replacement"`     // Replacement text (default: "[REDACTED]")
+	SkipToolResults bool     `json:"skipToolResults"` // Leave tool results as they are
+}
+
+// redaction replaces matches of its patterns in the prompts and history sent to
+// the LLM and in tool results, which are passed on to the LLM and the user
+type redaction struct {
+	*redactor
+	skipToolResults bool
+}
+
+// redactor replaces matches of patterns with a replacement text
+type redactor struct {
+	patterns    []*regexp.Regexp
+	replacement string
+}
+
+func init() {
+	if err := Register("redaction", newRedaction); err != nil {
+		panic(err)
+	}
+}
+
+func newRedaction(config map[string]interface{}, _ Logger) (Middleware, error) {
+	var cfg redactionConfig
+	if err := DecodeConfig(config, &cfg); err != nil {
+		return nil, err
+	}
+	r, err := newRedactor(cfg.Patterns, cfg.SkipDefaults, cfg.Replacement)
+	if err != nil {
+		return nil, err
+	}
+	return &redaction{redactor: r, skipToolResults: cfg.SkipToolResults}, nil
+}
+
+// newRedactor compiles the default patterns, unless skipDefaults is set, and the
+// extra patterns
+func newRedactor(patterns []string, skipDefaults bool, replacement string) (*redactor, error) {
+	sources := patterns
+	if !skipDefaults {
+		sources = append(append([]string{}, defaultRedactionPatterns...), patterns...)
+	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no patterns to redact")
+	}
+	r := &redactor{replacement: replacement}
+	if r.replacement == "" {
+		r.replacement = "[REDACTED]"
+	}
+	for _, source := range sources {
+		pattern, err := regexp.Compile(source)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", source, err)
+		}
+		r.patterns = append(r.patterns, pattern)
+	}
+	return r, nil
+}
+
+func (r *redactor) redact(text string) string {
+	for _, pattern := range r.patterns {
+		text = pattern.ReplaceAllString(text, r.replacement)
+	}
+	return text
+}
+
+func (r *redaction) WrapTool(next ToolHandler) ToolHandler {
+	if r.skipToolResults {
+		return next
+	}
+	return func(ctx context.Context, call *ToolCall) (string, error) {
+		result, err := next(ctx, call)
+		return r.redact(result), err
+	}
+}
+
+func (r *redaction) WrapLLM(next LLMHandler) LLMHandler {
+	return func(ctx context.Context, call *LLMCall) (*llms.ContentChoice, error) {
+		call.Prompt = r.redact(call.Prompt)
+		for i := range call.History {
+			call.History[i].Content = r.redact(call.History[i].Content)
+		}
+		return next(ctx, call)
+	}
+}
@@ -0,0 +1,285 @@
+// Package slackmcp embeds the Slack MCP client in another Go service. The client
+// runs the same bridge as the slack-mcp-client binary: it connects the configured
+// MCP servers, LLM providers and knowledge base to Slack. Services build it from a
+// Config, adjusted with options, and can offer their own tools next to the MCP
+// servers' tools:
+//
+//	client, err := slackmcp.New(
+//		slackmcp.WithConfigFile("config.json"),
+//		slackmcp.WithTool(slackmcp.Tool{Name: "deploy_status", Description: "...", Handler: deployStatus}),
+//	)
+//	if err != nil {
+//		return err
+//	}
+//	return client.Run(ctx)
+package slackmcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/tuannvm/slack-mcp-client/internal/app"
+	"github.com/tuannvm/slack-mcp-client/internal/common/logging"
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+	"github.com/tuannvm/slack-mcp-client/internal/mcp"
+	slackbot "github.com/tuannvm/slack-mcp-client/internal/slack"
+)
+
+// Config is the configuration of the client, as read from config.json
+type Config = config.Config
+
+// LLMProviderConfig configures an LLM provider
+type LLMProviderConfig = config.LLMProviderConfig
+
+// MCPServerConfig configures an MCP server
+type MCPServerConfig = config.MCPServerConfig
+
+// Logger is the structured logger used by the client
+type Logger = logging.Logger
+
+// Frontend receives messages and posts replies: Slack in Socket Mode or through
+// the Events API, or the terminal
+type Frontend = slackbot.UserFrontend
+
+// ToolServerName is the server name of the tools added with WithTool
+const ToolServerName = "embedded"
+
+// ToolHandler runs a tool call with the arguments chosen by the LLM and returns
+// the result shown to it
+type ToolHandler func(ctx context.Context, args map[string]interface{}) (string, error)
+
+// Tool is a tool implemented by the embedding service
+type Tool struct {
+	Name        string
+	Description string
+	InputSchema map[string]interface{} // JSON Schema of the arguments; nil accepts none
+	Handler     ToolHandler
+}
+
+// NewLogger returns a logger for the client. Level is "debug", "info", "warn" or
+// "error".
+func NewLogger(name, level string) *Logger {
+	return logging.New(name, logging.ParseLevel(level))
+}
+
+// LoadConfig reads a configuration file the way the binary does: environment
+// variable overrides, overlays merged in order, defaults and validation. An empty
+// path configures the client from environment variables only.
+func LoadConfig(path string, overlays ...string) (*Config, error) {
+	return config.LoadConfig(path, nil, overlays...)
+}
+
+// Option adjusts how New builds the client
+type Option func(*settings) error
+
+// settings collects the options of New
+type settings struct {
+	cfg      *Config
+	logger   *Logger
+	frontend Frontend
+	tools    map[string]Tool
+	adjust   []func(*Config)
+}
+
+// WithConfig uses a configuration built by the caller. New applies its defaults
+// and validates it.
+func WithConfig(cfg *Config) Option {
+	return func(s *settings) error {
+		if cfg == nil {
+			return errors.New("config cannot be nil")
+		}
+		s.cfg = cfg
+		return nil
+	}
+}
+
+// WithConfigFile loads the configuration from a file and its overlays
+func WithConfigFile(path string, overlays ...string) Option {
+	return func(s *settings) error {
+		cfg, err := LoadConfig(path, overlays...)
+		if err != nil {
+			return err
+		}
+		s.cfg = cfg
+		return nil
+	}
+}
+
+// WithLogger sets the logger of the client (default: info level)
+func WithLogger(logger *Logger) Option {
+	return func(s *settings) error {
+		s.logger = logger
+		return nil
+	}
+}
+
+// WithFrontend replaces the frontend selected by the configuration
+func WithFrontend(frontend Frontend) Option {
+	return func(s *settings) error {
+		s.frontend = frontend
+		return nil
+	}
+}
+
+// WithLLMProvider configures an LLM provider and makes it the one answering
+func WithLLMProvider(name string, provider LLMProviderConfig) Option {
+	return func(s *settings) error {
+		s.adjust = append(s.adjust, func(cfg *Config) {
+			if cfg.LLM.Providers == nil {
+				cfg.LLM.Providers = make(map[string]LLMProviderConfig)
+			}
+			cfg.LLM.Providers[name] = provider
+			cfg.LLM.Provider = name
+		})
+		return nil
+	}
+}
+
+// WithMCPServer adds an MCP server, or replaces the configured server of that name
+func WithMCPServer(name string, server MCPServerConfig) Option {
+	return func(s *settings) error {
+		s.adjust = append(s.adjust, func(cfg *Config) {
+			if cfg.MCPServers == nil {
+				cfg.MCPServers = make(map[string]MCPServerConfig)
+			}
+			cfg.MCPServers[name] = server
+		})
+		return nil
+	}
+}
+
+// WithTool offers a tool of the embedding service to the LLM. MCP tools of the
+// same name take precedence.
+func WithTool(tool Tool) Option {
+	return func(s *settings) error {
+		if tool.Name == "" || tool.Handler == nil {
+			return errors.New("tool needs a name and a handler")
+		}
+		if _, exists := s.tools[tool.Name]; exists {
+			return fmt.Errorf("tool %s added twice", tool.Name)
+		}
+		s.tools[tool.Name] = tool
+		return nil
+	}
+}
+
+// Client is an embedded Slack MCP client
+type Client struct {
+	cfg    *Config
+	logger *Logger
+	bot    *app.Bot
+
+	closeOnce sync.Once
+}
+
+// New builds the client the way the binary does: it connects the MCP servers (in
+// the background when mcpStartup is async), imports the OpenAPI tools, creates
+// the frontend and sets up the bridge. Start it with Run.
+func New(opts ...Option) (*Client, error) {
+	s := &settings{tools: make(map[string]Tool)}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+	if s.logger == nil {
+		s.logger = logging.New("slack-mcp-client", logging.LevelInfo)
+	}
+	if s.cfg == nil {
+		cfg, err := LoadConfig("")
+		if err != nil {
+			return nil, err
+		}
+		s.cfg = cfg
+	}
+	cfg := s.cfg
+	for _, adjust := range s.adjust {
+		adjust(cfg)
+	}
+	cfg.ApplyDefaults()
+	if err := cfg.ValidateAfterDefaults(); err != nil {
+		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	if err := app.ConfigureNetwork(s.logger, cfg); err != nil {
+		return nil, err
+	}
+
+	var clientOpts []slackbot.ClientOption
+	if len(s.tools) > 0 {
+		clientOpts = append(clientOpts, slackbot.WithNativeTools(ToolServerName, toolServer(s.tools), toolInfos(s.tools)))
+	}
+	bot, err := app.NewBot(s.logger, cfg, s.frontend, clientOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{cfg: cfg, logger: s.logger, bot: bot}, nil
+}
+
+// Config returns the configuration the client runs with
+func (c *Client) Config() *Config {
+	return c.cfg
+}
+
+// Run handles messages until ctx is cancelled or the frontend stops, then closes
+// the client. Servers of an async mcpStartup are connected in the background, and
+// the startup self-test runs once they are ready.
+func (c *Client) Run(ctx context.Context) error {
+	c.bot.Start(ctx)
+	done := make(chan error, 1)
+	go func() {
+		done <- c.bot.Run()
+	}()
+
+	var err error
+	select {
+	case <-ctx.Done():
+	case err = <-done:
+	}
+	if closeErr := c.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Close stops the requests in progress and closes the frontend and the MCP clients
+func (c *Client) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		err = c.bot.Close()
+		c.bot.CloseMCPClients()
+	})
+	return err
+}
+
+// toolServer runs the tools added with WithTool
+type toolServer map[string]Tool
+
+// CallTool implements the bridge's client interface
+func (s toolServer) CallTool(ctx context.Context, toolName string, args map[string]interface{}) (string, error) {
+	tool, ok := s[toolName]
+	if !ok {
+		return "", fmt.Errorf("unknown tool: %s", toolName)
+	}
+	return tool.Handler(ctx, args)
+}
+
+// toolInfos describes the tools added with WithTool to the bridge
+func toolInfos(tools map[string]Tool) map[string]mcp.ToolInfo {
+	infos := make(map[string]mcp.ToolInfo, len(tools))
+	for name, tool := range tools {
+		schema := tool.InputSchema
+		if schema == nil {
+			schema = map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+		}
+		infos[name] = mcp.ToolInfo{
+			ToolName:        name,
+			ToolDescription: tool.Description,
+			InputSchema:     schema,
+			ServerName:      ToolServerName,
+		}
+	}
+	return infos
+}
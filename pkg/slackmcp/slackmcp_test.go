@@ -0,0 +1,50 @@
+package slackmcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuannvm/slack-mcp-client/internal/config"
+)
+
+func TestNewEmbeddedClient(t *testing.T) {
+	cfg := &Config{UseStdIOClient: true}
+	deployStatus := Tool{
+		Name:        "deploy_status",
+		Description: "Report the status of the latest deploy",
+		Handler: func(_ context.Context, args map[string]interface{}) (string, error) {
+			return "green", nil
+		},
+	}
+	client, err := New(
+		WithConfig(cfg),
+		WithLogger(NewLogger("test", "error")),
+		WithLLMProvider(config.ProviderOllama, LLMProviderConfig{Model: "llama3", BaseURL: "http://localhost:11434"}),
+		WithTool(deployStatus),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, config.ProviderOllama, client.Config().LLM.Provider)
+	tool, ok := client.bot.Tools()["deploy_status"]
+	require.True(t, ok, "the tool is offered to the LLM")
+	assert.Equal(t, ToolServerName, tool.ServerName)
+	result, err := tool.Client.CallTool(context.Background(), "deploy_status", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "green", result)
+	assert.NoError(t, client.Close())
+	assert.NoError(t, client.Close(), "closing twice is harmless")
+
+	server := toolServer{"deploy_status": deployStatus}
+	result, err = server.CallTool(context.Background(), "deploy_status", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "green", result)
+	_, err = server.CallTool(context.Background(), "rollback", nil)
+	assert.ErrorContains(t, err, "unknown tool")
+
+	_, err = New(WithConfig(cfg), WithTool(Tool{Name: "nameless handler"}))
+	assert.ErrorContains(t, err, "needs a name and a handler")
+	_, err = New(WithConfig(cfg), WithTool(deployStatus), WithTool(deployStatus))
+	assert.ErrorContains(t, err, "added twice")
+}